@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"fiber-hello-world/config"
+)
+
+// runConfigCommand handles "api config print [--redacted]", which shows the
+// effective configuration and where each value came from
+func runConfigCommand(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New("usage: api config print [--redacted]")
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redacted := flags.Bool("redacted", false, "hide secret values")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, setting := range cfg.Settings() {
+		value := setting.Value
+		if *redacted {
+			value = setting.Redacted()
+		}
+		fmt.Fprintf(w, "%s=%s\t# %s\n", setting.Key, value, setting.Source)
+	}
+	return w.Flush()
+}
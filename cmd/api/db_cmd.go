@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"fiber-hello-world/config"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/usecase"
+)
+
+const dbUsage = "usage: api db backup [--out file] | api db restore <file>"
+
+// runDBCommand handles "api db backup" and "api db restore". Backups are
+// taken online, so the server may keep running; restores need it stopped.
+func runDBCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(dbUsage)
+	}
+
+	switch args[0] {
+	case "backup":
+		return runDBBackup(args[1:])
+	case "restore":
+		return runDBRestore(args[1:])
+	default:
+		return errors.New(dbUsage)
+	}
+}
+
+// runDBBackup writes a verified backup to --out, or to BACKUP_DIR with its
+// retention applied
+func runDBBackup(args []string) error {
+	flags := flag.NewFlagSet("db backup", flag.ContinueOnError)
+	out := flags.String("out", "", "backup file to write instead of a new file in BACKUP_DIR")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if *out == "" && !cfg.BackupsEnabled() {
+		return errors.New("set BACKUP_DIR or pass --out")
+	}
+	// Opening a missing path would create an empty database to back up
+	if _, err := os.Stat(cfg.DBPath); err != nil {
+		return err
+	}
+
+	db, err := database.OpenDatabase(cfg.DBPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if *out != "" {
+		if err := database.BackupDatabase(context.Background(), db, *out); err != nil {
+			return err
+		}
+		fmt.Printf("Backed up %s to %s\n", cfg.DBPath, *out)
+		return nil
+	}
+
+	backupUseCase := usecase.NewBackupUseCase(database.NewSQLiteBackupStore(db, cfg.BackupDir), cfg.BackupRetention)
+	backup, err := backupUseCase.Backup()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backed up %s to %s (%d bytes, sha256 %s)\n", cfg.DBPath, backup.Name, backup.Size, backup.SHA256)
+	return nil
+}
+
+// runDBRestore verifies a backup and copies it over DB_PATH
+func runDBRestore(args []string) error {
+	if len(args) != 1 {
+		return errors.New(dbUsage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.OpenDatabase(cfg.DBPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := database.RestoreDatabase(context.Background(), db, args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s from %s\n", cfg.DBPath, args[0])
+	return nil
+}
@@ -1,22 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"fiber-hello-world/config"
-	"fiber-hello-world/internal/infrastructure/database"
-	"fiber-hello-world/internal/presentation/handler"
-	"fiber-hello-world/internal/presentation/middleware"
-	"fiber-hello-world/internal/usecase"
-	"fiber-hello-world/pkg/jwt"
-	"fiber-hello-world/pkg/validator"
-
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/swagger"
-
-	_ "fiber-hello-world/docs" // This line is needed for go-swagger to find your docs!
+	"fiber-hello-world/server"
 )
 
+// commands are the subcommands run instead of the server
+var commands = map[string]func(args []string) error{
+	"config": runConfigCommand,
+	"db":     runDBCommand,
+}
+
 // @title Fiber Authentication API
 // @version 2.0
 // @description A Go Fiber API with JWT authentication, user registration, and login functionality built with Clean Architecture
@@ -33,62 +37,84 @@ import (
 // @description Type "Bearer" followed by a space and JWT token.
 
 func main() {
-	// Load configuration
-	cfg := config.Load()
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			return
+		}
+	}
 
-	// Initialize database
-	db, err := database.InitDatabase()
+	// Load configuration
+	cfg, err := config.LoadArgs(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		log.Fatal("Failed to initialize database:", err)
+		log.Fatal("Failed to load configuration:", err)
 	}
-	defer db.Close()
-
-	// Initialize repositories
-	userRepo := database.NewSQLiteUserRepository(db)
-
-	// Initialize use cases
-	userUseCase := usecase.NewUserUseCase(userRepo)
 
-	// Initialize services
-	jwtService := jwt.NewService(cfg.JWTSecret)
-	validatorService := validator.NewService()
-
-	// Initialize handlers
-	userHandler := handler.NewUserHandler(userUseCase, jwtService, validatorService)
-
-	// Create fiber app
-	app := fiber.New(fiber.Config{
-		AppName: "Fiber Authentication API v2.0",
-	})
-
-	// Swagger documentation route
-	app.Get("/swagger/*", swagger.HandlerDefault)
-
-	// @Summary Get hello world message
-	// @Description Returns a simple hello world JSON response
-	// @Tags general
-	// @Accept json
-	// @Produce json
-	// @Success 200 {object} map[string]string
-	// @Router / [get]
-	app.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"message": "Hello World - Clean Architecture",
-			"version": "2.0",
-		})
-	})
-
-	// Public routes
-	app.Post("/register", userHandler.Register)
-	app.Post("/login", userHandler.Login)
+	// Feature modules; each can be turned off with DISABLED_MODULES
+	srv, err := server.New(cfg, server.WithModules(
+		server.DocsModule,
+		server.UsersModule,
+		server.QRLoginModule,
+		server.ShareLinksModule,
+		server.AudiencesModule,
+		server.ScimModule,
+		server.AdminModule,
+		server.EventsModule,
+		server.ReadModelsModule,
+		server.ClaimsModule,
+		server.AuthorizationModule,
+		server.BackupsModule,
+		server.ExportsModule,
+		server.DigestsModule,
+		server.AutoscalingModule,
+		server.DeprecationsModule,
+		server.RecordingsModule,
+		server.ClientVersionsModule,
+		server.ChaosModule,
+		server.PlaygroundModule,
+	))
+	if err != nil {
+		log.Fatal("Failed to initialize server:", err)
+	}
+	defer srv.Close()
 
-	// Protected routes
-	protected := app.Group("/", middleware.JWTMiddleware(jwtService))
-	protected.Get("/me", userHandler.GetMe)
+	go handleSignals(srv, cfg.ShutdownTimeout)
 
-	// Start server
-	log.Printf("Server starting on port %s", cfg.Port)
-	if err := app.Listen(":" + cfg.Port); err != nil {
+	if err := srv.Listen(); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// handleSignals shuts the server down gracefully on SIGINT or SIGTERM. On
+// SIGHUP it first hands the listening socket to a freshly started binary, so
+// upgrades and configuration changes apply without refusing connections; if
+// the new process fails to start, this one keeps serving.
+func handleSignals(srv *server.Server, timeout time.Duration) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	for sig := range signals {
+		if sig == syscall.SIGHUP {
+			log.Println("Restarting: starting a new process on the same socket")
+			if err := srv.Restart(timeout); err != nil {
+				log.Printf("Restart failed, still serving: %v", err)
+				continue
+			}
+			return
+		}
+
+		log.Printf("Shutting down, draining connections for up to %s", timeout)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("Shutdown did not finish cleanly: %v", err)
+		}
+		cancel()
+		return
+	}
+}
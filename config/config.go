@@ -1,29 +1,603 @@
 package config
 
 import (
-	"os"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Config holds application configuration
+// Config holds application configuration.
+//
+// Multi-region deployments use NodeID, IDStrategy and UsersUpdateStrategy:
+//   - IDStrategy "snowflake" allocates user IDs in the application so nodes in
+//     different regions never hand out the same ID; NodeID must then be unique
+//     per node across every region (0-31). "sequential" keeps SQLite's
+//     AUTOINCREMENT and is only safe with a single writer.
+//   - UsersUpdateStrategy picks how concurrent full-record updates to users
+//     are resolved: "last-write-wins" or "version-checked" (stale writes fail
+//     with a conflict). Partial field updates are always last-write-wins.
+//   - Reads are served by the same database the node writes to, so a client
+//     always reads its own writes. Asynchronously replicated copies in other
+//     regions may lag; IDs and versions stay valid there, but a node must not
+//     serve reads from a replica it does not also write to.
 type Config struct {
-	Port      string
-	JWTSecret string
-	DBPath    string
+	Env                     string
+	PlaygroundEnabled       bool
+	Port                    string
+	JWTSecret               string
+	JWTAudiences            string
+	DBPath                  string
+	AdminEmails             []string
+	MaxBodyBytes            int
+	MaxJSONDepth            int
+	UploadDir               string
+	AdminActionDelay        time.Duration
+	AdminApprovalKinds      []string
+	WorkerInterval          time.Duration
+	NodeID                  int
+	IDStrategy              string
+	UsersUpdateStrategy     string
+	ScimToken               string
+	SigningKeys             map[string]string
+	SignatureMaxSkew        time.Duration
+	SignatureRedisURL       string
+	TLSCertFile             string
+	TLSKeyFile              string
+	TLSClientCAFile         string
+	MTLSIdentities          map[string]string
+	ACMEDomains             []string
+	ACMEEmail               string
+	ACMECacheDir            string
+	HTTPRedirectPort        string
+	ReadTimeout             time.Duration
+	WriteTimeout            time.Duration
+	IdleTimeout             time.Duration
+	MaxHeaderBytes          int
+	MaxRequestBytes         int
+	KeepAlive               bool
+	TrustedProxies          []string
+	GeoCountryHeader        string
+	ListenAddr              string
+	ShutdownTimeout         time.Duration
+	HookWebhooks            map[string]string
+	HookWebhookSecret       string
+	HookWebhookTimeout      time.Duration
+	HookScripts             map[string]string
+	HookScriptTimeout       time.Duration
+	HookMaxAttempts         int
+	HookRetryBackoff        time.Duration
+	InboundWebhookSecrets   map[string]string
+	InboundWebhookURL       string
+	PlanPrices              map[string]string
+	EntitlementsFile        string
+	EntitlementsCacheTTL    time.Duration
+	DisabledModules         []string
+	BackupDir               string
+	FieldKeyDir             string
+	BackupInterval          time.Duration
+	BackupRetention         int
+	ExportStore             string
+	ExportDir               string
+	ExportPrefix            string
+	ExportTime              string
+	ExportS3Endpoint        string
+	ExportS3Region          string
+	ExportS3Bucket          string
+	ExportS3AccessKey       string
+	ExportS3SecretKey       string
+	ExportS3SSE             string
+	ExportEncryptionKey     string
+	KYCStore                string
+	KYCDir                  string
+	KYCS3Bucket             string
+	KYCEncryptionKey        string
+	KYCProviderURL          string
+	KYCProviderSecret       string
+	KYCProviderTimeout      time.Duration
+	HashPoolSize            int
+	ChaosEnabled            bool
+	RecordingEnabled        bool
+	RecordingSize           int
+	RecordingFile           string
+	PayloadLogRedact        []string
+	PasswordResetTTL        time.Duration
+	QRLoginTTL              time.Duration
+	AccountReportLinkTTL    time.Duration
+	AccountReportTemplate   string
+	ClientMinVersions       map[string]string
+	EnumerationProtection   bool
+	AuthMinResponseTime     time.Duration
+	AuthResponseJitter      time.Duration
+	NameScreening           bool
+	NameBlocklist           string
+	NameReserved            []string
+	OpenFGAAPIURL           string
+	OpenFGAStoreID          string
+	OpenFGAModelID          string
+	OpenFGAToken            string
+	DigestSchedule          string
+	DigestTime              string
+	DigestTemplate          string
+	DigestLinkBase          string
+	BirthdayTime            string
+	BirthdayTimezone        string
+	BirthdayCalendar        string
+	Locale                  string
+	PresenceEnabled         bool
+	PresenceOnlineWindow    time.Duration
+	PresenceRecentWindow    time.Duration
+	PresenceFlushInterval   time.Duration
+	SMTPAddr                string
+	SMTPUsername            string
+	SMTPPassword            string
+	SMTPFrom                string
+	ClaimsCacheTTL          time.Duration
+	ClaimsCacheRedisURL     string
+	MissingUserCacheTTL     time.Duration
+	MissingUserCacheSize    int
+	WarmUpDBConns           int
+	DependencyCheckInterval time.Duration
+	DependencyCheckTimeout  time.Duration
+	JSONEncoder             string
+	SLOObjectives           map[string]string
+	SLOWindow               time.Duration
+	SLOLoadShedding         bool
+	AdmissionInFlight       int
+	AdmissionSaturation     float64
+	AdmissionHashWait       time.Duration
+	AdmissionPriorities     map[string]string
+	LaneLimits              map[string]string
+	RateLimits              map[string]string
+	RoutePolicyFile         string
+	CanaryRoutes            map[string]string
+	SchemaBackfillBatch     int
+	FraudFlagScore          int
+	FraudVelocityLimit      int
+	DisposableDomains       []string
+	BreakGlassTTL           time.Duration
+	WorkerLock              string
+	WorkerLockRedisURL      string
+
+	// settings records where each value came from, for Settings
+	settings []Setting
+}
+
+// Load loads configuration. Each setting is named after its environment
+// variable and resolved with this precedence, highest first: environment
+// variables, the profile file config.<profile>.yaml, config.yaml, defaults.
+// Secrets can instead be read from a file named by <KEY>_FILE. See
+// loadFiles for how the config files are found, and LoadArgs to add flags.
+func Load() (*Config, error) {
+	return LoadArgs(nil)
 }
 
-// Load loads configuration from environment variables or defaults
-func Load() *Config {
+// config resolves every setting through the loader's sources
+func (l *loader) config() *Config {
 	return &Config{
-		Port:      getEnv("PORT", "3000"),
-		JWTSecret: getEnv("JWT_SECRET", "your-secret-key"),
-		DBPath:    getEnv("DB_PATH", "users.db"),
+		Env:                     l.getEnv("ENV", "development"),
+		PlaygroundEnabled:       l.getEnvBool("PLAYGROUND_ENABLED", true),
+		Port:                    l.getEnv("PORT", "3000"),
+		JWTSecret:               l.getEnv("JWT_SECRET", "your-secret-key"),
+		JWTAudiences:            l.getEnv("JWT_AUDIENCES", ""),
+		DBPath:                  l.getEnv("DB_PATH", "users.db"),
+		AdminEmails:             l.getEnvList("ADMIN_EMAILS"),
+		MaxBodyBytes:            l.getEnvInt("MAX_BODY_BYTES", 1048576),
+		MaxJSONDepth:            l.getEnvInt("MAX_JSON_DEPTH", 32),
+		UploadDir:               l.getEnv("UPLOAD_DIR", "uploads"),
+		AdminActionDelay:        l.getEnvDuration("ADMIN_ACTION_DELAY", 30*time.Second),
+		AdminApprovalKinds:      l.getEnvList("ADMIN_APPROVAL_KINDS"),
+		WorkerInterval:          l.getEnvDuration("WORKER_INTERVAL", time.Second),
+		NodeID:                  l.getEnvInt("NODE_ID", 0),
+		IDStrategy:              l.getEnv("ID_STRATEGY", "sequential"),
+		UsersUpdateStrategy:     l.getEnv("USERS_UPDATE_STRATEGY", "last-write-wins"),
+		ScimToken:               l.getEnv("SCIM_TOKEN", ""),
+		SigningKeys:             l.getEnvPairs("SIGNING_KEYS", ":"),
+		SignatureMaxSkew:        l.getEnvDuration("SIGNATURE_MAX_SKEW", 5*time.Minute),
+		SignatureRedisURL:       l.getEnv("SIGNATURE_REDIS_URL", ""),
+		TLSCertFile:             l.getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:              l.getEnv("TLS_KEY_FILE", ""),
+		TLSClientCAFile:         l.getEnv("TLS_CLIENT_CA_FILE", ""),
+		MTLSIdentities:          l.getEnvPairs("MTLS_IDENTITIES", "="),
+		ACMEDomains:             l.getEnvList("ACME_DOMAINS"),
+		ACMEEmail:               l.getEnv("ACME_EMAIL", ""),
+		ACMECacheDir:            l.getEnv("ACME_CACHE_DIR", "certs"),
+		HTTPRedirectPort:        l.getEnv("HTTP_REDIRECT_PORT", ""),
+		ReadTimeout:             l.getEnvDuration("READ_TIMEOUT", 10*time.Second),
+		WriteTimeout:            l.getEnvDuration("WRITE_TIMEOUT", 10*time.Second),
+		IdleTimeout:             l.getEnvDuration("IDLE_TIMEOUT", 60*time.Second),
+		MaxHeaderBytes:          l.getEnvInt("MAX_HEADER_BYTES", 8192),
+		MaxRequestBytes:         l.getEnvInt("MAX_REQUEST_BYTES", 4<<20),
+		KeepAlive:               l.getEnvBool("KEEP_ALIVE", true),
+		TrustedProxies:          l.getEnvList("TRUSTED_PROXIES"),
+		GeoCountryHeader:        l.getEnv("GEO_COUNTRY_HEADER", ""),
+		ListenAddr:              l.getEnv("LISTEN_ADDR", ""),
+		ShutdownTimeout:         l.getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		HookWebhooks:            l.getEnvPairs("HOOK_WEBHOOKS", "="),
+		HookWebhookSecret:       l.getEnv("HOOK_WEBHOOK_SECRET", ""),
+		HookWebhookTimeout:      l.getEnvDuration("HOOK_WEBHOOK_TIMEOUT", 3*time.Second),
+		HookScripts:             l.getEnvPairs("HOOK_SCRIPTS", "="),
+		HookScriptTimeout:       l.getEnvDuration("HOOK_SCRIPT_TIMEOUT", 50*time.Millisecond),
+		HookMaxAttempts:         l.getEnvInt("HOOK_MAX_ATTEMPTS", 5),
+		HookRetryBackoff:        l.getEnvDuration("HOOK_RETRY_BACKOFF", 30*time.Second),
+		InboundWebhookSecrets:   l.getEnvPairs("INBOUND_WEBHOOK_SECRETS", "="),
+		InboundWebhookURL:       l.getEnv("INBOUND_WEBHOOK_URL", ""),
+		PlanPrices:              l.getEnvPairs("PLAN_PRICES", "="),
+		EntitlementsFile:        l.getEnv("ENTITLEMENTS_FILE", ""),
+		EntitlementsCacheTTL:    l.getEnvDuration("ENTITLEMENTS_CACHE_TTL", time.Minute),
+		DisabledModules:         l.getEnvList("DISABLED_MODULES"),
+		BackupDir:               l.getEnv("BACKUP_DIR", ""),
+		FieldKeyDir:             l.getEnv("FIELD_KEY_DIR", ""),
+		BackupInterval:          l.getEnvDuration("BACKUP_INTERVAL", 24*time.Hour),
+		BackupRetention:         l.getEnvInt("BACKUP_RETENTION", 7),
+		ExportStore:             l.getEnv("EXPORT_STORE", ""),
+		ExportDir:               l.getEnv("EXPORT_DIR", "exports"),
+		ExportPrefix:            l.getEnv("EXPORT_PREFIX", ""),
+		ExportTime:              l.getEnv("EXPORT_TIME", "02:00"),
+		ExportS3Endpoint:        l.getEnv("EXPORT_S3_ENDPOINT", ""),
+		ExportS3Region:          l.getEnv("EXPORT_S3_REGION", "us-east-1"),
+		ExportS3Bucket:          l.getEnv("EXPORT_S3_BUCKET", ""),
+		ExportS3AccessKey:       l.getEnv("EXPORT_S3_ACCESS_KEY", ""),
+		ExportS3SecretKey:       l.getEnv("EXPORT_S3_SECRET_KEY", ""),
+		ExportS3SSE:             l.getEnv("EXPORT_S3_SSE", ""),
+		ExportEncryptionKey:     l.getEnv("EXPORT_ENCRYPTION_KEY", ""),
+		KYCStore:                l.getEnv("KYC_STORE", ""),
+		KYCDir:                  l.getEnv("KYC_DIR", "kyc"),
+		KYCS3Bucket:             l.getEnv("KYC_S3_BUCKET", ""),
+		KYCEncryptionKey:        l.getEnv("KYC_ENCRYPTION_KEY", ""),
+		KYCProviderURL:          l.getEnv("KYC_PROVIDER_URL", ""),
+		KYCProviderSecret:       l.getEnv("KYC_PROVIDER_SECRET", ""),
+		KYCProviderTimeout:      l.getEnvDuration("KYC_PROVIDER_TIMEOUT", 30*time.Second),
+		HashPoolSize:            l.getEnvInt("HASH_POOL_SIZE", 0),
+		ChaosEnabled:            l.getEnvBool("CHAOS_ENABLED", false),
+		RecordingEnabled:        l.getEnvBool("RECORDING_ENABLED", false),
+		RecordingSize:           l.getEnvInt("RECORDING_SIZE", 200),
+		RecordingFile:           l.getEnv("RECORDING_FILE", ""),
+		PayloadLogRedact:        l.getEnvList("PAYLOAD_LOG_REDACT"),
+		PasswordResetTTL:        l.getEnvDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+		QRLoginTTL:              l.getEnvDuration("QR_LOGIN_TTL", 2*time.Minute),
+		AccountReportLinkTTL:    l.getEnvDuration("ACCOUNT_REPORT_LINK_TTL", 15*time.Minute),
+		AccountReportTemplate:   l.getEnv("ACCOUNT_REPORT_TEMPLATE", ""),
+		ClientMinVersions:       l.getEnvPairs("CLIENT_MIN_VERSIONS", "="),
+		EnumerationProtection:   l.getEnvBool("ENUMERATION_PROTECTION", false),
+		AuthMinResponseTime:     l.getEnvDuration("AUTH_MIN_RESPONSE_TIME", 0),
+		AuthResponseJitter:      l.getEnvDuration("AUTH_RESPONSE_JITTER", 0),
+		NameScreening:           l.getEnvBool("NAME_SCREENING", false),
+		NameBlocklist:           l.getEnv("NAME_BLOCKLIST", ""),
+		NameReserved:            l.getEnvList("NAME_RESERVED"),
+		OpenFGAAPIURL:           l.getEnv("OPENFGA_API_URL", ""),
+		OpenFGAStoreID:          l.getEnv("OPENFGA_STORE_ID", ""),
+		OpenFGAModelID:          l.getEnv("OPENFGA_MODEL_ID", ""),
+		OpenFGAToken:            l.getEnv("OPENFGA_API_TOKEN", ""),
+		DigestSchedule:          l.getEnv("DIGEST_SCHEDULE", ""),
+		DigestTime:              l.getEnv("DIGEST_TIME", "08:00"),
+		DigestTemplate:          l.getEnv("DIGEST_TEMPLATE", ""),
+		DigestLinkBase:          l.getEnv("DIGEST_LINK_BASE", ""),
+		BirthdayTime:            l.getEnv("BIRTHDAY_TIME", ""),
+		BirthdayTimezone:        l.getEnv("BIRTHDAY_TIMEZONE", "UTC"),
+		BirthdayCalendar:        l.getEnv("BIRTHDAY_CALENDAR", "gregorian"),
+		Locale:                  l.getEnv("LOCALE", "en"),
+		PresenceEnabled:         l.getEnvBool("PRESENCE_ENABLED", false),
+		PresenceOnlineWindow:    l.getEnvDuration("PRESENCE_ONLINE_WINDOW", 2*time.Minute),
+		PresenceRecentWindow:    l.getEnvDuration("PRESENCE_RECENT_WINDOW", 15*time.Minute),
+		PresenceFlushInterval:   l.getEnvDuration("PRESENCE_FLUSH_INTERVAL", 30*time.Second),
+		SMTPAddr:                l.getEnv("SMTP_ADDR", ""),
+		SMTPUsername:            l.getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:            l.getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:                l.getEnv("SMTP_FROM", ""),
+		ClaimsCacheTTL:          l.getEnvDuration("CLAIMS_CACHE_TTL", 5*time.Minute),
+		ClaimsCacheRedisURL:     l.getEnv("CLAIMS_CACHE_REDIS_URL", ""),
+		MissingUserCacheTTL:     l.getEnvDuration("MISSING_USER_CACHE_TTL", 10*time.Second),
+		MissingUserCacheSize:    l.getEnvInt("MISSING_USER_CACHE_SIZE", 10000),
+		WarmUpDBConns:           l.getEnvInt("WARMUP_DB_CONNS", 4),
+		DependencyCheckInterval: l.getEnvDuration("DEPENDENCY_CHECK_INTERVAL", 30*time.Second),
+		DependencyCheckTimeout:  l.getEnvDuration("DEPENDENCY_CHECK_TIMEOUT", 2*time.Second),
+		JSONEncoder:             l.getEnv("JSON_ENCODER", "fast"),
+		SLOObjectives:           l.getEnvPairs("SLO_OBJECTIVES", "="),
+		SLOWindow:               l.getEnvDuration("SLO_WINDOW", 24*time.Hour),
+		SLOLoadShedding:         l.getEnvBool("SLO_LOAD_SHEDDING", false),
+		AdmissionInFlight:       l.getEnvInt("ADMISSION_INFLIGHT", 0),
+		AdmissionSaturation:     l.getEnvFloat("ADMISSION_SATURATION", 0),
+		AdmissionHashWait:       l.getEnvDuration("ADMISSION_HASH_WAIT", 0),
+		AdmissionPriorities:     l.getEnvPairs("ADMISSION_PRIORITIES", "="),
+		LaneLimits:              l.getEnvPairs("LANE_LIMITS", "="),
+		RateLimits:              l.getEnvPairs("RATE_LIMITS", "="),
+		RoutePolicyFile:         l.getEnv("ROUTE_POLICY_FILE", ""),
+		CanaryRoutes:            l.getEnvPairs("CANARY_ROUTES", "="),
+		SchemaBackfillBatch:     l.getEnvInt("SCHEMA_BACKFILL_BATCH", 1000),
+		FraudFlagScore:          l.getEnvInt("FRAUD_FLAG_SCORE", 50),
+		FraudVelocityLimit:      l.getEnvInt("FRAUD_VELOCITY_LIMIT", 10),
+		DisposableDomains:       l.getEnvList("DISPOSABLE_DOMAINS"),
+		BreakGlassTTL:           l.getEnvDuration("BREAK_GLASS_TTL", time.Hour),
+		WorkerLock:              l.getEnv("WORKER_LOCK", ""),
+		WorkerLockRedisURL:      l.getEnv("WORKER_LOCK_REDIS_URL", ""),
+	}
+}
+
+// OpenFGAEnabled reports whether roles are synced to OpenFGA and permission
+// checks are answered by it
+func (c *Config) OpenFGAEnabled() bool {
+	return c.OpenFGAAPIURL != ""
+}
+
+// ClaimsCacheRedisEnabled reports whether users' derived claims are also
+// cached in the Redis server at CLAIMS_CACHE_REDIS_URL, shared by all nodes
+func (c *Config) ClaimsCacheRedisEnabled() bool {
+	return c.ClaimsCacheRedisURL != ""
+}
+
+// JWTAudiencesEnabled reports whether tokens restricted to the downstream
+// services in the JWT_AUDIENCES file are issued
+func (c *Config) JWTAudiencesEnabled() bool {
+	return c.JWTAudiences != ""
+}
+
+// HookRetriesEnabled reports whether post-register, password-reset and
+// birthday hooks that fail are retried and dead-lettered rather than only
+// logged
+func (c *Config) HookRetriesEnabled() bool {
+	return c.HookMaxAttempts > 0
+}
+
+// InboundWebhooksEnabled reports whether webhooks from the providers in
+// INBOUND_WEBHOOK_SECRETS are received
+func (c *Config) InboundWebhooksEnabled() bool {
+	return len(c.InboundWebhookSecrets) > 0
+}
+
+// PlansEnabled reports whether paid plans are configured in PLAN_PRICES
+func (c *Config) PlansEnabled() bool {
+	return len(c.PlanPrices) > 0
+}
+
+// EntitlementsEnabled reports whether users' features are computed from the
+// policy in ENTITLEMENTS_FILE
+func (c *Config) EntitlementsEnabled() bool {
+	return c.EntitlementsFile != ""
+}
+
+// ScimEnabled reports whether SCIM provisioning endpoints are served
+func (c *Config) ScimEnabled() bool {
+	return c.ScimToken != ""
+}
+
+// BackupsEnabled reports whether database backups are written to BACKUP_DIR
+func (c *Config) BackupsEnabled() bool {
+	return c.BackupDir != ""
+}
+
+// FieldEncryptionEnabled reports whether users' personal data is encrypted
+// with per-user keys kept in FIELD_KEY_DIR
+func (c *Config) FieldEncryptionEnabled() bool {
+	return c.FieldKeyDir != ""
+}
+
+// ExportsEnabled reports whether nightly data exports are written to EXPORT_STORE
+func (c *Config) ExportsEnabled() bool {
+	return c.ExportStore != ""
+}
+
+// ExportTimeOfDay parses EXPORT_TIME ("HH:MM", UTC) as the time after midnight
+func (c *Config) ExportTimeOfDay() (time.Duration, error) {
+	return parseTimeOfDay("EXPORT_TIME", c.ExportTime)
+}
+
+// KYCEnabled reports whether users can submit identity documents, kept in
+// KYC_STORE, for verification
+func (c *Config) KYCEnabled() bool {
+	return c.KYCStore != ""
+}
+
+// DigestsEnabled reports whether admins are emailed digests on DIGEST_SCHEDULE
+func (c *Config) DigestsEnabled() bool {
+	return c.DigestSchedule != ""
+}
+
+// DigestTimeOfDay parses DIGEST_TIME ("HH:MM", UTC) as the time after midnight
+func (c *Config) DigestTimeOfDay() (time.Duration, error) {
+	return parseTimeOfDay("DIGEST_TIME", c.DigestTime)
+}
+
+// BirthdaysEnabled reports whether users are greeted on their birthday at
+// BIRTHDAY_TIME
+func (c *Config) BirthdaysEnabled() bool {
+	return c.BirthdayTime != ""
+}
+
+// BirthdayTimeOfDay parses BIRTHDAY_TIME ("HH:MM", in each user's time zone)
+// as the time after midnight
+func (c *Config) BirthdayTimeOfDay() (time.Duration, error) {
+	return parseTimeOfDay("BIRTHDAY_TIME", c.BirthdayTime)
+}
+
+// parseTimeOfDay parses the setting key's "HH:MM" value as the time after midnight
+func parseTimeOfDay(key, value string) (time.Duration, error) {
+	at, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be HH:MM, got %q", key, value)
 	}
+	return time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute, nil
+}
+
+// SLOEnabled reports whether routes are tracked against the SLO_OBJECTIVES
+func (c *Config) SLOEnabled() bool {
+	return len(c.SLOObjectives) > 0
+}
+
+// AdmissionEnabled reports whether low priority requests are rejected when
+// a load signal is over its ADMISSION_INFLIGHT, ADMISSION_SATURATION or
+// ADMISSION_HASH_WAIT limit
+func (c *Config) AdmissionEnabled() bool {
+	return c.AdmissionInFlight > 0 || c.AdmissionSaturation > 0 || c.AdmissionHashWait > 0
+}
+
+// WorkerLockEnabled reports whether jobs that must run once across replicas,
+// such as backups, exports and digests, are run by the instance holding a
+// lease from the WORKER_LOCK store
+func (c *Config) WorkerLockEnabled() bool {
+	return c.WorkerLock != ""
+}
+
+// LanesEnabled reports whether requests are limited per class of traffic by
+// LANE_LIMITS
+func (c *Config) LanesEnabled() bool {
+	return len(c.LaneLimits) > 0
 }
 
-// getEnv gets an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+// RateLimitsEnabled reports whether calls to the routes in RATE_LIMITS are
+// limited per client
+func (c *Config) RateLimitsEnabled() bool {
+	return len(c.RateLimits) > 0
+}
+
+// MissingUserCacheEnabled reports whether emails found to have no user are
+// remembered for MISSING_USER_CACHE_TTL
+func (c *Config) MissingUserCacheEnabled() bool {
+	return c.MissingUserCacheTTL > 0 && c.MissingUserCacheSize > 0
+}
+
+// RoutePolicyEnabled reports whether the route groups in ROUTE_POLICY_FILE
+// are enforced
+func (c *Config) RoutePolicyEnabled() bool {
+	return c.RoutePolicyFile != ""
+}
+
+// CanariesEnabled reports whether a cohort of users is sent to alternate
+// implementations of the routes in CANARY_ROUTES
+func (c *Config) CanariesEnabled() bool {
+	return len(c.CanaryRoutes) > 0
+}
+
+// SignedRequestsEnabled reports whether sensitive endpoints require request signatures
+func (c *Config) SignedRequestsEnabled() bool {
+	return len(c.SigningKeys) > 0
+}
+
+// ListenAddress returns where the server listens: LISTEN_ADDR when set
+// (a TCP address, "unix:/path" or "systemd"), otherwise PORT on all interfaces
+func (c *Config) ListenAddress() string {
+	if c.ListenAddr != "" {
+		return c.ListenAddr
+	}
+	return ":" + c.Port
+}
+
+// TLSEnabled reports whether the server listens with TLS, using either the
+// certificate files or ACME
+func (c *Config) TLSEnabled() bool {
+	return (c.TLSCertFile != "" && c.TLSKeyFile != "") || c.ACMEEnabled()
+}
+
+// ACMEEnabled reports whether certificates are obtained automatically via ACME
+func (c *Config) ACMEEnabled() bool {
+	return len(c.ACMEDomains) > 0
+}
+
+// MTLSEnabled reports whether client certificates can authenticate requests
+func (c *Config) MTLSEnabled() bool {
+	return c.TLSEnabled() && c.TLSClientCAFile != "" && len(c.MTLSIdentities) > 0
+}
+
+// IsDevelopment reports whether the application runs in development mode
+func (c *Config) IsDevelopment() bool {
+	return c.Env == "development"
+}
+
+// getEnv gets a setting or returns a default value
+func (l *loader) getEnv(key, defaultValue string) string {
+	if value, source, ok := l.lookup(key); ok {
+		l.record(key, value, source)
 		return value
 	}
+	l.record(key, defaultValue, sourceDefault)
 	return defaultValue
 }
+
+// getEnvInt gets an integer setting or returns a default value
+func (l *loader) getEnvInt(key string, defaultValue int) int {
+	if raw, source, ok := l.lookup(key); ok {
+		if value, err := strconv.Atoi(raw); err == nil {
+			l.record(key, raw, source)
+			return value
+		}
+	}
+	l.record(key, strconv.Itoa(defaultValue), sourceDefault)
+	return defaultValue
+}
+
+// getEnvFloat gets a decimal setting or returns a default value
+func (l *loader) getEnvFloat(key string, defaultValue float64) float64 {
+	if raw, source, ok := l.lookup(key); ok {
+		if value, err := strconv.ParseFloat(raw, 64); err == nil {
+			l.record(key, raw, source)
+			return value
+		}
+	}
+	l.record(key, strconv.FormatFloat(defaultValue, 'g', -1, 64), sourceDefault)
+	return defaultValue
+}
+
+// getEnvBool gets a boolean setting or returns a default value
+func (l *loader) getEnvBool(key string, defaultValue bool) bool {
+	if l.bools == nil {
+		l.bools = make(map[string]bool)
+	}
+	l.bools[key] = true
+
+	if raw, source, ok := l.lookup(key); ok {
+		if value, err := strconv.ParseBool(raw); err == nil {
+			l.record(key, raw, source)
+			return value
+		}
+	}
+	l.record(key, strconv.FormatBool(defaultValue), sourceDefault)
+	return defaultValue
+}
+
+// getEnvDuration gets a duration setting (e.g. "30s") or returns a default value
+func (l *loader) getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if raw, source, ok := l.lookup(key); ok {
+		if value, err := time.ParseDuration(raw); err == nil && value >= 0 {
+			l.record(key, raw, source)
+			return value
+		}
+	}
+	l.record(key, defaultValue.String(), sourceDefault)
+	return defaultValue
+}
+
+// getEnvList gets a comma-separated setting as a trimmed list
+func (l *loader) getEnvList(key string) []string {
+	raw, source, ok := l.lookup(key)
+	if !ok {
+		source = sourceDefault
+	}
+	l.record(key, raw, source)
+
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvPairs gets a comma-separated list of "key<sep>value" pairs as a map,
+// splitting each entry at the first sep. Entries without sep or with an
+// empty key or value are skipped.
+func (l *loader) getEnvPairs(key, sep string) map[string]string {
+	var pairs map[string]string
+	for _, entry := range l.getEnvList(key) {
+		name, value, ok := strings.Cut(entry, sep)
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			continue
+		}
+		if pairs == nil {
+			pairs = make(map[string]string)
+		}
+		pairs[name] = value
+	}
+	return pairs
+}
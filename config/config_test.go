@@ -2,7 +2,10 @@ package config
 
 import (
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoad(t *testing.T) {
@@ -15,22 +18,337 @@ func TestLoad(t *testing.T) {
 			name:    "default values",
 			envVars: map[string]string{},
 			expected: &Config{
-				Port:      "3000",
-				JWTSecret: "your-secret-key",
-				DBPath:    "users.db",
+				Env:                     "development",
+				PlaygroundEnabled:       true,
+				Port:                    "3000",
+				JWTSecret:               "your-secret-key",
+				DBPath:                  "users.db",
+				MaxBodyBytes:            1048576,
+				MaxJSONDepth:            32,
+				UploadDir:               "uploads",
+				AdminActionDelay:        30 * time.Second,
+				WorkerInterval:          time.Second,
+				NodeID:                  0,
+				IDStrategy:              "sequential",
+				UsersUpdateStrategy:     "last-write-wins",
+				SignatureMaxSkew:        5 * time.Minute,
+				ACMECacheDir:            "certs",
+				ReadTimeout:             10 * time.Second,
+				WriteTimeout:            10 * time.Second,
+				IdleTimeout:             60 * time.Second,
+				MaxHeaderBytes:          8192,
+				MaxRequestBytes:         4 << 20,
+				KeepAlive:               true,
+				ShutdownTimeout:         30 * time.Second,
+				HookWebhookTimeout:      3 * time.Second,
+				HookScriptTimeout:       50 * time.Millisecond,
+				HookMaxAttempts:         5,
+				HookRetryBackoff:        30 * time.Second,
+				EntitlementsCacheTTL:    time.Minute,
+				BackupInterval:          24 * time.Hour,
+				BackupRetention:         7,
+				ExportDir:               "exports",
+				ExportTime:              "02:00",
+				ExportS3Region:          "us-east-1",
+				KYCDir:                  "kyc",
+				KYCProviderTimeout:      30 * time.Second,
+				PasswordResetTTL:        30 * time.Minute,
+				QRLoginTTL:              2 * time.Minute,
+				AccountReportLinkTTL:    15 * time.Minute,
+				RecordingSize:           200,
+				DigestTime:              "08:00",
+				BirthdayTimezone:        "UTC",
+				BirthdayCalendar:        "gregorian",
+				Locale:                  "en",
+				PresenceOnlineWindow:    2 * time.Minute,
+				PresenceRecentWindow:    15 * time.Minute,
+				PresenceFlushInterval:   30 * time.Second,
+				ClaimsCacheTTL:          5 * time.Minute,
+				MissingUserCacheTTL:     10 * time.Second,
+				MissingUserCacheSize:    10000,
+				WarmUpDBConns:           4,
+				DependencyCheckInterval: 30 * time.Second,
+				DependencyCheckTimeout:  2 * time.Second,
+				JSONEncoder:             "fast",
+				SLOWindow:               24 * time.Hour,
+				SchemaBackfillBatch:     1000,
+				FraudFlagScore:          50,
+				FraudVelocityLimit:      10,
+				BreakGlassTTL:           time.Hour,
 			},
 		},
 		{
 			name: "custom values from env",
 			envVars: map[string]string{
-				"PORT":       "8080",
-				"JWT_SECRET": "super-secret-key",
-				"DB_PATH":    "/tmp/test.db",
+				"PORT":                      "8080",
+				"JWT_SECRET":                "super-secret-key",
+				"JWT_AUDIENCES":             "/etc/api/audiences.yaml",
+				"DB_PATH":                   "/tmp/test.db",
+				"ADMIN_EMAILS":              "admin@example.com, ops@example.com,",
+				"MAX_BODY_BYTES":            "2048",
+				"MAX_JSON_DEPTH":            "8",
+				"UPLOAD_DIR":                "/var/uploads",
+				"ENV":                       "production",
+				"PLAYGROUND_ENABLED":        "false",
+				"ADMIN_ACTION_DELAY":        "2m",
+				"ADMIN_APPROVAL_KINDS":      "delete_users, incident_reset",
+				"WORKER_INTERVAL":           "250ms",
+				"NODE_ID":                   "7",
+				"ID_STRATEGY":               "snowflake",
+				"USERS_UPDATE_STRATEGY":     "version-checked",
+				"SCIM_TOKEN":                "scim-secret",
+				"SIGNING_KEYS":              "mobile:abc123, ops:s3:cr3t, broken",
+				"SIGNATURE_MAX_SKEW":        "1m",
+				"SIGNATURE_REDIS_URL":       "redis://nonces:6379",
+				"TLS_CERT_FILE":             "/etc/tls/cert.pem",
+				"TLS_KEY_FILE":              "/etc/tls/key.pem",
+				"TLS_CLIENT_CA_FILE":        "/etc/tls/ca.pem",
+				"ACME_DOMAINS":              "api.example.com, www.example.com",
+				"ACME_EMAIL":                "ops@example.com",
+				"ACME_CACHE_DIR":            "/var/lib/api/certs",
+				"HTTP_REDIRECT_PORT":        "80",
+				"READ_TIMEOUT":              "5s",
+				"WRITE_TIMEOUT":             "15s",
+				"IDLE_TIMEOUT":              "2m",
+				"MAX_HEADER_BYTES":          "16384",
+				"MAX_REQUEST_BYTES":         "8388608",
+				"KEEP_ALIVE":                "false",
+				"TRUSTED_PROXIES":           "10.0.0.0/8, 192.168.1.5",
+				"GEO_COUNTRY_HEADER":        "CF-IPCountry",
+				"LISTEN_ADDR":               "unix:/run/api/api.sock",
+				"SHUTDOWN_TIMEOUT":          "1m",
+				"HOOK_WEBHOOKS":             "pre-register=https://policy.internal/register?v=2, post-login=https://policy.internal/login",
+				"HOOK_WEBHOOK_SECRET":       "hook-secret",
+				"HOOK_WEBHOOK_TIMEOUT":      "1s",
+				"HOOK_SCRIPTS":              "pre-register=/etc/api/signup.rules",
+				"HOOK_SCRIPT_TIMEOUT":       "20ms",
+				"HOOK_MAX_ATTEMPTS":         "8",
+				"HOOK_RETRY_BACKOFF":        "1m",
+				"INBOUND_WEBHOOK_SECRETS":   "stripe=whsec_test, sendgrid=MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE==",
+				"INBOUND_WEBHOOK_URL":       "https://api.example.com",
+				"PLAN_PRICES":               "price_pro=pro,price_team=team",
+				"ENTITLEMENTS_FILE":         "/etc/api/entitlements.yaml",
+				"ENTITLEMENTS_CACHE_TTL":    "30s",
+				"DISABLED_MODULES":          "playground, scim",
+				"BACKUP_DIR":                "/var/backups/api",
+				"FIELD_KEY_DIR":             "/var/lib/api-keys",
+				"BACKUP_INTERVAL":           "6h",
+				"BACKUP_RETENTION":          "14",
+				"EXPORT_STORE":              "s3",
+				"EXPORT_PREFIX":             "api/",
+				"EXPORT_TIME":               "03:30",
+				"EXPORT_S3_REGION":          "eu-west-1",
+				"EXPORT_S3_BUCKET":          "exports",
+				"EXPORT_S3_SSE":             "aws:kms",
+				"KYC_STORE":                 "file",
+				"KYC_DIR":                   "/var/lib/api/kyc",
+				"KYC_S3_BUCKET":             "documents",
+				"KYC_PROVIDER_URL":          "https://kyc.example.com/verify",
+				"KYC_PROVIDER_SECRET":       "kyc-secret",
+				"KYC_PROVIDER_TIMEOUT":      "1m",
+				"HASH_POOL_SIZE":            "4",
+				"CHAOS_ENABLED":             "true",
+				"RECORDING_ENABLED":         "true",
+				"RECORDING_SIZE":            "50",
+				"RECORDING_FILE":            "/var/log/api/recordings.jsonl",
+				"PAYLOAD_LOG_REDACT":        "birthday, email",
+				"PASSWORD_RESET_TTL":        "15m",
+				"QR_LOGIN_TTL":              "90s",
+				"ACCOUNT_REPORT_LINK_TTL":   "1h",
+				"ACCOUNT_REPORT_TEMPLATE":   "/etc/api/account-report.tmpl",
+				"CLIENT_MIN_VERSIONS":       "ios=2.3.0, android=2.1.4",
+				"ENUMERATION_PROTECTION":    "true",
+				"AUTH_MIN_RESPONSE_TIME":    "300ms",
+				"AUTH_RESPONSE_JITTER":      "50ms",
+				"NAME_SCREENING":            "true",
+				"NAME_BLOCKLIST":            "/etc/api/blocklist.txt",
+				"NAME_RESERVED":             "admin, support",
+				"OPENFGA_API_URL":           "http://openfga:8080",
+				"OPENFGA_STORE_ID":          "01HSTORE",
+				"OPENFGA_MODEL_ID":          "01HMODEL",
+				"OPENFGA_API_TOKEN":         "fga-secret",
+				"DIGEST_SCHEDULE":           "weekly",
+				"DIGEST_TIME":               "07:30",
+				"DIGEST_TEMPLATE":           "/etc/api/digest.tmpl",
+				"DIGEST_LINK_BASE":          "https://admin.example.com",
+				"BIRTHDAY_TIME":             "09:00",
+				"BIRTHDAY_TIMEZONE":         "Asia/Bangkok",
+				"BIRTHDAY_CALENDAR":         "buddhist",
+				"LOCALE":                    "th",
+				"PRESENCE_ENABLED":          "true",
+				"PRESENCE_ONLINE_WINDOW":    "5m",
+				"PRESENCE_RECENT_WINDOW":    "1h",
+				"PRESENCE_FLUSH_INTERVAL":   "1m",
+				"SMTP_ADDR":                 "smtp.example.com:587",
+				"SMTP_USERNAME":             "api",
+				"SMTP_PASSWORD":             "smtp-secret",
+				"SMTP_FROM":                 "API <api@example.com>",
+				"CLAIMS_CACHE_TTL":          "30s",
+				"CLAIMS_CACHE_REDIS_URL":    "redis://:cache-secret@redis:6379/2",
+				"MISSING_USER_CACHE_TTL":    "2s",
+				"MISSING_USER_CACHE_SIZE":   "500",
+				"WARMUP_DB_CONNS":           "8",
+				"DEPENDENCY_CHECK_INTERVAL": "1m",
+				"DEPENDENCY_CHECK_TIMEOUT":  "500ms",
+				"JSON_ENCODER":              "std",
+				"SLO_OBJECTIVES":            "POST /login=99.9% 300ms, GET /me=99.5%",
+				"SLO_WINDOW":                "6h",
+				"SLO_LOAD_SHEDDING":         "true",
+				"ADMISSION_INFLIGHT":        "200",
+				"ADMISSION_SATURATION":      "2.5",
+				"ADMISSION_HASH_WAIT":       "250ms",
+				"ADMISSION_PRIORITIES":      "POST /register=low, /admin/users/export=low",
+				"LANE_LIMITS":               "anonymous=50, export=2",
+				"RATE_LIMITS":               "POST /login=10/1m, POST /register=5/1h",
+				"ROUTE_POLICY_FILE":         "/etc/api/routes.yaml",
+				"CANARY_ROUTES":             "POST /login=10%, GET /me=flag:new_profile",
+				"SCHEMA_BACKFILL_BATCH":     "250",
+				"FRAUD_FLAG_SCORE":          "70",
+				"FRAUD_VELOCITY_LIMIT":      "20",
+				"DISPOSABLE_DOMAINS":        "burner.example, spam.example",
+				"BREAK_GLASS_TTL":           "30m",
+				"WORKER_LOCK":               "redis",
+				"WORKER_LOCK_REDIS_URL":     "redis://locks:6379/1",
+				"MTLS_IDENTITIES":           "spiffe://cluster.local/ns/billing/sa/worker=billing@service.internal, dns:reports.internal=reports@service.internal",
 			},
 			expected: &Config{
-				Port:      "8080",
-				JWTSecret: "super-secret-key",
-				DBPath:    "/tmp/test.db",
+				Env:                 "production",
+				PlaygroundEnabled:   false,
+				Port:                "8080",
+				JWTSecret:           "super-secret-key",
+				DBPath:              "/tmp/test.db",
+				AdminEmails:         []string{"admin@example.com", "ops@example.com"},
+				MaxBodyBytes:        2048,
+				MaxJSONDepth:        8,
+				UploadDir:           "/var/uploads",
+				AdminActionDelay:    2 * time.Minute,
+				AdminApprovalKinds:  []string{"delete_users", "incident_reset"},
+				WorkerInterval:      250 * time.Millisecond,
+				NodeID:              7,
+				IDStrategy:          "snowflake",
+				UsersUpdateStrategy: "version-checked",
+				ScimToken:           "scim-secret",
+				SigningKeys:         map[string]string{"mobile": "abc123", "ops": "s3:cr3t"},
+				SignatureMaxSkew:    time.Minute,
+				SignatureRedisURL:   "redis://nonces:6379",
+				TLSCertFile:         "/etc/tls/cert.pem",
+				TLSKeyFile:          "/etc/tls/key.pem",
+				TLSClientCAFile:     "/etc/tls/ca.pem",
+				ACMEDomains:         []string{"api.example.com", "www.example.com"},
+				ACMEEmail:           "ops@example.com",
+				ACMECacheDir:        "/var/lib/api/certs",
+				HTTPRedirectPort:    "80",
+				ReadTimeout:         5 * time.Second,
+				WriteTimeout:        15 * time.Second,
+				IdleTimeout:         2 * time.Minute,
+				MaxHeaderBytes:      16384,
+				MaxRequestBytes:     8 << 20,
+				KeepAlive:           false,
+				TrustedProxies:      []string{"10.0.0.0/8", "192.168.1.5"},
+				GeoCountryHeader:    "CF-IPCountry",
+				ListenAddr:          "unix:/run/api/api.sock",
+				ShutdownTimeout:     time.Minute,
+				HookWebhooks: map[string]string{
+					"pre-register": "https://policy.internal/register?v=2",
+					"post-login":   "https://policy.internal/login",
+				},
+				HookWebhookSecret:       "hook-secret",
+				HookWebhookTimeout:      time.Second,
+				HookScripts:             map[string]string{"pre-register": "/etc/api/signup.rules"},
+				HookScriptTimeout:       20 * time.Millisecond,
+				HookMaxAttempts:         8,
+				HookRetryBackoff:        time.Minute,
+				InboundWebhookSecrets:   map[string]string{"stripe": "whsec_test", "sendgrid": "MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE=="},
+				InboundWebhookURL:       "https://api.example.com",
+				PlanPrices:              map[string]string{"price_pro": "pro", "price_team": "team"},
+				EntitlementsFile:        "/etc/api/entitlements.yaml",
+				EntitlementsCacheTTL:    30 * time.Second,
+				DisabledModules:         []string{"playground", "scim"},
+				BackupDir:               "/var/backups/api",
+				FieldKeyDir:             "/var/lib/api-keys",
+				BackupInterval:          6 * time.Hour,
+				BackupRetention:         14,
+				ExportStore:             "s3",
+				ExportDir:               "exports",
+				ExportPrefix:            "api/",
+				ExportTime:              "03:30",
+				ExportS3Region:          "eu-west-1",
+				ExportS3Bucket:          "exports",
+				ExportS3SSE:             "aws:kms",
+				KYCStore:                "file",
+				KYCDir:                  "/var/lib/api/kyc",
+				KYCS3Bucket:             "documents",
+				KYCProviderURL:          "https://kyc.example.com/verify",
+				KYCProviderSecret:       "kyc-secret",
+				KYCProviderTimeout:      time.Minute,
+				HashPoolSize:            4,
+				ChaosEnabled:            true,
+				RecordingEnabled:        true,
+				RecordingSize:           50,
+				RecordingFile:           "/var/log/api/recordings.jsonl",
+				PayloadLogRedact:        []string{"birthday", "email"},
+				PasswordResetTTL:        15 * time.Minute,
+				QRLoginTTL:              90 * time.Second,
+				AccountReportLinkTTL:    time.Hour,
+				AccountReportTemplate:   "/etc/api/account-report.tmpl",
+				ClientMinVersions:       map[string]string{"ios": "2.3.0", "android": "2.1.4"},
+				EnumerationProtection:   true,
+				AuthMinResponseTime:     300 * time.Millisecond,
+				AuthResponseJitter:      50 * time.Millisecond,
+				NameScreening:           true,
+				NameBlocklist:           "/etc/api/blocklist.txt",
+				NameReserved:            []string{"admin", "support"},
+				OpenFGAAPIURL:           "http://openfga:8080",
+				OpenFGAStoreID:          "01HSTORE",
+				OpenFGAModelID:          "01HMODEL",
+				OpenFGAToken:            "fga-secret",
+				DigestSchedule:          "weekly",
+				DigestTime:              "07:30",
+				DigestTemplate:          "/etc/api/digest.tmpl",
+				JWTAudiences:            "/etc/api/audiences.yaml",
+				DigestLinkBase:          "https://admin.example.com",
+				BirthdayTime:            "09:00",
+				BirthdayTimezone:        "Asia/Bangkok",
+				BirthdayCalendar:        "buddhist",
+				Locale:                  "th",
+				PresenceEnabled:         true,
+				PresenceOnlineWindow:    5 * time.Minute,
+				PresenceRecentWindow:    time.Hour,
+				PresenceFlushInterval:   time.Minute,
+				SMTPAddr:                "smtp.example.com:587",
+				SMTPUsername:            "api",
+				SMTPPassword:            "smtp-secret",
+				SMTPFrom:                "API <api@example.com>",
+				ClaimsCacheTTL:          30 * time.Second,
+				ClaimsCacheRedisURL:     "redis://:cache-secret@redis:6379/2",
+				MissingUserCacheTTL:     2 * time.Second,
+				MissingUserCacheSize:    500,
+				WarmUpDBConns:           8,
+				DependencyCheckInterval: time.Minute,
+				DependencyCheckTimeout:  500 * time.Millisecond,
+				JSONEncoder:             "std",
+				SLOObjectives:           map[string]string{"POST /login": "99.9% 300ms", "GET /me": "99.5%"},
+				SLOWindow:               6 * time.Hour,
+				SLOLoadShedding:         true,
+				AdmissionInFlight:       200,
+				AdmissionSaturation:     2.5,
+				AdmissionHashWait:       250 * time.Millisecond,
+				AdmissionPriorities:     map[string]string{"POST /register": "low", "/admin/users/export": "low"},
+				LaneLimits:              map[string]string{"anonymous": "50", "export": "2"},
+				RateLimits:              map[string]string{"POST /login": "10/1m", "POST /register": "5/1h"},
+				RoutePolicyFile:         "/etc/api/routes.yaml",
+				CanaryRoutes:            map[string]string{"POST /login": "10%", "GET /me": "flag:new_profile"},
+				SchemaBackfillBatch:     250,
+				FraudFlagScore:          70,
+				FraudVelocityLimit:      20,
+				DisposableDomains:       []string{"burner.example", "spam.example"},
+				BreakGlassTTL:           30 * time.Minute,
+				WorkerLock:              "redis",
+				WorkerLockRedisURL:      "redis://locks:6379/1",
+				MTLSIdentities: map[string]string{
+					"spiffe://cluster.local/ns/billing/sa/worker": "billing@service.internal",
+					"dns:reports.internal":                        "reports@service.internal",
+				},
 			},
 		},
 		{
@@ -39,9 +357,63 @@ func TestLoad(t *testing.T) {
 				"PORT": "9000",
 			},
 			expected: &Config{
-				Port:      "9000",
-				JWTSecret: "your-secret-key",
-				DBPath:    "users.db",
+				Env:                     "development",
+				PlaygroundEnabled:       true,
+				Port:                    "9000",
+				JWTSecret:               "your-secret-key",
+				DBPath:                  "users.db",
+				MaxBodyBytes:            1048576,
+				MaxJSONDepth:            32,
+				UploadDir:               "uploads",
+				AdminActionDelay:        30 * time.Second,
+				WorkerInterval:          time.Second,
+				NodeID:                  0,
+				IDStrategy:              "sequential",
+				UsersUpdateStrategy:     "last-write-wins",
+				SignatureMaxSkew:        5 * time.Minute,
+				ACMECacheDir:            "certs",
+				ReadTimeout:             10 * time.Second,
+				WriteTimeout:            10 * time.Second,
+				IdleTimeout:             60 * time.Second,
+				MaxHeaderBytes:          8192,
+				MaxRequestBytes:         4 << 20,
+				KeepAlive:               true,
+				ShutdownTimeout:         30 * time.Second,
+				HookWebhookTimeout:      3 * time.Second,
+				HookScriptTimeout:       50 * time.Millisecond,
+				HookMaxAttempts:         5,
+				HookRetryBackoff:        30 * time.Second,
+				EntitlementsCacheTTL:    time.Minute,
+				BackupInterval:          24 * time.Hour,
+				BackupRetention:         7,
+				ExportDir:               "exports",
+				ExportTime:              "02:00",
+				ExportS3Region:          "us-east-1",
+				KYCDir:                  "kyc",
+				KYCProviderTimeout:      30 * time.Second,
+				PasswordResetTTL:        30 * time.Minute,
+				QRLoginTTL:              2 * time.Minute,
+				AccountReportLinkTTL:    15 * time.Minute,
+				RecordingSize:           200,
+				DigestTime:              "08:00",
+				BirthdayTimezone:        "UTC",
+				BirthdayCalendar:        "gregorian",
+				Locale:                  "en",
+				PresenceOnlineWindow:    2 * time.Minute,
+				PresenceRecentWindow:    15 * time.Minute,
+				PresenceFlushInterval:   30 * time.Second,
+				ClaimsCacheTTL:          5 * time.Minute,
+				MissingUserCacheTTL:     10 * time.Second,
+				MissingUserCacheSize:    10000,
+				WarmUpDBConns:           4,
+				DependencyCheckInterval: 30 * time.Second,
+				DependencyCheckTimeout:  2 * time.Second,
+				JSONEncoder:             "fast",
+				SLOWindow:               24 * time.Hour,
+				SchemaBackfillBatch:     1000,
+				FraudFlagScore:          50,
+				FraudVelocityLimit:      10,
+				BreakGlassTTL:           time.Hour,
 			},
 		},
 	}
@@ -52,6 +424,58 @@ func TestLoad(t *testing.T) {
 			os.Unsetenv("PORT")
 			os.Unsetenv("JWT_SECRET")
 			os.Unsetenv("DB_PATH")
+			os.Unsetenv("ADMIN_EMAILS")
+			os.Unsetenv("MAX_BODY_BYTES")
+			os.Unsetenv("MAX_JSON_DEPTH")
+			os.Unsetenv("UPLOAD_DIR")
+			os.Unsetenv("ENV")
+			os.Unsetenv("PLAYGROUND_ENABLED")
+			os.Unsetenv("ADMIN_ACTION_DELAY")
+			os.Unsetenv("ADMIN_APPROVAL_KINDS")
+			os.Unsetenv("WORKER_INTERVAL")
+			os.Unsetenv("NODE_ID")
+			os.Unsetenv("ID_STRATEGY")
+			os.Unsetenv("USERS_UPDATE_STRATEGY")
+			os.Unsetenv("SCIM_TOKEN")
+			os.Unsetenv("SIGNING_KEYS")
+			os.Unsetenv("SIGNATURE_MAX_SKEW")
+			os.Unsetenv("SIGNATURE_REDIS_URL")
+			os.Unsetenv("TLS_CERT_FILE")
+			os.Unsetenv("TLS_KEY_FILE")
+			os.Unsetenv("TLS_CLIENT_CA_FILE")
+			os.Unsetenv("MTLS_IDENTITIES")
+			os.Unsetenv("ACME_DOMAINS")
+			os.Unsetenv("ACME_EMAIL")
+			os.Unsetenv("ACME_CACHE_DIR")
+			os.Unsetenv("HTTP_REDIRECT_PORT")
+			os.Unsetenv("READ_TIMEOUT")
+			os.Unsetenv("WRITE_TIMEOUT")
+			os.Unsetenv("IDLE_TIMEOUT")
+			os.Unsetenv("MAX_HEADER_BYTES")
+			os.Unsetenv("MAX_REQUEST_BYTES")
+			os.Unsetenv("KEEP_ALIVE")
+			os.Unsetenv("TRUSTED_PROXIES")
+			os.Unsetenv("LISTEN_ADDR")
+			os.Unsetenv("SHUTDOWN_TIMEOUT")
+			os.Unsetenv("HOOK_WEBHOOKS")
+			os.Unsetenv("HOOK_WEBHOOK_SECRET")
+			os.Unsetenv("HOOK_WEBHOOK_TIMEOUT")
+			os.Unsetenv("HOOK_SCRIPTS")
+			os.Unsetenv("HOOK_SCRIPT_TIMEOUT")
+			os.Unsetenv("HOOK_MAX_ATTEMPTS")
+			os.Unsetenv("HOOK_RETRY_BACKOFF")
+			os.Unsetenv("INBOUND_WEBHOOK_SECRETS")
+			os.Unsetenv("INBOUND_WEBHOOK_URL")
+			os.Unsetenv("PLAN_PRICES")
+			os.Unsetenv("ENTITLEMENTS_FILE")
+			os.Unsetenv("ENTITLEMENTS_CACHE_TTL")
+			os.Unsetenv("DISABLED_MODULES")
+			os.Unsetenv("BACKUP_DIR")
+			os.Unsetenv("BACKUP_INTERVAL")
+			os.Unsetenv("BACKUP_RETENTION")
+			for _, key := range []string{"EXPORT_STORE", "EXPORT_PREFIX", "EXPORT_TIME", "EXPORT_S3_REGION", "EXPORT_S3_BUCKET", "EXPORT_S3_SSE", "KYC_STORE", "KYC_DIR", "KYC_S3_BUCKET", "KYC_PROVIDER_URL", "KYC_PROVIDER_SECRET", "KYC_PROVIDER_TIMEOUT", "HASH_POOL_SIZE", "CHAOS_ENABLED", "RECORDING_ENABLED", "RECORDING_SIZE", "RECORDING_FILE", "PAYLOAD_LOG_REDACT", "PASSWORD_RESET_TTL", "QR_LOGIN_TTL", "ACCOUNT_REPORT_LINK_TTL", "ACCOUNT_REPORT_TEMPLATE", "CLIENT_MIN_VERSIONS", "ENUMERATION_PROTECTION", "AUTH_MIN_RESPONSE_TIME", "AUTH_RESPONSE_JITTER", "NAME_SCREENING", "NAME_BLOCKLIST", "NAME_RESERVED", "GEO_COUNTRY_HEADER", "FIELD_KEY_DIR", "OPENFGA_API_URL", "OPENFGA_STORE_ID", "OPENFGA_MODEL_ID", "OPENFGA_API_TOKEN", "DIGEST_SCHEDULE", "DIGEST_TIME", "BIRTHDAY_TIME", "BIRTHDAY_TIMEZONE", "BIRTHDAY_CALENDAR", "LOCALE", "PRESENCE_ENABLED", "PRESENCE_ONLINE_WINDOW", "PRESENCE_RECENT_WINDOW", "PRESENCE_FLUSH_INTERVAL", "SMTP_ADDR", "SMTP_FROM", "JWT_AUDIENCES", "CLAIMS_CACHE_TTL", "CLAIMS_CACHE_REDIS_URL", "MISSING_USER_CACHE_TTL", "MISSING_USER_CACHE_SIZE", "WARMUP_DB_CONNS", "DEPENDENCY_CHECK_INTERVAL", "DEPENDENCY_CHECK_TIMEOUT", "JSON_ENCODER", "SLO_OBJECTIVES", "SLO_WINDOW", "SLO_LOAD_SHEDDING", "ADMISSION_INFLIGHT", "ADMISSION_SATURATION", "ADMISSION_HASH_WAIT", "ADMISSION_PRIORITIES", "LANE_LIMITS", "RATE_LIMITS", "ROUTE_POLICY_FILE", "CANARY_ROUTES", "SCHEMA_BACKFILL_BATCH", "FRAUD_FLAG_SCORE", "FRAUD_VELOCITY_LIMIT", "DISPOSABLE_DOMAINS", "BREAK_GLASS_TTL", "WORKER_LOCK", "WORKER_LOCK_REDIS_URL"} {
+				os.Unsetenv(key)
+			}
 
 			// Set test environment variables
 			for key, value := range tt.envVars {
@@ -59,9 +483,18 @@ func TestLoad(t *testing.T) {
 			}
 
 			// Test Load function
-			config := Load()
+			config, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
 
 			// Verify results
+			if config.Env != tt.expected.Env {
+				t.Errorf("Env = %v, want %v", config.Env, tt.expected.Env)
+			}
+			if config.PlaygroundEnabled != tt.expected.PlaygroundEnabled {
+				t.Errorf("PlaygroundEnabled = %v, want %v", config.PlaygroundEnabled, tt.expected.PlaygroundEnabled)
+			}
 			if config.Port != tt.expected.Port {
 				t.Errorf("Port = %v, want %v", config.Port, tt.expected.Port)
 			}
@@ -71,6 +504,247 @@ func TestLoad(t *testing.T) {
 			if config.DBPath != tt.expected.DBPath {
 				t.Errorf("DBPath = %v, want %v", config.DBPath, tt.expected.DBPath)
 			}
+			if config.MaxBodyBytes != tt.expected.MaxBodyBytes {
+				t.Errorf("MaxBodyBytes = %v, want %v", config.MaxBodyBytes, tt.expected.MaxBodyBytes)
+			}
+			if config.MaxJSONDepth != tt.expected.MaxJSONDepth {
+				t.Errorf("MaxJSONDepth = %v, want %v", config.MaxJSONDepth, tt.expected.MaxJSONDepth)
+			}
+			if config.UploadDir != tt.expected.UploadDir {
+				t.Errorf("UploadDir = %v, want %v", config.UploadDir, tt.expected.UploadDir)
+			}
+			if config.AdminActionDelay != tt.expected.AdminActionDelay {
+				t.Errorf("AdminActionDelay = %v, want %v", config.AdminActionDelay, tt.expected.AdminActionDelay)
+			}
+			if !reflect.DeepEqual(config.AdminApprovalKinds, tt.expected.AdminApprovalKinds) {
+				t.Errorf("AdminApprovalKinds = %v, want %v", config.AdminApprovalKinds, tt.expected.AdminApprovalKinds)
+			}
+			if config.WorkerInterval != tt.expected.WorkerInterval {
+				t.Errorf("WorkerInterval = %v, want %v", config.WorkerInterval, tt.expected.WorkerInterval)
+			}
+			if config.NodeID != tt.expected.NodeID {
+				t.Errorf("NodeID = %v, want %v", config.NodeID, tt.expected.NodeID)
+			}
+			if config.IDStrategy != tt.expected.IDStrategy {
+				t.Errorf("IDStrategy = %v, want %v", config.IDStrategy, tt.expected.IDStrategy)
+			}
+			if config.UsersUpdateStrategy != tt.expected.UsersUpdateStrategy {
+				t.Errorf("UsersUpdateStrategy = %v, want %v", config.UsersUpdateStrategy, tt.expected.UsersUpdateStrategy)
+			}
+			if config.ScimToken != tt.expected.ScimToken {
+				t.Errorf("ScimToken = %v, want %v", config.ScimToken, tt.expected.ScimToken)
+			}
+			if !reflect.DeepEqual(config.SigningKeys, tt.expected.SigningKeys) {
+				t.Errorf("SigningKeys = %v, want %v", config.SigningKeys, tt.expected.SigningKeys)
+			}
+			if config.SignatureRedisURL != tt.expected.SignatureRedisURL {
+				t.Errorf("SignatureRedisURL = %v, want %v", config.SignatureRedisURL, tt.expected.SignatureRedisURL)
+			}
+			if config.SignatureMaxSkew != tt.expected.SignatureMaxSkew {
+				t.Errorf("SignatureMaxSkew = %v, want %v", config.SignatureMaxSkew, tt.expected.SignatureMaxSkew)
+			}
+			if config.TLSCertFile != tt.expected.TLSCertFile || config.TLSKeyFile != tt.expected.TLSKeyFile || config.TLSClientCAFile != tt.expected.TLSClientCAFile {
+				t.Errorf("TLS files = %v/%v/%v, want %v/%v/%v", config.TLSCertFile, config.TLSKeyFile, config.TLSClientCAFile,
+					tt.expected.TLSCertFile, tt.expected.TLSKeyFile, tt.expected.TLSClientCAFile)
+			}
+			if !reflect.DeepEqual(config.ACMEDomains, tt.expected.ACMEDomains) || config.ACMEEmail != tt.expected.ACMEEmail ||
+				config.ACMECacheDir != tt.expected.ACMECacheDir || config.HTTPRedirectPort != tt.expected.HTTPRedirectPort {
+				t.Errorf("ACME = %v/%v/%v/%v, want %v/%v/%v/%v", config.ACMEDomains, config.ACMEEmail, config.ACMECacheDir, config.HTTPRedirectPort,
+					tt.expected.ACMEDomains, tt.expected.ACMEEmail, tt.expected.ACMECacheDir, tt.expected.HTTPRedirectPort)
+			}
+			if config.ReadTimeout != tt.expected.ReadTimeout || config.WriteTimeout != tt.expected.WriteTimeout || config.IdleTimeout != tt.expected.IdleTimeout {
+				t.Errorf("timeouts = %v/%v/%v, want %v/%v/%v", config.ReadTimeout, config.WriteTimeout, config.IdleTimeout,
+					tt.expected.ReadTimeout, tt.expected.WriteTimeout, tt.expected.IdleTimeout)
+			}
+			if config.MaxHeaderBytes != tt.expected.MaxHeaderBytes || config.MaxRequestBytes != tt.expected.MaxRequestBytes || config.KeepAlive != tt.expected.KeepAlive {
+				t.Errorf("limits = %v/%v/%v, want %v/%v/%v", config.MaxHeaderBytes, config.MaxRequestBytes, config.KeepAlive,
+					tt.expected.MaxHeaderBytes, tt.expected.MaxRequestBytes, tt.expected.KeepAlive)
+			}
+			if !reflect.DeepEqual(config.HookWebhooks, tt.expected.HookWebhooks) || config.HookWebhookSecret != tt.expected.HookWebhookSecret ||
+				config.HookWebhookTimeout != tt.expected.HookWebhookTimeout {
+				t.Errorf("hook webhooks = %v/%v/%v, want %v/%v/%v", config.HookWebhooks, config.HookWebhookSecret, config.HookWebhookTimeout,
+					tt.expected.HookWebhooks, tt.expected.HookWebhookSecret, tt.expected.HookWebhookTimeout)
+			}
+			if !reflect.DeepEqual(config.HookScripts, tt.expected.HookScripts) || config.HookScriptTimeout != tt.expected.HookScriptTimeout {
+				t.Errorf("hook scripts = %v/%v, want %v/%v", config.HookScripts, config.HookScriptTimeout,
+					tt.expected.HookScripts, tt.expected.HookScriptTimeout)
+			}
+			if config.HookMaxAttempts != tt.expected.HookMaxAttempts || config.HookRetryBackoff != tt.expected.HookRetryBackoff {
+				t.Errorf("hook retries = %v/%v, want %v/%v", config.HookMaxAttempts, config.HookRetryBackoff,
+					tt.expected.HookMaxAttempts, tt.expected.HookRetryBackoff)
+			}
+			if !reflect.DeepEqual(config.InboundWebhookSecrets, tt.expected.InboundWebhookSecrets) || config.InboundWebhookURL != tt.expected.InboundWebhookURL {
+				t.Errorf("inbound webhooks = %v/%v, want %v/%v", config.InboundWebhookSecrets, config.InboundWebhookURL,
+					tt.expected.InboundWebhookSecrets, tt.expected.InboundWebhookURL)
+			}
+			if !reflect.DeepEqual(config.PlanPrices, tt.expected.PlanPrices) {
+				t.Errorf("PlanPrices = %v, want %v", config.PlanPrices, tt.expected.PlanPrices)
+			}
+			if config.EntitlementsFile != tt.expected.EntitlementsFile || config.EntitlementsCacheTTL != tt.expected.EntitlementsCacheTTL {
+				t.Errorf("entitlements = %q/%v, want %q/%v", config.EntitlementsFile, config.EntitlementsCacheTTL,
+					tt.expected.EntitlementsFile, tt.expected.EntitlementsCacheTTL)
+			}
+			if !reflect.DeepEqual(config.DisabledModules, tt.expected.DisabledModules) {
+				t.Errorf("DisabledModules = %v, want %v", config.DisabledModules, tt.expected.DisabledModules)
+			}
+			if config.BackupDir != tt.expected.BackupDir || config.BackupInterval != tt.expected.BackupInterval ||
+				config.BackupRetention != tt.expected.BackupRetention {
+				t.Errorf("backups = %q/%v/%d, want %q/%v/%d", config.BackupDir, config.BackupInterval, config.BackupRetention,
+					tt.expected.BackupDir, tt.expected.BackupInterval, tt.expected.BackupRetention)
+			}
+			if config.ExportStore != tt.expected.ExportStore || config.ExportDir != tt.expected.ExportDir ||
+				config.ExportPrefix != tt.expected.ExportPrefix || config.ExportTime != tt.expected.ExportTime ||
+				config.ExportS3Region != tt.expected.ExportS3Region || config.ExportS3Bucket != tt.expected.ExportS3Bucket ||
+				config.ExportS3SSE != tt.expected.ExportS3SSE {
+				t.Errorf("exports = %+v, want %+v", config, tt.expected)
+			}
+			if config.KYCStore != tt.expected.KYCStore || config.KYCDir != tt.expected.KYCDir || config.KYCS3Bucket != tt.expected.KYCS3Bucket ||
+				config.KYCProviderURL != tt.expected.KYCProviderURL || config.KYCProviderSecret != tt.expected.KYCProviderSecret ||
+				config.KYCProviderTimeout != tt.expected.KYCProviderTimeout {
+				t.Errorf("KYC = %q/%q/%q/%q/%q/%v, want %q/%q/%q/%q/%q/%v", config.KYCStore, config.KYCDir, config.KYCS3Bucket,
+					config.KYCProviderURL, config.KYCProviderSecret, config.KYCProviderTimeout, tt.expected.KYCStore, tt.expected.KYCDir,
+					tt.expected.KYCS3Bucket, tt.expected.KYCProviderURL, tt.expected.KYCProviderSecret, tt.expected.KYCProviderTimeout)
+			}
+			if config.HashPoolSize != tt.expected.HashPoolSize || config.ChaosEnabled != tt.expected.ChaosEnabled {
+				t.Errorf("HashPoolSize/ChaosEnabled = %v/%v, want %v/%v", config.HashPoolSize, config.ChaosEnabled,
+					tt.expected.HashPoolSize, tt.expected.ChaosEnabled)
+			}
+			if config.RecordingEnabled != tt.expected.RecordingEnabled || config.RecordingSize != tt.expected.RecordingSize ||
+				config.RecordingFile != tt.expected.RecordingFile {
+				t.Errorf("RecordingEnabled/RecordingSize/RecordingFile = %v/%v/%v, want %v/%v/%v", config.RecordingEnabled, config.RecordingSize, config.RecordingFile,
+					tt.expected.RecordingEnabled, tt.expected.RecordingSize, tt.expected.RecordingFile)
+			}
+			if !reflect.DeepEqual(config.PayloadLogRedact, tt.expected.PayloadLogRedact) {
+				t.Errorf("PayloadLogRedact = %v, want %v", config.PayloadLogRedact, tt.expected.PayloadLogRedact)
+			}
+			if config.NameScreening != tt.expected.NameScreening || config.NameBlocklist != tt.expected.NameBlocklist ||
+				!reflect.DeepEqual(config.NameReserved, tt.expected.NameReserved) {
+				t.Errorf("NameScreening/NameBlocklist/NameReserved = %v/%v/%v, want %v/%v/%v", config.NameScreening, config.NameBlocklist, config.NameReserved,
+					tt.expected.NameScreening, tt.expected.NameBlocklist, tt.expected.NameReserved)
+			}
+			if config.PasswordResetTTL != tt.expected.PasswordResetTTL || config.EnumerationProtection != tt.expected.EnumerationProtection {
+				t.Errorf("PasswordResetTTL/EnumerationProtection = %v/%v, want %v/%v", config.PasswordResetTTL, config.EnumerationProtection,
+					tt.expected.PasswordResetTTL, tt.expected.EnumerationProtection)
+			}
+			if config.AccountReportLinkTTL != tt.expected.AccountReportLinkTTL || config.AccountReportTemplate != tt.expected.AccountReportTemplate {
+				t.Errorf("AccountReport = %v/%v, want %v/%v", config.AccountReportLinkTTL, config.AccountReportTemplate,
+					tt.expected.AccountReportLinkTTL, tt.expected.AccountReportTemplate)
+			}
+			if config.QRLoginTTL != tt.expected.QRLoginTTL {
+				t.Errorf("QRLoginTTL = %v, want %v", config.QRLoginTTL, tt.expected.QRLoginTTL)
+			}
+			if !reflect.DeepEqual(config.ClientMinVersions, tt.expected.ClientMinVersions) {
+				t.Errorf("ClientMinVersions = %v, want %v", config.ClientMinVersions, tt.expected.ClientMinVersions)
+			}
+			if config.ShutdownTimeout != tt.expected.ShutdownTimeout {
+				t.Errorf("ShutdownTimeout = %v, want %v", config.ShutdownTimeout, tt.expected.ShutdownTimeout)
+			}
+			if config.ListenAddr != tt.expected.ListenAddr {
+				t.Errorf("ListenAddr = %v, want %v", config.ListenAddr, tt.expected.ListenAddr)
+			}
+			if !reflect.DeepEqual(config.TrustedProxies, tt.expected.TrustedProxies) {
+				t.Errorf("TrustedProxies = %v, want %v", config.TrustedProxies, tt.expected.TrustedProxies)
+			}
+			if config.FieldKeyDir != tt.expected.FieldKeyDir {
+				t.Errorf("FieldKeyDir = %v, want %v", config.FieldKeyDir, tt.expected.FieldKeyDir)
+			}
+			if config.OpenFGAAPIURL != tt.expected.OpenFGAAPIURL || config.OpenFGAStoreID != tt.expected.OpenFGAStoreID ||
+				config.OpenFGAModelID != tt.expected.OpenFGAModelID || config.OpenFGAToken != tt.expected.OpenFGAToken {
+				t.Errorf("OpenFGA = %v/%v/%v/%v, want %v/%v/%v/%v", config.OpenFGAAPIURL, config.OpenFGAStoreID, config.OpenFGAModelID, config.OpenFGAToken,
+					tt.expected.OpenFGAAPIURL, tt.expected.OpenFGAStoreID, tt.expected.OpenFGAModelID, tt.expected.OpenFGAToken)
+			}
+			if config.DigestSchedule != tt.expected.DigestSchedule || config.DigestTime != tt.expected.DigestTime ||
+				config.DigestTemplate != tt.expected.DigestTemplate || config.DigestLinkBase != tt.expected.DigestLinkBase {
+				t.Errorf("Digest = %v/%v/%v/%v, want %v/%v/%v/%v", config.DigestSchedule, config.DigestTime, config.DigestTemplate, config.DigestLinkBase,
+					tt.expected.DigestSchedule, tt.expected.DigestTime, tt.expected.DigestTemplate, tt.expected.DigestLinkBase)
+			}
+			if config.BirthdayTime != tt.expected.BirthdayTime || config.BirthdayTimezone != tt.expected.BirthdayTimezone {
+				t.Errorf("birthdays = %q/%q, want %q/%q", config.BirthdayTime, config.BirthdayTimezone,
+					tt.expected.BirthdayTime, tt.expected.BirthdayTimezone)
+			}
+			if config.BirthdayCalendar != tt.expected.BirthdayCalendar || config.Locale != tt.expected.Locale {
+				t.Errorf("BirthdayCalendar/Locale = %q/%q, want %q/%q", config.BirthdayCalendar, config.Locale,
+					tt.expected.BirthdayCalendar, tt.expected.Locale)
+			}
+			if config.PresenceEnabled != tt.expected.PresenceEnabled || config.PresenceOnlineWindow != tt.expected.PresenceOnlineWindow ||
+				config.PresenceRecentWindow != tt.expected.PresenceRecentWindow || config.PresenceFlushInterval != tt.expected.PresenceFlushInterval {
+				t.Errorf("presence = %v/%v/%v/%v, want %v/%v/%v/%v", config.PresenceEnabled, config.PresenceOnlineWindow, config.PresenceRecentWindow, config.PresenceFlushInterval,
+					tt.expected.PresenceEnabled, tt.expected.PresenceOnlineWindow, tt.expected.PresenceRecentWindow, tt.expected.PresenceFlushInterval)
+			}
+			if config.SMTPAddr != tt.expected.SMTPAddr || config.SMTPUsername != tt.expected.SMTPUsername ||
+				config.SMTPPassword != tt.expected.SMTPPassword || config.SMTPFrom != tt.expected.SMTPFrom {
+				t.Errorf("SMTP = %v/%v/%v/%v, want %v/%v/%v/%v", config.SMTPAddr, config.SMTPUsername, config.SMTPPassword, config.SMTPFrom,
+					tt.expected.SMTPAddr, tt.expected.SMTPUsername, tt.expected.SMTPPassword, tt.expected.SMTPFrom)
+			}
+			if config.ClaimsCacheTTL != tt.expected.ClaimsCacheTTL || config.ClaimsCacheRedisURL != tt.expected.ClaimsCacheRedisURL {
+				t.Errorf("ClaimsCache = %v/%v, want %v/%v", config.ClaimsCacheTTL, config.ClaimsCacheRedisURL, tt.expected.ClaimsCacheTTL, tt.expected.ClaimsCacheRedisURL)
+			}
+			if config.AuthMinResponseTime != tt.expected.AuthMinResponseTime || config.AuthResponseJitter != tt.expected.AuthResponseJitter {
+				t.Errorf("AuthMinResponseTime/AuthResponseJitter = %v/%v, want %v/%v", config.AuthMinResponseTime, config.AuthResponseJitter,
+					tt.expected.AuthMinResponseTime, tt.expected.AuthResponseJitter)
+			}
+			if config.MissingUserCacheTTL != tt.expected.MissingUserCacheTTL || config.MissingUserCacheSize != tt.expected.MissingUserCacheSize {
+				t.Errorf("MissingUserCache = %v/%d, want %v/%d", config.MissingUserCacheTTL, config.MissingUserCacheSize, tt.expected.MissingUserCacheTTL, tt.expected.MissingUserCacheSize)
+			}
+			if config.DependencyCheckInterval != tt.expected.DependencyCheckInterval || config.DependencyCheckTimeout != tt.expected.DependencyCheckTimeout {
+				t.Errorf("DependencyCheckInterval/Timeout = %v/%v, want %v/%v", config.DependencyCheckInterval, config.DependencyCheckTimeout,
+					tt.expected.DependencyCheckInterval, tt.expected.DependencyCheckTimeout)
+			}
+			if config.WarmUpDBConns != tt.expected.WarmUpDBConns {
+				t.Errorf("WarmUpDBConns = %v, want %v", config.WarmUpDBConns, tt.expected.WarmUpDBConns)
+			}
+			if config.JSONEncoder != tt.expected.JSONEncoder {
+				t.Errorf("JSONEncoder = %v, want %v", config.JSONEncoder, tt.expected.JSONEncoder)
+			}
+			if !reflect.DeepEqual(config.SLOObjectives, tt.expected.SLOObjectives) || config.SLOWindow != tt.expected.SLOWindow ||
+				config.SLOLoadShedding != tt.expected.SLOLoadShedding {
+				t.Errorf("SLO = %v/%v/%v, want %v/%v/%v", config.SLOObjectives, config.SLOWindow, config.SLOLoadShedding,
+					tt.expected.SLOObjectives, tt.expected.SLOWindow, tt.expected.SLOLoadShedding)
+			}
+			if config.AdmissionInFlight != tt.expected.AdmissionInFlight || config.AdmissionSaturation != tt.expected.AdmissionSaturation ||
+				config.AdmissionHashWait != tt.expected.AdmissionHashWait || !reflect.DeepEqual(config.AdmissionPriorities, tt.expected.AdmissionPriorities) {
+				t.Errorf("Admission = %v/%v/%v/%v, want %v/%v/%v/%v", config.AdmissionInFlight, config.AdmissionSaturation, config.AdmissionHashWait, config.AdmissionPriorities,
+					tt.expected.AdmissionInFlight, tt.expected.AdmissionSaturation, tt.expected.AdmissionHashWait, tt.expected.AdmissionPriorities)
+			}
+			if !reflect.DeepEqual(config.LaneLimits, tt.expected.LaneLimits) {
+				t.Errorf("LaneLimits = %v, want %v", config.LaneLimits, tt.expected.LaneLimits)
+			}
+			if !reflect.DeepEqual(config.RateLimits, tt.expected.RateLimits) {
+				t.Errorf("RateLimits = %v, want %v", config.RateLimits, tt.expected.RateLimits)
+			}
+			if config.RoutePolicyFile != tt.expected.RoutePolicyFile {
+				t.Errorf("RoutePolicyFile = %q, want %q", config.RoutePolicyFile, tt.expected.RoutePolicyFile)
+			}
+			if !reflect.DeepEqual(config.CanaryRoutes, tt.expected.CanaryRoutes) {
+				t.Errorf("CanaryRoutes = %v, want %v", config.CanaryRoutes, tt.expected.CanaryRoutes)
+			}
+			if config.SchemaBackfillBatch != tt.expected.SchemaBackfillBatch {
+				t.Errorf("SchemaBackfillBatch = %v, want %v", config.SchemaBackfillBatch, tt.expected.SchemaBackfillBatch)
+			}
+			if config.FraudFlagScore != tt.expected.FraudFlagScore || config.FraudVelocityLimit != tt.expected.FraudVelocityLimit {
+				t.Errorf("FraudFlagScore/FraudVelocityLimit = %v/%v, want %v/%v", config.FraudFlagScore, config.FraudVelocityLimit, tt.expected.FraudFlagScore, tt.expected.FraudVelocityLimit)
+			}
+			if !reflect.DeepEqual(config.DisposableDomains, tt.expected.DisposableDomains) {
+				t.Errorf("DisposableDomains = %v, want %v", config.DisposableDomains, tt.expected.DisposableDomains)
+			}
+			if config.BreakGlassTTL != tt.expected.BreakGlassTTL {
+				t.Errorf("BreakGlassTTL = %v, want %v", config.BreakGlassTTL, tt.expected.BreakGlassTTL)
+			}
+			if config.WorkerLock != tt.expected.WorkerLock || config.WorkerLockRedisURL != tt.expected.WorkerLockRedisURL {
+				t.Errorf("WorkerLock = %v/%v, want %v/%v", config.WorkerLock, config.WorkerLockRedisURL, tt.expected.WorkerLock, tt.expected.WorkerLockRedisURL)
+			}
+			if config.JWTAudiences != tt.expected.JWTAudiences {
+				t.Errorf("JWTAudiences = %v, want %v", config.JWTAudiences, tt.expected.JWTAudiences)
+			}
+			if config.GeoCountryHeader != tt.expected.GeoCountryHeader {
+				t.Errorf("GeoCountryHeader = %v, want %v", config.GeoCountryHeader, tt.expected.GeoCountryHeader)
+			}
+			if !reflect.DeepEqual(config.MTLSIdentities, tt.expected.MTLSIdentities) {
+				t.Errorf("MTLSIdentities = %v, want %v", config.MTLSIdentities, tt.expected.MTLSIdentities)
+			}
+			if !reflect.DeepEqual(config.AdminEmails, tt.expected.AdminEmails) {
+				t.Errorf("AdminEmails = %v, want %v", config.AdminEmails, tt.expected.AdminEmails)
+			}
 
 			// Clean up
 			for key := range tt.envVars {
@@ -122,7 +796,8 @@ func TestGetEnv(t *testing.T) {
 			}
 
 			// Test getEnv function
-			result := getEnv(tt.key, tt.defaultValue)
+			l := &loader{sources: []source{envSource()}}
+			result := l.getEnv(tt.key, tt.defaultValue)
 
 			// Verify result
 			if result != tt.expected {
@@ -152,3 +827,107 @@ func TestConfigStruct(t *testing.T) {
 		t.Errorf("DBPath = %v, want %v", config.DBPath, "test.db")
 	}
 }
+
+func TestConfig_IsDevelopment(t *testing.T) {
+	if !(&Config{Env: "development"}).IsDevelopment() {
+		t.Error("IsDevelopment() should be true for development")
+	}
+	if (&Config{Env: "production"}).IsDevelopment() {
+		t.Error("IsDevelopment() should be false for production")
+	}
+}
+
+func TestConfig_ScimEnabled(t *testing.T) {
+	if (&Config{}).ScimEnabled() {
+		t.Error("ScimEnabled() should be false without a token")
+	}
+	if !(&Config{ScimToken: "secret"}).ScimEnabled() {
+		t.Error("ScimEnabled() should be true with a token")
+	}
+}
+
+func TestConfig_SignedRequestsEnabled(t *testing.T) {
+	if (&Config{}).SignedRequestsEnabled() {
+		t.Error("SignedRequestsEnabled() should be false without keys")
+	}
+	if !(&Config{SigningKeys: map[string]string{"mobile": "secret"}}).SignedRequestsEnabled() {
+		t.Error("SignedRequestsEnabled() should be true with keys")
+	}
+}
+
+func TestConfig_TLS(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		wantTLS  bool
+		wantMTLS bool
+	}{
+		{"disabled", Config{}, false, false},
+		{"cert without key", Config{TLSCertFile: "cert.pem"}, false, false},
+		{"tls", Config{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"}, true, false},
+		{"acme", Config{ACMEDomains: []string{"api.example.com"}}, true, false},
+		{"client CA without identities", Config{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", TLSClientCAFile: "ca.pem"}, true, false},
+		{"mtls", Config{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", TLSClientCAFile: "ca.pem",
+			MTLSIdentities: map[string]string{"dns:worker": "worker@service.internal"}}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.TLSEnabled(); got != tt.wantTLS {
+				t.Errorf("TLSEnabled() = %v, want %v", got, tt.wantTLS)
+			}
+			if got := tt.config.MTLSEnabled(); got != tt.wantMTLS {
+				t.Errorf("MTLSEnabled() = %v, want %v", got, tt.wantMTLS)
+			}
+		})
+	}
+}
+
+func TestConfig_ListenAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"port", Config{Port: "3000"}, ":3000"},
+		{"unix socket", Config{Port: "3000", ListenAddr: "unix:/run/api/api.sock"}, "unix:/run/api/api.sock"},
+		{"systemd", Config{Port: "3000", ListenAddr: "systemd"}, "systemd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.ListenAddress(); got != tt.want {
+				t.Errorf("ListenAddress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_ExportTimeOfDay(t *testing.T) {
+	tests := []struct {
+		exportTime string
+		want       time.Duration
+		wantErr    bool
+	}{
+		{"02:00", 2 * time.Hour, false},
+		{"23:45", 23*time.Hour + 45*time.Minute, false},
+		{"2am", 0, true},
+		{"25:00", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := (&Config{ExportTime: tt.exportTime}).ExportTimeOfDay()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ExportTimeOfDay(%q) = %v, %v; want %v, error %v", tt.exportTime, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestConfig_DigestTimeOfDay(t *testing.T) {
+	if got, err := (&Config{DigestTime: "08:30"}).DigestTimeOfDay(); err != nil || got != 8*time.Hour+30*time.Minute {
+		t.Errorf("DigestTimeOfDay() = %v, %v; want 8h30m", got, err)
+	}
+	if _, err := (&Config{DigestTime: "8am"}).DigestTimeOfDay(); err == nil || !strings.Contains(err.Error(), "DIGEST_TIME") {
+		t.Errorf("DigestTimeOfDay() error = %v, want it to name DIGEST_TIME", err)
+	}
+}
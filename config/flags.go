@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sourceFlag names settings given as command-line flags
+const sourceFlag = "flag"
+
+// LoadArgs loads configuration like Load, with command-line flags taking
+// precedence over everything else. Every setting has a flag named after its
+// environment variable, e.g. --jwt-secret for JWT_SECRET and
+// --jwt-secret-file for JWT_SECRET_FILE, plus --config-dir.
+// With --help the flags are printed and flag.ErrHelp is returned.
+func LoadArgs(args []string) (*Config, error) {
+	flags, err := flagSource(args)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &loader{sources: []source{flags, envSource()}}
+	if err := l.loadFiles(); err != nil {
+		return nil, err
+	}
+
+	cfg := l.config()
+	if l.err != nil {
+		return nil, l.err
+	}
+	if err := l.checkUnknown(); err != nil {
+		return nil, err
+	}
+	cfg.settings = l.settings
+	return cfg, nil
+}
+
+// flagName converts a setting's environment variable name to its flag name
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// flagSource parses args into a source. The flags are generated from the
+// settings Config reads, so every new setting gets a flag.
+func flagSource(args []string) (source, error) {
+	values := make(map[string]string)
+	s := source{name: sourceFlag, lookup: func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}}
+
+	// Resolving with no sources lists every setting with its default
+	defaults := &loader{}
+	defaults.config()
+	var settings []Setting
+	for _, setting := range defaults.settings {
+		settings = append(settings, setting)
+		if secretKeys[setting.Key] {
+			settings = append(settings, Setting{Key: setting.Key + fileSuffix})
+		}
+	}
+	settings = append(settings, Setting{Key: "CONFIG_DIR", Value: "."})
+
+	flags := flag.NewFlagSet("api", flag.ContinueOnError)
+	for _, setting := range settings {
+		usage := fmt.Sprintf("same as %s", setting.Key)
+		if defaults.bools[setting.Key] {
+			flags.Var(boolValue{values, setting.Key}, flagName(setting.Key), usage)
+			continue
+		}
+		flags.Var(stringValue{values, setting.Key}, flagName(setting.Key), usage)
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: api [flags]\n       api config print [--redacted]\n       api db backup [--out file] | api db restore <file>\n\n")
+		fmt.Fprintf(flags.Output(), "Flags override environment variables and config files:\n")
+		for _, setting := range settings {
+			fmt.Fprintf(flags.Output(), "  --%s\n    \tsame as %s", flagName(setting.Key), setting.Key)
+			if setting.Value != "" {
+				fmt.Fprintf(flags.Output(), " (default %q)", setting.Value)
+			}
+			fmt.Fprintln(flags.Output())
+		}
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return s, err
+	}
+	if flags.NArg() > 0 {
+		return s, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+	return s, nil
+}
+
+// stringValue stores a flag's value under its setting key
+type stringValue struct {
+	values map[string]string
+	key    string
+}
+
+func (v stringValue) String() string { return "" }
+
+func (v stringValue) Set(value string) error {
+	v.values[v.key] = value
+	return nil
+}
+
+// boolValue is a stringValue that may be given without a value, e.g. --keep-alive
+type boolValue stringValue
+
+func (v boolValue) String() string   { return "" }
+func (v boolValue) IsBoolFlag() bool { return true }
+
+func (v boolValue) Set(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return errors.New("must be true or false")
+	}
+	v.values[v.key] = value
+	return nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadArgs(t *testing.T) {
+	writeConfigFiles(t, map[string]string{"config.yaml": "port: 8080\nkeep_alive: true\n"})
+	t.Setenv("JWT_SECRET", "env-secret")
+	t.Setenv("SHUTDOWN_TIMEOUT", "")
+
+	cfg, err := LoadArgs([]string{
+		"--port=9090",
+		"--jwt-secret", "flag-secret",
+		"--keep-alive=false",
+		"--playground-enabled",
+		"--admin-emails=a@example.com,b@example.com",
+		"--shutdown-timeout=5s",
+	})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+
+	if cfg.Port != "9090" || cfg.JWTSecret != "flag-secret" || cfg.KeepAlive || !cfg.PlaygroundEnabled {
+		t.Errorf("LoadArgs() = port %q, secret %q, keep-alive %v, playground %v",
+			cfg.Port, cfg.JWTSecret, cfg.KeepAlive, cfg.PlaygroundEnabled)
+	}
+	if want := []string{"a@example.com", "b@example.com"}; !reflect.DeepEqual(cfg.AdminEmails, want) {
+		t.Errorf("AdminEmails = %v, want %v", cfg.AdminEmails, want)
+	}
+	if cfg.ShutdownTimeout != 5*time.Second {
+		t.Errorf("ShutdownTimeout = %v, want 5s", cfg.ShutdownTimeout)
+	}
+	for _, setting := range cfg.Settings() {
+		if setting.Key == "PORT" && setting.Source != "flag" {
+			t.Errorf("source of PORT = %q, want flag", setting.Source)
+		}
+	}
+}
+
+func TestLoadArgs_Profile(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{"config.staging.yaml": "port: 4000\n"})
+	t.Setenv("CONFIG_DIR", "")
+
+	// --env and --config-dir choose the files
+	cfg, err := LoadArgs([]string{"--env=staging", "--config-dir=" + dir})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+	if cfg.Env != "staging" || cfg.Port != "4000" {
+		t.Errorf("LoadArgs() = env %q, port %q; want staging, 4000", cfg.Env, cfg.Port)
+	}
+}
+
+func TestLoadArgs_SecretFile(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{"jwt_secret": "file-secret\n"})
+	t.Setenv("JWT_SECRET", "env-secret")
+
+	// A file given as a flag overrides the environment variable
+	cfg, err := LoadArgs([]string{"--jwt-secret-file=" + filepath.Join(dir, "jwt_secret")})
+	if err != nil {
+		t.Fatalf("LoadArgs() error = %v", err)
+	}
+	if cfg.JWTSecret != "file-secret" {
+		t.Errorf("JWTSecret = %q, want file-secret", cfg.JWTSecret)
+	}
+}
+
+func TestLoadArgs_Errors(t *testing.T) {
+	writeConfigFiles(t, nil)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown flag", []string{"--prot=8080"}},
+		{"invalid bool", []string{"--keep-alive=maybe"}},
+		{"positional argument", []string{"serve"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadArgs(tt.args); err == nil {
+				t.Errorf("LoadArgs(%v) should fail", tt.args)
+			}
+		})
+	}
+
+	if _, err := LoadArgs([]string{"--help"}); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("LoadArgs(--help) error = %v, want flag.ErrHelp", err)
+	}
+}
+
+func TestFlagName(t *testing.T) {
+	tests := map[string]string{
+		"PORT":                "port",
+		"JWT_SECRET":          "jwt-secret",
+		"HOOK_WEBHOOK_SECRET": "hook-webhook-secret",
+	}
+	for key, want := range tests {
+		if got := flagName(key); got != want {
+			t.Errorf("flagName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
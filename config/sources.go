@@ -0,0 +1,266 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources a setting can come from, as reported by Settings
+const (
+	sourceDefault = "default"
+	sourceEnv     = "env"
+)
+
+// fileSuffix marks a secret read from a file, e.g. JWT_SECRET_FILE
+const fileSuffix = "_FILE"
+
+// maxSecretFileSize caps the size of a secret file
+const maxSecretFileSize = 64 << 10
+
+// secretKeys are the settings hidden by Setting.Redacted. Each can also be
+// read from a file named by the setting with fileSuffix.
+var secretKeys = map[string]bool{
+	"JWT_SECRET":              true,
+	"SCIM_TOKEN":              true,
+	"SIGNING_KEYS":            true,
+	"HOOK_WEBHOOK_SECRET":     true,
+	"INBOUND_WEBHOOK_SECRETS": true,
+	"EXPORT_S3_SECRET_KEY":    true,
+	"EXPORT_ENCRYPTION_KEY":   true,
+	"KYC_ENCRYPTION_KEY":      true,
+	"KYC_PROVIDER_SECRET":     true,
+	"OPENFGA_API_TOKEN":       true,
+	"SMTP_PASSWORD":           true,
+	// The URL may hold the Redis password
+	"CLAIMS_CACHE_REDIS_URL": true,
+}
+
+// profileFiles maps ENV values to the short names of their profile files
+var profileFiles = map[string]string{
+	"development": "dev",
+	"production":  "prod",
+}
+
+// Setting is a resolved configuration value and where it came from: "env",
+// a file name or "default"
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// Redacted returns the value with secrets replaced, for printing
+func (s Setting) Redacted() string {
+	if secretKeys[s.Key] && s.Value != "" {
+		return "[redacted]"
+	}
+	return s.Value
+}
+
+// Settings returns every setting in declaration order with its source
+func (c *Config) Settings() []Setting {
+	return c.settings
+}
+
+// source is one configuration layer, looked up by environment variable name
+type source struct {
+	name   string
+	lookup func(key string) (string, bool)
+	keys   []string
+}
+
+// loader resolves settings through its sources, highest precedence first,
+// and records where each value came from
+type loader struct {
+	sources  []source
+	settings []Setting
+	// bools marks boolean settings, which become flags without a value
+	bools map[string]bool
+	// err is the first secret file that could not be read
+	err error
+}
+
+// envSource reads environment variables; empty variables count as unset
+func envSource() source {
+	return source{name: sourceEnv, lookup: func(key string) (string, bool) {
+		value := os.Getenv(key)
+		return value, value != ""
+	}}
+}
+
+func (l *loader) lookup(key string) (value, sourceName string, ok bool) {
+	for _, s := range l.sources {
+		value, ok := s.lookup(key)
+		path, fromFile := "", false
+		if secretKeys[key] {
+			path, fromFile = s.lookup(key + fileSuffix)
+			fromFile = fromFile && path != ""
+		}
+
+		switch {
+		case ok && fromFile:
+			l.fail(fmt.Errorf("%s: set either %s or %s%s", s.name, key, key, fileSuffix))
+			return "", "", false
+		case fromFile:
+			value, err := readSecretFile(path)
+			if err != nil {
+				l.fail(fmt.Errorf("%s%s: %w", key, fileSuffix, err))
+				return "", "", false
+			}
+			return value, fmt.Sprintf("%s (%s%s)", s.name, key, fileSuffix), true
+		case ok:
+			return value, s.name, true
+		}
+	}
+	return "", "", false
+}
+
+// fail records the first error found while resolving settings
+func (l *loader) fail(err error) {
+	if l.err == nil {
+		l.err = err
+	}
+}
+
+// readSecretFile reads a secret mounted as a file, e.g. a Docker or
+// Kubernetes secret, without its trailing newline. Files others can write
+// to are rejected, as anyone able to change them could set the secret.
+func readSecretFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", path)
+	}
+	if info.Mode().Perm()&0o022 != 0 {
+		return "", fmt.Errorf("%s must not be writable by group or others (mode %s)", path, info.Mode().Perm())
+	}
+	if info.Size() > maxSecretFileSize {
+		return "", fmt.Errorf("%s is larger than %d bytes", path, maxSecretFileSize)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return value, nil
+}
+
+func (l *loader) record(key, value, sourceName string) {
+	l.settings = append(l.settings, Setting{Key: key, Value: value, Source: sourceName})
+}
+
+// loadFiles adds config.yaml and the profile overlay from CONFIG_DIR
+// (default the working directory) below the sources already added. The
+// profile is ENV, read from those sources or config.yaml (default
+// "development"): "development" loads config.dev.yaml, "production"
+// config.prod.yaml and any other value config.<ENV>.yaml. Missing files are
+// skipped.
+func (l *loader) loadFiles() error {
+	dir, _, _ := l.lookup("CONFIG_DIR")
+	if dir == "" {
+		dir = "."
+	}
+
+	base, err := fileSource(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		return err
+	}
+
+	env, _, _ := l.lookup("ENV")
+	if env == "" {
+		env, _ = base.lookup("ENV")
+	}
+	if env == "" {
+		env = "development"
+	}
+	profile := env
+	if short, ok := profileFiles[env]; ok {
+		profile = short
+	}
+	overlay, err := fileSource(filepath.Join(dir, "config."+filepath.Base(profile)+".yaml"))
+	if err != nil {
+		return err
+	}
+
+	l.sources = append(l.sources, overlay, base)
+	return nil
+}
+
+// fileSource reads a YAML file of settings named like their environment
+// variables in lower case, e.g. "jwt_secret: ...". Values use the same
+// format as the environment variables; lists may also be YAML sequences.
+// A missing file is an empty source.
+func fileSource(path string) (source, error) {
+	s := source{name: filepath.Base(path)}
+	values := make(map[string]string)
+	s.lookup = func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	for name, value := range raw {
+		key := strings.ToUpper(name)
+		switch v := value.(type) {
+		case nil:
+			values[key] = ""
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		case map[string]interface{}:
+			return s, fmt.Errorf("%s: %s must be a string or a list", path, name)
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+		s.keys = append(s.keys, key)
+	}
+	sort.Strings(s.keys)
+	return s, nil
+}
+
+// checkUnknown rejects file settings that no field reads, e.g. typos
+func (l *loader) checkUnknown() error {
+	known := make(map[string]bool, len(l.settings))
+	for _, setting := range l.settings {
+		known[setting.Key] = true
+		if secretKeys[setting.Key] {
+			known[setting.Key+fileSuffix] = true
+		}
+	}
+
+	for _, s := range l.sources {
+		for _, key := range s.keys {
+			if !known[key] {
+				return fmt.Errorf("%s: unknown setting %q", s.name, strings.ToLower(key))
+			}
+		}
+	}
+	return nil
+}
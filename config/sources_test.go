@@ -0,0 +1,204 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("CONFIG_DIR", dir)
+	for _, key := range []string{"ENV", "PORT", "JWT_SECRET", "ADMIN_EMAILS", "DB_PATH"} {
+		t.Setenv(key, "")
+	}
+	return dir
+}
+
+func TestLoad_Files(t *testing.T) {
+	writeConfigFiles(t, map[string]string{
+		"config.yaml": "env: production\nport: 8080\njwt_secret: base-secret\nadmin_emails:\n  - a@example.com\n  - b@example.com\n",
+		// Overlay for ENV=production
+		"config.prod.yaml": "port: 9090\njwt_secret: prod-secret\n",
+		// Not selected
+		"config.dev.yaml": "port: 1111\n",
+	})
+	t.Setenv("PORT", "7070")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Env != "production" || cfg.Port != "7070" || cfg.JWTSecret != "prod-secret" || cfg.DBPath != "users.db" {
+		t.Errorf("Load() = env %q, port %q, secret %q, db %q", cfg.Env, cfg.Port, cfg.JWTSecret, cfg.DBPath)
+	}
+	if want := []string{"a@example.com", "b@example.com"}; !reflect.DeepEqual(cfg.AdminEmails, want) {
+		t.Errorf("AdminEmails = %v, want %v", cfg.AdminEmails, want)
+	}
+
+	sources := make(map[string]string)
+	for _, setting := range cfg.Settings() {
+		sources[setting.Key] = setting.Source
+	}
+	want := map[string]string{
+		"ENV":          "config.yaml",
+		"PORT":         "env",
+		"JWT_SECRET":   "config.prod.yaml",
+		"ADMIN_EMAILS": "config.yaml",
+		"DB_PATH":      "default",
+	}
+	for key, source := range want {
+		if sources[key] != source {
+			t.Errorf("source of %s = %q, want %q", key, sources[key], source)
+		}
+	}
+}
+
+func TestLoad_ProfileFromEnv(t *testing.T) {
+	writeConfigFiles(t, map[string]string{
+		"config.yaml":         "port: 8080\n",
+		"config.dev.yaml":     "port: 3001\n",
+		"config.staging.yaml": "port: 4000\n",
+	})
+
+	tests := []struct {
+		env  string
+		port string
+	}{
+		{"", "3001"},
+		{"development", "3001"},
+		{"staging", "4000"},
+		{"production", "8080"},
+	}
+	for _, tt := range tests {
+		t.Setenv("ENV", tt.env)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Port != tt.port {
+			t.Errorf("ENV=%q: Port = %q, want %q", tt.env, cfg.Port, tt.port)
+		}
+	}
+}
+
+func TestLoad_FileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"unknown setting", "prot: 8080\n", `unknown setting "prot"`},
+		{"invalid yaml", "port: [8080\n", "config.yaml"},
+		{"mapping value", "signing_keys:\n  billing: secret\n", "must be a string or a list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfigFiles(t, map[string]string{"config.yaml": tt.content})
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_SecretFiles(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"jwt_secret":  "file-secret\n",
+		"scim_token":  "file-token",
+		"config.yaml": "scim_token_file: scim_token\n",
+	})
+	t.Setenv("JWT_SECRET_FILE", filepath.Join(dir, "jwt_secret"))
+	// Relative to the working directory, not CONFIG_DIR
+	t.Chdir(dir)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.JWTSecret != "file-secret" {
+		t.Errorf("JWTSecret = %q, want the trimmed file content", cfg.JWTSecret)
+	}
+	if cfg.ScimToken != "file-token" {
+		t.Errorf("ScimToken = %q, want file-token", cfg.ScimToken)
+	}
+
+	sources := make(map[string]string)
+	for _, setting := range cfg.Settings() {
+		sources[setting.Key] = setting.Source
+	}
+	if sources["JWT_SECRET"] != "env (JWT_SECRET_FILE)" || sources["SCIM_TOKEN"] != "config.yaml (SCIM_TOKEN_FILE)" {
+		t.Errorf("sources = %q, %q", sources["JWT_SECRET"], sources["SCIM_TOKEN"])
+	}
+}
+
+func TestLoad_SecretFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		mode    os.FileMode
+		value   string
+		wantErr string
+	}{
+		{"writable by others", "secret", 0o666, "", "must not be writable"},
+		{"empty", "\n", 0o400, "", "is empty"},
+		{"both set", "secret", 0o400, "other", "set either JWT_SECRET or JWT_SECRET_FILE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, nil)
+			path := filepath.Join(dir, "jwt_secret")
+			if err := os.WriteFile(path, []byte(tt.content), tt.mode); err != nil {
+				t.Fatal(err)
+			}
+			// WriteFile's mode is subject to the umask
+			if err := os.Chmod(path, tt.mode); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("JWT_SECRET", tt.value)
+			t.Setenv("JWT_SECRET_FILE", path)
+
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		writeConfigFiles(t, nil)
+		t.Setenv("JWT_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "JWT_SECRET_FILE") {
+			t.Errorf("Load() error = %v, want it to mention JWT_SECRET_FILE", err)
+		}
+	})
+}
+
+func TestSetting_Redacted(t *testing.T) {
+	tests := []struct {
+		setting Setting
+		want    string
+	}{
+		{Setting{Key: "JWT_SECRET", Value: "s3cret"}, "[redacted]"},
+		{Setting{Key: "SIGNING_KEYS", Value: "billing:key"}, "[redacted]"},
+		{Setting{Key: "SCIM_TOKEN", Value: ""}, ""},
+		{Setting{Key: "PORT", Value: "3000"}, "3000"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.setting.Redacted(); got != tt.want {
+			t.Errorf("Redacted() for %s = %q, want %q", tt.setting.Key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrIllegalStatusTransition is returned for an account status change the
+// lifecycle does not allow, such as activating a deleted account
+var ErrIllegalStatusTransition = errors.New("illegal account status transition")
+
+// statusTransitions is the account lifecycle: the statuses an account in
+// each status can move to. Deleted is final.
+//
+//	pending → active → suspended → deactivated → deleted
+//	          active ← suspended, deactivated
+//	any other status → deleted
+var statusTransitions = map[string][]string{
+	StatusPending:     {StatusActive, StatusDeleted},
+	StatusActive:      {StatusSuspended, StatusDeactivated, StatusDeleted},
+	StatusSuspended:   {StatusActive, StatusDeactivated, StatusDeleted},
+	StatusDeactivated: {StatusActive, StatusDeleted},
+	StatusDeleted:     nil,
+}
+
+// statusEvents are the events recorded when an account enters a status
+var statusEvents = map[string]EventType{
+	StatusActive:      EventUserActivated,
+	StatusSuspended:   EventUserSuspended,
+	StatusDeactivated: EventUserDeactivated,
+	StatusDeleted:     EventUserDeleted,
+}
+
+// IsValidStatus reports whether status is an account status
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// CanTransition reports whether an account may move from one status to another
+func CanTransition(from, to string) bool {
+	return slices.Contains(statusTransitions[from], to)
+}
+
+// CanSignIn reports whether the user may sign in and act on their account;
+// only active accounts can
+func (u *User) CanSignIn() bool {
+	return u.Status == StatusActive
+}
+
+// TransitionTo moves the user to status on behalf of actorID and returns
+// the event to record, with the status it came from. Moving to the current
+// status changes nothing and returns no event.
+func (u *User) TransitionTo(status string, actorID int) (*DomainEvent, error) {
+	if status == u.Status {
+		return nil, nil
+	}
+	if !CanTransition(u.Status, status) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrIllegalStatusTransition, u.Status, status)
+	}
+
+	data := map[string]interface{}{"from": u.Status}
+	if status == StatusDeleted {
+		data["email"] = u.Email
+	}
+	u.Status = status
+	return NewDomainEvent(statusEvents[status], EventSubjectUser, u.ID, actorID, data), nil
+}
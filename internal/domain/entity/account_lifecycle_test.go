@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUser_TransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to  string
+		wantEvent EventType
+		wantErr   bool
+	}{
+		{StatusPending, StatusActive, EventUserActivated, false},
+		{StatusActive, StatusSuspended, EventUserSuspended, false},
+		{StatusSuspended, StatusActive, EventUserActivated, false},
+		{StatusSuspended, StatusDeactivated, EventUserDeactivated, false},
+		{StatusActive, StatusDeactivated, EventUserDeactivated, false},
+		{StatusDeactivated, StatusActive, EventUserActivated, false},
+		{StatusPending, StatusDeleted, EventUserDeleted, false},
+		{StatusDeactivated, StatusDeleted, EventUserDeleted, false},
+		{StatusPending, StatusSuspended, "", true},
+		{StatusDeactivated, StatusSuspended, "", true},
+		{StatusActive, StatusPending, "", true},
+		{StatusDeleted, StatusActive, "", true},
+		{StatusActive, "archived", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			user := &User{ID: 7, Email: "user@example.com", Status: tt.from}
+			event, err := user.TransitionTo(tt.to, 1)
+			if tt.wantErr {
+				if !errors.Is(err, ErrIllegalStatusTransition) || event != nil || user.Status != tt.from {
+					t.Errorf("TransitionTo() = %+v, %v; want ErrIllegalStatusTransition and no change", event, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TransitionTo() error = %v", err)
+			}
+			if user.Status != tt.to || event.Type != tt.wantEvent || event.SubjectID != 7 || event.ActorID != 1 || event.Data["from"] != tt.from {
+				t.Errorf("TransitionTo() = %+v, status %s", event, user.Status)
+			}
+		})
+	}
+
+	// Staying in the same status is not a transition
+	user := &User{Status: StatusActive}
+	if event, err := user.TransitionTo(StatusActive, 1); event != nil || err != nil {
+		t.Errorf("TransitionTo() the current status = %+v, %v; want nothing", event, err)
+	}
+}
+
+func TestUser_CanSignIn(t *testing.T) {
+	for status, want := range map[string]bool{
+		StatusPending:     false,
+		StatusActive:      true,
+		StatusSuspended:   false,
+		StatusDeactivated: false,
+	} {
+		if got := (&User{Status: status}).CanSignIn(); got != want {
+			t.Errorf("CanSignIn() of a %s user = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// AccountReport gathers the data kept about a user, for a compliance
+// request or the user's own download
+type AccountReport struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	// User is the profile, without the password hash
+	User *User `json:"user"`
+	// Revisions are the changes made to the profile, newest first
+	Revisions []*UserRevision `json:"revisions"`
+	// Logins are the sign-in attempts on the account, newest first
+	Logins []*LoginEvent `json:"logins"`
+}
@@ -0,0 +1,81 @@
+package entity
+
+import "time"
+
+// AdminActionKind identifies a destructive admin action that is applied after a delay
+type AdminActionKind string
+
+const (
+	// AdminActionDeleteUsers removes the target users
+	AdminActionDeleteUsers AdminActionKind = "delete_users"
+	// AdminActionSuspendUsers sets the target users' status to suspended
+	AdminActionSuspendUsers AdminActionKind = "suspend_users"
+	// AdminActionSetRole sets the target users' role
+	AdminActionSetRole AdminActionKind = "set_role"
+	// AdminActionIncidentReset forces a password reset on the target users
+	// and notifies them, e.g. after a security incident
+	AdminActionIncidentReset AdminActionKind = "incident_reset"
+)
+
+// AdminActionStatus tracks a queued admin action through its lifecycle
+type AdminActionStatus string
+
+const (
+	// AdminActionAwaitingApproval needs a second admin's approval before its
+	// undo window starts
+	AdminActionAwaitingApproval AdminActionStatus = "awaiting_approval"
+	// AdminActionPending is waiting for its execution time and can still be undone
+	AdminActionPending AdminActionStatus = "pending"
+	// AdminActionRunning has been claimed by the job worker
+	AdminActionRunning AdminActionStatus = "running"
+	// AdminActionApplied finished successfully
+	AdminActionApplied AdminActionStatus = "applied"
+	// AdminActionFailed finished with an error
+	AdminActionFailed AdminActionStatus = "failed"
+	// AdminActionCancelled was undone before it ran
+	AdminActionCancelled AdminActionStatus = "cancelled"
+	// AdminActionRejected was turned down by the admin asked to approve it
+	AdminActionRejected AdminActionStatus = "rejected"
+)
+
+// AdminAction is a destructive admin operation queued with an undo window.
+// Subject and Message are the notification templates of an incident reset.
+// Processed counts the target users handled so far, and FailedUserIDs lists
+// the ones a failed action could not be applied to. ReviewedBy is the admin
+// who approved or rejected an action that needed approval; its ExecuteAt is
+// only set once approved.
+type AdminAction struct {
+	ID        int               `json:"id"`
+	Token     string            `json:"token"`
+	Kind      AdminActionKind   `json:"kind"`
+	ActorID   int               `json:"actorId"`
+	UserIDs   []int             `json:"userIds"`
+	Role      string            `json:"role,omitempty"`
+	Subject   string            `json:"subject,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Status    AdminActionStatus `json:"status"`
+	Error     string            `json:"error,omitempty"`
+	Processed int               `json:"processed"`
+	ExecuteAt time.Time         `json:"executeAt"`
+	CreatedAt time.Time         `json:"createdAt"`
+
+	FailedUserIDs []int      `json:"failedUserIds,omitempty"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+	ReviewedBy    int        `json:"reviewedBy,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewedAt,omitempty"`
+}
+
+// AdminActionThroughput counts the actions of a kind that finished within a window
+type AdminActionThroughput struct {
+	Kind    AdminActionKind `json:"kind"`
+	Applied int             `json:"applied"`
+	Failed  int             `json:"failed"`
+}
+
+// UserSegment selects the users who signed in successfully at or after Since
+// and before Until, and from IPRange (a CIDR range or single IP) when set
+type UserSegment struct {
+	Since   time.Time
+	Until   time.Time
+	IPRange string
+}
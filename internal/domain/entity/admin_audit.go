@@ -0,0 +1,107 @@
+package entity
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// AuditAction identifies the kind of admin mutation recorded in an audit entry
+type AuditAction string
+
+const (
+	// AuditUserUpdated is recorded when an admin changes a user's status, role or plan
+	AuditUserUpdated AuditAction = "user.updated"
+	// AuditUserDeleted is recorded when an admin deletes a user
+	AuditUserDeleted AuditAction = "user.deleted"
+	// AuditClientVersionSet is recorded when an admin sets a platform's minimum version
+	AuditClientVersionSet AuditAction = "client_version.set"
+	// AuditClientVersionReset is recorded when an admin removes a platform's minimum version
+	AuditClientVersionReset AuditAction = "client_version.reset"
+	// AuditEntitlementSet is recorded when an admin overrides a user's feature
+	AuditEntitlementSet AuditAction = "entitlement.set"
+	// AuditEntitlementDeleted is recorded when an admin removes a feature override
+	AuditEntitlementDeleted AuditAction = "entitlement.deleted"
+	// AuditFraudCleared is recorded when an admin clears a user flagged for fraud review
+	AuditFraudCleared AuditAction = "fraud.cleared"
+	// AuditDuplicateDismissed is recorded when an admin dismisses a duplicate account pair
+	AuditDuplicateDismissed AuditAction = "duplicate.dismissed"
+	// AuditKYCReviewed is recorded when an admin approves or rejects an identity document
+	AuditKYCReviewed AuditAction = "kyc.reviewed"
+)
+
+// Audit subject types besides the event subjects
+const (
+	AuditSubjectClientVersion = "client_version"
+	AuditSubjectDuplicate     = "duplicate"
+	AuditSubjectKYCSubmission = "kyc_submission"
+)
+
+// secretFieldMarkers are the name fragments of fields whose values are
+// never recorded in an audit entry
+var secretFieldMarkers = []string{"password", "secret", "token", "key", "hash"}
+
+// AuditEntry records an admin mutation: the admin, what they did to which
+// subject and the fields it changed, before and after
+type AuditEntry struct {
+	ID          int           `json:"id"`
+	ActorID     int           `json:"actorId"`
+	Action      AuditAction   `json:"action"`
+	SubjectType string        `json:"subjectType"`
+	SubjectID   string        `json:"subjectId"`
+	Changes     []FieldChange `json:"changes"`
+	CreatedAt   time.Time     `json:"createdAt"`
+}
+
+// NewAuditEntry creates an audit entry for a mutation of a subject by
+// actorID. The values of secret fields are redacted whatever their source.
+func NewAuditEntry(actorID int, action AuditAction, subjectType, subjectID string, changes []FieldChange) *AuditEntry {
+	redacted := make([]FieldChange, len(changes))
+	for i, change := range changes {
+		if SecretField(change.Field) {
+			change.Old, change.New = redactedValue, redactedValue
+		}
+		redacted[i] = change
+	}
+	return &AuditEntry{
+		ActorID:     actorID,
+		Action:      action,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Changes:     redacted,
+	}
+}
+
+// SecretField reports whether the values of a field are secret, e.g. a
+// password hash or an API key
+func SecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range secretFieldMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffFields lists the fields that differ between two states, sorted by
+// name. A field missing from a state is empty in it, so a nil before
+// records a creation and a nil after a removal.
+func DiffFields(before, after map[string]string) []FieldChange {
+	names := make(map[string]bool, len(before)+len(after))
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+
+	var changes []FieldChange
+	for name := range names {
+		if before[name] != after[name] {
+			changes = append(changes, FieldChange{Field: name, Old: before[name], New: after[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
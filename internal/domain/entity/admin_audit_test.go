@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffFields(t *testing.T) {
+	before := map[string]string{"minVersion": "1.0.0", "updateURL": "https://example.com/app", "source": "config"}
+	after := map[string]string{"minVersion": "2.0.0", "source": "admin"}
+
+	want := []FieldChange{
+		{Field: "minVersion", Old: "1.0.0", New: "2.0.0"},
+		{Field: "source", Old: "config", New: "admin"},
+		{Field: "updateURL", Old: "https://example.com/app", New: ""},
+	}
+	if changes := DiffFields(before, after); !reflect.DeepEqual(changes, want) {
+		t.Errorf("DiffFields() = %+v, want %+v", changes, want)
+	}
+	if changes := DiffFields(nil, map[string]string{"granted": "true"}); len(changes) != 1 || changes[0].Old != "" {
+		t.Errorf("DiffFields() from nothing = %+v", changes)
+	}
+	if changes := DiffFields(before, before); len(changes) != 0 {
+		t.Errorf("DiffFields() of identical states = %+v, want none", changes)
+	}
+}
+
+func TestNewAuditEntry_RedactsSecrets(t *testing.T) {
+	entry := NewAuditEntry(1, AuditUserUpdated, EventSubjectUser, "7", []FieldChange{
+		{Field: "role", Old: RoleUser, New: RoleAdmin},
+		{Field: "passwordHash", Old: "old", New: "new"},
+		{Field: "apiKey", Old: "", New: "sk_live_123"},
+		{Field: "webhookSecret", Old: "s1", New: "s2"},
+	})
+
+	want := []FieldChange{
+		{Field: "role", Old: RoleUser, New: RoleAdmin},
+		{Field: "passwordHash", Old: "[redacted]", New: "[redacted]"},
+		{Field: "apiKey", Old: "[redacted]", New: "[redacted]"},
+		{Field: "webhookSecret", Old: "[redacted]", New: "[redacted]"},
+	}
+	if !reflect.DeepEqual(entry.Changes, want) {
+		t.Errorf("Changes = %+v, want %+v", entry.Changes, want)
+	}
+	if entry.ActorID != 1 || entry.SubjectType != EventSubjectUser || entry.SubjectID != "7" {
+		t.Errorf("NewAuditEntry() = %+v", entry)
+	}
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// Backup is a verified copy of the database
+type Backup struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
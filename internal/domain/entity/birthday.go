@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Calendar is the calendar birthdays are entered in. They are always kept
+// and returned as ISO 8601 (Gregorian) dates.
+type Calendar string
+
+// Calendars birthdays can be entered in
+const (
+	CalendarGregorian Calendar = "gregorian"
+	// CalendarBuddhist is the Thai solar calendar, whose years are counted
+	// in the Buddhist era, BuddhistEraOffset years ahead of the common era
+	CalendarBuddhist Calendar = "buddhist"
+)
+
+// BuddhistEraOffset is the number of years the Buddhist era is ahead of the
+// common era: 2543 BE is 2000 CE
+const BuddhistEraOffset = 543
+
+// minBuddhistYear keeps Gregorian years out of Buddhist input: a Buddhist
+// year below it would be a birthday before 1900 CE, which is far more likely
+// a Gregorian year entered by mistake
+const minBuddhistYear = 1900 + BuddhistEraOffset
+
+// ValidCalendar reports whether calendar is a known calendar
+func ValidCalendar(calendar Calendar) bool {
+	return calendar == CalendarGregorian || calendar == CalendarBuddhist
+}
+
+// Birthday is a date of birth
+type Birthday struct {
+	date time.Time
+}
+
+// ParseBirthday parses a YYYY-MM-DD birthday entered in calendar. An empty
+// calendar is Gregorian. In the Buddhist calendar the year is in the
+// Buddhist era, e.g. 2533-01-15 for January 15, 1990.
+func ParseBirthday(value string, calendar Calendar) (Birthday, error) {
+	if calendar != CalendarBuddhist {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return Birthday{}, errors.New("birthday should be YYYY-MM-DD")
+		}
+		return Birthday{date: date}, nil
+	}
+
+	invalid := fmt.Errorf("birthday should be YYYY-MM-DD in the Buddhist era, e.g. %d-01-15", 1990+BuddhistEraOffset)
+	year, rest, ok := strings.Cut(value, "-")
+	if !ok || len(year) != 4 {
+		return Birthday{}, invalid
+	}
+	n, err := strconv.Atoi(year)
+	if err != nil || n < minBuddhistYear {
+		return Birthday{}, invalid
+	}
+	// Leap years fall on the same years in both eras, so the date is checked
+	// once converted
+	date, err := time.Parse("2006-01-02", fmt.Sprintf("%04d-%s", n-BuddhistEraOffset, rest))
+	if err != nil {
+		return Birthday{}, invalid
+	}
+	return Birthday{date: date}, nil
+}
+
+// String returns the birthday as an ISO 8601 date
+func (b Birthday) String() string {
+	return b.date.Format("2006-01-02")
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "testing"
+
+func TestParseBirthday(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		calendar Calendar
+		want     string
+		wantErr  bool
+	}{
+		{name: "gregorian", value: "1990-01-15", calendar: CalendarGregorian, want: "1990-01-15"},
+		{name: "default calendar", value: "1990-01-15", want: "1990-01-15"},
+		{name: "invalid gregorian", value: "15/01/1990", calendar: CalendarGregorian, wantErr: true},
+		{name: "buddhist", value: "2533-01-15", calendar: CalendarBuddhist, want: "1990-01-15"},
+		{name: "buddhist leap day", value: "2543-02-29", calendar: CalendarBuddhist, want: "2000-02-29"},
+		{name: "buddhist common year", value: "2544-02-29", calendar: CalendarBuddhist, wantErr: true},
+		{name: "gregorian year in buddhist", value: "1990-01-15", calendar: CalendarBuddhist, wantErr: true},
+		{name: "invalid buddhist", value: "2533-13-01", calendar: CalendarBuddhist, wantErr: true},
+		{name: "short buddhist year", value: "533-01-15", calendar: CalendarBuddhist, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBirthday(tt.value, tt.calendar)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseBirthday(%q) = %v, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil || got.String() != tt.want {
+				t.Errorf("ParseBirthday(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
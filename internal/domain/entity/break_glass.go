@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// BreakGlassSeal is the sealed emergency credential of a key ceremony. Its
+// secret is split into Shares shares held by custodians, Threshold of which
+// unseal it; only a hash of the secret is stored. A seal unseals once.
+type BreakGlassSeal struct {
+	ID         int        `json:"id"`
+	Shares     int        `json:"shares"`
+	Threshold  int        `json:"threshold"`
+	SecretHash string     `json:"-"`
+	CreatedBy  int        `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UsedAt     *time.Time `json:"usedAt,omitempty"`
+	// RevokedAt is set when a later ceremony replaced the seal
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// BreakGlassSession is admin access granted to a user by unsealing, until
+// ExpiresAt or until an admin closes it
+type BreakGlassSession struct {
+	ID        int        `json:"id"`
+	SealID    int        `json:"sealId"`
+	UserID    int        `json:"userId"`
+	Reason    string     `json:"reason"`
+	OpenedAt  time.Time  `json:"openedAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	ClosedAt  *time.Time `json:"closedAt,omitempty"`
+	// ClosedBy is the admin who closed the session, 0 when it expired
+	ClosedBy int `json:"closedBy,omitempty"`
+}
+
+// Active reports whether the session grants admin access at now
+func (s *BreakGlassSession) Active(now time.Time) bool {
+	return s.ClosedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// Sources of a client version policy
+const (
+	// ClientVersionSourceConfig is a minimum from CLIENT_MIN_VERSIONS
+	ClientVersionSourceConfig = "config"
+	// ClientVersionSourceAdmin is a minimum set by an admin, which
+	// overrides the configured one
+	ClientVersionSourceAdmin = "admin"
+)
+
+// ClientVersionPolicy is the oldest app version still served on a platform,
+// such as ios or android. Older apps are asked to upgrade.
+type ClientVersionPolicy struct {
+	Platform   string `json:"platform"`
+	MinVersion string `json:"minVersion"`
+	// UpdateURL is where users get the current app, e.g. its store page
+	UpdateURL string `json:"updateUrl,omitempty"`
+	// Source is ClientVersionSourceConfig or ClientVersionSourceAdmin; it
+	// is not stored
+	Source string `json:"source"`
+	// UpdatedBy is the admin who last set the policy
+	UpdatedBy int       `json:"updatedBy,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+// DerivedClaims are the claims computed from a user's record that
+// authenticated requests are checked against. Unlike the claims baked into a
+// token at login, they follow role, status and plan changes.
+type DerivedClaims struct {
+	UserID int    `json:"userId"`
+	Role   string `json:"role"`
+	Status string `json:"status"`
+	Plan   string `json:"plan"`
+}
+
+// DeriveClaims computes the derived claims of user
+func DeriveClaims(user *User) *DerivedClaims {
+	return &DerivedClaims{UserID: user.ID, Role: user.Role, Status: user.Status, Plan: user.Plan}
+}
+
+// CanSignIn reports whether the user may use the API
+func (c *DerivedClaims) CanSignIn() bool {
+	return c.Status == StatusActive
+}
+
+// ClaimsInvalidatingEvents are the event types after which a user's derived
+// claims must be recomputed
+var ClaimsInvalidatingEvents = []EventType{
+	EventUserActivated,
+	EventUserSuspended,
+	EventUserDeactivated,
+	EventUserRoleChanged,
+	EventUserPlanChanged,
+	EventUserDeleted,
+}
@@ -0,0 +1,60 @@
+package entity
+
+import "time"
+
+// DigestPeriod is how often admins are sent a digest
+type DigestPeriod string
+
+// Digest periods. Weekly digests are sent on Mondays.
+const (
+	DigestDaily  DigestPeriod = "daily"
+	DigestWeekly DigestPeriod = "weekly"
+)
+
+// Duration returns the length of the period
+func (p DigestPeriod) Duration() time.Duration {
+	if p == DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// IsValid reports whether p is a known period
+func (p DigestPeriod) IsValid() bool {
+	return p == DigestDaily || p == DigestWeekly
+}
+
+// HookFailure records a lifecycle hook that failed after its change was
+// saved, such as an undelivered post-register or password reset webhook
+type HookFailure struct {
+	ID        int       `json:"id"`
+	Point     string    `json:"point"`
+	UserID    int       `json:"userId,omitempty"`
+	Error     string    `json:"error"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// FailedLoginAccount is an account with failed login attempts in a digest
+type FailedLoginAccount struct {
+	UserID   int    `json:"userId"`
+	Email    string `json:"email"`
+	Attempts int    `json:"attempts"`
+}
+
+// Digest summarizes the admin-relevant events at or after Since and before
+// Until. The lists are capped; the counts are not. RepeatedFailures are the
+// accounts with several failed logins, HookFailures the latest failed hook
+// deliveries. PendingDeletions are the queued deletes still in their undo
+// window when the digest was built.
+type Digest struct {
+	Period           DigestPeriod         `json:"period"`
+	Since            time.Time            `json:"since"`
+	Until            time.Time            `json:"until"`
+	Signups          int                  `json:"signups"`
+	NewUsers         []*User              `json:"newUsers"`
+	FailedLogins     int                  `json:"failedLogins"`
+	RepeatedFailures []FailedLoginAccount `json:"repeatedFailures"`
+	FailedHooks      int                  `json:"failedHooks"`
+	HookFailures     []*HookFailure       `json:"hookFailures"`
+	PendingDeletions []*AdminAction       `json:"pendingDeletions"`
+}
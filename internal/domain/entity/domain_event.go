@@ -0,0 +1,110 @@
+package entity
+
+import "time"
+
+// EventType names a kind of domain event, as "<subject>.<what happened>"
+type EventType string
+
+// Domain event types. The data each one carries is described next to it and
+// versioned in EventSchemaVersions.
+const (
+	// EventUserRegistered: email
+	EventUserRegistered EventType = "user.registered"
+	// EventUserLoggedIn: method, "password" or "passwordless"
+	EventUserLoggedIn EventType = "user.logged_in"
+	// EventUserPasswordChanged: no data
+	EventUserPasswordChanged EventType = "user.password_changed"
+	// EventUserUpdated: fields, the names of the changed profile fields
+	EventUserUpdated EventType = "user.updated"
+	// EventUserActivated: from, the previous status
+	EventUserActivated EventType = "user.activated"
+	// EventUserSuspended: from, the previous status
+	EventUserSuspended EventType = "user.suspended"
+	// EventUserDeactivated: from, the previous status
+	EventUserDeactivated EventType = "user.deactivated"
+	// EventUserRoleChanged: role, the new role
+	EventUserRoleChanged EventType = "user.role_changed"
+	// EventUserPlanChanged: plan, the new plan, from, the previous one, and
+	// source, "admin" or the billing provider
+	EventUserPlanChanged EventType = "user.plan_changed"
+	// EventUserDeleted: email and from, the previous status
+	EventUserDeleted EventType = "user.deleted"
+	// EventUserEmailBounced: provider, type ("bounce", "dropped" or
+	// "spamreport") and reason, the provider's explanation
+	EventUserEmailBounced EventType = "user.email_bounced"
+	// EventUserBirthday: date, the local date of the birthday, timezone and
+	// age
+	EventUserBirthday EventType = "user.birthday"
+	// EventShareLinkCreated: fields, maxViews and expiresAt
+	EventShareLinkCreated EventType = "share_link.created"
+	// EventShareLinkRevoked: no data
+	EventShareLinkRevoked EventType = "share_link.revoked"
+	// EventHookDeadLettered: point, hook, userId, attempts and error, the
+	// last attempt's error
+	EventHookDeadLettered EventType = "hook.dead_lettered"
+	// EventBreakGlassSealed: shares and threshold. Break-glass events are
+	// about a seal, which unseals once.
+	EventBreakGlassSealed EventType = "break_glass.sealed"
+	// EventBreakGlassOpened: userId, reason and expiresAt
+	EventBreakGlassOpened EventType = "break_glass.opened"
+	// EventBreakGlassClosed: userId and reason, "closed" or "expired"
+	EventBreakGlassClosed EventType = "break_glass.closed"
+)
+
+// EventSchemaVersions is the current version of each event type's data.
+// Bump a type's version when its data changes incompatibly; stored events
+// keep the version they were written with.
+var EventSchemaVersions = map[EventType]int{
+	EventUserRegistered:      1,
+	EventUserLoggedIn:        1,
+	EventUserPasswordChanged: 1,
+	EventUserUpdated:         1,
+	EventUserActivated:       1,
+	EventUserSuspended:       1,
+	EventUserDeactivated:     1,
+	EventUserRoleChanged:     1,
+	EventUserPlanChanged:     1,
+	EventUserDeleted:         1,
+	EventUserEmailBounced:    1,
+	EventUserBirthday:        1,
+	EventShareLinkCreated:    1,
+	EventShareLinkRevoked:    1,
+	EventHookDeadLettered:    1,
+	EventBreakGlassSealed:    1,
+	EventBreakGlassOpened:    1,
+	EventBreakGlassClosed:    1,
+}
+
+// Subjects of domain events
+const (
+	EventSubjectUser         = "user"
+	EventSubjectShareLink    = "share_link"
+	EventSubjectHookDelivery = "hook_delivery"
+	EventSubjectBreakGlass   = "break_glass"
+)
+
+// DomainEvent is something that happened to a subject, such as a user,
+// kept in the append-only event log
+type DomainEvent struct {
+	ID            int64     `json:"id"`
+	Type          EventType `json:"type"`
+	SchemaVersion int       `json:"schemaVersion"`
+	SubjectType   string    `json:"subjectType"`
+	SubjectID     int       `json:"subjectId"`
+	// ActorID is the user who caused the event, 0 for the system
+	ActorID    int                    `json:"actorId,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	OccurredAt time.Time              `json:"occurredAt"`
+}
+
+// NewDomainEvent creates an event of the given type at its current schema version
+func NewDomainEvent(eventType EventType, subjectType string, subjectID, actorID int, data map[string]interface{}) *DomainEvent {
+	return &DomainEvent{
+		Type:          eventType,
+		SchemaVersion: EventSchemaVersions[eventType],
+		SubjectType:   subjectType,
+		SubjectID:     subjectID,
+		ActorID:       actorID,
+		Data:          data,
+	}
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// Signals that two accounts probably belong to the same person
+const (
+	// DuplicateSignalPhone is the same phone number, in any format
+	DuplicateSignalPhone = "phone"
+	// DuplicateSignalEmail is email addresses delivering to the same mailbox
+	DuplicateSignalEmail = "email"
+	// DuplicateSignalDevice is logins from the same device and IP
+	DuplicateSignalDevice = "device"
+)
+
+// Statuses of a duplicate candidate
+const (
+	DuplicatePending   = "pending"
+	DuplicateDismissed = "dismissed"
+)
+
+// DuplicateCandidate is a pair of accounts that probably belong to the same
+// person, for an admin to review. UserID is the lower of the two IDs.
+type DuplicateCandidate struct {
+	ID          int      `json:"id"`
+	UserID      int      `json:"userId"`
+	OtherUserID int      `json:"otherUserId"`
+	Signals     []string `json:"signals"`
+	Status      string   `json:"status"`
+	// DetectedAt is when the pair was first detected
+	DetectedAt time.Time  `json:"detectedAt"`
+	ReviewedBy int        `json:"reviewedBy,omitempty"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+	// User and OtherUser are loaded for review and not stored; nil for an
+	// account deleted since the pair was detected
+	User      *User `json:"-"`
+	OtherUser *User `json:"-"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// EntitlementOverride grants a feature to one user, or takes it away,
+// whatever their plan and the feature flags say
+type EntitlementOverride struct {
+	UserID  int    `json:"userId"`
+	Feature string `json:"feature"`
+	Granted bool   `json:"granted"`
+	// ActorID is the admin who set the override
+	ActorID   int       `json:"actorId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// ExportFile is one compressed NDJSON file of a data export
+type ExportFile struct {
+	Name    string `json:"name"`
+	Key     string `json:"key"`
+	Records int    `json:"records"`
+	Bytes   int    `json:"bytes"`
+	SHA256  string `json:"sha256"`
+}
+
+// ExportManifest describes a complete data export. It is written after the
+// files it lists, so a manifest means the export finished.
+type ExportManifest struct {
+	Date      string       `json:"date"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Files     []ExportFile `json:"files"`
+}
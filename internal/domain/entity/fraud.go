@@ -0,0 +1,63 @@
+package entity
+
+import "time"
+
+// Statuses of a fraud score
+const (
+	// FraudClear accounts have not reached the flag threshold
+	FraudClear = "clear"
+	// FraudFlagged accounts wait for review and may not take sensitive actions
+	FraudFlagged = "flagged"
+	// FraudCleared accounts were flagged and cleared by an admin
+	FraudCleared = "cleared"
+)
+
+// FraudScore is the latest fraud score of a user, from 0 to 100, with the
+// signals that raised it
+type FraudScore struct {
+	UserID    int        `json:"userId"`
+	Score     int        `json:"score"`
+	Signals   []string   `json:"signals"`
+	Status    string     `json:"status"`
+	ScoredAt  time.Time  `json:"scoredAt"`
+	FlaggedAt *time.Time `json:"flaggedAt,omitempty"`
+	ClearedBy int        `json:"clearedBy,omitempty"`
+	ClearedAt *time.Time `json:"clearedAt,omitempty"`
+	// ClearedScore is the score the account was cleared at
+	ClearedScore int `json:"clearedScore,omitempty"`
+	// User is loaded for review and not stored; nil for a deleted account
+	User *User `json:"-"`
+}
+
+// Restricted reports whether the account may not take sensitive actions
+func (s *FraudScore) Restricted() bool {
+	return s.Status == FraudFlagged
+}
+
+// Apply records a new score, flagging the account when it reaches
+// threshold. A flagged account stays flagged until cleared, and a cleared
+// one is only flagged again by a score above the one it was cleared at. It
+// reports whether the account was flagged by this score.
+func (s *FraudScore) Apply(score int, signals []string, threshold int, now time.Time) bool {
+	s.Score = score
+	s.Signals = signals
+	s.ScoredAt = now
+	if s.Status == "" {
+		s.Status = FraudClear
+	}
+
+	if score < threshold || s.Status == FraudFlagged || s.Status == FraudCleared && score <= s.ClearedScore {
+		return false
+	}
+	s.Status = FraudFlagged
+	s.FlaggedAt = &now
+	return true
+}
+
+// Clear lifts the flag of an account after review
+func (s *FraudScore) Clear(reviewerID int, now time.Time) {
+	s.Status = FraudCleared
+	s.ClearedBy = reviewerID
+	s.ClearedAt = &now
+	s.ClearedScore = s.Score
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFraudScore_Apply(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	score := &FraudScore{UserID: 42}
+
+	if score.Apply(30, []string{"velocity"}, 50, now) || score.Status != FraudClear || score.Restricted() {
+		t.Fatalf("score below the threshold = %+v, want clear", score)
+	}
+	if !score.Apply(55, []string{"disposable_email", "geo"}, 50, now) || !score.Restricted() || score.FlaggedAt == nil {
+		t.Fatalf("score at the threshold = %+v, want flagged", score)
+	}
+	// Flagged accounts stay flagged when the score drops
+	if score.Apply(0, []string{}, 50, now) || !score.Restricted() {
+		t.Fatalf("score after the signals passed = %+v, want flagged", score)
+	}
+
+	score.Apply(55, []string{"disposable_email", "geo"}, 50, now)
+	score.Clear(1, now)
+	if score.Restricted() || score.ClearedBy != 1 || score.ClearedScore != 55 {
+		t.Fatalf("cleared score = %+v", score)
+	}
+	if score.Apply(55, []string{"disposable_email", "geo"}, 50, now) || score.Status != FraudCleared {
+		t.Errorf("same score after clearing = %+v, want cleared", score)
+	}
+	if !score.Apply(85, []string{"disposable_email", "geo", "velocity"}, 50, now) || !score.Restricted() {
+		t.Errorf("higher score after clearing = %+v, want flagged", score)
+	}
+}
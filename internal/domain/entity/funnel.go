@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// FunnelStage identifies a step in the registration funnel
+type FunnelStage string
+
+const (
+	// FunnelStageStarted is recorded when a registration request is received
+	FunnelStageStarted FunnelStage = "started"
+	// FunnelStageValidated is recorded when a registration request passes validation
+	FunnelStageValidated FunnelStage = "validated"
+	// FunnelStageCreated is recorded when the user account is persisted
+	FunnelStageCreated FunnelStage = "created"
+	// FunnelStageFirstLogin is recorded on the user's first successful login
+	FunnelStageFirstLogin FunnelStage = "first_login"
+)
+
+// FunnelStages lists all funnel stages in order
+var FunnelStages = []FunnelStage{
+	FunnelStageStarted,
+	FunnelStageValidated,
+	FunnelStageCreated,
+	FunnelStageFirstLogin,
+}
+
+// FunnelEvent represents a single funnel stage occurrence
+type FunnelEvent struct {
+	ID         int         `json:"id"`
+	Stage      FunnelStage `json:"stage"`
+	UserID     int         `json:"userId,omitempty"`
+	OccurredAt time.Time   `json:"occurredAt"`
+}
+
+// NewFunnelEvent creates a new funnel event for the given stage
+func NewFunnelEvent(stage FunnelStage, userID int) *FunnelEvent {
+	return &FunnelEvent{
+		Stage:      stage,
+		UserID:     userID,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// Day returns the UTC calendar day of the event in YYYY-MM-DD format
+func (e *FunnelEvent) Day() string {
+	return e.OccurredAt.UTC().Format("2006-01-02")
+}
+
+// FunnelDay holds stage counts for a single day
+type FunnelDay struct {
+	Date   string              `json:"date"`
+	Counts map[FunnelStage]int `json:"counts"`
+}
@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// HookDeliveryStatus tracks a failed hook delivery through its retries
+type HookDeliveryStatus string
+
+const (
+	// HookDeliveryRetrying is waiting for its next attempt
+	HookDeliveryRetrying HookDeliveryStatus = "retrying"
+	// HookDeliveryRunning has been claimed by the retry worker
+	HookDeliveryRunning HookDeliveryStatus = "running"
+	// HookDeliveryDelivered succeeded on a retry or replay
+	HookDeliveryDelivered HookDeliveryStatus = "delivered"
+	// HookDeliveryDead exhausted its attempts and waits in the dead-letter
+	// store to be replayed
+	HookDeliveryDead HookDeliveryStatus = "dead"
+)
+
+// HookAttempt is a failed attempt at delivering a hook event
+type HookAttempt struct {
+	Error string    `json:"error"`
+	At    time.Time `json:"at"`
+}
+
+// HookDelivery is a lifecycle hook event that failed after its change was
+// saved, such as a password reset email or a post-register webhook, kept
+// with its payload so it can be retried. EventID is the hook event's ID,
+// resent on every retry. Hook is the position of the failed hook among the
+// hooks at Point; Errors holds every failed attempt, oldest first.
+type HookDelivery struct {
+	ID            int                    `json:"id"`
+	EventID       string                 `json:"eventId"`
+	Point         string                 `json:"point"`
+	Hook          int                    `json:"hook"`
+	UserID        int                    `json:"userId,omitempty"`
+	Email         string                 `json:"email"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+	Status        HookDeliveryStatus     `json:"status"`
+	Attempts      int                    `json:"attempts"`
+	Errors        []HookAttempt          `json:"errors"`
+	NextAttemptAt time.Time              `json:"nextAttemptAt"`
+	CreatedAt     time.Time              `json:"createdAt"`
+	UpdatedAt     time.Time              `json:"updatedAt"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// InboundEventStatus tracks a received webhook event through its handlers
+type InboundEventStatus string
+
+const (
+	// InboundEventProcessing is being handled
+	InboundEventProcessing InboundEventStatus = "processing"
+	// InboundEventProcessed was handled; the provider's retries are ignored
+	InboundEventProcessed InboundEventStatus = "processed"
+	// InboundEventFailed failed in a handler and is handled again when the
+	// provider retries it
+	InboundEventFailed InboundEventStatus = "failed"
+)
+
+// InboundEvent is an event received from an integrated provider's webhook,
+// kept by its provider and event ID so retries and replays run only once
+type InboundEvent struct {
+	ID          int                `json:"id"`
+	Provider    string             `json:"provider"`
+	EventID     string             `json:"eventId"`
+	Type        string             `json:"type"`
+	Subject     string             `json:"subject,omitempty"`
+	Status      InboundEventStatus `json:"status"`
+	Error       string             `json:"error,omitempty"`
+	ReceivedAt  time.Time          `json:"receivedAt"`
+	ProcessedAt *time.Time         `json:"processedAt,omitempty"`
+}
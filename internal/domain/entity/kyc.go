@@ -0,0 +1,89 @@
+package entity
+
+import "time"
+
+// Statuses of an identity verification, on the submission and on its user
+const (
+	// KYCPending submissions wait for the verification provider
+	KYCPending = "pending"
+	// KYCReview submissions wait for an admin, when there is no provider or
+	// it could not decide
+	KYCReview = "review"
+	// KYCVerified users proved their identity
+	KYCVerified = "verified"
+	// KYCRejected submissions were refused; the user may submit another
+	KYCRejected = "rejected"
+)
+
+// Identity documents users can submit
+const (
+	KYCDocumentNationalID     = "national_id"
+	KYCDocumentPassport       = "passport"
+	KYCDocumentDrivingLicense = "driving_license"
+)
+
+// ValidKYCDocumentType reports whether documentType is a known identity document
+func ValidKYCDocumentType(documentType string) bool {
+	switch documentType {
+	case KYCDocumentNationalID, KYCDocumentPassport, KYCDocumentDrivingLicense:
+		return true
+	}
+	return false
+}
+
+// KYCSubmission is an identity document a user submitted for verification.
+// The document itself is kept in the blob store under DocumentKey.
+type KYCSubmission struct {
+	ID           int    `json:"id"`
+	UserID       int    `json:"userId"`
+	DocumentType string `json:"documentType"`
+	ContentType  string `json:"contentType"`
+	DocumentKey  string `json:"-"`
+	Size         int    `json:"size"`
+	Status       string `json:"status"`
+	// Reference is the provider's ID for the check, if any
+	Reference string `json:"reference,omitempty"`
+	// Reason explains a rejection or why the provider asked for review
+	Reason string `json:"reason,omitempty"`
+	// ReviewedBy is the admin who decided; 0 when the provider did
+	ReviewedBy int        `json:"reviewedBy,omitempty"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	// User is loaded for review and not stored; nil for a deleted account
+	User *User `json:"-"`
+}
+
+// Open reports whether the submission still waits for a decision
+func (s *KYCSubmission) Open() bool {
+	return s.Status == KYCPending || s.Status == KYCReview
+}
+
+// Decide records a decision on the submission by reviewerID, or by the
+// provider when reviewerID is 0. A review decision passes the submission on
+// to admins and leaves it open.
+func (s *KYCSubmission) Decide(decision KYCDecision, reviewerID int, now time.Time) {
+	s.Status = decision.Status
+	s.Reason = decision.Reason
+	if decision.Reference != "" {
+		s.Reference = decision.Reference
+	}
+	if s.Open() {
+		return
+	}
+	s.ReviewedBy = reviewerID
+	s.ReviewedAt = &now
+}
+
+// KYCDecision is the outcome of verifying a submission: verified, rejected,
+// or review when a provider cannot decide
+type KYCDecision struct {
+	Status    string `json:"status"`
+	Reference string `json:"reference,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Valid reports whether the decision has a known status
+func (d KYCDecision) Valid() bool {
+	return d.Status == KYCVerified || d.Status == KYCRejected || d.Status == KYCReview
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKYCSubmission_Decide(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	submission := &KYCSubmission{UserID: 42, Status: KYCPending}
+
+	submission.Decide(KYCDecision{Status: KYCReview, Reference: "chk_1", Reason: "blurry photo"}, 0, now)
+	if !submission.Open() || submission.ReviewedAt != nil || submission.Reference != "chk_1" {
+		t.Fatalf("after a review decision = %+v, want it open", submission)
+	}
+
+	submission.Decide(KYCDecision{Status: KYCVerified}, 7, now.Add(time.Hour))
+	if submission.Open() || submission.ReviewedBy != 7 || submission.ReviewedAt == nil || !submission.ReviewedAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("after approval = %+v", submission)
+	}
+	if submission.Reference != "chk_1" || submission.Reason != "" {
+		t.Errorf("approval should keep the reference and clear the reason, got %+v", submission)
+	}
+}
+
+func TestKYCDecision_Valid(t *testing.T) {
+	for status, want := range map[string]bool{KYCVerified: true, KYCRejected: true, KYCReview: true, KYCPending: false, "": false} {
+		if got := (KYCDecision{Status: status}).Valid(); got != want {
+			t.Errorf("Valid() for %q = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// LoginEvent records a sign-in attempt on a user's account. Device is the
+// client's User-Agent; Country is set when a trusted proxy geolocates
+// requests. NewDevice and NewCountry mark successful logins from a device or
+// country the user had not signed in from before.
+type LoginEvent struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"userId"`
+	Success    bool      `json:"success"`
+	IP         string    `json:"ip"`
+	Device     string    `json:"device"`
+	Country    string    `json:"country,omitempty"`
+	NewDevice  bool      `json:"newDevice"`
+	NewCountry bool      `json:"newCountry"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// SecurityReport summarizes the sign-in activity on a user's account
+type SecurityReport struct {
+	Since            time.Time     `json:"since"`
+	Until            time.Time     `json:"until"`
+	SuccessfulLogins int           `json:"successfulLogins"`
+	FailedAttempts   int           `json:"failedAttempts"`
+	Countries        []string      `json:"countries"`
+	RecentLogins     []*LoginEvent `json:"recentLogins"`
+	NewDevices       []*LoginEvent `json:"newDevices"`
+	RecentFailures   []*LoginEvent `json:"recentFailures"`
+	// GeoAnomalies are successful logins from a country the user had not
+	// signed in from before
+	GeoAnomalies []*LoginEvent `json:"geoAnomalies"`
+}
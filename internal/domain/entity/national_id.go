@@ -0,0 +1,30 @@
+package entity
+
+import "strings"
+
+// NationalIDLength is the number of digits in a Thai national ID
+const NationalIDLength = 13
+
+// NormalizeNationalID removes the dashes and spaces a Thai national ID is
+// often written with, e.g. 1-2345-67890-12-1
+func NormalizeNationalID(id string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(id)
+}
+
+// ValidThaiNationalID reports whether id is 13 digits whose last digit is
+// the check digit of the first 12
+func ValidThaiNationalID(id string) bool {
+	if len(id) != NationalIDLength {
+		return false
+	}
+	sum := 0
+	for i := 0; i < NationalIDLength; i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return false
+		}
+		if i < NationalIDLength-1 {
+			sum += int(id[i]-'0') * (NationalIDLength - i)
+		}
+	}
+	return int(id[NationalIDLength-1]-'0') == (11-sum%11)%10
+}
@@ -0,0 +1,33 @@
+package entity
+
+import "testing"
+
+func TestValidThaiNationalID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"1101700230708", true},
+		{"3456789012347", true},
+		{"1101700230707", false},
+		{"110170023070", false},
+		{"11017002307080", false},
+		{"110170023070x", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidThaiNationalID(tt.id); got != tt.want {
+			t.Errorf("ValidThaiNationalID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeNationalID(t *testing.T) {
+	if got := NormalizeNationalID("1-1017-00230-70-8"); got != "1101700230708" {
+		t.Errorf("NormalizeNationalID() = %q, want 1101700230708", got)
+	}
+	if got := NormalizeNationalID("1 1017 00230 70 8"); got != "1101700230708" {
+		t.Errorf("NormalizeNationalID() = %q, want 1101700230708", got)
+	}
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// TokenPurpose names the flow a single-use token belongs to. A token only
+// works for the purpose it was issued for.
+type TokenPurpose string
+
+const (
+	// TokenPurposePasswordReset lets a user set a new password without the old one
+	TokenPurposePasswordReset TokenPurpose = "password_reset"
+	// TokenPurposeEmailVerification confirms the user owns their email address
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	// TokenPurposeMagicLink signs a user in without a password
+	TokenPurposeMagicLink TokenPurpose = "magic_link"
+	// TokenPurposeInvite lets an invited user create their account
+	TokenPurposeInvite TokenPurpose = "invite"
+	// TokenPurposeAccountReport downloads a report of a user's account data
+	TokenPurposeAccountReport TokenPurpose = "account_report"
+)
+
+// OneTimeToken is a single-use token sent to a user. Only a SHA-256 hash of
+// the token is stored, so the stored tokens cannot be replayed.
+type OneTimeToken struct {
+	ID         int          `json:"id"`
+	Purpose    TokenPurpose `json:"purpose"`
+	UserID     int          `json:"userId"`
+	TokenHash  string       `json:"-"`
+	ExpiresAt  time.Time    `json:"expiresAt"`
+	ConsumedAt *time.Time   `json:"consumedAt,omitempty"`
+	CreatedAt  time.Time    `json:"createdAt"`
+}
@@ -0,0 +1,80 @@
+package entity
+
+import "time"
+
+// PresenceStatus is how recently a user was active
+type PresenceStatus string
+
+const (
+	// PresenceOnline was active within the online window
+	PresenceOnline PresenceStatus = "online"
+	// PresenceRecentlyActive was active within the recent window
+	PresenceRecentlyActive PresenceStatus = "recently_active"
+	// PresenceAway was not active recently, or hides their presence
+	PresenceAway PresenceStatus = "away"
+)
+
+// PresenceVisibility is who may see a user's presence, chosen by the user
+type PresenceVisibility string
+
+const (
+	// PresenceVisibleEveryone shows the status and last seen time
+	PresenceVisibleEveryone PresenceVisibility = "everyone"
+	// PresenceVisibleStatus shows the status but not the last seen time
+	PresenceVisibleStatus PresenceVisibility = "status"
+	// PresenceVisibleNobody shows the user as away to everyone else
+	PresenceVisibleNobody PresenceVisibility = "nobody"
+)
+
+// PresenceVisibilities are the visibilities users may choose
+var PresenceVisibilities = []PresenceVisibility{PresenceVisibleEveryone, PresenceVisibleStatus, PresenceVisibleNobody}
+
+// Presence is when a user was last seen and who may know it. Users never
+// seen have a zero LastSeenAt.
+type Presence struct {
+	UserID     int                `json:"userId"`
+	LastSeenAt time.Time          `json:"lastSeenAt"`
+	Visibility PresenceVisibility `json:"visibility"`
+}
+
+// StatusAt returns the user's status at now: online when they were seen
+// within online, recently active within recent, and away otherwise
+func (p *Presence) StatusAt(now time.Time, online, recent time.Duration) PresenceStatus {
+	switch {
+	case p.LastSeenAt.IsZero():
+		return PresenceAway
+	case now.Sub(p.LastSeenAt) < online:
+		return PresenceOnline
+	case now.Sub(p.LastSeenAt) < recent:
+		return PresenceRecentlyActive
+	}
+	return PresenceAway
+}
+
+// PresenceView is a user's presence as one viewer may see it
+type PresenceView struct {
+	UserID int            `json:"userId"`
+	Status PresenceStatus `json:"status"`
+	// LastSeenAt is nil when the user was never seen or hides it
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	// Visibility is only shown to the user themselves
+	Visibility PresenceVisibility `json:"visibility,omitempty"`
+}
+
+// ViewBy returns the presence as viewerID may see it at now. Users always
+// see their own; others see what the user's visibility allows.
+func (p *Presence) ViewBy(viewerID int, now time.Time, online, recent time.Duration) *PresenceView {
+	view := &PresenceView{UserID: p.UserID, Status: PresenceAway}
+	self := viewerID == p.UserID
+	if self {
+		view.Visibility = p.Visibility
+	} else if p.Visibility == PresenceVisibleNobody {
+		return view
+	}
+	view.Status = p.StatusAt(now, online, recent)
+	if !p.LastSeenAt.IsZero() && (self || p.Visibility != PresenceVisibleStatus) {
+		lastSeenAt := p.LastSeenAt
+		view.LastSeenAt = &lastSeenAt
+	}
+	return view
+}
@@ -0,0 +1,48 @@
+package entity
+
+import "time"
+
+// QRLoginStatus is the state of a QR login
+type QRLoginStatus string
+
+const (
+	// QRLoginPending waits for a signed-in device to scan the QR code
+	QRLoginPending QRLoginStatus = "pending"
+	// QRLoginApproved was approved; the desktop's next poll gets a token
+	QRLoginApproved QRLoginStatus = "approved"
+	// QRLoginDenied was rejected on the signed-in device
+	QRLoginDenied QRLoginStatus = "denied"
+	// QRLoginCompleted handed its token to the desktop
+	QRLoginCompleted QRLoginStatus = "completed"
+	// QRLoginExpired was neither approved nor picked up in time. It is
+	// reported, not stored.
+	QRLoginExpired QRLoginStatus = "expired"
+)
+
+// QRLogin is a sign-in started on a desktop that a signed-in device approves
+// by scanning its QR code. The QR code carries the challenge; the desktop
+// polls with a separate token, so scanning the code is not enough to get the
+// session. Only SHA-256 hashes of both are stored.
+type QRLogin struct {
+	ID            int           `json:"id"`
+	ChallengeHash string        `json:"-"`
+	PollHash      string        `json:"-"`
+	Status        QRLoginStatus `json:"status"`
+	// UserID is the user who approved or denied the login
+	UserID int `json:"userId,omitempty"`
+	// IP, Device and Country describe the desktop, for the approving user
+	IP        string    `json:"ip"`
+	Device    string    `json:"device"`
+	Country   string    `json:"country,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// StatusAt returns the login's status at now, QRLoginExpired for pending
+// and approved logins past their expiry
+func (l *QRLogin) StatusAt(now time.Time) QRLoginStatus {
+	if (l.Status == QRLoginPending || l.Status == QRLoginApproved) && !now.Before(l.ExpiresAt) {
+		return QRLoginExpired
+	}
+	return l.Status
+}
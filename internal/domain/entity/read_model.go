@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// Projections, the read models kept up to date from the domain event log
+const (
+	ProjectionUserSearch = "user_search"
+	ProjectionLoginStats = "login_stats"
+)
+
+// Projections lists every projection
+var Projections = []string{ProjectionUserSearch, ProjectionLoginStats}
+
+// ProjectionStatus is how far a projection has read the domain event log
+type ProjectionStatus struct {
+	Name string `json:"name"`
+	// Position is the ID of the last event applied, 0 before the first
+	Position int64 `json:"position"`
+	// Behind is the number of events logged after Position
+	Behind int64 `json:"behind"`
+	// UpdatedAt is when the projection last moved; zero before it first runs
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// UserSearchEntry is a user's row in the user_search read model
+type UserSearchEntry struct {
+	UserID    int       `json:"userId"`
+	Email     string    `json:"email"`
+	FullName  string    `json:"fullName"`
+	Role      string    `json:"role"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// LoginStats is a user's row in the login_stats read model
+type LoginStats struct {
+	UserID             int       `json:"userId"`
+	Logins             int       `json:"logins"`
+	PasswordLogins     int       `json:"passwordLogins"`
+	PasswordlessLogins int       `json:"passwordlessLogins"`
+	FirstLoginAt       time.Time `json:"firstLoginAt"`
+	LastLoginAt        time.Time `json:"lastLoginAt"`
+}
+
+// UserSummary is a user found in the read models
+type UserSummary struct {
+	UserSearchEntry
+	// LoginStats is nil for users with no login in the event log
+	LoginStats *LoginStats `json:"loginStats,omitempty"`
+}
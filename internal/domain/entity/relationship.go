@@ -0,0 +1,49 @@
+package entity
+
+import "strconv"
+
+// Object types and relations this service writes to the relationship-based
+// authorization service. Products add their own types and relations to the
+// authorization model and refer to users as UserObject(id).
+const (
+	ObjectTypeUser = "user"
+	ObjectTypeRole = "role"
+	// RelationAssignee relates a role to the users holding it
+	RelationAssignee = "assignee"
+)
+
+// Relationship is a relationship tuple: Subject has Relation to Object.
+// Subjects and objects are written "<type>:<id>", e.g. "user:42", or
+// "<type>:<id>#<relation>" for usersets such as "role:admin#assignee".
+type Relationship struct {
+	Subject  string `json:"subject"`
+	Relation string `json:"relation"`
+	Object   string `json:"object"`
+}
+
+// UserObject returns the authorization object for the user with id
+func UserObject(id int) string {
+	return ObjectTypeUser + ":" + strconv.Itoa(id)
+}
+
+// RoleObject returns the authorization object for role
+func RoleObject(role string) string {
+	return ObjectTypeRole + ":" + role
+}
+
+// RoleRelationships returns the tuples to write and delete so the
+// authorization service matches user: an active user is an assignee of
+// their role and of no other; users who cannot sign in, such as suspended
+// ones, are assignees of none.
+// Writing both keeps role changes correct without reading the old role.
+func RoleRelationships(user *User) (writes, deletes []Relationship) {
+	for _, role := range []string{RoleUser, RoleAdmin} {
+		tuple := Relationship{Subject: UserObject(user.ID), Relation: RelationAssignee, Object: RoleObject(role)}
+		if role == user.Role && user.CanSignIn() {
+			writes = append(writes, tuple)
+		} else {
+			deletes = append(deletes, tuple)
+		}
+	}
+	return writes, deletes
+}
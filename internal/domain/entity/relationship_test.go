@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoleRelationships(t *testing.T) {
+	assignee := func(role string) Relationship {
+		return Relationship{Subject: "user:7", Relation: RelationAssignee, Object: "role:" + role}
+	}
+
+	tests := []struct {
+		name        string
+		user        *User
+		wantWrites  []Relationship
+		wantDeletes []Relationship
+	}{
+		{
+			"user",
+			&User{ID: 7, Role: RoleUser, Status: StatusActive},
+			[]Relationship{assignee(RoleUser)},
+			[]Relationship{assignee(RoleAdmin)},
+		},
+		{
+			"admin",
+			&User{ID: 7, Role: RoleAdmin, Status: StatusActive},
+			[]Relationship{assignee(RoleAdmin)},
+			[]Relationship{assignee(RoleUser)},
+		},
+		{
+			"suspended",
+			&User{ID: 7, Role: RoleAdmin, Status: StatusSuspended},
+			nil,
+			[]Relationship{assignee(RoleUser), assignee(RoleAdmin)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writes, deletes := RoleRelationships(tt.user)
+			if !reflect.DeepEqual(writes, tt.wantWrites) || !reflect.DeepEqual(deletes, tt.wantDeletes) {
+				t.Errorf("RoleRelationships() = %v, %v; want %v, %v", writes, deletes, tt.wantWrites, tt.wantDeletes)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// Phases of an online schema change. Writes go to both the old and the new
+// column or table in every phase, so reads can be switched back until the
+// old one is dropped.
+const (
+	// SchemaChangeBackfilling copies the existing rows in batches
+	SchemaChangeBackfilling = "backfilling"
+	// SchemaChangeBackfilled has copied every row; reads use the old schema
+	SchemaChangeBackfilled = "backfilled"
+	// SchemaChangeReadingNew reads from the new schema
+	SchemaChangeReadingNew = "reading_new"
+)
+
+// SchemaChange is the progress of an online change to a large table
+type SchemaChange struct {
+	Name  string `json:"name"`
+	Phase string `json:"phase"`
+	// Cursor is the ID of the last row backfilled, 0 before the first batch
+	Cursor int64 `json:"cursor"`
+	// Backfilled counts the rows copied so far
+	Backfilled int64 `json:"backfilled"`
+	// Total is the number of rows when the backfill started
+	Total        int64      `json:"total"`
+	StartedAt    time.Time  `json:"startedAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	BackfilledAt *time.Time `json:"backfilledAt,omitempty"`
+}
+
+// Progress is the share of the rows backfilled, from 0 to 1. Rows inserted
+// during the backfill are copied too, so it is capped at 1.
+func (c *SchemaChange) Progress() float64 {
+	if c.Phase != SchemaChangeBackfilling || c.Total == 0 {
+		return 1
+	}
+	return min(float64(c.Backfilled)/float64(c.Total), 1)
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "testing"
+
+func TestSchemaChange_Progress(t *testing.T) {
+	tests := []struct {
+		change SchemaChange
+		want   float64
+	}{
+		{SchemaChange{Phase: SchemaChangeBackfilling, Total: 4}, 0},
+		{SchemaChange{Phase: SchemaChangeBackfilling, Total: 4, Backfilled: 1}, 0.25},
+		// Rows inserted during the backfill are copied too
+		{SchemaChange{Phase: SchemaChangeBackfilling, Total: 4, Backfilled: 6}, 1},
+		{SchemaChange{Phase: SchemaChangeBackfilling}, 1},
+		{SchemaChange{Phase: SchemaChangeBackfilled, Total: 4}, 1},
+	}
+	for _, tt := range tests {
+		if got := tt.change.Progress(); got != tt.want {
+			t.Errorf("Progress() of %+v = %v, want %v", tt.change, got, tt.want)
+		}
+	}
+}
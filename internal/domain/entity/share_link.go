@@ -0,0 +1,58 @@
+package entity
+
+import "time"
+
+// ShareLinkStatus is the state of a share link
+type ShareLinkStatus string
+
+const (
+	// ShareLinkActive can still be opened
+	ShareLinkActive ShareLinkStatus = "active"
+	// ShareLinkExpired is past its expiry
+	ShareLinkExpired ShareLinkStatus = "expired"
+	// ShareLinkRevoked was revoked by its owner
+	ShareLinkRevoked ShareLinkStatus = "revoked"
+	// ShareLinkUsed was opened as often as it allows
+	ShareLinkUsed ShareLinkStatus = "used"
+)
+
+// ShareLink grants read-only access to some of a user's profile fields to
+// whoever holds its token, without signing in. Only the SHA-256 hash of the
+// token is stored.
+type ShareLink struct {
+	ID        int    `json:"id"`
+	UserID    int    `json:"userId"`
+	TokenHash string `json:"-"`
+	// Fields are the shared profile fields, named like repository.Field*
+	Fields []string `json:"fields"`
+	// MaxViews is how often the link can be opened; Views counts the opens
+	MaxViews  int        `json:"maxViews"`
+	Views     int        `json:"views"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// StatusAt returns the link's status at now
+func (l *ShareLink) StatusAt(now time.Time) ShareLinkStatus {
+	switch {
+	case l.RevokedAt != nil:
+		return ShareLinkRevoked
+	case !now.Before(l.ExpiresAt):
+		return ShareLinkExpired
+	case l.Views >= l.MaxViews:
+		return ShareLinkUsed
+	}
+	return ShareLinkActive
+}
+
+// ShareLinkAccess records an attempt to open a share link, granted or not
+type ShareLinkAccess struct {
+	ID         int       `json:"id"`
+	LinkID     int       `json:"linkId"`
+	IP         string    `json:"ip"`
+	Device     string    `json:"device"`
+	Country    string    `json:"country,omitempty"`
+	Granted    bool      `json:"granted"`
+	AccessedAt time.Time `json:"accessedAt"`
+}
@@ -2,15 +2,60 @@ package entity
 
 import "time"
 
+// Role constants for users
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// PlanFree is the plan of users without a paid subscription. Paid plans are
+// named in the configuration, e.g. "pro".
+const PlanFree = "free"
+
+// Status constants for user accounts. Accounts move between them as
+// allowed by the lifecycle in account_lifecycle.go.
+const (
+	// StatusPending accounts were provisioned but not activated yet
+	StatusPending = "pending"
+	// StatusActive accounts can sign in
+	StatusActive = "active"
+	// StatusSuspended accounts were locked by an admin or identity provider
+	StatusSuspended = "suspended"
+	// StatusDeactivated accounts were closed but can be reactivated
+	StatusDeactivated = "deactivated"
+	// StatusDeleted accounts are removed; the status only appears in events
+	StatusDeleted = "deleted"
+)
+
+// Profile field limits, in characters (runes) rather than bytes, so they
+// hold the same for every script
+const (
+	MaxEmailLength       = 254
+	MaxFullNameLength    = 100
+	MaxPhoneNumberLength = 20
+)
+
 // User represents the core user entity in the domain
 type User struct {
-	ID          int       `json:"id"`
-	Email       string    `json:"email"`
-	Password    string    `json:"password,omitempty"`
-	FullName    string    `json:"fullName"`
-	PhoneNumber string    `json:"phoneNumber"`
-	Birthday    string    `json:"birthday"`
-	CreatedAt   time.Time `json:"createdAt"`
+	ID          int    `json:"id"`
+	Email       string `json:"email"`
+	Password    string `json:"password,omitempty"`
+	FullName    string `json:"fullName"`
+	PhoneNumber string `json:"phoneNumber"`
+	Birthday    string `json:"birthday"`
+	Avatar      string `json:"avatar,omitempty"`
+	Role        string `json:"role"`
+	Status      string `json:"status"`
+	Plan        string `json:"plan"`
+	Timezone    string `json:"timezone,omitempty"`
+	NationalID  string `json:"nationalId,omitempty"`
+	// KYCStatus is the status of the user's latest identity verification,
+	// empty until they submit a document
+	KYCStatus string    `json:"kycStatus,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Version increases on every write and backs version-checked updates
+	Version int `json:"version"`
 }
 
 // NewUser creates a new user entity
@@ -21,6 +66,9 @@ func NewUser(email, password, fullName, phoneNumber, birthday string) *User {
 		FullName:    fullName,
 		PhoneNumber: phoneNumber,
 		Birthday:    birthday,
+		Role:        RoleUser,
+		Status:      StatusActive,
+		Plan:        PlanFree,
 		CreatedAt:   time.Now(),
 	}
 }
@@ -30,6 +78,11 @@ func (u *User) IsValidEmail() bool {
 	return u.Email != "" && len(u.Email) > 5
 }
 
+// IsPaidPlan reports whether plan is a paid plan
+func IsPaidPlan(plan string) bool {
+	return plan != "" && plan != PlanFree
+}
+
 // WithoutPassword returns user without password field for security
 func (u *User) WithoutPassword() *User {
 	userCopy := *u
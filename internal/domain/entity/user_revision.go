@@ -0,0 +1,85 @@
+package entity
+
+import "time"
+
+// RevisionAction identifies the kind of change recorded in a user revision
+type RevisionAction string
+
+const (
+	// RevisionActionUpdate is recorded when a user's data is changed
+	RevisionActionUpdate RevisionAction = "update"
+	// RevisionActionDelete is recorded when a user is removed
+	RevisionActionDelete RevisionAction = "delete"
+)
+
+// redactedValue replaces secret values in revision diffs
+const redactedValue = "[redacted]"
+
+// FieldChange describes a single field that differs between two user states
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// UserRevision is a snapshot of a user taken before an update or delete,
+// together with who made the change and what changed
+type UserRevision struct {
+	ID        int            `json:"id"`
+	UserID    int            `json:"userId"`
+	ActorID   int            `json:"actorId"`
+	Action    RevisionAction `json:"action"`
+	Snapshot  User           `json:"snapshot"`
+	Changes   []FieldChange  `json:"changes"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// NewUserRevision creates a revision for a change from before to after made by actorID.
+// A nil after records a delete. The snapshot never contains the password hash.
+func NewUserRevision(actorID int, before, after *User) *UserRevision {
+	revision := &UserRevision{
+		UserID:   before.ID,
+		ActorID:  actorID,
+		Action:   RevisionActionUpdate,
+		Snapshot: *before.WithoutPassword(),
+	}
+	if after == nil {
+		revision.Action = RevisionActionDelete
+		return revision
+	}
+
+	revision.Changes = DiffUsers(before, after)
+	return revision
+}
+
+// DiffUsers lists the profile fields that differ between two user states.
+// Password changes are reported without their values.
+func DiffUsers(before, after *User) []FieldChange {
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"email", before.Email, after.Email},
+		{"fullName", before.FullName, after.FullName},
+		{"phoneNumber", before.PhoneNumber, after.PhoneNumber},
+		{"birthday", before.Birthday, after.Birthday},
+		{"avatar", before.Avatar, after.Avatar},
+		{"role", before.Role, after.Role},
+		{"status", before.Status, after.Status},
+		{"plan", before.Plan, after.Plan},
+		{"timezone", before.Timezone, after.Timezone},
+		{"nationalId", before.NationalID, after.NationalID},
+		{"kycStatus", before.KYCStatus, after.KYCStatus},
+	}
+
+	var changes []FieldChange
+	for _, f := range fields {
+		if f.old != f.new {
+			changes = append(changes, FieldChange{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+	if before.Password != after.Password {
+		changes = append(changes, FieldChange{Field: "password", Old: redactedValue, New: redactedValue})
+	}
+	return changes
+}
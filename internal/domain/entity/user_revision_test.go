@@ -0,0 +1,57 @@
+package entity
+
+import "testing"
+
+func TestDiffUsers(t *testing.T) {
+	before := NewUser("old@example.com", "oldhash", "Old Name", "0812345678", "1990-01-15")
+	after := *before
+	after.Email = "new@example.com"
+	after.Status = StatusSuspended
+	after.Password = "newhash"
+
+	changes := DiffUsers(before, &after)
+
+	want := []FieldChange{
+		{Field: "email", Old: "old@example.com", New: "new@example.com"},
+		{Field: "status", Old: StatusActive, New: StatusSuspended},
+		{Field: "password", Old: "[redacted]", New: "[redacted]"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("DiffUsers() = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("changes[%d] = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	if changes := DiffUsers(before, before); len(changes) != 0 {
+		t.Errorf("DiffUsers() of identical users = %+v, want none", changes)
+	}
+}
+
+func TestNewUserRevision(t *testing.T) {
+	before := NewUser("user@example.com", "hash", "Before", "0812345678", "1990-01-15")
+	before.ID = 7
+	after := *before
+	after.FullName = "After"
+
+	update := NewUserRevision(3, before, &after)
+	if update.UserID != 7 || update.ActorID != 3 || update.Action != RevisionActionUpdate {
+		t.Errorf("NewUserRevision() = %+v", update)
+	}
+	if update.Snapshot.FullName != "Before" {
+		t.Errorf("Snapshot.FullName = %v, want the state before the change", update.Snapshot.FullName)
+	}
+	if update.Snapshot.Password != "" {
+		t.Error("Snapshot should not contain the password hash")
+	}
+	if len(update.Changes) != 1 || update.Changes[0].Field != "fullName" {
+		t.Errorf("Changes = %+v, want a single fullName change", update.Changes)
+	}
+
+	deletion := NewUserRevision(3, before, nil)
+	if deletion.Action != RevisionActionDelete || len(deletion.Changes) != 0 {
+		t.Errorf("NewUserRevision() for delete = %+v", deletion)
+	}
+}
@@ -29,6 +29,15 @@ func TestNewUser(t *testing.T) {
 	if user.Birthday != birthday {
 		t.Errorf("Birthday = %v, want %v", user.Birthday, birthday)
 	}
+	if user.Role != RoleUser {
+		t.Errorf("Role = %v, want %v", user.Role, RoleUser)
+	}
+	if user.Status != StatusActive {
+		t.Errorf("Status = %v, want %v", user.Status, StatusActive)
+	}
+	if user.Plan != PlanFree {
+		t.Errorf("Plan = %v, want %v", user.Plan, PlanFree)
+	}
 	if user.ID != 0 {
 		t.Errorf("ID should be 0 for new user, got %v", user.ID)
 	}
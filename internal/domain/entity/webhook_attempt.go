@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// WebhookAttempt is a request a webhook sent for a hook event. StatusCode is
+// the receiver's response code, 0 when there was no response; Error is empty
+// when the receiver accepted the event.
+type WebhookAttempt struct {
+	ID         int           `json:"id"`
+	EventID    string        `json:"eventId"`
+	Point      string        `json:"point"`
+	UserID     int           `json:"userId,omitempty"`
+	StatusCode int           `json:"statusCode"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+// WebhookDelivery is the timeline of a webhook's attempts at sending one
+// event, oldest first. Delivered is true once an attempt succeeded.
+type WebhookDelivery struct {
+	EventID   string            `json:"eventId"`
+	Point     string            `json:"point"`
+	UserID    int               `json:"userId,omitempty"`
+	Delivered bool              `json:"delivered"`
+	Attempts  []*WebhookAttempt `json:"attempts"`
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// AdminActionRepository defines the interface for the queue of delayed admin actions
+type AdminActionRepository interface {
+	// Create queues an action and sets its ID and CreatedAt
+	Create(action *entity.AdminAction) error
+
+	// GetByToken retrieves an action by its undo token.
+	// Returns ErrAdminActionNotFound if there is none.
+	GetByToken(token string) (*entity.AdminAction, error)
+
+	// Cancel marks a pending action, or one awaiting approval, as cancelled.
+	// Returns ErrAdminActionNotFound or ErrAdminActionNotPending.
+	Cancel(token string) error
+
+	// Approve records the approval of an action awaiting it by reviewerID at
+	// and makes it pending, due at executeAt.
+	// Returns ErrAdminActionNotFound or ErrAdminActionNotAwaitingApproval.
+	Approve(token string, reviewerID int, at, executeAt time.Time) error
+
+	// Reject records the rejection of an action awaiting approval by
+	// reviewerID at.
+	// Returns ErrAdminActionNotFound or ErrAdminActionNotAwaitingApproval.
+	Reject(token string, reviewerID int, at time.Time) error
+
+	// ClaimDue marks up to limit pending actions due at or before now as running
+	// and returns them. An action is only ever claimed once.
+	ClaimDue(now time.Time, limit int) ([]*entity.AdminAction, error)
+
+	// CountDue returns the number of pending actions due at or before now,
+	// i.e. the worker's backlog
+	CountDue(now time.Time) (int, error)
+
+	// ListPending returns the pending actions of kind, soonest first
+	ListPending(kind entity.AdminActionKind) ([]*entity.AdminAction, error)
+
+	// List returns up to limit actions with status and kind, newest first.
+	// An empty status or kind matches any.
+	List(status entity.AdminActionStatus, kind entity.AdminActionKind, limit int) ([]*entity.AdminAction, error)
+
+	// CountByStatus returns the number of actions in each status
+	CountByStatus() (map[entity.AdminActionStatus]int, error)
+
+	// Throughput counts the actions of each kind that finished at or after since
+	Throughput(since time.Time) ([]entity.AdminActionThroughput, error)
+
+	// Retry queues a failed action again to run at executeAt, for its failed
+	// users only when it recorded any.
+	// Returns ErrAdminActionNotFound or ErrAdminActionNotFailed.
+	Retry(token string, executeAt time.Time) error
+
+	// SetPaused pauses or resumes the queue; ClaimDue is not called while paused
+	SetPaused(paused bool, at time.Time) error
+
+	// PausedSince returns when the queue was paused, or the zero time if it runs
+	PausedSince() (time.Time, error)
+
+	// Progress records how many of a running action's users have been processed
+	Progress(id int, processed int) error
+
+	// Finish records the final status, error message and failed users of a
+	// claimed action
+	Finish(id int, status entity.AdminActionStatus, errMsg string, failedUserIDs []int) error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// AuditFilter selects audit entries. Zero fields match everything.
+type AuditFilter struct {
+	ActorID     int
+	SubjectType string
+	SubjectID   string
+	// BeforeID returns only entries older than this one, for paging
+	BeforeID int
+	// Limit caps the number of entries; 0 means no limit
+	Limit int
+}
+
+// AuditRepository defines the interface for the append-only audit trail of
+// admin mutations
+type AuditRepository interface {
+	// Record stores an entry and sets its ID and CreatedAt
+	Record(entry *entity.AuditEntry) error
+
+	// List returns the entries matching filter, newest first
+	List(filter AuditFilter) ([]*entity.AuditEntry, error)
+}
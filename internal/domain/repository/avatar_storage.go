@@ -0,0 +1,7 @@
+package repository
+
+// AvatarStorage defines the interface for storing user avatar images
+type AvatarStorage interface {
+	// Save stores the avatar image for a user and returns its public URL path
+	Save(userID int, contentType string, data []byte) (string, error)
+}
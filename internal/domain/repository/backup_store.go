@@ -0,0 +1,16 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// BackupStore defines the interface for storing database backups
+type BackupStore interface {
+	// Create writes a backup of the live database, verifies its integrity
+	// and returns it with its checksum
+	Create() (*entity.Backup, error)
+
+	// List returns the stored backups, newest first
+	List() ([]entity.Backup, error)
+
+	// Delete removes a stored backup by name
+	Delete(name string) error
+}
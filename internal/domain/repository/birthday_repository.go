@@ -0,0 +1,9 @@
+package repository
+
+// BirthdayRepository records which birthdays were celebrated, so each user
+// is greeted once a year even with several nodes running the birthday worker
+type BirthdayRepository interface {
+	// Claim marks the birthday of userID in year as celebrated. Returns
+	// false if it was already claimed.
+	Claim(userID, year int) (bool, error)
+}
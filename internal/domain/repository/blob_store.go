@@ -0,0 +1,13 @@
+package repository
+
+// BlobStore defines the interface for object storage such as S3 or GCS
+type BlobStore interface {
+	// Put stores data under key, replacing any existing object
+	Put(key string, data []byte, contentType string) error
+
+	// Get returns the object stored under key. Returns ErrBlobNotFound.
+	Get(key string) ([]byte, error)
+
+	// Delete removes the object stored under key. A missing object is not an error.
+	Delete(key string) error
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// BreakGlassRepository defines the interface for the break-glass seals of
+// key ceremonies and the sessions they open
+type BreakGlassRepository interface {
+	// CreateSeal stores a new seal and sets its ID, revoking the unused ones
+	CreateSeal(seal *entity.BreakGlassSeal) error
+
+	// ActiveSeal returns the seal that is neither used nor revoked.
+	// Returns ErrBreakGlassSealNotFound.
+	ActiveSeal() (*entity.BreakGlassSeal, error)
+
+	// Open marks a seal used and stores the session it opens, setting its
+	// ID. Returns ErrBreakGlassSealNotFound when the seal was used or
+	// revoked meanwhile.
+	Open(sealID int, session *entity.BreakGlassSession) error
+
+	// ActiveSession returns the session of a user open at now.
+	// Returns ErrBreakGlassSessionNotFound.
+	ActiveSession(userID int, now time.Time) (*entity.BreakGlassSession, error)
+
+	// ListSessions returns up to limit sessions, newest first
+	ListSessions(limit int) ([]*entity.BreakGlassSession, error)
+
+	// Close closes an open session at now. Returns
+	// ErrBreakGlassSessionNotFound when it is not open.
+	Close(id, closedBy int, now time.Time) (*entity.BreakGlassSession, error)
+
+	// CloseExpired closes the sessions that expired before now, as of their
+	// expiry, and returns them
+	CloseExpired(now time.Time) ([]*entity.BreakGlassSession, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// ClaimsCache holds users' derived claims so authenticated requests need not
+// read the user. Entries expire after the cache's TTL.
+type ClaimsCache interface {
+	// Get returns the cached claims of a user; ok is false on a miss
+	Get(userID int) (claims *entity.DerivedClaims, ok bool, err error)
+
+	// Set caches the claims of claims.UserID
+	Set(claims *entity.DerivedClaims) error
+
+	// Delete drops the cached claims of the users. Users without cached
+	// claims are ignored.
+	Delete(userIDs ...int) error
+}
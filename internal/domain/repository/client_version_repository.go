@@ -0,0 +1,17 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// ClientVersionRepository defines the interface for the minimum app
+// versions set by admins
+type ClientVersionRepository interface {
+	// List returns every stored policy, by platform
+	List() ([]*entity.ClientVersionPolicy, error)
+
+	// Save creates or replaces the policy of its platform and sets its
+	// UpdatedAt
+	Save(policy *entity.ClientVersionPolicy) error
+
+	// Delete removes the policy of a platform. Returns ErrClientVersionNotFound.
+	Delete(platform string) error
+}
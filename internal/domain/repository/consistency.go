@@ -0,0 +1,33 @@
+package repository
+
+import "fmt"
+
+// IDGenerator allocates primary keys outside the database so that nodes in
+// different regions can create rows without colliding
+type IDGenerator interface {
+	NextID() (int, error)
+}
+
+// UpdateStrategy decides how concurrent updates to the same row are resolved
+type UpdateStrategy string
+
+const (
+	// LastWriteWins applies every update; the most recent write overwrites
+	// earlier ones. Suitable for tables whose rows are only ever changed by
+	// their owner or where lost updates are harmless.
+	LastWriteWins UpdateStrategy = "last-write-wins"
+
+	// VersionChecked only applies an update when the row still has the
+	// version the caller read, and returns ErrVersionConflict otherwise
+	VersionChecked UpdateStrategy = "version-checked"
+)
+
+// ParseUpdateStrategy validates a configured update strategy
+func ParseUpdateStrategy(value string) (UpdateStrategy, error) {
+	switch strategy := UpdateStrategy(value); strategy {
+	case LastWriteWins, VersionChecked:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown update strategy %q", value)
+	}
+}
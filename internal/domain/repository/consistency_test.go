@@ -0,0 +1,28 @@
+package repository
+
+import "testing"
+
+func TestParseUpdateStrategy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    UpdateStrategy
+		wantErr bool
+	}{
+		{"last-write-wins", LastWriteWins, false},
+		{"version-checked", VersionChecked, false},
+		{"", "", true},
+		{"optimistic", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseUpdateStrategy(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUpdateStrategy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseUpdateStrategy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
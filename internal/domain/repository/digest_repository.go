@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// DigestRepository records which admin digests have been sent, so each is
+// sent once even with several nodes running the digest worker
+type DigestRepository interface {
+	// Claim marks the digest of period ending at until as sent. Returns
+	// false if it was already claimed.
+	Claim(period entity.DigestPeriod, until time.Time) (bool, error)
+
+	// Release removes a claim whose digest could not be sent, so it is retried
+	Release(period entity.DigestPeriod, until time.Time) error
+}
+
+// HookFailureRepository records lifecycle hooks that failed after their
+// change was saved, for admin digests
+type HookFailureRepository interface {
+	// Record stores a failure and sets its ID and CreatedAt
+	Record(failure *entity.HookFailure) error
+
+	// List returns the failures at or after since and before until, oldest first
+	List(since, until time.Time) ([]*entity.HookFailure, error)
+}
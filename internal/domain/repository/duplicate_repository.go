@@ -0,0 +1,20 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// DuplicateRepository defines the interface for the probable duplicate
+// accounts found by scans, kept for admin review
+type DuplicateRepository interface {
+	// Replace saves the pairs found by a scan: new pairs are added as
+	// pending and the signals of known ones updated. Pending pairs not
+	// found again are removed; dismissed ones are kept, so they are not
+	// surfaced again.
+	Replace(candidates []*entity.DuplicateCandidate) error
+
+	// List returns up to limit pairs with status, most recently detected first
+	List(status string, limit int) ([]*entity.DuplicateCandidate, error)
+
+	// Dismiss marks a pair as reviewed and not duplicates, keeping the first
+	// review. Returns ErrDuplicateNotFound.
+	Dismiss(id, reviewerID int) (*entity.DuplicateCandidate, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// EntitlementOverrideRepository defines the interface for per-user
+// entitlement overrides
+type EntitlementOverrideRepository interface {
+	// ListByUser returns the overrides of a user, sorted by feature
+	ListByUser(userID int) ([]*entity.EntitlementOverride, error)
+
+	// Set stores an override, replacing the user's override of the same
+	// feature
+	Set(override *entity.EntitlementOverride) error
+
+	// Delete removes the user's override of feature, reporting whether
+	// there was one
+	Delete(userID int, feature string) (bool, error)
+}
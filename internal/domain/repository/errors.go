@@ -0,0 +1,94 @@
+package repository
+
+import "errors"
+
+// ErrEmailTaken is returned when creating or updating a user would duplicate an existing email
+var ErrEmailTaken = errors.New("user with this email already exists")
+
+// ErrAdminActionNotFound is returned when no admin action has the given token
+var ErrAdminActionNotFound = errors.New("admin action not found")
+
+// ErrAdminActionNotPending is returned when undoing an action that already ran or was cancelled
+var ErrAdminActionNotPending = errors.New("admin action is no longer pending")
+
+// ErrAdminActionNotFailed is returned when retrying an action that did not fail
+var ErrAdminActionNotFailed = errors.New("only failed admin actions can be retried")
+
+// ErrAdminActionNotAwaitingApproval is returned when approving or rejecting an
+// action that does not need approval or was already reviewed
+var ErrAdminActionNotAwaitingApproval = errors.New("admin action is not awaiting approval")
+
+// ErrHookDeliveryNotFound is returned when no failed hook delivery has the given ID
+var ErrHookDeliveryNotFound = errors.New("hook delivery not found")
+
+// ErrHookDeliveryNotDead is returned when replaying a delivery that is not in the dead-letter store
+var ErrHookDeliveryNotDead = errors.New("only dead-lettered hook deliveries can be replayed")
+
+// ErrVersionConflict is returned by version-checked updates when the row changed since it was read
+var ErrVersionConflict = errors.New("record was modified concurrently")
+
+// ErrTokenNotFound is returned when no single-use token matches
+var ErrTokenNotFound = errors.New("token not found")
+
+// ErrTokenAlreadyUsed is returned when consuming a single-use token a second time
+var ErrTokenAlreadyUsed = errors.New("token has already been used")
+
+// ErrTokenExpired is returned when consuming a single-use token after it expired
+var ErrTokenExpired = errors.New("token has expired")
+
+// ErrBlobNotFound is returned when no object is stored under a key
+var ErrBlobNotFound = errors.New("object not found")
+
+// ErrKeyNotFound is returned when a user has no encryption key, e.g. after it was shredded
+var ErrKeyNotFound = errors.New("encryption key not found")
+
+// ErrKeyExists is returned when storing a key for a user who already has one
+var ErrKeyExists = errors.New("encryption key already exists")
+
+// ErrQRLoginNotFound is returned when no QR login matches
+var ErrQRLoginNotFound = errors.New("QR login not found")
+
+// ErrQRLoginNotPending is returned when approving or denying a QR login that was already decided or expired
+var ErrQRLoginNotPending = errors.New("QR login is no longer pending")
+
+// ErrShareLinkNotFound is returned when no share link matches
+var ErrShareLinkNotFound = errors.New("share link not found")
+
+// ErrShareLinkUnavailable is returned when opening a share link that expired, was revoked or was used up
+var ErrShareLinkUnavailable = errors.New("share link is no longer available")
+
+// ErrClientVersionNotFound is returned when no minimum app version is stored for a platform
+var ErrClientVersionNotFound = errors.New("client version policy not found")
+
+// ErrSchemaChangeNotFound is returned for a schema change that is not defined or has not started
+var ErrSchemaChangeNotFound = errors.New("schema change not found")
+
+// ErrDuplicateNotFound is returned when no duplicate candidate matches
+var ErrDuplicateNotFound = errors.New("duplicate candidate not found")
+
+// ErrFraudScoreNotFound is returned for a user who has not been scored
+var ErrFraudScoreNotFound = errors.New("fraud score not found")
+
+// ErrKYCSubmissionNotFound is returned when no identity document submission matches
+var ErrKYCSubmissionNotFound = errors.New("KYC submission not found")
+
+// ErrKYCSubmissionDecided is returned when deciding on a submission whose
+// status changed since it was read
+var ErrKYCSubmissionDecided = errors.New("KYC submission was already decided")
+
+// ErrKYCInProgress is returned when creating a submission for a user who
+// has another one waiting for a decision
+var ErrKYCInProgress = errors.New("identity verification is already in progress")
+
+// ErrBreakGlassSealNotFound is returned when no unused break-glass seal matches
+var ErrBreakGlassSealNotFound = errors.New("break-glass seal not found")
+
+// ErrBreakGlassSessionNotFound is returned when no break-glass session matches
+var ErrBreakGlassSessionNotFound = errors.New("break-glass session not found")
+
+// ErrUserNotFound is returned by GetByEmail when no user has the email
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrUserNotFoundCached is returned by GetByEmail when the email was recently
+// found to have no user, so no query was made
+var ErrUserNotFoundCached = errors.New("user not found")
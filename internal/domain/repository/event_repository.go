@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// EventFilter selects domain events. Zero fields match everything.
+type EventFilter struct {
+	Types []entity.EventType
+	// Since and Until bound OccurredAt to [Since, Until)
+	Since time.Time
+	Until time.Time
+	// AfterID returns only events after this one, for paging
+	AfterID int64
+	// Limit caps the number of events; 0 means no limit
+	Limit int
+}
+
+// EventRepository defines the interface for the append-only domain event log
+type EventRepository interface {
+	// Append stores an event and sets its ID and OccurredAt
+	Append(event *entity.DomainEvent) error
+
+	// List returns the events matching the filter, oldest first
+	List(filter EventFilter) ([]*entity.DomainEvent, error)
+
+	// LastID returns the ID of the newest event, 0 when the log is empty
+	LastID() (int64, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// FraudRepository defines the interface for the fraud scores of users
+type FraudRepository interface {
+	// Get returns the score of a user. Returns ErrFraudScoreNotFound.
+	Get(userID int) (*entity.FraudScore, error)
+
+	// Save creates or replaces the score of a user
+	Save(score *entity.FraudScore) error
+
+	// List returns up to limit scores with status, highest score first
+	List(status string, limit int) ([]*entity.FraudScore, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// FunnelRepository defines the interface for registration funnel data operations
+type FunnelRepository interface {
+	// Record saves a funnel event. A stage a user already reached is not
+	// recorded again; events without a user always are.
+	Record(event *entity.FunnelEvent) error
+
+	// CountByDay returns per-day stage counts between from and to (inclusive, YYYY-MM-DD)
+	CountByDay(from, to string) ([]entity.FunnelDay, error)
+}
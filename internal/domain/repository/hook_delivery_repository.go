@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// HookDeliveryRepository defines the interface for the retry queue and
+// dead-letter store of failed hook deliveries
+type HookDeliveryRepository interface {
+	// Create stores a failed delivery and sets its ID, CreatedAt and UpdatedAt
+	Create(delivery *entity.HookDelivery) error
+
+	// GetByID retrieves a delivery.
+	// Returns ErrHookDeliveryNotFound if there is none.
+	GetByID(id int) (*entity.HookDelivery, error)
+
+	// ClaimDue marks up to limit retrying deliveries due at or before now as
+	// running and returns them. A delivery is only ever claimed once per attempt.
+	ClaimDue(now time.Time, limit int) ([]*entity.HookDelivery, error)
+
+	// CountDue returns the number of retrying deliveries due at or before now
+	CountDue(now time.Time) (int, error)
+
+	// Update saves a claimed delivery's status, attempts, errors and next attempt
+	Update(delivery *entity.HookDelivery) error
+
+	// List returns up to limit deliveries with status and point, newest
+	// first. An empty status or point matches any.
+	List(status entity.HookDeliveryStatus, point string, limit int) ([]*entity.HookDelivery, error)
+
+	// Replay queues a dead delivery to be attempted again at at, with its
+	// attempts reset and its error history kept.
+	// Returns ErrHookDeliveryNotFound or ErrHookDeliveryNotDead.
+	Replay(id int, at time.Time) error
+
+	// ReplayAll queues every dead delivery at point, or at any point when
+	// empty, like Replay, and returns how many were queued
+	ReplayAll(point string, at time.Time) (int, error)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// InboundEventRepository defines the interface for the events received from
+// providers' webhooks, which guards against handling one twice
+type InboundEventRepository interface {
+	// Claim records a received event as processing and sets its ID. It
+	// reports false if the provider's event ID was already received, unless
+	// its handling failed or has been processing since before staleBefore.
+	Claim(event *entity.InboundEvent, staleBefore time.Time) (bool, error)
+
+	// Finish records that a claimed event was processed at at, or failed
+	// with errMsg when it is not empty
+	Finish(id int, errMsg string, at time.Time) error
+
+	// DeleteBefore removes the events received before the given time
+	DeleteBefore(before time.Time) (int, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+// KeyStore keeps each user's data encryption key. It must live apart from
+// the database and its backups: deleting a key makes the data encrypted
+// with it unreadable wherever it was copied.
+type KeyStore interface {
+	// Get returns the user's key.
+	// Returns ErrKeyNotFound if the user has none.
+	Get(userID int) ([]byte, error)
+
+	// Put stores a key for the user. Keys are never replaced; returns
+	// ErrKeyExists if the user already has one.
+	Put(userID int, key []byte) error
+
+	// Delete destroys the user's key. Deleting a missing key is not an error.
+	Delete(userID int) error
+}
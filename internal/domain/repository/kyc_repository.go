@@ -0,0 +1,34 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// KYCRepository defines the interface for identity document submissions
+type KYCRepository interface {
+	// Create stores a new submission and sets its ID, CreatedAt and
+	// UpdatedAt. A user has at most one open submission. Returns
+	// ErrKYCInProgress for a second one.
+	Create(submission *entity.KYCSubmission) error
+
+	// GetByID returns a submission. Returns ErrKYCSubmissionNotFound.
+	GetByID(id int) (*entity.KYCSubmission, error)
+
+	// Latest returns the newest submission of a user. Returns ErrKYCSubmissionNotFound.
+	Latest(userID int) (*entity.KYCSubmission, error)
+
+	// List returns up to limit submissions with status, oldest first
+	List(status string, limit int) ([]*entity.KYCSubmission, error)
+
+	// Update saves the status and decision of a submission that is still in
+	// status, the one it was read with, and sets its UpdatedAt. Returns
+	// ErrKYCSubmissionDecided when its status changed meanwhile.
+	Update(submission *entity.KYCSubmission, status string) error
+}
+
+// KYCProvider verifies identity documents, e.g. with a third-party
+// identity proofing service
+type KYCProvider interface {
+	// Verify checks the document of a submission. A provider that cannot
+	// decide returns a review decision, which leaves it to an admin; an
+	// error means the check should be tried again later.
+	Verify(submission *entity.KYCSubmission, document []byte) (entity.KYCDecision, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import "time"
+
+// Locker grants named leases to one instance at a time, so a background job
+// every replica runs is only run by one of them
+type Locker interface {
+	// Acquire takes the lease on name for ttl, or extends it when this
+	// instance already holds it. Returns false while another instance
+	// holds an unexpired lease.
+	Acquire(name string, ttl time.Duration) (bool, error)
+
+	// Release gives up the lease on name if this instance holds it
+	Release(name string) error
+}
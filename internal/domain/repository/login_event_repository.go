@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// LoginEventRepository defines the interface for the login history
+type LoginEventRepository interface {
+	// Record stores a login attempt and sets its ID and CreatedAt. For a
+	// successful login it also sets NewDevice and NewCountry, unless it is
+	// the user's first successful login.
+	Record(event *entity.LoginEvent) error
+
+	// ListByUser returns the user's login attempts since the given time, newest first
+	ListByUser(userID int, since time.Time) ([]*entity.LoginEvent, error)
+
+	// ListSuccessful returns the successful logins of all users at or after
+	// since and before until, oldest first
+	ListSuccessful(since, until time.Time) ([]*entity.LoginEvent, error)
+
+	// ListFailed returns the failed logins of all users at or after since
+	// and before until, oldest first
+	ListFailed(since, until time.Time) ([]*entity.LoginEvent, error)
+}
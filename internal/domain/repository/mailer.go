@@ -0,0 +1,7 @@
+package repository
+
+// Mailer sends plain text emails
+type Mailer interface {
+	// Send emails body to every address in to
+	Send(to []string, subject, body string) error
+}
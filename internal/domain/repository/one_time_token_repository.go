@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// OneTimeTokenRepository defines the interface for stored single-use tokens
+type OneTimeTokenRepository interface {
+	// Create stores a token and sets its ID and CreatedAt
+	Create(token *entity.OneTimeToken) error
+
+	// Consume marks the token with the given purpose and hash as used at now
+	// and returns it. Only one caller can ever consume a token. Returns
+	// ErrTokenNotFound, ErrTokenAlreadyUsed or ErrTokenExpired.
+	Consume(purpose entity.TokenPurpose, tokenHash string, now time.Time) (*entity.OneTimeToken, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// PresenceRepository stores when users were last seen and who may see it
+type PresenceRepository interface {
+	// Touch records the last seen times of users in one write. Times older
+	// than the stored ones are ignored, so nodes may flush in any order.
+	Touch(lastSeen map[int]time.Time) error
+	// Get returns the presence of the users found, in no particular order
+	Get(userIDs []int) ([]*entity.Presence, error)
+	// SetVisibility stores who may see a user's presence
+	SetVisibility(userID int, visibility entity.PresenceVisibility) error
+}
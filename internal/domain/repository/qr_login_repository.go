@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// QRLoginRepository defines the interface for stored QR logins
+type QRLoginRepository interface {
+	// Create stores a login and sets its ID and CreatedAt
+	Create(login *entity.QRLogin) error
+
+	// GetByChallenge returns the login whose QR code has the challenge with
+	// the given hash. Returns ErrQRLoginNotFound.
+	GetByChallenge(challengeHash string) (*entity.QRLogin, error)
+
+	// GetByPoll returns the login the desktop polls with the given token
+	// hash. Returns ErrQRLoginNotFound.
+	GetByPoll(pollHash string) (*entity.QRLogin, error)
+
+	// Decide moves a pending login that has not expired at now to approved
+	// or denied by userID. Returns ErrQRLoginNotPending otherwise.
+	Decide(id int, status entity.QRLoginStatus, userID int, now time.Time) error
+
+	// Complete moves an approved login that has not expired at now to
+	// completed. Only one caller can complete a login; the others get false.
+	Complete(id int, now time.Time) (bool, error)
+
+	// DeleteExpired removes the logins that expired before the given time
+	// and returns how many were removed
+	DeleteExpired(before time.Time) (int, error)
+}
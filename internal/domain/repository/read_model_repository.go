@@ -0,0 +1,42 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// ReadModelRepository defines the interface for the denormalized read models
+// projected from the domain event log, kept apart from the users table so
+// heavy admin queries do not contend with writes. Each projection records
+// the position it has reached in the log together with its changes.
+type ReadModelRepository interface {
+	// Position returns the ID of the last event a projection applied. ok is
+	// false until the projection first saves its changes.
+	Position(projection string) (position int64, ok bool, err error)
+
+	// Statuses returns the position of every projection that has run, by name.
+	// Behind is left for the caller to fill.
+	Statuses() ([]*entity.ProjectionStatus, error)
+
+	// SaveUserSearch upserts and removes user_search rows and moves the
+	// user_search projection to position, in one transaction
+	SaveUserSearch(position int64, entries []*entity.UserSearchEntry, removed []int) error
+
+	// AddLoginStats adds the counts of stats to the users' login_stats rows,
+	// keeping the earliest first and latest last login, removes the rows of
+	// removed users and moves the login_stats projection to position, in
+	// one transaction
+	AddLoginStats(position int64, stats []*entity.LoginStats, removed []int) error
+
+	// Reset empties a projection's read model and forgets its position, so
+	// it is rebuilt from the start of the log
+	Reset(projection string) error
+
+	// SearchUsers returns the users in user_search matching the filter,
+	// ordered by ID, within the page, with their login stats, and the
+	// number of matches
+	SearchUsers(filter UserFilter, page Page) ([]*entity.UserSummary, int, error)
+
+	// ListUsers returns up to limit users in user_search matching the
+	// filter with an ID above afterID, ordered by ID, with their login
+	// stats. Unlike SearchUsers it neither counts nor skips rows, so every
+	// match can be read in batches.
+	ListUsers(filter UserFilter, afterID, limit int) ([]*entity.UserSummary, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// RelationshipStore is an external relationship-based authorization service
+// such as OpenFGA, holding relationship tuples and answering permission
+// checks against its authorization model
+type RelationshipStore interface {
+	// Write adds writes and removes deletes in one request. Writing a tuple
+	// that exists or deleting one that does not is not an error.
+	Write(writes, deletes []entity.Relationship) error
+
+	// Check reports whether the relationship holds, directly or through the
+	// authorization model's rewrites
+	Check(tuple entity.Relationship) (bool, error)
+}
@@ -0,0 +1,275 @@
+package repositorytest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Property runs: each generates a random sequence of operations from its
+// seed and replays it against a new repository and an in-memory model
+const (
+	propertyRuns  = 25
+	propertySteps = 60
+	// propertyEmails is the size of the email pool; it is small so that
+	// operations often collide on an email
+	propertyEmails = 6
+)
+
+type opKind int
+
+const (
+	opCreate opKind = iota
+	opUpdate
+	opUpdateFields
+	opDelete
+	opGetByEmail
+)
+
+// op is one generated operation. Users are chosen by their position among
+// the live users rather than by ID, so operations stay meaningful when
+// earlier ones are removed while shrinking.
+type op struct {
+	kind  opKind
+	user  int
+	email int
+	name  int
+}
+
+func (o op) String() string {
+	email := propertyEmail(o.email)
+	switch o.kind {
+	case opCreate:
+		return fmt.Sprintf("Create(%s)", email)
+	case opUpdate:
+		return fmt.Sprintf("Update(user #%d, %s, name %d)", o.user, email, o.name)
+	case opUpdateFields:
+		return fmt.Sprintf("UpdateFields(user #%d, %s, name %d)", o.user, email, o.name)
+	case opDelete:
+		return fmt.Sprintf("Delete(user #%d)", o.user)
+	}
+	return fmt.Sprintf("GetByEmail(%s)", email)
+}
+
+func propertyEmail(i int) string {
+	return fmt.Sprintf("prop-%d@example.com", i)
+}
+
+func generateOps(seed uint64) []op {
+	r := rand.New(rand.NewPCG(seed, seed))
+	ops := make([]op, propertySteps)
+	for i := range ops {
+		ops[i] = op{
+			kind:  opKind(r.IntN(int(opGetByEmail) + 1)),
+			user:  r.IntN(propertyEmails),
+			email: r.IntN(propertyEmails),
+			name:  r.IntN(3),
+		}
+	}
+	return ops
+}
+
+// modelUser is what the model expects to read back for a user
+type modelUser struct {
+	email    string
+	fullName string
+}
+
+// model tracks the users that should be stored, by ID
+type model struct {
+	users map[int]modelUser
+	// deleted holds IDs that were deleted and not assigned again
+	deleted map[int]bool
+}
+
+func (m *model) liveIDs() []int {
+	ids := make([]int, 0, len(m.users))
+	for id := range m.users {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// owner returns the live user with the email, if any
+func (m *model) owner(email string) (int, bool) {
+	for id, user := range m.users {
+		if user.email == email {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// apply runs o against repo and the model and returns an error when the
+// repository's answer breaks an invariant
+func (m *model) apply(repo repository.UserRepository, o op) error {
+	email := propertyEmail(o.email)
+	name := fmt.Sprintf("Property User %d", o.name)
+	ids := m.liveIDs()
+	var id int
+	if len(ids) > 0 {
+		id = ids[o.user%len(ids)]
+	}
+
+	switch o.kind {
+	case opCreate:
+		owner, taken := m.owner(email)
+		user := NewUser(email)
+		user.FullName = name
+		created, err := repo.Create(user)
+		if taken {
+			if !errors.Is(err, repository.ErrEmailTaken) {
+				return fmt.Errorf("uniqueness: Create() of an email owned by user %d = %v, want ErrEmailTaken", owner, err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Create() error = %v", err)
+		}
+		if _, exists := m.users[created.ID]; exists || created.ID == 0 {
+			return fmt.Errorf("uniqueness: Create() assigned ID %d, which is in use", created.ID)
+		}
+		m.users[created.ID] = modelUser{email: email, fullName: name}
+		delete(m.deleted, created.ID)
+
+	case opUpdate, opUpdateFields:
+		if id == 0 {
+			return nil
+		}
+		owner, taken := m.owner(email)
+		taken = taken && owner != id
+		var err error
+		if o.kind == opUpdate {
+			var user *entity.User
+			if user, err = repo.GetByID(id); err != nil {
+				return fmt.Errorf("GetByID(%d) error = %v", id, err)
+			}
+			user.Email, user.FullName = email, name
+			err = repo.Update(user)
+		} else {
+			err = repo.UpdateFields(id, map[string]interface{}{
+				repository.FieldEmail:    email,
+				repository.FieldFullName: name,
+			})
+		}
+		if taken {
+			if !errors.Is(err, repository.ErrEmailTaken) {
+				return fmt.Errorf("uniqueness: updating user %d to an email owned by user %d = %v, want ErrEmailTaken", id, owner, err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("updating user %d error = %v", id, err)
+		}
+		m.users[id] = modelUser{email: email, fullName: name}
+
+	case opDelete:
+		if id == 0 {
+			return nil
+		}
+		if err := repo.Delete(id); err != nil {
+			return fmt.Errorf("Delete(%d) error = %v", id, err)
+		}
+		delete(m.users, id)
+		m.deleted[id] = true
+
+	case opGetByEmail:
+		owner, taken := m.owner(email)
+		found, err := repo.GetByEmail(email)
+		if !taken {
+			if err == nil {
+				return fmt.Errorf("delete-then-absent: GetByEmail(%s) found user %d, want none", email, found.ID)
+			}
+			return nil
+		}
+		if err != nil || found.ID != owner {
+			return fmt.Errorf("read-your-writes: GetByEmail(%s) = %v, %v; want user %d", email, found, err, owner)
+		}
+	}
+	return m.check(repo)
+}
+
+// check compares every user the model knows of with the repository
+func (m *model) check(repo repository.UserRepository) error {
+	for id, want := range m.users {
+		got, err := repo.GetByID(id)
+		if err != nil {
+			return fmt.Errorf("read-your-writes: GetByID(%d) error = %v", id, err)
+		}
+		if got.Email != want.email || got.FullName != want.fullName {
+			return fmt.Errorf("read-your-writes: GetByID(%d) = %s %q, want %s %q", id, got.Email, got.FullName, want.email, want.fullName)
+		}
+	}
+	for id := range m.deleted {
+		if got, err := repo.GetByID(id); err == nil {
+			return fmt.Errorf("delete-then-absent: GetByID(%d) = %s after Delete()", id, got.Email)
+		}
+	}
+	for i := 0; i < propertyEmails; i++ {
+		email := propertyEmail(i)
+		_, want := m.owner(email)
+		if exists, err := repo.ExistsByEmail(email); err != nil || exists != want {
+			return fmt.Errorf("ExistsByEmail(%s) = %v, %v; want %v", email, exists, err, want)
+		}
+	}
+	if count, err := repo.Count(repository.UserFilter{}); err != nil || count != len(m.users) {
+		return fmt.Errorf("Count() = %d, %v; want %d", count, err, len(m.users))
+	}
+	return nil
+}
+
+// runOps replays ops against a new repository and returns the index of the
+// first operation that broke an invariant, with the error
+func runOps(t *testing.T, factory Factory, ops []op) (int, error) {
+	clock := NewClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	repo := factory(t, clock.Now)
+	m := &model{users: map[int]modelUser{}, deleted: map[int]bool{}}
+	for i, o := range ops {
+		clock.Advance(time.Second)
+		if err := m.apply(repo, o); err != nil {
+			return i, err
+		}
+	}
+	return -1, nil
+}
+
+// shrink removes operations from a failing sequence one at a time while it
+// still fails, so the reported sequence is short enough to read
+func shrink(t *testing.T, factory Factory, ops []op) ([]op, error) {
+	failed, err := runOps(t, factory, ops)
+	ops = ops[:failed+1]
+	for i := len(ops) - 2; i >= 0; i-- {
+		candidate := slices.Delete(slices.Clone(ops), i, i+1)
+		if at, candidateErr := runOps(t, factory, candidate); at >= 0 {
+			ops, err = candidate[:at+1], candidateErr
+			i = min(i, len(ops)-1)
+		}
+	}
+	return ops, err
+}
+
+// testProperties replays random sequences of creates, updates, deletes and
+// lookups, checking after every operation that emails stay unique, that
+// reads return the last write and that deleted users are gone
+func testProperties(t *testing.T, factory Factory) {
+	for seed := uint64(1); seed <= propertyRuns; seed++ {
+		ops := generateOps(seed)
+		if at, _ := runOps(t, factory, ops); at < 0 {
+			continue
+		}
+		shrunk, err := shrink(t, factory, ops)
+		steps := make([]string, len(shrunk))
+		for i, o := range shrunk {
+			steps[i] = o.String()
+		}
+		t.Fatalf("seed %d: %v\nafter:\n  %s", seed, err, strings.Join(steps, "\n  "))
+	}
+}
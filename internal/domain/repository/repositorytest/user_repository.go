@@ -0,0 +1,737 @@
+// Package repositorytest provides a conformance suite that every
+// UserRepository implementation must pass.
+package repositorytest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Factory returns a new, empty repository for a single test that reads the
+// current time from now. Cleanup should be registered with t.Cleanup.
+type Factory func(t *testing.T, now func() time.Time) repository.UserRepository
+
+// Clock is a manually advanced time source shared between a test and the
+// repository under test
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a clock starting at the given time
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the clock's current time
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to the given time
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Run executes the UserRepository conformance suite against the backend built by factory
+func Run(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo repository.UserRepository, clock *Clock)
+	}{
+		{"CreateAssignsID", testCreateAssignsID},
+		{"CreateDuplicateEmail", testCreateDuplicateEmail},
+		{"GetByEmail", testGetByEmail},
+		{"GetByID", testGetByID},
+		{"ExistsByEmail", testExistsByEmail},
+		{"NotFound", testNotFound},
+		{"Update", testUpdate},
+		{"UpdateDuplicateEmail", testUpdateDuplicateEmail},
+		{"UpdateDoesNotChangePassword", testUpdateDoesNotChangePassword},
+		{"UpdatePassword", testUpdatePassword},
+		{"UpdateFields", testUpdateFields},
+		{"UpdateFieldsDuplicateEmail", testUpdateFieldsDuplicateEmail},
+		{"UpdateFieldsUnknownField", testUpdateFieldsUnknownField},
+		{"Delete", testDelete},
+		{"UnicodeEmail", testUnicodeEmail},
+		{"ReturnedUsersAreCopies", testReturnedUsersAreCopies},
+		{"ConcurrentCreates", testConcurrentCreates},
+		{"ConcurrentDuplicateCreates", testConcurrentDuplicateCreates},
+		{"DefaultRoleAndStatus", testDefaultRoleAndStatus},
+		{"CreateSetsTimestamps", testCreateSetsTimestamps},
+		{"UpdatesBumpUpdatedAt", testUpdatesBumpUpdatedAt},
+		{"WritesIncrementVersion", testWritesIncrementVersion},
+		{"ListFilters", testListFilters},
+		{"ListPagination", testListPagination},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+			tt.fn(t, factory(t, clock.Now), clock)
+		})
+	}
+	t.Run("Properties", func(t *testing.T) {
+		testProperties(t, factory)
+	})
+}
+
+// NewUser builds a valid user fixture for the given email
+func NewUser(email string) *entity.User {
+	return &entity.User{
+		Email:       email,
+		Password:    "hashedpassword",
+		FullName:    "Conformance User",
+		PhoneNumber: "0812345678",
+		Birthday:    "1990-01-15",
+	}
+}
+
+func mustCreate(t *testing.T, repo repository.UserRepository, email string) *entity.User {
+	t.Helper()
+	user, err := repo.Create(NewUser(email))
+	if err != nil {
+		t.Fatalf("Create(%s) error = %v", email, err)
+	}
+	return user
+}
+
+func testCreateAssignsID(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	first := mustCreate(t, repo, "first@example.com")
+	second := mustCreate(t, repo, "second@example.com")
+
+	if first.ID == 0 || second.ID == 0 {
+		t.Errorf("Create() should assign IDs, got %v and %v", first.ID, second.ID)
+	}
+	if first.ID == second.ID {
+		t.Errorf("Create() assigned duplicate ID %v", first.ID)
+	}
+}
+
+func testCreateDuplicateEmail(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	mustCreate(t, repo, "dup@example.com")
+
+	if _, err := repo.Create(NewUser("dup@example.com")); !errors.Is(err, repository.ErrEmailTaken) {
+		t.Errorf("Create() error = %v, want ErrEmailTaken", err)
+	}
+}
+
+func testGetByEmail(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	created := mustCreate(t, repo, "byemail@example.com")
+
+	found, err := repo.GetByEmail("byemail@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail() error = %v", err)
+	}
+	assertSameUser(t, found, created)
+}
+
+func testGetByID(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	created := mustCreate(t, repo, "byid@example.com")
+
+	found, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	assertSameUser(t, found, created)
+}
+
+func testExistsByEmail(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	exists, err := repo.ExistsByEmail("exists@example.com")
+	if err != nil {
+		t.Fatalf("ExistsByEmail() error = %v", err)
+	}
+	if exists {
+		t.Error("ExistsByEmail() should be false before Create()")
+	}
+
+	mustCreate(t, repo, "exists@example.com")
+
+	exists, err = repo.ExistsByEmail("exists@example.com")
+	if err != nil {
+		t.Fatalf("ExistsByEmail() error = %v", err)
+	}
+	if !exists {
+		t.Error("ExistsByEmail() should be true after Create()")
+	}
+}
+
+func testNotFound(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	if _, err := repo.GetByEmail("missing@example.com"); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("GetByEmail() of a missing user error = %v, want ErrUserNotFound", err)
+	}
+	if _, err := repo.GetByID(424242); err == nil {
+		t.Error("GetByID() should return error for missing user")
+	}
+}
+
+func testUpdate(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	created := mustCreate(t, repo, "update@example.com")
+
+	created.Email = "updated@example.com"
+	created.FullName = "Updated Name"
+	created.PhoneNumber = "0898765432"
+	created.Birthday = "1985-05-20"
+	created.Role = entity.RoleAdmin
+	created.Status = entity.StatusSuspended
+	if err := repo.Update(created); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	found, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if found.Email != "updated@example.com" || found.FullName != "Updated Name" ||
+		found.PhoneNumber != "0898765432" || found.Birthday != "1985-05-20" {
+		t.Errorf("Update() not persisted, got %+v", found)
+	}
+	if found.Role != entity.RoleAdmin || found.Status != entity.StatusSuspended {
+		t.Errorf("Update() role/status not persisted, got %v/%v", found.Role, found.Status)
+	}
+	if _, err := repo.GetByEmail("update@example.com"); err == nil {
+		t.Error("old email should no longer resolve after Update()")
+	}
+}
+
+func testUpdateDuplicateEmail(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	mustCreate(t, repo, "taken@example.com")
+	other := mustCreate(t, repo, "other@example.com")
+
+	other.Email = "taken@example.com"
+	if err := repo.Update(other); !errors.Is(err, repository.ErrEmailTaken) {
+		t.Errorf("Update() error = %v, want ErrEmailTaken", err)
+	}
+}
+
+func testUpdateDoesNotChangePassword(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	created := mustCreate(t, repo, "keep@example.com")
+
+	created.Password = "changed"
+	if err := repo.Update(created); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	found, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if found.Password != "hashedpassword" {
+		t.Errorf("Update() changed the password to %q", found.Password)
+	}
+}
+
+func testUpdatePassword(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	created := mustCreate(t, repo, "password@example.com")
+	other := mustCreate(t, repo, "bystander@example.com")
+
+	if err := repo.UpdatePassword(created.ID, "newhash"); err != nil {
+		t.Fatalf("UpdatePassword() error = %v", err)
+	}
+
+	found, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if found.Password != "newhash" {
+		t.Errorf("Password = %q, want newhash", found.Password)
+	}
+	if found.Email != created.Email || found.FullName != created.FullName {
+		t.Errorf("UpdatePassword() changed other fields, got %+v", found)
+	}
+
+	untouched, err := repo.GetByID(other.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if untouched.Password != "hashedpassword" {
+		t.Error("UpdatePassword() should only change the target user")
+	}
+
+	// Unknown IDs are a no-op, matching Update
+	if err := repo.UpdatePassword(999999, "newhash"); err != nil {
+		t.Errorf("UpdatePassword() for unknown ID error = %v", err)
+	}
+}
+
+func testUpdateFields(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	created := mustCreate(t, repo, "fields@example.com")
+
+	err := repo.UpdateFields(created.ID, map[string]interface{}{
+		repository.FieldFullName:   "Patched Name",
+		repository.FieldAvatar:     "",
+		repository.FieldTimezone:   "Asia/Bangkok",
+		repository.FieldNationalID: "1101700230708",
+		repository.FieldKYCStatus:  "review",
+	})
+	if err != nil {
+		t.Fatalf("UpdateFields() error = %v", err)
+	}
+
+	found, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if found.FullName != "Patched Name" {
+		t.Errorf("FullName = %v, want Patched Name", found.FullName)
+	}
+	if found.Timezone != "Asia/Bangkok" {
+		t.Errorf("Timezone = %v, want Asia/Bangkok", found.Timezone)
+	}
+	if found.NationalID != "1101700230708" {
+		t.Errorf("NationalID = %v, want 1101700230708", found.NationalID)
+	}
+	if found.KYCStatus != "review" {
+		t.Errorf("KYCStatus = %v, want review", found.KYCStatus)
+	}
+
+	// Fields not in the map are left untouched
+	if found.Email != created.Email || found.PhoneNumber != created.PhoneNumber || found.Birthday != created.Birthday {
+		t.Errorf("UpdateFields() changed unrelated fields, got %+v", found)
+	}
+	if found.Password != created.Password {
+		t.Error("UpdateFields() should not change the password")
+	}
+
+	// An empty patch is a no-op
+	if err := repo.UpdateFields(created.ID, map[string]interface{}{}); err != nil {
+		t.Errorf("UpdateFields() with no fields error = %v", err)
+	}
+}
+
+func testUpdateFieldsDuplicateEmail(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	mustCreate(t, repo, "taken@example.com")
+	other := mustCreate(t, repo, "other@example.com")
+
+	err := repo.UpdateFields(other.ID, map[string]interface{}{repository.FieldEmail: "taken@example.com"})
+	if !errors.Is(err, repository.ErrEmailTaken) {
+		t.Errorf("UpdateFields() error = %v, want ErrEmailTaken", err)
+	}
+}
+
+func testUpdateFieldsUnknownField(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	created := mustCreate(t, repo, "unknown@example.com")
+
+	err := repo.UpdateFields(created.ID, map[string]interface{}{
+		repository.FieldFullName: "Should Not Persist",
+		"password":               "plaintext",
+	})
+	if err == nil {
+		t.Fatal("UpdateFields() should reject unknown fields")
+	}
+
+	found, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if found.FullName != created.FullName || found.Password != created.Password {
+		t.Errorf("rejected UpdateFields() should not modify the user, got %+v", found)
+	}
+}
+
+func testDelete(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	created := mustCreate(t, repo, "delete@example.com")
+
+	if err := repo.Delete(created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.GetByID(created.ID); err == nil {
+		t.Error("GetByID() should return error after Delete()")
+	}
+	if _, err := repo.GetByEmail("delete@example.com"); err == nil {
+		t.Error("GetByEmail() should return error after Delete()")
+	}
+
+	// The email can be reused once the user is gone
+	mustCreate(t, repo, "delete@example.com")
+}
+
+func testUnicodeEmail(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	emails := []string{"ผู้ใช้@ตัวอย่าง.ไทย", "用户@例子.广告", "josé.ñúñez@example.com"}
+
+	for _, email := range emails {
+		created := mustCreate(t, repo, email)
+		found, err := repo.GetByEmail(email)
+		if err != nil {
+			t.Fatalf("GetByEmail(%s) error = %v", email, err)
+		}
+		if found.ID != created.ID || found.Email != email {
+			t.Errorf("GetByEmail(%s) = %+v, want ID %v", email, found, created.ID)
+		}
+	}
+}
+
+func testReturnedUsersAreCopies(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	created := mustCreate(t, repo, "copy@example.com")
+
+	found, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	found.FullName = "Mutated"
+
+	again, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if again.FullName == "Mutated" {
+		t.Error("mutating a returned user must not change stored data without Update()")
+	}
+}
+
+func testConcurrentCreates(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	const workers = 20
+
+	var wg sync.WaitGroup
+	ids := make(chan int, workers)
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user, err := repo.Create(NewUser(fmt.Sprintf("concurrent-%d@example.com", i)))
+			if err != nil {
+				errs <- err
+				return
+			}
+			ids <- user.ID
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent Create() error = %v", err)
+	}
+	seen := make(map[int]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("concurrent Create() assigned duplicate ID %v", id)
+		}
+		seen[id] = true
+	}
+}
+
+func testConcurrentDuplicateCreates(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	const workers = 10
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Create(NewUser("race@example.com"))
+			if err != nil && !errors.Is(err, repository.ErrEmailTaken) {
+				t.Errorf("concurrent duplicate Create() error = %v, want ErrEmailTaken", err)
+			}
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("concurrent Create() of the same email succeeded %d times, want exactly 1", succeeded)
+	}
+}
+
+func testDefaultRoleAndStatus(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	user := NewUser("defaults@example.com")
+	user.Role = ""
+	user.Status = ""
+	user.Plan = ""
+	created, err := repo.Create(user)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	found, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if found.Role != entity.RoleUser || found.Status != entity.StatusActive {
+		t.Errorf("defaults = %v/%v, want %v/%v", found.Role, found.Status, entity.RoleUser, entity.StatusActive)
+	}
+	if found.Plan != entity.PlanFree {
+		t.Errorf("default plan = %v, want %v", found.Plan, entity.PlanFree)
+	}
+}
+
+func testCreateSetsTimestamps(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	user := NewUser("stamped@example.com")
+	user.CreatedAt = time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC)
+	user.UpdatedAt = time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC)
+	created, err := repo.Create(user)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Caller-supplied timestamps are ignored in favour of the repository clock
+	want := clock.Now()
+	if !created.CreatedAt.Equal(want) || !created.UpdatedAt.Equal(want) {
+		t.Errorf("Create() timestamps = %v/%v, want %v", created.CreatedAt, created.UpdatedAt, want)
+	}
+
+	found, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if !found.CreatedAt.Equal(want) || !found.UpdatedAt.Equal(want) {
+		t.Errorf("stored timestamps = %v/%v, want %v", found.CreatedAt, found.UpdatedAt, want)
+	}
+}
+
+func testUpdatesBumpUpdatedAt(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	created := mustCreate(t, repo, "bump@example.com")
+	createdAt := clock.Now()
+
+	updates := []struct {
+		name  string
+		apply func() error
+	}{
+		{"Update", func() error {
+			created.FullName = "Bumped"
+			return repo.Update(created)
+		}},
+		{"UpdateFields", func() error {
+			return repo.UpdateFields(created.ID, map[string]interface{}{repository.FieldFullName: "Bumped Again"})
+		}},
+		{"UpdatePassword", func() error {
+			return repo.UpdatePassword(created.ID, "newhash")
+		}},
+	}
+
+	for _, u := range updates {
+		clock.Advance(time.Minute)
+		if err := u.apply(); err != nil {
+			t.Fatalf("%s() error = %v", u.name, err)
+		}
+
+		found, err := repo.GetByID(created.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if !found.UpdatedAt.Equal(clock.Now()) {
+			t.Errorf("%s() UpdatedAt = %v, want %v", u.name, found.UpdatedAt, clock.Now())
+		}
+		if !found.CreatedAt.Equal(createdAt) {
+			t.Errorf("%s() changed CreatedAt to %v", u.name, found.CreatedAt)
+		}
+	}
+}
+
+func testWritesIncrementVersion(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	created := mustCreate(t, repo, "version@example.com")
+	if created.Version != 1 {
+		t.Fatalf("Create() Version = %v, want 1", created.Version)
+	}
+
+	writes := []struct {
+		name  string
+		apply func() error
+	}{
+		{"Update", func() error {
+			created.FullName = "Versioned"
+			return repo.Update(created)
+		}},
+		{"UpdateFields", func() error {
+			return repo.UpdateFields(created.ID, map[string]interface{}{repository.FieldFullName: "Versioned Again"})
+		}},
+		{"UpdatePassword", func() error {
+			return repo.UpdatePassword(created.ID, "newhash")
+		}},
+	}
+
+	for i, w := range writes {
+		if err := w.apply(); err != nil {
+			t.Fatalf("%s() error = %v", w.name, err)
+		}
+
+		found, err := repo.GetByID(created.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if want := i + 2; found.Version != want {
+			t.Errorf("%s() Version = %v, want %v", w.name, found.Version, want)
+		}
+	}
+
+	// Update reports the new version back to the caller
+	if created.Version != 2 {
+		t.Errorf("Update() left caller Version = %v, want 2", created.Version)
+	}
+}
+
+func testListFilters(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fixtures := []struct {
+		email    string
+		fullName string
+		role     string
+		status   string
+		created  time.Time
+	}{
+		{"alice@example.com", "Alice Smith", entity.RoleAdmin, entity.StatusActive, base},
+		{"bob@example.com", "Bob Jones", entity.RoleUser, entity.StatusActive, base.AddDate(0, 0, 1)},
+		{"carol@example.com", "Carol Smith", entity.RoleUser, entity.StatusSuspended, base.AddDate(0, 0, 2)},
+		{"dave_100%@example.com", "Dave", entity.RoleUser, entity.StatusActive, base.AddDate(0, 0, 3)},
+	}
+	for _, f := range fixtures {
+		user := NewUser(f.email)
+		user.FullName = f.fullName
+		user.Role = f.role
+		user.Status = f.status
+		clock.Set(f.created)
+		if _, err := repo.Create(user); err != nil {
+			t.Fatalf("Create(%s) error = %v", f.email, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter repository.UserFilter
+		want   []string
+	}{
+		{"no filter", repository.UserFilter{}, []string{"alice@example.com", "bob@example.com", "carol@example.com", "dave_100%@example.com"}},
+		{"role", repository.UserFilter{Role: entity.RoleAdmin}, []string{"alice@example.com"}},
+		{"status", repository.UserFilter{Status: entity.StatusSuspended}, []string{"carol@example.com"}},
+		{"excluded status", repository.UserFilter{ExcludeStatus: entity.StatusActive}, []string{"carol@example.com"}},
+		{"created range", repository.UserFilter{CreatedFrom: base.AddDate(0, 0, 1), CreatedTo: base.AddDate(0, 0, 3)}, []string{"bob@example.com", "carol@example.com"}},
+		{"search name case-insensitive", repository.UserFilter{Search: "SMITH"}, []string{"alice@example.com", "carol@example.com"}},
+		{"search email", repository.UserFilter{Search: "bob@"}, []string{"bob@example.com"}},
+		{"search wildcards are literal", repository.UserFilter{Search: "_100%"}, []string{"dave_100%@example.com"}},
+		{"combined", repository.UserFilter{Role: entity.RoleUser, Status: entity.StatusActive, Search: "example"}, []string{"bob@example.com", "dave_100%@example.com"}},
+		{"no match", repository.UserFilter{Search: "nobody"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := repo.List(tt.filter, repository.Page{})
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(users) != len(tt.want) {
+				t.Fatalf("List() returned %d users, want %d", len(users), len(tt.want))
+			}
+			for i, email := range tt.want {
+				if users[i].Email != email {
+					t.Errorf("List()[%d].Email = %v, want %v", i, users[i].Email, email)
+				}
+			}
+
+			count, err := repo.Count(tt.filter)
+			if err != nil {
+				t.Fatalf("Count() error = %v", err)
+			}
+			if count != len(tt.want) {
+				t.Errorf("Count() = %d, want %d", count, len(tt.want))
+			}
+		})
+	}
+}
+
+func testListPagination(t *testing.T, repo repository.UserRepository, clock *Clock) {
+	for i := 0; i < 5; i++ {
+		mustCreate(t, repo, fmt.Sprintf("page-%d@example.com", i))
+	}
+
+	first, err := repo.List(repository.UserFilter{}, repository.Page{Limit: 2})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	last, err := repo.List(repository.UserFilter{}, repository.Page{Limit: 2, Offset: 4})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	beyond, err := repo.List(repository.UserFilter{}, repository.Page{Limit: 2, Offset: 10})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(first) != 2 || first[0].Email != "page-0@example.com" || first[1].Email != "page-1@example.com" {
+		t.Errorf("first page = %v, want page-0 and page-1", emails(first))
+	}
+	if len(last) != 1 || last[0].Email != "page-4@example.com" {
+		t.Errorf("last page = %v, want page-4", emails(last))
+	}
+	if beyond == nil || len(beyond) != 0 {
+		t.Errorf("page beyond results = %v, want empty non-nil slice", beyond)
+	}
+}
+
+func emails(users []*entity.User) []string {
+	result := make([]string, len(users))
+	for i, user := range users {
+		result[i] = user.Email
+	}
+	return result
+}
+
+func assertSameUser(t *testing.T, got, want *entity.User) {
+	t.Helper()
+	if got.ID != want.ID {
+		t.Errorf("ID = %v, want %v", got.ID, want.ID)
+	}
+	if got.Email != want.Email {
+		t.Errorf("Email = %v, want %v", got.Email, want.Email)
+	}
+	if got.Password != want.Password {
+		t.Errorf("Password = %v, want %v", got.Password, want.Password)
+	}
+	if got.FullName != want.FullName {
+		t.Errorf("FullName = %v, want %v", got.FullName, want.FullName)
+	}
+	if got.PhoneNumber != want.PhoneNumber {
+		t.Errorf("PhoneNumber = %v, want %v", got.PhoneNumber, want.PhoneNumber)
+	}
+	if got.Birthday != want.Birthday {
+		t.Errorf("Birthday = %v, want %v", got.Birthday, want.Birthday)
+	}
+	if got.Role != want.Role {
+		t.Errorf("Role = %v, want %v", got.Role, want.Role)
+	}
+	if got.Status != want.Status {
+		t.Errorf("Status = %v, want %v", got.Status, want.Status)
+	}
+	if got.Plan != want.Plan {
+		t.Errorf("Plan = %v, want %v", got.Plan, want.Plan)
+	}
+	if got.Timezone != want.Timezone {
+		t.Errorf("Timezone = %v, want %v", got.Timezone, want.Timezone)
+	}
+	if got.NationalID != want.NationalID {
+		t.Errorf("NationalID = %v, want %v", got.NationalID, want.NationalID)
+	}
+	if got.KYCStatus != want.KYCStatus {
+		t.Errorf("KYCStatus = %v, want %v", got.KYCStatus, want.KYCStatus)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("UpdatedAt = %v, want %v", got.UpdatedAt, want.UpdatedAt)
+	}
+}
@@ -0,0 +1,32 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// SchemaChangeRepository defines the interface for the online schema
+// changes of large tables, which backfill their new column or table in
+// batches and track how far they got
+type SchemaChangeRepository interface {
+	// List returns every change that has started, by name
+	List() ([]*entity.SchemaChange, error)
+
+	// Start records a change as backfilling the rows its table holds now,
+	// unless it has started already, and returns it. Returns
+	// ErrSchemaChangeNotFound for a change that is not defined.
+	Start(name string) (*entity.SchemaChange, error)
+
+	// Backfill copies the next batch of up to limit rows of a backfilling
+	// change and saves its progress in the same transaction. Once no rows
+	// are left the change is backfilled. Returns the change and the number
+	// of rows copied.
+	Backfill(name string, limit int) (*entity.SchemaChange, int, error)
+
+	// SetPhase moves a started change to phase. Returns
+	// ErrSchemaChangeNotFound before the change starts.
+	SetPhase(name, phase string) (*entity.SchemaChange, error)
+}
+
+// SchemaReads tells repositories between the backfill and the contraction
+// of a schema change whether to read its new column or table
+type SchemaReads interface {
+	ReadsNew(name string) bool
+}
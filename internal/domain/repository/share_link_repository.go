@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// ShareLinkRepository defines the interface for stored profile share links
+// and their access log
+type ShareLinkRepository interface {
+	// Create stores a link and sets its ID and CreatedAt
+	Create(link *entity.ShareLink) error
+
+	// GetByID returns a link. Returns ErrShareLinkNotFound.
+	GetByID(id int) (*entity.ShareLink, error)
+
+	// ListByUser returns the user's links, newest first
+	ListByUser(userID int) ([]*entity.ShareLink, error)
+
+	// Revoke revokes the user's link at now. Returns ErrShareLinkNotFound
+	// for links of other users; revoking twice keeps the first time.
+	Revoke(userID, id int, now time.Time) error
+
+	// View counts an open of the link with the given token hash and returns
+	// it. Only links that are active at now are counted; for the others the
+	// link is returned with ErrShareLinkUnavailable. Returns
+	// ErrShareLinkNotFound.
+	View(tokenHash string, now time.Time) (*entity.ShareLink, error)
+
+	// RecordAccess stores an access and sets its ID
+	RecordAccess(access *entity.ShareLinkAccess) error
+
+	// ListAccesses returns the accesses of a link, newest first
+	ListAccesses(linkID int) ([]*entity.ShareLinkAccess, error)
+}
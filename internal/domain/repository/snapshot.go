@@ -0,0 +1,15 @@
+package repository
+
+// SnapshotSource opens point-in-time views of the data, so long reads such
+// as exports see one consistent state while writes continue
+type SnapshotSource interface {
+	// Snapshot captures the current data; Close the snapshot when done
+	Snapshot() (Snapshot, error)
+}
+
+// Snapshot is a read-only view of the data at one point in time
+type Snapshot interface {
+	Users() UserRepository
+	Revisions() UserRevisionRepository
+	Close() error
+}
@@ -0,0 +1,46 @@
+package repository
+
+import "time"
+
+// UserFilter narrows the users returned by List and Count.
+// Zero values leave the corresponding criterion unrestricted.
+type UserFilter struct {
+	// CreatedFrom includes users created at or after this time
+	CreatedFrom time.Time
+	// CreatedTo includes users created before this time
+	CreatedTo time.Time
+	// Status matches the account status exactly
+	Status string
+	// ExcludeStatus leaves out users with this account status
+	ExcludeStatus string
+	// Role matches the user role exactly
+	Role string
+	// Search matches a case-insensitive substring of the email or full name
+	Search string
+}
+
+// Page selects a window of results ordered by ascending ID
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// DefaultPageLimit is used when a page has no limit set
+const DefaultPageLimit = 50
+
+// MaxPageLimit caps the number of results in a single page
+const MaxPageLimit = 500
+
+// Normalize returns the page with limits clamped to sane bounds
+func (p Page) Normalize() Page {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+	return p
+}
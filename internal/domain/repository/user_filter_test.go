@@ -0,0 +1,24 @@
+package repository
+
+import "testing"
+
+func TestPage_Normalize(t *testing.T) {
+	tests := []struct {
+		name string
+		page Page
+		want Page
+	}{
+		{"zero value uses default limit", Page{}, Page{Limit: DefaultPageLimit}},
+		{"limit capped", Page{Limit: MaxPageLimit + 1, Offset: 10}, Page{Limit: MaxPageLimit, Offset: 10}},
+		{"negative offset reset", Page{Limit: 5, Offset: -3}, Page{Limit: 5}},
+		{"valid page unchanged", Page{Limit: 20, Offset: 40}, Page{Limit: 20, Offset: 40}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.page.Normalize(); got != tt.want {
+				t.Errorf("Normalize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,18 +4,53 @@ import "fiber-hello-world/internal/domain/entity"
 
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
-	// Create saves a new user and returns the created user with ID
+	// Create saves a new user and returns the created user with ID.
+	// Returns ErrEmailTaken if the email is already in use.
 	Create(user *entity.User) (*entity.User, error)
 
-	// GetByEmail retrieves a user by email
+	// GetByEmail retrieves a user by email.
+	// Returns ErrUserNotFound if no user has the email.
 	GetByEmail(email string) (*entity.User, error)
 
+	// ExistsByEmail reports whether a user with the email exists
+	ExistsByEmail(email string) (bool, error)
+
 	// GetByID retrieves a user by ID
 	GetByID(id int) (*entity.User, error)
 
-	// Update updates user information
+	// Update updates user information. It never writes the password;
+	// use UpdatePassword for that.
+	// Returns ErrEmailTaken if the new email belongs to another user.
 	Update(user *entity.User) error
 
+	// UpdatePassword replaces the stored password hash
+	UpdatePassword(id int, hash string) error
+
+	// UpdateFields updates only the given fields (keyed by the Field* constants).
+	// Returns ErrEmailTaken if the new email belongs to another user.
+	UpdateFields(id int, fields map[string]interface{}) error
+
 	// Delete removes a user by ID
 	Delete(id int) error
+
+	// List returns users matching the filter, ordered by ID, within the page
+	List(filter UserFilter, page Page) ([]*entity.User, error)
+
+	// Count returns the number of users matching the filter
+	Count(filter UserFilter) (int, error)
 }
+
+// Field names accepted by UserRepository.UpdateFields
+const (
+	FieldEmail       = "email"
+	FieldFullName    = "fullName"
+	FieldPhoneNumber = "phoneNumber"
+	FieldBirthday    = "birthday"
+	FieldAvatar      = "avatar"
+	FieldRole        = "role"
+	FieldStatus      = "status"
+	FieldPlan        = "plan"
+	FieldTimezone    = "timezone"
+	FieldNationalID  = "nationalId"
+	FieldKYCStatus   = "kycStatus"
+)
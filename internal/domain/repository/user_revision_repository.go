@@ -0,0 +1,15 @@
+package repository
+
+import "fiber-hello-world/internal/domain/entity"
+
+// UserRevisionRepository defines the interface for user revision history
+type UserRevisionRepository interface {
+	// Record saves a revision and sets its ID and CreatedAt
+	Record(revision *entity.UserRevision) error
+
+	// ListByUser returns a user's revisions, newest first, within the page
+	ListByUser(userID int, page Page) ([]*entity.UserRevision, error)
+
+	// List returns every user's revisions, oldest first, within the page
+	List(page Page) ([]*entity.UserRevision, error)
+}
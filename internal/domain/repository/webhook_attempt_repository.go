@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// WebhookAttemptRepository defines the interface for the delivery log of
+// webhook requests
+type WebhookAttemptRepository interface {
+	// Create stores an attempt and sets its ID
+	Create(attempt *entity.WebhookAttempt) error
+
+	// ListByPoint returns every attempt at the limit events most recently
+	// sent to the webhook at point, or only at eventID when it is set,
+	// oldest first
+	ListByPoint(point, eventID string, limit int) ([]*entity.WebhookAttempt, error)
+
+	// DeleteBefore removes the attempts made before the given time
+	DeleteBefore(before time.Time) (int, error)
+}
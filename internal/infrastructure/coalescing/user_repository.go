@@ -0,0 +1,66 @@
+// Package coalescing serves identical concurrent reads of a repository with
+// one query, e.g. a burst of GET /me calls by one user.
+package coalescing
+
+import (
+	"strconv"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/coalesce"
+)
+
+// UserRepository coalesces concurrent GetByID calls for the same user into
+// one read of another UserRepository. Writes to a user make later calls
+// read again rather than join a read that started before the write.
+type UserRepository struct {
+	repository.UserRepository
+	reads *coalesce.Group
+}
+
+// NewUserRepository wraps users, coalescing reads in reads
+func NewUserRepository(users repository.UserRepository, reads *coalesce.Group) *UserRepository {
+	return &UserRepository{UserRepository: users, reads: reads}
+}
+
+// userKey names the reads of a user by ID
+func userKey(id int) string {
+	return "user:" + strconv.Itoa(id)
+}
+
+// GetByID retrieves a user by ID, sharing a read in flight for the same
+// user. Each caller gets its own copy, as callers change the users they read.
+func (r *UserRepository) GetByID(id int) (*entity.User, error) {
+	value, err := r.reads.Do(userKey(id), func() (interface{}, error) {
+		return r.UserRepository.GetByID(id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	user := *value.(*entity.User)
+	return &user, nil
+}
+
+// Update updates user information
+func (r *UserRepository) Update(user *entity.User) error {
+	defer r.reads.Forget(userKey(user.ID))
+	return r.UserRepository.Update(user)
+}
+
+// UpdatePassword replaces the stored password hash
+func (r *UserRepository) UpdatePassword(id int, hash string) error {
+	defer r.reads.Forget(userKey(id))
+	return r.UserRepository.UpdatePassword(id, hash)
+}
+
+// UpdateFields updates only the given fields
+func (r *UserRepository) UpdateFields(id int, fields map[string]interface{}) error {
+	defer r.reads.Forget(userKey(id))
+	return r.UserRepository.UpdateFields(id, fields)
+}
+
+// Delete removes a user by ID
+func (r *UserRepository) Delete(id int) error {
+	defer r.reads.Forget(userKey(id))
+	return r.UserRepository.Delete(id)
+}
@@ -0,0 +1,87 @@
+package coalescing
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/domain/repository/repositorytest"
+	"fiber-hello-world/internal/infrastructure/memory"
+	"fiber-hello-world/pkg/coalesce"
+)
+
+func TestUserRepository_Conformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T, now func() time.Time) repository.UserRepository {
+		return NewUserRepository(memory.NewUserRepositoryWithClock(now), coalesce.New())
+	})
+}
+
+// blockingRepository holds GetByID calls until released and counts them
+type blockingRepository struct {
+	repository.UserRepository
+	release chan struct{}
+
+	mu    sync.Mutex
+	reads int
+}
+
+func (r *blockingRepository) GetByID(id int) (*entity.User, error) {
+	r.mu.Lock()
+	r.reads++
+	r.mu.Unlock()
+	<-r.release
+	return r.UserRepository.GetByID(id)
+}
+
+func TestUserRepository_CoalescesGetByID(t *testing.T) {
+	inner := &blockingRepository{UserRepository: memory.NewUserRepository(), release: make(chan struct{})}
+	created, err := inner.Create(repositorytest.NewUser("burst@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reads := coalesce.New()
+	users := NewUserRepository(inner, reads)
+
+	const callers = 5
+	var wg sync.WaitGroup
+	got := make([]*entity.User, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[i], _ = users.GetByID(created.ID)
+		}()
+	}
+	for reads.Stats().Calls < callers {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+
+	if inner.reads != 1 {
+		t.Errorf("reads of the inner repository = %d, want 1", inner.reads)
+	}
+	for i, user := range got {
+		if user == nil || user.Email != "burst@example.com" {
+			t.Fatalf("caller %d got %+v", i, user)
+		}
+	}
+	// Callers get their own copies
+	got[0].FullName = "Changed"
+	if got[1].FullName == "Changed" {
+		t.Error("callers share the same user")
+	}
+
+	if err := users.UpdateFields(created.ID, map[string]interface{}{repository.FieldFullName: "Updated"}); err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := users.GetByID(created.ID); user.FullName != "Updated" {
+		t.Errorf("GetByID() after a write = %q, want the update", user.FullName)
+	}
+	if _, err := users.GetByID(999); err == nil {
+		t.Error("GetByID() of a missing user should fail")
+	}
+}
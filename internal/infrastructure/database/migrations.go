@@ -0,0 +1,313 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Migration is a versioned schema change applied once per database
+type Migration struct {
+	Version     int
+	Description string
+	Query       string
+}
+
+// migrations lists all schema changes in the order they must be applied.
+// Never edit an applied migration; append a new one instead.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create users table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS users (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			email TEXT UNIQUE NOT NULL,
+			password TEXT NOT NULL,
+			full_name TEXT NOT NULL,
+			phone_number TEXT NOT NULL,
+			birthday TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+	},
+	{
+		Version:     2,
+		Description: "create funnel events table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS funnel_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			stage TEXT NOT NULL,
+			user_id INTEGER,
+			day TEXT NOT NULL,
+			occurred_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_funnel_events_day ON funnel_events(day);
+		CREATE INDEX IF NOT EXISTS idx_funnel_events_user_stage ON funnel_events(user_id, stage);`,
+	},
+	{
+		Version:     3,
+		Description: "add avatar to users",
+		Query:       `ALTER TABLE users ADD COLUMN avatar TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		Version:     4,
+		Description: "add role and status to users",
+		Query: `
+		ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user';
+		ALTER TABLE users ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+		CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
+		CREATE INDEX IF NOT EXISTS idx_users_status ON users(status);
+		CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);`,
+	},
+	{
+		Version:     5,
+		Description: "add updated_at to users",
+		Query: `
+		ALTER TABLE users ADD COLUMN updated_at DATETIME;
+		UPDATE users SET updated_at = COALESCE(created_at, CURRENT_TIMESTAMP);
+		CREATE INDEX IF NOT EXISTS idx_users_updated_at ON users(updated_at);`,
+	},
+	{
+		Version:     6,
+		Description: "create user revisions table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS user_revisions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			actor_id INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			snapshot TEXT NOT NULL,
+			changes TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_user_revisions_user ON user_revisions(user_id, id);`,
+	},
+	{
+		Version:     7,
+		Description: "create admin actions table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS admin_actions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			token TEXT UNIQUE NOT NULL,
+			kind TEXT NOT NULL,
+			actor_id INTEGER NOT NULL,
+			user_ids TEXT NOT NULL,
+			role TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			execute_at DATETIME NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_admin_actions_due ON admin_actions(status, execute_at);`,
+	},
+	{
+		Version:     8,
+		Description: "add version to users",
+		Query:       `ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;`,
+	},
+	{
+		Version:     9,
+		Description: "create one-time tokens table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS one_time_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			purpose TEXT NOT NULL,
+			user_id INTEGER NOT NULL,
+			token_hash TEXT UNIQUE NOT NULL,
+			expires_at DATETIME NOT NULL,
+			consumed_at DATETIME,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_one_time_tokens_user ON one_time_tokens(user_id, purpose);`,
+	},
+	{
+		Version:     10,
+		Description: "create login events table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS login_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			success BOOLEAN NOT NULL,
+			ip TEXT NOT NULL,
+			device TEXT NOT NULL,
+			country TEXT NOT NULL DEFAULT '',
+			new_device BOOLEAN NOT NULL DEFAULT 0,
+			new_country BOOLEAN NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_login_events_user ON login_events(user_id, created_at);`,
+	},
+	{
+		Version:     11,
+		Description: "add incident notices and progress to admin actions",
+		Query: `
+		ALTER TABLE admin_actions ADD COLUMN subject TEXT NOT NULL DEFAULT '';
+		ALTER TABLE admin_actions ADD COLUMN message TEXT NOT NULL DEFAULT '';
+		ALTER TABLE admin_actions ADD COLUMN processed INTEGER NOT NULL DEFAULT 0;
+		CREATE INDEX IF NOT EXISTS idx_login_events_created ON login_events(created_at);`,
+	},
+	{
+		Version:     12,
+		Description: "create domain events table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS domain_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			schema_version INTEGER NOT NULL,
+			subject_type TEXT NOT NULL,
+			subject_id INTEGER NOT NULL,
+			actor_id INTEGER NOT NULL DEFAULT 0,
+			data TEXT NOT NULL DEFAULT '{}',
+			occurred_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_domain_events_type ON domain_events(type, id);
+		CREATE INDEX IF NOT EXISTS idx_domain_events_occurred ON domain_events(occurred_at);`,
+	},
+	{
+		Version:     13,
+		Description: "create leases table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS leases (
+			name TEXT PRIMARY KEY,
+			owner TEXT NOT NULL,
+			expires_at DATETIME NOT NULL
+		);`,
+	},
+	{
+		Version:     14,
+		Description: "add queue controls to admin actions",
+		Query: `
+		ALTER TABLE admin_actions ADD COLUMN failed_user_ids TEXT NOT NULL DEFAULT '[]';
+		ALTER TABLE admin_actions ADD COLUMN finished_at DATETIME;
+		CREATE INDEX IF NOT EXISTS idx_admin_actions_finished ON admin_actions(finished_at);
+		CREATE TABLE IF NOT EXISTS paused_queues (
+			name TEXT PRIMARY KEY,
+			paused_at DATETIME NOT NULL
+		);`,
+	},
+	{
+		Version:     15,
+		Description: "add plan to users",
+		Query:       `ALTER TABLE users ADD COLUMN plan TEXT NOT NULL DEFAULT 'free';`,
+	},
+	{
+		Version:     16,
+		Description: "add timezone to users",
+		Query:       `ALTER TABLE users ADD COLUMN timezone TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		Version:     17,
+		Description: "add approvals to admin actions",
+		Query: `
+		ALTER TABLE admin_actions ADD COLUMN reviewed_by INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE admin_actions ADD COLUMN reviewed_at DATETIME;`,
+	},
+	{
+		Version:     18,
+		Description: "create admin audit table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS admin_audit (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			actor_id INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			subject_type TEXT NOT NULL,
+			subject_id TEXT NOT NULL,
+			changes TEXT NOT NULL DEFAULT '[]',
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_admin_audit_subject ON admin_audit(subject_type, subject_id);
+		CREATE INDEX IF NOT EXISTS idx_admin_audit_actor ON admin_audit(actor_id);`,
+	},
+	{
+		Version:     19,
+		Description: "add national id to users",
+		Query:       `ALTER TABLE users ADD COLUMN national_id TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		Version:     20,
+		Description: "add kyc status to users",
+		Query:       `ALTER TABLE users ADD COLUMN kyc_status TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		Version:     21,
+		Description: "record each funnel stage once per user",
+		Query: `
+		DELETE FROM funnel_events WHERE user_id IS NOT NULL AND id NOT IN (
+			SELECT MIN(id) FROM funnel_events WHERE user_id IS NOT NULL GROUP BY user_id, stage
+		);
+		DROP INDEX IF EXISTS idx_funnel_events_user_stage;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_funnel_events_user_stage ON funnel_events(user_id, stage);`,
+	},
+}
+
+// Migrate applies all pending migrations and records them in schema_migrations
+func Migrate(db *sql.DB) error {
+	_, err := db.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`)
+	if err != nil {
+		return err
+	}
+
+	return applyMigrations(db, migrations,
+		`SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = ?)`,
+		`INSERT INTO schema_migrations (version, description) VALUES (?, ?)`)
+}
+
+// MigrateModule applies the pending migrations of a server module. Versions
+// are numbered per module and recorded in module_migrations, so modules can
+// be added or removed without affecting each other's schema history.
+func MigrateModule(db *sql.DB, module string, moduleMigrations []Migration) error {
+	_, err := db.Exec(`
+	CREATE TABLE IF NOT EXISTS module_migrations (
+		module TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		description TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (module, version)
+	);`)
+	if err != nil {
+		return err
+	}
+
+	return applyMigrations(db, moduleMigrations,
+		`SELECT EXISTS(SELECT 1 FROM module_migrations WHERE module = ? AND version = ?)`,
+		`INSERT INTO module_migrations (module, version, description) VALUES (?, ?, ?)`,
+		module)
+}
+
+// applyMigrations runs each migration not yet recorded in its own
+// transaction. appliedQuery and recordQuery take the key arguments followed
+// by the version (and description when recording).
+func applyMigrations(db *sql.DB, pending []Migration, appliedQuery, recordQuery string, key ...interface{}) error {
+	for _, m := range pending {
+		var applied bool
+		err := db.QueryRow(appliedQuery, append(key, m.Version)...).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(m.Query); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		if _, err := tx.Exec(recordQuery, append(key, m.Version, m.Description)...); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
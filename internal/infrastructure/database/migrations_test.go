@@ -0,0 +1,115 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMigrate_BackfillsUpdatedAt(t *testing.T) {
+	t.Parallel()
+	db := openTestDB(t)
+
+	// Bring the schema up to the version before updated_at existed
+	if _, err := db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, description TEXT NOT NULL, applied_at DATETIME DEFAULT CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatalf("Failed to create schema_migrations: %v", err)
+	}
+	for _, m := range migrations[:4] {
+		if _, err := db.Exec(m.Query); err != nil {
+			t.Fatalf("migration %d error = %v", m.Version, err)
+		}
+		if _, err := db.Exec(`INSERT INTO schema_migrations (version, description) VALUES (?, ?)`, m.Version, m.Description); err != nil {
+			t.Fatalf("Failed to record migration %d: %v", m.Version, err)
+		}
+	}
+
+	createdAt := time.Date(2023, 3, 4, 5, 6, 7, 0, time.UTC)
+	_, err := db.Exec(`INSERT INTO users (email, password, full_name, phone_number, birthday, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		"legacy@example.com", "hash", "Legacy User", "0812345678", "1990-01-15", createdAt)
+	if err != nil {
+		t.Fatalf("Failed to insert legacy user: %v", err)
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	user, err := NewSQLiteUserRepository(db).GetByEmail("legacy@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail() error = %v", err)
+	}
+	if !user.UpdatedAt.Equal(createdAt) {
+		t.Errorf("UpdatedAt = %v, want backfilled %v", user.UpdatedAt, createdAt)
+	}
+}
+
+func TestMigrate_DedupesFunnelEvents(t *testing.T) {
+	t.Parallel()
+	db := openTestDB(t)
+
+	// Bring the schema up to the version before funnel stages were unique
+	if _, err := db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, description TEXT NOT NULL, applied_at DATETIME DEFAULT CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatalf("Failed to create schema_migrations: %v", err)
+	}
+	for _, m := range migrations[:20] {
+		if _, err := db.Exec(m.Query); err != nil {
+			t.Fatalf("migration %d error = %v", m.Version, err)
+		}
+		if _, err := db.Exec(`INSERT INTO schema_migrations (version, description) VALUES (?, ?)`, m.Version, m.Description); err != nil {
+			t.Fatalf("Failed to record migration %d: %v", m.Version, err)
+		}
+	}
+	for _, userID := range []interface{}{7, 7, nil, nil} {
+		if _, err := db.Exec(`INSERT INTO funnel_events (stage, user_id, day) VALUES ('first_login', ?, '2024-05-01')`, userID); err != nil {
+			t.Fatalf("Failed to insert funnel event: %v", err)
+		}
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	var users, anonymous int
+	db.QueryRow(`SELECT COUNT(*) FROM funnel_events WHERE user_id = 7`).Scan(&users)
+	db.QueryRow(`SELECT COUNT(*) FROM funnel_events WHERE user_id IS NULL`).Scan(&anonymous)
+	if users != 1 || anonymous != 2 {
+		t.Errorf("funnel events after Migrate() = %d of user 7 and %d anonymous, want 1 and 2", users, anonymous)
+	}
+}
+
+func TestMigrateModule(t *testing.T) {
+	t.Parallel()
+	db := openTestDB(t)
+
+	notes := []Migration{
+		{Version: 1, Description: "create notes table", Query: `CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT NOT NULL);`},
+	}
+	if err := MigrateModule(db, "notes", notes); err != nil {
+		t.Fatalf("MigrateModule() error = %v", err)
+	}
+	// Applied versions are skipped, so running again does not recreate the table
+	if err := MigrateModule(db, "notes", notes); err != nil {
+		t.Fatalf("MigrateModule() second run error = %v", err)
+	}
+
+	// Versions are numbered per module
+	tags := []Migration{
+		{Version: 1, Description: "create tags table", Query: `CREATE TABLE tags (id INTEGER PRIMARY KEY);`},
+	}
+	if err := MigrateModule(db, "tags", tags); err != nil {
+		t.Fatalf("MigrateModule() for a second module error = %v", err)
+	}
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('notes', 'tags')`).Scan(&tables); err != nil || tables != 2 {
+		t.Errorf("module tables = %d, %v; want 2", tables, err)
+	}
+
+	notes = append(notes, Migration{Version: 2, Description: "broken", Query: `ALTER TABLE missing ADD COLUMN x TEXT;`})
+	if err := MigrateModule(db, "notes", notes); err == nil {
+		t.Error("MigrateModule() should fail when a migration fails")
+	}
+	var applied bool
+	db.QueryRow(`SELECT EXISTS(SELECT 1 FROM module_migrations WHERE module = 'notes' AND version = 2)`).Scan(&applied)
+	if applied {
+		t.Error("a failed migration must not be recorded")
+	}
+}
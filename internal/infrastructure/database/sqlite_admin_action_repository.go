@@ -0,0 +1,336 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// adminActionColumns lists the admin_actions columns in the order scanAdminAction expects them
+const adminActionColumns = `id, token, kind, actor_id, user_ids, role, subject, message, status, error, processed, execute_at, created_at, failed_user_ids, finished_at, reviewed_by, reviewed_at`
+
+// adminActionQueue names the admin action queue in paused_queues
+const adminActionQueue = "admin_actions"
+
+// scanAdminAction scans a row selected with adminActionColumns into an admin action
+func scanAdminAction(row rowScanner) (*entity.AdminAction, error) {
+	var action entity.AdminAction
+	var kind, status, userIDs, failedUserIDs string
+	var finishedAt, reviewedAt sql.NullTime
+	err := row.Scan(&action.ID, &action.Token, &kind, &action.ActorID, &userIDs, &action.Role, &action.Subject, &action.Message, &status, &action.Error, &action.Processed, &action.ExecuteAt, &action.CreatedAt, &failedUserIDs, &finishedAt, &action.ReviewedBy, &reviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	if finishedAt.Valid {
+		action.FinishedAt = &finishedAt.Time
+	}
+	if reviewedAt.Valid {
+		action.ReviewedAt = &reviewedAt.Time
+	}
+
+	action.Kind = entity.AdminActionKind(kind)
+	action.Status = entity.AdminActionStatus(status)
+	if err := json.Unmarshal([]byte(userIDs), &action.UserIDs); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(failedUserIDs), &action.FailedUserIDs); err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
+
+// SQLiteAdminActionRepository implements AdminActionRepository interface for SQLite
+type SQLiteAdminActionRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteAdminActionRepository creates a new SQLite admin action repository
+func NewSQLiteAdminActionRepository(db *sql.DB) *SQLiteAdminActionRepository {
+	return &SQLiteAdminActionRepository{db: db, now: time.Now}
+}
+
+// Create queues an action and sets its ID and CreatedAt
+func (r *SQLiteAdminActionRepository) Create(action *entity.AdminAction) error {
+	query := `
+	INSERT INTO admin_actions (token, kind, actor_id, user_ids, role, subject, message, status, error, execute_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	userIDs, err := json.Marshal(action.UserIDs)
+	if err != nil {
+		return err
+	}
+
+	createdAt := r.now().UTC()
+	err = r.db.QueryRow(query, action.Token, string(action.Kind), action.ActorID, string(userIDs), action.Role,
+		action.Subject, action.Message, string(action.Status), action.Error, action.ExecuteAt.UTC(), createdAt).Scan(&action.ID)
+	if err != nil {
+		return err
+	}
+
+	action.CreatedAt = createdAt
+	return nil
+}
+
+// GetByToken retrieves an action by its undo token
+func (r *SQLiteAdminActionRepository) GetByToken(token string) (*entity.AdminAction, error) {
+	query := `SELECT ` + adminActionColumns + ` FROM admin_actions WHERE token = ?`
+
+	action, err := scanAdminAction(r.db.QueryRow(query, token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrAdminActionNotFound
+	}
+	return action, err
+}
+
+// Cancel marks a pending action, or one awaiting approval, as cancelled
+func (r *SQLiteAdminActionRepository) Cancel(token string) error {
+	query := `UPDATE admin_actions SET status = ? WHERE token = ? AND status IN (?, ?)`
+
+	result, err := r.db.Exec(query, string(entity.AdminActionCancelled), token, string(entity.AdminActionPending), string(entity.AdminActionAwaitingApproval))
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	// Nothing was cancelled; tell a missing token apart from one that already ran
+	if _, err := r.GetByToken(token); err != nil {
+		return err
+	}
+	return repository.ErrAdminActionNotPending
+}
+
+// Approve records the approval of an action awaiting it and makes it pending, due at executeAt
+func (r *SQLiteAdminActionRepository) Approve(token string, reviewerID int, at, executeAt time.Time) error {
+	query := `UPDATE admin_actions SET status = ?, reviewed_by = ?, reviewed_at = ?, execute_at = ? WHERE token = ? AND status = ?`
+	return r.review(token, query, string(entity.AdminActionPending), reviewerID, at.UTC(), executeAt.UTC(), token, string(entity.AdminActionAwaitingApproval))
+}
+
+// Reject records the rejection of an action awaiting approval
+func (r *SQLiteAdminActionRepository) Reject(token string, reviewerID int, at time.Time) error {
+	query := `UPDATE admin_actions SET status = ?, reviewed_by = ?, reviewed_at = ? WHERE token = ? AND status = ?`
+	return r.review(token, query, string(entity.AdminActionRejected), reviewerID, at.UTC(), token, string(entity.AdminActionAwaitingApproval))
+}
+
+// review runs an approval or rejection query, telling a missing token apart
+// from an action that is not awaiting approval when nothing was updated
+func (r *SQLiteAdminActionRepository) review(token, query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	if _, err := r.GetByToken(token); err != nil {
+		return err
+	}
+	return repository.ErrAdminActionNotAwaitingApproval
+}
+
+// ClaimDue marks up to limit pending actions due at or before now as running and returns them
+func (r *SQLiteAdminActionRepository) ClaimDue(now time.Time, limit int) ([]*entity.AdminAction, error) {
+	// A single UPDATE ... RETURNING keeps claiming atomic across workers
+	query := `
+	UPDATE admin_actions SET status = ?
+	WHERE id IN (
+		SELECT id FROM admin_actions
+		WHERE status = ? AND execute_at <= ?
+		ORDER BY execute_at, id
+		LIMIT ?
+	)
+	RETURNING ` + adminActionColumns
+
+	rows, err := r.db.Query(query, string(entity.AdminActionRunning), string(entity.AdminActionPending), now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanAdminActions(rows)
+}
+
+// CountDue returns the number of pending actions due at or before now
+func (r *SQLiteAdminActionRepository) CountDue(now time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM admin_actions WHERE status = ? AND execute_at <= ?`
+
+	var count int
+	err := r.db.QueryRow(query, string(entity.AdminActionPending), now.UTC()).Scan(&count)
+	return count, err
+}
+
+// ListPending returns the pending actions of kind, soonest first
+func (r *SQLiteAdminActionRepository) ListPending(kind entity.AdminActionKind) ([]*entity.AdminAction, error) {
+	query := `SELECT ` + adminActionColumns + ` FROM admin_actions WHERE status = ? AND kind = ? ORDER BY execute_at, id`
+
+	rows, err := r.db.Query(query, string(entity.AdminActionPending), string(kind))
+	if err != nil {
+		return nil, err
+	}
+	return scanAdminActions(rows)
+}
+
+// List returns up to limit actions with status and kind, newest first
+func (r *SQLiteAdminActionRepository) List(status entity.AdminActionStatus, kind entity.AdminActionKind, limit int) ([]*entity.AdminAction, error) {
+	query := `
+	SELECT ` + adminActionColumns + ` FROM admin_actions
+	WHERE (? = '' OR status = ?) AND (? = '' OR kind = ?)
+	ORDER BY id DESC
+	LIMIT ?`
+
+	rows, err := r.db.Query(query, string(status), string(status), string(kind), string(kind), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanAdminActions(rows)
+}
+
+// CountByStatus returns the number of actions in each status
+func (r *SQLiteAdminActionRepository) CountByStatus() (map[entity.AdminActionStatus]int, error) {
+	rows, err := r.db.Query(`SELECT status, COUNT(*) FROM admin_actions GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[entity.AdminActionStatus]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[entity.AdminActionStatus(status)] = count
+	}
+	return counts, rows.Err()
+}
+
+// Throughput counts the actions of each kind that finished at or after since
+func (r *SQLiteAdminActionRepository) Throughput(since time.Time) ([]entity.AdminActionThroughput, error) {
+	query := `
+	SELECT kind,
+		SUM(CASE WHEN status = ? THEN 1 ELSE 0 END),
+		SUM(CASE WHEN status = ? THEN 1 ELSE 0 END)
+	FROM admin_actions
+	WHERE finished_at >= ?
+	GROUP BY kind
+	ORDER BY kind`
+
+	rows, err := r.db.Query(query, string(entity.AdminActionApplied), string(entity.AdminActionFailed), since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var throughput []entity.AdminActionThroughput
+	for rows.Next() {
+		var kind string
+		var item entity.AdminActionThroughput
+		if err := rows.Scan(&kind, &item.Applied, &item.Failed); err != nil {
+			return nil, err
+		}
+		item.Kind = entity.AdminActionKind(kind)
+		throughput = append(throughput, item)
+	}
+	return throughput, rows.Err()
+}
+
+// Retry queues a failed action again to run at executeAt. An action that
+// recorded failed users is narrowed down to them, so users it was already
+// applied to are not processed twice.
+func (r *SQLiteAdminActionRepository) Retry(token string, executeAt time.Time) error {
+	query := `
+	UPDATE admin_actions
+	SET status = ?, error = '', processed = 0, execute_at = ?, finished_at = NULL,
+		user_ids = CASE WHEN failed_user_ids = '[]' THEN user_ids ELSE failed_user_ids END,
+		failed_user_ids = '[]'
+	WHERE token = ? AND status = ?`
+
+	result, err := r.db.Exec(query, string(entity.AdminActionPending), executeAt.UTC(), token, string(entity.AdminActionFailed))
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	if _, err := r.GetByToken(token); err != nil {
+		return err
+	}
+	return repository.ErrAdminActionNotFailed
+}
+
+// SetPaused pauses or resumes the queue
+func (r *SQLiteAdminActionRepository) SetPaused(paused bool, at time.Time) error {
+	if !paused {
+		_, err := r.db.Exec(`DELETE FROM paused_queues WHERE name = ?`, adminActionQueue)
+		return err
+	}
+	// Pausing twice keeps the original time
+	_, err := r.db.Exec(`INSERT INTO paused_queues (name, paused_at) VALUES (?, ?) ON CONFLICT (name) DO NOTHING`, adminActionQueue, at.UTC())
+	return err
+}
+
+// PausedSince returns when the queue was paused, or the zero time if it runs
+func (r *SQLiteAdminActionRepository) PausedSince() (time.Time, error) {
+	var pausedAt time.Time
+	err := r.db.QueryRow(`SELECT paused_at FROM paused_queues WHERE name = ?`, adminActionQueue).Scan(&pausedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return pausedAt, err
+}
+
+// Progress records how many of a running action's users have been processed
+func (r *SQLiteAdminActionRepository) Progress(id int, processed int) error {
+	_, err := r.db.Exec(`UPDATE admin_actions SET processed = ? WHERE id = ?`, processed, id)
+	return err
+}
+
+// Finish records the final status, error message and failed users of a claimed action
+func (r *SQLiteAdminActionRepository) Finish(id int, status entity.AdminActionStatus, errMsg string, failedUserIDs []int) error {
+	if failedUserIDs == nil {
+		failedUserIDs = []int{}
+	}
+	failed, err := json.Marshal(failedUserIDs)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE admin_actions SET status = ?, error = ?, failed_user_ids = ?, finished_at = ? WHERE id = ?`
+	_, err = r.db.Exec(query, string(status), errMsg, string(failed), r.now().UTC(), id)
+	return err
+}
+
+// scanAdminActions scans and closes rows selected with adminActionColumns
+func scanAdminActions(rows *sql.Rows) ([]*entity.AdminAction, error) {
+	defer rows.Close()
+
+	var actions []*entity.AdminAction
+	for rows.Next() {
+		action, err := scanAdminAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, rows.Err()
+}
@@ -0,0 +1,356 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func newTestAdminAction(token string, executeAt time.Time) *entity.AdminAction {
+	return &entity.AdminAction{
+		Token:     token,
+		Kind:      entity.AdminActionSetRole,
+		ActorID:   1,
+		UserIDs:   []int{2, 3},
+		Role:      entity.RoleAdmin,
+		Status:    entity.AdminActionPending,
+		ExecuteAt: executeAt,
+	}
+}
+
+func TestSQLiteAdminActionRepository_CreateAndGet(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteAdminActionRepository(db)
+	executeAt := time.Date(2024, 6, 1, 12, 0, 30, 0, time.UTC)
+
+	action := newTestAdminAction("token-1", executeAt)
+	if err := repo.Create(action); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if action.ID == 0 || action.CreatedAt.IsZero() {
+		t.Errorf("Create() should set ID and CreatedAt, got %+v", action)
+	}
+
+	found, err := repo.GetByToken("token-1")
+	if err != nil {
+		t.Fatalf("GetByToken() error = %v", err)
+	}
+	if found.Kind != entity.AdminActionSetRole || found.Role != entity.RoleAdmin || found.ActorID != 1 ||
+		len(found.UserIDs) != 2 || found.UserIDs[1] != 3 || !found.ExecuteAt.Equal(executeAt) {
+		t.Errorf("GetByToken() = %+v", found)
+	}
+
+	if _, err := repo.GetByToken("missing"); !errors.Is(err, repository.ErrAdminActionNotFound) {
+		t.Errorf("GetByToken() error = %v, want ErrAdminActionNotFound", err)
+	}
+}
+
+func TestSQLiteAdminActionRepository_IncidentReset(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteAdminActionRepository(db)
+	action := newTestAdminAction("token-1", time.Now())
+	action.Kind, action.Role = entity.AdminActionIncidentReset, ""
+	action.Subject, action.Message = "Reset your password", "Hello {{.FullName}}"
+	if err := repo.Create(action); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := repo.Progress(action.ID, 1); err != nil {
+		t.Fatalf("Progress() error = %v", err)
+	}
+
+	found, err := repo.GetByToken("token-1")
+	if err != nil {
+		t.Fatalf("GetByToken() error = %v", err)
+	}
+	if found.Subject != action.Subject || found.Message != action.Message || found.Processed != 1 {
+		t.Errorf("GetByToken() = %+v", found)
+	}
+}
+
+func TestSQLiteAdminActionRepository_ClaimDue(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteAdminActionRepository(db)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, a := range []*entity.AdminAction{
+		newTestAdminAction("due-late", now.Add(-time.Second)),
+		newTestAdminAction("due-early", now.Add(-time.Minute)),
+		newTestAdminAction("future", now.Add(time.Minute)),
+		newTestAdminAction("cancelled", now.Add(-time.Minute)),
+	} {
+		if err := repo.Create(a); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if err := repo.Cancel("cancelled"); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+
+	if count, err := repo.CountDue(now); err != nil || count != 2 {
+		t.Errorf("CountDue() = %d, %v; want 2", count, err)
+	}
+
+	claimed, err := repo.ClaimDue(now, 10)
+	if err != nil {
+		t.Fatalf("ClaimDue() error = %v", err)
+	}
+	if len(claimed) != 2 {
+		t.Fatalf("ClaimDue() claimed %d actions, want 2", len(claimed))
+	}
+	for _, action := range claimed {
+		if action.Status != entity.AdminActionRunning {
+			t.Errorf("claimed action %s status = %v, want running", action.Token, action.Status)
+		}
+	}
+
+	// Claimed actions are not handed out twice
+	again, err := repo.ClaimDue(now, 10)
+	if err != nil {
+		t.Fatalf("ClaimDue() error = %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("second ClaimDue() claimed %d actions, want 0", len(again))
+	}
+	if count, err := repo.CountDue(now); err != nil || count != 0 {
+		t.Errorf("CountDue() after claiming = %d, %v; want 0", count, err)
+	}
+
+	if err := repo.Finish(claimed[0].ID, entity.AdminActionFailed, "boom", []int{3}); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	finished, err := repo.GetByToken(claimed[0].Token)
+	if err != nil {
+		t.Fatalf("GetByToken() error = %v", err)
+	}
+	if finished.Status != entity.AdminActionFailed || finished.Error != "boom" ||
+		len(finished.FailedUserIDs) != 1 || finished.FailedUserIDs[0] != 3 || finished.FinishedAt == nil {
+		t.Errorf("Finish() not persisted, got %+v", finished)
+	}
+}
+
+func TestSQLiteAdminActionRepository_Retry(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteAdminActionRepository(db)
+	now := time.Now().UTC()
+
+	for _, token := range []string{"partial", "whole"} {
+		if err := repo.Create(newTestAdminAction(token, now)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	claimed, err := repo.ClaimDue(now, 10)
+	if err != nil || len(claimed) != 2 {
+		t.Fatalf("ClaimDue() = %v, %v", claimed, err)
+	}
+	if err := repo.Retry("partial", now); !errors.Is(err, repository.ErrAdminActionNotFailed) {
+		t.Errorf("Retry() running error = %v, want ErrAdminActionNotFailed", err)
+	}
+	if err := repo.Finish(claimed[0].ID, entity.AdminActionFailed, "user 3: boom", []int{3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Finish(claimed[1].ID, entity.AdminActionFailed, "unknown kind", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	later := now.Add(time.Hour)
+	if err := repo.Retry("partial", later); err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if err := repo.Retry("whole", later); err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+
+	// Only the failed users are retried when the action recorded them
+	for token, want := range map[string][]int{"partial": {3}, "whole": {2, 3}} {
+		action, err := repo.GetByToken(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if action.Status != entity.AdminActionPending || action.Error != "" || action.FinishedAt != nil ||
+			len(action.FailedUserIDs) != 0 || !slices.Equal(action.UserIDs, want) || !action.ExecuteAt.Equal(later) {
+			t.Errorf("Retry(%s) = %+v, want pending for %v", token, action, want)
+		}
+	}
+
+	if err := repo.Retry("missing", later); !errors.Is(err, repository.ErrAdminActionNotFound) {
+		t.Errorf("Retry() unknown token error = %v, want ErrAdminActionNotFound", err)
+	}
+}
+
+func TestSQLiteAdminActionRepository_QueueState(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteAdminActionRepository(db)
+	now := time.Now().UTC()
+
+	for _, token := range []string{"one", "two", "three"} {
+		if err := repo.Create(newTestAdminAction(token, now)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deletion := newTestAdminAction("four", now.Add(time.Hour))
+	deletion.Kind = entity.AdminActionDeleteUsers
+	if err := repo.Create(deletion); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := repo.ClaimDue(now, 2)
+	if err != nil || len(claimed) != 2 {
+		t.Fatalf("ClaimDue() = %v, %v", claimed, err)
+	}
+	if err := repo.Finish(claimed[0].ID, entity.AdminActionApplied, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Finish(claimed[1].ID, entity.AdminActionFailed, "boom", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := repo.CountByStatus()
+	if err != nil {
+		t.Fatalf("CountByStatus() error = %v", err)
+	}
+	if counts[entity.AdminActionPending] != 2 || counts[entity.AdminActionApplied] != 1 || counts[entity.AdminActionFailed] != 1 {
+		t.Errorf("CountByStatus() = %v", counts)
+	}
+
+	throughput, err := repo.Throughput(now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("Throughput() error = %v", err)
+	}
+	want := []entity.AdminActionThroughput{{Kind: entity.AdminActionSetRole, Applied: 1, Failed: 1}}
+	if !slices.Equal(throughput, want) {
+		t.Errorf("Throughput() = %v, want %v", throughput, want)
+	}
+	if throughput, err := repo.Throughput(now.Add(time.Hour)); err != nil || len(throughput) != 0 {
+		t.Errorf("Throughput() after the window = %v, %v; want none", throughput, err)
+	}
+
+	pending, err := repo.List(entity.AdminActionPending, "", 10)
+	if err != nil || len(pending) != 2 || pending[0].Token != "four" {
+		t.Errorf("List(pending) = %v, %v; want the two pending actions, newest first", pending, err)
+	}
+	if deletes, err := repo.List("", entity.AdminActionDeleteUsers, 10); err != nil || len(deletes) != 1 {
+		t.Errorf("List(delete_users) = %v, %v; want one", deletes, err)
+	}
+	if limited, err := repo.List("", "", 3); err != nil || len(limited) != 3 {
+		t.Errorf("List() with limit 3 = %d actions, %v", len(limited), err)
+	}
+
+	if since, err := repo.PausedSince(); err != nil || !since.IsZero() {
+		t.Errorf("PausedSince() = %v, %v; want running", since, err)
+	}
+	pausedAt := now.Truncate(time.Second)
+	if err := repo.SetPaused(true, pausedAt); err != nil {
+		t.Fatalf("SetPaused(true) error = %v", err)
+	}
+	if err := repo.SetPaused(true, pausedAt.Add(time.Minute)); err != nil {
+		t.Fatalf("SetPaused(true) twice error = %v", err)
+	}
+	if since, err := repo.PausedSince(); err != nil || !since.Equal(pausedAt) {
+		t.Errorf("PausedSince() = %v, %v; want %v", since, err, pausedAt)
+	}
+	if err := repo.SetPaused(false, now); err != nil {
+		t.Fatalf("SetPaused(false) error = %v", err)
+	}
+	if since, err := repo.PausedSince(); err != nil || !since.IsZero() {
+		t.Errorf("PausedSince() after resuming = %v, %v; want running", since, err)
+	}
+}
+
+func TestSQLiteAdminActionRepository_Cancel(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteAdminActionRepository(db)
+	now := time.Now().UTC()
+
+	if err := repo.Create(newTestAdminAction("undo-me", now.Add(time.Minute))); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := repo.Cancel("undo-me"); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+
+	found, err := repo.GetByToken("undo-me")
+	if err != nil {
+		t.Fatalf("GetByToken() error = %v", err)
+	}
+	if found.Status != entity.AdminActionCancelled {
+		t.Errorf("Status = %v, want cancelled", found.Status)
+	}
+
+	if err := repo.Cancel("undo-me"); !errors.Is(err, repository.ErrAdminActionNotPending) {
+		t.Errorf("Cancel() twice error = %v, want ErrAdminActionNotPending", err)
+	}
+	if err := repo.Cancel("missing"); !errors.Is(err, repository.ErrAdminActionNotFound) {
+		t.Errorf("Cancel() unknown token error = %v, want ErrAdminActionNotFound", err)
+	}
+
+	deletion := newTestAdminAction("delete-me", now.Add(time.Minute))
+	deletion.Kind = entity.AdminActionDeleteUsers
+	if err := repo.Create(deletion); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := repo.ListPending(entity.AdminActionDeleteUsers); err != nil || len(pending) != 1 || pending[0].Token != "delete-me" {
+		t.Errorf("ListPending(delete) = %v, %v; want the queued delete", pending, err)
+	}
+	if pending, err := repo.ListPending(entity.AdminActionSetRole); err != nil || len(pending) != 0 {
+		t.Errorf("ListPending(set_role) = %v, %v; want none after the undo", pending, err)
+	}
+}
+
+func TestSQLiteAdminActionRepository_Approval(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteAdminActionRepository(db)
+	now := time.Now().UTC()
+
+	for _, token := range []string{"approve-me", "reject-me"} {
+		action := newTestAdminAction(token, time.Time{})
+		action.Status = entity.AdminActionAwaitingApproval
+		if err := repo.Create(action); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if due, err := repo.ClaimDue(now, 10); err != nil || len(due) != 0 {
+		t.Errorf("ClaimDue() = %v, %v; want nothing awaiting approval", due, err)
+	}
+
+	if err := repo.Approve("approve-me", 7, now, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	found, err := repo.GetByToken("approve-me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Status != entity.AdminActionPending || found.ReviewedBy != 7 || found.ReviewedAt == nil || !found.ExecuteAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("approved action = %+v", found)
+	}
+	if err := repo.Approve("approve-me", 7, now, now); !errors.Is(err, repository.ErrAdminActionNotAwaitingApproval) {
+		t.Errorf("Approve() twice error = %v, want ErrAdminActionNotAwaitingApproval", err)
+	}
+
+	if err := repo.Reject("reject-me", 8, now); err != nil {
+		t.Fatalf("Reject() error = %v", err)
+	}
+	if found, err := repo.GetByToken("reject-me"); err != nil || found.Status != entity.AdminActionRejected || found.ReviewedBy != 8 {
+		t.Errorf("rejected action = %+v, %v", found, err)
+	}
+	if err := repo.Reject("missing", 8, now); !errors.Is(err, repository.ErrAdminActionNotFound) {
+		t.Errorf("Reject() unknown token error = %v, want ErrAdminActionNotFound", err)
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// auditColumns lists the admin_audit columns in the order scanAuditEntry expects them
+const auditColumns = `id, actor_id, action, subject_type, subject_id, changes, created_at`
+
+// scanAuditEntry scans a row selected with auditColumns into an audit entry
+func scanAuditEntry(row rowScanner) (*entity.AuditEntry, error) {
+	var entry entity.AuditEntry
+	var changes string
+	err := row.Scan(&entry.ID, &entry.ActorID, &entry.Action, &entry.SubjectType, &entry.SubjectID, &changes, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// SQLiteAuditRepository implements AuditRepository interface for SQLite
+type SQLiteAuditRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteAuditRepository creates a new SQLite audit repository
+func NewSQLiteAuditRepository(db *sql.DB) *SQLiteAuditRepository {
+	return &SQLiteAuditRepository{db: db, now: time.Now}
+}
+
+// Record stores an entry and sets its ID and CreatedAt
+func (r *SQLiteAuditRepository) Record(entry *entity.AuditEntry) error {
+	changes := entry.Changes
+	if changes == nil {
+		changes = []entity.FieldChange{}
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	createdAt := r.now().UTC()
+	query := `INSERT INTO admin_audit (actor_id, action, subject_type, subject_id, changes, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := r.db.Exec(query, entry.ActorID, entry.Action, entry.SubjectType, entry.SubjectID, string(data), createdAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	entry.ID = int(id)
+	entry.CreatedAt = createdAt
+	return nil
+}
+
+// List returns the entries matching the filter, newest first
+func (r *SQLiteAuditRepository) List(filter repository.AuditFilter) ([]*entity.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.ActorID > 0 {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+	if filter.SubjectType != "" {
+		conditions = append(conditions, "subject_type = ?")
+		args = append(args, filter.SubjectType)
+	}
+	if filter.SubjectID != "" {
+		conditions = append(conditions, "subject_id = ?")
+		args = append(args, filter.SubjectID)
+	}
+	if filter.BeforeID > 0 {
+		conditions = append(conditions, "id < ?")
+		args = append(args, filter.BeforeID)
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM admin_audit`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*entity.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteAuditRepository_RecordAndList(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteAuditRepository(db)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	entries := []*entity.AuditEntry{
+		entity.NewAuditEntry(1, entity.AuditUserUpdated, entity.EventSubjectUser, "7", []entity.FieldChange{{Field: "role", Old: "user", New: "admin"}}),
+		entity.NewAuditEntry(2, entity.AuditClientVersionSet, entity.AuditSubjectClientVersion, "ios", []entity.FieldChange{{Field: "minVersion", Old: "", New: "2.0.0"}}),
+		entity.NewAuditEntry(1, entity.AuditUserDeleted, entity.EventSubjectUser, "7", nil),
+	}
+	for _, entry := range entries {
+		now = now.Add(time.Minute)
+		if err := repo.Record(entry); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+		if entry.ID == 0 || !entry.CreatedAt.Equal(now) {
+			t.Errorf("Record() should set ID and CreatedAt, got %+v", entry)
+		}
+	}
+
+	all, err := repo.List(repository.AuditFilter{})
+	if err != nil || len(all) != 3 || all[0].Action != entity.AuditUserDeleted {
+		t.Fatalf("List() = %v, %v; want 3 entries newest first", all, err)
+	}
+	if got := all[2]; len(got.Changes) != 1 || got.Changes[0] != (entity.FieldChange{Field: "role", Old: "user", New: "admin"}) || got.SubjectID != "7" {
+		t.Errorf("List() oldest = %+v", got)
+	}
+	if all[0].Changes == nil || len(all[0].Changes) != 0 {
+		t.Errorf("an entry without changes should list none, got %#v", all[0].Changes)
+	}
+
+	tests := []struct {
+		name   string
+		filter repository.AuditFilter
+		want   int
+	}{
+		{"actor", repository.AuditFilter{ActorID: 1}, 2},
+		{"subject", repository.AuditFilter{SubjectType: entity.EventSubjectUser, SubjectID: "7"}, 2},
+		{"subject type", repository.AuditFilter{SubjectType: entity.AuditSubjectClientVersion}, 1},
+		{"before", repository.AuditFilter{BeforeID: all[0].ID}, 2},
+		{"limit", repository.AuditFilter{Limit: 1}, 1},
+	}
+	for _, tt := range tests {
+		got, err := repo.List(tt.filter)
+		if err != nil || len(got) != tt.want {
+			t.Errorf("List() by %s = %d entries, %v; want %d", tt.name, len(got), err, tt.want)
+		}
+	}
+}
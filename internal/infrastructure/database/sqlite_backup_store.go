@@ -0,0 +1,241 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+
+	"modernc.org/sqlite"
+)
+
+// ErrCorruptDatabase is returned when a database file fails its integrity check
+var ErrCorruptDatabase = errors.New("database failed integrity check")
+
+// Backup files are named backup-<UTC time>.db, so names sort by age
+const (
+	backupPrefix     = "backup-"
+	backupSuffix     = ".db"
+	backupTimeLayout = "20060102T150405.000Z"
+)
+
+// onlineBackuper is implemented by modernc.org/sqlite connections
+type onlineBackuper interface {
+	NewBackup(dstURI string) (*sqlite.Backup, error)
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+// BackupDatabase copies db to path with SQLite's online backup API, so
+// writers are not blocked while it runs. The copy is written next to path,
+// verified with VerifyDatabase and only then renamed into place.
+func BackupDatabase(ctx context.Context, db *sql.DB, path string) error {
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	err := copyOnline(ctx, db, func(conn onlineBackuper) (*sqlite.Backup, error) {
+		return conn.NewBackup(tmp)
+	})
+	if err == nil {
+		// Backups hold personal data; keep them private to the service user
+		err = os.Chmod(tmp, 0o600)
+	}
+	if err == nil {
+		err = VerifyDatabase(tmp)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// RestoreDatabase replaces the contents of db with the backup at path after
+// verifying it. Other connections must not write while it runs, so stop the
+// server first.
+func RestoreDatabase(ctx context.Context, db *sql.DB, path string) error {
+	if err := VerifyDatabase(path); err != nil {
+		return err
+	}
+	return copyOnline(ctx, db, func(conn onlineBackuper) (*sqlite.Backup, error) {
+		return conn.NewRestore(path)
+	})
+}
+
+// copyOnline runs a backup or restore on one connection of db to completion
+func copyOnline(ctx context.Context, db *sql.DB, start func(onlineBackuper) (*sqlite.Backup, error)) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		backuper, ok := driverConn.(onlineBackuper)
+		if !ok {
+			return errors.New("database driver does not support online backups")
+		}
+		backup, err := start(backuper)
+		if err != nil {
+			return err
+		}
+
+		for more := true; more; {
+			if more, err = backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+		}
+		return backup.Finish()
+	})
+}
+
+// VerifyDatabase runs SQLite's integrity check on the database file at path.
+// Returns ErrCorruptDatabase if the file is damaged or not a database.
+func VerifyDatabase(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCorruptDatabase, err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return err
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorruptDatabase, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrCorruptDatabase, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// SQLiteBackupStore implements BackupStore with backup files in a directory
+type SQLiteBackupStore struct {
+	db  *sql.DB
+	dir string
+	now func() time.Time
+}
+
+// NewSQLiteBackupStore creates a backup store writing backups of db to dir
+func NewSQLiteBackupStore(db *sql.DB, dir string) *SQLiteBackupStore {
+	return &SQLiteBackupStore{
+		db:  db,
+		dir: dir,
+		now: time.Now,
+	}
+}
+
+// Create writes a backup of the live database, verifies its integrity and
+// returns it with its checksum
+func (s *SQLiteBackupStore) Create() (*entity.Backup, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	createdAt := s.now().UTC()
+	name := backupPrefix + createdAt.Format(backupTimeLayout) + backupSuffix
+	path := filepath.Join(s.dir, name)
+	if err := BackupDatabase(context.Background(), s.db, path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Backup{
+		Name:      name,
+		Size:      size,
+		SHA256:    hex.EncodeToString(hash.Sum(nil)),
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// List returns the stored backups, newest first. Other files in the
+// directory are ignored.
+func (s *SQLiteBackupStore) List() ([]entity.Backup, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []entity.Backup
+	for _, entry := range entries {
+		createdAt, ok := parseBackupName(entry.Name())
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, entity.Backup{Name: entry.Name(), Size: info.Size(), CreatedAt: createdAt})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// Delete removes a stored backup by name
+func (s *SQLiteBackupStore) Delete(name string) error {
+	if _, ok := parseBackupName(name); !ok {
+		return fmt.Errorf("invalid backup name %q", name)
+	}
+	return os.Remove(filepath.Join(s.dir, name))
+}
+
+// parseBackupName returns the creation time encoded in a backup file name
+func parseBackupName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix)
+	createdAt, err := time.Parse(backupTimeLayout, stamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteBackupStore_CreateAndRestore(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	userRepo := NewSQLiteUserRepository(db)
+	user, err := userRepo.Create(&entity.User{
+		Email:       "backup@example.com",
+		Password:    "hashedpassword",
+		FullName:    "Backup User",
+		PhoneNumber: "0812345678",
+		Birthday:    "1990-01-15",
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	store := NewSQLiteBackupStore(db, filepath.Join(dir, "backups"))
+	store.now = func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	backup, err := store.Create()
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if backup.Name != "backup-20240601T120000.000Z.db" || backup.Size == 0 || len(backup.SHA256) != 64 {
+		t.Errorf("Create() = %+v", backup)
+	}
+
+	path := filepath.Join(dir, "backups", backup.Name)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("backup mode = %v, want 0600", info.Mode().Perm())
+	}
+	if err := VerifyDatabase(path); err != nil {
+		t.Errorf("VerifyDatabase() error = %v", err)
+	}
+
+	// Changes after the backup are undone by restoring it
+	if err := userRepo.Delete(user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := RestoreDatabase(t.Context(), db, path); err != nil {
+		t.Fatalf("RestoreDatabase() error = %v", err)
+	}
+	if _, err := userRepo.GetByEmail("backup@example.com"); err != nil {
+		t.Errorf("user missing after restore: %v", err)
+	}
+}
+
+func TestSQLiteBackupStore_ListAndDelete(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	dir := t.TempDir()
+	store := NewSQLiteBackupStore(db, dir)
+	if backups, err := store.List(); err != nil || len(backups) != 0 {
+		t.Fatalf("List() = %v, %v; want none", backups, err)
+	}
+
+	for _, at := range []time.Time{
+		time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC),
+	} {
+		store.now = func() time.Time { return at }
+		if _, err := store.Create(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Not a backup
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(backups) != 3 || backups[0].CreatedAt.Day() != 3 || backups[2].CreatedAt.Day() != 1 {
+		t.Fatalf("List() = %+v, want 3 backups newest first", backups)
+	}
+
+	if err := store.Delete(backups[2].Name); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err := store.Delete("../users.db"); err == nil {
+		t.Error("Delete() should reject names that are not backups")
+	}
+	if backups, _ := store.List(); len(backups) != 2 {
+		t.Errorf("List() after Delete = %d backups, want 2", len(backups))
+	}
+}
+
+func TestVerifyDatabase_Corrupt(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "broken.db")
+	if err := os.WriteFile(path, []byte("definitely not a database, just some text"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifyDatabase(path); !errors.Is(err, ErrCorruptDatabase) {
+		t.Errorf("VerifyDatabase() error = %v, want ErrCorruptDatabase", err)
+	}
+	if err := VerifyDatabase(filepath.Join(t.TempDir(), "missing.db")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("VerifyDatabase() error = %v, want ErrNotExist", err)
+	}
+}
+
+func TestSQLiteSnapshotSource(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	userRepo := NewSQLiteUserRepository(db)
+	if _, err := userRepo.Create(entity.NewUser("before@example.com", "hash", "Before", "0812345678", "1990-01-15")); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	snapshot, err := NewSQLiteSnapshotSource(db, dir).Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	// Writes after the snapshot are not seen through it
+	if _, err := userRepo.Create(entity.NewUser("after@example.com", "hash", "After", "0812345678", "1990-01-15")); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := snapshot.Users().Count(repository.UserFilter{}); err != nil || count != 1 {
+		t.Errorf("snapshot Count() = %d, %v; want 1", count, err)
+	}
+
+	if err := snapshot.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Close() left %d files behind", len(entries))
+	}
+}
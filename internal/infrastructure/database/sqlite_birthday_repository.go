@@ -0,0 +1,45 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// BirthdayMigrations create the tables of the birthdays module, applied
+// with MigrateModule
+var BirthdayMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create celebrated birthdays table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS celebrated_birthdays (
+			user_id INTEGER NOT NULL,
+			year INTEGER NOT NULL,
+			celebrated_at DATETIME NOT NULL,
+			PRIMARY KEY (user_id, year)
+		);`,
+	},
+}
+
+// SQLiteBirthdayRepository implements BirthdayRepository interface for SQLite
+type SQLiteBirthdayRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteBirthdayRepository creates a new SQLite birthday repository
+func NewSQLiteBirthdayRepository(db *sql.DB) *SQLiteBirthdayRepository {
+	return &SQLiteBirthdayRepository{db: db, now: time.Now}
+}
+
+// Claim marks the birthday of userID in year as celebrated. The primary key
+// makes claiming atomic across nodes.
+func (r *SQLiteBirthdayRepository) Claim(userID, year int) (bool, error) {
+	result, err := r.db.Exec(`INSERT INTO celebrated_birthdays (user_id, year, celebrated_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+		userID, year, r.now().UTC())
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
@@ -0,0 +1,25 @@
+package database
+
+import "testing"
+
+func TestSQLiteBirthdayRepository_Claim(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "birthdays", BirthdayMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteBirthdayRepository(db)
+	if claimed, err := repo.Claim(1, 2024); err != nil || !claimed {
+		t.Fatalf("Claim() = %v, %v; want claimed", claimed, err)
+	}
+	if claimed, err := repo.Claim(1, 2024); err != nil || claimed {
+		t.Errorf("Claim() again = %v, %v; want already claimed", claimed, err)
+	}
+	if claimed, err := repo.Claim(1, 2025); err != nil || !claimed {
+		t.Errorf("Claim() next year = %v, %v; want claimed", claimed, err)
+	}
+	if claimed, err := repo.Claim(2, 2024); err != nil || !claimed {
+		t.Errorf("Claim() for another user = %v, %v; want claimed", claimed, err)
+	}
+}
@@ -0,0 +1,244 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// BreakGlassMigrations create the tables of the break-glass module, applied
+// with MigrateModule
+var BreakGlassMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create break-glass seals and sessions tables",
+		Query: `
+		CREATE TABLE IF NOT EXISTS break_glass_seals (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			shares INTEGER NOT NULL,
+			threshold INTEGER NOT NULL,
+			secret_hash TEXT NOT NULL,
+			created_by INTEGER NOT NULL,
+			created_at DATETIME NOT NULL,
+			used_at DATETIME,
+			revoked_at DATETIME
+		);
+		CREATE TABLE IF NOT EXISTS break_glass_sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			seal_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			reason TEXT NOT NULL,
+			opened_at DATETIME NOT NULL,
+			expires_at DATETIME NOT NULL,
+			closed_at DATETIME,
+			closed_by INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_break_glass_sessions_user ON break_glass_sessions(user_id, closed_at);`,
+	},
+}
+
+// breakGlassSealColumns lists the columns scanned by scanBreakGlassSeal
+const breakGlassSealColumns = `id, shares, threshold, secret_hash, created_by, created_at, used_at, revoked_at`
+
+// scanBreakGlassSeal scans a row selected with breakGlassSealColumns into a seal
+func scanBreakGlassSeal(row rowScanner) (*entity.BreakGlassSeal, error) {
+	var seal entity.BreakGlassSeal
+	var usedAt, revokedAt sql.NullTime
+	err := row.Scan(&seal.ID, &seal.Shares, &seal.Threshold, &seal.SecretHash, &seal.CreatedBy, &seal.CreatedAt, &usedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	if usedAt.Valid {
+		seal.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		seal.RevokedAt = &revokedAt.Time
+	}
+	return &seal, nil
+}
+
+// breakGlassSessionColumns lists the columns scanned by scanBreakGlassSession
+const breakGlassSessionColumns = `id, seal_id, user_id, reason, opened_at, expires_at, closed_at, closed_by`
+
+// scanBreakGlassSession scans a row selected with breakGlassSessionColumns into a session
+func scanBreakGlassSession(row rowScanner) (*entity.BreakGlassSession, error) {
+	var session entity.BreakGlassSession
+	var closedAt sql.NullTime
+	err := row.Scan(&session.ID, &session.SealID, &session.UserID, &session.Reason, &session.OpenedAt, &session.ExpiresAt, &closedAt, &session.ClosedBy)
+	if err != nil {
+		return nil, err
+	}
+	if closedAt.Valid {
+		session.ClosedAt = &closedAt.Time
+	}
+	return &session, nil
+}
+
+// SQLiteBreakGlassRepository implements BreakGlassRepository interface for SQLite
+type SQLiteBreakGlassRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteBreakGlassRepository creates a new SQLite break-glass repository
+func NewSQLiteBreakGlassRepository(db *sql.DB) *SQLiteBreakGlassRepository {
+	return &SQLiteBreakGlassRepository{db: db}
+}
+
+// CreateSeal stores a new seal, revoking the unused ones in the same transaction
+func (r *SQLiteBreakGlassRepository) CreateSeal(seal *entity.BreakGlassSeal) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE break_glass_seals SET revoked_at = ? WHERE used_at IS NULL AND revoked_at IS NULL`, seal.CreatedAt); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`
+	INSERT INTO break_glass_seals (shares, threshold, secret_hash, created_by, created_at)
+	VALUES (?, ?, ?, ?, ?)`,
+		seal.Shares, seal.Threshold, seal.SecretHash, seal.CreatedBy, seal.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	seal.ID = int(id)
+	return tx.Commit()
+}
+
+// ActiveSeal returns the seal that is neither used nor revoked
+func (r *SQLiteBreakGlassRepository) ActiveSeal() (*entity.BreakGlassSeal, error) {
+	seal, err := scanBreakGlassSeal(r.db.QueryRow(`SELECT ` + breakGlassSealColumns + ` FROM break_glass_seals WHERE used_at IS NULL AND revoked_at IS NULL ORDER BY id DESC LIMIT 1`))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrBreakGlassSealNotFound
+	}
+	return seal, err
+}
+
+// Open marks a seal used and stores its session in one transaction, so a
+// seal opens one session even when unsealed twice at once
+func (r *SQLiteBreakGlassRepository) Open(sealID int, session *entity.BreakGlassSession) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE break_glass_seals SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL`, session.OpenedAt, sealID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return repository.ErrBreakGlassSealNotFound
+	}
+
+	session.SealID = sealID
+	result, err = tx.Exec(`
+	INSERT INTO break_glass_sessions (seal_id, user_id, reason, opened_at, expires_at)
+	VALUES (?, ?, ?, ?, ?)`,
+		session.SealID, session.UserID, session.Reason, session.OpenedAt, session.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	session.ID = int(id)
+	return tx.Commit()
+}
+
+// ActiveSession returns the session of a user open at now
+func (r *SQLiteBreakGlassRepository) ActiveSession(userID int, now time.Time) (*entity.BreakGlassSession, error) {
+	session, err := scanBreakGlassSession(r.db.QueryRow(`
+	SELECT `+breakGlassSessionColumns+` FROM break_glass_sessions
+	WHERE user_id = ? AND closed_at IS NULL AND expires_at > ?
+	ORDER BY id DESC LIMIT 1`, userID, now.UTC()))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrBreakGlassSessionNotFound
+	}
+	return session, err
+}
+
+// ListSessions returns up to limit sessions, newest first
+func (r *SQLiteBreakGlassRepository) ListSessions(limit int) ([]*entity.BreakGlassSession, error) {
+	rows, err := r.db.Query(`SELECT `+breakGlassSessionColumns+` FROM break_glass_sessions ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*entity.BreakGlassSession
+	for rows.Next() {
+		session, err := scanBreakGlassSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// Close closes an open session at now
+func (r *SQLiteBreakGlassRepository) Close(id, closedBy int, now time.Time) (*entity.BreakGlassSession, error) {
+	result, err := r.db.Exec(`UPDATE break_glass_sessions SET closed_at = ?, closed_by = ? WHERE id = ? AND closed_at IS NULL AND expires_at > ?`,
+		now.UTC(), closedBy, id, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, repository.ErrBreakGlassSessionNotFound
+	}
+	return scanBreakGlassSession(r.db.QueryRow(`SELECT `+breakGlassSessionColumns+` FROM break_glass_sessions WHERE id = ?`, id))
+}
+
+// CloseExpired closes the sessions that expired before now as of their
+// expiry and returns them
+func (r *SQLiteBreakGlassRepository) CloseExpired(now time.Time) ([]*entity.BreakGlassSession, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT `+breakGlassSessionColumns+` FROM break_glass_sessions WHERE closed_at IS NULL AND expires_at <= ? ORDER BY id`, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	var expired []*entity.BreakGlassSession
+	for rows.Next() {
+		session, err := scanBreakGlassSession(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		expired = append(expired, session)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, session := range expired {
+		if _, err := tx.Exec(`UPDATE break_glass_sessions SET closed_at = expires_at WHERE id = ?`, session.ID); err != nil {
+			return nil, err
+		}
+		closedAt := session.ExpiresAt
+		session.ClosedAt = &closedAt
+	}
+	return expired, tx.Commit()
+}
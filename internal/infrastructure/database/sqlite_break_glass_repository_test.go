@@ -0,0 +1,83 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteBreakGlassRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "break-glass", BreakGlassMigrations); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewSQLiteBreakGlassRepository(db)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+
+	if _, err := repo.ActiveSeal(); !errors.Is(err, repository.ErrBreakGlassSealNotFound) {
+		t.Fatalf("ActiveSeal() before a ceremony error = %v, want ErrBreakGlassSealNotFound", err)
+	}
+	first := &entity.BreakGlassSeal{Shares: 3, Threshold: 2, SecretHash: "first", CreatedBy: 1, CreatedAt: now}
+	second := &entity.BreakGlassSeal{Shares: 5, Threshold: 3, SecretHash: "second", CreatedBy: 1, CreatedAt: now.Add(time.Minute)}
+	for _, seal := range []*entity.BreakGlassSeal{first, second} {
+		if err := repo.CreateSeal(seal); err != nil {
+			t.Fatalf("CreateSeal() error = %v", err)
+		}
+	}
+	// A new ceremony revokes the previous seal
+	active, err := repo.ActiveSeal()
+	if err != nil || active.ID != second.ID || active.SecretHash != "second" {
+		t.Fatalf("ActiveSeal() = %+v, %v; want the second seal", active, err)
+	}
+	if err := repo.Open(first.ID, &entity.BreakGlassSession{UserID: 2, Reason: "r", OpenedAt: now, ExpiresAt: now.Add(time.Hour)}); !errors.Is(err, repository.ErrBreakGlassSealNotFound) {
+		t.Errorf("Open() of a revoked seal error = %v, want ErrBreakGlassSealNotFound", err)
+	}
+
+	session := &entity.BreakGlassSession{UserID: 2, Reason: "all admins locked out", OpenedAt: now, ExpiresAt: now.Add(time.Hour)}
+	if err := repo.Open(second.ID, session); err != nil || session.ID == 0 || session.SealID != second.ID {
+		t.Fatalf("Open() = %+v, %v", session, err)
+	}
+	// A seal opens once
+	if err := repo.Open(second.ID, &entity.BreakGlassSession{UserID: 3, Reason: "r", OpenedAt: now, ExpiresAt: now.Add(time.Hour)}); !errors.Is(err, repository.ErrBreakGlassSealNotFound) {
+		t.Errorf("Open() of a used seal error = %v, want ErrBreakGlassSealNotFound", err)
+	}
+	if _, err := repo.ActiveSeal(); !errors.Is(err, repository.ErrBreakGlassSealNotFound) {
+		t.Errorf("ActiveSeal() after opening error = %v, want ErrBreakGlassSealNotFound", err)
+	}
+
+	if got, err := repo.ActiveSession(2, now.Add(30*time.Minute)); err != nil || got.ID != session.ID || got.Reason != "all admins locked out" {
+		t.Errorf("ActiveSession() = %+v, %v", got, err)
+	}
+	if _, err := repo.ActiveSession(2, now.Add(time.Hour)); !errors.Is(err, repository.ErrBreakGlassSessionNotFound) {
+		t.Errorf("ActiveSession() at expiry error = %v, want ErrBreakGlassSessionNotFound", err)
+	}
+
+	if expired, err := repo.CloseExpired(now.Add(30 * time.Minute)); err != nil || len(expired) != 0 {
+		t.Errorf("CloseExpired() before expiry = %+v, %v", expired, err)
+	}
+	expired, err := repo.CloseExpired(now.Add(2 * time.Hour))
+	if err != nil || len(expired) != 1 || expired[0].ClosedAt == nil || !expired[0].ClosedAt.Equal(session.ExpiresAt) {
+		t.Fatalf("CloseExpired() = %+v, %v", expired, err)
+	}
+	if _, err := repo.Close(session.ID, 1, now.Add(3*time.Hour)); !errors.Is(err, repository.ErrBreakGlassSessionNotFound) {
+		t.Errorf("Close() of an expired session error = %v, want ErrBreakGlassSessionNotFound", err)
+	}
+
+	third := &entity.BreakGlassSeal{Shares: 3, Threshold: 2, SecretHash: "third", CreatedBy: 1, CreatedAt: now}
+	repo.CreateSeal(third)
+	open := &entity.BreakGlassSession{UserID: 4, Reason: "r", OpenedAt: now, ExpiresAt: now.Add(time.Hour)}
+	repo.Open(third.ID, open)
+	closed, err := repo.Close(open.ID, 1, now.Add(time.Minute))
+	if err != nil || closed.ClosedBy != 1 || closed.ClosedAt == nil || closed.Active(now.Add(time.Minute)) {
+		t.Errorf("Close() = %+v, %v", closed, err)
+	}
+
+	sessions, err := repo.ListSessions(10)
+	if err != nil || len(sessions) != 2 || sessions[0].ID != open.ID {
+		t.Errorf("ListSessions() = %+v, %v; want newest first", sessions, err)
+	}
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ClientVersionMigrations create the tables of the client versions module,
+// applied with MigrateModule
+var ClientVersionMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create client versions table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS client_versions (
+			platform TEXT PRIMARY KEY,
+			min_version TEXT NOT NULL,
+			update_url TEXT NOT NULL,
+			updated_by INTEGER NOT NULL,
+			updated_at DATETIME NOT NULL
+		);`,
+	},
+}
+
+// SQLiteClientVersionRepository implements ClientVersionRepository interface for SQLite
+type SQLiteClientVersionRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteClientVersionRepository creates a new SQLite client version repository
+func NewSQLiteClientVersionRepository(db *sql.DB) *SQLiteClientVersionRepository {
+	return &SQLiteClientVersionRepository{db: db, now: time.Now}
+}
+
+// List returns every stored policy, by platform
+func (r *SQLiteClientVersionRepository) List() ([]*entity.ClientVersionPolicy, error) {
+	rows, err := r.db.Query(`SELECT platform, min_version, update_url, updated_by, updated_at FROM client_versions ORDER BY platform`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []*entity.ClientVersionPolicy
+	for rows.Next() {
+		policy := &entity.ClientVersionPolicy{Source: entity.ClientVersionSourceAdmin}
+		if err := rows.Scan(&policy.Platform, &policy.MinVersion, &policy.UpdateURL, &policy.UpdatedBy, &policy.UpdatedAt); err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+// Save creates or replaces the policy of its platform
+func (r *SQLiteClientVersionRepository) Save(policy *entity.ClientVersionPolicy) error {
+	query := `
+	INSERT INTO client_versions (platform, min_version, update_url, updated_by, updated_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (platform) DO UPDATE SET
+		min_version = excluded.min_version,
+		update_url = excluded.update_url,
+		updated_by = excluded.updated_by,
+		updated_at = excluded.updated_at`
+
+	updatedAt := r.now().UTC()
+	if _, err := r.db.Exec(query, policy.Platform, policy.MinVersion, policy.UpdateURL, policy.UpdatedBy, updatedAt); err != nil {
+		return err
+	}
+
+	policy.UpdatedAt = updatedAt
+	return nil
+}
+
+// Delete removes the policy of a platform
+func (r *SQLiteClientVersionRepository) Delete(platform string) error {
+	result, err := r.db.Exec(`DELETE FROM client_versions WHERE platform = ?`, platform)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return repository.ErrClientVersionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteClientVersionRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "client-versions", ClientVersionMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteClientVersionRepository(db)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	if policies, err := repo.List(); err != nil || len(policies) != 0 {
+		t.Fatalf("List() = %+v, %v; want none", policies, err)
+	}
+
+	ios := &entity.ClientVersionPolicy{Platform: "ios", MinVersion: "2.3.0", UpdateURL: "https://apps.apple.com/app/id1", UpdatedBy: 1}
+	if err := repo.Save(ios); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if !ios.UpdatedAt.Equal(now) {
+		t.Errorf("Save() set UpdatedAt %v", ios.UpdatedAt)
+	}
+	if err := repo.Save(&entity.ClientVersionPolicy{Platform: "android", MinVersion: "2.1.0", UpdatedBy: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving again replaces the platform's policy
+	now = now.Add(time.Hour)
+	if err := repo.Save(&entity.ClientVersionPolicy{Platform: "ios", MinVersion: "2.4.0", UpdatedBy: 2}); err != nil {
+		t.Fatal(err)
+	}
+	policies, err := repo.List()
+	if err != nil || len(policies) != 2 {
+		t.Fatalf("List() = %+v, %v", policies, err)
+	}
+	if got := policies[1]; got.Platform != "ios" || got.MinVersion != "2.4.0" || got.UpdateURL != "" || got.UpdatedBy != 2 ||
+		!got.UpdatedAt.Equal(now) || got.Source != entity.ClientVersionSourceAdmin {
+		t.Errorf("ios policy = %+v", got)
+	}
+
+	if err := repo.Delete("android"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := repo.Delete("android"); !errors.Is(err, repository.ErrClientVersionNotFound) {
+		t.Errorf("Delete() again error = %v, want ErrClientVersionNotFound", err)
+	}
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// DigestMigrations create the tables of the digests module, applied with
+// MigrateModule
+var DigestMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create sent digests and hook failures tables",
+		Query: `
+		CREATE TABLE IF NOT EXISTS sent_digests (
+			period TEXT NOT NULL,
+			until TEXT NOT NULL,
+			sent_at DATETIME NOT NULL,
+			PRIMARY KEY (period, until)
+		);
+		CREATE TABLE IF NOT EXISTS hook_failures (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			point TEXT NOT NULL,
+			user_id INTEGER NOT NULL,
+			error TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_hook_failures_created ON hook_failures (created_at);`,
+	},
+}
+
+// SQLiteDigestRepository implements DigestRepository interface for SQLite
+type SQLiteDigestRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteDigestRepository creates a new SQLite digest repository
+func NewSQLiteDigestRepository(db *sql.DB) *SQLiteDigestRepository {
+	return &SQLiteDigestRepository{db: db, now: time.Now}
+}
+
+// Claim marks the digest of period ending at until as sent. The primary key
+// makes claiming atomic across nodes.
+func (r *SQLiteDigestRepository) Claim(period entity.DigestPeriod, until time.Time) (bool, error) {
+	result, err := r.db.Exec(`INSERT INTO sent_digests (period, until, sent_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+		string(period), until.UTC().Format(time.RFC3339), r.now().UTC())
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+// Release removes a claim whose digest could not be sent
+func (r *SQLiteDigestRepository) Release(period entity.DigestPeriod, until time.Time) error {
+	_, err := r.db.Exec(`DELETE FROM sent_digests WHERE period = ? AND until = ?`, string(period), until.UTC().Format(time.RFC3339))
+	return err
+}
+
+// SQLiteHookFailureRepository implements HookFailureRepository interface for SQLite
+type SQLiteHookFailureRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteHookFailureRepository creates a new SQLite hook failure repository
+func NewSQLiteHookFailureRepository(db *sql.DB) *SQLiteHookFailureRepository {
+	return &SQLiteHookFailureRepository{db: db, now: time.Now}
+}
+
+// Record stores a failure and sets its ID and CreatedAt
+func (r *SQLiteHookFailureRepository) Record(failure *entity.HookFailure) error {
+	createdAt := r.now().UTC()
+	err := r.db.QueryRow(`INSERT INTO hook_failures (point, user_id, error, created_at) VALUES (?, ?, ?, ?) RETURNING id`,
+		failure.Point, failure.UserID, failure.Error, createdAt).Scan(&failure.ID)
+	if err != nil {
+		return err
+	}
+
+	failure.CreatedAt = createdAt
+	return nil
+}
+
+// List returns the failures at or after since and before until, oldest first
+func (r *SQLiteHookFailureRepository) List(since, until time.Time) ([]*entity.HookFailure, error) {
+	query := `SELECT id, point, user_id, error, created_at FROM hook_failures WHERE created_at >= ? AND created_at < ? ORDER BY created_at, id`
+
+	rows, err := r.db.Query(query, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failures []*entity.HookFailure
+	for rows.Next() {
+		var failure entity.HookFailure
+		if err := rows.Scan(&failure.ID, &failure.Point, &failure.UserID, &failure.Error, &failure.CreatedAt); err != nil {
+			return nil, err
+		}
+		failures = append(failures, &failure)
+	}
+	return failures, rows.Err()
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+func TestSQLiteDigestRepository_Claim(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "digests", DigestMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteDigestRepository(db)
+	until := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+
+	if claimed, err := repo.Claim(entity.DigestDaily, until); err != nil || !claimed {
+		t.Fatalf("Claim() = %v, %v; want claimed", claimed, err)
+	}
+	if claimed, err := repo.Claim(entity.DigestDaily, until.In(time.FixedZone("ICT", 7*3600))); err != nil || claimed {
+		t.Errorf("Claim() again = %v, %v; want already claimed", claimed, err)
+	}
+	if claimed, err := repo.Claim(entity.DigestWeekly, until); err != nil || !claimed {
+		t.Errorf("Claim() weekly = %v, %v; want claimed separately", claimed, err)
+	}
+
+	if err := repo.Release(entity.DigestDaily, until); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if claimed, err := repo.Claim(entity.DigestDaily, until); err != nil || !claimed {
+		t.Errorf("Claim() after Release = %v, %v; want claimed", claimed, err)
+	}
+}
+
+func TestSQLiteHookFailureRepository_RecordAndList(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "digests", DigestMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteHookFailureRepository(db)
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, point := range []string{"post-register", "password-reset", "post-register"} {
+		repo.now = func() time.Time { return start.Add(time.Duration(i) * time.Hour) }
+		failure := &entity.HookFailure{Point: point, UserID: i + 1, Error: "connection refused"}
+		if err := repo.Record(failure); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+		if failure.ID == 0 || !failure.CreatedAt.Equal(start.Add(time.Duration(i)*time.Hour)) {
+			t.Errorf("Record() = %+v, want ID and CreatedAt set", failure)
+		}
+	}
+
+	failures, err := repo.List(start.Add(time.Hour), start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(failures) != 1 || failures[0].Point != "password-reset" || failures[0].UserID != 2 || failures[0].Error != "connection refused" {
+		t.Errorf("List() = %+v, want the password reset failure", failures)
+	}
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// DuplicateMigrations create the tables of the duplicates module, applied
+// with MigrateModule
+var DuplicateMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create duplicate candidates table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS duplicate_candidates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			other_user_id INTEGER NOT NULL,
+			signals TEXT NOT NULL,
+			status TEXT NOT NULL,
+			detected_at DATETIME NOT NULL,
+			scanned_at DATETIME NOT NULL,
+			reviewed_by INTEGER NOT NULL DEFAULT 0,
+			reviewed_at DATETIME,
+			UNIQUE (user_id, other_user_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_duplicate_candidates_status ON duplicate_candidates(status, detected_at);`,
+	},
+}
+
+// duplicateColumns lists the columns scanned by scanDuplicate
+const duplicateColumns = `id, user_id, other_user_id, signals, status, detected_at, reviewed_by, reviewed_at`
+
+// scanDuplicate scans a row selected with duplicateColumns into a duplicate candidate
+func scanDuplicate(row rowScanner) (*entity.DuplicateCandidate, error) {
+	var candidate entity.DuplicateCandidate
+	var signals string
+	var reviewedAt sql.NullTime
+	err := row.Scan(&candidate.ID, &candidate.UserID, &candidate.OtherUserID, &signals, &candidate.Status, &candidate.DetectedAt, &candidate.ReviewedBy, &reviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	candidate.Signals = strings.Split(signals, ",")
+	if reviewedAt.Valid {
+		candidate.ReviewedAt = &reviewedAt.Time
+	}
+	return &candidate, nil
+}
+
+// SQLiteDuplicateRepository implements DuplicateRepository interface for SQLite
+type SQLiteDuplicateRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteDuplicateRepository creates a new SQLite duplicate repository
+func NewSQLiteDuplicateRepository(db *sql.DB) *SQLiteDuplicateRepository {
+	return &SQLiteDuplicateRepository{db: db, now: time.Now}
+}
+
+// Replace saves the pairs found by a scan in one transaction. Each pair is
+// stamped with the scan time, so pending pairs with an older one were not
+// found again.
+func (r *SQLiteDuplicateRepository) Replace(candidates []*entity.DuplicateCandidate) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	scannedAt := r.now().UTC()
+	for _, candidate := range candidates {
+		_, err := tx.Exec(`
+		INSERT INTO duplicate_candidates (user_id, other_user_id, signals, status, detected_at, scanned_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, other_user_id) DO UPDATE SET
+			signals = excluded.signals,
+			scanned_at = excluded.scanned_at`,
+			candidate.UserID, candidate.OtherUserID, strings.Join(candidate.Signals, ","), entity.DuplicatePending, scannedAt, scannedAt)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM duplicate_candidates WHERE status = ? AND scanned_at < ?`, entity.DuplicatePending, scannedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// List returns up to limit pairs with status, most recently detected first
+func (r *SQLiteDuplicateRepository) List(status string, limit int) ([]*entity.DuplicateCandidate, error) {
+	rows, err := r.db.Query(`SELECT `+duplicateColumns+` FROM duplicate_candidates WHERE status = ? ORDER BY detected_at DESC, id DESC LIMIT ?`, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []*entity.DuplicateCandidate
+	for rows.Next() {
+		candidate, err := scanDuplicate(rows)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, rows.Err()
+}
+
+// Dismiss marks a pending pair as reviewed and not duplicates
+func (r *SQLiteDuplicateRepository) Dismiss(id, reviewerID int) (*entity.DuplicateCandidate, error) {
+	_, err := r.db.Exec(`UPDATE duplicate_candidates SET status = ?, reviewed_by = ?, reviewed_at = ? WHERE id = ? AND status = ?`,
+		entity.DuplicateDismissed, reviewerID, r.now().UTC(), id, entity.DuplicatePending)
+	if err != nil {
+		return nil, err
+	}
+	candidate, err := scanDuplicate(r.db.QueryRow(`SELECT `+duplicateColumns+` FROM duplicate_candidates WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrDuplicateNotFound
+	}
+	return candidate, err
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteDuplicateRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "duplicates", DuplicateMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteDuplicateRepository(db)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	err := repo.Replace([]*entity.DuplicateCandidate{
+		{UserID: 1, OtherUserID: 2, Signals: []string{entity.DuplicateSignalPhone}},
+		{UserID: 1, OtherUserID: 3, Signals: []string{entity.DuplicateSignalDevice}},
+	})
+	if err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	pending, err := repo.List(entity.DuplicatePending, 10)
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("List() = %+v, %v; want 2 pairs", pending, err)
+	}
+
+	// Most recently detected first, then newest
+	if pending[0].OtherUserID != 3 || pending[1].OtherUserID != 2 {
+		t.Errorf("List() order = %d, %d; want 3, 2", pending[0].OtherUserID, pending[1].OtherUserID)
+	}
+	phonePair := pending[1].ID
+	dismissed, err := repo.Dismiss(phonePair, 7)
+	if err != nil || dismissed.Status != entity.DuplicateDismissed || dismissed.ReviewedBy != 7 || dismissed.ReviewedAt == nil {
+		t.Fatalf("Dismiss() = %+v, %v", dismissed, err)
+	}
+	// Dismissing again keeps the first review
+	if again, err := repo.Dismiss(phonePair, 8); err != nil || again.ReviewedBy != 7 {
+		t.Errorf("Dismiss() again = %+v, %v", again, err)
+	}
+	if _, err := repo.Dismiss(999, 7); !errors.Is(err, repository.ErrDuplicateNotFound) {
+		t.Errorf("Dismiss() of a missing pair error = %v, want ErrDuplicateNotFound", err)
+	}
+
+	// The next scan updates the signals of known pairs, keeps the dismissed
+	// pair and drops the pending pairs it no longer finds
+	now = now.Add(time.Hour)
+	err = repo.Replace([]*entity.DuplicateCandidate{
+		{UserID: 1, OtherUserID: 2, Signals: []string{entity.DuplicateSignalEmail, entity.DuplicateSignalPhone}},
+		{UserID: 4, OtherUserID: 5, Signals: []string{entity.DuplicateSignalEmail}},
+	})
+	if err != nil {
+		t.Fatalf("Replace() again error = %v", err)
+	}
+	pending, err = repo.List(entity.DuplicatePending, 10)
+	if err != nil || len(pending) != 1 || pending[0].UserID != 4 || pending[0].OtherUserID != 5 || !pending[0].DetectedAt.Equal(now) {
+		t.Errorf("pending after the second scan = %+v, %v", pending, err)
+	}
+	dismissedPairs, err := repo.List(entity.DuplicateDismissed, 10)
+	if err != nil || len(dismissedPairs) != 1 {
+		t.Fatalf("dismissed after the second scan = %+v, %v", dismissedPairs, err)
+	}
+	if got := dismissedPairs[0]; len(got.Signals) != 2 || got.Signals[0] != entity.DuplicateSignalEmail || !got.DetectedAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("dismissed pair = %+v, want updated signals and the first detection time", got)
+	}
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"database/sql"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// EntitlementOverrideMigrations create the tables of the entitlements
+// module, applied with MigrateModule
+var EntitlementOverrideMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create entitlement overrides table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS entitlement_overrides (
+			user_id INTEGER NOT NULL,
+			feature TEXT NOT NULL,
+			granted BOOLEAN NOT NULL,
+			actor_id INTEGER NOT NULL,
+			created_at DATETIME NOT NULL,
+			PRIMARY KEY (user_id, feature)
+		);`,
+	},
+}
+
+// SQLiteEntitlementOverrideRepository implements EntitlementOverrideRepository interface for SQLite
+type SQLiteEntitlementOverrideRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteEntitlementOverrideRepository creates a new SQLite entitlement override repository
+func NewSQLiteEntitlementOverrideRepository(db *sql.DB) *SQLiteEntitlementOverrideRepository {
+	return &SQLiteEntitlementOverrideRepository{db: db}
+}
+
+// ListByUser returns the overrides of a user, sorted by feature
+func (r *SQLiteEntitlementOverrideRepository) ListByUser(userID int) ([]*entity.EntitlementOverride, error) {
+	rows, err := r.db.Query(`
+	SELECT user_id, feature, granted, actor_id, created_at FROM entitlement_overrides
+	WHERE user_id = ? ORDER BY feature`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []*entity.EntitlementOverride
+	for rows.Next() {
+		var override entity.EntitlementOverride
+		if err := rows.Scan(&override.UserID, &override.Feature, &override.Granted, &override.ActorID, &override.CreatedAt); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, &override)
+	}
+	return overrides, rows.Err()
+}
+
+// Set stores an override, replacing the user's override of the same feature
+func (r *SQLiteEntitlementOverrideRepository) Set(override *entity.EntitlementOverride) error {
+	_, err := r.db.Exec(`
+	INSERT INTO entitlement_overrides (user_id, feature, granted, actor_id, created_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (user_id, feature) DO UPDATE SET granted = excluded.granted, actor_id = excluded.actor_id, created_at = excluded.created_at`,
+		override.UserID, override.Feature, override.Granted, override.ActorID, override.CreatedAt.UTC())
+	return err
+}
+
+// Delete removes the user's override of feature, reporting whether there was one
+func (r *SQLiteEntitlementOverrideRepository) Delete(userID int, feature string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM entitlement_overrides WHERE user_id = ? AND feature = ?`, userID, feature)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
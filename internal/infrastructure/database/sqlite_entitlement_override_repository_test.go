@@ -0,0 +1,54 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+func TestSQLiteEntitlementOverrideRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "entitlements", EntitlementOverrideMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteEntitlementOverrideRepository(db)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	for _, override := range []*entity.EntitlementOverride{
+		{UserID: 1, Feature: "reports", Granted: true, ActorID: 9, CreatedAt: now},
+		{UserID: 1, Feature: "api_access", Granted: true, ActorID: 9, CreatedAt: now},
+		{UserID: 2, Feature: "reports", Granted: true, ActorID: 9, CreatedAt: now},
+		// Replaces the first override
+		{UserID: 1, Feature: "reports", Granted: false, ActorID: 8, CreatedAt: now.Add(time.Hour)},
+	} {
+		if err := repo.Set(override); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+
+	overrides, err := repo.ListByUser(1)
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(overrides) != 2 || overrides[0].Feature != "api_access" || overrides[1].Feature != "reports" {
+		t.Fatalf("ListByUser() = %+v, want api_access and reports", overrides)
+	}
+	if replaced := overrides[1]; replaced.Granted || replaced.ActorID != 8 || !replaced.CreatedAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("replaced override = %+v, want revoked by 8", replaced)
+	}
+
+	if deleted, err := repo.Delete(1, "reports"); err != nil || !deleted {
+		t.Errorf("Delete() = %v, %v; want true", deleted, err)
+	}
+	if deleted, err := repo.Delete(1, "reports"); err != nil || deleted {
+		t.Errorf("Delete() again = %v, %v; want false", deleted, err)
+	}
+	if overrides, _ := repo.ListByUser(1); len(overrides) != 1 {
+		t.Errorf("ListByUser() after Delete() = %+v, want api_access only", overrides)
+	}
+	if overrides, _ := repo.ListByUser(3); len(overrides) != 0 {
+		t.Errorf("ListByUser() of a user without overrides = %+v", overrides)
+	}
+}
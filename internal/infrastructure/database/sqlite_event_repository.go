@@ -0,0 +1,127 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// eventColumns lists the domain_events columns in the order scanEvent expects them
+const eventColumns = `id, type, schema_version, subject_type, subject_id, actor_id, data, occurred_at`
+
+// scanEvent scans a row selected with eventColumns into a domain event
+func scanEvent(row rowScanner) (*entity.DomainEvent, error) {
+	var event entity.DomainEvent
+	var data string
+	err := row.Scan(&event.ID, &event.Type, &event.SchemaVersion, &event.SubjectType, &event.SubjectID,
+		&event.ActorID, &data, &event.OccurredAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(data), &event.Data); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// SQLiteEventRepository implements EventRepository interface for SQLite
+type SQLiteEventRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteEventRepository creates a new SQLite domain event repository
+func NewSQLiteEventRepository(db *sql.DB) *SQLiteEventRepository {
+	return &SQLiteEventRepository{db: db, now: time.Now}
+}
+
+// Append stores an event and sets its ID and OccurredAt
+func (r *SQLiteEventRepository) Append(event *entity.DomainEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	if event.Data == nil {
+		data = []byte("{}")
+	}
+
+	occurredAt := r.now().UTC()
+	query := `INSERT INTO domain_events (type, schema_version, subject_type, subject_id, actor_id, data, occurred_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`
+	result, err := r.db.Exec(query, event.Type, event.SchemaVersion, event.SubjectType, event.SubjectID,
+		event.ActorID, string(data), occurredAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	event.ID = id
+	event.OccurredAt = occurredAt
+	return nil
+}
+
+// List returns the events matching the filter, oldest first
+func (r *SQLiteEventRepository) List(filter repository.EventFilter) ([]*entity.DomainEvent, error) {
+	var conditions []string
+	var args []interface{}
+	if len(filter.Types) > 0 {
+		placeholders := make([]string, len(filter.Types))
+		for i, eventType := range filter.Types {
+			placeholders[i] = "?"
+			args = append(args, eventType)
+		}
+		conditions = append(conditions, "type IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "occurred_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "occurred_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+	if filter.AfterID > 0 {
+		conditions = append(conditions, "id > ?")
+		args = append(args, filter.AfterID)
+	}
+
+	query := `SELECT ` + eventColumns + ` FROM domain_events`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY id`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entity.DomainEvent
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// LastID returns the ID of the newest event, 0 when the log is empty
+func (r *SQLiteEventRepository) LastID() (int64, error) {
+	var id int64
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM domain_events`).Scan(&id)
+	return id, err
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteEventRepository_AppendAndList(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteEventRepository(db)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	if last, err := repo.LastID(); err != nil || last != 0 {
+		t.Errorf("LastID() of an empty log = %d, %v; want 0", last, err)
+	}
+
+	events := []*entity.DomainEvent{
+		entity.NewDomainEvent(entity.EventUserRegistered, entity.EventSubjectUser, 1, 1, map[string]interface{}{"email": "a@example.com"}),
+		entity.NewDomainEvent(entity.EventUserLoggedIn, entity.EventSubjectUser, 1, 1, map[string]interface{}{"method": "password"}),
+		entity.NewDomainEvent(entity.EventUserSuspended, entity.EventSubjectUser, 1, 9, nil),
+		entity.NewDomainEvent(entity.EventUserLoggedIn, entity.EventSubjectUser, 2, 2, map[string]interface{}{"method": "password"}),
+	}
+	for _, event := range events {
+		now = now.Add(time.Hour)
+		if err := repo.Append(event); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		if event.ID == 0 || !event.OccurredAt.Equal(now) {
+			t.Errorf("Append() should set ID and OccurredAt, got %+v", event)
+		}
+	}
+
+	all, err := repo.List(repository.EventFilter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(all) != 4 || all[0].Type != entity.EventUserRegistered || all[0].SchemaVersion != 1 ||
+		all[0].Data["email"] != "a@example.com" || all[2].ActorID != 9 || len(all[2].Data) != 0 {
+		t.Errorf("List() = %+v", all)
+	}
+
+	if last, err := repo.LastID(); err != nil || last != 4 {
+		t.Errorf("LastID() = %d, %v; want 4", last, err)
+	}
+
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		filter repository.EventFilter
+		want   []int64
+	}{
+		{"by type", repository.EventFilter{Types: []entity.EventType{entity.EventUserLoggedIn}}, []int64{2, 4}},
+		{"by types", repository.EventFilter{Types: []entity.EventType{entity.EventUserRegistered, entity.EventUserSuspended}}, []int64{1, 3}},
+		{"by time", repository.EventFilter{Since: start.Add(2 * time.Hour), Until: start.Add(4 * time.Hour)}, []int64{2, 3}},
+		{"after ID with limit", repository.EventFilter{AfterID: 1, Limit: 2}, []int64{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.List(tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			ids := make([]int64, len(got))
+			for i, event := range got {
+				ids[i] = event.ID
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("List() IDs = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// FraudMigrations create the tables of the fraud module, applied with
+// MigrateModule
+var FraudMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create fraud scores table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS fraud_scores (
+			user_id INTEGER PRIMARY KEY,
+			score INTEGER NOT NULL,
+			signals TEXT NOT NULL,
+			status TEXT NOT NULL,
+			scored_at DATETIME NOT NULL,
+			flagged_at DATETIME,
+			cleared_by INTEGER NOT NULL DEFAULT 0,
+			cleared_at DATETIME,
+			cleared_score INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE INDEX IF NOT EXISTS idx_fraud_scores_status ON fraud_scores(status, score);`,
+	},
+}
+
+// fraudScoreColumns lists the columns scanned by scanFraudScore
+const fraudScoreColumns = `user_id, score, signals, status, scored_at, flagged_at, cleared_by, cleared_at, cleared_score`
+
+// scanFraudScore scans a row selected with fraudScoreColumns into a fraud score
+func scanFraudScore(row rowScanner) (*entity.FraudScore, error) {
+	var score entity.FraudScore
+	var signals string
+	var flaggedAt, clearedAt sql.NullTime
+	err := row.Scan(&score.UserID, &score.Score, &signals, &score.Status, &score.ScoredAt, &flaggedAt, &score.ClearedBy, &clearedAt, &score.ClearedScore)
+	if err != nil {
+		return nil, err
+	}
+	score.Signals = []string{}
+	if signals != "" {
+		score.Signals = strings.Split(signals, ",")
+	}
+	if flaggedAt.Valid {
+		score.FlaggedAt = &flaggedAt.Time
+	}
+	if clearedAt.Valid {
+		score.ClearedAt = &clearedAt.Time
+	}
+	return &score, nil
+}
+
+// SQLiteFraudRepository implements FraudRepository interface for SQLite
+type SQLiteFraudRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteFraudRepository creates a new SQLite fraud repository
+func NewSQLiteFraudRepository(db *sql.DB) *SQLiteFraudRepository {
+	return &SQLiteFraudRepository{db: db}
+}
+
+// Get returns the score of a user
+func (r *SQLiteFraudRepository) Get(userID int) (*entity.FraudScore, error) {
+	score, err := scanFraudScore(r.db.QueryRow(`SELECT `+fraudScoreColumns+` FROM fraud_scores WHERE user_id = ?`, userID))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrFraudScoreNotFound
+	}
+	return score, err
+}
+
+// Save creates or replaces the score of a user
+func (r *SQLiteFraudRepository) Save(score *entity.FraudScore) error {
+	_, err := r.db.Exec(`
+	INSERT INTO fraud_scores (user_id, score, signals, status, scored_at, flagged_at, cleared_by, cleared_at, cleared_score)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (user_id) DO UPDATE SET
+		score = excluded.score,
+		signals = excluded.signals,
+		status = excluded.status,
+		scored_at = excluded.scored_at,
+		flagged_at = excluded.flagged_at,
+		cleared_by = excluded.cleared_by,
+		cleared_at = excluded.cleared_at,
+		cleared_score = excluded.cleared_score`,
+		score.UserID, score.Score, strings.Join(score.Signals, ","), score.Status, score.ScoredAt,
+		score.FlaggedAt, score.ClearedBy, score.ClearedAt, score.ClearedScore)
+	return err
+}
+
+// List returns up to limit scores with status, highest score first
+func (r *SQLiteFraudRepository) List(status string, limit int) ([]*entity.FraudScore, error) {
+	rows, err := r.db.Query(`SELECT `+fraudScoreColumns+` FROM fraud_scores WHERE status = ? ORDER BY score DESC, user_id LIMIT ?`, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []*entity.FraudScore
+	for rows.Next() {
+		score, err := scanFraudScore(rows)
+		if err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+	return scores, rows.Err()
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteFraudRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "fraud", FraudMigrations); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewSQLiteFraudRepository(db)
+
+	if _, err := repo.Get(1); !errors.Is(err, repository.ErrFraudScoreNotFound) {
+		t.Fatalf("Get() of an unscored user error = %v, want ErrFraudScoreNotFound", err)
+	}
+
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	for _, score := range []*entity.FraudScore{
+		{UserID: 1, Score: 0, Signals: []string{}, Status: entity.FraudClear, ScoredAt: now},
+		{UserID: 2, Score: 55, Signals: []string{"disposable_email", "geo"}, Status: entity.FraudFlagged, ScoredAt: now, FlaggedAt: &now},
+		{UserID: 3, Score: 85, Signals: []string{"disposable_email", "geo", "velocity"}, Status: entity.FraudFlagged, ScoredAt: now, FlaggedAt: &now},
+	} {
+		if err := repo.Save(score); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	flagged, err := repo.List(entity.FraudFlagged, 10)
+	if err != nil || len(flagged) != 2 {
+		t.Fatalf("List() = %+v, %v; want 2 scores", flagged, err)
+	}
+	if flagged[0].UserID != 3 || flagged[1].UserID != 2 {
+		t.Errorf("List() order = %d, %d; want 3, 2", flagged[0].UserID, flagged[1].UserID)
+	}
+	if got := flagged[1]; len(got.Signals) != 2 || got.Signals[1] != "geo" || got.FlaggedAt == nil || !got.FlaggedAt.Equal(now) {
+		t.Errorf("List()[1] = %+v", got)
+	}
+
+	// Saving again replaces the score
+	cleared := flagged[1]
+	cleared.Clear(7, now.Add(time.Hour))
+	if err := repo.Save(cleared); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := repo.Get(2)
+	if err != nil || got.Status != entity.FraudCleared || got.ClearedBy != 7 || got.ClearedScore != 55 || got.ClearedAt == nil {
+		t.Errorf("Get() after clearing = %+v, %v", got, err)
+	}
+	if clear, err := repo.Get(1); err != nil || len(clear.Signals) != 0 || clear.FlaggedAt != nil {
+		t.Errorf("Get() of a clear user = %+v, %v", clear, err)
+	}
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// SQLiteFunnelRepository implements FunnelRepository interface for SQLite
+type SQLiteFunnelRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteFunnelRepository creates a new SQLite funnel repository
+func NewSQLiteFunnelRepository(db *sql.DB) *SQLiteFunnelRepository {
+	return &SQLiteFunnelRepository{db: db}
+}
+
+// Record saves a funnel event. The unique index on user and stage makes a
+// stage a user already reached a no-op, even for concurrent requests; events
+// without a user have a NULL user_id and are all kept.
+func (r *SQLiteFunnelRepository) Record(event *entity.FunnelEvent) error {
+	query := `
+	INSERT INTO funnel_events (stage, user_id, day, occurred_at)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT DO NOTHING
+	RETURNING id`
+
+	var userID interface{}
+	if event.UserID != 0 {
+		userID = event.UserID
+	}
+
+	err := r.db.QueryRow(query, string(event.Stage), userID, event.Day(), event.OccurredAt).Scan(&event.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Already recorded
+		return nil
+	}
+	return err
+}
+
+// CountByDay returns per-day stage counts between from and to (inclusive, YYYY-MM-DD)
+func (r *SQLiteFunnelRepository) CountByDay(from, to string) ([]entity.FunnelDay, error) {
+	query := `
+	SELECT day, stage, COUNT(*)
+	FROM funnel_events
+	WHERE day BETWEEN ? AND ?
+	GROUP BY day, stage
+	ORDER BY day`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []entity.FunnelDay
+	for rows.Next() {
+		var day, stage string
+		var count int
+		if err := rows.Scan(&day, &stage, &count); err != nil {
+			return nil, err
+		}
+
+		if len(days) == 0 || days[len(days)-1].Date != day {
+			days = append(days, entity.FunnelDay{Date: day, Counts: make(map[entity.FunnelStage]int)})
+		}
+		days[len(days)-1].Counts[entity.FunnelStage(stage)] = count
+	}
+
+	return days, rows.Err()
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+func TestSQLiteFunnelRepository_Record(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteFunnelRepository(db)
+
+	event := entity.NewFunnelEvent(entity.FunnelStageStarted, 0)
+	if err := repo.Record(event); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if event.ID == 0 {
+		t.Error("ID should be set after Record()")
+	}
+
+	// Anonymous events are stored with a NULL user
+	var userID sql.NullInt64
+	err := db.QueryRow("SELECT user_id FROM funnel_events WHERE id = ?", event.ID).Scan(&userID)
+	if err != nil {
+		t.Fatalf("Failed to read event: %v", err)
+	}
+	if userID.Valid {
+		t.Errorf("user_id = %v, want NULL", userID.Int64)
+	}
+}
+
+func TestSQLiteFunnelRepository_RecordOncePerUser(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteFunnelRepository(db)
+
+	// Concurrent first logins of one user are recorded once
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repo.Record(entity.NewFunnelEvent(entity.FunnelStageFirstLogin, 7))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	for range 2 {
+		if err := repo.Record(entity.NewFunnelEvent(entity.FunnelStageStarted, 0)); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	counts := map[entity.FunnelStage]int{}
+	rows, err := db.Query("SELECT stage, COUNT(*) FROM funnel_events GROUP BY stage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var stage string
+		var count int
+		if err := rows.Scan(&stage, &count); err != nil {
+			t.Fatal(err)
+		}
+		counts[entity.FunnelStage(stage)] = count
+	}
+	if counts[entity.FunnelStageFirstLogin] != 1 || counts[entity.FunnelStageStarted] != 2 {
+		t.Errorf("recorded events = %v, want one first_login and both anonymous starts", counts)
+	}
+}
+
+func TestSQLiteFunnelRepository_CountByDay(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteFunnelRepository(db)
+
+	events := []*entity.FunnelEvent{
+		{Stage: entity.FunnelStageStarted, OccurredAt: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+		{Stage: entity.FunnelStageStarted, OccurredAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)},
+		{Stage: entity.FunnelStageCreated, UserID: 1, OccurredAt: time.Date(2024, 5, 1, 9, 0, 1, 0, time.UTC)},
+		{Stage: entity.FunnelStageStarted, OccurredAt: time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)},
+		{Stage: entity.FunnelStageStarted, OccurredAt: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, event := range events {
+		if err := repo.Record(event); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	days, err := repo.CountByDay("2024-05-01", "2024-05-31")
+	if err != nil {
+		t.Fatalf("CountByDay() error = %v", err)
+	}
+
+	if len(days) != 2 {
+		t.Fatalf("days = %v, want 2", len(days))
+	}
+	if days[0].Date != "2024-05-01" {
+		t.Errorf("Date = %v, want 2024-05-01", days[0].Date)
+	}
+	if days[0].Counts[entity.FunnelStageStarted] != 2 {
+		t.Errorf("Counts[started] = %v, want 2", days[0].Counts[entity.FunnelStageStarted])
+	}
+	if days[0].Counts[entity.FunnelStageCreated] != 1 {
+		t.Errorf("Counts[created] = %v, want 1", days[0].Counts[entity.FunnelStageCreated])
+	}
+	if days[1].Counts[entity.FunnelStageStarted] != 1 {
+		t.Errorf("Counts[started] on day 2 = %v, want 1", days[1].Counts[entity.FunnelStageStarted])
+	}
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// HookDeliveryMigrations create the tables of the dead letters module,
+// applied with MigrateModule
+var HookDeliveryMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create hook deliveries table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS hook_deliveries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			point TEXT NOT NULL,
+			hook INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			email TEXT NOT NULL,
+			data TEXT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL,
+			errors TEXT NOT NULL,
+			next_attempt_at DATETIME NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_hook_deliveries_due ON hook_deliveries (status, next_attempt_at);`,
+	},
+	{
+		Version:     2,
+		Description: "add event IDs to hook deliveries",
+		Query:       `ALTER TABLE hook_deliveries ADD COLUMN event_id TEXT NOT NULL DEFAULT '';`,
+	},
+}
+
+// hookDeliveryColumns lists the hook_deliveries columns in the order scanHookDelivery expects them
+const hookDeliveryColumns = `id, event_id, point, hook, user_id, email, data, status, attempts, errors, next_attempt_at, created_at, updated_at`
+
+// scanHookDelivery scans a row selected with hookDeliveryColumns into a delivery
+func scanHookDelivery(row rowScanner) (*entity.HookDelivery, error) {
+	var delivery entity.HookDelivery
+	var data, status, errs string
+	err := row.Scan(&delivery.ID, &delivery.EventID, &delivery.Point, &delivery.Hook, &delivery.UserID, &delivery.Email, &data, &status,
+		&delivery.Attempts, &errs, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrHookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Status = entity.HookDeliveryStatus(status)
+	if err := json.Unmarshal([]byte(data), &delivery.Data); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(errs), &delivery.Errors); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// scanHookDeliveries scans and closes rows selected with hookDeliveryColumns
+func scanHookDeliveries(rows *sql.Rows) ([]*entity.HookDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []*entity.HookDelivery
+	for rows.Next() {
+		delivery, err := scanHookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// SQLiteHookDeliveryRepository implements HookDeliveryRepository interface for SQLite
+type SQLiteHookDeliveryRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteHookDeliveryRepository creates a new SQLite hook delivery repository
+func NewSQLiteHookDeliveryRepository(db *sql.DB) *SQLiteHookDeliveryRepository {
+	return &SQLiteHookDeliveryRepository{db: db, now: time.Now}
+}
+
+// Create stores a failed delivery and sets its ID, CreatedAt and UpdatedAt
+func (r *SQLiteHookDeliveryRepository) Create(delivery *entity.HookDelivery) error {
+	query := `
+	INSERT INTO hook_deliveries (event_id, point, hook, user_id, email, data, status, attempts, errors, next_attempt_at, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	data, err := json.Marshal(delivery.Data)
+	if err != nil {
+		return err
+	}
+	errs, err := json.Marshal(delivery.Errors)
+	if err != nil {
+		return err
+	}
+
+	createdAt := r.now().UTC()
+	err = r.db.QueryRow(query, delivery.EventID, delivery.Point, delivery.Hook, delivery.UserID, delivery.Email, string(data),
+		string(delivery.Status), delivery.Attempts, string(errs), delivery.NextAttemptAt.UTC(), createdAt, createdAt).Scan(&delivery.ID)
+	if err != nil {
+		return err
+	}
+
+	delivery.CreatedAt, delivery.UpdatedAt = createdAt, createdAt
+	return nil
+}
+
+// GetByID retrieves a delivery
+func (r *SQLiteHookDeliveryRepository) GetByID(id int) (*entity.HookDelivery, error) {
+	return scanHookDelivery(r.db.QueryRow(`SELECT `+hookDeliveryColumns+` FROM hook_deliveries WHERE id = ?`, id))
+}
+
+// ClaimDue marks up to limit retrying deliveries due at or before now as running and returns them
+func (r *SQLiteHookDeliveryRepository) ClaimDue(now time.Time, limit int) ([]*entity.HookDelivery, error) {
+	// A single UPDATE ... RETURNING keeps claiming atomic across workers
+	query := `
+	UPDATE hook_deliveries SET status = ?
+	WHERE id IN (
+		SELECT id FROM hook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id
+		LIMIT ?
+	)
+	RETURNING ` + hookDeliveryColumns
+
+	rows, err := r.db.Query(query, string(entity.HookDeliveryRunning), string(entity.HookDeliveryRetrying), now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanHookDeliveries(rows)
+}
+
+// CountDue returns the number of retrying deliveries due at or before now
+func (r *SQLiteHookDeliveryRepository) CountDue(now time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM hook_deliveries WHERE status = ? AND next_attempt_at <= ?`
+
+	var count int
+	err := r.db.QueryRow(query, string(entity.HookDeliveryRetrying), now.UTC()).Scan(&count)
+	return count, err
+}
+
+// Update saves a claimed delivery's status, attempts, errors and next attempt
+func (r *SQLiteHookDeliveryRepository) Update(delivery *entity.HookDelivery) error {
+	errs, err := json.Marshal(delivery.Errors)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE hook_deliveries SET status = ?, attempts = ?, errors = ?, next_attempt_at = ?, updated_at = ? WHERE id = ?`
+	updatedAt := r.now().UTC()
+	_, err = r.db.Exec(query, string(delivery.Status), delivery.Attempts, string(errs), delivery.NextAttemptAt.UTC(), updatedAt, delivery.ID)
+	if err != nil {
+		return err
+	}
+
+	delivery.UpdatedAt = updatedAt
+	return nil
+}
+
+// List returns up to limit deliveries with status and point, newest first
+func (r *SQLiteHookDeliveryRepository) List(status entity.HookDeliveryStatus, point string, limit int) ([]*entity.HookDelivery, error) {
+	query := `
+	SELECT ` + hookDeliveryColumns + ` FROM hook_deliveries
+	WHERE (? = '' OR status = ?) AND (? = '' OR point = ?)
+	ORDER BY id DESC
+	LIMIT ?`
+
+	rows, err := r.db.Query(query, string(status), string(status), point, point, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanHookDeliveries(rows)
+}
+
+// replayQuery requeues dead deliveries; callers append their conditions
+const replayQuery = `UPDATE hook_deliveries SET status = ?, attempts = 0, next_attempt_at = ?, updated_at = ? WHERE status = ?`
+
+// Replay queues a dead delivery to be attempted again at at
+func (r *SQLiteHookDeliveryRepository) Replay(id int, at time.Time) error {
+	result, err := r.db.Exec(replayQuery+` AND id = ?`, string(entity.HookDeliveryRetrying), at.UTC(), r.now().UTC(),
+		string(entity.HookDeliveryDead), id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	// Nothing was replayed; tell a missing delivery apart from one that is not dead
+	if _, err := r.GetByID(id); err != nil {
+		return err
+	}
+	return repository.ErrHookDeliveryNotDead
+}
+
+// ReplayAll queues every dead delivery at point, or at any point when empty
+func (r *SQLiteHookDeliveryRepository) ReplayAll(point string, at time.Time) (int, error) {
+	result, err := r.db.Exec(replayQuery+` AND (? = '' OR point = ?)`, string(entity.HookDeliveryRetrying), at.UTC(), r.now().UTC(),
+		string(entity.HookDeliveryDead), point, point)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
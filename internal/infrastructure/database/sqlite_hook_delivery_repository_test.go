@@ -0,0 +1,126 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteHookDeliveryRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "dead-letters", HookDeliveryMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteHookDeliveryRepository(db)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	delivery := &entity.HookDelivery{
+		EventID: "evt_1", Point: "password-reset", Hook: 1, UserID: 7, Email: "lee@example.com",
+		Data:          map[string]interface{}{"token": "secret"},
+		Status:        entity.HookDeliveryRetrying,
+		Attempts:      1,
+		Errors:        []entity.HookAttempt{{Error: "webhook returned 502", At: now}},
+		NextAttemptAt: now.Add(time.Minute),
+	}
+	if err := repo.Create(delivery); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if delivery.ID == 0 || !delivery.CreatedAt.Equal(now) {
+		t.Errorf("Create() set ID %d and CreatedAt %v", delivery.ID, delivery.CreatedAt)
+	}
+
+	got, err := repo.GetByID(delivery.ID)
+	if err != nil || got.EventID != "evt_1" || got.Hook != 1 || got.Data["token"] != "secret" || len(got.Errors) != 1 || got.Errors[0].Error != "webhook returned 502" {
+		t.Fatalf("GetByID() = %+v, %v", got, err)
+	}
+	if _, err := repo.GetByID(99); !errors.Is(err, repository.ErrHookDeliveryNotFound) {
+		t.Errorf("GetByID(99) error = %v, want ErrHookDeliveryNotFound", err)
+	}
+
+	// Not due yet
+	if claimed, err := repo.ClaimDue(now, 10); err != nil || len(claimed) != 0 {
+		t.Errorf("ClaimDue() before the next attempt = %v, %v", claimed, err)
+	}
+	later := now.Add(time.Minute)
+	if count, err := repo.CountDue(later); err != nil || count != 1 {
+		t.Errorf("CountDue() = %d, %v; want 1", count, err)
+	}
+	claimed, err := repo.ClaimDue(later, 10)
+	if err != nil || len(claimed) != 1 || claimed[0].Status != entity.HookDeliveryRunning {
+		t.Fatalf("ClaimDue() = %v, %v", claimed, err)
+	}
+	if again, err := repo.ClaimDue(later, 10); err != nil || len(again) != 0 {
+		t.Errorf("second ClaimDue() = %v, %v; want none", again, err)
+	}
+
+	if err := repo.Replay(delivery.ID, later); !errors.Is(err, repository.ErrHookDeliveryNotDead) {
+		t.Errorf("Replay() running error = %v, want ErrHookDeliveryNotDead", err)
+	}
+
+	dead := claimed[0]
+	dead.Status, dead.Attempts = entity.HookDeliveryDead, 2
+	dead.Errors = append(dead.Errors, entity.HookAttempt{Error: "timeout", At: later})
+	if err := repo.Update(dead); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if listed, err := repo.List(entity.HookDeliveryDead, "", 10); err != nil || len(listed) != 1 || len(listed[0].Errors) != 2 {
+		t.Errorf("List(dead) = %v, %v; want the dead delivery with both errors", listed, err)
+	}
+	if listed, err := repo.List("", "post-register", 10); err != nil || len(listed) != 0 {
+		t.Errorf("List(post-register) = %v, %v; want none", listed, err)
+	}
+
+	// Replaying resets the attempts and keeps the error history
+	if err := repo.Replay(delivery.ID, later); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	replayed, _ := repo.GetByID(delivery.ID)
+	if replayed.Status != entity.HookDeliveryRetrying || replayed.Attempts != 0 || len(replayed.Errors) != 2 || !replayed.NextAttemptAt.Equal(later) {
+		t.Errorf("Replay() = %+v", replayed)
+	}
+	if err := repo.Replay(99, later); !errors.Is(err, repository.ErrHookDeliveryNotFound) {
+		t.Errorf("Replay(99) error = %v, want ErrHookDeliveryNotFound", err)
+	}
+}
+
+func TestSQLiteHookDeliveryRepository_ReplayAll(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "dead-letters", HookDeliveryMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteHookDeliveryRepository(db)
+	now := time.Now().UTC()
+
+	for _, d := range []struct {
+		point  string
+		status entity.HookDeliveryStatus
+	}{
+		{"password-reset", entity.HookDeliveryDead},
+		{"password-reset", entity.HookDeliveryDead},
+		{"post-register", entity.HookDeliveryDead},
+		{"password-reset", entity.HookDeliveryDelivered},
+	} {
+		delivery := &entity.HookDelivery{Point: d.point, Status: d.status, NextAttemptAt: now}
+		if err := repo.Create(delivery); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if replayed, err := repo.ReplayAll("password-reset", now); err != nil || replayed != 2 {
+		t.Errorf("ReplayAll(password-reset) = %d, %v; want 2", replayed, err)
+	}
+	if replayed, err := repo.ReplayAll("", now); err != nil || replayed != 1 {
+		t.Errorf("ReplayAll() = %d, %v; want the remaining post-register delivery", replayed, err)
+	}
+	if count, err := repo.CountDue(now); err != nil || count != 3 {
+		t.Errorf("CountDue() after replaying = %d, %v; want 3", count, err)
+	}
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// InboundEventMigrations create the tables of the inbound webhooks module,
+// applied with MigrateModule
+var InboundEventMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create inbound events table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS inbound_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			provider TEXT NOT NULL,
+			event_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			status TEXT NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			received_at DATETIME NOT NULL,
+			processed_at DATETIME,
+			UNIQUE (provider, event_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_inbound_events_received ON inbound_events (received_at);`,
+	},
+}
+
+// SQLiteInboundEventRepository implements InboundEventRepository interface for SQLite
+type SQLiteInboundEventRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteInboundEventRepository creates a new SQLite inbound event repository
+func NewSQLiteInboundEventRepository(db *sql.DB) *SQLiteInboundEventRepository {
+	return &SQLiteInboundEventRepository{db: db}
+}
+
+// Claim records a received event as processing, unless its provider's event
+// ID was already received and did not fail or stall
+func (r *SQLiteInboundEventRepository) Claim(event *entity.InboundEvent, staleBefore time.Time) (bool, error) {
+	// The unique key makes one replica win a race; the upsert only takes
+	// over a failed or stalled event
+	query := `
+	INSERT INTO inbound_events (provider, event_id, type, subject, status, received_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT (provider, event_id) DO UPDATE SET status = excluded.status, error = '', received_at = excluded.received_at
+	WHERE inbound_events.status = ? OR (inbound_events.status = ? AND inbound_events.received_at < ?)
+	RETURNING id`
+
+	err := r.db.QueryRow(query, event.Provider, event.EventID, event.Type, event.Subject, string(entity.InboundEventProcessing),
+		event.ReceivedAt.UTC(), string(entity.InboundEventFailed), string(entity.InboundEventProcessing), staleBefore.UTC()).Scan(&event.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	event.Status = entity.InboundEventProcessing
+	return true, nil
+}
+
+// Finish records that a claimed event was processed, or failed with errMsg
+func (r *SQLiteInboundEventRepository) Finish(id int, errMsg string, at time.Time) error {
+	status := entity.InboundEventProcessed
+	if errMsg != "" {
+		status = entity.InboundEventFailed
+	}
+	_, err := r.db.Exec(`UPDATE inbound_events SET status = ?, error = ?, processed_at = ? WHERE id = ?`,
+		string(status), errMsg, at.UTC(), id)
+	return err
+}
+
+// DeleteBefore removes the events received before the given time
+func (r *SQLiteInboundEventRepository) DeleteBefore(before time.Time) (int, error) {
+	result, err := r.db.Exec(`DELETE FROM inbound_events WHERE received_at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+func TestSQLiteInboundEventRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "inbound-webhooks", InboundEventMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteInboundEventRepository(db)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	stale := now.Add(-5 * time.Minute)
+	receive := func(provider, id string, at time.Time) (*entity.InboundEvent, bool) {
+		t.Helper()
+		event := &entity.InboundEvent{Provider: provider, EventID: id, Type: "bounce", Subject: "lee@example.com", ReceivedAt: at}
+		claimed, err := repo.Claim(event, at.Add(-5*time.Minute))
+		if err != nil {
+			t.Fatalf("Claim() error = %v", err)
+		}
+		return event, claimed
+	}
+
+	first, claimed := receive("sendgrid", "sg-1", now)
+	if !claimed || first.ID == 0 || first.Status != entity.InboundEventProcessing {
+		t.Fatalf("Claim() = %+v, %v", first, claimed)
+	}
+	// Another replica receiving the same event while it is processed
+	if _, claimed := receive("sendgrid", "sg-1", now); claimed {
+		t.Error("Claim() of an event being processed should report a duplicate")
+	}
+	// The same ID from another provider is another event
+	if _, claimed := receive("stripe", "sg-1", now); !claimed {
+		t.Error("Claim() of another provider's event should succeed")
+	}
+
+	if err := repo.Finish(first.ID, "", now); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	if _, claimed := receive("sendgrid", "sg-1", now.Add(time.Hour)); claimed {
+		t.Error("Claim() of a processed event should report a duplicate")
+	}
+
+	// A failed event is taken over by the provider's retry
+	failed, _ := receive("sendgrid", "sg-2", now)
+	repo.Finish(failed.ID, "store unavailable", now)
+	if retried, claimed := receive("sendgrid", "sg-2", now.Add(time.Minute)); !claimed || retried.ID != failed.ID {
+		t.Errorf("Claim() of a failed event = %+v, %v; want it claimed again", retried, claimed)
+	}
+
+	// So is one whose processing stalled
+	if _, claimed := receive("sendgrid", "sg-3", stale.Add(-time.Second)); !claimed {
+		t.Fatal("Claim() error")
+	}
+	if _, claimed := receive("sendgrid", "sg-3", now); !claimed {
+		t.Error("Claim() of a stalled event should succeed")
+	}
+
+	if deleted, err := repo.DeleteBefore(now.Add(time.Second)); err != nil || deleted != 3 {
+		t.Errorf("DeleteBefore() = %d, %v; want all but the retried sg-2", deleted, err)
+	}
+}
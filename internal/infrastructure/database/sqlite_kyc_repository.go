@@ -0,0 +1,149 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// KYCMigrations create the tables of the KYC module, applied with
+// MigrateModule
+var KYCMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create kyc submissions table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS kyc_submissions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			document_type TEXT NOT NULL,
+			content_type TEXT NOT NULL,
+			document_key TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			status TEXT NOT NULL,
+			reference TEXT NOT NULL DEFAULT '',
+			reason TEXT NOT NULL DEFAULT '',
+			reviewed_by INTEGER NOT NULL DEFAULT 0,
+			reviewed_at DATETIME,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_kyc_submissions_user ON kyc_submissions(user_id, id);
+		CREATE INDEX IF NOT EXISTS idx_kyc_submissions_status ON kyc_submissions(status, id);`,
+	},
+	{
+		Version:     2,
+		Description: "allow one open kyc submission per user",
+		Query:       `CREATE UNIQUE INDEX IF NOT EXISTS idx_kyc_submissions_open ON kyc_submissions(user_id) WHERE status IN ('pending', 'review');`,
+	},
+}
+
+// kycSubmissionColumns lists the columns scanned by scanKYCSubmission
+const kycSubmissionColumns = `id, user_id, document_type, content_type, document_key, size, status, reference, reason, reviewed_by, reviewed_at, created_at, updated_at`
+
+// scanKYCSubmission scans a row selected with kycSubmissionColumns into a submission
+func scanKYCSubmission(row rowScanner) (*entity.KYCSubmission, error) {
+	var submission entity.KYCSubmission
+	var reviewedAt sql.NullTime
+	err := row.Scan(&submission.ID, &submission.UserID, &submission.DocumentType, &submission.ContentType, &submission.DocumentKey,
+		&submission.Size, &submission.Status, &submission.Reference, &submission.Reason, &submission.ReviewedBy, &reviewedAt,
+		&submission.CreatedAt, &submission.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrKYCSubmissionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reviewedAt.Valid {
+		submission.ReviewedAt = &reviewedAt.Time
+	}
+	return &submission, nil
+}
+
+// SQLiteKYCRepository implements KYCRepository interface for SQLite
+type SQLiteKYCRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteKYCRepository creates a new SQLite KYC repository
+func NewSQLiteKYCRepository(db *sql.DB) *SQLiteKYCRepository {
+	return &SQLiteKYCRepository{db: db, now: time.Now}
+}
+
+// Create stores a new submission and sets its ID, CreatedAt and UpdatedAt.
+// The partial unique index refuses a second open submission of a user.
+func (r *SQLiteKYCRepository) Create(submission *entity.KYCSubmission) error {
+	query := `
+	INSERT INTO kyc_submissions (user_id, document_type, content_type, document_key, size, status, reference, reason, reviewed_by, reviewed_at, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	createdAt := r.now().UTC()
+	err := r.db.QueryRow(query, submission.UserID, submission.DocumentType, submission.ContentType, submission.DocumentKey, submission.Size,
+		submission.Status, submission.Reference, submission.Reason, submission.ReviewedBy, submission.ReviewedAt, createdAt, createdAt).Scan(&submission.ID)
+	if isUniqueViolation(err) {
+		return repository.ErrKYCInProgress
+	}
+	if err != nil {
+		return err
+	}
+
+	submission.CreatedAt, submission.UpdatedAt = createdAt, createdAt
+	return nil
+}
+
+// GetByID returns a submission
+func (r *SQLiteKYCRepository) GetByID(id int) (*entity.KYCSubmission, error) {
+	return scanKYCSubmission(r.db.QueryRow(`SELECT `+kycSubmissionColumns+` FROM kyc_submissions WHERE id = ?`, id))
+}
+
+// Latest returns the newest submission of a user
+func (r *SQLiteKYCRepository) Latest(userID int) (*entity.KYCSubmission, error) {
+	return scanKYCSubmission(r.db.QueryRow(`SELECT `+kycSubmissionColumns+` FROM kyc_submissions WHERE user_id = ? ORDER BY id DESC LIMIT 1`, userID))
+}
+
+// List returns up to limit submissions with status, oldest first
+func (r *SQLiteKYCRepository) List(status string, limit int) ([]*entity.KYCSubmission, error) {
+	rows, err := r.db.Query(`SELECT `+kycSubmissionColumns+` FROM kyc_submissions WHERE status = ? ORDER BY id LIMIT ?`, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var submissions []*entity.KYCSubmission
+	for rows.Next() {
+		submission, err := scanKYCSubmission(rows)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, submission)
+	}
+	return submissions, rows.Err()
+}
+
+// Update saves the status and decision of a submission still in status and
+// sets its UpdatedAt. The conditional UPDATE lets one of an admin and the
+// provider decide on a submission both read.
+func (r *SQLiteKYCRepository) Update(submission *entity.KYCSubmission, status string) error {
+	query := `UPDATE kyc_submissions SET status = ?, reference = ?, reason = ?, reviewed_by = ?, reviewed_at = ?, updated_at = ? WHERE id = ? AND status = ?`
+	updatedAt := r.now().UTC()
+	result, err := r.db.Exec(query, submission.Status, submission.Reference, submission.Reason, submission.ReviewedBy, submission.ReviewedAt,
+		updatedAt, submission.ID, status)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrKYCSubmissionDecided
+	}
+
+	submission.UpdatedAt = updatedAt
+	return nil
+}
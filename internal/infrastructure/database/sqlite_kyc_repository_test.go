@@ -0,0 +1,90 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteKYCRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "kyc", KYCMigrations); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewSQLiteKYCRepository(db)
+
+	if _, err := repo.Latest(1); !errors.Is(err, repository.ErrKYCSubmissionNotFound) {
+		t.Fatalf("Latest() without submissions error = %v, want ErrKYCSubmissionNotFound", err)
+	}
+
+	var submissions []*entity.KYCSubmission
+	for _, status := range []string{entity.KYCRejected, entity.KYCReview, entity.KYCPending} {
+		submission := &entity.KYCSubmission{UserID: 1, DocumentType: entity.KYCDocumentPassport, ContentType: "image/png",
+			DocumentKey: "kyc/1/" + status + ".png", Size: 512, Status: status}
+		if status == entity.KYCReview {
+			submission.UserID = 2
+		}
+		if err := repo.Create(submission); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if submission.ID == 0 || submission.CreatedAt.IsZero() {
+			t.Fatalf("Create() = %+v, want an ID and creation time", submission)
+		}
+		submissions = append(submissions, submission)
+	}
+
+	// A user has one open submission at most
+	second := &entity.KYCSubmission{UserID: 1, DocumentType: entity.KYCDocumentPassport, ContentType: "image/png",
+		DocumentKey: "kyc/1/second.png", Size: 512, Status: entity.KYCReview}
+	if err := repo.Create(second); !errors.Is(err, repository.ErrKYCInProgress) {
+		t.Fatalf("Create() of a second open submission error = %v, want ErrKYCInProgress", err)
+	}
+
+	latest, err := repo.Latest(1)
+	if err != nil || latest.ID != submissions[2].ID || latest.DocumentKey != "kyc/1/pending.png" || latest.ReviewedAt != nil {
+		t.Fatalf("Latest() = %+v, %v; want the pending submission", latest, err)
+	}
+
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	latest.Decide(entity.KYCDecision{Status: entity.KYCVerified, Reference: "chk_9"}, 7, now)
+	if err := repo.Update(latest, entity.KYCPending); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := repo.GetByID(latest.ID)
+	if err != nil || got.Status != entity.KYCVerified || got.Reference != "chk_9" || got.ReviewedBy != 7 || got.ReviewedAt == nil || !got.ReviewedAt.Equal(now) {
+		t.Errorf("GetByID() after Update() = %+v, %v", got, err)
+	}
+
+	review, err := repo.List(entity.KYCReview, 10)
+	if err != nil || len(review) != 1 || review[0].UserID != 2 {
+		t.Errorf("List(review) = %+v, %v; want the submission of user 2", review, err)
+	}
+	if pending, err := repo.List(entity.KYCPending, 10); err != nil || len(pending) != 0 {
+		t.Errorf("List(pending) = %+v, %v; want none", pending, err)
+	}
+
+	if _, err := repo.GetByID(999); !errors.Is(err, repository.ErrKYCSubmissionNotFound) {
+		t.Errorf("GetByID() of a missing submission error = %v, want ErrKYCSubmissionNotFound", err)
+	}
+	// A decision on a submission read before it was decided is refused
+	stale := *latest
+	stale.Decide(entity.KYCDecision{Status: entity.KYCRejected, Reason: "blurry"}, 0, now)
+	if err := repo.Update(&stale, entity.KYCPending); !errors.Is(err, repository.ErrKYCSubmissionDecided) {
+		t.Errorf("Update() of a decided submission error = %v, want ErrKYCSubmissionDecided", err)
+	}
+	if got, _ := repo.GetByID(latest.ID); got.Status != entity.KYCVerified {
+		t.Errorf("status after a stale Update() = %q, want verified", got.Status)
+	}
+	if err := repo.Update(&entity.KYCSubmission{ID: 999, Status: entity.KYCRejected}, entity.KYCPending); !errors.Is(err, repository.ErrKYCSubmissionDecided) {
+		t.Errorf("Update() of a missing submission error = %v, want ErrKYCSubmissionDecided", err)
+	}
+
+	// Once decided, the user may submit again
+	if err := repo.Create(second); err != nil {
+		t.Errorf("Create() after a decision error = %v", err)
+	}
+}
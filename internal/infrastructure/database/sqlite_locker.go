@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SQLiteLocker implements Locker with the leases table, for replicas that
+// share the database
+type SQLiteLocker struct {
+	db    *sql.DB
+	owner string
+	now   func() time.Time
+}
+
+// NewSQLiteLocker creates a locker taking leases as owner, which must be
+// unique to the instance
+func NewSQLiteLocker(db *sql.DB, owner string) *SQLiteLocker {
+	return &SQLiteLocker{db: db, owner: owner, now: time.Now}
+}
+
+// Acquire takes the lease on name for ttl, or extends it. A single upsert
+// takes it only when it is free, expired or already this owner's.
+func (l *SQLiteLocker) Acquire(name string, ttl time.Duration) (bool, error) {
+	now := l.now().UTC()
+	result, err := l.db.Exec(`
+	INSERT INTO leases (name, owner, expires_at) VALUES (?, ?, ?)
+	ON CONFLICT (name) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+	WHERE leases.owner = excluded.owner OR leases.expires_at <= ?`,
+		name, l.owner, now.Add(ttl), now)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+// Release gives up the lease on name if this owner holds it
+func (l *SQLiteLocker) Release(name string) error {
+	_, err := l.db.Exec(`DELETE FROM leases WHERE name = ? AND owner = ?`, name, l.owner)
+	return err
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSQLiteLocker(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	a, b := NewSQLiteLocker(db, "a"), NewSQLiteLocker(db, "b")
+	a.now, b.now = clock, clock
+
+	acquire := func(l *SQLiteLocker, want bool) {
+		t.Helper()
+		if held, err := l.Acquire("digests", time.Minute); err != nil || held != want {
+			t.Fatalf("%s.Acquire() = %v, %v; want %v", l.owner, held, err, want)
+		}
+	}
+	acquire(a, true)
+	acquire(b, false)
+	// The holder extends its lease, so it outlives the first ttl
+	now = now.Add(50 * time.Second)
+	acquire(a, true)
+	now = now.Add(50 * time.Second)
+	acquire(b, false)
+	// Other leases are independent
+	if held, err := b.Acquire("backups", time.Minute); err != nil || !held {
+		t.Fatalf("b.Acquire(backups) = %v, %v; want held", held, err)
+	}
+
+	// An expired lease is taken over
+	now = now.Add(time.Minute)
+	acquire(b, true)
+	acquire(a, false)
+
+	// Releasing someone else's lease does nothing; releasing one's own frees it
+	if err := a.Release("digests"); err != nil {
+		t.Fatal(err)
+	}
+	acquire(a, false)
+	if err := b.Release("digests"); err != nil {
+		t.Fatal(err)
+	}
+	acquire(a, true)
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// loginEventColumns lists the login_events columns in the order scanLoginEvent expects them
+const loginEventColumns = `id, user_id, success, ip, device, country, new_device, new_country, created_at`
+
+// scanLoginEvent scans a row selected with loginEventColumns into a login event
+func scanLoginEvent(row rowScanner) (*entity.LoginEvent, error) {
+	var event entity.LoginEvent
+	err := row.Scan(&event.ID, &event.UserID, &event.Success, &event.IP, &event.Device, &event.Country,
+		&event.NewDevice, &event.NewCountry, &event.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// SQLiteLoginEventRepository implements LoginEventRepository interface for SQLite
+type SQLiteLoginEventRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteLoginEventRepository creates a new SQLite login event repository
+func NewSQLiteLoginEventRepository(db *sql.DB) *SQLiteLoginEventRepository {
+	return &SQLiteLoginEventRepository{db: db, now: time.Now}
+}
+
+// Record stores a login attempt and sets its ID, CreatedAt, NewDevice and NewCountry
+func (r *SQLiteLoginEventRepository) Record(event *entity.LoginEvent) error {
+	// The flags are worked out in the INSERT itself, so two logins racing
+	// from a new device cannot both miss each other. A country is only new
+	// once earlier logins had one.
+	query := `
+	INSERT INTO login_events (user_id, success, ip, device, country, new_device, new_country, created_at)
+	SELECT ?1, ?2, ?3, ?4, ?5,
+		?2 AND EXISTS (SELECT 1 FROM login_events WHERE user_id = ?1 AND success)
+			AND NOT EXISTS (SELECT 1 FROM login_events WHERE user_id = ?1 AND success AND device = ?4),
+		?2 AND ?5 != '' AND EXISTS (SELECT 1 FROM login_events WHERE user_id = ?1 AND success AND country != '')
+			AND NOT EXISTS (SELECT 1 FROM login_events WHERE user_id = ?1 AND success AND country = ?5),
+		?6
+	RETURNING id, new_device, new_country`
+
+	createdAt := r.now().UTC()
+	err := r.db.QueryRow(query, event.UserID, event.Success, event.IP, event.Device, event.Country, createdAt).
+		Scan(&event.ID, &event.NewDevice, &event.NewCountry)
+	if err != nil {
+		return err
+	}
+
+	event.CreatedAt = createdAt
+	return nil
+}
+
+// ListByUser returns the user's login attempts since the given time, newest first
+func (r *SQLiteLoginEventRepository) ListByUser(userID int, since time.Time) ([]*entity.LoginEvent, error) {
+	query := `SELECT ` + loginEventColumns + ` FROM login_events WHERE user_id = ? AND created_at >= ? ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, userID, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entity.LoginEvent
+	for rows.Next() {
+		event, err := scanLoginEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// ListSuccessful returns the successful logins of all users at or after
+// since and before until, oldest first
+func (r *SQLiteLoginEventRepository) ListSuccessful(since, until time.Time) ([]*entity.LoginEvent, error) {
+	return r.list(true, since, until)
+}
+
+// ListFailed returns the failed logins of all users at or after since and
+// before until, oldest first
+func (r *SQLiteLoginEventRepository) ListFailed(since, until time.Time) ([]*entity.LoginEvent, error) {
+	return r.list(false, since, until)
+}
+
+// list returns the successful or failed logins in [since, until), oldest first
+func (r *SQLiteLoginEventRepository) list(success bool, since, until time.Time) ([]*entity.LoginEvent, error) {
+	query := `SELECT ` + loginEventColumns + ` FROM login_events WHERE success = ? AND created_at >= ? AND created_at < ? ORDER BY created_at, id`
+
+	rows, err := r.db.Query(query, success, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entity.LoginEvent
+	for rows.Next() {
+		event, err := scanLoginEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
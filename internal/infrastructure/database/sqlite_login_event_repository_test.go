@@ -0,0 +1,102 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+func TestSQLiteLoginEventRepository_RecordAndList(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteLoginEventRepository(db)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	tests := []struct {
+		name        string
+		event       entity.LoginEvent
+		wantDevice  bool
+		wantCountry bool
+	}{
+		{"first login is not new", entity.LoginEvent{Success: true, Device: "laptop", Country: "TH"}, false, false},
+		{"same device and country", entity.LoginEvent{Success: true, Device: "laptop", Country: "TH"}, false, false},
+		{"failed attempts are never new", entity.LoginEvent{Success: false, Device: "bot", Country: "RU"}, false, false},
+		{"new device", entity.LoginEvent{Success: true, Device: "phone", Country: "TH"}, true, false},
+		{"new country", entity.LoginEvent{Success: true, Device: "phone", Country: "JP"}, false, true},
+		{"unknown country", entity.LoginEvent{Success: true, Device: "phone"}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(time.Minute)
+			event := tt.event
+			event.UserID = 1
+			event.IP = "203.0.113.7"
+			if err := repo.Record(&event); err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+			if event.ID == 0 || !event.CreatedAt.Equal(now) {
+				t.Errorf("Record() should set ID and CreatedAt, got %+v", event)
+			}
+			if event.NewDevice != tt.wantDevice || event.NewCountry != tt.wantCountry {
+				t.Errorf("NewDevice/NewCountry = %v/%v, want %v/%v", event.NewDevice, event.NewCountry, tt.wantDevice, tt.wantCountry)
+			}
+		})
+	}
+
+	// Other users' history does not count
+	if err := repo.Record(&entity.LoginEvent{UserID: 2, Success: true, Device: "tablet"}); err != nil {
+		t.Fatal(err)
+	}
+	other := &entity.LoginEvent{UserID: 2, Success: true, Device: "laptop"}
+	if err := repo.Record(other); err != nil || !other.NewDevice {
+		t.Errorf("Record() for another user = %+v, %v; want a new device", other, err)
+	}
+
+	events, err := repo.ListByUser(1, now.Add(-2*time.Minute))
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(events) != 3 || events[0].Country != "" || !events[1].NewCountry || events[2].Device != "phone" {
+		t.Errorf("ListByUser() = %+v, want the last three attempts newest first", events)
+	}
+}
+
+func TestSQLiteLoginEventRepository_ListSuccessful(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteLoginEventRepository(db)
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, event := range []entity.LoginEvent{
+		{UserID: 1, Success: true},
+		{UserID: 2, Success: true},
+		{UserID: 1, Success: false},
+		{UserID: 3, Success: true},
+		{UserID: 1, Success: true},
+	} {
+		repo.now = func() time.Time { return start.Add(time.Duration(i) * time.Minute) }
+		if err := repo.Record(&event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The window includes its start and excludes its end
+	events, err := repo.ListSuccessful(start.Add(time.Minute), start.Add(4*time.Minute))
+	if err != nil {
+		t.Fatalf("ListSuccessful() error = %v", err)
+	}
+	if len(events) != 2 || events[0].UserID != 2 || events[1].UserID != 3 {
+		t.Errorf("ListSuccessful() = %+v, want the logins of users 2 and 3", events)
+	}
+
+	events, err = repo.ListFailed(start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ListFailed() error = %v", err)
+	}
+	if len(events) != 1 || events[0].UserID != 1 || events[0].Success {
+		t.Errorf("ListFailed() = %+v, want the failed login of user 1", events)
+	}
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// oneTimeTokenColumns lists the one_time_tokens columns in the order scanOneTimeToken expects them
+const oneTimeTokenColumns = `id, purpose, user_id, token_hash, expires_at, consumed_at, created_at`
+
+// scanOneTimeToken scans a row selected with oneTimeTokenColumns into a token
+func scanOneTimeToken(row rowScanner) (*entity.OneTimeToken, error) {
+	var token entity.OneTimeToken
+	var purpose string
+	var consumedAt sql.NullTime
+	err := row.Scan(&token.ID, &purpose, &token.UserID, &token.TokenHash, &token.ExpiresAt, &consumedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	token.Purpose = entity.TokenPurpose(purpose)
+	if consumedAt.Valid {
+		token.ConsumedAt = &consumedAt.Time
+	}
+	return &token, nil
+}
+
+// SQLiteOneTimeTokenRepository implements OneTimeTokenRepository interface for SQLite
+type SQLiteOneTimeTokenRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteOneTimeTokenRepository creates a new SQLite one-time token repository
+func NewSQLiteOneTimeTokenRepository(db *sql.DB) *SQLiteOneTimeTokenRepository {
+	return &SQLiteOneTimeTokenRepository{db: db, now: time.Now}
+}
+
+// Create stores a token and sets its ID and CreatedAt
+func (r *SQLiteOneTimeTokenRepository) Create(token *entity.OneTimeToken) error {
+	query := `
+	INSERT INTO one_time_tokens (purpose, user_id, token_hash, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?)
+	RETURNING id`
+
+	createdAt := r.now().UTC()
+	err := r.db.QueryRow(query, string(token.Purpose), token.UserID, token.TokenHash, token.ExpiresAt.UTC(), createdAt).Scan(&token.ID)
+	if err != nil {
+		return err
+	}
+
+	token.CreatedAt = createdAt
+	return nil
+}
+
+// Consume marks the token with the given purpose and hash as used at now and returns it
+func (r *SQLiteOneTimeTokenRepository) Consume(purpose entity.TokenPurpose, tokenHash string, now time.Time) (*entity.OneTimeToken, error) {
+	// A single conditional UPDATE ... RETURNING lets only one caller consume the token
+	query := `
+	UPDATE one_time_tokens SET consumed_at = ?
+	WHERE purpose = ? AND token_hash = ? AND consumed_at IS NULL AND expires_at > ?
+	RETURNING ` + oneTimeTokenColumns
+
+	token, err := scanOneTimeToken(r.db.QueryRow(query, now.UTC(), string(purpose), tokenHash, now.UTC()))
+	if !errors.Is(err, sql.ErrNoRows) {
+		return token, err
+	}
+
+	// Nothing was consumed; tell an unknown token apart from a used or expired one
+	query = `SELECT ` + oneTimeTokenColumns + ` FROM one_time_tokens WHERE purpose = ? AND token_hash = ?`
+	token, err = scanOneTimeToken(r.db.QueryRow(query, string(purpose), tokenHash))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repository.ErrTokenNotFound
+	case err != nil:
+		return nil, err
+	case token.ConsumedAt != nil:
+		return nil, repository.ErrTokenAlreadyUsed
+	default:
+		return nil, repository.ErrTokenExpired
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteOneTimeTokenRepository_Consume(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteOneTimeTokenRepository(db)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, token := range []*entity.OneTimeToken{
+		{Purpose: entity.TokenPurposePasswordReset, UserID: 1, TokenHash: "fresh", ExpiresAt: now.Add(time.Hour)},
+		{Purpose: entity.TokenPurposePasswordReset, UserID: 1, TokenHash: "stale", ExpiresAt: now.Add(-time.Second)},
+	} {
+		if err := repo.Create(token); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if token.ID == 0 || token.CreatedAt.IsZero() {
+			t.Errorf("Create() should set ID and CreatedAt, got %+v", token)
+		}
+	}
+
+	// A token only works for the purpose it was issued for
+	if _, err := repo.Consume(entity.TokenPurposeMagicLink, "fresh", now); !errors.Is(err, repository.ErrTokenNotFound) {
+		t.Errorf("Consume() with another purpose error = %v, want ErrTokenNotFound", err)
+	}
+
+	token, err := repo.Consume(entity.TokenPurposePasswordReset, "fresh", now)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if token.UserID != 1 || token.ConsumedAt == nil || !token.ConsumedAt.Equal(now) {
+		t.Errorf("Consume() = %+v", token)
+	}
+
+	tests := []struct {
+		hash string
+		want error
+	}{
+		{"fresh", repository.ErrTokenAlreadyUsed},
+		{"stale", repository.ErrTokenExpired},
+		{"missing", repository.ErrTokenNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := repo.Consume(entity.TokenPurposePasswordReset, tt.hash, now); !errors.Is(err, tt.want) {
+			t.Errorf("Consume(%q) error = %v, want %v", tt.hash, err, tt.want)
+		}
+	}
+}
+
+func TestSQLiteOneTimeTokenRepository_ConsumeConcurrently(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteOneTimeTokenRepository(db)
+	now := time.Now()
+	if err := repo.Create(&entity.OneTimeToken{Purpose: entity.TokenPurposeInvite, UserID: 1, TokenHash: "race", ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	consumed, used := 0, 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Consume(entity.TokenPurposeInvite, "race", now)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				consumed++
+			case errors.Is(err, repository.ErrTokenAlreadyUsed):
+				used++
+			default:
+				t.Errorf("Consume() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if consumed != 1 || used != 9 {
+		t.Errorf("consumed %d times and rejected %d, want 1 and 9", consumed, used)
+	}
+}
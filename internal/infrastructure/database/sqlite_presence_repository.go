@@ -0,0 +1,94 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// PresenceMigrations create the tables of the presence module, applied with
+// MigrateModule
+var PresenceMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create user presence table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS user_presence (
+			user_id INTEGER PRIMARY KEY,
+			last_seen_at DATETIME,
+			visibility TEXT NOT NULL DEFAULT 'everyone'
+		);`,
+	},
+}
+
+// SQLitePresenceRepository implements PresenceRepository interface for SQLite
+type SQLitePresenceRepository struct {
+	db *sql.DB
+}
+
+// NewSQLitePresenceRepository creates a new SQLite presence repository
+func NewSQLitePresenceRepository(db *sql.DB) *SQLitePresenceRepository {
+	return &SQLitePresenceRepository{db: db}
+}
+
+// Touch upserts the last seen times in one transaction. Times are stored to
+// the second, which keeps them comparable as text.
+func (r *SQLitePresenceRepository) Touch(lastSeen map[int]time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	for userID, seenAt := range lastSeen {
+		_, err := tx.Exec(`
+		INSERT INTO user_presence (user_id, last_seen_at) VALUES (?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET last_seen_at = excluded.last_seen_at
+		WHERE last_seen_at IS NULL OR last_seen_at < excluded.last_seen_at`,
+			userID, seenAt.UTC().Truncate(time.Second))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Get returns the presence rows of userIDs
+func (r *SQLitePresenceRepository) Get(userIDs []int) ([]*entity.Presence, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	placeholders := make([]string, len(userIDs))
+	args := make([]interface{}, len(userIDs))
+	for i, userID := range userIDs {
+		placeholders[i] = "?"
+		args[i] = userID
+	}
+	rows, err := r.db.Query(`SELECT user_id, last_seen_at, visibility FROM user_presence WHERE user_id IN (`+strings.Join(placeholders, ", ")+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var presences []*entity.Presence
+	for rows.Next() {
+		var presence entity.Presence
+		var lastSeenAt sql.NullTime
+		if err := rows.Scan(&presence.UserID, &lastSeenAt, &presence.Visibility); err != nil {
+			return nil, err
+		}
+		presence.LastSeenAt = lastSeenAt.Time
+		presences = append(presences, &presence)
+	}
+	return presences, rows.Err()
+}
+
+// SetVisibility upserts the visibility of a user's presence
+func (r *SQLitePresenceRepository) SetVisibility(userID int, visibility entity.PresenceVisibility) error {
+	_, err := r.db.Exec(`
+	INSERT INTO user_presence (user_id, visibility) VALUES (?, ?)
+	ON CONFLICT (user_id) DO UPDATE SET visibility = excluded.visibility`,
+		userID, visibility)
+	return err
+}
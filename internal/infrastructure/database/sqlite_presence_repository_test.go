@@ -0,0 +1,58 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+func TestSQLitePresenceRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "presence", PresenceMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLitePresenceRepository(db)
+	seen := time.Date(2025, 6, 3, 8, 0, 0, 0, time.UTC)
+	if err := repo.Touch(map[int]time.Time{1: seen, 2: seen.Add(-time.Hour)}); err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+	// An older time flushed late by another node is ignored
+	if err := repo.Touch(map[int]time.Time{1: seen.Add(-time.Minute), 2: seen.Add(500 * time.Millisecond)}); err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+	if err := repo.SetVisibility(1, entity.PresenceVisibleNobody); err != nil {
+		t.Fatalf("SetVisibility() error = %v", err)
+	}
+	if err := repo.SetVisibility(3, entity.PresenceVisibleStatus); err != nil {
+		t.Fatalf("SetVisibility() error = %v", err)
+	}
+
+	presences, err := repo.Get([]int{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got := make(map[int]*entity.Presence)
+	for _, presence := range presences {
+		got[presence.UserID] = presence
+	}
+	want := map[int]entity.Presence{
+		1: {UserID: 1, LastSeenAt: seen, Visibility: entity.PresenceVisibleNobody},
+		2: {UserID: 2, LastSeenAt: seen, Visibility: entity.PresenceVisibleEveryone},
+		3: {UserID: 3, Visibility: entity.PresenceVisibleStatus},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Get() returned %d users, want %d", len(got), len(want))
+	}
+	for userID, w := range want {
+		if p := got[userID]; p == nil || !p.LastSeenAt.Equal(w.LastSeenAt) || p.Visibility != w.Visibility {
+			t.Errorf("Get()[%d] = %+v, want %+v", userID, p, w)
+		}
+	}
+
+	if presences, err := repo.Get(nil); err != nil || len(presences) != 0 {
+		t.Errorf("Get(nil) = %v, %v; want none", presences, err)
+	}
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// QRLoginMigrations create the tables of the QR login module, applied with
+// MigrateModule
+var QRLoginMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create QR logins table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS qr_logins (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			challenge_hash TEXT NOT NULL UNIQUE,
+			poll_hash TEXT NOT NULL UNIQUE,
+			status TEXT NOT NULL,
+			user_id INTEGER NOT NULL DEFAULT 0,
+			ip TEXT NOT NULL,
+			device TEXT NOT NULL,
+			country TEXT NOT NULL,
+			expires_at DATETIME NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_qr_logins_expires ON qr_logins (expires_at);`,
+	},
+}
+
+// qrLoginColumns lists the qr_logins columns in the order scanQRLogin expects them
+const qrLoginColumns = `id, challenge_hash, poll_hash, status, user_id, ip, device, country, expires_at, created_at`
+
+// scanQRLogin scans a row selected with qrLoginColumns into a login
+func scanQRLogin(row rowScanner) (*entity.QRLogin, error) {
+	var login entity.QRLogin
+	var status string
+	err := row.Scan(&login.ID, &login.ChallengeHash, &login.PollHash, &status, &login.UserID,
+		&login.IP, &login.Device, &login.Country, &login.ExpiresAt, &login.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrQRLoginNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	login.Status = entity.QRLoginStatus(status)
+	return &login, nil
+}
+
+// SQLiteQRLoginRepository implements QRLoginRepository interface for SQLite
+type SQLiteQRLoginRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteQRLoginRepository creates a new SQLite QR login repository
+func NewSQLiteQRLoginRepository(db *sql.DB) *SQLiteQRLoginRepository {
+	return &SQLiteQRLoginRepository{db: db, now: time.Now}
+}
+
+// Create stores a login and sets its ID and CreatedAt
+func (r *SQLiteQRLoginRepository) Create(login *entity.QRLogin) error {
+	query := `
+	INSERT INTO qr_logins (challenge_hash, poll_hash, status, user_id, ip, device, country, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	createdAt := r.now().UTC()
+	err := r.db.QueryRow(query, login.ChallengeHash, login.PollHash, string(login.Status), login.UserID,
+		login.IP, login.Device, login.Country, login.ExpiresAt.UTC(), createdAt).Scan(&login.ID)
+	if err != nil {
+		return err
+	}
+
+	login.CreatedAt = createdAt
+	return nil
+}
+
+// GetByChallenge returns the login with the given challenge hash
+func (r *SQLiteQRLoginRepository) GetByChallenge(challengeHash string) (*entity.QRLogin, error) {
+	return scanQRLogin(r.db.QueryRow(`SELECT `+qrLoginColumns+` FROM qr_logins WHERE challenge_hash = ?`, challengeHash))
+}
+
+// GetByPoll returns the login with the given poll token hash
+func (r *SQLiteQRLoginRepository) GetByPoll(pollHash string) (*entity.QRLogin, error) {
+	return scanQRLogin(r.db.QueryRow(`SELECT `+qrLoginColumns+` FROM qr_logins WHERE poll_hash = ?`, pollHash))
+}
+
+// Decide moves a pending, unexpired login to approved or denied by userID
+func (r *SQLiteQRLoginRepository) Decide(id int, status entity.QRLoginStatus, userID int, now time.Time) error {
+	result, err := r.db.Exec(`UPDATE qr_logins SET status = ?, user_id = ? WHERE id = ? AND status = ? AND expires_at > ?`,
+		string(status), userID, id, string(entity.QRLoginPending), now.UTC())
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return repository.ErrQRLoginNotPending
+	}
+	return nil
+}
+
+// Complete moves an approved, unexpired login to completed. The conditional
+// UPDATE lets only one caller complete it.
+func (r *SQLiteQRLoginRepository) Complete(id int, now time.Time) (bool, error) {
+	result, err := r.db.Exec(`UPDATE qr_logins SET status = ? WHERE id = ? AND status = ? AND expires_at > ?`,
+		string(entity.QRLoginCompleted), id, string(entity.QRLoginApproved), now.UTC())
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+// DeleteExpired removes the logins that expired before the given time
+func (r *SQLiteQRLoginRepository) DeleteExpired(before time.Time) (int, error) {
+	result, err := r.db.Exec(`DELETE FROM qr_logins WHERE expires_at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
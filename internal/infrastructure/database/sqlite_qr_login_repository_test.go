@@ -0,0 +1,76 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteQRLoginRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "qr-login", QRLoginMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteQRLoginRepository(db)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	login := &entity.QRLogin{ChallengeHash: "challenge", PollHash: "poll", Status: entity.QRLoginPending,
+		IP: "203.0.113.7", Device: "Firefox", ExpiresAt: now.Add(2 * time.Minute)}
+	if err := repo.Create(login); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if login.ID == 0 || !login.CreatedAt.Equal(now) {
+		t.Errorf("Create() set ID %d and CreatedAt %v", login.ID, login.CreatedAt)
+	}
+
+	got, err := repo.GetByChallenge("challenge")
+	if err != nil || got.ID != login.ID || got.PollHash != "poll" || got.Status != entity.QRLoginPending || got.Device != "Firefox" {
+		t.Fatalf("GetByChallenge() = %+v, %v", got, err)
+	}
+	if _, err := repo.GetByPoll("challenge"); !errors.Is(err, repository.ErrQRLoginNotFound) {
+		t.Errorf("GetByPoll(challenge) error = %v, want ErrQRLoginNotFound", err)
+	}
+
+	// Not approved yet
+	if completed, err := repo.Complete(login.ID, now); err != nil || completed {
+		t.Errorf("Complete() pending = %v, %v; want false", completed, err)
+	}
+	if err := repo.Decide(login.ID, entity.QRLoginApproved, 7, now); err != nil {
+		t.Fatalf("Decide() error = %v", err)
+	}
+	if err := repo.Decide(login.ID, entity.QRLoginDenied, 8, now); !errors.Is(err, repository.ErrQRLoginNotPending) {
+		t.Errorf("Decide() again error = %v, want ErrQRLoginNotPending", err)
+	}
+	if got, _ := repo.GetByPoll("poll"); got.Status != entity.QRLoginApproved || got.UserID != 7 {
+		t.Errorf("after Decide = %+v", got)
+	}
+
+	// Completed once
+	if completed, err := repo.Complete(login.ID, now); err != nil || !completed {
+		t.Fatalf("Complete() = %v, %v; want true", completed, err)
+	}
+	if completed, err := repo.Complete(login.ID, now); err != nil || completed {
+		t.Errorf("Complete() again = %v, %v; want false", completed, err)
+	}
+
+	// Expired logins cannot be decided and are deleted
+	expired := &entity.QRLogin{ChallengeHash: "old", PollHash: "old-poll", Status: entity.QRLoginPending, ExpiresAt: now.Add(-time.Minute)}
+	if err := repo.Create(expired); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Decide(expired.ID, entity.QRLoginApproved, 7, now); !errors.Is(err, repository.ErrQRLoginNotPending) {
+		t.Errorf("Decide() expired error = %v, want ErrQRLoginNotPending", err)
+	}
+	if deleted, err := repo.DeleteExpired(now); err != nil || deleted != 1 {
+		t.Errorf("DeleteExpired() = %d, %v; want 1", deleted, err)
+	}
+	if _, err := repo.GetByChallenge("old"); !errors.Is(err, repository.ErrQRLoginNotFound) {
+		t.Errorf("GetByChallenge(old) error = %v, want ErrQRLoginNotFound", err)
+	}
+}
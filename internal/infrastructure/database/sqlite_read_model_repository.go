@@ -0,0 +1,276 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ReadModelMigrations create the tables of the read models module, applied
+// with MigrateModule
+var ReadModelMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create read model tables",
+		Query: `
+		CREATE TABLE IF NOT EXISTS projection_positions (
+			name TEXT PRIMARY KEY,
+			position INTEGER NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS user_search (
+			user_id INTEGER PRIMARY KEY,
+			email TEXT NOT NULL,
+			full_name TEXT NOT NULL,
+			role TEXT NOT NULL,
+			status TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_user_search_role ON user_search(role);
+		CREATE INDEX IF NOT EXISTS idx_user_search_status ON user_search(status);
+		CREATE INDEX IF NOT EXISTS idx_user_search_created_at ON user_search(created_at);
+		CREATE TABLE IF NOT EXISTS login_stats (
+			user_id INTEGER PRIMARY KEY,
+			logins INTEGER NOT NULL,
+			password_logins INTEGER NOT NULL,
+			passwordless_logins INTEGER NOT NULL,
+			first_login_at DATETIME NOT NULL,
+			last_login_at DATETIME NOT NULL
+		);`,
+	},
+}
+
+// projectionTables maps each projection to the table it fills
+var projectionTables = map[string]string{
+	entity.ProjectionUserSearch: "user_search",
+	entity.ProjectionLoginStats: "login_stats",
+}
+
+// SQLiteReadModelRepository implements ReadModelRepository interface for SQLite
+type SQLiteReadModelRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteReadModelRepository creates a new SQLite read model repository
+func NewSQLiteReadModelRepository(db *sql.DB) *SQLiteReadModelRepository {
+	return &SQLiteReadModelRepository{db: db, now: time.Now}
+}
+
+// Position returns the ID of the last event a projection applied
+func (r *SQLiteReadModelRepository) Position(projection string) (int64, bool, error) {
+	var position int64
+	err := r.db.QueryRow(`SELECT position FROM projection_positions WHERE name = ?`, projection).Scan(&position)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return position, true, nil
+}
+
+// Statuses returns the position of every projection that has run, by name
+func (r *SQLiteReadModelRepository) Statuses() ([]*entity.ProjectionStatus, error) {
+	rows, err := r.db.Query(`SELECT name, position, updated_at FROM projection_positions ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statuses []*entity.ProjectionStatus
+	for rows.Next() {
+		var status entity.ProjectionStatus
+		if err := rows.Scan(&status.Name, &status.Position, &status.UpdatedAt); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, &status)
+	}
+	return statuses, rows.Err()
+}
+
+// savePosition moves a projection to position within tx
+func (r *SQLiteReadModelRepository) savePosition(tx *sql.Tx, projection string, position int64) error {
+	_, err := tx.Exec(`
+	INSERT INTO projection_positions (name, position, updated_at) VALUES (?, ?, ?)
+	ON CONFLICT (name) DO UPDATE SET position = excluded.position, updated_at = excluded.updated_at`,
+		projection, position, r.now().UTC())
+	return err
+}
+
+// inTx runs fn in a transaction, committing when it succeeds
+func (r *SQLiteReadModelRepository) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveUserSearch upserts and removes user_search rows and moves the
+// user_search projection to position, in one transaction
+func (r *SQLiteReadModelRepository) SaveUserSearch(position int64, entries []*entity.UserSearchEntry, removed []int) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		for _, entry := range entries {
+			_, err := tx.Exec(`
+			INSERT INTO user_search (user_id, email, full_name, role, status, created_at) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (user_id) DO UPDATE SET
+				email = excluded.email,
+				full_name = excluded.full_name,
+				role = excluded.role,
+				status = excluded.status,
+				created_at = excluded.created_at`,
+				entry.UserID, entry.Email, entry.FullName, entry.Role, entry.Status, entry.CreatedAt.UTC())
+			if err != nil {
+				return err
+			}
+		}
+		for _, userID := range removed {
+			if _, err := tx.Exec(`DELETE FROM user_search WHERE user_id = ?`, userID); err != nil {
+				return err
+			}
+		}
+		return r.savePosition(tx, entity.ProjectionUserSearch, position)
+	})
+}
+
+// AddLoginStats adds stats to the users' login_stats rows, removes those of
+// removed users and moves the login_stats projection to position, in one
+// transaction
+func (r *SQLiteReadModelRepository) AddLoginStats(position int64, stats []*entity.LoginStats, removed []int) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		for _, added := range stats {
+			var current entity.LoginStats
+			err := tx.QueryRow(`
+			SELECT logins, password_logins, passwordless_logins, first_login_at, last_login_at
+			FROM login_stats WHERE user_id = ?`, added.UserID).Scan(
+				&current.Logins, &current.PasswordLogins, &current.PasswordlessLogins, &current.FirstLoginAt, &current.LastLoginAt)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+
+			merged := *added
+			if err == nil {
+				merged.Logins += current.Logins
+				merged.PasswordLogins += current.PasswordLogins
+				merged.PasswordlessLogins += current.PasswordlessLogins
+				if current.FirstLoginAt.Before(merged.FirstLoginAt) {
+					merged.FirstLoginAt = current.FirstLoginAt
+				}
+				if current.LastLoginAt.After(merged.LastLoginAt) {
+					merged.LastLoginAt = current.LastLoginAt
+				}
+			}
+			_, err = tx.Exec(`
+			INSERT OR REPLACE INTO login_stats (user_id, logins, password_logins, passwordless_logins, first_login_at, last_login_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+				merged.UserID, merged.Logins, merged.PasswordLogins, merged.PasswordlessLogins, merged.FirstLoginAt.UTC(), merged.LastLoginAt.UTC())
+			if err != nil {
+				return err
+			}
+		}
+		for _, userID := range removed {
+			if _, err := tx.Exec(`DELETE FROM login_stats WHERE user_id = ?`, userID); err != nil {
+				return err
+			}
+		}
+		return r.savePosition(tx, entity.ProjectionLoginStats, position)
+	})
+}
+
+// Reset empties a projection's read model and forgets its position
+func (r *SQLiteReadModelRepository) Reset(projection string) error {
+	table, ok := projectionTables[projection]
+	if !ok {
+		return fmt.Errorf("unknown projection %q", projection)
+	}
+	return r.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`DELETE FROM projection_positions WHERE name = ?`, projection)
+		return err
+	})
+}
+
+// SearchUsers returns the users in user_search matching the filter, ordered
+// by ID, within the page, with their login stats, and the number of matches
+func (r *SQLiteReadModelRepository) SearchUsers(filter repository.UserFilter, page repository.Page) ([]*entity.UserSummary, int, error) {
+	page = page.Normalize()
+	// user_search has the users table's column names, so its filter applies
+	where, args := userFilterClause(filter)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM user_search`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(userSummaryQuery+where+`
+	ORDER BY s.user_id LIMIT ? OFFSET ?`, append(args, page.Limit, page.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	users, err := scanUserSummaries(rows)
+	return users, total, err
+}
+
+// ListUsers returns up to limit users in user_search matching the filter
+// with an ID above afterID, ordered by ID, with their login stats
+func (r *SQLiteReadModelRepository) ListUsers(filter repository.UserFilter, afterID, limit int) ([]*entity.UserSummary, error) {
+	where, args := userFilterClause(filter)
+	if where == "" {
+		where = " WHERE s.user_id > ?"
+	} else {
+		where += " AND s.user_id > ?"
+	}
+
+	rows, err := r.db.Query(userSummaryQuery+where+`
+	ORDER BY s.user_id LIMIT ?`, append(args, afterID, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	return scanUserSummaries(rows)
+}
+
+// userSummaryQuery selects user_search rows with their login stats, for a
+// WHERE clause to be appended
+const userSummaryQuery = `
+	SELECT s.user_id, s.email, s.full_name, s.role, s.status, s.created_at,
+		l.logins, l.password_logins, l.passwordless_logins, l.first_login_at, l.last_login_at
+	FROM user_search s LEFT JOIN login_stats l ON l.user_id = s.user_id`
+
+// scanUserSummaries reads the rows of userSummaryQuery and closes them
+func scanUserSummaries(rows *sql.Rows) ([]*entity.UserSummary, error) {
+	defer rows.Close()
+
+	users := []*entity.UserSummary{}
+	for rows.Next() {
+		var user entity.UserSummary
+		var logins, passwordLogins, passwordlessLogins sql.NullInt64
+		var firstLoginAt, lastLoginAt sql.NullTime
+		err := rows.Scan(&user.UserID, &user.Email, &user.FullName, &user.Role, &user.Status, &user.CreatedAt,
+			&logins, &passwordLogins, &passwordlessLogins, &firstLoginAt, &lastLoginAt)
+		if err != nil {
+			return nil, err
+		}
+		if logins.Valid {
+			user.LoginStats = &entity.LoginStats{
+				UserID:             user.UserID,
+				Logins:             int(logins.Int64),
+				PasswordLogins:     int(passwordLogins.Int64),
+				PasswordlessLogins: int(passwordlessLogins.Int64),
+				FirstLoginAt:       firstLoginAt.Time,
+				LastLoginAt:        lastLoginAt.Time,
+			}
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
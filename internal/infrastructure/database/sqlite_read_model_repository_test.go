@@ -0,0 +1,172 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func setupReadModelRepository(t *testing.T) *SQLiteReadModelRepository {
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "read-models", ReadModelMigrations); err != nil {
+		t.Fatal(err)
+	}
+	return NewSQLiteReadModelRepository(db)
+}
+
+func TestSQLiteReadModelRepository_UserSearch(t *testing.T) {
+	t.Parallel()
+	repo := setupReadModelRepository(t)
+
+	if _, ok, err := repo.Position(entity.ProjectionUserSearch); err != nil || ok {
+		t.Fatalf("Position() before the first save = %v, %v; want not ok", ok, err)
+	}
+
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	entries := []*entity.UserSearchEntry{
+		{UserID: 1, Email: "ann@example.com", FullName: "Ann Lee", Role: entity.RoleAdmin, Status: entity.StatusActive, CreatedAt: created},
+		{UserID: 2, Email: "bob@example.com", FullName: "Bob Stone", Role: entity.RoleUser, Status: entity.StatusActive, CreatedAt: created.AddDate(0, 0, 1)},
+		{UserID: 3, Email: "cat@example.com", FullName: "Cat Lee", Role: entity.RoleUser, Status: entity.StatusActive, CreatedAt: created.AddDate(0, 0, 2)},
+	}
+	if err := repo.SaveUserSearch(5, entries, nil); err != nil {
+		t.Fatalf("SaveUserSearch() error = %v", err)
+	}
+	// Later changes replace rows and remove deleted users
+	suspended := *entries[2]
+	suspended.Status = entity.StatusSuspended
+	if err := repo.SaveUserSearch(8, []*entity.UserSearchEntry{&suspended}, []int{2}); err != nil {
+		t.Fatalf("SaveUserSearch() error = %v", err)
+	}
+	if position, ok, err := repo.Position(entity.ProjectionUserSearch); err != nil || !ok || position != 8 {
+		t.Errorf("Position() = %d, %v, %v; want 8", position, ok, err)
+	}
+
+	tests := []struct {
+		name   string
+		filter repository.UserFilter
+		want   []int
+	}{
+		{"all", repository.UserFilter{}, []int{1, 3}},
+		{"by name", repository.UserFilter{Search: "lee"}, []int{1, 3}},
+		{"by email", repository.UserFilter{Search: "CAT@"}, []int{3}},
+		{"by status", repository.UserFilter{Status: entity.StatusSuspended}, []int{3}},
+		{"by role", repository.UserFilter{Role: entity.RoleAdmin}, []int{1}},
+		{"removed user", repository.UserFilter{Search: "bob"}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, total, err := repo.SearchUsers(tt.filter, repository.Page{})
+			if err != nil {
+				t.Fatalf("SearchUsers() error = %v", err)
+			}
+			ids := []int{}
+			for _, user := range users {
+				ids = append(ids, user.UserID)
+			}
+			if total != len(tt.want) || len(ids) != len(tt.want) {
+				t.Fatalf("SearchUsers() = %v (total %d), want %v", ids, total, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Errorf("SearchUsers() = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+
+	listTests := []struct {
+		name    string
+		filter  repository.UserFilter
+		afterID int
+		limit   int
+		want    []int
+	}{
+		{"first batch", repository.UserFilter{}, 0, 1, []int{1}},
+		{"next batch", repository.UserFilter{}, 1, 1, []int{3}},
+		{"past the end", repository.UserFilter{}, 3, 1, []int{}},
+		{"filtered", repository.UserFilter{Search: "lee"}, 1, 10, []int{3}},
+	}
+	for _, tt := range listTests {
+		t.Run("list "+tt.name, func(t *testing.T) {
+			users, err := repo.ListUsers(tt.filter, tt.afterID, tt.limit)
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			ids := []int{}
+			for _, user := range users {
+				ids = append(ids, user.UserID)
+			}
+			if len(ids) != len(tt.want) || (len(ids) > 0 && ids[0] != tt.want[0]) {
+				t.Errorf("ListUsers() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	if err := repo.Reset(entity.ProjectionUserSearch); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if _, ok, _ := repo.Position(entity.ProjectionUserSearch); ok {
+		t.Error("Reset() should forget the position")
+	}
+	if users, total, _ := repo.SearchUsers(repository.UserFilter{}, repository.Page{}); len(users) != 0 || total != 0 {
+		t.Errorf("SearchUsers() after Reset() = %+v", users)
+	}
+	if err := repo.Reset("unknown"); err == nil {
+		t.Error("Reset() of an unknown projection should fail")
+	}
+}
+
+func TestSQLiteReadModelRepository_LoginStats(t *testing.T) {
+	t.Parallel()
+	repo := setupReadModelRepository(t)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+	if err := repo.SaveUserSearch(1, []*entity.UserSearchEntry{
+		{UserID: 1, Email: "ann@example.com", Role: entity.RoleUser, Status: entity.StatusActive, CreatedAt: now},
+		{UserID: 2, Email: "bob@example.com", Role: entity.RoleUser, Status: entity.StatusActive, CreatedAt: now},
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	first := now.Add(-2 * time.Hour)
+	if err := repo.AddLoginStats(3, []*entity.LoginStats{
+		{UserID: 1, Logins: 2, PasswordLogins: 2, FirstLoginAt: first, LastLoginAt: first.Add(time.Minute)},
+	}, nil); err != nil {
+		t.Fatalf("AddLoginStats() error = %v", err)
+	}
+	if err := repo.AddLoginStats(4, []*entity.LoginStats{
+		{UserID: 1, Logins: 1, PasswordlessLogins: 1, FirstLoginAt: now, LastLoginAt: now},
+	}, nil); err != nil {
+		t.Fatalf("AddLoginStats() error = %v", err)
+	}
+
+	users, _, err := repo.SearchUsers(repository.UserFilter{}, repository.Page{})
+	if err != nil || len(users) != 2 {
+		t.Fatalf("SearchUsers() = %+v, %v", users, err)
+	}
+	stats := users[0].LoginStats
+	if stats == nil || stats.Logins != 3 || stats.PasswordLogins != 2 || stats.PasswordlessLogins != 1 ||
+		!stats.FirstLoginAt.Equal(first) || !stats.LastLoginAt.Equal(now) {
+		t.Errorf("login stats of user 1 = %+v", stats)
+	}
+	if users[1].LoginStats != nil {
+		t.Errorf("user 2 never logged in, got %+v", users[1].LoginStats)
+	}
+
+	if err := repo.AddLoginStats(6, nil, []int{1}); err != nil {
+		t.Fatalf("AddLoginStats() error = %v", err)
+	}
+	statuses, err := repo.Statuses()
+	if err != nil || len(statuses) != 2 {
+		t.Fatalf("Statuses() = %+v, %v", statuses, err)
+	}
+	if got := statuses[0]; got.Name != entity.ProjectionLoginStats || got.Position != 6 || !got.UpdatedAt.Equal(now) {
+		t.Errorf("login_stats status = %+v", got)
+	}
+	if users, _, _ := repo.SearchUsers(repository.UserFilter{}, repository.Page{}); users[0].LoginStats != nil {
+		t.Errorf("removed login stats = %+v", users[0].LoginStats)
+	}
+}
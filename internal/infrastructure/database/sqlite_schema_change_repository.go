@@ -0,0 +1,221 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// SchemaChangeMigrations create the tables of the schema changes module,
+// applied with MigrateModule
+var SchemaChangeMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create schema changes table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS schema_changes (
+			name TEXT PRIMARY KEY,
+			phase TEXT NOT NULL,
+			cursor INTEGER NOT NULL,
+			backfilled INTEGER NOT NULL,
+			total INTEGER NOT NULL,
+			started_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL,
+			backfilled_at DATETIME
+		);`,
+	},
+}
+
+// SchemaChange is an online change to a large table, made in steps that
+// each keep the table available:
+//
+//  1. A migration adds the new column or table, and the same release writes
+//     both the old and the new one.
+//  2. A worker backfills the rows from before that release in batches.
+//  3. Once backfilled, an admin switches reads to the new column or table.
+//     Writes still go to both, so reads can be switched back.
+//  4. A later release stops writing the old one and a migration drops it.
+type SchemaChange struct {
+	// Name identifies the change in schema_changes and the admin API
+	Name string
+	// Table is the table whose rows are backfilled, in the order of its id
+	// column
+	Table string
+	// Backfill copies the rows with an id above its first argument and up
+	// to its second, e.g. UPDATE users SET email_lower = lower(email)
+	// WHERE id > ? AND id <= ?. It must write what the release of step 1
+	// does, as rows written since are copied again.
+	Backfill string
+}
+
+// SchemaChanges lists the online changes of the core tables, backfilled by
+// the schema changes module. Remove a change once the migration dropping
+// its old column or table has been applied everywhere.
+var SchemaChanges = []SchemaChange{}
+
+// schemaChangeColumns lists the columns scanned by scanSchemaChange
+const schemaChangeColumns = `name, phase, cursor, backfilled, total, started_at, updated_at, backfilled_at`
+
+// scanSchemaChange scans a row selected with schemaChangeColumns into a schema change
+func scanSchemaChange(row rowScanner) (*entity.SchemaChange, error) {
+	var change entity.SchemaChange
+	var backfilledAt sql.NullTime
+	err := row.Scan(&change.Name, &change.Phase, &change.Cursor, &change.Backfilled, &change.Total, &change.StartedAt, &change.UpdatedAt, &backfilledAt)
+	if err != nil {
+		return nil, err
+	}
+	if backfilledAt.Valid {
+		change.BackfilledAt = &backfilledAt.Time
+	}
+	return &change, nil
+}
+
+// SQLiteSchemaChangeRepository implements SchemaChangeRepository interface for SQLite
+type SQLiteSchemaChangeRepository struct {
+	db      *sql.DB
+	changes map[string]SchemaChange
+	now     func() time.Time
+}
+
+// NewSQLiteSchemaChangeRepository creates a new SQLite schema change
+// repository for changes
+func NewSQLiteSchemaChangeRepository(db *sql.DB, changes []SchemaChange) *SQLiteSchemaChangeRepository {
+	byName := make(map[string]SchemaChange, len(changes))
+	for _, change := range changes {
+		byName[change.Name] = change
+	}
+	return &SQLiteSchemaChangeRepository{db: db, changes: byName, now: time.Now}
+}
+
+// List returns every change that has started, by name
+func (r *SQLiteSchemaChangeRepository) List() ([]*entity.SchemaChange, error) {
+	rows, err := r.db.Query(`SELECT ` + schemaChangeColumns + ` FROM schema_changes ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*entity.SchemaChange
+	for rows.Next() {
+		change, err := scanSchemaChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// Start records a change as backfilling, unless it has started already
+func (r *SQLiteSchemaChangeRepository) Start(name string) (*entity.SchemaChange, error) {
+	definition, ok := r.changes[name]
+	if !ok {
+		return nil, repository.ErrSchemaChangeNotFound
+	}
+
+	var change *entity.SchemaChange
+	err := r.inTx(func(tx *sql.Tx) error {
+		var err error
+		change, err = scanSchemaChange(tx.QueryRow(`SELECT `+schemaChangeColumns+` FROM schema_changes WHERE name = ?`, name))
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		now := r.now().UTC()
+		change = &entity.SchemaChange{Name: name, Phase: entity.SchemaChangeBackfilling, StartedAt: now, UpdatedAt: now}
+		if err := tx.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %s`, definition.Table)).Scan(&change.Total); err != nil {
+			return fmt.Errorf("failed to count %s: %w", definition.Table, err)
+		}
+		_, err = tx.Exec(`
+		INSERT INTO schema_changes (name, phase, cursor, backfilled, total, started_at, updated_at)
+		VALUES (?, ?, 0, 0, ?, ?, ?)`,
+			change.Name, change.Phase, change.Total, change.StartedAt, change.UpdatedAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// Backfill copies the next batch of up to limit rows of a backfilling
+// change, saving its progress in the same transaction
+func (r *SQLiteSchemaChangeRepository) Backfill(name string, limit int) (*entity.SchemaChange, int, error) {
+	definition, ok := r.changes[name]
+	if !ok {
+		return nil, 0, repository.ErrSchemaChangeNotFound
+	}
+
+	var change *entity.SchemaChange
+	var copied int
+	err := r.inTx(func(tx *sql.Tx) error {
+		var err error
+		change, err = scanSchemaChange(tx.QueryRow(`SELECT `+schemaChangeColumns+` FROM schema_changes WHERE name = ?`, name))
+		if err == sql.ErrNoRows {
+			return repository.ErrSchemaChangeNotFound
+		}
+		if err != nil || change.Phase != entity.SchemaChangeBackfilling {
+			return err
+		}
+
+		var last sql.NullInt64
+		query := fmt.Sprintf(`SELECT MAX(id), COUNT(*) FROM (SELECT id FROM %s WHERE id > ? ORDER BY id LIMIT ?)`, definition.Table)
+		if err := tx.QueryRow(query, change.Cursor, limit).Scan(&last, &copied); err != nil {
+			return fmt.Errorf("failed to read the next batch of %s: %w", definition.Table, err)
+		}
+
+		now := r.now().UTC()
+		if copied == 0 {
+			change.Phase = entity.SchemaChangeBackfilled
+			change.BackfilledAt = &now
+		} else {
+			if _, err := tx.Exec(definition.Backfill, change.Cursor, last.Int64); err != nil {
+				return fmt.Errorf("failed to backfill %s: %w", name, err)
+			}
+			change.Cursor = last.Int64
+			change.Backfilled += int64(copied)
+		}
+		change.UpdatedAt = now
+		_, err = tx.Exec(`
+		UPDATE schema_changes SET phase = ?, cursor = ?, backfilled = ?, updated_at = ?, backfilled_at = ?
+		WHERE name = ?`,
+			change.Phase, change.Cursor, change.Backfilled, change.UpdatedAt, change.BackfilledAt, name)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return change, copied, nil
+}
+
+// SetPhase moves a started change to phase
+func (r *SQLiteSchemaChangeRepository) SetPhase(name, phase string) (*entity.SchemaChange, error) {
+	result, err := r.db.Exec(`UPDATE schema_changes SET phase = ?, updated_at = ? WHERE name = ?`, phase, r.now().UTC(), name)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, repository.ErrSchemaChangeNotFound
+	}
+	return scanSchemaChange(r.db.QueryRow(`SELECT `+schemaChangeColumns+` FROM schema_changes WHERE name = ?`, name))
+}
+
+// inTx runs fn in a transaction, committing when it succeeds
+func (r *SQLiteSchemaChangeRepository) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteSchemaChangeRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "schema-changes", SchemaChangeMigrations); err != nil {
+		t.Fatal(err)
+	}
+	users := NewSQLiteUserRepository(db)
+	for i := 1; i <= 5; i++ {
+		if _, err := users.Create(entity.NewUser(fmt.Sprintf("User%d@Example.com", i), "hash", "User", "0812345678", "1990-01-15")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The expand step, whose release writes email_lower for new users
+	if _, err := db.Exec(`ALTER TABLE users ADD COLUMN email_lower TEXT`); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteSchemaChangeRepository(db, []SchemaChange{{
+		Name:     "users_email_lower",
+		Table:    "users",
+		Backfill: `UPDATE users SET email_lower = lower(email) WHERE id > ? AND id <= ?`,
+	}})
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	if _, err := repo.Start("missing"); !errors.Is(err, repository.ErrSchemaChangeNotFound) {
+		t.Errorf("Start() of an undefined change error = %v, want ErrSchemaChangeNotFound", err)
+	}
+	if _, _, err := repo.Backfill("users_email_lower", 2); !errors.Is(err, repository.ErrSchemaChangeNotFound) {
+		t.Errorf("Backfill() before Start() error = %v, want ErrSchemaChangeNotFound", err)
+	}
+
+	change, err := repo.Start("users_email_lower")
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if change.Phase != entity.SchemaChangeBackfilling || change.Total != 5 || !change.StartedAt.Equal(now) {
+		t.Errorf("Start() = %+v", change)
+	}
+
+	// Batches move the cursor until no rows are left
+	var batches []int
+	for change.Phase == entity.SchemaChangeBackfilling {
+		var copied int
+		if change, copied, err = repo.Backfill("users_email_lower", 2); err != nil {
+			t.Fatalf("Backfill() error = %v", err)
+		}
+		batches = append(batches, copied)
+	}
+	if fmt.Sprint(batches) != "[2 2 1 0]" || change.Cursor != 5 || change.Backfilled != 5 || change.BackfilledAt == nil {
+		t.Errorf("batches = %v, change = %+v", batches, change)
+	}
+	var missing int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM users WHERE email_lower IS NULL OR email_lower != lower(email)`).Scan(&missing); err != nil || missing != 0 {
+		t.Errorf("%d users not backfilled, %v", missing, err)
+	}
+
+	// Starting again keeps the progress
+	if change, err = repo.Start("users_email_lower"); err != nil || change.Phase != entity.SchemaChangeBackfilled || change.Cursor != 5 {
+		t.Errorf("Start() again = %+v, %v", change, err)
+	}
+	if _, copied, err := repo.Backfill("users_email_lower", 2); err != nil || copied != 0 {
+		t.Errorf("Backfill() after the backfill = %d, %v", copied, err)
+	}
+
+	if change, err = repo.SetPhase("users_email_lower", entity.SchemaChangeReadingNew); err != nil || change.Phase != entity.SchemaChangeReadingNew {
+		t.Errorf("SetPhase() = %+v, %v", change, err)
+	}
+	if _, err := repo.SetPhase("missing", entity.SchemaChangeReadingNew); !errors.Is(err, repository.ErrSchemaChangeNotFound) {
+		t.Errorf("SetPhase() of an unstarted change error = %v, want ErrSchemaChangeNotFound", err)
+	}
+	changes, err := repo.List()
+	if err != nil || len(changes) != 1 || changes[0].Phase != entity.SchemaChangeReadingNew || changes[0].BackfilledAt == nil {
+		t.Errorf("List() = %+v, %v", changes, err)
+	}
+}
@@ -0,0 +1,207 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ShareLinkMigrations create the tables of the share links module, applied
+// with MigrateModule
+var ShareLinkMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create share links and share link accesses tables",
+		Query: `
+		CREATE TABLE IF NOT EXISTS share_links (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			fields TEXT NOT NULL,
+			max_views INTEGER NOT NULL,
+			views INTEGER NOT NULL DEFAULT 0,
+			expires_at DATETIME NOT NULL,
+			revoked_at DATETIME,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_share_links_user ON share_links (user_id);
+		CREATE TABLE IF NOT EXISTS share_link_accesses (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			link_id INTEGER NOT NULL,
+			ip TEXT NOT NULL,
+			device TEXT NOT NULL,
+			country TEXT NOT NULL,
+			granted BOOLEAN NOT NULL,
+			accessed_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_share_link_accesses_link ON share_link_accesses (link_id);`,
+	},
+}
+
+// shareLinkColumns lists the share_links columns in the order scanShareLink expects them
+const shareLinkColumns = `id, user_id, token_hash, fields, max_views, views, expires_at, revoked_at, created_at`
+
+// scanShareLink scans a row selected with shareLinkColumns into a link
+func scanShareLink(row rowScanner) (*entity.ShareLink, error) {
+	var link entity.ShareLink
+	var fields string
+	var revokedAt sql.NullTime
+	err := row.Scan(&link.ID, &link.UserID, &link.TokenHash, &fields, &link.MaxViews, &link.Views,
+		&link.ExpiresAt, &revokedAt, &link.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrShareLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+	if err := json.Unmarshal([]byte(fields), &link.Fields); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// shareLinkAccessColumns lists the share_link_accesses columns in the order scanShareLinkAccess expects them
+const shareLinkAccessColumns = `id, link_id, ip, device, country, granted, accessed_at`
+
+// scanShareLinkAccess scans a row selected with shareLinkAccessColumns into an access
+func scanShareLinkAccess(row rowScanner) (*entity.ShareLinkAccess, error) {
+	var access entity.ShareLinkAccess
+	err := row.Scan(&access.ID, &access.LinkID, &access.IP, &access.Device, &access.Country, &access.Granted, &access.AccessedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &access, nil
+}
+
+// SQLiteShareLinkRepository implements ShareLinkRepository interface for SQLite
+type SQLiteShareLinkRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteShareLinkRepository creates a new SQLite share link repository
+func NewSQLiteShareLinkRepository(db *sql.DB) *SQLiteShareLinkRepository {
+	return &SQLiteShareLinkRepository{db: db, now: time.Now}
+}
+
+// Create stores a link and sets its ID and CreatedAt
+func (r *SQLiteShareLinkRepository) Create(link *entity.ShareLink) error {
+	fields, err := json.Marshal(link.Fields)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO share_links (user_id, token_hash, fields, max_views, views, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	createdAt := r.now().UTC()
+	err = r.db.QueryRow(query, link.UserID, link.TokenHash, string(fields), link.MaxViews, link.Views,
+		link.ExpiresAt.UTC(), createdAt).Scan(&link.ID)
+	if err != nil {
+		return err
+	}
+
+	link.CreatedAt = createdAt
+	return nil
+}
+
+// GetByID returns a link
+func (r *SQLiteShareLinkRepository) GetByID(id int) (*entity.ShareLink, error) {
+	return scanShareLink(r.db.QueryRow(`SELECT `+shareLinkColumns+` FROM share_links WHERE id = ?`, id))
+}
+
+// ListByUser returns the user's links, newest first
+func (r *SQLiteShareLinkRepository) ListByUser(userID int) ([]*entity.ShareLink, error) {
+	rows, err := r.db.Query(`SELECT `+shareLinkColumns+` FROM share_links WHERE user_id = ? ORDER BY created_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*entity.ShareLink
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// Revoke revokes the user's link, keeping the time of an earlier revocation
+func (r *SQLiteShareLinkRepository) Revoke(userID, id int, now time.Time) error {
+	result, err := r.db.Exec(`UPDATE share_links SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ? AND user_id = ?`,
+		now.UTC(), id, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return repository.ErrShareLinkNotFound
+	}
+	return nil
+}
+
+// View counts an open of an active link. The conditional UPDATE keeps
+// concurrent opens from going over the link's views.
+func (r *SQLiteShareLinkRepository) View(tokenHash string, now time.Time) (*entity.ShareLink, error) {
+	query := `
+	UPDATE share_links SET views = views + 1
+	WHERE token_hash = ? AND revoked_at IS NULL AND expires_at > ? AND views < max_views
+	RETURNING ` + shareLinkColumns
+
+	link, err := scanShareLink(r.db.QueryRow(query, tokenHash, now.UTC()))
+	if !errors.Is(err, repository.ErrShareLinkNotFound) {
+		return link, err
+	}
+
+	link, err = scanShareLink(r.db.QueryRow(`SELECT `+shareLinkColumns+` FROM share_links WHERE token_hash = ?`, tokenHash))
+	if err != nil {
+		return nil, err
+	}
+	return link, repository.ErrShareLinkUnavailable
+}
+
+// RecordAccess stores an access and sets its ID
+func (r *SQLiteShareLinkRepository) RecordAccess(access *entity.ShareLinkAccess) error {
+	query := `
+	INSERT INTO share_link_accesses (link_id, ip, device, country, granted, accessed_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	return r.db.QueryRow(query, access.LinkID, access.IP, access.Device, access.Country, access.Granted,
+		access.AccessedAt.UTC()).Scan(&access.ID)
+}
+
+// ListAccesses returns the accesses of a link, newest first
+func (r *SQLiteShareLinkRepository) ListAccesses(linkID int) ([]*entity.ShareLinkAccess, error) {
+	rows, err := r.db.Query(`SELECT `+shareLinkAccessColumns+` FROM share_link_accesses WHERE link_id = ? ORDER BY accessed_at DESC, id DESC`, linkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accesses []*entity.ShareLinkAccess
+	for rows.Next() {
+		access, err := scanShareLinkAccess(rows)
+		if err != nil {
+			return nil, err
+		}
+		accesses = append(accesses, access)
+	}
+	return accesses, rows.Err()
+}
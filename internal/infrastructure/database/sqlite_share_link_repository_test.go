@@ -0,0 +1,93 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteShareLinkRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "share-links", ShareLinkMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteShareLinkRepository(db)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	link := &entity.ShareLink{UserID: 7, TokenHash: "token", Fields: []string{"fullName", "avatar"}, MaxViews: 2, ExpiresAt: now.Add(time.Hour)}
+	if err := repo.Create(link); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if link.ID == 0 || !link.CreatedAt.Equal(now) {
+		t.Errorf("Create() set ID %d and CreatedAt %v", link.ID, link.CreatedAt)
+	}
+
+	got, err := repo.GetByID(link.ID)
+	if err != nil || got.UserID != 7 || len(got.Fields) != 2 || got.Fields[1] != "avatar" || got.MaxViews != 2 || got.RevokedAt != nil {
+		t.Fatalf("GetByID() = %+v, %v", got, err)
+	}
+	if _, err := repo.GetByID(99); !errors.Is(err, repository.ErrShareLinkNotFound) {
+		t.Errorf("GetByID(99) error = %v, want ErrShareLinkNotFound", err)
+	}
+
+	// Opened as often as allowed, then unavailable
+	for want := 1; want <= 2; want++ {
+		viewed, err := repo.View("token", now)
+		if err != nil || viewed.Views != want {
+			t.Fatalf("View() #%d = %+v, %v", want, viewed, err)
+		}
+	}
+	if viewed, err := repo.View("token", now); !errors.Is(err, repository.ErrShareLinkUnavailable) || viewed == nil || viewed.Views != 2 {
+		t.Errorf("View() used up = %+v, %v; want the link and ErrShareLinkUnavailable", viewed, err)
+	}
+	if _, err := repo.View("missing", now); !errors.Is(err, repository.ErrShareLinkNotFound) {
+		t.Errorf("View(missing) error = %v, want ErrShareLinkNotFound", err)
+	}
+
+	// Expired and revoked links are not counted
+	other := &entity.ShareLink{UserID: 7, TokenHash: "other", Fields: []string{"email"}, MaxViews: 5, ExpiresAt: now.Add(time.Hour)}
+	if err := repo.Create(other); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.View("other", now.Add(time.Hour)); !errors.Is(err, repository.ErrShareLinkUnavailable) {
+		t.Errorf("View() expired error = %v, want ErrShareLinkUnavailable", err)
+	}
+	if err := repo.Revoke(8, other.ID, now); !errors.Is(err, repository.ErrShareLinkNotFound) {
+		t.Errorf("Revoke() by another user error = %v, want ErrShareLinkNotFound", err)
+	}
+	if err := repo.Revoke(7, other.ID, now); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if err := repo.Revoke(7, other.ID, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Revoke() again error = %v", err)
+	}
+	if viewed, err := repo.View("other", now); !errors.Is(err, repository.ErrShareLinkUnavailable) || viewed.Views != 0 || !viewed.RevokedAt.Equal(now) {
+		t.Errorf("View() revoked = %+v, %v", viewed, err)
+	}
+
+	links, err := repo.ListByUser(7)
+	if err != nil || len(links) != 2 || links[0].ID != other.ID {
+		t.Errorf("ListByUser() = %+v, %v; want both links, newest first", links, err)
+	}
+
+	// Accesses
+	for _, granted := range []bool{true, false} {
+		access := &entity.ShareLinkAccess{LinkID: link.ID, IP: "203.0.113.7", Device: "Firefox", Granted: granted, AccessedAt: now}
+		if err := repo.RecordAccess(access); err != nil || access.ID == 0 {
+			t.Fatalf("RecordAccess() ID %d, error = %v", access.ID, err)
+		}
+	}
+	accesses, err := repo.ListAccesses(link.ID)
+	if err != nil || len(accesses) != 2 || accesses[0].Granted || !accesses[1].Granted || accesses[1].IP != "203.0.113.7" {
+		t.Errorf("ListAccesses() = %+v, %v", accesses, err)
+	}
+	if accesses, err := repo.ListAccesses(other.ID); err != nil || len(accesses) != 0 {
+		t.Errorf("ListAccesses(other) = %+v, %v; want none", accesses, err)
+	}
+}
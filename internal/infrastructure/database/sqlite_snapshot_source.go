@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// SQLiteSnapshotSource implements SnapshotSource by copying the database
+// with the online backup API to a temporary file and reading the copy
+type SQLiteSnapshotSource struct {
+	db  *sql.DB
+	dir string
+}
+
+// NewSQLiteSnapshotSource creates a snapshot source for db. Snapshots are
+// written to dir, or the system temporary directory when dir is empty.
+func NewSQLiteSnapshotSource(db *sql.DB, dir string) *SQLiteSnapshotSource {
+	return &SQLiteSnapshotSource{db: db, dir: dir}
+}
+
+// Snapshot copies the database as it is now; Close removes the copy
+func (s *SQLiteSnapshotSource) Snapshot() (repository.Snapshot, error) {
+	file, err := os.CreateTemp(s.dir, "snapshot-*.db")
+	if err != nil {
+		return nil, err
+	}
+	path := file.Name()
+	file.Close()
+
+	if err := BackupDatabase(context.Background(), s.db, path); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	db, err := OpenDatabase(path)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	return &sqliteSnapshot{
+		db:        db,
+		path:      path,
+		users:     NewSQLiteUserRepository(db),
+		revisions: NewSQLiteUserRevisionRepository(db),
+	}, nil
+}
+
+// sqliteSnapshot reads a copy of the database made by SQLiteSnapshotSource
+type sqliteSnapshot struct {
+	db        *sql.DB
+	path      string
+	users     *SQLiteUserRepository
+	revisions *SQLiteUserRevisionRepository
+}
+
+func (s *sqliteSnapshot) Users() repository.UserRepository             { return s.users }
+func (s *sqliteSnapshot) Revisions() repository.UserRevisionRepository { return s.revisions }
+
+// Close closes the copy and removes its files
+func (s *sqliteSnapshot) Close() error {
+	err := s.db.Close()
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if removeErr := os.Remove(s.path + suffix); removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+			err = removeErr
+		}
+	}
+	return err
+}
@@ -2,73 +2,242 @@ package database
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"time"
 
 	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
 
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
+// userColumns lists the users columns in the order scanUser expects them
+const userColumns = `id, email, password, full_name, phone_number, birthday, avatar, role, status, plan, timezone, national_id, kyc_status, created_at, updated_at, version`
+
+// updatableColumns maps UpdateFields field names to users columns
+var updatableColumns = map[string]string{
+	repository.FieldEmail:       "email",
+	repository.FieldFullName:    "full_name",
+	repository.FieldPhoneNumber: "phone_number",
+	repository.FieldBirthday:    "birthday",
+	repository.FieldAvatar:      "avatar",
+	repository.FieldRole:        "role",
+	repository.FieldStatus:      "status",
+	repository.FieldPlan:        "plan",
+	repository.FieldTimezone:    "timezone",
+	repository.FieldNationalID:  "national_id",
+	repository.FieldKYCStatus:   "kyc_status",
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser scans a row selected with userColumns into a user entity
+func scanUser(row rowScanner) (*entity.User, error) {
+	var user entity.User
+	err := row.Scan(&user.ID, &user.Email, &user.Password, &user.FullName, &user.PhoneNumber, &user.Birthday, &user.Avatar, &user.Role, &user.Status, &user.Plan, &user.Timezone, &user.NationalID, &user.KYCStatus, &user.CreatedAt, &user.UpdatedAt, &user.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// isUniqueViolation reports whether err is a SQLite UNIQUE constraint failure
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// UserRepositoryOptions configures how the SQLite user repository behaves
+// when several regions write to replicas of the same data
+type UserRepositoryOptions struct {
+	// IDs allocates user IDs; nil uses SQLite's AUTOINCREMENT, which is only
+	// safe with a single writer
+	IDs repository.IDGenerator
+	// UpdateStrategy resolves concurrent Update calls; empty means LastWriteWins
+	UpdateStrategy repository.UpdateStrategy
+}
+
 // SQLiteUserRepository implements UserRepository interface for SQLite
 type SQLiteUserRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	ids      repository.IDGenerator
+	strategy repository.UpdateStrategy
+	now      func() time.Time
 }
 
 // NewSQLiteUserRepository creates a new SQLite user repository
 func NewSQLiteUserRepository(db *sql.DB) *SQLiteUserRepository {
-	return &SQLiteUserRepository{db: db}
+	return NewSQLiteUserRepositoryWithOptions(db, UserRepositoryOptions{})
 }
 
-// Create saves a new user and returns the created user with ID
+// NewSQLiteUserRepositoryWithOptions creates a SQLite user repository with
+// explicit ID generation and conflict handling
+func NewSQLiteUserRepositoryWithOptions(db *sql.DB, opts UserRepositoryOptions) *SQLiteUserRepository {
+	strategy := opts.UpdateStrategy
+	if strategy == "" {
+		strategy = repository.LastWriteWins
+	}
+	return &SQLiteUserRepository{db: db, ids: opts.IDs, strategy: strategy, now: time.Now}
+}
+
+// Create saves a new user and returns the created user with ID.
+// CreatedAt, UpdatedAt and Version are always set by the repository.
 func (r *SQLiteUserRepository) Create(user *entity.User) (*entity.User, error) {
+	// A NULL id lets SQLite assign the next AUTOINCREMENT value
+	var explicitID interface{}
+	if r.ids != nil {
+		id, err := r.ids.NextID()
+		if err != nil {
+			return nil, err
+		}
+		explicitID = id
+	}
+
 	query := `
-	INSERT INTO users (email, password, full_name, phone_number, birthday, created_at)
-	VALUES (?, ?, ?, ?, ?, ?)
+	INSERT INTO users (id, email, password, full_name, phone_number, birthday, avatar, role, status, plan, timezone, national_id, kyc_status, created_at, updated_at, version)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1)
 	RETURNING id`
 
+	if user.Role == "" {
+		user.Role = entity.RoleUser
+	}
+	if user.Status == "" {
+		user.Status = entity.StatusActive
+	}
+	if user.Plan == "" {
+		user.Plan = entity.PlanFree
+	}
+	now := r.now().UTC()
+
 	var id int
-	err := r.db.QueryRow(query, user.Email, user.Password, user.FullName, user.PhoneNumber, user.Birthday, user.CreatedAt).Scan(&id)
+	err := r.db.QueryRow(query, explicitID, user.Email, user.Password, user.FullName, user.PhoneNumber, user.Birthday, user.Avatar, user.Role, user.Status, user.Plan, user.Timezone, user.NationalID, user.KYCStatus, now, now).Scan(&id)
+	if isUniqueViolation(err) {
+		return nil, repository.ErrEmailTaken
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	user.ID = id
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Version = 1
 	return user, nil
 }
 
 // GetByEmail retrieves a user by email
 func (r *SQLiteUserRepository) GetByEmail(email string) (*entity.User, error) {
-	query := `SELECT id, email, password, full_name, phone_number, birthday, created_at FROM users WHERE email = ?`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
 
-	var user entity.User
-	err := r.db.QueryRow(query, email).Scan(&user.ID, &user.Email, &user.Password, &user.FullName, &user.PhoneNumber, &user.Birthday, &user.CreatedAt)
-	if err != nil {
-		return nil, err
+	user, err := scanUser(r.db.QueryRow(query, email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrUserNotFound
 	}
+	return user, err
+}
 
-	return &user, nil
+// ExistsByEmail reports whether a user with the email exists
+func (r *SQLiteUserRepository) ExistsByEmail(email string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = ?)`
+
+	var exists bool
+	err := r.db.QueryRow(query, email).Scan(&exists)
+	return exists, err
 }
 
 // GetByID retrieves a user by ID
 func (r *SQLiteUserRepository) GetByID(id int) (*entity.User, error) {
-	query := `SELECT id, email, password, full_name, phone_number, birthday, created_at FROM users WHERE id = ?`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
 
-	var user entity.User
-	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Email, &user.Password, &user.FullName, &user.PhoneNumber, &user.Birthday, &user.CreatedAt)
-	if err != nil {
-		return nil, err
-	}
-
-	return &user, nil
+	return scanUser(r.db.QueryRow(query, id))
 }
 
-// Update updates user information
+// Update updates user information. With the VersionChecked strategy the
+// update only applies if user.Version still matches the stored row.
+// On success user.Version is set to the new version.
 func (r *SQLiteUserRepository) Update(user *entity.User) error {
 	query := `
-	UPDATE users SET email = ?, full_name = ?, phone_number = ?, birthday = ?
+	UPDATE users SET email = ?, full_name = ?, phone_number = ?, birthday = ?, avatar = ?, role = ?, status = ?, plan = ?, timezone = ?, national_id = ?, kyc_status = ?, updated_at = ?, version = version + 1
 	WHERE id = ?`
+	args := []interface{}{user.Email, user.FullName, user.PhoneNumber, user.Birthday, user.Avatar, user.Role, user.Status, user.Plan, user.Timezone, user.NationalID, user.KYCStatus, r.now().UTC(), user.ID}
+	if r.strategy == repository.VersionChecked {
+		query += ` AND version = ?`
+		args = append(args, user.Version)
+	}
 
-	_, err := r.db.Exec(query, user.Email, user.FullName, user.PhoneNumber, user.Birthday, user.ID)
+	var version int
+	err := r.db.QueryRow(query+` RETURNING version`, args...).Scan(&version)
+	if isUniqueViolation(err) {
+		return repository.ErrEmailTaken
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		if r.strategy != repository.VersionChecked {
+			return nil
+		}
+		// Distinguish a stale version from a missing user, which stays a no-op
+		var exists bool
+		if err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)`, user.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return repository.ErrVersionConflict
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	user.Version = version
+	return nil
+}
+
+// UpdatePassword replaces the stored password hash
+func (r *SQLiteUserRepository) UpdatePassword(id int, hash string) error {
+	_, err := r.db.Exec(`UPDATE users SET password = ?, updated_at = ?, version = version + 1 WHERE id = ?`, hash, r.now().UTC(), id)
+	return err
+}
+
+// UpdateFields updates only the given fields, building the SET clause dynamically.
+// Field updates never conflict on version: untouched columns keep their
+// current values, so concurrent writers of different fields both succeed.
+func (r *SQLiteUserRepository) UpdateFields(id int, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	// Sort names so the generated statement is stable
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if _, ok := updatableColumns[name]; !ok {
+			return fmt.Errorf("unknown user field %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	assignments := make([]string, 0, len(names)+1)
+	args := make([]interface{}, 0, len(names)+2)
+	for _, name := range names {
+		assignments = append(assignments, updatableColumns[name]+" = ?")
+		args = append(args, fields[name])
+	}
+	assignments = append(assignments, "updated_at = ?", "version = version + 1")
+	args = append(args, r.now().UTC(), id)
+
+	query := `UPDATE users SET ` + strings.Join(assignments, ", ") + ` WHERE id = ?`
+	_, err := r.db.Exec(query, args...)
+	if isUniqueViolation(err) {
+		return repository.ErrEmailTaken
+	}
 	return err
 }
 
@@ -79,30 +248,101 @@ func (r *SQLiteUserRepository) Delete(id int) error {
 	return err
 }
 
-// InitDatabase initializes SQLite database and creates tables
-func InitDatabase() (*sql.DB, error) {
-	db, err := sql.Open("sqlite", "users.db")
+// List returns users matching the filter, ordered by ID, within the page
+func (r *SQLiteUserRepository) List(filter repository.UserFilter, page repository.Page) ([]*entity.User, error) {
+	page = page.Normalize()
+	where, args := userFilterClause(filter)
+
+	query := `SELECT ` + userColumns + ` FROM users` + where + ` ORDER BY id LIMIT ? OFFSET ?`
+	rows, err := r.db.Query(query, append(args, page.Limit, page.Offset)...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	users := []*entity.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// Count returns the number of users matching the filter
+func (r *SQLiteUserRepository) Count(filter repository.UserFilter) (int, error) {
+	where, args := userFilterClause(filter)
+
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM users`+where, args...).Scan(&count)
+	return count, err
+}
+
+// userFilterClause builds the WHERE clause and arguments for a user filter
+func userFilterClause(filter repository.UserFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if !filter.CreatedFrom.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.CreatedTo)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.ExcludeStatus != "" {
+		conditions = append(conditions, "status <> ?")
+		args = append(args, filter.ExcludeStatus)
+	}
+	if filter.Role != "" {
+		conditions = append(conditions, "role = ?")
+		args = append(args, filter.Role)
+	}
+	if filter.Search != "" {
+		pattern := "%" + escapeLike(strings.ToLower(filter.Search)) + "%"
+		conditions = append(conditions, `(lower(email) LIKE ? ESCAPE '\' OR lower(full_name) LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
 
-	// Create users table if it doesn't exist
-	createTableQuery := `
-	CREATE TABLE IF NOT EXISTS users (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		email TEXT UNIQUE NOT NULL,
-		password TEXT NOT NULL,
-		full_name TEXT NOT NULL,
-		phone_number TEXT NOT NULL,
-		birthday TEXT NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// escapeLike escapes LIKE wildcards so search terms match literally
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return replacer.Replace(value)
+}
+
+// OpenDatabase opens a SQLite database file with the connection settings the
+// repositories rely on. The busy timeout makes concurrent writers wait for the
+// lock instead of failing immediately with SQLITE_BUSY.
+func OpenDatabase(path string) (*sql.DB, error) {
+	return sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+}
 
-	_, err = db.Exec(createTableQuery)
+// InitDatabase initializes SQLite database and creates tables
+func InitDatabase() (*sql.DB, error) {
+	db, err := OpenDatabase("users.db")
 	if err != nil {
 		return nil, err
 	}
 
+	// Apply schema migrations
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+
 	log.Println("Database initialized successfully")
 	return db, nil
 }
@@ -0,0 +1,19 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/domain/repository/repositorytest"
+)
+
+func TestSQLiteUserRepository_Conformance(t *testing.T) {
+	t.Parallel()
+	repositorytest.Run(t, func(t *testing.T, now func() time.Time) repository.UserRepository {
+		db := setupTestDB(t)
+		repo := NewSQLiteUserRepository(db)
+		repo.now = now
+		return repo
+	})
+}
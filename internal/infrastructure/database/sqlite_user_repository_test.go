@@ -1,66 +1,25 @@
 package database
 
 import (
-	"database/sql"
-	"os"
+	"errors"
 	"testing"
 	"time"
 
 	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
 
 	_ "modernc.org/sqlite"
 )
 
-func setupTestDB(t *testing.T) (*sql.DB, func()) {
-	// Create temporary database file
-	dbFile := "test_users.db"
-
-	db, err := sql.Open("sqlite", dbFile)
-	if err != nil {
-		t.Fatalf("Failed to open test database: %v", err)
-	}
-
-	// Create users table
-	createTableQuery := `
-	CREATE TABLE IF NOT EXISTS users (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		email TEXT UNIQUE NOT NULL,
-		password TEXT NOT NULL,
-		full_name TEXT NOT NULL,
-		phone_number TEXT NOT NULL,
-		birthday TEXT NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	_, err = db.Exec(createTableQuery)
-	if err != nil {
-		t.Fatalf("Failed to create test table: %v", err)
-	}
-
-	// Return cleanup function
-	cleanup := func() {
-		db.Close()
-		os.Remove(dbFile)
-	}
-
-	return db, cleanup
-}
-
 func TestInitDatabase(t *testing.T) {
-	// Use a temporary database file
-	dbFile := "test_init.db"
-	defer os.Remove(dbFile)
-
-	// Change working directory temporarily for the test
-	originalDBPath := "users.db"
+	// InitDatabase creates users.db in the working directory
+	t.Chdir(t.TempDir())
 
-	// Test InitDatabase function
 	db, err := InitDatabase()
 	if err != nil {
 		t.Fatalf("InitDatabase() error = %v", err)
 	}
 	defer db.Close()
-	defer os.Remove(originalDBPath) // Clean up the created database
 
 	// Verify database connection works
 	err = db.Ping()
@@ -80,8 +39,8 @@ func TestInitDatabase(t *testing.T) {
 }
 
 func TestNewSQLiteUserRepository(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
+	t.Parallel()
+	db := setupTestDB(t)
 
 	repo := NewSQLiteUserRepository(db)
 	if repo == nil {
@@ -93,8 +52,8 @@ func TestNewSQLiteUserRepository(t *testing.T) {
 }
 
 func TestSQLiteUserRepository_Create(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
+	t.Parallel()
+	db := setupTestDB(t)
 
 	repo := NewSQLiteUserRepository(db)
 	now := time.Now()
@@ -137,8 +96,8 @@ func TestSQLiteUserRepository_Create(t *testing.T) {
 }
 
 func TestSQLiteUserRepository_Create_DuplicateEmail(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
+	t.Parallel()
+	db := setupTestDB(t)
 
 	repo := NewSQLiteUserRepository(db)
 
@@ -174,8 +133,8 @@ func TestSQLiteUserRepository_Create_DuplicateEmail(t *testing.T) {
 }
 
 func TestSQLiteUserRepository_GetByEmail(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
+	t.Parallel()
+	db := setupTestDB(t)
 
 	repo := NewSQLiteUserRepository(db)
 
@@ -218,8 +177,8 @@ func TestSQLiteUserRepository_GetByEmail(t *testing.T) {
 }
 
 func TestSQLiteUserRepository_GetByID(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
+	t.Parallel()
+	db := setupTestDB(t)
 
 	repo := NewSQLiteUserRepository(db)
 
@@ -259,8 +218,8 @@ func TestSQLiteUserRepository_GetByID(t *testing.T) {
 }
 
 func TestSQLiteUserRepository_Update(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
+	t.Parallel()
+	db := setupTestDB(t)
 
 	repo := NewSQLiteUserRepository(db)
 
@@ -322,9 +281,133 @@ func TestSQLiteUserRepository_Update(t *testing.T) {
 	}
 }
 
+func TestSQLiteUserRepository_Update_VersionChecked(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteUserRepositoryWithOptions(db, UserRepositoryOptions{UpdateStrategy: repository.VersionChecked})
+
+	created, err := repo.Create(&entity.User{
+		Email:       "versioned@example.com",
+		Password:    "hashedpassword",
+		FullName:    "Original Name",
+		PhoneNumber: "0812345678",
+		Birthday:    "1990-01-15",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Two writers read the same version
+	first, _ := repo.GetByID(created.ID)
+	second, _ := repo.GetByID(created.ID)
+
+	first.FullName = "First Writer"
+	if err := repo.Update(first); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	second.FullName = "Second Writer"
+	if err := repo.Update(second); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("stale Update() error = %v, want ErrVersionConflict", err)
+	}
+
+	found, _ := repo.GetByID(created.ID)
+	if found.FullName != "First Writer" {
+		t.Errorf("FullName = %v, want First Writer", found.FullName)
+	}
+
+	// Retrying with the fresh version succeeds
+	found.FullName = "Second Writer"
+	if err := repo.Update(found); err != nil {
+		t.Errorf("retried Update() error = %v", err)
+	}
+
+	// Missing users remain a no-op
+	if err := repo.Update(&entity.User{ID: 999, Email: "missing@example.com"}); err != nil {
+		t.Errorf("Update() for missing user error = %v", err)
+	}
+}
+
+func TestSQLiteUserRepository_Update_LastWriteWins(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteUserRepository(db)
+
+	created, err := repo.Create(&entity.User{
+		Email:       "lww@example.com",
+		Password:    "hashedpassword",
+		FullName:    "Original Name",
+		PhoneNumber: "0812345678",
+		Birthday:    "1990-01-15",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	first, _ := repo.GetByID(created.ID)
+	second, _ := repo.GetByID(created.ID)
+
+	first.FullName = "First Writer"
+	if err := repo.Update(first); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	second.FullName = "Second Writer"
+	if err := repo.Update(second); err != nil {
+		t.Fatalf("stale Update() error = %v", err)
+	}
+
+	found, _ := repo.GetByID(created.ID)
+	if found.FullName != "Second Writer" {
+		t.Errorf("FullName = %v, want Second Writer", found.FullName)
+	}
+}
+
+// fixedIDs hands out a predefined sequence of IDs
+type fixedIDs struct {
+	ids []int
+	err error
+}
+
+func (f *fixedIDs) NextID() (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	id := f.ids[0]
+	f.ids = f.ids[1:]
+	return id, nil
+}
+
+func TestSQLiteUserRepository_Create_WithIDGenerator(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	ids := &fixedIDs{ids: []int{1 << 40, 1<<40 + 1}}
+	repo := NewSQLiteUserRepositoryWithOptions(db, UserRepositoryOptions{IDs: ids})
+
+	for i, email := range []string{"gen1@example.com", "gen2@example.com"} {
+		created, err := repo.Create(&entity.User{Email: email, Password: "hash", FullName: "Generated", PhoneNumber: "0812345678", Birthday: "1990-01-15"})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if want := 1<<40 + i; created.ID != want {
+			t.Errorf("Create() ID = %v, want %v", created.ID, want)
+		}
+		if _, err := repo.GetByID(created.ID); err != nil {
+			t.Errorf("GetByID(%d) error = %v", created.ID, err)
+		}
+	}
+
+	ids.err = errors.New("clock moved backwards")
+	if _, err := repo.Create(&entity.User{Email: "gen3@example.com"}); err == nil {
+		t.Error("Create() should fail when the ID generator fails")
+	}
+}
+
 func TestSQLiteUserRepository_Delete(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
+	t.Parallel()
+	db := setupTestDB(t)
 
 	repo := NewSQLiteUserRepository(db)
 
@@ -364,8 +447,8 @@ func TestSQLiteUserRepository_Delete(t *testing.T) {
 }
 
 func TestSQLiteUserRepository_CRUD_Integration(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
+	t.Parallel()
+	db := setupTestDB(t)
 
 	repo := NewSQLiteUserRepository(db)
 
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// SQLiteUserRevisionRepository implements UserRevisionRepository interface for SQLite.
+// Revisions have no foreign key to users so history outlives deleted accounts.
+type SQLiteUserRevisionRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteUserRevisionRepository creates a new SQLite user revision repository
+func NewSQLiteUserRevisionRepository(db *sql.DB) *SQLiteUserRevisionRepository {
+	return &SQLiteUserRevisionRepository{db: db, now: time.Now}
+}
+
+// Record saves a revision and sets its ID and CreatedAt
+func (r *SQLiteUserRevisionRepository) Record(revision *entity.UserRevision) error {
+	query := `
+	INSERT INTO user_revisions (user_id, actor_id, action, snapshot, changes, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	// Snapshots must never carry the password hash
+	snapshot, err := json.Marshal(revision.Snapshot.WithoutPassword())
+	if err != nil {
+		return err
+	}
+	changes := revision.Changes
+	if changes == nil {
+		changes = []entity.FieldChange{}
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	createdAt := r.now().UTC()
+	err = r.db.QueryRow(query, revision.UserID, revision.ActorID, string(revision.Action), string(snapshot), string(changesJSON), createdAt).Scan(&revision.ID)
+	if err != nil {
+		return err
+	}
+
+	revision.CreatedAt = createdAt
+	return nil
+}
+
+// ListByUser returns a user's revisions, newest first, within the page
+func (r *SQLiteUserRevisionRepository) ListByUser(userID int, page repository.Page) ([]*entity.UserRevision, error) {
+	page = page.Normalize()
+	query := `
+	SELECT id, user_id, actor_id, action, snapshot, changes, created_at
+	FROM user_revisions
+	WHERE user_id = ?
+	ORDER BY id DESC
+	LIMIT ? OFFSET ?`
+
+	return r.query(query, userID, page.Limit, page.Offset)
+}
+
+// List returns every user's revisions, oldest first, within the page
+func (r *SQLiteUserRevisionRepository) List(page repository.Page) ([]*entity.UserRevision, error) {
+	page = page.Normalize()
+	query := `
+	SELECT id, user_id, actor_id, action, snapshot, changes, created_at
+	FROM user_revisions
+	ORDER BY id
+	LIMIT ? OFFSET ?`
+
+	return r.query(query, page.Limit, page.Offset)
+}
+
+// query scans the revisions selected by query
+func (r *SQLiteUserRevisionRepository) query(query string, args ...interface{}) ([]*entity.UserRevision, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*entity.UserRevision
+	for rows.Next() {
+		var revision entity.UserRevision
+		var action, snapshot, changes string
+		if err := rows.Scan(&revision.ID, &revision.UserID, &revision.ActorID, &action, &snapshot, &changes, &revision.CreatedAt); err != nil {
+			return nil, err
+		}
+		revision.Action = entity.RevisionAction(action)
+		if err := json.Unmarshal([]byte(snapshot), &revision.Snapshot); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &revision.Changes); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, &revision)
+	}
+
+	return revisions, rows.Err()
+}
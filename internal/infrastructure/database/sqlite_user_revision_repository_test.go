@@ -0,0 +1,87 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestSQLiteUserRevisionRepository_RecordAndList(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+
+	repo := NewSQLiteUserRevisionRepository(db)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	before := entity.NewUser("history@example.com", "secrethash", "Before", "0812345678", "1990-01-15")
+	before.ID = 1
+	after := *before
+	after.FullName = "After"
+
+	update := entity.NewUserRevision(2, before, &after)
+	if err := repo.Record(update); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if update.ID == 0 || !update.CreatedAt.Equal(now) {
+		t.Errorf("Record() should set ID and CreatedAt, got %+v", update)
+	}
+
+	now = now.Add(time.Hour)
+	if err := repo.Record(entity.NewUserRevision(2, &after, nil)); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	// Revisions for other users are not returned
+	other := entity.NewUser("other@example.com", "hash", "Other", "0812345678", "1990-01-15")
+	other.ID = 99
+	if err := repo.Record(entity.NewUserRevision(99, other, nil)); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	revisions, err := repo.ListByUser(1, repository.Page{})
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("ListByUser() returned %d revisions, want 2", len(revisions))
+	}
+
+	// Newest first
+	if revisions[0].Action != entity.RevisionActionDelete || revisions[1].Action != entity.RevisionActionUpdate {
+		t.Errorf("actions = %v, %v, want delete, update", revisions[0].Action, revisions[1].Action)
+	}
+
+	got := revisions[1]
+	if got.ActorID != 2 || got.Snapshot.FullName != "Before" || got.Snapshot.Email != "history@example.com" {
+		t.Errorf("update revision = %+v", got)
+	}
+	if got.Snapshot.Password != "" {
+		t.Error("stored snapshot should not contain the password hash")
+	}
+	if len(got.Changes) != 1 || got.Changes[0] != (entity.FieldChange{Field: "fullName", Old: "Before", New: "After"}) {
+		t.Errorf("Changes = %+v", got.Changes)
+	}
+	if !got.CreatedAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, now.Add(-time.Hour))
+	}
+
+	paged, err := repo.ListByUser(1, repository.Page{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(paged) != 1 || paged[0].ID != got.ID {
+		t.Errorf("ListByUser() with page = %+v, want only the oldest revision", paged)
+	}
+
+	// List spans every user, oldest first
+	all, err := repo.List(repository.Page{Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(all) != 2 || all[0].Action != entity.RevisionActionDelete || all[1].UserID != 99 {
+		t.Errorf("List() = %+v, want the last two revisions in ID order", all)
+	}
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// WebhookAttemptMigrations create the tables of the webhooks module, applied
+// with MigrateModule
+var WebhookAttemptMigrations = []Migration{
+	{
+		Version:     1,
+		Description: "create webhook attempts table",
+		Query: `
+		CREATE TABLE IF NOT EXISTS webhook_attempts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			event_id TEXT NOT NULL,
+			point TEXT NOT NULL,
+			user_id INTEGER NOT NULL,
+			status_code INTEGER NOT NULL,
+			error TEXT NOT NULL,
+			duration_ms INTEGER NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_webhook_attempts_point ON webhook_attempts (point, event_id);
+		CREATE INDEX IF NOT EXISTS idx_webhook_attempts_created ON webhook_attempts (created_at);`,
+	},
+}
+
+// SQLiteWebhookAttemptRepository implements WebhookAttemptRepository interface for SQLite
+type SQLiteWebhookAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteWebhookAttemptRepository creates a new SQLite webhook attempt repository
+func NewSQLiteWebhookAttemptRepository(db *sql.DB) *SQLiteWebhookAttemptRepository {
+	return &SQLiteWebhookAttemptRepository{db: db}
+}
+
+// Create stores an attempt and sets its ID
+func (r *SQLiteWebhookAttemptRepository) Create(attempt *entity.WebhookAttempt) error {
+	query := `
+	INSERT INTO webhook_attempts (event_id, point, user_id, status_code, error, duration_ms, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	RETURNING id`
+
+	return r.db.QueryRow(query, attempt.EventID, attempt.Point, attempt.UserID, attempt.StatusCode, attempt.Error,
+		attempt.Duration.Milliseconds(), attempt.CreatedAt.UTC()).Scan(&attempt.ID)
+}
+
+// ListByPoint returns every attempt at the limit events most recently sent
+// to the webhook at point, or only at eventID when it is set, oldest first
+func (r *SQLiteWebhookAttemptRepository) ListByPoint(point, eventID string, limit int) ([]*entity.WebhookAttempt, error) {
+	query := `
+	SELECT id, event_id, point, user_id, status_code, error, duration_ms, created_at
+	FROM webhook_attempts
+	WHERE point = ? AND event_id IN (
+		SELECT event_id FROM webhook_attempts
+		WHERE point = ? AND (? = '' OR event_id = ?)
+		GROUP BY event_id
+		ORDER BY MAX(id) DESC
+		LIMIT ?
+	)
+	ORDER BY id`
+
+	rows, err := r.db.Query(query, point, point, eventID, eventID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []*entity.WebhookAttempt
+	for rows.Next() {
+		var attempt entity.WebhookAttempt
+		var durationMs int64
+		if err := rows.Scan(&attempt.ID, &attempt.EventID, &attempt.Point, &attempt.UserID, &attempt.StatusCode,
+			&attempt.Error, &durationMs, &attempt.CreatedAt); err != nil {
+			return nil, err
+		}
+		attempt.Duration = time.Duration(durationMs) * time.Millisecond
+		attempts = append(attempts, &attempt)
+	}
+	return attempts, rows.Err()
+}
+
+// DeleteBefore removes the attempts made before the given time
+func (r *SQLiteWebhookAttemptRepository) DeleteBefore(before time.Time) (int, error) {
+	result, err := r.db.Exec(`DELETE FROM webhook_attempts WHERE created_at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+func TestSQLiteWebhookAttemptRepository(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	if err := MigrateModule(db, "webhooks", WebhookAttemptMigrations); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSQLiteWebhookAttemptRepository(db)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	for i, a := range []struct {
+		eventID, point string
+		status         int
+	}{
+		{"evt_1", "post-register", 503},
+		{"evt_2", "post-register", 200},
+		{"evt_1", "post-register", 200},
+		{"evt_3", "pre-login", 200},
+	} {
+		attempt := &entity.WebhookAttempt{EventID: a.eventID, Point: a.point, StatusCode: a.status,
+			Duration: 120 * time.Millisecond, CreatedAt: now.Add(time.Duration(i) * time.Minute)}
+		if err := repo.Create(attempt); err != nil || attempt.ID == 0 {
+			t.Fatalf("Create() = %d, %v", attempt.ID, err)
+		}
+	}
+
+	// evt_1 was attempted last, so it is the most recent event
+	attempts, err := repo.ListByPoint("post-register", "", 1)
+	if err != nil || len(attempts) != 2 || attempts[0].EventID != "evt_1" || attempts[0].StatusCode != 503 ||
+		attempts[1].StatusCode != 200 || attempts[0].Duration != 120*time.Millisecond {
+		t.Fatalf("ListByPoint(limit 1) = %+v, %v; want both evt_1 attempts", attempts, err)
+	}
+	if attempts, err := repo.ListByPoint("post-register", "", 10); err != nil || len(attempts) != 3 {
+		t.Errorf("ListByPoint() = %d attempts, %v; want 3", len(attempts), err)
+	}
+	if attempts, err := repo.ListByPoint("post-register", "evt_2", 10); err != nil || len(attempts) != 1 || attempts[0].EventID != "evt_2" {
+		t.Errorf("ListByPoint(evt_2) = %+v, %v", attempts, err)
+	}
+	if attempts, err := repo.ListByPoint("post-register", "evt_3", 10); err != nil || len(attempts) != 0 {
+		t.Errorf("ListByPoint() of another point's event = %+v, %v; want none", attempts, err)
+	}
+
+	if deleted, err := repo.DeleteBefore(now.Add(2 * time.Minute)); err != nil || deleted != 2 {
+		t.Errorf("DeleteBefore() = %d, %v; want 2", deleted, err)
+	}
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// openTestDB opens an empty database of the test's own in a temporary
+// directory and closes it when the test ends, so tests using it can run in
+// parallel
+func openTestDB(t testing.TB) *sql.DB {
+	t.Helper()
+	db, err := OpenDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// setupTestDB opens a database with openTestDB and applies the core schema
+// migrations. Tests apply their module's migrations with MigrateModule.
+func setupTestDB(t testing.TB) *sql.DB {
+	t.Helper()
+	db := openTestDB(t)
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return db
+}
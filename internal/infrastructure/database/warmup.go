@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// warmUpQueries are the reads behind sign-in and authenticated requests.
+// A new SQLite connection parses the schema on its first statement, and the
+// first reads of a table fault its pages into the cache; running these once
+// per connection does both before traffic arrives. They match no rows.
+var warmUpQueries = []string{
+	`SELECT ` + userColumns + ` FROM users WHERE id = -1`,
+	`SELECT ` + userColumns + ` FROM users WHERE email = ''`,
+	`SELECT COUNT(*) FROM users`,
+	`SELECT COALESCE(MAX(id), 0) FROM domain_events`,
+}
+
+// WarmUp opens conns connections to db at once, so the pool does not open
+// them on the first requests, and runs the warm-up queries on each. The
+// connections go back to the pool, which keeps as many of them idle as its
+// SetMaxIdleConns allows.
+func WarmUp(ctx context.Context, db *sql.DB, conns int) error {
+	held := make([]*sql.Conn, 0, conns)
+	defer func() {
+		for _, conn := range held {
+			conn.Close()
+		}
+	}()
+
+	for range conns {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to open database connection: %w", err)
+		}
+		held = append(held, conn)
+		for _, query := range warmUpQueries {
+			rows, err := conn.QueryContext(ctx, query)
+			if err != nil {
+				return fmt.Errorf("failed to warm up database connection: %w", err)
+			}
+			rows.Close()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWarmUp(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	db.SetMaxIdleConns(3)
+
+	if err := WarmUp(context.Background(), db, 3); err != nil {
+		t.Fatalf("WarmUp() error = %v", err)
+	}
+	if stats := db.Stats(); stats.OpenConnections != 3 || stats.Idle != 3 {
+		t.Errorf("connections = %d open, %d idle; want 3 idle", stats.OpenConnections, stats.Idle)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WarmUp(ctx, db, 1); err == nil {
+		t.Error("WarmUp() with a cancelled context succeeded")
+	}
+}
@@ -0,0 +1,114 @@
+// Package encryption encrypts users' personal data with a key per user kept
+// in a KeyStore. Deleting a user's key (crypto-shredding) makes their data
+// unreadable at once, including the copies in database backups.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// KeySize is the size of the per-user AES-256 keys
+const KeySize = 32
+
+// sealedPrefix marks a field value encrypted by this package. Values
+// without it were stored before encryption was enabled.
+const sealedPrefix = "enc:v1:"
+
+// encryptedFields lists the user fields that are encrypted. Email and full
+// name stay in plaintext, as users are looked up and searched by them.
+var encryptedFields = []string{repository.FieldPhoneNumber, repository.FieldBirthday, repository.FieldNationalID}
+
+// isEncrypted reports whether field is one of encryptedFields
+func isEncrypted(field string) bool {
+	for _, name := range encryptedFields {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// userFields returns pointers to the user's encrypted fields by name
+func userFields(user *entity.User) map[string]*string {
+	return map[string]*string{
+		repository.FieldPhoneNumber: &user.PhoneNumber,
+		repository.FieldBirthday:    &user.Birthday,
+		repository.FieldNationalID:  &user.NationalID,
+	}
+}
+
+// newKey returns a random key
+func newKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// newAEAD returns AES-256-GCM with key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts value for field with key. The field name is authenticated,
+// so sealed values cannot be moved between fields. Empty values stay empty.
+func seal(key []byte, field, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(field))
+	return sealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts a value sealed for field. Values stored before encryption
+// was enabled are returned as they are. A nil key, i.e. a shredded one,
+// opens sealed values as empty.
+func open(key []byte, field, value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, sealedPrefix)
+	if !ok {
+		return value, nil
+	}
+	if key == nil {
+		return "", nil
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted field " + field)
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt field %s: %w", field, err)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,104 @@
+package encryption
+
+import (
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// RevisionRepository encrypts the personal data in the revisions stored in
+// another UserRevisionRepository with the user's key. Revisions recorded
+// after the key was shredded, such as the one for the delete itself, keep
+// no personal data.
+type RevisionRepository struct {
+	revisions repository.UserRevisionRepository
+	keys      repository.KeyStore
+}
+
+// NewRevisionRepository wraps revisions, reading the keys from keys
+func NewRevisionRepository(revisions repository.UserRevisionRepository, keys repository.KeyStore) *RevisionRepository {
+	return &RevisionRepository{revisions: revisions, keys: keys}
+}
+
+// Record saves a revision with its personal data encrypted
+func (r *RevisionRepository) Record(revision *entity.UserRevision) error {
+	key, err := getKey(r.keys, revision.UserID)
+	if err != nil {
+		return err
+	}
+
+	sealed := *revision
+	sealed.Changes = append([]entity.FieldChange(nil), revision.Changes...)
+	sealValue := func(field, value string) (string, error) {
+		if key == nil {
+			return "", nil
+		}
+		return seal(key, field, value)
+	}
+	for name, field := range userFields(&sealed.Snapshot) {
+		if *field, err = sealValue(name, *field); err != nil {
+			return err
+		}
+	}
+	for i, change := range sealed.Changes {
+		if !isEncrypted(change.Field) {
+			continue
+		}
+		if sealed.Changes[i].Old, err = sealValue(change.Field, change.Old); err != nil {
+			return err
+		}
+		if sealed.Changes[i].New, err = sealValue(change.Field, change.New); err != nil {
+			return err
+		}
+	}
+
+	if err := r.revisions.Record(&sealed); err != nil {
+		return err
+	}
+	revision.ID, revision.CreatedAt = sealed.ID, sealed.CreatedAt
+	return nil
+}
+
+// ListByUser returns a user's revisions, newest first, within the page
+func (r *RevisionRepository) ListByUser(userID int, page repository.Page) ([]*entity.UserRevision, error) {
+	return r.open(r.revisions.ListByUser(userID, page))
+}
+
+// List returns every user's revisions, oldest first, within the page
+func (r *RevisionRepository) List(page repository.Page) ([]*entity.UserRevision, error) {
+	return r.open(r.revisions.List(page))
+}
+
+// open decrypts revisions read from the wrapped repository, reading each
+// user's key once
+func (r *RevisionRepository) open(revisions []*entity.UserRevision, err error) ([]*entity.UserRevision, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[int][]byte)
+	for _, revision := range revisions {
+		key, ok := keys[revision.UserID]
+		if !ok {
+			if key, err = getKey(r.keys, revision.UserID); err != nil {
+				return nil, err
+			}
+			keys[revision.UserID] = key
+		}
+
+		if err := openUser(key, &revision.Snapshot); err != nil {
+			return nil, err
+		}
+		for i, change := range revision.Changes {
+			if !isEncrypted(change.Field) {
+				continue
+			}
+			if revision.Changes[i].Old, err = open(key, change.Field, change.Old); err != nil {
+				return nil, err
+			}
+			if revision.Changes[i].New, err = open(key, change.Field, change.New); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return revisions, nil
+}
@@ -0,0 +1,38 @@
+package encryption
+
+import "fiber-hello-world/internal/domain/repository"
+
+// SnapshotSource decrypts the users and revisions read from the snapshots
+// of another SnapshotSource
+type SnapshotSource struct {
+	source repository.SnapshotSource
+	keys   repository.KeyStore
+}
+
+// NewSnapshotSource wraps source, reading the keys from keys
+func NewSnapshotSource(source repository.SnapshotSource, keys repository.KeyStore) *SnapshotSource {
+	return &SnapshotSource{source: source, keys: keys}
+}
+
+// Snapshot captures the current data; Close the snapshot when done
+func (s *SnapshotSource) Snapshot() (repository.Snapshot, error) {
+	snapshot, err := s.source.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &decryptingSnapshot{Snapshot: snapshot, keys: s.keys}, nil
+}
+
+// decryptingSnapshot wraps a snapshot's repositories to decrypt what they read
+type decryptingSnapshot struct {
+	repository.Snapshot
+	keys repository.KeyStore
+}
+
+func (s *decryptingSnapshot) Users() repository.UserRepository {
+	return NewUserRepository(s.Snapshot.Users(), s.keys)
+}
+
+func (s *decryptingSnapshot) Revisions() repository.UserRevisionRepository {
+	return NewRevisionRepository(s.Snapshot.Revisions(), s.keys)
+}
@@ -0,0 +1,209 @@
+package encryption
+
+import (
+	"errors"
+	"fmt"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// UserRepository encrypts the personal data of the users stored in another
+// UserRepository with each user's key from a KeyStore. Delete shreds the
+// key, so the user's data stays unreadable in backups and revisions too.
+type UserRepository struct {
+	users repository.UserRepository
+	keys  repository.KeyStore
+}
+
+// NewUserRepository wraps users, keeping the keys in keys
+func NewUserRepository(users repository.UserRepository, keys repository.KeyStore) *UserRepository {
+	return &UserRepository{users: users, keys: keys}
+}
+
+// Create saves a new user with a new key
+func (r *UserRepository) Create(user *entity.User) (*entity.User, error) {
+	key, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	sealed := *user
+	if err := sealUser(key, &sealed); err != nil {
+		return nil, err
+	}
+
+	created, err := r.users.Create(&sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	// A key left behind for this ID belongs to no one and is replaced
+	err = r.keys.Put(created.ID, key)
+	if errors.Is(err, repository.ErrKeyExists) {
+		if err = r.keys.Delete(created.ID); err == nil {
+			err = r.keys.Put(created.ID, key)
+		}
+	}
+	if err != nil {
+		// Without its key the user's data could never be read
+		r.users.Delete(created.ID)
+		return nil, fmt.Errorf("failed to store encryption key: %w", err)
+	}
+
+	return created, openUser(key, created)
+}
+
+// GetByEmail retrieves a user by email
+func (r *UserRepository) GetByEmail(email string) (*entity.User, error) {
+	return r.open(r.users.GetByEmail(email))
+}
+
+// ExistsByEmail reports whether a user with the email exists
+func (r *UserRepository) ExistsByEmail(email string) (bool, error) {
+	return r.users.ExistsByEmail(email)
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(id int) (*entity.User, error) {
+	return r.open(r.users.GetByID(id))
+}
+
+// Update updates user information, encrypting it with the user's key
+func (r *UserRepository) Update(user *entity.User) error {
+	key, err := r.keyFor(user.ID)
+	if err != nil {
+		return err
+	}
+	sealed := *user
+	if err := sealUser(key, &sealed); err != nil {
+		return err
+	}
+
+	if err := r.users.Update(&sealed); err != nil {
+		return err
+	}
+	user.Version = sealed.Version
+	return nil
+}
+
+// UpdatePassword replaces the stored password hash
+func (r *UserRepository) UpdatePassword(id int, hash string) error {
+	return r.users.UpdatePassword(id, hash)
+}
+
+// UpdateFields updates only the given fields, encrypting personal data with the user's key
+func (r *UserRepository) UpdateFields(id int, fields map[string]interface{}) error {
+	sealed := make(map[string]interface{}, len(fields))
+	var key []byte
+	for name, value := range fields {
+		text, ok := value.(string)
+		if !isEncrypted(name) || !ok {
+			sealed[name] = value
+			continue
+		}
+
+		if key == nil {
+			var err error
+			if key, err = r.keyFor(id); err != nil {
+				return err
+			}
+		}
+		var err error
+		if sealed[name], err = seal(key, name, text); err != nil {
+			return err
+		}
+	}
+
+	return r.users.UpdateFields(id, sealed)
+}
+
+// Delete shreds the user's key, then removes the user
+func (r *UserRepository) Delete(id int) error {
+	if err := r.keys.Delete(id); err != nil {
+		return fmt.Errorf("failed to shred encryption key: %w", err)
+	}
+	return r.users.Delete(id)
+}
+
+// List returns users matching the filter, ordered by ID, within the page
+func (r *UserRepository) List(filter repository.UserFilter, page repository.Page) ([]*entity.User, error) {
+	users, err := r.users.List(filter, page)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if _, err := r.open(user, nil); err != nil {
+			return nil, err
+		}
+	}
+	return users, nil
+}
+
+// Count returns the number of users matching the filter
+func (r *UserRepository) Count(filter repository.UserFilter) (int, error) {
+	return r.users.Count(filter)
+}
+
+// open decrypts a user read from the wrapped repository
+func (r *UserRepository) open(user *entity.User, err error) (*entity.User, error) {
+	if err != nil {
+		return nil, err
+	}
+	key, err := getKey(r.keys, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return user, openUser(key, user)
+}
+
+// keyFor returns the user's key, creating one for users stored before
+// encryption was enabled
+func (r *UserRepository) keyFor(id int) ([]byte, error) {
+	key, err := r.keys.Get(id)
+	if !errors.Is(err, repository.ErrKeyNotFound) {
+		return key, err
+	}
+
+	if key, err = newKey(); err != nil {
+		return nil, err
+	}
+	err = r.keys.Put(id, key)
+	if errors.Is(err, repository.ErrKeyExists) {
+		// Created concurrently; use the stored one
+		return r.keys.Get(id)
+	}
+	return key, err
+}
+
+// getKey returns the user's key, or nil when there is none
+func getKey(keys repository.KeyStore, userID int) ([]byte, error) {
+	key, err := keys.Get(userID)
+	if errors.Is(err, repository.ErrKeyNotFound) {
+		return nil, nil
+	}
+	return key, err
+}
+
+// sealUser encrypts the user's personal data in place
+func sealUser(key []byte, user *entity.User) error {
+	for name, field := range userFields(user) {
+		sealed, err := seal(key, name, *field)
+		if err != nil {
+			return err
+		}
+		*field = sealed
+	}
+	return nil
+}
+
+// openUser decrypts the user's personal data in place
+func openUser(key []byte, user *entity.User) error {
+	for name, field := range userFields(user) {
+		plain, err := open(key, name, *field)
+		if err != nil {
+			return fmt.Errorf("user %d: %w", user.ID, err)
+		}
+		*field = plain
+	}
+	return nil
+}
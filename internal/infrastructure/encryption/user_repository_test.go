@@ -0,0 +1,191 @@
+package encryption
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/domain/repository/repositorytest"
+	"fiber-hello-world/internal/infrastructure/memory"
+)
+
+// mapKeyStore is an in-memory KeyStore for tests
+type mapKeyStore struct {
+	mu   sync.Mutex
+	keys map[int][]byte
+}
+
+func newMapKeyStore() *mapKeyStore {
+	return &mapKeyStore{keys: make(map[int][]byte)}
+}
+
+func (s *mapKeyStore) Get(userID int) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[userID]
+	if !ok {
+		return nil, repository.ErrKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *mapKeyStore) Put(userID int, key []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[userID]; ok {
+		return repository.ErrKeyExists
+	}
+	s.keys[userID] = key
+	return nil
+}
+
+func (s *mapKeyStore) Delete(userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, userID)
+	return nil
+}
+
+func TestUserRepository_Conformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T, now func() time.Time) repository.UserRepository {
+		return NewUserRepository(memory.NewUserRepositoryWithClock(now), newMapKeyStore())
+	})
+}
+
+func TestUserRepository_EncryptsAndShreds(t *testing.T) {
+	inner := memory.NewUserRepository()
+	keys := newMapKeyStore()
+	repo := NewUserRepository(inner, keys)
+
+	created, err := repo.Create(repositorytest.NewUser("secret@example.com"))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.PhoneNumber != "0812345678" || created.Birthday != "1990-01-15" {
+		t.Errorf("Create() = %+v, want decrypted fields", created)
+	}
+
+	// Only the wrapped repository's copy is encrypted
+	stored, _ := inner.GetByID(created.ID)
+	if !strings.HasPrefix(stored.PhoneNumber, sealedPrefix) || !strings.HasPrefix(stored.Birthday, sealedPrefix) {
+		t.Errorf("stored = %+v, want encrypted phone number and birthday", stored)
+	}
+	if stored.Email != "secret@example.com" || stored.FullName != "Conformance User" {
+		t.Errorf("stored = %+v, want plaintext email and name", stored)
+	}
+
+	if err := repo.UpdateFields(created.ID, map[string]interface{}{repository.FieldPhoneNumber: "0899999999"}); err != nil {
+		t.Fatalf("UpdateFields() error = %v", err)
+	}
+	if found, err := repo.GetByID(created.ID); err != nil || found.PhoneNumber != "0899999999" {
+		t.Errorf("GetByID() = %+v, %v; want the new phone number", found, err)
+	}
+
+	// A copy taken before the delete, as in a backup, cannot be read afterwards
+	backup, _ := inner.GetByID(created.ID)
+	if err := repo.Delete(created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := keys.Get(created.ID); err == nil {
+		t.Error("Delete() should shred the user's key")
+	}
+	restored, err := repo.open(backup, nil)
+	if err != nil || restored.PhoneNumber != "" || restored.Birthday != "" {
+		t.Errorf("restored user = %+v, %v; want personal data erased", restored, err)
+	}
+}
+
+func TestUserRepository_LegacyPlaintext(t *testing.T) {
+	inner := memory.NewUserRepository()
+	legacy, err := inner.Create(repositorytest.NewUser("legacy@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Users stored before encryption was enabled are read as they are and
+	// get a key on their next write
+	keys := newMapKeyStore()
+	repo := NewUserRepository(inner, keys)
+	if found, err := repo.GetByID(legacy.ID); err != nil || found.PhoneNumber != "0812345678" {
+		t.Errorf("GetByID() = %+v, %v", found, err)
+	}
+	if err := repo.UpdateFields(legacy.ID, map[string]interface{}{repository.FieldBirthday: "1991-02-03"}); err != nil {
+		t.Fatalf("UpdateFields() error = %v", err)
+	}
+	if _, err := keys.Get(legacy.ID); err != nil {
+		t.Errorf("UpdateFields() should create a key, got %v", err)
+	}
+	stored, _ := inner.GetByID(legacy.ID)
+	if !strings.HasPrefix(stored.Birthday, sealedPrefix) || stored.PhoneNumber != "0812345678" {
+		t.Errorf("stored = %+v, want only the written field encrypted", stored)
+	}
+}
+
+func TestRevisionRepository(t *testing.T) {
+	keys := newMapKeyStore()
+	inner := &memoryRevisions{}
+	repo := NewRevisionRepository(inner, keys)
+	key, _ := newKey()
+	keys.Put(1, key)
+
+	before := &entity.User{ID: 1, Email: "rev@example.com", PhoneNumber: "0812345678", Birthday: "1990-01-15"}
+	after := *before
+	after.PhoneNumber = "0899999999"
+	if err := repo.Record(entity.NewUserRevision(1, before, &after)); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	stored := inner.revisions[0]
+	if !strings.HasPrefix(stored.Snapshot.PhoneNumber, sealedPrefix) || !strings.HasPrefix(stored.Changes[0].New, sealedPrefix) {
+		t.Errorf("stored = %+v, want encrypted personal data", stored)
+	}
+
+	revisions, err := repo.ListByUser(1, repository.Page{})
+	if err != nil || revisions[0].Snapshot.PhoneNumber != "0812345678" || revisions[0].Changes[0].New != "0899999999" {
+		t.Errorf("ListByUser() = %+v, %v; want decrypted revisions", revisions, err)
+	}
+
+	// After shredding, old revisions read without personal data and new
+	// ones store none
+	keys.Delete(1)
+	if err := repo.Record(entity.NewUserRevision(1, &after, nil)); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if inner.revisions[1].Snapshot.PhoneNumber != "" {
+		t.Errorf("revision after shredding stored %q", inner.revisions[1].Snapshot.PhoneNumber)
+	}
+	revisions, err = repo.List(repository.Page{})
+	if err != nil || revisions[0].Snapshot.PhoneNumber != "" || revisions[0].Changes[0].Old != "" || revisions[0].Snapshot.Email != "rev@example.com" {
+		t.Errorf("List() = %+v, %v; want personal data erased", revisions[0], err)
+	}
+}
+
+// memoryRevisions is an in-memory UserRevisionRepository for tests. It
+// returns copies, as a database would.
+type memoryRevisions struct {
+	revisions []*entity.UserRevision
+}
+
+func (m *memoryRevisions) Record(revision *entity.UserRevision) error {
+	stored := *revision
+	stored.ID = len(m.revisions) + 1
+	m.revisions = append(m.revisions, &stored)
+	revision.ID = stored.ID
+	return nil
+}
+
+func (m *memoryRevisions) ListByUser(userID int, page repository.Page) ([]*entity.UserRevision, error) {
+	return m.List(page)
+}
+
+func (m *memoryRevisions) List(page repository.Page) ([]*entity.UserRevision, error) {
+	var revisions []*entity.UserRevision
+	for _, revision := range m.revisions {
+		copied := *revision
+		copied.Changes = append([]entity.FieldChange(nil), revision.Changes...)
+		revisions = append(revisions, &copied)
+	}
+	return revisions, nil
+}
@@ -0,0 +1,116 @@
+// Package kyc verifies identity documents with an external identity
+// proofing service.
+package kyc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/pkg/signature"
+)
+
+// maxProviderResponse caps how much of a provider response is read
+const maxProviderResponse = 64 << 10
+
+// Options configures an HTTPProvider
+type Options struct {
+	// URL receives a POST per document
+	URL string
+	// Secret signs requests like incoming signed requests
+	// (X-Signature-Timestamp, X-Signature-Nonce and X-Signature) when set
+	Secret string
+	// Client defaults to an http.Client with a 30 second timeout
+	Client *http.Client
+}
+
+// HTTPProvider implements KYCProvider by POSTing each document as JSON to a
+// verification service, which answers with a decision
+type HTTPProvider struct {
+	opts Options
+}
+
+// NewHTTPProvider creates a new HTTP verification provider
+func NewHTTPProvider(opts Options) (*HTTPProvider, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid KYC provider URL %q", opts.URL)
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &HTTPProvider{opts: opts}, nil
+}
+
+// verifyRequest is the body sent for a document. Document is base64 encoded.
+type verifyRequest struct {
+	SubmissionID int    `json:"submissionId"`
+	UserID       int    `json:"userId"`
+	DocumentType string `json:"documentType"`
+	ContentType  string `json:"contentType"`
+	Document     []byte `json:"document"`
+}
+
+// Verify sends the document and returns the provider's decision. A non-2xx
+// response or a decision other than verified, rejected or review is an error.
+func (p *HTTPProvider) Verify(submission *entity.KYCSubmission, document []byte) (entity.KYCDecision, error) {
+	body, err := json.Marshal(verifyRequest{
+		SubmissionID: submission.ID,
+		UserID:       submission.UserID,
+		DocumentType: submission.DocumentType,
+		ContentType:  submission.ContentType,
+		Document:     document,
+	})
+	if err != nil {
+		return entity.KYCDecision{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return entity.KYCDecision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.opts.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		nonce := make([]byte, 16)
+		if _, err := rand.Read(nonce); err != nil {
+			return entity.KYCDecision{}, err
+		}
+		req.Header.Set(signature.HeaderTimestamp, timestamp)
+		req.Header.Set(signature.HeaderNonce, hex.EncodeToString(nonce))
+		req.Header.Set(signature.HeaderSignature, signature.Sign([]byte(p.opts.Secret), req.Method, req.URL.RequestURI(),
+			timestamp, hex.EncodeToString(nonce), "", body))
+	}
+
+	resp, err := p.opts.Client.Do(req)
+	if err != nil {
+		return entity.KYCDecision{}, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxProviderResponse))
+	if err != nil {
+		return entity.KYCDecision{}, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return entity.KYCDecision{}, fmt.Errorf("KYC provider returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var decision entity.KYCDecision
+	if err := json.Unmarshal(data, &decision); err != nil {
+		return entity.KYCDecision{}, fmt.Errorf("invalid KYC provider response: %w", err)
+	}
+	if !decision.Valid() {
+		return entity.KYCDecision{}, fmt.Errorf("invalid KYC provider response: unknown status %q", decision.Status)
+	}
+	return decision, nil
+}
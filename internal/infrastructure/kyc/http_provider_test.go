@@ -0,0 +1,77 @@
+package kyc
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/pkg/signature"
+)
+
+func TestHTTPProvider_Verify(t *testing.T) {
+	verifier := signature.NewVerifier(map[string]string{"kyc": "kyc-secret"}, time.Minute)
+	var received verifyRequest
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verifyErr = verifier.Verify(signature.Request{
+			ClientID:  "kyc",
+			Method:    r.Method,
+			URI:       r.URL.RequestURI(),
+			Timestamp: r.Header.Get(signature.HeaderTimestamp),
+			Nonce:     r.Header.Get(signature.HeaderNonce),
+			Signature: r.Header.Get(signature.HeaderSignature),
+			Body:      body,
+		})
+		json.Unmarshal(body, &received)
+		switch received.DocumentType {
+		case entity.KYCDocumentPassport:
+			io.WriteString(w, `{"status":"verified","reference":"chk_1"}`)
+		case entity.KYCDocumentDrivingLicense:
+			io.WriteString(w, `{"status":"approved"}`)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, "busy")
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPProvider(Options{URL: server.URL + "/verify", Secret: "kyc-secret"})
+	if err != nil {
+		t.Fatalf("NewHTTPProvider() error = %v", err)
+	}
+
+	submission := &entity.KYCSubmission{ID: 3, UserID: 42, DocumentType: entity.KYCDocumentPassport, ContentType: "image/png"}
+	decision, err := provider.Verify(submission, []byte("scan"))
+	if err != nil || decision.Status != entity.KYCVerified || decision.Reference != "chk_1" {
+		t.Fatalf("Verify() = %+v, %v", decision, err)
+	}
+	if verifyErr != nil {
+		t.Errorf("signature verification error = %v", verifyErr)
+	}
+	if received.SubmissionID != 3 || received.UserID != 42 || string(received.Document) != "scan" {
+		t.Errorf("request = %+v", received)
+	}
+
+	submission.DocumentType = entity.KYCDocumentDrivingLicense
+	if _, err := provider.Verify(submission, []byte("scan")); err == nil || !strings.Contains(err.Error(), "approved") {
+		t.Errorf("Verify() with an unknown status error = %v", err)
+	}
+	submission.DocumentType = entity.KYCDocumentNationalID
+	if _, err := provider.Verify(submission, []byte("scan")); err == nil || !strings.Contains(err.Error(), "busy") {
+		t.Errorf("Verify() on a 503 error = %v", err)
+	}
+}
+
+func TestNewHTTPProvider_InvalidURL(t *testing.T) {
+	for _, target := range []string{"", "ftp://kyc.example.com", "https://"} {
+		if _, err := NewHTTPProvider(Options{URL: target}); err == nil {
+			t.Errorf("NewHTTPProvider(%q) should fail", target)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Package mail sends email through an SMTP server.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPMailer implements Mailer with an SMTP server. The connection is
+// upgraded with STARTTLS when the server offers it.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+	now  func() time.Time
+}
+
+// NewSMTPMailer creates a mailer sending from from through the server at
+// addr (host:port). Username and password are optional; PLAIN
+// authentication is only used over TLS or to localhost.
+func NewSMTPMailer(addr, username, password, from string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP address %q: %w", addr, err)
+	}
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", from, err)
+	}
+
+	mailer := &SMTPMailer{addr: addr, from: from, now: time.Now}
+	if username != "" {
+		mailer.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return mailer, nil
+}
+
+// Send emails body to every address in to as one plain text message
+func (m *SMTPMailer) Send(to []string, subject, body string) error {
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, sender.Address, to, m.message(to, subject, body))
+}
+
+// Ping checks that the SMTP server answers with its greeting, e.g. for
+// dependency checks, without sending anything
+func (m *SMTPMailer) Ping(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	host, _, _ := net.SplitHostPort(m.addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	return client.Quit()
+}
+
+// message builds the RFC 5322 message. Line breaks in the subject are
+// folded into spaces, so it cannot add headers, and non-ASCII is encoded.
+func (m *SMTPMailer) message(to []string, subject, body string) []byte {
+	subject = strings.Join(strings.Fields(subject), " ")
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", m.now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(msg.String())
+}
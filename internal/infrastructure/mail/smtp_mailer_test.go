@@ -0,0 +1,129 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveSMTP accepts one SMTP session on a local listener and sends the
+// envelope and message it received on the returned channel
+func serveSMTP(t *testing.T) (string, <-chan []string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var lines []string
+		reader := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for inData := false; ; {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case inData && line == ".":
+				inData = false
+				reply("250 OK")
+			case inData:
+				lines = append(lines, line)
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case line == "DATA":
+				inData = true
+				reply("354 Go ahead")
+			case line == "QUIT":
+				reply("221 Bye")
+				received <- lines
+				return
+			default:
+				lines = append(lines, line)
+				reply("250 OK")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	addr, received := serveSMTP(t)
+	mailer, err := NewSMTPMailer(addr, "", "", "API Digest <digest@example.com>")
+	if err != nil {
+		t.Fatalf("NewSMTPMailer() error = %v", err)
+	}
+	mailer.now = func() time.Time { return time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC) }
+
+	err = mailer.Send([]string{"admin@example.com", "ops@example.com"}, "Daily digest\r\nBcc: victim@example.com", "3 signups\nSee /admin/funnel")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	var session string
+	select {
+	case lines := <-received:
+		session = strings.Join(lines, "\n")
+	case <-time.After(2 * time.Second):
+		t.Fatal("no SMTP session")
+	}
+	for _, want := range []string{
+		"MAIL FROM:<digest@example.com>",
+		"RCPT TO:<admin@example.com>",
+		"RCPT TO:<ops@example.com>",
+		"Subject: Daily digest Bcc: victim@example.com\n",
+		"Date: Mon, 03 Jun 2024 08:00:00 +0000",
+		"3 signups\nSee /admin/funnel",
+	} {
+		if !strings.Contains(session, want) {
+			t.Errorf("session missing %q:\n%s", want, session)
+		}
+	}
+}
+
+func TestNewSMTPMailer_Validation(t *testing.T) {
+	if _, err := NewSMTPMailer("smtp.example.com", "", "", "digest@example.com"); err == nil {
+		t.Error("NewSMTPMailer() should require a port")
+	}
+	if _, err := NewSMTPMailer("smtp.example.com:587", "", "", "not an address"); err == nil {
+		t.Error("NewSMTPMailer() should reject an invalid sender")
+	}
+}
+
+func TestSMTPMailer_Ping(t *testing.T) {
+	addr, received := serveSMTP(t)
+	mailer, err := NewSMTPMailer(addr, "", "", "digest@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := mailer.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if lines := <-received; len(lines) != 0 {
+		t.Errorf("Ping() sent %q, want nothing", lines)
+	}
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed := listener.Addr().String()
+	listener.Close()
+	mailer, _ = NewSMTPMailer(closed, "", "", "digest@example.com")
+	if err := mailer.Ping(ctx); err == nil {
+		t.Error("Ping() of a closed port should fail")
+	}
+}
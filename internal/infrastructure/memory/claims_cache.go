@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// claimsEntry is a cached copy of a user's claims
+type claimsEntry struct {
+	claims    entity.DerivedClaims
+	expiresAt time.Time
+}
+
+// ClaimsCache implements ClaimsCache in the memory of one process
+type ClaimsCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[int]claimsEntry
+	// swept is when expired entries were last dropped
+	swept time.Time
+	now   func() time.Time
+}
+
+// NewClaimsCache creates a new in-memory claims cache whose entries expire
+// after ttl
+func NewClaimsCache(ttl time.Duration) *ClaimsCache {
+	return &ClaimsCache{ttl: ttl, entries: make(map[int]claimsEntry), now: time.Now}
+}
+
+// Get returns the cached claims of a user
+func (c *ClaimsCache) Get(userID int) (*entity.DerivedClaims, bool, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[userID]
+	c.mu.RUnlock()
+	if !ok || !c.now().Before(entry.expiresAt) {
+		return nil, false, nil
+	}
+	claims := entry.claims
+	return &claims, true, nil
+}
+
+// Set caches the claims of claims.UserID. Expired entries are dropped once
+// per TTL on the way, so the cache holds about the users seen within two TTLs.
+func (c *ClaimsCache) Set(claims *entity.DerivedClaims) error {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.Sub(c.swept) >= c.ttl {
+		for userID, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, userID)
+			}
+		}
+		c.swept = now
+	}
+	c.entries[claims.UserID] = claimsEntry{claims: *claims, expiresAt: now.Add(c.ttl)}
+	return nil
+}
+
+// Delete drops the cached claims of the users
+func (c *ClaimsCache) Delete(userIDs ...int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, userID := range userIDs {
+		delete(c.entries, userID)
+	}
+	return nil
+}
+
+// Len returns the number of cached users, including expired ones not yet
+// dropped
+func (c *ClaimsCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
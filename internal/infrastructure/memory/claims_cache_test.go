@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+func TestClaimsCache(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewClaimsCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	if _, ok, err := cache.Get(1); ok || err != nil {
+		t.Fatalf("Get() on an empty cache = %v, %v", ok, err)
+	}
+	cache.Set(&entity.DerivedClaims{UserID: 1, Role: entity.RoleAdmin, Status: entity.StatusActive})
+	cache.Set(&entity.DerivedClaims{UserID: 2, Role: entity.RoleUser, Status: entity.StatusActive})
+
+	claims, ok, err := cache.Get(1)
+	if !ok || err != nil || claims.Role != entity.RoleAdmin {
+		t.Fatalf("Get() = %+v, %v, %v", claims, ok, err)
+	}
+	claims.Role = entity.RoleUser
+	if cached, _, _ := cache.Get(1); cached.Role != entity.RoleAdmin {
+		t.Error("changing returned claims changed the cache")
+	}
+
+	cache.Delete(1, 3)
+	if _, ok, _ := cache.Get(1); ok {
+		t.Error("Get() after Delete() hit")
+	}
+	if _, ok, _ := cache.Get(2); !ok {
+		t.Error("Delete() dropped another user")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := cache.Get(2); ok {
+		t.Error("Get() of an expired entry hit")
+	}
+	cache.Set(&entity.DerivedClaims{UserID: 3})
+	if cache.Len() != 1 {
+		t.Errorf("Len() after expiry = %d, want 1", cache.Len())
+	}
+}
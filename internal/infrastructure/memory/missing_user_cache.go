@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"container/list"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// missingEntry is an email found to have no user
+type missingEntry struct {
+	email     string
+	expiresAt time.Time
+}
+
+// MissingUserCache remembers the emails GetByEmail found no user for, in the
+// memory of one process, so repeated lookups of unknown emails, e.g. a
+// credential-stuffing flood, do not each query the UserRepository it wraps.
+// Remembered emails fail with repository.ErrUserNotFoundCached until they
+// expire or a user is saved with them here. Writes on other nodes are not
+// seen, so the TTL should be short.
+type MissingUserCache struct {
+	repository.UserRepository
+	ttl  time.Duration
+	size int
+	now  func() time.Time
+
+	mu sync.Mutex
+	// order holds the entries oldest first, so the oldest is dropped once
+	// size emails are remembered
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// NewMissingUserCache wraps users, remembering up to size unknown emails
+// for ttl each
+func NewMissingUserCache(users repository.UserRepository, ttl time.Duration, size int) *MissingUserCache {
+	return &MissingUserCache{
+		UserRepository: users,
+		ttl:            ttl,
+		size:           size,
+		now:            time.Now,
+		order:          list.New(),
+		entries:        make(map[string]*list.Element),
+	}
+}
+
+// missingKey compares emails without case, so a remembered email is
+// forgotten whichever case it is saved in
+func missingKey(email string) string {
+	return strings.ToLower(email)
+}
+
+// GetByEmail retrieves a user by email, failing without a query for an
+// email recently found to have no user. Only repository.ErrUserNotFound is
+// remembered, so database errors are not.
+func (c *MissingUserCache) GetByEmail(email string) (*entity.User, error) {
+	key := missingKey(email)
+	if c.missing(key) {
+		return nil, repository.ErrUserNotFoundCached
+	}
+	user, err := c.UserRepository.GetByEmail(email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		c.remember(key)
+	}
+	return user, err
+}
+
+// Create saves a new user, then forgets that their email had none, also
+// if a lookup remembered it while the user was being saved
+func (c *MissingUserCache) Create(user *entity.User) (*entity.User, error) {
+	defer c.forget(missingKey(user.Email))
+	return c.UserRepository.Create(user)
+}
+
+// Update updates user information, then forgets that their email had none
+func (c *MissingUserCache) Update(user *entity.User) error {
+	defer c.forget(missingKey(user.Email))
+	return c.UserRepository.Update(user)
+}
+
+// UpdateFields updates the given fields, then forgets that a new email had
+// no user
+func (c *MissingUserCache) UpdateFields(id int, fields map[string]interface{}) error {
+	if email, ok := fields[repository.FieldEmail].(string); ok {
+		defer c.forget(missingKey(email))
+	}
+	return c.UserRepository.UpdateFields(id, fields)
+}
+
+// Len returns the number of remembered emails, including expired ones not
+// yet dropped
+func (c *MissingUserCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// missing reports whether key is remembered and has not expired
+func (c *MissingUserCache) missing(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	if !c.now().Before(element.Value.(*missingEntry).expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return false
+	}
+	return true
+}
+
+// remember records that key has no user, dropping the oldest entry when full
+func (c *MissingUserCache) remember(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*missingEntry).expiresAt = expiresAt
+		c.order.MoveToBack(element)
+		return
+	}
+	c.entries[key] = c.order.PushBack(&missingEntry{email: key, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*missingEntry).email)
+	}
+}
+
+// forget drops key, e.g. before a user is saved with it
+func (c *MissingUserCache) forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/domain/repository/repositorytest"
+)
+
+func TestMissingUserCache_Conformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T, now func() time.Time) repository.UserRepository {
+		return NewMissingUserCache(NewUserRepositoryWithClock(now), time.Minute, 100)
+	})
+}
+
+// countingRepository counts GetByEmail calls, failing them with err if set
+type countingRepository struct {
+	repository.UserRepository
+	lookups int
+	err     error
+}
+
+func (r *countingRepository) GetByEmail(email string) (*entity.User, error) {
+	r.lookups++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.UserRepository.GetByEmail(email)
+}
+
+func TestMissingUserCache_GetByEmail(t *testing.T) {
+	inner := &countingRepository{UserRepository: NewUserRepository()}
+	cache := NewMissingUserCache(inner, time.Minute, 2)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	for range 3 {
+		if _, err := cache.GetByEmail("ghost@example.com"); err == nil {
+			t.Fatal("GetByEmail() of an unknown email should fail")
+		}
+	}
+	if inner.lookups != 1 {
+		t.Errorf("lookups = %d, want 1", inner.lookups)
+	}
+	if _, err := cache.GetByEmail("Ghost@Example.com"); !errors.Is(err, repository.ErrUserNotFoundCached) {
+		t.Errorf("GetByEmail() in another case error = %v, want ErrUserNotFoundCached", err)
+	}
+
+	// Registering forgets the email
+	if _, err := cache.Create(repositorytest.NewUser("ghost@example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if user, err := cache.GetByEmail("ghost@example.com"); err != nil || user.Email != "ghost@example.com" {
+		t.Errorf("GetByEmail() after Create = %v, %v", user, err)
+	}
+
+	// So does changing a user's email to it
+	cache.GetByEmail("renamed@example.com")
+	if err := cache.UpdateFields(1, map[string]interface{}{repository.FieldEmail: "renamed@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.GetByEmail("renamed@example.com"); err != nil {
+		t.Errorf("GetByEmail() after an email change error = %v", err)
+	}
+
+	// Entries expire
+	lookups := inner.lookups
+	cache.GetByEmail("late@example.com")
+	now = now.Add(time.Minute)
+	cache.GetByEmail("late@example.com")
+	if inner.lookups != lookups+2 {
+		t.Errorf("lookups after expiry = %d, want %d", inner.lookups, lookups+2)
+	}
+
+	// The oldest entries are dropped past size
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		cache.GetByEmail(email)
+	}
+	if cache.Len() != 2 || cache.missing("a@example.com") || !cache.missing("c@example.com") {
+		t.Errorf("entries = %v, want the last two", cache.entries)
+	}
+}
+
+func TestMissingUserCache_GetByEmail_Failure(t *testing.T) {
+	inner := &countingRepository{UserRepository: NewUserRepository(), err: errors.New("database is locked")}
+	cache := NewMissingUserCache(inner, time.Minute, 2)
+
+	for range 2 {
+		if _, err := cache.GetByEmail("ghost@example.com"); err != inner.err {
+			t.Fatalf("GetByEmail() error = %v, want %v", err, inner.err)
+		}
+	}
+	if inner.lookups != 2 || cache.Len() != 0 {
+		t.Errorf("lookups = %d, entries = %d; a failed lookup should not be remembered", inner.lookups, cache.Len())
+	}
+}
@@ -0,0 +1,278 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// UserRepository implements UserRepository interface in memory.
+// It is intended for tests and ephemeral development setups.
+type UserRepository struct {
+	mu     sync.RWMutex
+	users  map[int]*entity.User
+	emails map[string]int
+	nextID int
+	now    func() time.Time
+}
+
+// NewUserRepository creates a new in-memory user repository
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users:  make(map[int]*entity.User),
+		emails: make(map[string]int),
+		nextID: 1,
+		now:    time.Now,
+	}
+}
+
+// NewUserRepositoryWithClock creates a new in-memory user repository that
+// reads the current time from now
+func NewUserRepositoryWithClock(now func() time.Time) *UserRepository {
+	repo := NewUserRepository()
+	repo.now = now
+	return repo
+}
+
+// Create saves a new user and returns the created user with ID.
+// CreatedAt and UpdatedAt are always set by the repository.
+func (r *UserRepository) Create(user *entity.User) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.emails[user.Email]; exists {
+		return nil, repository.ErrEmailTaken
+	}
+
+	user.ID = r.nextID
+	r.nextID++
+	if user.Role == "" {
+		user.Role = entity.RoleUser
+	}
+	if user.Status == "" {
+		user.Status = entity.StatusActive
+	}
+	if user.Plan == "" {
+		user.Plan = entity.PlanFree
+	}
+	user.CreatedAt = r.now().UTC()
+	user.UpdatedAt = user.CreatedAt
+	user.Version = 1
+
+	stored := *user
+	r.users[user.ID] = &stored
+	r.emails[user.Email] = user.ID
+	return user, nil
+}
+
+// GetByEmail retrieves a user by email
+func (r *UserRepository) GetByEmail(email string) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.emails[email]
+	if !exists {
+		return nil, repository.ErrUserNotFound
+	}
+
+	user := *r.users[id]
+	return &user, nil
+}
+
+// ExistsByEmail reports whether a user with the email exists
+func (r *UserRepository) ExistsByEmail(email string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, exists := r.emails[email]
+	return exists, nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(id int) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, exists := r.users[id]
+	if !exists {
+		return nil, errors.New("user not found")
+	}
+
+	user := *stored
+	return &user, nil
+}
+
+// Update updates user information. The memory backend always uses
+// last-write-wins; it only bumps the version.
+func (r *UserRepository) Update(user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.users[user.ID]
+	if !exists {
+		return nil
+	}
+
+	if owner, taken := r.emails[user.Email]; taken && owner != user.ID {
+		return repository.ErrEmailTaken
+	}
+
+	delete(r.emails, stored.Email)
+	r.emails[user.Email] = user.ID
+
+	// Password and creation time are not changed by Update, matching the SQL backends
+	stored.Email = user.Email
+	stored.FullName = user.FullName
+	stored.PhoneNumber = user.PhoneNumber
+	stored.Birthday = user.Birthday
+	stored.Avatar = user.Avatar
+	stored.Role = user.Role
+	stored.Status = user.Status
+	stored.Plan = user.Plan
+	stored.Timezone = user.Timezone
+	stored.NationalID = user.NationalID
+	stored.KYCStatus = user.KYCStatus
+	stored.UpdatedAt = r.now().UTC()
+	stored.Version++
+	user.Version = stored.Version
+	return nil
+}
+
+// UpdatePassword replaces the stored password hash
+func (r *UserRepository) UpdatePassword(id int, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, exists := r.users[id]; exists {
+		stored.Password = hash
+		stored.UpdatedAt = r.now().UTC()
+		stored.Version++
+	}
+	return nil
+}
+
+// UpdateFields updates only the given fields
+func (r *UserRepository) UpdateFields(id int, fields map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.users[id]
+	if !exists {
+		return nil
+	}
+
+	// Apply to a copy so a bad field leaves the stored user untouched
+	updated := *stored
+	for name, value := range fields {
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("field %q must be a string", name)
+		}
+		switch name {
+		case repository.FieldEmail:
+			updated.Email = str
+		case repository.FieldFullName:
+			updated.FullName = str
+		case repository.FieldPhoneNumber:
+			updated.PhoneNumber = str
+		case repository.FieldBirthday:
+			updated.Birthday = str
+		case repository.FieldAvatar:
+			updated.Avatar = str
+		case repository.FieldRole:
+			updated.Role = str
+		case repository.FieldStatus:
+			updated.Status = str
+		case repository.FieldPlan:
+			updated.Plan = str
+		case repository.FieldTimezone:
+			updated.Timezone = str
+		case repository.FieldNationalID:
+			updated.NationalID = str
+		case repository.FieldKYCStatus:
+			updated.KYCStatus = str
+		default:
+			return fmt.Errorf("unknown user field %q", name)
+		}
+	}
+
+	if owner, taken := r.emails[updated.Email]; taken && owner != id {
+		return repository.ErrEmailTaken
+	}
+	delete(r.emails, stored.Email)
+	r.emails[updated.Email] = id
+	updated.UpdatedAt = r.now().UTC()
+	updated.Version++
+	*stored = updated
+	return nil
+}
+
+// Delete removes a user by ID
+func (r *UserRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, exists := r.users[id]; exists {
+		delete(r.emails, user.Email)
+		delete(r.users, id)
+	}
+	return nil
+}
+
+// List returns users matching the filter, ordered by ID, within the page
+func (r *UserRepository) List(filter repository.UserFilter, page repository.Page) ([]*entity.User, error) {
+	page = page.Normalize()
+	matched := r.match(filter)
+
+	users := []*entity.User{}
+	for i := page.Offset; i < len(matched) && len(users) < page.Limit; i++ {
+		user := *matched[i]
+		users = append(users, &user)
+	}
+	return users, nil
+}
+
+// Count returns the number of users matching the filter
+func (r *UserRepository) Count(filter repository.UserFilter) (int, error) {
+	return len(r.match(filter)), nil
+}
+
+// match returns stored users matching the filter, sorted by ID
+func (r *UserRepository) match(filter repository.UserFilter) []*entity.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	search := strings.ToLower(filter.Search)
+	var matched []*entity.User
+	for _, user := range r.users {
+		if !filter.CreatedFrom.IsZero() && user.CreatedAt.Before(filter.CreatedFrom) {
+			continue
+		}
+		if !filter.CreatedTo.IsZero() && !user.CreatedAt.Before(filter.CreatedTo) {
+			continue
+		}
+		if filter.Status != "" && user.Status != filter.Status {
+			continue
+		}
+		if filter.ExcludeStatus != "" && user.Status == filter.ExcludeStatus {
+			continue
+		}
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(user.Email), search) &&
+			!strings.Contains(strings.ToLower(user.FullName), search) {
+			continue
+		}
+		matched = append(matched, user)
+	}
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return matched
+}
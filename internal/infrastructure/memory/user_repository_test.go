@@ -0,0 +1,17 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/domain/repository/repositorytest"
+)
+
+func TestUserRepository_Conformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T, now func() time.Time) repository.UserRepository {
+		repo := NewUserRepository()
+		repo.now = now
+		return repo
+	})
+}
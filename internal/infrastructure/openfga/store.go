@@ -0,0 +1,155 @@
+// Package openfga keeps users' role relationships in an OpenFGA store and
+// checks permissions against it over the OpenFGA HTTP API.
+package openfga
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// maxTuplesPerWrite is OpenFGA's default limit on tuples in one write
+const maxTuplesPerWrite = 100
+
+// Options configures a Store
+type Options struct {
+	// APIURL is the OpenFGA HTTP API, e.g. http://openfga:8080
+	APIURL  string
+	StoreID string
+	// ModelID pins the authorization model; empty uses the store's latest
+	ModelID string
+	// Token is sent as a bearer token when the server requires a preshared key
+	Token string
+	// Client defaults to an http.Client with a 5 second timeout
+	Client *http.Client
+}
+
+// Store implements RelationshipStore with an OpenFGA store
+type Store struct {
+	opts Options
+}
+
+// NewStore creates a new OpenFGA relationship store
+func NewStore(opts Options) (*Store, error) {
+	u, err := url.Parse(opts.APIURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid OpenFGA API URL %q", opts.APIURL)
+	}
+	if opts.StoreID == "" {
+		return nil, fmt.Errorf("OpenFGA store ID is required")
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+	opts.APIURL = strings.TrimSuffix(opts.APIURL, "/")
+
+	return &Store{opts: opts}, nil
+}
+
+// tupleKey is a tuple in the OpenFGA API
+type tupleKey struct {
+	User     string `json:"user"`
+	Relation string `json:"relation"`
+	Object   string `json:"object"`
+}
+
+type writeTuples struct {
+	TupleKeys   []tupleKey `json:"tuple_keys"`
+	OnDuplicate string     `json:"on_duplicate,omitempty"`
+	OnMissing   string     `json:"on_missing,omitempty"`
+}
+
+type writeRequest struct {
+	Writes               *writeTuples `json:"writes,omitempty"`
+	Deletes              *writeTuples `json:"deletes,omitempty"`
+	AuthorizationModelID string       `json:"authorization_model_id,omitempty"`
+}
+
+type checkRequest struct {
+	TupleKey             tupleKey `json:"tuple_key"`
+	AuthorizationModelID string   `json:"authorization_model_id,omitempty"`
+}
+
+type checkResponse struct {
+	Allowed bool `json:"allowed"`
+}
+
+// Write adds writes and removes deletes. Existing and missing tuples are
+// ignored, which needs OpenFGA 1.5.7 or later. Changes of more than 100
+// tuples are split over several requests, so they are not atomic.
+func (s *Store) Write(writes, deletes []entity.Relationship) error {
+	for len(writes) > 0 || len(deletes) > 0 {
+		req := writeRequest{AuthorizationModelID: s.opts.ModelID}
+		n := min(len(writes), maxTuplesPerWrite)
+		if n > 0 {
+			req.Writes = &writeTuples{TupleKeys: tupleKeys(writes[:n]), OnDuplicate: "ignore"}
+			writes = writes[n:]
+		}
+		if m := min(len(deletes), maxTuplesPerWrite-n); m > 0 {
+			req.Deletes = &writeTuples{TupleKeys: tupleKeys(deletes[:m]), OnMissing: "ignore"}
+			deletes = deletes[m:]
+		}
+		if err := s.post("write", req, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check asks OpenFGA whether the relationship holds
+func (s *Store) Check(tuple entity.Relationship) (bool, error) {
+	var resp checkResponse
+	req := checkRequest{TupleKey: tupleKeys([]entity.Relationship{tuple})[0], AuthorizationModelID: s.opts.ModelID}
+	if err := s.post("check", req, &resp); err != nil {
+		return false, err
+	}
+	return resp.Allowed, nil
+}
+
+// post sends body to the store's endpoint and decodes the response into out
+func (s *Store) post(endpoint string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost,
+		s.opts.APIURL+"/stores/"+url.PathEscape(s.opts.StoreID)+"/"+endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.opts.Token)
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("OpenFGA %s: %s: %s", endpoint, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// tupleKeys converts relationships to OpenFGA tuple keys
+func tupleKeys(tuples []entity.Relationship) []tupleKey {
+	keys := make([]tupleKey, len(tuples))
+	for i, tuple := range tuples {
+		keys[i] = tupleKey{User: tuple.Subject, Relation: tuple.Relation, Object: tuple.Object}
+	}
+	return keys
+}
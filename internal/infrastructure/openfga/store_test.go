@@ -0,0 +1,120 @@
+package openfga
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+func TestNewStore_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"no URL", Options{StoreID: "store"}},
+		{"not HTTP", Options{APIURL: "ftp://openfga", StoreID: "store"}},
+		{"no store", Options{APIURL: "http://openfga:8080"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewStore(tt.opts); err == nil {
+				t.Error("NewStore() should fail")
+			}
+		})
+	}
+}
+
+func TestStore_Write(t *testing.T) {
+	var requests []writeRequest
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		var req writeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode write: %v", err)
+		}
+		requests = append(requests, req)
+		io.WriteString(w, "{}")
+	}))
+	defer server.Close()
+
+	store, err := NewStore(Options{APIURL: server.URL + "/", StoreID: "01HSTORE", ModelID: "01HMODEL", Token: "secret"})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	// 150 writes and 60 deletes take three requests of at most 100 tuples
+	tuples := func(n int, object string) []entity.Relationship {
+		result := make([]entity.Relationship, n)
+		for i := range result {
+			result[i] = entity.Relationship{Subject: fmt.Sprintf("user:%d", i), Relation: "assignee", Object: object}
+		}
+		return result
+	}
+	if err := store.Write(tuples(150, "role:user"), tuples(60, "role:admin")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if gotPath != "/stores/01HSTORE/write" || gotAuth != "Bearer secret" {
+		t.Errorf("request = %s with Authorization %q", gotPath, gotAuth)
+	}
+	if len(requests) != 3 {
+		t.Fatalf("Write() sent %d requests, want 3", len(requests))
+	}
+	first, last := requests[0], requests[2]
+	if len(first.Writes.TupleKeys) != 100 || first.Deletes != nil || first.Writes.OnDuplicate != "ignore" || first.AuthorizationModelID != "01HMODEL" {
+		t.Errorf("first request = %+v", first)
+	}
+	if len(requests[1].Writes.TupleKeys) != 50 || len(requests[1].Deletes.TupleKeys) != 50 || requests[1].Deletes.OnMissing != "ignore" {
+		t.Errorf("second request = %d writes, %+v", len(requests[1].Writes.TupleKeys), requests[1].Deletes)
+	}
+	if last.Writes != nil || len(last.Deletes.TupleKeys) != 10 {
+		t.Errorf("last request = %+v", last)
+	}
+	if key := first.Writes.TupleKeys[0]; key != (tupleKey{User: "user:0", Relation: "assignee", Object: "role:user"}) {
+		t.Errorf("tuple key = %+v", key)
+	}
+
+	requests = nil
+	if err := store.Write(nil, nil); err != nil || len(requests) != 0 {
+		t.Errorf("empty Write() = %v with %d requests, want no request", err, len(requests))
+	}
+}
+
+func TestStore_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req checkRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case r.URL.Path != "/stores/store/check":
+			w.WriteHeader(http.StatusNotFound)
+		case req.TupleKey.Object == "document:broken":
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"code":"validation_error","message":"type 'document' not found"}`)
+		default:
+			fmt.Fprintf(w, `{"allowed":%t,"resolution":""}`, req.TupleKey == tupleKey{User: "user:1", Relation: "viewer", Object: "document:1"})
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewStore(Options{APIURL: server.URL, StoreID: "store"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if allowed, err := store.Check(entity.Relationship{Subject: "user:1", Relation: "viewer", Object: "document:1"}); err != nil || !allowed {
+		t.Errorf("Check() = %v, %v; want allowed", allowed, err)
+	}
+	if allowed, err := store.Check(entity.Relationship{Subject: "user:2", Relation: "viewer", Object: "document:1"}); err != nil || allowed {
+		t.Errorf("Check() = %v, %v; want denied", allowed, err)
+	}
+	_, err = store.Check(entity.Relationship{Subject: "user:1", Relation: "viewer", Object: "document:broken"})
+	if err == nil || !strings.Contains(err.Error(), "type 'document' not found") {
+		t.Errorf("Check() error = %v, want the server's message", err)
+	}
+}
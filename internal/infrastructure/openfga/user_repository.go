@@ -0,0 +1,82 @@
+package openfga
+
+import (
+	"log"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// UserRepository keeps users' role relationships in a RelationshipStore in
+// step with the users saved in another UserRepository. The user is saved
+// first, so a failed sync is logged rather than failing the write; a full
+// sync repairs it.
+type UserRepository struct {
+	repository.UserRepository
+	store repository.RelationshipStore
+}
+
+// NewUserRepository wraps users, syncing their roles to store
+func NewUserRepository(users repository.UserRepository, store repository.RelationshipStore) *UserRepository {
+	return &UserRepository{UserRepository: users, store: store}
+}
+
+// Create saves a new user and assigns their role
+func (r *UserRepository) Create(user *entity.User) (*entity.User, error) {
+	created, err := r.UserRepository.Create(user)
+	if err != nil {
+		return nil, err
+	}
+	r.sync(created)
+	return created, nil
+}
+
+// Update updates user information and syncs a changed role or status
+func (r *UserRepository) Update(user *entity.User) error {
+	if err := r.UserRepository.Update(user); err != nil {
+		return err
+	}
+	r.sync(user)
+	return nil
+}
+
+// UpdateFields updates the given fields, syncing the user when their role
+// or status changes
+func (r *UserRepository) UpdateFields(id int, fields map[string]interface{}) error {
+	if err := r.UserRepository.UpdateFields(id, fields); err != nil {
+		return err
+	}
+	_, role := fields[repository.FieldRole]
+	_, status := fields[repository.FieldStatus]
+	if !role && !status {
+		return nil
+	}
+
+	user, err := r.UserRepository.GetByID(id)
+	if err != nil {
+		log.Printf("Failed to sync roles of user %d to OpenFGA: %v", id, err)
+		return nil
+	}
+	r.sync(user)
+	return nil
+}
+
+// Delete removes a user and their role assignments
+func (r *UserRepository) Delete(id int) error {
+	if err := r.UserRepository.Delete(id); err != nil {
+		return err
+	}
+	_, deletes := entity.RoleRelationships(&entity.User{ID: id, Status: entity.StatusDeleted})
+	if err := r.store.Write(nil, deletes); err != nil {
+		log.Printf("Failed to remove roles of user %d from OpenFGA: %v", id, err)
+	}
+	return nil
+}
+
+// sync writes the user's role relationships
+func (r *UserRepository) sync(user *entity.User) {
+	writes, deletes := entity.RoleRelationships(user)
+	if err := r.store.Write(writes, deletes); err != nil {
+		log.Printf("Failed to sync roles of user %d to OpenFGA: %v", user.ID, err)
+	}
+}
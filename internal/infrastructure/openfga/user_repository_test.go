@@ -0,0 +1,87 @@
+package openfga
+
+import (
+	"errors"
+	"testing"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/memory"
+)
+
+// memoryStore is a RelationshipStore holding tuples in a map
+type memoryStore struct {
+	tuples map[entity.Relationship]bool
+	err    error
+}
+
+func (s *memoryStore) Write(writes, deletes []entity.Relationship) error {
+	if s.err != nil {
+		return s.err
+	}
+	for _, tuple := range writes {
+		s.tuples[tuple] = true
+	}
+	for _, tuple := range deletes {
+		delete(s.tuples, tuple)
+	}
+	return nil
+}
+
+func (s *memoryStore) Check(tuple entity.Relationship) (bool, error) {
+	return s.tuples[tuple], s.err
+}
+
+func TestUserRepository_SyncsRoles(t *testing.T) {
+	store := &memoryStore{tuples: make(map[entity.Relationship]bool)}
+	users := NewUserRepository(memory.NewUserRepository(), store)
+	assigned := func(id int, role string) bool {
+		return store.tuples[entity.Relationship{Subject: entity.UserObject(id), Relation: entity.RelationAssignee, Object: entity.RoleObject(role)}]
+	}
+
+	user, err := users.Create(entity.NewUser("fga@example.com", "hash", "FGA User", "0812345678", "1990-01-15"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assigned(user.ID, entity.RoleUser) || len(store.tuples) != 1 {
+		t.Fatalf("after Create tuples = %v", store.tuples)
+	}
+
+	user.Role = entity.RoleAdmin
+	if err := users.Update(user); err != nil {
+		t.Fatal(err)
+	}
+	if !assigned(user.ID, entity.RoleAdmin) || len(store.tuples) != 1 {
+		t.Errorf("after Update tuples = %v", store.tuples)
+	}
+
+	if err := users.UpdateFields(user.ID, map[string]interface{}{repository.FieldStatus: entity.StatusSuspended}); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.tuples) != 0 {
+		t.Errorf("suspended user keeps tuples %v", store.tuples)
+	}
+	if err := users.UpdateFields(user.ID, map[string]interface{}{
+		repository.FieldStatus: entity.StatusActive,
+		repository.FieldRole:   entity.RoleUser,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !assigned(user.ID, entity.RoleUser) || len(store.tuples) != 1 {
+		t.Errorf("after UpdateFields tuples = %v", store.tuples)
+	}
+
+	// A failed sync does not fail the write
+	store.err = errors.New("unavailable")
+	if err := users.UpdateFields(user.ID, map[string]interface{}{repository.FieldRole: entity.RoleAdmin}); err != nil {
+		t.Errorf("UpdateFields() error = %v, want the sync failure logged", err)
+	}
+	store.err = nil
+
+	if err := users.Delete(user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.tuples) != 0 {
+		t.Errorf("deleted user keeps tuples %v", store.tuples)
+	}
+}
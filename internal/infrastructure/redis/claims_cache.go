@@ -0,0 +1,83 @@
+// Package redis keeps shared caches and leases in a Redis server. It speaks
+// just enough of the Redis protocol (RESP) for them, over a small pool of
+// connections.
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// claimsKeyPrefix prefixes the keys of cached claims, e.g. "claims:42"
+const claimsKeyPrefix = "claims:"
+
+// ClaimsCache implements ClaimsCache with a Redis server shared by all
+// nodes. Claims are stored as JSON and expire with the TTL on the server.
+type ClaimsCache struct {
+	*client
+	ttl time.Duration
+}
+
+// NewClaimsCache creates a new Redis claims cache for the server at rawURL,
+// redis://[[user]:password@]host[:port][/db], or rediss:// for TLS. No
+// connection is made until the first command.
+func NewClaimsCache(rawURL string, ttl time.Duration) (*ClaimsCache, error) {
+	c, err := newClient(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &ClaimsCache{client: c, ttl: ttl}, nil
+}
+
+// Get returns the cached claims of a user
+func (c *ClaimsCache) Get(userID int) (*entity.DerivedClaims, bool, error) {
+	reply, err := c.do("GET", claimsKey(userID))
+	if errors.Is(err, errNil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %v", reply)
+	}
+	var claims entity.DerivedClaims
+	if err := json.Unmarshal([]byte(data), &claims); err != nil {
+		return nil, false, fmt.Errorf("invalid cached claims of user %d: %w", userID, err)
+	}
+	return &claims, true, nil
+}
+
+// Set caches the claims of claims.UserID
+func (c *ClaimsCache) Set(claims *entity.DerivedClaims) error {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return err
+	}
+	_, err = c.do("SET", claimsKey(claims.UserID), string(data), "PX", strconv.FormatInt(c.ttl.Milliseconds(), 10))
+	return err
+}
+
+// Delete drops the cached claims of the users
+func (c *ClaimsCache) Delete(userIDs ...int) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	args := make([]string, 0, len(userIDs)+1)
+	args = append(args, "DEL")
+	for _, userID := range userIDs {
+		args = append(args, claimsKey(userID))
+	}
+	_, err := c.do(args...)
+	return err
+}
+
+func claimsKey(userID int) string {
+	return claimsKeyPrefix + strconv.Itoa(userID)
+}
@@ -0,0 +1,246 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Connection settings
+const (
+	// defaultTimeout bounds dialing and each command
+	defaultTimeout = 2 * time.Second
+	// maxIdleConns is how many connections are kept open between commands
+	maxIdleConns = 8
+)
+
+// errNil is returned by do for a nil reply, e.g. GET of a missing key
+var errNil = errors.New("redis: nil")
+
+// Error is an error reply from the server
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+// conn is a connection with its buffered reader
+type conn struct {
+	net.Conn
+	rd *bufio.Reader
+}
+
+// client runs commands on a Redis server over a small pool of connections
+type client struct {
+	addr     string
+	tls      bool
+	username string
+	password string
+	db       int
+	timeout  time.Duration
+	idle     chan *conn
+}
+
+// newClient creates a client for the server at rawURL,
+// redis://[[user]:password@]host[:port][/db], or rediss:// for TLS. No
+// connection is made until the first command.
+func newClient(rawURL string) (*client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid Redis URL: want redis://[[user]:password@]host[:port][/db]")
+	}
+	c := &client{
+		addr:    u.Host,
+		tls:     u.Scheme == "rediss",
+		timeout: defaultTimeout,
+		idle:    make(chan *conn, maxIdleConns),
+	}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil || c.db < 0 {
+			return nil, fmt.Errorf("invalid Redis database %q", db)
+		}
+	}
+	return c, nil
+}
+
+// Close closes the idle connections
+func (c *client) Close() error {
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// Ping checks that the server answers, e.g. for dependency checks. The
+// command is bounded by the client's timeout; ctx only stops it starting.
+func (c *client) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := c.do("PING")
+	return err
+}
+
+// do runs a command on an idle connection, or a new one. Connections that
+// fail are closed rather than reused.
+func (c *client) do(args ...string) (interface{}, error) {
+	var cn *conn
+	select {
+	case cn = <-c.idle:
+	default:
+		var err error
+		if cn, err = c.dial(); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := cn.do(c.timeout, args...)
+	var replyErr Error
+	if err != nil && !errors.Is(err, errNil) && !errors.As(err, &replyErr) {
+		cn.Close()
+		return nil, err
+	}
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+	return reply, err
+}
+
+// dial opens a connection, authenticated and on the configured database
+func (c *client) dial() (*conn, error) {
+	dialer := &net.Dialer{Timeout: c.timeout}
+	var nc net.Conn
+	var err error
+	if c.tls {
+		host, _, _ := net.SplitHostPort(c.addr)
+		nc, err = tls.DialWithDialer(dialer, "tcp", c.addr, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+	} else {
+		nc, err = dialer.Dial("tcp", c.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	cn := &conn{Conn: nc, rd: bufio.NewReader(nc)}
+	var setup [][]string
+	switch {
+	case c.username != "":
+		setup = append(setup, []string{"AUTH", c.username, c.password})
+	case c.password != "":
+		setup = append(setup, []string{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	for _, args := range setup {
+		if _, err := cn.do(c.timeout, args...); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// do sends a command and reads its reply
+func (cn *conn) do(timeout time.Duration, args ...string) (interface{}, error) {
+	if err := cn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := cn.Write(encodeCommand(args)); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	return readReply(cn.rd)
+}
+
+// encodeCommand encodes a command as an array of bulk strings
+func encodeCommand(args []string) []byte {
+	var b []byte
+	b = append(b, '*')
+	b = strconv.AppendInt(b, int64(len(args)), 10)
+	b = append(b, '\r', '\n')
+	for _, arg := range args {
+		b = append(b, '$')
+		b = strconv.AppendInt(b, int64(len(arg)), 10)
+		b = append(b, '\r', '\n')
+		b = append(b, arg...)
+		b = append(b, '\r', '\n')
+	}
+	return b
+}
+
+// readReply reads a reply: a string for simple and bulk strings, an int64
+// for integers and a []interface{} for arrays. Error replies are returned
+// as Error and nil replies as errNil.
+func readReply(rd *bufio.Reader) (interface{}, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid integer reply %q", line)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 {
+			return nil, fmt.Errorf("redis: invalid bulk reply %q", line)
+		}
+		if n == -1 {
+			return nil, errNil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 {
+			return nil, fmt.Errorf("redis: invalid array reply %q", line)
+		}
+		if n == -1 {
+			return nil, errNil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			item, err := readReply(rd)
+			if err != nil && !errors.Is(err, errNil) {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply %q", line)
+}
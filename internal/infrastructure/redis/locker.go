@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// leaseKeyPrefix prefixes the keys of leases, e.g. "lease:digests"
+const leaseKeyPrefix = "lease:"
+
+// acquireScript sets the lease key to the owner unless another owner holds
+// it, and extends it when the owner does. Running it as a script keeps the
+// check and the write atomic.
+const acquireScript = `if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then return 1 end
+if redis.call("GET", KEYS[1]) == ARGV[1] then redis.call("PEXPIRE", KEYS[1], ARGV[2]) return 1 end
+return 0`
+
+// releaseScript deletes the lease key only while the owner holds it
+const releaseScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end
+return 0`
+
+// Locker implements Locker with a Redis server shared by all nodes. Each
+// lease is a key holding its owner, which expires with the lease.
+type Locker struct {
+	*client
+	owner string
+}
+
+// NewLocker creates a locker for the server at rawURL, taking leases as
+// owner, which must be unique to the instance. No connection is made until
+// the first command.
+func NewLocker(rawURL, owner string) (*Locker, error) {
+	c, err := newClient(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Locker{client: c, owner: owner}, nil
+}
+
+// Acquire takes the lease on name for ttl, or extends it
+func (l *Locker) Acquire(name string, ttl time.Duration) (bool, error) {
+	reply, err := l.do("EVAL", acquireScript, "1", leaseKeyPrefix+name, l.owner, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	held, ok := reply.(int64)
+	if !ok {
+		return false, fmt.Errorf("redis: unexpected EVAL reply %v", reply)
+	}
+	return held == 1, nil
+}
+
+// Release gives up the lease on name if this owner holds it
+func (l *Locker) Release(name string) error {
+	_, err := l.do("EVAL", releaseScript, "1", leaseKeyPrefix+name, l.owner)
+	return err
+}
@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocker(t *testing.T) {
+	server := newFakeServer(t, "")
+	a, err := NewLocker("redis://"+server.ln.Addr().String(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, _ := NewLocker("redis://"+server.ln.Addr().String(), "b")
+	defer b.Close()
+
+	acquire := func(l *Locker, want bool) {
+		t.Helper()
+		if held, err := l.Acquire("digests", 3*time.Minute); err != nil || held != want {
+			t.Fatalf("%s.Acquire() = %v, %v; want %v", l.owner, held, err, want)
+		}
+	}
+	acquire(a, true)
+	acquire(b, false)
+	acquire(a, true)
+
+	// Releasing someone else's lease does nothing; releasing one's own frees it
+	if err := b.Release("digests"); err != nil {
+		t.Fatal(err)
+	}
+	acquire(b, false)
+	if err := a.Release("digests"); err != nil {
+		t.Fatal(err)
+	}
+	acquire(b, true)
+
+	if got := server.Commands()[0]; !strings.HasSuffix(got, " 1 lease:digests a 180000") {
+		t.Errorf("first command = %q, want the lease key, owner and ttl in milliseconds", got)
+	}
+}
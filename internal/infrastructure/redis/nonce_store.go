@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// nonceKeyPrefix prefixes the keys of used request signature nonces
+const nonceKeyPrefix = "nonce:"
+
+// NonceStore implements signature.NonceStore with a Redis server shared by
+// all nodes, so a signed request is accepted by one node only
+type NonceStore struct {
+	*client
+}
+
+// NewNonceStore creates a nonce store for the server at rawURL. No
+// connection is made until the first command.
+func NewNonceStore(rawURL string) (*NonceStore, error) {
+	c, err := newClient(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &NonceStore{client: c}, nil
+}
+
+// Use records nonce for ttl. SET NX makes the check and the write atomic.
+func (s *NonceStore) Use(nonce string, ttl time.Duration) (bool, error) {
+	_, err := s.do("SET", nonceKeyPrefix+nonce, "1", "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if errors.Is(err, errNil) {
+		return false, nil
+	}
+	return err == nil, err
+}
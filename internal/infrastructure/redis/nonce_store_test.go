@@ -0,0 +1,31 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNonceStore(t *testing.T) {
+	server := newFakeServer(t, "")
+	store, err := NewNonceStore("redis://" + server.ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if fresh, err := store.Use("mobile\nn1", 10*time.Minute); err != nil || !fresh {
+		t.Fatalf("Use() = %v, %v; want fresh", fresh, err)
+	}
+	if fresh, err := store.Use("mobile\nn1", 10*time.Minute); err != nil || fresh {
+		t.Errorf("Use() again = %v, %v; want already used", fresh, err)
+	}
+	if got := server.Commands()[0]; got != "SET nonce:mobile\nn1 1 NX PX 600000" {
+		t.Errorf("command = %q", got)
+	}
+
+	server.ln.Close()
+	store, _ = NewNonceStore("redis://" + server.ln.Addr().String())
+	if _, err := store.Use("mobile\nn2", time.Minute); err == nil {
+		t.Error("Use() without a server succeeded")
+	}
+}
@@ -0,0 +1,212 @@
+// Package shadow mirrors the calls to a repository onto a new backend that
+// is not trusted yet, compares the results and logs the differences, so a
+// migration, e.g. from SQLite to Postgres, is checked against real traffic
+// before the new backend is switched over to.
+package shadow
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// UserRepository serves users from a primary repository and mirrors every
+// call to a shadow one, after the primary call and in the same order. The
+// primary's results are returned; the shadow's are only compared, and its
+// failures and differences logged without values, as those hold personal
+// data. Mirrored calls add the shadow's latency to each call.
+type UserRepository struct {
+	primary repository.UserRepository
+	shadow  repository.UserRepository
+	logf    func(format string, args ...interface{})
+
+	mu sync.Mutex
+	// ids maps the IDs of users created while shadowing to the shadow's,
+	// when the shadow assigned another one. Users copied over before
+	// shadowing must keep their IDs.
+	ids map[int]int
+}
+
+// NewUserRepository serves users from primary, mirroring calls to shadow
+func NewUserRepository(primary, shadow repository.UserRepository) *UserRepository {
+	return &UserRepository{
+		primary: primary,
+		shadow:  shadow,
+		logf:    log.Printf,
+		ids:     make(map[int]int),
+	}
+}
+
+// Create saves a new user in both repositories
+func (r *UserRepository) Create(user *entity.User) (*entity.User, error) {
+	mirrored := *user
+	created, err := r.primary.Create(user)
+	shadowCreated, shadowErr := r.shadow.Create(&mirrored)
+	if r.compareErrors("Create", err, shadowErr) && err == nil {
+		if shadowCreated.ID != created.ID {
+			r.mu.Lock()
+			r.ids[created.ID] = shadowCreated.ID
+			r.mu.Unlock()
+		}
+		r.compareUsers(fmt.Sprintf("Create(%d)", created.ID), created, shadowCreated)
+	}
+	return created, err
+}
+
+// GetByEmail retrieves a user by email from the primary
+func (r *UserRepository) GetByEmail(email string) (*entity.User, error) {
+	user, err := r.primary.GetByEmail(email)
+	shadowUser, shadowErr := r.shadow.GetByEmail(email)
+	if r.compareErrors("GetByEmail", err, shadowErr) && err == nil {
+		r.compareUsers(fmt.Sprintf("GetByEmail(user %d)", user.ID), user, shadowUser)
+	}
+	return user, err
+}
+
+// ExistsByEmail reports whether the primary has a user with the email
+func (r *UserRepository) ExistsByEmail(email string) (bool, error) {
+	exists, err := r.primary.ExistsByEmail(email)
+	shadowExists, shadowErr := r.shadow.ExistsByEmail(email)
+	if r.compareErrors("ExistsByEmail", err, shadowErr) && err == nil && exists != shadowExists {
+		r.logf("Shadow users: ExistsByEmail = %t, shadow %t", exists, shadowExists)
+	}
+	return exists, err
+}
+
+// GetByID retrieves a user by ID from the primary
+func (r *UserRepository) GetByID(id int) (*entity.User, error) {
+	user, err := r.primary.GetByID(id)
+	shadowUser, shadowErr := r.shadow.GetByID(r.shadowID(id))
+	name := fmt.Sprintf("GetByID(%d)", id)
+	if r.compareErrors(name, err, shadowErr) && err == nil {
+		r.compareUsers(name, user, shadowUser)
+	}
+	return user, err
+}
+
+// Update updates user information in both repositories
+func (r *UserRepository) Update(user *entity.User) error {
+	mirrored := *user
+	mirrored.ID = r.shadowID(user.ID)
+	err := r.primary.Update(user)
+	r.compareErrors(fmt.Sprintf("Update(%d)", user.ID), err, r.shadow.Update(&mirrored))
+	return err
+}
+
+// UpdatePassword replaces the password hash in both repositories
+func (r *UserRepository) UpdatePassword(id int, hash string) error {
+	err := r.primary.UpdatePassword(id, hash)
+	r.compareErrors(fmt.Sprintf("UpdatePassword(%d)", id), err, r.shadow.UpdatePassword(r.shadowID(id), hash))
+	return err
+}
+
+// UpdateFields updates the given fields in both repositories
+func (r *UserRepository) UpdateFields(id int, fields map[string]interface{}) error {
+	err := r.primary.UpdateFields(id, fields)
+	r.compareErrors(fmt.Sprintf("UpdateFields(%d)", id), err, r.shadow.UpdateFields(r.shadowID(id), fields))
+	return err
+}
+
+// Delete removes a user from both repositories
+func (r *UserRepository) Delete(id int) error {
+	err := r.primary.Delete(id)
+	r.compareErrors(fmt.Sprintf("Delete(%d)", id), err, r.shadow.Delete(r.shadowID(id)))
+	return err
+}
+
+// List returns the primary's users matching the filter within the page
+func (r *UserRepository) List(filter repository.UserFilter, page repository.Page) ([]*entity.User, error) {
+	users, err := r.primary.List(filter, page)
+	shadowUsers, shadowErr := r.shadow.List(filter, page)
+	if !r.compareErrors("List", err, shadowErr) || err != nil {
+		return users, err
+	}
+	if len(users) != len(shadowUsers) {
+		r.logf("Shadow users: List returned %d users, shadow %d", len(users), len(shadowUsers))
+		return users, err
+	}
+	for i, user := range users {
+		if id := r.shadowID(user.ID); shadowUsers[i].ID != id {
+			r.logf("Shadow users: List returned user %d at %d, shadow user %d", user.ID, i, shadowUsers[i].ID)
+			continue
+		}
+		r.compareUsers(fmt.Sprintf("List(user %d)", user.ID), user, shadowUsers[i])
+	}
+	return users, err
+}
+
+// Count returns the number of the primary's users matching the filter
+func (r *UserRepository) Count(filter repository.UserFilter) (int, error) {
+	count, err := r.primary.Count(filter)
+	shadowCount, shadowErr := r.shadow.Count(filter)
+	if r.compareErrors("Count", err, shadowErr) && err == nil && count != shadowCount {
+		r.logf("Shadow users: Count = %d, shadow %d", count, shadowCount)
+	}
+	return count, err
+}
+
+// shadowID returns the shadow's ID of a user
+func (r *UserRepository) shadowID(id int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if shadowID, ok := r.ids[id]; ok {
+		return shadowID
+	}
+	return id
+}
+
+// compareErrors logs when the shadow failed differently from the primary,
+// and reports whether both failed alike
+func (r *UserRepository) compareErrors(call string, err, shadowErr error) bool {
+	switch {
+	case err == nil && shadowErr == nil:
+		return true
+	case err == nil:
+		r.logf("Shadow users: %s failed in the shadow only: %v", call, shadowErr)
+	case shadowErr == nil:
+		r.logf("Shadow users: %s succeeded in the shadow only, primary failed: %v", call, err)
+	case !errors.Is(shadowErr, err) && shadowErr.Error() != err.Error():
+		r.logf("Shadow users: %s failed with %v, shadow with %v", call, err, shadowErr)
+	default:
+		return true
+	}
+	return false
+}
+
+// compareUsers logs the fields a shadow user differs in. IDs are compared
+// through the ID map and the timestamps, which each backend sets from its
+// own clock, are not compared.
+func (r *UserRepository) compareUsers(call string, user, shadowUser *entity.User) {
+	var fields []string
+	for _, field := range []struct {
+		name string
+		same bool
+	}{
+		{"id", r.shadowID(user.ID) == shadowUser.ID},
+		{repository.FieldEmail, user.Email == shadowUser.Email},
+		{"password", user.Password == shadowUser.Password},
+		{repository.FieldFullName, user.FullName == shadowUser.FullName},
+		{repository.FieldPhoneNumber, user.PhoneNumber == shadowUser.PhoneNumber},
+		{repository.FieldBirthday, user.Birthday == shadowUser.Birthday},
+		{repository.FieldAvatar, user.Avatar == shadowUser.Avatar},
+		{repository.FieldRole, user.Role == shadowUser.Role},
+		{repository.FieldStatus, user.Status == shadowUser.Status},
+		{repository.FieldPlan, user.Plan == shadowUser.Plan},
+		{repository.FieldTimezone, user.Timezone == shadowUser.Timezone},
+		{repository.FieldNationalID, user.NationalID == shadowUser.NationalID},
+		{repository.FieldKYCStatus, user.KYCStatus == shadowUser.KYCStatus},
+		{"version", user.Version == shadowUser.Version},
+	} {
+		if !field.same {
+			fields = append(fields, field.name)
+		}
+	}
+	if len(fields) > 0 {
+		r.logf("Shadow users: %s differs in %s", call, strings.Join(fields, ", "))
+	}
+}
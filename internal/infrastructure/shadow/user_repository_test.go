@@ -0,0 +1,110 @@
+package shadow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/memory"
+)
+
+func newTestRepository(t *testing.T) (*UserRepository, repository.UserRepository, *[]string) {
+	t.Helper()
+	shadowUsers := memory.NewUserRepository()
+	repo := NewUserRepository(memory.NewUserRepository(), shadowUsers)
+	var logs []string
+	repo.logf = func(format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	}
+	return repo, shadowUsers, &logs
+}
+
+func TestUserRepository_Mirrors(t *testing.T) {
+	repo, shadowUsers, logs := newTestRepository(t)
+
+	user, err := repo.Create(entity.NewUser("lee@example.com", "hash", "Lee Park", "0812345678", "1990-01-15"))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := repo.Create(entity.NewUser("lee@example.com", "hash", "Lee Again", "", "")); !errors.Is(err, repository.ErrEmailTaken) {
+		t.Errorf("Create() of a taken email error = %v, want ErrEmailTaken", err)
+	}
+	if err := repo.UpdateFields(user.ID, map[string]interface{}{repository.FieldFullName: "Lee Kim"}); err != nil {
+		t.Fatalf("UpdateFields() error = %v", err)
+	}
+	repo.GetByID(user.ID)
+	repo.GetByEmail("lee@example.com")
+	repo.List(repository.UserFilter{}, repository.Page{Limit: 10})
+	repo.Count(repository.UserFilter{})
+	if len(*logs) != 0 {
+		t.Fatalf("logs = %q, want none while the backends agree", *logs)
+	}
+
+	if mirrored, err := shadowUsers.GetByID(user.ID); err != nil || mirrored.FullName != "Lee Kim" {
+		t.Errorf("shadow user = %+v, %v; want the writes mirrored", mirrored, err)
+	}
+	if err := repo.Delete(user.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if exists, _ := shadowUsers.ExistsByEmail("lee@example.com"); exists {
+		t.Error("Delete() should be mirrored")
+	}
+}
+
+func TestUserRepository_LogsDifferences(t *testing.T) {
+	repo, shadowUsers, logs := newTestRepository(t)
+	user, err := repo.Create(entity.NewUser("lee@example.com", "hash", "Lee Park", "0812345678", "1990-01-15"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The shadow drifts, e.g. a column that is not migrated
+	shadowUsers.UpdateFields(user.ID, map[string]interface{}{repository.FieldPlan: "pro", repository.FieldPhoneNumber: ""})
+	got, err := repo.GetByID(user.ID)
+	if err != nil || got.Plan != entity.PlanFree {
+		t.Fatalf("GetByID() = %+v, %v; want the primary's user", got, err)
+	}
+	if len(*logs) != 1 || !strings.Contains((*logs)[0], "GetByID(1) differs in phoneNumber, plan, version") {
+		t.Fatalf("logs = %q, want the fields that differ", *logs)
+	}
+	if strings.Contains(strings.Join(*logs, "\n"), "0812345678") {
+		t.Errorf("logs = %q, want no values", *logs)
+	}
+
+	// Failures of the shadow are logged and never returned
+	*logs = nil
+	shadowUsers.Delete(user.ID)
+	if _, err := repo.GetByID(user.ID); err != nil {
+		t.Fatalf("GetByID() error = %v, want the primary's result", err)
+	}
+	if count, _ := repo.Count(repository.UserFilter{}); count != 1 {
+		t.Errorf("Count() = %d, want the primary's 1", count)
+	}
+	if len(*logs) != 2 || !strings.Contains((*logs)[0], "GetByID(1) failed in the shadow only: user not found") || (*logs)[1] != "Shadow users: Count = 1, shadow 0" {
+		t.Errorf("logs = %q", *logs)
+	}
+}
+
+func TestUserRepository_MapsShadowIDs(t *testing.T) {
+	repo, shadowUsers, logs := newTestRepository(t)
+	// The shadow assigns its own IDs, one apart from the primary's
+	shadowUsers.Create(entity.NewUser("other@example.com", "hash", "Other", "", ""))
+	shadowUsers.Delete(1)
+
+	user, err := repo.Create(entity.NewUser("lee@example.com", "hash", "Lee Park", "", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.UpdateFields(user.ID, map[string]interface{}{repository.FieldTimezone: "Asia/Bangkok"})
+	repo.GetByID(user.ID)
+	repo.List(repository.UserFilter{}, repository.Page{Limit: 10})
+	if len(*logs) != 0 {
+		t.Errorf("logs = %q, want the shadow's IDs mapped", *logs)
+	}
+	if mirrored, _ := shadowUsers.GetByID(2); mirrored.Timezone != "Asia/Bangkok" {
+		t.Errorf("shadow user 2 = %+v, want the update mirrored to it", mirrored)
+	}
+}
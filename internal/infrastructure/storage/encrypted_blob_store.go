@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// EncryptedBlobStore encrypts data with AES-256-GCM before passing it to
+// another BlobStore. Each object is the random nonce followed by the
+// ciphertext; the key is authenticated too, so objects cannot be swapped.
+type EncryptedBlobStore struct {
+	store repository.BlobStore
+	aead  cipher.AEAD
+}
+
+// NewEncryptedBlobStore wraps store, encrypting with a 32 byte key
+func NewEncryptedBlobStore(store repository.BlobStore, key []byte) (*EncryptedBlobStore, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedBlobStore{store: store, aead: aead}, nil
+}
+
+// Put encrypts data and stores it under key
+func (s *EncryptedBlobStore) Put(key string, data []byte, contentType string) error {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := s.aead.Seal(nonce, nonce, data, []byte(key))
+	return s.store.Put(key, sealed, "application/octet-stream")
+}
+
+// Get reads the object stored under key and decrypts it
+func (s *EncryptedBlobStore) Get(key string) ([]byte, error) {
+	sealed, err := s.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.Decrypt(key, sealed)
+}
+
+// Delete removes the object stored under key
+func (s *EncryptedBlobStore) Delete(key string) error {
+	return s.store.Delete(key)
+}
+
+// Decrypt reverses Put for an object read back from the underlying store
+func (s *EncryptedBlobStore) Decrypt(key string, sealed []byte) ([]byte, error) {
+	if len(sealed) < s.aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted object %s is too short", key)
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	return s.aead.Open(nil, nonce, ciphertext, []byte(key))
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// FileKeyStore implements KeyStore with one file per user in a directory,
+// which must not be inside the database's backups
+type FileKeyStore struct {
+	dir string
+}
+
+// NewFileKeyStore creates a new key store keeping keys in dir
+func NewFileKeyStore(dir string) *FileKeyStore {
+	return &FileKeyStore{dir: dir}
+}
+
+// path returns the file holding the user's key
+func (s *FileKeyStore) path(userID int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("user-%d.key", userID))
+}
+
+// Get returns the user's key
+func (s *FileKeyStore) Get(userID int) ([]byte, error) {
+	key, err := os.ReadFile(s.path(userID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, repository.ErrKeyNotFound
+	}
+	return key, err
+}
+
+// Put stores a key for the user unless one exists. The key is written to a
+// temporary file first and linked into place, so readers never see a
+// partial key and concurrent writers cannot replace each other's.
+func (s *FileKeyStore) Put(userID int, key []byte) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "key-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(key); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	err = os.Link(tmp.Name(), s.path(userID))
+	if errors.Is(err, os.ErrExist) {
+		return repository.ErrKeyExists
+	}
+	return err
+}
+
+// Delete removes the user's key file
+func (s *FileKeyStore) Delete(userID int) error {
+	err := os.Remove(s.path(userID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
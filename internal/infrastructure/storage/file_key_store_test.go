@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestFileKeyStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+	store := NewFileKeyStore(dir)
+
+	if _, err := store.Get(1); !errors.Is(err, repository.ErrKeyNotFound) {
+		t.Fatalf("Get() error = %v, want ErrKeyNotFound", err)
+	}
+
+	key := bytes.Repeat([]byte{1}, 32)
+	if err := store.Put(1, key); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got, err := store.Get(1); err != nil || !bytes.Equal(got, key) {
+		t.Errorf("Get() = %x, %v; want the stored key", got, err)
+	}
+	if err := store.Put(1, bytes.Repeat([]byte{2}, 32)); !errors.Is(err, repository.ErrKeyExists) {
+		t.Errorf("Put() over an existing key error = %v, want ErrKeyExists", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "user-1.key"))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("key file = %v, %v; want mode 0600", info, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Put() left %d files behind, want only the key", len(entries))
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := store.Delete(1); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+	}
+	if _, err := store.Get(1); !errors.Is(err, repository.ErrKeyNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrKeyNotFound", err)
+	}
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// avatarExtensions maps supported image content types to file extensions
+var avatarExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// LocalAvatarStorage implements AvatarStorage on the local filesystem
+type LocalAvatarStorage struct {
+	dir       string
+	urlPrefix string
+}
+
+// NewLocalAvatarStorage creates a new local avatar storage writing under dir/avatars
+// and serving files from urlPrefix/avatars
+func NewLocalAvatarStorage(dir, urlPrefix string) *LocalAvatarStorage {
+	return &LocalAvatarStorage{
+		dir:       dir,
+		urlPrefix: urlPrefix,
+	}
+}
+
+// Save stores the avatar image for a user and returns its public URL path
+func (s *LocalAvatarStorage) Save(userID int, contentType string, data []byte) (string, error) {
+	ext, ok := avatarExtensions[contentType]
+	if !ok {
+		return "", fmt.Errorf("unsupported avatar content type %s", contentType)
+	}
+
+	avatarDir := filepath.Join(s.dir, "avatars")
+	if err := os.MkdirAll(avatarDir, 0o755); err != nil {
+		return "", err
+	}
+
+	// Random suffix keeps URLs unguessable and busts caches on change
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%d-%s%s", userID, hex.EncodeToString(suffix), ext)
+
+	if err := os.WriteFile(filepath.Join(avatarDir, name), data, 0o644); err != nil {
+		return "", err
+	}
+
+	return s.urlPrefix + "/avatars/" + name, nil
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalAvatarStorage_Save(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewLocalAvatarStorage(dir, "/uploads")
+
+	url, err := storage.Save(42, "image/png", []byte("png-data"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if !strings.HasPrefix(url, "/uploads/avatars/42-") || !strings.HasSuffix(url, ".png") {
+		t.Errorf("Save() url = %v, want /uploads/avatars/42-*.png", url)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "avatars", filepath.Base(url)))
+	if err != nil {
+		t.Fatalf("Failed to read saved avatar: %v", err)
+	}
+	if string(data) != "png-data" {
+		t.Errorf("Saved data = %v, want png-data", string(data))
+	}
+
+	// Each save gets a distinct name
+	second, err := storage.Save(42, "image/png", []byte("png-data"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if second == url {
+		t.Error("Save() should generate a unique file name per call")
+	}
+}
+
+func TestLocalAvatarStorage_Save_UnsupportedType(t *testing.T) {
+	storage := NewLocalAvatarStorage(t.TempDir(), "/uploads")
+
+	if _, err := storage.Save(1, "text/html", []byte("<html>")); err == nil {
+		t.Error("Save() should reject unsupported content types")
+	}
+}
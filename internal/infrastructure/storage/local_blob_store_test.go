@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fiber-hello-world/internal/domain/repository"
+)
+
+func TestLocalBlobStore_Put(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalBlobStore(dir)
+
+	if err := store.Put("exports/2024-06-01/users.ndjson.gz", []byte("v1"), "application/gzip"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Put("exports/2024-06-01/users.ndjson.gz", []byte("v2"), "application/gzip"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "exports", "2024-06-01", "users.ndjson.gz"))
+	if err != nil || string(data) != "v2" {
+		t.Errorf("stored = %q, %v; want the latest version", data, err)
+	}
+
+	for _, key := range []string{"", "../escape", "/etc/passwd"} {
+		if err := store.Put(key, []byte("x"), "text/plain"); err == nil {
+			t.Errorf("Put(%q) should be rejected", key)
+		}
+	}
+}
+
+func TestLocalBlobStore_Get(t *testing.T) {
+	store := NewLocalBlobStore(t.TempDir())
+	if err := store.Put("kyc/1/document.png", []byte("image"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := store.Get("kyc/1/document.png")
+	if err != nil || string(data) != "image" {
+		t.Errorf("Get() = %q, %v", data, err)
+	}
+	if _, err := store.Get("kyc/1/missing.png"); !errors.Is(err, repository.ErrBlobNotFound) {
+		t.Errorf("Get() for a missing key error = %v, want ErrBlobNotFound", err)
+	}
+	if _, err := store.Get("../escape"); err == nil {
+		t.Error("Get() outside the directory should be rejected")
+	}
+}
+
+func TestLocalBlobStore_Delete(t *testing.T) {
+	store := NewLocalBlobStore(t.TempDir())
+	if err := store.Put("kyc/1/document.png", []byte("image"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Delete("kyc/1/document.png"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get("kyc/1/document.png"); !errors.Is(err, repository.ErrBlobNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrBlobNotFound", err)
+	}
+	if err := store.Delete("kyc/1/document.png"); err != nil {
+		t.Errorf("Delete() of a missing key error = %v", err)
+	}
+	if err := store.Delete("../escape"); err == nil {
+		t.Error("Delete() outside the directory should be rejected")
+	}
+}
+
+func TestEncryptedBlobStore(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{7}, 32)
+	store, err := NewEncryptedBlobStore(NewLocalBlobStore(dir), key)
+	if err != nil {
+		t.Fatalf("NewEncryptedBlobStore() error = %v", err)
+	}
+
+	if err := store.Put("a/manifest.json", []byte(`{"files":[]}`), "application/json"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	sealed, err := os.ReadFile(filepath.Join(dir, "a", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("files")) {
+		t.Error("stored object should be encrypted")
+	}
+
+	plain, err := store.Decrypt("a/manifest.json", sealed)
+	if err != nil || string(plain) != `{"files":[]}` {
+		t.Errorf("Decrypt() = %q, %v", plain, err)
+	}
+	if plain, err := store.Get("a/manifest.json"); err != nil || string(plain) != `{"files":[]}` {
+		t.Errorf("Get() = %q, %v", plain, err)
+	}
+	// The key is authenticated, so a moved object does not decrypt
+	if _, err := store.Decrypt("b/manifest.json", sealed); err == nil {
+		t.Error("Decrypt() under another key should fail")
+	}
+	if err := store.Delete("a/manifest.json"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a", "manifest.json")); !os.IsNotExist(err) {
+		t.Errorf("object after Delete() = %v, want removed", err)
+	}
+
+	if _, err := NewEncryptedBlobStore(NewLocalBlobStore(dir), []byte("short")); err == nil {
+		t.Error("NewEncryptedBlobStore() should reject keys that are not 32 bytes")
+	}
+}
@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// S3Options configures an S3BlobStore. Any S3-compatible service works,
+// including Google Cloud Storage through its XML API with HMAC keys
+// (endpoint https://storage.googleapis.com, region "auto").
+type S3Options struct {
+	// Endpoint is the service URL, e.g. https://s3.eu-west-1.amazonaws.com
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// ServerSideEncryption is sent as x-amz-server-side-encryption when set,
+	// e.g. "AES256" or "aws:kms"
+	ServerSideEncryption string
+	// Client defaults to an http.Client with a 30 second timeout
+	Client *http.Client
+}
+
+// S3BlobStore implements BlobStore with the S3 API, using path-style URLs
+// and AWS Signature Version 4
+type S3BlobStore struct {
+	opts S3Options
+	now  func() time.Time
+}
+
+// NewS3BlobStore creates a new S3 blob store
+func NewS3BlobStore(opts S3Options) (*S3BlobStore, error) {
+	if opts.Endpoint == "" || opts.Region == "" || opts.Bucket == "" {
+		return nil, fmt.Errorf("S3 endpoint, region and bucket are required")
+	}
+	if opts.AccessKey == "" || opts.SecretKey == "" {
+		return nil, fmt.Errorf("S3 access key and secret key are required")
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	opts.Endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+
+	return &S3BlobStore{opts: opts, now: time.Now}, nil
+}
+
+// Put uploads data to the bucket under key
+func (s *S3BlobStore) Put(key string, data []byte, contentType string) error {
+	objectURL := s.opts.Endpoint + "/" + escapePath(s.opts.Bucket+"/"+key)
+	req, err := http.NewRequest(http.MethodPut, objectURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.opts.ServerSideEncryption != "" {
+		req.Header.Set("X-Amz-Server-Side-Encryption", s.opts.ServerSideEncryption)
+	}
+
+	sum := sha256.Sum256(data)
+	signV4(req, hex.EncodeToString(sum[:]), s.opts.AccessKey, s.opts.SecretKey, s.opts.Region, "s3", s.now())
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("S3 PUT %s: %s: %s", key, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// emptyPayloadHash is the hex SHA-256 of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Get downloads the object stored under key
+func (s *S3BlobStore) Get(key string) ([]byte, error) {
+	objectURL := s.opts.Endpoint + "/" + escapePath(s.opts.Bucket+"/"+key)
+	req, err := http.NewRequest(http.MethodGet, objectURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	signV4(req, emptyPayloadHash, s.opts.AccessKey, s.opts.SecretKey, s.opts.Region, "s3", s.now())
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, repository.ErrBlobNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("S3 GET %s: %s: %s", key, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Delete removes the object stored under key. S3 answers a missing object
+// like a deleted one.
+func (s *S3BlobStore) Delete(key string) error {
+	objectURL := s.opts.Endpoint + "/" + escapePath(s.opts.Bucket+"/"+key)
+	req, err := http.NewRequest(http.MethodDelete, objectURL, nil)
+	if err != nil {
+		return err
+	}
+	signV4(req, emptyPayloadHash, s.opts.AccessKey, s.opts.SecretKey, s.opts.Region, "s3", s.now())
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("S3 DELETE %s: %s: %s", key, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// escapePath percent-encodes each segment of an object path as SigV4 expects
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+	return strings.Join(segments, "/")
+}
+
+// signV4 signs req with AWS Signature Version 4, covering the host and
+// every header already set on req. payloadHash is the hex SHA-256 of the body.
+func signV4(req *http.Request, payloadHash, accessKey, secretKey, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery sorts and encodes query parameters as SigV4 expects
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, awsEscape(key)+"="+awsEscape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes everything but unreserved characters
+func awsEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
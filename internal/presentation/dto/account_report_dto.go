@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// AccountReportLinkResponse represents a single-use link downloading an
+// account report
+type AccountReportLinkResponse struct {
+	// URL downloads the report once without signing in; add ?format=pdf
+	// for a PDF instead of HTML
+	URL       string    `json:"url" example:"https://api.example.com/account-reports/3q2-7w"`
+	Formats   []string  `json:"formats" example:"html,pdf"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
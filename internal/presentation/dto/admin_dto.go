@@ -0,0 +1,168 @@
+package dto
+
+import "time"
+
+// FunnelDayResponse represents stage counts for a single day
+type FunnelDayResponse struct {
+	Date   string         `json:"date"`
+	Counts map[string]int `json:"counts"`
+}
+
+// FunnelReportResponse represents the response payload for the registration funnel report
+type FunnelReportResponse struct {
+	From       string              `json:"from"`
+	To         string              `json:"to"`
+	Stages     []string            `json:"stages"`
+	Totals     map[string]int      `json:"totals"`
+	Conversion map[string]float64  `json:"conversion"`
+	Days       []FunnelDayResponse `json:"days"`
+}
+
+// FieldChangeResponse represents a single changed field in a user revision or audit entry
+type FieldChangeResponse struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// UserRevisionResponse represents a recorded change to a user.
+// Snapshot holds the user as it was before the change.
+type UserRevisionResponse struct {
+	ID        int                   `json:"id"`
+	ActorID   int                   `json:"actorId"`
+	Action    string                `json:"action"`
+	Changes   []FieldChangeResponse `json:"changes"`
+	Snapshot  UserResponse          `json:"snapshot"`
+	CreatedAt time.Time             `json:"createdAt"`
+}
+
+// UserHistoryResponse represents the response payload for a user's revision history
+type UserHistoryResponse struct {
+	UserID    int                    `json:"userId"`
+	Revisions []UserRevisionResponse `json:"revisions"`
+}
+
+// UserStatusRequest represents the request payload for moving a user's
+// account to another status
+type UserStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active suspended deactivated" example:"deactivated"`
+}
+
+// UserPlanRequest represents the request payload for moving a user to
+// another plan
+type UserPlanRequest struct {
+	Plan string `json:"plan" validate:"required,max=50" example:"pro"`
+}
+
+// BulkUserActionRequest represents the request payload for an admin action on several users
+type BulkUserActionRequest struct {
+	UserIDs []int `json:"userIds" validate:"required,min=1,max=500,dive,gt=0"`
+}
+
+// BulkRoleRequest represents the request payload for changing several users' role
+type BulkRoleRequest struct {
+	UserIDs []int  `json:"userIds" validate:"required,min=1,max=500,dive,gt=0"`
+	Role    string `json:"role" validate:"required,oneof=user admin"`
+}
+
+// IncidentResetRequest represents the request payload for forcing a password
+// reset on the users who signed in during a window, optionally from an IP range
+type IncidentResetRequest struct {
+	LoggedInSince time.Time `json:"loggedInSince" validate:"required"`
+	LoggedInUntil time.Time `json:"loggedInUntil" validate:"required"`
+	IPRange       string    `json:"ipRange,omitempty" validate:"omitempty,max=64"`
+	Subject       string    `json:"subject" validate:"required,max=200"`
+	Message       string    `json:"message" validate:"required,max=10000"`
+}
+
+// AdminActionResponse represents a queued destructive admin action.
+// The action can be undone with its token until executeAt. While it runs,
+// processed counts the users handled so far; once it failed, failedUserIds
+// lists the users a retry runs for.
+type AdminActionResponse struct {
+	Token         string     `json:"token"`
+	Kind          string     `json:"kind"`
+	Status        string     `json:"status"`
+	ActorID       int        `json:"actorId"`
+	UserIDs       []int      `json:"userIds"`
+	Role          string     `json:"role,omitempty"`
+	Subject       string     `json:"subject,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	FailedUserIDs []int      `json:"failedUserIds,omitempty"`
+	Processed     int        `json:"processed"`
+	ExecuteAt     time.Time  `json:"executeAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+	ReviewedBy    int        `json:"reviewedBy,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewedAt,omitempty"`
+	UndoURL       string     `json:"undoUrl"`
+}
+
+// BackupResponse represents a stored database backup. SHA256 is only
+// returned for a backup that was just created.
+type BackupResponse struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// BackupListResponse represents the response payload for the stored backups
+type BackupListResponse struct {
+	Backups []BackupResponse `json:"backups"`
+}
+
+// ChaosRule represents a fault injected into a share of matching requests.
+// Latency is added first; the request then fails with status, is dropped,
+// or proceeds when neither is set.
+type ChaosRule struct {
+	Method    string  `json:"method,omitempty" example:"POST"`
+	Path      string  `json:"path" validate:"required" example:"/login"`
+	Percent   float64 `json:"percent" validate:"gt=0,lte=100" example:"25"`
+	LatencyMs int     `json:"latencyMs,omitempty" validate:"gte=0" example:"1500"`
+	Status    int     `json:"status,omitempty" example:"503"`
+	Drop      bool    `json:"drop,omitempty"`
+}
+
+// ChaosRulesRequest represents the request payload replacing the fault
+// injection rules; an empty list stops injecting faults
+type ChaosRulesRequest struct {
+	Rules []ChaosRule `json:"rules" validate:"max=50,dive"`
+}
+
+// ChaosRulesResponse represents the active fault injection rules
+type ChaosRulesResponse struct {
+	Rules []ChaosRule `json:"rules"`
+}
+
+// PayloadLogRequest represents the request payload for logging a route's
+// request and response bodies for a while
+type PayloadLogRequest struct {
+	Method  string `json:"method" validate:"required" example:"POST"`
+	Path    string `json:"path" validate:"required" example:"/login"`
+	Minutes int    `json:"minutes" validate:"required,min=1,max=1440" example:"30"`
+}
+
+// PayloadLogRoute represents a route whose payloads are logged until Until
+type PayloadLogRoute struct {
+	Method string    `json:"method" example:"POST"`
+	Path   string    `json:"path" example:"/login"`
+	Until  time.Time `json:"until"`
+}
+
+// PayloadLogRoutesResponse represents the routes whose payloads are logged
+type PayloadLogRoutesResponse struct {
+	Routes []PayloadLogRoute `json:"routes"`
+}
+
+// AuthorizationSyncResponse represents a full sync of users' roles to the
+// authorization service
+type AuthorizationSyncResponse struct {
+	Users int `json:"users" example:"1200"`
+}
+
+// DigestPreviewResponse represents an admin digest email as it would be sent
+type DigestPreviewResponse struct {
+	Subject string `json:"subject" example:"Daily admin digest: 12 signups, 40 failed logins"`
+	Body    string `json:"body"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// AudienceTokenRequest represents the request payload for a token restricted
+// to one downstream service
+type AudienceTokenRequest struct {
+	Audience string   `json:"audience" validate:"required" example:"orders"`
+	Scopes   []string `json:"scopes,omitempty" example:"orders:read"`
+}
+
+// AudienceTokenResponse represents an issued audience-restricted token
+type AudienceTokenResponse struct {
+	Token     string    `json:"token"`
+	Audience  string    `json:"audience" example:"orders"`
+	Scope     string    `json:"scope" example:"orders:read orders:write"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// JWKResponse represents a public key that verifies audience tokens
+type JWKResponse struct {
+	Kty string `json:"kty" example:"EC"`
+	Kid string `json:"kid"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"ES256"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty" example:"P-256"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSetResponse represents the key set of an audience
+type JWKSetResponse struct {
+	Keys []JWKResponse `json:"keys"`
+}
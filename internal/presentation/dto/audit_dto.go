@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// AuditEntryResponse represents an admin mutation in the audit trail
+type AuditEntryResponse struct {
+	ID          int                   `json:"id" example:"318"`
+	ActorID     int                   `json:"actorId" example:"1"`
+	Action      string                `json:"action" example:"user.updated"`
+	SubjectType string                `json:"subjectType" example:"user"`
+	SubjectID   string                `json:"subjectId" example:"7"`
+	Changes     []FieldChangeResponse `json:"changes"`
+	CreatedAt   time.Time             `json:"createdAt"`
+}
+
+// AuditResponse represents a page of the audit trail, newest first
+type AuditResponse struct {
+	Entries []AuditEntryResponse `json:"entries"`
+	// NextBefore is passed as before for the next page; it is omitted on the last page
+	NextBefore int `json:"nextBefore,omitempty" example:"318"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+// HashPoolResponse represents the load on the password hashing pool
+type HashPoolResponse struct {
+	Size       int     `json:"size" example:"4"`
+	Busy       int     `json:"busy" example:"2"`
+	Waiting    int     `json:"waiting" example:"0"`
+	Saturation float64 `json:"saturation" example:"0.5"`
+}
+
+// AutoscalingResponse represents the load signals for autoscalers
+type AutoscalingResponse struct {
+	InFlightRequests int64            `json:"inFlightRequests" example:"12"`
+	Queues           map[string]int   `json:"queues"`
+	HashPool         HashPoolResponse `json:"hashPool"`
+}
@@ -0,0 +1,66 @@
+package dto
+
+import "time"
+
+// BreakGlassCeremonyRequest represents the request payload for a key ceremony
+type BreakGlassCeremonyRequest struct {
+	// Shares is the number of custodians, at most 16
+	Shares int `json:"shares" validate:"required,min=2,max=16" example:"5"`
+	// Threshold is how many shares unseal the credential
+	Threshold int `json:"threshold" validate:"required,min=2" example:"3"`
+}
+
+// BreakGlassSealResponse represents a sealed emergency credential
+type BreakGlassSealResponse struct {
+	ID        int       `json:"id" example:"3"`
+	Shares    int       `json:"shares" example:"5"`
+	Threshold int       `json:"threshold" example:"3"`
+	CreatedBy int       `json:"createdBy" example:"1"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// BreakGlassCeremonyResponse represents the outcome of a key ceremony. The
+// shares are shown once: hand one to each custodian.
+type BreakGlassCeremonyResponse struct {
+	Seal   BreakGlassSealResponse `json:"seal"`
+	Shares []string               `json:"shares" example:"9f2c...01,4be1...02"`
+}
+
+// BreakGlassUnsealRequest represents the request payload to break the glass
+type BreakGlassUnsealRequest struct {
+	// Shares are the custodians' shares, at least the threshold of them
+	Shares []string `json:"shares" validate:"required,min=2" example:"9f2c...01,4be1...02,77a0...03"`
+	// Email is the account that gets admin access
+	Email string `json:"email" validate:"required,email" example:"oncall@example.com"`
+	// Reason is recorded in the event log
+	Reason string `json:"reason" validate:"required" example:"All admin accounts locked out after SSO outage"`
+}
+
+// BreakGlassSessionResponse represents emergency admin access of a user
+type BreakGlassSessionResponse struct {
+	ID        int        `json:"id" example:"1"`
+	SealID    int        `json:"sealId" example:"3"`
+	UserID    int        `json:"userId" example:"42"`
+	Reason    string     `json:"reason" example:"All admin accounts locked out after SSO outage"`
+	OpenedAt  time.Time  `json:"openedAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	ClosedAt  *time.Time `json:"closedAt,omitempty"`
+	ClosedBy  int        `json:"closedBy,omitempty" example:"1"`
+	Active    bool       `json:"active" example:"true"`
+}
+
+// BreakGlassUnsealResponse represents the token of a break-glass session
+type BreakGlassUnsealResponse struct {
+	Session BreakGlassSessionResponse `json:"session"`
+	// Token signs in as the account; it has admin access until the session
+	// expires
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// BreakGlassStatusResponse represents the current seal and recent sessions
+type BreakGlassStatusResponse struct {
+	// Seal is missing when no unused seal exists and a ceremony is due
+	Seal     *BreakGlassSealResponse     `json:"seal,omitempty"`
+	Sessions []BreakGlassSessionResponse `json:"sessions"`
+}
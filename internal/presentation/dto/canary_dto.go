@@ -0,0 +1,24 @@
+package dto
+
+// CanaryCountsResponse represents the requests one variant of a route answered
+type CanaryCountsResponse struct {
+	Requests     int64 `json:"requests" example:"1200"`
+	ServerErrors int64 `json:"serverErrors" example:"3"`
+}
+
+// CanaryResponse represents a route served by a stable and a canary
+// implementation, and how each answered
+type CanaryResponse struct {
+	Method string `json:"method" example:"POST"`
+	Path   string `json:"path" example:"/login"`
+	// Cohort is the percentage of users sent to the canary, or the flag
+	// that sends them
+	Cohort string               `json:"cohort" example:"10%"`
+	Stable CanaryCountsResponse `json:"stable"`
+	Canary CanaryCountsResponse `json:"canary"`
+}
+
+// CanariesResponse represents the canaried routes
+type CanariesResponse struct {
+	Routes []CanaryResponse `json:"routes"`
+}
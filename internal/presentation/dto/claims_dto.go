@@ -0,0 +1,14 @@
+package dto
+
+// ClaimsCacheResponse represents how users' derived claims were looked up
+// since the server started
+type ClaimsCacheResponse struct {
+	// Shared is set when claims are also cached in Redis
+	Shared        bool    `json:"shared" example:"true"`
+	LocalHits     int64   `json:"localHits" example:"9120"`
+	SharedHits    int64   `json:"sharedHits" example:"412"`
+	Misses        int64   `json:"misses" example:"96"`
+	HitRate       float64 `json:"hitRate" example:"0.99"`
+	Invalidations int64   `json:"invalidations" example:"7"`
+	Errors        int64   `json:"errors" example:"0"`
+}
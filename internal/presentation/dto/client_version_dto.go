@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// SetClientVersionRequest represents the request payload for setting the
+// minimum app version of a platform
+type SetClientVersionRequest struct {
+	MinVersion string `json:"minVersion" validate:"required" example:"2.4.0"`
+	// UpdateURL is where users get the current app, e.g. its store page
+	UpdateURL string `json:"updateUrl" example:"https://apps.apple.com/app/id123456789"`
+}
+
+// ClientVersionPolicyResponse represents the minimum app version of a platform
+type ClientVersionPolicyResponse struct {
+	Platform   string `json:"platform" example:"ios"`
+	MinVersion string `json:"minVersion" example:"2.4.0"`
+	UpdateURL  string `json:"updateUrl,omitempty" example:"https://apps.apple.com/app/id123456789"`
+	// Source is config for minimums from CLIENT_MIN_VERSIONS and admin for
+	// ones set through the API, which take precedence
+	Source    string     `json:"source" example:"admin"`
+	UpdatedBy int        `json:"updatedBy,omitempty" example:"1"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// ClientVersionPoliciesResponse represents the minimum app versions
+type ClientVersionPoliciesResponse struct {
+	Policies []ClientVersionPolicyResponse `json:"policies"`
+}
+
+// UpgradeRequiredResponse represents the 426 response to apps older than
+// their platform's minimum version
+type UpgradeRequiredResponse struct {
+	Error          string `json:"error" example:"Upgrade required"`
+	Message        string `json:"message" example:"This version of the app is no longer supported, please update it"`
+	Platform       string `json:"platform" example:"ios"`
+	CurrentVersion string `json:"currentVersion" example:"2.3.1"`
+	MinimumVersion string `json:"minimumVersion" example:"2.4.0"`
+	UpdateURL      string `json:"updateUrl,omitempty" example:"https://apps.apple.com/app/id123456789"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+// ReadCoalescingResponse represents how many reads of users shared a
+// concurrent identical read since the server started
+type ReadCoalescingResponse struct {
+	Calls     int64   `json:"calls" example:"12400"`
+	Queries   int64   `json:"queries" example:"9100"`
+	Coalesced int64   `json:"coalesced" example:"3300"`
+	DedupRate float64 `json:"dedupRate" example:"0.27"`
+}
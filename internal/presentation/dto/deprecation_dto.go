@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// DeprecatedClientResponse represents the calls of one client to a deprecated route
+type DeprecatedClientResponse struct {
+	Client   string    `json:"client" example:"okhttp"`
+	Requests int64     `json:"requests" example:"42"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// DeprecatedRouteResponse represents a deprecated route and who still calls it
+type DeprecatedRouteResponse struct {
+	Method    string                     `json:"method" example:"GET"`
+	Path      string                     `json:"path" example:"/v1/me"`
+	Since     time.Time                  `json:"since"`
+	Sunset    *time.Time                 `json:"sunset,omitempty"`
+	Successor string                     `json:"successor,omitempty" example:"/me"`
+	Link      string                     `json:"link,omitempty"`
+	Warning   string                     `json:"warning"`
+	Requests  int64                      `json:"requests" example:"42"`
+	Clients   []DeprecatedClientResponse `json:"clients"`
+}
+
+// DeprecationsResponse represents the deprecated routes
+type DeprecationsResponse struct {
+	Routes []DeprecatedRouteResponse `json:"routes"`
+}
@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// HeapResponse represents the Go heap
+type HeapResponse struct {
+	AllocBytes   uint64     `json:"allocBytes" example:"8388608"`
+	InUseBytes   uint64     `json:"inUseBytes" example:"12582912"`
+	SysBytes     uint64     `json:"sysBytes" example:"16777216"`
+	Objects      uint64     `json:"objects" example:"42000"`
+	NumGC        uint32     `json:"numGC" example:"17"`
+	LastGC       *time.Time `json:"lastGC,omitempty"`
+	PauseTotalMs float64    `json:"pauseTotalMs" example:"1.5"`
+}
+
+// DBPoolResponse represents the database connection pool
+type DBPoolResponse struct {
+	MaxOpen           int     `json:"maxOpen" example:"25"`
+	Open              int     `json:"open" example:"3"`
+	InUse             int     `json:"inUse" example:"1"`
+	Idle              int     `json:"idle" example:"2"`
+	WaitCount         int64   `json:"waitCount" example:"0"`
+	WaitMs            float64 `json:"waitMs" example:"0"`
+	MaxIdleClosed     int64   `json:"maxIdleClosed" example:"0"`
+	MaxLifetimeClosed int64   `json:"maxLifetimeClosed" example:"0"`
+}
+
+// ErrorSummaryResponse represents the occurrences of one recent error
+type ErrorSummaryResponse struct {
+	Source    string    `json:"source" example:"GET /users/:id"`
+	Message   string    `json:"message" example:"Failed to get user: database is locked"`
+	Count     int64     `json:"count" example:"3"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// DiagnosticsResponse represents the state of an instance for triage
+type DiagnosticsResponse struct {
+	Goroutines int                    `json:"goroutines" example:"42"`
+	Heap       HeapResponse           `json:"heap"`
+	Caches     map[string]int         `json:"caches"`
+	Queues     map[string]int         `json:"queues"`
+	QueueError string                 `json:"queueError,omitempty"`
+	HashPool   HashPoolResponse       `json:"hashPool"`
+	DB         DBPoolResponse         `json:"db"`
+	Errors     []ErrorSummaryResponse `json:"errors"`
+}
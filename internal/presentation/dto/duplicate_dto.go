@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// DuplicateUserResponse represents one of the accounts of a duplicate pair
+type DuplicateUserResponse struct {
+	ID        int       `json:"id" example:"42"`
+	Email     string    `json:"email" example:"jane.doe@gmail.com"`
+	FullName  string    `json:"fullName" example:"Jane Doe"`
+	Status    string    `json:"status" example:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DuplicateResponse represents a pair of accounts that probably belong to
+// the same person
+type DuplicateResponse struct {
+	ID int `json:"id" example:"7"`
+	// Users are both accounts, oldest ID first; an account deleted since
+	// the pair was found is missing
+	Users []DuplicateUserResponse `json:"users"`
+	// Signals are what the accounts share: phone, email or device
+	Signals    []string   `json:"signals" example:"email,phone"`
+	Status     string     `json:"status" example:"pending"`
+	DetectedAt time.Time  `json:"detectedAt"`
+	ReviewedBy int        `json:"reviewedBy,omitempty" example:"1"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+}
+
+// DuplicateListResponse represents probable duplicate accounts, most
+// recently detected first
+type DuplicateListResponse struct {
+	Duplicates []DuplicateResponse `json:"duplicates"`
+}
+
+// DuplicateScanResponse represents the number of pairs a scan found
+type DuplicateScanResponse struct {
+	Found int `json:"found" example:"12"`
+}
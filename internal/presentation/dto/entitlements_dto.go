@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// EntitlementsResponse represents the features a user may use
+type EntitlementsResponse struct {
+	Plan     string   `json:"plan" example:"pro"`
+	Features []string `json:"features" example:"api_access,exports"`
+	// Sources maps each feature to the step that granted it: plan, flag or
+	// override
+	Sources map[string]string `json:"sources"`
+	// Overrides are only listed for admins
+	Overrides []EntitlementOverrideResponse `json:"overrides,omitempty"`
+}
+
+// EntitlementOverrideRequest represents the request payload for granting a
+// feature to a user or taking it away
+type EntitlementOverrideRequest struct {
+	Granted *bool `json:"granted" validate:"required" example:"true"`
+}
+
+// EntitlementOverrideResponse represents a feature granted to one user or
+// taken away from them
+type EntitlementOverrideResponse struct {
+	Feature   string    `json:"feature" example:"api_access"`
+	Granted   bool      `json:"granted" example:"true"`
+	ActorID   int       `json:"actorId" example:"1"`
+	CreatedAt time.Time `json:"createdAt"`
+}
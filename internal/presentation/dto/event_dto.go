@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// EventResponse represents a domain event
+type EventResponse struct {
+	ID            int64                  `json:"id" example:"1042"`
+	Type          string                 `json:"type" example:"user.registered"`
+	SchemaVersion int                    `json:"schemaVersion" example:"1"`
+	SubjectType   string                 `json:"subjectType" example:"user"`
+	SubjectID     int                    `json:"subjectId" example:"7"`
+	ActorID       int                    `json:"actorId,omitempty" example:"7"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+	OccurredAt    time.Time              `json:"occurredAt"`
+}
+
+// EventsResponse represents a page of domain events, oldest first
+type EventsResponse struct {
+	Events []EventResponse `json:"events"`
+	// NextAfter is passed as after for the next page; it is omitted on the last page
+	NextAfter int64 `json:"nextAfter,omitempty" example:"1042"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+// FraudScoreResponse represents the latest fraud score of a user
+type FraudScoreResponse struct {
+	UserID int `json:"userId" example:"42"`
+	// Email and FullName are missing for an account deleted since it was scored
+	Email    string `json:"email,omitempty" example:"jane@yopmail.com"`
+	FullName string `json:"fullName,omitempty" example:"Jane Doe"`
+	Score    int    `json:"score" example:"55"`
+	// Signals raised the score: velocity, disposable_email, geo or device
+	Signals []string `json:"signals" example:"disposable_email,geo"`
+	// Status is clear, flagged (sensitive actions refused) or cleared
+	Status       string     `json:"status" example:"flagged"`
+	ScoredAt     time.Time  `json:"scoredAt"`
+	FlaggedAt    *time.Time `json:"flaggedAt,omitempty"`
+	ClearedBy    int        `json:"clearedBy,omitempty" example:"1"`
+	ClearedAt    *time.Time `json:"clearedAt,omitempty"`
+	ClearedScore int        `json:"clearedScore,omitempty" example:"55"`
+}
+
+// FraudScoreListResponse represents fraud scores, highest first
+type FraudScoreListResponse struct {
+	Scores []FraudScoreResponse `json:"scores"`
+}
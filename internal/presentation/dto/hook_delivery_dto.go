@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// HookAttemptResponse represents a failed attempt at delivering a hook event
+type HookAttemptResponse struct {
+	Error string    `json:"error" example:"webhook returned 502"`
+	At    time.Time `json:"at"`
+}
+
+// HookDeliveryResponse represents a hook event that failed after its change
+// was saved. Data is the event's payload with tokens and secrets redacted;
+// errors lists every failed attempt, oldest first.
+type HookDeliveryResponse struct {
+	ID            int                    `json:"id" example:"12"`
+	EventID       string                 `json:"eventId" example:"evt_NUQ3MWK5OHDG3J7WZ6SQ2DHCXA"`
+	Point         string                 `json:"point" example:"password-reset"`
+	Hook          int                    `json:"hook" example:"0"`
+	UserID        int                    `json:"userId,omitempty" example:"42"`
+	Email         string                 `json:"email" example:"lee@example.com"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+	Status        string                 `json:"status" example:"dead"`
+	Attempts      int                    `json:"attempts" example:"5"`
+	Errors        []HookAttemptResponse  `json:"errors"`
+	NextAttemptAt time.Time              `json:"nextAttemptAt"`
+	CreatedAt     time.Time              `json:"createdAt"`
+	UpdatedAt     time.Time              `json:"updatedAt"`
+}
+
+// HookDeliveryListResponse represents failed hook deliveries, newest first
+type HookDeliveryListResponse struct {
+	Deliveries []HookDeliveryResponse `json:"deliveries"`
+}
+
+// HookReplayResponse represents the number of dead deliveries queued again
+type HookReplayResponse struct {
+	Replayed int `json:"replayed" example:"3"`
+}
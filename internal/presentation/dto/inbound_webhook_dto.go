@@ -0,0 +1,8 @@
+package dto
+
+// InboundWebhookResponse acknowledges a provider's webhook request.
+// Duplicates counts the events received before, which were not handled again.
+type InboundWebhookResponse struct {
+	Received   int `json:"received" example:"2"`
+	Duplicates int `json:"duplicates" example:"0"`
+}
@@ -0,0 +1,95 @@
+package dto
+
+import "fiber-hello-world/pkg/encoder"
+
+// The responses below are written on every request, so they encode
+// themselves for the fast JSON encoder. Output must stay byte for byte what
+// encoding/json produces from the struct tags.
+
+// AppendJSON implements encoder.Appender
+func (r UserResponse) AppendJSON(dst []byte) ([]byte, error) {
+	var err error
+	dst = append(dst, `{"id":`...)
+	dst = encoder.AppendInt(dst, int64(r.ID))
+	dst = append(dst, `,"email":`...)
+	dst = encoder.AppendString(dst, r.Email)
+	dst = append(dst, `,"fullName":`...)
+	dst = encoder.AppendString(dst, r.FullName)
+	dst = append(dst, `,"phoneNumber":`...)
+	dst = encoder.AppendString(dst, r.PhoneNumber)
+	dst = append(dst, `,"birthday":`...)
+	dst = encoder.AppendString(dst, r.Birthday)
+	if r.AvatarURL != "" {
+		dst = append(dst, `,"avatarUrl":`...)
+		dst = encoder.AppendString(dst, r.AvatarURL)
+	}
+	dst = append(dst, `,"plan":`...)
+	dst = encoder.AppendString(dst, r.Plan)
+	if r.Timezone != "" {
+		dst = append(dst, `,"timezone":`...)
+		dst = encoder.AppendString(dst, r.Timezone)
+	}
+	if r.NationalID != "" {
+		dst = append(dst, `,"nationalId":`...)
+		dst = encoder.AppendString(dst, r.NationalID)
+	}
+	if r.KYCStatus != "" {
+		dst = append(dst, `,"kycStatus":`...)
+		dst = encoder.AppendString(dst, r.KYCStatus)
+	}
+	dst = append(dst, `,"createdAt":`...)
+	if dst, err = encoder.AppendTime(dst, r.CreatedAt); err != nil {
+		return dst, err
+	}
+	dst = append(dst, `,"updatedAt":`...)
+	if dst, err = encoder.AppendTime(dst, r.UpdatedAt); err != nil {
+		return dst, err
+	}
+	return append(dst, '}'), nil
+}
+
+// AppendJSON implements encoder.Appender
+func (r LoginResponse) AppendJSON(dst []byte) ([]byte, error) {
+	var err error
+	dst = append(dst, `{"message":`...)
+	dst = encoder.AppendString(dst, r.Message)
+	dst = append(dst, `,"token":`...)
+	dst = encoder.AppendString(dst, r.Token)
+	dst = append(dst, `,"user":`...)
+	if dst, err = r.User.AppendJSON(dst); err != nil {
+		return dst, err
+	}
+	dst = append(dst, `,"expiresAt":`...)
+	if dst, err = encoder.AppendTime(dst, r.ExpiresAt); err != nil {
+		return dst, err
+	}
+	return append(dst, '}'), nil
+}
+
+// AppendJSON implements encoder.Appender
+func (r ErrorResponse) AppendJSON(dst []byte) ([]byte, error) {
+	dst = append(dst, `{"error":`...)
+	dst = encoder.AppendString(dst, r.Error)
+	dst = append(dst, `,"message":`...)
+	dst = encoder.AppendString(dst, r.Message)
+	if r.Code != "" {
+		dst = append(dst, `,"code":`...)
+		dst = encoder.AppendString(dst, r.Code)
+	}
+	return append(dst, '}'), nil
+}
+
+// AppendJSON implements encoder.Appender. Data is encoded directly when it
+// is an Appender itself and with encoding/json otherwise.
+func (r SuccessResponse) AppendJSON(dst []byte) ([]byte, error) {
+	dst = append(dst, `{"message":`...)
+	dst = encoder.AppendString(dst, r.Message)
+	if r.Data != nil {
+		var err error
+		dst = append(dst, `,"data":`...)
+		if dst, err = encoder.AppendValue(dst, r.Data); err != nil {
+			return dst, err
+		}
+	}
+	return append(dst, '}'), nil
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// KYCSubmissionResponse represents an identity document submitted for verification
+type KYCSubmissionResponse struct {
+	ID     int `json:"id" example:"3"`
+	UserID int `json:"userId" example:"42"`
+	// Email and FullName are set for admins, and missing for an account
+	// deleted since it submitted
+	Email    string `json:"email,omitempty" example:"jane@example.com"`
+	FullName string `json:"fullName,omitempty" example:"Jane Doe"`
+	// DocumentType is national_id, passport or driving_license
+	DocumentType string `json:"documentType" example:"passport"`
+	ContentType  string `json:"contentType" example:"image/jpeg"`
+	Size         int    `json:"size" example:"482133"`
+	// Status is pending (with the provider), review (with an admin),
+	// verified or rejected
+	Status string `json:"status" example:"review"`
+	// Reference is the verification provider's ID for the check
+	Reference string `json:"reference,omitempty" example:"chk_8f2a"`
+	// Reason explains a rejection, or why the provider asked for review
+	Reason     string     `json:"reason,omitempty" example:"Photo page is cut off"`
+	ReviewedBy int        `json:"reviewedBy,omitempty" example:"1"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// KYCSubmissionListResponse represents submissions, oldest first
+type KYCSubmissionListResponse struct {
+	Submissions []KYCSubmissionResponse `json:"submissions"`
+}
+
+// RejectKYCRequest represents the request payload for rejecting a submission
+type RejectKYCRequest struct {
+	// Reason is shown to the user, who may submit another document
+	Reason string `json:"reason" form:"reason" validate:"required,max=500" example:"Photo page is cut off"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// PresenceResponse represents whether a user is online, recently active or
+// away
+type PresenceResponse struct {
+	UserID int    `json:"userId" example:"1"`
+	Status string `json:"status" example:"online"`
+	// LastSeenAt is left out when the user was never seen or hides it
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	// Visibility is only returned to the user themselves
+	Visibility string `json:"visibility,omitempty" example:"everyone"`
+}
+
+// PresencesResponse represents the presence of several users
+type PresencesResponse struct {
+	Users []PresenceResponse `json:"users"`
+}
+
+// PresenceVisibilityRequest represents the request payload for choosing who
+// may see the caller's presence
+type PresenceVisibilityRequest struct {
+	Visibility string `json:"visibility" validate:"required,oneof=everyone status nobody" example:"status"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// QRLoginStartResponse represents a started QR login
+type QRLoginStartResponse struct {
+	// Challenge is shown as a QR code for a signed-in device to scan
+	Challenge string `json:"challenge"`
+	// PollToken is kept by the desktop to poll for the result
+	PollToken    string    `json:"pollToken"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	PollInterval int       `json:"pollInterval" example:"2"`
+}
+
+// QRLoginPollRequest represents the request payload for polling a QR login
+type QRLoginPollRequest struct {
+	PollToken string `json:"pollToken" validate:"required"`
+}
+
+// QRLoginPollResponse represents the status of a QR login. Token and User
+// are only set on the poll that completes an approved login.
+type QRLoginPollResponse struct {
+	Status    string        `json:"status" example:"pending"`
+	Token     string        `json:"token,omitempty"`
+	ExpiresAt *time.Time    `json:"expiresAt,omitempty"`
+	User      *UserResponse `json:"user,omitempty"`
+}
+
+// QRLoginChallengeRequest represents the request payload for scanning,
+// approving or denying a QR login
+type QRLoginChallengeRequest struct {
+	Challenge string `json:"challenge" validate:"required"`
+}
+
+// QRLoginScanResponse describes the desktop asking to sign in
+type QRLoginScanResponse struct {
+	IP        string    `json:"ip" example:"203.0.113.7"`
+	Device    string    `json:"device" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64) Firefox/126.0"`
+	Country   string    `json:"country,omitempty" example:"TH"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// QueueThroughputResponse counts the jobs of one type that finished within
+// the report's window
+type QueueThroughputResponse struct {
+	Kind    string `json:"kind" example:"suspend_users"`
+	Applied int    `json:"applied" example:"12"`
+	Failed  int    `json:"failed" example:"1"`
+}
+
+// QueueResponse represents a background job queue. Counts holds the number
+// of jobs in each status, and due those pending jobs the worker may start.
+type QueueResponse struct {
+	Name        string                    `json:"name" example:"admin_actions"`
+	Paused      bool                      `json:"paused"`
+	PausedSince *time.Time                `json:"pausedSince,omitempty"`
+	Due         int                       `json:"due" example:"3"`
+	Counts      map[string]int            `json:"counts"`
+	Window      string                    `json:"window" example:"1h"`
+	Throughput  []QueueThroughputResponse `json:"throughput"`
+}
+
+// QueueListResponse represents the background job queues
+type QueueListResponse struct {
+	Queues []QueueResponse `json:"queues"`
+}
+
+// QueueJobsResponse represents a page of a queue's jobs, newest first
+type QueueJobsResponse struct {
+	Jobs []AdminActionResponse `json:"jobs"`
+}
+
+// QueuePauseRequest represents the request payload pausing or resuming a queue
+type QueuePauseRequest struct {
+	Paused *bool `json:"paused" validate:"required" example:"true"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// UserSummaryResponse represents a user found in the read models
+type UserSummaryResponse struct {
+	ID                 int        `json:"id" example:"7"`
+	Email              string     `json:"email" example:"user@example.com"`
+	FullName           string     `json:"fullName" example:"John Doe"`
+	Role               string     `json:"role" example:"user"`
+	Status             string     `json:"status" example:"active"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	Logins             int        `json:"logins" example:"12"`
+	PasswordLogins     int        `json:"passwordLogins" example:"10"`
+	PasswordlessLogins int        `json:"passwordlessLogins" example:"2"`
+	FirstLoginAt       *time.Time `json:"firstLoginAt,omitempty"`
+	LastLoginAt        *time.Time `json:"lastLoginAt,omitempty"`
+}
+
+// UserSearchResponse represents a page of users found in the read models
+type UserSearchResponse struct {
+	Users  []UserSummaryResponse `json:"users"`
+	Total  int                   `json:"total" example:"120"`
+	Limit  int                   `json:"limit" example:"50"`
+	Offset int                   `json:"offset" example:"0"`
+}
+
+// ProjectionResponse represents how far a read model has read the event log
+type ProjectionResponse struct {
+	Name      string     `json:"name" example:"user_search"`
+	Position  int64      `json:"position" example:"1042"`
+	Behind    int64      `json:"behind" example:"3"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// ProjectionsResponse represents the read models
+type ProjectionsResponse struct {
+	Projections []ProjectionResponse `json:"projections"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// ReadinessResponse represents whether the server is ready for traffic and
+// the state of its optional dependencies
+type ReadinessResponse struct {
+	Status       string             `json:"status" example:"degraded"`
+	Reason       string             `json:"reason,omitempty" example:"warming up: database: context deadline exceeded"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// DependencyStatus represents an optional dependency as of its last check
+// and the features disabled while it is down
+type DependencyStatus struct {
+	Name      string    `json:"name" example:"redis:claims-cache"`
+	Up        bool      `json:"up" example:"false"`
+	Features  []string  `json:"features" example:"shared claims cache"`
+	Error     string    `json:"error,omitempty" example:"redis: dial tcp 10.0.0.5:6379: connect: connection refused"`
+	Since     time.Time `json:"since"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
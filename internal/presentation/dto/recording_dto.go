@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+// RecordingSummary represents a recorded request in a list
+type RecordingSummary struct {
+	ID         int64     `json:"id" example:"42"`
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method" example:"PUT"`
+	Path       string    `json:"path" example:"/me"`
+	Status     int       `json:"status" example:"500"`
+	DurationMs float64   `json:"durationMs" example:"12.5"`
+}
+
+// RecordingsResponse represents the recorded requests, newest first
+type RecordingsResponse struct {
+	Recordings []RecordingSummary `json:"recordings"`
+}
+
+// RecordedResponse represents a response to a recorded or replayed request
+type RecordedResponse struct {
+	Status  int               `json:"status" example:"500"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// RecordingResponse represents a recorded request and its response, with
+// secrets redacted
+type RecordingResponse struct {
+	RecordingSummary
+	Query     string            `json:"query,omitempty" example:"lang=th"`
+	Headers   map[string]string `json:"headers"`
+	Body      string            `json:"body,omitempty"`
+	Response  RecordedResponse  `json:"response"`
+	Truncated bool              `json:"truncated,omitempty"`
+}
+
+// ReplayRequest represents the request payload for replaying a recording
+type ReplayRequest struct {
+	// Headers are set on the replayed request, e.g. the Authorization of a
+	// test user. Without one, a request recorded with an Authorization
+	// header is replayed with the caller's.
+	Headers map[string]string `json:"headers" validate:"max=20"`
+}
+
+// ReplayResponse represents a recording replayed against the running code
+type ReplayResponse struct {
+	Recording     RecordingSummary `json:"recording"`
+	Original      RecordedResponse `json:"original"`
+	Replay        RecordedResponse `json:"replay"`
+	DurationMs    float64          `json:"durationMs" example:"9.8"`
+	StatusMatches bool             `json:"statusMatches"`
+	BodyMatches   bool             `json:"bodyMatches"`
+}
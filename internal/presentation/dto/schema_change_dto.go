@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// SwitchSchemaReadsRequest represents the request payload for switching the
+// reads of a backfilled schema change
+type SwitchSchemaReadsRequest struct {
+	// Reads is new to read the new column or table, old to read the old one
+	Reads string `json:"reads" validate:"required,oneof=old new" example:"new"`
+}
+
+// SchemaChangeResponse represents the progress of an online schema change
+type SchemaChangeResponse struct {
+	Name string `json:"name" example:"users_email_lower"`
+	// Phase is backfilling, backfilled or reading_new
+	Phase string `json:"phase" example:"backfilling"`
+	// Cursor is the ID of the last row backfilled
+	Cursor     int64 `json:"cursor" example:"420000"`
+	Backfilled int64 `json:"backfilled" example:"420000"`
+	// Total is the number of rows when the backfill started
+	Total int64 `json:"total" example:"1000000"`
+	// Progress is the share of the rows backfilled, from 0 to 1
+	Progress     float64    `json:"progress" example:"0.42"`
+	StartedAt    time.Time  `json:"startedAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	BackfilledAt *time.Time `json:"backfilledAt,omitempty"`
+}
+
+// SchemaChangesResponse represents the online schema changes
+type SchemaChangesResponse struct {
+	Changes []SchemaChangeResponse `json:"changes"`
+}
@@ -0,0 +1,75 @@
+package dto
+
+import "fiber-hello-world/pkg/scim"
+
+// ScimName represents the name component of a SCIM user
+type ScimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// ScimMultiValue represents an entry of a SCIM multi-valued attribute such as emails
+type ScimMultiValue struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// ScimMeta represents the resource metadata of a SCIM user
+type ScimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created"`
+	LastModified string `json:"lastModified"`
+	Location     string `json:"location"`
+	Version      string `json:"version"`
+}
+
+// ScimUserRequest represents a SCIM user sent by an identity provider.
+// Attributes the service does not store are accepted and ignored.
+type ScimUserRequest struct {
+	Schemas      []string         `json:"schemas"`
+	UserName     string           `json:"userName" example:"jane@example.com"`
+	Name         *ScimName        `json:"name,omitempty"`
+	DisplayName  string           `json:"displayName,omitempty" example:"Jane Doe"`
+	Active       *bool            `json:"active,omitempty"`
+	Password     string           `json:"password,omitempty"`
+	Emails       []ScimMultiValue `json:"emails,omitempty"`
+	PhoneNumbers []ScimMultiValue `json:"phoneNumbers,omitempty"`
+}
+
+// ScimUserResponse represents a user as a SCIM resource
+type ScimUserResponse struct {
+	Schemas      []string         `json:"schemas"`
+	ID           string           `json:"id"`
+	UserName     string           `json:"userName"`
+	Name         ScimName         `json:"name"`
+	DisplayName  string           `json:"displayName"`
+	Active       bool             `json:"active"`
+	Emails       []ScimMultiValue `json:"emails"`
+	PhoneNumbers []ScimMultiValue `json:"phoneNumbers,omitempty"`
+	Meta         ScimMeta         `json:"meta"`
+}
+
+// ScimListResponse represents a page of SCIM users
+type ScimListResponse struct {
+	Schemas      []string           `json:"schemas"`
+	TotalResults int                `json:"totalResults"`
+	StartIndex   int                `json:"startIndex"`
+	ItemsPerPage int                `json:"itemsPerPage"`
+	Resources    []ScimUserResponse `json:"Resources"`
+}
+
+// ScimPatchRequest represents a SCIM PatchOp request
+type ScimPatchRequest struct {
+	Schemas    []string              `json:"schemas"`
+	Operations []scim.PatchOperation `json:"Operations"`
+}
+
+// ScimErrorResponse represents a SCIM error
+type ScimErrorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
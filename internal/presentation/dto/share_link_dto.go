@@ -0,0 +1,65 @@
+package dto
+
+import "time"
+
+// CreateShareLinkRequest represents the request payload for sharing profile fields
+type CreateShareLinkRequest struct {
+	// Fields are the shared profile fields: email, fullName, phoneNumber,
+	// birthday and avatar
+	Fields []string `json:"fields" validate:"required,min=1" example:"fullName,avatar"`
+	// ExpiresIn is the link's lifetime in seconds (default a day, at most 30 days)
+	ExpiresIn int `json:"expiresIn" validate:"gte=0" example:"3600"`
+	// MaxViews is how often the link can be opened (default once, at most 100)
+	MaxViews int `json:"maxViews" validate:"gte=0" example:"1"`
+}
+
+// ShareLinkResponse represents a share link
+type ShareLinkResponse struct {
+	ID        int        `json:"id"`
+	Fields    []string   `json:"fields" example:"fullName,avatar"`
+	Status    string     `json:"status" example:"active"`
+	MaxViews  int        `json:"maxViews" example:"1"`
+	Views     int        `json:"views" example:"0"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// CreatedShareLinkResponse represents a new share link with its token,
+// which is only returned here
+type CreatedShareLinkResponse struct {
+	ShareLinkResponse
+	Token string `json:"token"`
+	// URL opens the shared profile without signing in
+	URL string `json:"url" example:"https://api.example.com/share/3q2-7w"`
+}
+
+// ShareLinksResponse represents the caller's share links, newest first
+type ShareLinksResponse struct {
+	Links []ShareLinkResponse `json:"links"`
+}
+
+// ShareLinkAccessResponse represents an attempt to open a share link
+type ShareLinkAccessResponse struct {
+	IP         string    `json:"ip" example:"203.0.113.7"`
+	Device     string    `json:"device" example:"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) Safari/604.1"`
+	Country    string    `json:"country,omitempty" example:"TH"`
+	Granted    bool      `json:"granted"`
+	AccessedAt time.Time `json:"accessedAt"`
+}
+
+// ShareLinkAccessesResponse represents the accesses of a share link, newest first
+type ShareLinkAccessesResponse struct {
+	LinkID   int                       `json:"linkId"`
+	Accesses []ShareLinkAccessResponse `json:"accesses"`
+}
+
+// SharedProfileResponse represents the profile fields shared by a link;
+// fields that were not shared are left out
+type SharedProfileResponse struct {
+	Email       string `json:"email,omitempty" example:"user@example.com"`
+	FullName    string `json:"fullName,omitempty" example:"Somchai Jaidee"`
+	PhoneNumber string `json:"phoneNumber,omitempty" example:"0812345678"`
+	Birthday    string `json:"birthday,omitempty" example:"1990-01-15"`
+	AvatarURL   string `json:"avatarUrl,omitempty"`
+}
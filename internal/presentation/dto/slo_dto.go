@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// BurnRateResponse represents how fast an error budget is being spent over
+// a lookback; 1 spends exactly the budget over the SLO window
+type BurnRateResponse struct {
+	Window string  `json:"window" example:"5m"`
+	Rate   float64 `json:"rate" example:"2.5"`
+}
+
+// SLOObjectiveResponse represents a route's state against its objective
+// over the SLO window
+type SLOObjectiveResponse struct {
+	Route     string  `json:"route" example:"POST /login"`
+	Target    float64 `json:"target" example:"0.999"`
+	LatencyMs int64   `json:"latencyMs,omitempty" example:"300"`
+	Requests  int64   `json:"requests" example:"120000"`
+	// Errors were answered with a 5xx status; Slow took longer than LatencyMs
+	Errors          int64              `json:"errors" example:"42"`
+	Slow            int64              `json:"slow" example:"18"`
+	Compliance      float64            `json:"compliance" example:"0.9995"`
+	BudgetRemaining float64            `json:"budgetRemaining" example:"0.5"`
+	BurnRates       []BurnRateResponse `json:"burnRates"`
+	Exhausted       bool               `json:"exhausted"`
+}
+
+// LoadSheddingResponse represents whether requests for routes without an
+// objective are shed while an error budget is exhausted
+type LoadSheddingResponse struct {
+	Enabled bool       `json:"enabled"`
+	Active  bool       `json:"active"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// SLOReportResponse represents the routes' states against their objectives
+type SLOReportResponse struct {
+	Window       string                 `json:"window" example:"24h"`
+	Objectives   []SLOObjectiveResponse `json:"objectives"`
+	LoadShedding LoadSheddingResponse   `json:"loadShedding"`
+}
+
+// LoadSheddingRequest represents the request payload allowing or stopping
+// load shedding
+type LoadSheddingRequest struct {
+	Enabled *bool `json:"enabled" validate:"required" example:"true"`
+}
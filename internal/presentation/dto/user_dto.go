@@ -4,27 +4,70 @@ import "time"
 
 // RegisterRequest represents the request payload for user registration
 type RegisterRequest struct {
-	Email       string `json:"email" validate:"required,email"`
-	Password    string `json:"password" validate:"required,min=6"`
-	FullName    string `json:"fullName" validate:"required,min=2"`
-	PhoneNumber string `json:"phoneNumber" validate:"required,min=10"`
-	Birthday    string `json:"birthday" validate:"required"`
+	Email       string `json:"email" form:"email" validate:"required,email,max=254"`
+	Password    string `json:"password" form:"password" validate:"required,min=6"`
+	FullName    string `json:"fullName" form:"fullName" validate:"required,min=2,max=100"`
+	PhoneNumber string `json:"phoneNumber" form:"phoneNumber" validate:"required,min=10,max=20"`
+	// Birthday is YYYY-MM-DD, with the year in the Buddhist era when
+	// BIRTHDAY_CALENDAR is buddhist
+	Birthday string `json:"birthday" form:"birthday" validate:"required"`
 }
 
 // LoginRequest represents the request payload for user login
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email    string `json:"email" form:"email" validate:"required,email"`
+	Password string `json:"password" form:"password" validate:"required"`
+}
+
+// ChangePasswordRequest represents the request payload for changing the current user's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" form:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword" form:"newPassword" validate:"required,min=6"`
+}
+
+// ForgotPasswordRequest represents the request payload for requesting a password reset
+type ForgotPasswordRequest struct {
+	Email string `json:"email" form:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest represents the request payload for setting a new password with a reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" form:"token" validate:"required"`
+	NewPassword string `json:"newPassword" form:"newPassword" validate:"required,min=6"`
+}
+
+// PatchMeRequest documents the JSON Merge Patch body for PATCH /me.
+// Omitted fields are left unchanged; null removes a field (avatar, timezone
+// and nationalId only). The email cannot be changed.
+type PatchMeRequest struct {
+	FullName    *string `json:"fullName,omitempty"`
+	PhoneNumber *string `json:"phoneNumber,omitempty"`
+	Birthday    *string `json:"birthday,omitempty"`
+	Avatar      *string `json:"avatar,omitempty"`
+	// Timezone is an IANA time zone for scheduled notifications such as
+	// birthday greetings; null uses the server's default
+	Timezone *string `json:"timezone,omitempty" example:"Asia/Bangkok"`
+	// NationalID is a 13-digit Thai national ID, checked against its check
+	// digit; dashes and spaces are removed
+	NationalID *string `json:"nationalId,omitempty" example:"1-1017-00230-70-8"`
 }
 
 // UserResponse represents the response payload for user data
 type UserResponse struct {
-	ID          int       `json:"id"`
-	Email       string    `json:"email"`
-	FullName    string    `json:"fullName"`
-	PhoneNumber string    `json:"phoneNumber"`
-	Birthday    string    `json:"birthday"`
-	CreatedAt   time.Time `json:"createdAt"`
+	ID          int    `json:"id"`
+	Email       string `json:"email"`
+	FullName    string `json:"fullName"`
+	PhoneNumber string `json:"phoneNumber"`
+	Birthday    string `json:"birthday"`
+	AvatarURL   string `json:"avatarUrl,omitempty"`
+	Plan        string `json:"plan"`
+	Timezone    string `json:"timezone,omitempty"`
+	NationalID  string `json:"nationalId,omitempty"`
+	// KYCStatus is pending, review, verified or rejected once the user
+	// submitted an identity document
+	KYCStatus string    `json:"kycStatus,omitempty" example:"verified"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 // LoginResponse represents the response payload for login
@@ -35,10 +78,12 @@ type LoginResponse struct {
 	ExpiresAt time.Time    `json:"expiresAt"`
 }
 
-// ErrorResponse represents the error response payload
+// ErrorResponse represents the error response payload. Code is set for
+// errors clients can act on, such as a rejected name.
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
+	Code    string `json:"code,omitempty" example:"name_reserved"`
 }
 
 // SuccessResponse represents the success response payload
@@ -46,3 +91,39 @@ type SuccessResponse struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
+
+// FieldError represents a validation error for a single field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse represents a validation error response with per-field details
+type ValidationErrorResponse struct {
+	Error   string       `json:"error"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields"`
+}
+
+// LoginEventResponse represents a sign-in attempt in a security report
+type LoginEventResponse struct {
+	IP         string    `json:"ip"`
+	Device     string    `json:"device"`
+	Country    string    `json:"country,omitempty"`
+	NewDevice  bool      `json:"newDevice"`
+	NewCountry bool      `json:"newCountry"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// SecurityReportResponse represents the response payload for the caller's security report
+type SecurityReportResponse struct {
+	Since            time.Time            `json:"since"`
+	Until            time.Time            `json:"until"`
+	SuccessfulLogins int                  `json:"successfulLogins"`
+	FailedAttempts   int                  `json:"failedAttempts"`
+	Countries        []string             `json:"countries"`
+	RecentLogins     []LoginEventResponse `json:"recentLogins"`
+	NewDevices       []LoginEventResponse `json:"newDevices"`
+	RecentFailures   []LoginEventResponse `json:"recentFailures"`
+	GeoAnomalies     []LoginEventResponse `json:"geoAnomalies"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// WebhookAttemptResponse represents a request a webhook sent for an event.
+// StatusCode is 0 when the receiver did not respond.
+type WebhookAttemptResponse struct {
+	StatusCode int       `json:"statusCode" example:"503"`
+	Error      string    `json:"error,omitempty" example:"webhook returned 503"`
+	DurationMs int64     `json:"durationMs" example:"184"`
+	At         time.Time `json:"at"`
+}
+
+// WebhookDeliveryResponse represents the timeline of a webhook's attempts at
+// sending one event, oldest first
+type WebhookDeliveryResponse struct {
+	EventID  string                   `json:"eventId" example:"evt_NUQ3MWK5OHDG3J7WZ6SQ2DHCXA"`
+	UserID   int                      `json:"userId,omitempty" example:"42"`
+	Status   string                   `json:"status" example:"delivered"`
+	Attempts []WebhookAttemptResponse `json:"attempts"`
+}
+
+// WebhookDeliveryListResponse represents the events most recently sent to a
+// webhook, newest first
+type WebhookDeliveryListResponse struct {
+	Webhook    string                    `json:"webhook" example:"post-register"`
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AccountReportHandler issues and serves account data reports
+type AccountReportHandler struct {
+	accountReportUseCase *usecase.AccountReportUseCase
+}
+
+// NewAccountReportHandler creates a new account report handler
+func NewAccountReportHandler(accountReportUseCase *usecase.AccountReportUseCase) *AccountReportHandler {
+	return &AccountReportHandler{
+		accountReportUseCase: accountReportUseCase,
+	}
+}
+
+// @Summary Request my account report
+// @Description Get a single-use link to a report of the caller's profile, profile changes and sign-ins, as HTML or, with ?format=pdf, as a PDF.
+// @Description The link works without signing in and lasts ACCOUNT_REPORT_LINK_TTL; the report is put together when it is downloaded.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} dto.AccountReportLinkResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/account-report [post]
+func (h *AccountReportHandler) RequestMyReport(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+	return h.issueLink(c, claims.UserID)
+}
+
+// @Summary Request a user's account report
+// @Description Get a single-use link to a report of a user's profile, profile changes and sign-ins, e.g. for a data access request. Admin only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 201 {object} dto.AccountReportLinkResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/account-report [post]
+func (h *AccountReportHandler) RequestUserReport(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "user ID must be a positive integer",
+		})
+	}
+	return h.issueLink(c, id)
+}
+
+// issueLink answers with a new report link for userID
+func (h *AccountReportHandler) issueLink(c *fiber.Ctx, userID int) error {
+	token, record, err := h.accountReportUseCase.IssueLink(userID)
+	if err != nil {
+		return c.Status(accountReportErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Account report failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(dto.AccountReportLinkResponse{
+		URL:       c.BaseURL() + "/account-reports/" + token,
+		Formats:   []string{usecase.ReportFormatHTML, usecase.ReportFormatPDF},
+		ExpiresAt: record.ExpiresAt,
+	})
+}
+
+// @Summary Download an account report
+// @Description Download the account report of a link from POST /me/account-report or POST /admin/users/{id}/account-report. No token is needed; the link works once.
+// @Tags user
+// @Produce html
+// @Produce application/pdf
+// @Param token path string true "Report link token"
+// @Param format query string false "Report format" Enums(html, pdf)
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 410 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /account-reports/{token} [get]
+func (h *AccountReportHandler) DownloadReport(c *fiber.Ctx) error {
+	// Personal data must not outlive the link in caches
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	format := c.Query("format", usecase.ReportFormatHTML)
+	report, data, contentType, err := h.accountReportUseCase.Download(c.Params("token"), format)
+	if err != nil {
+		return c.Status(accountReportErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Account report failed",
+			Message: err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="account-report-%d.%s"`, report.User.ID, format))
+	return c.Send(data)
+}
+
+// accountReportErrorStatus maps account report errors to HTTP statuses
+func accountReportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidReportFormat):
+		return 400
+	case errors.Is(err, usecase.ErrInvalidToken), err.Error() == "user not found":
+		return 404
+	case errors.Is(err, usecase.ErrTokenAlreadyUsed), errors.Is(err, usecase.ErrTokenExpired):
+		return 410
+	}
+	return 500
+}
@@ -0,0 +1,753 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHandler handles admin-only HTTP requests
+type AdminHandler struct {
+	funnelUseCase      *usecase.FunnelUseCase
+	userUseCase        *usecase.UserUseCase
+	adminActionUseCase *usecase.AdminActionUseCase
+	validator          *validator.Service
+	decoder            *decoder.Service
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(funnelUseCase *usecase.FunnelUseCase, userUseCase *usecase.UserUseCase, adminActionUseCase *usecase.AdminActionUseCase, validator *validator.Service, decoder *decoder.Service) *AdminHandler {
+	return &AdminHandler{
+		funnelUseCase:      funnelUseCase,
+		userUseCase:        userUseCase,
+		adminActionUseCase: adminActionUseCase,
+		validator:          validator,
+		decoder:            decoder,
+	}
+}
+
+// toAdminActionResponse converts an admin action entity to its response DTO
+func toAdminActionResponse(action *entity.AdminAction) dto.AdminActionResponse {
+	return dto.AdminActionResponse{
+		Token:         action.Token,
+		Kind:          string(action.Kind),
+		Status:        string(action.Status),
+		ActorID:       action.ActorID,
+		UserIDs:       action.UserIDs,
+		Role:          action.Role,
+		Subject:       action.Subject,
+		Error:         action.Error,
+		FailedUserIDs: action.FailedUserIDs,
+		Processed:     action.Processed,
+		ExecuteAt:     action.ExecuteAt,
+		CreatedAt:     action.CreatedAt,
+		FinishedAt:    action.FinishedAt,
+		ReviewedBy:    action.ReviewedBy,
+		ReviewedAt:    action.ReviewedAt,
+		UndoURL:       "/admin/actions/" + action.Token + "/undo",
+	}
+}
+
+// scheduledAction writes the response for a newly queued admin action
+func scheduledAction(c *fiber.Ctx, action *entity.AdminAction, err error) error {
+	if err != nil {
+		status := 500
+		if errors.Is(err, usecase.ErrInvalidAdminAction) {
+			status = 400
+		}
+
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Admin action failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(202).JSON(toAdminActionResponse(action))
+}
+
+// @Summary Get registration funnel report
+// @Description Get daily registration funnel counts and conversion rates between two dates (defaults to the last 30 days)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} dto.FunnelReportResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/funnel [get]
+func (h *AdminHandler) GetFunnel(c *fiber.Ctx) error {
+	report, err := h.funnelUseCase.GetReport(c.Query("from"), c.Query("to"))
+	if err != nil {
+		status := 400
+		if err.Error() == "failed to load funnel data" {
+			status = 500
+		}
+
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Funnel report failed",
+			Message: err.Error(),
+		})
+	}
+
+	// Convert to response DTO
+	response := dto.FunnelReportResponse{
+		From:       report.From,
+		To:         report.To,
+		Totals:     make(map[string]int),
+		Conversion: make(map[string]float64),
+	}
+	for _, stage := range entity.FunnelStages {
+		response.Stages = append(response.Stages, string(stage))
+		response.Totals[string(stage)] = report.Totals[stage]
+		response.Conversion[string(stage)] = report.Conversion[stage]
+	}
+	for _, day := range report.Days {
+		dayResponse := dto.FunnelDayResponse{Date: day.Date, Counts: make(map[string]int)}
+		for stage, count := range day.Counts {
+			dayResponse.Counts[string(stage)] = count
+		}
+		response.Days = append(response.Days, dayResponse)
+	}
+
+	return c.JSON(response)
+}
+
+// @Summary Get user revision history
+// @Description Get the recorded updates and deletes of a user, newest first. Each revision holds who made the change, when, the changed fields and a snapshot of the user before the change. History is kept after the user is deleted.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param limit query int false "Maximum number of revisions (default 50, max 500)"
+// @Param offset query int false "Number of revisions to skip"
+// @Success 200 {object} dto.UserHistoryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/history [get]
+func (h *AdminHandler) GetUserHistory(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "user ID must be a positive integer",
+		})
+	}
+
+	page := repository.Page{Limit: c.QueryInt("limit"), Offset: c.QueryInt("offset")}
+	revisions, err := h.userUseCase.GetUserHistory(id, page)
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "User history failed",
+			Message: err.Error(),
+		})
+	}
+
+	// Convert to response DTO
+	response := dto.UserHistoryResponse{
+		UserID:    id,
+		Revisions: make([]dto.UserRevisionResponse, 0, len(revisions)),
+	}
+	for _, revision := range revisions {
+		changes := make([]dto.FieldChangeResponse, 0, len(revision.Changes))
+		for _, change := range revision.Changes {
+			changes = append(changes, dto.FieldChangeResponse{
+				Field: change.Field,
+				Old:   change.Old,
+				New:   change.New,
+			})
+		}
+
+		response.Revisions = append(response.Revisions, dto.UserRevisionResponse{
+			ID:        revision.ID,
+			ActorID:   revision.ActorID,
+			Action:    string(revision.Action),
+			Changes:   changes,
+			Snapshot:  toUserResponse(&revision.Snapshot),
+			CreatedAt: revision.CreatedAt,
+		})
+	}
+
+	return c.JSON(response)
+}
+
+// @Summary Delete a user
+// @Description Queue the deletion of a user. The delete is applied by the job worker after ADMIN_ACTION_DELAY and can be undone with the returned token until then. When delete_users is one of ADMIN_APPROVAL_KINDS the delay starts once another admin approves it.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 202 {object} dto.AdminActionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/{id} [delete]
+func (h *AdminHandler) DeleteUser(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "user ID must be a positive integer",
+		})
+	}
+
+	action, err := h.adminActionUseCase.ScheduleDelete(claims.UserID, []int{id})
+	return scheduledAction(c, action, err)
+}
+
+// @Summary Change a user's account status
+// @Description Move a user's account to another status, at once. Accounts go from pending to active, between active and suspended, and from either to deactivated; deactivated accounts can be activated again. Only active accounts can sign in. Use DELETE /admin/users/{id} to delete.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body dto.UserStatusRequest true "New status"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/status [put]
+func (h *AdminHandler) SetUserStatus(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "user ID must be a positive integer",
+		})
+	}
+
+	var req dto.UserStatusRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	user, err := h.userUseCase.ChangeStatus(claims.UserID, id, req.Status)
+	if err != nil {
+		status := 500
+		switch {
+		case errors.Is(err, usecase.ErrInvalidStatus):
+			status = 400
+		case errors.Is(err, usecase.ErrIllegalStatusTransition):
+			status = 409
+		case err.Error() == "user not found":
+			status = 404
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Status change failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(dto.SuccessResponse{
+		Message: "User status changed",
+		Data:    toUserResponse(user),
+	})
+}
+
+// @Summary Suspend users
+// @Description Queue the suspension of several users. Applied by the job worker after ADMIN_ACTION_DELAY and can be undone with the returned token until then.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.BulkUserActionRequest true "Users to suspend"
+// @Success 202 {object} dto.AdminActionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/bulk/suspend [post]
+func (h *AdminHandler) SuspendUsers(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.BulkUserActionRequest
+	if err := h.decoder.Decode(c.Get(fiber.HeaderContentType), c.Body(), &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	action, err := h.adminActionUseCase.ScheduleSuspend(claims.UserID, req.UserIDs)
+	return scheduledAction(c, action, err)
+}
+
+// @Summary Change users' role
+// @Description Queue a role change for several users. Applied by the job worker after ADMIN_ACTION_DELAY and can be undone with the returned token until then.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.BulkRoleRequest true "Users and their new role"
+// @Success 202 {object} dto.AdminActionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/bulk/role [post]
+func (h *AdminHandler) SetUsersRole(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.BulkRoleRequest
+	if err := h.decoder.Decode(c.Get(fiber.HeaderContentType), c.Body(), &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	action, err := h.adminActionUseCase.ScheduleSetRole(claims.UserID, req.UserIDs, req.Role)
+	return scheduledAction(c, action, err)
+}
+
+// @Summary Force a password reset after an incident
+// @Description Queue a forced password reset for every user who signed in during a window, optionally from an IP range.
+// @Description Each user's password is replaced and a reset token is delivered with the subject and message through the password-reset hooks.
+// @Description The templates may use {{.FullName}}, {{.Email}}, {{.Token}} and {{.ExpiresAt}}.
+// @Description Applied by the job worker after ADMIN_ACTION_DELAY and can be undone with the returned token until then.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.IncidentResetRequest true "Login segment and notification"
+// @Success 202 {object} dto.AdminActionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/bulk/incident-reset [post]
+func (h *AdminHandler) IncidentReset(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.IncidentResetRequest
+	if err := h.decoder.Decode(c.Get(fiber.HeaderContentType), c.Body(), &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	segment := entity.UserSegment{Since: req.LoggedInSince, Until: req.LoggedInUntil, IPRange: req.IPRange}
+	action, err := h.adminActionUseCase.ScheduleIncidentReset(claims.UserID, segment, req.Subject, req.Message)
+	return scheduledAction(c, action, err)
+}
+
+// @Summary Get a queued admin action
+// @Description Get the status of a queued destructive admin action by its undo token
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token path string true "Undo token"
+// @Success 200 {object} dto.AdminActionResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/actions/{token} [get]
+func (h *AdminHandler) GetAction(c *fiber.Ctx) error {
+	action, err := h.adminActionUseCase.GetAction(c.Params("token"))
+	if err != nil {
+		status := 500
+		if errors.Is(err, repository.ErrAdminActionNotFound) {
+			status = 404
+		}
+
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Admin action lookup failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(toAdminActionResponse(action))
+}
+
+// @Summary Undo a queued admin action
+// @Description Cancel a queued destructive admin action before the job worker applies it, including one still awaiting approval. Pending jobs listed in the admin_actions queue are cancelled the same way.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token path string true "Undo token"
+// @Success 200 {object} dto.AdminActionResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/actions/{token}/undo [post]
+// @Router /admin/queues/admin_actions/jobs/{token}/cancel [post]
+func (h *AdminHandler) UndoAction(c *fiber.Ctx) error {
+	action, err := h.adminActionUseCase.Undo(c.Params("token"))
+	if err != nil {
+		status := 500
+		if errors.Is(err, repository.ErrAdminActionNotFound) {
+			status = 404
+		} else if errors.Is(err, repository.ErrAdminActionNotPending) {
+			status = 409
+		}
+
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Undo failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(toAdminActionResponse(action))
+}
+
+// reviewError writes the response for a failed approval or rejection
+func reviewError(c *fiber.Ctx, title string, err error) error {
+	status := 500
+	switch {
+	case errors.Is(err, repository.ErrAdminActionNotFound):
+		status = 404
+	case errors.Is(err, repository.ErrAdminActionNotAwaitingApproval):
+		status = 409
+	case errors.Is(err, usecase.ErrSelfApproval):
+		status = 403
+	}
+
+	return c.Status(status).JSON(dto.ErrorResponse{
+		Error:   title,
+		Message: err.Error(),
+	})
+}
+
+// @Summary Approve a queued admin action
+// @Description Approve an admin action awaiting approval, i.e. one of ADMIN_APPROVAL_KINDS. Its undo window of ADMIN_ACTION_DELAY starts now, after which the job worker applies it. Admins cannot approve their own actions.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token path string true "Undo token"
+// @Success 200 {object} dto.AdminActionResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/actions/{token}/approve [post]
+func (h *AdminHandler) ApproveAction(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	action, err := h.adminActionUseCase.Approve(c.Params("token"), claims.UserID)
+	if err != nil {
+		return reviewError(c, "Approval failed", err)
+	}
+
+	log.Printf("Admin action %d (%s) of user %d approved by user %d", action.ID, action.Kind, action.ActorID, claims.UserID)
+	return c.JSON(toAdminActionResponse(action))
+}
+
+// @Summary Reject a queued admin action
+// @Description Reject an admin action awaiting approval, so it is never applied
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token path string true "Undo token"
+// @Success 200 {object} dto.AdminActionResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/actions/{token}/reject [post]
+func (h *AdminHandler) RejectAction(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	action, err := h.adminActionUseCase.Reject(c.Params("token"), claims.UserID)
+	if err != nil {
+		return reviewError(c, "Rejection failed", err)
+	}
+
+	log.Printf("Admin action %d (%s) of user %d rejected by user %d", action.ID, action.Kind, action.ActorID, claims.UserID)
+	return c.JSON(toAdminActionResponse(action))
+}
+
+// adminActionQueue is the name the admin action queue is listed under, as
+// reported to autoscalers
+const adminActionQueue = "admin_actions"
+
+// @Summary List background job queues
+// @Description List the background job queues with the number of jobs in each status, the jobs due to run, whether the queue is paused and how many jobs of each type were applied or failed within the window.
+// @Description admin_actions holds the bulk admin actions (delete_users, suspend_users, set_role, incident_reset) run by the job worker.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param window query string false "Throughput window as a Go duration (default 1h)"
+// @Success 200 {object} dto.QueueListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/queues [get]
+func (h *AdminHandler) ListQueues(c *fiber.Ctx) error {
+	window, err := time.ParseDuration(c.Query("window", "1h"))
+	if err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid window",
+			Message: "window must be a duration such as 15m or 24h",
+		})
+	}
+
+	queue, err := h.adminActionUseCase.Queue(window)
+	if err != nil {
+		status := 500
+		if errors.Is(err, usecase.ErrInvalidAdminAction) {
+			status = 400
+		}
+
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Queue lookup failed",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.QueueResponse{
+		Name:       adminActionQueue,
+		Paused:     !queue.PausedSince.IsZero(),
+		Due:        queue.Due,
+		Counts:     make(map[string]int),
+		Window:     c.Query("window", "1h"),
+		Throughput: make([]dto.QueueThroughputResponse, 0, len(queue.Throughput)),
+	}
+	if response.Paused {
+		response.PausedSince = &queue.PausedSince
+	}
+	for _, status := range []entity.AdminActionStatus{entity.AdminActionPending, entity.AdminActionRunning, entity.AdminActionApplied, entity.AdminActionFailed, entity.AdminActionCancelled} {
+		response.Counts[string(status)] = queue.Counts[status]
+	}
+	for _, item := range queue.Throughput {
+		response.Throughput = append(response.Throughput, dto.QueueThroughputResponse{
+			Kind:    string(item.Kind),
+			Applied: item.Applied,
+			Failed:  item.Failed,
+		})
+	}
+
+	return c.JSON(dto.QueueListResponse{Queues: []dto.QueueResponse{response}})
+}
+
+// @Summary List queued admin actions
+// @Description List the jobs of the admin_actions queue, newest first, optionally by status and type
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Job status" Enums(awaiting_approval, pending, running, applied, failed, cancelled, rejected)
+// @Param kind query string false "Job type" Enums(delete_users, suspend_users, set_role, incident_reset)
+// @Param limit query int false "Maximum number of jobs (default 50, at most 500)"
+// @Success 200 {object} dto.QueueJobsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/queues/admin_actions/jobs [get]
+func (h *AdminHandler) ListQueueJobs(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	status, kind := entity.AdminActionStatus(c.Query("status")), entity.AdminActionKind(c.Query("kind"))
+	actions, err := h.adminActionUseCase.ListActions(status, kind, limit)
+	if err != nil {
+		code := 500
+		if errors.Is(err, usecase.ErrInvalidAdminAction) {
+			code = 400
+		}
+
+		return c.Status(code).JSON(dto.ErrorResponse{
+			Error:   "Job listing failed",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.QueueJobsResponse{Jobs: make([]dto.AdminActionResponse, 0, len(actions))}
+	for _, action := range actions {
+		response.Jobs = append(response.Jobs, toAdminActionResponse(action))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Retry a failed admin action
+// @Description Queue a failed admin action to run again on the job worker's next pass. When the action recorded the users it failed for, only those users are retried.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token path string true "Undo token"
+// @Success 200 {object} dto.AdminActionResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/queues/admin_actions/jobs/{token}/retry [post]
+func (h *AdminHandler) RetryAction(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	action, err := h.adminActionUseCase.Retry(c.Params("token"))
+	if err != nil {
+		status := 500
+		if errors.Is(err, repository.ErrAdminActionNotFound) {
+			status = 404
+		} else if errors.Is(err, repository.ErrAdminActionNotFailed) {
+			status = 409
+		}
+
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Retry failed",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Admin action %d (%s) retried by user %d", action.ID, action.Kind, claims.UserID)
+	return c.JSON(toAdminActionResponse(action))
+}
+
+// @Summary Pause or resume a job queue
+// @Description Pause or resume the admin_actions queue. While paused the job worker starts no jobs; jobs already running finish, and pending jobs can still be undone. The state is stored in the database and shared by every instance.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.QueuePauseRequest true "Pause switch"
+// @Success 200 {object} dto.QueueListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/queues/admin_actions/paused [put]
+func (h *AdminHandler) PauseQueue(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.QueuePauseRequest
+	if err := h.decoder.Decode(c.Get(fiber.HeaderContentType), c.Body(), &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	if err := h.adminActionUseCase.SetPaused(*req.Paused); err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Queue update failed",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Queue %s paused=%v by user %d", adminActionQueue, *req.Paused, claims.UserID)
+	return h.ListQueues(c)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AudienceHandler issues tokens restricted to downstream services and
+// publishes the keys that verify them
+type AudienceHandler struct {
+	userUseCase *usecase.UserUseCase
+	jwtService  *jwt.Service
+	validator   *validator.Service
+	decoder     *decoder.Service
+}
+
+// NewAudienceHandler creates a new audience handler
+func NewAudienceHandler(userUseCase *usecase.UserUseCase, jwtService *jwt.Service, validator *validator.Service, decoder *decoder.Service) *AudienceHandler {
+	return &AudienceHandler{
+		userUseCase: userUseCase,
+		jwtService:  jwtService,
+		validator:   validator,
+		decoder:     decoder,
+	}
+}
+
+// @Summary Issue an audience-restricted token
+// @Description Exchange the caller's login token for a short-lived token that only the named downstream service accepts.
+// @Description Scopes must be allowed for the audience; omitted scopes grant all of them.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.AudienceTokenRequest true "Audience and scopes"
+// @Success 200 {object} dto.AudienceTokenResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tokens [post]
+func (h *AudienceHandler) IssueToken(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.AudienceTokenRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	// Users who can no longer sign in keep their login token until it
+	// expires, but may not spread it to other services
+	user, err := h.userUseCase.GetUserByID(claims.UserID)
+	if err != nil {
+		return c.Status(404).JSON(dto.ErrorResponse{
+			Error:   "User not found",
+			Message: err.Error(),
+		})
+	}
+	if err := usecase.CheckCanSignIn(user); err != nil {
+		return c.Status(403).JSON(dto.ErrorResponse{
+			Error:   "Token issuance failed",
+			Message: err.Error(),
+		})
+	}
+
+	token, expiresAt, err := h.jwtService.GenerateAudienceToken(user.ID, user.Email, req.Audience, req.Scopes)
+	if err != nil {
+		status := 500
+		switch {
+		case errors.Is(err, jwt.ErrUnknownAudience), errors.Is(err, jwt.ErrScopeNotAllowed):
+			status = 400
+		case errors.Is(err, usecase.ErrVetoed):
+			status = 403
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Token issuance failed",
+			Message: err.Error(),
+		})
+	}
+
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes, _ = h.jwtService.AudienceScopes(req.Audience)
+	}
+	return c.JSON(dto.AudienceTokenResponse{
+		Token:     token,
+		Audience:  req.Audience,
+		Scope:     strings.Join(scopes, " "),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// @Summary Get an audience's verification keys
+// @Description The JSON Web Key Set a downstream service uses to verify the tokens issued for it.
+// @Description The first key signs new tokens; the others verify tokens issued before a key rotation.
+// @Tags authentication
+// @Produce json
+// @Param audience path string true "Audience name"
+// @Success 200 {object} dto.JWKSetResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /.well-known/audiences/{audience}/jwks.json [get]
+func (h *AudienceHandler) GetKeys(c *fiber.Ctx) error {
+	keys, err := h.jwtService.AudienceKeys(c.Params("audience"))
+	if err != nil {
+		return c.Status(404).JSON(dto.ErrorResponse{
+			Error:   "Audience not found",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.JWKSetResponse{Keys: make([]dto.JWKResponse, len(keys))}
+	for i, key := range keys {
+		response.Keys[i] = dto.JWKResponse{
+			Kty: key.Kty, Kid: key.Kid, Use: key.Use, Alg: key.Alg,
+			N: key.N, E: key.E, Crv: key.Crv, X: key.X, Y: key.Y,
+		}
+	}
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(response)
+}
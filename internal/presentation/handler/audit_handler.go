@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuditHandler serves the audit trail of admin mutations
+type AuditHandler struct {
+	auditUseCase *usecase.AuditUseCase
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditUseCase *usecase.AuditUseCase) *AuditHandler {
+	return &AuditHandler{
+		auditUseCase: auditUseCase,
+	}
+}
+
+// @Summary List the admin audit trail
+// @Description List the mutations admins made, newest first, each with the fields it changed before and after. Secret fields are redacted.
+// @Description Actions are user.updated, user.deleted, client_version.set, client_version.reset, entitlement.set, entitlement.deleted, fraud.cleared, kyc.reviewed and duplicate.dismissed. Page with before=nextBefore.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param actor query int false "Only mutations by this admin"
+// @Param subjectType query string false "Only mutations of this subject type, e.g. user or client_version"
+// @Param subjectId query string false "Only mutations of this subject, with subjectType"
+// @Param before query int false "Only entries before this ID"
+// @Param limit query int false "Maximum number of entries (default 50, at most 500)"
+// @Success 200 {object} dto.AuditResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/audit [get]
+func (h *AuditHandler) ListAudit(c *fiber.Ctx) error {
+	filter := repository.AuditFilter{
+		ActorID:     c.QueryInt("actor"),
+		SubjectType: c.Query("subjectType"),
+		SubjectID:   c.Query("subjectId"),
+		BeforeID:    c.QueryInt("before"),
+		Limit:       c.QueryInt("limit"),
+	}
+
+	entries, next, err := h.auditUseCase.List(filter)
+	if err != nil {
+		status := 500
+		if errors.Is(err, usecase.ErrInvalidAuditFilter) {
+			status = 400
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Failed to list audit entries",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.AuditResponse{Entries: make([]dto.AuditEntryResponse, 0, len(entries)), NextBefore: next}
+	for _, entry := range entries {
+		changes := make([]dto.FieldChangeResponse, 0, len(entry.Changes))
+		for _, change := range entry.Changes {
+			changes = append(changes, dto.FieldChangeResponse{
+				Field: change.Field,
+				Old:   change.Old,
+				New:   change.New,
+			})
+		}
+		response.Entries = append(response.Entries, dto.AuditEntryResponse{
+			ID:          entry.ID,
+			ActorID:     entry.ActorID,
+			Action:      string(entry.Action),
+			SubjectType: entry.SubjectType,
+			SubjectID:   entry.SubjectID,
+			Changes:     changes,
+			CreatedAt:   entry.CreatedAt,
+		})
+	}
+	return c.JSON(response)
+}
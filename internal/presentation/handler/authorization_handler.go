@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuthorizationHandler handles admin requests for the relationship-based
+// authorization integration
+type AuthorizationHandler struct {
+	authorizationUseCase *usecase.AuthorizationUseCase
+}
+
+// NewAuthorizationHandler creates a new authorization handler
+func NewAuthorizationHandler(authorizationUseCase *usecase.AuthorizationUseCase) *AuthorizationHandler {
+	return &AuthorizationHandler{
+		authorizationUseCase: authorizationUseCase,
+	}
+}
+
+// @Summary Sync roles to OpenFGA
+// @Description Write every user's role assignment to the OpenFGA store and remove stale ones. Changes are synced as they happen; run this after enabling the integration or when OpenFGA was unreachable.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.AuthorizationSyncResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 502 {object} dto.ErrorResponse
+// @Router /admin/authorization/sync [post]
+func (h *AuthorizationHandler) SyncRoles(c *fiber.Ctx) error {
+	count, err := h.authorizationUseCase.SyncAll()
+	if err != nil {
+		return c.Status(502).JSON(dto.ErrorResponse{
+			Error:   "Sync failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(dto.AuthorizationSyncResponse{Users: count})
+}
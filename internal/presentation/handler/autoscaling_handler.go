@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"bytes"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/autoscale"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AutoscalingHandler serves the load signals autoscalers scale on
+type AutoscalingHandler struct {
+	signals *autoscale.Signals
+}
+
+// NewAutoscalingHandler creates a new autoscaling handler
+func NewAutoscalingHandler(signals *autoscale.Signals) *AutoscalingHandler {
+	return &AutoscalingHandler{
+		signals: signals,
+	}
+}
+
+// @Summary Get autoscaling signals
+// @Description Report requests in flight, background queue depths and password hashing pool load. With format=prometheus the signals are returned as gauges in the Prometheus text format, for the Prometheus adapter or KEDA.
+// @Tags general
+// @Accept json
+// @Produce json
+// @Produce plain
+// @Param format query string false "Response format" Enums(json, prometheus)
+// @Success 200 {object} dto.AutoscalingResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /autoscaling [get]
+func (h *AutoscalingHandler) GetSignals(c *fiber.Ctx) error {
+	format := c.Query("format", "json")
+	if format != "json" && format != "prometheus" {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid format",
+			Message: "format must be json or prometheus",
+		})
+	}
+
+	report, err := h.signals.Report()
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to read autoscaling signals",
+			Message: err.Error(),
+		})
+	}
+
+	if format == "prometheus" {
+		var buf bytes.Buffer
+		if err := report.WritePrometheus(&buf); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		return c.Send(buf.Bytes())
+	}
+
+	return c.JSON(dto.AutoscalingResponse{
+		InFlightRequests: report.InFlightRequests,
+		Queues:           report.Queues,
+		HashPool: dto.HashPoolResponse{
+			Size:       report.HashPool.Size,
+			Busy:       report.HashPool.Busy,
+			Waiting:    report.HashPool.Waiting,
+			Saturation: report.HashPoolSaturation,
+		},
+	})
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BackupHandler handles admin requests for database backups
+type BackupHandler struct {
+	backupUseCase *usecase.BackupUseCase
+}
+
+// NewBackupHandler creates a new backup handler
+func NewBackupHandler(backupUseCase *usecase.BackupUseCase) *BackupHandler {
+	return &BackupHandler{
+		backupUseCase: backupUseCase,
+	}
+}
+
+// toBackupResponse converts a backup entity to its response DTO
+func toBackupResponse(backup entity.Backup) dto.BackupResponse {
+	return dto.BackupResponse{
+		Name:      backup.Name,
+		Size:      backup.Size,
+		SHA256:    backup.SHA256,
+		CreatedAt: backup.CreatedAt,
+	}
+}
+
+// @Summary Create a database backup
+// @Description Take an online backup of the database now, verify its integrity and prune backups beyond BACKUP_RETENTION
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} dto.BackupResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/backups [post]
+func (h *BackupHandler) CreateBackup(c *fiber.Ctx) error {
+	backup, err := h.backupUseCase.Backup()
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Backup failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(toBackupResponse(*backup))
+}
+
+// @Summary List database backups
+// @Description List the stored database backups, newest first
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.BackupListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/backups [get]
+func (h *BackupHandler) ListBackups(c *fiber.Ctx) error {
+	backups, err := h.backupUseCase.List()
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Backup listing failed",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.BackupListResponse{Backups: []dto.BackupResponse{}}
+	for _, backup := range backups {
+		response.Backups = append(response.Backups, toBackupResponse(backup))
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/decoder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var errUnsupportedContentType = errors.New("content type must be application/json, application/x-www-form-urlencoded or multipart/form-data")
+
+// isForm reports whether the request carries a URL-encoded or multipart form body
+func isForm(c *fiber.Ctx) bool {
+	mediaType, _, err := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	return err == nil && (mediaType == fiber.MIMEApplicationForm || mediaType == fiber.MIMEMultipartForm)
+}
+
+// parseBody decodes the request body into dst. JSON bodies go through the strict
+// shared decoder; form bodies are bound using the `form` struct tags.
+func parseBody(c *fiber.Ctx, bodyDecoder *decoder.Service, dst interface{}) error {
+	contentType := c.Get(fiber.HeaderContentType)
+	if decoder.IsJSON(contentType) {
+		return bodyDecoder.Decode(contentType, c.Body(), dst)
+	}
+	if isForm(c) {
+		return c.BodyParser(dst)
+	}
+	return errUnsupportedContentType
+}
+
+// bodyError writes the error response for a failed body decode
+func bodyError(c *fiber.Ctx, err error) error {
+	status := 400
+	if errors.Is(err, errUnsupportedContentType) || errors.Is(err, decoder.ErrUnsupportedMediaType) {
+		status = 415
+	} else if errors.Is(err, decoder.ErrBodyTooLarge) {
+		status = 413
+	}
+
+	return c.Status(status).JSON(dto.ErrorResponse{
+		Error:   "Invalid request body",
+		Message: err.Error(),
+	})
+}
+
+// readFormFile reads an uploaded multipart file into memory
+func readFormFile(file *multipart.FileHeader) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BreakGlassHandler serves key ceremonies and emergency admin access
+type BreakGlassHandler struct {
+	breakGlassUseCase *usecase.BreakGlassUseCase
+	jwtService        *jwt.Service
+	validator         *validator.Service
+	decoder           *decoder.Service
+}
+
+// NewBreakGlassHandler creates a new break-glass handler
+func NewBreakGlassHandler(breakGlassUseCase *usecase.BreakGlassUseCase, jwtService *jwt.Service, validator *validator.Service, decoder *decoder.Service) *BreakGlassHandler {
+	return &BreakGlassHandler{
+		breakGlassUseCase: breakGlassUseCase,
+		jwtService:        jwtService,
+		validator:         validator,
+		decoder:           decoder,
+	}
+}
+
+// breakGlassErrorStatus maps break-glass errors to HTTP statuses
+func breakGlassErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidCeremony), errors.Is(err, usecase.ErrBreakGlassReasonRequired):
+		return 400
+	case errors.Is(err, usecase.ErrBreakGlassInvalidShares), errors.Is(err, usecase.ErrBreakGlassUser):
+		return 403
+	case errors.Is(err, usecase.ErrBreakGlassNotSealed), errors.Is(err, usecase.ErrBreakGlassSessionNotFound):
+		return 404
+	default:
+		return 500
+	}
+}
+
+// toBreakGlassSealResponse converts a seal to its response DTO
+func toBreakGlassSealResponse(seal *entity.BreakGlassSeal) dto.BreakGlassSealResponse {
+	return dto.BreakGlassSealResponse{
+		ID:        seal.ID,
+		Shares:    seal.Shares,
+		Threshold: seal.Threshold,
+		CreatedBy: seal.CreatedBy,
+		CreatedAt: seal.CreatedAt,
+	}
+}
+
+// toBreakGlassSessionResponse converts a session to its response DTO
+func toBreakGlassSessionResponse(session *entity.BreakGlassSession, now time.Time) dto.BreakGlassSessionResponse {
+	return dto.BreakGlassSessionResponse{
+		ID:        session.ID,
+		SealID:    session.SealID,
+		UserID:    session.UserID,
+		Reason:    session.Reason,
+		OpenedAt:  session.OpenedAt,
+		ExpiresAt: session.ExpiresAt,
+		ClosedAt:  session.ClosedAt,
+		ClosedBy:  session.ClosedBy,
+		Active:    session.Active(now),
+	}
+}
+
+// @Summary Run a break-glass key ceremony
+// @Description Seal a new emergency credential and split it into shares, threshold of which unseal it with POST /break-glass/unseal. Only a hash of the credential is stored and the shares are returned once: hand one to each custodian. The previous unused seal is revoked.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param ceremony body dto.BreakGlassCeremonyRequest true "Shares and threshold"
+// @Success 201 {object} dto.BreakGlassCeremonyResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/break-glass/ceremony [post]
+func (h *BreakGlassHandler) Ceremony(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.BreakGlassCeremonyRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	seal, shares, err := h.breakGlassUseCase.Seal(req.Shares, req.Threshold, claims.UserID)
+	if err != nil {
+		return c.Status(breakGlassErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Key ceremony failed",
+			Message: err.Error(),
+		})
+	}
+	return c.Status(201).JSON(dto.BreakGlassCeremonyResponse{
+		Seal:   toBreakGlassSealResponse(seal),
+		Shares: shares,
+	})
+}
+
+// @Summary Get break-glass status
+// @Description Get the unused seal, missing when a key ceremony is due, and the most recent break-glass sessions, newest first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.BreakGlassStatusResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/break-glass [get]
+func (h *BreakGlassHandler) Status(c *fiber.Ctx) error {
+	seal, err := h.breakGlassUseCase.ActiveSeal()
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to get break-glass status",
+			Message: err.Error(),
+		})
+	}
+	sessions, err := h.breakGlassUseCase.Sessions(50)
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to get break-glass status",
+			Message: err.Error(),
+		})
+	}
+
+	now := time.Now()
+	response := dto.BreakGlassStatusResponse{Sessions: make([]dto.BreakGlassSessionResponse, 0, len(sessions))}
+	if seal != nil {
+		sealResponse := toBreakGlassSealResponse(seal)
+		response.Seal = &sealResponse
+	}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, toBreakGlassSessionResponse(session, now))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Close a break-glass session
+// @Description End emergency admin access before it expires, e.g. once an admin account is recovered
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} dto.BreakGlassSessionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/break-glass/sessions/{id}/close [post]
+func (h *BreakGlassHandler) CloseSession(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid session ID",
+			Message: "session ID must be a positive integer",
+		})
+	}
+
+	session, err := h.breakGlassUseCase.Close(id, claims.UserID)
+	if err != nil {
+		return c.Status(breakGlassErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Failed to close session",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(toBreakGlassSessionResponse(session, time.Now()))
+}
+
+// @Summary Break the glass
+// @Description Unseal the emergency credential with the custodians' shares and get admin access for an account until BREAK_GLASS_TTL passes, e.g. when every admin is locked out. The account must be able to sign in. The reason is recorded in the event log before access is granted, and the seal is used up: run a new key ceremony afterwards.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param unseal body dto.BreakGlassUnsealRequest true "Shares, account and reason"
+// @Success 200 {object} dto.BreakGlassUnsealResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /break-glass/unseal [post]
+func (h *BreakGlassHandler) Unseal(c *fiber.Ctx) error {
+	var req dto.BreakGlassUnsealRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	session, user, err := h.breakGlassUseCase.Unseal(req.Shares, req.Email, req.Reason)
+	if err != nil {
+		log.Printf("Break-glass unseal from %s refused: %v", middleware.ClientIP(c), err)
+		return c.Status(breakGlassErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Break-glass failed",
+			Message: err.Error(),
+		})
+	}
+
+	token, expiresAt, err := h.jwtService.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Token generation failed",
+			Message: err.Error(),
+		})
+	}
+	log.Printf("BREAK-GLASS: token issued to user %d from %s", user.ID, middleware.ClientIP(c))
+	return c.JSON(dto.BreakGlassUnsealResponse{
+		Session:   toBreakGlassSessionResponse(session, time.Now()),
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
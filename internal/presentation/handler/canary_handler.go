@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/canary"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CanaryHandler reports how the canaried routes answered
+type CanaryHandler struct {
+	router *canary.Router
+}
+
+// NewCanaryHandler creates a new canary handler
+func NewCanaryHandler(router *canary.Router) *CanaryHandler {
+	return &CanaryHandler{
+		router: router,
+	}
+}
+
+// @Summary Get canaried routes
+// @Description List the routes in CANARY_ROUTES with the cohort sent to their alternate implementation, and the requests and 5xx responses of the stable and the canary variant since this instance started, to compare them before the rewrite replaces the original.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.CanariesResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/canaries [get]
+func (h *CanaryHandler) GetCanaries(c *fiber.Ctx) error {
+	stats := h.router.Stats()
+	response := dto.CanariesResponse{Routes: make([]dto.CanaryResponse, 0, len(stats))}
+	for _, route := range stats {
+		response.Routes = append(response.Routes, dto.CanaryResponse{
+			Method: route.Rule.Method,
+			Path:   route.Rule.Path,
+			Cohort: route.Rule.Cohort(),
+			Stable: dto.CanaryCountsResponse{Requests: route.Stable.Requests, ServerErrors: route.Stable.ServerErrors},
+			Canary: dto.CanaryCountsResponse{Requests: route.Canary.Requests, ServerErrors: route.Canary.ServerErrors},
+		})
+	}
+	return c.JSON(response)
+}
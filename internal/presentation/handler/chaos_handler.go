@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/chaos"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ChaosHandler handles admin requests that change the fault injection rules
+type ChaosHandler struct {
+	injector  *chaos.Injector
+	validator *validator.Service
+	decoder   *decoder.Service
+}
+
+// NewChaosHandler creates a new fault injection handler
+func NewChaosHandler(injector *chaos.Injector, validator *validator.Service, decoder *decoder.Service) *ChaosHandler {
+	return &ChaosHandler{
+		injector:  injector,
+		validator: validator,
+		decoder:   decoder,
+	}
+}
+
+// rulesResponse converts the active rules to their response DTO
+func (h *ChaosHandler) rulesResponse() dto.ChaosRulesResponse {
+	response := dto.ChaosRulesResponse{Rules: []dto.ChaosRule{}}
+	for _, rule := range h.injector.Rules() {
+		response.Rules = append(response.Rules, dto.ChaosRule{
+			Method:    rule.Method,
+			Path:      rule.Path,
+			Percent:   rule.Percent,
+			LatencyMs: int(rule.Latency / time.Millisecond),
+			Status:    rule.Status,
+			Drop:      rule.Drop,
+		})
+	}
+	return response
+}
+
+// @Summary Get fault injection rules
+// @Description List the faults being injected into requests for resilience testing
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ChaosRulesResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/chaos [get]
+func (h *ChaosHandler) GetRules(c *fiber.Ctx) error {
+	return c.JSON(h.rulesResponse())
+}
+
+// @Summary Set fault injection rules
+// @Description Replace the fault injection rules. Each rule adds latency to, fails or drops a percentage of the requests matching its method and path prefix; the first matching rule applies. /admin/chaos itself is never affected.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ChaosRulesRequest true "Fault injection rules"
+// @Success 200 {object} dto.ChaosRulesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Router /admin/chaos [put]
+func (h *ChaosHandler) SetRules(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.ChaosRulesRequest
+	if err := h.decoder.Decode(c.Get(fiber.HeaderContentType), c.Body(), &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	rules := make([]chaos.Rule, len(req.Rules))
+	for i, rule := range req.Rules {
+		rules[i] = chaos.Rule{
+			Method:  rule.Method,
+			Path:    rule.Path,
+			Percent: rule.Percent,
+			Latency: time.Duration(rule.LatencyMs) * time.Millisecond,
+			Status:  rule.Status,
+			Drop:    rule.Drop,
+		}
+	}
+	if err := h.injector.SetRules(rules); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid fault rules",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Fault injection rules set by user %d: %d rules", claims.UserID, len(rules))
+	return c.JSON(h.rulesResponse())
+}
+
+// @Summary Clear fault injection rules
+// @Description Stop injecting faults
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ChaosRulesResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/chaos [delete]
+func (h *ChaosHandler) ClearRules(c *fiber.Ctx) error {
+	// Clearing cannot fail
+	_ = h.injector.SetRules(nil)
+	log.Println("Fault injection rules cleared")
+	return c.JSON(h.rulesResponse())
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClaimsHandler reports on the cache of users' derived claims
+type ClaimsHandler struct {
+	claimsUseCase *usecase.ClaimsUseCase
+}
+
+// NewClaimsHandler creates a new claims handler
+func NewClaimsHandler(claimsUseCase *usecase.ClaimsUseCase) *ClaimsHandler {
+	return &ClaimsHandler{claimsUseCase: claimsUseCase}
+}
+
+// @Summary Get claims cache statistics
+// @Description Report how the role and status of callers were looked up since the server started: from this node's memory, from Redis when CLAIMS_CACHE_REDIS_URL is set, or from the database on a miss. Invalidations count users whose cached claims were dropped after a role or status change.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ClaimsCacheResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/claims-cache [get]
+func (h *ClaimsHandler) GetStats(c *fiber.Ctx) error {
+	stats := h.claimsUseCase.Stats()
+	return c.JSON(dto.ClaimsCacheResponse{
+		Shared:        h.claimsUseCase.SharedEnabled(),
+		LocalHits:     stats.LocalHits,
+		SharedHits:    stats.SharedHits,
+		Misses:        stats.Misses,
+		HitRate:       stats.HitRate(),
+		Invalidations: stats.Invalidations,
+		Errors:        stats.Errors,
+	})
+}
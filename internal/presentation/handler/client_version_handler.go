@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientVersionHandler handles the minimum app version of each platform
+type ClientVersionHandler struct {
+	clientVersionUseCase *usecase.ClientVersionUseCase
+	validator            *validator.Service
+	decoder              *decoder.Service
+}
+
+// NewClientVersionHandler creates a new client version handler
+func NewClientVersionHandler(clientVersionUseCase *usecase.ClientVersionUseCase, validator *validator.Service, decoder *decoder.Service) *ClientVersionHandler {
+	return &ClientVersionHandler{
+		clientVersionUseCase: clientVersionUseCase,
+		validator:            validator,
+		decoder:              decoder,
+	}
+}
+
+// toClientVersionPolicyResponse converts a client version policy to its response DTO
+func toClientVersionPolicyResponse(policy *entity.ClientVersionPolicy) dto.ClientVersionPolicyResponse {
+	response := dto.ClientVersionPolicyResponse{
+		Platform:   policy.Platform,
+		MinVersion: policy.MinVersion,
+		UpdateURL:  policy.UpdateURL,
+		Source:     policy.Source,
+		UpdatedBy:  policy.UpdatedBy,
+	}
+	if !policy.UpdatedAt.IsZero() {
+		updatedAt := policy.UpdatedAt
+		response.UpdatedAt = &updatedAt
+	}
+	return response
+}
+
+// @Summary List minimum app versions
+// @Description List the oldest app version served on each platform, from CLIENT_MIN_VERSIONS or set by an admin. Apps send "X-Client-Version: <platform>/<version>"; older ones get 426 Upgrade Required.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ClientVersionPoliciesResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/client-versions [get]
+func (h *ClientVersionHandler) ListPolicies(c *fiber.Ctx) error {
+	policies, err := h.clientVersionUseCase.Policies()
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to list client versions",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.ClientVersionPoliciesResponse{Policies: make([]dto.ClientVersionPolicyResponse, 0, len(policies))}
+	for _, policy := range policies {
+		response.Policies = append(response.Policies, toClientVersionPolicyResponse(policy))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Set the minimum app version of a platform
+// @Description Set the oldest app version served on a platform, overriding CLIENT_MIN_VERSIONS, and where users get the current app. Versions are dot-separated numbers with an optional pre-release, e.g. 2.4.0 or 2.4.0-beta.1.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param platform path string true "Platform, e.g. ios or android"
+// @Param request body dto.SetClientVersionRequest true "Minimum version"
+// @Success 200 {object} dto.ClientVersionPolicyResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/client-versions/{platform} [put]
+func (h *ClientVersionHandler) SetPolicy(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.SetClientVersionRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	policy, err := h.clientVersionUseCase.SetMinimum(claims.UserID, c.Params("platform"), req.MinVersion, req.UpdateURL)
+	if err != nil {
+		return c.Status(clientVersionErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Failed to set client version",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Minimum %s version set to %s by user %d", policy.Platform, policy.MinVersion, claims.UserID)
+	return c.JSON(toClientVersionPolicyResponse(policy))
+}
+
+// @Summary Reset the minimum app version of a platform
+// @Description Remove the minimum an admin set for a platform, so the one from CLIENT_MIN_VERSIONS applies again, if any
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param platform path string true "Platform, e.g. ios or android"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/client-versions/{platform} [delete]
+func (h *ClientVersionHandler) ResetPolicy(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	platform := c.Params("platform")
+	if err := h.clientVersionUseCase.Reset(claims.UserID, platform); err != nil {
+		return c.Status(clientVersionErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Failed to reset client version",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Minimum %s version reset by user %d", platform, claims.UserID)
+	return c.JSON(dto.SuccessResponse{
+		Message: "Client version reset",
+	})
+}
+
+// clientVersionErrorStatus maps client version errors to HTTP statuses
+func clientVersionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidClientVersion):
+		return 400
+	case errors.Is(err, usecase.ErrClientVersionNotFound):
+		return 404
+	}
+	return 500
+}
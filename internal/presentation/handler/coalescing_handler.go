@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"bytes"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/coalesce"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CoalescingHandler reports on coalesced reads
+type CoalescingHandler struct {
+	reads *coalesce.Group
+}
+
+// NewCoalescingHandler creates a new coalescing handler
+func NewCoalescingHandler(reads *coalesce.Group) *CoalescingHandler {
+	return &CoalescingHandler{reads: reads}
+}
+
+// @Summary Get read coalescing statistics
+// @Description Report how many reads of a user by ID were asked for since the server started, how many ran a database query and how many shared the query of a concurrent identical read, e.g. during a burst of GET /me calls by one user.
+// @Description With format=prometheus the counts are returned as counters in the Prometheus text format.
+// @Tags admin
+// @Produce json
+// @Produce plain
+// @Security BearerAuth
+// @Param format query string false "Response format" Enums(json, prometheus)
+// @Success 200 {object} dto.ReadCoalescingResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/read-coalescing [get]
+func (h *CoalescingHandler) GetStats(c *fiber.Ctx) error {
+	format := c.Query("format", "json")
+	if format != "json" && format != "prometheus" {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid format",
+			Message: "format must be json or prometheus",
+		})
+	}
+
+	stats := h.reads.Stats()
+	if format == "prometheus" {
+		var buf bytes.Buffer
+		if err := stats.WritePrometheus(&buf); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		return c.Send(buf.Bytes())
+	}
+	return c.JSON(dto.ReadCoalescingResponse{
+		Calls:     stats.Calls,
+		Queries:   stats.Queries,
+		Coalesced: stats.Coalesced(),
+		DedupRate: stats.DedupRate(),
+	})
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"bytes"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/deprecation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeprecationHandler reports the calls to deprecated routes
+type DeprecationHandler struct {
+	registry *deprecation.Registry
+}
+
+// NewDeprecationHandler creates a new deprecation handler
+func NewDeprecationHandler(registry *deprecation.Registry) *DeprecationHandler {
+	return &DeprecationHandler{
+		registry: registry,
+	}
+}
+
+// @Summary Get deprecated route usage
+// @Description List the deprecated routes with the clients still calling them, most requests first. Clients are named by their X-Client-ID header, or else the product in their User-Agent.
+// @Description With format=prometheus the calls are returned as counters in the Prometheus text format.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Produce plain
+// @Security BearerAuth
+// @Param format query string false "Response format" Enums(json, prometheus)
+// @Success 200 {object} dto.DeprecationsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/deprecations [get]
+func (h *DeprecationHandler) GetDeprecations(c *fiber.Ctx) error {
+	format := c.Query("format", "json")
+	if format != "json" && format != "prometheus" {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid format",
+			Message: "format must be json or prometheus",
+		})
+	}
+
+	report := h.registry.Report()
+	if format == "prometheus" {
+		var buf bytes.Buffer
+		if err := deprecation.WritePrometheus(&buf, report); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		return c.Send(buf.Bytes())
+	}
+
+	response := dto.DeprecationsResponse{Routes: make([]dto.DeprecatedRouteResponse, 0, len(report))}
+	for _, usage := range report {
+		route := dto.DeprecatedRouteResponse{
+			Method:    usage.Policy.Method,
+			Path:      usage.Policy.Path,
+			Since:     usage.Policy.Since,
+			Successor: usage.Policy.Successor,
+			Link:      usage.Policy.Link,
+			Warning:   usage.Policy.Warning(),
+			Requests:  usage.Requests,
+			Clients:   make([]dto.DeprecatedClientResponse, 0, len(usage.Clients)),
+		}
+		if !usage.Policy.Sunset.IsZero() {
+			sunset := usage.Policy.Sunset
+			route.Sunset = &sunset
+		}
+		for _, client := range usage.Clients {
+			route.Clients = append(route.Clients, dto.DeprecatedClientResponse{
+				Client:   client.Client,
+				Requests: client.Requests,
+				LastSeen: client.LastSeen,
+			})
+		}
+		response.Routes = append(response.Routes, route)
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/diagnostics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DiagnosticsHandler serves the state of the instance for triage
+type DiagnosticsHandler struct {
+	collector *diagnostics.Collector
+}
+
+// NewDiagnosticsHandler creates a new diagnostics handler
+func NewDiagnosticsHandler(collector *diagnostics.Collector) *DiagnosticsHandler {
+	return &DiagnosticsHandler{
+		collector: collector,
+	}
+}
+
+// @Summary Get diagnostics
+// @Description Report goroutines, heap statistics, cache sizes, background queue depths, the database connection pool and a summary of recent errors of this instance, for triage without a profiler. Errors that differ only by numbers are counted together. Admin only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.DiagnosticsResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/diagnostics [get]
+func (h *DiagnosticsHandler) GetDiagnostics(c *fiber.Ctx) error {
+	report := h.collector.Report()
+
+	response := dto.DiagnosticsResponse{
+		Goroutines: report.Goroutines,
+		Heap: dto.HeapResponse{
+			AllocBytes:   report.Heap.Alloc,
+			InUseBytes:   report.Heap.InUse,
+			SysBytes:     report.Heap.Sys,
+			Objects:      report.Heap.Objects,
+			NumGC:        report.Heap.NumGC,
+			PauseTotalMs: float64(report.Heap.PauseTotal) / float64(time.Millisecond),
+		},
+		Caches:     report.Caches,
+		Queues:     report.Queues,
+		QueueError: report.QueueError,
+		HashPool: dto.HashPoolResponse{
+			Size:       report.HashPool.Size,
+			Busy:       report.HashPool.Busy,
+			Waiting:    report.HashPool.Waiting,
+			Saturation: report.HashPool.Saturation(),
+		},
+		DB: dto.DBPoolResponse{
+			MaxOpen:           report.DB.MaxOpenConnections,
+			Open:              report.DB.OpenConnections,
+			InUse:             report.DB.InUse,
+			Idle:              report.DB.Idle,
+			WaitCount:         report.DB.WaitCount,
+			WaitMs:            float64(report.DB.WaitDuration) / float64(time.Millisecond),
+			MaxIdleClosed:     report.DB.MaxIdleClosed,
+			MaxLifetimeClosed: report.DB.MaxLifetimeClosed,
+		},
+		Errors: make([]dto.ErrorSummaryResponse, 0, len(report.Errors)),
+	}
+	if !report.Heap.LastGC.IsZero() {
+		lastGC := report.Heap.LastGC
+		response.Heap.LastGC = &lastGC
+	}
+	for _, summary := range report.Errors {
+		response.Errors = append(response.Errors, dto.ErrorSummaryResponse{
+			Source:    summary.Source,
+			Message:   summary.Message,
+			Count:     summary.Count,
+			FirstSeen: summary.FirstSeen,
+			LastSeen:  summary.LastSeen,
+		})
+	}
+
+	return c.JSON(response)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DigestHandler handles admin requests for the email digests
+type DigestHandler struct {
+	digestUseCase *usecase.DigestUseCase
+	period        entity.DigestPeriod
+}
+
+// NewDigestHandler creates a new digest handler previewing period by default
+func NewDigestHandler(digestUseCase *usecase.DigestUseCase, period entity.DigestPeriod) *DigestHandler {
+	return &DigestHandler{
+		digestUseCase: digestUseCase,
+		period:        period,
+	}
+}
+
+// @Summary Preview the admin digest
+// @Description Render the digest of the period ending now, as it would be emailed, without sending it
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param period query string false "daily or weekly (defaults to DIGEST_SCHEDULE)"
+// @Success 200 {object} dto.DigestPreviewResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/digest/preview [get]
+func (h *DigestHandler) Preview(c *fiber.Ctx) error {
+	period := entity.DigestPeriod(c.Query("period", string(h.period)))
+	subject, body, err := h.digestUseCase.Preview(period)
+	if err != nil {
+		status := 500
+		if errors.Is(err, usecase.ErrInvalidDigestPeriod) {
+			status = 400
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Digest preview failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(dto.DigestPreviewResponse{Subject: subject, Body: body})
+}
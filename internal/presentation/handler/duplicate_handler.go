@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DuplicateHandler serves the probable duplicate accounts for admin review
+type DuplicateHandler struct {
+	duplicateUseCase *usecase.DuplicateUseCase
+}
+
+// NewDuplicateHandler creates a new duplicate handler
+func NewDuplicateHandler(duplicateUseCase *usecase.DuplicateUseCase) *DuplicateHandler {
+	return &DuplicateHandler{duplicateUseCase: duplicateUseCase}
+}
+
+// toDuplicateResponse converts a duplicate candidate to its response DTO
+func toDuplicateResponse(candidate *entity.DuplicateCandidate) dto.DuplicateResponse {
+	response := dto.DuplicateResponse{
+		ID:         candidate.ID,
+		Users:      make([]dto.DuplicateUserResponse, 0, 2),
+		Signals:    candidate.Signals,
+		Status:     candidate.Status,
+		DetectedAt: candidate.DetectedAt,
+		ReviewedBy: candidate.ReviewedBy,
+		ReviewedAt: candidate.ReviewedAt,
+	}
+	for _, user := range []*entity.User{candidate.User, candidate.OtherUser} {
+		if user == nil {
+			continue
+		}
+		response.Users = append(response.Users, dto.DuplicateUserResponse{
+			ID:        user.ID,
+			Email:     user.Email,
+			FullName:  user.FullName,
+			Status:    user.Status,
+			CreatedAt: user.CreatedAt,
+		})
+	}
+	return response
+}
+
+// @Summary List probable duplicate accounts
+// @Description List pairs of accounts that probably belong to the same person, most recently detected first: the same phone number in any format, email addresses delivering to the same mailbox (case, +tags and Gmail dots ignored), or logins from the same device and IP in the last 90 days.
+// @Description Pairs are found by a daily scan, or on demand with POST /admin/duplicates/scan. Values shared by more than 5 accounts, such as an office IP, are ignored.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Review status (default pending)" Enums(pending, dismissed)
+// @Param limit query int false "Maximum number of pairs (default 50, at most 500)"
+// @Success 200 {object} dto.DuplicateListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/duplicates [get]
+func (h *DuplicateHandler) ListDuplicates(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+	status := c.Query("status", entity.DuplicatePending)
+	if status != entity.DuplicatePending && status != entity.DuplicateDismissed {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid status",
+			Message: "status must be pending or dismissed",
+		})
+	}
+
+	candidates, err := h.duplicateUseCase.List(status, limit)
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to list duplicates",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.DuplicateListResponse{Duplicates: make([]dto.DuplicateResponse, 0, len(candidates))}
+	for _, candidate := range candidates {
+		response.Duplicates = append(response.Duplicates, toDuplicateResponse(candidate))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Scan for duplicate accounts
+// @Description Compare every account now instead of waiting for the daily scan. Pending pairs no longer found are removed; dismissed pairs stay dismissed.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.DuplicateScanResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/duplicates/scan [post]
+func (h *DuplicateHandler) ScanDuplicates(c *fiber.Ctx) error {
+	found, err := h.duplicateUseCase.Scan()
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to scan for duplicates",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(dto.DuplicateScanResponse{Found: found})
+}
+
+// @Summary Dismiss a duplicate pair
+// @Description Record that the two accounts belong to different people, so later scans do not surface them again
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Pair ID"
+// @Success 200 {object} dto.DuplicateResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/duplicates/{id}/dismiss [post]
+func (h *DuplicateHandler) DismissDuplicate(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid pair ID",
+			Message: "pair ID must be a positive integer",
+		})
+	}
+
+	candidate, err := h.duplicateUseCase.Dismiss(id, claims.UserID)
+	if err != nil {
+		status := 500
+		if errors.Is(err, usecase.ErrDuplicateNotFound) {
+			status = 404
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Failed to dismiss duplicate",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Duplicate pair %d (users %d and %d) dismissed by user %d", candidate.ID, candidate.UserID, candidate.OtherUserID, claims.UserID)
+	return c.JSON(toDuplicateResponse(candidate))
+}
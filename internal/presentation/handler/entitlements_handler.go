@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/entitlements"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EntitlementsHandler handles requests for users' features and the admin
+// overrides of them
+type EntitlementsHandler struct {
+	entitlementsUseCase *usecase.EntitlementsUseCase
+	validator           *validator.Service
+	decoder             *decoder.Service
+}
+
+// NewEntitlementsHandler creates a new entitlements handler
+func NewEntitlementsHandler(entitlementsUseCase *usecase.EntitlementsUseCase, validator *validator.Service, decoder *decoder.Service) *EntitlementsHandler {
+	return &EntitlementsHandler{
+		entitlementsUseCase: entitlementsUseCase,
+		validator:           validator,
+		decoder:             decoder,
+	}
+}
+
+// @Summary Get the caller's entitlements
+// @Description Get the features the caller may use, so clients gate features the way the server does. Features come from the caller's plan, then the feature flags in ENTITLEMENTS_FILE, which may grant a feature or take it away, then overrides an admin set for the caller.
+// @Description The set is cached for ENTITLEMENTS_CACHE_TTL, on the server and by clients through Cache-Control. A plan change applies at once; flag changes apply on restart.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.EntitlementsResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/entitlements [get]
+func (h *EntitlementsHandler) GetMyEntitlements(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	// The claims module keeps the plan current without a database read
+	plan := ""
+	if derived, ok := c.Locals("claims").(*entity.DerivedClaims); ok {
+		plan = derived.Plan
+	}
+	set, err := h.entitlementsUseCase.Resolve(claims.UserID, plan)
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Entitlements failed",
+			Message: err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("private, max-age=%d", int(h.entitlementsUseCase.CacheTTL().Seconds())))
+	return c.JSON(toEntitlementsResponse(set))
+}
+
+// @Summary Get a user's entitlements
+// @Description Get the features a user may use on the plan in their record, with the overrides set for them
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.EntitlementsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/entitlements [get]
+func (h *EntitlementsHandler) GetUserEntitlements(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "user ID must be a positive integer",
+		})
+	}
+
+	set, err := h.entitlementsUseCase.Resolve(id, "")
+	if err != nil {
+		return c.Status(entitlementsErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Entitlements failed",
+			Message: err.Error(),
+		})
+	}
+	overrides, err := h.entitlementsUseCase.Overrides(id)
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Entitlements failed",
+			Message: err.Error(),
+		})
+	}
+
+	response := toEntitlementsResponse(set)
+	for _, override := range overrides {
+		response.Overrides = append(response.Overrides, dto.EntitlementOverrideResponse{
+			Feature:   override.Feature,
+			Granted:   override.Granted,
+			ActorID:   override.ActorID,
+			CreatedAt: override.CreatedAt,
+		})
+	}
+	return c.JSON(response)
+}
+
+// @Summary Override a user's entitlement
+// @Description Grant a feature to a user, or take it away with granted false, whatever their plan and the feature flags say. The feature must be named by a plan or flag in ENTITLEMENTS_FILE.
+// @Description Other replicas apply the override within ENTITLEMENTS_CACHE_TTL.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param feature path string true "Feature"
+// @Param request body dto.EntitlementOverrideRequest true "Override"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/entitlements/{feature} [put]
+func (h *EntitlementsHandler) SetOverride(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "user ID must be a positive integer",
+		})
+	}
+
+	var req dto.EntitlementOverrideRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	feature := c.Params("feature")
+	if err := h.entitlementsUseCase.SetOverride(claims.UserID, id, feature, *req.Granted); err != nil {
+		return c.Status(entitlementsErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Entitlement override failed",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Entitlement %s of user %d set to %t by user %d", feature, id, *req.Granted, claims.UserID)
+	return c.JSON(dto.SuccessResponse{Message: "Entitlement overridden"})
+}
+
+// @Summary Remove an entitlement override
+// @Description Remove a user's override of a feature, so their plan and the feature flags decide it again
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param feature path string true "Feature"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/entitlements/{feature} [delete]
+func (h *EntitlementsHandler) DeleteOverride(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "user ID must be a positive integer",
+		})
+	}
+
+	if err := h.entitlementsUseCase.DeleteOverride(claims.UserID, id, c.Params("feature")); err != nil {
+		return c.Status(entitlementsErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Entitlement override removal failed",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(dto.SuccessResponse{Message: "Entitlement override removed"})
+}
+
+// entitlementsErrorStatus maps entitlements use case errors to HTTP statuses
+func entitlementsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrUnknownFeature):
+		return 400
+	case errors.Is(err, usecase.ErrEntitlementOverrideNotFound), err.Error() == "user not found":
+		return 404
+	}
+	return 500
+}
+
+// toEntitlementsResponse converts a feature set to its response
+func toEntitlementsResponse(set *entitlements.Set) dto.EntitlementsResponse {
+	response := dto.EntitlementsResponse{
+		Plan:     set.Plan,
+		Features: set.Names(),
+		Sources:  make(map[string]string, len(set.Features)),
+	}
+	for feature, source := range set.Features {
+		response.Sources[feature] = string(source)
+	}
+	return response
+}
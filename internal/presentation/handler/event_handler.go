@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EventHandler serves the domain event log
+type EventHandler struct {
+	eventUseCase *usecase.EventUseCase
+}
+
+// NewEventHandler creates a new event handler
+func NewEventHandler(eventUseCase *usecase.EventUseCase) *EventHandler {
+	return &EventHandler{
+		eventUseCase: eventUseCase,
+	}
+}
+
+// toEventResponse converts a domain event to its response DTO
+func toEventResponse(event *entity.DomainEvent) dto.EventResponse {
+	return dto.EventResponse{
+		ID:            event.ID,
+		Type:          string(event.Type),
+		SchemaVersion: event.SchemaVersion,
+		SubjectType:   event.SubjectType,
+		SubjectID:     event.SubjectID,
+		ActorID:       event.ActorID,
+		Data:          event.Data,
+		OccurredAt:    event.OccurredAt,
+	}
+}
+
+// eventFilter reads the type, since, until, after and limit query parameters
+func eventFilter(c *fiber.Ctx) (repository.EventFilter, error) {
+	var filter repository.EventFilter
+	for _, eventType := range strings.Split(c.Query("type"), ",") {
+		if eventType = strings.TrimSpace(eventType); eventType != "" {
+			filter.Types = append(filter.Types, entity.EventType(eventType))
+		}
+	}
+	for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, errors.New(name + " must be an RFC 3339 time, e.g. 2025-01-31T00:00:00Z")
+		}
+		*dst = t
+	}
+	if value := c.Query("after"); value != "" {
+		after, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || after < 0 {
+			return filter, errors.New("after must be an event ID")
+		}
+		filter.AfterID = after
+	}
+	filter.Limit = c.QueryInt("limit")
+	return filter, nil
+}
+
+// @Summary List domain events
+// @Description List the events in the domain event log, oldest first, such as user.registered, user.logged_in, user.updated, user.password_changed, user.role_changed, user.plan_changed, user.activated, user.suspended, user.deactivated, user.deleted, user.email_bounced, user.birthday, share_link.created, share_link.revoked, hook.dead_lettered, break_glass.sealed, break_glass.opened and break_glass.closed.
+// @Description Each event carries the schema version of its data. Page with after=nextAfter.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Comma-separated event types"
+// @Param since query string false "Only events at or after this RFC 3339 time"
+// @Param until query string false "Only events before this RFC 3339 time"
+// @Param after query int false "Only events after this ID"
+// @Param limit query int false "Maximum number of events (default 100, at most 1000)"
+// @Success 200 {object} dto.EventsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/events [get]
+func (h *EventHandler) ListEvents(c *fiber.Ctx) error {
+	filter, err := eventFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid event filter",
+			Message: err.Error(),
+		})
+	}
+
+	events, next, err := h.eventUseCase.List(filter)
+	if err != nil {
+		return c.Status(eventErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Failed to list events",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.EventsResponse{Events: make([]dto.EventResponse, 0, len(events)), NextAfter: next}
+	for _, event := range events {
+		response.Events = append(response.Events, toEventResponse(event))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Export domain events
+// @Description Download every event matching the filters, oldest first, as JSON lines (one EventResponse per line) or CSV with the data as a JSON column
+// @Tags admin
+// @Produce plain
+// @Security BearerAuth
+// @Param format query string false "Export format" Enums(jsonl, csv)
+// @Param type query string false "Comma-separated event types"
+// @Param since query string false "Only events at or after this RFC 3339 time"
+// @Param until query string false "Only events before this RFC 3339 time"
+// @Param after query int false "Only events after this ID"
+// @Success 200 {string} string "Events"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/events/export [get]
+func (h *EventHandler) ExportEvents(c *fiber.Ctx) error {
+	format := c.Query("format", "jsonl")
+	if format != "jsonl" && format != "csv" {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid format",
+			Message: "format must be jsonl or csv",
+		})
+	}
+	filter, err := eventFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid event filter",
+			Message: err.Error(),
+		})
+	}
+	// Check the filter before the response starts, while errors can still
+	// be reported
+	filter.Limit = 1
+	if _, _, err := h.eventUseCase.List(filter); err != nil {
+		return c.Status(eventErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Failed to export events",
+			Message: err.Error(),
+		})
+	}
+
+	if format == "jsonl" {
+		streamNDJSON(c, "events", format, func(write func(v interface{}) error) error {
+			return h.eventUseCase.Export(filter, func(event *entity.DomainEvent) error {
+				return write(toEventResponse(event))
+			})
+		})
+		return nil
+	}
+
+	name := "events-" + time.Now().UTC().Format("20060102-150405") + ".csv"
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+name+`"`)
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+
+	// Events are written as they are read, so large exports are not held
+	// in memory. A failure midway can only cut the download short.
+	release := middleware.HoldLane(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer release()
+		out := csv.NewWriter(w)
+		defer out.Flush()
+		out.Write([]string{"id", "type", "schemaVersion", "subjectType", "subjectId", "actorId", "occurredAt", "data"})
+		err := h.eventUseCase.Export(filter, func(event *entity.DomainEvent) error {
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				return err
+			}
+			out.Write([]string{
+				strconv.FormatInt(event.ID, 10), string(event.Type), strconv.Itoa(event.SchemaVersion), event.SubjectType,
+				strconv.Itoa(event.SubjectID), strconv.Itoa(event.ActorID), event.OccurredAt.UTC().Format(time.RFC3339Nano), string(data),
+			})
+			return out.Error()
+		})
+		if err != nil {
+			log.Printf("Event export failed: %v", err)
+		}
+	})
+	return nil
+}
+
+// eventErrorStatus maps event log errors to HTTP statuses
+func eventErrorStatus(err error) int {
+	if errors.Is(err, usecase.ErrInvalidEventFilter) {
+		return 400
+	}
+	return 500
+}
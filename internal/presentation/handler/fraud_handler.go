@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FraudHandler serves the fraud scores of users for admin review
+type FraudHandler struct {
+	fraudUseCase *usecase.FraudUseCase
+}
+
+// NewFraudHandler creates a new fraud handler
+func NewFraudHandler(fraudUseCase *usecase.FraudUseCase) *FraudHandler {
+	return &FraudHandler{fraudUseCase: fraudUseCase}
+}
+
+// toFraudScoreResponse converts a fraud score to its response DTO
+func toFraudScoreResponse(score *entity.FraudScore) dto.FraudScoreResponse {
+	response := dto.FraudScoreResponse{
+		UserID:       score.UserID,
+		Score:        score.Score,
+		Signals:      score.Signals,
+		Status:       score.Status,
+		ScoredAt:     score.ScoredAt,
+		FlaggedAt:    score.FlaggedAt,
+		ClearedBy:    score.ClearedBy,
+		ClearedAt:    score.ClearedAt,
+		ClearedScore: score.ClearedScore,
+	}
+	if score.User != nil {
+		response.Email = score.User.Email
+		response.FullName = score.User.FullName
+	}
+	return response
+}
+
+// userIDParam reads the :id path parameter as a user ID
+func userIDParam(c *fiber.Ctx) (int, bool) {
+	id, err := c.ParamsInt("id")
+	return id, err == nil && id > 0
+}
+
+// @Summary List fraud scores
+// @Description List users by fraud review status, highest score first. Users are scored from their email address and the logins of the last day: login velocity, disposable email, logins from several countries and from several new devices each add to the score, and users reaching FRAUD_FLAG_SCORE are flagged.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Review status (default flagged)" Enums(flagged, cleared, clear)
+// @Param limit query int false "Maximum number of users (default 50, at most 500)"
+// @Success 200 {object} dto.FraudScoreListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/fraud [get]
+func (h *FraudHandler) ListScores(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+	status := c.Query("status", entity.FraudFlagged)
+	if status != entity.FraudFlagged && status != entity.FraudCleared && status != entity.FraudClear {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid status",
+			Message: "status must be flagged, cleared or clear",
+		})
+	}
+
+	scores, err := h.fraudUseCase.List(status, limit)
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to list fraud scores",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.FraudScoreListResponse{Scores: make([]dto.FraudScoreResponse, 0, len(scores))}
+	for _, score := range scores {
+		response.Scores = append(response.Scores, toFraudScoreResponse(score))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Get a user's fraud score
+// @Description Get the latest fraud score of a user and the signals that raised it
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.FraudScoreResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/fraud/{id} [get]
+func (h *FraudHandler) GetScore(c *fiber.Ctx) error {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "user ID must be a positive integer",
+		})
+	}
+
+	score, err := h.fraudUseCase.Get(userID)
+	if err != nil {
+		status := 500
+		if errors.Is(err, usecase.ErrFraudScoreNotFound) {
+			status = 404
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Failed to get fraud score",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(toFraudScoreResponse(score))
+}
+
+// @Summary Score a user now
+// @Description Score a user now instead of waiting for their next login, e.g. after adding disposable domains. A score reaching FRAUD_FLAG_SCORE flags the user.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.FraudScoreResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/fraud/{id}/score [post]
+func (h *FraudHandler) ScoreUser(c *fiber.Ctx) error {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "user ID must be a positive integer",
+		})
+	}
+
+	score, err := h.fraudUseCase.ScoreUser(userID)
+	if err != nil {
+		return c.Status(404).JSON(dto.ErrorResponse{
+			Error:   "Failed to score user",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(toFraudScoreResponse(score))
+}
+
+// @Summary Clear a flagged user
+// @Description Lift the flag of a user after review, allowing sensitive actions again. The user is flagged again only by a higher score.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.FraudScoreResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/fraud/{id}/clear [post]
+func (h *FraudHandler) ClearUser(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+	userID, ok := userIDParam(c)
+	if !ok {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "user ID must be a positive integer",
+		})
+	}
+
+	score, err := h.fraudUseCase.Clear(userID, claims.UserID)
+	if err != nil {
+		status := 500
+		switch {
+		case errors.Is(err, usecase.ErrFraudScoreNotFound):
+			status = 404
+		case errors.Is(err, usecase.ErrFraudNotFlagged):
+			status = 409
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Failed to clear user",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("User %d cleared of fraud review at score %d by user %d", userID, score.Score, claims.UserID)
+	return c.JSON(toFraudScoreResponse(score))
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/redact"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HookDeliveryHandler handles admin requests for failed hook deliveries
+type HookDeliveryHandler struct {
+	deliveryUseCase *usecase.HookDeliveryUseCase
+}
+
+// NewHookDeliveryHandler creates a new hook delivery handler
+func NewHookDeliveryHandler(deliveryUseCase *usecase.HookDeliveryUseCase) *HookDeliveryHandler {
+	return &HookDeliveryHandler{deliveryUseCase: deliveryUseCase}
+}
+
+// toHookDeliveryResponse converts a delivery to its response DTO. Secret
+// payload fields, such as a password reset token, are redacted; replays
+// still send them.
+func toHookDeliveryResponse(delivery *entity.HookDelivery) dto.HookDeliveryResponse {
+	response := dto.HookDeliveryResponse{
+		ID:            delivery.ID,
+		EventID:       delivery.EventID,
+		Point:         delivery.Point,
+		Hook:          delivery.Hook,
+		UserID:        delivery.UserID,
+		Email:         delivery.Email,
+		Status:        string(delivery.Status),
+		Attempts:      delivery.Attempts,
+		Errors:        make([]dto.HookAttemptResponse, 0, len(delivery.Errors)),
+		NextAttemptAt: delivery.NextAttemptAt,
+		CreatedAt:     delivery.CreatedAt,
+		UpdatedAt:     delivery.UpdatedAt,
+	}
+	if len(delivery.Data) > 0 {
+		response.Data = make(map[string]interface{}, len(delivery.Data))
+		for key, value := range delivery.Data {
+			if redact.Secrets.IsSecret(key) {
+				value = redact.Redacted
+			}
+			response.Data[key] = value
+		}
+	}
+	for _, attempt := range delivery.Errors {
+		response.Errors = append(response.Errors, dto.HookAttemptResponse{Error: attempt.Error, At: attempt.At})
+	}
+	return response
+}
+
+// deliveryError writes the response for a failed delivery lookup or replay
+func deliveryError(c *fiber.Ctx, title string, err error) error {
+	status := 500
+	switch {
+	case errors.Is(err, usecase.ErrInvalidHookDelivery):
+		status = 400
+	case errors.Is(err, repository.ErrHookDeliveryNotFound):
+		status = 404
+	case errors.Is(err, repository.ErrHookDeliveryNotDead):
+		status = 409
+	}
+
+	return c.Status(status).JSON(dto.ErrorResponse{
+		Error:   title,
+		Message: err.Error(),
+	})
+}
+
+// deliveryID parses the :id path parameter, writing a 400 response if it is invalid
+func deliveryID(c *fiber.Ctx) (int, bool, error) {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return 0, false, c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid delivery ID",
+			Message: "delivery ID must be a positive integer",
+		})
+	}
+	return id, true, nil
+}
+
+// @Summary List failed hook deliveries
+// @Description List post-register, password-reset and birthday hook events that failed, which cannot reject their change, newest first, with their payload and error history.
+// @Description Deliveries are retried up to HOOK_MAX_ATTEMPTS times with exponential backoff starting at HOOK_RETRY_BACKOFF; then they are dead-lettered. The dead letters are listed by default.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Delivery status (default dead)" Enums(retrying, running, delivered, dead, all)
+// @Param point query string false "Hook point" Enums(post-register, password-reset, birthday)
+// @Param limit query int false "Maximum number of deliveries (default 50, at most 500)"
+// @Success 200 {object} dto.HookDeliveryListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dead-letters [get]
+func (h *HookDeliveryHandler) ListDeliveries(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+	status := c.Query("status", string(entity.HookDeliveryDead))
+	if status == "all" {
+		status = ""
+	}
+
+	deliveries, err := h.deliveryUseCase.ListDeliveries(entity.HookDeliveryStatus(status), c.Query("point"), limit)
+	if err != nil {
+		return deliveryError(c, "Delivery listing failed", err)
+	}
+
+	response := dto.HookDeliveryListResponse{Deliveries: make([]dto.HookDeliveryResponse, 0, len(deliveries))}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, toHookDeliveryResponse(delivery))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Get a failed hook delivery
+// @Description Get a failed hook delivery with its payload and error history
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Delivery ID"
+// @Success 200 {object} dto.HookDeliveryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dead-letters/{id} [get]
+func (h *HookDeliveryHandler) GetDelivery(c *fiber.Ctx) error {
+	id, ok, err := deliveryID(c)
+	if !ok {
+		return err
+	}
+
+	delivery, err := h.deliveryUseCase.GetDelivery(id)
+	if err != nil {
+		return deliveryError(c, "Delivery lookup failed", err)
+	}
+	return c.JSON(toHookDeliveryResponse(delivery))
+}
+
+// @Summary Replay a dead-lettered hook delivery
+// @Description Queue a dead-lettered delivery to be sent again on the job worker's next pass, with a fresh set of HOOK_MAX_ATTEMPTS attempts. Its error history is kept.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Delivery ID"
+// @Success 200 {object} dto.HookDeliveryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dead-letters/{id}/replay [post]
+func (h *HookDeliveryHandler) ReplayDelivery(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+	id, ok, err := deliveryID(c)
+	if !ok {
+		return err
+	}
+
+	delivery, err := h.deliveryUseCase.Replay(id)
+	if err != nil {
+		return deliveryError(c, "Replay failed", err)
+	}
+
+	log.Printf("Hook delivery %d replayed by user %d", delivery.ID, claims.UserID)
+	return c.JSON(toHookDeliveryResponse(delivery))
+}
+
+// @Summary Replay dead-lettered hook deliveries
+// @Description Queue every dead-lettered delivery, optionally only those at one hook point, to be sent again on the job worker's next pass
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param point query string false "Hook point" Enums(post-register, password-reset, birthday)
+// @Success 200 {object} dto.HookReplayResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dead-letters/replay [post]
+func (h *HookDeliveryHandler) ReplayDeliveries(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	replayed, err := h.deliveryUseCase.ReplayAll(c.Query("point"))
+	if err != nil {
+		return deliveryError(c, "Replay failed", err)
+	}
+
+	log.Printf("%d hook deliveries replayed by user %d", replayed, claims.UserID)
+	return c.JSON(dto.HookReplayResponse{Replayed: replayed})
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/inbound"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// InboundWebhookHandler handles webhook requests from integrated providers
+type InboundWebhookHandler struct {
+	inboundUseCase *usecase.InboundWebhookUseCase
+	baseURL        string
+}
+
+// NewInboundWebhookHandler creates a new inbound webhook handler. baseURL is
+// the public scheme and host providers post to, for signatures covering the
+// URL; when empty, the request's own is used.
+func NewInboundWebhookHandler(inboundUseCase *usecase.InboundWebhookUseCase, baseURL string) *InboundWebhookHandler {
+	return &InboundWebhookHandler{inboundUseCase: inboundUseCase, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// @Summary Receive a provider's webhook
+// @Description Receive events from an integrated provider configured in INBOUND_WEBHOOK_SECRETS: sendgrid (Event Webhook, signed with ECDSA), twilio (status callbacks, signed with the auth token) or stripe (signed with the endpoint secret).
+// @Description Each event is handled once: retries and replays of an event ID already received are acknowledged without handling it again. A failing handler answers 500, so the provider retries.
+// @Tags webhooks
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param provider path string true "Provider" Enums(sendgrid, twilio, stripe)
+// @Success 200 {object} dto.InboundWebhookResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /webhooks/{provider} [post]
+func (h *InboundWebhookHandler) Receive(c *fiber.Ctx) error {
+	base := h.baseURL
+	if base == "" {
+		base = c.BaseURL()
+	}
+	req := &inbound.Request{
+		URL:    base + c.OriginalURL(),
+		Header: make(http.Header),
+		Body:   c.Body(),
+	}
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		req.Header.Add(string(key), string(value))
+	})
+
+	receipt, err := h.inboundUseCase.Receive(c.Params("provider"), req)
+	if err != nil {
+		status, title := 500, "Webhook handling failed"
+		switch {
+		case errors.Is(err, inbound.ErrUnknownProvider):
+			status, title = 404, "Unknown provider"
+		case errors.Is(err, inbound.ErrInvalidSignature), errors.Is(err, inbound.ErrExpired):
+			status, title = 401, "Invalid signature"
+		case errors.Is(err, inbound.ErrInvalidPayload):
+			status, title = 400, "Invalid payload"
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   title,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(dto.InboundWebhookResponse{Received: receipt.Received, Duplicates: receipt.Duplicates})
+}
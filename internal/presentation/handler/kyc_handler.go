@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// KYCHandler takes users' identity documents and serves them for admin review
+type KYCHandler struct {
+	kycUseCase *usecase.KYCUseCase
+	validator  *validator.Service
+	decoder    *decoder.Service
+}
+
+// NewKYCHandler creates a new KYC handler
+func NewKYCHandler(kycUseCase *usecase.KYCUseCase, validator *validator.Service, decoder *decoder.Service) *KYCHandler {
+	return &KYCHandler{
+		kycUseCase: kycUseCase,
+		validator:  validator,
+		decoder:    decoder,
+	}
+}
+
+// kycErrorStatus maps KYC errors to HTTP statuses
+func kycErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidKYCDocument), errors.Is(err, usecase.ErrKYCReasonRequired):
+		return 400
+	case errors.Is(err, usecase.ErrKYCSubmissionNotFound):
+		return 404
+	case errors.Is(err, usecase.ErrKYCInProgress), errors.Is(err, usecase.ErrKYCAlreadyVerified), errors.Is(err, usecase.ErrKYCSubmissionDecided):
+		return 409
+	default:
+		return 500
+	}
+}
+
+// toKYCSubmissionResponse converts a submission to its response DTO
+func toKYCSubmissionResponse(submission *entity.KYCSubmission) dto.KYCSubmissionResponse {
+	response := dto.KYCSubmissionResponse{
+		ID:           submission.ID,
+		UserID:       submission.UserID,
+		DocumentType: submission.DocumentType,
+		ContentType:  submission.ContentType,
+		Size:         submission.Size,
+		Status:       submission.Status,
+		Reference:    submission.Reference,
+		Reason:       submission.Reason,
+		ReviewedBy:   submission.ReviewedBy,
+		ReviewedAt:   submission.ReviewedAt,
+		CreatedAt:    submission.CreatedAt,
+		UpdatedAt:    submission.UpdatedAt,
+	}
+	if submission.User != nil {
+		response.Email = submission.User.Email
+		response.FullName = submission.User.FullName
+	}
+	return response
+}
+
+// @Summary Submit an identity document
+// @Description Upload an identity document to verify the caller's identity. The document is checked by the verification provider when KYC_PROVIDER_URL is set, and reviewed by an admin otherwise or when the provider cannot decide.
+// @Description The caller's kycStatus follows the submission. A rejected user may submit again; a verified one, or one with a submission in progress, may not.
+// @Tags user
+// @Accept mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param documentType formData string true "Document type" Enums(national_id, passport, driving_license)
+// @Param document formData file true "JPEG, PNG or PDF of at most 3MB"
+// @Success 201 {object} dto.KYCSubmissionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/kyc [post]
+func (h *KYCHandler) Submit(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	file, err := c.FormFile("document")
+	if err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: "document must be uploaded as multipart/form-data",
+		})
+	}
+	if file.Size > usecase.MaxKYCDocumentBytes {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: "document must not exceed 3MB",
+		})
+	}
+	document, err := readFormFile(file)
+	if err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+	}
+
+	submission, err := h.kycUseCase.Submit(claims.UserID, c.FormValue("documentType"), document)
+	if err != nil {
+		return c.Status(kycErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Failed to submit document",
+			Message: err.Error(),
+		})
+	}
+	return c.Status(201).JSON(toKYCSubmissionResponse(submission))
+}
+
+// @Summary Get my identity verification
+// @Description Get the caller's latest identity document submission and its status
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.KYCSubmissionResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/kyc [get]
+func (h *KYCHandler) GetStatus(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	submission, err := h.kycUseCase.Status(claims.UserID)
+	if err != nil {
+		return c.Status(kycErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Failed to get identity verification",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(toKYCSubmissionResponse(submission))
+}
+
+// @Summary List identity document submissions
+// @Description List identity document submissions by status, oldest first. Submissions in review wait for an admin: there is no verification provider, or it could not decide.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Status (default review)" Enums(review, pending, verified, rejected)
+// @Param limit query int false "Maximum number of submissions (default 50, at most 500)"
+// @Success 200 {object} dto.KYCSubmissionListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/kyc [get]
+func (h *KYCHandler) ListSubmissions(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+	status := c.Query("status", entity.KYCReview)
+	if status != entity.KYCReview && status != entity.KYCPending && status != entity.KYCVerified && status != entity.KYCRejected {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid status",
+			Message: "status must be review, pending, verified or rejected",
+		})
+	}
+
+	submissions, err := h.kycUseCase.List(status, limit)
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to list submissions",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.KYCSubmissionListResponse{Submissions: make([]dto.KYCSubmissionResponse, 0, len(submissions))}
+	for _, submission := range submissions {
+		response.Submissions = append(response.Submissions, toKYCSubmissionResponse(submission))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Download an identity document
+// @Description Download the document of a submission for review
+// @Tags admin
+// @Produce image/jpeg
+// @Produce image/png
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Submission ID"
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/kyc/{id}/document [get]
+func (h *KYCHandler) GetDocument(c *fiber.Ctx) error {
+	// Identity documents must not be kept by caches
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid submission ID",
+			Message: "submission ID must be a positive integer",
+		})
+	}
+
+	submission, document, err := h.kycUseCase.Document(id)
+	if err != nil {
+		return c.Status(kycErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Failed to get document",
+			Message: err.Error(),
+		})
+	}
+
+	extension := submission.DocumentKey[strings.LastIndex(submission.DocumentKey, ".")+1:]
+	c.Set(fiber.HeaderContentType, submission.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="kyc-%d.%s"`, submission.ID, extension))
+	return c.Send(document)
+}
+
+// @Summary Approve an identity document
+// @Description Verify the identity of the user of a pending or in review submission
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Submission ID"
+// @Success 200 {object} dto.KYCSubmissionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/kyc/{id}/approve [post]
+func (h *KYCHandler) Approve(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid submission ID",
+			Message: "submission ID must be a positive integer",
+		})
+	}
+
+	submission, err := h.kycUseCase.Approve(id, claims.UserID)
+	if err != nil {
+		return c.Status(kycErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Failed to approve submission",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("KYC submission %d of user %d approved by user %d", submission.ID, submission.UserID, claims.UserID)
+	return c.JSON(toKYCSubmissionResponse(submission))
+}
+
+// @Summary Reject an identity document
+// @Description Reject a pending or in review submission with a reason shown to the user, who may then submit another document
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Submission ID"
+// @Param rejection body dto.RejectKYCRequest true "Reason shown to the user"
+// @Success 200 {object} dto.KYCSubmissionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/kyc/{id}/reject [post]
+func (h *KYCHandler) Reject(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid submission ID",
+			Message: "submission ID must be a positive integer",
+		})
+	}
+	var req dto.RejectKYCRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	submission, err := h.kycUseCase.Reject(id, claims.UserID, req.Reason)
+	if err != nil {
+		return c.Status(kycErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Failed to reject submission",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("KYC submission %d of user %d rejected by user %d", submission.ID, submission.UserID, claims.UserID)
+	return c.JSON(toKYCSubmissionResponse(submission))
+}
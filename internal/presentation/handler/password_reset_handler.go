@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PasswordResetHandler handles requests to reset a forgotten password
+type PasswordResetHandler struct {
+	passwordResetUseCase *usecase.PasswordResetUseCase
+	validator            *validator.Service
+	decoder              *decoder.Service
+}
+
+// NewPasswordResetHandler creates a new password reset handler
+func NewPasswordResetHandler(passwordResetUseCase *usecase.PasswordResetUseCase, validator *validator.Service, decoder *decoder.Service) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		passwordResetUseCase: passwordResetUseCase,
+		validator:            validator,
+		decoder:              decoder,
+	}
+}
+
+// @Summary Request a password reset
+// @Description Send a single-use password reset token to the user through the password-reset hooks. The response is the same whether or not the email has an account.
+// @Tags user
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param request body dto.ForgotPasswordRequest true "Account email"
+// @Success 202 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /password/forgot [post]
+func (h *PasswordResetHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req dto.ForgotPasswordRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	if err := h.passwordResetUseCase.RequestReset(req.Email); err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Password reset failed",
+			Message: "Could not issue a reset token",
+		})
+	}
+
+	return c.Status(202).JSON(dto.SuccessResponse{
+		Message: "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+// @Summary Reset a forgotten password
+// @Description Set a new password with a password reset token. Each token works once; a token that was already used is rejected with 409.
+// @Tags user
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param request body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 410 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /password/reset [post]
+func (h *PasswordResetHandler) ResetPassword(c *fiber.Ctx) error {
+	var req dto.ResetPasswordRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	if err := h.passwordResetUseCase.ResetPassword(req.Token, req.NewPassword); err != nil {
+		status, message := 500, "Password reset failed"
+		switch {
+		case errors.Is(err, usecase.ErrInvalidToken):
+			status, message = 400, "Invalid token"
+		case errors.Is(err, usecase.ErrTokenAlreadyUsed):
+			status, message = 409, "Token already used"
+		case errors.Is(err, usecase.ErrTokenExpired):
+			status, message = 410, "Token expired"
+		}
+
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   message,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(dto.SuccessResponse{
+		Message: "Password reset successfully",
+	})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/payloadlog"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PayloadLogHandler handles admin requests that switch payload logging on
+// and off per route
+type PayloadLogHandler struct {
+	logger    *payloadlog.Logger
+	validator *validator.Service
+	decoder   *decoder.Service
+}
+
+// NewPayloadLogHandler creates a new payload logging handler
+func NewPayloadLogHandler(logger *payloadlog.Logger, validator *validator.Service, decoder *decoder.Service) *PayloadLogHandler {
+	return &PayloadLogHandler{
+		logger:    logger,
+		validator: validator,
+		decoder:   decoder,
+	}
+}
+
+// routesResponse converts the logged routes to their response DTO
+func (h *PayloadLogHandler) routesResponse() dto.PayloadLogRoutesResponse {
+	response := dto.PayloadLogRoutesResponse{Routes: []dto.PayloadLogRoute{}}
+	for _, route := range h.logger.Routes() {
+		response.Routes = append(response.Routes, dto.PayloadLogRoute{
+			Method: route.Method,
+			Path:   route.Path,
+			Until:  route.Until,
+		})
+	}
+	return response
+}
+
+// @Summary Get the routes whose payloads are logged
+// @Description List the routes whose request and response bodies are logged on this instance, and until when
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.PayloadLogRoutesResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/payload-logging [get]
+func (h *PayloadLogHandler) GetRoutes(c *fiber.Ctx) error {
+	return c.JSON(h.routesResponse())
+}
+
+// @Summary Log a route's payloads
+// @Description Log the request and response bodies of a route on this instance for a number of minutes, at most a day, e.g. while investigating an incident. Passwords, secrets, tokens, API keys, one-time codes and PINs are redacted, phone numbers keep their last four digits, and the fields named in PAYLOAD_LOG_REDACT are redacted too. Paths may use :param segments. Enabling a logged route again sets a new end.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.PayloadLogRequest true "Route"
+// @Success 200 {object} dto.PayloadLogRoutesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Router /admin/payload-logging [post]
+func (h *PayloadLogHandler) EnableRoute(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.PayloadLogRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	route, err := h.logger.Enable(req.Method, req.Path, time.Duration(req.Minutes)*time.Minute)
+	if err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid payload logging route",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Payload logging of %s enabled by user %d until %s", route.Name(), claims.UserID, route.Until.Format(time.RFC3339))
+	return c.JSON(h.routesResponse())
+}
+
+// @Summary Stop logging payloads
+// @Description Stop logging the payloads of the route given by method and path on this instance, or of every route without them
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param method query string false "Method of the route"
+// @Param path query string false "Path of the route, as enabled"
+// @Success 200 {object} dto.PayloadLogRoutesResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/payload-logging [delete]
+func (h *PayloadLogHandler) DisableRoute(c *fiber.Ctx) error {
+	method, path := c.Query("method"), c.Query("path")
+	if !h.logger.Disable(method, path) && (method != "" || path != "") {
+		return c.Status(404).JSON(dto.ErrorResponse{
+			Error:   "Route not logged",
+			Message: method + " " + path + " is not being logged",
+		})
+	}
+
+	if method == "" && path == "" {
+		log.Println("Payload logging disabled for every route")
+	} else {
+		log.Printf("Payload logging of %s %s disabled", method, path)
+	}
+	return c.JSON(h.routesResponse())
+}
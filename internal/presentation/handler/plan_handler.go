@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PlanHandler handles admin requests that change users' plans
+type PlanHandler struct {
+	planUseCase *usecase.PlanUseCase
+	validator   *validator.Service
+	decoder     *decoder.Service
+}
+
+// NewPlanHandler creates a new plan handler
+func NewPlanHandler(planUseCase *usecase.PlanUseCase, validator *validator.Service, decoder *decoder.Service) *PlanHandler {
+	return &PlanHandler{
+		planUseCase: planUseCase,
+		validator:   validator,
+		decoder:     decoder,
+	}
+}
+
+// @Summary Change a user's plan
+// @Description Move a user to "free" or one of the paid plans in PLAN_PRICES, e.g. for a refund or a sales deal. Stripe subscription events change plans on their own; the next one overrides this change.
+// @Description The plan claim of tokens issued before the change is corrected on protected routes, but the priority lanes go by the token until the user signs in again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body dto.UserPlanRequest true "New plan"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/plan [put]
+func (h *PlanHandler) SetUserPlan(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "user ID must be a positive integer",
+		})
+	}
+
+	var req dto.UserPlanRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	user, err := h.planUseCase.SetPlan(claims.UserID, id, req.Plan)
+	if err != nil {
+		status := 500
+		switch {
+		case errors.Is(err, usecase.ErrInvalidPlan):
+			status = 400
+		case err.Error() == "user not found":
+			status = 404
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Plan change failed",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Plan of user %d set to %s by user %d", id, user.Plan, claims.UserID)
+	return c.JSON(dto.SuccessResponse{
+		Message: "User plan changed",
+		Data:    toUserResponse(user),
+	})
+}
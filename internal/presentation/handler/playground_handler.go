@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"fiber-hello-world/internal/presentation/playground"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PlaygroundHandler serves the interactive API playground page
+type PlaygroundHandler struct{}
+
+// NewPlaygroundHandler creates a new playground handler
+func NewPlaygroundHandler() *PlaygroundHandler {
+	return &PlaygroundHandler{}
+}
+
+// Page serves the embedded playground HTML
+func (h *PlaygroundHandler) Page(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	c.Set("Cache-Control", "no-store")
+	return c.Send(playground.Page)
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PresenceHandler handles requests for users' presence
+type PresenceHandler struct {
+	presenceUseCase *usecase.PresenceUseCase
+	validator       *validator.Service
+	decoder         *decoder.Service
+}
+
+// NewPresenceHandler creates a new presence handler
+func NewPresenceHandler(presenceUseCase *usecase.PresenceUseCase, validator *validator.Service, decoder *decoder.Service) *PresenceHandler {
+	return &PresenceHandler{
+		presenceUseCase: presenceUseCase,
+		validator:       validator,
+		decoder:         decoder,
+	}
+}
+
+// @Summary Get users' presence
+// @Description Get whether users are online, recently active or away, from the last time they made an authenticated request. Each user chooses who sees what: with visibility status their last seen time is left out, and with nobody they always appear away.
+// @Description Users never seen, including unknown IDs, are away. Activity on other replicas shows within PRESENCE_FLUSH_INTERVAL.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Param ids query string true "Comma-separated user IDs, at most 100"
+// @Success 200 {object} dto.PresencesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /presence [get]
+func (h *PresenceHandler) GetPresence(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var userIDs []int
+	for _, value := range strings.Split(c.Query("ids"), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return c.Status(400).JSON(dto.ErrorResponse{
+				Error:   "Invalid user ID",
+				Message: "ids must be comma-separated positive integers",
+			})
+		}
+		userIDs = append(userIDs, id)
+	}
+	if len(userIDs) == 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user ID",
+			Message: "ids is required",
+		})
+	}
+
+	views, err := h.presenceUseCase.Get(claims.UserID, userIDs)
+	if err != nil {
+		return c.Status(presenceErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Presence failed",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.PresencesResponse{Users: make([]dto.PresenceResponse, 0, len(views))}
+	for _, view := range views {
+		response.Users = append(response.Users, toPresenceResponse(view))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Get the caller's presence
+// @Description Get the caller's own presence and who may see it
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.PresenceResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/presence [get]
+func (h *PresenceHandler) GetMyPresence(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	views, err := h.presenceUseCase.Get(claims.UserID, []int{claims.UserID})
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Presence failed",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(toPresenceResponse(views[0]))
+}
+
+// @Summary Choose who sees the caller's presence
+// @Description Set who may see the caller's presence: everyone sees the status and last seen time, status hides the last seen time, and nobody makes the caller always appear away
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.PresenceVisibilityRequest true "Visibility"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/presence [put]
+func (h *PresenceHandler) SetMyVisibility(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.PresenceVisibilityRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	if err := h.presenceUseCase.SetVisibility(claims.UserID, req.Visibility); err != nil {
+		return c.Status(presenceErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Presence visibility update failed",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(dto.SuccessResponse{Message: "Presence visibility updated"})
+}
+
+// presenceErrorStatus maps presence use case errors to HTTP statuses
+func presenceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidPresenceVisibility), errors.Is(err, usecase.ErrTooManyPresenceUsers):
+		return 400
+	}
+	return 500
+}
+
+// toPresenceResponse converts a presence view to its response
+func toPresenceResponse(view *entity.PresenceView) dto.PresenceResponse {
+	return dto.PresenceResponse{
+		UserID:     view.UserID,
+		Status:     string(view.Status),
+		LastSeenAt: view.LastSeenAt,
+		Visibility: string(view.Visibility),
+	}
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// qrLoginPollInterval is how often, in seconds, desktops are asked to poll
+const qrLoginPollInterval = 2
+
+// QRLoginHandler handles signing desktops in by approving a QR code on a
+// signed-in device
+type QRLoginHandler struct {
+	qrLoginUseCase  *usecase.QRLoginUseCase
+	funnelUseCase   *usecase.FunnelUseCase
+	securityUseCase *usecase.SecurityUseCase
+	jwtService      *jwt.Service
+	validator       *validator.Service
+	decoder         *decoder.Service
+}
+
+// NewQRLoginHandler creates a new QR login handler
+func NewQRLoginHandler(qrLoginUseCase *usecase.QRLoginUseCase, funnelUseCase *usecase.FunnelUseCase, securityUseCase *usecase.SecurityUseCase,
+	jwtService *jwt.Service, validator *validator.Service, decoder *decoder.Service) *QRLoginHandler {
+	return &QRLoginHandler{
+		qrLoginUseCase:  qrLoginUseCase,
+		funnelUseCase:   funnelUseCase,
+		securityUseCase: securityUseCase,
+		jwtService:      jwtService,
+		validator:       validator,
+		decoder:         decoder,
+	}
+}
+
+// @Summary Start a QR login
+// @Description Start signing in a desktop by QR code. Show the challenge as a QR code and keep the poll token secret;
+// @Description poll /auth/qr/status with it every pollInterval seconds until a signed-in device approves or denies the login.
+// @Tags authentication
+// @Produce json
+// @Success 201 {object} dto.QRLoginStartResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/qr/start [post]
+func (h *QRLoginHandler) Start(c *fiber.Ctx) error {
+	challenge, pollToken, login, err := h.qrLoginUseCase.Start(middleware.ClientIP(c), c.Get(fiber.HeaderUserAgent), middleware.ClientCountry(c))
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "QR login failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(dto.QRLoginStartResponse{
+		Challenge:    challenge,
+		PollToken:    pollToken,
+		ExpiresAt:    login.ExpiresAt,
+		PollInterval: qrLoginPollInterval,
+	})
+}
+
+// @Summary Poll a QR login
+// @Description Returns pending, approved, denied, expired or completed. The poll that completes an approved login
+// @Description also returns the desktop's token; it is handed out once.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.QRLoginPollRequest true "Poll token from /auth/qr/start"
+// @Success 200 {object} dto.QRLoginPollResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/qr/status [post]
+func (h *QRLoginHandler) Poll(c *fiber.Ctx) error {
+	var req dto.QRLoginPollRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	status, user, err := h.qrLoginUseCase.Poll(req.PollToken)
+	if err != nil {
+		return c.Status(qrLoginErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "QR login failed",
+			Message: err.Error(),
+		})
+	}
+	if user == nil {
+		return c.JSON(dto.QRLoginPollResponse{Status: string(status)})
+	}
+
+	token, expiresAt, err := h.jwtService.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		return c.Status(qrLoginErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Token generation failed",
+			Message: err.Error(),
+		})
+	}
+
+	if err := h.funnelUseCase.TrackFirstLogin(user.ID); err != nil {
+		log.Printf("Failed to track first login: %v", err)
+	}
+	if _, err := h.securityUseCase.RecordLogin(user.ID, middleware.ClientIP(c), c.Get(fiber.HeaderUserAgent), middleware.ClientCountry(c)); err != nil {
+		log.Printf("Failed to record login: %v", err)
+	}
+
+	userResponse := toUserResponse(user)
+	return c.JSON(dto.QRLoginPollResponse{
+		Status:    string(status),
+		Token:     token,
+		ExpiresAt: &expiresAt,
+		User:      &userResponse,
+	})
+}
+
+// @Summary Scan a QR login
+// @Description Describe the desktop whose QR code was scanned, so the user can check it before approving
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.QRLoginChallengeRequest true "Challenge from the QR code"
+// @Success 200 {object} dto.QRLoginScanResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 410 {object} dto.ErrorResponse
+// @Router /auth/qr/scan [post]
+func (h *QRLoginHandler) Scan(c *fiber.Ctx) error {
+	var req dto.QRLoginChallengeRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	login, err := h.qrLoginUseCase.Scan(req.Challenge)
+	if err != nil {
+		return c.Status(qrLoginErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "QR login failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(dto.QRLoginScanResponse{
+		IP:        login.IP,
+		Device:    login.Device,
+		Country:   login.Country,
+		CreatedAt: login.CreatedAt,
+		ExpiresAt: login.ExpiresAt,
+	})
+}
+
+// @Summary Approve a QR login
+// @Description Sign the desktop showing the QR code in as the current user
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.QRLoginChallengeRequest true "Challenge from the QR code"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 410 {object} dto.ErrorResponse
+// @Router /auth/qr/approve [post]
+func (h *QRLoginHandler) Approve(c *fiber.Ctx) error {
+	return h.decide(c, h.qrLoginUseCase.Approve, "QR login approved")
+}
+
+// @Summary Deny a QR login
+// @Description Reject the sign-in of the desktop showing the QR code
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.QRLoginChallengeRequest true "Challenge from the QR code"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 410 {object} dto.ErrorResponse
+// @Router /auth/qr/deny [post]
+func (h *QRLoginHandler) Deny(c *fiber.Ctx) error {
+	return h.decide(c, h.qrLoginUseCase.Deny, "QR login denied")
+}
+
+// decide approves or denies the login whose challenge is in the body
+func (h *QRLoginHandler) decide(c *fiber.Ctx, decide func(userID int, challenge string) error, message string) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.QRLoginChallengeRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	if err := decide(claims.UserID, req.Challenge); err != nil {
+		return c.Status(qrLoginErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "QR login failed",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(dto.SuccessResponse{Message: message})
+}
+
+// qrLoginErrorStatus maps QR login errors to HTTP statuses
+func qrLoginErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrQRLoginNotFound):
+		return 404
+	case errors.Is(err, usecase.ErrQRLoginNotPending):
+		return 409
+	case errors.Is(err, usecase.ErrQRLoginExpired):
+		return 410
+	case errors.Is(err, usecase.ErrAccountSuspended), errors.Is(err, usecase.ErrAccountInactive), errors.Is(err, usecase.ErrVetoed):
+		return 403
+	}
+	return 500
+}
@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReadModelHandler serves the admin queries backed by the read models
+type ReadModelHandler struct {
+	readModelUseCase *usecase.ReadModelUseCase
+}
+
+// NewReadModelHandler creates a new read model handler
+func NewReadModelHandler(readModelUseCase *usecase.ReadModelUseCase) *ReadModelHandler {
+	return &ReadModelHandler{
+		readModelUseCase: readModelUseCase,
+	}
+}
+
+// toUserSummaryResponse converts a user summary to its response DTO
+func toUserSummaryResponse(user *entity.UserSummary) dto.UserSummaryResponse {
+	response := dto.UserSummaryResponse{
+		ID:        user.UserID,
+		Email:     user.Email,
+		FullName:  user.FullName,
+		Role:      user.Role,
+		Status:    user.Status,
+		CreatedAt: user.CreatedAt,
+	}
+	if stats := user.LoginStats; stats != nil {
+		response.Logins = stats.Logins
+		response.PasswordLogins = stats.PasswordLogins
+		response.PasswordlessLogins = stats.PasswordlessLogins
+		response.FirstLoginAt = &stats.FirstLoginAt
+		response.LastLoginAt = &stats.LastLoginAt
+	}
+	return response
+}
+
+// userSearchFilter reads the q, role, status, createdFrom and createdTo
+// query parameters
+func userSearchFilter(c *fiber.Ctx) (repository.UserFilter, error) {
+	filter := repository.UserFilter{
+		Search: c.Query("q"),
+		Role:   c.Query("role"),
+		Status: c.Query("status"),
+	}
+	for name, dst := range map[string]*time.Time{"createdFrom": &filter.CreatedFrom, "createdTo": &filter.CreatedTo} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, errors.New(name + " must be an RFC 3339 time, e.g. 2025-01-31T00:00:00Z")
+		}
+		*dst = t
+	}
+	return filter, nil
+}
+
+// @Summary Search users
+// @Description Search users by email or name, role, status and signup time, with their login counts. Served from the user_search and login_stats read models, which trail writes by about WORKER_INTERVAL.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Case-insensitive part of the email or full name"
+// @Param role query string false "Role" Enums(user, admin)
+// @Param status query string false "Account status" Enums(pending, active, suspended, deactivated)
+// @Param createdFrom query string false "Only users created at or after this RFC 3339 time"
+// @Param createdTo query string false "Only users created before this RFC 3339 time"
+// @Param limit query int false "Maximum number of users (default 50, at most 500)"
+// @Param offset query int false "Number of users to skip"
+// @Success 200 {object} dto.UserSearchResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/search [get]
+func (h *ReadModelHandler) SearchUsers(c *fiber.Ctx) error {
+	filter, err := userSearchFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user filter",
+			Message: err.Error(),
+		})
+	}
+
+	page := repository.Page{Limit: c.QueryInt("limit"), Offset: c.QueryInt("offset")}.Normalize()
+	users, total, err := h.readModelUseCase.SearchUsers(filter, page)
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to search users",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.UserSearchResponse{
+		Users:  make([]dto.UserSummaryResponse, 0, len(users)),
+		Total:  total,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}
+	for _, user := range users {
+		response.Users = append(response.Users, toUserSummaryResponse(user))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Export users
+// @Description Download every user matching the filters, ordered by ID, as newline-delimited JSON: one UserSummaryResponse per line, flushed as it is read.
+// @Description Memory use does not grow with the number of users, so this suits lists too large for /admin/users/search. Served from the same read models.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Case-insensitive part of the email or full name"
+// @Param role query string false "Role" Enums(user, admin)
+// @Param status query string false "Account status" Enums(pending, active, suspended, deactivated)
+// @Param createdFrom query string false "Only users created at or after this RFC 3339 time"
+// @Param createdTo query string false "Only users created before this RFC 3339 time"
+// @Success 200 {string} string "Users"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/users/export [get]
+func (h *ReadModelHandler) ExportUsers(c *fiber.Ctx) error {
+	filter, err := userSearchFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid user filter",
+			Message: err.Error(),
+		})
+	}
+	// Check the read models before the response starts, while errors can
+	// still be reported
+	if _, _, err := h.readModelUseCase.SearchUsers(filter, repository.Page{Limit: 1}); err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to export users",
+			Message: err.Error(),
+		})
+	}
+
+	streamNDJSON(c, "users", "ndjson", func(write func(v interface{}) error) error {
+		return h.readModelUseCase.ExportUsers(filter, func(user *entity.UserSummary) error {
+			return write(toUserSummaryResponse(user))
+		})
+	})
+	return nil
+}
+
+// @Summary List read models
+// @Description List the read models and how far each has read the domain event log. behind counts the events not applied yet.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ProjectionsResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/read-models [get]
+func (h *ReadModelHandler) ListProjections(c *fiber.Ctx) error {
+	statuses, err := h.readModelUseCase.Statuses()
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to list read models",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.ProjectionsResponse{Projections: make([]dto.ProjectionResponse, 0, len(statuses))}
+	for _, status := range statuses {
+		projection := dto.ProjectionResponse{Name: status.Name, Position: status.Position, Behind: status.Behind}
+		if !status.UpdatedAt.IsZero() {
+			updatedAt := status.UpdatedAt
+			projection.UpdatedAt = &updatedAt
+		}
+		response.Projections = append(response.Projections, projection)
+	}
+	return c.JSON(response)
+}
+
+// @Summary Rebuild a read model
+// @Description Empty a read model so it is rebuilt from the start of the domain event log, e.g. after a change to its projection. user_search is first seeded from the users table.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Read model" Enums(user_search, login_stats)
+// @Success 202 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/read-models/{name}/rebuild [post]
+func (h *ReadModelHandler) RebuildProjection(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	name := c.Params("name")
+	if err := h.readModelUseCase.Rebuild(name); err != nil {
+		status := 500
+		if errors.Is(err, usecase.ErrUnknownProjection) {
+			status = 404
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Failed to rebuild read model",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Read model %s reset for rebuilding by user %d", name, claims.UserID)
+	return c.Status(202).JSON(dto.SuccessResponse{
+		Message: "Read model is being rebuilt",
+	})
+}
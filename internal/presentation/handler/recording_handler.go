@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/recorder"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// replayTimeout bounds how long a replayed request may run
+const replayTimeout = 30 * time.Second
+
+// RecordingHandler handles recorded requests and their replays
+type RecordingHandler struct {
+	recorder  *recorder.Recorder
+	validator *validator.Service
+	decoder   *decoder.Service
+}
+
+// NewRecordingHandler creates a new recording handler
+func NewRecordingHandler(rec *recorder.Recorder, validator *validator.Service, decoder *decoder.Service) *RecordingHandler {
+	return &RecordingHandler{
+		recorder:  rec,
+		validator: validator,
+		decoder:   decoder,
+	}
+}
+
+// toRecordingSummary converts a recording to its list entry DTO
+func toRecordingSummary(recording recorder.Recording) dto.RecordingSummary {
+	return dto.RecordingSummary{
+		ID:         recording.ID,
+		Time:       recording.Time,
+		Method:     recording.Method,
+		Path:       recording.Path,
+		Status:     recording.Status,
+		DurationMs: durationMs(recording.Duration),
+	}
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// recording looks up the recording named by the id parameter, answering
+// 400 or 404 when there is none
+func (h *RecordingHandler) recording(c *fiber.Ctx) (recorder.Recording, bool, error) {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return recorder.Recording{}, false, c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid recording ID",
+			Message: "Recording ID must be a positive integer",
+		})
+	}
+	recording, ok := h.recorder.Get(int64(id))
+	if !ok {
+		return recorder.Recording{}, false, c.Status(404).JSON(dto.ErrorResponse{
+			Error:   "Recording not found",
+			Message: "The recording does not exist or was dropped for newer ones",
+		})
+	}
+	return recording, true, nil
+}
+
+// @Summary List recorded requests
+// @Description List the recorded requests, newest first. Only the latest RECORDING_SIZE are kept.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param path query string false "Only requests whose path starts with this"
+// @Param status query int false "Only requests answered with this status"
+// @Param limit query int false "Maximum number of recordings (default 50)"
+// @Success 200 {object} dto.RecordingsResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/recordings [get]
+func (h *RecordingHandler) ListRecordings(c *fiber.Ctx) error {
+	path := c.Query("path")
+	status := c.QueryInt("status")
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > recorder.MaxSize {
+		limit = 50
+	}
+
+	response := dto.RecordingsResponse{Recordings: []dto.RecordingSummary{}}
+	for _, recording := range h.recorder.List() {
+		if len(response.Recordings) == limit {
+			break
+		}
+		if !strings.HasPrefix(recording.Path, path) || (status != 0 && recording.Status != status) {
+			continue
+		}
+		response.Recordings = append(response.Recordings, toRecordingSummary(recording))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Get a recorded request
+// @Description Get a recorded request and its response. Credentials, cookies and fields named like passwords, secrets or tokens are redacted.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Recording ID"
+// @Success 200 {object} dto.RecordingResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/recordings/{id} [get]
+func (h *RecordingHandler) GetRecording(c *fiber.Ctx) error {
+	recording, ok, err := h.recording(c)
+	if !ok {
+		return err
+	}
+
+	return c.JSON(dto.RecordingResponse{
+		RecordingSummary: toRecordingSummary(recording),
+		Query:            recording.Query,
+		Headers:          recording.Headers,
+		Body:             recording.Body,
+		Response: dto.RecordedResponse{
+			Status:  recording.Status,
+			Headers: recording.ResponseHeaders,
+			Body:    recording.ResponseBody,
+		},
+		Truncated: recording.Truncated,
+	})
+}
+
+// @Summary Replay a recorded request
+// @Description Send a recorded request again through the running code and compare the responses. Redacted values are sent as recorded, so pass credentials in headers; without an Authorization header, a request recorded with one is replayed with the caller's.
+// @Description Replays run with the full middleware and have side effects like any request.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Recording ID"
+// @Param request body dto.ReplayRequest false "Headers to set on the replay"
+// @Success 200 {object} dto.ReplayResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 502 {object} dto.ErrorResponse
+// @Router /admin/recordings/{id}/replay [post]
+func (h *RecordingHandler) Replay(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	recording, ok, err := h.recording(c)
+	if !ok {
+		return err
+	}
+
+	var req dto.ReplayRequest
+	if len(c.Body()) > 0 {
+		if err := parseBody(c, h.decoder, &req); err != nil {
+			return bodyError(c, err)
+		}
+		if err := h.validator.Validate(&req); err != nil {
+			return c.Status(400).JSON(dto.ErrorResponse{
+				Error:   "Validation failed",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	replay, err := recording.Request()
+	if err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Replay failed",
+			Message: err.Error(),
+		})
+	}
+	replay.Host = c.Hostname()
+	if _, recorded := recording.Headers[fiber.HeaderAuthorization]; recorded {
+		replay.Header.Set(fiber.HeaderAuthorization, c.Get(fiber.HeaderAuthorization))
+	}
+	for name, value := range req.Headers {
+		replay.Header.Set(name, value)
+	}
+
+	start := time.Now()
+	resp, err := c.App().Test(replay, int(replayTimeout.Milliseconds()))
+	if err != nil {
+		return c.Status(502).JSON(dto.ErrorResponse{
+			Error:   "Replay failed",
+			Message: err.Error(),
+		})
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, recorder.MaxBodyBytes))
+	if err != nil {
+		return c.Status(502).JSON(dto.ErrorResponse{
+			Error:   "Replay failed",
+			Message: err.Error(),
+		})
+	}
+	duration := time.Since(start)
+
+	headers := make(map[string]string, len(resp.Header))
+	for name, values := range resp.Header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	replayed := recorder.Sanitize(recorder.Recording{ResponseHeaders: headers, ResponseBody: string(body)})
+
+	log.Printf("Recording %d (%s %s) replayed by user %d: %d, originally %d", recording.ID, recording.Method, recording.Path,
+		claims.UserID, resp.StatusCode, recording.Status)
+	return c.JSON(dto.ReplayResponse{
+		Recording: toRecordingSummary(recording),
+		Original: dto.RecordedResponse{
+			Status:  recording.Status,
+			Headers: recording.ResponseHeaders,
+			Body:    recording.ResponseBody,
+		},
+		Replay: dto.RecordedResponse{
+			Status:  resp.StatusCode,
+			Headers: replayed.ResponseHeaders,
+			Body:    replayed.ResponseBody,
+		},
+		DurationMs:    durationMs(duration),
+		StatusMatches: resp.StatusCode == recording.Status,
+		BodyMatches:   replayed.ResponseBody == recording.ResponseBody,
+	})
+}
+
+// @Summary Clear recorded requests
+// @Description Drop the recordings kept in memory. RECORDING_FILE, if set, is left alone.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/recordings [delete]
+func (h *RecordingHandler) ClearRecordings(c *fiber.Ctx) error {
+	h.recorder.Clear()
+	return c.JSON(dto.SuccessResponse{
+		Message: "Recordings cleared",
+	})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SchemaChangeHandler handles the online schema changes of large tables
+type SchemaChangeHandler struct {
+	schemaChangeUseCase *usecase.SchemaChangeUseCase
+	validator           *validator.Service
+	decoder             *decoder.Service
+}
+
+// NewSchemaChangeHandler creates a new schema change handler
+func NewSchemaChangeHandler(schemaChangeUseCase *usecase.SchemaChangeUseCase, validator *validator.Service, decoder *decoder.Service) *SchemaChangeHandler {
+	return &SchemaChangeHandler{
+		schemaChangeUseCase: schemaChangeUseCase,
+		validator:           validator,
+		decoder:             decoder,
+	}
+}
+
+// toSchemaChangeResponse converts a schema change to its response DTO
+func toSchemaChangeResponse(change *entity.SchemaChange) dto.SchemaChangeResponse {
+	return dto.SchemaChangeResponse{
+		Name:         change.Name,
+		Phase:        change.Phase,
+		Cursor:       change.Cursor,
+		Backfilled:   change.Backfilled,
+		Total:        change.Total,
+		Progress:     change.Progress(),
+		StartedAt:    change.StartedAt,
+		UpdatedAt:    change.UpdatedAt,
+		BackfilledAt: change.BackfilledAt,
+	}
+}
+
+// @Summary List schema changes
+// @Description List the online schema changes of large tables and how far their backfill got. Rows are copied a batch of SCHEMA_BACKFILL_BATCH at a time, every WORKER_INTERVAL.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.SchemaChangesResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/schema-changes [get]
+func (h *SchemaChangeHandler) ListChanges(c *fiber.Ctx) error {
+	changes, err := h.schemaChangeUseCase.List()
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to list schema changes",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.SchemaChangesResponse{Changes: make([]dto.SchemaChangeResponse, 0, len(changes))}
+	for _, change := range changes {
+		response.Changes = append(response.Changes, toSchemaChangeResponse(change))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Switch the reads of a schema change
+// @Description Read a backfilled schema change's new column or table, or the old one again. Writes go to both until a later release drops the old one, so reads can be switched back. Other instances follow within WORKER_INTERVAL.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Schema change"
+// @Param request body dto.SwitchSchemaReadsRequest true "Reads"
+// @Success 200 {object} dto.SchemaChangeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/schema-changes/{name}/reads [put]
+func (h *SchemaChangeHandler) SwitchReads(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.SwitchSchemaReadsRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	change, err := h.schemaChangeUseCase.SwitchReads(c.Params("name"), req.Reads == "new")
+	if err != nil {
+		status := 500
+		switch {
+		case errors.Is(err, usecase.ErrSchemaChangeNotFound):
+			status = 404
+		case errors.Is(err, usecase.ErrSchemaChangeBackfilling):
+			status = 409
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Failed to switch schema change reads",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Schema change %s reads the %s schema, switched by user %d", change.Name, req.Reads, claims.UserID)
+	return c.JSON(toSchemaChangeResponse(change))
+}
@@ -0,0 +1,358 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/scim"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ScimHandler handles SCIM 2.0 provisioning requests from identity providers
+type ScimHandler struct {
+	provisioningUseCase *usecase.ProvisioningUseCase
+	decoder             *decoder.Service
+}
+
+// NewScimHandler creates a new SCIM handler
+func NewScimHandler(provisioningUseCase *usecase.ProvisioningUseCase, decoder *decoder.Service) *ScimHandler {
+	return &ScimHandler{
+		provisioningUseCase: provisioningUseCase,
+		decoder:             decoder,
+	}
+}
+
+// scimError writes a SCIM error response
+func scimError(c *fiber.Ctx, status int, scimType, detail string) error {
+	return c.Status(status).JSON(dto.ScimErrorResponse{
+		Schemas:  []string{scim.SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}, scim.MediaType)
+}
+
+// parseScimBody checks the body with the shared decoder's limits, then binds it
+// leniently: identity providers send many attributes the service does not store
+func parseScimBody(c *fiber.Ctx, bodyDecoder *decoder.Service, dst interface{}) error {
+	var raw interface{}
+	if err := bodyDecoder.Decode(c.Get(fiber.HeaderContentType), c.Body(), &raw); err != nil {
+		status := 400
+		if errors.Is(err, decoder.ErrUnsupportedMediaType) {
+			status = 415
+		} else if errors.Is(err, decoder.ErrBodyTooLarge) {
+			status = 413
+		}
+		return scimError(c, status, scim.ErrorInvalidSyntax, err.Error())
+	}
+	if err := json.Unmarshal(c.Body(), dst); err != nil {
+		return scimError(c, 400, scim.ErrorInvalidSyntax, err.Error())
+	}
+	return nil
+}
+
+// toScimUser converts a user entity to its SCIM representation
+func toScimUser(user *entity.User) dto.ScimUserResponse {
+	id := strconv.Itoa(user.ID)
+	resource := dto.ScimUserResponse{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          id,
+		UserName:    user.Email,
+		Name:        dto.ScimName{Formatted: user.FullName},
+		DisplayName: user.FullName,
+		Active:      user.CanSignIn(),
+		Emails:      []dto.ScimMultiValue{{Value: user.Email, Type: "work", Primary: true}},
+		Meta: dto.ScimMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt.UTC().Format(time.RFC3339),
+			LastModified: user.UpdatedAt.UTC().Format(time.RFC3339),
+			Location:     "/scim/v2/Users/" + id,
+			Version:      fmt.Sprintf(`W/"%d"`, user.Version),
+		},
+	}
+	if user.PhoneNumber != "" {
+		resource.PhoneNumbers = []dto.ScimMultiValue{{Value: user.PhoneNumber, Type: "work"}}
+	}
+	return resource
+}
+
+// scimUserID parses the :id path parameter
+func scimUserID(c *fiber.Ctx) (int, bool) {
+	id, err := strconv.Atoi(c.Params("id"))
+	return id, err == nil && id > 0
+}
+
+// @Summary List users (SCIM)
+// @Description Lists users for identity providers. Supports `eq` filters on userName, emails.value and active joined with `and`, and 1-based startIndex/count paging.
+// @Tags scim
+// @Produce json
+// @Param filter query string false "SCIM filter, e.g. userName eq \"jane@example.com\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Maximum number of results"
+// @Success 200 {object} dto.ScimListResponse
+// @Failure 400 {object} dto.ScimErrorResponse
+// @Failure 401 {object} dto.ScimErrorResponse
+// @Failure 500 {object} dto.ScimErrorResponse
+// @Security BearerAuth
+// @Router /scim/v2/Users [get]
+func (h *ScimHandler) ListUsers(c *fiber.Ctx) error {
+	comparisons, err := scim.ParseFilter(c.Query("filter"))
+	if err != nil {
+		return scimError(c, 400, scim.ErrorInvalidFilter, err.Error())
+	}
+
+	var filter usecase.DirectoryFilter
+	for _, comparison := range comparisons {
+		switch comparison.Attribute {
+		case "username", "emails.value", "emails":
+			email, ok := comparison.Value.(string)
+			if !ok || (filter.Email != "" && filter.Email != email) {
+				return scimError(c, 400, scim.ErrorInvalidFilter, comparison.Attribute+" must be compared with a single string")
+			}
+			filter.Email = email
+		case "active":
+			active, ok := comparison.Value.(bool)
+			if !ok {
+				return scimError(c, 400, scim.ErrorInvalidFilter, "active must be compared with true or false")
+			}
+			filter.Active = &active
+		default:
+			return scimError(c, 400, scim.ErrorInvalidFilter, "filtering on "+comparison.Attribute+" is not supported")
+		}
+	}
+
+	startIndex := c.QueryInt("startIndex", 1)
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	page := repository.Page{Limit: c.QueryInt("count", repository.DefaultPageLimit), Offset: startIndex - 1}.Normalize()
+
+	users, total, err := h.provisioningUseCase.ListUsers(filter, page)
+	if err != nil {
+		return scimError(c, 500, "", err.Error())
+	}
+
+	resources := make([]dto.ScimUserResponse, 0, len(users))
+	for _, user := range users {
+		resources = append(resources, toScimUser(user))
+	}
+
+	return c.JSON(dto.ScimListResponse{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, scim.MediaType)
+}
+
+// @Summary Get user (SCIM)
+// @Description Returns a single user as a SCIM resource
+// @Tags scim
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.ScimUserResponse
+// @Failure 401 {object} dto.ScimErrorResponse
+// @Failure 404 {object} dto.ScimErrorResponse
+// @Security BearerAuth
+// @Router /scim/v2/Users/{id} [get]
+func (h *ScimHandler) GetUser(c *fiber.Ctx) error {
+	id, ok := scimUserID(c)
+	if !ok {
+		return scimError(c, 404, "", "User not found")
+	}
+
+	user, err := h.provisioningUseCase.GetUser(id)
+	if err != nil {
+		return scimError(c, 404, "", "User not found")
+	}
+
+	return c.JSON(toScimUser(user), scim.MediaType)
+}
+
+// @Summary Create user (SCIM)
+// @Description Provisions a user from an identity provider. userName must be the user's email address. Without a password the user must reset it before signing in with one.
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param user body dto.ScimUserRequest true "SCIM user"
+// @Success 201 {object} dto.ScimUserResponse
+// @Failure 400 {object} dto.ScimErrorResponse
+// @Failure 401 {object} dto.ScimErrorResponse
+// @Failure 409 {object} dto.ScimErrorResponse
+// @Failure 415 {object} dto.ScimErrorResponse
+// @Failure 500 {object} dto.ScimErrorResponse
+// @Security BearerAuth
+// @Router /scim/v2/Users [post]
+func (h *ScimHandler) CreateUser(c *fiber.Ctx) error {
+	var req dto.ScimUserRequest
+	if err := parseScimBody(c, h.decoder, &req); err != nil {
+		return err
+	}
+
+	input := usecase.DirectoryUser{
+		Email:    req.UserName,
+		FullName: scimFullName(req),
+		Password: req.Password,
+		Active:   req.Active == nil || *req.Active,
+	}
+	if len(req.PhoneNumbers) > 0 {
+		input.PhoneNumber = req.PhoneNumbers[0].Value
+	}
+
+	user, err := h.provisioningUseCase.ProvisionUser(input)
+	if err != nil {
+		if errors.Is(err, usecase.ErrEmailTaken) {
+			return scimError(c, 409, scim.ErrorUniqueness, "A user with this userName already exists")
+		} else if errors.Is(err, usecase.ErrInvalidDirectoryUser) {
+			return scimError(c, 400, scim.ErrorInvalidValue, err.Error())
+		}
+		return scimError(c, 500, "", err.Error())
+	}
+
+	resource := toScimUser(user)
+	c.Location(resource.Meta.Location)
+	return c.Status(201).JSON(resource, scim.MediaType)
+}
+
+// scimFullName picks the best available full name from a SCIM user
+func scimFullName(req dto.ScimUserRequest) string {
+	if req.Name != nil {
+		if req.Name.Formatted != "" {
+			return req.Name.Formatted
+		}
+		if joined := strings.TrimSpace(req.Name.GivenName + " " + req.Name.FamilyName); joined != "" {
+			return joined
+		}
+	}
+	return req.DisplayName
+}
+
+// @Summary Update user (SCIM)
+// @Description Applies a SCIM PatchOp. Supported attributes are userName, displayName, name.formatted, phoneNumbers and active; others are ignored. Setting active to false suspends the user, which is how users are deprovisioned.
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param patch body dto.ScimPatchRequest true "SCIM PatchOp"
+// @Success 200 {object} dto.ScimUserResponse
+// @Failure 400 {object} dto.ScimErrorResponse
+// @Failure 401 {object} dto.ScimErrorResponse
+// @Failure 404 {object} dto.ScimErrorResponse
+// @Failure 409 {object} dto.ScimErrorResponse
+// @Failure 415 {object} dto.ScimErrorResponse
+// @Failure 500 {object} dto.ScimErrorResponse
+// @Security BearerAuth
+// @Router /scim/v2/Users/{id} [patch]
+func (h *ScimHandler) PatchUser(c *fiber.Ctx) error {
+	id, ok := scimUserID(c)
+	if !ok {
+		return scimError(c, 404, "", "User not found")
+	}
+
+	var req dto.ScimPatchRequest
+	if err := parseScimBody(c, h.decoder, &req); err != nil {
+		return err
+	}
+
+	changes, err := scim.Flatten(req.Operations)
+	if err != nil {
+		return scimError(c, 400, scim.ErrorInvalidSyntax, err.Error())
+	}
+	userChanges, err := toDirectoryChanges(changes)
+	if err != nil {
+		return scimError(c, 400, scim.ErrorInvalidValue, err.Error())
+	}
+
+	user, err := h.provisioningUseCase.UpdateUser(id, userChanges)
+	if err != nil {
+		if errors.Is(err, usecase.ErrEmailTaken) {
+			return scimError(c, 409, scim.ErrorUniqueness, "A user with this userName already exists")
+		} else if errors.Is(err, usecase.ErrInvalidDirectoryUser) {
+			return scimError(c, 400, scim.ErrorInvalidValue, err.Error())
+		} else if err.Error() == "user not found" {
+			return scimError(c, 404, "", "User not found")
+		}
+		return scimError(c, 500, "", err.Error())
+	}
+
+	return c.JSON(toScimUser(user), scim.MediaType)
+}
+
+// toDirectoryChanges maps normalized SCIM changes onto the stored user
+// attributes. Attributes the service does not store are ignored so that
+// identity providers can sync their full schema.
+func toDirectoryChanges(changes []scim.Change) (usecase.DirectoryUserChanges, error) {
+	var result usecase.DirectoryUserChanges
+	for _, change := range changes {
+		switch change.Attribute {
+		case "active":
+			active, err := scimBool(change.Value)
+			if err != nil || change.Op == scim.OpRemove {
+				return result, errors.New("active must be true or false")
+			}
+			result.Active = &active
+		case "username":
+			email, ok := change.Value.(string)
+			if !ok || change.Op == scim.OpRemove {
+				return result, errors.New("userName must be a string")
+			}
+			result.Email = &email
+		case "displayname", "name.formatted":
+			name, ok := change.Value.(string)
+			if !ok || change.Op == scim.OpRemove {
+				return result, errors.New(change.Attribute + " must be a string")
+			}
+			result.FullName = &name
+		case "phonenumbers", "phonenumbers.value":
+			phone, err := scimPhoneNumber(change)
+			if err != nil {
+				return result, err
+			}
+			result.PhoneNumber = &phone
+		}
+	}
+	return result, nil
+}
+
+// scimBool accepts JSON booleans and the "True"/"False" strings Azure AD sends
+func scimBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	}
+	return false, errors.New("not a boolean")
+}
+
+// scimPhoneNumber extracts the phone number from a phoneNumbers change
+func scimPhoneNumber(change scim.Change) (string, error) {
+	if change.Op == scim.OpRemove {
+		return "", nil
+	}
+	switch v := change.Value.(type) {
+	case string:
+		return v, nil
+	case []interface{}:
+		if len(v) == 0 {
+			return "", nil
+		}
+		if entry, ok := v[0].(map[string]interface{}); ok {
+			if phone, ok := entry["value"].(string); ok {
+				return phone, nil
+			}
+		}
+	}
+	return "", errors.New("phoneNumbers must contain a value")
+}
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ShareLinkHandler handles links that share profile fields without signing in
+type ShareLinkHandler struct {
+	shareLinkUseCase *usecase.ShareLinkUseCase
+	validator        *validator.Service
+	decoder          *decoder.Service
+}
+
+// NewShareLinkHandler creates a new share link handler
+func NewShareLinkHandler(shareLinkUseCase *usecase.ShareLinkUseCase, validator *validator.Service, decoder *decoder.Service) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		shareLinkUseCase: shareLinkUseCase,
+		validator:        validator,
+		decoder:          decoder,
+	}
+}
+
+// toShareLinkResponse converts a share link to its response DTO
+func toShareLinkResponse(link *entity.ShareLink, now time.Time) dto.ShareLinkResponse {
+	return dto.ShareLinkResponse{
+		ID:        link.ID,
+		Fields:    link.Fields,
+		Status:    string(link.StatusAt(now)),
+		MaxViews:  link.MaxViews,
+		Views:     link.Views,
+		ExpiresAt: link.ExpiresAt,
+		RevokedAt: link.RevokedAt,
+		CreatedAt: link.CreatedAt,
+	}
+}
+
+// @Summary Create a share link
+// @Description Share some profile fields (email, fullName, phoneNumber, birthday, avatar) through a link that works without signing in.
+// @Description Links last expiresIn seconds (default a day, at most 30 days) and open maxViews times (default once, at most 100).
+// @Description The token is only returned here.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateShareLinkRequest true "Fields to share"
+// @Success 201 {object} dto.CreatedShareLinkResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/share-links [post]
+func (h *ShareLinkHandler) CreateShareLink(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.CreateShareLinkRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	token, link, err := h.shareLinkUseCase.Create(claims.UserID, req.Fields, time.Duration(req.ExpiresIn)*time.Second, req.MaxViews)
+	if err != nil {
+		return c.Status(shareLinkErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Share link failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(dto.CreatedShareLinkResponse{
+		ShareLinkResponse: toShareLinkResponse(link, time.Now()),
+		Token:             token,
+		URL:               c.BaseURL() + "/share/" + token,
+	})
+}
+
+// @Summary List share links
+// @Description List the caller's share links, newest first, with their status: active, expired, revoked or used
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ShareLinksResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/share-links [get]
+func (h *ShareLinkHandler) ListShareLinks(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	links, err := h.shareLinkUseCase.List(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Share links failed",
+			Message: err.Error(),
+		})
+	}
+
+	now := time.Now()
+	response := dto.ShareLinksResponse{Links: make([]dto.ShareLinkResponse, 0, len(links))}
+	for _, link := range links {
+		response.Links = append(response.Links, toShareLinkResponse(link, now))
+	}
+	return c.JSON(response)
+}
+
+// @Summary Revoke a share link
+// @Description Stop one of the caller's share links from opening. Revoking a link twice succeeds.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Share link ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/share-links/{id} [delete]
+func (h *ShareLinkHandler) RevokeShareLink(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid share link ID",
+			Message: "share link ID must be a positive integer",
+		})
+	}
+
+	if err := h.shareLinkUseCase.Revoke(claims.UserID, id); err != nil {
+		return c.Status(shareLinkErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Share link revocation failed",
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(dto.SuccessResponse{Message: "Share link revoked"})
+}
+
+// @Summary List share link accesses
+// @Description List every attempt to open one of the caller's share links, newest first, including attempts after it expired, was revoked or was used up
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Share link ID"
+// @Success 200 {object} dto.ShareLinkAccessesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/share-links/{id}/accesses [get]
+func (h *ShareLinkHandler) ListAccesses(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid share link ID",
+			Message: "share link ID must be a positive integer",
+		})
+	}
+
+	accesses, err := h.shareLinkUseCase.Accesses(claims.UserID, id)
+	if err != nil {
+		return c.Status(shareLinkErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Share link accesses failed",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.ShareLinkAccessesResponse{
+		LinkID:   id,
+		Accesses: make([]dto.ShareLinkAccessResponse, 0, len(accesses)),
+	}
+	for _, access := range accesses {
+		response.Accesses = append(response.Accesses, dto.ShareLinkAccessResponse{
+			IP:         access.IP,
+			Device:     access.Device,
+			Country:    access.Country,
+			Granted:    access.Granted,
+			AccessedAt: access.AccessedAt,
+		})
+	}
+	return c.JSON(response)
+}
+
+// @Summary Open a share link
+// @Description Get the profile fields shared by a link. No token is needed; each call counts as a view and is logged for the link's owner.
+// @Tags user
+// @Produce json
+// @Param token path string true "Share link token"
+// @Success 200 {object} dto.SharedProfileResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 410 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /share/{token} [get]
+func (h *ShareLinkHandler) OpenShareLink(c *fiber.Ctx) error {
+	// Shared data must not outlive the link in caches
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	profile, err := h.shareLinkUseCase.Open(c.Params("token"), middleware.ClientIP(c), c.Get(fiber.HeaderUserAgent), middleware.ClientCountry(c))
+	if err != nil {
+		return c.Status(shareLinkErrorStatus(err)).JSON(dto.ErrorResponse{
+			Error:   "Share link failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(dto.SharedProfileResponse{
+		Email:       profile.Email,
+		FullName:    profile.FullName,
+		PhoneNumber: profile.PhoneNumber,
+		Birthday:    profile.Birthday,
+		AvatarURL:   profile.Avatar,
+	})
+}
+
+// shareLinkErrorStatus maps share link errors to HTTP statuses
+func shareLinkErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidShareLink):
+		return 400
+	case errors.Is(err, usecase.ErrAccountSuspended), errors.Is(err, usecase.ErrAccountInactive):
+		return 403
+	case errors.Is(err, usecase.ErrShareLinkNotFound):
+		return 404
+	case errors.Is(err, usecase.ErrShareLinkUnavailable):
+		return 410
+	}
+	return 500
+}
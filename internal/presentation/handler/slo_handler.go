@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"bytes"
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/slo"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SLOHandler reports the routes' error budgets and switches load shedding
+type SLOHandler struct {
+	tracker   *slo.Tracker
+	validator *validator.Service
+	decoder   *decoder.Service
+}
+
+// NewSLOHandler creates a new SLO handler
+func NewSLOHandler(tracker *slo.Tracker, validator *validator.Service, decoder *decoder.Service) *SLOHandler {
+	return &SLOHandler{
+		tracker:   tracker,
+		validator: validator,
+		decoder:   decoder,
+	}
+}
+
+// reportResponse converts the tracker's report to its response DTO
+func (h *SLOHandler) reportResponse(report *slo.Report) dto.SLOReportResponse {
+	response := dto.SLOReportResponse{
+		Window:     slo.FormatWindow(report.Window),
+		Objectives: make([]dto.SLOObjectiveResponse, 0, len(report.Objectives)),
+		LoadShedding: dto.LoadSheddingResponse{
+			Enabled: report.LoadShedding.Enabled,
+			Active:  report.LoadShedding.Active,
+		},
+	}
+	if !report.LoadShedding.Since.IsZero() {
+		since := report.LoadShedding.Since
+		response.LoadShedding.Since = &since
+	}
+	for _, objective := range report.Objectives {
+		item := dto.SLOObjectiveResponse{
+			Route:           objective.Objective.Route(),
+			Target:          objective.Objective.Target,
+			LatencyMs:       int64(objective.Objective.Latency / time.Millisecond),
+			Requests:        objective.Requests,
+			Errors:          objective.Errors,
+			Slow:            objective.Slow,
+			Compliance:      objective.Compliance,
+			BudgetRemaining: objective.BudgetRemaining,
+			BurnRates:       make([]dto.BurnRateResponse, 0, len(objective.BurnRates)),
+			Exhausted:       objective.Exhausted,
+		}
+		for _, rate := range objective.BurnRates {
+			item.BurnRates = append(item.BurnRates, dto.BurnRateResponse{
+				Window: slo.FormatWindow(rate.Window),
+				Rate:   rate.Rate,
+			})
+		}
+		response.Objectives = append(response.Objectives, item)
+	}
+	return response
+}
+
+// @Summary Get SLO report
+// @Description Report each route in SLO_OBJECTIVES against its objective over SLO_WINDOW: requests, failed and slow requests, the share of the error budget left and the burn rates over the last 5 minutes and hour, with the load shedding state.
+// @Description With format=prometheus the report is returned as gauges in the Prometheus text format, for burn rate alerts.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Produce plain
+// @Security BearerAuth
+// @Param format query string false "Response format" Enums(json, prometheus)
+// @Success 200 {object} dto.SLOReportResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/slo [get]
+func (h *SLOHandler) GetReport(c *fiber.Ctx) error {
+	format := c.Query("format", "json")
+	if format != "json" && format != "prometheus" {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid format",
+			Message: "format must be json or prometheus",
+		})
+	}
+
+	report := h.tracker.Report()
+	if format == "prometheus" {
+		var buf bytes.Buffer
+		if err := report.WritePrometheus(&buf); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		return c.Send(buf.Bytes())
+	}
+	return c.JSON(h.reportResponse(report))
+}
+
+// @Summary Switch load shedding
+// @Description Allow or stop load shedding. While allowed and a route's error budget is exhausted and still burning, requests for routes without an objective are answered 503 with Retry-After. /admin routes are never shed. The switch is kept in memory, per instance, and starts from SLO_LOAD_SHEDDING.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.LoadSheddingRequest true "Load shedding switch"
+// @Success 200 {object} dto.SLOReportResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Router /admin/slo/load-shedding [put]
+func (h *SLOHandler) SetLoadShedding(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.LoadSheddingRequest
+	if err := h.decoder.Decode(c.Get(fiber.HeaderContentType), c.Body(), &req); err != nil {
+		return bodyError(c, err)
+	}
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	h.tracker.SetLoadShedding(*req.Enabled)
+	log.Printf("Load shedding enabled=%v by user %d", *req.Enabled, claims.UserID)
+	return c.JSON(h.reportResponse(h.tracker.Report()))
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/presentation/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// streamNDJSON sends a download of newline-delimited JSON, one value per
+// line, named <name>-<time>.<ext>. each is called once the headers are sent
+// and passes the values to write, which flushes every line to the client,
+// so rows reach it as they are read and none are held in memory. A failure
+// midway can only cut the download short, so it is logged. The request's
+// lane slot is held until the download ends.
+func streamNDJSON(c *fiber.Ctx, name, ext string, each func(write func(v interface{}) error) error) {
+	filename := name + "-" + time.Now().UTC().Format("20060102-150405") + "." + ext
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+
+	release := middleware.HoldLane(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer release()
+		encoder := json.NewEncoder(w)
+		write := func(v interface{}) error {
+			if err := encoder.Encode(v); err != nil {
+				return err
+			}
+			// Fails once the client has gone, which stops the export
+			return w.Flush()
+		}
+		if err := each(write); err != nil {
+			log.Printf("Streaming %s failed: %v", filename, err)
+		}
+	})
+}
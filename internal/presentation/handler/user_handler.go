@@ -1,9 +1,17 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"log"
+
+	"fiber-hello-world/internal/domain/entity"
 	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/presentation/middleware"
 	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/decoder"
 	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/screening"
 	"fiber-hello-world/pkg/validator"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,39 +19,79 @@ import (
 
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
-	userUseCase *usecase.UserUseCase
-	jwtService  *jwt.Service
-	validator   *validator.Service
+	userUseCase     *usecase.UserUseCase
+	avatarUseCase   *usecase.AvatarUseCase
+	funnelUseCase   *usecase.FunnelUseCase
+	securityUseCase *usecase.SecurityUseCase
+	jwtService      *jwt.Service
+	validator       *validator.Service
+	decoder         *decoder.Service
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userUseCase *usecase.UserUseCase, jwtService *jwt.Service, validator *validator.Service) *UserHandler {
+func NewUserHandler(userUseCase *usecase.UserUseCase, avatarUseCase *usecase.AvatarUseCase, funnelUseCase *usecase.FunnelUseCase, securityUseCase *usecase.SecurityUseCase, jwtService *jwt.Service, validator *validator.Service, decoder *decoder.Service) *UserHandler {
 	return &UserHandler{
-		userUseCase: userUseCase,
-		jwtService:  jwtService,
-		validator:   validator,
+		userUseCase:     userUseCase,
+		avatarUseCase:   avatarUseCase,
+		funnelUseCase:   funnelUseCase,
+		securityUseCase: securityUseCase,
+		jwtService:      jwtService,
+		validator:       validator,
+		decoder:         decoder,
+	}
+}
+
+// toUserResponse converts a user entity to its response DTO
+func toUserResponse(user *entity.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:          user.ID,
+		Email:       user.Email,
+		FullName:    user.FullName,
+		PhoneNumber: user.PhoneNumber,
+		Birthday:    user.Birthday,
+		AvatarURL:   user.Avatar,
+		Plan:        user.Plan,
+		Timezone:    user.Timezone,
+		NationalID:  user.NationalID,
+		KYCStatus:   user.KYCStatus,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+}
+
+// trackFunnel records a funnel stage without failing the request on error
+func (h *UserHandler) trackFunnel(stage entity.FunnelStage, userID int) {
+	if err := h.funnelUseCase.Track(stage, userID); err != nil {
+		log.Printf("Failed to track funnel stage %s: %v", stage, err)
 	}
 }
 
 // @Summary Register a new user
-// @Description Register a new user with email, password, full name, phone number, and birthday
+// @Description Register a new user with email, password, full name, phone number, and birthday.
+// @Description Also accepts application/x-www-form-urlencoded and multipart/form-data bodies; multipart requests may include an optional "avatar" image file.
 // @Tags authentication
 // @Accept json
+// @Accept x-www-form-urlencoded
+// @Accept mpfd
 // @Produce json
+// @Description With ENUMERATION_PROTECTION set, new and already registered emails both get 202 without user data.
 // @Param user body dto.RegisterRequest true "User registration information"
 // @Success 201 {object} dto.SuccessResponse
+// @Success 202 {object} dto.SuccessResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 409 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /register [post]
 func (h *UserHandler) Register(c *fiber.Ctx) error {
+	h.trackFunnel(entity.FunnelStageStarted, 0)
+
 	// Parse request body
 	var req dto.RegisterRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(dto.ErrorResponse{
-			Error:   "Invalid request body",
-			Message: err.Error(),
-		})
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
 	}
 
 	// Validate input
@@ -54,57 +102,116 @@ func (h *UserHandler) Register(c *fiber.Ctx) error {
 		})
 	}
 
+	// Read optional avatar from multipart bodies
+	var avatar []byte
+	if file, err := c.FormFile("avatar"); err == nil {
+		if file.Size > usecase.MaxAvatarBytes {
+			return c.Status(400).JSON(dto.ErrorResponse{
+				Error:   "Validation failed",
+				Message: "avatar must not exceed 2MB",
+			})
+		}
+		avatar, err = readFormFile(file)
+		if err != nil {
+			return c.Status(400).JSON(dto.ErrorResponse{
+				Error:   "Invalid request body",
+				Message: err.Error(),
+			})
+		}
+		if _, err := h.avatarUseCase.ValidateAvatar(avatar); err != nil {
+			return c.Status(400).JSON(dto.ErrorResponse{
+				Error:   "Validation failed",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	h.trackFunnel(entity.FunnelStageValidated, 0)
+
 	// Register user
 	user, err := h.userUseCase.RegisterUser(req.Email, req.Password, req.FullName, req.PhoneNumber, req.Birthday)
+	if errors.Is(err, usecase.ErrEmailTaken) && h.userUseCase.EnumerationProtected() {
+		return registrationAccepted(c)
+	}
 	if err != nil {
 		status := 500
-		if err.Error() == "user with this email already exists" {
+		if errors.Is(err, usecase.ErrEmailTaken) {
 			status = 409
-		} else if err.Error() == "invalid birthday format, should be YYYY-MM-DD" {
+		} else if errors.Is(err, usecase.ErrVetoed) {
+			status = 403
+		} else if errors.Is(err, usecase.ErrNameRejected) || errors.Is(err, usecase.ErrInvalidProfile) ||
+			err.Error() == "invalid birthday format, should be YYYY-MM-DD" {
 			status = 400
 		}
 
 		return c.Status(status).JSON(dto.ErrorResponse{
 			Error:   "Registration failed",
 			Message: err.Error(),
+			Code:    rejectionCode(err),
 		})
 	}
 
-	// Convert to response DTO
-	userResponse := dto.UserResponse{
-		ID:          user.ID,
-		Email:       user.Email,
-		FullName:    user.FullName,
-		PhoneNumber: user.PhoneNumber,
-		Birthday:    user.Birthday,
-		CreatedAt:   user.CreatedAt,
+	h.trackFunnel(entity.FunnelStageCreated, user.ID)
+
+	// The account already exists at this point, so an avatar failure is logged rather than returned
+	if avatar != nil {
+		if withAvatar, err := h.avatarUseCase.SetAvatar(user.ID, avatar); err != nil {
+			log.Printf("Failed to set avatar for user %d: %v", user.ID, err)
+		} else {
+			user = withAvatar
+		}
+	}
+
+	if h.userUseCase.EnumerationProtected() {
+		return registrationAccepted(c)
 	}
 
+	// Convert to response DTO
+	userResponse := toUserResponse(user)
+
 	return c.Status(201).JSON(dto.SuccessResponse{
 		Message: "User registered successfully",
 		Data:    userResponse,
 	})
 }
 
+// registrationAccepted answers a registration the same way whether the
+// email was new or already registered
+func registrationAccepted(c *fiber.Ctx) error {
+	return c.Status(202).JSON(dto.SuccessResponse{
+		Message: "Registration received. If this email was not registered yet, you can now sign in",
+	})
+}
+
+// rejectionCode returns the code of a rejected name, or "" for other errors
+func rejectionCode(err error) string {
+	var rejection *screening.Rejection
+	if errors.As(err, &rejection) {
+		return string(rejection.Code)
+	}
+	return ""
+}
+
 // @Summary User login
 // @Description Authenticate user with email and password, returns JWT token
 // @Tags authentication
 // @Accept json
+// @Accept x-www-form-urlencoded
 // @Produce json
 // @Param credentials body dto.LoginRequest true "User login credentials"
 // @Success 200 {object} dto.LoginResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /login [post]
 func (h *UserHandler) Login(c *fiber.Ctx) error {
 	// Parse request body
 	var req dto.LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(dto.ErrorResponse{
-			Error:   "Invalid request body",
-			Message: err.Error(),
-		})
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
 	}
 
 	// Validate input
@@ -118,7 +225,14 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 	// Authenticate user
 	user, err := h.userUseCase.AuthenticateUser(req.Email, req.Password)
 	if err != nil {
-		return c.Status(401).JSON(dto.ErrorResponse{
+		if err := h.securityUseCase.RecordFailedLogin(req.Email, middleware.ClientIP(c), c.Get(fiber.HeaderUserAgent), middleware.ClientCountry(c)); err != nil {
+			log.Printf("Failed to record failed login: %v", err)
+		}
+		status := 401
+		if errors.Is(err, usecase.ErrAccountSuspended) || errors.Is(err, usecase.ErrAccountInactive) || errors.Is(err, usecase.ErrVetoed) {
+			status = 403
+		}
+		return c.Status(status).JSON(dto.ErrorResponse{
 			Error:   "Authentication failed",
 			Message: err.Error(),
 		})
@@ -126,6 +240,12 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 
 	// Generate JWT token
 	token, expiresAt, err := h.jwtService.GenerateToken(user.ID, user.Email)
+	if errors.Is(err, usecase.ErrVetoed) {
+		return c.Status(403).JSON(dto.ErrorResponse{
+			Error:   "Authentication failed",
+			Message: err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(500).JSON(dto.ErrorResponse{
 			Error:   "Token generation failed",
@@ -133,16 +253,16 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	// Convert to response DTO
-	userResponse := dto.UserResponse{
-		ID:          user.ID,
-		Email:       user.Email,
-		FullName:    user.FullName,
-		PhoneNumber: user.PhoneNumber,
-		Birthday:    user.Birthday,
-		CreatedAt:   user.CreatedAt,
+	if err := h.funnelUseCase.TrackFirstLogin(user.ID); err != nil {
+		log.Printf("Failed to track first login: %v", err)
+	}
+	if _, err := h.securityUseCase.RecordLogin(user.ID, middleware.ClientIP(c), c.Get(fiber.HeaderUserAgent), middleware.ClientCountry(c)); err != nil {
+		log.Printf("Failed to record login: %v", err)
 	}
 
+	// Convert to response DTO
+	userResponse := toUserResponse(user)
+
 	return c.JSON(dto.LoginResponse{
 		Message:   "Login successful",
 		Token:     token,
@@ -181,17 +301,212 @@ func (h *UserHandler) GetMe(c *fiber.Ctx) error {
 	}
 
 	// Convert to response DTO
-	userResponse := dto.UserResponse{
-		ID:          user.ID,
-		Email:       user.Email,
-		FullName:    user.FullName,
-		PhoneNumber: user.PhoneNumber,
-		Birthday:    user.Birthday,
-		CreatedAt:   user.CreatedAt,
-	}
+	userResponse := toUserResponse(user)
 
 	return c.JSON(dto.SuccessResponse{
 		Message: "User information retrieved successfully",
 		Data:    userResponse,
 	})
 }
+
+// @Summary Get the current user's security report
+// @Description Summarizes the last 30 days of sign-ins: recent logins, logins from new devices,
+// @Description failed attempts and logins from countries the account had not been used from before
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.SecurityReportResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/security/report [get]
+func (h *UserHandler) GetSecurityReport(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	report, err := h.securityUseCase.Report(claims.UserID)
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Failed to build security report",
+			Message: err.Error(),
+		})
+	}
+
+	// Convert to response DTO
+	return c.JSON(dto.SecurityReportResponse{
+		Since:            report.Since,
+		Until:            report.Until,
+		SuccessfulLogins: report.SuccessfulLogins,
+		FailedAttempts:   report.FailedAttempts,
+		Countries:        report.Countries,
+		RecentLogins:     toLoginEventResponses(report.RecentLogins),
+		NewDevices:       toLoginEventResponses(report.NewDevices),
+		RecentFailures:   toLoginEventResponses(report.RecentFailures),
+		GeoAnomalies:     toLoginEventResponses(report.GeoAnomalies),
+	})
+}
+
+// toLoginEventResponses converts login events to their response DTOs
+func toLoginEventResponses(events []*entity.LoginEvent) []dto.LoginEventResponse {
+	responses := make([]dto.LoginEventResponse, len(events))
+	for i, event := range events {
+		responses[i] = dto.LoginEventResponse{
+			IP:         event.IP,
+			Device:     event.Device,
+			Country:    event.Country,
+			NewDevice:  event.NewDevice,
+			NewCountry: event.NewCountry,
+			CreatedAt:  event.CreatedAt,
+		}
+	}
+	return responses
+}
+
+// @Summary Update current user profile
+// @Description Partially update the current user's profile using JSON Merge Patch (RFC 7396).
+// @Description Only the fields present in the body are changed; null removes a field, which is only allowed for avatar, timezone and nationalId.
+// @Description The email cannot be changed.
+// @Tags user
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param patch body dto.PatchMeRequest true "Fields to change"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me [patch]
+func (h *UserHandler) PatchMe(c *fiber.Ctx) error {
+	// Get user claims from middleware
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	// Decode generically so absent fields can be told apart from null ones
+	var raw interface{}
+	if err := h.decoder.Decode(c.Get(fiber.HeaderContentType), c.Body(), &raw); err != nil {
+		return bodyError(c, err)
+	}
+
+	patch, err := toMergePatch(raw)
+	if err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+	}
+
+	user, err := h.userUseCase.PatchUser(claims.UserID, patch)
+	if err != nil {
+		status := 500
+		if errors.Is(err, usecase.ErrInvalidPatch) || errors.Is(err, usecase.ErrNameRejected) {
+			status = 400
+		} else if err.Error() == "user not found" {
+			status = 404
+		}
+
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Update failed",
+			Message: err.Error(),
+			Code:    rejectionCode(err),
+		})
+	}
+
+	return c.JSON(dto.SuccessResponse{
+		Message: "User updated successfully",
+		Data:    toUserResponse(user),
+	})
+}
+
+// @Summary Change current user password
+// @Description Change the current user's password after verifying the current one
+// @Tags user
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security BearerAuth
+// @Param passwords body dto.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/password [put]
+func (h *UserHandler) ChangePassword(c *fiber.Ctx) error {
+	// Get user claims from middleware
+	claims, ok := c.Locals("user").(*jwt.Claims)
+	if !ok {
+		return c.Status(401).JSON(dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "Invalid token claims",
+		})
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := parseBody(c, h.decoder, &req); err != nil {
+		return bodyError(c, err)
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		return c.Status(400).JSON(dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	if err := h.userUseCase.ChangePassword(claims.UserID, req.CurrentPassword, req.NewPassword); err != nil {
+		status := 500
+		switch err.Error() {
+		case "invalid credentials":
+			status = 403
+		case "user not found":
+			status = 404
+		}
+
+		return c.Status(status).JSON(dto.ErrorResponse{
+			Error:   "Password change failed",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(dto.SuccessResponse{
+		Message: "Password changed successfully",
+	})
+}
+
+// toMergePatch converts a decoded merge patch document into string values,
+// with nil marking members explicitly set to null
+func toMergePatch(raw interface{}) (map[string]*string, error) {
+	members, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("merge patch must be a JSON object")
+	}
+
+	patch := make(map[string]*string, len(members))
+	for name, value := range members {
+		switch v := value.(type) {
+		case nil:
+			patch[name] = nil
+		case string:
+			patch[name] = &v
+		default:
+			return nil, fmt.Errorf("field %q must be a string or null", name)
+		}
+	}
+	return patch, nil
+}
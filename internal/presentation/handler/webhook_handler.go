@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebhookHandler handles admin requests for webhook delivery logs
+type WebhookHandler struct {
+	webhookUseCase *usecase.WebhookUseCase
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookUseCase *usecase.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{webhookUseCase: webhookUseCase}
+}
+
+// @Summary List a webhook's deliveries
+// @Description List the events most recently sent to the webhook at a hook point, newest first, each with the timeline of its attempts: response code, error and duration, oldest first.
+// @Description Every attempt at an event carries the same event ID in X-Event-Id and the body's id, so receivers dedupe retries on it. An event is delivered once an attempt succeeded.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Hook point of the webhook, as in HOOK_WEBHOOKS" Enums(pre-register, post-register, pre-login, post-login, pre-token-issue, password-reset, birthday)
+// @Param eventId query string false "Only this event"
+// @Param limit query int false "Maximum number of events (default 50, at most 500)"
+// @Success 200 {object} dto.WebhookDeliveryListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	deliveries, err := h.webhookUseCase.Deliveries(c.Params("id"), c.Query("eventId"), limit)
+	if errors.Is(err, usecase.ErrWebhookNotFound) {
+		return c.Status(404).JSON(dto.ErrorResponse{
+			Error:   "Webhook not found",
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(dto.ErrorResponse{
+			Error:   "Delivery listing failed",
+			Message: err.Error(),
+		})
+	}
+
+	response := dto.WebhookDeliveryListResponse{
+		Webhook:    c.Params("id"),
+		Deliveries: make([]dto.WebhookDeliveryResponse, 0, len(deliveries)),
+	}
+	for _, delivery := range deliveries {
+		item := dto.WebhookDeliveryResponse{
+			EventID:  delivery.EventID,
+			UserID:   delivery.UserID,
+			Status:   "failed",
+			Attempts: make([]dto.WebhookAttemptResponse, 0, len(delivery.Attempts)),
+		}
+		if delivery.Delivered {
+			item.Status = "delivered"
+		}
+		for _, attempt := range delivery.Attempts {
+			item.Attempts = append(item.Attempts, dto.WebhookAttemptResponse{
+				StatusCode: attempt.StatusCode,
+				Error:      attempt.Error,
+				DurationMs: attempt.Duration.Milliseconds(),
+				At:         attempt.CreatedAt,
+			})
+		}
+		response.Deliveries = append(response.Deliveries, item)
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminMiddleware restricts access to users whose stored role is admin, or
+// whom one of grants lets through, e.g. during a break-glass session. The
+// role is read from the user on every request, so demotions apply at once.
+// It must run after JWTMiddleware.
+func AdminMiddleware(userUseCase *usecase.UserUseCase, grants ...func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(*jwt.Claims)
+		if !ok {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "Unauthorized",
+				"message": "Invalid token claims",
+			})
+		}
+
+		if !isAdmin(userUseCase, claims.UserID) && !granted(c, grants) {
+			return c.Status(403).JSON(fiber.Map{
+				"error":   "Forbidden",
+				"message": "Admin access required",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// isAdmin reports whether the user with id has the admin role
+func isAdmin(userUseCase *usecase.UserUseCase, id int) bool {
+	user, err := userUseCase.GetUserByID(id)
+	return err == nil && user.Role == entity.RoleAdmin
+}
+
+// granted reports whether one of grants lets the caller through
+func granted(c *fiber.Ctx, grants []func(c *fiber.Ctx) bool) bool {
+	for _, grant := range grants {
+		if grant(c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/admission"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// admissionRetryAfter is the Retry-After, in seconds, of requests rejected
+// by AdmissionMiddleware; overload usually passes within seconds
+const admissionRetryAfter = "5"
+
+// AdmissionMiddleware rejects requests with 503 while the load signals are
+// over the controller's limits, lowest priority first, before they take a
+// database connection or a hashing slot
+func AdmissionMiddleware(controller *admission.Controller) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		decision := controller.Admit(controller.Priority(c.Method(), c.Path()))
+		if decision.Admitted {
+			return c.Next()
+		}
+
+		c.Set(fiber.HeaderRetryAfter, admissionRetryAfter)
+		return c.Status(fiber.StatusServiceUnavailable).JSON(dto.ErrorResponse{
+			Error:   "Service overloaded",
+			Message: "The service is busy, please retry later",
+		})
+	}
+}
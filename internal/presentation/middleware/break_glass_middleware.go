@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"log"
+
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BreakGlassGrant returns an AdminMiddleware grant letting through callers
+// who hold an open break-glass session
+func BreakGlassGrant(breakGlassUseCase *usecase.BreakGlassUseCase) func(c *fiber.Ctx) bool {
+	return func(c *fiber.Ctx) bool {
+		claims, ok := c.Locals("user").(*jwt.Claims)
+		if !ok {
+			return false
+		}
+		allowed, err := breakGlassUseCase.Allowed(claims.UserID)
+		if err != nil {
+			log.Printf("Failed to check the break-glass session of user %d: %v", claims.UserID, err)
+			return false
+		}
+		return allowed
+	}
+}
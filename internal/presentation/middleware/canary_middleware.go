@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/canary"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CanaryHandler serves the route of rule through alternate for the rule's
+// cohort and through stable for everyone else. Users are told apart by
+// their bearer token and anonymous clients by IP; flag cohorts follow the
+// users' entitlements, so anonymous clients stay on stable. Responses name
+// their variant in X-Canary-Variant and are counted in router.
+func CanaryHandler(router *canary.Router, rule canary.Rule, stable, alternate fiber.Handler, jwtService *jwt.Service, entitlementsUseCase *usecase.EntitlementsUseCase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		variant := canaryVariant(c, rule, jwtService, entitlementsUseCase)
+		handler := stable
+		if variant == canary.Canary {
+			handler = alternate
+		}
+		c.Set(canary.HeaderVariant, string(variant))
+
+		// Answer errors here, so the status is counted as sent
+		if err := handler(c); err != nil {
+			if err := c.App().Config().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+		router.Record(rule, variant, c.Response().StatusCode())
+		return nil
+	}
+}
+
+// canaryVariant picks the variant of rule's route that serves the caller
+func canaryVariant(c *fiber.Ctx, rule canary.Rule, jwtService *jwt.Service, entitlementsUseCase *usecase.EntitlementsUseCase) canary.Variant {
+	userID := 0
+	if claims, ok := c.Locals("user").(*jwt.Claims); ok {
+		userID = claims.UserID
+	} else if token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok && token != "" {
+		if claims, err := jwtService.ValidateToken(token); err == nil {
+			userID = claims.UserID
+		}
+	}
+
+	if rule.Flag != "" {
+		if userID == 0 || entitlementsUseCase == nil {
+			return canary.Stable
+		}
+		// The claims module keeps the plan current without a database read
+		plan := ""
+		if derived, ok := c.Locals("claims").(*entity.DerivedClaims); ok {
+			plan = derived.Plan
+		}
+		set, err := entitlementsUseCase.Resolve(userID, plan)
+		if err != nil {
+			log.Printf("Canary of %s served stable to user %d: %v", rule.Route(), userID, err)
+			return canary.Stable
+		}
+		if set.Has(rule.Flag) {
+			return canary.Canary
+		}
+		return canary.Stable
+	}
+
+	key := "ip:" + ClientIP(c)
+	if userID != 0 {
+		key = "user:" + strconv.Itoa(userID)
+	}
+	if rule.Includes(key) {
+		return canary.Canary
+	}
+	return canary.Stable
+}
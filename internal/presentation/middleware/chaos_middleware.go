@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/chaos"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderChaosFault marks responses failed on purpose by ChaosMiddleware
+const HeaderChaosFault = "X-Chaos-Fault"
+
+// ChaosMiddleware injects the faults picked by injector: latency, error
+// responses or dropped connections. Paths under exempt are never touched,
+// so the rules can always be changed back.
+func ChaosMiddleware(injector *chaos.Injector, exempt string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if strings.HasPrefix(c.Path(), exempt) {
+			return c.Next()
+		}
+		rule := injector.Pick(c.Method(), c.Path())
+		if rule == nil {
+			return c.Next()
+		}
+
+		if rule.Latency > 0 {
+			timer := time.NewTimer(rule.Latency)
+			select {
+			case <-timer.C:
+			case <-c.Context().Done():
+				// Server shutting down
+				timer.Stop()
+			}
+		}
+
+		switch {
+		case rule.Drop:
+			c.Context().HijackSetNoResponse(true)
+			c.Context().Hijack(func(conn net.Conn) {
+				conn.Close()
+			})
+			return nil
+		case rule.Status != 0:
+			c.Set(HeaderChaosFault, "error")
+			return c.Status(rule.Status).JSON(dto.ErrorResponse{
+				Error:   "Injected fault",
+				Message: "This failure was injected for resilience testing",
+			})
+		}
+		c.Set(HeaderChaosFault, "latency")
+		return c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClaimsMiddleware checks authenticated callers against their current role,
+// status and plan, which are cached so requests do not read the user. Tokens
+// of deleted users are refused, as are those of users who are not active,
+// and the role and plan claims are replaced by the current ones. The derived claims are
+// stored in c.Locals("claims"). It must run after JWTMiddleware.
+func ClaimsMiddleware(claimsUseCase *usecase.ClaimsUseCase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(*jwt.Claims)
+		if !ok {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "Unauthorized",
+				"message": "Invalid token claims",
+			})
+		}
+
+		derived, err := claimsUseCase.Resolve(claims.UserID)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "Unauthorized",
+				"message": "User not found",
+			})
+		}
+		if !derived.CanSignIn() {
+			return c.Status(403).JSON(fiber.Map{
+				"error":   "Forbidden",
+				"message": "Account is " + derived.Status,
+			})
+		}
+
+		if claims.Extra == nil {
+			claims.Extra = make(map[string]interface{})
+		}
+		claims.Extra["role"] = derived.Role
+		claims.Extra["plan"] = derived.Plan
+		c.Locals("claims", derived)
+		return c.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/mtls"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientCertMiddleware authenticates internal callers by their verified TLS
+// client certificate. A certificate identity mapped to a service account is
+// resolved to that account's user and stored as the request claims, so the
+// following JWTMiddleware lets the request through without a bearer token.
+// Requests without a mapped certificate fall through to JWT authentication.
+func ClientCertMiddleware(mapper *mtls.Mapper, userUseCase *usecase.UserUseCase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		state := c.Context().TLSConnectionState()
+		if state == nil || len(state.VerifiedChains) == 0 {
+			return c.Next()
+		}
+
+		account, identity, ok := mapper.Resolve(state.PeerCertificates[0])
+		if !ok {
+			return c.Next()
+		}
+
+		user, err := userUseCase.GetUserByEmail(account)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "Unauthorized",
+				"message": "Service account not found",
+			})
+		}
+		if !user.CanSignIn() {
+			return c.Status(403).JSON(fiber.Map{
+				"error":   "Forbidden",
+				"message": "Service account is " + user.Status,
+			})
+		}
+
+		c.Locals("user", &jwt.Claims{
+			UserID: user.ID,
+			Email:  user.Email,
+			Extra: map[string]interface{}{
+				"auth_method":   "mtls",
+				"cert_identity": identity,
+			},
+		})
+		return c.Next()
+	}
+}
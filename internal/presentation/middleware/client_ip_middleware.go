@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"strings"
+
+	"fiber-hello-world/pkg/clientip"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientIPMiddleware resolves the real client IP once per request, honoring
+// forwarding headers only from trusted proxies. Handlers read it with ClientIP.
+// When countryHeader is set (e.g. CF-IPCountry), the country a trusted proxy
+// geolocated the client to is kept too; read it with ClientCountry.
+func ClientIPMiddleware(resolver *clientip.Resolver, countryHeader string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		remoteIP := c.Context().RemoteIP().String()
+		ip := resolver.Resolve(remoteIP, c.Get(clientip.HeaderForwardedFor), c.Get(clientip.HeaderRealIP))
+		c.Locals("clientIP", ip)
+		if countryHeader != "" && resolver.Trusts(remoteIP) {
+			c.Locals("clientCountry", strings.ToUpper(strings.TrimSpace(c.Get(countryHeader))))
+		}
+		return c.Next()
+	}
+}
+
+// ClientIP returns the IP resolved by ClientIPMiddleware, or the peer address
+// when the middleware did not run. Use it instead of c.IP() so rate limits,
+// audit records and geo lookups all see the same address.
+func ClientIP(c *fiber.Ctx) string {
+	if ip, ok := c.Locals("clientIP").(string); ok {
+		return ip
+	}
+	return c.Context().RemoteIP().String()
+}
+
+// ClientCountry returns the client's country code as set by a trusted proxy,
+// or "" when unknown
+func ClientCountry(c *fiber.Ctx) string {
+	country, _ := c.Locals("clientCountry").(string)
+	return country
+}
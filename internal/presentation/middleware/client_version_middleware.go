@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/clientversion"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientVersionMiddleware turns away apps older than their platform's
+// minimum version, named by their X-Client-Version header, with 426 Upgrade
+// Required. Requests without the header pass. Paths under exempt are never
+// checked, so the minimums can always be changed back.
+func ClientVersionMiddleware(clientVersionUseCase *usecase.ClientVersionUseCase, exempt string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if strings.HasPrefix(c.Path(), exempt) {
+			return c.Next()
+		}
+
+		err := clientVersionUseCase.Check(c.Get(clientversion.Header))
+		if err == nil {
+			return c.Next()
+		}
+
+		var outdated *usecase.OutdatedClientError
+		switch {
+		case errors.As(err, &outdated):
+			return c.Status(fiber.StatusUpgradeRequired).JSON(dto.UpgradeRequiredResponse{
+				Error:          "Upgrade required",
+				Message:        "This version of the app is no longer supported, please update it",
+				Platform:       outdated.Policy.Platform,
+				CurrentVersion: outdated.Version,
+				MinimumVersion: outdated.Policy.MinVersion,
+				UpdateURL:      outdated.Policy.UpdateURL,
+			})
+		case errors.Is(err, usecase.ErrInvalidClientVersion):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Error:   "Invalid client version",
+				Message: err.Error(),
+			})
+		}
+		return err
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"fiber-hello-world/pkg/deprecation"
+	"fiber-hello-world/pkg/signature"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxClientLength bounds the client names counted for deprecated routes
+const maxClientLength = 64
+
+// DeprecationMiddleware announces the deprecation of the routes in registry
+// with the Deprecation, Sunset and Link headers and a "warning" field in
+// JSON object responses, and counts their calls per client. Routes past
+// their sunset answer 410 Gone without running.
+func DeprecationMiddleware(registry *deprecation.Registry) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		policy, ok := registry.Match(c.Method(), c.Path())
+		if !ok {
+			return c.Next()
+		}
+
+		registry.Record(policy, DeprecationClient(c))
+		for name, value := range policy.Headers() {
+			c.Set(name, value)
+		}
+
+		if policy.SunsetAt(time.Now()) {
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{
+				"error":   "Gone",
+				"message": policy.Route() + " was removed on " + policy.Sunset.UTC().Format(time.DateOnly),
+				"warning": policy.Warning(),
+			})
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		addWarning(c, policy.Warning())
+		return nil
+	}
+}
+
+// DeprecationClient names the caller of a deprecated route: its X-Client-ID,
+// or else the product of its User-Agent, such as "okhttp" or "Mozilla"
+func DeprecationClient(c *fiber.Ctx) string {
+	client := c.Get(signature.HeaderClientID)
+	if client == "" {
+		client, _, _ = strings.Cut(c.Get(fiber.HeaderUserAgent), "/")
+	}
+	client = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return -1
+	}, client)
+	if len(client) > maxClientLength {
+		client = client[:maxClientLength]
+	}
+	if client == "" {
+		return "unknown"
+	}
+	return client
+}
+
+// addWarning adds a "warning" field to a JSON object response that has none
+func addWarning(c *fiber.Ctx, warning string) {
+	if !strings.HasPrefix(string(c.Response().Header.ContentType()), fiber.MIMEApplicationJSON) {
+		return
+	}
+	body := bytes.TrimSpace(c.Response().Body())
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil || fields == nil {
+		return
+	}
+	if _, exists := fields["warning"]; exists {
+		return
+	}
+
+	value, err := json.Marshal(warning)
+	if err != nil {
+		return
+	}
+	// Splice the field in rather than re-encode, keeping the field order
+	out := make([]byte, 0, len(body)+len(value)+12)
+	out = append(out, `{"warning":`...)
+	out = append(out, value...)
+	if len(fields) > 0 {
+		out = append(out, ',')
+	}
+	out = append(out, body[1:]...)
+	c.Response().SetBodyRaw(out)
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/diagnostics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DiagnosticsMiddleware records the requests failing with a 5xx status in
+// collector's error summary, under their method and route
+func DiagnosticsMiddleware(collector *diagnostics.Collector) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		status := c.Response().StatusCode()
+		if err != nil {
+			// The error handler has not answered yet
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+		if status < fiber.StatusInternalServerError {
+			return err
+		}
+
+		source := c.Method() + " " + c.Route().Path
+		if err != nil {
+			collector.RecordError(source, err)
+			return err
+		}
+		collector.RecordError(source, errors.New(responseError(c, status)))
+		return nil
+	}
+}
+
+// responseError describes a failed response from its ErrorResponse body,
+// or its status when it has none
+func responseError(c *fiber.Ctx, status int) string {
+	var body dto.ErrorResponse
+	if json.Unmarshal(c.Response().Body(), &body) != nil || body.Error == "" {
+		return http.StatusText(status)
+	}
+	if body.Message == "" {
+		return body.Error
+	}
+	return body.Error + ": " + body.Message
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"log"
+
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FraudMiddleware refuses the sensitive routes, given as "METHOD /path", to
+// callers flagged for fraud review until an admin clears them. Other routes
+// are not checked. It must run after JWTMiddleware.
+func FraudMiddleware(fraudUseCase *usecase.FraudUseCase, routes []string) fiber.Handler {
+	sensitive := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		sensitive[route] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		if !sensitive[c.Method()+" "+c.Path()] {
+			return c.Next()
+		}
+		claims, ok := c.Locals("user").(*jwt.Claims)
+		if !ok {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "Unauthorized",
+				"message": "Invalid token claims",
+			})
+		}
+
+		restricted, err := fraudUseCase.Restricted(claims.UserID)
+		if err != nil {
+			log.Printf("Failed to check the fraud review of user %d: %v", claims.UserID, err)
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "Internal Server Error",
+				"message": "Failed to check the account",
+			})
+		}
+		if restricted {
+			return c.Status(403).JSON(fiber.Map{
+				"error":   "Forbidden",
+				"message": "Account is under review; this action is unavailable until it is cleared",
+			})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"fiber-hello-world/pkg/autoscale"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// InFlightMiddleware counts the requests being served, for the autoscaling
+// signals
+func InFlightMiddleware(signals *autoscale.Signals) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		defer signals.Begin()()
+		return c.Next()
+	}
+}
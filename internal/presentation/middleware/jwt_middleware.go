@@ -8,9 +8,14 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-// JWTMiddleware validates JWT tokens in requests
+// JWTMiddleware validates JWT tokens in requests. Requests already
+// authenticated by ClientCertMiddleware are passed through.
 func JWTMiddleware(jwtService *jwt.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("user").(*jwt.Claims); ok {
+			return c.Next()
+		}
+
 		// Get Authorization header
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
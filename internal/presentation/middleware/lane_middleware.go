@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"strings"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/lanes"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// laneRetryAfter is the Retry-After, in seconds, of requests rejected by
+// LaneMiddleware; a lane frees up as soon as its requests finish
+const laneRetryAfter = "2"
+
+// LaneMiddleware serves each request in its lane, rejecting it with 503
+// while the lane is full. Requests with a bearer token that validates are
+// in the paid lane when its plan claim is a paid plan and in the
+// authenticated lane otherwise; the JWT middleware still checks it for
+// protected routes.
+func LaneMiddleware(l *lanes.Lanes, jwtService *jwt.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authenticated, paid := false, false
+		if token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok && token != "" {
+			claims, err := jwtService.ValidateToken(token)
+			if authenticated = err == nil; authenticated {
+				plan, _ := claims.Extra["plan"].(string)
+				paid = entity.IsPaidPlan(plan)
+			}
+		}
+
+		slot, ok := l.Acquire(lanes.Classify(c.Path(), authenticated, paid))
+		if !ok {
+			c.Set(fiber.HeaderRetryAfter, laneRetryAfter)
+			return c.Status(fiber.StatusServiceUnavailable).JSON(dto.ErrorResponse{
+				Error:   "Service overloaded",
+				Message: "Too many requests of this kind are in progress, please retry later",
+			})
+		}
+		defer slot.Done()
+		c.Locals("laneSlot", slot)
+		return c.Next()
+	}
+}
+
+// HoldLane keeps the request's lane slot taken after the handler returns,
+// for a response streamed by a body stream writer, and returns the function
+// the writer calls once it is done. Without LaneMiddleware it does nothing.
+func HoldLane(c *fiber.Ctx) func() {
+	slot, _ := c.Locals("laneSlot").(*lanes.Slot)
+	return slot.Hold()
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"fiber-hello-world/pkg/payloadlog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PayloadLogMiddleware logs the request and response bodies of the routes
+// switched on in logger, redacted. Other requests only pay for the lookup.
+func PayloadLogMiddleware(logger *payloadlog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !logger.Match(c.Method(), c.Path()) {
+			return c.Next()
+		}
+
+		start := time.Now()
+		entry := payloadlog.Entry{
+			Method:      strings.Clone(c.Method()),
+			Path:        strings.Clone(c.Path()),
+			RequestType: strings.Clone(c.Get(fiber.HeaderContentType)),
+			RequestBody: string(c.Body()),
+		}
+
+		// Answer errors here, so the response is logged as sent
+		if err := c.Next(); err != nil {
+			if err := c.App().Config().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+
+		entry.Status = c.Response().StatusCode()
+		entry.ResponseType = string(c.Response().Header.ContentType())
+		if c.Response().IsBodyStream() {
+			entry.ResponseBody = "[streamed]"
+		} else {
+			entry.ResponseBody = string(c.Response().Body())
+		}
+		entry.Duration = time.Since(start)
+		logger.Log(entry)
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PermissionMiddleware requires the caller to have relation to the object of
+// objectType whose ID is the route parameter param, e.g.
+// PermissionMiddleware(authz, "editor", "document", "id") on /documents/:id.
+// It must run after JWTMiddleware. It fails closed when the authorization
+// service cannot answer, and denies requests without the parameter.
+func PermissionMiddleware(authz *usecase.AuthorizationUseCase, relation, objectType, param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(*jwt.Claims)
+		if !ok {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "Unauthorized",
+				"message": "Invalid token claims",
+			})
+		}
+
+		allowed, err := authz.CheckPermission(claims.UserID, relation, objectType+":"+c.Params(param))
+		if err != nil && !errors.Is(err, usecase.ErrInvalidPermissionCheck) {
+			log.Printf("Permission check failed for user %d: %v", claims.UserID, err)
+			return c.Status(503).JSON(fiber.Map{
+				"error":   "Service Unavailable",
+				"message": "Permission check failed",
+			})
+		}
+		if err != nil || !allowed {
+			return c.Status(403).JSON(fiber.Map{
+				"error":   "Forbidden",
+				"message": "Permission denied",
+			})
+		}
+
+		return c.Next()
+	}
+}
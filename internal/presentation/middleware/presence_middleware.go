@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PresenceMiddleware marks authenticated callers as seen. The time is only
+// buffered, so it adds no database write to the request. It must run after
+// JWTMiddleware.
+func PresenceMiddleware(presenceUseCase *usecase.PresenceUseCase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if claims, ok := c.Locals("user").(*jwt.Claims); ok {
+			presenceUseCase.Seen(claims.UserID)
+		}
+		return c.Next()
+	}
+}
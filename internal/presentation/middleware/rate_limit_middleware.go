@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimitMiddleware limits the calls to the routes of limiter's rules per
+// client, answering 429 once a client is over the limit. Every response of
+// those routes carries the rate limit headers, and JSON object responses get
+// a "warning" field once the client used most of its limit. Clients are
+// counted by user when their bearer token validates and by IP otherwise.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, jwtService *jwt.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		index, ok := limiter.Match(c.Method(), c.Path())
+		if !ok {
+			return c.Next()
+		}
+
+		return applyRateLimit(c, limiter.Allow(index, rateLimitClient(c, jwtService)), c.Next)
+	}
+}
+
+// rateLimitClient names the caller counted against rate limits: the user
+// when their bearer token validates and the IP otherwise
+func rateLimitClient(c *fiber.Ctx, jwtService *jwt.Service) string {
+	if token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok && token != "" {
+		if claims, err := jwtService.ValidateToken(token); err == nil {
+			return "user:" + strconv.Itoa(claims.UserID)
+		}
+	}
+	return "ip:" + ClientIP(c)
+}
+
+// applyRateLimit sets the rate limit headers of usage and answers 429 when
+// the client is over the limit. Otherwise it calls next and warns clients
+// that used most of their limit.
+func applyRateLimit(c *fiber.Ctx, usage ratelimit.Usage, next func() error) error {
+	resetIn := int(time.Until(usage.Reset).Seconds() + 0.999)
+	c.Set(ratelimit.HeaderXRateLimitLimit, strconv.Itoa(usage.Limit))
+	c.Set(ratelimit.HeaderXRateLimitRemaining, strconv.Itoa(usage.Remaining))
+	c.Set(ratelimit.HeaderXRateLimitReset, strconv.FormatInt(usage.Reset.Unix(), 10))
+	c.Set(ratelimit.HeaderRateLimitLimit, strconv.Itoa(usage.Limit))
+	c.Set(ratelimit.HeaderRateLimitRemaining, strconv.Itoa(usage.Remaining))
+	c.Set(ratelimit.HeaderRateLimitReset, strconv.Itoa(resetIn))
+	c.Set(ratelimit.HeaderRateLimitPolicy, fmt.Sprintf("%d;w=%d", usage.Limit, int(usage.Window.Seconds())))
+
+	if !usage.Allowed {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(resetIn))
+		return c.Status(fiber.StatusTooManyRequests).JSON(dto.ErrorResponse{
+			Error:   "Too many requests",
+			Message: fmt.Sprintf("At most %d requests are allowed every %d seconds, please retry in %d seconds", usage.Limit, int(usage.Window.Seconds()), resetIn),
+		})
+	}
+
+	if err := next(); err != nil {
+		return err
+	}
+	if usage.Warn() {
+		addWarning(c, fmt.Sprintf("%d of %d requests left until the rate limit resets in %d seconds", usage.Remaining, usage.Limit, resetIn))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"fiber-hello-world/pkg/recorder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RecordingMiddleware records every request and its response, with secrets
+// redacted, so they can be inspected and replayed. Paths under exempt and
+// replayed requests are not recorded.
+func RecordingMiddleware(rec *recorder.Recorder, exempt string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if strings.HasPrefix(c.Path(), exempt) || c.Get(recorder.HeaderReplayOf) != "" {
+			return c.Next()
+		}
+
+		start := time.Now()
+		recording := recorder.Recording{
+			Time:    start.UTC(),
+			Method:  strings.Clone(c.Method()),
+			Path:    strings.Clone(c.Path()),
+			Query:   string(c.Request().URI().QueryString()),
+			Headers: joinHeaders(c.GetReqHeaders()),
+			Body:    string(c.Body()),
+		}
+
+		// Answer errors here, so the response is recorded as sent
+		if err := c.Next(); err != nil {
+			if err := c.App().Config().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+
+		recording.Status = c.Response().StatusCode()
+		recording.ResponseHeaders = joinHeaders(c.GetRespHeaders())
+		if c.Response().IsBodyStream() {
+			recording.ResponseBody = "[streamed]"
+		} else {
+			recording.ResponseBody = string(c.Response().Body())
+		}
+		recording.Duration = time.Since(start)
+		if _, err := rec.Record(recording); err != nil {
+			log.Printf("Failed to record %s %s: %v", recording.Method, recording.Path, err)
+		}
+		return nil
+	}
+}
+
+// joinHeaders flattens repeated headers into one comma-separated value.
+// Fiber's strings point into buffers reused after the request, so the
+// names and values are copied.
+func joinHeaders(headers map[string][]string) map[string]string {
+	joined := make(map[string]string, len(headers))
+	for name, values := range headers {
+		joined[strings.Clone(name)] = strings.Clone(strings.Join(values, ", "))
+	}
+	return joined
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/routepolicy"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RoutePolicyMiddleware enforces the policy of the group each request's path
+// belongs to, in order: CORS headers, answering preflight requests itself,
+// then the group's rate limit, then its roles and scopes. Roles are read
+// from the caller's current user and scopes from the space-separated
+// "scope" claim of their bearer token, e.g. one added by a token issuance
+// hook.
+func RoutePolicyMiddleware(policy *routepolicy.Policy, jwtService *jwt.Service, userUseCase *usecase.UserUseCase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		index, ok := policy.Match(c.Path())
+		if !ok {
+			return c.Next()
+		}
+		group := policy.Group(index)
+
+		if group.CORS != nil {
+			if preflight := c.Method() == fiber.MethodOptions && c.Get(fiber.HeaderAccessControlRequestMethod) != ""; preflight {
+				return answerPreflight(c, group.CORS)
+			}
+			setCORSHeaders(c, group.CORS)
+		}
+
+		next := func() error {
+			if !group.Authenticated() {
+				return c.Next()
+			}
+			return authorizeRoute(c, group, jwtService, userUseCase)
+		}
+		if usage, ok := policy.Allow(index, rateLimitClient(c, jwtService)); ok {
+			return applyRateLimit(c, usage, next)
+		}
+		return next()
+	}
+}
+
+// setCORSHeaders lets a page on an allowed origin read the response
+func setCORSHeaders(c *fiber.Ctx, cors *routepolicy.CORS) {
+	c.Vary(fiber.HeaderOrigin)
+	origin := c.Get(fiber.HeaderOrigin)
+	if !cors.AllowsOrigin(origin) {
+		return
+	}
+	c.Set(fiber.HeaderAccessControlAllowOrigin, origin)
+	if cors.Credentials {
+		c.Set(fiber.HeaderAccessControlAllowCredentials, "true")
+	}
+	if len(cors.ExposeHeaders) > 0 {
+		c.Set(fiber.HeaderAccessControlExposeHeaders, strings.Join(cors.ExposeHeaders, ", "))
+	}
+}
+
+// answerPreflight answers a browser asking whether a page on its origin may
+// send a request, without running the route
+func answerPreflight(c *fiber.Ctx, cors *routepolicy.CORS) error {
+	setCORSHeaders(c, cors)
+	if !cors.AllowsOrigin(c.Get(fiber.HeaderOrigin)) {
+		return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Origin not allowed",
+		})
+	}
+	c.Set(fiber.HeaderAccessControlAllowMethods, strings.Join(cors.AllowedMethods(), ", "))
+	if len(cors.Headers) > 0 {
+		c.Set(fiber.HeaderAccessControlAllowHeaders, strings.Join(cors.Headers, ", "))
+	}
+	if cors.MaxAge > 0 {
+		c.Set(fiber.HeaderAccessControlMaxAge, strconv.Itoa(int(cors.MaxAge.Seconds())))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// authorizeRoute checks the caller's bearer token, role and scopes against
+// the group's before calling the route
+func authorizeRoute(c *fiber.Ctx, group *routepolicy.Group, jwtService *jwt.Service, userUseCase *usecase.UserUseCase) error {
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !ok || token == "" {
+		return c.Status(401).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Bearer token required",
+		})
+	}
+	claims, err := jwtService.ValidateToken(token)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Invalid token",
+		})
+	}
+
+	var role string
+	if len(group.Roles) > 0 {
+		user, err := userUseCase.GetUserByID(claims.UserID)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "Unauthorized",
+				"message": "User not found",
+			})
+		}
+		role = user.Role
+	}
+	scope, _ := claims.Extra["scope"].(string)
+	if err := group.Authorize(role, strings.Fields(scope)); err != nil {
+		message := "Access denied"
+		switch {
+		case errors.Is(err, routepolicy.ErrRoleRequired):
+			message = "Role " + strings.Join(group.Roles, " or ") + " required"
+		case errors.Is(err, routepolicy.ErrScopeRequired):
+			message = "Scopes " + strings.Join(group.Scopes, ", ") + " required"
+		}
+		return c.Status(403).JSON(fiber.Map{
+			"error":   "Forbidden",
+			"message": message,
+		})
+	}
+
+	// Later authentication reuses the validated claims
+	c.Locals("user", claims)
+	return c.Next()
+}
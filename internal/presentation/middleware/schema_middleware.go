@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/jsonschema"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SchemaMiddleware validates the JSON request body against a registered schema
+// before the handler runs. Non-JSON bodies are left for the handler to reject.
+func SchemaMiddleware(schemaService *jsonschema.Service, name string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !decoder.IsJSON(c.Get(fiber.HeaderContentType)) {
+			return c.Next()
+		}
+
+		err := schemaService.Validate(name, c.Body())
+		if err == nil {
+			return c.Next()
+		}
+
+		var validationErr *jsonschema.ValidationError
+		if !errors.As(err, &validationErr) {
+			return c.Status(500).JSON(dto.ErrorResponse{
+				Error:   "Validation failed",
+				Message: err.Error(),
+			})
+		}
+
+		fields := make([]dto.FieldError, len(validationErr.Errors))
+		for i, fieldErr := range validationErr.Errors {
+			fields[i] = dto.FieldError{Field: fieldErr.Field, Message: fieldErr.Message}
+		}
+
+		return c.Status(400).JSON(dto.ValidationErrorResponse{
+			Error:   "Validation failed",
+			Message: validationErr.Error(),
+			Fields:  fields,
+		})
+	}
+}
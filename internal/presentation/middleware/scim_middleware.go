@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/scim"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ScimMiddleware authenticates identity provider requests with the shared
+// bearer token configured for SCIM provisioning
+func ScimMiddleware(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		provided := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+		if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			return c.Status(401).JSON(dto.ScimErrorResponse{
+				Schemas: []string{scim.SchemaError},
+				Status:  "401",
+				Detail:  "Invalid or missing SCIM bearer token",
+			}, scim.MediaType)
+		}
+
+		return c.Next()
+	}
+}
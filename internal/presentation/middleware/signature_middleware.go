@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+
+	"fiber-hello-world/pkg/signature"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SignatureMiddleware requires a valid request signature from a known client.
+// It complements, not replaces, JWT authentication.
+func SignatureMiddleware(verifier *signature.Verifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := verifier.Verify(signature.Request{
+			ClientID:      c.Get(signature.HeaderClientID),
+			Timestamp:     c.Get(signature.HeaderTimestamp),
+			Nonce:         c.Get(signature.HeaderNonce),
+			Signature:     c.Get(signature.HeaderSignature),
+			Method:        c.Method(),
+			URI:           c.OriginalURL(),
+			Authorization: c.Get(fiber.HeaderAuthorization),
+			Body:          c.Body(),
+		})
+		if errors.Is(err, signature.ErrUnavailable) {
+			log.Printf("Signed request rejected: %v", err)
+			return c.Status(503).JSON(fiber.Map{
+				"error":   "Service Unavailable",
+				"message": signature.ErrUnavailable.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "Unauthorized",
+				"message": err.Error(),
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/pkg/slo"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// shedRetryAfter is the Retry-After, in seconds, of requests shed by
+// SLOMiddleware
+const shedRetryAfter = "30"
+
+// SLOMiddleware counts the requests for the routes of tracker's objectives
+// with their status and latency. While tracker sheds load, requests for
+// routes without an objective are answered 503 without running; paths under
+// exempt are never shed, so admins can always turn shedding off.
+func SLOMiddleware(tracker *slo.Tracker, exempt string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		objective, ok := tracker.Match(c.Method(), c.Path())
+		if !ok {
+			if tracker.Shedding() && !strings.HasPrefix(c.Path(), exempt) {
+				c.Set(fiber.HeaderRetryAfter, shedRetryAfter)
+				return c.Status(fiber.StatusServiceUnavailable).JSON(dto.ErrorResponse{
+					Error:   "Service overloaded",
+					Message: "Non-essential requests are shed while an error budget is exhausted, please retry later",
+				})
+			}
+			return c.Next()
+		}
+
+		start := time.Now()
+		err := c.Next()
+		status := c.Response().StatusCode()
+		if err != nil {
+			// The error handler has not answered yet
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+		tracker.Record(objective, status, time.Since(start))
+		return err
+	}
+}
@@ -0,0 +1,8 @@
+package playground
+
+import _ "embed"
+
+// Page is the single-page API playground served in development mode
+//
+//go:embed index.html
+var Page []byte
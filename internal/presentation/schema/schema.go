@@ -0,0 +1,9 @@
+package schema
+
+import "embed"
+
+// Files holds the JSON Schemas used to validate request payloads.
+// Each schema is registered under its file name without the .json extension.
+//
+//go:embed *.json
+var Files embed.FS
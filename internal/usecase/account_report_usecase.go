@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/pdf"
+)
+
+// ErrInvalidReportFormat is returned for an account report format other
+// than ReportFormatHTML or ReportFormatPDF
+var ErrInvalidReportFormat = errors.New("invalid report format")
+
+// Account report formats
+const (
+	ReportFormatHTML = "html"
+	ReportFormatPDF  = "pdf"
+)
+
+// DefaultAccountReportTemplate renders account reports. A report template
+// defines "title", "html" and "text", which are executed with an
+// *entity.AccountReport. "html" is escaped as HTML and served as is; "text"
+// is laid out as the PDF, headed by the title.
+const DefaultAccountReportTemplate = `{{define "title"}}Account report for {{.User.Email}}{{end}}
+{{define "html"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{template "title" .}}</title>
+<style>body{font-family:sans-serif;margin:2em}table{border-collapse:collapse;margin-bottom:1em}th,td{border:1px solid #ccc;padding:4px 8px;text-align:left;vertical-align:top}</style>
+</head>
+<body>
+<h1>{{template "title" .}}</h1>
+<p>Generated {{.GeneratedAt.Format "2006-01-02 15:04"}} UTC</p>
+<h2>Profile</h2>
+<table>
+<tr><th>ID</th><td>{{.User.ID}}</td></tr>
+<tr><th>Email</th><td>{{.User.Email}}</td></tr>
+<tr><th>Full name</th><td>{{.User.FullName}}</td></tr>
+<tr><th>Phone number</th><td>{{.User.PhoneNumber}}</td></tr>
+<tr><th>Birthday</th><td>{{.User.Birthday}}</td></tr>
+{{with .User.NationalID}}<tr><th>National ID</th><td>{{.}}</td></tr>
+{{end}}<tr><th>Role</th><td>{{.User.Role}}</td></tr>
+<tr><th>Status</th><td>{{.User.Status}}</td></tr>
+<tr><th>Plan</th><td>{{.User.Plan}}</td></tr>
+<tr><th>Created</th><td>{{.User.CreatedAt.Format "2006-01-02 15:04"}} UTC</td></tr>
+<tr><th>Updated</th><td>{{.User.UpdatedAt.Format "2006-01-02 15:04"}} UTC</td></tr>
+</table>
+<h2>Profile changes ({{len .Revisions}})</h2>
+{{if .Revisions}}<table>
+<tr><th>Date</th><th>Action</th><th>By user</th><th>Changes</th></tr>
+{{range .Revisions}}<tr><td>{{.CreatedAt.Format "2006-01-02 15:04"}}</td><td>{{.Action}}</td><td>{{.ActorID}}</td><td>{{range .Changes}}{{.Field}}: {{.Old}} &rarr; {{.New}}<br>{{end}}</td></tr>
+{{end}}</table>{{else}}<p>None</p>{{end}}
+<h2>Sign-ins ({{len .Logins}})</h2>
+{{if .Logins}}<table>
+<tr><th>Date</th><th>Result</th><th>IP</th><th>Country</th><th>Device</th></tr>
+{{range .Logins}}<tr><td>{{.CreatedAt.Format "2006-01-02 15:04"}}</td><td>{{if .Success}}Succeeded{{else}}Failed{{end}}</td><td>{{.IP}}</td><td>{{.Country}}</td><td>{{.Device}}</td></tr>
+{{end}}</table>{{else}}<p>None</p>{{end}}
+</body>
+</html>
+{{end}}
+{{define "text"}}Generated {{.GeneratedAt.Format "2006-01-02 15:04"}} UTC
+
+PROFILE
+ID: {{.User.ID}}
+Email: {{.User.Email}}
+Full name: {{.User.FullName}}
+Phone number: {{.User.PhoneNumber}}
+Birthday: {{.User.Birthday}}
+{{with .User.NationalID}}National ID: {{.}}
+{{end}}Role: {{.User.Role}}
+Status: {{.User.Status}}
+Plan: {{.User.Plan}}
+Created: {{.User.CreatedAt.Format "2006-01-02 15:04"}} UTC
+Updated: {{.User.UpdatedAt.Format "2006-01-02 15:04"}} UTC
+
+PROFILE CHANGES ({{len .Revisions}})
+{{range .Revisions}}{{.CreatedAt.Format "2006-01-02 15:04"}} {{.Action}} by user {{.ActorID}}
+{{range .Changes}}    {{.Field}}: {{.Old}} -> {{.New}}
+{{end}}{{else}}None
+{{end}}
+SIGN-INS ({{len .Logins}})
+{{range .Logins}}{{.CreatedAt.Format "2006-01-02 15:04"}} {{if .Success}}succeeded{{else}}failed{{end}} from {{.IP}}{{with .Country}} ({{.}}){{end}}, {{.Device}}
+{{else}}None
+{{end}}{{end}}`
+
+// AccountReportTemplate renders account reports as HTML or PDF
+type AccountReportTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// NewAccountReportTemplate parses text, which must define "title", "html"
+// and "text"
+func NewAccountReportTemplate(text string) (*AccountReportTemplate, error) {
+	html, err := htmltemplate.New("report").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account report template: %w", err)
+	}
+	plain, err := texttemplate.New("report").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account report template: %w", err)
+	}
+
+	t := &AccountReportTemplate{html: html, text: plain}
+	sample := &entity.AccountReport{User: &entity.User{}}
+	for _, format := range []string{ReportFormatHTML, ReportFormatPDF} {
+		if _, _, err := t.Render(sample, format); err != nil {
+			return nil, fmt.Errorf("invalid account report template: %w", err)
+		}
+	}
+	return t, nil
+}
+
+// Render returns the report in format with its content type
+func (t *AccountReportTemplate) Render(report *entity.AccountReport, format string) ([]byte, string, error) {
+	switch format {
+	case ReportFormatHTML:
+		var body bytes.Buffer
+		if err := t.html.ExecuteTemplate(&body, "html", report); err != nil {
+			return nil, "", err
+		}
+		return body.Bytes(), "text/html; charset=utf-8", nil
+	case ReportFormatPDF:
+		var title, body strings.Builder
+		if err := t.text.ExecuteTemplate(&title, "title", report); err != nil {
+			return nil, "", err
+		}
+		if err := t.text.ExecuteTemplate(&body, "text", report); err != nil {
+			return nil, "", err
+		}
+		return pdf.Render(strings.TrimSpace(title.String()), body.String(), report.GeneratedAt), "application/pdf", nil
+	}
+	return nil, "", fmt.Errorf("%w: %q, want %s or %s", ErrInvalidReportFormat, format, ReportFormatHTML, ReportFormatPDF)
+}
+
+// AccountReportUseCase assembles the data kept about a user into a report
+// downloaded through a single-use link
+type AccountReportUseCase struct {
+	users        *UserUseCase
+	revisionRepo repository.UserRevisionRepository
+	loginRepo    repository.LoginEventRepository
+	tokens       *TokenUseCase
+	template     *AccountReportTemplate
+	linkTTL      time.Duration
+	now          func() time.Time
+}
+
+// NewAccountReportUseCase creates a new account report use case whose
+// download links last linkTTL
+func NewAccountReportUseCase(users *UserUseCase, revisionRepo repository.UserRevisionRepository, loginRepo repository.LoginEventRepository,
+	tokens *TokenUseCase, template *AccountReportTemplate, linkTTL time.Duration) *AccountReportUseCase {
+	return &AccountReportUseCase{
+		users:        users,
+		revisionRepo: revisionRepo,
+		loginRepo:    loginRepo,
+		tokens:       tokens,
+		template:     template,
+		linkTTL:      linkTTL,
+		now:          time.Now,
+	}
+}
+
+// Assemble gathers the user's profile, every revision of it and every
+// sign-in attempt
+func (uc *AccountReportUseCase) Assemble(userID int) (*entity.AccountReport, error) {
+	user, err := uc.users.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	report := &entity.AccountReport{GeneratedAt: uc.now().UTC(), User: user}
+
+	for page := (repository.Page{Limit: repository.MaxPageLimit}); ; page.Offset += page.Limit {
+		revisions, err := uc.revisionRepo.ListByUser(userID, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read revisions: %w", err)
+		}
+		report.Revisions = append(report.Revisions, revisions...)
+		if len(revisions) < page.Limit {
+			break
+		}
+	}
+	if report.Logins, err = uc.loginRepo.ListByUser(userID, time.Time{}); err != nil {
+		return nil, fmt.Errorf("failed to read login history: %w", err)
+	}
+	return report, nil
+}
+
+// IssueLink returns a token that downloads the report of userID once within
+// the link lifetime. The report is assembled on download, so it is current.
+func (uc *AccountReportUseCase) IssueLink(userID int) (string, *entity.OneTimeToken, error) {
+	if _, err := uc.users.GetUserByID(userID); err != nil {
+		return "", nil, err
+	}
+	return uc.tokens.Issue(entity.TokenPurposeAccountReport, userID, uc.linkTTL)
+}
+
+// Download uses up token and returns the report of its user in format, with
+// its content type. The format is checked first, so a wrong one does not
+// use up the link.
+func (uc *AccountReportUseCase) Download(token, format string) (*entity.AccountReport, []byte, string, error) {
+	if format != ReportFormatHTML && format != ReportFormatPDF {
+		return nil, nil, "", fmt.Errorf("%w: %q, want %s or %s", ErrInvalidReportFormat, format, ReportFormatHTML, ReportFormatPDF)
+	}
+	record, err := uc.tokens.Consume(entity.TokenPurposeAccountReport, token)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	report, err := uc.Assemble(record.UserID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	data, contentType, err := uc.template.Render(report, format)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to render report: %w", err)
+	}
+	return report, data, contentType, nil
+}
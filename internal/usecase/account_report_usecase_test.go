@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+func TestAccountReportUseCase_Download(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	revisionRepo := NewMockUserRevisionRepository()
+	users := NewUserUseCase(userRepo, revisionRepo)
+	user, err := users.RegisterUser("report@example.com", "password123", "Report <User>", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed := *user
+	renamed.FullName = "Renamed"
+	revisionRepo.Record(entity.NewUserRevision(user.ID, user, &renamed))
+	loginRepo := &MockLoginEventRepository{now: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)}
+	loginRepo.Record(&entity.LoginEvent{UserID: user.ID, Success: true, IP: "203.0.113.7", Device: "Firefox", Country: "TH"})
+
+	template, err := NewAccountReportTemplate(DefaultAccountReportTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	useCase := NewAccountReportUseCase(users, revisionRepo, loginRepo, NewTokenUseCase(&MockOneTimeTokenRepository{}), template, time.Hour)
+
+	report, err := useCase.Assemble(user.ID)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if report.User.Password != "" || len(report.Revisions) != 1 || len(report.Logins) != 1 {
+		t.Errorf("Assemble() = %+v", report)
+	}
+
+	token, record, err := useCase.IssueLink(user.ID)
+	if err != nil || record.Purpose != entity.TokenPurposeAccountReport {
+		t.Fatalf("IssueLink() = %+v, %v", record, err)
+	}
+	// A wrong format does not use up the link
+	if _, _, _, err := useCase.Download(token, "docx"); !errors.Is(err, ErrInvalidReportFormat) {
+		t.Errorf("Download() as docx error = %v, want ErrInvalidReportFormat", err)
+	}
+	_, data, contentType, err := useCase.Download(token, ReportFormatHTML)
+	if err != nil || contentType != "text/html; charset=utf-8" {
+		t.Fatalf("Download() = %q, %v", contentType, err)
+	}
+	html := string(data)
+	for _, want := range []string{"Report &lt;User&gt;", "fullName: Report &lt;User&gt; &rarr; Renamed", "203.0.113.7", "Sign-ins (1)"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML report does not contain %q:\n%s", want, html)
+		}
+	}
+	if _, _, _, err := useCase.Download(token, ReportFormatHTML); !errors.Is(err, ErrTokenAlreadyUsed) {
+		t.Errorf("second Download() error = %v, want ErrTokenAlreadyUsed", err)
+	}
+
+	token, _, _ = useCase.IssueLink(user.ID)
+	_, data, contentType, err = useCase.Download(token, ReportFormatPDF)
+	if err != nil || contentType != "application/pdf" || !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatalf("Download() as PDF = %q, %v", contentType, err)
+	}
+	for _, want := range []string{"(Account report for report@example.com) Tj", "(Full name: Report <User>) Tj", "fullName: Report <User> -> Renamed"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("PDF report does not contain %q", want)
+		}
+	}
+
+	if _, _, err := useCase.IssueLink(999); err == nil {
+		t.Error("IssueLink() for an unknown user should fail")
+	}
+}
+
+func TestNewAccountReportTemplate_Invalid(t *testing.T) {
+	for name, text := range map[string]string{
+		"does not parse":    `{{define "html"}}{{.User.Email}`,
+		"misses a template": `{{define "title"}}Report{{end}}{{define "html"}}{{.User.Email}}{{end}}`,
+		"unknown field":     `{{define "title"}}{{.Owner}}{{end}}{{define "html"}}{{end}}{{define "text"}}{{end}}`,
+	} {
+		if _, err := NewAccountReportTemplate(text); err == nil {
+			t.Errorf("NewAccountReportTemplate() for a template that %s should fail", name)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// MaxAdminActionUsers caps the number of users a single admin action may target
+const MaxAdminActionUsers = 500
+
+// ErrInvalidAdminAction is returned when an admin action request is malformed
+var ErrInvalidAdminAction = errors.New("invalid admin action")
+
+// ErrSelfApproval is returned when admins approve their own action
+var ErrSelfApproval = errors.New("admin actions must be approved by another admin")
+
+// adminActionKinds lists every kind of admin action
+var adminActionKinds = []entity.AdminActionKind{
+	entity.AdminActionDeleteUsers,
+	entity.AdminActionSuspendUsers,
+	entity.AdminActionSetRole,
+	entity.AdminActionIncidentReset,
+}
+
+// AdminActionUseCase queues destructive admin actions behind an undo window
+// and applies them once the window has passed. Kinds that need approval wait
+// for a second admin before their window starts.
+type AdminActionUseCase struct {
+	actionRepo    repository.AdminActionRepository
+	loginRepo     repository.LoginEventRepository
+	userUseCase   *UserUseCase
+	resets        *PasswordResetUseCase
+	delay         time.Duration
+	approvalKinds map[entity.AdminActionKind]bool
+	now           func() time.Time
+}
+
+// NewAdminActionUseCase creates a new admin action use case that applies actions after delay
+func NewAdminActionUseCase(actionRepo repository.AdminActionRepository, loginRepo repository.LoginEventRepository, userUseCase *UserUseCase, resets *PasswordResetUseCase, delay time.Duration) *AdminActionUseCase {
+	return &AdminActionUseCase{
+		actionRepo:  actionRepo,
+		loginRepo:   loginRepo,
+		userUseCase: userUseCase,
+		resets:      resets,
+		delay:       delay,
+		now:         time.Now,
+	}
+}
+
+// SetApprovalKinds makes the actions of kinds, such as delete_users, wait
+// for a second admin's approval before they are queued
+func (uc *AdminActionUseCase) SetApprovalKinds(kinds []string) error {
+	approvalKinds := make(map[entity.AdminActionKind]bool, len(kinds))
+	for _, kind := range kinds {
+		if !slices.Contains(adminActionKinds, entity.AdminActionKind(kind)) {
+			return fmt.Errorf("%w: unknown kind %q", ErrInvalidAdminAction, kind)
+		}
+		approvalKinds[entity.AdminActionKind(kind)] = true
+	}
+	uc.approvalKinds = approvalKinds
+	return nil
+}
+
+// NeedsApproval reports whether actions of kind wait for a second admin
+func (uc *AdminActionUseCase) NeedsApproval(kind entity.AdminActionKind) bool {
+	return uc.approvalKinds[kind]
+}
+
+// ScheduleDelete queues the deletion of users
+func (uc *AdminActionUseCase) ScheduleDelete(actorID int, userIDs []int) (*entity.AdminAction, error) {
+	return uc.schedule(&entity.AdminAction{Kind: entity.AdminActionDeleteUsers, ActorID: actorID, UserIDs: userIDs})
+}
+
+// ScheduleSuspend queues the suspension of users
+func (uc *AdminActionUseCase) ScheduleSuspend(actorID int, userIDs []int) (*entity.AdminAction, error) {
+	return uc.schedule(&entity.AdminAction{Kind: entity.AdminActionSuspendUsers, ActorID: actorID, UserIDs: userIDs})
+}
+
+// ScheduleSetRole queues a role change for users
+func (uc *AdminActionUseCase) ScheduleSetRole(actorID int, userIDs []int, role string) (*entity.AdminAction, error) {
+	if role != entity.RoleUser && role != entity.RoleAdmin {
+		return nil, fmt.Errorf("%w: role must be %q or %q", ErrInvalidAdminAction, entity.RoleUser, entity.RoleAdmin)
+	}
+	return uc.schedule(&entity.AdminAction{Kind: entity.AdminActionSetRole, ActorID: actorID, UserIDs: userIDs, Role: role})
+}
+
+// ScheduleIncidentReset queues a forced password reset for the users in
+// segment, each notified with the subject and message templates (see
+// NoticeData). The acting admin is left out of the segment.
+func (uc *AdminActionUseCase) ScheduleIncidentReset(actorID int, segment entity.UserSegment, subject, message string) (*entity.AdminAction, error) {
+	if _, err := NewNotice(subject, message); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAdminAction, err)
+	}
+
+	userIDs, err := uc.segmentUsers(actorID, segment)
+	if err != nil {
+		return nil, err
+	}
+	return uc.schedule(&entity.AdminAction{
+		Kind:    entity.AdminActionIncidentReset,
+		ActorID: actorID,
+		UserIDs: userIDs,
+		Subject: subject,
+		Message: message,
+	})
+}
+
+// segmentUsers returns the existing users in segment other than actorID,
+// in the order of their first matching login
+func (uc *AdminActionUseCase) segmentUsers(actorID int, segment entity.UserSegment) ([]int, error) {
+	if segment.Since.IsZero() || !segment.Until.After(segment.Since) {
+		return nil, fmt.Errorf("%w: the login window must end after it starts", ErrInvalidAdminAction)
+	}
+	var ipRange netip.Prefix
+	if segment.IPRange != "" {
+		var err error
+		if ipRange, err = parseIPRange(segment.IPRange); err != nil {
+			return nil, fmt.Errorf("%w: invalid IP range %q", ErrInvalidAdminAction, segment.IPRange)
+		}
+	}
+
+	events, err := uc.loginRepo.ListSuccessful(segment.Since, segment.Until)
+	if err != nil {
+		return nil, errors.New("failed to read login history")
+	}
+
+	seen := make(map[int]bool)
+	var userIDs []int
+	for _, event := range events {
+		if event.UserID == actorID || seen[event.UserID] {
+			continue
+		}
+		if ipRange.IsValid() {
+			addr, err := netip.ParseAddr(event.IP)
+			if err != nil || !ipRange.Contains(addr.Unmap()) {
+				continue
+			}
+		}
+		seen[event.UserID] = true
+		// Users deleted since they signed in are left out
+		if _, err := uc.userUseCase.GetUserByID(event.UserID); err == nil {
+			userIDs = append(userIDs, event.UserID)
+		}
+	}
+
+	if len(userIDs) == 0 {
+		return nil, fmt.Errorf("%w: no users signed in within the segment", ErrInvalidAdminAction)
+	}
+	return userIDs, nil
+}
+
+// parseIPRange parses a CIDR range or a single IP as a prefix
+func parseIPRange(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// schedule validates the action's targets and stores it with a fresh undo
+// token, as pending or, for kinds that need it, awaiting approval
+func (uc *AdminActionUseCase) schedule(action *entity.AdminAction) (*entity.AdminAction, error) {
+	actorID, userIDs := action.ActorID, action.UserIDs
+	if len(userIDs) == 0 {
+		return nil, fmt.Errorf("%w: at least one user ID is required", ErrInvalidAdminAction)
+	}
+	if len(userIDs) > MaxAdminActionUsers {
+		return nil, fmt.Errorf("%w: at most %d users per action", ErrInvalidAdminAction, MaxAdminActionUsers)
+	}
+
+	seen := make(map[int]bool, len(userIDs))
+	targets := make([]int, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id == actorID {
+			return nil, fmt.Errorf("%w: admins cannot target their own account", ErrInvalidAdminAction)
+		}
+		if seen[id] {
+			continue
+		}
+		if _, err := uc.userUseCase.GetUserByID(id); err != nil {
+			return nil, fmt.Errorf("%w: user %d not found", ErrInvalidAdminAction, id)
+		}
+		seen[id] = true
+		targets = append(targets, id)
+	}
+
+	token, err := newUndoToken()
+	if err != nil {
+		return nil, errors.New("failed to generate undo token")
+	}
+
+	action.Token = token
+	action.UserIDs = targets
+	action.Status = entity.AdminActionPending
+	action.ExecuteAt = uc.now().UTC().Add(uc.delay)
+	if uc.NeedsApproval(action.Kind) {
+		action.Status, action.ExecuteAt = entity.AdminActionAwaitingApproval, time.Time{}
+	}
+	if err := uc.actionRepo.Create(action); err != nil {
+		return nil, errors.New("failed to queue admin action")
+	}
+
+	return action, nil
+}
+
+// GetAction retrieves a queued action by its undo token
+func (uc *AdminActionUseCase) GetAction(token string) (*entity.AdminAction, error) {
+	action, err := uc.actionRepo.GetByToken(token)
+	if errors.Is(err, repository.ErrAdminActionNotFound) {
+		return nil, repository.ErrAdminActionNotFound
+	}
+	if err != nil {
+		return nil, errors.New("failed to load admin action")
+	}
+
+	return action, nil
+}
+
+// Undo cancels a pending action or one awaiting approval. Returns
+// ErrAdminActionNotFound for an unknown token and ErrAdminActionNotPending
+// once the action has run.
+func (uc *AdminActionUseCase) Undo(token string) (*entity.AdminAction, error) {
+	err := uc.actionRepo.Cancel(token)
+	if errors.Is(err, repository.ErrAdminActionNotFound) || errors.Is(err, repository.ErrAdminActionNotPending) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to cancel admin action")
+	}
+
+	return uc.GetAction(token)
+}
+
+// Approve approves an action awaiting approval on behalf of reviewerID, who
+// must not be the admin who requested it, and starts its undo window.
+// Returns ErrAdminActionNotFound, ErrAdminActionNotAwaitingApproval or
+// ErrSelfApproval.
+func (uc *AdminActionUseCase) Approve(token string, reviewerID int) (*entity.AdminAction, error) {
+	action, err := uc.GetAction(token)
+	if err != nil {
+		return nil, err
+	}
+	if action.Status != entity.AdminActionAwaitingApproval {
+		return nil, repository.ErrAdminActionNotAwaitingApproval
+	}
+	if action.ActorID == reviewerID {
+		return nil, ErrSelfApproval
+	}
+
+	now := uc.now().UTC()
+	err = uc.actionRepo.Approve(token, reviewerID, now, now.Add(uc.delay))
+	if errors.Is(err, repository.ErrAdminActionNotFound) || errors.Is(err, repository.ErrAdminActionNotAwaitingApproval) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to approve admin action")
+	}
+
+	return uc.GetAction(token)
+}
+
+// Reject turns down an action awaiting approval on behalf of reviewerID.
+// Returns ErrAdminActionNotFound or ErrAdminActionNotAwaitingApproval.
+func (uc *AdminActionUseCase) Reject(token string, reviewerID int) (*entity.AdminAction, error) {
+	err := uc.actionRepo.Reject(token, reviewerID, uc.now().UTC())
+	if errors.Is(err, repository.ErrAdminActionNotFound) || errors.Is(err, repository.ErrAdminActionNotAwaitingApproval) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to reject admin action")
+	}
+
+	return uc.GetAction(token)
+}
+
+// Backlog returns the number of actions past their undo window that the
+// worker has not started yet
+func (uc *AdminActionUseCase) Backlog() (int, error) {
+	count, err := uc.actionRepo.CountDue(uc.now().UTC())
+	if err != nil {
+		return 0, errors.New("failed to count admin actions")
+	}
+	return count, nil
+}
+
+// AdminActionQueue reports the state of the admin action queue. Throughput
+// counts the actions that finished within Window.
+type AdminActionQueue struct {
+	PausedSince time.Time
+	Counts      map[entity.AdminActionStatus]int
+	Due         int
+	Window      time.Duration
+	Throughput  []entity.AdminActionThroughput
+}
+
+// Queue reports the admin action queue's state and its throughput over window
+func (uc *AdminActionUseCase) Queue(window time.Duration) (*AdminActionQueue, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("%w: the window must be positive", ErrInvalidAdminAction)
+	}
+
+	now := uc.now().UTC()
+	queue := &AdminActionQueue{Window: window}
+	var err error
+	if queue.PausedSince, err = uc.actionRepo.PausedSince(); err != nil {
+		return nil, errors.New("failed to read queue state")
+	}
+	if queue.Counts, err = uc.actionRepo.CountByStatus(); err != nil {
+		return nil, errors.New("failed to count admin actions")
+	}
+	if queue.Due, err = uc.actionRepo.CountDue(now); err != nil {
+		return nil, errors.New("failed to count admin actions")
+	}
+	if queue.Throughput, err = uc.actionRepo.Throughput(now.Add(-window)); err != nil {
+		return nil, errors.New("failed to read admin action throughput")
+	}
+	return queue, nil
+}
+
+// ListActions returns up to limit actions with status and kind, newest
+// first. An empty status or kind matches any.
+func (uc *AdminActionUseCase) ListActions(status entity.AdminActionStatus, kind entity.AdminActionKind, limit int) ([]*entity.AdminAction, error) {
+	switch status {
+	case "", entity.AdminActionAwaitingApproval, entity.AdminActionPending, entity.AdminActionRunning, entity.AdminActionApplied, entity.AdminActionFailed, entity.AdminActionCancelled, entity.AdminActionRejected:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidAdminAction, status)
+	}
+	if kind != "" && !slices.Contains(adminActionKinds, kind) {
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidAdminAction, kind)
+	}
+
+	actions, err := uc.actionRepo.List(status, kind, limit)
+	if err != nil {
+		return nil, errors.New("failed to list admin actions")
+	}
+	return actions, nil
+}
+
+// Retry queues a failed action to run again on the worker's next pass, for
+// the users it failed for. Returns ErrAdminActionNotFound for an unknown
+// token and ErrAdminActionNotFailed unless the action failed.
+func (uc *AdminActionUseCase) Retry(token string) (*entity.AdminAction, error) {
+	err := uc.actionRepo.Retry(token, uc.now().UTC())
+	if errors.Is(err, repository.ErrAdminActionNotFound) || errors.Is(err, repository.ErrAdminActionNotFailed) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to retry admin action")
+	}
+
+	return uc.GetAction(token)
+}
+
+// SetPaused pauses or resumes the queue. Paused actions stay pending, can
+// still be undone, and run once the queue is resumed.
+func (uc *AdminActionUseCase) SetPaused(paused bool) error {
+	if err := uc.actionRepo.SetPaused(paused, uc.now().UTC()); err != nil {
+		return errors.New("failed to update queue state")
+	}
+	return nil
+}
+
+// ProcessDue applies up to limit actions whose undo window has passed and
+// returns how many were processed. It is run periodically by the job worker
+// and does nothing while the queue is paused.
+func (uc *AdminActionUseCase) ProcessDue(limit int) (int, error) {
+	pausedSince, err := uc.actionRepo.PausedSince()
+	if err != nil || !pausedSince.IsZero() {
+		return 0, err
+	}
+
+	actions, err := uc.actionRepo.ClaimDue(uc.now().UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, action := range actions {
+		status, errMsg := entity.AdminActionApplied, ""
+		failed, err := uc.apply(action)
+		if err != nil {
+			status, errMsg = entity.AdminActionFailed, err.Error()
+		}
+		if err := uc.actionRepo.Finish(action.ID, status, errMsg, failed); err != nil {
+			return len(actions), err
+		}
+	}
+
+	return len(actions), nil
+}
+
+// apply performs an action against every target user, continuing past
+// individual failures and reporting them together with the users they
+// happened for
+func (uc *AdminActionUseCase) apply(action *entity.AdminAction) ([]int, error) {
+	var notice *Notice
+	if action.Kind == entity.AdminActionIncidentReset {
+		var err error
+		if notice, err = NewNotice(action.Subject, action.Message); err != nil {
+			return nil, err
+		}
+	}
+
+	var failed []int
+	var failures []string
+	for i, id := range action.UserIDs {
+		var err error
+		switch action.Kind {
+		case entity.AdminActionDeleteUsers:
+			err = uc.userUseCase.DeleteUser(action.ActorID, id)
+		case entity.AdminActionSuspendUsers:
+			err = uc.userUseCase.SuspendUser(action.ActorID, id)
+		case entity.AdminActionSetRole:
+			err = uc.userUseCase.SetUserRole(action.ActorID, id, action.Role)
+		case entity.AdminActionIncidentReset:
+			err = uc.resets.ForceReset(id, notice)
+		default:
+			return nil, fmt.Errorf("unknown admin action kind %q", action.Kind)
+		}
+		if err != nil {
+			failed = append(failed, id)
+			failures = append(failures, fmt.Sprintf("user %d: %v", id, err))
+		}
+
+		// Progress is informational; failing to record it does not fail the action
+		_ = uc.actionRepo.Progress(action.ID, i+1)
+	}
+
+	if len(failures) > 0 {
+		return failed, errors.New(strings.Join(failures, "; "))
+	}
+	return nil, nil
+}
+
+// newUndoToken returns a random, URL-safe undo token
+func newUndoToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
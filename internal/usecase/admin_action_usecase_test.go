@@ -0,0 +1,674 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/hooks"
+)
+
+// Mock admin action repository for testing
+type MockAdminActionRepository struct {
+	actions     []*entity.AdminAction
+	pausedSince time.Time
+}
+
+func (m *MockAdminActionRepository) Create(action *entity.AdminAction) error {
+	action.ID = len(m.actions) + 1
+	m.actions = append(m.actions, action)
+	return nil
+}
+
+func (m *MockAdminActionRepository) GetByToken(token string) (*entity.AdminAction, error) {
+	for _, action := range m.actions {
+		if action.Token == token {
+			return action, nil
+		}
+	}
+	return nil, repository.ErrAdminActionNotFound
+}
+
+func (m *MockAdminActionRepository) Cancel(token string) error {
+	action, err := m.GetByToken(token)
+	if err != nil {
+		return err
+	}
+	if action.Status != entity.AdminActionPending && action.Status != entity.AdminActionAwaitingApproval {
+		return repository.ErrAdminActionNotPending
+	}
+	action.Status = entity.AdminActionCancelled
+	return nil
+}
+
+func (m *MockAdminActionRepository) Approve(token string, reviewerID int, at, executeAt time.Time) error {
+	action, err := m.GetByToken(token)
+	if err != nil {
+		return err
+	}
+	if action.Status != entity.AdminActionAwaitingApproval {
+		return repository.ErrAdminActionNotAwaitingApproval
+	}
+	action.Status, action.ReviewedBy, action.ReviewedAt, action.ExecuteAt = entity.AdminActionPending, reviewerID, &at, executeAt
+	return nil
+}
+
+func (m *MockAdminActionRepository) Reject(token string, reviewerID int, at time.Time) error {
+	action, err := m.GetByToken(token)
+	if err != nil {
+		return err
+	}
+	if action.Status != entity.AdminActionAwaitingApproval {
+		return repository.ErrAdminActionNotAwaitingApproval
+	}
+	action.Status, action.ReviewedBy, action.ReviewedAt = entity.AdminActionRejected, reviewerID, &at
+	return nil
+}
+
+func (m *MockAdminActionRepository) ClaimDue(now time.Time, limit int) ([]*entity.AdminAction, error) {
+	var claimed []*entity.AdminAction
+	for _, action := range m.actions {
+		if len(claimed) < limit && action.Status == entity.AdminActionPending && !action.ExecuteAt.After(now) {
+			action.Status = entity.AdminActionRunning
+			claimed = append(claimed, action)
+		}
+	}
+	return claimed, nil
+}
+
+func (m *MockAdminActionRepository) CountDue(now time.Time) (int, error) {
+	count := 0
+	for _, action := range m.actions {
+		if action.Status == entity.AdminActionPending && !action.ExecuteAt.After(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockAdminActionRepository) ListPending(kind entity.AdminActionKind) ([]*entity.AdminAction, error) {
+	var pending []*entity.AdminAction
+	for _, action := range m.actions {
+		if action.Status == entity.AdminActionPending && action.Kind == kind {
+			pending = append(pending, action)
+		}
+	}
+	return pending, nil
+}
+
+func (m *MockAdminActionRepository) Progress(id int, processed int) error {
+	m.actions[id-1].Processed = processed
+	return nil
+}
+
+func (m *MockAdminActionRepository) List(status entity.AdminActionStatus, kind entity.AdminActionKind, limit int) ([]*entity.AdminAction, error) {
+	var actions []*entity.AdminAction
+	for i := len(m.actions) - 1; i >= 0 && len(actions) < limit; i-- {
+		action := m.actions[i]
+		if (status == "" || action.Status == status) && (kind == "" || action.Kind == kind) {
+			actions = append(actions, action)
+		}
+	}
+	return actions, nil
+}
+
+func (m *MockAdminActionRepository) CountByStatus() (map[entity.AdminActionStatus]int, error) {
+	counts := make(map[entity.AdminActionStatus]int)
+	for _, action := range m.actions {
+		counts[action.Status]++
+	}
+	return counts, nil
+}
+
+func (m *MockAdminActionRepository) Throughput(since time.Time) ([]entity.AdminActionThroughput, error) {
+	var throughput []entity.AdminActionThroughput
+	for _, action := range m.actions {
+		if action.FinishedAt == nil || action.FinishedAt.Before(since) {
+			continue
+		}
+		throughput = append(throughput, entity.AdminActionThroughput{Kind: action.Kind})
+		item := &throughput[len(throughput)-1]
+		if action.Status == entity.AdminActionApplied {
+			item.Applied++
+		} else {
+			item.Failed++
+		}
+	}
+	return throughput, nil
+}
+
+func (m *MockAdminActionRepository) Retry(token string, executeAt time.Time) error {
+	action, err := m.GetByToken(token)
+	if err != nil {
+		return err
+	}
+	if action.Status != entity.AdminActionFailed {
+		return repository.ErrAdminActionNotFailed
+	}
+	if len(action.FailedUserIDs) > 0 {
+		action.UserIDs = action.FailedUserIDs
+	}
+	action.Status, action.Error, action.FailedUserIDs, action.FinishedAt = entity.AdminActionPending, "", nil, nil
+	action.Processed, action.ExecuteAt = 0, executeAt
+	return nil
+}
+
+func (m *MockAdminActionRepository) SetPaused(paused bool, at time.Time) error {
+	if !paused {
+		m.pausedSince = time.Time{}
+	} else if m.pausedSince.IsZero() {
+		m.pausedSince = at
+	}
+	return nil
+}
+
+func (m *MockAdminActionRepository) PausedSince() (time.Time, error) {
+	return m.pausedSince, nil
+}
+
+func (m *MockAdminActionRepository) Finish(id int, status entity.AdminActionStatus, errMsg string, failedUserIDs []int) error {
+	finishedAt := time.Now()
+	m.actions[id-1].Status = status
+	m.actions[id-1].Error = errMsg
+	m.actions[id-1].FailedUserIDs = failedUserIDs
+	m.actions[id-1].FinishedAt = &finishedAt
+	return nil
+}
+
+// setupAdminActionTest registers an admin and two users and returns a use case whose clock is controlled by the test
+func setupAdminActionTest(t *testing.T) (*AdminActionUseCase, *UserUseCase, *time.Time, []int) {
+	t.Helper()
+	userUseCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+
+	var ids []int
+	for _, email := range []string{"admin@example.com", "one@example.com", "two@example.com"} {
+		user, err := userUseCase.RegisterUser(email, "password123", "Some User", "0812345678", "1990-01-15")
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", email, err)
+		}
+		ids = append(ids, user.ID)
+	}
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	resets := NewPasswordResetUseCase(userUseCase, NewTokenUseCase(&MockOneTimeTokenRepository{}), 30*time.Minute)
+	useCase := NewAdminActionUseCase(&MockAdminActionRepository{}, &MockLoginEventRepository{}, userUseCase, resets, 30*time.Second)
+	useCase.now = func() time.Time { return now }
+	return useCase, userUseCase, &now, ids
+}
+
+func TestAdminActionUseCase_AppliesAfterDelay(t *testing.T) {
+	useCase, userUseCase, now, ids := setupAdminActionTest(t)
+	adminID, oneID, twoID := ids[0], ids[1], ids[2]
+
+	action, err := useCase.ScheduleSuspend(adminID, []int{oneID, twoID, oneID})
+	if err != nil {
+		t.Fatalf("ScheduleSuspend() error = %v", err)
+	}
+	if action.Token == "" || action.Status != entity.AdminActionPending || len(action.UserIDs) != 2 {
+		t.Errorf("ScheduleSuspend() = %+v", action)
+	}
+	if !action.ExecuteAt.Equal(now.Add(30 * time.Second)) {
+		t.Errorf("ExecuteAt = %v, want %v", action.ExecuteAt, now.Add(30*time.Second))
+	}
+
+	// Nothing happens inside the undo window
+	if processed, err := useCase.ProcessDue(10); err != nil || processed != 0 {
+		t.Errorf("ProcessDue() = %v, %v, want 0 before the delay", processed, err)
+	}
+
+	if backlog, err := useCase.Backlog(); err != nil || backlog != 0 {
+		t.Errorf("Backlog() = %v, %v, want 0 inside the undo window", backlog, err)
+	}
+
+	*now = now.Add(30 * time.Second)
+	if backlog, err := useCase.Backlog(); err != nil || backlog != 1 {
+		t.Errorf("Backlog() = %v, %v, want 1 once due", backlog, err)
+	}
+	if processed, err := useCase.ProcessDue(10); err != nil || processed != 1 {
+		t.Fatalf("ProcessDue() = %v, %v, want 1", processed, err)
+	}
+
+	for _, id := range []int{oneID, twoID} {
+		user, err := userUseCase.GetUserByID(id)
+		if err != nil {
+			t.Fatalf("GetUserByID() error = %v", err)
+		}
+		if user.Status != entity.StatusSuspended {
+			t.Errorf("user %d status = %v, want suspended", id, user.Status)
+		}
+	}
+
+	applied, err := useCase.GetAction(action.Token)
+	if err != nil {
+		t.Fatalf("GetAction() error = %v", err)
+	}
+	if applied.Status != entity.AdminActionApplied {
+		t.Errorf("Status = %v, want applied", applied.Status)
+	}
+
+	// Applied actions can no longer be undone
+	if _, err := useCase.Undo(action.Token); !errors.Is(err, repository.ErrAdminActionNotPending) {
+		t.Errorf("Undo() error = %v, want ErrAdminActionNotPending", err)
+	}
+}
+
+func TestAdminActionUseCase_Undo(t *testing.T) {
+	useCase, userUseCase, now, ids := setupAdminActionTest(t)
+
+	action, err := useCase.ScheduleDelete(ids[0], []int{ids[1]})
+	if err != nil {
+		t.Fatalf("ScheduleDelete() error = %v", err)
+	}
+
+	undone, err := useCase.Undo(action.Token)
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if undone.Status != entity.AdminActionCancelled {
+		t.Errorf("Status = %v, want cancelled", undone.Status)
+	}
+
+	*now = now.Add(time.Hour)
+	if processed, err := useCase.ProcessDue(10); err != nil || processed != 0 {
+		t.Errorf("ProcessDue() = %v, %v, want 0 for a cancelled action", processed, err)
+	}
+	if _, err := userUseCase.GetUserByID(ids[1]); err != nil {
+		t.Error("undone delete should leave the user in place")
+	}
+
+	if _, err := useCase.Undo("missing"); !errors.Is(err, repository.ErrAdminActionNotFound) {
+		t.Errorf("Undo() error = %v, want ErrAdminActionNotFound", err)
+	}
+}
+
+func TestAdminActionUseCase_Approval(t *testing.T) {
+	useCase, userUseCase, now, ids := setupAdminActionTest(t)
+	adminID, oneID, twoID := ids[0], ids[1], ids[2]
+	if err := useCase.SetApprovalKinds([]string{"delete_users"}); err != nil {
+		t.Fatalf("SetApprovalKinds() error = %v", err)
+	}
+
+	action, err := useCase.ScheduleDelete(adminID, []int{oneID})
+	if err != nil {
+		t.Fatalf("ScheduleDelete() error = %v", err)
+	}
+	if action.Status != entity.AdminActionAwaitingApproval || !action.ExecuteAt.IsZero() {
+		t.Fatalf("ScheduleDelete() = %+v, want it awaiting approval", action)
+	}
+	// Kinds left out run after the delay alone
+	if suspend, err := useCase.ScheduleSuspend(adminID, []int{twoID}); err != nil || suspend.Status != entity.AdminActionPending {
+		t.Errorf("ScheduleSuspend() = %+v, %v; want pending", suspend, err)
+	}
+
+	// The worker leaves an unapproved action alone
+	*now = now.Add(time.Hour)
+	if processed, err := useCase.ProcessDue(10); err != nil || processed != 1 {
+		t.Errorf("ProcessDue() = %v, %v, want only the suspension", processed, err)
+	}
+	if _, err := userUseCase.GetUserByID(oneID); err != nil {
+		t.Fatal("an unapproved delete should leave the user in place")
+	}
+
+	if _, err := useCase.Approve(action.Token, adminID); !errors.Is(err, ErrSelfApproval) {
+		t.Errorf("Approve() by the requester error = %v, want ErrSelfApproval", err)
+	}
+	approved, err := useCase.Approve(action.Token, twoID)
+	if err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if approved.Status != entity.AdminActionPending || approved.ReviewedBy != twoID || !approved.ExecuteAt.Equal(now.Add(30*time.Second)) {
+		t.Errorf("Approve() = %+v, want pending with the undo window starting now", approved)
+	}
+	if _, err := useCase.Approve(action.Token, twoID); !errors.Is(err, repository.ErrAdminActionNotAwaitingApproval) {
+		t.Errorf("Approve() twice error = %v, want ErrAdminActionNotAwaitingApproval", err)
+	}
+
+	*now = now.Add(time.Minute)
+	if processed, err := useCase.ProcessDue(10); err != nil || processed != 1 {
+		t.Errorf("ProcessDue() = %v, %v, want the approved delete", processed, err)
+	}
+	if _, err := userUseCase.GetUserByID(oneID); err == nil {
+		t.Error("the approved delete should have removed the user")
+	}
+}
+
+func TestAdminActionUseCase_Reject(t *testing.T) {
+	useCase, _, _, ids := setupAdminActionTest(t)
+	if err := useCase.SetApprovalKinds([]string{"set_role"}); err != nil {
+		t.Fatal(err)
+	}
+
+	action, err := useCase.ScheduleSetRole(ids[0], []int{ids[1]}, entity.RoleAdmin)
+	if err != nil {
+		t.Fatalf("ScheduleSetRole() error = %v", err)
+	}
+	rejected, err := useCase.Reject(action.Token, ids[2])
+	if err != nil {
+		t.Fatalf("Reject() error = %v", err)
+	}
+	if rejected.Status != entity.AdminActionRejected || rejected.ReviewedBy != ids[2] || rejected.ReviewedAt == nil {
+		t.Errorf("Reject() = %+v", rejected)
+	}
+	if _, err := useCase.Approve(action.Token, ids[2]); !errors.Is(err, repository.ErrAdminActionNotAwaitingApproval) {
+		t.Errorf("Approve() after Reject() error = %v, want ErrAdminActionNotAwaitingApproval", err)
+	}
+	if _, err := useCase.Reject("missing", ids[2]); !errors.Is(err, repository.ErrAdminActionNotFound) {
+		t.Errorf("Reject() error = %v, want ErrAdminActionNotFound", err)
+	}
+
+	// An action awaiting approval can still be undone by its requester
+	pending, err := useCase.ScheduleSetRole(ids[0], []int{ids[2]}, entity.RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undone, err := useCase.Undo(pending.Token); err != nil || undone.Status != entity.AdminActionCancelled {
+		t.Errorf("Undo() = %+v, %v; want cancelled", undone, err)
+	}
+
+	if err := useCase.SetApprovalKinds([]string{"erase_everything"}); !errors.Is(err, ErrInvalidAdminAction) {
+		t.Errorf("SetApprovalKinds() with an unknown kind error = %v, want ErrInvalidAdminAction", err)
+	}
+}
+
+func TestAdminActionUseCase_SetRoleAndDelete(t *testing.T) {
+	useCase, userUseCase, now, ids := setupAdminActionTest(t)
+
+	if _, err := useCase.ScheduleSetRole(ids[0], []int{ids[1]}, entity.RoleAdmin); err != nil {
+		t.Fatalf("ScheduleSetRole() error = %v", err)
+	}
+	deletion, err := useCase.ScheduleDelete(ids[0], []int{ids[2]})
+	if err != nil {
+		t.Fatalf("ScheduleDelete() error = %v", err)
+	}
+
+	*now = now.Add(time.Minute)
+	if processed, err := useCase.ProcessDue(10); err != nil || processed != 2 {
+		t.Fatalf("ProcessDue() = %v, %v, want 2", processed, err)
+	}
+
+	promoted, err := userUseCase.GetUserByID(ids[1])
+	if err != nil || promoted.Role != entity.RoleAdmin {
+		t.Errorf("user role = %+v, %v, want admin", promoted, err)
+	}
+	if _, err := userUseCase.GetUserByID(ids[2]); err == nil {
+		t.Error("deleted user should be gone")
+	}
+
+	history, err := userUseCase.GetUserHistory(ids[2], repository.Page{})
+	if err != nil || len(history) != 1 || history[0].ActorID != ids[0] {
+		t.Errorf("delete should be recorded with the admin as actor, got %+v, %v", history, err)
+	}
+	if applied, _ := useCase.GetAction(deletion.Token); applied.Status != entity.AdminActionApplied {
+		t.Errorf("Status = %v, want applied", applied.Status)
+	}
+}
+
+func TestAdminActionUseCase_FailedTargetsAreReported(t *testing.T) {
+	useCase, userUseCase, now, ids := setupAdminActionTest(t)
+
+	action, err := useCase.ScheduleDelete(ids[0], []int{ids[1], ids[2]})
+	if err != nil {
+		t.Fatalf("ScheduleDelete() error = %v", err)
+	}
+
+	// One target disappears before the action runs
+	if err := userUseCase.DeleteUser(ids[0], ids[1]); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	*now = now.Add(time.Minute)
+	if _, err := useCase.ProcessDue(10); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+
+	failed, _ := useCase.GetAction(action.Token)
+	if failed.Status != entity.AdminActionFailed || failed.Error == "" {
+		t.Errorf("action = %+v, want failed with an error message", failed)
+	}
+	if _, err := userUseCase.GetUserByID(ids[2]); err == nil {
+		t.Error("remaining targets should still be processed")
+	}
+	if len(failed.FailedUserIDs) != 1 || failed.FailedUserIDs[0] != ids[1] {
+		t.Errorf("FailedUserIDs = %v, want [%d]", failed.FailedUserIDs, ids[1])
+	}
+}
+
+func TestAdminActionUseCase_Retry(t *testing.T) {
+	useCase, userUseCase, now, ids := setupAdminActionTest(t)
+
+	action, err := useCase.ScheduleSuspend(ids[0], []int{ids[1], ids[2]})
+	if err != nil {
+		t.Fatalf("ScheduleSuspend() error = %v", err)
+	}
+	if _, err := useCase.Retry(action.Token); !errors.Is(err, repository.ErrAdminActionNotFailed) {
+		t.Errorf("Retry() pending error = %v, want ErrAdminActionNotFailed", err)
+	}
+
+	// The second target is missing when the action first runs
+	if err := userUseCase.DeleteUser(ids[0], ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(time.Minute)
+	if _, err := useCase.ProcessDue(10); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+
+	retried, err := useCase.Retry(action.Token)
+	if err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if retried.Status != entity.AdminActionPending || retried.Error != "" || len(retried.UserIDs) != 1 || retried.UserIDs[0] != ids[2] {
+		t.Errorf("Retry() = %+v, want pending for the failed user only", retried)
+	}
+	if !retried.ExecuteAt.Equal(*now) {
+		t.Errorf("ExecuteAt = %v, want now", retried.ExecuteAt)
+	}
+
+	if _, err := useCase.Retry("missing"); !errors.Is(err, repository.ErrAdminActionNotFound) {
+		t.Errorf("Retry() error = %v, want ErrAdminActionNotFound", err)
+	}
+}
+
+func TestAdminActionUseCase_Pause(t *testing.T) {
+	useCase, userUseCase, now, ids := setupAdminActionTest(t)
+
+	if _, err := useCase.ScheduleSuspend(ids[0], []int{ids[1]}); err != nil {
+		t.Fatalf("ScheduleSuspend() error = %v", err)
+	}
+	if err := useCase.SetPaused(true); err != nil {
+		t.Fatalf("SetPaused() error = %v", err)
+	}
+
+	*now = now.Add(time.Minute)
+	if processed, err := useCase.ProcessDue(10); err != nil || processed != 0 {
+		t.Errorf("ProcessDue() = %v, %v, want 0 while paused", processed, err)
+	}
+
+	queue, err := useCase.Queue(time.Hour)
+	if err != nil {
+		t.Fatalf("Queue() error = %v", err)
+	}
+	if queue.PausedSince.IsZero() || queue.Due != 1 || queue.Counts[entity.AdminActionPending] != 1 {
+		t.Errorf("Queue() = %+v, want paused with one due action", queue)
+	}
+
+	if err := useCase.SetPaused(false); err != nil {
+		t.Fatalf("SetPaused() error = %v", err)
+	}
+	if processed, err := useCase.ProcessDue(10); err != nil || processed != 1 {
+		t.Errorf("ProcessDue() = %v, %v, want 1 once resumed", processed, err)
+	}
+	if user, _ := userUseCase.GetUserByID(ids[1]); user.Status != entity.StatusSuspended {
+		t.Errorf("user status = %v, want suspended", user.Status)
+	}
+
+	queue, err = useCase.Queue(time.Hour)
+	if err != nil {
+		t.Fatalf("Queue() error = %v", err)
+	}
+	if !queue.PausedSince.IsZero() || len(queue.Throughput) != 1 || queue.Throughput[0].Applied != 1 {
+		t.Errorf("Queue() = %+v, want running with one applied action", queue)
+	}
+	if _, err := useCase.Queue(0); !errors.Is(err, ErrInvalidAdminAction) {
+		t.Errorf("Queue(0) error = %v, want ErrInvalidAdminAction", err)
+	}
+}
+
+func TestAdminActionUseCase_ListActions(t *testing.T) {
+	useCase, _, _, ids := setupAdminActionTest(t)
+
+	first, _ := useCase.ScheduleSuspend(ids[0], []int{ids[1]})
+	second, _ := useCase.ScheduleDelete(ids[0], []int{ids[2]})
+	if _, err := useCase.Undo(first.Token); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		status entity.AdminActionStatus
+		kind   entity.AdminActionKind
+		want   []string
+	}{
+		{"", "", []string{second.Token, first.Token}},
+		{entity.AdminActionPending, "", []string{second.Token}},
+		{"", entity.AdminActionSuspendUsers, []string{first.Token}},
+		{entity.AdminActionFailed, "", nil},
+	}
+	for _, tt := range tests {
+		actions, err := useCase.ListActions(tt.status, tt.kind, 10)
+		if err != nil {
+			t.Fatalf("ListActions(%q, %q) error = %v", tt.status, tt.kind, err)
+		}
+		var got []string
+		for _, action := range actions {
+			got = append(got, action.Token)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ListActions(%q, %q) = %v, want %v", tt.status, tt.kind, got, tt.want)
+		}
+	}
+
+	if _, err := useCase.ListActions("done", "", 10); !errors.Is(err, ErrInvalidAdminAction) {
+		t.Errorf("ListActions() unknown status error = %v, want ErrInvalidAdminAction", err)
+	}
+	if _, err := useCase.ListActions("", "wipe", 10); !errors.Is(err, ErrInvalidAdminAction) {
+		t.Errorf("ListActions() unknown kind error = %v, want ErrInvalidAdminAction", err)
+	}
+}
+
+func TestAdminActionUseCase_ScheduleValidation(t *testing.T) {
+	useCase, _, _, ids := setupAdminActionTest(t)
+
+	tooMany := make([]int, MaxAdminActionUsers+1)
+	for i := range tooMany {
+		tooMany[i] = i + 100
+	}
+
+	tests := []struct {
+		name     string
+		schedule func() (*entity.AdminAction, error)
+	}{
+		{"no users", func() (*entity.AdminAction, error) { return useCase.ScheduleDelete(ids[0], nil) }},
+		{"too many users", func() (*entity.AdminAction, error) { return useCase.ScheduleSuspend(ids[0], tooMany) }},
+		{"own account", func() (*entity.AdminAction, error) { return useCase.ScheduleDelete(ids[0], []int{ids[0]}) }},
+		{"unknown user", func() (*entity.AdminAction, error) { return useCase.ScheduleSuspend(ids[0], []int{999}) }},
+		{"invalid role", func() (*entity.AdminAction, error) {
+			return useCase.ScheduleSetRole(ids[0], []int{ids[1]}, "superuser")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.schedule(); !errors.Is(err, ErrInvalidAdminAction) {
+				t.Errorf("error = %v, want ErrInvalidAdminAction", err)
+			}
+		})
+	}
+}
+
+func TestAdminActionUseCase_IncidentReset(t *testing.T) {
+	useCase, userUseCase, now, ids := setupAdminActionTest(t)
+	adminID, oneID, twoID := ids[0], ids[1], ids[2]
+
+	var delivered []*hooks.Event
+	registry := hooks.NewRegistry()
+	registry.Register(hooks.PasswordReset, hooks.HookFunc(func(e *hooks.Event) error {
+		delivered = append(delivered, e)
+		return nil
+	}))
+	userUseCase.SetHooks(registry)
+
+	// Logins during the incident, from the affected range and elsewhere
+	incident := now.Add(-2 * time.Hour)
+	loginRepo := useCase.loginRepo.(*MockLoginEventRepository)
+	for _, login := range []struct {
+		userID int
+		ip     string
+		at     time.Time
+	}{
+		{oneID, "203.0.113.5", incident},
+		{twoID, "198.51.100.1", incident},
+		{adminID, "203.0.113.9", incident},
+		{twoID, "203.0.113.6", incident.Add(-24 * time.Hour)},
+	} {
+		loginRepo.now = login.at
+		loginRepo.Record(&entity.LoginEvent{UserID: login.userID, Success: true, IP: login.ip})
+	}
+
+	segment := entity.UserSegment{Since: incident.Add(-time.Hour), Until: incident.Add(time.Hour), IPRange: "203.0.113.0/24"}
+	action, err := useCase.ScheduleIncidentReset(adminID, segment, "Action needed, {{.FullName}}", "Reset with {{.Token}}")
+	if err != nil {
+		t.Fatalf("ScheduleIncidentReset() error = %v", err)
+	}
+	if len(action.UserIDs) != 1 || action.UserIDs[0] != oneID || action.Kind != entity.AdminActionIncidentReset {
+		t.Fatalf("ScheduleIncidentReset() = %+v, want only user %d", action, oneID)
+	}
+
+	*now = now.Add(time.Minute)
+	if _, err := useCase.ProcessDue(10); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+
+	applied, _ := useCase.GetAction(action.Token)
+	if applied.Status != entity.AdminActionApplied || applied.Processed != 1 {
+		t.Errorf("action = %+v, want applied with 1 user processed", applied)
+	}
+	if _, err := userUseCase.AuthenticateUser("one@example.com", "password123"); err == nil {
+		t.Error("the old password should no longer work")
+	}
+	if len(delivered) != 1 || delivered[0].UserID != oneID || delivered[0].Data["reason"] != "forced" ||
+		delivered[0].Data["subject"] != "Action needed, Some User" || delivered[0].Data["message"] != "Reset with "+delivered[0].Data["token"].(string) {
+		t.Errorf("delivered = %+v", delivered)
+	}
+}
+
+func TestAdminActionUseCase_IncidentResetValidation(t *testing.T) {
+	useCase, _, now, ids := setupAdminActionTest(t)
+	loginRepo := useCase.loginRepo.(*MockLoginEventRepository)
+	loginRepo.now = *now
+	loginRepo.Record(&entity.LoginEvent{UserID: ids[1], Success: true, IP: "203.0.113.5"})
+	window := entity.UserSegment{Since: now.Add(-time.Hour), Until: now.Add(time.Hour)}
+
+	tests := []struct {
+		name    string
+		segment entity.UserSegment
+		subject string
+	}{
+		{"unknown template field", window, "{{.Password}}"},
+		{"malformed template", window, "{{.FullName"},
+		{"window ends before it starts", entity.UserSegment{Since: window.Until, Until: window.Since}, "Reset"},
+		{"no window", entity.UserSegment{}, "Reset"},
+		{"invalid IP range", entity.UserSegment{Since: window.Since, Until: window.Until, IPRange: "203.0.113.0/33"}, "Reset"},
+		{"nobody in the segment", entity.UserSegment{Since: window.Since, Until: window.Until, IPRange: "198.51.100.0/24"}, "Reset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := useCase.ScheduleIncidentReset(ids[0], tt.segment, tt.subject, "Please reset your password"); !errors.Is(err, ErrInvalidAdminAction) {
+				t.Errorf("error = %v, want ErrInvalidAdminAction", err)
+			}
+		})
+	}
+}
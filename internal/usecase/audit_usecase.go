@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ErrInvalidAuditFilter is returned for a limit out of range
+var ErrInvalidAuditFilter = errors.New("invalid audit filter")
+
+// Limits on listing audit entries
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// AuditUseCase reads the audit trail of admin mutations
+type AuditUseCase struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewAuditUseCase creates a new audit use case
+func NewAuditUseCase(auditRepo repository.AuditRepository) *AuditUseCase {
+	return &AuditUseCase{auditRepo: auditRepo}
+}
+
+// List returns a page of the entries matching the filter, newest first,
+// and the BeforeID of the next page, 0 on the last one. The limit defaults
+// to 50 and is at most 500.
+func (uc *AuditUseCase) List(filter repository.AuditFilter) ([]*entity.AuditEntry, int, error) {
+	switch {
+	case filter.Limit == 0:
+		filter.Limit = defaultAuditLimit
+	case filter.Limit < 0 || filter.Limit > maxAuditLimit:
+		return nil, 0, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidAuditFilter, maxAuditLimit)
+	}
+
+	entries, err := uc.auditRepo.List(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	var next int
+	if len(entries) == filter.Limit {
+		next = entries[len(entries)-1].ID
+	}
+	return entries, next, nil
+}
+
+// recordAudit stores an audit entry, if the use case has an audit trail and
+// the mutation changed anything. The change is already persisted, so a
+// failure is logged rather than returned.
+func recordAudit(auditRepo repository.AuditRepository, entry *entity.AuditEntry) {
+	if auditRepo == nil || len(entry.Changes) == 0 {
+		return
+	}
+	if err := auditRepo.Record(entry); err != nil {
+		log.Printf("Failed to record %s audit entry for %s %s: %v", entry.Action, entry.SubjectType, entry.SubjectID, err)
+	}
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock audit repository for testing
+type MockAuditRepository struct {
+	entries []*entity.AuditEntry
+}
+
+func (m *MockAuditRepository) Record(entry *entity.AuditEntry) error {
+	entry.ID = len(m.entries) + 1
+	entry.CreatedAt = time.Now()
+	stored := *entry
+	m.entries = append(m.entries, &stored)
+	return nil
+}
+
+func (m *MockAuditRepository) List(filter repository.AuditFilter) ([]*entity.AuditEntry, error) {
+	var entries []*entity.AuditEntry
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		entry := m.entries[i]
+		if (filter.BeforeID > 0 && entry.ID >= filter.BeforeID) || (filter.ActorID > 0 && entry.ActorID != filter.ActorID) ||
+			(filter.SubjectType != "" && entry.SubjectType != filter.SubjectType) || (filter.SubjectID != "" && entry.SubjectID != filter.SubjectID) {
+			continue
+		}
+		found := *entry
+		entries = append(entries, &found)
+		if len(entries) == filter.Limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+func TestUserUseCase_RecordsAudit(t *testing.T) {
+	audit := &MockAuditRepository{}
+	uc := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	uc.SetAuditLog(audit)
+
+	user, err := uc.RegisterUser("test@example.com", "password123", "John Doe", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	// Changes by the user themselves are not admin mutations
+	fullName := "Johnny Doe"
+	if _, err := uc.PatchUser(user.ID, map[string]*string{"fullName": &fullName}); err != nil {
+		t.Fatalf("PatchUser() error = %v", err)
+	}
+	if err := uc.ChangePassword(user.ID, "password123", "password456"); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if err := uc.SetUserRole(99, user.ID, entity.RoleAdmin); err != nil {
+		t.Fatalf("SetUserRole() error = %v", err)
+	}
+	// Setting the same role changes nothing, so nothing is recorded
+	if err := uc.SetUserRole(99, user.ID, entity.RoleAdmin); err != nil {
+		t.Fatalf("SetUserRole() error = %v", err)
+	}
+	if err := uc.SuspendUser(99, user.ID); err != nil {
+		t.Fatalf("SuspendUser() error = %v", err)
+	}
+	if err := uc.DeleteUser(99, user.ID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	want := []struct {
+		action   entity.AuditAction
+		field    string
+		old, new string
+	}{
+		{entity.AuditUserUpdated, "role", entity.RoleUser, entity.RoleAdmin},
+		{entity.AuditUserUpdated, "status", entity.StatusActive, entity.StatusSuspended},
+		{entity.AuditUserDeleted, "status", entity.StatusSuspended, entity.StatusDeleted},
+	}
+	if len(audit.entries) != len(want) {
+		t.Fatalf("audit entries = %d, want %d", len(audit.entries), len(want))
+	}
+	for i, w := range want {
+		entry := audit.entries[i]
+		if entry.ActorID != 99 || entry.Action != w.action || entry.SubjectType != entity.EventSubjectUser || entry.SubjectID != "1" {
+			t.Errorf("entry %d = %+v", i, entry)
+		}
+		if len(entry.Changes) != 1 || entry.Changes[0] != (entity.FieldChange{Field: w.field, Old: w.old, New: w.new}) {
+			t.Errorf("entry %d changes = %+v, want %s %s -> %s", i, entry.Changes, w.field, w.old, w.new)
+		}
+	}
+}
+
+func TestAuditUseCase_List(t *testing.T) {
+	audit := &MockAuditRepository{}
+	for _, actorID := range []int{1, 2, 1} {
+		recordAudit(audit, entity.NewAuditEntry(actorID, entity.AuditUserUpdated, entity.EventSubjectUser, "7",
+			[]entity.FieldChange{{Field: "plan", Old: "free", New: "pro"}}))
+	}
+	recordAudit(audit, entity.NewAuditEntry(1, entity.AuditUserUpdated, entity.EventSubjectUser, "7", nil))
+	if len(audit.entries) != 3 {
+		t.Fatalf("recorded %d entries, want 3 (none without changes)", len(audit.entries))
+	}
+	recordAudit(nil, entity.NewAuditEntry(1, entity.AuditUserUpdated, entity.EventSubjectUser, "7",
+		[]entity.FieldChange{{Field: "plan", Old: "free", New: "pro"}}))
+
+	uc := NewAuditUseCase(audit)
+	got, next, err := uc.List(repository.AuditFilter{ActorID: 1, Limit: 1})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 3 || next != 3 {
+		t.Errorf("List() = %+v, next %d", got, next)
+	}
+	got, next, _ = uc.List(repository.AuditFilter{ActorID: 1, BeforeID: next})
+	if len(got) != 1 || got[0].ID != 1 || next != 0 {
+		t.Errorf("List() second page = %+v, next %d", got, next)
+	}
+
+	for _, limit := range []int{-1, maxAuditLimit + 1} {
+		if _, _, err := uc.List(repository.AuditFilter{Limit: limit}); !errors.Is(err, ErrInvalidAuditFilter) {
+			t.Errorf("List(limit %d) error = %v, want ErrInvalidAuditFilter", limit, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ErrInvalidPermissionCheck is returned for a check without a relation or
+// with an object not written "<type>:<id>"
+var ErrInvalidPermissionCheck = errors.New("invalid permission check")
+
+// AuthorizationUseCase checks object-level permissions with an external
+// relationship-based authorization service and keeps users' roles there
+type AuthorizationUseCase struct {
+	store    repository.RelationshipStore
+	userRepo repository.UserRepository
+}
+
+// NewAuthorizationUseCase creates a new authorization use case
+func NewAuthorizationUseCase(store repository.RelationshipStore, userRepo repository.UserRepository) *AuthorizationUseCase {
+	return &AuthorizationUseCase{store: store, userRepo: userRepo}
+}
+
+// CheckPermission reports whether the user has relation to object, e.g.
+// CheckPermission(42, "viewer", "document:7")
+func (uc *AuthorizationUseCase) CheckPermission(userID int, relation, object string) (bool, error) {
+	objectType, id, ok := strings.Cut(object, ":")
+	if relation == "" || !ok || objectType == "" || id == "" {
+		return false, fmt.Errorf("%w: relation %q on object %q", ErrInvalidPermissionCheck, relation, object)
+	}
+
+	allowed, err := uc.store.Check(entity.Relationship{Subject: entity.UserObject(userID), Relation: relation, Object: object})
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+	return allowed, nil
+}
+
+// SyncAll writes the role relationships of every user, repairing changes
+// that failed to sync or predate the integration. Returns the number of
+// users synced.
+func (uc *AuthorizationUseCase) SyncAll() (int, error) {
+	count := 0
+	for page := (repository.Page{Limit: repository.MaxPageLimit}); ; page.Offset += page.Limit {
+		users, err := uc.userRepo.List(repository.UserFilter{}, page)
+		if err != nil {
+			return count, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		var writes, deletes []entity.Relationship
+		for _, user := range users {
+			userWrites, userDeletes := entity.RoleRelationships(user)
+			writes = append(writes, userWrites...)
+			deletes = append(deletes, userDeletes...)
+		}
+		if err := uc.store.Write(writes, deletes); err != nil {
+			return count, fmt.Errorf("failed to sync roles: %w", err)
+		}
+
+		count += len(users)
+		if len(users) < page.Limit {
+			return count, nil
+		}
+	}
+}
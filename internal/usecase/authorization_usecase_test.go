@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock relationship store for testing. Check only answers direct tuples.
+type MockRelationshipStore struct {
+	tuples map[entity.Relationship]bool
+	checks []entity.Relationship
+	err    error
+}
+
+func NewMockRelationshipStore() *MockRelationshipStore {
+	return &MockRelationshipStore{tuples: make(map[entity.Relationship]bool)}
+}
+
+func (m *MockRelationshipStore) Write(writes, deletes []entity.Relationship) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, tuple := range writes {
+		m.tuples[tuple] = true
+	}
+	for _, tuple := range deletes {
+		delete(m.tuples, tuple)
+	}
+	return nil
+}
+
+func (m *MockRelationshipStore) Check(tuple entity.Relationship) (bool, error) {
+	m.checks = append(m.checks, tuple)
+	return m.tuples[tuple], m.err
+}
+
+var _ repository.RelationshipStore = (*MockRelationshipStore)(nil)
+
+func TestAuthorizationUseCase_CheckPermission(t *testing.T) {
+	store := NewMockRelationshipStore()
+	store.tuples[entity.Relationship{Subject: "user:7", Relation: "viewer", Object: "document:1"}] = true
+	useCase := NewAuthorizationUseCase(store, NewMockUserRepository())
+
+	tests := []struct {
+		name     string
+		relation string
+		object   string
+		want     bool
+		wantErr  error
+	}{
+		{"allowed", "viewer", "document:1", true, nil},
+		{"other object", "viewer", "document:2", false, nil},
+		{"other relation", "editor", "document:1", false, nil},
+		{"no relation", "", "document:1", false, ErrInvalidPermissionCheck},
+		{"no type", "viewer", ":1", false, ErrInvalidPermissionCheck},
+		{"no id", "viewer", "document:", false, ErrInvalidPermissionCheck},
+		{"not an object", "viewer", "document", false, ErrInvalidPermissionCheck},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := useCase.CheckPermission(7, tt.relation, tt.object)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("CheckPermission() = %v, %v; want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+	if len(store.checks) != 3 {
+		t.Errorf("invalid checks reached the store: %v", store.checks)
+	}
+
+	store.err = errors.New("unavailable")
+	if _, err := useCase.CheckPermission(7, "viewer", "document:1"); err == nil {
+		t.Error("CheckPermission() should fail when the store does")
+	}
+}
+
+func TestAuthorizationUseCase_SyncAll(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	admin, _ := userRepo.Create(entity.NewUser("admin@example.com", "hash", "Admin", "0812345678", "1990-01-15"))
+	admin.Role = entity.RoleAdmin
+	suspended, _ := userRepo.Create(entity.NewUser("suspended@example.com", "hash", "Suspended", "0812345678", "1990-01-15"))
+	suspended.Status = entity.StatusSuspended
+
+	store := NewMockRelationshipStore()
+	// Stale assignments from before the role change and suspension
+	store.tuples[entity.Relationship{Subject: entity.UserObject(admin.ID), Relation: entity.RelationAssignee, Object: "role:user"}] = true
+	store.tuples[entity.Relationship{Subject: entity.UserObject(suspended.ID), Relation: entity.RelationAssignee, Object: "role:user"}] = true
+
+	useCase := NewAuthorizationUseCase(store, userRepo)
+	count, err := useCase.SyncAll()
+	if err != nil || count != 2 {
+		t.Fatalf("SyncAll() = %d, %v; want 2", count, err)
+	}
+
+	want := map[entity.Relationship]bool{
+		{Subject: entity.UserObject(admin.ID), Relation: entity.RelationAssignee, Object: "role:admin"}: true,
+	}
+	if len(store.tuples) != len(want) {
+		t.Fatalf("tuples = %v, want %v", store.tuples, want)
+	}
+	for tuple := range want {
+		if !store.tuples[tuple] {
+			t.Errorf("missing tuple %+v", tuple)
+		}
+	}
+
+	store.err = errors.New("unavailable")
+	if _, err := useCase.SyncAll(); err == nil {
+		t.Error("SyncAll() should fail when the store does")
+	}
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"errors"
+	"net/http"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// MaxAvatarBytes is the maximum accepted avatar image size
+const MaxAvatarBytes = 2 << 20
+
+// allowedAvatarTypes lists the image types accepted as avatars
+var allowedAvatarTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// AvatarUseCase handles user avatar business logic
+type AvatarUseCase struct {
+	userRepo      repository.UserRepository
+	avatarStorage repository.AvatarStorage
+	revisionRepo  repository.UserRevisionRepository
+}
+
+// NewAvatarUseCase creates a new avatar use case
+func NewAvatarUseCase(userRepo repository.UserRepository, avatarStorage repository.AvatarStorage, revisionRepo repository.UserRevisionRepository) *AvatarUseCase {
+	return &AvatarUseCase{
+		userRepo:      userRepo,
+		avatarStorage: avatarStorage,
+		revisionRepo:  revisionRepo,
+	}
+}
+
+// ValidateAvatar checks the avatar size and detects its content type from the data
+func (uc *AvatarUseCase) ValidateAvatar(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("avatar must not be empty")
+	}
+	if len(data) > MaxAvatarBytes {
+		return "", errors.New("avatar must not exceed 2MB")
+	}
+
+	contentType := http.DetectContentType(data)
+	if !allowedAvatarTypes[contentType] {
+		return "", errors.New("avatar must be a PNG, JPEG, GIF or WebP image")
+	}
+
+	return contentType, nil
+}
+
+// SetAvatar validates, stores and assigns an avatar image to a user
+func (uc *AvatarUseCase) SetAvatar(userID int, data []byte) (*entity.User, error) {
+	contentType, err := uc.ValidateAvatar(data)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	url, err := uc.avatarStorage.Save(userID, contentType, data)
+	if err != nil {
+		return nil, errors.New("failed to store avatar")
+	}
+
+	before := *user
+	user.Avatar = url
+	if err := uc.userRepo.Update(user); err != nil {
+		return nil, errors.New("failed to save user")
+	}
+	recordRevision(uc.revisionRepo, userID, &before, user)
+
+	return user.WithoutPassword(), nil
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// Mock avatar storage for testing
+type MockAvatarStorage struct {
+	saved map[int][]byte
+	err   error
+}
+
+func (m *MockAvatarStorage) Save(userID int, contentType string, data []byte) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	if m.saved == nil {
+		m.saved = make(map[int][]byte)
+	}
+	m.saved[userID] = data
+	return "/uploads/avatars/test.png", nil
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n0000")
+
+func TestAvatarUseCase_ValidateAvatar(t *testing.T) {
+	useCase := NewAvatarUseCase(NewMockUserRepository(), &MockAvatarStorage{}, NewMockUserRevisionRepository())
+
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		errorMsg    string
+	}{
+		{"png image", pngHeader, "image/png", ""},
+		{"gif image", []byte("GIF89a000000"), "image/gif", ""},
+		{"empty data", nil, "", "avatar must not be empty"},
+		{"not an image", []byte("<html><body>hi</body></html>"), "", "avatar must be a PNG, JPEG, GIF or WebP image"},
+		{"too large", append(pngHeader, bytes.Repeat([]byte{0}, MaxAvatarBytes)...), "", "avatar must not exceed 2MB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType, err := useCase.ValidateAvatar(tt.data)
+			if tt.errorMsg != "" {
+				if err == nil || err.Error() != tt.errorMsg {
+					t.Errorf("ValidateAvatar() error = %v, want %v", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateAvatar() error = %v", err)
+			}
+			if contentType != tt.contentType {
+				t.Errorf("contentType = %v, want %v", contentType, tt.contentType)
+			}
+		})
+	}
+}
+
+func TestAvatarUseCase_SetAvatar(t *testing.T) {
+	mockRepo := NewMockUserRepository()
+	mockStorage := &MockAvatarStorage{}
+	mockRevisions := NewMockUserRevisionRepository()
+	userUseCase := NewUserUseCase(mockRepo, mockRevisions)
+	useCase := NewAvatarUseCase(mockRepo, mockStorage, mockRevisions)
+
+	registered, err := userUseCase.RegisterUser("avatar@example.com", "password123", "Avatar User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	user, err := useCase.SetAvatar(registered.ID, pngHeader)
+	if err != nil {
+		t.Fatalf("SetAvatar() error = %v", err)
+	}
+	if user.Avatar != "/uploads/avatars/test.png" {
+		t.Errorf("Avatar = %v, want /uploads/avatars/test.png", user.Avatar)
+	}
+	if user.Password != "" {
+		t.Error("Password should be empty in returned user")
+	}
+
+	stored, _ := mockRepo.GetByID(registered.ID)
+	if stored.Avatar != user.Avatar {
+		t.Errorf("stored Avatar = %v, want %v", stored.Avatar, user.Avatar)
+	}
+
+	if len(mockRevisions.revisions) != 1 || mockRevisions.revisions[0].Changes[0].Field != "avatar" {
+		t.Errorf("SetAvatar() should record an avatar revision, got %+v", mockRevisions.revisions)
+	}
+}
+
+func TestAvatarUseCase_SetAvatar_Errors(t *testing.T) {
+	mockRepo := NewMockUserRepository()
+	userUseCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
+	registered, err := userUseCase.RegisterUser("avatar@example.com", "password123", "Avatar User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	useCase := NewAvatarUseCase(mockRepo, &MockAvatarStorage{}, NewMockUserRevisionRepository())
+	if _, err := useCase.SetAvatar(999, pngHeader); err == nil || err.Error() != "user not found" {
+		t.Errorf("SetAvatar() error = %v, want 'user not found'", err)
+	}
+
+	failing := NewAvatarUseCase(mockRepo, &MockAvatarStorage{err: errors.New("disk full")}, NewMockUserRevisionRepository())
+	if _, err := failing.SetAvatar(registered.ID, pngHeader); err == nil || err.Error() != "failed to store avatar" {
+		t.Errorf("SetAvatar() error = %v, want 'failed to store avatar'", err)
+	}
+}
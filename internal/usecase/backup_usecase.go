@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// BackupUseCase takes database backups and prunes old ones
+type BackupUseCase struct {
+	store     repository.BackupStore
+	retention int
+	now       func() time.Time
+}
+
+// NewBackupUseCase creates a new backup use case keeping the newest retention
+// backups; zero or less keeps them all
+func NewBackupUseCase(store repository.BackupStore, retention int) *BackupUseCase {
+	return &BackupUseCase{
+		store:     store,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// Backup creates a verified backup, then deletes the backups beyond the
+// retention count. Failing to delete an old backup is logged, not returned.
+func (uc *BackupUseCase) Backup() (*entity.Backup, error) {
+	backup, err := uc.store.Create()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	if err := uc.prune(); err != nil {
+		log.Printf("Failed to prune old backups: %v", err)
+	}
+	return backup, nil
+}
+
+// BackupIfDue creates a backup when the newest one is at least interval old,
+// so restarts do not add a backup each time. Returns nil when none was due.
+func (uc *BackupUseCase) BackupIfDue(interval time.Duration) (*entity.Backup, error) {
+	backups, err := uc.store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	if len(backups) > 0 && uc.now().Sub(backups[0].CreatedAt) < interval {
+		return nil, nil
+	}
+	return uc.Backup()
+}
+
+// List returns the stored backups, newest first
+func (uc *BackupUseCase) List() ([]entity.Backup, error) {
+	backups, err := uc.store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	return backups, nil
+}
+
+// prune deletes the backups beyond the retention count, oldest first
+func (uc *BackupUseCase) prune() error {
+	if uc.retention <= 0 {
+		return nil
+	}
+
+	backups, err := uc.store.List()
+	if err != nil {
+		return err
+	}
+	for i := len(backups) - 1; i >= uc.retention; i-- {
+		if err := uc.store.Delete(backups[i].Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
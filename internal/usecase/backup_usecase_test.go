@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// Mock backup store for testing
+type MockBackupStore struct {
+	backups   []entity.Backup
+	now       time.Time
+	createErr error
+}
+
+func (m *MockBackupStore) Create() (*entity.Backup, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	m.now = m.now.Add(time.Hour)
+	backup := entity.Backup{Name: fmt.Sprintf("backup-%d.db", m.now.Unix()), CreatedAt: m.now}
+	m.backups = append(m.backups, backup)
+	return &backup, nil
+}
+
+func (m *MockBackupStore) List() ([]entity.Backup, error) {
+	backups := append([]entity.Backup(nil), m.backups...)
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+func (m *MockBackupStore) Delete(name string) error {
+	for i, backup := range m.backups {
+		if backup.Name == name {
+			m.backups = append(m.backups[:i], m.backups[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+func TestBackupUseCase_Retention(t *testing.T) {
+	store := &MockBackupStore{now: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+	uc := NewBackupUseCase(store, 2)
+
+	var last *entity.Backup
+	for i := 0; i < 4; i++ {
+		backup, err := uc.Backup()
+		if err != nil {
+			t.Fatalf("Backup() error = %v", err)
+		}
+		last = backup
+	}
+
+	backups, _ := uc.List()
+	if len(backups) != 2 || backups[0].Name != last.Name {
+		t.Errorf("List() = %+v, want the 2 newest backups", backups)
+	}
+}
+
+func TestBackupUseCase_BackupIfDue(t *testing.T) {
+	store := &MockBackupStore{now: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+	uc := NewBackupUseCase(store, 0)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantNew bool
+	}{
+		{"no backups yet", store.now, true},
+		{"newest is recent", store.now.Add(2 * time.Hour), false},
+		{"newest is old enough", store.now.Add(25 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc.now = func() time.Time { return tt.now }
+			backup, err := uc.BackupIfDue(24 * time.Hour)
+			if err != nil {
+				t.Fatalf("BackupIfDue() error = %v", err)
+			}
+			if (backup != nil) != tt.wantNew {
+				t.Errorf("BackupIfDue() = %+v, want new backup %v", backup, tt.wantNew)
+			}
+		})
+	}
+}
+
+func TestBackupUseCase_CreateError(t *testing.T) {
+	uc := NewBackupUseCase(&MockBackupStore{createErr: errors.New("disk full")}, 7)
+
+	if _, err := uc.Backup(); err == nil {
+		t.Error("Backup() should fail when the store fails")
+	}
+}
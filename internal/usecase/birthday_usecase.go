@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/hooks"
+)
+
+// BirthdayUseCase greets users on their birthday, in their time zone, with
+// a user.birthday event and the Birthday hooks, e.g. webhooks that send
+// birthday emails or promotions
+type BirthdayUseCase struct {
+	userRepo     repository.UserRepository
+	birthdayRepo repository.BirthdayRepository
+	eventRepo    repository.EventRepository
+	hooks        *hooks.Registry
+	location     *time.Location
+	at           time.Duration
+	now          func() time.Time
+}
+
+// NewBirthdayUseCase creates a new birthday use case greeting users at the
+// time of day at in their time zone, or in location for users without one
+func NewBirthdayUseCase(userRepo repository.UserRepository, birthdayRepo repository.BirthdayRepository, eventRepo repository.EventRepository,
+	hookRegistry *hooks.Registry, location *time.Location, at time.Duration) *BirthdayUseCase {
+	return &BirthdayUseCase{
+		userRepo:     userRepo,
+		birthdayRepo: birthdayRepo,
+		eventRepo:    eventRepo,
+		hooks:        hookRegistry,
+		location:     location,
+		at:           at,
+		now:          time.Now,
+	}
+}
+
+// Celebrate greets every active user whose birthday it is in their time
+// zone, once their local time reaches the time of day, and at most once a
+// year. Returns how many users were greeted.
+func (uc *BirthdayUseCase) Celebrate() (int, error) {
+	now := uc.now()
+	greeted := 0
+	page := repository.Page{Limit: repository.MaxPageLimit}
+	for {
+		users, err := uc.userRepo.List(repository.UserFilter{Status: entity.StatusActive}, page)
+		if err != nil {
+			return greeted, err
+		}
+		for _, user := range users {
+			ok, err := uc.celebrate(user, now)
+			if err != nil {
+				return greeted, err
+			}
+			if ok {
+				greeted++
+			}
+		}
+		if len(users) < page.Limit {
+			return greeted, nil
+		}
+		page.Offset += page.Limit
+	}
+}
+
+// celebrate greets user if it is their birthday at now and they were not
+// greeted this year yet
+func (uc *BirthdayUseCase) celebrate(user *entity.User, now time.Time) (bool, error) {
+	// Users provisioned without a birthday have none to celebrate
+	birthday, err := time.Parse("2006-01-02", user.Birthday)
+	if err != nil {
+		return false, nil
+	}
+	location := uc.userLocation(user)
+	local := now.In(location)
+	month, day := birthdayIn(birthday, local.Year())
+	age := local.Year() - birthday.Year()
+	timeOfDay := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	if local.Month() != month || local.Day() != day || age < 1 || timeOfDay < uc.at {
+		return false, nil
+	}
+
+	claimed, err := uc.birthdayRepo.Claim(user.ID, local.Year())
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	date := local.Format("2006-01-02")
+	uc.hooks.Run(&hooks.Event{Point: hooks.Birthday, UserID: user.ID, Email: user.Email, Data: map[string]interface{}{
+		"fullName": user.FullName,
+		"date":     date,
+		"timezone": location.String(),
+		"age":      age,
+	}})
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventUserBirthday, entity.EventSubjectUser, user.ID, 0, map[string]interface{}{
+		"date":     date,
+		"timezone": location.String(),
+		"age":      age,
+	}))
+	return true, nil
+}
+
+// userLocation returns the time zone of user, or the default one when they
+// have none or it is no longer known
+func (uc *BirthdayUseCase) userLocation(user *entity.User) *time.Location {
+	if user.Timezone == "" {
+		return uc.location
+	}
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		log.Printf("Unknown time zone %q of user %d, using %s", user.Timezone, user.ID, uc.location)
+		return uc.location
+	}
+	return location
+}
+
+// birthdayIn returns the month and day of a birthday in year: February 29
+// birthdays are celebrated on the 28th in common years
+func birthdayIn(birthday time.Time, year int) (time.Month, int) {
+	if birthday.Month() == time.February && birthday.Day() == 29 && time.Date(year, time.February, 29, 0, 0, 0, 0, time.UTC).Day() != 29 {
+		return time.February, 28
+	}
+	return birthday.Month(), birthday.Day()
+}
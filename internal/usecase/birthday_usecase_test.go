@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/pkg/hooks"
+)
+
+// Mock birthday repository for testing
+type MockBirthdayRepository struct {
+	claimed map[string]bool
+}
+
+func (m *MockBirthdayRepository) Claim(userID, year int) (bool, error) {
+	key := fmt.Sprintf("%d/%d", userID, year)
+	if m.claimed[key] {
+		return false, nil
+	}
+	m.claimed[key] = true
+	return true, nil
+}
+
+func TestBirthdayUseCase_Celebrate(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	for _, user := range []struct{ email, birthday, timezone string }{
+		// 10:00 on June 3 in Bangkok
+		{"bangkok@example.com", "1990-06-03", "Asia/Bangkok"},
+		// 03:00 on June 3 in UTC, before the greeting time
+		{"utc@example.com", "1990-06-03", ""},
+		// 23:00 on June 2 in New York
+		{"newyork@example.com", "1985-06-02", "America/New_York"},
+		{"tomorrow@example.com", "1990-06-04", "Asia/Bangkok"},
+		{"unborn@example.com", "2025-06-03", "Asia/Bangkok"},
+		{"scim@example.com", "", "Asia/Bangkok"},
+	} {
+		created, err := userRepo.Create(entity.NewUser(user.email, "hash", "Birthday User", "0812345678", user.birthday))
+		if err != nil {
+			t.Fatal(err)
+		}
+		created.Timezone = user.timezone
+	}
+
+	var greeted []*hooks.Event
+	registry := hooks.NewRegistry()
+	registry.Register(hooks.Birthday, hooks.HookFunc(func(event *hooks.Event) error {
+		greeted = append(greeted, event)
+		return nil
+	}))
+	events := &MockEventRepository{}
+	useCase := NewBirthdayUseCase(userRepo, &MockBirthdayRepository{claimed: make(map[string]bool)}, events, registry, time.UTC, 9*time.Hour)
+	useCase.now = func() time.Time { return time.Date(2025, 6, 3, 3, 0, 0, 0, time.UTC) }
+
+	count, err := useCase.Celebrate()
+	if err != nil {
+		t.Fatalf("Celebrate() error = %v", err)
+	}
+	if count != 2 || len(greeted) != 2 || len(events.events) != 2 {
+		t.Fatalf("Celebrate() = %d with %d hook events and %d domain events, want 2", count, len(greeted), len(events.events))
+	}
+	want := map[string]string{"bangkok@example.com": "2025-06-03 Asia/Bangkok 35", "newyork@example.com": "2025-06-02 America/New_York 40"}
+	for _, event := range greeted {
+		if got := fmt.Sprintf("%s %s %d", event.Data["date"], event.Data["timezone"], event.Data["age"]); got != want[event.Email] {
+			t.Errorf("%s greeted with %s, want %s", event.Email, got, want[event.Email])
+		}
+	}
+	if event := events.events[0]; event.Type != entity.EventUserBirthday || event.ActorID != 0 {
+		t.Errorf("event = %+v, want a user.birthday event by the system", event)
+	}
+
+	// Users are greeted once a year
+	if count, err := useCase.Celebrate(); err != nil || count != 0 {
+		t.Errorf("Celebrate() again = %d, %v; want 0", count, err)
+	}
+}
+
+func TestBirthdayIn(t *testing.T) {
+	tests := []struct {
+		birthday string
+		year     int
+		month    time.Month
+		day      int
+	}{
+		{"1990-06-03", 2025, time.June, 3},
+		{"1992-02-29", 2024, time.February, 29},
+		{"1992-02-29", 2025, time.February, 28},
+		{"1992-02-28", 2025, time.February, 28},
+	}
+	for _, tt := range tests {
+		birthday, _ := time.Parse("2006-01-02", tt.birthday)
+		if month, day := birthdayIn(birthday, tt.year); month != tt.month || day != tt.day {
+			t.Errorf("birthdayIn(%s, %d) = %s %d, want %s %d", tt.birthday, tt.year, month, day, tt.month, tt.day)
+		}
+	}
+}
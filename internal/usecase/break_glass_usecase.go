@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/shamir"
+	"fiber-hello-world/pkg/textnorm"
+)
+
+// Break-glass errors
+var (
+	// ErrInvalidCeremony is returned for a share count or threshold out of range
+	ErrInvalidCeremony = errors.New("invalid key ceremony")
+	// ErrBreakGlassNotSealed is returned when unsealing without an unused seal
+	ErrBreakGlassNotSealed = errors.New("no break-glass seal to open")
+	// ErrBreakGlassInvalidShares is returned when the shares do not unseal the seal
+	ErrBreakGlassInvalidShares = errors.New("shares do not unseal the break-glass seal")
+	// ErrBreakGlassReasonRequired is returned when unsealing without a reason
+	ErrBreakGlassReasonRequired = errors.New("a reason is required to break the glass")
+	// ErrBreakGlassUser is returned when the account to grant admin access to
+	// does not exist or cannot sign in
+	ErrBreakGlassUser = errors.New("account cannot receive break-glass access")
+	// ErrBreakGlassSessionNotFound is returned when no open session matches
+	ErrBreakGlassSessionNotFound = repository.ErrBreakGlassSessionNotFound
+)
+
+// maxCeremonyShares bounds the custodians of a key ceremony
+const maxCeremonyShares = 16
+
+// breakGlassSecretSize is the size in bytes of the emergency secret
+const breakGlassSecretSize = 32
+
+// BreakGlassUseCase runs key ceremonies, which seal an emergency secret
+// split among custodians, and opens time-limited admin access for one
+// account when enough custodians bring their shares back, e.g. when every
+// admin is locked out. Every step is recorded in the event log; access is
+// only granted once its event is recorded.
+type BreakGlassUseCase struct {
+	breakGlassRepo repository.BreakGlassRepository
+	userRepo       repository.UserRepository
+	eventRepo      repository.EventRepository
+	ttl            time.Duration
+	now            func() time.Time
+}
+
+// NewBreakGlassUseCase creates a new break-glass use case whose sessions
+// last ttl
+func NewBreakGlassUseCase(breakGlassRepo repository.BreakGlassRepository, userRepo repository.UserRepository, eventRepo repository.EventRepository, ttl time.Duration) *BreakGlassUseCase {
+	return &BreakGlassUseCase{
+		breakGlassRepo: breakGlassRepo,
+		userRepo:       userRepo,
+		eventRepo:      eventRepo,
+		ttl:            ttl,
+		now:            time.Now,
+	}
+}
+
+// Seal runs a key ceremony: it generates a new emergency secret, splits it
+// into shares, threshold of which unseal it, and stores only its hash. The
+// shares are returned hex-encoded, once, for the custodians. The previous
+// unused seal is revoked.
+func (uc *BreakGlassUseCase) Seal(shares, threshold, adminID int) (*entity.BreakGlassSeal, []string, error) {
+	if shares > maxCeremonyShares || threshold < 2 || threshold > shares {
+		return nil, nil, fmt.Errorf("%w: threshold must be at least 2 and at most shares, at most %d", ErrInvalidCeremony, maxCeremonyShares)
+	}
+	secret := make([]byte, breakGlassSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, nil, err
+	}
+	parts, err := shamir.Split(secret, shares, threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seal := &entity.BreakGlassSeal{
+		Shares:     shares,
+		Threshold:  threshold,
+		SecretHash: hashBreakGlassSecret(secret),
+		CreatedBy:  adminID,
+		CreatedAt:  uc.now().UTC(),
+	}
+	if err := uc.breakGlassRepo.CreateSeal(seal); err != nil {
+		return nil, nil, fmt.Errorf("failed to store seal: %w", err)
+	}
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventBreakGlassSealed, entity.EventSubjectBreakGlass, seal.ID, adminID, map[string]interface{}{
+		"shares":    shares,
+		"threshold": threshold,
+	}))
+	log.Printf("Break-glass key ceremony by user %d: seal %d split into %d shares, %d to unseal", adminID, seal.ID, shares, threshold)
+
+	encoded := make([]string, len(parts))
+	for i, part := range parts {
+		encoded[i] = hex.EncodeToString(part)
+	}
+	return seal, encoded, nil
+}
+
+// ActiveSeal returns the seal that can be opened, nil when there is none
+func (uc *BreakGlassUseCase) ActiveSeal() (*entity.BreakGlassSeal, error) {
+	seal, err := uc.breakGlassRepo.ActiveSeal()
+	if errors.Is(err, repository.ErrBreakGlassSealNotFound) {
+		return nil, nil
+	}
+	return seal, err
+}
+
+// Unseal opens the seal with the custodians' shares and grants admin
+// access to the account with email until the session expires. The seal is
+// used up, so a new ceremony must follow.
+func (uc *BreakGlassUseCase) Unseal(shares []string, email, reason string) (*entity.BreakGlassSession, *entity.User, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, nil, ErrBreakGlassReasonRequired
+	}
+	seal, err := uc.breakGlassRepo.ActiveSeal()
+	if errors.Is(err, repository.ErrBreakGlassSealNotFound) {
+		return nil, nil, ErrBreakGlassNotSealed
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parts := make([][]byte, 0, len(shares))
+	for _, share := range shares {
+		part, err := hex.DecodeString(strings.TrimSpace(share))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrBreakGlassInvalidShares, err)
+		}
+		parts = append(parts, part)
+	}
+	secret, err := shamir.Combine(parts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrBreakGlassInvalidShares, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashBreakGlassSecret(secret)), []byte(seal.SecretHash)) != 1 {
+		log.Printf("Break-glass unseal of seal %d failed: shares do not match", seal.ID)
+		return nil, nil, ErrBreakGlassInvalidShares
+	}
+
+	user, err := uc.userRepo.GetByEmail(textnorm.Identifier(email))
+	if err != nil || !user.CanSignIn() {
+		return nil, nil, fmt.Errorf("%w: %q", ErrBreakGlassUser, email)
+	}
+
+	now := uc.now().UTC()
+	session := &entity.BreakGlassSession{UserID: user.ID, Reason: reason, OpenedAt: now, ExpiresAt: now.Add(uc.ttl)}
+	// The audit record comes first: no access without it
+	err = uc.eventRepo.Append(entity.NewDomainEvent(entity.EventBreakGlassOpened, entity.EventSubjectBreakGlass, seal.ID, user.ID, map[string]interface{}{
+		"userId":    user.ID,
+		"reason":    reason,
+		"expiresAt": session.ExpiresAt,
+	}))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to record break-glass access: %w", err)
+	}
+	if err := uc.breakGlassRepo.Open(seal.ID, session); err != nil {
+		if errors.Is(err, repository.ErrBreakGlassSealNotFound) {
+			return nil, nil, ErrBreakGlassNotSealed
+		}
+		return nil, nil, err
+	}
+	log.Printf("BREAK-GLASS: seal %d opened, user %d (%s) has admin access until %s: %s", seal.ID, user.ID, user.Email, session.ExpiresAt.Format(time.RFC3339), reason)
+	return session, user, nil
+}
+
+// Allowed reports whether a user holds an open break-glass session
+func (uc *BreakGlassUseCase) Allowed(userID int) (bool, error) {
+	_, err := uc.breakGlassRepo.ActiveSession(userID, uc.now())
+	if errors.Is(err, repository.ErrBreakGlassSessionNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Sessions returns up to limit sessions, newest first
+func (uc *BreakGlassUseCase) Sessions(limit int) ([]*entity.BreakGlassSession, error) {
+	return uc.breakGlassRepo.ListSessions(limit)
+}
+
+// Close ends an open session before it expires
+func (uc *BreakGlassUseCase) Close(id, adminID int) (*entity.BreakGlassSession, error) {
+	session, err := uc.breakGlassRepo.Close(id, adminID, uc.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	uc.recordClosed(session, adminID, "closed")
+	return session, nil
+}
+
+// CloseExpired closes the sessions past their expiry, so the event log
+// records the end of each. It returns how many were closed.
+func (uc *BreakGlassUseCase) CloseExpired() (int, error) {
+	expired, err := uc.breakGlassRepo.CloseExpired(uc.now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	for _, session := range expired {
+		uc.recordClosed(session, 0, "expired")
+	}
+	return len(expired), nil
+}
+
+// recordClosed records the end of a session
+func (uc *BreakGlassUseCase) recordClosed(session *entity.BreakGlassSession, actorID int, reason string) {
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventBreakGlassClosed, entity.EventSubjectBreakGlass, session.SealID, actorID, map[string]interface{}{
+		"userId": session.UserID,
+		"reason": reason,
+	}))
+	log.Printf("BREAK-GLASS: admin access of user %d %s", session.UserID, reason)
+}
+
+// hashBreakGlassSecret returns the stored hash of an emergency secret
+func hashBreakGlassSecret(secret []byte) string {
+	sum := sha256.Sum256(secret)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock break-glass repository for testing
+type MockBreakGlassRepository struct {
+	seals    []*entity.BreakGlassSeal
+	sessions []*entity.BreakGlassSession
+}
+
+func (m *MockBreakGlassRepository) CreateSeal(seal *entity.BreakGlassSeal) error {
+	for _, old := range m.seals {
+		if old.UsedAt == nil && old.RevokedAt == nil {
+			old.RevokedAt = &seal.CreatedAt
+		}
+	}
+	seal.ID = len(m.seals) + 1
+	m.seals = append(m.seals, seal)
+	return nil
+}
+
+func (m *MockBreakGlassRepository) ActiveSeal() (*entity.BreakGlassSeal, error) {
+	for _, seal := range m.seals {
+		if seal.UsedAt == nil && seal.RevokedAt == nil {
+			return seal, nil
+		}
+	}
+	return nil, repository.ErrBreakGlassSealNotFound
+}
+
+func (m *MockBreakGlassRepository) Open(sealID int, session *entity.BreakGlassSession) error {
+	seal, err := m.ActiveSeal()
+	if err != nil || seal.ID != sealID {
+		return repository.ErrBreakGlassSealNotFound
+	}
+	seal.UsedAt = &session.OpenedAt
+	session.ID = len(m.sessions) + 1
+	session.SealID = sealID
+	m.sessions = append(m.sessions, session)
+	return nil
+}
+
+func (m *MockBreakGlassRepository) ActiveSession(userID int, now time.Time) (*entity.BreakGlassSession, error) {
+	for _, session := range m.sessions {
+		if session.UserID == userID && session.Active(now) {
+			return session, nil
+		}
+	}
+	return nil, repository.ErrBreakGlassSessionNotFound
+}
+
+func (m *MockBreakGlassRepository) ListSessions(limit int) ([]*entity.BreakGlassSession, error) {
+	return m.sessions, nil
+}
+
+func (m *MockBreakGlassRepository) Close(id, closedBy int, now time.Time) (*entity.BreakGlassSession, error) {
+	for _, session := range m.sessions {
+		if session.ID == id && session.Active(now) {
+			session.ClosedAt = &now
+			session.ClosedBy = closedBy
+			return session, nil
+		}
+	}
+	return nil, repository.ErrBreakGlassSessionNotFound
+}
+
+func (m *MockBreakGlassRepository) CloseExpired(now time.Time) ([]*entity.BreakGlassSession, error) {
+	var expired []*entity.BreakGlassSession
+	for _, session := range m.sessions {
+		if session.ClosedAt == nil && !now.Before(session.ExpiresAt) {
+			closedAt := session.ExpiresAt
+			session.ClosedAt = &closedAt
+			expired = append(expired, session)
+		}
+	}
+	return expired, nil
+}
+
+// failingEventRepository refuses to append events
+type failingEventRepository struct {
+	MockEventRepository
+}
+
+func (m *failingEventRepository) Append(event *entity.DomainEvent) error {
+	return errors.New("event log unavailable")
+}
+
+func TestBreakGlassUseCase_Unseal(t *testing.T) {
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	userRepo := NewMockUserRepository()
+	user, _ := userRepo.Create(entity.NewUser("oncall@example.com", "hash", "On Call", "0812345678", "1990-01-15"))
+	suspended := entity.NewUser("gone@example.com", "hash", "Gone", "0899999999", "1990-01-15")
+	suspended.Status = entity.StatusSuspended
+	userRepo.Create(suspended)
+
+	breakGlassRepo := &MockBreakGlassRepository{}
+	events := &MockEventRepository{}
+	uc := NewBreakGlassUseCase(breakGlassRepo, userRepo, events, time.Hour)
+	uc.now = func() time.Time { return now }
+
+	if _, _, err := uc.Unseal([]string{"00", "11"}, user.Email, "locked out"); !errors.Is(err, ErrBreakGlassNotSealed) {
+		t.Errorf("Unseal() before a ceremony error = %v, want ErrBreakGlassNotSealed", err)
+	}
+	for _, tt := range []struct{ shares, threshold int }{{3, 1}, {3, 4}, {17, 2}} {
+		if _, _, err := uc.Seal(tt.shares, tt.threshold, 1); !errors.Is(err, ErrInvalidCeremony) {
+			t.Errorf("Seal(%d, %d) error = %v, want ErrInvalidCeremony", tt.shares, tt.threshold, err)
+		}
+	}
+
+	seal, shares, err := uc.Seal(5, 3, 1)
+	if err != nil || len(shares) != 5 || seal.Threshold != 3 || seal.SecretHash == "" {
+		t.Fatalf("Seal() = %+v, %d shares, %v", seal, len(shares), err)
+	}
+
+	tests := []struct {
+		name   string
+		shares []string
+		email  string
+		reason string
+		want   error
+	}{
+		{"no reason", shares[:3], user.Email, " ", ErrBreakGlassReasonRequired},
+		{"too few shares", shares[:2], user.Email, "locked out", ErrBreakGlassInvalidShares},
+		{"malformed share", []string{shares[0], shares[1], "zz"}, user.Email, "locked out", ErrBreakGlassInvalidShares},
+		{"unknown account", shares[2:], "nobody@example.com", "locked out", ErrBreakGlassUser},
+		{"suspended account", shares[2:], "gone@example.com", "locked out", ErrBreakGlassUser},
+	}
+	for _, tt := range tests {
+		if _, _, err := uc.Unseal(tt.shares, tt.email, tt.reason); !errors.Is(err, tt.want) {
+			t.Errorf("Unseal() with %s error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	session, got, err := uc.Unseal([]string{shares[4], shares[0], shares[2]}, "oncall@example.com", "all admins locked out")
+	if err != nil || got.ID != user.ID || !session.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("Unseal() = %+v, %+v, %v", session, got, err)
+	}
+	if allowed, err := uc.Allowed(user.ID); err != nil || !allowed {
+		t.Errorf("Allowed() = %v, %v; want true", allowed, err)
+	}
+	// The seal is used up
+	if _, _, err := uc.Unseal(shares[:3], user.Email, "again"); !errors.Is(err, ErrBreakGlassNotSealed) {
+		t.Errorf("Unseal() again error = %v, want ErrBreakGlassNotSealed", err)
+	}
+
+	// Sessions expire on their own
+	now = now.Add(time.Hour)
+	if allowed, _ := uc.Allowed(user.ID); allowed {
+		t.Error("Allowed() after expiry = true")
+	}
+	if closed, err := uc.CloseExpired(); err != nil || closed != 1 {
+		t.Errorf("CloseExpired() = %d, %v; want 1", closed, err)
+	}
+
+	want := []entity.EventType{entity.EventBreakGlassSealed, entity.EventBreakGlassOpened, entity.EventBreakGlassClosed}
+	if !reflect.DeepEqual(events.types(), want) {
+		t.Errorf("events = %v, want %v", events.types(), want)
+	}
+	if data := events.events[1].Data; data["reason"] != "all admins locked out" || data["userId"] != user.ID {
+		t.Errorf("opened event data = %v", data)
+	}
+}
+
+func TestBreakGlassUseCase_UnsealRequiresAudit(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	user, _ := userRepo.Create(entity.NewUser("oncall@example.com", "hash", "On Call", "0812345678", "1990-01-15"))
+	breakGlassRepo := &MockBreakGlassRepository{}
+	uc := NewBreakGlassUseCase(breakGlassRepo, userRepo, &failingEventRepository{}, time.Hour)
+
+	_, shares, err := uc.Seal(3, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := uc.Unseal(shares[:2], user.Email, "locked out"); err == nil {
+		t.Fatal("Unseal() without an event log succeeded")
+	}
+	if allowed, _ := uc.Allowed(user.ID); allowed || len(breakGlassRepo.sessions) != 0 {
+		t.Errorf("a session was opened without its audit record: %+v", breakGlassRepo.sessions)
+	}
+}
+
+func TestBreakGlassUseCase_Close(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	user, _ := userRepo.Create(entity.NewUser("oncall@example.com", "hash", "On Call", "0812345678", "1990-01-15"))
+	events := &MockEventRepository{}
+	uc := NewBreakGlassUseCase(&MockBreakGlassRepository{}, userRepo, events, time.Hour)
+
+	_, shares, _ := uc.Seal(2, 2, 1)
+	session, _, err := uc.Unseal(shares, user.Email, "locked out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed, err := uc.Close(session.ID, 1)
+	if err != nil || closed.ClosedBy != 1 {
+		t.Fatalf("Close() = %+v, %v", closed, err)
+	}
+	if allowed, _ := uc.Allowed(user.ID); allowed {
+		t.Error("Allowed() after closing = true")
+	}
+	if _, err := uc.Close(session.ID, 1); !errors.Is(err, ErrBreakGlassSessionNotFound) {
+		t.Errorf("Close() again error = %v, want ErrBreakGlassSessionNotFound", err)
+	}
+	if last := events.events[len(events.events)-1]; last.Type != entity.EventBreakGlassClosed || last.Data["reason"] != "closed" || last.ActorID != 1 {
+		t.Errorf("last event = %+v", last)
+	}
+}
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// claimsBatchSize is how many events Sync reads at a time
+const claimsBatchSize = 500
+
+// ClaimsStats counts how users' derived claims were looked up
+type ClaimsStats struct {
+	// LocalHits were served from this node's memory
+	LocalHits int64
+	// SharedHits were served from the shared cache, e.g. Redis
+	SharedHits int64
+	// Misses read the user from the database
+	Misses int64
+	// Invalidations are users whose cached claims were dropped
+	Invalidations int64
+	// Errors are failed reads and writes of the shared cache
+	Errors int64
+}
+
+// HitRate returns the share of lookups served from a cache, 0 before the
+// first lookup
+func (s ClaimsStats) HitRate() float64 {
+	lookups := s.LocalHits + s.SharedHits + s.Misses
+	if lookups == 0 {
+		return 0
+	}
+	return float64(s.LocalHits+s.SharedHits) / float64(lookups)
+}
+
+// ClaimsUseCase resolves users' derived claims through a local cache and an
+// optional shared one, so authenticated requests need not read the user.
+// Cached claims are dropped when the event log shows a role or status change.
+type ClaimsUseCase struct {
+	userRepo  repository.UserRepository
+	eventRepo repository.EventRepository
+	local     repository.ClaimsCache
+	shared    repository.ClaimsCache
+	// sharedUp reports whether the shared cache passed its last health
+	// check; nil when it is not checked
+	sharedUp func() bool
+
+	// mu serializes Sync; position is the last event it applied
+	mu       sync.Mutex
+	started  bool
+	position int64
+
+	localHits     atomic.Int64
+	sharedHits    atomic.Int64
+	misses        atomic.Int64
+	invalidations atomic.Int64
+	cacheErrors   atomic.Int64
+}
+
+// NewClaimsUseCase creates a new claims use case. shared may be nil to cache
+// on this node only.
+func NewClaimsUseCase(userRepo repository.UserRepository, eventRepo repository.EventRepository, local, shared repository.ClaimsCache) *ClaimsUseCase {
+	return &ClaimsUseCase{userRepo: userRepo, eventRepo: eventRepo, local: local, shared: shared}
+}
+
+// SetSharedHealth makes Resolve skip the shared cache while up reports it
+// down, so requests do not wait on an unreachable cache. Invalidations are
+// still sent, so Sync retries them until the cache is back.
+func (uc *ClaimsUseCase) SetSharedHealth(up func() bool) {
+	uc.sharedUp = up
+}
+
+// useShared reports whether Resolve reads and writes the shared cache
+func (uc *ClaimsUseCase) useShared() bool {
+	return uc.shared != nil && (uc.sharedUp == nil || uc.sharedUp())
+}
+
+// Resolve returns the derived claims of a user, from the local cache, the
+// shared cache or else the user's record. The shared cache is best effort:
+// when it fails or is down, the user is read from the database.
+func (uc *ClaimsUseCase) Resolve(userID int) (*entity.DerivedClaims, error) {
+	if claims, ok, _ := uc.local.Get(userID); ok {
+		uc.localHits.Add(1)
+		return claims, nil
+	}
+	shared := uc.useShared()
+	if shared {
+		claims, ok, err := uc.shared.Get(userID)
+		if err != nil {
+			uc.sharedError("read", userID, err)
+		} else if ok {
+			uc.sharedHits.Add(1)
+			uc.local.Set(claims)
+			return claims, nil
+		}
+	}
+
+	uc.misses.Add(1)
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	claims := entity.DeriveClaims(user)
+	uc.local.Set(claims)
+	if shared {
+		if err := uc.shared.Set(claims); err != nil {
+			uc.sharedError("write", userID, err)
+		}
+	}
+	return claims, nil
+}
+
+// sharedError counts and logs a failed use of the shared cache
+func (uc *ClaimsUseCase) sharedError(op string, userID int, err error) {
+	uc.cacheErrors.Add(1)
+	log.Printf("Failed to %s cached claims of user %d: %v", op, userID, err)
+}
+
+// Invalidate drops the cached claims of the users from both caches
+func (uc *ClaimsUseCase) Invalidate(userIDs ...int) error {
+	uc.local.Delete(userIDs...)
+	if uc.shared != nil {
+		if err := uc.shared.Delete(userIDs...); err != nil {
+			uc.cacheErrors.Add(1)
+			return fmt.Errorf("failed to invalidate cached claims: %w", err)
+		}
+	}
+	uc.invalidations.Add(int64(len(userIDs)))
+	return nil
+}
+
+// Sync invalidates the claims of the users in the role and status events
+// appended since the last Sync, and returns how many users it invalidated.
+// The first Sync starts from the newest event; claims cached before then
+// expire with the cache's TTL. When invalidating fails, the same events are
+// applied again on the next Sync.
+func (uc *ClaimsUseCase) Sync() (int, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if !uc.started {
+		lastID, err := uc.eventRepo.LastID()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read event log: %w", err)
+		}
+		uc.position, uc.started = lastID, true
+		return 0, nil
+	}
+
+	invalidated := 0
+	for {
+		events, err := uc.eventRepo.List(repository.EventFilter{
+			Types:   entity.ClaimsInvalidatingEvents,
+			AfterID: uc.position,
+			Limit:   claimsBatchSize,
+		})
+		if err != nil {
+			return invalidated, fmt.Errorf("failed to list events: %w", err)
+		}
+		if len(events) == 0 {
+			return invalidated, nil
+		}
+
+		seen := make(map[int]bool)
+		var userIDs []int
+		for _, event := range events {
+			if event.SubjectType == entity.EventSubjectUser && !seen[event.SubjectID] {
+				seen[event.SubjectID] = true
+				userIDs = append(userIDs, event.SubjectID)
+			}
+		}
+		if len(userIDs) > 0 {
+			if err := uc.Invalidate(userIDs...); err != nil {
+				return invalidated, err
+			}
+		}
+		invalidated += len(userIDs)
+		uc.position = events[len(events)-1].ID
+		if len(events) < claimsBatchSize {
+			return invalidated, nil
+		}
+	}
+}
+
+// Stats returns the lookup and invalidation counts since the use case was
+// created
+func (uc *ClaimsUseCase) Stats() ClaimsStats {
+	return ClaimsStats{
+		LocalHits:     uc.localHits.Load(),
+		SharedHits:    uc.sharedHits.Load(),
+		Misses:        uc.misses.Load(),
+		Invalidations: uc.invalidations.Load(),
+		Errors:        uc.cacheErrors.Load(),
+	}
+}
+
+// WarmUp opens a connection to the shared cache, if any, so the first
+// requests do not wait for it. The shared cache is best effort, so failing
+// to reach it is logged rather than returned.
+func (uc *ClaimsUseCase) WarmUp() error {
+	if uc.shared == nil {
+		return nil
+	}
+	if _, _, err := uc.shared.Get(0); err != nil {
+		uc.cacheErrors.Add(1)
+		log.Printf("Failed to reach the shared claims cache: %v", err)
+	}
+	return nil
+}
+
+// SharedEnabled reports whether claims are also cached in a shared cache
+func (uc *ClaimsUseCase) SharedEnabled() bool {
+	return uc.shared != nil
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// MockClaimsCache keeps claims in a map and fails every call while err is set
+type MockClaimsCache struct {
+	claims map[int]entity.DerivedClaims
+	err    error
+}
+
+func NewMockClaimsCache() *MockClaimsCache {
+	return &MockClaimsCache{claims: map[int]entity.DerivedClaims{}}
+}
+
+func (m *MockClaimsCache) Get(userID int) (*entity.DerivedClaims, bool, error) {
+	if m.err != nil {
+		return nil, false, m.err
+	}
+	claims, ok := m.claims[userID]
+	return &claims, ok, nil
+}
+
+func (m *MockClaimsCache) Set(claims *entity.DerivedClaims) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.claims[claims.UserID] = *claims
+	return nil
+}
+
+func (m *MockClaimsCache) Delete(userIDs ...int) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, userID := range userIDs {
+		delete(m.claims, userID)
+	}
+	return nil
+}
+
+func TestClaimsUseCase_Resolve(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	user, _ := userRepo.Create(&entity.User{Email: "a@example.com", Role: entity.RoleAdmin, Status: entity.StatusActive})
+	local, shared := NewMockClaimsCache(), NewMockClaimsCache()
+	uc := NewClaimsUseCase(userRepo, &MockEventRepository{}, local, shared)
+
+	claims, err := uc.Resolve(user.ID)
+	if err != nil || claims.Role != entity.RoleAdmin || claims.Status != entity.StatusActive {
+		t.Fatalf("Resolve() = %+v, %v", claims, err)
+	}
+	if _, ok := shared.claims[user.ID]; !ok {
+		t.Error("Resolve() did not fill the shared cache")
+	}
+	uc.Resolve(user.ID)
+
+	// Another node's cached claims are copied to the local cache
+	delete(local.claims, user.ID)
+	uc.Resolve(user.ID)
+	if _, ok := local.claims[user.ID]; !ok {
+		t.Error("a shared hit did not fill the local cache")
+	}
+
+	// A failing shared cache falls back to the database
+	delete(local.claims, user.ID)
+	shared.err = errors.New("connection refused")
+	if claims, err := uc.Resolve(user.ID); err != nil || claims.UserID != user.ID {
+		t.Errorf("Resolve() with a failing shared cache = %+v, %v", claims, err)
+	}
+
+	if _, err := uc.Resolve(99); err == nil {
+		t.Error("Resolve() of an unknown user succeeded")
+	}
+
+	want := ClaimsStats{LocalHits: 1, SharedHits: 1, Misses: 3, Errors: 3}
+	if got := uc.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	if rate := want.HitRate(); rate != 0.4 {
+		t.Errorf("HitRate() = %v, want 0.4", rate)
+	}
+	if rate := (ClaimsStats{}).HitRate(); rate != 0 {
+		t.Errorf("HitRate() without lookups = %v, want 0", rate)
+	}
+}
+
+func TestClaimsUseCase_SharedHealth(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	user, _ := userRepo.Create(&entity.User{Email: "a@example.com", Role: entity.RoleUser, Status: entity.StatusActive})
+	local, shared := NewMockClaimsCache(), NewMockClaimsCache()
+	uc := NewClaimsUseCase(userRepo, &MockEventRepository{}, local, shared)
+	up := false
+	uc.SetSharedHealth(func() bool { return up })
+
+	// A shared cache reported down is neither read nor written
+	shared.err = errors.New("connection refused")
+	if claims, err := uc.Resolve(user.ID); err != nil || claims.UserID != user.ID {
+		t.Fatalf("Resolve() = %+v, %v", claims, err)
+	}
+	if got := uc.Stats(); got.Errors != 0 || got.Misses != 1 {
+		t.Errorf("Stats() with the shared cache down = %+v, want no errors", got)
+	}
+	// Invalidations are still sent, so they are retried until it is back
+	if err := uc.Invalidate(user.ID); err == nil {
+		t.Error("Invalidate() with the shared cache down succeeded")
+	}
+
+	up, shared.err = true, nil
+	uc.Resolve(user.ID)
+	if _, ok := shared.claims[user.ID]; !ok {
+		t.Error("Resolve() did not fill the shared cache once it was back")
+	}
+}
+
+func TestClaimsUseCase_Sync(t *testing.T) {
+	eventRepo := &MockEventRepository{}
+	eventRepo.Append(entity.NewDomainEvent(entity.EventUserRoleChanged, entity.EventSubjectUser, 1, 0, nil))
+	local, shared := NewMockClaimsCache(), NewMockClaimsCache()
+	for _, cache := range []*MockClaimsCache{local, shared} {
+		for userID := 1; userID <= 4; userID++ {
+			cache.Set(&entity.DerivedClaims{UserID: userID})
+		}
+	}
+	uc := NewClaimsUseCase(NewMockUserRepository(), eventRepo, local, shared)
+
+	// The first Sync starts after the events already in the log
+	if n, err := uc.Sync(); n != 0 || err != nil {
+		t.Fatalf("first Sync() = %d, %v", n, err)
+	}
+	if _, ok := local.claims[1]; !ok {
+		t.Error("the first Sync() applied old events")
+	}
+
+	eventRepo.Append(entity.NewDomainEvent(entity.EventUserSuspended, entity.EventSubjectUser, 2, 0, nil))
+	eventRepo.Append(entity.NewDomainEvent(entity.EventUserUpdated, entity.EventSubjectUser, 3, 0, nil))
+	eventRepo.Append(entity.NewDomainEvent(entity.EventUserRoleChanged, entity.EventSubjectUser, 2, 0, nil))
+	eventRepo.Append(entity.NewDomainEvent(entity.EventUserDeleted, entity.EventSubjectUser, 4, 0, nil))
+	shared.err = errors.New("connection refused")
+	if _, err := uc.Sync(); err == nil {
+		t.Fatal("Sync() with a failing shared cache succeeded")
+	}
+
+	shared.err = nil
+	if n, err := uc.Sync(); n != 2 || err != nil {
+		t.Fatalf("Sync() = %d, %v; want 2", n, err)
+	}
+	for _, cache := range []*MockClaimsCache{local, shared} {
+		if len(cache.claims) != 2 || cache.claims[1].UserID != 1 || cache.claims[3].UserID != 3 {
+			t.Errorf("cached users after Sync() = %v, want 1 and 3", cache.claims)
+		}
+	}
+	if n, err := uc.Sync(); n != 0 || err != nil {
+		t.Errorf("Sync() without new events = %d, %v", n, err)
+	}
+	if got := uc.Stats().Invalidations; got != 2 {
+		t.Errorf("Invalidations = %d, want 2", got)
+	}
+}
+
+func TestClaimsUseCase_WarmUp(t *testing.T) {
+	shared := NewMockClaimsCache()
+	shared.err = errors.New("connection refused")
+	uc := NewClaimsUseCase(NewMockUserRepository(), &MockEventRepository{}, NewMockClaimsCache(), shared)
+
+	// An unreachable shared cache does not hold up the warm-up
+	if err := uc.WarmUp(); err != nil {
+		t.Errorf("WarmUp() error = %v", err)
+	}
+	if got := uc.Stats().Errors; got != 1 {
+		t.Errorf("Errors = %d, want 1", got)
+	}
+	if err := NewClaimsUseCase(NewMockUserRepository(), &MockEventRepository{}, NewMockClaimsCache(), nil).WarmUp(); err != nil {
+		t.Errorf("WarmUp() without a shared cache error = %v", err)
+	}
+}
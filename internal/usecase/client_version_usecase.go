@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/clientversion"
+)
+
+// ErrInvalidClientVersion is returned for an X-Client-Version header, or a
+// minimum version set by an admin, that cannot be parsed
+var ErrInvalidClientVersion = clientversion.ErrInvalid
+
+// ErrClientOutdated is returned for apps older than their platform's minimum
+var ErrClientOutdated = errors.New("client version is no longer supported")
+
+// ErrClientVersionNotFound is returned when resetting a platform whose
+// minimum was not set by an admin
+var ErrClientVersionNotFound = repository.ErrClientVersionNotFound
+
+// maxUpdateURLLength bounds update URLs
+const maxUpdateURLLength = 2048
+
+// OutdatedClientError is the ErrClientOutdated returned by Check, with the
+// app's version and the policy it fails
+type OutdatedClientError struct {
+	Version string
+	Policy  *entity.ClientVersionPolicy
+}
+
+func (e *OutdatedClientError) Error() string {
+	return fmt.Sprintf("%v: %s %s is older than %s", ErrClientOutdated, e.Policy.Platform, e.Version, e.Policy.MinVersion)
+}
+
+// Unwrap makes errors.Is(err, ErrClientOutdated) hold for outdated clients
+func (e *OutdatedClientError) Unwrap() error { return ErrClientOutdated }
+
+// ClientVersionUseCase enforces the minimum app version of each platform.
+// Minimums come from CLIENT_MIN_VERSIONS and can be overridden by admins;
+// the overrides are cached and reloaded with Refresh.
+type ClientVersionUseCase struct {
+	clientVersionRepo repository.ClientVersionRepository
+	defaults          map[string]string
+	auditRepo         repository.AuditRepository
+
+	mu        sync.RWMutex
+	loaded    bool
+	overrides map[string]*entity.ClientVersionPolicy
+}
+
+// NewClientVersionUseCase creates a new client version use case with the
+// configured minimum version per platform. Platforms and versions must
+// already be valid.
+func NewClientVersionUseCase(clientVersionRepo repository.ClientVersionRepository, defaults map[string]string) *ClientVersionUseCase {
+	return &ClientVersionUseCase{
+		clientVersionRepo: clientVersionRepo,
+		defaults:          defaults,
+	}
+}
+
+// SetAuditLog records the minimums admins set and reset in the audit trail
+func (uc *ClientVersionUseCase) SetAuditLog(auditRepo repository.AuditRepository) {
+	uc.auditRepo = auditRepo
+}
+
+// Refresh reloads the minimums set by admins, e.g. on other instances
+func (uc *ClientVersionUseCase) Refresh() error {
+	policies, err := uc.clientVersionRepo.List()
+	if err != nil {
+		return fmt.Errorf("failed to load client versions: %w", err)
+	}
+	overrides := make(map[string]*entity.ClientVersionPolicy, len(policies))
+	for _, policy := range policies {
+		overrides[policy.Platform] = policy
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.overrides = overrides
+	uc.loaded = true
+	return nil
+}
+
+// policy returns the minimum of a platform, nil when it has none. The
+// overrides are loaded on first use.
+func (uc *ClientVersionUseCase) policy(platform string) (*entity.ClientVersionPolicy, error) {
+	uc.mu.RLock()
+	loaded := uc.loaded
+	uc.mu.RUnlock()
+	if !loaded {
+		if err := uc.Refresh(); err != nil {
+			return nil, err
+		}
+	}
+
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	if policy, ok := uc.overrides[platform]; ok {
+		return policy, nil
+	}
+	if minVersion, ok := uc.defaults[platform]; ok {
+		return &entity.ClientVersionPolicy{Platform: platform, MinVersion: minVersion, Source: entity.ClientVersionSourceConfig}, nil
+	}
+	return nil, nil
+}
+
+// Check checks the X-Client-Version header of a request, of the form
+// "<platform>/<version>". Requests without it and platforms without a
+// minimum pass. Apps older than their platform's minimum get an
+// *OutdatedClientError.
+func (uc *ClientVersionUseCase) Check(header string) error {
+	if header == "" {
+		return nil
+	}
+	platform, version, err := clientversion.ParseHeader(header)
+	if err != nil {
+		return err
+	}
+	policy, err := uc.policy(platform)
+	if err != nil || policy == nil {
+		return err
+	}
+	minVersion, err := clientversion.Parse(policy.MinVersion)
+	if err != nil {
+		return fmt.Errorf("minimum version of %s: %w", platform, err)
+	}
+	if version.Compare(minVersion) < 0 {
+		return &OutdatedClientError{Version: version.String(), Policy: policy}
+	}
+	return nil
+}
+
+// Policies returns the minimum of every platform that has one, by platform
+func (uc *ClientVersionUseCase) Policies() ([]*entity.ClientVersionPolicy, error) {
+	if err := uc.Refresh(); err != nil {
+		return nil, err
+	}
+
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	policies := make([]*entity.ClientVersionPolicy, 0, len(uc.defaults)+len(uc.overrides))
+	for _, policy := range uc.overrides {
+		policies = append(policies, policy)
+	}
+	for platform, minVersion := range uc.defaults {
+		if _, ok := uc.overrides[platform]; !ok {
+			policies = append(policies, &entity.ClientVersionPolicy{Platform: platform, MinVersion: minVersion, Source: entity.ClientVersionSourceConfig})
+		}
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Platform < policies[j].Platform })
+	return policies, nil
+}
+
+// SetMinimum sets the oldest app version served on a platform, overriding
+// CLIENT_MIN_VERSIONS, and where users get the current app. It applies to
+// this instance at once and to others on their next Refresh.
+func (uc *ClientVersionUseCase) SetMinimum(actorID int, platform, minVersion, updateURL string) (*entity.ClientVersionPolicy, error) {
+	platform, err := clientversion.ParsePlatform(platform)
+	if err != nil {
+		return nil, err
+	}
+	version, err := clientversion.Parse(minVersion)
+	if err != nil {
+		return nil, err
+	}
+	if updateURL != "" {
+		u, err := url.Parse(updateURL)
+		if err != nil || !u.IsAbs() || len(updateURL) > maxUpdateURLLength {
+			return nil, fmt.Errorf("%w: update URL must be an absolute URL", ErrInvalidClientVersion)
+		}
+	}
+
+	before, err := uc.policy(platform)
+	if err != nil {
+		return nil, err
+	}
+	policy := &entity.ClientVersionPolicy{
+		Platform:   platform,
+		MinVersion: version.String(),
+		UpdateURL:  updateURL,
+		Source:     entity.ClientVersionSourceAdmin,
+		UpdatedBy:  actorID,
+	}
+	if err := uc.clientVersionRepo.Save(policy); err != nil {
+		return nil, fmt.Errorf("failed to save client version: %w", err)
+	}
+	uc.recordAudit(actorID, entity.AuditClientVersionSet, platform, before, policy)
+	return policy, uc.Refresh()
+}
+
+// Reset removes the minimum an admin set for a platform on behalf of
+// actorID, so the one from CLIENT_MIN_VERSIONS applies again, if any
+func (uc *ClientVersionUseCase) Reset(actorID int, platform string) error {
+	platform, err := clientversion.ParsePlatform(platform)
+	if err != nil {
+		return err
+	}
+	before, err := uc.policy(platform)
+	if err != nil {
+		return err
+	}
+	if err := uc.clientVersionRepo.Delete(platform); err != nil {
+		return err
+	}
+	if err := uc.Refresh(); err != nil {
+		return err
+	}
+	after, err := uc.policy(platform)
+	if err != nil {
+		return err
+	}
+	uc.recordAudit(actorID, entity.AuditClientVersionReset, platform, before, after)
+	return nil
+}
+
+// recordAudit records the change of a platform's minimum from before to
+// after, either nil when the platform had none
+func (uc *ClientVersionUseCase) recordAudit(actorID int, action entity.AuditAction, platform string, before, after *entity.ClientVersionPolicy) {
+	fields := func(policy *entity.ClientVersionPolicy) map[string]string {
+		if policy == nil {
+			return nil
+		}
+		return map[string]string{"minVersion": policy.MinVersion, "updateURL": policy.UpdateURL, "source": policy.Source}
+	}
+	recordAudit(uc.auditRepo, entity.NewAuditEntry(actorID, action, entity.AuditSubjectClientVersion, platform, entity.DiffFields(fields(before), fields(after))))
+}
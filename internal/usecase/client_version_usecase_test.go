@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock client version repository for testing
+type MockClientVersionRepository struct {
+	policies map[string]*entity.ClientVersionPolicy
+	lists    int
+}
+
+func (m *MockClientVersionRepository) List() ([]*entity.ClientVersionPolicy, error) {
+	m.lists++
+	policies := make([]*entity.ClientVersionPolicy, 0, len(m.policies))
+	for _, policy := range m.policies {
+		found := *policy
+		policies = append(policies, &found)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Platform < policies[j].Platform })
+	return policies, nil
+}
+
+func (m *MockClientVersionRepository) Save(policy *entity.ClientVersionPolicy) error {
+	if m.policies == nil {
+		m.policies = make(map[string]*entity.ClientVersionPolicy)
+	}
+	policy.UpdatedAt = time.Now()
+	stored := *policy
+	m.policies[policy.Platform] = &stored
+	return nil
+}
+
+func (m *MockClientVersionRepository) Delete(platform string) error {
+	if _, ok := m.policies[platform]; !ok {
+		return repository.ErrClientVersionNotFound
+	}
+	delete(m.policies, platform)
+	return nil
+}
+
+func TestClientVersionUseCase_Check(t *testing.T) {
+	repo := &MockClientVersionRepository{}
+	uc := NewClientVersionUseCase(repo, map[string]string{"ios": "2.3.0", "android": "2.1.4"})
+
+	tests := []struct {
+		header string
+		want   error
+	}{
+		{"", nil},
+		{"ios/2.3.0", nil},
+		{"iOS/2.10", nil},
+		{"web/0.1", nil},
+		{"ios/2.2.9", ErrClientOutdated},
+		{"ios/2.3.0-beta.1", ErrClientOutdated},
+		{"android/2.1.3", ErrClientOutdated},
+		{"ios", ErrInvalidClientVersion},
+		{"ios/latest", ErrInvalidClientVersion},
+	}
+	for _, tt := range tests {
+		if err := uc.Check(tt.header); !errors.Is(err, tt.want) {
+			t.Errorf("Check(%q) error = %v, want %v", tt.header, err, tt.want)
+		}
+	}
+	if repo.lists != 1 {
+		t.Errorf("overrides loaded %d times, want once", repo.lists)
+	}
+
+	var outdated *OutdatedClientError
+	if err := uc.Check("android/2.0"); !errors.As(err, &outdated) {
+		t.Fatalf("Check() error = %v, want *OutdatedClientError", err)
+	}
+	if outdated.Version != "2.0" || outdated.Policy.MinVersion != "2.1.4" || outdated.Policy.Source != entity.ClientVersionSourceConfig {
+		t.Errorf("outdated = %+v, policy %+v", outdated, outdated.Policy)
+	}
+}
+
+func TestClientVersionUseCase_SetMinimum(t *testing.T) {
+	repo := &MockClientVersionRepository{}
+	uc := NewClientVersionUseCase(repo, map[string]string{"ios": "2.3.0"})
+
+	policy, err := uc.SetMinimum(1, "iOS", "v2.5", "https://apps.apple.com/app/id1")
+	if err != nil {
+		t.Fatalf("SetMinimum() error = %v", err)
+	}
+	if policy.Platform != "ios" || policy.MinVersion != "2.5" || policy.UpdatedBy != 1 || policy.Source != entity.ClientVersionSourceAdmin {
+		t.Errorf("policy = %+v", policy)
+	}
+	// The override applies at once, over the configured minimum
+	if err := uc.Check("ios/2.4.0"); !errors.Is(err, ErrClientOutdated) {
+		t.Errorf("Check() below the override error = %v, want ErrClientOutdated", err)
+	}
+	if _, err := uc.SetMinimum(1, "android", "2.0", ""); err != nil {
+		t.Fatalf("SetMinimum() error = %v", err)
+	}
+
+	policies, err := uc.Policies()
+	if err != nil {
+		t.Fatalf("Policies() error = %v", err)
+	}
+	if len(policies) != 2 || policies[0].Platform != "android" || policies[1].MinVersion != "2.5" {
+		t.Errorf("Policies() = %+v, %+v", policies[0], policies[1])
+	}
+
+	for _, tt := range []struct{ platform, version, url string }{
+		{"i os", "2.0", ""},
+		{"ios", "two", ""},
+		{"ios", "2.0", "/download"},
+		{"ios", "2.0", "not a url"},
+	} {
+		if _, err := uc.SetMinimum(1, tt.platform, tt.version, tt.url); !errors.Is(err, ErrInvalidClientVersion) {
+			t.Errorf("SetMinimum(%q, %q, %q) error = %v, want ErrInvalidClientVersion", tt.platform, tt.version, tt.url, err)
+		}
+	}
+}
+
+func TestClientVersionUseCase_Reset(t *testing.T) {
+	repo := &MockClientVersionRepository{}
+	uc := NewClientVersionUseCase(repo, map[string]string{"ios": "2.3.0"})
+
+	if _, err := uc.SetMinimum(1, "ios", "3.0", ""); err != nil {
+		t.Fatalf("SetMinimum() error = %v", err)
+	}
+	if err := uc.Reset(1, "ios"); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	// The configured minimum applies again
+	if err := uc.Check("ios/2.5"); err != nil {
+		t.Errorf("Check() after Reset error = %v", err)
+	}
+	if err := uc.Reset(1, "ios"); !errors.Is(err, ErrClientVersionNotFound) {
+		t.Errorf("Reset() without override error = %v, want ErrClientVersionNotFound", err)
+	}
+}
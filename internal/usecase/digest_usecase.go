@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ErrInvalidDigestPeriod is returned for periods other than daily and weekly
+var ErrInvalidDigestPeriod = errors.New("digest period must be daily or weekly")
+
+// maxDigestItems bounds each list in a digest
+const maxDigestItems = 20
+
+// DigestFailureThreshold is how many failed logins in a digest period put
+// an account on the digest's list
+const DigestFailureThreshold = 5
+
+// DefaultDigestTemplate renders digests as plain text. A digest template
+// defines "subject" and "body", which are executed with an *entity.Digest;
+// link makes an API path such as /admin/funnel a deep link.
+const DefaultDigestTemplate = `{{define "subject"}}{{if eq .Period "weekly"}}Weekly{{else}}Daily{{end}} admin digest: {{.Signups}} signups, {{.FailedLogins}} failed logins{{end}}
+{{define "body"}}Admin digest for {{.Since.Format "2006-01-02 15:04"}} to {{.Until.Format "2006-01-02 15:04"}} UTC
+
+New signups: {{.Signups}}
+{{range .NewUsers}}  - {{.Email}} ({{.FullName}}) {{link (printf "/admin/users/%d/history" .ID)}}
+{{end}}{{if gt .Signups (len .NewUsers)}}  (first {{len .NewUsers}} shown)
+{{end}}Signup funnel: {{link "/admin/funnel"}}
+
+Failed logins: {{.FailedLogins}}
+{{range .RepeatedFailures}}  - {{.Email}}: {{.Attempts}} failed attempts {{link (printf "/admin/users/%d/history" .UserID)}}
+{{end}}
+Failed hook deliveries: {{.FailedHooks}}
+{{range .HookFailures}}  - {{.CreatedAt.Format "2006-01-02 15:04"}} {{.Point}} for user {{.UserID}}: {{.Error}}
+{{end}}
+Pending deletions: {{len .PendingDeletions}}
+{{range .PendingDeletions}}  - {{len .UserIDs}} users at {{.ExecuteAt.Format "2006-01-02 15:04"}} UTC {{link (printf "/admin/actions/%s" .Token)}}
+{{end}}{{end}}`
+
+// DigestTemplate renders digests as emails
+type DigestTemplate struct {
+	tmpl *template.Template
+}
+
+// NewDigestTemplate parses text, which must define "subject" and "body".
+// Deep links are made by prefixing paths with linkBase, e.g. the admin UI URL.
+func NewDigestTemplate(text, linkBase string) (*DigestTemplate, error) {
+	linkBase = strings.TrimSuffix(linkBase, "/")
+	tmpl, err := template.New("digest").Funcs(template.FuncMap{
+		"link": func(path string) string { return linkBase + path },
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest template: %w", err)
+	}
+
+	t := &DigestTemplate{tmpl: tmpl}
+	if _, _, err := t.Render(&entity.Digest{Period: entity.DigestDaily}); err != nil {
+		return nil, fmt.Errorf("invalid digest template: %w", err)
+	}
+	return t, nil
+}
+
+// Render returns the subject and body of the digest email
+func (t *DigestTemplate) Render(digest *entity.Digest) (string, string, error) {
+	var subject, body strings.Builder
+	if err := t.tmpl.ExecuteTemplate(&subject, "subject", digest); err != nil {
+		return "", "", err
+	}
+	if err := t.tmpl.ExecuteTemplate(&body, "body", digest); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(subject.String()), body.String(), nil
+}
+
+// DigestUseCase emails admins periodic summaries of signups, failed logins,
+// failed hook deliveries and pending deletions
+type DigestUseCase struct {
+	userRepo    repository.UserRepository
+	loginRepo   repository.LoginEventRepository
+	actionRepo  repository.AdminActionRepository
+	failureRepo repository.HookFailureRepository
+	digestRepo  repository.DigestRepository
+	mailer      repository.Mailer
+	template    *DigestTemplate
+	recipients  []string
+	now         func() time.Time
+}
+
+// NewDigestUseCase creates a new digest use case emailing recipients
+func NewDigestUseCase(userRepo repository.UserRepository, loginRepo repository.LoginEventRepository, actionRepo repository.AdminActionRepository,
+	failureRepo repository.HookFailureRepository, digestRepo repository.DigestRepository, mailer repository.Mailer,
+	template *DigestTemplate, recipients []string) *DigestUseCase {
+	return &DigestUseCase{
+		userRepo:    userRepo,
+		loginRepo:   loginRepo,
+		actionRepo:  actionRepo,
+		failureRepo: failureRepo,
+		digestRepo:  digestRepo,
+		mailer:      mailer,
+		template:    template,
+		recipients:  recipients,
+		now:         time.Now,
+	}
+}
+
+// Build summarizes the period ending at until
+func (uc *DigestUseCase) Build(period entity.DigestPeriod, until time.Time) (*entity.Digest, error) {
+	until = until.UTC()
+	digest := &entity.Digest{Period: period, Since: until.Add(-period.Duration()), Until: until}
+
+	filter := repository.UserFilter{CreatedFrom: digest.Since, CreatedTo: until}
+	signups, err := uc.userRepo.Count(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count signups: %w", err)
+	}
+	users, err := uc.userRepo.List(filter, repository.Page{Limit: maxDigestItems})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signups: %w", err)
+	}
+	digest.Signups = signups
+	for _, user := range users {
+		digest.NewUsers = append(digest.NewUsers, user.WithoutPassword())
+	}
+
+	failed, err := uc.loginRepo.ListFailed(digest.Since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed logins: %w", err)
+	}
+	digest.FailedLogins = len(failed)
+	digest.RepeatedFailures = uc.repeatedFailures(failed)
+
+	failures, err := uc.failureRepo.List(digest.Since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hook failures: %w", err)
+	}
+	digest.FailedHooks = len(failures)
+	// The latest failures are the most useful when the list is cut
+	digest.HookFailures = failures[max(0, len(failures)-maxDigestItems):]
+
+	if digest.PendingDeletions, err = uc.actionRepo.ListPending(entity.AdminActionDeleteUsers); err != nil {
+		return nil, fmt.Errorf("failed to list pending deletions: %w", err)
+	}
+	return digest, nil
+}
+
+// repeatedFailures returns the accounts with at least DigestFailureThreshold
+// failed logins, most attempts first
+func (uc *DigestUseCase) repeatedFailures(failed []*entity.LoginEvent) []entity.FailedLoginAccount {
+	attempts := make(map[int]int)
+	for _, event := range failed {
+		attempts[event.UserID]++
+	}
+
+	var accounts []entity.FailedLoginAccount
+	for userID, count := range attempts {
+		if count >= DigestFailureThreshold {
+			accounts = append(accounts, entity.FailedLoginAccount{UserID: userID, Attempts: count})
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Attempts != accounts[j].Attempts {
+			return accounts[i].Attempts > accounts[j].Attempts
+		}
+		return accounts[i].UserID < accounts[j].UserID
+	})
+	if len(accounts) > maxDigestItems {
+		accounts = accounts[:maxDigestItems]
+	}
+
+	for i := range accounts {
+		user, err := uc.userRepo.GetByID(accounts[i].UserID)
+		if err != nil {
+			// Deleted since; the ID still links to the history
+			continue
+		}
+		accounts[i].Email = user.Email
+	}
+	return accounts
+}
+
+// Preview renders the digest of the period ending now without sending it
+func (uc *DigestUseCase) Preview(period entity.DigestPeriod) (string, string, error) {
+	if !period.IsValid() {
+		return "", "", ErrInvalidDigestPeriod
+	}
+	digest, err := uc.Build(period, uc.now())
+	if err != nil {
+		return "", "", err
+	}
+	subject, body, err := uc.template.Render(digest)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to render digest: %w", err)
+	}
+	return subject, body, nil
+}
+
+// SendIfDue sends the digest of the latest period ending at the time of day
+// at (UTC), on Mondays for weekly digests, unless it was already sent.
+// Returns nil when no digest was due. A digest that fails to send is
+// retried on the next call.
+func (uc *DigestUseCase) SendIfDue(period entity.DigestPeriod, at time.Duration) (*entity.Digest, error) {
+	until := digestDue(period, at, uc.now())
+	claimed, err := uc.digestRepo.Claim(period, until)
+	if err != nil || !claimed {
+		return nil, err
+	}
+
+	digest, err := uc.send(period, until)
+	if err != nil {
+		if releaseErr := uc.digestRepo.Release(period, until); releaseErr != nil {
+			return nil, fmt.Errorf("%w (and failed to release the digest: %v)", err, releaseErr)
+		}
+		return nil, err
+	}
+	return digest, nil
+}
+
+// send builds, renders and emails the digest of the period ending at until
+func (uc *DigestUseCase) send(period entity.DigestPeriod, until time.Time) (*entity.Digest, error) {
+	digest, err := uc.Build(period, until)
+	if err != nil {
+		return nil, err
+	}
+	subject, body, err := uc.template.Render(digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render digest: %w", err)
+	}
+	if err := uc.mailer.Send(uc.recipients, subject, body); err != nil {
+		return nil, fmt.Errorf("failed to send digest: %w", err)
+	}
+	return digest, nil
+}
+
+// digestDue returns the end of the latest period due at or before now
+func digestDue(period entity.DigestPeriod, at time.Duration, now time.Time) time.Time {
+	now = now.UTC()
+	due := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(at)
+	if period == entity.DigestWeekly {
+		// Back to Monday
+		due = due.AddDate(0, 0, -((int(due.Weekday()) + 6) % 7))
+	}
+	if due.After(now) {
+		due = due.Add(-period.Duration())
+	}
+	return due
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/memory"
+)
+
+// Mock hook failure repository for testing
+type MockHookFailureRepository struct {
+	failures []*entity.HookFailure
+}
+
+func (m *MockHookFailureRepository) Record(failure *entity.HookFailure) error {
+	failure.ID = len(m.failures) + 1
+	m.failures = append(m.failures, failure)
+	return nil
+}
+
+func (m *MockHookFailureRepository) List(since, until time.Time) ([]*entity.HookFailure, error) {
+	var failures []*entity.HookFailure
+	for _, failure := range m.failures {
+		if !failure.CreatedAt.Before(since) && failure.CreatedAt.Before(until) {
+			failures = append(failures, failure)
+		}
+	}
+	return failures, nil
+}
+
+// Mock digest repository for testing
+type MockDigestRepository struct {
+	claimed map[string]bool
+}
+
+func (m *MockDigestRepository) Claim(period entity.DigestPeriod, until time.Time) (bool, error) {
+	key := string(period) + until.UTC().String()
+	if m.claimed[key] {
+		return false, nil
+	}
+	m.claimed[key] = true
+	return true, nil
+}
+
+func (m *MockDigestRepository) Release(period entity.DigestPeriod, until time.Time) error {
+	delete(m.claimed, string(period)+until.UTC().String())
+	return nil
+}
+
+// Mock mailer for testing
+type MockMailer struct {
+	sent []string
+	err  error
+}
+
+func (m *MockMailer) Send(to []string, subject, body string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, strings.Join(to, ",")+"\n"+subject+"\n"+body)
+	return nil
+}
+
+var (
+	_ repository.HookFailureRepository = (*MockHookFailureRepository)(nil)
+	_ repository.DigestRepository      = (*MockDigestRepository)(nil)
+	_ repository.Mailer                = (*MockMailer)(nil)
+)
+
+func TestDigestUseCase_Build(t *testing.T) {
+	until := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	clock := until.Add(-48 * time.Hour)
+	userRepo := memory.NewUserRepositoryWithClock(func() time.Time { return clock })
+
+	// One signup before the period, three in it
+	if _, err := userRepo.Create(entity.NewUser("old@example.com", "hash", "Old User", "0812345678", "1990-01-15")); err != nil {
+		t.Fatal(err)
+	}
+	clock = until.Add(-time.Hour)
+	var users []*entity.User
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		user, err := userRepo.Create(entity.NewUser(email, "hash", "New User", "0812345678", "1990-01-15"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, user)
+	}
+
+	loginRepo := &MockLoginEventRepository{now: until.Add(-time.Hour)}
+	for i := 0; i < DigestFailureThreshold; i++ {
+		loginRepo.Record(&entity.LoginEvent{UserID: users[0].ID})
+	}
+	loginRepo.Record(&entity.LoginEvent{UserID: users[1].ID})
+	loginRepo.Record(&entity.LoginEvent{UserID: users[1].ID, Success: true})
+
+	failureRepo := &MockHookFailureRepository{}
+	failureRepo.Record(&entity.HookFailure{Point: "post-register", UserID: users[2].ID, Error: "timeout", CreatedAt: until.Add(-time.Minute)})
+	failureRepo.Record(&entity.HookFailure{Point: "post-register", UserID: users[2].ID, Error: "old", CreatedAt: until.Add(-25 * time.Hour)})
+
+	actionRepo := &MockAdminActionRepository{}
+	actionRepo.Create(&entity.AdminAction{Token: "undo-token", Kind: entity.AdminActionDeleteUsers, UserIDs: []int{users[2].ID}, Status: entity.AdminActionPending, ExecuteAt: until.Add(time.Minute)})
+	actionRepo.Create(&entity.AdminAction{Token: "suspend", Kind: entity.AdminActionSuspendUsers, Status: entity.AdminActionPending})
+
+	template, err := NewDigestTemplate(DefaultDigestTemplate, "https://admin.example.com/")
+	if err != nil {
+		t.Fatalf("NewDigestTemplate() error = %v", err)
+	}
+	useCase := NewDigestUseCase(userRepo, loginRepo, actionRepo, failureRepo, &MockDigestRepository{}, &MockMailer{}, template, nil)
+
+	digest, err := useCase.Build(entity.DigestDaily, until)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if digest.Signups != 3 || len(digest.NewUsers) != 3 || digest.NewUsers[0].Password != "" {
+		t.Errorf("signups = %d %+v, want three without passwords", digest.Signups, digest.NewUsers)
+	}
+	if digest.FailedLogins != DigestFailureThreshold+1 || len(digest.RepeatedFailures) != 1 ||
+		digest.RepeatedFailures[0] != (entity.FailedLoginAccount{UserID: users[0].ID, Email: "a@example.com", Attempts: DigestFailureThreshold}) {
+		t.Errorf("failed logins = %d %+v", digest.FailedLogins, digest.RepeatedFailures)
+	}
+	if digest.FailedHooks != 1 || digest.HookFailures[0].Error != "timeout" {
+		t.Errorf("hook failures = %d %+v", digest.FailedHooks, digest.HookFailures)
+	}
+	if len(digest.PendingDeletions) != 1 || digest.PendingDeletions[0].Token != "undo-token" {
+		t.Errorf("pending deletions = %+v", digest.PendingDeletions)
+	}
+
+	subject, body, err := template.Render(digest)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if subject != "Daily admin digest: 3 signups, 6 failed logins" {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{
+		"Admin digest for 2024-06-02 08:00 to 2024-06-03 08:00 UTC",
+		"  - a@example.com (New User) https://admin.example.com/admin/users/2/history\n",
+		"  - a@example.com: 5 failed attempts https://admin.example.com/admin/users/2/history\n",
+		"post-register for user 4: timeout",
+		"  - 1 users at 2024-06-03 08:01 UTC https://admin.example.com/admin/actions/undo-token\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestDigestUseCase_SendIfDue(t *testing.T) {
+	mailer := &MockMailer{}
+	digestRepo := &MockDigestRepository{claimed: make(map[string]bool)}
+	template, _ := NewDigestTemplate(DefaultDigestTemplate, "")
+	useCase := NewDigestUseCase(memory.NewUserRepository(), &MockLoginEventRepository{}, &MockAdminActionRepository{},
+		&MockHookFailureRepository{}, digestRepo, mailer, template, []string{"admin@example.com"})
+
+	// Wednesday 2024-06-05 09:00 UTC; digests go out at 08:00
+	now := time.Date(2024, 6, 5, 9, 0, 0, 0, time.UTC)
+	useCase.now = func() time.Time { return now }
+	at := 8 * time.Hour
+
+	digest, err := useCase.SendIfDue(entity.DigestDaily, at)
+	if err != nil || digest == nil || !digest.Until.Equal(time.Date(2024, 6, 5, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("SendIfDue() = %+v, %v; want today's digest", digest, err)
+	}
+	if len(mailer.sent) != 1 || !strings.HasPrefix(mailer.sent[0], "admin@example.com\nDaily admin digest") {
+		t.Errorf("sent = %q", mailer.sent)
+	}
+	if digest, err := useCase.SendIfDue(entity.DigestDaily, at); digest != nil || err != nil {
+		t.Errorf("SendIfDue() again = %+v, %v; want nothing due", digest, err)
+	}
+
+	// Weekly digests cover the week up to Monday
+	digest, err = useCase.SendIfDue(entity.DigestWeekly, at)
+	if err != nil || digest == nil || !digest.Until.Equal(time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)) || !digest.Since.Equal(time.Date(2024, 5, 27, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("SendIfDue(weekly) = %+v, %v; want the week to Monday", digest, err)
+	}
+
+	// A failed send is retried
+	now = now.Add(24 * time.Hour)
+	mailer.err = errors.New("connection refused")
+	if _, err := useCase.SendIfDue(entity.DigestDaily, at); err == nil {
+		t.Fatal("SendIfDue() should fail when the mailer does")
+	}
+	mailer.err = nil
+	if digest, err := useCase.SendIfDue(entity.DigestDaily, at); err != nil || digest == nil {
+		t.Errorf("SendIfDue() retry = %+v, %v; want the digest sent", digest, err)
+	}
+}
+
+func TestDigestUseCase_Preview(t *testing.T) {
+	mailer := &MockMailer{}
+	template, _ := NewDigestTemplate(DefaultDigestTemplate, "")
+	useCase := NewDigestUseCase(memory.NewUserRepository(), &MockLoginEventRepository{}, &MockAdminActionRepository{},
+		&MockHookFailureRepository{}, &MockDigestRepository{}, mailer, template, []string{"admin@example.com"})
+
+	subject, body, err := useCase.Preview(entity.DigestWeekly)
+	if err != nil || subject != "Weekly admin digest: 0 signups, 0 failed logins" || !strings.Contains(body, "Pending deletions: 0") {
+		t.Errorf("Preview() = %q, %q, %v", subject, body, err)
+	}
+	if len(mailer.sent) != 0 {
+		t.Errorf("Preview() sent %q", mailer.sent)
+	}
+	if _, _, err := useCase.Preview("monthly"); !errors.Is(err, ErrInvalidDigestPeriod) {
+		t.Errorf("Preview(monthly) error = %v, want ErrInvalidDigestPeriod", err)
+	}
+}
+
+func TestDigestDue(t *testing.T) {
+	at := 8 * time.Hour
+	tests := []struct {
+		name   string
+		period entity.DigestPeriod
+		now    time.Time
+		want   time.Time
+	}{
+		{"daily after time", entity.DigestDaily, time.Date(2024, 6, 5, 8, 0, 0, 0, time.UTC), time.Date(2024, 6, 5, 8, 0, 0, 0, time.UTC)},
+		{"daily before time", entity.DigestDaily, time.Date(2024, 6, 5, 7, 59, 0, 0, time.UTC), time.Date(2024, 6, 4, 8, 0, 0, 0, time.UTC)},
+		{"weekly on Monday", entity.DigestWeekly, time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC), time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)},
+		{"weekly Monday morning", entity.DigestWeekly, time.Date(2024, 6, 3, 7, 0, 0, 0, time.UTC), time.Date(2024, 5, 27, 8, 0, 0, 0, time.UTC)},
+		{"weekly on Sunday", entity.DigestWeekly, time.Date(2024, 6, 9, 23, 0, 0, 0, time.UTC), time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := digestDue(tt.period, at, tt.now); !got.Equal(tt.want) {
+				t.Errorf("digestDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDigestTemplate_Invalid(t *testing.T) {
+	for _, text := range []string{
+		`{{define "subject"}}{{.Nope}}{{end}}{{define "body"}}{{end}}`,
+		`{{define "body"}}{{end}}`,
+		`{{define "subject"}}{{end}}{{define "body"}}{{link}}{{end}}`,
+		`{{define "subject"}}`,
+	} {
+		if _, err := NewDigestTemplate(text, ""); err == nil {
+			t.Errorf("NewDigestTemplate(%q) should fail", text)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/dedupe"
+)
+
+// ErrDuplicateNotFound is returned when no duplicate candidate matches
+var ErrDuplicateNotFound = repository.ErrDuplicateNotFound
+
+// duplicateMaxGroup is how many accounts may share a phone number, mailbox
+// or device before it says nothing about any two of them, e.g. a shared
+// office computer
+const duplicateMaxGroup = 5
+
+// duplicateDeviceWindow is how far back logins are compared for shared devices
+const duplicateDeviceWindow = 90 * 24 * time.Hour
+
+// DuplicateUseCase finds accounts that probably belong to the same person,
+// by phone number, mailbox and login device, for admins to review
+type DuplicateUseCase struct {
+	duplicateRepo  repository.DuplicateRepository
+	userRepo       repository.UserRepository
+	loginEventRepo repository.LoginEventRepository
+	auditRepo      repository.AuditRepository
+	now            func() time.Time
+}
+
+// NewDuplicateUseCase creates a new duplicate use case
+func NewDuplicateUseCase(duplicateRepo repository.DuplicateRepository, userRepo repository.UserRepository, loginEventRepo repository.LoginEventRepository) *DuplicateUseCase {
+	return &DuplicateUseCase{
+		duplicateRepo:  duplicateRepo,
+		userRepo:       userRepo,
+		loginEventRepo: loginEventRepo,
+		now:            time.Now,
+	}
+}
+
+// SetAuditLog records the pairs admins dismiss in the audit trail
+func (uc *DuplicateUseCase) SetAuditLog(auditRepo repository.AuditRepository) {
+	uc.auditRepo = auditRepo
+}
+
+// Scan compares every account and saves the pairs sharing a phone number,
+// a mailbox, or a device and IP they logged in from in the last 90 days.
+// It returns how many pairs were found, including dismissed ones.
+func (uc *DuplicateUseCase) Scan() (int, error) {
+	index := dedupe.NewIndex()
+	users := make(map[int]bool)
+	page := repository.Page{Limit: repository.MaxPageLimit}
+	for {
+		batch, err := uc.userRepo.List(repository.UserFilter{}, page)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, user := range batch {
+			users[user.ID] = true
+			index.Add(entity.DuplicateSignalPhone, dedupe.PhoneKey(user.PhoneNumber), user.ID)
+			index.Add(entity.DuplicateSignalEmail, dedupe.EmailKey(user.Email), user.ID)
+		}
+		if len(batch) < page.Limit {
+			break
+		}
+		page.Offset += page.Limit
+	}
+
+	now := uc.now()
+	logins, err := uc.loginEventRepo.ListSuccessful(now.Add(-duplicateDeviceWindow), now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list logins: %w", err)
+	}
+	for _, login := range logins {
+		// Logins of deleted accounts stay in the history
+		if users[login.UserID] && login.Device != "" {
+			index.Add(entity.DuplicateSignalDevice, login.IP+"|"+login.Device, login.UserID)
+		}
+	}
+
+	pairs := index.Pairs(duplicateMaxGroup)
+	candidates := make([]*entity.DuplicateCandidate, 0, len(pairs))
+	for _, pair := range pairs {
+		candidates = append(candidates, &entity.DuplicateCandidate{UserID: pair.A, OtherUserID: pair.B, Signals: pair.Signals})
+	}
+	if err := uc.duplicateRepo.Replace(candidates); err != nil {
+		return 0, fmt.Errorf("failed to save duplicates: %w", err)
+	}
+	return len(candidates), nil
+}
+
+// List returns up to limit pairs with status, most recently detected first,
+// with both accounts
+func (uc *DuplicateUseCase) List(status string, limit int) ([]*entity.DuplicateCandidate, error) {
+	candidates, err := uc.duplicateRepo.List(status, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		uc.loadUsers(candidate)
+	}
+	return candidates, nil
+}
+
+// Dismiss records that an admin reviewed a pair and found two people, so it
+// is not surfaced again
+func (uc *DuplicateUseCase) Dismiss(id, reviewerID int) (*entity.DuplicateCandidate, error) {
+	candidate, err := uc.duplicateRepo.Dismiss(id, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(uc.auditRepo, entity.NewAuditEntry(reviewerID, entity.AuditDuplicateDismissed, entity.AuditSubjectDuplicate, strconv.Itoa(id),
+		[]entity.FieldChange{{Field: "status", Old: entity.DuplicatePending, New: candidate.Status}}))
+	uc.loadUsers(candidate)
+	return candidate, nil
+}
+
+// loadUsers loads both accounts of a pair, leaving nil those deleted since
+// the scan
+func (uc *DuplicateUseCase) loadUsers(candidate *entity.DuplicateCandidate) {
+	candidate.User, _ = uc.userRepo.GetByID(candidate.UserID)
+	candidate.OtherUser, _ = uc.userRepo.GetByID(candidate.OtherUserID)
+}
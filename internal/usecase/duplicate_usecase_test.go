@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock duplicate repository for testing
+type MockDuplicateRepository struct {
+	candidates []*entity.DuplicateCandidate
+}
+
+func (m *MockDuplicateRepository) Replace(candidates []*entity.DuplicateCandidate) error {
+	m.candidates = candidates
+	for i, candidate := range candidates {
+		candidate.ID = i + 1
+		candidate.Status = entity.DuplicatePending
+	}
+	return nil
+}
+
+func (m *MockDuplicateRepository) List(status string, limit int) ([]*entity.DuplicateCandidate, error) {
+	var candidates []*entity.DuplicateCandidate
+	for _, candidate := range m.candidates {
+		if candidate.Status == status && len(candidates) < limit {
+			found := *candidate
+			candidates = append(candidates, &found)
+		}
+	}
+	return candidates, nil
+}
+
+func (m *MockDuplicateRepository) Dismiss(id, reviewerID int) (*entity.DuplicateCandidate, error) {
+	for _, candidate := range m.candidates {
+		if candidate.ID == id {
+			candidate.Status = entity.DuplicateDismissed
+			candidate.ReviewedBy = reviewerID
+			return candidate, nil
+		}
+	}
+	return nil, repository.ErrDuplicateNotFound
+}
+
+func TestDuplicateUseCase_Scan(t *testing.T) {
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	userRepo := NewMockUserRepository()
+	for _, user := range []struct{ email, phone string }{
+		{"jane.doe@gmail.com", "0812345678"},
+		{"janedoe+shop@gmail.com", "+66 81 234 5678"},
+		{"bob@example.com", "0899999999"},
+		{"bobby@example.com", "0877777777"},
+		{"carol@example.com", "0866666666"},
+	} {
+		if _, err := userRepo.Create(entity.NewUser(user.email, "hash", "User", user.phone, "1990-01-15")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logins := &MockLoginEventRepository{}
+	for _, login := range []struct {
+		userID int
+		at     time.Time
+	}{
+		{3, now.Add(-time.Hour)},
+		{4, now.Add(-24 * time.Hour)},
+		// Outside the window
+		{5, now.Add(-100 * 24 * time.Hour)},
+		// A deleted account
+		{9, now.Add(-time.Hour)},
+	} {
+		logins.now = login.at
+		logins.Record(&entity.LoginEvent{UserID: login.userID, Success: true, IP: "198.51.100.2", Device: "Firefox"})
+	}
+
+	duplicates := &MockDuplicateRepository{}
+	uc := NewDuplicateUseCase(duplicates, userRepo, logins)
+	uc.now = func() time.Time { return now }
+
+	found, err := uc.Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	var pairs []string
+	for _, candidate := range duplicates.candidates {
+		pairs = append(pairs, fmt.Sprintf("%d-%d %v", candidate.UserID, candidate.OtherUserID, candidate.Signals))
+	}
+	if got := fmt.Sprint(pairs); found != 2 || got != "[1-2 [email phone] 3-4 [device]]" {
+		t.Errorf("Scan() = %d with %s, want 1-2 by email and phone and 3-4 by device", found, got)
+	}
+
+	candidates, err := uc.List(entity.DuplicatePending, 10)
+	if err != nil || len(candidates) != 2 {
+		t.Fatalf("List() = %+v, %v", candidates, err)
+	}
+	if candidates[0].User == nil || candidates[0].User.Email != "jane.doe@gmail.com" || candidates[0].OtherUser == nil {
+		t.Errorf("List() should load both accounts, got %+v", candidates[0])
+	}
+
+	if _, err := uc.Dismiss(candidates[0].ID, 1); err != nil {
+		t.Fatalf("Dismiss() error = %v", err)
+	}
+	if pending, _ := uc.List(entity.DuplicatePending, 10); len(pending) != 1 {
+		t.Errorf("pending after Dismiss() = %d, want 1", len(pending))
+	}
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/entitlements"
+)
+
+var (
+	// ErrUnknownFeature is returned for an override of a feature that no
+	// plan or flag names
+	ErrUnknownFeature = errors.New("unknown feature")
+	// ErrEntitlementOverrideNotFound is returned when removing an override
+	// the user does not have
+	ErrEntitlementOverrideNotFound = errors.New("entitlement override not found")
+)
+
+// entitlementsEntry is a user's cached feature set
+type entitlementsEntry struct {
+	set       *entitlements.Set
+	expiresAt time.Time
+}
+
+// EntitlementsUseCase computes users' effective features from their plan,
+// the feature flags and their overrides, caching each user's set
+type EntitlementsUseCase struct {
+	policy       *entitlements.Policy
+	overrideRepo repository.EntitlementOverrideRepository
+	userUseCase  *UserUseCase
+	auditRepo    repository.AuditRepository
+	ttl          time.Duration
+
+	mu    sync.Mutex
+	cache map[int]entitlementsEntry
+	// swept is when expired sets were last dropped
+	swept time.Time
+	now   func() time.Time
+}
+
+// NewEntitlementsUseCase creates a new entitlements use case whose feature
+// sets are cached for ttl
+func NewEntitlementsUseCase(policy *entitlements.Policy, overrideRepo repository.EntitlementOverrideRepository, userUseCase *UserUseCase, ttl time.Duration) *EntitlementsUseCase {
+	return &EntitlementsUseCase{
+		policy:       policy,
+		overrideRepo: overrideRepo,
+		userUseCase:  userUseCase,
+		ttl:          ttl,
+		cache:        make(map[int]entitlementsEntry),
+		now:          time.Now,
+	}
+}
+
+// SetAuditLog records the overrides admins set and remove in the audit trail
+func (uc *EntitlementsUseCase) SetAuditLog(auditRepo repository.AuditRepository) {
+	uc.auditRepo = auditRepo
+}
+
+// Resolve returns the effective features of a user on plan, or on the plan
+// in their record when plan is empty. Sets are cached per user for the TTL
+// unless the plan changed, so overrides set on other replicas apply within
+// the TTL and plan changes at once.
+func (uc *EntitlementsUseCase) Resolve(userID int, plan string) (*entitlements.Set, error) {
+	now := uc.now()
+	uc.mu.Lock()
+	entry, ok := uc.cache[userID]
+	uc.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) && (plan == "" || plan == entry.set.Plan) {
+		return entry.set, nil
+	}
+
+	if plan == "" {
+		user, err := uc.userUseCase.GetUserByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		plan = user.Plan
+	}
+	stored, err := uc.overrideRepo.ListByUser(userID)
+	if err != nil {
+		return nil, errors.New("failed to get entitlement overrides")
+	}
+	overrides := make(map[string]bool, len(stored))
+	for _, override := range stored {
+		overrides[override.Feature] = override.Granted
+	}
+
+	set := uc.policy.Evaluate(userID, plan, overrides)
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	// Drop expired sets once per TTL, so the cache holds about the users
+	// seen within two TTLs
+	if now.Sub(uc.swept) >= uc.ttl {
+		for id, cached := range uc.cache {
+			if !now.Before(cached.expiresAt) {
+				delete(uc.cache, id)
+			}
+		}
+		uc.swept = now
+	}
+	uc.cache[userID] = entitlementsEntry{set: set, expiresAt: now.Add(uc.ttl)}
+	return set, nil
+}
+
+// Overrides returns the overrides of a user, sorted by feature
+func (uc *EntitlementsUseCase) Overrides(userID int) ([]*entity.EntitlementOverride, error) {
+	overrides, err := uc.overrideRepo.ListByUser(userID)
+	if err != nil {
+		return nil, errors.New("failed to get entitlement overrides")
+	}
+	return overrides, nil
+}
+
+// SetOverride grants feature to a user, or takes it away, on behalf of
+// actorID. Returns ErrUnknownFeature for a feature no plan or flag names.
+func (uc *EntitlementsUseCase) SetOverride(actorID, userID int, feature string, granted bool) error {
+	if !slices.Contains(uc.policy.Features(), feature) {
+		return ErrUnknownFeature
+	}
+	if _, err := uc.userUseCase.GetUserByID(userID); err != nil {
+		return err
+	}
+
+	before := uc.overrideValue(userID, feature)
+	override := &entity.EntitlementOverride{UserID: userID, Feature: feature, Granted: granted, ActorID: actorID, CreatedAt: uc.now().UTC()}
+	if err := uc.overrideRepo.Set(override); err != nil {
+		return errors.New("failed to save entitlement override")
+	}
+	uc.invalidate(userID)
+	recordAudit(uc.auditRepo, entity.NewAuditEntry(actorID, entity.AuditEntitlementSet, entity.EventSubjectUser, strconv.Itoa(userID),
+		entity.DiffFields(map[string]string{feature: before}, map[string]string{feature: strconv.FormatBool(granted)})))
+	return nil
+}
+
+// DeleteOverride removes a user's override of feature on behalf of
+// actorID, so their plan and the flags decide it again. Returns
+// ErrEntitlementOverrideNotFound.
+func (uc *EntitlementsUseCase) DeleteOverride(actorID, userID int, feature string) error {
+	before := uc.overrideValue(userID, feature)
+	deleted, err := uc.overrideRepo.Delete(userID, feature)
+	if err != nil {
+		return errors.New("failed to delete entitlement override")
+	}
+	if !deleted {
+		return ErrEntitlementOverrideNotFound
+	}
+	uc.invalidate(userID)
+	recordAudit(uc.auditRepo, entity.NewAuditEntry(actorID, entity.AuditEntitlementDeleted, entity.EventSubjectUser, strconv.Itoa(userID),
+		entity.DiffFields(map[string]string{feature: before}, nil)))
+	return nil
+}
+
+// overrideValue returns "true" or "false" for a user's override of
+// feature, empty when there is none or it cannot be read
+func (uc *EntitlementsUseCase) overrideValue(userID int, feature string) string {
+	overrides, err := uc.overrideRepo.ListByUser(userID)
+	if err != nil {
+		return ""
+	}
+	for _, override := range overrides {
+		if override.Feature == feature {
+			return strconv.FormatBool(override.Granted)
+		}
+	}
+	return ""
+}
+
+// Features returns every feature a plan or flag names
+func (uc *EntitlementsUseCase) Features() []string {
+	return uc.policy.Features()
+}
+
+// CacheTTL returns how long feature sets are cached
+func (uc *EntitlementsUseCase) CacheTTL() time.Duration {
+	return uc.ttl
+}
+
+// invalidate drops the cached set of a user
+func (uc *EntitlementsUseCase) invalidate(userID int) {
+	uc.mu.Lock()
+	delete(uc.cache, userID)
+	uc.mu.Unlock()
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/pkg/entitlements"
+)
+
+// Mock entitlement override repository for testing
+type MockEntitlementOverrideRepository struct {
+	overrides []*entity.EntitlementOverride
+	lists     int
+}
+
+func (m *MockEntitlementOverrideRepository) ListByUser(userID int) ([]*entity.EntitlementOverride, error) {
+	m.lists++
+	var found []*entity.EntitlementOverride
+	for _, override := range m.overrides {
+		if override.UserID == userID {
+			found = append(found, override)
+		}
+	}
+	return found, nil
+}
+
+func (m *MockEntitlementOverrideRepository) Set(override *entity.EntitlementOverride) error {
+	m.Delete(override.UserID, override.Feature)
+	m.overrides = append(m.overrides, override)
+	return nil
+}
+
+func (m *MockEntitlementOverrideRepository) Delete(userID int, feature string) (bool, error) {
+	for i, override := range m.overrides {
+		if override.UserID == userID && override.Feature == feature {
+			m.overrides = append(m.overrides[:i], m.overrides[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newTestEntitlements(t *testing.T) (*EntitlementsUseCase, *MockEntitlementOverrideRepository, *entity.User) {
+	t.Helper()
+	policy, err := entitlements.NewPolicy(
+		map[string][]string{"free": {"exports"}, "pro": {"exports", "api_access"}},
+		map[string]entitlements.Flag{"new_dashboard": {Percent: 100}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userUseCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	user, err := userUseCase.RegisterUser("lee@example.com", "password123", "Lee Park", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &MockEntitlementOverrideRepository{}
+	return NewEntitlementsUseCase(policy, repo, userUseCase, time.Minute), repo, user
+}
+
+func TestEntitlementsUseCase_Resolve(t *testing.T) {
+	useCase, repo, user := newTestEntitlements(t)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	useCase.now = func() time.Time { return now }
+
+	set, err := useCase.Resolve(user.ID, "")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if got, want := set.Names(), []string{"exports", "new_dashboard"}; set.Plan != entity.PlanFree || !slices.Equal(got, want) {
+		t.Errorf("Resolve() = %s %v, want free %v", set.Plan, got, want)
+	}
+
+	// Cached for the TTL, but a plan change is evaluated at once
+	useCase.Resolve(user.ID, entity.PlanFree)
+	if repo.lists != 1 {
+		t.Errorf("overrides read %d times, want the set cached", repo.lists)
+	}
+	if set, _ := useCase.Resolve(user.ID, "pro"); !set.Has("api_access") || repo.lists != 2 {
+		t.Errorf("Resolve(pro) = %v after %d reads, want api_access", set.Names(), repo.lists)
+	}
+	now = now.Add(time.Minute)
+	useCase.Resolve(user.ID, "pro")
+	if repo.lists != 3 {
+		t.Errorf("overrides read %d times, want the set expired", repo.lists)
+	}
+
+	if _, err := useCase.Resolve(999, ""); err == nil {
+		t.Error("Resolve() of an unknown user should fail")
+	}
+}
+
+func TestEntitlementsUseCase_Overrides(t *testing.T) {
+	useCase, _, user := newTestEntitlements(t)
+	useCase.Resolve(user.ID, "")
+
+	if err := useCase.SetOverride(1, user.ID, "teleport", true); !errors.Is(err, ErrUnknownFeature) {
+		t.Errorf("SetOverride(teleport) error = %v, want ErrUnknownFeature", err)
+	}
+	if err := useCase.SetOverride(1, 999, "api_access", true); err == nil {
+		t.Error("SetOverride() for an unknown user should fail")
+	}
+	if err := useCase.SetOverride(1, user.ID, "api_access", true); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	if err := useCase.SetOverride(1, user.ID, "exports", false); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+
+	// Overrides drop the cached set
+	set, err := useCase.Resolve(user.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := set.Names(), []string{"api_access", "new_dashboard"}; !slices.Equal(got, want) || set.Features["api_access"] != entitlements.SourceOverride {
+		t.Errorf("Resolve() = %v, want %v with api_access from the override", set.Features, want)
+	}
+	if overrides, _ := useCase.Overrides(user.ID); len(overrides) != 2 || overrides[0].ActorID != 1 {
+		t.Errorf("Overrides() = %+v, want 2 set by user 1", overrides)
+	}
+
+	if err := useCase.DeleteOverride(1, user.ID, "exports"); err != nil {
+		t.Fatalf("DeleteOverride() error = %v", err)
+	}
+	if err := useCase.DeleteOverride(1, user.ID, "exports"); !errors.Is(err, ErrEntitlementOverrideNotFound) {
+		t.Errorf("DeleteOverride() again error = %v, want ErrEntitlementOverrideNotFound", err)
+	}
+	if set, _ := useCase.Resolve(user.ID, ""); !set.Has("exports") {
+		t.Errorf("Resolve() = %v, want exports back from the plan", set.Names())
+	}
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ErrInvalidEventFilter is returned for an unknown event type or a bad
+// time range or limit
+var ErrInvalidEventFilter = errors.New("invalid event filter")
+
+// Limits on listing events
+const (
+	defaultEventLimit = 100
+	maxEventLimit     = 1000
+	// exportBatchSize is how many events Export reads at a time
+	exportBatchSize = 500
+)
+
+// EventUseCase reads the domain event log
+type EventUseCase struct {
+	eventRepo repository.EventRepository
+}
+
+// NewEventUseCase creates a new event use case
+func NewEventUseCase(eventRepo repository.EventRepository) *EventUseCase {
+	return &EventUseCase{eventRepo: eventRepo}
+}
+
+// validateEventFilter checks the types and time range of a filter
+func validateEventFilter(filter repository.EventFilter) error {
+	for _, eventType := range filter.Types {
+		if _, ok := entity.EventSchemaVersions[eventType]; !ok {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidEventFilter, eventType)
+		}
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Until.After(filter.Since) {
+		return fmt.Errorf("%w: until must be after since", ErrInvalidEventFilter)
+	}
+	return nil
+}
+
+// List returns a page of the events matching the filter, oldest first,
+// and the AfterID of the next page, 0 on the last one. The limit defaults
+// to 100 and is at most 1000.
+func (uc *EventUseCase) List(filter repository.EventFilter) ([]*entity.DomainEvent, int64, error) {
+	if err := validateEventFilter(filter); err != nil {
+		return nil, 0, err
+	}
+	switch {
+	case filter.Limit == 0:
+		filter.Limit = defaultEventLimit
+	case filter.Limit < 0 || filter.Limit > maxEventLimit:
+		return nil, 0, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidEventFilter, maxEventLimit)
+	}
+
+	events, err := uc.eventRepo.List(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list events: %w", err)
+	}
+	var next int64
+	if len(events) == filter.Limit {
+		next = events[len(events)-1].ID
+	}
+	return events, next, nil
+}
+
+// Export passes every event matching the filter to write, oldest first,
+// reading them in batches. The filter's limit is ignored.
+func (uc *EventUseCase) Export(filter repository.EventFilter, write func(event *entity.DomainEvent) error) error {
+	if err := validateEventFilter(filter); err != nil {
+		return err
+	}
+	filter.Limit = exportBatchSize
+	for {
+		events, err := uc.eventRepo.List(filter)
+		if err != nil {
+			return fmt.Errorf("failed to list events: %w", err)
+		}
+		for _, event := range events {
+			if err := write(event); err != nil {
+				return err
+			}
+		}
+		if len(events) < exportBatchSize {
+			return nil
+		}
+		filter.AfterID = events[len(events)-1].ID
+	}
+}
+
+// recordEvent appends an event to the log, if the use case has one. The
+// change is already persisted, so a failure is logged rather than returned.
+func recordEvent(eventRepo repository.EventRepository, event *entity.DomainEvent) {
+	if eventRepo == nil {
+		return
+	}
+	if err := eventRepo.Append(event); err != nil {
+		log.Printf("Failed to record %s event for %s %d: %v", event.Type, event.SubjectType, event.SubjectID, err)
+	}
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock event repository for testing
+type MockEventRepository struct {
+	events []*entity.DomainEvent
+}
+
+func (m *MockEventRepository) Append(event *entity.DomainEvent) error {
+	event.ID = int64(len(m.events) + 1)
+	event.OccurredAt = time.Now()
+	stored := *event
+	m.events = append(m.events, &stored)
+	return nil
+}
+
+func (m *MockEventRepository) List(filter repository.EventFilter) ([]*entity.DomainEvent, error) {
+	var events []*entity.DomainEvent
+	for _, event := range m.events {
+		if event.ID <= filter.AfterID || (len(filter.Types) > 0 && !slices.Contains(filter.Types, event.Type)) {
+			continue
+		}
+		found := *event
+		events = append(events, &found)
+		if len(events) == filter.Limit {
+			break
+		}
+	}
+	return events, nil
+}
+
+// types returns the types of the recorded events in order
+func (m *MockEventRepository) LastID() (int64, error) {
+	return int64(len(m.events)), nil
+}
+
+func (m *MockEventRepository) types() []entity.EventType {
+	types := make([]entity.EventType, len(m.events))
+	for i, event := range m.events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+func TestUserUseCase_RecordsEvents(t *testing.T) {
+	events := &MockEventRepository{}
+	uc := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	uc.SetEventLog(events)
+
+	user, err := uc.RegisterUser("test@example.com", "password123", "John Doe", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	if _, err := uc.AuthenticateUser("test@example.com", "wrong-password"); err == nil {
+		t.Fatal("AuthenticateUser() with a wrong password should fail")
+	}
+	if _, err := uc.AuthenticateUser("test@example.com", "password123"); err != nil {
+		t.Fatalf("AuthenticateUser() error = %v", err)
+	}
+	fullName := "Johnny Doe"
+	if _, err := uc.PatchUser(user.ID, map[string]*string{"fullName": &fullName}); err != nil {
+		t.Fatalf("PatchUser() error = %v", err)
+	}
+	if err := uc.ChangePassword(user.ID, "password123", "password456"); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if err := uc.SetUserRole(99, user.ID, entity.RoleAdmin); err != nil {
+		t.Fatalf("SetUserRole() error = %v", err)
+	}
+	if err := uc.SuspendUser(99, user.ID); err != nil {
+		t.Fatalf("SuspendUser() error = %v", err)
+	}
+	if err := uc.DeleteUser(99, user.ID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	want := []entity.EventType{
+		entity.EventUserRegistered, entity.EventUserLoggedIn, entity.EventUserUpdated, entity.EventUserPasswordChanged,
+		entity.EventUserRoleChanged, entity.EventUserSuspended, entity.EventUserDeleted,
+	}
+	if got := events.types(); !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for _, event := range events.events {
+		if event.SubjectType != entity.EventSubjectUser || event.SubjectID != user.ID || event.SchemaVersion != 1 {
+			t.Errorf("event = %+v", event)
+		}
+	}
+	if fields := events.events[2].Data["fields"]; !slices.Equal(fields.([]string), []string{"fullName"}) {
+		t.Errorf("user.updated fields = %v", fields)
+	}
+	if event := events.events[4]; event.ActorID != 99 || event.Data["role"] != entity.RoleAdmin {
+		t.Errorf("user.role_changed = %+v", event)
+	}
+}
+
+func TestEventUseCase_List(t *testing.T) {
+	events := &MockEventRepository{}
+	for _, eventType := range []entity.EventType{entity.EventUserRegistered, entity.EventUserLoggedIn, entity.EventUserLoggedIn} {
+		events.Append(entity.NewDomainEvent(eventType, entity.EventSubjectUser, 1, 1, nil))
+	}
+	uc := NewEventUseCase(events)
+
+	got, next, err := uc.List(repository.EventFilter{Types: []entity.EventType{entity.EventUserLoggedIn}, Limit: 1})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 2 || next != 2 {
+		t.Errorf("List() = %+v, next %d", got, next)
+	}
+	got, next, _ = uc.List(repository.EventFilter{Types: []entity.EventType{entity.EventUserLoggedIn}, AfterID: next})
+	if len(got) != 1 || got[0].ID != 3 || next != 0 {
+		t.Errorf("List() second page = %+v, next %d", got, next)
+	}
+
+	now := time.Now()
+	for _, filter := range []repository.EventFilter{
+		{Types: []entity.EventType{"user.exploded"}},
+		{Since: now, Until: now.Add(-time.Hour)},
+		{Limit: maxEventLimit + 1},
+	} {
+		if _, _, err := uc.List(filter); !errors.Is(err, ErrInvalidEventFilter) {
+			t.Errorf("List(%+v) error = %v, want ErrInvalidEventFilter", filter, err)
+		}
+	}
+}
+
+func TestEventUseCase_Export(t *testing.T) {
+	events := &MockEventRepository{}
+	for i := 0; i < exportBatchSize+3; i++ {
+		events.Append(entity.NewDomainEvent(entity.EventUserLoggedIn, entity.EventSubjectUser, i, i, nil))
+	}
+	uc := NewEventUseCase(events)
+
+	var ids []int64
+	err := uc.Export(repository.EventFilter{}, func(event *entity.DomainEvent) error {
+		ids = append(ids, event.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(ids) != exportBatchSize+3 || ids[0] != 1 || ids[len(ids)-1] != exportBatchSize+3 {
+		t.Errorf("exported %d events, %v..%v", len(ids), ids[0], ids[len(ids)-1])
+	}
+}
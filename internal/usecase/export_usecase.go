@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// exportDateLayout names the per-day export directories
+const exportDateLayout = "2006-01-02"
+
+// ExportUseCase writes point-in-time snapshots of users and their revision
+// history (the audit trail) to object storage as gzipped NDJSON
+type ExportUseCase struct {
+	snapshots repository.SnapshotSource
+	store     repository.BlobStore
+	prefix    string
+	lastDate  string
+	now       func() time.Time
+}
+
+// NewExportUseCase creates a new export use case writing under prefix in store
+func NewExportUseCase(snapshots repository.SnapshotSource, store repository.BlobStore, prefix string) *ExportUseCase {
+	return &ExportUseCase{
+		snapshots: snapshots,
+		store:     store,
+		prefix:    prefix,
+		now:       time.Now,
+	}
+}
+
+// Export writes <prefix><date>/users.ndjson.gz and user_revisions.ndjson.gz
+// from one snapshot, then manifest.json listing them. Exporting again on the
+// same day replaces that day's export. Users are exported without their
+// password hashes.
+func (uc *ExportUseCase) Export() (*entity.ExportManifest, error) {
+	snapshot, err := uc.snapshots.Snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot data: %w", err)
+	}
+	defer snapshot.Close()
+
+	createdAt := uc.now().UTC()
+	manifest := &entity.ExportManifest{Date: createdAt.Format(exportDateLayout), CreatedAt: createdAt}
+	dir := uc.prefix + manifest.Date + "/"
+
+	users, err := uc.writeFile(dir, "users.ndjson.gz", func(page repository.Page) ([]interface{}, error) {
+		users, err := snapshot.Users().List(repository.UserFilter{}, page)
+		records := make([]interface{}, len(users))
+		for i, user := range users {
+			records[i] = user.WithoutPassword()
+		}
+		return records, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := uc.writeFile(dir, "user_revisions.ndjson.gz", func(page repository.Page) ([]interface{}, error) {
+		revisions, err := snapshot.Revisions().List(page)
+		records := make([]interface{}, len(revisions))
+		for i, revision := range revisions {
+			records[i] = revision
+		}
+		return records, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	manifest.Files = []entity.ExportFile{*users, *revisions}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.store.Put(dir+"manifest.json", data, "application/json"); err != nil {
+		return nil, fmt.Errorf("failed to write export manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// ExportIfDue exports once per UTC day, on the first call at or after the
+// time of day at. Returns nil when no export was due.
+func (uc *ExportUseCase) ExportIfDue(at time.Duration) (*entity.ExportManifest, error) {
+	now := uc.now().UTC()
+	today := now.Format(exportDateLayout)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if uc.lastDate == today || now.Before(midnight.Add(at)) {
+		return nil, nil
+	}
+
+	manifest, err := uc.Export()
+	if err != nil {
+		return nil, err
+	}
+	uc.lastDate = today
+	return manifest, nil
+}
+
+// writeFile pages through records with list, writes them as gzipped NDJSON
+// under dir and describes the result for the manifest
+func (uc *ExportUseCase) writeFile(dir, name string, list func(page repository.Page) ([]interface{}, error)) (*entity.ExportFile, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+
+	count := 0
+	for page := (repository.Page{Limit: repository.MaxPageLimit}); ; page.Offset += page.Limit {
+		records, err := list(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return nil, err
+			}
+		}
+		count += len(records)
+		if len(records) < page.Limit {
+			break
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	file := &entity.ExportFile{Name: name, Key: dir + name, Records: count, Bytes: buf.Len()}
+	sum := sha256.Sum256(buf.Bytes())
+	file.SHA256 = hex.EncodeToString(sum[:])
+	if err := uc.store.Put(file.Key, buf.Bytes(), "application/gzip"); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return file, nil
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock snapshot source for testing; snapshots read the mocks directly
+type MockSnapshotSource struct {
+	users     *MockUserRepository
+	revisions *MockUserRevisionRepository
+	closed    int
+}
+
+func (m *MockSnapshotSource) Snapshot() (repository.Snapshot, error) { return m, nil }
+
+func (m *MockSnapshotSource) Users() repository.UserRepository { return m.users }
+
+func (m *MockSnapshotSource) Revisions() repository.UserRevisionRepository { return m.revisions }
+
+func (m *MockSnapshotSource) Close() error {
+	m.closed++
+	return nil
+}
+
+// Mock blob store for testing
+type MockBlobStore struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (m *MockBlobStore) Put(key string, data []byte, contentType string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.objects[key] = data
+	return nil
+}
+
+func (m *MockBlobStore) Get(key string) ([]byte, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, repository.ErrBlobNotFound
+	}
+	return data, nil
+}
+
+func (m *MockBlobStore) Delete(key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func newTestExport(t *testing.T) (*ExportUseCase, *MockSnapshotSource, *MockBlobStore) {
+	t.Helper()
+	source := &MockSnapshotSource{users: NewMockUserRepository(), revisions: NewMockUserRevisionRepository()}
+	user, err := source.users.Create(entity.NewUser("export@example.com", "secrethash", "Export User", "0812345678", "1990-01-15"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := source.revisions.Record(entity.NewUserRevision(user.ID, user, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store := &MockBlobStore{objects: make(map[string][]byte)}
+	uc := NewExportUseCase(source, store, "exports/")
+	uc.now = func() time.Time { return time.Date(2024, 6, 1, 2, 30, 0, 0, time.UTC) }
+	return uc, source, store
+}
+
+func readNDJSON(t *testing.T, data []byte) []string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(plain)), "\n")
+}
+
+func TestExportUseCase_Export(t *testing.T) {
+	uc, source, store := newTestExport(t)
+
+	manifest, err := uc.Export()
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if source.closed != 1 {
+		t.Errorf("snapshot closed %d times, want 1", source.closed)
+	}
+	if manifest.Date != "2024-06-01" || len(manifest.Files) != 2 {
+		t.Fatalf("Export() = %+v", manifest)
+	}
+
+	users := readNDJSON(t, store.objects["exports/2024-06-01/users.ndjson.gz"])
+	if len(users) != 1 || !strings.Contains(users[0], "export@example.com") || strings.Contains(users[0], "secrethash") {
+		t.Errorf("users.ndjson = %v, want one user without the password hash", users)
+	}
+	revisions := readNDJSON(t, store.objects["exports/2024-06-01/user_revisions.ndjson.gz"])
+	if len(revisions) != 2 || manifest.Files[1].Records != 2 {
+		t.Errorf("user_revisions.ndjson = %d lines, manifest %d records; want 2", len(revisions), manifest.Files[1].Records)
+	}
+
+	var stored entity.ExportManifest
+	if err := json.Unmarshal(store.objects["exports/2024-06-01/manifest.json"], &stored); err != nil {
+		t.Fatalf("manifest.json: %v", err)
+	}
+	if len(stored.Files) != 2 || stored.Files[0].Key != "exports/2024-06-01/users.ndjson.gz" || len(stored.Files[0].SHA256) != 64 {
+		t.Errorf("manifest.json = %+v", stored)
+	}
+}
+
+func TestExportUseCase_ExportIfDue(t *testing.T) {
+	uc, _, store := newTestExport(t)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantNew bool
+	}{
+		{"before the export time", time.Date(2024, 6, 1, 1, 59, 0, 0, time.UTC), false},
+		{"at the export time", time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC), true},
+		{"later the same day", time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC), false},
+		{"next day", time.Date(2024, 6, 2, 2, 5, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc.now = func() time.Time { return tt.now }
+			manifest, err := uc.ExportIfDue(2 * time.Hour)
+			if err != nil {
+				t.Fatalf("ExportIfDue() error = %v", err)
+			}
+			if (manifest != nil) != tt.wantNew {
+				t.Errorf("ExportIfDue() = %+v, want new export %v", manifest, tt.wantNew)
+			}
+		})
+	}
+
+	// A failed export is retried on the next call
+	store.err = errors.New("bucket unavailable")
+	uc.now = func() time.Time { return time.Date(2024, 6, 3, 3, 0, 0, 0, time.UTC) }
+	if _, err := uc.ExportIfDue(2 * time.Hour); err == nil {
+		t.Fatal("ExportIfDue() should fail while the store fails")
+	}
+	store.err = nil
+	if manifest, err := uc.ExportIfDue(2 * time.Hour); err != nil || manifest == nil {
+		t.Errorf("ExportIfDue() = %v, %v; want the export retried", manifest, err)
+	}
+}
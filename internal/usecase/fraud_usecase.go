@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/fraud"
+)
+
+// ErrFraudScoreNotFound is returned for a user who has not been scored
+var ErrFraudScoreNotFound = repository.ErrFraudScoreNotFound
+
+// ErrFraudNotFlagged is returned when clearing an account that is not flagged
+var ErrFraudNotFlagged = errors.New("account is not flagged")
+
+// fraudWindow is how far back logins are scored for geo and device signals
+const fraudWindow = 24 * time.Hour
+
+// fraudVelocityWindow is how far back login attempts are counted for the
+// velocity signal
+const fraudVelocityWindow = time.Hour
+
+// FraudUseCase scores users from their email address and recent logins,
+// flags those reaching the threshold for review, and tells which accounts
+// may not take sensitive actions
+type FraudUseCase struct {
+	fraudRepo repository.FraudRepository
+	userRepo  repository.UserRepository
+	loginRepo repository.LoginEventRepository
+	scorer    *fraud.Scorer
+	threshold int
+	auditRepo repository.AuditRepository
+	now       func() time.Time
+
+	// since is when the last ScoreRecent run ended
+	since time.Time
+}
+
+// NewFraudUseCase creates a new fraud use case flagging accounts whose
+// score reaches threshold
+func NewFraudUseCase(fraudRepo repository.FraudRepository, userRepo repository.UserRepository, loginRepo repository.LoginEventRepository, scorer *fraud.Scorer, threshold int) *FraudUseCase {
+	return &FraudUseCase{
+		fraudRepo: fraudRepo,
+		userRepo:  userRepo,
+		loginRepo: loginRepo,
+		scorer:    scorer,
+		threshold: threshold,
+		now:       time.Now,
+	}
+}
+
+// SetAuditLog records the flags admins clear in the audit trail
+func (uc *FraudUseCase) SetAuditLog(auditRepo repository.AuditRepository) {
+	uc.auditRepo = auditRepo
+}
+
+// ScoreUser scores a user now and saves the score
+func (uc *FraudUseCase) ScoreUser(userID int) (*entity.FraudScore, error) {
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := uc.now().UTC()
+	events, err := uc.loginRepo.ListByUser(userID, now.Add(-fraudWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read login history: %w", err)
+	}
+	activity := fraud.Activity{Email: user.Email}
+	countries := make(map[string]bool)
+	for _, event := range events {
+		if event.CreatedAt.After(now.Add(-fraudVelocityWindow)) {
+			activity.Attempts++
+		}
+		if !event.Success {
+			continue
+		}
+		if event.Country != "" {
+			countries[event.Country] = true
+		}
+		if event.NewDevice {
+			activity.NewDevices++
+		}
+	}
+	activity.Countries = len(countries)
+
+	score, err := uc.fraudRepo.Get(userID)
+	if errors.Is(err, repository.ErrFraudScoreNotFound) {
+		score, err = &entity.FraudScore{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	signals := uc.scorer.Signals(activity)
+	if score.Apply(uc.scorer.Score(signals), signals, uc.threshold, now) {
+		log.Printf("User %d flagged for fraud review with score %d: %v", userID, score.Score, signals)
+	}
+	if err := uc.fraudRepo.Save(score); err != nil {
+		return nil, fmt.Errorf("failed to save fraud score: %w", err)
+	}
+	score.User = user
+	return score, nil
+}
+
+// ScoreRecent scores the users who registered or tried to log in since the
+// last run, or within the last day on the first one. It returns how many
+// users were scored.
+func (uc *FraudUseCase) ScoreRecent() (int, error) {
+	now := uc.now().UTC()
+	since := uc.since
+	if since.IsZero() {
+		since = now.Add(-fraudWindow)
+	}
+
+	var userIDs []int
+	seen := make(map[int]bool)
+	add := func(userID int) {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+	page := repository.Page{Limit: repository.MaxPageLimit}
+	for {
+		batch, err := uc.userRepo.List(repository.UserFilter{CreatedFrom: since, CreatedTo: now}, page)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list new users: %w", err)
+		}
+		for _, user := range batch {
+			add(user.ID)
+		}
+		if len(batch) < page.Limit {
+			break
+		}
+		page.Offset += page.Limit
+	}
+	for _, list := range []func(since, until time.Time) ([]*entity.LoginEvent, error){uc.loginRepo.ListSuccessful, uc.loginRepo.ListFailed} {
+		events, err := list(since, now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list logins: %w", err)
+		}
+		for _, event := range events {
+			add(event.UserID)
+		}
+	}
+
+	var scored int
+	for _, userID := range userIDs {
+		// Logins of deleted accounts stay in the history, so a user that
+		// fails does not hold up the others
+		if _, err := uc.ScoreUser(userID); err != nil {
+			log.Printf("Failed to score user %d for fraud: %v", userID, err)
+			continue
+		}
+		scored++
+	}
+	uc.since = now
+	return scored, nil
+}
+
+// Restricted reports whether a user is flagged and may not take sensitive
+// actions until cleared
+func (uc *FraudUseCase) Restricted(userID int) (bool, error) {
+	score, err := uc.fraudRepo.Get(userID)
+	if errors.Is(err, repository.ErrFraudScoreNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return score.Restricted(), nil
+}
+
+// Get returns the latest score of a user
+func (uc *FraudUseCase) Get(userID int) (*entity.FraudScore, error) {
+	score, err := uc.fraudRepo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	score.User, _ = uc.userRepo.GetByID(userID)
+	return score, nil
+}
+
+// List returns up to limit scores with status, highest first
+func (uc *FraudUseCase) List(status string, limit int) ([]*entity.FraudScore, error) {
+	scores, err := uc.fraudRepo.List(status, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, score := range scores {
+		// Accounts deleted since they were scored are left nil
+		score.User, _ = uc.userRepo.GetByID(score.UserID)
+	}
+	return scores, nil
+}
+
+// Clear lifts the flag of a user after an admin reviewed the account. It is
+// flagged again only by a higher score.
+func (uc *FraudUseCase) Clear(userID, reviewerID int) (*entity.FraudScore, error) {
+	score, err := uc.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !score.Restricted() {
+		return nil, fmt.Errorf("%w: user %d is %s", ErrFraudNotFlagged, userID, score.Status)
+	}
+	before := score.Status
+	score.Clear(reviewerID, uc.now().UTC())
+	if err := uc.fraudRepo.Save(score); err != nil {
+		return nil, err
+	}
+	recordAudit(uc.auditRepo, entity.NewAuditEntry(reviewerID, entity.AuditFraudCleared, entity.EventSubjectUser, strconv.Itoa(userID),
+		[]entity.FieldChange{{Field: "fraudStatus", Old: before, New: score.Status}}))
+	return score, nil
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/fraud"
+)
+
+// Mock fraud repository for testing
+type MockFraudRepository struct {
+	scores map[int]*entity.FraudScore
+}
+
+func NewMockFraudRepository() *MockFraudRepository {
+	return &MockFraudRepository{scores: make(map[int]*entity.FraudScore)}
+}
+
+func (m *MockFraudRepository) Get(userID int) (*entity.FraudScore, error) {
+	score, ok := m.scores[userID]
+	if !ok {
+		return nil, repository.ErrFraudScoreNotFound
+	}
+	found := *score
+	return &found, nil
+}
+
+func (m *MockFraudRepository) Save(score *entity.FraudScore) error {
+	saved := *score
+	m.scores[score.UserID] = &saved
+	return nil
+}
+
+func (m *MockFraudRepository) List(status string, limit int) ([]*entity.FraudScore, error) {
+	var scores []*entity.FraudScore
+	for _, score := range m.scores {
+		if score.Status == status && len(scores) < limit {
+			found := *score
+			scores = append(scores, &found)
+		}
+	}
+	return scores, nil
+}
+
+var _ repository.FraudRepository = (*MockFraudRepository)(nil)
+
+func TestFraudUseCase_ScoreUser(t *testing.T) {
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	userRepo := NewMockUserRepository()
+	quiet, _ := userRepo.Create(entity.NewUser("quiet@example.com", "hash", "Quiet User", "0812345678", "1990-01-15"))
+	burner, _ := userRepo.Create(entity.NewUser("burner@yopmail.com", "hash", "Burner User", "0899999999", "1990-01-15"))
+
+	logins := &MockLoginEventRepository{}
+	for _, login := range []struct {
+		userID  int
+		success bool
+		country string
+		at      time.Time
+	}{
+		{quiet.ID, true, "TH", now.Add(-2 * time.Hour)},
+		// Outside the window
+		{quiet.ID, true, "JP", now.Add(-48 * time.Hour)},
+		{burner.ID, true, "TH", now.Add(-20 * time.Hour)},
+		{burner.ID, true, "US", now.Add(-10 * time.Minute)},
+		{burner.ID, false, "US", now.Add(-5 * time.Minute)},
+		{burner.ID, false, "US", now.Add(-4 * time.Minute)},
+		{burner.ID, false, "US", now.Add(-3 * time.Minute)},
+	} {
+		logins.now = login.at
+		logins.Record(&entity.LoginEvent{UserID: login.userID, Success: login.success, Country: login.country})
+	}
+
+	fraudRepo := NewMockFraudRepository()
+	uc := NewFraudUseCase(fraudRepo, userRepo, logins, fraud.New(fraud.Options{VelocityLimit: 3}), 50)
+	uc.now = func() time.Time { return now }
+
+	score, err := uc.ScoreUser(quiet.ID)
+	if err != nil || score.Score != 0 || score.Status != entity.FraudClear || len(score.Signals) != 0 {
+		t.Fatalf("ScoreUser(quiet) = %+v, %v", score, err)
+	}
+	score, err = uc.ScoreUser(burner.ID)
+	if err != nil {
+		t.Fatalf("ScoreUser(burner) error = %v", err)
+	}
+	if want := []string{fraud.SignalDisposableEmail, fraud.SignalGeo, fraud.SignalVelocity}; !reflect.DeepEqual(score.Signals, want) || score.Score != 85 {
+		t.Errorf("ScoreUser(burner) = %d %v, want 85 %v", score.Score, score.Signals, want)
+	}
+	if !score.Restricted() || score.User == nil || score.User.Email != "burner@yopmail.com" {
+		t.Errorf("ScoreUser(burner) = %+v, want flagged", score)
+	}
+
+	for userID, want := range map[int]bool{quiet.ID: false, burner.ID: true, 99: false} {
+		if restricted, err := uc.Restricted(userID); err != nil || restricted != want {
+			t.Errorf("Restricted(%d) = %v, %v; want %v", userID, restricted, err, want)
+		}
+	}
+
+	flagged, err := uc.List(entity.FraudFlagged, 10)
+	if err != nil || len(flagged) != 1 || flagged[0].UserID != burner.ID || flagged[0].User == nil {
+		t.Fatalf("List(flagged) = %+v, %v", flagged, err)
+	}
+
+	cleared, err := uc.Clear(burner.ID, 1)
+	if err != nil || cleared.Status != entity.FraudCleared || cleared.ClearedBy != 1 {
+		t.Fatalf("Clear() = %+v, %v", cleared, err)
+	}
+	if restricted, _ := uc.Restricted(burner.ID); restricted {
+		t.Error("Restricted() after clearing = true")
+	}
+	if _, err := uc.Clear(burner.ID, 1); !errors.Is(err, ErrFraudNotFlagged) {
+		t.Errorf("Clear() of a cleared user error = %v, want ErrFraudNotFlagged", err)
+	}
+	if _, err := uc.Clear(99, 1); !errors.Is(err, ErrFraudScoreNotFound) {
+		t.Errorf("Clear() of an unscored user error = %v, want ErrFraudScoreNotFound", err)
+	}
+
+	// The same signals do not flag a cleared account again
+	if score, _ := uc.ScoreUser(burner.ID); score.Restricted() {
+		t.Errorf("ScoreUser() after clearing = %+v, want cleared", score)
+	}
+}
+
+func TestFraudUseCase_ScoreRecent(t *testing.T) {
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	userRepo := NewMockUserRepository()
+	user, _ := userRepo.Create(entity.NewUser("burner@mailinator.com", "hash", "Burner User", "0899999999", "1990-01-15"))
+	logins := &MockLoginEventRepository{now: now.Add(-time.Minute)}
+	logins.Record(&entity.LoginEvent{UserID: user.ID, Success: true})
+	// A deleted account
+	logins.Record(&entity.LoginEvent{UserID: 99, Success: true})
+
+	fraudRepo := NewMockFraudRepository()
+	uc := NewFraudUseCase(fraudRepo, userRepo, logins, fraud.New(fraud.Options{}), 30)
+	uc.now = func() time.Time { return now }
+
+	scored, err := uc.ScoreRecent()
+	if err != nil || scored != 1 {
+		t.Fatalf("ScoreRecent() = %d, %v; want 1", scored, err)
+	}
+	if score := fraudRepo.scores[user.ID]; score == nil || !score.Restricted() {
+		t.Errorf("score = %+v, want flagged", score)
+	}
+	if !uc.since.Equal(now) {
+		t.Errorf("since = %v, want %v", uc.since, now)
+	}
+}
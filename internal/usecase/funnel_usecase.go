@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// maxFunnelReportDays limits the size of a single funnel report
+const maxFunnelReportDays = 366
+
+// FunnelReport summarizes registration funnel activity over a date range
+type FunnelReport struct {
+	From       string
+	To         string
+	Days       []entity.FunnelDay
+	Totals     map[entity.FunnelStage]int
+	Conversion map[entity.FunnelStage]float64
+}
+
+// FunnelUseCase handles registration funnel tracking and reporting
+type FunnelUseCase struct {
+	funnelRepo repository.FunnelRepository
+}
+
+// NewFunnelUseCase creates a new funnel use case
+func NewFunnelUseCase(funnelRepo repository.FunnelRepository) *FunnelUseCase {
+	return &FunnelUseCase{
+		funnelRepo: funnelRepo,
+	}
+}
+
+// Track records that a funnel stage was reached
+func (uc *FunnelUseCase) Track(stage entity.FunnelStage, userID int) error {
+	return uc.funnelRepo.Record(entity.NewFunnelEvent(stage, userID))
+}
+
+// TrackFirstLogin records the first login stage; the repository records it
+// once per user, so later logins are ignored
+func (uc *FunnelUseCase) TrackFirstLogin(userID int) error {
+	return uc.Track(entity.FunnelStageFirstLogin, userID)
+}
+
+// GetReport builds a daily funnel breakdown between from and to (YYYY-MM-DD).
+// Empty values default to the last 30 days.
+func (uc *FunnelUseCase) GetReport(from, to string) (*FunnelReport, error) {
+	toDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return nil, errors.New("invalid to date, should be YYYY-MM-DD")
+		}
+		toDate = parsed
+	}
+
+	fromDate := toDate.AddDate(0, 0, -29)
+	if from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return nil, errors.New("invalid from date, should be YYYY-MM-DD")
+		}
+		fromDate = parsed
+	}
+
+	if fromDate.After(toDate) {
+		return nil, errors.New("from date must not be after to date")
+	}
+	if toDate.Sub(fromDate) >= maxFunnelReportDays*24*time.Hour {
+		return nil, errors.New("date range must not exceed 366 days")
+	}
+
+	fromStr := fromDate.Format("2006-01-02")
+	toStr := toDate.Format("2006-01-02")
+
+	counted, err := uc.funnelRepo.CountByDay(fromStr, toStr)
+	if err != nil {
+		return nil, errors.New("failed to load funnel data")
+	}
+
+	byDate := make(map[string]entity.FunnelDay, len(counted))
+	for _, day := range counted {
+		byDate[day.Date] = day
+	}
+
+	// Fill every day in range so gaps show as zero rather than missing
+	report := &FunnelReport{
+		From:       fromStr,
+		To:         toStr,
+		Totals:     make(map[entity.FunnelStage]int),
+		Conversion: make(map[entity.FunnelStage]float64),
+	}
+	for d := fromDate; !d.After(toDate); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		day := entity.FunnelDay{Date: date, Counts: make(map[entity.FunnelStage]int)}
+		for _, stage := range entity.FunnelStages {
+			day.Counts[stage] = byDate[date].Counts[stage]
+			report.Totals[stage] += day.Counts[stage]
+		}
+		report.Days = append(report.Days, day)
+	}
+
+	// Conversion is expressed relative to the number of started registrations
+	started := report.Totals[entity.FunnelStageStarted]
+	for _, stage := range entity.FunnelStages {
+		if started > 0 {
+			report.Conversion[stage] = float64(report.Totals[stage]) / float64(started)
+		} else {
+			report.Conversion[stage] = 0
+		}
+	}
+
+	return report, nil
+}
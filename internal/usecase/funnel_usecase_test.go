@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// Mock funnel repository for testing
+type MockFunnelRepository struct {
+	events []*entity.FunnelEvent
+	err    error
+}
+
+func (m *MockFunnelRepository) Record(event *entity.FunnelEvent) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, recorded := range m.events {
+		if event.UserID != 0 && recorded.UserID == event.UserID && recorded.Stage == event.Stage {
+			return nil
+		}
+	}
+	event.ID = len(m.events) + 1
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *MockFunnelRepository) CountByDay(from, to string) ([]entity.FunnelDay, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var days []entity.FunnelDay
+	for _, event := range m.events {
+		day := event.Day()
+		if day < from || day > to {
+			continue
+		}
+		if len(days) == 0 || days[len(days)-1].Date != day {
+			days = append(days, entity.FunnelDay{Date: day, Counts: make(map[entity.FunnelStage]int)})
+		}
+		days[len(days)-1].Counts[event.Stage]++
+	}
+	return days, nil
+}
+
+func (m *MockFunnelRepository) add(stage entity.FunnelStage, userID int, at time.Time) {
+	m.events = append(m.events, &entity.FunnelEvent{Stage: stage, UserID: userID, OccurredAt: at})
+}
+
+func TestFunnelUseCase_Track(t *testing.T) {
+	mockRepo := &MockFunnelRepository{}
+	useCase := NewFunnelUseCase(mockRepo)
+
+	if err := useCase.Track(entity.FunnelStageStarted, 0); err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+	if len(mockRepo.events) != 1 {
+		t.Fatalf("events = %v, want 1", len(mockRepo.events))
+	}
+	if mockRepo.events[0].Stage != entity.FunnelStageStarted {
+		t.Errorf("Stage = %v, want %v", mockRepo.events[0].Stage, entity.FunnelStageStarted)
+	}
+}
+
+func TestFunnelUseCase_TrackFirstLogin(t *testing.T) {
+	mockRepo := &MockFunnelRepository{}
+	useCase := NewFunnelUseCase(mockRepo)
+
+	for i := 0; i < 3; i++ {
+		if err := useCase.TrackFirstLogin(42); err != nil {
+			t.Fatalf("TrackFirstLogin() error = %v", err)
+		}
+	}
+
+	if len(mockRepo.events) != 1 {
+		t.Errorf("events = %v, want 1 (first login recorded once)", len(mockRepo.events))
+	}
+}
+
+func TestFunnelUseCase_GetReport(t *testing.T) {
+	mockRepo := &MockFunnelRepository{}
+	day1 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	day3 := time.Date(2024, 3, 3, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 4; i++ {
+		mockRepo.add(entity.FunnelStageStarted, 0, day1)
+	}
+	mockRepo.add(entity.FunnelStageValidated, 0, day1)
+	mockRepo.add(entity.FunnelStageValidated, 0, day1)
+	mockRepo.add(entity.FunnelStageCreated, 1, day1)
+	mockRepo.add(entity.FunnelStageFirstLogin, 1, day3)
+
+	useCase := NewFunnelUseCase(mockRepo)
+	report, err := useCase.GetReport("2024-03-01", "2024-03-03")
+	if err != nil {
+		t.Fatalf("GetReport() error = %v", err)
+	}
+
+	if len(report.Days) != 3 {
+		t.Fatalf("Days = %v, want 3 (gaps filled)", len(report.Days))
+	}
+	if report.Days[1].Date != "2024-03-02" || report.Days[1].Counts[entity.FunnelStageStarted] != 0 {
+		t.Errorf("Day 2 = %+v, want zero counts for 2024-03-02", report.Days[1])
+	}
+	if report.Totals[entity.FunnelStageStarted] != 4 {
+		t.Errorf("Totals[started] = %v, want 4", report.Totals[entity.FunnelStageStarted])
+	}
+	if report.Conversion[entity.FunnelStageValidated] != 0.5 {
+		t.Errorf("Conversion[validated] = %v, want 0.5", report.Conversion[entity.FunnelStageValidated])
+	}
+	if report.Conversion[entity.FunnelStageFirstLogin] != 0.25 {
+		t.Errorf("Conversion[first_login] = %v, want 0.25", report.Conversion[entity.FunnelStageFirstLogin])
+	}
+}
+
+func TestFunnelUseCase_GetReport_Defaults(t *testing.T) {
+	useCase := NewFunnelUseCase(&MockFunnelRepository{})
+
+	report, err := useCase.GetReport("", "")
+	if err != nil {
+		t.Fatalf("GetReport() error = %v", err)
+	}
+	if len(report.Days) != 30 {
+		t.Errorf("Days = %v, want 30", len(report.Days))
+	}
+	if report.Conversion[entity.FunnelStageCreated] != 0 {
+		t.Errorf("Conversion with no starts = %v, want 0", report.Conversion[entity.FunnelStageCreated])
+	}
+}
+
+func TestFunnelUseCase_GetReport_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		errorMsg string
+	}{
+		{"invalid from", "2024/01/01", "2024-01-02", "invalid from date, should be YYYY-MM-DD"},
+		{"invalid to", "2024-01-01", "tomorrow", "invalid to date, should be YYYY-MM-DD"},
+		{"reversed range", "2024-02-01", "2024-01-01", "from date must not be after to date"},
+		{"range too large", "2022-01-01", "2024-01-01", "date range must not exceed 366 days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase := NewFunnelUseCase(&MockFunnelRepository{})
+			_, err := useCase.GetReport(tt.from, tt.to)
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("GetReport() error = %v, want %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestFunnelUseCase_GetReport_RepositoryError(t *testing.T) {
+	useCase := NewFunnelUseCase(&MockFunnelRepository{err: errors.New("db down")})
+
+	_, err := useCase.GetReport("2024-01-01", "2024-01-02")
+	if err == nil || err.Error() != "failed to load funnel data" {
+		t.Errorf("GetReport() error = %v, want 'failed to load funnel data'", err)
+	}
+}
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/hooks"
+)
+
+// maxHookRetryBackoff caps the wait between attempts at a failed hook delivery
+const maxHookRetryBackoff = time.Hour
+
+// ErrInvalidHookDelivery is returned for an unknown status or hook point
+var ErrInvalidHookDelivery = errors.New("invalid hook delivery filter")
+
+// HookDeliveryUseCase retries lifecycle hooks that failed after their change
+// was saved, with exponential backoff, and moves the ones that exhaust their
+// attempts to the dead-letter store, where admins can replay them
+type HookDeliveryUseCase struct {
+	deliveryRepo repository.HookDeliveryRepository
+	eventRepo    repository.EventRepository
+	hooks        *hooks.Registry
+	maxAttempts  int
+	backoff      time.Duration
+	now          func() time.Time
+}
+
+// NewHookDeliveryUseCase creates a new hook delivery use case that attempts
+// each delivery up to maxAttempts times, the first time included, waiting
+// backoff after the first failure and twice as long after each next one
+func NewHookDeliveryUseCase(deliveryRepo repository.HookDeliveryRepository, eventRepo repository.EventRepository, registry *hooks.Registry, maxAttempts int, backoff time.Duration) *HookDeliveryUseCase {
+	return &HookDeliveryUseCase{
+		deliveryRepo: deliveryRepo,
+		eventRepo:    eventRepo,
+		hooks:        registry,
+		maxAttempts:  maxAttempts,
+		backoff:      backoff,
+		now:          time.Now,
+	}
+}
+
+// RecordFailure stores a hook that failed while its event was first run, so
+// it is retried. It is registered with hooks.Registry.OnFailure.
+func (uc *HookDeliveryUseCase) RecordFailure(event *hooks.Event, hook int, hookErr error) {
+	now := uc.now().UTC()
+	delivery := &entity.HookDelivery{
+		EventID:  event.ID,
+		Point:    string(event.Point),
+		Hook:     hook,
+		UserID:   event.UserID,
+		Email:    event.Email,
+		Data:     event.Data,
+		Attempts: 1,
+		Errors:   []entity.HookAttempt{{Error: hookErr.Error(), At: now}},
+	}
+	uc.schedule(delivery, now)
+	if err := uc.deliveryRepo.Create(delivery); err != nil {
+		log.Printf("Failed to queue %s hook %d for user %d for retrying: %v", event.Point, hook, event.UserID, err)
+		return
+	}
+	if delivery.Status == entity.HookDeliveryDead {
+		uc.alert(delivery)
+	}
+}
+
+// Backlog returns the number of deliveries due for another attempt
+func (uc *HookDeliveryUseCase) Backlog() (int, error) {
+	count, err := uc.deliveryRepo.CountDue(uc.now().UTC())
+	if err != nil {
+		return 0, errors.New("failed to count hook deliveries")
+	}
+	return count, nil
+}
+
+// ProcessDue attempts up to limit deliveries that are due and returns how
+// many were attempted. It is run periodically by the job worker.
+func (uc *HookDeliveryUseCase) ProcessDue(limit int) (int, error) {
+	deliveries, err := uc.deliveryRepo.ClaimDue(uc.now().UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		event := &hooks.Event{
+			ID:     delivery.EventID,
+			Point:  hooks.Point(delivery.Point),
+			UserID: delivery.UserID,
+			Email:  delivery.Email,
+			Data:   delivery.Data,
+		}
+		now := uc.now().UTC()
+		delivery.Attempts++
+		if err := uc.hooks.Deliver(event, delivery.Hook); err != nil {
+			delivery.Errors = append(delivery.Errors, entity.HookAttempt{Error: err.Error(), At: now})
+			uc.schedule(delivery, now)
+		} else {
+			delivery.Status = entity.HookDeliveryDelivered
+			log.Printf("Delivered %s hook %d for user %d on attempt %d", delivery.Point, delivery.Hook, delivery.UserID, delivery.Attempts)
+		}
+
+		if err := uc.deliveryRepo.Update(delivery); err != nil {
+			return len(deliveries), err
+		}
+		if delivery.Status == entity.HookDeliveryDead {
+			uc.alert(delivery)
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// schedule sets a failed delivery's next attempt, or moves it to the
+// dead-letter store once it has used its attempts
+func (uc *HookDeliveryUseCase) schedule(delivery *entity.HookDelivery, now time.Time) {
+	if delivery.Attempts >= uc.maxAttempts {
+		delivery.Status = entity.HookDeliveryDead
+		delivery.NextAttemptAt = now
+		return
+	}
+
+	wait := uc.backoff
+	for i := 1; i < delivery.Attempts && wait < maxHookRetryBackoff; i++ {
+		wait *= 2
+	}
+	delivery.Status = entity.HookDeliveryRetrying
+	delivery.NextAttemptAt = now.Add(min(wait, maxHookRetryBackoff))
+}
+
+// alert reports a dead-lettered delivery in the log and the domain event
+// log, where integrations following the event stream can page someone
+func (uc *HookDeliveryUseCase) alert(delivery *entity.HookDelivery) {
+	lastErr := delivery.Errors[len(delivery.Errors)-1].Error
+	log.Printf("Dead-lettered %s hook %d for user %d after %d attempts: %s",
+		delivery.Point, delivery.Hook, delivery.UserID, delivery.Attempts, lastErr)
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventHookDeadLettered, entity.EventSubjectHookDelivery, delivery.ID, 0, map[string]interface{}{
+		"point":    delivery.Point,
+		"hook":     delivery.Hook,
+		"userId":   delivery.UserID,
+		"attempts": delivery.Attempts,
+		"error":    lastErr,
+	}))
+}
+
+// ListDeliveries returns up to limit deliveries with status and point,
+// newest first. An empty status or point matches any.
+func (uc *HookDeliveryUseCase) ListDeliveries(status entity.HookDeliveryStatus, point string, limit int) ([]*entity.HookDelivery, error) {
+	switch status {
+	case "", entity.HookDeliveryRetrying, entity.HookDeliveryRunning, entity.HookDeliveryDelivered, entity.HookDeliveryDead:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidHookDelivery, status)
+	}
+	if err := validHookPoint(point); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := uc.deliveryRepo.List(status, point, limit)
+	if err != nil {
+		return nil, errors.New("failed to list hook deliveries")
+	}
+	return deliveries, nil
+}
+
+// GetDelivery retrieves a delivery. Returns ErrHookDeliveryNotFound if there is none.
+func (uc *HookDeliveryUseCase) GetDelivery(id int) (*entity.HookDelivery, error) {
+	delivery, err := uc.deliveryRepo.GetByID(id)
+	if errors.Is(err, repository.ErrHookDeliveryNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to load hook delivery")
+	}
+	return delivery, nil
+}
+
+// Replay queues a dead delivery for the worker's next pass, with a fresh
+// set of attempts. Returns ErrHookDeliveryNotFound or ErrHookDeliveryNotDead.
+func (uc *HookDeliveryUseCase) Replay(id int) (*entity.HookDelivery, error) {
+	err := uc.deliveryRepo.Replay(id, uc.now().UTC())
+	if errors.Is(err, repository.ErrHookDeliveryNotFound) || errors.Is(err, repository.ErrHookDeliveryNotDead) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("failed to replay hook delivery")
+	}
+	return uc.GetDelivery(id)
+}
+
+// ReplayAll queues every dead delivery at point, or at any point when
+// empty, like Replay, and returns how many were queued
+func (uc *HookDeliveryUseCase) ReplayAll(point string) (int, error) {
+	if err := validHookPoint(point); err != nil {
+		return 0, err
+	}
+	replayed, err := uc.deliveryRepo.ReplayAll(point, uc.now().UTC())
+	if err != nil {
+		return 0, errors.New("failed to replay hook deliveries")
+	}
+	return replayed, nil
+}
+
+// validHookPoint checks a point filter, which may be empty
+func validHookPoint(point string) error {
+	if point != "" && !slices.Contains(hooks.Points, hooks.Point(point)) {
+		return fmt.Errorf("%w: unknown hook point %q", ErrInvalidHookDelivery, point)
+	}
+	return nil
+}
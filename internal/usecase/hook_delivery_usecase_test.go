@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/hooks"
+)
+
+// Mock hook delivery repository for testing
+type MockHookDeliveryRepository struct {
+	deliveries []*entity.HookDelivery
+}
+
+func (m *MockHookDeliveryRepository) Create(delivery *entity.HookDelivery) error {
+	delivery.ID = len(m.deliveries) + 1
+	m.deliveries = append(m.deliveries, delivery)
+	return nil
+}
+
+func (m *MockHookDeliveryRepository) GetByID(id int) (*entity.HookDelivery, error) {
+	if id < 1 || id > len(m.deliveries) {
+		return nil, repository.ErrHookDeliveryNotFound
+	}
+	return m.deliveries[id-1], nil
+}
+
+func (m *MockHookDeliveryRepository) ClaimDue(now time.Time, limit int) ([]*entity.HookDelivery, error) {
+	var claimed []*entity.HookDelivery
+	for _, delivery := range m.deliveries {
+		if len(claimed) < limit && delivery.Status == entity.HookDeliveryRetrying && !delivery.NextAttemptAt.After(now) {
+			delivery.Status = entity.HookDeliveryRunning
+			claimed = append(claimed, delivery)
+		}
+	}
+	return claimed, nil
+}
+
+func (m *MockHookDeliveryRepository) CountDue(now time.Time) (int, error) {
+	count := 0
+	for _, delivery := range m.deliveries {
+		if delivery.Status == entity.HookDeliveryRetrying && !delivery.NextAttemptAt.After(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockHookDeliveryRepository) Update(delivery *entity.HookDelivery) error {
+	m.deliveries[delivery.ID-1] = delivery
+	return nil
+}
+
+func (m *MockHookDeliveryRepository) List(status entity.HookDeliveryStatus, point string, limit int) ([]*entity.HookDelivery, error) {
+	var deliveries []*entity.HookDelivery
+	for i := len(m.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		delivery := m.deliveries[i]
+		if (status == "" || delivery.Status == status) && (point == "" || delivery.Point == point) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (m *MockHookDeliveryRepository) Replay(id int, at time.Time) error {
+	delivery, err := m.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if delivery.Status != entity.HookDeliveryDead {
+		return repository.ErrHookDeliveryNotDead
+	}
+	delivery.Status, delivery.Attempts, delivery.NextAttemptAt = entity.HookDeliveryRetrying, 0, at
+	return nil
+}
+
+func (m *MockHookDeliveryRepository) ReplayAll(point string, at time.Time) (int, error) {
+	replayed := 0
+	for _, delivery := range m.deliveries {
+		if delivery.Status == entity.HookDeliveryDead && (point == "" || delivery.Point == point) {
+			_ = m.Replay(delivery.ID, at)
+			replayed++
+		}
+	}
+	return replayed, nil
+}
+
+// setupHookDeliveryTest returns a use case retrying a password-reset hook
+// that fails while *failing is true, with a clock controlled by the test
+func setupHookDeliveryTest(t *testing.T, maxAttempts int) (*HookDeliveryUseCase, *MockEventRepository, *time.Time, *bool) {
+	t.Helper()
+	failing := true
+	registry := hooks.NewRegistry()
+	registry.Register(hooks.PasswordReset, hooks.HookFunc(func(*hooks.Event) error {
+		if failing {
+			return errors.New("mailer unreachable")
+		}
+		return nil
+	}))
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	eventRepo := &MockEventRepository{}
+	useCase := NewHookDeliveryUseCase(&MockHookDeliveryRepository{}, eventRepo, registry, maxAttempts, 30*time.Second)
+	useCase.now = func() time.Time { return now }
+	registry.OnFailure(useCase.RecordFailure)
+	return useCase, eventRepo, &now, &failing
+}
+
+func TestHookDeliveryUseCase_RetriesWithBackoff(t *testing.T) {
+	useCase, eventRepo, now, failing := setupHookDeliveryTest(t, 3)
+
+	if err := useCase.hooks.Run(&hooks.Event{Point: hooks.PasswordReset, UserID: 7, Email: "lee@example.com",
+		Data: map[string]interface{}{"token": "reset-token"}}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	delivery, err := useCase.GetDelivery(1)
+	if err != nil {
+		t.Fatalf("GetDelivery() error = %v", err)
+	}
+	if delivery.Status != entity.HookDeliveryRetrying || delivery.Attempts != 1 || delivery.Data["token"] != "reset-token" ||
+		!delivery.NextAttemptAt.Equal(now.Add(30*time.Second)) {
+		t.Errorf("recorded delivery = %+v, want a retry in 30s", delivery)
+	}
+
+	// The second failure waits twice as long
+	*now = now.Add(30 * time.Second)
+	if backlog, _ := useCase.Backlog(); backlog != 1 {
+		t.Errorf("Backlog() = %d, want 1", backlog)
+	}
+	if processed, err := useCase.ProcessDue(10); err != nil || processed != 1 {
+		t.Fatalf("ProcessDue() = %d, %v, want 1", processed, err)
+	}
+	if delivery.Attempts != 2 || len(delivery.Errors) != 2 || !delivery.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("after the second attempt = %+v, want a retry in 1m", delivery)
+	}
+
+	*failing = false
+	*now = now.Add(time.Minute)
+	if _, err := useCase.ProcessDue(10); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+	if delivery.Status != entity.HookDeliveryDelivered || delivery.Attempts != 3 {
+		t.Errorf("after the third attempt = %+v, want delivered", delivery)
+	}
+	if len(eventRepo.events) != 0 {
+		t.Errorf("a delivered hook should not raise an alert, got %v", eventRepo.types())
+	}
+}
+
+func TestHookDeliveryUseCase_DeadLetterAndReplay(t *testing.T) {
+	useCase, eventRepo, now, failing := setupHookDeliveryTest(t, 2)
+
+	useCase.hooks.Run(&hooks.Event{Point: hooks.PasswordReset, UserID: 7})
+	*now = now.Add(time.Minute)
+	if _, err := useCase.ProcessDue(10); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+
+	dead, err := useCase.ListDeliveries(entity.HookDeliveryDead, "", 10)
+	if err != nil || len(dead) != 1 || dead[0].Attempts != 2 || len(dead[0].Errors) != 2 {
+		t.Fatalf("ListDeliveries(dead) = %v, %v; want the delivery after 2 attempts", dead, err)
+	}
+	if len(eventRepo.events) != 1 || eventRepo.events[0].Type != entity.EventHookDeadLettered ||
+		eventRepo.events[0].SubjectID != dead[0].ID || eventRepo.events[0].Data["error"] != "mailer unreachable" {
+		t.Errorf("alert events = %+v, want one hook.dead_lettered", eventRepo.events)
+	}
+
+	// Dead deliveries are not retried until replayed
+	*now = now.Add(time.Hour)
+	if processed, _ := useCase.ProcessDue(10); processed != 0 {
+		t.Errorf("ProcessDue() = %d, want 0 for a dead delivery", processed)
+	}
+
+	replayed, err := useCase.Replay(dead[0].ID)
+	if err != nil || replayed.Status != entity.HookDeliveryRetrying || replayed.Attempts != 0 {
+		t.Fatalf("Replay() = %+v, %v", replayed, err)
+	}
+	if _, err := useCase.Replay(dead[0].ID); !errors.Is(err, repository.ErrHookDeliveryNotDead) {
+		t.Errorf("Replay() twice error = %v, want ErrHookDeliveryNotDead", err)
+	}
+	if _, err := useCase.Replay(99); !errors.Is(err, repository.ErrHookDeliveryNotFound) {
+		t.Errorf("Replay(99) error = %v, want ErrHookDeliveryNotFound", err)
+	}
+
+	*failing = false
+	if _, err := useCase.ProcessDue(10); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+	if delivery, _ := useCase.GetDelivery(dead[0].ID); delivery.Status != entity.HookDeliveryDelivered || len(delivery.Errors) != 2 {
+		t.Errorf("replayed delivery = %+v, want delivered with its error history", delivery)
+	}
+}
+
+func TestHookDeliveryUseCase_KeepsEventID(t *testing.T) {
+	var sent []string
+	registry := hooks.NewRegistry()
+	registry.Register(hooks.PostRegister, hooks.HookFunc(func(event *hooks.Event) error {
+		sent = append(sent, event.ID)
+		return errors.New("webhook returned 502")
+	}))
+	useCase := NewHookDeliveryUseCase(&MockHookDeliveryRepository{}, &MockEventRepository{}, registry, 3, 0)
+	registry.OnFailure(useCase.RecordFailure)
+
+	// Receivers dedupe retries on the event ID, so every attempt sends the first one
+	registry.Run(&hooks.Event{Point: hooks.PostRegister, UserID: 7})
+	useCase.ProcessDue(10)
+	delivery, _ := useCase.GetDelivery(1)
+	if len(sent) != 2 || sent[0] == "" || sent[1] != sent[0] || delivery.EventID != sent[0] {
+		t.Errorf("event IDs sent = %v, stored %q; want the same ID every time", sent, delivery.EventID)
+	}
+}
+
+func TestHookDeliveryUseCase_SingleAttempt(t *testing.T) {
+	useCase, eventRepo, _, _ := setupHookDeliveryTest(t, 1)
+
+	// With one attempt, a failure goes straight to the dead-letter store
+	useCase.hooks.Run(&hooks.Event{Point: hooks.PasswordReset, UserID: 7})
+	if delivery, _ := useCase.GetDelivery(1); delivery.Status != entity.HookDeliveryDead {
+		t.Errorf("Status = %v, want dead", delivery.Status)
+	}
+	if len(eventRepo.events) != 1 {
+		t.Errorf("alert events = %v, want one", eventRepo.types())
+	}
+}
+
+func TestHookDeliveryUseCase_ReplayAll(t *testing.T) {
+	useCase, _, _, _ := setupHookDeliveryTest(t, 1)
+
+	for i := 0; i < 3; i++ {
+		useCase.hooks.Run(&hooks.Event{Point: hooks.PasswordReset, UserID: i + 1})
+	}
+	if replayed, err := useCase.ReplayAll(string(hooks.PostRegister)); err != nil || replayed != 0 {
+		t.Errorf("ReplayAll(post-register) = %d, %v; want 0", replayed, err)
+	}
+	if replayed, err := useCase.ReplayAll(string(hooks.PasswordReset)); err != nil || replayed != 3 {
+		t.Errorf("ReplayAll(password-reset) = %d, %v; want 3", replayed, err)
+	}
+
+	if _, err := useCase.ReplayAll("on-login"); !errors.Is(err, ErrInvalidHookDelivery) {
+		t.Errorf("ReplayAll() unknown point error = %v, want ErrInvalidHookDelivery", err)
+	}
+	if _, err := useCase.ListDeliveries("lost", "", 10); !errors.Is(err, ErrInvalidHookDelivery) {
+		t.Errorf("ListDeliveries() unknown status error = %v, want ErrInvalidHookDelivery", err)
+	}
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/inbound"
+)
+
+const (
+	// inboundEventRetention is how long received event IDs are kept to catch
+	// replays; providers stop retrying within a few days
+	inboundEventRetention = 30 * 24 * time.Hour
+	// inboundProcessingTimeout is how long an event may be processing before
+	// a retry takes it over, e.g. after a crash
+	inboundProcessingTimeout = 5 * time.Minute
+)
+
+// ErrInboundHandlerFailed is returned when a handler fails on a received
+// event, so the provider retries the request
+var ErrInboundHandlerFailed = errors.New("webhook event handling failed")
+
+// InboundReceipt counts the events of a received webhook request.
+// Duplicates were received before and skipped.
+type InboundReceipt struct {
+	Received   int
+	Duplicates int
+}
+
+// InboundWebhookUseCase receives webhooks from integrated providers: it
+// verifies them, skips events it already handled and routes the others to
+// their handlers
+type InboundWebhookUseCase struct {
+	inboundRepo repository.InboundEventRepository
+	router      *inbound.Router
+	userUseCase *UserUseCase
+	eventRepo   repository.EventRepository
+	now         func() time.Time
+}
+
+// NewInboundWebhookUseCase creates a new inbound webhook use case for the
+// providers and handlers in router
+func NewInboundWebhookUseCase(inboundRepo repository.InboundEventRepository, router *inbound.Router, userUseCase *UserUseCase, eventRepo repository.EventRepository) *InboundWebhookUseCase {
+	return &InboundWebhookUseCase{
+		inboundRepo: inboundRepo,
+		router:      router,
+		userUseCase: userUseCase,
+		eventRepo:   eventRepo,
+		now:         time.Now,
+	}
+}
+
+// Receive verifies a request from provider and handles each of its events
+// once. Returns inbound.ErrUnknownProvider, inbound.ErrInvalidSignature,
+// inbound.ErrExpired, inbound.ErrInvalidPayload or ErrInboundHandlerFailed.
+func (uc *InboundWebhookUseCase) Receive(provider string, req *inbound.Request) (*InboundReceipt, error) {
+	p, err := uc.router.Provider(provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Verify(req, uc.now()); err != nil {
+		return nil, err
+	}
+	events, err := p.Events(req)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &InboundReceipt{}
+	var failed error
+	for i := range events {
+		event := &events[i]
+		now := uc.now().UTC()
+		stored := &entity.InboundEvent{Provider: provider, EventID: event.ID, Type: event.Type, Subject: event.Subject, ReceivedAt: now}
+		claimed, err := uc.inboundRepo.Claim(stored, now.Add(-inboundProcessingTimeout))
+		if err != nil {
+			return nil, errors.New("failed to record webhook event")
+		}
+		if !claimed {
+			receipt.Duplicates++
+			continue
+		}
+
+		receipt.Received++
+		var errMsg string
+		if err := uc.router.Dispatch(event); err != nil {
+			log.Printf("Handling %s %s event %s failed: %v", provider, event.Type, event.ID, err)
+			errMsg = err.Error()
+			failed = fmt.Errorf("%w: %s event %s", ErrInboundHandlerFailed, event.Type, event.ID)
+		}
+		if err := uc.inboundRepo.Finish(stored.ID, errMsg, uc.now().UTC()); err != nil {
+			return nil, errors.New("failed to record webhook event")
+		}
+	}
+	return receipt, failed
+}
+
+// RecordBounce records a user.email_bounced event for the user whose email
+// bounced. Bounces of addresses that are not a user's are ignored.
+func (uc *InboundWebhookUseCase) RecordBounce(event *inbound.Event) error {
+	user, err := uc.userUseCase.GetUserByEmail(event.Subject)
+	if err != nil {
+		return nil
+	}
+	reason, _ := event.Data["reason"].(string)
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventUserEmailBounced, entity.EventSubjectUser, user.ID, 0, map[string]interface{}{
+		"provider": event.Provider,
+		"type":     event.Type,
+		"reason":   reason,
+	}))
+	return nil
+}
+
+// DeleteExpired removes received events older than the replay window
+func (uc *InboundWebhookUseCase) DeleteExpired() (int, error) {
+	return uc.inboundRepo.DeleteBefore(uc.now().Add(-inboundEventRetention))
+}
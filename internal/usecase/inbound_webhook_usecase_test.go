@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/pkg/inbound"
+)
+
+// Mock inbound event repository for testing
+type MockInboundEventRepository struct {
+	events []*entity.InboundEvent
+}
+
+func (m *MockInboundEventRepository) Claim(event *entity.InboundEvent, staleBefore time.Time) (bool, error) {
+	for _, stored := range m.events {
+		if stored.Provider == event.Provider && stored.EventID == event.EventID {
+			if stored.Status == entity.InboundEventFailed || (stored.Status == entity.InboundEventProcessing && stored.ReceivedAt.Before(staleBefore)) {
+				stored.Status, stored.ReceivedAt = entity.InboundEventProcessing, event.ReceivedAt
+				event.ID, event.Status = stored.ID, stored.Status
+				return true, nil
+			}
+			return false, nil
+		}
+	}
+	event.ID, event.Status = len(m.events)+1, entity.InboundEventProcessing
+	stored := *event
+	m.events = append(m.events, &stored)
+	return true, nil
+}
+
+func (m *MockInboundEventRepository) Finish(id int, errMsg string, at time.Time) error {
+	stored := m.events[id-1]
+	stored.Status, stored.Error, stored.ProcessedAt = entity.InboundEventProcessed, errMsg, &at
+	if errMsg != "" {
+		stored.Status = entity.InboundEventFailed
+	}
+	return nil
+}
+
+func (m *MockInboundEventRepository) DeleteBefore(before time.Time) (int, error) {
+	kept := m.events[:0]
+	for _, event := range m.events {
+		if !event.ReceivedAt.Before(before) {
+			kept = append(kept, event)
+		}
+	}
+	deleted := len(m.events) - len(kept)
+	m.events = kept
+	return deleted, nil
+}
+
+// testProvider accepts requests whose Authorization header is "valid" and
+// whose body is a JSON array of events
+type testProvider struct{}
+
+func (testProvider) Name() string { return "test" }
+
+func (testProvider) Verify(req *inbound.Request, _ time.Time) error {
+	if req.Header.Get("Authorization") != "valid" {
+		return inbound.ErrInvalidSignature
+	}
+	return nil
+}
+
+func (testProvider) Events(req *inbound.Request) ([]inbound.Event, error) {
+	var events []inbound.Event
+	if err := json.Unmarshal(req.Body, &events); err != nil {
+		return nil, inbound.ErrInvalidPayload
+	}
+	for i := range events {
+		events[i].Provider = "test"
+	}
+	return events, nil
+}
+
+func testInboundRequest(body string) *inbound.Request {
+	req := &inbound.Request{Header: map[string][]string{}, Body: []byte(body)}
+	req.Header.Set("Authorization", "valid")
+	return req
+}
+
+func TestInboundWebhookUseCase_Receive(t *testing.T) {
+	router := inbound.NewRouter()
+	router.AddProvider(testProvider{})
+	var handled []string
+	failing := true
+	router.Handle("test", "paid", inbound.HandlerFunc(func(event *inbound.Event) error {
+		handled = append(handled, event.ID)
+		return nil
+	}))
+	router.Handle("test", "refunded", inbound.HandlerFunc(func(event *inbound.Event) error {
+		if failing {
+			return errors.New("ledger unavailable")
+		}
+		handled = append(handled, event.ID)
+		return nil
+	}))
+	repo := &MockInboundEventRepository{}
+	useCase := NewInboundWebhookUseCase(repo, router, nil, nil)
+
+	receipt, err := useCase.Receive("test", testInboundRequest(`[{"ID":"evt_1","Type":"paid"},{"ID":"evt_2","Type":"paid"}]`))
+	if err != nil || receipt.Received != 2 || receipt.Duplicates != 0 {
+		t.Fatalf("Receive() = %+v, %v", receipt, err)
+	}
+
+	// A replayed request is acknowledged without running the handlers again
+	receipt, err = useCase.Receive("test", testInboundRequest(`[{"ID":"evt_1","Type":"paid"},{"ID":"evt_3","Type":"paid"}]`))
+	if err != nil || receipt.Received != 1 || receipt.Duplicates != 1 {
+		t.Errorf("Receive() of a replay = %+v, %v; want one duplicate", receipt, err)
+	}
+	if len(handled) != 3 {
+		t.Errorf("handled %v, want each event once", handled)
+	}
+
+	// A failed event is handled again when the provider retries it
+	if _, err := useCase.Receive("test", testInboundRequest(`[{"ID":"evt_4","Type":"refunded"}]`)); !errors.Is(err, ErrInboundHandlerFailed) {
+		t.Fatalf("Receive() with a failing handler error = %v, want ErrInboundHandlerFailed", err)
+	}
+	failing = false
+	if receipt, err := useCase.Receive("test", testInboundRequest(`[{"ID":"evt_4","Type":"refunded"}]`)); err != nil || receipt.Received != 1 {
+		t.Errorf("Receive() retry = %+v, %v; want the event handled", receipt, err)
+	}
+	if repo.events[3].Status != entity.InboundEventProcessed {
+		t.Errorf("retried event status = %v, want processed", repo.events[3].Status)
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		req      *inbound.Request
+		wantErr  error
+	}{
+		{"unknown provider", "paypal", testInboundRequest(`[]`), inbound.ErrUnknownProvider},
+		{"bad signature", "test", &inbound.Request{Header: map[string][]string{}}, inbound.ErrInvalidSignature},
+		{"bad payload", "test", testInboundRequest(`{`), inbound.ErrInvalidPayload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := useCase.Receive(tt.provider, tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Receive() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInboundWebhookUseCase_RecordBounce(t *testing.T) {
+	userUseCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	user, err := userUseCase.RegisterUser("lee@example.com", "password123", "Lee Park", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := &MockEventRepository{}
+	useCase := NewInboundWebhookUseCase(&MockInboundEventRepository{}, inbound.NewRouter(), userUseCase, events)
+
+	useCase.RecordBounce(&inbound.Event{Provider: "sendgrid", Type: "bounce", Subject: "lee@example.com",
+		Data: map[string]interface{}{"reason": "550 mailbox unavailable"}})
+	useCase.RecordBounce(&inbound.Event{Provider: "sendgrid", Type: "bounce", Subject: "unknown@example.com"})
+
+	if len(events.events) != 1 || events.events[0].Type != entity.EventUserEmailBounced || events.events[0].SubjectID != user.ID ||
+		events.events[0].Data["reason"] != "550 mailbox unavailable" {
+		t.Errorf("events = %+v, want one user.email_bounced for the user", events.events)
+	}
+}
+
+func TestInboundWebhookUseCase_DeleteExpired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := &MockInboundEventRepository{}
+	useCase := NewInboundWebhookUseCase(repo, inbound.NewRouter(), nil, nil)
+	useCase.now = func() time.Time { return now }
+
+	repo.Claim(&entity.InboundEvent{Provider: "test", EventID: "evt_old", ReceivedAt: now.Add(-31 * 24 * time.Hour)}, now)
+	repo.Claim(&entity.InboundEvent{Provider: "test", EventID: "evt_new", ReceivedAt: now.Add(-time.Hour)}, now)
+	if deleted, err := useCase.DeleteExpired(); err != nil || deleted != 1 || repo.events[0].EventID != "evt_new" {
+		t.Errorf("DeleteExpired() = %d, %v; want the old event deleted", deleted, err)
+	}
+}
@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ErrKYCSubmissionNotFound is returned when no identity document submission matches
+var ErrKYCSubmissionNotFound = repository.ErrKYCSubmissionNotFound
+
+// ErrInvalidKYCDocument is returned for an unknown document type or a
+// document that is empty, too large or not a JPEG, PNG or PDF
+var ErrInvalidKYCDocument = errors.New("invalid identity document")
+
+// ErrKYCInProgress is returned when submitting a document while another one
+// waits for a decision
+var ErrKYCInProgress = repository.ErrKYCInProgress
+
+// ErrKYCAlreadyVerified is returned when a verified user submits a document
+var ErrKYCAlreadyVerified = errors.New("identity is already verified")
+
+// ErrKYCSubmissionDecided is returned when reviewing a submission that was
+// already verified or rejected, or decided on while it was being reviewed
+var ErrKYCSubmissionDecided = repository.ErrKYCSubmissionDecided
+
+// ErrKYCReasonRequired is returned when rejecting a submission without a reason
+var ErrKYCReasonRequired = errors.New("a rejection needs a reason")
+
+// MaxKYCDocumentBytes is the maximum accepted identity document size. It
+// stays below the default MAX_REQUEST_BYTES, leaving room for the form.
+const MaxKYCDocumentBytes = 3 << 20
+
+// kycVerifyBatch is how many pending submissions VerifyPending sends to the
+// provider in one run
+const kycVerifyBatch = 50
+
+// kycExtensions lists the content types accepted as identity documents and
+// the extension they are stored with
+var kycExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// KYCUseCase takes the identity documents of users, has them checked by a
+// verification provider, and leaves those it cannot decide, or all of them
+// without a provider, to admin review. Users carry the status of their
+// latest submission.
+type KYCUseCase struct {
+	kycRepo   repository.KYCRepository
+	store     repository.BlobStore
+	provider  repository.KYCProvider
+	users     *UserUseCase
+	auditRepo repository.AuditRepository
+	now       func() time.Time
+}
+
+// NewKYCUseCase creates a new KYC use case keeping documents in store.
+// provider may be nil, when every submission is reviewed by an admin.
+func NewKYCUseCase(kycRepo repository.KYCRepository, store repository.BlobStore, provider repository.KYCProvider, users *UserUseCase) *KYCUseCase {
+	return &KYCUseCase{
+		kycRepo:  kycRepo,
+		store:    store,
+		provider: provider,
+		users:    users,
+		now:      time.Now,
+	}
+}
+
+// SetAuditLog records the submissions admins review in the audit trail
+func (uc *KYCUseCase) SetAuditLog(auditRepo repository.AuditRepository) {
+	uc.auditRepo = auditRepo
+}
+
+// Submit stores an identity document of a user and queues it for the
+// provider, or for admin review without one. A user may submit again once
+// rejected, but not while a submission is open or once verified.
+func (uc *KYCUseCase) Submit(userID int, documentType string, document []byte) (*entity.KYCSubmission, error) {
+	if !entity.ValidKYCDocumentType(documentType) {
+		return nil, fmt.Errorf("%w: documentType must be %s, %s or %s", ErrInvalidKYCDocument,
+			entity.KYCDocumentNationalID, entity.KYCDocumentPassport, entity.KYCDocumentDrivingLicense)
+	}
+	if len(document) == 0 {
+		return nil, fmt.Errorf("%w: document must not be empty", ErrInvalidKYCDocument)
+	}
+	if len(document) > MaxKYCDocumentBytes {
+		return nil, fmt.Errorf("%w: document must not exceed 3MB", ErrInvalidKYCDocument)
+	}
+	contentType := http.DetectContentType(document)
+	extension, ok := kycExtensions[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: document must be a JPEG or PNG image or a PDF", ErrInvalidKYCDocument)
+	}
+
+	user, err := uc.users.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.KYCStatus == entity.KYCVerified {
+		return nil, ErrKYCAlreadyVerified
+	}
+	latest, err := uc.kycRepo.Latest(userID)
+	if err != nil && !errors.Is(err, repository.ErrKYCSubmissionNotFound) {
+		return nil, err
+	}
+	if latest != nil && latest.Open() {
+		return nil, fmt.Errorf("%w: submission %d is %s", ErrKYCInProgress, latest.ID, latest.Status)
+	}
+
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("kyc/%d/%s%s", userID, hex.EncodeToString(name), extension)
+	if err := uc.store.Put(key, document, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+
+	submission := &entity.KYCSubmission{
+		UserID:       userID,
+		DocumentType: documentType,
+		ContentType:  contentType,
+		DocumentKey:  key,
+		Size:         len(document),
+		Status:       entity.KYCReview,
+	}
+	if uc.provider != nil {
+		submission.Status = entity.KYCPending
+	}
+	if err := uc.kycRepo.Create(submission); err != nil {
+		// Nothing refers to the document any more
+		if err := uc.store.Delete(key); err != nil {
+			log.Printf("Failed to delete unsubmitted KYC document %s: %v", key, err)
+		}
+		if errors.Is(err, repository.ErrKYCInProgress) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save submission: %w", err)
+	}
+	if _, err := uc.users.updateFields(userID, userID, map[string]interface{}{repository.FieldKYCStatus: submission.Status}); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// Status returns the latest submission of a user
+func (uc *KYCUseCase) Status(userID int) (*entity.KYCSubmission, error) {
+	return uc.kycRepo.Latest(userID)
+}
+
+// VerifyPending sends pending submissions to the provider and applies its
+// decisions. Submissions the provider fails on stay pending for the next
+// run. It returns how many submissions were decided or passed on to review.
+func (uc *KYCUseCase) VerifyPending() (int, error) {
+	if uc.provider == nil {
+		return 0, nil
+	}
+	submissions, err := uc.kycRepo.List(entity.KYCPending, kycVerifyBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pending submissions: %w", err)
+	}
+
+	var verified int
+	for _, submission := range submissions {
+		document, err := uc.store.Get(submission.DocumentKey)
+		if err != nil {
+			log.Printf("Failed to read document of KYC submission %d: %v", submission.ID, err)
+			continue
+		}
+		decision, err := uc.provider.Verify(submission, document)
+		if err != nil {
+			log.Printf("Failed to verify KYC submission %d: %v", submission.ID, err)
+			continue
+		}
+		// An admin may have decided meanwhile; their decision stands
+		if err := uc.decide(submission, decision, 0); err != nil {
+			log.Printf("Failed to save decision on KYC submission %d: %v", submission.ID, err)
+			continue
+		}
+		verified++
+	}
+	return verified, nil
+}
+
+// List returns up to limit submissions with status, oldest first
+func (uc *KYCUseCase) List(status string, limit int) ([]*entity.KYCSubmission, error) {
+	submissions, err := uc.kycRepo.List(status, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, submission := range submissions {
+		// Accounts deleted since they submitted are left nil
+		submission.User, _ = uc.users.GetUserByID(submission.UserID)
+	}
+	return submissions, nil
+}
+
+// Document returns a submission with its document, for review
+func (uc *KYCUseCase) Document(id int) (*entity.KYCSubmission, []byte, error) {
+	submission, err := uc.kycRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	document, err := uc.store.Get(submission.DocumentKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	return submission, document, nil
+}
+
+// Approve verifies the identity of the user of an open submission after
+// an admin reviewed it
+func (uc *KYCUseCase) Approve(id, reviewerID int) (*entity.KYCSubmission, error) {
+	return uc.review(id, reviewerID, entity.KYCDecision{Status: entity.KYCVerified})
+}
+
+// Reject refuses an open submission with a reason shown to the user, who
+// may then submit another document
+func (uc *KYCUseCase) Reject(id, reviewerID int, reason string) (*entity.KYCSubmission, error) {
+	if reason == "" {
+		return nil, ErrKYCReasonRequired
+	}
+	return uc.review(id, reviewerID, entity.KYCDecision{Status: entity.KYCRejected, Reason: reason})
+}
+
+// review applies an admin's decision to an open submission and records it
+// in the audit trail
+func (uc *KYCUseCase) review(id, reviewerID int, decision entity.KYCDecision) (*entity.KYCSubmission, error) {
+	submission, err := uc.kycRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !submission.Open() {
+		return nil, fmt.Errorf("%w: submission %d is %s", ErrKYCSubmissionDecided, id, submission.Status)
+	}
+	before := *submission
+	if err := uc.decide(submission, decision, reviewerID); err != nil {
+		return nil, err
+	}
+	recordAudit(uc.auditRepo, entity.NewAuditEntry(reviewerID, entity.AuditKYCReviewed, entity.AuditSubjectKYCSubmission, strconv.Itoa(id),
+		[]entity.FieldChange{
+			{Field: "status", Old: before.Status, New: submission.Status},
+			{Field: "reason", Old: before.Reason, New: submission.Reason},
+		}))
+	return submission, nil
+}
+
+// decide saves a decision on a submission by reviewerID, or by the provider
+// when 0, and sets it as the status of its user. Returns
+// ErrKYCSubmissionDecided when the submission's status changed since it was
+// read, so the first of an admin and the provider to decide wins.
+func (uc *KYCUseCase) decide(submission *entity.KYCSubmission, decision entity.KYCDecision, reviewerID int) error {
+	read := submission.Status
+	submission.Decide(decision, reviewerID, uc.now().UTC())
+	if err := uc.kycRepo.Update(submission, read); err != nil {
+		return err
+	}
+	// The decision stands even if the account was deleted meanwhile
+	if _, err := uc.users.updateFields(reviewerID, submission.UserID, map[string]interface{}{repository.FieldKYCStatus: submission.Status}); err != nil {
+		log.Printf("Failed to set KYC status of user %d: %v", submission.UserID, err)
+	}
+	submission.User, _ = uc.users.GetUserByID(submission.UserID)
+	return nil
+}
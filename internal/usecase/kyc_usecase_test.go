@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock KYC repository for testing
+type MockKYCRepository struct {
+	submissions []*entity.KYCSubmission
+	createErr   error
+}
+
+func (m *MockKYCRepository) Create(submission *entity.KYCSubmission) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	for _, other := range m.submissions {
+		if other.UserID == submission.UserID && other.Open() && submission.Open() {
+			return repository.ErrKYCInProgress
+		}
+	}
+	submission.ID = len(m.submissions) + 1
+	stored := *submission
+	m.submissions = append(m.submissions, &stored)
+	return nil
+}
+
+func (m *MockKYCRepository) GetByID(id int) (*entity.KYCSubmission, error) {
+	if id < 1 || id > len(m.submissions) {
+		return nil, repository.ErrKYCSubmissionNotFound
+	}
+	found := *m.submissions[id-1]
+	return &found, nil
+}
+
+func (m *MockKYCRepository) Latest(userID int) (*entity.KYCSubmission, error) {
+	for i := len(m.submissions) - 1; i >= 0; i-- {
+		if m.submissions[i].UserID == userID {
+			found := *m.submissions[i]
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrKYCSubmissionNotFound
+}
+
+func (m *MockKYCRepository) List(status string, limit int) ([]*entity.KYCSubmission, error) {
+	var submissions []*entity.KYCSubmission
+	for _, submission := range m.submissions {
+		if submission.Status == status && len(submissions) < limit {
+			found := *submission
+			submissions = append(submissions, &found)
+		}
+	}
+	return submissions, nil
+}
+
+func (m *MockKYCRepository) Update(submission *entity.KYCSubmission, status string) error {
+	if submission.ID < 1 || submission.ID > len(m.submissions) || m.submissions[submission.ID-1].Status != status {
+		return repository.ErrKYCSubmissionDecided
+	}
+	stored := *submission
+	m.submissions[submission.ID-1] = &stored
+	return nil
+}
+
+var _ repository.KYCRepository = (*MockKYCRepository)(nil)
+
+// Mock KYC provider deciding by the document's content
+type MockKYCProvider struct {
+	calls int
+}
+
+func (m *MockKYCProvider) Verify(submission *entity.KYCSubmission, document []byte) (entity.KYCDecision, error) {
+	m.calls++
+	switch {
+	case bytes.Contains(document, []byte("forged")):
+		return entity.KYCDecision{Status: entity.KYCRejected, Reference: "chk_2", Reason: "document was altered"}, nil
+	case bytes.Contains(document, []byte("blurry")):
+		return entity.KYCDecision{Status: entity.KYCReview, Reference: "chk_3", Reason: "photo unreadable"}, nil
+	case bytes.Contains(document, []byte("timeout")):
+		return entity.KYCDecision{}, errors.New("provider timed out")
+	}
+	return entity.KYCDecision{Status: entity.KYCVerified, Reference: "chk_1"}, nil
+}
+
+// pngDocument returns a PNG header followed by content, which is enough
+// for content type detection
+func pngDocument(content string) []byte {
+	return append([]byte("\x89PNG\r\n\x1a\n"), content...)
+}
+
+func newTestKYCUseCase(t *testing.T, provider repository.KYCProvider) (*KYCUseCase, *UserUseCase, *MockBlobStore) {
+	t.Helper()
+	users := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	store := &MockBlobStore{objects: make(map[string][]byte)}
+	return NewKYCUseCase(&MockKYCRepository{}, store, provider, users), users, store
+}
+
+func TestKYCUseCase_Submit(t *testing.T) {
+	useCase, users, store := newTestKYCUseCase(t, nil)
+	user, err := users.RegisterUser("kyc@example.com", "password123", "KYC User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tt := range map[string]struct {
+		documentType string
+		document     []byte
+	}{
+		"unknown type": {"library_card", pngDocument("scan")},
+		"empty":        {entity.KYCDocumentPassport, nil},
+		"too large":    {entity.KYCDocumentPassport, pngDocument(strings.Repeat("x", MaxKYCDocumentBytes))},
+		"not an image": {entity.KYCDocumentPassport, []byte("plain text")},
+	} {
+		if _, err := useCase.Submit(user.ID, tt.documentType, tt.document); !errors.Is(err, ErrInvalidKYCDocument) {
+			t.Errorf("Submit() with a document that is %s error = %v, want ErrInvalidKYCDocument", name, err)
+		}
+	}
+
+	submission, err := useCase.Submit(user.ID, entity.KYCDocumentPassport, pngDocument("scan"))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	// Without a provider every submission waits for an admin
+	if submission.Status != entity.KYCReview || submission.ContentType != "image/png" || !strings.HasPrefix(submission.DocumentKey, "kyc/1/") {
+		t.Errorf("Submit() = %+v", submission)
+	}
+	if stored := store.objects[submission.DocumentKey]; !bytes.Equal(stored, pngDocument("scan")) {
+		t.Errorf("stored document = %q", stored)
+	}
+	if found, _ := users.GetUserByID(user.ID); found.KYCStatus != entity.KYCReview {
+		t.Errorf("KYCStatus = %q, want review", found.KYCStatus)
+	}
+
+	if _, err := useCase.Submit(user.ID, entity.KYCDocumentPassport, pngDocument("again")); !errors.Is(err, ErrKYCInProgress) {
+		t.Errorf("Submit() while in review error = %v, want ErrKYCInProgress", err)
+	}
+	if _, err := useCase.Submit(999, entity.KYCDocumentPassport, pngDocument("scan")); err == nil {
+		t.Error("Submit() for an unknown user should fail")
+	}
+}
+
+func TestKYCUseCase_VerifyPending(t *testing.T) {
+	provider := &MockKYCProvider{}
+	useCase, users, _ := newTestKYCUseCase(t, provider)
+
+	var ids []int
+	for _, content := range []string{"genuine", "forged", "blurry", "timeout"} {
+		user, err := users.RegisterUser(content+"@example.com", "password123", "KYC User", "0812345678", "1990-01-15")
+		if err != nil {
+			t.Fatal(err)
+		}
+		submission, err := useCase.Submit(user.ID, entity.KYCDocumentNationalID, pngDocument(content))
+		if err != nil || submission.Status != entity.KYCPending {
+			t.Fatalf("Submit() = %+v, %v; want pending", submission, err)
+		}
+		ids = append(ids, user.ID)
+	}
+
+	decided, err := useCase.VerifyPending()
+	if err != nil || decided != 3 {
+		t.Fatalf("VerifyPending() = %d, %v; want 3", decided, err)
+	}
+	for i, want := range []string{entity.KYCVerified, entity.KYCRejected, entity.KYCReview, entity.KYCPending} {
+		submission, _ := useCase.Status(ids[i])
+		user, _ := users.GetUserByID(ids[i])
+		if submission.Status != want || user.KYCStatus != want {
+			t.Errorf("user %d status = %q on the submission and %q on the user, want %q", ids[i], submission.Status, user.KYCStatus, want)
+		}
+	}
+	if rejected, _ := useCase.Status(ids[1]); rejected.Reason != "document was altered" || rejected.ReviewedBy != 0 || rejected.ReviewedAt == nil {
+		t.Errorf("rejected submission = %+v", rejected)
+	}
+
+	// The submission the provider failed on is tried again
+	if _, err := useCase.VerifyPending(); err != nil || provider.calls != 5 {
+		t.Errorf("second VerifyPending() = %v after %d calls, want 5", err, provider.calls)
+	}
+
+	// Verified users cannot submit again; rejected ones can
+	if _, err := useCase.Submit(ids[0], entity.KYCDocumentPassport, pngDocument("genuine")); !errors.Is(err, ErrKYCAlreadyVerified) {
+		t.Errorf("Submit() when verified error = %v, want ErrKYCAlreadyVerified", err)
+	}
+	if _, err := useCase.Submit(ids[1], entity.KYCDocumentPassport, pngDocument("genuine")); err != nil {
+		t.Errorf("Submit() after a rejection error = %v", err)
+	}
+}
+
+func TestKYCUseCase_Review(t *testing.T) {
+	useCase, users, _ := newTestKYCUseCase(t, nil)
+	auditRepo := &MockAuditRepository{}
+	useCase.SetAuditLog(auditRepo)
+	user, err := users.RegisterUser("review@example.com", "password123", "KYC User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	submission, err := useCase.Submit(user.ID, entity.KYCDocumentDrivingLicense, pngDocument("scan"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	review, err := useCase.List(entity.KYCReview, 10)
+	if err != nil || len(review) != 1 || review[0].User == nil || review[0].User.Email != "review@example.com" {
+		t.Fatalf("List() = %+v, %v", review, err)
+	}
+	found, document, err := useCase.Document(submission.ID)
+	if err != nil || found.ID != submission.ID || !bytes.Equal(document, pngDocument("scan")) {
+		t.Errorf("Document() = %+v, %q, %v", found, document, err)
+	}
+
+	if _, err := useCase.Reject(submission.ID, 7, ""); !errors.Is(err, ErrKYCReasonRequired) {
+		t.Errorf("Reject() without a reason error = %v, want ErrKYCReasonRequired", err)
+	}
+	rejected, err := useCase.Reject(submission.ID, 7, "photo is cut off")
+	if err != nil || rejected.Status != entity.KYCRejected || rejected.ReviewedBy != 7 {
+		t.Fatalf("Reject() = %+v, %v", rejected, err)
+	}
+	if _, err := useCase.Approve(submission.ID, 7); !errors.Is(err, ErrKYCSubmissionDecided) {
+		t.Errorf("Approve() of a rejected submission error = %v, want ErrKYCSubmissionDecided", err)
+	}
+	if len(auditRepo.entries) != 1 || auditRepo.entries[0].Action != entity.AuditKYCReviewed || auditRepo.entries[0].SubjectID != "1" {
+		t.Errorf("audit entries = %+v", auditRepo.entries)
+	}
+
+	resubmitted, err := useCase.Submit(user.ID, entity.KYCDocumentDrivingLicense, pngDocument("rescan"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	approved, err := useCase.Approve(resubmitted.ID, 7)
+	if err != nil || approved.Status != entity.KYCVerified || approved.User == nil || approved.User.KYCStatus != entity.KYCVerified {
+		t.Errorf("Approve() = %+v, %v", approved, err)
+	}
+	if _, err := useCase.Approve(999, 7); !errors.Is(err, ErrKYCSubmissionNotFound) {
+		t.Errorf("Approve() of a missing submission error = %v, want ErrKYCSubmissionNotFound", err)
+	}
+}
+
+func TestKYCUseCase_Submit_CreateFails(t *testing.T) {
+	useCase, users, store := newTestKYCUseCase(t, nil)
+	user, err := users.RegisterUser("kyc@example.com", "password123", "KYC User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A concurrent submission won, or the submission could not be saved;
+	// either way the document is deleted again
+	kycRepo := useCase.kycRepo.(*MockKYCRepository)
+	for _, createErr := range []error{repository.ErrKYCInProgress, errors.New("database is locked")} {
+		kycRepo.createErr = createErr
+		if _, err := useCase.Submit(user.ID, entity.KYCDocumentPassport, pngDocument("scan")); !errors.Is(err, createErr) {
+			t.Errorf("Submit() error = %v, want %v", err, createErr)
+		}
+		if len(store.objects) != 0 {
+			t.Errorf("stored documents after a failed Submit() = %d, want 0", len(store.objects))
+		}
+	}
+	if found, _ := users.GetUserByID(user.ID); found.KYCStatus != "" {
+		t.Errorf("KYCStatus = %q, want none", found.KYCStatus)
+	}
+}
+
+// reviewingKYCProvider has an admin reject each submission while it is
+// being verified
+type reviewingKYCProvider struct {
+	useCase *KYCUseCase
+}
+
+func (p *reviewingKYCProvider) Verify(submission *entity.KYCSubmission, document []byte) (entity.KYCDecision, error) {
+	if _, err := p.useCase.Reject(submission.ID, 7, "face does not match"); err != nil {
+		return entity.KYCDecision{}, err
+	}
+	return entity.KYCDecision{Status: entity.KYCVerified, Reference: "chk_1"}, nil
+}
+
+func TestKYCUseCase_VerifyPending_AdminDecidedMeanwhile(t *testing.T) {
+	provider := &reviewingKYCProvider{}
+	useCase, users, _ := newTestKYCUseCase(t, provider)
+	provider.useCase = useCase
+	user, err := users.RegisterUser("kyc@example.com", "password123", "KYC User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := useCase.Submit(user.ID, entity.KYCDocumentPassport, pngDocument("scan")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The provider's verdict comes after the admin's and is dropped
+	if decided, err := useCase.VerifyPending(); err != nil || decided != 0 {
+		t.Errorf("VerifyPending() = %d, %v; want 0", decided, err)
+	}
+	submission, _ := useCase.Status(user.ID)
+	found, _ := users.GetUserByID(user.ID)
+	if submission.Status != entity.KYCRejected || submission.ReviewedBy != 7 || found.KYCStatus != entity.KYCRejected {
+		t.Errorf("submission = %+v with user status %q, want the admin's rejection", submission, found.KYCStatus)
+	}
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/pkg/hooks"
+	"fiber-hello-world/pkg/textnorm"
+)
+
+// ErrInvalidNotice is returned when a notice template cannot be parsed or executed
+var ErrInvalidNotice = errors.New("invalid notice template")
+
+// NoticeData is what notice templates can refer to, e.g. {{.FullName}}
+type NoticeData struct {
+	FullName  string
+	Email     string
+	Token     string
+	ExpiresAt time.Time
+}
+
+// Notice is a notification delivered with a forced password reset. Its
+// subject and message are text/template templates executed with NoticeData.
+type Notice struct {
+	subject *template.Template
+	message *template.Template
+}
+
+// NewNotice parses the subject and message templates. Returns
+// ErrInvalidNotice for a template that does not parse or refers to
+// anything but NoticeData.
+func NewNotice(subject, message string) (*Notice, error) {
+	notice := &Notice{}
+	var err error
+	if notice.subject, err = template.New("subject").Parse(subject); err != nil {
+		return nil, fmt.Errorf("%w: subject: %v", ErrInvalidNotice, err)
+	}
+	if notice.message, err = template.New("message").Parse(message); err != nil {
+		return nil, fmt.Errorf("%w: message: %v", ErrInvalidNotice, err)
+	}
+
+	// Catch unknown fields now rather than for every user
+	if _, _, err := notice.render(NoticeData{}); err != nil {
+		return nil, err
+	}
+	return notice, nil
+}
+
+// render executes the templates with data
+func (n *Notice) render(data NoticeData) (string, string, error) {
+	var subject, message bytes.Buffer
+	if err := n.subject.Execute(&subject, data); err != nil {
+		return "", "", fmt.Errorf("%w: subject: %v", ErrInvalidNotice, err)
+	}
+	if err := n.message.Execute(&message, data); err != nil {
+		return "", "", fmt.Errorf("%w: message: %v", ErrInvalidNotice, err)
+	}
+	return subject.String(), message.String(), nil
+}
+
+// PasswordResetUseCase lets users who forgot their password set a new one
+// with a single-use token delivered by the password-reset hooks
+type PasswordResetUseCase struct {
+	userUseCase  *UserUseCase
+	tokenUseCase *TokenUseCase
+	ttl          time.Duration
+}
+
+// NewPasswordResetUseCase creates a new password reset use case issuing tokens valid for ttl
+func NewPasswordResetUseCase(userUseCase *UserUseCase, tokenUseCase *TokenUseCase, ttl time.Duration) *PasswordResetUseCase {
+	return &PasswordResetUseCase{
+		userUseCase:  userUseCase,
+		tokenUseCase: tokenUseCase,
+		ttl:          ttl,
+	}
+}
+
+// RequestReset issues a reset token for the user with email and hands it to
+// the password-reset hooks for delivery. Unknown emails are ignored without
+// an error, so callers cannot tell which emails have accounts.
+func (uc *PasswordResetUseCase) RequestReset(email string) error {
+	defer uc.userUseCase.responseFloor.Pad(uc.userUseCase.responseFloor.Now())
+	user, err := uc.userUseCase.userRepo.GetByEmail(textnorm.Identifier(email))
+	if err != nil {
+		return nil
+	}
+
+	token, record, err := uc.tokenUseCase.Issue(entity.TokenPurposePasswordReset, user.ID, uc.ttl)
+	if err != nil {
+		return err
+	}
+
+	// Delivery failures are logged by the registry
+	return uc.userUseCase.hooks.Run(&hooks.Event{
+		Point:  hooks.PasswordReset,
+		UserID: user.ID,
+		Email:  user.Email,
+		Data: map[string]interface{}{
+			"token":     token,
+			"expiresAt": record.ExpiresAt.Format(time.RFC3339),
+		},
+	})
+}
+
+// ResetPassword consumes a reset token and sets the user's new password.
+// Returns ErrInvalidToken, ErrTokenAlreadyUsed or ErrTokenExpired for a
+// token that cannot be used.
+func (uc *PasswordResetUseCase) ResetPassword(token, newPassword string) error {
+	record, err := uc.tokenUseCase.Consume(entity.TokenPurposePasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.userUseCase.SetPassword(record.UserID, newPassword); err != nil {
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+	return nil
+}
+
+// ForceReset replaces the user's password with a random one, so it can no
+// longer be used to sign in, and delivers a reset token with the rendered
+// notice through the password-reset hooks. The hook event carries the
+// token, expiresAt, subject and message, and reason "forced".
+func (uc *PasswordResetUseCase) ForceReset(userID int, notice *Notice) error {
+	user, err := uc.userUseCase.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	if err := uc.userUseCase.SetPassword(user.ID, base64.RawURLEncoding.EncodeToString(b)); err != nil {
+		return err
+	}
+
+	token, record, err := uc.tokenUseCase.Issue(entity.TokenPurposePasswordReset, user.ID, uc.ttl)
+	if err != nil {
+		return err
+	}
+	subject, message, err := notice.render(NoticeData{
+		FullName:  user.FullName,
+		Email:     user.Email,
+		Token:     token,
+		ExpiresAt: record.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Delivery failures are logged by the registry
+	return uc.userUseCase.hooks.Run(&hooks.Event{
+		Point:  hooks.PasswordReset,
+		UserID: user.ID,
+		Email:  user.Email,
+		Data: map[string]interface{}{
+			"token":     token,
+			"expiresAt": record.ExpiresAt.Format(time.RFC3339),
+			"reason":    "forced",
+			"subject":   subject,
+			"message":   message,
+		},
+	})
+}
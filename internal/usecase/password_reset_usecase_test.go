@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/pkg/hooks"
+)
+
+func TestPasswordResetUseCase(t *testing.T) {
+	userUseCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	if _, err := userUseCase.RegisterUser("reset@example.com", "password123", "Reset User", "0812345678", "1990-01-15"); err != nil {
+		t.Fatal(err)
+	}
+
+	var delivered []*hooks.Event
+	registry := hooks.NewRegistry()
+	registry.Register(hooks.PasswordReset, hooks.HookFunc(func(e *hooks.Event) error {
+		delivered = append(delivered, e)
+		return nil
+	}))
+	userUseCase.SetHooks(registry)
+	useCase := NewPasswordResetUseCase(userUseCase, NewTokenUseCase(&MockOneTimeTokenRepository{}), 30*time.Minute)
+
+	// Unknown emails look the same to the caller but deliver nothing
+	if err := useCase.RequestReset("nobody@example.com"); err != nil || len(delivered) != 0 {
+		t.Fatalf("RequestReset() for unknown email = %v, delivered %d", err, len(delivered))
+	}
+
+	if err := useCase.RequestReset("reset@example.com"); err != nil {
+		t.Fatalf("RequestReset() error = %v", err)
+	}
+	if len(delivered) != 1 || delivered[0].Email != "reset@example.com" || delivered[0].Data["expiresAt"] == "" {
+		t.Fatalf("delivered = %+v, want one event for the user", delivered)
+	}
+	token, _ := delivered[0].Data["token"].(string)
+
+	if err := useCase.ResetPassword("not-a-token", "newpassword"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ResetPassword() with unknown token error = %v, want ErrInvalidToken", err)
+	}
+	if err := useCase.ResetPassword(token, "newpassword"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	if _, err := userUseCase.AuthenticateUser("reset@example.com", "newpassword"); err != nil {
+		t.Errorf("AuthenticateUser() with the new password error = %v", err)
+	}
+
+	// A replayed token is rejected
+	if err := useCase.ResetPassword(token, "attackerpassword"); !errors.Is(err, ErrTokenAlreadyUsed) {
+		t.Errorf("replayed ResetPassword() error = %v, want ErrTokenAlreadyUsed", err)
+	}
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"log"
+	"slices"
+	"strconv"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/pkg/inbound"
+)
+
+// StripeSubscriptionEvents are the Stripe event types that change a plan
+var StripeSubscriptionEvents = []string{
+	"customer.subscription.created",
+	"customer.subscription.updated",
+	"customer.subscription.deleted",
+}
+
+// entitledStatuses are the Stripe subscription statuses that keep the paid
+// plan. A past due subscription keeps it while Stripe retries the payment.
+var entitledStatuses = []string{"active", "trialing", "past_due"}
+
+// PlanUseCase changes users' plans, from the billing provider's
+// subscription events or on behalf of admins
+type PlanUseCase struct {
+	userUseCase *UserUseCase
+	// prices maps Stripe price IDs to the plans they buy
+	prices map[string]string
+}
+
+// NewPlanUseCase creates a new plan use case. prices maps Stripe price IDs
+// to plan names, e.g. "price_1Nxyz" => "pro".
+func NewPlanUseCase(userUseCase *UserUseCase, prices map[string]string) *PlanUseCase {
+	return &PlanUseCase{userUseCase: userUseCase, prices: prices}
+}
+
+// Plans returns the free plan followed by the paid plans, sorted
+func (uc *PlanUseCase) Plans() []string {
+	var paid []string
+	for _, plan := range uc.prices {
+		if entity.IsPaidPlan(plan) && !slices.Contains(paid, plan) {
+			paid = append(paid, plan)
+		}
+	}
+	slices.Sort(paid)
+	return append([]string{entity.PlanFree}, paid...)
+}
+
+// SetPlan moves a user to plan on behalf of actorID. Returns ErrInvalidPlan
+// for a plan that is not configured.
+func (uc *PlanUseCase) SetPlan(actorID, userID int, plan string) (*entity.User, error) {
+	if !slices.Contains(uc.Plans(), plan) {
+		return nil, ErrInvalidPlan
+	}
+	return uc.userUseCase.SetUserPlan(actorID, userID, plan, "admin")
+}
+
+// ApplySubscription updates the plan of the user a Stripe subscription
+// event is about. The user is named by the subscription's user_id metadata,
+// set when the checkout session is created. An entitled subscription moves
+// the user to the plan of its first configured price; any other status, or
+// a deleted subscription, moves them back to the free plan. Events that do
+// not name a known user or price are ignored, since retrying them would not
+// help.
+func (uc *PlanUseCase) ApplySubscription(event *inbound.Event) error {
+	metadata, _ := event.Data["metadata"].(map[string]interface{})
+	userIDText, _ := metadata["user_id"].(string)
+	userID, err := strconv.Atoi(userIDText)
+	if err != nil || userID <= 0 {
+		log.Printf("Ignoring Stripe event %s: subscription %s has no user_id metadata", event.ID, event.Subject)
+		return nil
+	}
+	if _, err := uc.userUseCase.GetUserByID(userID); err != nil {
+		log.Printf("Ignoring Stripe event %s: user %d not found", event.ID, userID)
+		return nil
+	}
+
+	plan := entity.PlanFree
+	status, _ := event.Data["status"].(string)
+	if event.Type != "customer.subscription.deleted" && slices.Contains(entitledStatuses, status) {
+		var ok bool
+		if plan, ok = uc.subscriptionPlan(event.Data); !ok {
+			log.Printf("Ignoring Stripe event %s: subscription %s has no price in PLAN_PRICES", event.ID, event.Subject)
+			return nil
+		}
+	}
+
+	_, err = uc.userUseCase.SetUserPlan(0, userID, plan, event.Provider)
+	return err
+}
+
+// subscriptionPlan returns the plan of the first subscription item whose
+// price is configured
+func (uc *PlanUseCase) subscriptionPlan(subscription map[string]interface{}) (string, bool) {
+	items, _ := subscription["items"].(map[string]interface{})
+	data, _ := items["data"].([]interface{})
+	for _, item := range data {
+		item, _ := item.(map[string]interface{})
+		price, _ := item["price"].(map[string]interface{})
+		priceID, _ := price["id"].(string)
+		if plan, ok := uc.prices[priceID]; ok {
+			return plan, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/pkg/inbound"
+)
+
+// stripeSubscription returns a Stripe subscription event for userID with
+// the given status and price
+func stripeSubscription(eventType string, userID int, status, priceID string) *inbound.Event {
+	return &inbound.Event{Provider: "stripe", ID: "evt_1", Type: eventType, Subject: "sub_1", Data: map[string]interface{}{
+		"id":       "sub_1",
+		"status":   status,
+		"metadata": map[string]interface{}{"user_id": strconv.Itoa(userID)},
+		"items": map[string]interface{}{"data": []interface{}{
+			map[string]interface{}{"price": map[string]interface{}{"id": priceID}},
+		}},
+	}}
+}
+
+func TestPlanUseCase_Plans(t *testing.T) {
+	useCase := NewPlanUseCase(nil, map[string]string{"price_team": "team", "price_pro": "pro", "price_pro_yearly": "pro"})
+
+	if got, want := useCase.Plans(), []string{"free", "pro", "team"}; !slices.Equal(got, want) {
+		t.Errorf("Plans() = %v, want %v", got, want)
+	}
+}
+
+func TestPlanUseCase_SetPlan(t *testing.T) {
+	events := &MockEventRepository{}
+	userUseCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	userUseCase.SetEventLog(events)
+	user, err := userUseCase.RegisterUser("lee@example.com", "password123", "Lee Park", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	useCase := NewPlanUseCase(userUseCase, map[string]string{"price_pro": "pro"})
+	events.events = nil
+
+	if _, err := useCase.SetPlan(1, user.ID, "enterprise"); !errors.Is(err, ErrInvalidPlan) {
+		t.Errorf("SetPlan(enterprise) error = %v, want ErrInvalidPlan", err)
+	}
+	updated, err := useCase.SetPlan(1, user.ID, "pro")
+	if err != nil || updated.Plan != "pro" {
+		t.Fatalf("SetPlan(pro) = %+v, %v", updated, err)
+	}
+	if _, err := useCase.SetPlan(1, user.ID, "pro"); err != nil {
+		t.Fatalf("SetPlan(pro) again error = %v", err)
+	}
+
+	if len(events.events) != 1 || events.events[0].Type != entity.EventUserPlanChanged {
+		t.Fatalf("events = %+v, want one user.plan_changed", events.events)
+	}
+	data := events.events[0].Data
+	if data["plan"] != "pro" || data["from"] != entity.PlanFree || data["source"] != "admin" || events.events[0].ActorID != 1 {
+		t.Errorf("event = %+v, want pro from free by admin 1", events.events[0])
+	}
+}
+
+func TestPlanUseCase_ApplySubscription(t *testing.T) {
+	userUseCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	user, err := userUseCase.RegisterUser("lee@example.com", "password123", "Lee Park", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	useCase := NewPlanUseCase(userUseCase, map[string]string{"price_pro": "pro", "price_team": "team"})
+
+	tests := []struct {
+		name  string
+		event *inbound.Event
+		want  string
+	}{
+		{"subscribed", stripeSubscription("customer.subscription.created", user.ID, "active", "price_pro"), "pro"},
+		{"unknown price is ignored", stripeSubscription("customer.subscription.updated", user.ID, "active", "price_other"), "pro"},
+		{"upgraded", stripeSubscription("customer.subscription.updated", user.ID, "trialing", "price_team"), "team"},
+		{"past due keeps the plan", stripeSubscription("customer.subscription.updated", user.ID, "past_due", "price_team"), "team"},
+		{"unpaid", stripeSubscription("customer.subscription.updated", user.ID, "unpaid", "price_team"), entity.PlanFree},
+		{"resubscribed", stripeSubscription("customer.subscription.updated", user.ID, "active", "price_pro"), "pro"},
+		{"unknown user is ignored", stripeSubscription("customer.subscription.deleted", 999, "canceled", "price_pro"), "pro"},
+		{"canceled", stripeSubscription("customer.subscription.deleted", user.ID, "canceled", "price_pro"), entity.PlanFree},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := useCase.ApplySubscription(tt.event); err != nil {
+				t.Fatalf("ApplySubscription() error = %v", err)
+			}
+			found, err := userUseCase.GetUserByID(user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found.Plan != tt.want {
+				t.Errorf("Plan = %v, want %v", found.Plan, tt.want)
+			}
+		})
+	}
+
+	noMetadata := stripeSubscription("customer.subscription.created", user.ID, "active", "price_team")
+	delete(noMetadata.Data, "metadata")
+	if err := useCase.ApplySubscription(noMetadata); err != nil {
+		t.Errorf("ApplySubscription() without metadata error = %v, want it ignored", err)
+	}
+}
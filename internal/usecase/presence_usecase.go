@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// MaxPresenceUsers is how many users' presence can be read at once
+const MaxPresenceUsers = 100
+
+var (
+	// ErrInvalidPresenceVisibility is returned for a visibility other than
+	// everyone, status or nobody
+	ErrInvalidPresenceVisibility = errors.New("invalid presence visibility")
+	// ErrTooManyPresenceUsers is returned when reading the presence of more
+	// than MaxPresenceUsers users
+	ErrTooManyPresenceUsers = errors.New("too many users requested")
+)
+
+// PresenceUseCase tracks when users were last seen and shows whether they
+// are online, recently active or away. Activity is buffered in memory and
+// written by Flush, so requests do not write to the database.
+type PresenceUseCase struct {
+	presenceRepo repository.PresenceRepository
+	online       time.Duration
+	recent       time.Duration
+
+	mu sync.Mutex
+	// pending are the last seen times not flushed yet
+	pending map[int]time.Time
+	now     func() time.Time
+}
+
+// NewPresenceUseCase creates a new presence use case showing users online
+// when seen within online and recently active when seen within recent
+func NewPresenceUseCase(presenceRepo repository.PresenceRepository, online, recent time.Duration) *PresenceUseCase {
+	return &PresenceUseCase{
+		presenceRepo: presenceRepo,
+		online:       online,
+		recent:       recent,
+		pending:      make(map[int]time.Time),
+		now:          time.Now,
+	}
+}
+
+// Seen records that a user is active now. It is only buffered until the
+// next Flush.
+func (uc *PresenceUseCase) Seen(userID int) {
+	now := uc.now()
+	uc.mu.Lock()
+	uc.pending[userID] = now
+	uc.mu.Unlock()
+}
+
+// Flush writes the buffered last seen times in one batch, returning how
+// many users were written. On failure they are kept for the next flush.
+func (uc *PresenceUseCase) Flush() (int, error) {
+	uc.mu.Lock()
+	pending := uc.pending
+	uc.pending = make(map[int]time.Time)
+	uc.mu.Unlock()
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	if err := uc.presenceRepo.Touch(pending); err != nil {
+		uc.mu.Lock()
+		for userID, seenAt := range pending {
+			if seenAt.After(uc.pending[userID]) {
+				uc.pending[userID] = seenAt
+			}
+		}
+		uc.mu.Unlock()
+		return 0, errors.New("failed to save last seen times")
+	}
+	return len(pending), nil
+}
+
+// Get returns the presence of users as viewerID may see it, in the order
+// asked and without duplicates. Users never seen, including unknown ones,
+// are away. Activity buffered on this node counts, while other nodes'
+// shows once they flush it.
+func (uc *PresenceUseCase) Get(viewerID int, userIDs []int) ([]*entity.PresenceView, error) {
+	var unique []int
+	for _, userID := range userIDs {
+		if !slices.Contains(unique, userID) {
+			unique = append(unique, userID)
+		}
+	}
+	if len(unique) > MaxPresenceUsers {
+		return nil, ErrTooManyPresenceUsers
+	}
+
+	stored, err := uc.presenceRepo.Get(unique)
+	if err != nil {
+		return nil, errors.New("failed to get presence")
+	}
+	presences := make(map[int]*entity.Presence, len(stored))
+	for _, presence := range stored {
+		presences[presence.UserID] = presence
+	}
+
+	now := uc.now()
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	views := make([]*entity.PresenceView, 0, len(unique))
+	for _, userID := range unique {
+		presence, ok := presences[userID]
+		if !ok {
+			presence = &entity.Presence{UserID: userID, Visibility: entity.PresenceVisibleEveryone}
+		}
+		if seenAt := uc.pending[userID]; seenAt.After(presence.LastSeenAt) {
+			presence.LastSeenAt = seenAt
+		}
+		views = append(views, presence.ViewBy(viewerID, now, uc.online, uc.recent))
+	}
+	return views, nil
+}
+
+// SetVisibility sets who may see a user's presence
+func (uc *PresenceUseCase) SetVisibility(userID int, visibility string) error {
+	if !slices.Contains(entity.PresenceVisibilities, entity.PresenceVisibility(visibility)) {
+		return ErrInvalidPresenceVisibility
+	}
+	if err := uc.presenceRepo.SetVisibility(userID, entity.PresenceVisibility(visibility)); err != nil {
+		return errors.New("failed to save presence visibility")
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+)
+
+// Mock presence repository for testing
+type MockPresenceRepository struct {
+	presences map[int]*entity.Presence
+	touches   int
+	fail      bool
+}
+
+func (m *MockPresenceRepository) Touch(lastSeen map[int]time.Time) error {
+	if m.fail {
+		return errors.New("database is locked")
+	}
+	m.touches++
+	for userID, seenAt := range lastSeen {
+		presence := m.presence(userID)
+		if seenAt.After(presence.LastSeenAt) {
+			presence.LastSeenAt = seenAt
+		}
+	}
+	return nil
+}
+
+func (m *MockPresenceRepository) Get(userIDs []int) ([]*entity.Presence, error) {
+	var found []*entity.Presence
+	for _, userID := range userIDs {
+		if presence, ok := m.presences[userID]; ok {
+			copied := *presence
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+func (m *MockPresenceRepository) SetVisibility(userID int, visibility entity.PresenceVisibility) error {
+	m.presence(userID).Visibility = visibility
+	return nil
+}
+
+func (m *MockPresenceRepository) presence(userID int) *entity.Presence {
+	if m.presences[userID] == nil {
+		m.presences[userID] = &entity.Presence{UserID: userID, Visibility: entity.PresenceVisibleEveryone}
+	}
+	return m.presences[userID]
+}
+
+func TestPresenceUseCase_Flush(t *testing.T) {
+	repo := &MockPresenceRepository{presences: make(map[int]*entity.Presence)}
+	useCase := NewPresenceUseCase(repo, 2*time.Minute, 15*time.Minute)
+	now := time.Date(2025, 6, 3, 8, 0, 0, 0, time.UTC)
+	useCase.now = func() time.Time { return now }
+
+	useCase.Seen(1)
+	useCase.Seen(2)
+	useCase.Seen(1)
+	if repo.touches != 0 {
+		t.Fatal("Seen() should only buffer")
+	}
+	if count, err := useCase.Flush(); err != nil || count != 2 || repo.touches != 1 {
+		t.Fatalf("Flush() = %d, %v after %d writes; want 2 users in one write", count, err, repo.touches)
+	}
+	if count, err := useCase.Flush(); err != nil || count != 0 || repo.touches != 1 {
+		t.Errorf("Flush() with nothing buffered = %d, %v after %d writes; want no write", count, err, repo.touches)
+	}
+
+	// Failed flushes keep the times for the next one
+	now = now.Add(time.Minute)
+	useCase.Seen(1)
+	repo.fail = true
+	if _, err := useCase.Flush(); err == nil {
+		t.Fatal("Flush() should fail")
+	}
+	repo.fail = false
+	if count, err := useCase.Flush(); err != nil || count != 1 || !repo.presences[1].LastSeenAt.Equal(now) {
+		t.Errorf("Flush() = %d, %v with user 1 seen at %s; want %s", count, err, repo.presences[1].LastSeenAt, now)
+	}
+}
+
+func TestPresenceUseCase_Get(t *testing.T) {
+	now := time.Date(2025, 6, 3, 8, 0, 0, 0, time.UTC)
+	repo := &MockPresenceRepository{presences: map[int]*entity.Presence{
+		1: {UserID: 1, LastSeenAt: now.Add(-time.Minute), Visibility: entity.PresenceVisibleEveryone},
+		2: {UserID: 2, LastSeenAt: now.Add(-10 * time.Minute), Visibility: entity.PresenceVisibleStatus},
+		3: {UserID: 3, LastSeenAt: now.Add(-time.Minute), Visibility: entity.PresenceVisibleNobody},
+		4: {UserID: 4, LastSeenAt: now.Add(-time.Hour), Visibility: entity.PresenceVisibleEveryone},
+	}}
+	useCase := NewPresenceUseCase(repo, 2*time.Minute, 15*time.Minute)
+	useCase.now = func() time.Time { return now }
+	// Buffered activity counts before it is flushed
+	useCase.Seen(5)
+
+	views, err := useCase.Get(1, []int{1, 2, 3, 4, 5, 6, 1})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	tests := []struct {
+		userID     int
+		status     entity.PresenceStatus
+		lastSeen   bool
+		visibility entity.PresenceVisibility
+	}{
+		{1, entity.PresenceOnline, true, entity.PresenceVisibleEveryone},
+		{2, entity.PresenceRecentlyActive, false, ""},
+		{3, entity.PresenceAway, false, ""},
+		{4, entity.PresenceAway, true, ""},
+		{5, entity.PresenceOnline, true, ""},
+		{6, entity.PresenceAway, false, ""},
+	}
+	if len(views) != len(tests) {
+		t.Fatalf("Get() returned %d users, want %d", len(views), len(tests))
+	}
+	for i, tt := range tests {
+		view := views[i]
+		if view.UserID != tt.userID || view.Status != tt.status || (view.LastSeenAt != nil) != tt.lastSeen || view.Visibility != tt.visibility {
+			t.Errorf("Get()[%d] = %+v, want user %d %s with last seen %t and visibility %q", i, view, tt.userID, tt.status, tt.lastSeen, tt.visibility)
+		}
+	}
+
+	// Users see their own presence, whatever its visibility
+	if views, _ := useCase.Get(3, []int{3}); views[0].Status != entity.PresenceOnline || views[0].LastSeenAt == nil || views[0].Visibility != entity.PresenceVisibleNobody {
+		t.Errorf("Get() of self = %+v, want online with the last seen time", views[0])
+	}
+
+	ids := make([]int, MaxPresenceUsers+1)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	if _, err := useCase.Get(1, ids); !errors.Is(err, ErrTooManyPresenceUsers) {
+		t.Errorf("Get() of %d users error = %v, want ErrTooManyPresenceUsers", len(ids), err)
+	}
+}
+
+func TestPresenceUseCase_SetVisibility(t *testing.T) {
+	repo := &MockPresenceRepository{presences: make(map[int]*entity.Presence)}
+	useCase := NewPresenceUseCase(repo, 2*time.Minute, 15*time.Minute)
+
+	if err := useCase.SetVisibility(1, "friends"); !errors.Is(err, ErrInvalidPresenceVisibility) {
+		t.Errorf("SetVisibility(friends) error = %v, want ErrInvalidPresenceVisibility", err)
+	}
+	if err := useCase.SetVisibility(1, "nobody"); err != nil || repo.presences[1].Visibility != entity.PresenceVisibleNobody {
+		t.Errorf("SetVisibility(nobody) error = %v, want it saved", err)
+	}
+}
@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"unicode/utf8"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/textnorm"
+)
+
+// DirectoryActorID is the actor recorded in user history for changes pushed by
+// an identity provider rather than made by a user
+const DirectoryActorID = 0
+
+// ErrInvalidDirectoryUser is returned when provisioned user data fails validation
+var ErrInvalidDirectoryUser = errors.New("invalid directory user")
+
+// DirectoryUser is a user as provisioned by an external identity provider
+type DirectoryUser struct {
+	Email       string
+	FullName    string
+	PhoneNumber string
+	// Password is optional; without one the account can only sign in after a password reset
+	Password string
+	Active   bool
+}
+
+// DirectoryUserChanges lists the attributes an identity provider changed; nil means unchanged
+type DirectoryUserChanges struct {
+	Email       *string
+	FullName    *string
+	PhoneNumber *string
+	Active      *bool
+}
+
+// DirectoryFilter narrows directory user lookups; zero values match everything
+type DirectoryFilter struct {
+	Email  string
+	Active *bool
+}
+
+// ProvisioningUseCase handles users created and managed by identity providers
+type ProvisioningUseCase struct {
+	userRepo    repository.UserRepository
+	userUseCase *UserUseCase
+}
+
+// NewProvisioningUseCase creates a new provisioning use case
+func NewProvisioningUseCase(userRepo repository.UserRepository, userUseCase *UserUseCase) *ProvisioningUseCase {
+	return &ProvisioningUseCase{
+		userRepo:    userRepo,
+		userUseCase: userUseCase,
+	}
+}
+
+// ProvisionUser creates a user pushed by an identity provider
+func (uc *ProvisioningUseCase) ProvisionUser(input DirectoryUser) (*entity.User, error) {
+	email := normalizeField(repository.FieldEmail, input.Email)
+	if err := validateDirectoryEmail(email); err != nil {
+		return nil, err
+	}
+	fullName := normalizeField(repository.FieldFullName, input.FullName)
+	if err := validateDirectoryName(fullName); err != nil {
+		return nil, err
+	}
+	phoneNumber := normalizeField(repository.FieldPhoneNumber, input.PhoneNumber)
+
+	password := input.Password
+	if password == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, errors.New("failed to generate password")
+		}
+		password = hex.EncodeToString(random)
+	}
+	hashedPassword, err := uc.userUseCase.hashPassword(password)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	// Identity providers do not send a birthday; users can add it later
+	user := entity.NewUser(email, string(hashedPassword), fullName, phoneNumber, "")
+	if !input.Active {
+		user.Status = entity.StatusPending
+	}
+
+	savedUser, err := uc.userRepo.Create(user)
+	if errors.Is(err, repository.ErrEmailTaken) {
+		return nil, ErrEmailTaken
+	}
+	if err != nil {
+		return nil, errors.New("failed to save user")
+	}
+
+	return savedUser.WithoutPassword(), nil
+}
+
+// GetUser retrieves a provisioned user by ID
+func (uc *ProvisioningUseCase) GetUser(id int) (*entity.User, error) {
+	return uc.userUseCase.GetUserByID(id)
+}
+
+// ListUsers returns a page of users matching the filter and the total number of matches
+func (uc *ProvisioningUseCase) ListUsers(filter DirectoryFilter, page repository.Page) ([]*entity.User, int, error) {
+	userFilter := repository.UserFilter{}
+	if filter.Active != nil {
+		userFilter.ExcludeStatus = entity.StatusActive
+		if *filter.Active {
+			userFilter.Status, userFilter.ExcludeStatus = entity.StatusActive, ""
+		}
+	}
+
+	// Lookups by email are the common case when an identity provider checks
+	// whether a user already exists
+	if filter.Email != "" {
+		email := textnorm.Identifier(filter.Email)
+		exists, err := uc.userRepo.ExistsByEmail(email)
+		if err != nil {
+			return nil, 0, errors.New("failed to list users")
+		}
+		if !exists {
+			return []*entity.User{}, 0, nil
+		}
+		user, err := uc.userRepo.GetByEmail(email)
+		if err != nil {
+			return nil, 0, errors.New("failed to list users")
+		}
+		if filter.Active != nil && user.CanSignIn() != *filter.Active {
+			return []*entity.User{}, 0, nil
+		}
+		if page.Normalize().Offset > 0 {
+			return []*entity.User{}, 1, nil
+		}
+		return []*entity.User{user.WithoutPassword()}, 1, nil
+	}
+
+	users, err := uc.userRepo.List(userFilter, page)
+	if err != nil {
+		return nil, 0, errors.New("failed to list users")
+	}
+	total, err := uc.userRepo.Count(userFilter)
+	if err != nil {
+		return nil, 0, errors.New("failed to list users")
+	}
+
+	for i, user := range users {
+		users[i] = user.WithoutPassword()
+	}
+	return users, total, nil
+}
+
+// UpdateUser applies attribute changes pushed by an identity provider.
+// Setting Active to false suspends an active account, which is how users
+// are deprovisioned; setting it to true activates the account.
+func (uc *ProvisioningUseCase) UpdateUser(id int, changes DirectoryUserChanges) (*entity.User, error) {
+	fields := make(map[string]interface{})
+	if changes.Email != nil {
+		email := normalizeField(repository.FieldEmail, *changes.Email)
+		if err := validateDirectoryEmail(email); err != nil {
+			return nil, err
+		}
+		fields[repository.FieldEmail] = email
+	}
+	if changes.FullName != nil {
+		fullName := normalizeField(repository.FieldFullName, *changes.FullName)
+		if err := validateDirectoryName(fullName); err != nil {
+			return nil, err
+		}
+		fields[repository.FieldFullName] = fullName
+	}
+	if changes.PhoneNumber != nil {
+		fields[repository.FieldPhoneNumber] = normalizeField(repository.FieldPhoneNumber, *changes.PhoneNumber)
+	}
+	if changes.Active != nil {
+		user, err := uc.userUseCase.GetUserByID(id)
+		if err != nil {
+			return nil, err
+		}
+		// Accounts that are already inactive stay as they are when deprovisioned
+		if *changes.Active && !user.CanSignIn() {
+			fields[repository.FieldStatus] = entity.StatusActive
+		} else if !*changes.Active && user.CanSignIn() {
+			fields[repository.FieldStatus] = entity.StatusSuspended
+		}
+	}
+
+	if len(fields) == 0 {
+		return uc.userUseCase.GetUserByID(id)
+	}
+	return uc.userUseCase.updateFields(DirectoryActorID, id, fields)
+}
+
+// validateDirectoryEmail checks that a provisioned user name is a plain email address
+func validateDirectoryEmail(email string) error {
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email || utf8.RuneCountInString(email) > entity.MaxEmailLength {
+		return fmt.Errorf("%w: userName must be an email address", ErrInvalidDirectoryUser)
+	}
+	return nil
+}
+
+// validateDirectoryName checks that a provisioned user has a name that fits
+func validateDirectoryName(fullName string) error {
+	if fullName == "" {
+		return fmt.Errorf("%w: a name is required", ErrInvalidDirectoryUser)
+	}
+	if utf8.RuneCountInString(fullName) > entity.MaxFullNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidDirectoryUser, entity.MaxFullNameLength)
+	}
+	return nil
+}
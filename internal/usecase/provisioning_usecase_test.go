@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func newProvisioningUseCase() (*ProvisioningUseCase, *MockUserRepository, *MockUserRevisionRepository) {
+	mockRepo := NewMockUserRepository()
+	mockRevisions := NewMockUserRevisionRepository()
+	return NewProvisioningUseCase(mockRepo, NewUserUseCase(mockRepo, mockRevisions)), mockRepo, mockRevisions
+}
+
+func TestProvisioningUseCase_ProvisionUser(t *testing.T) {
+	useCase, mockRepo, _ := newProvisioningUseCase()
+
+	user, err := useCase.ProvisionUser(DirectoryUser{Email: "jane@example.com", FullName: " Jane Doe ", Active: true})
+	if err != nil {
+		t.Fatalf("ProvisionUser() error = %v", err)
+	}
+	if user.ID == 0 || user.FullName != "Jane Doe" || user.Status != entity.StatusActive {
+		t.Errorf("ProvisionUser() = %+v", user)
+	}
+	if user.Password != "" {
+		t.Error("ProvisionUser() should not return the password")
+	}
+
+	// Without a password a random one is stored, so no guessable login exists
+	stored, _ := mockRepo.GetByEmail("jane@example.com")
+	if stored.Password == "" || bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("")) == nil {
+		t.Error("ProvisionUser() should store a random password hash")
+	}
+
+	inactive, err := useCase.ProvisionUser(DirectoryUser{Email: "inactive@example.com", FullName: "Inactive", Password: "password123"})
+	if err != nil {
+		t.Fatalf("ProvisionUser() error = %v", err)
+	}
+	if inactive.Status != entity.StatusPending {
+		t.Errorf("Status = %v, want %v", inactive.Status, entity.StatusPending)
+	}
+
+	if _, err := useCase.ProvisionUser(DirectoryUser{Email: "jane@example.com", FullName: "Jane Again", Active: true}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("ProvisionUser() duplicate error = %v, want ErrEmailTaken", err)
+	}
+}
+
+func TestProvisioningUseCase_ProvisionUser_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input DirectoryUser
+	}{
+		{"userName not an email", DirectoryUser{Email: "jdoe", FullName: "Jane Doe"}},
+		{"display name in userName", DirectoryUser{Email: "Jane <jane@example.com>", FullName: "Jane Doe"}},
+		{"missing name", DirectoryUser{Email: "jane@example.com", FullName: "  "}},
+		{"invisible name", DirectoryUser{Email: "jane@example.com", FullName: "\u200b\u200d"}},
+		{"name too long", DirectoryUser{Email: "jane@example.com", FullName: strings.Repeat("ก", entity.MaxFullNameLength+1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, _, _ := newProvisioningUseCase()
+			if _, err := useCase.ProvisionUser(tt.input); !errors.Is(err, ErrInvalidDirectoryUser) {
+				t.Errorf("ProvisionUser() error = %v, want ErrInvalidDirectoryUser", err)
+			}
+		})
+	}
+}
+
+func TestProvisioningUseCase_ListUsers_ByEmail(t *testing.T) {
+	useCase, _, _ := newProvisioningUseCase()
+	if _, err := useCase.ProvisionUser(DirectoryUser{Email: "jane@example.com", FullName: "Jane Doe", Active: true}); err != nil {
+		t.Fatalf("ProvisionUser() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		filter    DirectoryFilter
+		page      repository.Page
+		wantCount int
+		wantTotal int
+	}{
+		{"match", DirectoryFilter{Email: "jane@example.com"}, repository.Page{}, 1, 1},
+		{"no match", DirectoryFilter{Email: "john@example.com"}, repository.Page{}, 0, 0},
+		{"active matches", DirectoryFilter{Email: "jane@example.com", Active: boolPtr(true)}, repository.Page{}, 1, 1},
+		{"inactive excludes", DirectoryFilter{Email: "jane@example.com", Active: boolPtr(false)}, repository.Page{}, 0, 0},
+		{"past first page", DirectoryFilter{Email: "jane@example.com"}, repository.Page{Offset: 1}, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, total, err := useCase.ListUsers(tt.filter, tt.page)
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if len(users) != tt.wantCount || total != tt.wantTotal {
+				t.Errorf("ListUsers() = %d users, total %d; want %d, %d", len(users), total, tt.wantCount, tt.wantTotal)
+			}
+			for _, user := range users {
+				if user.Password != "" {
+					t.Error("ListUsers() should not return passwords")
+				}
+			}
+		})
+	}
+}
+
+func TestProvisioningUseCase_UpdateUser(t *testing.T) {
+	useCase, _, mockRevisions := newProvisioningUseCase()
+	user, err := useCase.ProvisionUser(DirectoryUser{Email: "jane@example.com", FullName: "Jane Doe", Active: true})
+	if err != nil {
+		t.Fatalf("ProvisionUser() error = %v", err)
+	}
+
+	updated, err := useCase.UpdateUser(user.ID, DirectoryUserChanges{FullName: strPtr("Jane Smith"), Active: boolPtr(false)})
+	if err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if updated.FullName != "Jane Smith" || updated.Status != entity.StatusSuspended {
+		t.Errorf("UpdateUser() = %+v", updated)
+	}
+
+	// Changes are recorded as made by the directory
+	history, _ := mockRevisions.ListByUser(user.ID, repository.Page{})
+	if len(history) != 1 || history[0].ActorID != DirectoryActorID {
+		t.Errorf("history = %+v, want one revision by the directory", history)
+	}
+
+	reactivated, err := useCase.UpdateUser(user.ID, DirectoryUserChanges{Active: boolPtr(true)})
+	if err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if reactivated.Status != entity.StatusActive {
+		t.Errorf("Status = %v, want %v", reactivated.Status, entity.StatusActive)
+	}
+
+	// No changes returns the user unchanged
+	unchanged, err := useCase.UpdateUser(user.ID, DirectoryUserChanges{})
+	if err != nil || unchanged.FullName != "Jane Smith" {
+		t.Errorf("UpdateUser() with no changes = %+v, %v", unchanged, err)
+	}
+
+	if _, err := useCase.UpdateUser(user.ID, DirectoryUserChanges{Email: strPtr("not-an-email")}); !errors.Is(err, ErrInvalidDirectoryUser) {
+		t.Errorf("UpdateUser() error = %v, want ErrInvalidDirectoryUser", err)
+	}
+	if _, err := useCase.UpdateUser(999, DirectoryUserChanges{Active: boolPtr(false)}); err == nil || err.Error() != "user not found" {
+		t.Errorf("UpdateUser() error = %v, want 'user not found'", err)
+	}
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ErrQRLoginNotFound is returned for an unknown QR challenge or poll token
+var ErrQRLoginNotFound = repository.ErrQRLoginNotFound
+
+// ErrQRLoginNotPending is returned when approving or denying a QR login that
+// was already decided
+var ErrQRLoginNotPending = repository.ErrQRLoginNotPending
+
+// ErrQRLoginExpired is returned when approving or denying an expired QR login
+var ErrQRLoginExpired = errors.New("QR login has expired")
+
+// qrLoginRetention is how long expired QR logins are kept, so desktops
+// polling late are told they expired rather than that they are unknown
+const qrLoginRetention = time.Hour
+
+// QRLoginUseCase signs desktops in when a signed-in device approves the QR
+// code they display
+type QRLoginUseCase struct {
+	qrLoginRepo repository.QRLoginRepository
+	users       *UserUseCase
+	ttl         time.Duration
+	now         func() time.Time
+}
+
+// NewQRLoginUseCase creates a new QR login use case whose codes are valid for ttl
+func NewQRLoginUseCase(qrLoginRepo repository.QRLoginRepository, users *UserUseCase, ttl time.Duration) *QRLoginUseCase {
+	return &QRLoginUseCase{
+		qrLoginRepo: qrLoginRepo,
+		users:       users,
+		ttl:         ttl,
+		now:         time.Now,
+	}
+}
+
+// Start creates a login for the desktop at ip with the given device
+// (User-Agent) and country. It returns the challenge to show as a QR code
+// and the token the desktop polls with; only their hashes are stored.
+func (uc *QRLoginUseCase) Start(ip, device, country string) (string, string, *entity.QRLogin, error) {
+	challenge, err := randomToken()
+	if err != nil {
+		return "", "", nil, err
+	}
+	pollToken, err := randomToken()
+	if err != nil {
+		return "", "", nil, err
+	}
+	if len(device) > maxDeviceLength {
+		device = device[:maxDeviceLength]
+	}
+
+	login := &entity.QRLogin{
+		ChallengeHash: hashToken(challenge),
+		PollHash:      hashToken(pollToken),
+		Status:        entity.QRLoginPending,
+		IP:            ip,
+		Device:        device,
+		Country:       country,
+		ExpiresAt:     uc.now().Add(uc.ttl).UTC(),
+	}
+	if err := uc.qrLoginRepo.Create(login); err != nil {
+		return "", "", nil, fmt.Errorf("failed to store QR login: %w", err)
+	}
+	return challenge, pollToken, login, nil
+}
+
+// Scan returns the pending login for a scanned challenge, so the user can
+// check the desktop before approving it
+func (uc *QRLoginUseCase) Scan(challenge string) (*entity.QRLogin, error) {
+	login, err := uc.qrLoginRepo.GetByChallenge(hashToken(challenge))
+	if err != nil {
+		return nil, err
+	}
+	switch login.StatusAt(uc.now()) {
+	case entity.QRLoginPending:
+		return login, nil
+	case entity.QRLoginExpired:
+		return nil, ErrQRLoginExpired
+	default:
+		return nil, ErrQRLoginNotPending
+	}
+}
+
+// Approve signs the desktop that shows challenge in as userID. Users who
+// cannot sign in, such as suspended ones, cannot approve logins.
+func (uc *QRLoginUseCase) Approve(userID int, challenge string) error {
+	user, err := uc.users.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := CheckCanSignIn(user); err != nil {
+		return err
+	}
+	return uc.decide(userID, challenge, entity.QRLoginApproved)
+}
+
+// Deny rejects the login of the desktop that shows challenge
+func (uc *QRLoginUseCase) Deny(userID int, challenge string) error {
+	return uc.decide(userID, challenge, entity.QRLoginDenied)
+}
+
+// decide approves or denies a pending login
+func (uc *QRLoginUseCase) decide(userID int, challenge string, status entity.QRLoginStatus) error {
+	login, err := uc.Scan(challenge)
+	if err != nil {
+		return err
+	}
+	return uc.qrLoginRepo.Decide(login.ID, status, userID, uc.now())
+}
+
+// Poll returns the status of the desktop's login. The first poll after
+// approval completes the login and also returns the approving user, who
+// the desktop is then issued a token for; later polls only see
+// QRLoginCompleted.
+func (uc *QRLoginUseCase) Poll(pollToken string) (entity.QRLoginStatus, *entity.User, error) {
+	login, err := uc.qrLoginRepo.GetByPoll(hashToken(pollToken))
+	if err != nil {
+		return "", nil, err
+	}
+	now := uc.now()
+	status := login.StatusAt(now)
+	if status != entity.QRLoginApproved {
+		return status, nil, nil
+	}
+
+	completed, err := uc.qrLoginRepo.Complete(login.ID, now)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to complete QR login: %w", err)
+	}
+	if !completed {
+		// Another poll got there first
+		return entity.QRLoginCompleted, nil, nil
+	}
+	user, err := uc.users.AuthenticateUserByID(login.UserID)
+	if err != nil {
+		return "", nil, err
+	}
+	return entity.QRLoginCompleted, user, nil
+}
+
+// DeleteExpired removes logins that expired more than an hour ago
+func (uc *QRLoginUseCase) DeleteExpired() (int, error) {
+	return uc.qrLoginRepo.DeleteExpired(uc.now().Add(-qrLoginRetention))
+}
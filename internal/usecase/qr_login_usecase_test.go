@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock QR login repository for testing
+type MockQRLoginRepository struct {
+	logins []*entity.QRLogin
+}
+
+func (m *MockQRLoginRepository) Create(login *entity.QRLogin) error {
+	login.ID = len(m.logins) + 1
+	stored := *login
+	m.logins = append(m.logins, &stored)
+	return nil
+}
+
+func (m *MockQRLoginRepository) find(match func(*entity.QRLogin) bool) (*entity.QRLogin, error) {
+	for _, login := range m.logins {
+		if login != nil && match(login) {
+			found := *login
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrQRLoginNotFound
+}
+
+func (m *MockQRLoginRepository) GetByChallenge(challengeHash string) (*entity.QRLogin, error) {
+	return m.find(func(l *entity.QRLogin) bool { return l.ChallengeHash == challengeHash })
+}
+
+func (m *MockQRLoginRepository) GetByPoll(pollHash string) (*entity.QRLogin, error) {
+	return m.find(func(l *entity.QRLogin) bool { return l.PollHash == pollHash })
+}
+
+func (m *MockQRLoginRepository) Decide(id int, status entity.QRLoginStatus, userID int, now time.Time) error {
+	login := m.logins[id-1]
+	if login.Status != entity.QRLoginPending || !now.Before(login.ExpiresAt) {
+		return repository.ErrQRLoginNotPending
+	}
+	login.Status, login.UserID = status, userID
+	return nil
+}
+
+func (m *MockQRLoginRepository) Complete(id int, now time.Time) (bool, error) {
+	login := m.logins[id-1]
+	if login.Status != entity.QRLoginApproved || !now.Before(login.ExpiresAt) {
+		return false, nil
+	}
+	login.Status = entity.QRLoginCompleted
+	return true, nil
+}
+
+func (m *MockQRLoginRepository) DeleteExpired(before time.Time) (int, error) {
+	deleted := 0
+	for i, login := range m.logins {
+		if login != nil && login.ExpiresAt.Before(before) {
+			m.logins[i] = nil
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+var _ repository.QRLoginRepository = (*MockQRLoginRepository)(nil)
+
+func TestQRLoginUseCase(t *testing.T) {
+	userUseCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	user, err := userUseCase.RegisterUser("phone@example.com", "password123", "Phone User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &MockQRLoginRepository{}
+	useCase := NewQRLoginUseCase(repo, userUseCase, 2*time.Minute)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	useCase.now = func() time.Time { return now }
+
+	challenge, pollToken, login, err := useCase.Start("203.0.113.7", "Firefox", "TH")
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if challenge == pollToken || login.ChallengeHash == challenge || !login.ExpiresAt.Equal(now.Add(2*time.Minute)) {
+		t.Errorf("Start() = %q, %q, %+v", challenge, pollToken, login)
+	}
+
+	if status, user, err := useCase.Poll(pollToken); err != nil || status != entity.QRLoginPending || user != nil {
+		t.Errorf("Poll() = %v, %v, %v; want pending", status, user, err)
+	}
+	// The challenge in the QR code cannot be used to poll
+	if _, _, err := useCase.Poll(challenge); !errors.Is(err, ErrQRLoginNotFound) {
+		t.Errorf("Poll(challenge) error = %v, want ErrQRLoginNotFound", err)
+	}
+
+	scanned, err := useCase.Scan(challenge)
+	if err != nil || scanned.Device != "Firefox" || scanned.IP != "203.0.113.7" {
+		t.Fatalf("Scan() = %+v, %v", scanned, err)
+	}
+	if err := useCase.Approve(user.ID, challenge); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if err := useCase.Deny(user.ID, challenge); !errors.Is(err, ErrQRLoginNotPending) {
+		t.Errorf("Deny() after approval error = %v, want ErrQRLoginNotPending", err)
+	}
+
+	// The first poll after approval gets the user, once
+	status, signedIn, err := useCase.Poll(pollToken)
+	if err != nil || status != entity.QRLoginCompleted || signedIn == nil || signedIn.ID != user.ID || signedIn.Password != "" {
+		t.Fatalf("Poll() = %v, %+v, %v; want the approving user", status, signedIn, err)
+	}
+	if status, signedIn, err := useCase.Poll(pollToken); err != nil || status != entity.QRLoginCompleted || signedIn != nil {
+		t.Errorf("Poll() again = %v, %+v, %v; want completed without a user", status, signedIn, err)
+	}
+}
+
+func TestQRLoginUseCase_DenyAndExpire(t *testing.T) {
+	userUseCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	user, _ := userUseCase.RegisterUser("phone@example.com", "password123", "Phone User", "0812345678", "1990-01-15")
+	repo := &MockQRLoginRepository{}
+	useCase := NewQRLoginUseCase(repo, userUseCase, 2*time.Minute)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	useCase.now = func() time.Time { return now }
+
+	denied, deniedPoll, _, _ := useCase.Start("203.0.113.7", "Firefox", "")
+	if err := useCase.Deny(user.ID, denied); err != nil {
+		t.Fatalf("Deny() error = %v", err)
+	}
+	if status, signedIn, err := useCase.Poll(deniedPoll); status != entity.QRLoginDenied || signedIn != nil || err != nil {
+		t.Errorf("Poll() denied = %v, %v, %v", status, signedIn, err)
+	}
+
+	expiring, expiringPoll, _, _ := useCase.Start("203.0.113.7", "Firefox", "")
+	now = now.Add(2 * time.Minute)
+	if err := useCase.Approve(user.ID, expiring); !errors.Is(err, ErrQRLoginExpired) {
+		t.Errorf("Approve() expired error = %v, want ErrQRLoginExpired", err)
+	}
+	if status, _, _ := useCase.Poll(expiringPoll); status != entity.QRLoginExpired {
+		t.Errorf("Poll() expired = %v", status)
+	}
+	if err := useCase.Approve(user.ID, "unknown"); !errors.Is(err, ErrQRLoginNotFound) {
+		t.Errorf("Approve() unknown error = %v, want ErrQRLoginNotFound", err)
+	}
+
+	// Suspended users cannot approve
+	suspended, _, _, _ := useCase.Start("203.0.113.7", "Firefox", "")
+	if err := userUseCase.SuspendUser(0, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := useCase.Approve(user.ID, suspended); !errors.Is(err, ErrAccountSuspended) {
+		t.Errorf("Approve() by suspended user error = %v, want ErrAccountSuspended", err)
+	}
+
+	now = now.Add(qrLoginRetention + 3*time.Minute)
+	if deleted, err := useCase.DeleteExpired(); err != nil || deleted != 3 {
+		t.Errorf("DeleteExpired() = %d, %v; want 3", deleted, err)
+	}
+}
@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ErrUnknownProjection is returned for a projection name that is not one of
+// entity.Projections
+var ErrUnknownProjection = errors.New("unknown projection")
+
+// projectionBatchSize is how many events a projection applies per transaction
+const projectionBatchSize = 500
+
+// userExportBatchSize is how many users ExportUsers reads at a time
+const userExportBatchSize = 500
+
+// ReadModelUseCase keeps the read models up to date from the domain event
+// log and serves the admin queries that would be heavy on the users table.
+// The read models trail the log by up to a worker interval.
+type ReadModelUseCase struct {
+	readModelRepo repository.ReadModelRepository
+	eventRepo     repository.EventRepository
+	userRepo      repository.UserRepository
+}
+
+// NewReadModelUseCase creates a new read model use case
+func NewReadModelUseCase(readModelRepo repository.ReadModelRepository, eventRepo repository.EventRepository, userRepo repository.UserRepository) *ReadModelUseCase {
+	return &ReadModelUseCase{
+		readModelRepo: readModelRepo,
+		eventRepo:     eventRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// Project applies the events logged since a projection last ran, in
+// batches, and returns how many it read
+func (uc *ReadModelUseCase) Project(projection string) (int, error) {
+	var apply func(position int64, events []*entity.DomainEvent) error
+	switch projection {
+	case entity.ProjectionUserSearch:
+		apply = uc.applyUserSearch
+	case entity.ProjectionLoginStats:
+		apply = uc.applyLoginStats
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownProjection, projection)
+	}
+
+	position, ok, err := uc.readModelRepo.Position(projection)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s position: %w", projection, err)
+	}
+	if !ok && projection == entity.ProjectionUserSearch {
+		return 0, uc.seedUserSearch()
+	}
+
+	var read int
+	for {
+		events, err := uc.eventRepo.List(repository.EventFilter{AfterID: position, Limit: projectionBatchSize})
+		if err != nil {
+			return read, fmt.Errorf("failed to list events: %w", err)
+		}
+		if len(events) == 0 {
+			return read, nil
+		}
+		position = events[len(events)-1].ID
+		if err := apply(position, events); err != nil {
+			return read, fmt.Errorf("failed to update %s: %w", projection, err)
+		}
+		read += len(events)
+		if len(events) < projectionBatchSize {
+			return read, nil
+		}
+	}
+}
+
+// seedUserSearch fills user_search from the users table, so users from
+// before the event log are found too, and starts the projection at the
+// newest event. Events logged while seeding are applied again, which only
+// reloads their users.
+func (uc *ReadModelUseCase) seedUserSearch() error {
+	position, err := uc.eventRepo.LastID()
+	if err != nil {
+		return fmt.Errorf("failed to read the event log: %w", err)
+	}
+
+	var entries []*entity.UserSearchEntry
+	page := repository.Page{Limit: repository.MaxPageLimit}
+	for {
+		users, err := uc.userRepo.List(repository.UserFilter{}, page)
+		if err != nil {
+			return fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, user := range users {
+			entries = append(entries, userSearchEntry(user))
+		}
+		if len(users) < page.Limit {
+			break
+		}
+		page.Offset += page.Limit
+	}
+	if err := uc.readModelRepo.SaveUserSearch(position, entries, nil); err != nil {
+		return fmt.Errorf("failed to seed %s: %w", entity.ProjectionUserSearch, err)
+	}
+	return nil
+}
+
+// userSearchEntry returns the user_search row of a user
+func userSearchEntry(user *entity.User) *entity.UserSearchEntry {
+	return &entity.UserSearchEntry{
+		UserID:    user.ID,
+		Email:     user.Email,
+		FullName:  user.FullName,
+		Role:      user.Role,
+		Status:    user.Status,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+// applyUserSearch reloads the users the events are about. Events carry
+// what changed rather than the new values, so each user is read once per
+// batch; users that can no longer be read were deleted and are removed.
+func (uc *ReadModelUseCase) applyUserSearch(position int64, events []*entity.DomainEvent) error {
+	var changed []int
+	for _, event := range events {
+		if event.SubjectType == entity.EventSubjectUser && !slices.Contains(changed, event.SubjectID) {
+			changed = append(changed, event.SubjectID)
+		}
+	}
+
+	var entries []*entity.UserSearchEntry
+	var removed []int
+	for _, userID := range changed {
+		user, err := uc.userRepo.GetByID(userID)
+		if err != nil {
+			removed = append(removed, userID)
+			continue
+		}
+		entries = append(entries, userSearchEntry(user))
+	}
+	return uc.readModelRepo.SaveUserSearch(position, entries, removed)
+}
+
+// applyLoginStats counts the user.logged_in events by user and drops the
+// stats of deleted users
+func (uc *ReadModelUseCase) applyLoginStats(position int64, events []*entity.DomainEvent) error {
+	added := map[int]*entity.LoginStats{}
+	var order, removed []int
+	for _, event := range events {
+		switch event.Type {
+		case entity.EventUserLoggedIn:
+			stats, ok := added[event.SubjectID]
+			if !ok {
+				stats = &entity.LoginStats{UserID: event.SubjectID, FirstLoginAt: event.OccurredAt}
+				added[event.SubjectID] = stats
+				order = append(order, event.SubjectID)
+			}
+			stats.Logins++
+			if event.Data["method"] == "passwordless" {
+				stats.PasswordlessLogins++
+			} else {
+				stats.PasswordLogins++
+			}
+			stats.LastLoginAt = event.OccurredAt
+		case entity.EventUserDeleted:
+			if _, ok := added[event.SubjectID]; ok {
+				delete(added, event.SubjectID)
+				order = slices.DeleteFunc(order, func(id int) bool { return id == event.SubjectID })
+			}
+			removed = append(removed, event.SubjectID)
+		}
+	}
+
+	stats := make([]*entity.LoginStats, 0, len(order))
+	for _, userID := range order {
+		stats = append(stats, added[userID])
+	}
+	return uc.readModelRepo.AddLoginStats(position, stats, removed)
+}
+
+// Statuses returns how far each projection has read the event log
+func (uc *ReadModelUseCase) Statuses() ([]*entity.ProjectionStatus, error) {
+	stored, err := uc.readModelRepo.Statuses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read projection positions: %w", err)
+	}
+	last, err := uc.eventRepo.LastID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the event log: %w", err)
+	}
+
+	statuses := make([]*entity.ProjectionStatus, 0, len(entity.Projections))
+	for _, name := range entity.Projections {
+		status := &entity.ProjectionStatus{Name: name}
+		if i := slices.IndexFunc(stored, func(s *entity.ProjectionStatus) bool { return s.Name == name }); i >= 0 {
+			status = stored[i]
+		}
+		status.Behind = last - status.Position
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Rebuild empties a projection's read model; its worker then rebuilds it
+// from the start of the event log
+func (uc *ReadModelUseCase) Rebuild(projection string) error {
+	if !slices.Contains(entity.Projections, projection) {
+		return fmt.Errorf("%w: %q", ErrUnknownProjection, projection)
+	}
+	if err := uc.readModelRepo.Reset(projection); err != nil {
+		return fmt.Errorf("failed to reset %s: %w", projection, err)
+	}
+	return nil
+}
+
+// SearchUsers returns a page of the users matching the filter, with their
+// login stats, and the total number of matches
+func (uc *ReadModelUseCase) SearchUsers(filter repository.UserFilter, page repository.Page) ([]*entity.UserSummary, int, error) {
+	users, total, err := uc.readModelRepo.SearchUsers(filter, page.Normalize())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search users: %w", err)
+	}
+	return users, total, nil
+}
+
+// ExportUsers passes every user matching the filter to write, ordered by ID,
+// with their login stats, reading them in batches so memory use does not
+// grow with the number of users
+func (uc *ReadModelUseCase) ExportUsers(filter repository.UserFilter, write func(user *entity.UserSummary) error) error {
+	afterID := 0
+	for {
+		users, err := uc.readModelRepo.ListUsers(filter, afterID, userExportBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, user := range users {
+			if err := write(user); err != nil {
+				return err
+			}
+		}
+		if len(users) < userExportBatchSize {
+			return nil
+		}
+		afterID = users[len(users)-1].UserID
+	}
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// MockReadModelRepository keeps the read models in memory
+type MockReadModelRepository struct {
+	positions  map[string]int64
+	userSearch map[int]*entity.UserSearchEntry
+	loginStats map[int]*entity.LoginStats
+	listCalls  int
+}
+
+func NewMockReadModelRepository() *MockReadModelRepository {
+	return &MockReadModelRepository{
+		positions:  map[string]int64{},
+		userSearch: map[int]*entity.UserSearchEntry{},
+		loginStats: map[int]*entity.LoginStats{},
+	}
+}
+
+func (m *MockReadModelRepository) Position(projection string) (int64, bool, error) {
+	position, ok := m.positions[projection]
+	return position, ok, nil
+}
+
+func (m *MockReadModelRepository) Statuses() ([]*entity.ProjectionStatus, error) {
+	var statuses []*entity.ProjectionStatus
+	for name, position := range m.positions {
+		statuses = append(statuses, &entity.ProjectionStatus{Name: name, Position: position})
+	}
+	return statuses, nil
+}
+
+func (m *MockReadModelRepository) SaveUserSearch(position int64, entries []*entity.UserSearchEntry, removed []int) error {
+	for _, entry := range entries {
+		m.userSearch[entry.UserID] = entry
+	}
+	for _, userID := range removed {
+		delete(m.userSearch, userID)
+	}
+	m.positions[entity.ProjectionUserSearch] = position
+	return nil
+}
+
+func (m *MockReadModelRepository) AddLoginStats(position int64, stats []*entity.LoginStats, removed []int) error {
+	for _, added := range stats {
+		if current, ok := m.loginStats[added.UserID]; ok {
+			current.Logins += added.Logins
+			current.PasswordLogins += added.PasswordLogins
+			current.PasswordlessLogins += added.PasswordlessLogins
+			current.LastLoginAt = added.LastLoginAt
+		} else {
+			m.loginStats[added.UserID] = added
+		}
+	}
+	for _, userID := range removed {
+		delete(m.loginStats, userID)
+	}
+	m.positions[entity.ProjectionLoginStats] = position
+	return nil
+}
+
+func (m *MockReadModelRepository) Reset(projection string) error {
+	delete(m.positions, projection)
+	if projection == entity.ProjectionUserSearch {
+		m.userSearch = map[int]*entity.UserSearchEntry{}
+	} else {
+		m.loginStats = map[int]*entity.LoginStats{}
+	}
+	return nil
+}
+
+func (m *MockReadModelRepository) SearchUsers(filter repository.UserFilter, page repository.Page) ([]*entity.UserSummary, int, error) {
+	var users []*entity.UserSummary
+	for _, entry := range m.userSearch {
+		users = append(users, &entity.UserSummary{UserSearchEntry: *entry, LoginStats: m.loginStats[entry.UserID]})
+	}
+	return users, len(users), nil
+}
+
+func (m *MockReadModelRepository) ListUsers(filter repository.UserFilter, afterID, limit int) ([]*entity.UserSummary, error) {
+	m.listCalls++
+	var ids []int
+	for id := range m.userSearch {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	users := []*entity.UserSummary{}
+	for _, id := range ids[:min(limit, len(ids))] {
+		users = append(users, &entity.UserSummary{UserSearchEntry: *m.userSearch[id], LoginStats: m.loginStats[id]})
+	}
+	return users, nil
+}
+
+func TestReadModelUseCase_UserSearch(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	events := &MockEventRepository{}
+	users := NewUserUseCase(userRepo, NewMockUserRevisionRepository())
+	existing, _ := users.RegisterUser("old@example.com", "password123", "Old User", "0812345678", "1990-01-15")
+	users.SetEventLog(events)
+
+	readModels := NewMockReadModelRepository()
+	uc := NewReadModelUseCase(readModels, events, userRepo)
+
+	// The first run seeds the users from before the event log
+	if _, err := uc.Project(entity.ProjectionUserSearch); err != nil {
+		t.Fatalf("Project() error = %v", err)
+	}
+	if entry := readModels.userSearch[existing.ID]; entry == nil || entry.Email != "old@example.com" {
+		t.Fatalf("seeded user_search = %+v", readModels.userSearch)
+	}
+
+	user, _ := users.RegisterUser("new@example.com", "password123", "New User", "0812345678", "1990-01-15")
+	if err := users.SuspendUser(99, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.DeleteUser(99, existing.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := uc.Project(entity.ProjectionUserSearch)
+	if err != nil || read != 3 {
+		t.Fatalf("Project() = %d, %v; want 3 events", read, err)
+	}
+	if entry := readModels.userSearch[user.ID]; entry == nil || entry.Status != entity.StatusSuspended {
+		t.Errorf("user_search entry of the new user = %+v", entry)
+	}
+	if _, ok := readModels.userSearch[existing.ID]; ok {
+		t.Error("deleted user should be removed from user_search")
+	}
+	if read, _ := uc.Project(entity.ProjectionUserSearch); read != 0 {
+		t.Errorf("Project() with no new events read %d", read)
+	}
+
+	statuses, err := uc.Statuses()
+	if err != nil || len(statuses) != 2 {
+		t.Fatalf("Statuses() = %+v, %v", statuses, err)
+	}
+	if statuses[0].Name != entity.ProjectionUserSearch || statuses[0].Behind != 0 || statuses[1].Behind != 3 {
+		t.Errorf("Statuses() = %+v, %+v", statuses[0], statuses[1])
+	}
+}
+
+func TestReadModelUseCase_LoginStats(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	events := &MockEventRepository{}
+	users := NewUserUseCase(userRepo, NewMockUserRevisionRepository())
+	users.SetEventLog(events)
+	user, _ := users.RegisterUser("test@example.com", "password123", "John Doe", "0812345678", "1990-01-15")
+	other, _ := users.RegisterUser("other@example.com", "password123", "Jane Doe", "0812345678", "1990-01-15")
+	for range 2 {
+		if _, err := users.AuthenticateUser("test@example.com", "password123"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	users.AuthenticateUserByID(user.ID)
+	users.AuthenticateUser("other@example.com", "password123")
+
+	readModels := NewMockReadModelRepository()
+	uc := NewReadModelUseCase(readModels, events, userRepo)
+	if _, err := uc.Project(entity.ProjectionLoginStats); err != nil {
+		t.Fatalf("Project() error = %v", err)
+	}
+	stats := readModels.loginStats[user.ID]
+	if stats == nil || stats.Logins != 3 || stats.PasswordLogins != 2 || stats.PasswordlessLogins != 1 ||
+		stats.FirstLoginAt.IsZero() || stats.LastLoginAt.Before(stats.FirstLoginAt) {
+		t.Errorf("login stats = %+v", stats)
+	}
+
+	users.DeleteUser(99, other.ID)
+	if _, err := uc.Project(entity.ProjectionLoginStats); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := readModels.loginStats[other.ID]; ok {
+		t.Error("deleted user's login stats should be removed")
+	}
+
+	// Rebuilding replays the whole log
+	if err := uc.Rebuild(entity.ProjectionLoginStats); err != nil {
+		t.Fatalf("Rebuild() error = %v", err)
+	}
+	if _, err := uc.Project(entity.ProjectionLoginStats); err != nil {
+		t.Fatal(err)
+	}
+	if stats := readModels.loginStats[user.ID]; stats == nil || stats.Logins != 3 {
+		t.Errorf("rebuilt login stats = %+v", stats)
+	}
+
+	if _, err := uc.Project("unknown"); !errors.Is(err, ErrUnknownProjection) {
+		t.Errorf("Project() error = %v, want ErrUnknownProjection", err)
+	}
+	if err := uc.Rebuild("unknown"); !errors.Is(err, ErrUnknownProjection) {
+		t.Errorf("Rebuild() error = %v, want ErrUnknownProjection", err)
+	}
+}
+
+func TestReadModelUseCase_ExportUsers(t *testing.T) {
+	readModels := NewMockReadModelRepository()
+	total := userExportBatchSize + 3
+	for id := 1; id <= total; id++ {
+		readModels.userSearch[id] = &entity.UserSearchEntry{UserID: id}
+	}
+	uc := NewReadModelUseCase(readModels, &MockEventRepository{}, NewMockUserRepository())
+
+	var ids []int
+	err := uc.ExportUsers(repository.UserFilter{}, func(user *entity.UserSummary) error {
+		ids = append(ids, user.UserID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportUsers() error = %v", err)
+	}
+	if len(ids) != total || ids[0] != 1 || ids[total-1] != total || !slices.IsSorted(ids) {
+		t.Errorf("ExportUsers() wrote %d users, want %d in ID order", len(ids), total)
+	}
+	if readModels.listCalls != 2 {
+		t.Errorf("ListUsers() calls = %d, want 2 batches", readModels.listCalls)
+	}
+
+	// A failed write stops the export
+	stop := errors.New("client gone")
+	written := 0
+	err = uc.ExportUsers(repository.UserFilter{}, func(user *entity.UserSummary) error {
+		written++
+		return stop
+	})
+	if !errors.Is(err, stop) || written != 1 {
+		t.Errorf("ExportUsers() = %v after %d writes, want the write error after 1", err, written)
+	}
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ErrSchemaChangeNotFound is returned for a schema change that is not
+// defined or has not started
+var ErrSchemaChangeNotFound = repository.ErrSchemaChangeNotFound
+
+// ErrSchemaChangeBackfilling is returned when switching the reads of a
+// schema change whose backfill is not done
+var ErrSchemaChangeBackfilling = errors.New("schema change is still backfilling")
+
+// SchemaChangeUseCase backfills the online schema changes of large tables
+// a batch at a time and switches their reads to the new schema. Which
+// changes read the new schema is cached and reloaded with Refresh.
+type SchemaChangeUseCase struct {
+	schemaChangeRepo repository.SchemaChangeRepository
+	names            []string
+	batchSize        int
+
+	mu       sync.RWMutex
+	readsNew map[string]bool
+}
+
+// NewSchemaChangeUseCase creates a new schema change use case for the
+// changes with names, copying batchSize rows per batch
+func NewSchemaChangeUseCase(schemaChangeRepo repository.SchemaChangeRepository, names []string, batchSize int) *SchemaChangeUseCase {
+	return &SchemaChangeUseCase{
+		schemaChangeRepo: schemaChangeRepo,
+		names:            names,
+		batchSize:        max(batchSize, 1),
+		readsNew:         make(map[string]bool),
+	}
+}
+
+// Backfill starts the changes that have not started and copies one batch
+// of each change still backfilling, so writers get the table between
+// batches. It returns how many rows were copied.
+func (uc *SchemaChangeUseCase) Backfill() (int, error) {
+	var copied int
+	for _, name := range uc.names {
+		change, err := uc.schemaChangeRepo.Start(name)
+		if err != nil {
+			return copied, fmt.Errorf("failed to start %s: %w", name, err)
+		}
+		if change.Phase != entity.SchemaChangeBackfilling {
+			continue
+		}
+		change, n, err := uc.schemaChangeRepo.Backfill(name, uc.batchSize)
+		if err != nil {
+			return copied, err
+		}
+		copied += n
+		if change.Phase == entity.SchemaChangeBackfilled {
+			log.Printf("Schema change %s backfilled %d rows, its reads can be switched", name, change.Backfilled)
+		}
+	}
+	return copied, nil
+}
+
+// Refresh reloads which changes read the new schema, e.g. after an admin
+// switched them on another instance
+func (uc *SchemaChangeUseCase) Refresh() error {
+	changes, err := uc.schemaChangeRepo.List()
+	if err != nil {
+		return fmt.Errorf("failed to load schema changes: %w", err)
+	}
+	readsNew := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		readsNew[change.Name] = change.Phase == entity.SchemaChangeReadingNew
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.readsNew = readsNew
+	return nil
+}
+
+// ReadsNew reports whether reads of a change go to its new column or table.
+// It is false until the change is switched and Refresh has run since.
+func (uc *SchemaChangeUseCase) ReadsNew(name string) bool {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	return uc.readsNew[name]
+}
+
+// List returns the progress of every change that has started, by name
+func (uc *SchemaChangeUseCase) List() ([]*entity.SchemaChange, error) {
+	return uc.schemaChangeRepo.List()
+}
+
+// SwitchReads moves the reads of a backfilled change to its new column or
+// table, or back to the old one
+func (uc *SchemaChangeUseCase) SwitchReads(name string, toNew bool) (*entity.SchemaChange, error) {
+	changes, err := uc.schemaChangeRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	var current *entity.SchemaChange
+	for _, change := range changes {
+		if change.Name == name {
+			current = change
+		}
+	}
+	switch {
+	case current == nil:
+		return nil, fmt.Errorf("%w: %q", ErrSchemaChangeNotFound, name)
+	case current.Phase == entity.SchemaChangeBackfilling:
+		return nil, fmt.Errorf("%w: %s has copied %d of %d rows", ErrSchemaChangeBackfilling, name, current.Backfilled, current.Total)
+	}
+
+	phase := entity.SchemaChangeBackfilled
+	if toNew {
+		phase = entity.SchemaChangeReadingNew
+	}
+	change, err := uc.schemaChangeRepo.SetPhase(name, phase)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.readsNew[name] = toNew
+	return change, nil
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock schema change repository for testing, backfilling tables of rows
+// numbered from 1
+type MockSchemaChangeRepository struct {
+	rows    map[string]int
+	changes map[string]*entity.SchemaChange
+}
+
+func (m *MockSchemaChangeRepository) List() ([]*entity.SchemaChange, error) {
+	var changes []*entity.SchemaChange
+	for _, change := range m.changes {
+		found := *change
+		changes = append(changes, &found)
+	}
+	return changes, nil
+}
+
+func (m *MockSchemaChangeRepository) Start(name string) (*entity.SchemaChange, error) {
+	rows, ok := m.rows[name]
+	if !ok {
+		return nil, repository.ErrSchemaChangeNotFound
+	}
+	if _, ok := m.changes[name]; !ok {
+		m.changes[name] = &entity.SchemaChange{Name: name, Phase: entity.SchemaChangeBackfilling, Total: int64(rows)}
+	}
+	found := *m.changes[name]
+	return &found, nil
+}
+
+func (m *MockSchemaChangeRepository) Backfill(name string, limit int) (*entity.SchemaChange, int, error) {
+	change, ok := m.changes[name]
+	if !ok {
+		return nil, 0, repository.ErrSchemaChangeNotFound
+	}
+	copied := 0
+	if change.Phase == entity.SchemaChangeBackfilling {
+		copied = min(limit, m.rows[name]-int(change.Cursor))
+		change.Cursor += int64(copied)
+		change.Backfilled += int64(copied)
+		if copied == 0 {
+			change.Phase = entity.SchemaChangeBackfilled
+		}
+	}
+	found := *change
+	return &found, copied, nil
+}
+
+func (m *MockSchemaChangeRepository) SetPhase(name, phase string) (*entity.SchemaChange, error) {
+	change, ok := m.changes[name]
+	if !ok {
+		return nil, repository.ErrSchemaChangeNotFound
+	}
+	change.Phase = phase
+	found := *change
+	return &found, nil
+}
+
+func TestSchemaChangeUseCase(t *testing.T) {
+	repo := &MockSchemaChangeRepository{
+		rows:    map[string]int{"users_email_lower": 5, "events_payload": 2},
+		changes: make(map[string]*entity.SchemaChange),
+	}
+	uc := NewSchemaChangeUseCase(repo, []string{"users_email_lower", "events_payload"}, 2)
+
+	// Each run copies a batch of every change still backfilling
+	var runs []int
+	for i := 0; i < 3; i++ {
+		copied, err := uc.Backfill()
+		if err != nil {
+			t.Fatalf("Backfill() error = %v", err)
+		}
+		runs = append(runs, copied)
+	}
+	if fmt.Sprint(runs) != "[4 2 1]" {
+		t.Errorf("Backfill() copied %v, want [4 2 1]", runs)
+	}
+	if phase := repo.changes["events_payload"].Phase; phase != entity.SchemaChangeBackfilled {
+		t.Errorf("events_payload phase = %s, want backfilled", phase)
+	}
+	if progress := repo.changes["users_email_lower"]; progress.Phase != entity.SchemaChangeBackfilling || progress.Progress() != 1 {
+		t.Errorf("users_email_lower = %+v with progress %v, want all rows copied", progress, progress.Progress())
+	}
+
+	// Reads switch only once the backfill is done
+	if _, err := uc.SwitchReads("users_email_lower", true); !errors.Is(err, ErrSchemaChangeBackfilling) {
+		t.Errorf("SwitchReads() while backfilling error = %v, want ErrSchemaChangeBackfilling", err)
+	}
+	if _, err := uc.SwitchReads("missing", true); !errors.Is(err, ErrSchemaChangeNotFound) {
+		t.Errorf("SwitchReads() of an unknown change error = %v, want ErrSchemaChangeNotFound", err)
+	}
+	// The next run finds no rows left
+	if copied, err := uc.Backfill(); err != nil || copied != 0 || repo.changes["users_email_lower"].Phase != entity.SchemaChangeBackfilled {
+		t.Errorf("Backfill() after the last batch = %d, %v", copied, err)
+	}
+	change, err := uc.SwitchReads("events_payload", true)
+	if err != nil || change.Phase != entity.SchemaChangeReadingNew {
+		t.Fatalf("SwitchReads() = %+v, %v", change, err)
+	}
+	if !uc.ReadsNew("events_payload") || uc.ReadsNew("users_email_lower") {
+		t.Error("ReadsNew() should follow the switch")
+	}
+
+	// Other instances pick the switch up with Refresh
+	other := NewSchemaChangeUseCase(repo, nil, 2)
+	if other.ReadsNew("events_payload") {
+		t.Error("ReadsNew() should be false before Refresh()")
+	}
+	if err := other.Refresh(); err != nil || !other.ReadsNew("events_payload") {
+		t.Errorf("ReadsNew() after Refresh() = false, %v", err)
+	}
+
+	if _, err := uc.SwitchReads("events_payload", false); err != nil || uc.ReadsNew("events_payload") {
+		t.Errorf("SwitchReads() back = %v, reads new %t", err, uc.ReadsNew("events_payload"))
+	}
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/textnorm"
+)
+
+// SecurityReportWindow is how far back security reports look
+const SecurityReportWindow = 30 * 24 * time.Hour
+
+// maxReportEvents bounds each list of login attempts in a security report
+const maxReportEvents = 20
+
+// maxDeviceLength bounds the stored User-Agent, which clients control
+const maxDeviceLength = 256
+
+// SecurityUseCase keeps the login history and reports on it to users
+type SecurityUseCase struct {
+	loginRepo repository.LoginEventRepository
+	userRepo  repository.UserRepository
+	now       func() time.Time
+}
+
+// NewSecurityUseCase creates a new security use case
+func NewSecurityUseCase(loginRepo repository.LoginEventRepository, userRepo repository.UserRepository) *SecurityUseCase {
+	return &SecurityUseCase{
+		loginRepo: loginRepo,
+		userRepo:  userRepo,
+		now:       time.Now,
+	}
+}
+
+// RecordLogin records a successful login by userID from ip with the given
+// device (User-Agent) and country, which may be empty
+func (uc *SecurityUseCase) RecordLogin(userID int, ip, device, country string) (*entity.LoginEvent, error) {
+	return uc.record(userID, true, ip, device, country)
+}
+
+// RecordFailedLogin records a failed login on the account with email.
+// Attempts on emails without an account are not recorded.
+func (uc *SecurityUseCase) RecordFailedLogin(email, ip, device, country string) error {
+	user, err := uc.userRepo.GetByEmail(textnorm.Identifier(email))
+	if err != nil {
+		return nil
+	}
+	_, err = uc.record(user.ID, false, ip, device, country)
+	return err
+}
+
+// record stores a login attempt
+func (uc *SecurityUseCase) record(userID int, success bool, ip, device, country string) (*entity.LoginEvent, error) {
+	if len(device) > maxDeviceLength {
+		device = device[:maxDeviceLength]
+	}
+
+	event := &entity.LoginEvent{UserID: userID, Success: success, IP: ip, Device: device, Country: country}
+	if err := uc.loginRepo.Record(event); err != nil {
+		return nil, fmt.Errorf("failed to record login: %w", err)
+	}
+	return event, nil
+}
+
+// Report summarizes the user's logins over the last SecurityReportWindow:
+// recent logins and failed attempts, logins from new devices and logins
+// from countries the user had not signed in from before
+func (uc *SecurityUseCase) Report(userID int) (*entity.SecurityReport, error) {
+	until := uc.now().UTC()
+	report := &entity.SecurityReport{
+		Since:          until.Add(-SecurityReportWindow),
+		Until:          until,
+		Countries:      []string{},
+		RecentLogins:   []*entity.LoginEvent{},
+		NewDevices:     []*entity.LoginEvent{},
+		RecentFailures: []*entity.LoginEvent{},
+		GeoAnomalies:   []*entity.LoginEvent{},
+	}
+
+	events, err := uc.loginRepo.ListByUser(userID, report.Since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read login history: %w", err)
+	}
+
+	countries := make(map[string]bool)
+	for _, event := range events {
+		if !event.Success {
+			report.FailedAttempts++
+			report.RecentFailures = appendCapped(report.RecentFailures, event)
+			continue
+		}
+
+		report.SuccessfulLogins++
+		report.RecentLogins = appendCapped(report.RecentLogins, event)
+		if event.NewDevice {
+			report.NewDevices = appendCapped(report.NewDevices, event)
+		}
+		if event.NewCountry {
+			report.GeoAnomalies = appendCapped(report.GeoAnomalies, event)
+		}
+		if event.Country != "" && !countries[event.Country] {
+			countries[event.Country] = true
+			report.Countries = append(report.Countries, event.Country)
+		}
+	}
+	sort.Strings(report.Countries)
+
+	return report, nil
+}
+
+// appendCapped appends event unless events already holds maxReportEvents
+func appendCapped(events []*entity.LoginEvent, event *entity.LoginEvent) []*entity.LoginEvent {
+	if len(events) >= maxReportEvents {
+		return events
+	}
+	return append(events, event)
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock login event repository for testing
+type MockLoginEventRepository struct {
+	events []*entity.LoginEvent
+	now    time.Time
+}
+
+func (m *MockLoginEventRepository) Record(event *entity.LoginEvent) error {
+	event.ID = len(m.events) + 1
+	event.CreatedAt = m.now
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *MockLoginEventRepository) ListByUser(userID int, since time.Time) ([]*entity.LoginEvent, error) {
+	var events []*entity.LoginEvent
+	for i := len(m.events) - 1; i >= 0; i-- {
+		if m.events[i].UserID == userID && !m.events[i].CreatedAt.Before(since) {
+			events = append(events, m.events[i])
+		}
+	}
+	return events, nil
+}
+
+func (m *MockLoginEventRepository) ListSuccessful(since, until time.Time) ([]*entity.LoginEvent, error) {
+	var events []*entity.LoginEvent
+	for _, event := range m.events {
+		if event.Success && !event.CreatedAt.Before(since) && event.CreatedAt.Before(until) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (m *MockLoginEventRepository) ListFailed(since, until time.Time) ([]*entity.LoginEvent, error) {
+	var events []*entity.LoginEvent
+	for _, event := range m.events {
+		if !event.Success && !event.CreatedAt.Before(since) && event.CreatedAt.Before(until) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+var _ repository.LoginEventRepository = (*MockLoginEventRepository)(nil)
+
+func TestSecurityUseCase_Report(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	user, _ := userRepo.Create(entity.NewUser("secure@example.com", "hash", "Secure User", "0812345678", "1990-01-15"))
+
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	loginRepo := &MockLoginEventRepository{now: now.Add(-40 * 24 * time.Hour)}
+	useCase := NewSecurityUseCase(loginRepo, userRepo)
+	useCase.now = func() time.Time { return now }
+
+	// Outside the window
+	if _, err := useCase.RecordLogin(user.ID, "198.51.100.1", "old", "TH"); err != nil {
+		t.Fatal(err)
+	}
+
+	loginRepo.now = now.Add(-time.Hour)
+	useCase.RecordLogin(user.ID, "198.51.100.1", "laptop", "TH")
+	loginRepo.events = append(loginRepo.events, &entity.LoginEvent{UserID: user.ID, Success: true, Device: "phone", Country: "JP", NewDevice: true, NewCountry: true, CreatedAt: now})
+	if err := useCase.RecordFailedLogin("secure@example.com", "203.0.113.9", "curl/8.0", "RU"); err != nil {
+		t.Fatal(err)
+	}
+	// Unknown emails have no history to record in
+	if err := useCase.RecordFailedLogin("nobody@example.com", "203.0.113.9", "curl/8.0", ""); err != nil || len(loginRepo.events) != 4 {
+		t.Fatalf("RecordFailedLogin() for unknown email = %v, %d events", err, len(loginRepo.events))
+	}
+
+	report, err := useCase.Report(user.ID)
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if !report.Since.Equal(now.Add(-SecurityReportWindow)) || report.SuccessfulLogins != 2 || report.FailedAttempts != 1 {
+		t.Errorf("Report() = %+v", report)
+	}
+	if !reflect.DeepEqual(report.Countries, []string{"JP", "TH"}) {
+		t.Errorf("Countries = %v, want [JP TH]", report.Countries)
+	}
+	if len(report.NewDevices) != 1 || report.NewDevices[0].Device != "phone" || len(report.GeoAnomalies) != 1 {
+		t.Errorf("NewDevices = %+v, GeoAnomalies = %+v", report.NewDevices, report.GeoAnomalies)
+	}
+	if len(report.RecentFailures) != 1 || report.RecentFailures[0].Country != "RU" {
+		t.Errorf("RecentFailures = %+v", report.RecentFailures)
+	}
+}
@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ErrShareLinkNotFound is returned for an unknown share link or token
+var ErrShareLinkNotFound = repository.ErrShareLinkNotFound
+
+// ErrShareLinkUnavailable is returned when opening a share link that
+// expired, was revoked or was used up
+var ErrShareLinkUnavailable = repository.ErrShareLinkUnavailable
+
+// ErrInvalidShareLink is returned when a share link is requested with
+// fields, a lifetime or a view limit that are not allowed
+var ErrInvalidShareLink = errors.New("invalid share link")
+
+// Share link limits
+const (
+	defaultShareLinkTTL = 24 * time.Hour
+	maxShareLinkTTL     = 30 * 24 * time.Hour
+	maxShareLinkViews   = 100
+)
+
+// shareableFields are the profile fields a share link can grant
+var shareableFields = []string{
+	repository.FieldEmail,
+	repository.FieldFullName,
+	repository.FieldPhoneNumber,
+	repository.FieldBirthday,
+	repository.FieldAvatar,
+}
+
+// ShareLinkUseCase lets users share some of their profile fields through
+// links that work without signing in
+type ShareLinkUseCase struct {
+	shareLinkRepo repository.ShareLinkRepository
+	users         *UserUseCase
+	now           func() time.Time
+}
+
+// NewShareLinkUseCase creates a new share link use case
+func NewShareLinkUseCase(shareLinkRepo repository.ShareLinkRepository, users *UserUseCase) *ShareLinkUseCase {
+	return &ShareLinkUseCase{
+		shareLinkRepo: shareLinkRepo,
+		users:         users,
+		now:           time.Now,
+	}
+}
+
+// Create makes a link to the given profile fields of userID that lasts ttl
+// (a day when zero, at most 30 days) and opens maxViews times (once when
+// zero, at most 100). It returns the link's token; only its hash is stored.
+func (uc *ShareLinkUseCase) Create(userID int, fields []string, ttl time.Duration, maxViews int) (string, *entity.ShareLink, error) {
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("%w: no fields to share", ErrInvalidShareLink)
+	}
+	var shared []string
+	for _, field := range fields {
+		if !slices.Contains(shareableFields, field) {
+			return "", nil, fmt.Errorf("%w: %q cannot be shared", ErrInvalidShareLink, field)
+		}
+		if !slices.Contains(shared, field) {
+			shared = append(shared, field)
+		}
+	}
+	if ttl == 0 {
+		ttl = defaultShareLinkTTL
+	}
+	if ttl < 0 || ttl > maxShareLinkTTL {
+		return "", nil, fmt.Errorf("%w: links last at most %v", ErrInvalidShareLink, maxShareLinkTTL)
+	}
+	if maxViews == 0 {
+		maxViews = 1
+	}
+	if maxViews < 0 || maxViews > maxShareLinkViews {
+		return "", nil, fmt.Errorf("%w: links open at most %d times", ErrInvalidShareLink, maxShareLinkViews)
+	}
+
+	user, err := uc.users.GetUserByID(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := CheckCanSignIn(user); err != nil {
+		return "", nil, err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	link := &entity.ShareLink{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		Fields:    shared,
+		MaxViews:  maxViews,
+		ExpiresAt: uc.now().Add(ttl).UTC(),
+	}
+	if err := uc.shareLinkRepo.Create(link); err != nil {
+		return "", nil, fmt.Errorf("failed to store share link: %w", err)
+	}
+	// Share link events go to the users' event log
+	recordEvent(uc.users.eventRepo, entity.NewDomainEvent(entity.EventShareLinkCreated, entity.EventSubjectShareLink, link.ID, userID,
+		map[string]interface{}{"fields": link.Fields, "maxViews": link.MaxViews, "expiresAt": link.ExpiresAt}))
+	return token, link, nil
+}
+
+// List returns the user's links, newest first
+func (uc *ShareLinkUseCase) List(userID int) ([]*entity.ShareLink, error) {
+	return uc.shareLinkRepo.ListByUser(userID)
+}
+
+// Revoke stops the user's link from opening
+func (uc *ShareLinkUseCase) Revoke(userID, id int) error {
+	if err := uc.shareLinkRepo.Revoke(userID, id, uc.now()); err != nil {
+		return err
+	}
+	recordEvent(uc.users.eventRepo, entity.NewDomainEvent(entity.EventShareLinkRevoked, entity.EventSubjectShareLink, id, userID, nil))
+	return nil
+}
+
+// Accesses returns who opened or tried to open the user's link, newest first
+func (uc *ShareLinkUseCase) Accesses(userID, id int) ([]*entity.ShareLinkAccess, error) {
+	link, err := uc.shareLinkRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if link.UserID != userID {
+		return nil, ErrShareLinkNotFound
+	}
+	return uc.shareLinkRepo.ListAccesses(id)
+}
+
+// Open counts a view of the link with the given token and returns its
+// owner's profile with only the shared fields set. Every attempt on a known
+// link is logged with the visitor's ip, device (User-Agent) and country.
+// Links of suspended users are unavailable.
+func (uc *ShareLinkUseCase) Open(token, ip, device, country string) (*entity.User, error) {
+	now := uc.now()
+	link, err := uc.shareLinkRepo.View(hashToken(token), now)
+	if errors.Is(err, ErrShareLinkNotFound) {
+		return nil, err
+	}
+	if err != nil && !errors.Is(err, ErrShareLinkUnavailable) {
+		return nil, fmt.Errorf("failed to open share link: %w", err)
+	}
+
+	var profile *entity.User
+	if err == nil {
+		profile, err = uc.sharedProfile(link)
+	}
+
+	if len(device) > maxDeviceLength {
+		device = device[:maxDeviceLength]
+	}
+	access := &entity.ShareLinkAccess{
+		LinkID:     link.ID,
+		IP:         ip,
+		Device:     device,
+		Country:    country,
+		Granted:    err == nil,
+		AccessedAt: now.UTC(),
+	}
+	if recordErr := uc.shareLinkRepo.RecordAccess(access); recordErr != nil {
+		return nil, fmt.Errorf("failed to record share link access: %w", recordErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// sharedProfile returns the link owner's profile with only the shared
+// fields set
+func (uc *ShareLinkUseCase) sharedProfile(link *entity.ShareLink) (*entity.User, error) {
+	owner, err := uc.users.GetUserByID(link.UserID)
+	if err != nil || !owner.CanSignIn() {
+		return nil, ErrShareLinkUnavailable
+	}
+
+	profile := &entity.User{}
+	for _, field := range link.Fields {
+		switch field {
+		case repository.FieldEmail:
+			profile.Email = owner.Email
+		case repository.FieldFullName:
+			profile.FullName = owner.FullName
+		case repository.FieldPhoneNumber:
+			profile.PhoneNumber = owner.PhoneNumber
+		case repository.FieldBirthday:
+			profile.Birthday = owner.Birthday
+		case repository.FieldAvatar:
+			profile.Avatar = owner.Avatar
+		}
+	}
+	return profile, nil
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock share link repository for testing
+type MockShareLinkRepository struct {
+	links    []*entity.ShareLink
+	accesses []*entity.ShareLinkAccess
+}
+
+func (m *MockShareLinkRepository) Create(link *entity.ShareLink) error {
+	link.ID = len(m.links) + 1
+	stored := *link
+	m.links = append(m.links, &stored)
+	return nil
+}
+
+func (m *MockShareLinkRepository) GetByID(id int) (*entity.ShareLink, error) {
+	if id < 1 || id > len(m.links) {
+		return nil, repository.ErrShareLinkNotFound
+	}
+	found := *m.links[id-1]
+	return &found, nil
+}
+
+func (m *MockShareLinkRepository) ListByUser(userID int) ([]*entity.ShareLink, error) {
+	var links []*entity.ShareLink
+	for i := len(m.links) - 1; i >= 0; i-- {
+		if m.links[i].UserID == userID {
+			found := *m.links[i]
+			links = append(links, &found)
+		}
+	}
+	return links, nil
+}
+
+func (m *MockShareLinkRepository) Revoke(userID, id int, now time.Time) error {
+	if id < 1 || id > len(m.links) || m.links[id-1].UserID != userID {
+		return repository.ErrShareLinkNotFound
+	}
+	if m.links[id-1].RevokedAt == nil {
+		m.links[id-1].RevokedAt = &now
+	}
+	return nil
+}
+
+func (m *MockShareLinkRepository) View(tokenHash string, now time.Time) (*entity.ShareLink, error) {
+	for _, link := range m.links {
+		if link.TokenHash != tokenHash {
+			continue
+		}
+		if link.StatusAt(now) != entity.ShareLinkActive {
+			found := *link
+			return &found, repository.ErrShareLinkUnavailable
+		}
+		link.Views++
+		found := *link
+		return &found, nil
+	}
+	return nil, repository.ErrShareLinkNotFound
+}
+
+func (m *MockShareLinkRepository) RecordAccess(access *entity.ShareLinkAccess) error {
+	access.ID = len(m.accesses) + 1
+	stored := *access
+	m.accesses = append(m.accesses, &stored)
+	return nil
+}
+
+func (m *MockShareLinkRepository) ListAccesses(linkID int) ([]*entity.ShareLinkAccess, error) {
+	var accesses []*entity.ShareLinkAccess
+	for i := len(m.accesses) - 1; i >= 0; i-- {
+		if m.accesses[i].LinkID == linkID {
+			accesses = append(accesses, m.accesses[i])
+		}
+	}
+	return accesses, nil
+}
+
+var _ repository.ShareLinkRepository = (*MockShareLinkRepository)(nil)
+
+func TestShareLinkUseCase(t *testing.T) {
+	userUseCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	user, err := userUseCase.RegisterUser("share@example.com", "password123", "Share User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &MockShareLinkRepository{}
+	useCase := NewShareLinkUseCase(repo, userUseCase)
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	useCase.now = func() time.Time { return now }
+
+	token, link, err := useCase.Create(user.ID, []string{"fullName", "birthday", "fullName"}, 0, 0)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if token == "" || link.TokenHash == token || len(link.Fields) != 2 || link.MaxViews != 1 || !link.ExpiresAt.Equal(now.Add(defaultShareLinkTTL)) {
+		t.Errorf("Create() = %q, %+v; want a one-time link to two fields for a day", token, link)
+	}
+
+	// Only the shared fields are returned, once
+	profile, err := useCase.Open(token, "203.0.113.7", "Firefox", "TH")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if profile.FullName != "Share User" || profile.Birthday != "1990-01-15" || profile.Email != "" || profile.PhoneNumber != "" || profile.ID != 0 {
+		t.Errorf("Open() = %+v; want only the full name and birthday", profile)
+	}
+	if _, err := useCase.Open(token, "198.51.100.2", "curl", ""); !errors.Is(err, ErrShareLinkUnavailable) {
+		t.Errorf("Open() again error = %v, want ErrShareLinkUnavailable", err)
+	}
+	if _, err := useCase.Open("unknown", "198.51.100.2", "curl", ""); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("Open(unknown) error = %v, want ErrShareLinkNotFound", err)
+	}
+
+	// Both attempts on the link are logged, for its owner only
+	accesses, err := useCase.Accesses(user.ID, link.ID)
+	if err != nil || len(accesses) != 2 || accesses[0].Granted || accesses[0].IP != "198.51.100.2" || !accesses[1].Granted || accesses[1].Country != "TH" {
+		t.Errorf("Accesses() = %+v, %v", accesses, err)
+	}
+	if _, err := useCase.Accesses(user.ID+1, link.ID); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("Accesses() by another user error = %v, want ErrShareLinkNotFound", err)
+	}
+
+	// Revoked links stop opening
+	token, revoked, err := useCase.Create(user.ID, []string{"email"}, time.Hour, 5)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := useCase.Revoke(user.ID+1, revoked.ID); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("Revoke() by another user error = %v, want ErrShareLinkNotFound", err)
+	}
+	if err := useCase.Revoke(user.ID, revoked.ID); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if _, err := useCase.Open(token, "203.0.113.7", "Firefox", ""); !errors.Is(err, ErrShareLinkUnavailable) {
+		t.Errorf("Open() revoked error = %v, want ErrShareLinkUnavailable", err)
+	}
+
+	links, err := useCase.List(user.ID)
+	if err != nil || len(links) != 2 || links[0].ID != revoked.ID || links[0].StatusAt(now) != entity.ShareLinkRevoked {
+		t.Errorf("List() = %+v, %v", links, err)
+	}
+
+	// Links of suspended users are unavailable
+	token, _, _ = useCase.Create(user.ID, []string{"avatar"}, time.Hour, 5)
+	if err := userUseCase.SuspendUser(0, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := useCase.Open(token, "203.0.113.7", "Firefox", ""); !errors.Is(err, ErrShareLinkUnavailable) {
+		t.Errorf("Open() of a suspended user error = %v, want ErrShareLinkUnavailable", err)
+	}
+	if _, _, err := useCase.Create(user.ID, []string{"avatar"}, 0, 0); !errors.Is(err, ErrAccountSuspended) {
+		t.Errorf("Create() by a suspended user error = %v, want ErrAccountSuspended", err)
+	}
+}
+
+func TestShareLinkUseCase_Create_Invalid(t *testing.T) {
+	userUseCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	user, _ := userUseCase.RegisterUser("share@example.com", "password123", "Share User", "0812345678", "1990-01-15")
+	useCase := NewShareLinkUseCase(&MockShareLinkRepository{}, userUseCase)
+
+	tests := []struct {
+		name     string
+		fields   []string
+		ttl      time.Duration
+		maxViews int
+	}{
+		{"no fields", nil, 0, 0},
+		{"password", []string{"fullName", "password"}, 0, 0},
+		{"role", []string{"role"}, 0, 0},
+		{"negative lifetime", []string{"fullName"}, -time.Hour, 0},
+		{"lifetime too long", []string{"fullName"}, maxShareLinkTTL + time.Second, 0},
+		{"negative views", []string{"fullName"}, 0, -1},
+		{"too many views", []string{"fullName"}, 0, maxShareLinkViews + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := useCase.Create(user.ID, tt.fields, tt.ttl, tt.maxViews); !errors.Is(err, ErrInvalidShareLink) {
+				t.Errorf("Create() error = %v, want ErrInvalidShareLink", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// ErrInvalidToken is returned when a single-use token is unknown or was issued for another purpose
+var ErrInvalidToken = errors.New("invalid token")
+
+// ErrTokenAlreadyUsed is returned when a single-use token is presented again after it was used
+var ErrTokenAlreadyUsed = repository.ErrTokenAlreadyUsed
+
+// ErrTokenExpired is returned when a single-use token is presented after it expired
+var ErrTokenExpired = repository.ErrTokenExpired
+
+// TokenUseCase issues and consumes the single-use tokens sent to users for
+// password resets, email verification, magic links and invites
+type TokenUseCase struct {
+	tokenRepo repository.OneTimeTokenRepository
+	now       func() time.Time
+}
+
+// NewTokenUseCase creates a new single-use token use case
+func NewTokenUseCase(tokenRepo repository.OneTimeTokenRepository) *TokenUseCase {
+	return &TokenUseCase{
+		tokenRepo: tokenRepo,
+		now:       time.Now,
+	}
+}
+
+// Issue creates a token for userID valid for ttl and returns it with its
+// record. The token is only returned here; just its hash is stored.
+func (uc *TokenUseCase) Issue(purpose entity.TokenPurpose, userID int, ttl time.Duration) (string, *entity.OneTimeToken, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	record := &entity.OneTimeToken{
+		Purpose:   purpose,
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: uc.now().Add(ttl).UTC(),
+	}
+	if err := uc.tokenRepo.Create(record); err != nil {
+		return "", nil, fmt.Errorf("failed to store token: %w", err)
+	}
+	return token, record, nil
+}
+
+// Consume uses up a token issued for purpose and returns its record. A token
+// can be consumed once, even by concurrent requests; later attempts get
+// ErrTokenAlreadyUsed.
+func (uc *TokenUseCase) Consume(purpose entity.TokenPurpose, token string) (*entity.OneTimeToken, error) {
+	record, err := uc.tokenRepo.Consume(purpose, hashToken(token), uc.now())
+	switch {
+	case errors.Is(err, repository.ErrTokenNotFound):
+		return nil, ErrInvalidToken
+	case errors.Is(err, repository.ErrTokenAlreadyUsed), errors.Is(err, repository.ErrTokenExpired):
+		return nil, err
+	case err != nil:
+		return nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+	return record, nil
+}
+
+// hashToken returns the stored form of a token. Tokens are 256 random bits,
+// so a fast unsalted hash is enough to make a leaked table useless.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken returns 256 random bits, base64url encoded
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+)
+
+// Mock one-time token repository for testing
+type MockOneTimeTokenRepository struct {
+	tokens []*entity.OneTimeToken
+}
+
+func (m *MockOneTimeTokenRepository) Create(token *entity.OneTimeToken) error {
+	token.ID = len(m.tokens) + 1
+	token.CreatedAt = time.Now()
+	m.tokens = append(m.tokens, token)
+	return nil
+}
+
+func (m *MockOneTimeTokenRepository) Consume(purpose entity.TokenPurpose, tokenHash string, now time.Time) (*entity.OneTimeToken, error) {
+	for _, token := range m.tokens {
+		if token.Purpose != purpose || token.TokenHash != tokenHash {
+			continue
+		}
+		if token.ConsumedAt != nil {
+			return nil, repository.ErrTokenAlreadyUsed
+		}
+		if !token.ExpiresAt.After(now) {
+			return nil, repository.ErrTokenExpired
+		}
+		token.ConsumedAt = &now
+		return token, nil
+	}
+	return nil, repository.ErrTokenNotFound
+}
+
+func TestTokenUseCase_IssueAndConsume(t *testing.T) {
+	tokenRepo := &MockOneTimeTokenRepository{}
+	useCase := NewTokenUseCase(tokenRepo)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	useCase.now = func() time.Time { return now }
+
+	token, record, err := useCase.Issue(entity.TokenPurposeEmailVerification, 7, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if len(token) != 43 || record.TokenHash == token || !record.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("Issue() = %q, %+v; want a random token stored only as a hash", token, record)
+	}
+
+	if _, err := useCase.Consume(entity.TokenPurposeInvite, token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Consume() for another purpose error = %v, want ErrInvalidToken", err)
+	}
+	consumed, err := useCase.Consume(entity.TokenPurposeEmailVerification, token)
+	if err != nil || consumed.UserID != 7 {
+		t.Fatalf("Consume() = %+v, %v", consumed, err)
+	}
+	if _, err := useCase.Consume(entity.TokenPurposeEmailVerification, token); !errors.Is(err, ErrTokenAlreadyUsed) {
+		t.Errorf("second Consume() error = %v, want ErrTokenAlreadyUsed", err)
+	}
+
+	expiring, _, _ := useCase.Issue(entity.TokenPurposeMagicLink, 7, time.Minute)
+	now = now.Add(time.Minute)
+	if _, err := useCase.Consume(entity.TokenPurposeMagicLink, expiring); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Consume() after expiry error = %v, want ErrTokenExpired", err)
+	}
+}
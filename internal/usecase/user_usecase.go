@@ -1,33 +1,227 @@
 package usecase
 
 import (
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"fiber-hello-world/internal/domain/entity"
 	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/hashpool"
+	"fiber-hello-world/pkg/hooks"
+	"fiber-hello-world/pkg/screening"
+	"fiber-hello-world/pkg/textnorm"
+	"fiber-hello-world/pkg/timing"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrEmailTaken is returned when registering an email that is already in use
+var ErrEmailTaken = repository.ErrEmailTaken
+
+// ErrAccountSuspended is returned when a suspended user signs in with valid credentials
+var ErrAccountSuspended = errors.New("account is suspended")
+
+// ErrAccountInactive is returned when a pending or deactivated user signs in
+// with valid credentials
+var ErrAccountInactive = errors.New("account is not active")
+
+// ErrInvalidStatus is returned for a status that is not an account status
+var ErrInvalidStatus = errors.New("invalid account status")
+
+// ErrInvalidPlan is returned for a plan that is not configured
+var ErrInvalidPlan = errors.New("invalid plan")
+
+// ErrIllegalStatusTransition is returned for a status change the account
+// lifecycle does not allow, such as suspending a deactivated account
+var ErrIllegalStatusTransition = entity.ErrIllegalStatusTransition
+
+// ErrVetoed is returned when a lifecycle hook rejects a registration or login
+var ErrVetoed = hooks.ErrVetoed
+
+// ErrNameRejected is returned, wrapped in a *screening.Rejection, when the
+// name screener rejects a full name
+var ErrNameRejected = screening.ErrRejected
+
+// ErrInvalidProfile is returned when a registration field breaks the
+// registration rules once normalized
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// ErrInvalidPatch is returned when a profile patch contains an unknown or invalid field
+var ErrInvalidPatch = errors.New("invalid profile patch")
+
+// errInvalidCredentials is returned by AuthenticateUser for an unknown email
+// and a wrong password alike
+var errInvalidCredentials = errors.New("invalid credentials")
+
+// dummyHash is checked against when a login names an unknown email, so it
+// takes as long as a wrong password and the timing does not reveal accounts.
+// Its password is random and it is made once per process, when the first use
+// case is created, so no login pays for generating it.
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := bcrypt.GenerateFromPassword([]byte(rand.Text()), bcrypt.DefaultCost)
+	return string(hash)
+})
+
 // UserUseCase handles user-related business logic
 type UserUseCase struct {
-	userRepo repository.UserRepository
+	userRepo     repository.UserRepository
+	revisionRepo repository.UserRevisionRepository
+	eventRepo    repository.EventRepository
+	auditRepo    repository.AuditRepository
+	hooks        *hooks.Registry
+	hashPool     *hashpool.Pool
+	screener     *screening.Screener
+	// compareHash checks passwords without a hash pool; replaced in tests
+	compareHash func(hash, password []byte) error
+	// enumerationProtection hides whether an email has an account
+	enumerationProtection bool
+	// responseFloor pads logins, registrations and reset requests
+	responseFloor *timing.Floor
+	// birthdayCalendar is the calendar birthdays are entered in
+	birthdayCalendar entity.Calendar
 }
 
 // NewUserUseCase creates a new user use case
-func NewUserUseCase(userRepo repository.UserRepository) *UserUseCase {
+func NewUserUseCase(userRepo repository.UserRepository, revisionRepo repository.UserRevisionRepository) *UserUseCase {
+	dummyHash()
 	return &UserUseCase{
-		userRepo: userRepo,
+		userRepo:     userRepo,
+		revisionRepo: revisionRepo,
+		compareHash:  bcrypt.CompareHashAndPassword,
 	}
 }
 
+// SetHooks installs the lifecycle hooks run by RegisterUser and AuthenticateUser
+func (uc *UserUseCase) SetHooks(registry *hooks.Registry) {
+	uc.hooks = registry
+}
+
+// SetEventLog records the users' lifecycle events, such as registrations,
+// logins and deletions, in the domain event log
+func (uc *UserUseCase) SetEventLog(eventRepo repository.EventRepository) {
+	uc.eventRepo = eventRepo
+}
+
+// SetAuditLog records the changes admins make to other users' accounts in
+// the audit trail
+func (uc *UserUseCase) SetAuditLog(auditRepo repository.AuditRepository) {
+	uc.auditRepo = auditRepo
+}
+
+// SetHashPool runs password hashing through pool instead of directly, to
+// bound concurrent bcrypt work
+func (uc *UserUseCase) SetHashPool(pool *hashpool.Pool) {
+	uc.hashPool = pool
+}
+
+// SetNameScreener screens full names on registration and profile updates
+func (uc *UserUseCase) SetNameScreener(screener *screening.Screener) {
+	uc.screener = screener
+}
+
+// SetEnumerationProtection makes registration behave the same whether or not
+// an email has an account, at the cost of the explicit "email already taken"
+func (uc *UserUseCase) SetEnumerationProtection(enabled bool) {
+	uc.enumerationProtection = enabled
+}
+
+// SetResponseFloor pads AuthenticateUser, RegisterUser and password reset
+// requests to a minimum duration, so the path they took cannot be timed
+func (uc *UserUseCase) SetResponseFloor(floor *timing.Floor) {
+	uc.responseFloor = floor
+}
+
+// SetBirthdayCalendar sets the calendar birthdays are entered in on
+// registration and profile updates. Birthdays are converted to ISO 8601
+// dates, so they are stored and returned the same in every calendar.
+func (uc *UserUseCase) SetBirthdayCalendar(calendar entity.Calendar) {
+	uc.birthdayCalendar = calendar
+}
+
+// EnumerationProtected reports whether responses must not reveal which
+// emails have accounts
+func (uc *UserUseCase) EnumerationProtected() bool {
+	return uc.enumerationProtection
+}
+
+// hashPassword returns the bcrypt hash of password
+func (uc *UserUseCase) hashPassword(password string) ([]byte, error) {
+	if uc.hashPool != nil {
+		return uc.hashPool.Hash([]byte(password))
+	}
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
+
+// verifyPassword compares password with the user's hash, or with the dummy
+// hash when there is no user or the user has no password, so every login does
+// one bcrypt comparison
+func (uc *UserUseCase) verifyPassword(user *entity.User, password string) error {
+	if user == nil || user.Password == "" {
+		uc.comparePassword(dummyHash(), password)
+		return errInvalidCredentials
+	}
+	if err := uc.comparePassword(user.Password, password); err != nil {
+		return errInvalidCredentials
+	}
+	return nil
+}
+
+// comparePassword returns nil if password matches the bcrypt hash
+func (uc *UserUseCase) comparePassword(hash, password string) error {
+	if uc.hashPool != nil {
+		return uc.hashPool.Compare([]byte(hash), []byte(password))
+	}
+	return uc.compareHash([]byte(hash), []byte(password))
+}
+
 // RegisterUser handles user registration logic
 func (uc *UserUseCase) RegisterUser(email, password, fullName, phoneNumber, birthday string) (*entity.User, error) {
-	// Check if user already exists
-	existingUser, err := uc.userRepo.GetByEmail(email)
-	if err == nil && existingUser != nil {
-		return nil, errors.New("user with this email already exists")
+	defer uc.responseFloor.Pad(uc.responseFloor.Now())
+	fields := map[string]string{
+		repository.FieldEmail:       email,
+		repository.FieldFullName:    fullName,
+		repository.FieldPhoneNumber: phoneNumber,
+		repository.FieldBirthday:    birthday,
+	}
+	for name, value := range fields {
+		fields[name] = normalizeField(name, value)
+	}
+	birthday, err := uc.isoBirthday(fields[repository.FieldBirthday])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProfile, err)
+	}
+	fields[repository.FieldBirthday] = birthday
+	if err := uc.runPreRegister(fields); err != nil {
+		return nil, err
+	}
+	email, fullName = fields[repository.FieldEmail], fields[repository.FieldFullName]
+	phoneNumber, birthday = fields[repository.FieldPhoneNumber], fields[repository.FieldBirthday]
+
+	// Normalizing can shorten fields the request was validated with
+	for _, name := range []string{repository.FieldEmail, repository.FieldFullName, repository.FieldPhoneNumber} {
+		if err := validateField(name, fields[name]); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidProfile, err)
+		}
+	}
+	if err := uc.screenName(fullName); err != nil {
+		return nil, err
+	}
+
+	// Cheap pre-check to skip hashing for obvious duplicates; the unique
+	// constraint enforced by Create remains the source of truth under races.
+	// With enumeration protection duplicates are hashed too, so they take
+	// as long as a new account.
+	exists, err := uc.userRepo.ExistsByEmail(email)
+	if err == nil && exists && !uc.enumerationProtection {
+		return nil, ErrEmailTaken
 	}
 
 	// Validate birthday format
@@ -37,7 +231,7 @@ func (uc *UserUseCase) RegisterUser(email, password, fullName, phoneNumber, birt
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := uc.hashPassword(password)
 	if err != nil {
 		return nil, errors.New("failed to hash password")
 	}
@@ -47,30 +241,125 @@ func (uc *UserUseCase) RegisterUser(email, password, fullName, phoneNumber, birt
 
 	// Save user to repository
 	savedUser, err := uc.userRepo.Create(user)
+	if errors.Is(err, repository.ErrEmailTaken) {
+		return nil, ErrEmailTaken
+	}
 	if err != nil {
 		return nil, errors.New("failed to save user")
 	}
 
+	uc.hooks.Run(&hooks.Event{Point: hooks.PostRegister, UserID: savedUser.ID, Email: savedUser.Email})
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventUserRegistered, entity.EventSubjectUser, savedUser.ID, savedUser.ID,
+		map[string]interface{}{"email": savedUser.Email}))
+
 	return savedUser.WithoutPassword(), nil
 }
 
+// isoBirthday converts a birthday entered in the Buddhist calendar to an ISO
+// 8601 date. Gregorian birthdays are returned as they are and checked with
+// the other fields.
+func (uc *UserUseCase) isoBirthday(value string) (string, error) {
+	if uc.birthdayCalendar != entity.CalendarBuddhist {
+		return value, nil
+	}
+	birthday, err := entity.ParseBirthday(value, uc.birthdayCalendar)
+	if err != nil {
+		return "", err
+	}
+	return birthday.String(), nil
+}
+
+// runPreRegister runs the PreRegister hooks and applies the fields they
+// changed, which must still pass the registration rules
+func (uc *UserUseCase) runPreRegister(fields map[string]string) error {
+	data := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		data[name] = value
+	}
+
+	event := &hooks.Event{Point: hooks.PreRegister, Email: fields[repository.FieldEmail], Data: data}
+	if err := uc.hooks.Run(event); err != nil {
+		return err
+	}
+
+	for name, original := range fields {
+		value, ok := event.Data[name].(string)
+		if !ok {
+			continue
+		}
+		if value = normalizeField(name, value); value == original {
+			continue
+		}
+		if err := validateField(name, value); err != nil {
+			return fmt.Errorf("pre-register hook set an invalid %s", name)
+		}
+		fields[name] = value
+	}
+	return nil
+}
+
+// screenName checks a full name with the name screener, if one is set
+func (uc *UserUseCase) screenName(fullName string) error {
+	if uc.screener == nil {
+		return nil
+	}
+	return uc.screener.Screen(fullName)
+}
+
 // AuthenticateUser handles user authentication
 func (uc *UserUseCase) AuthenticateUser(email, password string) (*entity.User, error) {
-	// Find user by email
+	defer uc.responseFloor.Pad(uc.responseFloor.Now())
+	email = textnorm.Identifier(email)
+	if err := uc.hooks.Run(&hooks.Event{Point: hooks.PreLogin, Email: email}); err != nil {
+		return nil, err
+	}
+
+	// Unknown emails, cached or not, are checked against the dummy hash
 	user, err := uc.userRepo.GetByEmail(email)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		user = nil
+	}
+	if err := uc.verifyPassword(user, password); err != nil {
+		return nil, err
 	}
 
-	// Check password
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
-	if err != nil {
-		return nil, errors.New("invalid credentials")
+	// Checked after the password so the status is not revealed to guessers
+	if err := CheckCanSignIn(user); err != nil {
+		return nil, err
+	}
+
+	if err := uc.hooks.Run(&hooks.Event{Point: hooks.PostLogin, UserID: user.ID, Email: user.Email}); err != nil {
+		return nil, err
 	}
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventUserLoggedIn, entity.EventSubjectUser, user.ID, user.ID,
+		map[string]interface{}{"method": "password"}))
 
 	return user, nil
 }
 
+// AuthenticateUserByID signs in a user who proved who they are without a
+// password, such as by approving a QR login on a signed-in device. Suspended
+// users are rejected and the login hooks run as for AuthenticateUser.
+func (uc *UserUseCase) AuthenticateUserByID(id int) (*entity.User, error) {
+	user, err := uc.userRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if err := uc.hooks.Run(&hooks.Event{Point: hooks.PreLogin, Email: user.Email}); err != nil {
+		return nil, err
+	}
+	if err := CheckCanSignIn(user); err != nil {
+		return nil, err
+	}
+	if err := uc.hooks.Run(&hooks.Event{Point: hooks.PostLogin, UserID: user.ID, Email: user.Email}); err != nil {
+		return nil, err
+	}
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventUserLoggedIn, entity.EventSubjectUser, user.ID, user.ID,
+		map[string]interface{}{"method": "passwordless"}))
+
+	return user.WithoutPassword(), nil
+}
+
 // GetUserByID retrieves user by ID
 func (uc *UserUseCase) GetUserByID(id int) (*entity.User, error) {
 	user, err := uc.userRepo.GetByID(id)
@@ -80,3 +369,333 @@ func (uc *UserUseCase) GetUserByID(id int) (*entity.User, error) {
 
 	return user.WithoutPassword(), nil
 }
+
+// GetUserByEmail retrieves user by email
+func (uc *UserUseCase) GetUserByEmail(email string) (*entity.User, error) {
+	user, err := uc.userRepo.GetByEmail(textnorm.Identifier(email))
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	return user.WithoutPassword(), nil
+}
+
+// ChangePassword verifies the current password and stores a hash of the new one
+func (uc *UserUseCase) ChangePassword(id int, currentPassword, newPassword string) error {
+	user, err := uc.userRepo.GetByID(id)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := uc.comparePassword(user.Password, currentPassword); err != nil {
+		return errors.New("invalid credentials")
+	}
+
+	return uc.setPassword(user, newPassword)
+}
+
+// SetPassword stores a hash of the user's new password without checking the
+// current one, e.g. after a password reset
+func (uc *UserUseCase) SetPassword(id int, newPassword string) error {
+	user, err := uc.userRepo.GetByID(id)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	return uc.setPassword(user, newPassword)
+}
+
+// setPassword stores a hash of newPassword for user and records the change as made by the user
+func (uc *UserUseCase) setPassword(user *entity.User, newPassword string) error {
+	hashedPassword, err := uc.hashPassword(newPassword)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	if err := uc.userRepo.UpdatePassword(user.ID, string(hashedPassword)); err != nil {
+		return errors.New("failed to update password")
+	}
+
+	after := *user
+	after.Password = string(hashedPassword)
+	recordRevision(uc.revisionRepo, user.ID, user, &after)
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventUserPasswordChanged, entity.EventSubjectUser, user.ID, user.ID, nil))
+
+	return nil
+}
+
+// PatchUser applies a JSON Merge Patch to the user's profile. A nil value
+// removes the field, which is only allowed for the avatar, time zone and
+// national ID. Only the patched fields are written.
+func (uc *UserUseCase) PatchUser(id int, patch map[string]*string) (*entity.User, error) {
+	fields := make(map[string]interface{}, len(patch))
+	for name, value := range patch {
+		if value == nil {
+			if name != repository.FieldAvatar && name != repository.FieldTimezone && name != repository.FieldNationalID {
+				return nil, fmt.Errorf("%w: %s cannot be removed", ErrInvalidPatch, name)
+			}
+			fields[name] = ""
+			continue
+		}
+
+		normalized := normalizeField(name, *value)
+		if name == repository.FieldBirthday {
+			birthday, err := uc.isoBirthday(normalized)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+			}
+			normalized = birthday
+		}
+		if err := validatePatchField(name, normalized); err != nil {
+			return nil, err
+		}
+		if name == repository.FieldFullName {
+			if err := uc.screenName(normalized); err != nil {
+				return nil, err
+			}
+		}
+		fields[name] = normalized
+	}
+
+	user, err := uc.updateFields(id, id, fields)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventUserUpdated, entity.EventSubjectUser, id, id,
+		map[string]interface{}{"fields": names}))
+	return user, nil
+}
+
+// CheckCanSignIn returns ErrAccountSuspended or ErrAccountInactive for a
+// user whose account status does not allow signing in
+func CheckCanSignIn(user *entity.User) error {
+	switch {
+	case user.CanSignIn():
+		return nil
+	case user.Status == entity.StatusSuspended:
+		return ErrAccountSuspended
+	}
+	return ErrAccountInactive
+}
+
+// SuspendUser sets a user's status to suspended on behalf of actorID
+func (uc *UserUseCase) SuspendUser(actorID, id int) error {
+	_, err := uc.ChangeStatus(actorID, id, entity.StatusSuspended)
+	return err
+}
+
+// ChangeStatus moves a user's account to status on behalf of actorID, as
+// the account lifecycle allows, and records the event of the transition.
+// Moving to the deleted status deletes the user.
+func (uc *UserUseCase) ChangeStatus(actorID, id int, status string) (*entity.User, error) {
+	if !entity.IsValidStatus(status) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+	}
+	if status == entity.StatusDeleted {
+		return nil, uc.DeleteUser(actorID, id)
+	}
+
+	return uc.updateFields(actorID, id, map[string]interface{}{repository.FieldStatus: status})
+}
+
+// SetUserRole changes a user's role on behalf of actorID
+func (uc *UserUseCase) SetUserRole(actorID, id int, role string) error {
+	if role != entity.RoleUser && role != entity.RoleAdmin {
+		return errors.New("invalid role")
+	}
+
+	if _, err := uc.updateFields(actorID, id, map[string]interface{}{repository.FieldRole: role}); err != nil {
+		return err
+	}
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventUserRoleChanged, entity.EventSubjectUser, id, actorID,
+		map[string]interface{}{"role": role}))
+	return nil
+}
+
+// SetUserPlan changes a user's plan on behalf of actorID, recording source,
+// "admin" or the billing provider, in the event. Setting the current plan
+// again changes nothing and records no event.
+func (uc *UserUseCase) SetUserPlan(actorID, id int, plan, source string) (*entity.User, error) {
+	if plan == "" {
+		return nil, ErrInvalidPlan
+	}
+	before, err := uc.userRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	from := before.Plan
+	if from == plan {
+		return before, nil
+	}
+
+	user, err := uc.updateFields(actorID, id, map[string]interface{}{repository.FieldPlan: plan})
+	if err != nil {
+		return nil, err
+	}
+	recordEvent(uc.eventRepo, entity.NewDomainEvent(entity.EventUserPlanChanged, entity.EventSubjectUser, id, actorID,
+		map[string]interface{}{"plan": plan, "from": from, "source": source}))
+	return user, nil
+}
+
+// updateFields writes the given fields and records the change as made by
+// actorID. A status change must be allowed by the account lifecycle and
+// records the event of the transition.
+func (uc *UserUseCase) updateFields(actorID, id int, fields map[string]interface{}) (*entity.User, error) {
+	before, err := uc.userRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	var event *entity.DomainEvent
+	if status, ok := fields[repository.FieldStatus].(string); ok {
+		user := *before
+		if event, err = user.TransitionTo(status, actorID); err != nil {
+			return nil, err
+		}
+	}
+
+	err = uc.userRepo.UpdateFields(id, fields)
+	if errors.Is(err, repository.ErrEmailTaken) {
+		return nil, ErrEmailTaken
+	}
+	if err != nil {
+		return nil, errors.New("failed to update user")
+	}
+
+	after, err := uc.userRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	recordRevision(uc.revisionRepo, actorID, before, after)
+	uc.recordAdminChange(actorID, id, entity.AuditUserUpdated, entity.DiffUsers(before, after))
+	if event != nil {
+		recordEvent(uc.eventRepo, event)
+	}
+
+	return after.WithoutPassword(), nil
+}
+
+// DeleteUser removes a user on behalf of actorID, keeping a final snapshot in the history
+func (uc *UserUseCase) DeleteUser(actorID, id int) error {
+	user, err := uc.userRepo.GetByID(id)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	before := *user
+	event, err := user.TransitionTo(entity.StatusDeleted, actorID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.userRepo.Delete(id); err != nil {
+		return errors.New("failed to delete user")
+	}
+
+	recordRevision(uc.revisionRepo, actorID, &before, nil)
+	uc.recordAdminChange(actorID, id, entity.AuditUserDeleted, []entity.FieldChange{{Field: "status", Old: before.Status, New: entity.StatusDeleted}})
+	recordEvent(uc.eventRepo, event)
+	return nil
+}
+
+// recordAdminChange records a change to a user in the audit trail when an
+// admin made it, i.e. neither the user nor the system
+func (uc *UserUseCase) recordAdminChange(actorID, id int, action entity.AuditAction, changes []entity.FieldChange) {
+	if actorID == 0 || actorID == id {
+		return
+	}
+	recordAudit(uc.auditRepo, entity.NewAuditEntry(actorID, action, entity.EventSubjectUser, strconv.Itoa(id), changes))
+}
+
+// GetUserHistory returns the recorded revisions of a user, newest first
+func (uc *UserUseCase) GetUserHistory(id int, page repository.Page) ([]*entity.UserRevision, error) {
+	revisions, err := uc.revisionRepo.ListByUser(id, page)
+	if err != nil {
+		return nil, errors.New("failed to load user history")
+	}
+
+	return revisions, nil
+}
+
+// recordRevision stores the change from before to after (nil for a delete).
+// The change is already persisted, so a failure is logged rather than returned.
+func recordRevision(revisionRepo repository.UserRevisionRepository, actorID int, before, after *entity.User) {
+	revision := entity.NewUserRevision(actorID, before, after)
+	if revision.Action == entity.RevisionActionUpdate && len(revision.Changes) == 0 {
+		return
+	}
+
+	if err := revisionRepo.Record(revision); err != nil {
+		log.Printf("Failed to record revision for user %d: %v", before.ID, err)
+	}
+}
+
+// normalizeField puts a profile field in its canonical form: names in NFC,
+// machine-compared fields in NFKC, both without invisible characters.
+// National IDs also lose their dashes and spaces.
+func normalizeField(name, value string) string {
+	switch name {
+	case repository.FieldFullName:
+		return textnorm.Text(value)
+	case repository.FieldNationalID:
+		return entity.NormalizeNationalID(textnorm.Identifier(value))
+	}
+	return textnorm.Identifier(value)
+}
+
+// validatePatchField applies the registration rules to a single patched
+// field. Emails cannot be patched: nothing verifies that the user owns a new
+// address.
+func validatePatchField(name, value string) error {
+	if name == repository.FieldEmail {
+		return fmt.Errorf("%w: email cannot be changed", ErrInvalidPatch)
+	}
+	if err := validateField(name, value); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return nil
+}
+
+// validateField applies the registration rules to a single normalized
+// field. Lengths are counted in runes.
+func validateField(name, value string) error {
+	length := utf8.RuneCountInString(value)
+	switch name {
+	case repository.FieldEmail:
+		if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+			return errors.New("email must be a valid email address")
+		}
+		if length > entity.MaxEmailLength {
+			return fmt.Errorf("email must be at most %d characters", entity.MaxEmailLength)
+		}
+	case repository.FieldFullName:
+		if length < 2 || length > entity.MaxFullNameLength {
+			return fmt.Errorf("fullName must be 2 to %d characters", entity.MaxFullNameLength)
+		}
+	case repository.FieldPhoneNumber:
+		if length < 10 || length > entity.MaxPhoneNumberLength {
+			return fmt.Errorf("phoneNumber must be 10 to %d characters", entity.MaxPhoneNumberLength)
+		}
+	case repository.FieldBirthday:
+		if _, err := entity.ParseBirthday(value, entity.CalendarGregorian); err != nil {
+			return err
+		}
+	case repository.FieldAvatar:
+		// Avatars are uploaded through their own endpoint and can only be removed here
+		return errors.New("avatar can only be set to null")
+	case repository.FieldTimezone:
+		if _, err := time.LoadLocation(value); err != nil || value == "" || value == "Local" {
+			return errors.New("timezone must be an IANA time zone such as Asia/Bangkok")
+		}
+	case repository.FieldNationalID:
+		if !entity.ValidThaiNationalID(value) {
+			return errors.New("nationalId must be a valid 13-digit Thai national ID")
+		}
+	default:
+		return fmt.Errorf("%s cannot be changed", name)
+	}
+	return nil
+}
@@ -2,9 +2,16 @@ package usecase
 
 import (
 	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/hooks"
+	"fiber-hello-world/pkg/screening"
+	"fiber-hello-world/pkg/timing"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -24,7 +31,7 @@ func NewMockUserRepository() *MockUserRepository {
 
 func (m *MockUserRepository) Create(user *entity.User) (*entity.User, error) {
 	if _, exists := m.users[user.Email]; exists {
-		return nil, errors.New("user already exists")
+		return nil, repository.ErrEmailTaken
 	}
 
 	user.ID = m.nextID
@@ -40,10 +47,16 @@ func (m *MockUserRepository) GetByEmail(email string) (*entity.User, error) {
 	return nil, errors.New("user not found")
 }
 
+func (m *MockUserRepository) ExistsByEmail(email string) (bool, error) {
+	_, exists := m.users[email]
+	return exists, nil
+}
+
 func (m *MockUserRepository) GetByID(id int) (*entity.User, error) {
 	for _, user := range m.users {
 		if user.ID == id {
-			return user, nil
+			found := *user
+			return &found, nil
 		}
 	}
 	return nil, errors.New("user not found")
@@ -57,6 +70,76 @@ func (m *MockUserRepository) Update(user *entity.User) error {
 	return nil
 }
 
+func (m *MockUserRepository) findByID(id int) (*entity.User, error) {
+	for _, user := range m.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (m *MockUserRepository) UpdatePassword(id int, hash string) error {
+	user, err := m.findByID(id)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+	return nil
+}
+
+func (m *MockUserRepository) UpdateFields(id int, fields map[string]interface{}) error {
+	user, err := m.findByID(id)
+	if err != nil {
+		return err
+	}
+	for name, value := range fields {
+		str, _ := value.(string)
+		switch name {
+		case repository.FieldEmail:
+			if other, exists := m.users[str]; exists && other.ID != id {
+				return repository.ErrEmailTaken
+			}
+			delete(m.users, user.Email)
+			user.Email = str
+			m.users[str] = user
+		case repository.FieldFullName:
+			user.FullName = str
+		case repository.FieldPhoneNumber:
+			user.PhoneNumber = str
+		case repository.FieldBirthday:
+			user.Birthday = str
+		case repository.FieldAvatar:
+			user.Avatar = str
+		case repository.FieldRole:
+			user.Role = str
+		case repository.FieldStatus:
+			user.Status = str
+		case repository.FieldPlan:
+			user.Plan = str
+		case repository.FieldTimezone:
+			user.Timezone = str
+		case repository.FieldNationalID:
+			user.NationalID = str
+		case repository.FieldKYCStatus:
+			user.KYCStatus = str
+		}
+	}
+	return nil
+}
+
+func (m *MockUserRepository) List(filter repository.UserFilter, page repository.Page) ([]*entity.User, error) {
+	var users []*entity.User
+	for _, user := range m.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (m *MockUserRepository) Count(filter repository.UserFilter) (int, error) {
+	return len(m.users), nil
+}
+
 func (m *MockUserRepository) Delete(id int) error {
 	for email, user := range m.users {
 		if user.ID == id {
@@ -67,9 +150,52 @@ func (m *MockUserRepository) Delete(id int) error {
 	return errors.New("user not found")
 }
 
+// Mock revision repository for testing
+type MockUserRevisionRepository struct {
+	revisions []*entity.UserRevision
+	err       error
+}
+
+func NewMockUserRevisionRepository() *MockUserRevisionRepository {
+	return &MockUserRevisionRepository{}
+}
+
+func (m *MockUserRevisionRepository) Record(revision *entity.UserRevision) error {
+	if m.err != nil {
+		return m.err
+	}
+	revision.ID = len(m.revisions) + 1
+	m.revisions = append(m.revisions, revision)
+	return nil
+}
+
+func (m *MockUserRevisionRepository) ListByUser(userID int, page repository.Page) ([]*entity.UserRevision, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var revisions []*entity.UserRevision
+	for i := len(m.revisions) - 1; i >= 0; i-- {
+		if m.revisions[i].UserID == userID {
+			revisions = append(revisions, m.revisions[i])
+		}
+	}
+	return revisions, nil
+}
+
+func (m *MockUserRevisionRepository) List(page repository.Page) ([]*entity.UserRevision, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	page = page.Normalize()
+	if page.Offset >= len(m.revisions) {
+		return nil, nil
+	}
+	return m.revisions[page.Offset:min(page.Offset+page.Limit, len(m.revisions))], nil
+}
+
 func TestNewUserUseCase(t *testing.T) {
 	mockRepo := NewMockUserRepository()
-	useCase := NewUserUseCase(mockRepo)
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
 
 	if useCase == nil {
 		t.Fatal("NewUserUseCase() returned nil")
@@ -124,7 +250,7 @@ func TestUserUseCase_RegisterUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := NewMockUserRepository()
-			useCase := NewUserUseCase(mockRepo)
+			useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
 
 			user, err := useCase.RegisterUser(tt.email, tt.password, tt.fullName, tt.phoneNumber, tt.birthday)
 
@@ -168,7 +294,7 @@ func TestUserUseCase_RegisterUser(t *testing.T) {
 
 func TestUserUseCase_RegisterUser_DuplicateEmail(t *testing.T) {
 	mockRepo := NewMockUserRepository()
-	useCase := NewUserUseCase(mockRepo)
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
 
 	// Register first user
 	_, err := useCase.RegisterUser("test@example.com", "password123", "John Doe", "0812345678", "1990-01-15")
@@ -188,7 +314,7 @@ func TestUserUseCase_RegisterUser_DuplicateEmail(t *testing.T) {
 
 func TestUserUseCase_AuthenticateUser(t *testing.T) {
 	mockRepo := NewMockUserRepository()
-	useCase := NewUserUseCase(mockRepo)
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
 
 	// First, register a user
 	email := "auth@example.com"
@@ -226,9 +352,162 @@ func TestUserUseCase_AuthenticateUser(t *testing.T) {
 	}
 }
 
+func TestUserUseCase_AuthenticateUser_Suspended(t *testing.T) {
+	mockRepo := NewMockUserRepository()
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
+
+	registered, err := useCase.RegisterUser("suspended@example.com", "password123", "Suspended User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	if err := useCase.SuspendUser(1, registered.ID); err != nil {
+		t.Fatalf("SuspendUser() error = %v", err)
+	}
+
+	if _, err := useCase.AuthenticateUser("suspended@example.com", "password123"); !errors.Is(err, ErrAccountSuspended) {
+		t.Errorf("AuthenticateUser() error = %v, want ErrAccountSuspended", err)
+	}
+
+	// A wrong password still reports invalid credentials
+	if _, err := useCase.AuthenticateUser("suspended@example.com", "wrongpassword"); err == nil || err.Error() != "invalid credentials" {
+		t.Errorf("AuthenticateUser() error = %v, want 'invalid credentials'", err)
+	}
+}
+
+// CachedMissUserRepository answers email lookups of unregistered emails as
+// misses remembered by a cache, without a query
+type CachedMissUserRepository struct {
+	*MockUserRepository
+}
+
+func (m *CachedMissUserRepository) GetByEmail(email string) (*entity.User, error) {
+	user, err := m.MockUserRepository.GetByEmail(email)
+	if err != nil {
+		return nil, repository.ErrUserNotFoundCached
+	}
+	return user, nil
+}
+
+// countComparisons makes useCase count its password comparisons, still
+// checking them with bcrypt
+func countComparisons(useCase *UserUseCase) *[]string {
+	var hashes []string
+	useCase.compareHash = func(hash, password []byte) error {
+		hashes = append(hashes, string(hash))
+		return bcrypt.CompareHashAndPassword(hash, password)
+	}
+	return &hashes
+}
+
+func TestUserUseCase_AuthenticateUser_AlwaysCompares(t *testing.T) {
+	useCase := NewUserUseCase(&CachedMissUserRepository{NewMockUserRepository()}, NewMockUserRevisionRepository())
+	if _, err := useCase.RegisterUser("known@example.com", "password123", "Known User", "0812345678", "1990-01-15"); err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	user, _ := useCase.userRepo.GetByEmail("known@example.com")
+	hashes := countComparisons(useCase)
+
+	// A cached miss costs a bcrypt comparison like a wrong password
+	tests := []struct {
+		email, password string
+		hash            string
+		wantErr         bool
+	}{
+		{"unknown@example.com", "password123", dummyHash(), true},
+		{"known@example.com", "wrongpassword", user.Password, true},
+		{"known@example.com", "password123", user.Password, false},
+	}
+	for _, tt := range tests {
+		*hashes = nil
+		_, err := useCase.AuthenticateUser(tt.email, tt.password)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("AuthenticateUser(%q, %q) error = %v, wantErr %v", tt.email, tt.password, err, tt.wantErr)
+		}
+		if len(*hashes) != 1 || (*hashes)[0] != tt.hash {
+			t.Errorf("AuthenticateUser(%q, %q) compared against %d hashes, want one: %q", tt.email, tt.password, len(*hashes), tt.hash)
+		}
+	}
+}
+
+func TestUserUseCase_AuthenticateUser_ResponseFloor(t *testing.T) {
+	useCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	if _, err := useCase.RegisterUser("timing@example.com", "password123", "Timing User", "0812345678", "1990-01-15"); err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	hashes := countComparisons(useCase)
+
+	// The clock stands still apart from the floor's sleeps, so each login is
+	// padded by the whole floor
+	floor := timing.New(150*time.Millisecond, 10*time.Millisecond)
+	now := time.Unix(0, 0)
+	var slept []time.Duration
+	floor.SetClock(func() time.Time { return now }, func(d time.Duration) {
+		slept = append(slept, d)
+		now = now.Add(d)
+	})
+	useCase.SetResponseFloor(floor)
+
+	// Unknown emails and wrong passwords take different paths; both do one
+	// comparison and are padded to the floor
+	for _, email := range []string{"timing@example.com", "nobody@example.com"} {
+		*hashes, slept = nil, nil
+		if _, err := useCase.AuthenticateUser(email, "wrongpassword"); err == nil {
+			t.Errorf("AuthenticateUser(%q) with a wrong password should fail", email)
+		}
+		if len(*hashes) != 1 {
+			t.Errorf("AuthenticateUser(%q) compared %d passwords, want 1", email, len(*hashes))
+		}
+		if len(slept) != 1 || slept[0] < floor.Min() || slept[0] >= floor.Min()+floor.Jitter() {
+			t.Errorf("AuthenticateUser(%q) slept %v, want once between %v and %v", email, slept, floor.Min(), floor.Min()+floor.Jitter())
+		}
+	}
+}
+
+func TestUserUseCase_ChangeStatus(t *testing.T) {
+	events := &MockEventRepository{}
+	useCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	useCase.SetEventLog(events)
+
+	registered, err := useCase.RegisterUser("status@example.com", "password123", "Status User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	user, err := useCase.ChangeStatus(9, registered.ID, entity.StatusDeactivated)
+	if err != nil || user.Status != entity.StatusDeactivated {
+		t.Fatalf("ChangeStatus() = %+v, %v", user, err)
+	}
+	if _, err := useCase.AuthenticateUser("status@example.com", "password123"); !errors.Is(err, ErrAccountInactive) {
+		t.Errorf("AuthenticateUser() of a deactivated user error = %v, want ErrAccountInactive", err)
+	}
+
+	// Illegal jumps are rejected and change nothing
+	if _, err := useCase.ChangeStatus(9, registered.ID, entity.StatusSuspended); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Errorf("ChangeStatus() to suspended error = %v, want ErrIllegalStatusTransition", err)
+	}
+	if _, err := useCase.ChangeStatus(9, registered.ID, "archived"); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("ChangeStatus() to an unknown status error = %v, want ErrInvalidStatus", err)
+	}
+
+	if _, err := useCase.ChangeStatus(9, registered.ID, entity.StatusActive); err != nil {
+		t.Fatalf("ChangeStatus() to active error = %v", err)
+	}
+	if _, err := useCase.AuthenticateUser("status@example.com", "password123"); err != nil {
+		t.Errorf("AuthenticateUser() after reactivation error = %v", err)
+	}
+
+	want := []entity.EventType{entity.EventUserRegistered, entity.EventUserDeactivated, entity.EventUserActivated, entity.EventUserLoggedIn}
+	if got := events.types(); !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	if event := events.events[1]; event.ActorID != 9 || event.Data["from"] != entity.StatusActive {
+		t.Errorf("user.deactivated = %+v", event)
+	}
+}
+
 func TestUserUseCase_GetUserByID(t *testing.T) {
 	mockRepo := NewMockUserRepository()
-	useCase := NewUserUseCase(mockRepo)
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
 
 	// Register a user first
 	registeredUser, err := useCase.RegisterUser("get@example.com", "password123", "Get User", "0812345678", "1990-01-15")
@@ -261,9 +540,31 @@ func TestUserUseCase_GetUserByID(t *testing.T) {
 	}
 }
 
+func TestUserUseCase_GetUserByEmail(t *testing.T) {
+	mockRepo := NewMockUserRepository()
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
+
+	registeredUser, err := useCase.RegisterUser("service@example.com", "password123", "Service Account", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	user, err := useCase.GetUserByEmail("service@example.com")
+	if err != nil {
+		t.Fatalf("GetUserByEmail() error = %v", err)
+	}
+	if user.ID != registeredUser.ID || user.Password != "" {
+		t.Errorf("GetUserByEmail() = %+v", user)
+	}
+
+	if _, err := useCase.GetUserByEmail("missing@example.com"); err == nil || err.Error() != "user not found" {
+		t.Errorf("GetUserByEmail() error = %v, want 'user not found'", err)
+	}
+}
+
 func TestUserUseCase_RegisterUser_PasswordHashing(t *testing.T) {
 	mockRepo := NewMockUserRepository()
-	useCase := NewUserUseCase(mockRepo)
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
 
 	email := "hash@example.com"
 	password := "plaintextpassword"
@@ -307,7 +608,7 @@ func TestUserUseCase_RegisterUser_RepositoryError(t *testing.T) {
 		MockUserRepository: NewMockUserRepository(),
 	}
 
-	useCase := NewUserUseCase(mockRepo)
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
 
 	_, err := useCase.RegisterUser("repo@example.com", "password123", "Repo User", "0812345678", "1990-01-15")
 	if err == nil {
@@ -317,3 +618,458 @@ func TestUserUseCase_RegisterUser_RepositoryError(t *testing.T) {
 		t.Errorf("RegisterUser() error = %v, want 'failed to save user'", err.Error())
 	}
 }
+
+// Mock repository whose existence check misses a concurrently created user,
+// simulating two signups racing for the same email
+type RacingMockUserRepository struct {
+	*MockUserRepository
+}
+
+func (m *RacingMockUserRepository) ExistsByEmail(email string) (bool, error) {
+	return false, nil
+}
+
+func TestUserUseCase_RegisterUser_ConcurrentDuplicate(t *testing.T) {
+	mockRepo := &RacingMockUserRepository{
+		MockUserRepository: NewMockUserRepository(),
+	}
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
+
+	if _, err := useCase.RegisterUser("race@example.com", "password123", "First", "0812345678", "1990-01-15"); err != nil {
+		t.Fatalf("First registration failed: %v", err)
+	}
+
+	_, err := useCase.RegisterUser("race@example.com", "password456", "Second", "0812345678", "1990-01-15")
+	if !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("RegisterUser() error = %v, want ErrEmailTaken", err)
+	}
+}
+
+func TestUserUseCase_ChangePassword(t *testing.T) {
+	mockRepo := NewMockUserRepository()
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
+
+	registered, err := useCase.RegisterUser("change@example.com", "password123", "Change User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	// Wrong current password
+	err = useCase.ChangePassword(registered.ID, "wrongpassword", "newpassword")
+	if err == nil || err.Error() != "invalid credentials" {
+		t.Errorf("ChangePassword() error = %v, want 'invalid credentials'", err)
+	}
+
+	// Successful change
+	if err := useCase.ChangePassword(registered.ID, "password123", "newpassword"); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if _, err := useCase.AuthenticateUser("change@example.com", "newpassword"); err != nil {
+		t.Errorf("AuthenticateUser() with new password error = %v", err)
+	}
+	if _, err := useCase.AuthenticateUser("change@example.com", "password123"); err == nil {
+		t.Error("AuthenticateUser() should reject the old password")
+	}
+
+	// Unknown user
+	err = useCase.ChangePassword(999, "password123", "newpassword")
+	if err == nil || err.Error() != "user not found" {
+		t.Errorf("ChangePassword() error = %v, want 'user not found'", err)
+	}
+}
+
+// Mock repository that counts Create calls
+type CountingMockUserRepository struct {
+	*MockUserRepository
+	creates int
+}
+
+func (m *CountingMockUserRepository) Create(user *entity.User) (*entity.User, error) {
+	m.creates++
+	return m.MockUserRepository.Create(user)
+}
+
+func TestUserUseCase_RegisterUser_EnumerationProtection(t *testing.T) {
+	mockRepo := &CountingMockUserRepository{MockUserRepository: NewMockUserRepository()}
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
+	useCase.SetEnumerationProtection(true)
+
+	if _, err := useCase.RegisterUser("taken@example.com", "password123", "John Doe", "0812345678", "1990-01-15"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Duplicates are hashed and rejected by Create like a new account would be saved
+	_, err := useCase.RegisterUser("taken@example.com", "password456", "Jane Doe", "0898765432", "1985-05-20")
+	if !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("RegisterUser() error = %v, want ErrEmailTaken", err)
+	}
+	if mockRepo.creates != 2 {
+		t.Errorf("Create() called %d times, want 2 so duplicates cost the same as new accounts", mockRepo.creates)
+	}
+}
+
+// Mock repository that always fails on UpdatePassword
+type FailingPasswordMockUserRepository struct {
+	*MockUserRepository
+}
+
+func (m *FailingPasswordMockUserRepository) UpdatePassword(id int, hash string) error {
+	return errors.New("database error")
+}
+
+func TestUserUseCase_ChangePassword_RepositoryError(t *testing.T) {
+	mockRepo := &FailingPasswordMockUserRepository{
+		MockUserRepository: NewMockUserRepository(),
+	}
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
+
+	registered, err := useCase.RegisterUser("fail@example.com", "password123", "Fail User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	err = useCase.ChangePassword(registered.ID, "password123", "newpassword")
+	if err == nil || err.Error() != "failed to update password" {
+		t.Errorf("ChangePassword() error = %v, want 'failed to update password'", err)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestUserUseCase_PatchUser(t *testing.T) {
+	tests := []struct {
+		name        string
+		patch       map[string]*string
+		expectError error
+		check       func(t *testing.T, user *entity.User)
+	}{
+		{
+			name:  "single field",
+			patch: map[string]*string{"fullName": strPtr("Patched Name")},
+			check: func(t *testing.T, user *entity.User) {
+				if user.FullName != "Patched Name" {
+					t.Errorf("FullName = %v, want Patched Name", user.FullName)
+				}
+				if user.PhoneNumber != "0812345678" || user.Birthday != "1990-01-15" {
+					t.Errorf("unpatched fields changed, got %+v", user)
+				}
+			},
+		},
+		{
+			name:  "remove avatar",
+			patch: map[string]*string{"avatar": nil},
+			check: func(t *testing.T, user *entity.User) {
+				if user.Avatar != "" {
+					t.Errorf("Avatar = %v, want empty", user.Avatar)
+				}
+			},
+		},
+		{
+			name:  "set timezone",
+			patch: map[string]*string{"timezone": strPtr("Asia/Bangkok")},
+			check: func(t *testing.T, user *entity.User) {
+				if user.Timezone != "Asia/Bangkok" {
+					t.Errorf("Timezone = %v, want Asia/Bangkok", user.Timezone)
+				}
+			},
+		},
+		{
+			name:  "set national id",
+			patch: map[string]*string{"nationalId": strPtr("1-1017-00230-70-8")},
+			check: func(t *testing.T, user *entity.User) {
+				if user.NationalID != "1101700230708" {
+					t.Errorf("NationalID = %v, want 1101700230708", user.NationalID)
+				}
+			},
+		},
+		{
+			name:  "remove national id",
+			patch: map[string]*string{"nationalId": nil},
+			check: func(t *testing.T, user *entity.User) {
+				if user.NationalID != "" {
+					t.Errorf("NationalID = %v, want empty", user.NationalID)
+				}
+			},
+		},
+		{
+			name:  "empty patch",
+			patch: map[string]*string{},
+			check: func(t *testing.T, user *entity.User) {
+				if user.FullName != "Patch User" {
+					t.Errorf("FullName = %v, want Patch User", user.FullName)
+				}
+			},
+		},
+		{name: "remove required field", patch: map[string]*string{"fullName": nil}, expectError: ErrInvalidPatch},
+		{name: "email not patchable", patch: map[string]*string{"email": strPtr("new@example.com")}, expectError: ErrInvalidPatch},
+		{name: "short phone", patch: map[string]*string{"phoneNumber": strPtr("123")}, expectError: ErrInvalidPatch},
+		{name: "invalid birthday", patch: map[string]*string{"birthday": strPtr("1990/01/15")}, expectError: ErrInvalidPatch},
+		{name: "unknown timezone", patch: map[string]*string{"timezone": strPtr("Mars/Olympus")}, expectError: ErrInvalidPatch},
+		{name: "national id check digit", patch: map[string]*string{"nationalId": strPtr("1101700230707")}, expectError: ErrInvalidPatch},
+		{name: "set avatar", patch: map[string]*string{"avatar": strPtr("/uploads/x.png")}, expectError: ErrInvalidPatch},
+		{name: "password not patchable", patch: map[string]*string{"password": strPtr("newpassword")}, expectError: ErrInvalidPatch},
+		{name: "role not patchable", patch: map[string]*string{"role": strPtr("admin")}, expectError: ErrInvalidPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockUserRepository()
+			useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
+
+			registered, err := useCase.RegisterUser("patch@example.com", "password123", "Patch User", "0812345678", "1990-01-15")
+			if err != nil {
+				t.Fatalf("Failed to register user: %v", err)
+			}
+
+			user, err := useCase.PatchUser(registered.ID, tt.patch)
+			if tt.expectError != nil {
+				if !errors.Is(err, tt.expectError) {
+					t.Errorf("PatchUser() error = %v, want %v", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PatchUser() error = %v", err)
+			}
+			if user.Password != "" {
+				t.Error("Password should be empty in returned user")
+			}
+			tt.check(t, user)
+		})
+	}
+}
+
+func TestUserUseCase_NameScreening(t *testing.T) {
+	useCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	useCase.SetNameScreener(screening.New(screening.Options{}))
+
+	_, err := useCase.RegisterUser("admin@example.com", "password123", "Admin", "0812345678", "1990-01-15")
+	var rejection *screening.Rejection
+	if !errors.As(err, &rejection) || rejection.Code != screening.CodeReserved || !errors.Is(err, ErrNameRejected) {
+		t.Errorf("RegisterUser() error = %v, want a reserved name rejection", err)
+	}
+
+	user, err := useCase.RegisterUser("john@example.com", "password123", "John Doe", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	_, err = useCase.PatchUser(user.ID, map[string]*string{"fullName": strPtr("John\x00Doe")})
+	if !errors.As(err, &rejection) || rejection.Code != screening.CodeControlCharacters {
+		t.Errorf("PatchUser() error = %v, want a control characters rejection", err)
+	}
+	if _, err := useCase.PatchUser(user.ID, map[string]*string{"phoneNumber": strPtr("0898765432")}); err != nil {
+		t.Errorf("PatchUser() error = %v, want other fields unaffected", err)
+	}
+}
+
+func TestUserUseCase_NormalizesProfile(t *testing.T) {
+	useCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+
+	// Fullwidth characters and a zero-width space are normalized away
+	user, err := useCase.RegisterUser("ｊｏｈｎ@example.com", "password123", "Jose\u0301\u200b Doe ", "０８１２３４５６７８", "1990-01-15")
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	if user.Email != "john@example.com" || user.FullName != "Jos\u00e9 Doe" || user.PhoneNumber != "0812345678" {
+		t.Errorf("RegisterUser() = %q %q %q, want normalized fields", user.Email, user.FullName, user.PhoneNumber)
+	}
+	if _, err := useCase.AuthenticateUser("john\u200b@example.com", "password123"); err != nil {
+		t.Errorf("AuthenticateUser() error = %v, want the same account", err)
+	}
+
+	// Only visible characters count towards lengths
+	_, err = useCase.RegisterUser("jane@example.com", "password123", "J\u200b\u200b", "0812345678", "1990-01-15")
+	if !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("RegisterUser() error = %v, want ErrInvalidProfile", err)
+	}
+	if _, err := useCase.PatchUser(user.ID, map[string]*string{"fullName": strPtr("\u202eJ\u202c")}); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("PatchUser() error = %v, want ErrInvalidPatch", err)
+	}
+
+	// Lengths are counted in runes, so a 100 character Thai name fits
+	thai := strings.Repeat("ก", entity.MaxFullNameLength)
+	if _, err := useCase.PatchUser(user.ID, map[string]*string{"fullName": strPtr(thai)}); err != nil {
+		t.Errorf("PatchUser() error = %v, want %d runes accepted", err, entity.MaxFullNameLength)
+	}
+	if _, err := useCase.PatchUser(user.ID, map[string]*string{"fullName": strPtr(thai + "ก")}); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("PatchUser() error = %v, want ErrInvalidPatch", err)
+	}
+}
+
+func TestUserUseCase_BuddhistBirthdays(t *testing.T) {
+	useCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	useCase.SetBirthdayCalendar(entity.CalendarBuddhist)
+
+	user, err := useCase.RegisterUser("somchai@example.com", "password123", "Somchai Jaidee", "0812345678", "2533-01-15")
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	if user.Birthday != "1990-01-15" {
+		t.Errorf("Birthday = %v, want 1990-01-15", user.Birthday)
+	}
+	if _, err := useCase.RegisterUser("somsri@example.com", "password123", "Somsri Jaidee", "0812345678", "1990-01-15"); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("RegisterUser() with a Gregorian year error = %v, want ErrInvalidProfile", err)
+	}
+
+	user, err = useCase.PatchUser(user.ID, map[string]*string{"birthday": strPtr("2543-02-29")})
+	if err != nil || user.Birthday != "2000-02-29" {
+		t.Errorf("PatchUser() = %v, %v, want birthday 2000-02-29", user, err)
+	}
+	if _, err := useCase.PatchUser(user.ID, map[string]*string{"birthday": strPtr("2544-02-29")}); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("PatchUser() error = %v, want ErrInvalidPatch", err)
+	}
+}
+
+func TestUserUseCase_PatchUser_NotFound(t *testing.T) {
+	useCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+
+	_, err := useCase.PatchUser(999, map[string]*string{"fullName": strPtr("Nobody")})
+	if err == nil || err.Error() != "user not found" {
+		t.Errorf("PatchUser() error = %v, want 'user not found'", err)
+	}
+}
+
+func TestUserUseCase_RecordsRevisions(t *testing.T) {
+	mockRepo := NewMockUserRepository()
+	mockRevisions := NewMockUserRevisionRepository()
+	useCase := NewUserUseCase(mockRepo, mockRevisions)
+
+	registered, err := useCase.RegisterUser("history@example.com", "password123", "History User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	if _, err := useCase.PatchUser(registered.ID, map[string]*string{"fullName": strPtr("Renamed")}); err != nil {
+		t.Fatalf("PatchUser() error = %v", err)
+	}
+
+	// A patch that changes nothing is not recorded
+	if _, err := useCase.PatchUser(registered.ID, map[string]*string{"fullName": strPtr("Renamed")}); err != nil {
+		t.Fatalf("PatchUser() error = %v", err)
+	}
+
+	if err := useCase.ChangePassword(registered.ID, "password123", "newpassword"); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if err := useCase.DeleteUser(42, registered.ID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	history, err := useCase.GetUserHistory(registered.ID, repository.Page{})
+	if err != nil {
+		t.Fatalf("GetUserHistory() error = %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("GetUserHistory() returned %d revisions, want 3", len(history))
+	}
+
+	deletion, password, patch := history[0], history[1], history[2]
+	if deletion.Action != entity.RevisionActionDelete || deletion.ActorID != 42 || deletion.Snapshot.FullName != "Renamed" {
+		t.Errorf("delete revision = %+v", deletion)
+	}
+	if len(password.Changes) != 1 || password.Changes[0].Field != "password" || password.Changes[0].New != "[redacted]" {
+		t.Errorf("password revision changes = %+v", password.Changes)
+	}
+	if patch.ActorID != registered.ID || patch.Snapshot.FullName != "History User" ||
+		len(patch.Changes) != 1 || patch.Changes[0].New != "Renamed" {
+		t.Errorf("patch revision = %+v", patch)
+	}
+}
+
+func TestUserUseCase_RevisionFailureDoesNotFailUpdate(t *testing.T) {
+	mockRevisions := &MockUserRevisionRepository{err: errors.New("database error")}
+	useCase := NewUserUseCase(NewMockUserRepository(), mockRevisions)
+
+	registered, err := useCase.RegisterUser("norecord@example.com", "password123", "No Record", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	if _, err := useCase.PatchUser(registered.ID, map[string]*string{"fullName": strPtr("Renamed")}); err != nil {
+		t.Errorf("PatchUser() error = %v, want the update to succeed", err)
+	}
+
+	if _, err := useCase.GetUserHistory(registered.ID, repository.Page{}); err == nil || err.Error() != "failed to load user history" {
+		t.Errorf("GetUserHistory() error = %v, want 'failed to load user history'", err)
+	}
+}
+
+func TestUserUseCase_DeleteUser_NotFound(t *testing.T) {
+	useCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+
+	if err := useCase.DeleteUser(1, 999); err == nil || err.Error() != "user not found" {
+		t.Errorf("DeleteUser() error = %v, want 'user not found'", err)
+	}
+}
+
+func TestUserUseCase_Hooks(t *testing.T) {
+	mockRepo := NewMockUserRepository()
+	useCase := NewUserUseCase(mockRepo, NewMockUserRevisionRepository())
+
+	var postRegisterID, postLoginID int
+	registry := hooks.NewRegistry()
+	registry.Register(hooks.PreRegister, hooks.HookFunc(func(event *hooks.Event) error {
+		if strings.HasSuffix(event.Email, "@blocked.example") {
+			return hooks.Veto("signups from this domain are closed")
+		}
+		event.Data["fullName"] = strings.ToUpper(event.Data["fullName"].(string))
+		return nil
+	}))
+	registry.Register(hooks.PostRegister, hooks.HookFunc(func(event *hooks.Event) error {
+		postRegisterID = event.UserID
+		return nil
+	}))
+	registry.Register(hooks.PreLogin, hooks.HookFunc(func(event *hooks.Event) error {
+		if event.Email == "locked@example.com" {
+			return hooks.Veto("locked")
+		}
+		return nil
+	}))
+	registry.Register(hooks.PostLogin, hooks.HookFunc(func(event *hooks.Event) error {
+		postLoginID = event.UserID
+		return nil
+	}))
+	useCase.SetHooks(registry)
+
+	user, err := useCase.RegisterUser("hooked@example.com", "password123", "Hooked User", "0812345678", "1990-01-15")
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	if user.FullName != "HOOKED USER" {
+		t.Errorf("FullName = %q, want the value set by the pre-register hook", user.FullName)
+	}
+	if postRegisterID != user.ID {
+		t.Errorf("post-register hook saw user %d, want %d", postRegisterID, user.ID)
+	}
+
+	if _, err := useCase.RegisterUser("someone@blocked.example", "password123", "Blocked User", "0812345678", "1990-01-15"); !errors.Is(err, ErrVetoed) {
+		t.Errorf("RegisterUser() error = %v, want ErrVetoed", err)
+	}
+
+	if _, err := useCase.AuthenticateUser("hooked@example.com", "password123"); err != nil {
+		t.Fatalf("AuthenticateUser() error = %v", err)
+	}
+	if postLoginID != user.ID {
+		t.Errorf("post-login hook saw user %d, want %d", postLoginID, user.ID)
+	}
+	if _, err := useCase.AuthenticateUser("locked@example.com", "password123"); !errors.Is(err, ErrVetoed) {
+		t.Errorf("AuthenticateUser() error = %v, want ErrVetoed", err)
+	}
+}
+
+func TestUserUseCase_PreRegisterHookInvalidField(t *testing.T) {
+	useCase := NewUserUseCase(NewMockUserRepository(), NewMockUserRevisionRepository())
+	registry := hooks.NewRegistry()
+	registry.Register(hooks.PreRegister, hooks.HookFunc(func(event *hooks.Event) error {
+		event.Data["email"] = "not-an-email"
+		return nil
+	}))
+	useCase.SetHooks(registry)
+
+	_, err := useCase.RegisterUser("user@example.com", "password123", "Some User", "0812345678", "1990-01-15")
+	if err == nil || err.Error() != "pre-register hook set an invalid email" {
+		t.Errorf("RegisterUser() error = %v, want invalid email from hook", err)
+	}
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/pkg/hooks"
+)
+
+// webhookAttemptRetention is how long the delivery log keeps webhook attempts
+const webhookAttemptRetention = 30 * 24 * time.Hour
+
+// ErrWebhookNotFound is returned for a hook point without a webhook
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// WebhookUseCase keeps a delivery log of the requests webhooks send, with
+// their response codes, so admins can follow an event through its retries
+type WebhookUseCase struct {
+	attemptRepo repository.WebhookAttemptRepository
+	points      []hooks.Point
+	now         func() time.Time
+}
+
+// NewWebhookUseCase creates a new webhook use case for the webhooks
+// registered at points
+func NewWebhookUseCase(attemptRepo repository.WebhookAttemptRepository, points []hooks.Point) *WebhookUseCase {
+	return &WebhookUseCase{
+		attemptRepo: attemptRepo,
+		points:      points,
+		now:         time.Now,
+	}
+}
+
+// RecordAttempt stores a webhook request in the delivery log. It is
+// registered with hooks.Webhook.OnAttempt.
+func (uc *WebhookUseCase) RecordAttempt(attempt hooks.WebhookAttempt) {
+	stored := &entity.WebhookAttempt{
+		EventID:    attempt.EventID,
+		Point:      string(attempt.Point),
+		UserID:     attempt.UserID,
+		StatusCode: attempt.StatusCode,
+		Duration:   attempt.Duration,
+		CreatedAt:  attempt.At.UTC(),
+	}
+	if attempt.Err != nil {
+		stored.Error = attempt.Err.Error()
+	}
+	if err := uc.attemptRepo.Create(stored); err != nil {
+		log.Printf("Failed to log %s webhook attempt for event %s: %v", attempt.Point, attempt.EventID, err)
+	}
+}
+
+// Deliveries returns the timelines of the limit events most recently sent
+// to the webhook at point, newest first, or only of eventID when it is set.
+// Returns ErrWebhookNotFound if no webhook is registered at point.
+func (uc *WebhookUseCase) Deliveries(point, eventID string, limit int) ([]*entity.WebhookDelivery, error) {
+	if !slices.Contains(uc.points, hooks.Point(point)) {
+		return nil, fmt.Errorf("%w: no webhook at %q", ErrWebhookNotFound, point)
+	}
+
+	attempts, err := uc.attemptRepo.ListByPoint(point, eventID, limit)
+	if err != nil {
+		return nil, errors.New("failed to list webhook deliveries")
+	}
+
+	// Attempts come oldest first; an event's latest attempt orders its delivery
+	var deliveries []*entity.WebhookDelivery
+	byEvent := make(map[string]*entity.WebhookDelivery)
+	for _, attempt := range attempts {
+		delivery, ok := byEvent[attempt.EventID]
+		if !ok {
+			delivery = &entity.WebhookDelivery{EventID: attempt.EventID, Point: attempt.Point, UserID: attempt.UserID}
+			byEvent[attempt.EventID] = delivery
+		} else {
+			deliveries = slices.DeleteFunc(deliveries, func(d *entity.WebhookDelivery) bool { return d == delivery })
+		}
+		delivery.Attempts = append(delivery.Attempts, attempt)
+		delivery.Delivered = delivery.Delivered || attempt.Error == ""
+		deliveries = append(deliveries, delivery)
+	}
+	slices.Reverse(deliveries)
+	return deliveries, nil
+}
+
+// DeleteExpired removes attempts older than the delivery log's retention
+func (uc *WebhookUseCase) DeleteExpired() (int, error) {
+	return uc.attemptRepo.DeleteBefore(uc.now().Add(-webhookAttemptRetention))
+}
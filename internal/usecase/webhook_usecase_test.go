@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/pkg/hooks"
+)
+
+// Mock webhook attempt repository for testing
+type MockWebhookAttemptRepository struct {
+	attempts []*entity.WebhookAttempt
+}
+
+func (m *MockWebhookAttemptRepository) Create(attempt *entity.WebhookAttempt) error {
+	attempt.ID = len(m.attempts) + 1
+	m.attempts = append(m.attempts, attempt)
+	return nil
+}
+
+func (m *MockWebhookAttemptRepository) ListByPoint(point, eventID string, limit int) ([]*entity.WebhookAttempt, error) {
+	var attempts []*entity.WebhookAttempt
+	for _, attempt := range m.attempts {
+		if attempt.Point == point && (eventID == "" || attempt.EventID == eventID) {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return attempts, nil
+}
+
+func (m *MockWebhookAttemptRepository) DeleteBefore(before time.Time) (int, error) {
+	kept := m.attempts[:0]
+	for _, attempt := range m.attempts {
+		if !attempt.CreatedAt.Before(before) {
+			kept = append(kept, attempt)
+		}
+	}
+	deleted := len(m.attempts) - len(kept)
+	m.attempts = kept
+	return deleted, nil
+}
+
+func TestWebhookUseCase_Deliveries(t *testing.T) {
+	useCase := NewWebhookUseCase(&MockWebhookAttemptRepository{}, []hooks.Point{hooks.PostRegister})
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, attempt := range []hooks.WebhookAttempt{
+		{EventID: "evt_1", StatusCode: 503, Err: errors.New("webhook returned 503")},
+		{EventID: "evt_2", StatusCode: 200},
+		{EventID: "evt_1", Err: errors.New("connection refused")},
+		{EventID: "evt_1", StatusCode: 204},
+	} {
+		attempt.Point, attempt.UserID, attempt.At = hooks.PostRegister, 7, at.Add(time.Duration(i)*time.Minute)
+		useCase.RecordAttempt(attempt)
+	}
+
+	deliveries, err := useCase.Deliveries("post-register", "", 50)
+	if err != nil {
+		t.Fatalf("Deliveries() error = %v", err)
+	}
+	// evt_1 was retried after evt_2 was sent, so it comes first
+	if len(deliveries) != 2 || deliveries[0].EventID != "evt_1" || deliveries[1].EventID != "evt_2" {
+		t.Fatalf("Deliveries() = %+v, want evt_1 then evt_2", deliveries)
+	}
+	timeline := deliveries[0].Attempts
+	if !deliveries[0].Delivered || len(timeline) != 3 || timeline[0].StatusCode != 503 ||
+		timeline[1].StatusCode != 0 || timeline[1].Error != "connection refused" || timeline[2].StatusCode != 204 {
+		t.Errorf("evt_1 timeline = %+v, want 503, no response, then 204", timeline)
+	}
+
+	if deliveries, _ := useCase.Deliveries("post-register", "evt_2", 50); len(deliveries) != 1 || len(deliveries[0].Attempts) != 1 {
+		t.Errorf("Deliveries(evt_2) = %+v, want its one attempt", deliveries)
+	}
+	if _, err := useCase.Deliveries("pre-login", "", 50); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("Deliveries() without a webhook error = %v, want ErrWebhookNotFound", err)
+	}
+}
+
+func TestWebhookUseCase_DeleteExpired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	useCase := NewWebhookUseCase(&MockWebhookAttemptRepository{}, []hooks.Point{hooks.PostRegister})
+	useCase.now = func() time.Time { return now }
+
+	useCase.RecordAttempt(hooks.WebhookAttempt{EventID: "evt_old", Point: hooks.PostRegister, StatusCode: 200, At: now.Add(-31 * 24 * time.Hour)})
+	useCase.RecordAttempt(hooks.WebhookAttempt{EventID: "evt_new", Point: hooks.PostRegister, StatusCode: 500, At: now.Add(-time.Hour),
+		Err: errors.New("webhook returned 500")})
+
+	if deleted, err := useCase.DeleteExpired(); err != nil || deleted != 1 {
+		t.Errorf("DeleteExpired() = %d, %v; want 1", deleted, err)
+	}
+	deliveries, _ := useCase.Deliveries("post-register", "", 50)
+	if len(deliveries) != 1 || deliveries[0].EventID != "evt_new" || deliveries[0].Delivered {
+		t.Errorf("Deliveries() after DeleteExpired = %+v, want the undelivered evt_new", deliveries)
+	}
+}
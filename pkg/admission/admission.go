@@ -0,0 +1,171 @@
+// Package admission turns requests away before the service is overloaded.
+// It reads the load signals on every request and rejects the lowest
+// priority traffic first, so sign-ins keep working through a sign-up burst.
+package admission
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fiber-hello-world/pkg/autoscale"
+)
+
+// ErrInvalidPriority is returned for an unknown priority name
+var ErrInvalidPriority = errors.New("invalid priority")
+
+// Priority orders requests by how long they are admitted under load
+type Priority int
+
+const (
+	// Low requests are rejected as soon as a signal is over its limit
+	Low Priority = iota
+	// Normal requests are rejected once a signal is over NormalHeadroom
+	// times its limit
+	Normal
+	// Critical requests are always admitted
+	Critical
+)
+
+// NormalHeadroom is how far past the limits normal requests are still
+// admitted, leaving them capacity freed by rejecting low ones
+const NormalHeadroom = 2
+
+var priorityNames = []string{"low", "normal", "critical"}
+
+// ParsePriority parses "low", "normal" or "critical"
+func ParsePriority(s string) (Priority, error) {
+	for i, name := range priorityNames {
+		if strings.EqualFold(s, name) {
+			return Priority(i), nil
+		}
+	}
+	return 0, fmt.Errorf("%w %q: must be low, normal or critical", ErrInvalidPriority, s)
+}
+
+func (p Priority) String() string {
+	if p < 0 || int(p) >= len(priorityNames) {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// Limits are the load signals' values above which low priority requests
+// are rejected. Zero disables a signal.
+type Limits struct {
+	// MaxInFlight bounds the requests being served by the instance,
+	// including the one being admitted
+	MaxInFlight int64
+	// MaxSaturation bounds the hashing pool's (busy + waiting) / size
+	MaxSaturation float64
+	// MaxHashWait bounds how long callers queue for the hashing pool
+	MaxHashWait time.Duration
+}
+
+// Enabled reports whether any limit is set
+func (l Limits) Enabled() bool {
+	return l.MaxInFlight > 0 || l.MaxSaturation > 0 || l.MaxHashWait > 0
+}
+
+// Rule gives the requests matching a method and path prefix a priority
+type Rule struct {
+	// Method matches the request method; empty matches any
+	Method   string
+	Path     string
+	Priority Priority
+}
+
+// ParseRule parses a rule from a route such as "POST /register" or
+// "/admin" and a priority name
+func ParseRule(route, priority string) (Rule, error) {
+	rule := Rule{Path: strings.TrimSpace(route)}
+	if method, path, ok := strings.Cut(rule.Path, " "); ok {
+		rule.Method, rule.Path = strings.ToUpper(method), strings.TrimSpace(path)
+	}
+	if !strings.HasPrefix(rule.Path, "/") {
+		return Rule{}, fmt.Errorf("route %q: path must start with /", route)
+	}
+	var err error
+	if rule.Priority, err = ParsePriority(priority); err != nil {
+		return Rule{}, fmt.Errorf("route %q: %w", route, err)
+	}
+	return rule, nil
+}
+
+// DefaultRules shed sign-ups and password reset emails first and never turn
+// admins away
+var DefaultRules = []Rule{
+	{Method: "POST", Path: "/register", Priority: Low},
+	{Method: "POST", Path: "/password/forgot", Priority: Low},
+	{Path: "/admin", Priority: Critical},
+}
+
+// Decision is whether a request is admitted, and if not why
+type Decision struct {
+	Admitted bool
+	Priority Priority
+	// Signal names the signal over its limit, e.g. "in_flight"
+	Signal string
+}
+
+// Controller admits requests by priority against the load signals
+type Controller struct {
+	limits  Limits
+	signals *autoscale.Signals
+	rules   []Rule
+}
+
+// New creates a controller reading signals. Rules are matched by the
+// longest path prefix; requests matching none are Normal.
+func New(limits Limits, signals *autoscale.Signals, rules []Rule) *Controller {
+	rules = append([]Rule(nil), rules...)
+	sort.SliceStable(rules, func(i, j int) bool {
+		if len(rules[i].Path) != len(rules[j].Path) {
+			return len(rules[i].Path) > len(rules[j].Path)
+		}
+		// A rule for one method wins over one for any method
+		return rules[i].Method != "" && rules[j].Method == ""
+	})
+	return &Controller{limits: limits, signals: signals, rules: rules}
+}
+
+// Priority returns the priority of a request
+func (c *Controller) Priority(method, path string) Priority {
+	for _, rule := range c.rules {
+		if (rule.Method == "" || strings.EqualFold(rule.Method, method)) && strings.HasPrefix(path, rule.Path) {
+			return rule.Priority
+		}
+	}
+	return Normal
+}
+
+// Admit decides whether to serve a request of the given priority now
+func (c *Controller) Admit(priority Priority) Decision {
+	decision := Decision{Admitted: true, Priority: priority}
+	if priority >= Critical {
+		return decision
+	}
+
+	// Load is the highest signal as a share of its limit
+	load, signal := 0.0, ""
+	measure := func(name string, value, limit float64) {
+		if limit > 0 && value/limit > load {
+			load, signal = value/limit, name
+		}
+	}
+	measure("in_flight", float64(c.signals.InFlight()), float64(c.limits.MaxInFlight))
+	pool := c.signals.HashPool()
+	measure("hash_saturation", pool.Saturation(), c.limits.MaxSaturation)
+	measure("hash_wait", float64(pool.Wait), float64(c.limits.MaxHashWait))
+
+	threshold := 1.0
+	if priority == Normal {
+		threshold = NormalHeadroom
+	}
+	if load > threshold {
+		decision.Admitted, decision.Signal = false, signal
+	}
+	return decision
+}
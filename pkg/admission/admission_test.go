@@ -0,0 +1,109 @@
+package admission
+
+import (
+	"errors"
+	"testing"
+
+	"fiber-hello-world/pkg/autoscale"
+	"fiber-hello-world/pkg/hashpool"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		route    string
+		priority string
+		want     Rule
+		wantErr  bool
+	}{
+		{"method and path", "post /register", "low", Rule{Method: "POST", Path: "/register", Priority: Low}, false},
+		{"any method", "/admin", "Critical", Rule{Path: "/admin", Priority: Critical}, false},
+		{"relative path", "POST register", "low", Rule{}, true},
+		{"unknown priority", "/me", "urgent", Rule{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRule(tt.route, tt.priority)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if _, err := ParseRule("/me", "urgent"); !errors.Is(err, ErrInvalidPriority) {
+		t.Errorf("ParseRule() error = %v, want ErrInvalidPriority", err)
+	}
+}
+
+func TestController_Priority(t *testing.T) {
+	controller := New(Limits{MaxInFlight: 1}, autoscale.New(nil), append(DefaultRules,
+		Rule{Path: "/admin/users/export", Priority: Low},
+		Rule{Path: "/me", Priority: Low},
+		Rule{Method: "GET", Path: "/me", Priority: Critical},
+	))
+
+	tests := []struct {
+		method, path string
+		want         Priority
+	}{
+		{"POST", "/register", Low},
+		{"GET", "/register", Normal},
+		{"POST", "/login", Normal},
+		{"DELETE", "/admin/users/3", Critical},
+		// The longest prefix wins, then a rule for the method
+		{"GET", "/admin/users/export", Low},
+		{"GET", "/me", Critical},
+		{"PATCH", "/me", Low},
+	}
+	for _, tt := range tests {
+		if got := controller.Priority(tt.method, tt.path); got != tt.want {
+			t.Errorf("Priority(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestController_Admit(t *testing.T) {
+	signals := autoscale.New(hashpool.New(2))
+	controller := New(Limits{MaxInFlight: 2}, signals, nil)
+
+	admitted := func(priority Priority) bool {
+		return controller.Admit(priority).Admitted
+	}
+	if !admitted(Low) || !admitted(Normal) {
+		t.Fatal("Admit() rejected a request on an idle instance")
+	}
+
+	// Over the limit, low priority requests are rejected first
+	for i := 0; i < 3; i++ {
+		defer signals.Begin()()
+	}
+	if decision := controller.Admit(Low); decision.Admitted || decision.Signal != "in_flight" {
+		t.Errorf("Admit(Low) with 3 in flight = %+v, want rejected on in_flight", decision)
+	}
+	if !admitted(Normal) {
+		t.Error("Admit(Normal) with 3 in flight was rejected within the headroom")
+	}
+
+	// Past the headroom normal ones are too, but never critical ones
+	for i := 0; i < 2; i++ {
+		defer signals.Begin()()
+	}
+	if admitted(Normal) {
+		t.Error("Admit(Normal) with 5 in flight was admitted past the headroom")
+	}
+	if !admitted(Critical) {
+		t.Error("Admit(Critical) was rejected")
+	}
+}
+
+func TestLimits_Enabled(t *testing.T) {
+	if (Limits{}).Enabled() {
+		t.Error("Enabled() = true without limits")
+	}
+	if !(Limits{MaxSaturation: 1.5}).Enabled() {
+		t.Error("Enabled() = false with a saturation limit")
+	}
+}
@@ -0,0 +1,124 @@
+// Package autoscale collects the load signals autoscalers scale the service
+// on: requests in flight, background queue depths and hashing pool load.
+package autoscale
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"fiber-hello-world/pkg/hashpool"
+)
+
+// QueueDepth returns the number of jobs waiting in a background queue
+type QueueDepth func() (int, error)
+
+// Report is a snapshot of the load signals
+type Report struct {
+	InFlightRequests int64
+	Queues           map[string]int
+	HashPool         hashpool.Stats
+	// HashPoolSaturation is (busy + waiting) / size; above 1 logins queue
+	HashPoolSaturation float64
+}
+
+// Signals tracks in-flight requests and reads the other signals on demand
+type Signals struct {
+	inFlight atomic.Int64
+	pool     *hashpool.Pool
+
+	mu     sync.Mutex
+	queues map[string]QueueDepth
+}
+
+// New creates signals reporting on pool, which may be nil
+func New(pool *hashpool.Pool) *Signals {
+	return &Signals{
+		pool:   pool,
+		queues: make(map[string]QueueDepth),
+	}
+}
+
+// Begin counts a request as in flight; call the returned function when it ends
+func (s *Signals) Begin() func() {
+	s.inFlight.Add(1)
+	return func() { s.inFlight.Add(-1) }
+}
+
+// InFlight returns the number of requests being served
+func (s *Signals) InFlight() int64 {
+	return s.inFlight.Load()
+}
+
+// HashPool returns the load on the hashing pool; zero without a pool
+func (s *Signals) HashPool() hashpool.Stats {
+	if s.pool == nil {
+		return hashpool.Stats{}
+	}
+	return s.pool.Stats()
+}
+
+// RegisterQueue adds a background queue reported under name
+func (s *Signals) RegisterQueue(name string, depth QueueDepth) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queues[name] = depth
+}
+
+// Report reads every signal. It fails if a queue depth cannot be read, as
+// a partial report would look like an idle queue.
+func (s *Signals) Report() (*Report, error) {
+	report := &Report{
+		InFlightRequests: s.inFlight.Load(),
+		Queues:           make(map[string]int),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, depth := range s.queues {
+		count, err := depth()
+		if err != nil {
+			return nil, fmt.Errorf("queue %s: %w", name, err)
+		}
+		report.Queues[name] = count
+	}
+
+	report.HashPool = s.HashPool()
+	report.HashPoolSaturation = report.HashPool.Saturation()
+	return report, nil
+}
+
+// WritePrometheus writes the report in the Prometheus text exposition
+// format, for the Prometheus adapter or KEDA
+func (r *Report) WritePrometheus(w io.Writer) error {
+	names := make([]string, 0, len(r.Queues))
+	for name := range r.Queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var err error
+	write := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	write("# HELP api_inflight_requests Requests being served.\n# TYPE api_inflight_requests gauge\n")
+	write("api_inflight_requests %d\n", r.InFlightRequests)
+	write("# HELP api_queue_depth Jobs waiting in a background queue.\n# TYPE api_queue_depth gauge\n")
+	for _, name := range names {
+		write("api_queue_depth{queue=%q} %d\n", name, r.Queues[name])
+	}
+	write("# HELP api_hash_pool_size Password hashes that may run at once.\n# TYPE api_hash_pool_size gauge\n")
+	write("api_hash_pool_size %d\n", r.HashPool.Size)
+	write("# HELP api_hash_pool_busy Password hashes running.\n# TYPE api_hash_pool_busy gauge\n")
+	write("api_hash_pool_busy %d\n", r.HashPool.Busy)
+	write("# HELP api_hash_pool_waiting Password hashes queued for a slot.\n# TYPE api_hash_pool_waiting gauge\n")
+	write("api_hash_pool_waiting %d\n", r.HashPool.Waiting)
+	write("# HELP api_hash_pool_saturation Busy plus waiting hashes over the pool size.\n# TYPE api_hash_pool_saturation gauge\n")
+	write("api_hash_pool_saturation %g\n", r.HashPoolSaturation)
+	return err
+}
@@ -0,0 +1,50 @@
+package autoscale
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"fiber-hello-world/pkg/hashpool"
+)
+
+func TestSignals_Report(t *testing.T) {
+	signals := New(hashpool.New(4))
+	signals.RegisterQueue("admin_actions", func() (int, error) { return 3, nil })
+
+	end := signals.Begin()
+	signals.Begin()
+	end()
+
+	report, err := signals.Report()
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if report.InFlightRequests != 1 || report.Queues["admin_actions"] != 3 || report.HashPool.Size != 4 {
+		t.Errorf("Report() = %+v", report)
+	}
+
+	var out strings.Builder
+	if err := report.WritePrometheus(&out); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	for _, want := range []string{
+		"api_inflight_requests 1\n",
+		`api_queue_depth{queue="admin_actions"} 3` + "\n",
+		"api_hash_pool_size 4\n",
+		"api_hash_pool_saturation 0\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WritePrometheus() output is missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestSignals_QueueError(t *testing.T) {
+	signals := New(nil)
+	signals.RegisterQueue("broken", func() (int, error) { return 0, errors.New("database is locked") })
+
+	if _, err := signals.Report(); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Report() error = %v, want the failing queue named", err)
+	}
+}
@@ -0,0 +1,211 @@
+// Package canary sends a share or a cohort of users to an alternate
+// implementation of a route, served side by side with the stable one, and
+// counts how each variant answers so a rewrite can be compared with the
+// original before it replaces it.
+package canary
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidRule is returned for a rule that cannot be applied
+var ErrInvalidRule = errors.New("invalid canary")
+
+// HeaderVariant names the variant that answered a request to a canaried route
+const HeaderVariant = "X-Canary-Variant"
+
+// flagPrefix starts the cohort of a rule that follows a feature flag
+const flagPrefix = "flag:"
+
+// Variant is the implementation of a route that served a request
+type Variant string
+
+const (
+	// Stable is the implementation the route was registered with
+	Stable Variant = "stable"
+	// Canary is the alternate implementation under test
+	Canary Variant = "canary"
+)
+
+// Rule sends users to the canary of a route: either a Percent of them,
+// chosen by hash, or those with the feature Flag
+type Rule struct {
+	Method string
+	// Path is the route as registered, e.g. /users/:id
+	Path    string
+	Percent int
+	Flag    string
+}
+
+// ParseRule parses a rule from a route such as "POST /login" and a cohort
+// such as "10%" or "flag:new_login"
+func ParseRule(route, cohort string) (Rule, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+	if !ok {
+		return Rule{}, fmt.Errorf("%w: route %q must be a method and a path", ErrInvalidRule, route)
+	}
+	rule := Rule{Method: strings.ToUpper(method), Path: strings.TrimSpace(path)}
+
+	cohort = strings.TrimSpace(cohort)
+	if flag, ok := strings.CutPrefix(cohort, flagPrefix); ok {
+		if rule.Flag = strings.TrimSpace(flag); rule.Flag == "" {
+			return Rule{}, fmt.Errorf("%w: %s: cohort %q must name a flag", ErrInvalidRule, route, cohort)
+		}
+		return rule, rule.Validate()
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(cohort, "%"))
+	if !strings.HasSuffix(cohort, "%") || err != nil {
+		return Rule{}, fmt.Errorf("%w: %s: cohort %q must be a percentage such as 10%% or a flag such as flag:new_login", ErrInvalidRule, route, cohort)
+	}
+	rule.Percent = percent
+	return rule, rule.Validate()
+}
+
+// ParseRules parses rules keyed by route, e.g. "POST /login" => "10%",
+// sorted by route
+func ParseRules(pairs map[string]string) ([]Rule, error) {
+	routes := make([]string, 0, len(pairs))
+	for route := range pairs {
+		routes = append(routes, route)
+	}
+	slices.Sort(routes)
+
+	rules := make([]Rule, 0, len(pairs))
+	for _, route := range routes {
+		rule, err := ParseRule(route, pairs[route])
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Validate checks that the rule names a route and a cohort
+func (r Rule) Validate() error {
+	switch {
+	case r.Method == "":
+		return fmt.Errorf("%w: method is required", ErrInvalidRule)
+	case !strings.HasPrefix(r.Path, "/"):
+		return fmt.Errorf("%w: %s: path must start with /", ErrInvalidRule, r.Route())
+	case r.Flag == "" && (r.Percent < 0 || r.Percent > 100):
+		return fmt.Errorf("%w: %s: percentage must be between 0%% and 100%%", ErrInvalidRule, r.Route())
+	}
+	return nil
+}
+
+// Route names the rule's route, e.g. "POST /login"
+func (r Rule) Route() string {
+	return r.Method + " " + r.Path
+}
+
+// Cohort describes who is sent to the canary, e.g. "10%" or "flag:new_login"
+func (r Rule) Cohort() string {
+	if r.Flag != "" {
+		return flagPrefix + r.Flag
+	}
+	return strconv.Itoa(r.Percent) + "%"
+}
+
+// Includes reports whether the client identified by key is in the rule's
+// percentage. It hashes the route and key, so a client stays in the canary
+// as it grows and each route canaries different clients.
+func (r Rule) Includes(key string) bool {
+	if r.Percent >= 100 || r.Percent <= 0 {
+		return r.Percent >= 100
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(r.Route() + ":" + key))
+	return int(hash.Sum32()%100) < r.Percent
+}
+
+// Counts are the requests a variant answered, and how many of them with a
+// 5xx status
+type Counts struct {
+	Requests     int64
+	ServerErrors int64
+}
+
+// Stats are the counts of both variants of a rule's route
+type Stats struct {
+	Rule   Rule
+	Stable Counts
+	Canary Counts
+}
+
+// Router holds the rules and counts the requests each variant answered, in
+// this process only
+type Router struct {
+	rules []Rule
+
+	mu     sync.Mutex
+	counts map[string]*Stats
+}
+
+// New creates a router for rules
+func New(rules []Rule) (*Router, error) {
+	counts := make(map[string]*Stats, len(rules))
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := counts[rule.Route()]; ok {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrInvalidRule, rule.Route())
+		}
+		counts[rule.Route()] = &Stats{Rule: rule}
+	}
+	return &Router{
+		rules:  append([]Rule(nil), rules...),
+		counts: counts,
+	}, nil
+}
+
+// Rules returns the router's rules
+func (r *Router) Rules() []Rule {
+	return r.rules
+}
+
+// Rule returns the rule of a route as registered, e.g. "POST /login"
+func (r *Router) Rule(method, path string) (Rule, bool) {
+	for _, rule := range r.rules {
+		if strings.EqualFold(rule.Method, method) && rule.Path == path {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Record counts a request to the rule's route answered by variant with status
+func (r *Router) Record(rule Rule, variant Variant, status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats, ok := r.counts[rule.Route()]
+	if !ok {
+		return
+	}
+	counts := &stats.Stable
+	if variant == Canary {
+		counts = &stats.Canary
+	}
+	counts.Requests++
+	if status >= 500 {
+		counts.ServerErrors++
+	}
+}
+
+// Stats returns the counts of every rule, in the order of the rules
+func (r *Router) Stats() []Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := make([]Stats, len(r.rules))
+	for i, rule := range r.rules {
+		stats[i] = *r.counts[rule.Route()]
+	}
+	return stats
+}
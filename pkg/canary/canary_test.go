@@ -0,0 +1,106 @@
+package canary
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   string
+		cohort  string
+		want    Rule
+		wantErr bool
+	}{
+		{"percentage", "POST /login", "10%", Rule{Method: "POST", Path: "/login", Percent: 10}, false},
+		{"flag", "get /users/:id", " flag:new_profile ", Rule{Method: "GET", Path: "/users/:id", Flag: "new_profile"}, false},
+		{"off", "POST /login", "0%", Rule{Method: "POST", Path: "/login"}, false},
+		{"no method", "/login", "10%", Rule{}, true},
+		{"relative path", "POST login", "10%", Rule{}, true},
+		{"no percent sign", "POST /login", "10", Rule{}, true},
+		{"over 100", "POST /login", "101%", Rule{}, true},
+		{"no flag", "POST /login", "flag:", Rule{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRule(tt.route, tt.cohort)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidRule) {
+					t.Errorf("ParseRule() error = %v, want ErrInvalidRule", err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseRule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(map[string]string{"POST /register": "flag:new_signup", "POST /login": "10%"})
+	if err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+	if len(rules) != 2 || rules[0].Route() != "POST /login" || rules[1].Cohort() != "flag:new_signup" {
+		t.Errorf("ParseRules() = %+v, want the rules sorted by route", rules)
+	}
+	if _, err := ParseRules(map[string]string{"POST /login": "some"}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("ParseRules() error = %v, want ErrInvalidRule", err)
+	}
+}
+
+func TestRule_Includes(t *testing.T) {
+	rule := Rule{Method: "POST", Path: "/login", Percent: 25}
+	included := 0
+	for i := 0; i < 1000; i++ {
+		key := "user:" + strconv.Itoa(i)
+		if rule.Includes(key) {
+			included++
+		}
+		// A client stays in the canary as it grows
+		if rule.Includes(key) && !(Rule{Method: "POST", Path: "/login", Percent: 50}).Includes(key) {
+			t.Fatalf("%s left the canary when it grew to 50%%", key)
+		}
+	}
+	if included < 200 || included > 300 {
+		t.Errorf("Includes() chose %d of 1000 clients, want about 250", included)
+	}
+
+	if (Rule{Percent: 0}).Includes("user:1") || !(Rule{Percent: 100}).Includes("user:1") {
+		t.Error("0% should include no one and 100% everyone")
+	}
+}
+
+func TestRouter(t *testing.T) {
+	login := Rule{Method: "POST", Path: "/login", Percent: 10}
+	router, err := New([]Rule{login, {Method: "GET", Path: "/users/:id", Flag: "new_profile"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := New([]Rule{login, login}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("New() with a route twice error = %v, want ErrInvalidRule", err)
+	}
+
+	if rule, ok := router.Rule("get", "/users/:id"); !ok || rule.Flag != "new_profile" {
+		t.Errorf("Rule(GET /users/:id) = %+v, %v", rule, ok)
+	}
+	if _, ok := router.Rule("GET", "/users/1"); ok {
+		t.Error("Rule() should match the route as registered, not a request path")
+	}
+
+	router.Record(login, Stable, 200)
+	router.Record(login, Canary, 200)
+	router.Record(login, Canary, 503)
+	router.Record(Rule{Method: "GET", Path: "/"}, Canary, 200)
+	stats := router.Stats()
+	if len(stats) != 2 || stats[0].Stable != (Counts{Requests: 1}) || stats[0].Canary != (Counts{Requests: 2, ServerErrors: 1}) {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
@@ -0,0 +1,110 @@
+// Package chaos injects faults into requests at runtime, so client retries
+// and server timeouts can be exercised in staging.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidRule is returned by SetRules for a rule that cannot be applied
+var ErrInvalidRule = errors.New("invalid fault rule")
+
+// MaxLatency bounds injected latency, so a rule cannot hold connections open
+// past any sensible timeout
+const MaxLatency = 2 * time.Minute
+
+// Rule injects faults into a share of the requests it matches. Latency is
+// added first; the request then fails with Status, is dropped without a
+// response, or proceeds when neither is set.
+type Rule struct {
+	// Method matches the request method; empty matches any
+	Method string
+	// Path matches request paths with this prefix
+	Path string
+	// Percent of matching requests that get the fault, from 0 to 100
+	Percent float64
+	Latency time.Duration
+	// Status is the error status returned instead of the response
+	Status int
+	// Drop closes the connection without responding
+	Drop bool
+}
+
+// Validate checks that the rule matches something and injects a fault
+func (r Rule) Validate() error {
+	switch {
+	case !strings.HasPrefix(r.Path, "/"):
+		return fmt.Errorf("%w: path must start with /", ErrInvalidRule)
+	case r.Percent <= 0 || r.Percent > 100:
+		return fmt.Errorf("%w: percent must be above 0 and at most 100", ErrInvalidRule)
+	case r.Latency < 0 || r.Latency > MaxLatency:
+		return fmt.Errorf("%w: latency must be between 0 and %s", ErrInvalidRule, MaxLatency)
+	case r.Status != 0 && (r.Status < 400 || r.Status > 599):
+		return fmt.Errorf("%w: status must be a 4xx or 5xx code", ErrInvalidRule)
+	case r.Status != 0 && r.Drop:
+		return fmt.Errorf("%w: a rule cannot both fail and drop requests", ErrInvalidRule)
+	case r.Latency == 0 && r.Status == 0 && !r.Drop:
+		return fmt.Errorf("%w: set latency, status or drop", ErrInvalidRule)
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to a request
+func (r Rule) matches(method, path string) bool {
+	return (r.Method == "" || strings.EqualFold(r.Method, method)) && strings.HasPrefix(path, r.Path)
+}
+
+// Injector holds the active rules. It injects nothing until rules are set.
+type Injector struct {
+	mu    sync.RWMutex
+	rules []Rule
+	roll  func() float64
+}
+
+// New creates an injector with no rules
+func New() *Injector {
+	return &Injector{roll: func() float64 { return rand.Float64() * 100 }}
+}
+
+// SetRules replaces the active rules; an empty list stops injecting faults
+func (i *Injector) SetRules(rules []Rule) error {
+	for n, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("rule %d: %w", n+1, err)
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rules = append([]Rule(nil), rules...)
+	return nil
+}
+
+// Rules returns the active rules
+func (i *Injector) Rules() []Rule {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return append([]Rule(nil), i.rules...)
+}
+
+// Pick returns the fault to inject into a request, or nil. Only the first
+// rule matching the request applies.
+func (i *Injector) Pick(method, path string) *Rule {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, rule := range i.rules {
+		if !rule.matches(method, path) {
+			continue
+		}
+		if i.roll() < rule.Percent {
+			return &rule
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package chaos
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRule_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{"latency", Rule{Path: "/login", Percent: 50, Latency: time.Second}, false},
+		{"error", Rule{Method: "POST", Path: "/", Percent: 100, Status: 503}, false},
+		{"drop after latency", Rule{Path: "/me", Percent: 10, Latency: time.Second, Drop: true}, false},
+		{"relative path", Rule{Path: "login", Percent: 50, Status: 500}, true},
+		{"no percent", Rule{Path: "/", Status: 500}, true},
+		{"over 100 percent", Rule{Path: "/", Percent: 101, Status: 500}, true},
+		{"success status", Rule{Path: "/", Percent: 50, Status: 200}, true},
+		{"latency too long", Rule{Path: "/", Percent: 50, Latency: time.Hour}, true},
+		{"fail and drop", Rule{Path: "/", Percent: 50, Status: 500, Drop: true}, true},
+		{"no fault", Rule{Path: "/", Percent: 50}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("Validate() error = %v, want ErrInvalidRule", err)
+			}
+		})
+	}
+}
+
+func TestInjector_Pick(t *testing.T) {
+	injector := New()
+	if rule := injector.Pick("GET", "/me"); rule != nil {
+		t.Fatalf("Pick() with no rules = %+v", rule)
+	}
+
+	err := injector.SetRules([]Rule{
+		{Method: "post", Path: "/login", Percent: 100, Status: 503},
+		{Path: "/", Percent: 25, Latency: time.Second},
+	})
+	if err != nil {
+		t.Fatalf("SetRules() error = %v", err)
+	}
+
+	if rule := injector.Pick("POST", "/login"); rule == nil || rule.Status != 503 {
+		t.Errorf("Pick(POST /login) = %+v, want the error rule", rule)
+	}
+
+	// Only the first matching rule applies, at its percentage
+	injector.roll = func() float64 { return 30 }
+	if rule := injector.Pick("GET", "/me"); rule != nil {
+		t.Errorf("Pick(GET /me) rolling 30 = %+v, want none at 25%%", rule)
+	}
+	injector.roll = func() float64 { return 10 }
+	if rule := injector.Pick("GET", "/me"); rule == nil || rule.Latency != time.Second {
+		t.Errorf("Pick(GET /me) rolling 10 = %+v, want the latency rule", rule)
+	}
+
+	if err := injector.SetRules([]Rule{{Path: "/", Percent: 50}}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("SetRules() error = %v, want ErrInvalidRule", err)
+	}
+	if len(injector.Rules()) != 2 {
+		t.Error("an invalid SetRules() should keep the active rules")
+	}
+	if err := injector.SetRules(nil); err != nil || injector.Pick("POST", "/login") != nil {
+		t.Errorf("SetRules(nil) should stop injecting faults, error = %v", err)
+	}
+}
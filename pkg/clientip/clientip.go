@@ -0,0 +1,98 @@
+// Package clientip resolves the real client IP behind reverse proxies.
+// Forwarding headers are honored only when the peer is a trusted proxy.
+package clientip
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Forwarding headers set by reverse proxies
+const (
+	HeaderForwardedFor = "X-Forwarded-For"
+	HeaderRealIP       = "X-Real-IP"
+)
+
+// Resolver resolves client IPs from the connection peer and forwarding headers
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// NewResolver creates a resolver trusting the given proxies, each a CIDR range
+// (e.g. "10.0.0.0/8") or a single IP. With no proxies, headers are ignored.
+func NewResolver(trustedProxies []string) (*Resolver, error) {
+	r := &Resolver{}
+	for _, proxy := range trustedProxies {
+		prefix, err := parsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		r.trusted = append(r.trusted, prefix)
+	}
+	return r, nil
+}
+
+// Resolve returns the client IP for a request from remoteIP. When remoteIP is
+// a trusted proxy, X-Forwarded-For is walked from the right and the first
+// address that is not a trusted proxy is the client; entries to its left
+// were supplied by the client and are ignored. X-Real-IP is used when
+// X-Forwarded-For is absent.
+func (r *Resolver) Resolve(remoteIP, forwardedFor, realIP string) string {
+	peer, err := netip.ParseAddr(remoteIP)
+	if err != nil || !r.isTrusted(peer) {
+		return remoteIP
+	}
+
+	if forwardedFor != "" {
+		client := peer
+		hops := strings.Split(forwardedFor, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			client = hop.Unmap()
+			if !r.isTrusted(client) {
+				break
+			}
+		}
+		return client.String()
+	}
+
+	if addr, err := netip.ParseAddr(strings.TrimSpace(realIP)); err == nil {
+		return addr.Unmap().String()
+	}
+	return remoteIP
+}
+
+// Trusts reports whether remoteIP is a trusted proxy, i.e. whether headers
+// it sets may be believed
+func (r *Resolver) Trusts(remoteIP string) bool {
+	peer, err := netip.ParseAddr(remoteIP)
+	return err == nil && r.isTrusted(peer)
+}
+
+// isTrusted reports whether addr is within a trusted proxy range
+func (r *Resolver) isTrusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePrefix parses a CIDR range or a single IP as a prefix
+func parsePrefix(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -0,0 +1,64 @@
+package clientip
+
+import "testing"
+
+func TestNewResolver(t *testing.T) {
+	if _, err := NewResolver([]string{"10.0.0.0/8", "192.168.1.5", "fd00::/8"}); err != nil {
+		t.Errorf("NewResolver() error = %v", err)
+	}
+	if _, err := NewResolver([]string{"not-an-ip"}); err == nil {
+		t.Error("NewResolver() should reject an invalid proxy")
+	}
+	if _, err := NewResolver([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("NewResolver() should reject an invalid CIDR")
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8", "192.168.1.5"})
+	if err != nil {
+		t.Fatalf("NewResolver() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		remoteIP     string
+		forwardedFor string
+		realIP       string
+		want         string
+	}{
+		{"untrusted peer ignores headers", "203.0.113.9", "198.51.100.1", "198.51.100.2", "203.0.113.9"},
+		{"trusted peer uses forwarded for", "10.0.0.2", "198.51.100.1", "", "198.51.100.1"},
+		{"spoofed entries left of the client are ignored", "10.0.0.2", "1.2.3.4, 198.51.100.1", "", "198.51.100.1"},
+		{"skips trusted proxy chain", "10.0.0.2", "198.51.100.1, 192.168.1.5, 10.1.1.1", "", "198.51.100.1"},
+		{"all hops trusted uses leftmost", "10.0.0.2", "10.0.0.7, 10.0.0.8", "", "10.0.0.7"},
+		{"invalid hop stops the walk", "10.0.0.2", "198.51.100.1, garbage, 10.0.0.9", "", "10.0.0.9"},
+		{"real ip without forwarded for", "192.168.1.5", "", "198.51.100.3", "198.51.100.3"},
+		{"invalid real ip falls back to peer", "192.168.1.5", "", "nope", "192.168.1.5"},
+		{"ipv4-mapped peer", "::ffff:10.0.0.2", "198.51.100.1", "", "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolver.Resolve(tt.remoteIP, tt.forwardedFor, tt.realIP); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolver_NoTrustedProxies(t *testing.T) {
+	resolver, _ := NewResolver(nil)
+	if got := resolver.Resolve("10.0.0.2", "198.51.100.1", "198.51.100.2"); got != "10.0.0.2" {
+		t.Errorf("Resolve() = %q, want the peer when no proxies are trusted", got)
+	}
+}
+
+func TestResolver_Trusts(t *testing.T) {
+	resolver, _ := NewResolver([]string{"10.0.0.0/8"})
+	for remoteIP, want := range map[string]bool{"10.1.2.3": true, "::ffff:10.1.2.3": true, "198.51.100.1": false, "garbage": false} {
+		if got := resolver.Trusts(remoteIP); got != want {
+			t.Errorf("Trusts(%q) = %v, want %v", remoteIP, got, want)
+		}
+	}
+}
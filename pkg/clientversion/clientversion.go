@@ -0,0 +1,129 @@
+// Package clientversion parses the X-Client-Version header apps send, such
+// as "ios/2.3.1", and compares app versions.
+package clientversion
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Header carries the platform and version of the calling app
+const Header = "X-Client-Version"
+
+// ErrInvalid is returned for a header, platform or version that cannot be parsed
+var ErrInvalid = errors.New("invalid client version")
+
+// maxParts bounds the numeric parts of a version, e.g. 1.2.3.4
+const maxParts = 4
+
+// maxPlatformLength bounds platform names
+const maxPlatformLength = 32
+
+// Version is an app version: dot-separated numbers with an optional
+// pre-release after "-", which sorts before the release. Build metadata
+// after "+" is ignored.
+type Version struct {
+	parts      []int
+	prerelease string
+}
+
+// Parse parses a version such as "2.3.1", "v2.3" or "2.4.0-beta.1"
+func Parse(s string) (Version, error) {
+	raw := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	raw, _, _ = strings.Cut(raw, "+")
+	raw, prerelease, _ := strings.Cut(raw, "-")
+
+	fields := strings.Split(raw, ".")
+	if len(fields) > maxParts {
+		return Version{}, fmt.Errorf("%w: %q has more than %d parts", ErrInvalid, s, maxParts)
+	}
+	v := Version{parts: make([]int, len(fields)), prerelease: prerelease}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 || field[0] == '+' {
+			return Version{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+		}
+		v.parts[i] = n
+	}
+	return v, nil
+}
+
+// String returns the version without build metadata
+func (v Version) String() string {
+	parts := make([]string, len(v.parts))
+	for i, n := range v.parts {
+		parts[i] = strconv.Itoa(n)
+	}
+	s := strings.Join(parts, ".")
+	if v.prerelease != "" {
+		s += "-" + v.prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v is older than, the same as or newer than
+// other. Missing parts count as 0, so 2.3 equals 2.3.0.
+func (v Version) Compare(other Version) int {
+	for i := 0; i < maxParts; i++ {
+		a, b := part(v.parts, i), part(other.parts, i)
+		if a != b {
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case v.prerelease == other.prerelease:
+		return 0
+	case v.prerelease == "":
+		return 1
+	case other.prerelease == "":
+		return -1
+	case v.prerelease < other.prerelease:
+		return -1
+	}
+	return 1
+}
+
+func part(parts []int, i int) int {
+	if i < len(parts) {
+		return parts[i]
+	}
+	return 0
+}
+
+// ParsePlatform checks and lower-cases a platform name such as "ios" or
+// "android": letters, digits, "-" and "_"
+func ParsePlatform(s string) (string, error) {
+	platform := strings.ToLower(strings.TrimSpace(s))
+	if platform == "" || len(platform) > maxPlatformLength {
+		return "", fmt.Errorf("%w: platform must have 1 to %d characters", ErrInvalid, maxPlatformLength)
+	}
+	for _, r := range platform {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", fmt.Errorf("%w: platform %q may only hold letters, digits, - and _", ErrInvalid, s)
+		}
+	}
+	return platform, nil
+}
+
+// ParseHeader parses an X-Client-Version header of the form
+// "<platform>/<version>", e.g. "ios/2.3.1"
+func ParseHeader(header string) (string, Version, error) {
+	name, version, ok := strings.Cut(header, "/")
+	if !ok {
+		return "", Version{}, fmt.Errorf("%w: %s must be <platform>/<version>", ErrInvalid, Header)
+	}
+	platform, err := ParsePlatform(name)
+	if err != nil {
+		return "", Version{}, err
+	}
+	v, err := Parse(version)
+	if err != nil {
+		return "", Version{}, err
+	}
+	return platform, v, nil
+}
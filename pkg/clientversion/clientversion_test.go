@@ -0,0 +1,59 @@
+package clientversion
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.3.1", "2.3.1", 0},
+		{"2.3", "2.3.0", 0},
+		{"v2.3.1", "2.3.1", 0},
+		{"2.3.1+build.7", "2.3.1", 0},
+		{"2.10.0", "2.9.9", 1},
+		{"2.3.0", "2.3.1", -1},
+		{"3", "2.99.99", 1},
+		{"2.4.0-beta.1", "2.4.0", -1},
+		{"2.4.0-beta.2", "2.4.0-beta.1", 1},
+		{"2.4.0-beta.1", "2.3.9", 1},
+		{"1.2.3.4", "1.2.3", 1},
+	}
+	for _, tt := range tests {
+		a, err := Parse(tt.a)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.a, err)
+		}
+		b, err := Parse(tt.b)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.b, err)
+		}
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, s := range []string{"", "latest", "2..1", "2.x", "1.2.3.4.5", "+1.2", "2.3.", "-beta"} {
+		if _, err := Parse(s); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalid", s, err)
+		}
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	platform, version, err := ParseHeader("iOS/2.3.1-rc.1")
+	if err != nil || platform != "ios" || version.String() != "2.3.1-rc.1" {
+		t.Errorf("ParseHeader() = %q, %v, %v", platform, version, err)
+	}
+
+	for _, header := range []string{"2.3.1", "/2.3.1", "ios/", "i os/2.3.1", "ios/two"} {
+		if _, _, err := ParseHeader(header); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ParseHeader(%q) error = %v, want ErrInvalid", header, err)
+		}
+	}
+}
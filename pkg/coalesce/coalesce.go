@@ -0,0 +1,77 @@
+// Package coalesce runs identical concurrent reads once: callers asking for
+// a key while a read of it is in flight wait for that read and share its
+// result, so a burst of requests for one record costs one query.
+package coalesce
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Stats counts the reads asked of a group since it was created
+type Stats struct {
+	// Calls are the reads asked for
+	Calls int64
+	// Queries are the reads that ran; the other calls shared their results
+	Queries int64
+}
+
+// Coalesced returns how many calls shared another call's read
+func (s Stats) Coalesced() int64 {
+	return s.Calls - s.Queries
+}
+
+// DedupRate returns the share of calls that shared another call's read, 0
+// before the first call
+func (s Stats) DedupRate() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Coalesced()) / float64(s.Calls)
+}
+
+// WritePrometheus writes the stats as counters in the Prometheus text format
+func (s Stats) WritePrometheus(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP api_read_coalescing_calls_total Reads asked for.\n# TYPE api_read_coalescing_calls_total counter\napi_read_coalescing_calls_total %d\n"+
+		"# HELP api_read_coalescing_queries_total Reads that ran rather than sharing a concurrent identical read.\n# TYPE api_read_coalescing_queries_total counter\napi_read_coalescing_queries_total %d\n",
+		s.Calls, s.Queries)
+	return err
+}
+
+// Group coalesces the reads of its keys
+type Group struct {
+	flight  singleflight.Group
+	calls   atomic.Int64
+	queries atomic.Int64
+}
+
+// New creates an empty group
+func New() *Group {
+	return &Group{}
+}
+
+// Do returns the result of read for key. While a read of key is in flight,
+// callers wait for it and share its result rather than running read, so
+// results must not be modified by callers.
+func (g *Group) Do(key string, read func() (interface{}, error)) (interface{}, error) {
+	g.calls.Add(1)
+	value, err, _ := g.flight.Do(key, func() (interface{}, error) {
+		g.queries.Add(1)
+		return read()
+	})
+	return value, err
+}
+
+// Forget makes the next call for key run a read of its own rather than
+// share one in flight, e.g. once the record was written
+func (g *Group) Forget(key string) {
+	g.flight.Forget(key)
+}
+
+// Stats returns the calls and queries since the group was created
+func (g *Group) Stats() Stats {
+	return Stats{Calls: g.calls.Load(), Queries: g.queries.Load()}
+}
@@ -0,0 +1,87 @@
+package coalesce
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup_Do(t *testing.T) {
+	g := New()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var queries atomic.Int64
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([]interface{}, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = g.Do("user:1", func() (interface{}, error) {
+				if queries.Add(1) == 1 {
+					close(started)
+				}
+				<-release
+				return "jane", nil
+			})
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+	// Let the other callers join the read in flight before it returns
+	for g.Stats().Calls < callers {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if queries.Load() != 1 {
+		t.Errorf("queries = %d, want 1", queries.Load())
+	}
+	for i, result := range results {
+		if result != "jane" {
+			t.Errorf("caller %d got %v", i, result)
+		}
+	}
+	stats := g.Stats()
+	if stats.Calls != callers || stats.Queries != 1 || stats.Coalesced() != callers-1 || stats.DedupRate() != 0.9 {
+		t.Errorf("Stats() = %+v, rate %g", stats, stats.DedupRate())
+	}
+}
+
+func TestGroup_DoSequential(t *testing.T) {
+	g := New()
+	errRead := errors.New("read failed")
+	for range 2 {
+		if _, err := g.Do("user:1", func() (interface{}, error) { return nil, errRead }); !errors.Is(err, errRead) {
+			t.Errorf("Do() error = %v, want the read's", err)
+		}
+	}
+	g.Forget("user:1")
+	if stats := g.Stats(); stats.Queries != 2 || stats.DedupRate() != 0 {
+		t.Errorf("sequential calls should each run a read, Stats() = %+v", stats)
+	}
+	if (Stats{}).DedupRate() != 0 {
+		t.Error("DedupRate() before the first call should be 0")
+	}
+}
+
+func TestStats_WritePrometheus(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (Stats{Calls: 12, Queries: 3}).WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"api_read_coalescing_calls_total 12\n", "api_read_coalescing_queries_total 3\n", "# TYPE api_read_coalescing_calls_total counter"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WritePrometheus() = %q, want %q", buf.String(), want)
+		}
+	}
+}
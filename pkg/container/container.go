@@ -0,0 +1,90 @@
+// Package container is a small dependency injection container. Each type has
+// one provider whose result is built on first use and shared afterwards;
+// registering a value or provider again replaces it, e.g. to swap a
+// repository in tests.
+package container
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrNotProvided is returned when a type has no provider
+var ErrNotProvided = errors.New("no provider registered")
+
+// ErrCycle is returned when a provider depends on its own type
+var ErrCycle = errors.New("dependency cycle")
+
+// Container builds and holds the services of an application. It is meant
+// to be assembled at startup and is not safe for concurrent use.
+type Container struct {
+	providers map[reflect.Type]func(*Container) (interface{}, error)
+	instances map[reflect.Type]interface{}
+	building  []reflect.Type
+}
+
+// New creates an empty container
+func New() *Container {
+	return &Container{
+		providers: make(map[reflect.Type]func(*Container) (interface{}, error)),
+		instances: make(map[reflect.Type]interface{}),
+	}
+}
+
+// Provide registers how to build a T. The provider runs at most once, the
+// first time a T is requested, and may request its own dependencies from c.
+func Provide[T any](c *Container, provider func(c *Container) (T, error)) {
+	key := reflect.TypeFor[T]()
+	delete(c.instances, key)
+	c.providers[key] = func(c *Container) (interface{}, error) {
+		return provider(c)
+	}
+}
+
+// Set registers a ready-made T, replacing any provider for it
+func Set[T any](c *Container, value T) {
+	Provide(c, func(*Container) (T, error) { return value, nil })
+}
+
+// Get returns the T, building it and its dependencies on first use
+func Get[T any](c *Container) (T, error) {
+	var zero T
+	key := reflect.TypeFor[T]()
+
+	if instance, ok := c.instances[key]; ok {
+		value, _ := instance.(T)
+		return value, nil
+	}
+	provider, ok := c.providers[key]
+	if !ok {
+		return zero, fmt.Errorf("%w for %s", ErrNotProvided, key)
+	}
+	for i, t := range c.building {
+		if t == key {
+			return zero, fmt.Errorf("%w: %s", ErrCycle, cyclePath(append(slices.Clone(c.building[i:]), key)))
+		}
+	}
+
+	c.building = append(c.building, key)
+	instance, err := provider(c)
+	c.building = c.building[:len(c.building)-1]
+	if err != nil {
+		return zero, fmt.Errorf("building %s: %w", key, err)
+	}
+
+	c.instances[key] = instance
+	value, _ := instance.(T)
+	return value, nil
+}
+
+// cyclePath formats the types of a cycle as "a -> b -> a"
+func cyclePath(types []reflect.Type) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+	return strings.Join(names, " -> ")
+}
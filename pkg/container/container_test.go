@@ -0,0 +1,103 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type english struct{ name string }
+
+func (e *english) Greet() string { return "hello " + e.name }
+
+type service struct {
+	greeter greeter
+}
+
+func TestGet(t *testing.T) {
+	c := New()
+	builds := 0
+	Set(c, "world")
+	Provide(c, func(c *Container) (greeter, error) {
+		builds++
+		name, err := Get[string](c)
+		if err != nil {
+			return nil, err
+		}
+		return &english{name: name}, nil
+	})
+	Provide(c, func(c *Container) (*service, error) {
+		g, err := Get[greeter](c)
+		return &service{greeter: g}, err
+	})
+
+	svc, err := Get[*service](c)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := svc.greeter.Greet(); got != "hello world" {
+		t.Errorf("Greet() = %q, want %q", got, "hello world")
+	}
+
+	// Instances are shared
+	g, _ := Get[greeter](c)
+	if g != svc.greeter || builds != 1 {
+		t.Errorf("provider ran %d times, want the instance to be shared", builds)
+	}
+}
+
+func TestSet_Overrides(t *testing.T) {
+	c := New()
+	Provide(c, func(*Container) (greeter, error) { return &english{name: "default"}, nil })
+	Provide(c, func(c *Container) (*service, error) {
+		g, err := Get[greeter](c)
+		return &service{greeter: g}, err
+	})
+
+	// Overrides registered before first use replace the provider
+	Set[greeter](c, &english{name: "test"})
+
+	svc, err := Get[*service](c)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := svc.greeter.Greet(); got != "hello test" {
+		t.Errorf("Greet() = %q, want the override", got)
+	}
+}
+
+func TestGet_Errors(t *testing.T) {
+	c := New()
+	if _, err := Get[*service](c); !errors.Is(err, ErrNotProvided) {
+		t.Errorf("Get() error = %v, want ErrNotProvided", err)
+	}
+
+	Provide(c, func(c *Container) (greeter, error) {
+		_, err := Get[*service](c)
+		return nil, err
+	})
+	Provide(c, func(c *Container) (*service, error) {
+		g, err := Get[greeter](c)
+		return &service{greeter: g}, err
+	})
+	_, err := Get[*service](c)
+	if !errors.Is(err, ErrCycle) || !strings.Contains(err.Error(), "*container.service -> container.greeter -> *container.service") {
+		t.Errorf("Get() error = %v, want the cycle", err)
+	}
+
+	failing := fmt.Errorf("connection refused")
+	Provide(c, func(*Container) (greeter, error) { return nil, failing })
+	if _, err := Get[greeter](c); !errors.Is(err, failing) {
+		t.Errorf("Get() error = %v, want the provider error", err)
+	}
+	// A failed build is retried on the next request
+	Set[greeter](c, &english{name: "again"})
+	if g, err := Get[greeter](c); err != nil || g.Greet() != "hello again" {
+		t.Errorf("Get() = %v, %v after replacing a failing provider", g, err)
+	}
+}
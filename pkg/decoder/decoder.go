@@ -0,0 +1,179 @@
+package decoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+const (
+	// DefaultMaxBytes is the default maximum request body size
+	DefaultMaxBytes = 1 << 20
+	// DefaultMaxDepth is the default maximum nesting depth of JSON documents
+	DefaultMaxDepth = 32
+)
+
+var (
+	// ErrUnsupportedMediaType is returned when the body is not JSON
+	ErrUnsupportedMediaType = errors.New("content type must be application/json")
+	// ErrBodyTooLarge is returned when the body exceeds the size limit
+	ErrBodyTooLarge = errors.New("request body too large")
+)
+
+// Error describes a body decoding failure with its location in the document
+type Error struct {
+	Message string
+	Field   string
+	Line    int
+	Column  int
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	location := fmt.Sprintf("line %d, column %d", e.Line, e.Column)
+	if e.Field != "" {
+		return fmt.Sprintf("%s (field %q at %s)", e.Message, e.Field, location)
+	}
+	return fmt.Sprintf("%s (at %s)", e.Message, location)
+}
+
+// Service provides strict JSON body decoding
+type Service struct {
+	maxBytes int
+	maxDepth int
+}
+
+// NewService creates a new decoder service with the given limits.
+// Non-positive limits fall back to the defaults.
+func NewService(maxBytes, maxDepth int) *Service {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	return &Service{
+		maxBytes: maxBytes,
+		maxDepth: maxDepth,
+	}
+}
+
+// IsJSON reports whether the content type is application/json or a +json
+// structured syntax type such as application/merge-patch+json (parameters allowed)
+func IsJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" ||
+		(strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// Decode decodes a JSON body into dst, rejecting unknown fields, trailing data,
+// oversized bodies and documents nested deeper than the configured limit
+func (s *Service) Decode(contentType string, body []byte, dst interface{}) error {
+	if !IsJSON(contentType) {
+		return ErrUnsupportedMediaType
+	}
+	if len(body) > s.maxBytes {
+		return ErrBodyTooLarge
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return &Error{Message: "request body must not be empty", Line: 1, Column: 1}
+	}
+
+	if err := s.checkDepth(body); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return describe(body, err, dec.InputOffset())
+	}
+
+	// Only a single JSON value is allowed
+	if _, err := dec.Token(); err != io.EOF {
+		line, column := position(body, dec.InputOffset())
+		return &Error{Message: "request body must contain a single JSON value", Line: line, Column: column}
+	}
+
+	return nil
+}
+
+// checkDepth walks the token stream and rejects documents nested too deeply
+func (s *Service) checkDepth(body []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return describe(body, err, dec.InputOffset())
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if depth > s.maxDepth {
+				line, column := position(body, dec.InputOffset())
+				return &Error{Message: fmt.Sprintf("request body exceeds maximum nesting depth of %d", s.maxDepth), Line: line, Column: column}
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
+
+// describe converts encoding/json errors into located decoding errors
+func describe(body []byte, err error, offset int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, column := position(body, syntaxErr.Offset)
+		return &Error{Message: "malformed JSON: " + syntaxErr.Error(), Line: line, Column: column}
+	case errors.As(err, &typeErr):
+		line, column := position(body, typeErr.Offset)
+		return &Error{
+			Message: fmt.Sprintf("must be of type %s, got %s", typeErr.Type, typeErr.Value),
+			Field:   typeErr.Field,
+			Line:    line,
+			Column:  column,
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		line, column := position(body, int64(len(body)))
+		return &Error{Message: "malformed JSON: unexpected end of input", Line: line, Column: column}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		line, column := position(body, offset)
+		return &Error{Message: "unknown field", Field: field, Line: line, Column: column}
+	}
+
+	line, column := position(body, offset)
+	return &Error{Message: err.Error(), Line: line, Column: column}
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(body []byte, offset int64) (int, int) {
+	if offset > int64(len(body)) {
+		offset = int64(len(body))
+	}
+	line, column := 1, 1
+	for _, b := range body[:offset] {
+		if b == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
@@ -0,0 +1,230 @@
+package decoder
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testPayload struct {
+	Email   string `json:"email"`
+	Age     int    `json:"age"`
+	Profile struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+}
+
+func TestNewService_Defaults(t *testing.T) {
+	service := NewService(0, -1)
+
+	if service.maxBytes != DefaultMaxBytes {
+		t.Errorf("maxBytes = %v, want %v", service.maxBytes, DefaultMaxBytes)
+	}
+	if service.maxDepth != DefaultMaxDepth {
+		t.Errorf("maxDepth = %v, want %v", service.maxDepth, DefaultMaxDepth)
+	}
+}
+
+func TestIsJSON(t *testing.T) {
+	tests := []struct {
+		contentType string
+		expected    bool
+	}{
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"Application/JSON", true},
+		{"application/merge-patch+json", true},
+		{"text/plain+json", false},
+		{"text/plain", false},
+		{"application/x-www-form-urlencoded", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if result := IsJSON(tt.contentType); result != tt.expected {
+			t.Errorf("IsJSON(%q) = %v, want %v", tt.contentType, result, tt.expected)
+		}
+	}
+}
+
+func TestService_Decode_Valid(t *testing.T) {
+	service := NewService(0, 0)
+
+	var payload testPayload
+	err := service.Decode("application/json", []byte(`{"email": "a@b.co", "age": 30, "profile": {"name": "Ann"}}`), &payload)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if payload.Email != "a@b.co" || payload.Age != 30 || payload.Profile.Name != "Ann" {
+		t.Errorf("Decode() payload = %+v", payload)
+	}
+}
+
+func TestService_Decode_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxBytes    int
+		maxDepth    int
+		sentinel    error
+		field       string
+		line        int
+		column      int
+		contains    string
+	}{
+		{
+			name:        "wrong content type",
+			contentType: "text/plain",
+			body:        `{}`,
+			sentinel:    ErrUnsupportedMediaType,
+		},
+		{
+			name:        "body too large",
+			contentType: "application/json",
+			body:        `{"email": "a@b.co"}`,
+			maxBytes:    5,
+			sentinel:    ErrBodyTooLarge,
+		},
+		{
+			name:        "empty body",
+			contentType: "application/json",
+			body:        "  ",
+			line:        1,
+			column:      1,
+			contains:    "must not be empty",
+		},
+		{
+			name:        "unknown field",
+			contentType: "application/json",
+			body:        "{\n  \"email\": \"a@b.co\",\n  \"admin\": true\n}",
+			field:       "admin",
+			contains:    "unknown field",
+		},
+		{
+			name:        "type mismatch reports field and location",
+			contentType: "application/json",
+			body:        "{\n  \"age\": \"thirty\"\n}",
+			field:       "age",
+			line:        2,
+			contains:    "must be of type int",
+		},
+		{
+			name:        "syntax error reports location",
+			contentType: "application/json",
+			body:        "{\n  \"email\": \"a@b.co\",\n  oops\n}",
+			line:        3,
+			contains:    "malformed JSON",
+		},
+		{
+			name:        "truncated body",
+			contentType: "application/json",
+			body:        `{"email": "a@b.co"`,
+			contains:    "malformed JSON",
+		},
+		{
+			name:        "trailing data",
+			contentType: "application/json",
+			body:        `{"email": "a@b.co"} {"email": "c@d.co"}`,
+			contains:    "single JSON value",
+		},
+		{
+			name:        "too deeply nested",
+			contentType: "application/json",
+			body:        `{"profile": {"name": [[["x"]]]}}`,
+			maxDepth:    3,
+			contains:    "maximum nesting depth of 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(tt.maxBytes, tt.maxDepth)
+
+			var payload testPayload
+			err := service.Decode(tt.contentType, []byte(tt.body), &payload)
+			if err == nil {
+				t.Fatal("Decode() should return error")
+			}
+
+			if tt.sentinel != nil {
+				if !errors.Is(err, tt.sentinel) {
+					t.Errorf("Decode() error = %v, want %v", err, tt.sentinel)
+				}
+				return
+			}
+
+			var decodeErr *Error
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("Decode() error = %v, want *Error", err)
+			}
+			if !strings.Contains(decodeErr.Message, tt.contains) {
+				t.Errorf("Message = %q, want it to contain %q", decodeErr.Message, tt.contains)
+			}
+			if tt.field != "" && decodeErr.Field != tt.field {
+				t.Errorf("Field = %q, want %q", decodeErr.Field, tt.field)
+			}
+			if tt.line != 0 && decodeErr.Line != tt.line {
+				t.Errorf("Line = %v, want %v", decodeErr.Line, tt.line)
+			}
+			if tt.column != 0 && decodeErr.Column != tt.column {
+				t.Errorf("Column = %v, want %v", decodeErr.Column, tt.column)
+			}
+		})
+	}
+}
+
+func TestError_Error(t *testing.T) {
+	withField := &Error{Message: "unknown field", Field: "admin", Line: 3, Column: 4}
+	if withField.Error() != `unknown field (field "admin" at line 3, column 4)` {
+		t.Errorf("Error() = %q", withField.Error())
+	}
+
+	withoutField := &Error{Message: "malformed JSON", Line: 1, Column: 2}
+	if withoutField.Error() != "malformed JSON (at line 1, column 2)" {
+		t.Errorf("Error() = %q", withoutField.Error())
+	}
+}
+
+// FuzzService_Decode checks that arbitrary bodies are decoded or rejected
+// with one of the documented errors, never a panic.
+// Run with go test ./pkg/decoder -fuzz FuzzService_Decode
+func FuzzService_Decode(f *testing.F) {
+	for _, seed := range []string{
+		`{"email":"a@example.com","age":30,"profile":{"name":"A"}}`,
+		`{"email":"a@example.com","extra":true}`,
+		`{"age":"thirty"}`,
+		`{"email":`,
+		`{} {}`,
+		`[[[[[[[[[[]]]]]]]]]]`,
+		"\n\t ",
+		"{\"email\":\"\xff\"}",
+		`null`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	service := NewService(1<<12, 8)
+	f.Fuzz(func(t *testing.T, body []byte) {
+		for _, dst := range []interface{}{&testPayload{}, new(interface{})} {
+			err := service.Decode("application/json", body, dst)
+			if err == nil {
+				if !json.Valid(body) {
+					t.Fatalf("Decode(%q) accepted invalid JSON", body)
+				}
+				continue
+			}
+			var decodeErr *Error
+			switch {
+			case errors.As(err, &decodeErr):
+				if decodeErr.Line < 1 || decodeErr.Column < 1 {
+					t.Fatalf("Decode(%q) error at line %d, column %d", body, decodeErr.Line, decodeErr.Column)
+				}
+			case errors.Is(err, ErrBodyTooLarge):
+			default:
+				t.Fatalf("Decode(%q) error = %v (%T), want *Error or ErrBodyTooLarge", body, err, err)
+			}
+		}
+	})
+}
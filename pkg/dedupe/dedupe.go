@@ -0,0 +1,115 @@
+// Package dedupe finds records that probably belong to the same person:
+// it puts identifiers that differ only in form in one canonical key and
+// pairs the records sharing a key.
+package dedupe
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"fiber-hello-world/pkg/textnorm"
+)
+
+// phoneDigits is how many trailing digits of a phone number are compared,
+// so national and international forms of a number match, e.g.
+// 0812345678 and +66 81 234 5678
+const phoneDigits = 9
+
+// gmailDomains deliver to the same mailbox and ignore dots in it
+var gmailDomains = []string{"gmail.com", "googlemail.com"}
+
+// EmailKey returns the mailbox an address delivers to, so addresses
+// differing only in case, a +tag or, at Gmail, dots match. It returns ""
+// for an address without a domain.
+func EmailKey(email string) string {
+	local, domain, ok := strings.Cut(strings.ToLower(textnorm.Identifier(email)), "@")
+	if !ok || local == "" || domain == "" {
+		return ""
+	}
+	local, _, _ = strings.Cut(local, "+")
+	if slices.Contains(gmailDomains, domain) {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = gmailDomains[0]
+	}
+	return local + "@" + domain
+}
+
+// PhoneKey returns the last digits of a phone number, ignoring spaces,
+// punctuation and the country code. It returns "" for numbers too short
+// to tell people apart.
+func PhoneKey(phone string) string {
+	var digits strings.Builder
+	for _, r := range textnorm.Identifier(phone) {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		}
+	}
+	key := digits.String()
+	if len(key) < phoneDigits {
+		return ""
+	}
+	return key[len(key)-phoneDigits:]
+}
+
+// Pair is two records sharing keys, A below B, with the signals of the keys
+// they share, sorted
+type Pair struct {
+	A, B    int
+	Signals []string
+}
+
+// Index collects the keys of records by signal, e.g. "phone"
+type Index struct {
+	groups map[string][]int
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{groups: make(map[string][]int)}
+}
+
+// Add indexes record id under key for signal. Empty keys are ignored.
+func (x *Index) Add(signal, key string, id int) {
+	if key == "" {
+		return
+	}
+	group := signal + "\x00" + key
+	if !slices.Contains(x.groups[group], id) {
+		x.groups[group] = append(x.groups[group], id)
+	}
+}
+
+// Pairs returns every pair of records sharing a key, ordered by A then B.
+// Keys shared by more than maxGroup records, such as the IP of an office,
+// say little about any two of them and are skipped.
+func (x *Index) Pairs(maxGroup int) []Pair {
+	signals := make(map[[2]int][]string)
+	for group, ids := range x.groups {
+		if len(ids) < 2 || len(ids) > maxGroup {
+			continue
+		}
+		signal, _, _ := strings.Cut(group, "\x00")
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				key := [2]int{min(a, b), max(a, b)}
+				if !slices.Contains(signals[key], signal) {
+					signals[key] = append(signals[key], signal)
+				}
+			}
+		}
+	}
+
+	pairs := make([]Pair, 0, len(signals))
+	for key, shared := range signals {
+		slices.Sort(shared)
+		pairs = append(pairs, Pair{A: key[0], B: key[1], Signals: shared})
+	}
+	slices.SortFunc(pairs, func(p, q Pair) int {
+		if p.A != q.A {
+			return p.A - q.A
+		}
+		return p.B - q.B
+	})
+	return pairs
+}
@@ -0,0 +1,67 @@
+package dedupe
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEmailKey(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"Jane.Doe@Example.com", "jane.doe@example.com"},
+		{"jane.doe+shop@example.com", "jane.doe@example.com"},
+		{"J.a.n.e+x@googlemail.com", "jane@gmail.com"},
+		{"jane@ｇｍａｉｌ.com", "jane@gmail.com"},
+		{" jane​@example.com ", "jane@example.com"},
+		{"jane", ""},
+		{"@example.com", ""},
+	}
+	for _, tt := range tests {
+		if got := EmailKey(tt.email); got != tt.want {
+			t.Errorf("EmailKey(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestPhoneKey(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{"0812345678", "812345678"},
+		{"+66 81 234 5678", "812345678"},
+		{"(081) 234-5678", "812345678"},
+		{"０８１２３４５６７８", "812345678"},
+		{"1234", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := PhoneKey(tt.phone); got != tt.want {
+			t.Errorf("PhoneKey(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestIndex_Pairs(t *testing.T) {
+	index := NewIndex()
+	index.Add("phone", "812345678", 3)
+	index.Add("phone", "812345678", 1)
+	index.Add("email", "jane@example.com", 1)
+	index.Add("email", "jane@example.com", 3)
+	index.Add("email", "jane@example.com", 3)
+	index.Add("device", "", 4)
+	index.Add("device", "", 5)
+	// An office IP shared by more than maxGroup users is skipped
+	for id := 10; id < 14; id++ {
+		index.Add("device", "203.0.113.7|Safari", id)
+	}
+	index.Add("device", "198.51.100.2|Firefox", 2)
+	index.Add("device", "198.51.100.2|Firefox", 1)
+
+	got := fmt.Sprint(index.Pairs(3))
+	if want := "[{1 2 [device]} {1 3 [email phone]}]"; got != want {
+		t.Errorf("Pairs() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+// Package depcheck checks the optional services a server uses, such as
+// Redis or an SMTP server, so the features that need an unreachable one are
+// disabled rather than failing every request, and come back once it
+// answers again.
+package depcheck
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds each check when New is given none
+const DefaultTimeout = 2 * time.Second
+
+// Pinger is implemented by clients of services that can be checked, e.g.
+// a Redis cache or an SMTP mailer
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Dependency is an optional service and the features that need it
+type Dependency struct {
+	// Name identifies the dependency, e.g. "redis:claims-cache"
+	Name string
+	// Features are disabled, or degraded, while the dependency is down
+	Features []string
+	// Check returns an error when the dependency cannot be used
+	Check func(ctx context.Context) error
+}
+
+// Status is the state of a dependency as of its last check
+type Status struct {
+	Name     string   `json:"name"`
+	Features []string `json:"features"`
+	// Up is true until a check fails, so features run as usual before the
+	// first check
+	Up bool `json:"up"`
+	// Error is why the last check failed
+	Error string `json:"error,omitempty"`
+	// Since is when the dependency last went up or down; zero before the
+	// first check
+	Since time.Time `json:"since"`
+	// CheckedAt is when the dependency was last checked; zero before the
+	// first check
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Checker checks the registered dependencies and remembers which are down
+type Checker struct {
+	timeout time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	deps     []Dependency
+	statuses map[string]*Status
+}
+
+// New creates a checker bounding each check by timeout, or DefaultTimeout
+// when it is not positive
+func New(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout, now: time.Now, statuses: make(map[string]*Status)}
+}
+
+// Register adds a dependency, replacing any of the same name
+func (c *Checker) Register(dep Dependency) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deps = slices.DeleteFunc(c.deps, func(d Dependency) bool { return d.Name == dep.Name })
+	c.deps = append(c.deps, dep)
+	c.statuses[dep.Name] = &Status{Name: dep.Name, Features: dep.Features, Up: true}
+}
+
+// RegisterPinger adds a dependency checked with pinger's Ping
+func (c *Checker) RegisterPinger(name string, pinger Pinger, features ...string) {
+	c.Register(Dependency{Name: name, Features: features, Check: pinger.Ping})
+}
+
+// Len returns the number of registered dependencies
+func (c *Checker) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.deps)
+}
+
+// CheckAll checks every dependency at once and logs those that went down
+// or came back. It returns once all checks have finished.
+func (c *Checker) CheckAll(ctx context.Context) {
+	c.mu.Lock()
+	deps := slices.Clone(c.deps)
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, dep := range deps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+			c.record(dep, dep.Check(checkCtx))
+		}()
+	}
+	wg.Wait()
+}
+
+// record stores the result of a check, logging a change of state
+func (c *Checker) record(dep Dependency, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status, ok := c.statuses[dep.Name]
+	if !ok {
+		return
+	}
+	now := c.now()
+	up := err == nil
+	if up != status.Up || status.Since.IsZero() {
+		status.Since = now
+		switch {
+		case !up:
+			log.Printf("Dependency %s is down, disabling %s: %v", dep.Name, featureList(dep.Features), err)
+		case !status.Up:
+			log.Printf("Dependency %s is back, enabling %s", dep.Name, featureList(dep.Features))
+		}
+	}
+	status.Up, status.CheckedAt, status.Error = up, now, ""
+	if err != nil {
+		status.Error = err.Error()
+	}
+}
+
+// featureList names features in a log line
+func featureList(features []string) string {
+	if len(features) == 0 {
+		return "nothing"
+	}
+	return strings.Join(features, ", ")
+}
+
+// Up reports whether the named dependency passed its last check. Unknown
+// and unchecked dependencies are up.
+func (c *Checker) Up(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status, ok := c.statuses[name]
+	return !ok || status.Up
+}
+
+// Degraded reports whether any dependency is down
+func (c *Checker) Degraded() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, status := range c.statuses {
+		if !status.Up {
+			return true
+		}
+	}
+	return false
+}
+
+// Statuses returns the state of every dependency, sorted by name
+func (c *Checker) Statuses() []Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	statuses := make([]Status, 0, len(c.statuses))
+	for _, status := range c.statuses {
+		statuses = append(statuses, *status)
+	}
+	slices.SortFunc(statuses, func(a, b Status) int { return strings.Compare(a.Name, b.Name) })
+	return statuses
+}
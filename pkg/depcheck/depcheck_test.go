@@ -0,0 +1,79 @@
+package depcheck
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChecker_CheckAll(t *testing.T) {
+	checker := New(time.Second)
+	var redisDown atomic.Bool
+	checker.Register(Dependency{Name: "redis", Features: []string{"shared cache"}, Check: func(context.Context) error {
+		if redisDown.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	}})
+	checker.Register(Dependency{Name: "smtp", Features: []string{"digests"}, Check: func(context.Context) error { return nil }})
+
+	// Dependencies are up until a check fails
+	redisDown.Store(true)
+	if !checker.Up("redis") || checker.Degraded() {
+		t.Fatal("unchecked dependencies should be up")
+	}
+
+	checker.CheckAll(context.Background())
+	if checker.Up("redis") || !checker.Up("smtp") || !checker.Degraded() {
+		t.Fatalf("Statuses() = %+v, want redis down", checker.Statuses())
+	}
+	statuses := checker.Statuses()
+	if len(statuses) != 2 || statuses[0].Name != "redis" || statuses[0].Error != "connection refused" || statuses[0].Since.IsZero() {
+		t.Errorf("Statuses() = %+v", statuses)
+	}
+	downSince := statuses[0].Since
+
+	// A dependency that answers again is enabled on the next check
+	redisDown.Store(false)
+	checker.CheckAll(context.Background())
+	status := checker.Statuses()[0]
+	if !status.Up || status.Error != "" || status.Since.Before(downSince) || checker.Degraded() {
+		t.Errorf("after recovery = %+v", status)
+	}
+	if !checker.Up("unknown") {
+		t.Error("unknown dependencies should be up")
+	}
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	checker := New(20 * time.Millisecond)
+	checker.Register(Dependency{Name: "slow", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	start := time.Now()
+	checker.CheckAll(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("CheckAll() took %v, want the check timed out", elapsed)
+	}
+	if checker.Up("slow") {
+		t.Error("a check that timed out should mark the dependency down")
+	}
+}
+
+type fakePinger struct{ err error }
+
+func (p fakePinger) Ping(context.Context) error { return p.err }
+
+func TestChecker_RegisterPinger(t *testing.T) {
+	checker := New(0)
+	checker.RegisterPinger("redis", fakePinger{errors.New("down")}, "shared cache")
+	checker.RegisterPinger("redis", fakePinger{}, "shared cache")
+	checker.CheckAll(context.Background())
+	if checker.Len() != 1 || !checker.Up("redis") {
+		t.Errorf("Statuses() = %+v, want the replacement registered", checker.Statuses())
+	}
+}
@@ -0,0 +1,282 @@
+// Package deprecation marks routes deprecated and counts who still calls
+// them, so clients can be moved from one API version to the next before the
+// old routes are removed.
+package deprecation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidPolicy is returned by Register for a policy that cannot be applied
+var ErrInvalidPolicy = errors.New("invalid deprecation policy")
+
+// OtherClients collects the calls of clients past MaxClients on a route
+const OtherClients = "other"
+
+// MaxClients bounds the clients counted per route, so clients that make up
+// names cannot grow the counts without limit
+const MaxClients = 100
+
+// Policy marks a route deprecated
+type Policy struct {
+	// Method and Path name the route as it was registered, e.g.
+	// GET /v1/users/:id. Path segments starting with ":" match any one
+	// segment and a final "*" or "+" matches the rest of the path.
+	Method string
+	Path   string
+	// Since is when the route was deprecated, sent in the Deprecation header
+	Since time.Time
+	// Sunset is when the route stops working, sent in the Sunset header.
+	// From then on the route answers 410 Gone. Zero keeps it working.
+	Sunset time.Time
+	// Link is a page explaining the deprecation, if any
+	Link string
+	// Successor is the route that replaces this one, if any
+	Successor string
+	// Message overrides the warning added to responses
+	Message string
+}
+
+// Validate checks that the policy names a route and its dates are set
+func (p Policy) Validate() error {
+	switch {
+	case p.Method == "":
+		return fmt.Errorf("%w: method is required", ErrInvalidPolicy)
+	case !strings.HasPrefix(p.Path, "/"):
+		return fmt.Errorf("%w: path must start with /", ErrInvalidPolicy)
+	case p.Since.IsZero():
+		return fmt.Errorf("%w: since is required", ErrInvalidPolicy)
+	case !p.Sunset.IsZero() && !p.Sunset.After(p.Since):
+		return fmt.Errorf("%w: sunset must be after since", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// Route returns the route as "METHOD path"
+func (p Policy) Route() string {
+	return strings.ToUpper(p.Method) + " " + p.Path
+}
+
+// Warning returns the message telling clients about the deprecation
+func (p Policy) Warning() string {
+	if p.Message != "" {
+		return p.Message
+	}
+	warning := p.Route() + " is deprecated"
+	if p.Successor != "" {
+		warning += "; use " + p.Successor + " instead"
+	}
+	if !p.Sunset.IsZero() {
+		warning += ". It will be removed on " + p.Sunset.UTC().Format(time.DateOnly)
+	}
+	return warning + "."
+}
+
+// Headers returns the Deprecation (RFC 9745), Sunset (RFC 8594) and Link
+// response headers for the route
+func (p Policy) Headers() map[string]string {
+	headers := map[string]string{
+		"Deprecation": fmt.Sprintf("@%d", p.Since.Unix()),
+	}
+	if !p.Sunset.IsZero() {
+		headers["Sunset"] = p.Sunset.UTC().Format(http.TimeFormat)
+	}
+	var links []string
+	if p.Link != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"deprecation\"; type=\"text/html\"", p.Link))
+	}
+	if p.Successor != "" {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"successor-version\"", p.Successor))
+	}
+	if len(links) > 0 {
+		headers["Link"] = strings.Join(links, ", ")
+	}
+	return headers
+}
+
+// SunsetAt reports whether the route no longer works at now
+func (p Policy) SunsetAt(now time.Time) bool {
+	return !p.Sunset.IsZero() && !now.Before(p.Sunset)
+}
+
+// matches reports whether the policy applies to a request. Like the router,
+// it ignores case and a trailing slash, and HEAD requests match GET routes.
+func (p Policy) matches(method, path string) bool {
+	if !strings.EqualFold(p.Method, method) && !(strings.EqualFold(p.Method, http.MethodGet) && method == http.MethodHead) {
+		return false
+	}
+	pattern := strings.Split(strings.Trim(p.Path, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range pattern {
+		if (part == "*" || part == "+") && i == len(pattern)-1 {
+			return part == "*" || (i < len(segments) && segments[i] != "")
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if strings.HasPrefix(part, ":") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if !strings.EqualFold(part, segments[i]) {
+			return false
+		}
+	}
+	return len(segments) == len(pattern)
+}
+
+// ClientUsage counts the calls of one client to a deprecated route
+type ClientUsage struct {
+	Client   string
+	Requests int64
+	LastSeen time.Time
+}
+
+// Usage is a deprecated route with the clients that called it, most
+// requests first
+type Usage struct {
+	Policy   Policy
+	Requests int64
+	Clients  []ClientUsage
+}
+
+// route is a registered policy with its counts
+type route struct {
+	policy  Policy
+	clients map[string]*ClientUsage
+}
+
+// Registry holds the deprecated routes and counts their calls. It is safe
+// for concurrent use.
+type Registry struct {
+	mu     sync.Mutex
+	routes []*route
+	now    func() time.Time
+}
+
+// New creates a registry with no deprecated routes
+func New() *Registry {
+	return &Registry{now: time.Now}
+}
+
+// Register marks a route deprecated
+func (r *Registry) Register(policy Policy) error {
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", policy.Route(), err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.routes {
+		if strings.EqualFold(existing.policy.Route(), policy.Route()) {
+			return fmt.Errorf("%w: %s is deprecated twice", ErrInvalidPolicy, policy.Route())
+		}
+	}
+	r.routes = append(r.routes, &route{policy: policy, clients: make(map[string]*ClientUsage)})
+	return nil
+}
+
+// Policies returns the registered policies in the order registered
+func (r *Registry) Policies() []Policy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	policies := make([]Policy, len(r.routes))
+	for i, route := range r.routes {
+		policies[i] = route.policy
+	}
+	return policies
+}
+
+// Match returns the policy of the deprecated route a request is for
+func (r *Registry) Match(method, path string) (Policy, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, route := range r.routes {
+		if route.policy.matches(method, path) {
+			return route.policy, true
+		}
+	}
+	return Policy{}, false
+}
+
+// Record counts a call of client to the deprecated route of policy
+func (r *Registry) Record(policy Policy, client string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, route := range r.routes {
+		if route.policy.Route() != policy.Route() {
+			continue
+		}
+		usage, ok := route.clients[client]
+		if !ok {
+			if len(route.clients) >= MaxClients {
+				client = OtherClients
+			}
+			if usage, ok = route.clients[client]; !ok {
+				usage = &ClientUsage{Client: client}
+				route.clients[client] = usage
+			}
+		}
+		usage.Requests++
+		usage.LastSeen = r.now().UTC()
+		return
+	}
+}
+
+// Report returns the calls to each deprecated route, in the order
+// registered
+func (r *Registry) Report() []Usage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := make([]Usage, 0, len(r.routes))
+	for _, route := range r.routes {
+		usage := Usage{Policy: route.policy, Clients: make([]ClientUsage, 0, len(route.clients))}
+		for _, client := range route.clients {
+			usage.Requests += client.Requests
+			usage.Clients = append(usage.Clients, *client)
+		}
+		sort.Slice(usage.Clients, func(i, j int) bool {
+			if usage.Clients[i].Requests != usage.Clients[j].Requests {
+				return usage.Clients[i].Requests > usage.Clients[j].Requests
+			}
+			return usage.Clients[i].Client < usage.Clients[j].Client
+		})
+		report = append(report, usage)
+	}
+	return report
+}
+
+// WritePrometheus writes the calls to deprecated routes per client as
+// counters in the Prometheus text exposition format
+func WritePrometheus(w io.Writer, report []Usage) error {
+	var err error
+	write := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	write("# HELP api_deprecated_requests_total Requests to deprecated routes.\n# TYPE api_deprecated_requests_total counter\n")
+	for _, usage := range report {
+		for _, client := range usage.Clients {
+			write("api_deprecated_requests_total{method=%q,route=%q,client=%q} %d\n",
+				strings.ToUpper(usage.Policy.Method), usage.Policy.Path, client.Client, client.Requests)
+		}
+	}
+	write("# HELP api_deprecated_route_sunset_seconds When a deprecated route stops working, as a Unix time.\n# TYPE api_deprecated_route_sunset_seconds gauge\n")
+	for _, usage := range report {
+		if !usage.Policy.Sunset.IsZero() {
+			write("api_deprecated_route_sunset_seconds{method=%q,route=%q} %d\n",
+				strings.ToUpper(usage.Policy.Method), usage.Policy.Path, usage.Policy.Sunset.Unix())
+		}
+	}
+	return err
+}
@@ -0,0 +1,144 @@
+package deprecation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	since  = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset = time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+	}{
+		{"no method", Policy{Path: "/v1/me", Since: since}},
+		{"relative path", Policy{Method: "GET", Path: "v1/me", Since: since}},
+		{"no since", Policy{Method: "GET", Path: "/v1/me"}},
+		{"sunset before since", Policy{Method: "GET", Path: "/v1/me", Since: sunset, Sunset: since}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); !errors.Is(err, ErrInvalidPolicy) {
+				t.Errorf("Validate() error = %v, want ErrInvalidPolicy", err)
+			}
+		})
+	}
+}
+
+func TestPolicy_Headers(t *testing.T) {
+	policy := Policy{Method: "GET", Path: "/v1/me", Since: since, Sunset: sunset, Link: "https://docs.example.com/v2", Successor: "/me"}
+	headers := policy.Headers()
+	if headers["Deprecation"] != "@1735689600" {
+		t.Errorf("Deprecation = %q", headers["Deprecation"])
+	}
+	if headers["Sunset"] != "Tue, 01 Jul 2025 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", headers["Sunset"])
+	}
+	if headers["Link"] != `<https://docs.example.com/v2>; rel="deprecation"; type="text/html", </me>; rel="successor-version"` {
+		t.Errorf("Link = %q", headers["Link"])
+	}
+	if want := "GET /v1/me is deprecated; use /me instead. It will be removed on 2025-07-01."; policy.Warning() != want {
+		t.Errorf("Warning() = %q, want %q", policy.Warning(), want)
+	}
+
+	headers = Policy{Method: "GET", Path: "/v1/me", Since: since}.Headers()
+	if len(headers) != 1 {
+		t.Errorf("headers without sunset or links = %v", headers)
+	}
+	if got := (Policy{Message: "Use v2."}).Warning(); got != "Use v2." {
+		t.Errorf("Warning() = %q, want the message", got)
+	}
+	if policy.SunsetAt(sunset.Add(-time.Second)) || !policy.SunsetAt(sunset) {
+		t.Error("SunsetAt() should turn true at the sunset")
+	}
+}
+
+func TestRegistry_Match(t *testing.T) {
+	registry := New()
+	for _, policy := range []Policy{
+		{Method: "GET", Path: "/v1/users/:id", Since: since},
+		{Method: "POST", Path: "/v1/files/*", Since: since},
+		{Method: "DELETE", Path: "/v1/items/+", Since: since},
+	} {
+		if err := registry.Register(policy); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+	if err := registry.Register(Policy{Method: "get", Path: "/v1/users/:id", Since: since}); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("Register() twice error = %v, want ErrInvalidPolicy", err)
+	}
+
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{"GET", "/v1/users/7", true},
+		{"GET", "/V1/Users/7/", true},
+		{"HEAD", "/v1/users/7", true},
+		{"PUT", "/v1/users/7", false},
+		{"GET", "/v1/users", false},
+		{"GET", "/v1/users/7/roles", false},
+		{"POST", "/v1/files", true},
+		{"POST", "/v1/files/a/b", true},
+		{"DELETE", "/v1/items", false},
+		{"DELETE", "/v1/items/a/b", true},
+		{"GET", "/v2/users/7", false},
+	}
+	for _, tt := range tests {
+		if _, got := registry.Match(tt.method, tt.path); got != tt.want {
+			t.Errorf("Match(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_Record(t *testing.T) {
+	registry := New()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+	users := Policy{Method: "GET", Path: "/v1/users/:id", Since: since, Sunset: sunset}
+	files := Policy{Method: "POST", Path: "/v1/files", Since: since}
+	registry.Register(users)
+	registry.Register(files)
+
+	registry.Record(users, "okhttp")
+	registry.Record(users, "ios-app")
+	registry.Record(users, "ios-app")
+	// Clients past the limit are counted together
+	for i := 0; i < MaxClients+5; i++ {
+		registry.Record(files, fmt.Sprintf("client-%d", i))
+	}
+
+	report := registry.Report()
+	if len(report) != 2 || report[0].Policy.Path != "/v1/users/:id" {
+		t.Fatalf("Report() = %+v", report)
+	}
+	if report[0].Requests != 3 || len(report[0].Clients) != 2 || report[0].Clients[0].Client != "ios-app" ||
+		report[0].Clients[0].Requests != 2 || !report[0].Clients[0].LastSeen.Equal(now) {
+		t.Errorf("users usage = %+v", report[0])
+	}
+	if report[1].Requests != MaxClients+5 || len(report[1].Clients) != MaxClients+1 || report[1].Clients[0].Client != OtherClients {
+		t.Errorf("files usage: %d requests from %d clients, first %+v", report[1].Requests, len(report[1].Clients), report[1].Clients[0])
+	}
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, report[:1]); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`api_deprecated_requests_total{method="GET",route="/v1/users/:id",client="ios-app"} 2`,
+		`api_deprecated_requests_total{method="GET",route="/v1/users/:id",client="okhttp"} 1`,
+		`api_deprecated_route_sunset_seconds{method="GET",route="/v1/users/:id"} 1751328000`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("WritePrometheus() is missing %q in\n%s", line, buf.String())
+		}
+	}
+}
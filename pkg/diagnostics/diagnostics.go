@@ -0,0 +1,202 @@
+// Package diagnostics gathers what operators look at first when triaging an
+// instance: goroutines, heap, cache sizes, queue depths, the database pool
+// and a summary of recent errors, so they need not attach a profiler.
+package diagnostics
+
+import (
+	"database/sql"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"fiber-hello-world/pkg/autoscale"
+	"fiber-hello-world/pkg/hashpool"
+)
+
+// maxErrorSummaries bounds the distinct errors kept; the least recently seen
+// is dropped to make room
+const maxErrorSummaries = 100
+
+// Heap describes the Go heap
+type Heap struct {
+	// Alloc is the bytes of live and not yet collected objects
+	Alloc uint64
+	// InUse is the bytes of in-use heap spans
+	InUse uint64
+	// Sys is the bytes of heap memory obtained from the OS
+	Sys     uint64
+	Objects uint64
+	NumGC   uint32
+	// LastGC is when the last collection finished; zero before the first
+	LastGC time.Time
+	// PauseTotal is the stop-the-world time of every collection so far
+	PauseTotal time.Duration
+}
+
+// ErrorSummary counts the occurrences of one error from one source
+type ErrorSummary struct {
+	// Source is where the error happened, e.g. "GET /users/:id" or
+	// "worker digests"
+	Source string
+	// Message is the error, with numbers replaced by N so occurrences that
+	// differ only by an ID are counted together
+	Message   string
+	Count     int64
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Report is a snapshot of an instance's state
+type Report struct {
+	Goroutines int
+	Heap       Heap
+	// Caches maps each registered cache to its number of entries
+	Caches map[string]int
+	// Queues maps each background queue to its number of waiting jobs;
+	// QueueError is set instead when they could not be read
+	Queues     map[string]int
+	QueueError string
+	HashPool   hashpool.Stats
+	// DB is the database connection pool; zero without a database
+	DB sql.DBStats
+	// Errors are the recent errors, most recently seen first
+	Errors []ErrorSummary
+}
+
+// Collector assembles reports and keeps the summary of recent errors
+type Collector struct {
+	db      *sql.DB
+	signals *autoscale.Signals
+	now     func() time.Time
+
+	mu     sync.Mutex
+	caches map[string]func() int
+	errors map[errorKey]*ErrorSummary
+}
+
+// errorKey identifies the occurrences of an error counted together
+type errorKey struct {
+	source, message string
+}
+
+// New creates a collector reporting on db and on the queues and hashing pool
+// of signals; either may be nil
+func New(db *sql.DB, signals *autoscale.Signals) *Collector {
+	return &Collector{
+		db:      db,
+		signals: signals,
+		now:     time.Now,
+		caches:  make(map[string]func() int),
+		errors:  make(map[errorKey]*ErrorSummary),
+	}
+}
+
+// RegisterCache adds a cache reported under name, size returning its
+// number of entries
+func (c *Collector) RegisterCache(name string, size func() int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.caches[name] = size
+}
+
+// RecordError counts an error from source in the summary
+func (c *Collector) RecordError(source string, err error) {
+	if err == nil {
+		return
+	}
+	key := errorKey{source, normalize(err.Error())}
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	summary, ok := c.errors[key]
+	if !ok {
+		if len(c.errors) >= maxErrorSummaries {
+			c.dropOldestError()
+		}
+		summary = &ErrorSummary{Source: key.source, Message: key.message, FirstSeen: now}
+		c.errors[key] = summary
+	}
+	summary.Count++
+	summary.LastSeen = now
+}
+
+// dropOldestError removes the least recently seen error. c.mu is held.
+func (c *Collector) dropOldestError() {
+	var oldest errorKey
+	var seen time.Time
+	for key, summary := range c.errors {
+		if seen.IsZero() || summary.LastSeen.Before(seen) {
+			oldest, seen = key, summary.LastSeen
+		}
+	}
+	delete(c.errors, oldest)
+}
+
+// normalize replaces each run of digits with N
+func normalize(message string) string {
+	isDigit := func(b byte) bool { return b >= '0' && b <= '9' }
+	out := make([]byte, 0, len(message))
+	for i := 0; i < len(message); i++ {
+		switch {
+		case !isDigit(message[i]):
+			out = append(out, message[i])
+		case i == 0 || !isDigit(message[i-1]):
+			out = append(out, 'N')
+		}
+	}
+	return string(out)
+}
+
+// Report reads every figure. A queue that cannot be read is reported in
+// QueueError rather than failing the report, which is needed most when
+// something is broken.
+func (c *Collector) Report() *Report {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	report := &Report{
+		Goroutines: runtime.NumGoroutine(),
+		Heap: Heap{
+			Alloc:      mem.HeapAlloc,
+			InUse:      mem.HeapInuse,
+			Sys:        mem.HeapSys,
+			Objects:    mem.HeapObjects,
+			NumGC:      mem.NumGC,
+			PauseTotal: time.Duration(mem.PauseTotalNs),
+		},
+		Caches: make(map[string]int),
+		Queues: make(map[string]int),
+	}
+	if mem.LastGC > 0 {
+		report.Heap.LastGC = time.Unix(0, int64(mem.LastGC))
+	}
+	if c.db != nil {
+		report.DB = c.db.Stats()
+	}
+	if c.signals != nil {
+		report.HashPool = c.signals.HashPool()
+		if signals, err := c.signals.Report(); err != nil {
+			report.QueueError = err.Error()
+		} else {
+			report.Queues = signals.Queues
+		}
+	}
+
+	c.mu.Lock()
+	sizes := make(map[string]func() int, len(c.caches))
+	for name, size := range c.caches {
+		sizes[name] = size
+	}
+	for _, summary := range c.errors {
+		report.Errors = append(report.Errors, *summary)
+	}
+	c.mu.Unlock()
+
+	// Sizes are read unlocked, as caches take their own locks
+	for name, size := range sizes {
+		report.Caches[name] = size()
+	}
+	slices.SortFunc(report.Errors, func(a, b ErrorSummary) int { return b.LastSeen.Compare(a.LastSeen) })
+	return report
+}
@@ -0,0 +1,86 @@
+package diagnostics
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"fiber-hello-world/pkg/autoscale"
+	"fiber-hello-world/pkg/hashpool"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestCollector_Report(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	signals := autoscale.New(hashpool.New(4))
+	signals.RegisterQueue("admin_actions", func() (int, error) { return 3, nil })
+	collector := New(db, signals)
+	collector.RegisterCache("claims", func() int { return 42 })
+
+	report := collector.Report()
+	if report.Goroutines == 0 || report.Heap.Alloc == 0 || report.Heap.Sys < report.Heap.InUse {
+		t.Errorf("runtime figures = %d goroutines, %+v", report.Goroutines, report.Heap)
+	}
+	if report.Caches["claims"] != 42 || report.Queues["admin_actions"] != 3 || report.HashPool.Size != 4 {
+		t.Errorf("Report() = caches %v, queues %v, hash pool %+v", report.Caches, report.Queues, report.HashPool)
+	}
+	if report.DB.OpenConnections != 1 {
+		t.Errorf("DB = %+v, want 1 open connection", report.DB)
+	}
+
+	// A queue that cannot be read does not fail the report
+	signals.RegisterQueue("hook_deliveries", func() (int, error) { return 0, errors.New("database is locked") })
+	if report := collector.Report(); report.QueueError == "" || report.Caches["claims"] != 42 {
+		t.Errorf("Report() with a failing queue = %+v", report)
+	}
+
+	if report := New(nil, nil).Report(); report.DB.OpenConnections != 0 || len(report.Queues) != 0 {
+		t.Errorf("Report() without a database or signals = %+v", report)
+	}
+}
+
+func TestCollector_RecordError(t *testing.T) {
+	collector := New(nil, nil)
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	collector.now = func() time.Time { return now }
+
+	collector.RecordError("worker digests", errors.New("dial tcp 10.0.0.5:25: connection refused"))
+	now = now.Add(time.Minute)
+	collector.RecordError("GET /users/:id", fmt.Errorf("failed to get user %d", 7))
+	now = now.Add(time.Minute)
+	collector.RecordError("GET /users/:id", fmt.Errorf("failed to get user %d", 1234))
+	collector.RecordError("GET /users/:id", nil)
+
+	errs := collector.Report().Errors
+	if len(errs) != 2 {
+		t.Fatalf("Errors = %+v, want 2 summaries", errs)
+	}
+	if got := errs[0]; got.Source != "GET /users/:id" || got.Message != "failed to get user N" || got.Count != 2 ||
+		!got.LastSeen.Equal(now) || !got.FirstSeen.Equal(now.Add(-time.Minute)) {
+		t.Errorf("newest summary = %+v", got)
+	}
+	if got := errs[1]; got.Message != "dial tcp N.N.N.N:N: connection refused" || got.Count != 1 {
+		t.Errorf("oldest summary = %+v", got)
+	}
+
+	// The least recently seen error makes room for a new one
+	for i := range maxErrorSummaries {
+		now = now.Add(time.Second)
+		collector.RecordError(fmt.Sprintf("worker %d", i), errors.New("database is locked"))
+	}
+	errs = collector.Report().Errors
+	if len(errs) != maxErrorSummaries || errs[len(errs)-1].Source == "worker digests" {
+		t.Errorf("kept %d summaries, oldest %+v", len(errs), errs[len(errs)-1])
+	}
+}
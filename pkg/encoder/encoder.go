@@ -0,0 +1,192 @@
+// Package encoder encodes JSON response bodies. Types on the hot path
+// implement Appender and are encoded without reflection into pooled
+// buffers; other values fall back to encoding/json. Both produce the same
+// bytes as encoding/json.
+package encoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Names of the encoders, as set in JSON_ENCODER
+const (
+	// Standard encodes every value with encoding/json
+	Standard = "std"
+	// Fast encodes Appenders directly and other values with encoding/json
+	Fast = "fast"
+)
+
+// ErrUnknownEncoder is returned for an encoder name other than Standard and Fast
+var ErrUnknownEncoder = errors.New("unknown JSON encoder")
+
+// Appender is implemented by types that append their own JSON encoding,
+// exactly as encoding/json would encode them
+type Appender interface {
+	AppendJSON(dst []byte) ([]byte, error)
+}
+
+// New returns the named encoder, in the form fiber.Config's JSONEncoder takes
+func New(name string) (func(v interface{}) ([]byte, error), error) {
+	switch name {
+	case Standard:
+		return json.Marshal, nil
+	case Fast:
+		return Marshal, nil
+	}
+	return nil, fmt.Errorf("%w %q: want %s or %s", ErrUnknownEncoder, name, Standard, Fast)
+}
+
+// Buffer sizes. Buffers that grew past maxPooledBuffer, e.g. for an
+// export, are left to the garbage collector rather than pinned in the pool.
+const (
+	initialBuffer   = 1 << 10
+	maxPooledBuffer = 64 << 10
+)
+
+var buffers = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, initialBuffer)
+		return &buf
+	},
+}
+
+// Marshal encodes v. Appenders are encoded into a pooled scratch buffer and
+// copied into a slice of exactly their size, so a response costs one
+// allocation however often the buffer grew. Other values use json.Marshal.
+func Marshal(v interface{}) ([]byte, error) {
+	appender, ok := asAppender(v)
+	if !ok {
+		return json.Marshal(v)
+	}
+
+	bufp := buffers.Get().(*[]byte)
+	buf, err := appender.AppendJSON((*bufp)[:0])
+	var out []byte
+	if err == nil {
+		out = make([]byte, len(buf))
+		copy(out, buf)
+	}
+	if cap(buf) <= maxPooledBuffer {
+		*bufp = buf
+		buffers.Put(bufp)
+	}
+	return out, err
+}
+
+// AppendValue appends the encoding of v: directly for Appenders, with
+// encoding/json otherwise. It is meant for interface{} fields.
+func AppendValue(dst []byte, v interface{}) ([]byte, error) {
+	if appender, ok := asAppender(v); ok {
+		return appender.AppendJSON(dst)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, data...), nil
+}
+
+// asAppender returns v as an Appender unless it is a nil pointer, which
+// encoding/json writes as null
+func asAppender(v interface{}) (Appender, bool) {
+	appender, ok := v.(Appender)
+	if !ok {
+		return nil, false
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, false
+	}
+	return appender, true
+}
+
+// AppendInt appends n
+func AppendInt(dst []byte, n int64) []byte {
+	return strconv.AppendInt(dst, n, 10)
+}
+
+// AppendBool appends b
+func AppendBool(dst []byte, b bool) []byte {
+	return strconv.AppendBool(dst, b)
+}
+
+// AppendTime appends t as time.Time's MarshalJSON does: RFC 3339 with
+// nanoseconds, failing for years outside 0 to 9999
+func AppendTime(dst []byte, t time.Time) ([]byte, error) {
+	if year := t.Year(); year < 0 || year > 9999 {
+		return dst, fmt.Errorf("time %v: year outside of range [0,9999]", t)
+	}
+	dst = append(dst, '"')
+	dst = t.AppendFormat(dst, time.RFC3339Nano)
+	return append(dst, '"'), nil
+}
+
+const hex = "0123456789abcdef"
+
+// invalidUTF8 replaces each invalid byte. encoding/json writes an escaped
+// U+FFFD, or the raw rune when built on the v2 implementation, so the
+// replacement is taken from encoding/json itself.
+var invalidUTF8 = func() string {
+	data, _ := json.Marshal("\xff")
+	return string(data[1 : len(data)-1])
+}()
+
+// AppendString appends s as a JSON string, escaped as encoding/json does:
+// HTML characters, U+2028 and U+2029 are escaped, and invalid UTF-8 is
+// replaced by U+FFFD
+func AppendString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, invalidUTF8...)
+			i += size
+			start = i
+			continue
+		}
+		if c == '\u2028' || c == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
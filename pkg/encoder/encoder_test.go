@@ -0,0 +1,156 @@
+package encoder
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type appenderStub struct {
+	Name string
+	Err  error `json:"-"`
+}
+
+func (a appenderStub) AppendJSON(dst []byte) ([]byte, error) {
+	if a.Err != nil {
+		return dst, a.Err
+	}
+	dst = append(dst, `{"Name":`...)
+	dst = AppendString(dst, a.Name)
+	return append(dst, '}'), nil
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{Standard, false},
+		{Fast, false},
+		{"", true},
+		{"sonic", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, err := New(tt.name)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnknownEncoder) {
+					t.Fatalf("New(%q) error = %v, want ErrUnknownEncoder", tt.name, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New(%q) error = %v", tt.name, err)
+			}
+			data, err := fn(appenderStub{Name: "x"})
+			if err != nil || string(data) != `{"Name":"x"}` {
+				t.Errorf("encode = %s, %v", data, err)
+			}
+		})
+	}
+}
+
+func TestAppendString(t *testing.T) {
+	inputs := []string{
+		"",
+		"plain ascii",
+		`quote " and backslash \`,
+		"<script>alert('x') & more</script>",
+		"tab\tnewline\ncr\rbackspace\bformfeed\f",
+		"\x00\x01\x1f\x7f",
+		"unicode ✓ 日本語 🎉",
+		"separators   and  ",
+		"invalid \xff\xfe utf8 \xc3",
+		strings.Repeat("long ", 100),
+	}
+
+	for _, in := range inputs {
+		want, _ := json.Marshal(in)
+		if got := AppendString(nil, in); string(got) != string(want) {
+			t.Errorf("AppendString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestAppendTime(t *testing.T) {
+	times := []time.Time{
+		time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.FixedZone("x", 7*3600)),
+		{},
+	}
+	for _, tm := range times {
+		want, _ := json.Marshal(tm)
+		got, err := AppendTime(nil, tm)
+		if err != nil || string(got) != string(want) {
+			t.Errorf("AppendTime(%v) = %s, %v, want %s", tm, got, err, want)
+		}
+	}
+
+	if _, err := AppendTime(nil, time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("AppendTime() error = nil for year 10000")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	data, err := Marshal(map[string]int{"a": 1})
+	if err != nil || string(data) != `{"a":1}` {
+		t.Errorf("Marshal(map) = %s, %v", data, err)
+	}
+
+	first, _ := Marshal(appenderStub{Name: "first"})
+	second, _ := Marshal(appenderStub{Name: "second"})
+	if string(first) != `{"Name":"first"}` || string(second) != `{"Name":"second"}` {
+		t.Errorf("pooled buffers leaked between calls: %s, %s", first, second)
+	}
+	if cap(first) != len(first) {
+		t.Errorf("cap = %d, want exact size %d", cap(first), len(first))
+	}
+
+	big := strings.Repeat("x", 2*maxPooledBuffer)
+	data, err = Marshal(appenderStub{Name: big})
+	if err != nil || len(data) != len(big)+len(`{"Name":""}`) {
+		t.Errorf("Marshal(big) len = %d, %v", len(data), err)
+	}
+
+	if data, err := Marshal((*appenderStub)(nil)); err != nil || string(data) != "null" {
+		t.Errorf("Marshal(nil pointer) = %s, %v, want null", data, err)
+	}
+
+	boom := errors.New("boom")
+	if _, err := Marshal(appenderStub{Err: boom}); !errors.Is(err, boom) {
+		t.Errorf("Marshal() error = %v, want boom", err)
+	}
+}
+
+func TestAppendValue(t *testing.T) {
+	got, err := AppendValue([]byte("["), appenderStub{Name: "a"})
+	if err != nil || string(got) != `[{"Name":"a"}` {
+		t.Errorf("AppendValue(appender) = %s, %v", got, err)
+	}
+	got, err = AppendValue(nil, []int{1, 2})
+	if err != nil || string(got) != `[1,2]` {
+		t.Errorf("AppendValue(slice) = %s, %v", got, err)
+	}
+	if _, err := AppendValue(nil, make(chan int)); err == nil {
+		t.Error("AppendValue(chan) error = nil")
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	v := appenderStub{Name: "benchmark <user> name"}
+	b.Run(Standard, func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = json.Marshal(v)
+		}
+	})
+	b.Run(Fast, func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = Marshal(v)
+		}
+	})
+}
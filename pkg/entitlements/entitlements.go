@@ -0,0 +1,206 @@
+// Package entitlements computes the features a user may use from their plan,
+// feature flags and per-user overrides, in one evaluation order, so every
+// client gates the same features the same way.
+package entitlements
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidPolicy is returned for a feature or flag that cannot be parsed
+var ErrInvalidPolicy = errors.New("invalid entitlements policy")
+
+// Source is the step of the evaluation that decided a feature
+type Source string
+
+const (
+	// SourcePlan features come with the user's plan
+	SourcePlan Source = "plan"
+	// SourceFlag features are granted by a flag turned on or rolled out
+	SourceFlag Source = "flag"
+	// SourceOverride features are granted to the user alone
+	SourceOverride Source = "override"
+)
+
+// Flag is the state of a feature flag: on for everyone, off for everyone
+// whatever their plan, or rolled out to a percentage of users
+type Flag struct {
+	// Percent of users the flag is on for; 100 is on and 0 is off
+	Percent int
+}
+
+// ParseFlag parses "on", "off" or a rollout such as "25%"
+func ParseFlag(s string) (Flag, error) {
+	switch s = strings.TrimSpace(strings.ToLower(s)); s {
+	case "on":
+		return Flag{Percent: 100}, nil
+	case "off":
+		return Flag{Percent: 0}, nil
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(s, "%"))
+	if !strings.HasSuffix(s, "%") || err != nil || percent < 0 || percent > 100 {
+		return Flag{}, fmt.Errorf("%w: flag %q must be on, off or a percentage such as 25%%", ErrInvalidPolicy, s)
+	}
+	return Flag{Percent: percent}, nil
+}
+
+// enabledFor reports whether the flag of feature is on for userID. Rollouts
+// hash the feature and user, so a user stays in a rollout as it grows and
+// each feature rolls out to different users.
+func (f Flag) enabledFor(feature string, userID int) bool {
+	if f.Percent >= 100 || f.Percent <= 0 {
+		return f.Percent >= 100
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(feature + ":" + strconv.Itoa(userID)))
+	return int(hash.Sum32()%100) < f.Percent
+}
+
+// Policy holds the features of each plan and the feature flags
+type Policy struct {
+	plans map[string][]string
+	flags map[string]Flag
+}
+
+// NewPolicy creates a policy from the features of each plan and the flags
+// of features by name
+func NewPolicy(plans map[string][]string, flags map[string]Flag) (*Policy, error) {
+	for plan, features := range plans {
+		for _, feature := range features {
+			if err := checkFeature(feature); err != nil {
+				return nil, fmt.Errorf("plan %s: %w", plan, err)
+			}
+		}
+	}
+	for feature := range flags {
+		if err := checkFeature(feature); err != nil {
+			return nil, fmt.Errorf("flags: %w", err)
+		}
+	}
+	return &Policy{plans: plans, flags: flags}, nil
+}
+
+// checkFeature fails for a feature name that is empty or has spaces
+func checkFeature(feature string) error {
+	if feature == "" || strings.ContainsAny(feature, " \t\n") {
+		return fmt.Errorf("%w: feature %q", ErrInvalidPolicy, feature)
+	}
+	return nil
+}
+
+// policyFile is the ENTITLEMENTS_FILE
+type policyFile struct {
+	Plans map[string][]string `yaml:"plans"`
+	Flags map[string]string   `yaml:"flags"`
+}
+
+// Load reads the policy in the YAML file at path:
+//
+//	plans:
+//	  free: [exports]
+//	  pro: [exports, api_access, priority_support]
+//	flags:
+//	  new_dashboard: on   # everyone
+//	  api_access: off     # no one, whatever their plan
+//	  beta_reports: 25%   # a quarter of users
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file policyFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	flags := make(map[string]Flag, len(file.Flags))
+	for feature, value := range file.Flags {
+		flag, err := ParseFlag(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, feature, err)
+		}
+		flags[feature] = flag
+	}
+	policy, err := NewPolicy(file.Plans, flags)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Features returns every feature a plan or flag names, sorted
+func (p *Policy) Features() []string {
+	var features []string
+	for _, planFeatures := range p.plans {
+		features = append(features, planFeatures...)
+	}
+	for feature := range p.flags {
+		features = append(features, feature)
+	}
+	slices.Sort(features)
+	return slices.Compact(features)
+}
+
+// Set is the effective features of a user, each with the step that granted it
+type Set struct {
+	Plan     string
+	Features map[string]Source
+}
+
+// Has reports whether the set includes feature
+func (s *Set) Has(feature string) bool {
+	_, ok := s.Features[feature]
+	return ok
+}
+
+// Names returns the features of the set, sorted
+func (s *Set) Names() []string {
+	names := make([]string, 0, len(s.Features))
+	for feature := range s.Features {
+		names = append(names, feature)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Evaluate computes the features of userID on plan. Each step overrules the
+// ones before it:
+//
+//  1. the features of the plan; an unknown plan has none
+//  2. flags: a flag that is on for the user grants its feature, and a flag
+//     that is off for them takes it away, even from their plan
+//  3. overrides: true grants the feature to the user, false takes it away
+func (p *Policy) Evaluate(userID int, plan string, overrides map[string]bool) *Set {
+	set := &Set{Plan: plan, Features: make(map[string]Source)}
+	for _, feature := range p.plans[plan] {
+		set.Features[feature] = SourcePlan
+	}
+	for feature, flag := range p.flags {
+		if flag.enabledFor(feature, userID) {
+			if _, ok := set.Features[feature]; !ok {
+				set.Features[feature] = SourceFlag
+			}
+		} else {
+			delete(set.Features, feature)
+		}
+	}
+	for feature, granted := range overrides {
+		if granted {
+			set.Features[feature] = SourceOverride
+		} else {
+			delete(set.Features, feature)
+		}
+	}
+	return set
+}
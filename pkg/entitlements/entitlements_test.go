@@ -0,0 +1,130 @@
+package entitlements
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseFlag(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"on", 100, false},
+		{"OFF", 0, false},
+		{"25%", 25, false},
+		{" 100% ", 100, false},
+		{"25", 0, true},
+		{"150%", 0, true},
+		{"yes", 0, true},
+	}
+	for _, tt := range tests {
+		flag, err := ParseFlag(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFlag(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("ParseFlag(%q) error = %v, want ErrInvalidPolicy", tt.value, err)
+		}
+		if err == nil && flag.Percent != tt.want {
+			t.Errorf("ParseFlag(%q) = %d%%, want %d%%", tt.value, flag.Percent, tt.want)
+		}
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	policy, err := NewPolicy(
+		map[string][]string{"free": {"exports"}, "pro": {"exports", "api_access", "reports"}},
+		map[string]Flag{"new_dashboard": {Percent: 100}, "reports": {Percent: 0}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		plan      string
+		overrides map[string]bool
+		want      map[string]Source
+	}{
+		{"free", "free", nil, map[string]Source{"exports": SourcePlan, "new_dashboard": SourceFlag}},
+		{"flag off beats the plan", "pro", nil,
+			map[string]Source{"exports": SourcePlan, "api_access": SourcePlan, "new_dashboard": SourceFlag}},
+		{"unknown plan", "legacy", nil, map[string]Source{"new_dashboard": SourceFlag}},
+		{"overrides beat flags and plans", "free", map[string]bool{"reports": true, "new_dashboard": false, "exports": false},
+			map[string]Source{"reports": SourceOverride}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := policy.Evaluate(7, tt.plan, tt.overrides)
+			if set.Plan != tt.plan || len(set.Features) != len(tt.want) {
+				t.Fatalf("Evaluate() = %v %v, want %v", set.Plan, set.Features, tt.want)
+			}
+			for feature, source := range tt.want {
+				if set.Features[feature] != source {
+					t.Errorf("Features[%s] = %q, want %q", feature, set.Features[feature], source)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicy_Rollout(t *testing.T) {
+	policy, err := NewPolicy(nil, map[string]Flag{"beta": {Percent: 30}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wider, err := NewPolicy(nil, map[string]Flag{"beta": {Percent: 60}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enabled := 0
+	for userID := 1; userID <= 1000; userID++ {
+		in := policy.Evaluate(userID, "free", nil).Has("beta")
+		if in {
+			enabled++
+		}
+		if in && !wider.Evaluate(userID, "free", nil).Has("beta") {
+			t.Fatalf("user %d left the rollout when it grew", userID)
+		}
+	}
+	if enabled < 250 || enabled > 350 {
+		t.Errorf("30%% rollout enabled for %d of 1000 users", enabled)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "entitlements.yaml")
+	if err := os.WriteFile(path, []byte("plans:\n  free: [exports]\n  pro: [exports, api_access]\nflags:\n  new_dashboard: on\n  beta: 10%\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, want := policy.Features(), []string{"api_access", "beta", "exports", "new_dashboard"}; !slices.Equal(got, want) {
+		t.Errorf("Features() = %v, want %v", got, want)
+	}
+
+	for name, content := range map[string]string{
+		"bad-flag.yaml":    "flags:\n  beta: sometimes\n",
+		"bad-feature.yaml": "plans:\n  pro: [\"api access\"]\n",
+		"unknown.yaml":     "plans: {}\noverrides: {}\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("Load(%s) should fail", name)
+		}
+	}
+}
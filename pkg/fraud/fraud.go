@@ -0,0 +1,141 @@
+// Package fraud scores how likely an account is to be abused from signals
+// seen on it, such as a burst of logins or a throwaway email address. Each
+// signal adds its weight to the score, up to MaxScore.
+package fraud
+
+import (
+	"slices"
+	"strings"
+
+	"fiber-hello-world/pkg/textnorm"
+)
+
+// Signals raised by a Scorer
+const (
+	// SignalVelocity: more login attempts than the velocity limit
+	SignalVelocity = "velocity"
+	// SignalDisposableEmail: the email address is at a throwaway provider
+	SignalDisposableEmail = "disposable_email"
+	// SignalGeo: successful logins from more than one country
+	SignalGeo = "geo"
+	// SignalDevice: logins from several devices new to the account
+	SignalDevice = "device"
+)
+
+// MaxScore is the highest score
+const MaxScore = 100
+
+// DefaultVelocityLimit is how many login attempts an account may see in the
+// velocity window before SignalVelocity is raised
+const DefaultVelocityLimit = 10
+
+// newDeviceLimit is how many logins from new devices raise SignalDevice
+const newDeviceLimit = 3
+
+// DefaultWeights are the points each signal adds to a score, so any two
+// signals flag an account at a threshold of 50
+var DefaultWeights = map[string]int{
+	SignalVelocity:        30,
+	SignalDisposableEmail: 30,
+	SignalGeo:             25,
+	SignalDevice:          25,
+}
+
+// DefaultDisposableDomains are common throwaway email providers.
+// Deployments can add the ones they see.
+var DefaultDisposableDomains = []string{
+	"10minutemail.com", "dispostable.com", "getnada.com", "guerrillamail.com",
+	"mailinator.com", "maildrop.cc", "sharklasers.com", "temp-mail.org",
+	"tempmail.com", "throwawaymail.com", "trashmail.com", "yopmail.com",
+}
+
+// Activity is what was seen on an account recently
+type Activity struct {
+	Email string
+	// Attempts counts the login attempts in the velocity window
+	Attempts int
+	// Countries counts the countries of the successful logins
+	Countries int
+	// NewDevices counts the successful logins from devices new to the account
+	NewDevices int
+}
+
+// Options configure a Scorer
+type Options struct {
+	// VelocityLimit is DefaultVelocityLimit when zero
+	VelocityLimit int
+	// DisposableDomains are added to DefaultDisposableDomains
+	DisposableDomains []string
+	// Weights replace DefaultWeights when set
+	Weights map[string]int
+}
+
+// Scorer turns the activity of an account into signals and a score
+type Scorer struct {
+	velocityLimit int
+	disposable    map[string]bool
+	weights       map[string]int
+}
+
+// New creates a scorer
+func New(opts Options) *Scorer {
+	s := &Scorer{
+		velocityLimit: opts.VelocityLimit,
+		disposable:    make(map[string]bool),
+		weights:       opts.Weights,
+	}
+	if s.velocityLimit <= 0 {
+		s.velocityLimit = DefaultVelocityLimit
+	}
+	if s.weights == nil {
+		s.weights = DefaultWeights
+	}
+	for _, domain := range append(slices.Clone(DefaultDisposableDomains), opts.DisposableDomains...) {
+		s.disposable[strings.ToLower(strings.TrimSpace(domain))] = true
+	}
+	return s
+}
+
+// Disposable reports whether email is at a throwaway provider or one of
+// its subdomains
+func (s *Scorer) Disposable(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(textnorm.Identifier(email[at+1:]))
+	for domain != "" {
+		if s.disposable[domain] {
+			return true
+		}
+		_, domain, _ = strings.Cut(domain, ".")
+	}
+	return false
+}
+
+// Signals returns the signals raised by activity, sorted
+func (s *Scorer) Signals(activity Activity) []string {
+	signals := []string{}
+	if s.Disposable(activity.Email) {
+		signals = append(signals, SignalDisposableEmail)
+	}
+	if activity.NewDevices >= newDeviceLimit {
+		signals = append(signals, SignalDevice)
+	}
+	if activity.Countries > 1 {
+		signals = append(signals, SignalGeo)
+	}
+	if activity.Attempts > s.velocityLimit {
+		signals = append(signals, SignalVelocity)
+	}
+	return signals
+}
+
+// Score sums the weights of signals, up to MaxScore
+func (s *Scorer) Score(signals []string) int {
+	var score int
+	for _, signal := range signals {
+		score += s.weights[signal]
+	}
+	return min(score, MaxScore)
+}
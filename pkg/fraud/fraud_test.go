@@ -0,0 +1,60 @@
+package fraud
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScorer_Disposable(t *testing.T) {
+	s := New(Options{DisposableDomains: []string{" Burner.example "}})
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"someone@mailinator.com", true},
+		{"someone@MAILINATOR.com", true},
+		{"someone@eu.mailinator.com", true},
+		{"someone@burner.example", true},
+		{"someone@example.com", false},
+		{"someone@notmailinator.com", false},
+		{"mailinator.com", false},
+	}
+	for _, tt := range tests {
+		if got := s.Disposable(tt.email); got != tt.want {
+			t.Errorf("Disposable(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestScorer_Signals(t *testing.T) {
+	s := New(Options{VelocityLimit: 5})
+	tests := []struct {
+		name     string
+		activity Activity
+		want     []string
+		score    int
+	}{
+		{"quiet", Activity{Email: "jane@example.com", Attempts: 5, Countries: 1, NewDevices: 2}, []string{}, 0},
+		{"velocity", Activity{Email: "jane@example.com", Attempts: 6}, []string{SignalVelocity}, 30},
+		{"disposable and geo", Activity{Email: "jane@yopmail.com", Countries: 2}, []string{SignalDisposableEmail, SignalGeo}, 55},
+		{"everything", Activity{Email: "jane@yopmail.com", Attempts: 50, Countries: 3, NewDevices: 3}, []string{SignalDisposableEmail, SignalDevice, SignalGeo, SignalVelocity}, MaxScore},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signals := s.Signals(tt.activity)
+			if !reflect.DeepEqual(signals, tt.want) {
+				t.Errorf("Signals() = %v, want %v", signals, tt.want)
+			}
+			if score := s.Score(signals); score != tt.score {
+				t.Errorf("Score() = %d, want %d", score, tt.score)
+			}
+		})
+	}
+}
+
+func TestScorer_Weights(t *testing.T) {
+	s := New(Options{Weights: map[string]int{SignalGeo: 70}})
+	if got := s.Score([]string{SignalGeo, SignalVelocity}); got != 70 {
+		t.Errorf("Score() = %d, want 70", got)
+	}
+}
@@ -0,0 +1,100 @@
+// Package hashpool bounds concurrent bcrypt work. Hashing is CPU bound, so a
+// burst of logins queues for a slot instead of starving every other request.
+package hashpool
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Stats describes how busy a pool is
+type Stats struct {
+	// Size is the number of hashes that may run at once
+	Size int
+	// Busy is the number of hashes running now
+	Busy int
+	// Waiting is the number of callers queued for a slot
+	Waiting int
+	// Wait is the moving average of how long recent callers queued for a
+	// slot while callers are queued; 0 when none are
+	Wait time.Duration
+}
+
+// Saturation is (Busy + Waiting) / Size: below 1 the pool has spare
+// capacity, above 1 callers are queueing
+func (s Stats) Saturation() float64 {
+	if s.Size == 0 {
+		return 0
+	}
+	return float64(s.Busy+s.Waiting) / float64(s.Size)
+}
+
+// waitWeight is the weight of each new wait in the moving average, as a
+// divisor: 4 moves it a quarter of the way to the new wait
+const waitWeight = 4
+
+// Pool runs bcrypt operations with at most Size running at once
+type Pool struct {
+	slots   chan struct{}
+	waiting atomic.Int64
+	// wait is the moving average of queueing times, in nanoseconds
+	wait atomic.Int64
+	cost int
+}
+
+// New creates a pool running size hashes at once; zero or less uses one
+// per CPU (GOMAXPROCS)
+func New(size int) *Pool {
+	if size <= 0 {
+		size = runtime.GOMAXPROCS(0)
+	}
+	return &Pool{
+		slots: make(chan struct{}, size),
+		cost:  bcrypt.DefaultCost,
+	}
+}
+
+// Hash returns the bcrypt hash of password
+func (p *Pool) Hash(password []byte) ([]byte, error) {
+	defer p.acquire()()
+	return bcrypt.GenerateFromPassword(password, p.cost)
+}
+
+// Compare returns nil if password matches the bcrypt hash
+func (p *Pool) Compare(hash, password []byte) error {
+	defer p.acquire()()
+	return bcrypt.CompareHashAndPassword(hash, password)
+}
+
+// Stats reports the pool's current load
+func (p *Pool) Stats() Stats {
+	stats := Stats{
+		Size:    cap(p.slots),
+		Busy:    len(p.slots),
+		Waiting: int(p.waiting.Load()),
+	}
+	if stats.Waiting > 0 {
+		stats.Wait = time.Duration(p.wait.Load())
+	}
+	return stats
+}
+
+// acquire waits for a slot and returns the function releasing it
+func (p *Pool) acquire() func() {
+	start := time.Now()
+	p.waiting.Add(1)
+	p.slots <- struct{}{}
+	p.waiting.Add(-1)
+
+	waited := int64(time.Since(start))
+	for {
+		average := p.wait.Load()
+		if p.wait.CompareAndSwap(average, average+(waited-average)/waitWeight) {
+			break
+		}
+	}
+	return func() { <-p.slots }
+}
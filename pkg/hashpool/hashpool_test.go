@@ -0,0 +1,99 @@
+package hashpool
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPool_HashAndCompare(t *testing.T) {
+	pool := New(2)
+	pool.cost = bcrypt.MinCost
+
+	hash, err := pool.Hash([]byte("password123"))
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if err := pool.Compare(hash, []byte("password123")); err != nil {
+		t.Errorf("Compare() error = %v, want a match", err)
+	}
+	if err := pool.Compare(hash, []byte("wrong")); err == nil {
+		t.Error("Compare() should fail for the wrong password")
+	}
+	if stats := pool.Stats(); stats != (Stats{Size: 2}) {
+		t.Errorf("Stats() = %+v, want an idle pool of 2", stats)
+	}
+}
+
+func TestPool_Saturation(t *testing.T) {
+	pool := New(1)
+
+	// Hold the only slot so the next callers queue
+	release := pool.acquire()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pool.acquire()()
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().Waiting != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stats := pool.Stats()
+	if stats.Busy != 1 || stats.Waiting != 2 || stats.Saturation() != 3 {
+		t.Errorf("Stats() = %+v (saturation %v), want 1 busy, 2 waiting, saturation 3", stats, stats.Saturation())
+	}
+
+	release()
+	wg.Wait()
+	if stats := pool.Stats(); stats.Busy != 0 || stats.Waiting != 0 {
+		t.Errorf("Stats() after release = %+v, want idle", stats)
+	}
+}
+
+func TestPool_Wait(t *testing.T) {
+	pool := New(1)
+	waitFor := func(waiting int) {
+		deadline := time.Now().Add(time.Second)
+		for pool.Stats().Waiting != waiting && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// One caller queues for 40ms
+	release := pool.acquire()
+	acquired := make(chan func())
+	go func() { acquired <- pool.acquire() }()
+	waitFor(1)
+	time.Sleep(40 * time.Millisecond)
+	release()
+	release = <-acquired
+
+	// The average is only reported while callers are queued
+	if stats := pool.Stats(); stats.Wait != 0 {
+		t.Errorf("Stats().Wait with no queue = %v, want 0", stats.Wait)
+	}
+	done := make(chan struct{})
+	go func() {
+		pool.acquire()()
+		close(done)
+	}()
+	waitFor(1)
+	if stats := pool.Stats(); stats.Wait < 40*time.Millisecond/waitWeight {
+		t.Errorf("Stats().Wait = %v, want at least a quarter of 40ms", stats.Wait)
+	}
+	release()
+	<-done
+}
+
+func TestNew_DefaultSize(t *testing.T) {
+	if New(0).Stats().Size < 1 {
+		t.Error("New(0) should size the pool by GOMAXPROCS")
+	}
+}
@@ -0,0 +1,190 @@
+// Package hooks lets integrators extend the register, login and token
+// issuance flows with Go functions or out-of-process webhooks that can
+// change or veto the operation, and be notified of scheduled events such
+// as users' birthdays.
+package hooks
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+
+	"fiber-hello-world/pkg/jwt"
+)
+
+// Point is a place in a request's lifecycle where hooks run
+type Point string
+
+// Hook points. Hooks at pre points and at PostLogin can veto; PostRegister,
+// PasswordReset and Birthday run after the change is saved or as
+// notifications, so their errors are only logged.
+const (
+	// PreRegister runs before a user is created. Data holds "email",
+	// "fullName", "phoneNumber" and "birthday", which hooks may change.
+	PreRegister Point = "pre-register"
+	// PostRegister runs after a user is created
+	PostRegister Point = "post-register"
+	// PreLogin runs before the password is checked
+	PreLogin Point = "pre-login"
+	// PostLogin runs after the credentials are verified, before a token is issued
+	PostLogin Point = "post-login"
+	// PreTokenIssue runs while a token is built. Entries hooks put in Data are
+	// added to the token's "ext" claim.
+	PreTokenIssue Point = "pre-token-issue"
+	// PasswordReset runs after a password reset token is issued, to deliver
+	// it. Data holds "token" and "expiresAt".
+	PasswordReset Point = "password-reset"
+	// Birthday runs once a year on a user's birthday in their time zone.
+	// Data holds "fullName", "date", the local date, "timezone" and "age".
+	Birthday Point = "birthday"
+)
+
+// Points lists every hook point
+var Points = []Point{PreRegister, PostRegister, PreLogin, PostLogin, PreTokenIssue, PasswordReset, Birthday}
+
+// ErrVetoed is returned when a hook rejects the operation
+var ErrVetoed = errors.New("rejected by policy")
+
+// ErrUnknownHook is returned by Deliver when no hook is registered at the
+// given position
+var ErrUnknownHook = errors.New("unknown hook")
+
+// Veto returns an error rejecting the operation with a reason shown to the client
+func Veto(reason string) error {
+	return fmt.Errorf("%w: %s", ErrVetoed, reason)
+}
+
+// Event describes the operation a hook runs for. UserID is 0 before the
+// user is known. ID is assigned when the event is first run and stays the
+// same when it is retried, so receivers can drop events they already handled.
+type Event struct {
+	ID     string                 `json:"id"`
+	Point  Point                  `json:"point"`
+	UserID int                    `json:"userId,omitempty"`
+	Email  string                 `json:"email"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+}
+
+// NewEventID returns a random event ID, such as "evt_" followed by 26 base32 characters
+func NewEventID() string {
+	return "evt_" + rand.Text()
+}
+
+// Hook handles an event. Returning an error vetoes the operation where the
+// point allows it; use Veto to give the client a reason.
+type Hook interface {
+	Handle(event *Event) error
+}
+
+// HookFunc adapts a function to the Hook interface
+type HookFunc func(event *Event) error
+
+// Handle calls f(event)
+func (f HookFunc) Handle(event *Event) error {
+	return f(event)
+}
+
+// Registry holds the hooks registered per point. A nil Registry runs no hooks.
+type Registry struct {
+	hooks     map[Point][]Hook
+	onFailure []func(event *Event, hook int, err error)
+}
+
+// NewRegistry creates an empty hook registry
+func NewRegistry() *Registry {
+	return &Registry{hooks: make(map[Point][]Hook)}
+}
+
+// Register adds a hook at point. Hooks run in registration order. Register
+// all hooks before the server starts handling requests.
+func (r *Registry) Register(point Point, hook Hook) {
+	r.hooks[point] = append(r.hooks[point], hook)
+}
+
+// OnFailure calls handle for every hook failing at PostRegister,
+// PasswordReset or Birthday, whose errors are otherwise only logged, e.g. to report
+// undelivered webhooks. hook is the failed hook's position among the
+// point's hooks, for Deliver. Register handlers before the server starts.
+func (r *Registry) OnFailure(handle func(event *Event, hook int, err error)) {
+	r.onFailure = append(r.onFailure, handle)
+}
+
+// Run runs the hooks at event.Point in order and stops at the first error.
+// Errors that are not vetoes are wrapped as one, so a failing hook never
+// lets the operation through. At PostRegister, PasswordReset and Birthday
+// all hooks run and errors are logged instead.
+func (r *Registry) Run(event *Event) error {
+	if r == nil {
+		return nil
+	}
+	if event.ID == "" {
+		event.ID = NewEventID()
+	}
+	if event.Data == nil {
+		event.Data = make(map[string]interface{})
+	}
+
+	for i, hook := range r.hooks[event.Point] {
+		err := hook.Handle(event)
+		if err == nil {
+			continue
+		}
+		if event.Point == PostRegister || event.Point == PasswordReset || event.Point == Birthday {
+			log.Printf("%s hook failed for user %d: %v", event.Point, event.UserID, err)
+			for _, handle := range r.onFailure {
+				handle(event, i, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrVetoed) {
+			err = fmt.Errorf("%w: %s hook failed: %v", ErrVetoed, event.Point, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// Deliver runs only the hook at position hook of event.Point, e.g. to retry
+// one that failed with the event's original ID, and returns its error as is
+func (r *Registry) Deliver(event *Event, hook int) error {
+	if r == nil || hook < 0 || hook >= len(r.hooks[event.Point]) {
+		return fmt.Errorf("%w: %s hook %d", ErrUnknownHook, event.Point, hook)
+	}
+	if event.ID == "" {
+		event.ID = NewEventID()
+	}
+	if event.Data == nil {
+		event.Data = make(map[string]interface{})
+	}
+	return r.hooks[event.Point][hook].Handle(event)
+}
+
+// Webhooks returns the registered webhooks, by point, e.g. to observe their
+// attempts with Webhook.OnAttempt
+func (r *Registry) Webhooks() map[Point][]*Webhook {
+	webhooks := make(map[Point][]*Webhook)
+	if r == nil {
+		return webhooks
+	}
+	for point, registered := range r.hooks {
+		for _, hook := range registered {
+			if webhook, ok := hook.(*Webhook); ok {
+				webhooks[point] = append(webhooks[point], webhook)
+			}
+		}
+	}
+	return webhooks
+}
+
+// ClaimsProvider runs the PreTokenIssue hooks for every issued token and
+// returns the claims they put in the event data. A veto fails issuance.
+func (r *Registry) ClaimsProvider() jwt.ClaimsProvider {
+	return jwt.ClaimsProviderFunc(func(req jwt.ClaimsRequest) (map[string]interface{}, error) {
+		event := &Event{Point: PreTokenIssue, UserID: req.UserID, Email: req.Email}
+		if err := r.Run(event); err != nil {
+			return nil, err
+		}
+		return event.Data, nil
+	})
+}
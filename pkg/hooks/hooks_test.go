@@ -0,0 +1,200 @@
+package hooks
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-hello-world/pkg/jwt"
+)
+
+func TestRegistry_Run(t *testing.T) {
+	registry := NewRegistry()
+	var order []string
+	registry.Register(PreRegister, HookFunc(func(event *Event) error {
+		order = append(order, "first")
+		event.Data["fullName"] = "Changed"
+		return nil
+	}))
+	registry.Register(PreRegister, HookFunc(func(event *Event) error {
+		order = append(order, "second:"+event.Data["fullName"].(string))
+		return nil
+	}))
+
+	event := &Event{Point: PreRegister, Email: "user@example.com", Data: map[string]interface{}{"fullName": "Original"}}
+	if err := registry.Run(event); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if strings.Join(order, ",") != "first,second:Changed" {
+		t.Errorf("hooks ran as %v, want in registration order seeing earlier changes", order)
+	}
+
+	// Hooks at other points do not run
+	if err := registry.Run(&Event{Point: PreLogin}); err != nil || len(order) != 2 {
+		t.Errorf("Run(PreLogin) error = %v, order = %v", err, order)
+	}
+}
+
+func TestRegistry_RunVeto(t *testing.T) {
+	tests := []struct {
+		name    string
+		hookErr error
+		want    string
+	}{
+		{"veto", Veto("signups from example.org are closed"), "rejected by policy: signups from example.org are closed"},
+		{"other errors veto too", errors.New("database down"), "rejected by policy: pre-login hook failed: database down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			ranAfter := false
+			registry.Register(PreLogin, HookFunc(func(*Event) error { return tt.hookErr }))
+			registry.Register(PreLogin, HookFunc(func(*Event) error { ranAfter = true; return nil }))
+
+			err := registry.Run(&Event{Point: PreLogin})
+			if !errors.Is(err, ErrVetoed) || err.Error() != tt.want {
+				t.Errorf("Run() error = %v, want %q", err, tt.want)
+			}
+			if ranAfter {
+				t.Error("hooks after a veto should not run")
+			}
+		})
+	}
+}
+
+func TestRegistry_RunAfterPointsIgnoreErrors(t *testing.T) {
+	for _, point := range []Point{PostRegister, PasswordReset, Birthday} {
+		t.Run(string(point), func(t *testing.T) {
+			registry := NewRegistry()
+			ranAfter := false
+			var failures []error
+			registry.OnFailure(func(event *Event, hook int, err error) {
+				if hook != 0 {
+					t.Errorf("OnFailure got hook %d, want 0", hook)
+				}
+				failures = append(failures, err)
+			})
+			registry.Register(point, HookFunc(func(*Event) error { return Veto("too late") }))
+			registry.Register(point, HookFunc(func(*Event) error { ranAfter = true; return nil }))
+
+			if err := registry.Run(&Event{Point: point, UserID: 1}); err != nil {
+				t.Errorf("Run() error = %v, want nil after the change is saved", err)
+			}
+			if !ranAfter {
+				t.Errorf("all %s hooks should run", point)
+			}
+			if len(failures) != 1 || !errors.Is(failures[0], ErrVetoed) {
+				t.Errorf("OnFailure got %v, want the failed hook's error", failures)
+			}
+		})
+	}
+}
+
+func TestRegistry_Deliver(t *testing.T) {
+	registry := NewRegistry()
+	var ran []int
+	for i := 0; i < 2; i++ {
+		registry.Register(PostRegister, HookFunc(func(*Event) error {
+			ran = append(ran, i)
+			return errors.New("unreachable")
+		}))
+	}
+
+	event := &Event{ID: "evt_original", Point: PostRegister, UserID: 1}
+	err := registry.Deliver(event, 1)
+	if err == nil || err.Error() != "unreachable" {
+		t.Errorf("Deliver() error = %v, want the hook's error", err)
+	}
+	if event.ID != "evt_original" {
+		t.Errorf("Deliver() changed the event ID to %q", event.ID)
+	}
+	if len(ran) != 1 || ran[0] != 1 {
+		t.Errorf("Deliver() ran hooks %v, want only [1]", ran)
+	}
+
+	for _, hook := range []int{-1, 2} {
+		if err := registry.Deliver(&Event{Point: PostRegister}, hook); !errors.Is(err, ErrUnknownHook) {
+			t.Errorf("Deliver(%d) error = %v, want ErrUnknownHook", hook, err)
+		}
+	}
+	if err := registry.Deliver(&Event{Point: PasswordReset}, 0); !errors.Is(err, ErrUnknownHook) {
+		t.Errorf("Deliver() at a point without hooks error = %v, want ErrUnknownHook", err)
+	}
+}
+
+func TestRegistry_EventIDs(t *testing.T) {
+	registry := NewRegistry()
+	var seen []string
+	registry.Register(PostLogin, HookFunc(func(event *Event) error {
+		seen = append(seen, event.ID)
+		return nil
+	}))
+
+	for i := 0; i < 2; i++ {
+		if err := registry.Run(&Event{Point: PostLogin}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+	if len(seen) != 2 || !strings.HasPrefix(seen[0], "evt_") || seen[0] == seen[1] {
+		t.Errorf("event IDs = %v, want two distinct evt_ IDs", seen)
+	}
+	registry.Run(&Event{ID: "evt_given", Point: PostLogin})
+	if seen[2] != "evt_given" {
+		t.Errorf("Run() replaced the event ID with %q", seen[2])
+	}
+}
+
+func TestRegistry_Webhooks(t *testing.T) {
+	registry := NewRegistry()
+	webhook, _ := NewWebhook("https://hooks.internal/register", "", time.Second)
+	registry.Register(PostRegister, HookFunc(func(*Event) error { return nil }))
+	registry.Register(PostRegister, webhook)
+
+	webhooks := registry.Webhooks()
+	if len(webhooks) != 1 || len(webhooks[PostRegister]) != 1 || webhooks[PostRegister][0] != webhook {
+		t.Errorf("Webhooks() = %v, want the post-register webhook", webhooks)
+	}
+	var nilRegistry *Registry
+	if webhooks := nilRegistry.Webhooks(); len(webhooks) != 0 {
+		t.Errorf("Webhooks() on nil registry = %v", webhooks)
+	}
+}
+
+func TestRegistry_Nil(t *testing.T) {
+	var registry *Registry
+	if err := registry.Run(&Event{Point: PreLogin}); err != nil {
+		t.Errorf("Run() on nil registry error = %v", err)
+	}
+}
+
+func TestRegistry_ClaimsProvider(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(PreTokenIssue, HookFunc(func(event *Event) error {
+		if event.Email == "blocked@example.com" {
+			return Veto("blocked")
+		}
+		event.Data["plan"] = "pro"
+		return nil
+	}))
+
+	service := jwt.NewService("test-secret")
+	service.RegisterClaimsProvider("hooks", registry.ClaimsProvider())
+
+	token, _, err := service.GenerateToken(1, "user@example.com")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	claims, err := service.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims.Extra["plan"] != "pro" {
+		t.Errorf("Extra = %v, want plan claim from the hook", claims.Extra)
+	}
+
+	if _, _, err := service.GenerateToken(2, "blocked@example.com"); !errors.Is(err, ErrVetoed) {
+		t.Errorf("GenerateToken() error = %v, want ErrVetoed", err)
+	}
+}
@@ -0,0 +1,437 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxScriptSize caps the size of a policy script
+const maxScriptSize = 64 << 10
+
+// ErrScriptTimeout is returned when a policy script runs past its timeout
+var ErrScriptTimeout = errors.New("policy script timed out")
+
+// scriptFuncs lists the functions available to rules and their argument
+// counts; -1 means two or more
+var scriptFuncs = map[string]int{
+	"contains":  2,
+	"hasPrefix": 2,
+	"hasSuffix": 2,
+	"lower":     1,
+	"len":       1,
+	"matches":   2,
+	"in":        -1,
+}
+
+// scriptVars lists the event variables available to rules
+var scriptVars = map[string]bool{
+	"point": true, "email": true, "domain": true, "userID": true,
+	"hour": true, "minute": true, "weekday": true,
+}
+
+// rule is a single policy rule; the operation is vetoed when expr is true
+type rule struct {
+	line    int
+	expr    ast.Expr
+	reason  string
+	regexps map[ast.Expr]*regexp.Regexp
+}
+
+// Script is a hook that evaluates policy rules, for deployments that need
+// rules without recompiling. Each non-blank line is a rule written as a Go
+// expression, and the first rule that is true vetoes the operation. A run of
+// "#" comment lines directly above a rule is its veto reason. For example:
+//
+//	# Signups from example.org are closed outside office hours
+//	point == "pre-register" && domain == "example.org" && (hour < 9 || hour >= 17)
+//
+// Rules see the variables point, email, domain, userID, hour, minute and
+// weekday (e.g. "Monday", in the server's time zone), and data.<field> for
+// the event data; missing fields are "". The functions contains, hasPrefix,
+// hasSuffix, lower, len, matches (with a literal pattern) and in(x, a, b...)
+// are available. Rules cannot loop or reach anything outside the event, and
+// each run is bounded by the timeout.
+type Script struct {
+	rules   []rule
+	timeout time.Duration
+	now     func() time.Time
+}
+
+// LoadScript reads and parses the policy script at path
+func LoadScript(path string, timeout time.Duration) (*Script, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	script, err := ParseScript(string(source), timeout)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return script, nil
+}
+
+// ParseScript parses policy rules, rejecting anything outside the supported
+// expressions so mistakes surface at startup rather than per request
+func ParseScript(source string, timeout time.Duration) (*Script, error) {
+	if len(source) > maxScriptSize {
+		return nil, fmt.Errorf("script is larger than %d bytes", maxScriptSize)
+	}
+
+	script := &Script{timeout: timeout, now: time.Now}
+	var comment []string
+	for i, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			comment = nil
+			continue
+		case strings.HasPrefix(line, "#"):
+			comment = append(comment, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			continue
+		}
+
+		expr, err := parser.ParseExpr(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		r := rule{line: i + 1, expr: expr, reason: strings.Join(comment, " "), regexps: make(map[ast.Expr]*regexp.Regexp)}
+		if err := r.check(expr); err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		script.rules = append(script.rules, r)
+		comment = nil
+	}
+	return script, nil
+}
+
+// check verifies that expr only uses supported syntax, variables and
+// functions, and compiles the patterns passed to matches
+func (r *rule) check(expr ast.Expr) error {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+		case token.INT, token.FLOAT:
+			if _, err := strconv.ParseFloat(e.Value, 64); err != nil {
+				return fmt.Errorf("unsupported number %s", e.Value)
+			}
+		default:
+			return fmt.Errorf("unsupported literal %s", e.Value)
+		}
+	case *ast.Ident:
+		if !scriptVars[e.Name] && e.Name != "true" && e.Name != "false" {
+			return fmt.Errorf("unknown variable %q", e.Name)
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); !ok || x.Name != "data" {
+			return errors.New("only data.<field> can be selected")
+		}
+	case *ast.ParenExpr:
+		return r.check(e.X)
+	case *ast.UnaryExpr:
+		if e.Op != token.NOT && e.Op != token.SUB {
+			return fmt.Errorf("unsupported operator %s", e.Op)
+		}
+		return r.check(e.X)
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.LAND, token.LOR, token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ,
+			token.ADD, token.SUB, token.MUL, token.QUO, token.REM:
+		default:
+			return fmt.Errorf("unsupported operator %s", e.Op)
+		}
+		if err := r.check(e.X); err != nil {
+			return err
+		}
+		return r.check(e.Y)
+	case *ast.CallExpr:
+		fn, ok := e.Fun.(*ast.Ident)
+		if !ok {
+			return errors.New("unsupported function call")
+		}
+		arity, ok := scriptFuncs[fn.Name]
+		if !ok {
+			return fmt.Errorf("unknown function %q", fn.Name)
+		}
+		if (arity >= 0 && len(e.Args) != arity) || (arity < 0 && len(e.Args) < 2) || e.Ellipsis.IsValid() {
+			return fmt.Errorf("wrong number of arguments to %s", fn.Name)
+		}
+		if fn.Name == "matches" {
+			lit, ok := e.Args[1].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return errors.New("matches needs a literal pattern")
+			}
+			pattern, _ := strconv.Unquote(lit.Value)
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return err
+			}
+			r.regexps[e] = re
+		}
+		for _, arg := range e.Args {
+			if err := r.check(arg); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported expression %T", expr)
+	}
+	return nil
+}
+
+// Handle evaluates the rules against the event and vetoes the operation at
+// the first rule that is true. Rules that fail to evaluate or run past the
+// timeout return an error, so pre hooks fail closed.
+func (s *Script) Handle(event *Event) error {
+	env := &scriptEnv{event: event, now: s.now(), deadline: time.Now().Add(s.timeout)}
+
+	for i := range s.rules {
+		r := &s.rules[i]
+		env.rule = r
+		value, err := env.eval(r.expr)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", r.line, err)
+		}
+		matched, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("line %d: rule is not a condition", r.line)
+		}
+		if matched {
+			if r.reason == "" {
+				return ErrVetoed
+			}
+			return Veto(r.reason)
+		}
+	}
+	return nil
+}
+
+// scriptEnv evaluates rules for one event. Values are strings, float64
+// numbers or booleans.
+type scriptEnv struct {
+	event    *Event
+	now      time.Time
+	deadline time.Time
+	rule     *rule
+}
+
+func (env *scriptEnv) eval(expr ast.Expr) (interface{}, error) {
+	if time.Now().After(env.deadline) {
+		return nil, ErrScriptTimeout
+	}
+
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			return strconv.Unquote(e.Value)
+		}
+		return strconv.ParseFloat(e.Value, 64)
+	case *ast.Ident:
+		return env.variable(e.Name), nil
+	case *ast.SelectorExpr:
+		return scriptValue(env.event.Data[e.Sel.Name]), nil
+	case *ast.ParenExpr:
+		return env.eval(e.X)
+	case *ast.UnaryExpr:
+		x, err := env.eval(e.X)
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == token.NOT {
+			b, ok := x.(bool)
+			if !ok {
+				return nil, errors.New("! needs a condition")
+			}
+			return !b, nil
+		}
+		n, ok := x.(float64)
+		if !ok {
+			return nil, errors.New("- needs a number")
+		}
+		return -n, nil
+	case *ast.BinaryExpr:
+		return env.binary(e)
+	case *ast.CallExpr:
+		return env.call(e)
+	}
+	return nil, fmt.Errorf("unsupported expression %T", expr)
+}
+
+func (env *scriptEnv) variable(name string) interface{} {
+	switch name {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "point":
+		return string(env.event.Point)
+	case "email":
+		return env.event.Email
+	case "domain":
+		if at := strings.LastIndex(env.event.Email, "@"); at >= 0 {
+			return strings.ToLower(env.event.Email[at+1:])
+		}
+		return ""
+	case "userID":
+		return float64(env.event.UserID)
+	case "hour":
+		return float64(env.now.Hour())
+	case "minute":
+		return float64(env.now.Minute())
+	case "weekday":
+		return env.now.Weekday().String()
+	}
+	return nil
+}
+
+func (env *scriptEnv) binary(e *ast.BinaryExpr) (interface{}, error) {
+	x, err := env.eval(e.X)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Op == token.LAND || e.Op == token.LOR {
+		b, ok := x.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s needs conditions", e.Op)
+		}
+		if b == (e.Op == token.LOR) {
+			return b, nil
+		}
+		y, err := env.eval(e.Y)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := y.(bool); !ok {
+			return nil, fmt.Errorf("%s needs conditions", e.Op)
+		}
+		return y, nil
+	}
+
+	y, err := env.eval(e.Y)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Op {
+	case token.EQL:
+		return x == y, nil
+	case token.NEQ:
+		return x != y, nil
+	}
+
+	if xs, ok := x.(string); ok {
+		ys, ok := y.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot use %s on a string and %T", e.Op, y)
+		}
+		switch e.Op {
+		case token.ADD:
+			return xs + ys, nil
+		case token.LSS:
+			return xs < ys, nil
+		case token.LEQ:
+			return xs <= ys, nil
+		case token.GTR:
+			return xs > ys, nil
+		case token.GEQ:
+			return xs >= ys, nil
+		}
+		return nil, fmt.Errorf("cannot use %s on strings", e.Op)
+	}
+
+	xn, xok := x.(float64)
+	yn, yok := y.(float64)
+	if !xok || !yok {
+		return nil, fmt.Errorf("%s needs numbers", e.Op)
+	}
+	switch e.Op {
+	case token.ADD:
+		return xn + yn, nil
+	case token.SUB:
+		return xn - yn, nil
+	case token.MUL:
+		return xn * yn, nil
+	case token.QUO:
+		return xn / yn, nil
+	case token.REM:
+		return math.Mod(xn, yn), nil
+	case token.LSS:
+		return xn < yn, nil
+	case token.LEQ:
+		return xn <= yn, nil
+	case token.GTR:
+		return xn > yn, nil
+	case token.GEQ:
+		return xn >= yn, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %s", e.Op)
+}
+
+func (env *scriptEnv) call(e *ast.CallExpr) (interface{}, error) {
+	args := make([]interface{}, len(e.Args))
+	for i, arg := range e.Args {
+		value, err := env.eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	name := e.Fun.(*ast.Ident).Name
+	if name == "in" {
+		for _, candidate := range args[1:] {
+			if args[0] == candidate {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		s, ok := arg.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s needs strings", name)
+		}
+		strs[i] = s
+	}
+	switch name {
+	case "contains":
+		return strings.Contains(strs[0], strs[1]), nil
+	case "hasPrefix":
+		return strings.HasPrefix(strs[0], strs[1]), nil
+	case "hasSuffix":
+		return strings.HasSuffix(strs[0], strs[1]), nil
+	case "lower":
+		return strings.ToLower(strs[0]), nil
+	case "len":
+		return float64(len([]rune(strs[0]))), nil
+	case "matches":
+		return env.rule.regexps[e].MatchString(strs[0]), nil
+	}
+	return nil, fmt.Errorf("unknown function %q", name)
+}
+
+// scriptValue converts an event data value to a script value
+func scriptValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string, bool, float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return fmt.Sprint(v)
+}
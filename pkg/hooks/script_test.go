@@ -0,0 +1,134 @@
+package hooks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScript_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"syntax error", `email ==`},
+		{"unknown variable", `os == "linux"`},
+		{"unknown function", `exec("rm")`},
+		{"method call", `email.Close()`},
+		{"selector outside data", `event.email == ""`},
+		{"unsupported operator", `hour << 2 == 0`},
+		{"wrong argument count", `contains(email)`},
+		{"non-literal pattern", `matches(email, domain)`},
+		{"invalid pattern", `matches(email, "(")`},
+		{"function literal", `func() bool { for {} }()`},
+		{"too large", strings.Repeat("true\n", maxScriptSize)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseScript(tt.source, time.Second); err == nil {
+				t.Errorf("ParseScript(%q) should fail", tt.source)
+			}
+		})
+	}
+}
+
+func TestScript_Handle(t *testing.T) {
+	source := `
+# Signups from example.org are closed outside office hours
+point == "pre-register" && domain == "example.org" && (hour < 9 || hour >= 17)
+
+# Test accounts cannot sign in
+# on weekends
+point == "pre-login" && matches(email, "^test\\+") && in(weekday, "Saturday", "Sunday")
+
+len(data.fullName) > 50
+`
+	tests := []struct {
+		name       string
+		now        string
+		event      Event
+		wantReason string
+	}{
+		{"office hours", "2026-10-14T10:00:00Z", Event{Point: PreRegister, Email: "jane@Example.org"}, ""},
+		{"after hours", "2026-10-14T18:30:00Z", Event{Point: PreRegister, Email: "jane@Example.org"}, "Signups from example.org are closed outside office hours"},
+		{"other domain", "2026-10-14T18:30:00Z", Event{Point: PreRegister, Email: "jane@example.com"}, ""},
+		{"weekday test login", "2026-10-14T10:00:00Z", Event{Point: PreLogin, Email: "test+1@example.com"}, ""},
+		{"weekend test login", "2026-10-17T10:00:00Z", Event{Point: PreLogin, Email: "test+1@example.com"}, "Test accounts cannot sign in on weekends"},
+		{"rule without reason", "2026-10-14T10:00:00Z", Event{Point: PreRegister, Email: "a@b.co",
+			Data: map[string]interface{}{"fullName": strings.Repeat("x", 51)}}, "rejected by policy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := ParseScript(source, time.Second)
+			if err != nil {
+				t.Fatalf("ParseScript() error = %v", err)
+			}
+			now, _ := time.Parse(time.RFC3339, tt.now)
+			script.now = func() time.Time { return now }
+
+			err = script.Handle(&tt.event)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Errorf("Handle() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrVetoed) || !strings.Contains(err.Error(), tt.wantReason) {
+				t.Errorf("Handle() error = %v, want veto %q", err, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestScript_HandleErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		timeout time.Duration
+		wantErr error
+	}{
+		{"not a condition", `"pre-register"`, time.Second, nil},
+		{"type mismatch", `email > 3`, time.Second, nil},
+		{"timed out", `email == ""`, -time.Second, ErrScriptTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := ParseScript(tt.source, tt.timeout)
+			if err != nil {
+				t.Fatalf("ParseScript() error = %v", err)
+			}
+			err = script.Handle(&Event{Point: PreLogin, Email: "user@example.com"})
+			if err == nil || errors.Is(err, ErrVetoed) {
+				t.Fatalf("Handle() error = %v, want an evaluation error", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Handle() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadScript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.rules")
+	if err := os.WriteFile(path, []byte("# Closed\nuserID == 7\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	script, err := LoadScript(path, time.Second)
+	if err != nil {
+		t.Fatalf("LoadScript() error = %v", err)
+	}
+	if err := script.Handle(&Event{Point: PostLogin, UserID: 7}); !errors.Is(err, ErrVetoed) {
+		t.Errorf("Handle() error = %v, want veto", err)
+	}
+
+	if _, err := LoadScript(filepath.Join(t.TempDir(), "missing"), time.Second); err == nil {
+		t.Error("LoadScript() should fail for a missing file")
+	}
+}
@@ -0,0 +1,179 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"fiber-hello-world/pkg/signature"
+)
+
+// maxWebhookResponse caps how much of a webhook response is read
+const maxWebhookResponse = 64 << 10
+
+// HeaderEventID carries the event's ID on webhook requests. It is the same on
+// every retry of an event, so receivers dedupe on it.
+const HeaderEventID = "X-Event-Id"
+
+// WebhookResponse is the optional JSON body a webhook returns. Allow false
+// vetoes the operation with Reason; Data replaces the event's data, e.g.
+// to normalize registration fields or add token claims.
+type WebhookResponse struct {
+	Allow  *bool                  `json:"allow"`
+	Reason string                 `json:"reason"`
+	Data   map[string]interface{} `json:"data"`
+}
+
+// WebhookAttempt describes a request a webhook sent for an event.
+// StatusCode is 0 when no response was received; Err is nil when the
+// webhook accepted or vetoed the event.
+type WebhookAttempt struct {
+	EventID    string
+	Point      Point
+	UserID     int
+	StatusCode int
+	Err        error
+	Duration   time.Duration
+	At         time.Time
+}
+
+// Webhook is a hook that POSTs the event as JSON to an external service
+type Webhook struct {
+	url       string
+	secret    []byte
+	client    *http.Client
+	onAttempt []func(attempt WebhookAttempt)
+}
+
+// NewWebhook creates a webhook hook. When secret is set, requests are signed
+// like incoming signed requests (X-Signature-Timestamp, X-Signature-Nonce and
+// X-Signature) so the receiver can verify them.
+func NewWebhook(target, secret string, timeout time.Duration) (*Webhook, error) {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL %q", target)
+	}
+	return &Webhook{
+		url:    target,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// URL returns the address events are sent to
+func (w *Webhook) URL() string {
+	return w.url
+}
+
+// Ping checks that the webhook's host accepts connections, e.g. for
+// dependency checks, without sending an event
+func (w *Webhook) Ping(ctx context.Context) error {
+	u, err := url.Parse(w.url)
+	if err != nil {
+		return err
+	}
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// OnAttempt calls handle after every request the webhook sends, e.g. to keep
+// a delivery log. Register handlers before the server starts.
+func (w *Webhook) OnAttempt(handle func(attempt WebhookAttempt)) {
+	w.onAttempt = append(w.onAttempt, handle)
+}
+
+// Handle sends the event with its ID in X-Event-Id. An unreachable webhook or
+// a non-2xx response is an error, so pre hooks fail closed. An empty 2xx
+// response allows the operation unchanged.
+func (w *Webhook) Handle(event *Event) error {
+	attempt := WebhookAttempt{EventID: event.ID, Point: event.Point, UserID: event.UserID, At: time.Now()}
+	err := w.send(event, &attempt.StatusCode)
+	attempt.Duration = time.Since(attempt.At)
+	if err != nil && !errors.Is(err, ErrVetoed) {
+		attempt.Err = err
+	}
+	for _, handle := range w.onAttempt {
+		handle(attempt)
+	}
+	return err
+}
+
+// send POSTs the event and applies the response, setting statusCode once
+// one is received
+func (w *Webhook) send(event *Event, statusCode *int) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, event.ID)
+	if len(w.secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		nonce := make([]byte, 16)
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		req.Header.Set(signature.HeaderTimestamp, timestamp)
+		req.Header.Set(signature.HeaderNonce, hex.EncodeToString(nonce))
+		req.Header.Set(signature.HeaderSignature, signature.Sign(w.secret, req.Method, req.URL.RequestURI(),
+			timestamp, hex.EncodeToString(nonce), "", body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	*statusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponse))
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	var result WebhookResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("invalid webhook response: %w", err)
+	}
+	if result.Allow != nil && !*result.Allow {
+		reason := result.Reason
+		if reason == "" {
+			reason = "rejected by " + string(event.Point) + " webhook"
+		}
+		return Veto(reason)
+	}
+	if result.Data != nil {
+		event.Data = result.Data
+	}
+	return nil
+}
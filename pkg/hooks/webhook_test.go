@@ -0,0 +1,162 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fiber-hello-world/pkg/signature"
+)
+
+func TestNewWebhook(t *testing.T) {
+	for _, target := range []string{"", "ftp://hooks.internal", "not a url", "https://"} {
+		if _, err := NewWebhook(target, "", time.Second); err == nil {
+			t.Errorf("NewWebhook(%q) should fail", target)
+		}
+	}
+}
+
+func TestWebhook_Handle(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		wantErr  error
+		wantData map[string]interface{}
+	}{
+		{"empty response allows", 204, "", nil, map[string]interface{}{"fullName": "Original"}},
+		{"allow", 200, `{"allow":true}`, nil, map[string]interface{}{"fullName": "Original"}},
+		{"veto with reason", 200, `{"allow":false,"reason":"closed"}`, ErrVetoed, nil},
+		{"data replaces event data", 200, `{"data":{"fullName":"Normalized"}}`, nil, map[string]interface{}{"fullName": "Normalized"}},
+		{"error status", 500, "", errors.New("webhook returned 500"), nil},
+		{"invalid body", 200, "not json", errors.New("invalid"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received Event
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&received)
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.response)
+			}))
+			defer ts.Close()
+
+			webhook, err := NewWebhook(ts.URL, "", time.Second)
+			if err != nil {
+				t.Fatalf("NewWebhook() error = %v", err)
+			}
+			event := &Event{Point: PreRegister, Email: "user@example.com", Data: map[string]interface{}{"fullName": "Original"}}
+			err = webhook.Handle(event)
+
+			if received.Point != PreRegister || received.Email != "user@example.com" {
+				t.Errorf("webhook received %+v", received)
+			}
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("Handle() error = %v", err)
+			case errors.Is(tt.wantErr, ErrVetoed) && !errors.Is(err, ErrVetoed):
+				t.Fatalf("Handle() error = %v, want a veto", err)
+			case tt.wantErr != nil && err == nil:
+				t.Fatalf("Handle() error = nil, want %v", tt.wantErr)
+			}
+			if tt.wantData != nil && event.Data["fullName"] != tt.wantData["fullName"] {
+				t.Errorf("Data = %v, want %v", event.Data, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestWebhook_Signed(t *testing.T) {
+	verifier := signature.NewVerifier(map[string]string{"hooks": "hook-secret"}, time.Minute)
+	var verifyErr error
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verifyErr = verifier.Verify(signature.Request{
+			ClientID:  "hooks",
+			Method:    r.Method,
+			URI:       r.URL.RequestURI(),
+			Timestamp: r.Header.Get(signature.HeaderTimestamp),
+			Nonce:     r.Header.Get(signature.HeaderNonce),
+			Signature: r.Header.Get(signature.HeaderSignature),
+			Body:      body,
+		})
+	}))
+	defer ts.Close()
+
+	webhook, _ := NewWebhook(ts.URL+"/login?v=1", "hook-secret", time.Second)
+	if err := webhook.Handle(&Event{Point: PostLogin, UserID: 1, Email: "user@example.com"}); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if verifyErr != nil {
+		t.Errorf("signature verification error = %v", verifyErr)
+	}
+}
+
+func TestWebhook_EventIDAndAttempts(t *testing.T) {
+	statuses := []int{503, 200}
+	var ids []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var received Event
+		json.NewDecoder(r.Body).Decode(&received)
+		if received.ID != r.Header.Get(HeaderEventID) {
+			t.Errorf("body ID %q differs from %s %q", received.ID, HeaderEventID, r.Header.Get(HeaderEventID))
+		}
+		ids = append(ids, r.Header.Get(HeaderEventID))
+		w.WriteHeader(statuses[len(ids)-1])
+	}))
+	defer ts.Close()
+
+	webhook, _ := NewWebhook(ts.URL, "", time.Second)
+	var attempts []WebhookAttempt
+	webhook.OnAttempt(func(attempt WebhookAttempt) {
+		attempts = append(attempts, attempt)
+	})
+
+	// A retry sends the same event again
+	event := &Event{ID: "evt_1", Point: PostRegister, UserID: 3}
+	if err := webhook.Handle(event); err == nil {
+		t.Fatal("Handle() error = nil, want the 503")
+	}
+	if err := webhook.Handle(event); err != nil {
+		t.Fatalf("Handle() retry error = %v", err)
+	}
+
+	if len(ids) != 2 || ids[0] != "evt_1" || ids[1] != "evt_1" {
+		t.Errorf("%s headers = %v, want evt_1 twice", HeaderEventID, ids)
+	}
+	if len(attempts) != 2 || attempts[0].StatusCode != 503 || attempts[0].Err == nil ||
+		attempts[1].StatusCode != 200 || attempts[1].Err != nil || attempts[1].EventID != "evt_1" || attempts[1].UserID != 3 {
+		t.Errorf("attempts = %+v, want a failed 503 and a delivered 200", attempts)
+	}
+
+	// Unreachable webhooks report no status
+	ts.Close()
+	webhook.Handle(event)
+	if last := attempts[len(attempts)-1]; last.StatusCode != 0 || last.Err == nil {
+		t.Errorf("attempt at a closed server = %+v, want an error without status", last)
+	}
+}
+
+func TestWebhook_Ping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Ping() should not send a request")
+	}))
+	webhook, _ := NewWebhook(server.URL+"/hooks", "", time.Second)
+	if webhook.URL() != server.URL+"/hooks" {
+		t.Errorf("URL() = %q", webhook.URL())
+	}
+	if err := webhook.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+
+	server.Close()
+	if err := webhook.Ping(context.Background()); err == nil {
+		t.Error("Ping() of a stopped server should fail")
+	}
+}
@@ -0,0 +1,129 @@
+package idgen
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Layout of a generated ID, most significant bits first:
+//
+//	41 bits  milliseconds since Epoch (about 69 years)
+//	 5 bits  node ID (up to 32 nodes across all regions)
+//	 7 bits  per-millisecond sequence (128 IDs per node per millisecond)
+//
+// The total of 53 bits keeps IDs exactly representable as JavaScript numbers,
+// so browser clients can round-trip them through JSON.
+const (
+	nodeBits     = 5
+	sequenceBits = 7
+	timeBits     = 41
+
+	// MaxNodeID is the largest accepted node ID
+	MaxNodeID   = 1<<nodeBits - 1
+	maxSequence = 1<<sequenceBits - 1
+	maxElapsed  = 1<<timeBits - 1
+)
+
+// Epoch is the reference time IDs are measured from
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	// ErrInvalidNodeID is returned for node IDs outside 0..MaxNodeID
+	ErrInvalidNodeID = errors.New("node ID must be between 0 and 31")
+	// ErrClockBackwards is returned when the clock moves back further than the generator tolerates
+	ErrClockBackwards = errors.New("clock moved backwards")
+	// ErrClockOutOfRange is returned when the clock is before Epoch or past the end of the ID space
+	ErrClockOutOfRange = errors.New("clock is outside the supported ID range")
+)
+
+// maxBackwardsWait is how far the clock may step back before NextID gives up
+const maxBackwardsWait = 10 * time.Millisecond
+
+// Snowflake generates roughly time-ordered, unique 53-bit IDs without
+// coordination, as long as every node in every region has a distinct node ID
+type Snowflake struct {
+	mu       sync.Mutex
+	nodeID   int64
+	lastMS   int64
+	sequence int64
+	now      func() time.Time
+	sleep    func(time.Duration)
+}
+
+// NewSnowflake creates an ID generator for the given node
+func NewSnowflake(nodeID int) (*Snowflake, error) {
+	if nodeID < 0 || nodeID > MaxNodeID {
+		return nil, ErrInvalidNodeID
+	}
+
+	return &Snowflake{
+		nodeID: int64(nodeID),
+		lastMS: -1,
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}, nil
+}
+
+// NextID returns the next unique ID
+func (s *Snowflake) NextID() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms, err := s.elapsed()
+	if err != nil {
+		return 0, err
+	}
+
+	// Small backwards steps (e.g. NTP adjustments) are waited out
+	if ms < s.lastMS {
+		wait := time.Duration(s.lastMS-ms) * time.Millisecond
+		if wait > maxBackwardsWait {
+			return 0, ErrClockBackwards
+		}
+		s.sleep(wait)
+		if ms, err = s.elapsed(); err != nil {
+			return 0, err
+		}
+		if ms < s.lastMS {
+			return 0, ErrClockBackwards
+		}
+	}
+
+	if ms == s.lastMS {
+		s.sequence = (s.sequence + 1) & maxSequence
+		if s.sequence == 0 {
+			// Sequence exhausted for this millisecond; wait for the next one
+			for ms <= s.lastMS {
+				s.sleep(time.Millisecond)
+				if ms, err = s.elapsed(); err != nil {
+					return 0, err
+				}
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.lastMS = ms
+
+	return int(ms<<(nodeBits+sequenceBits) | s.nodeID<<sequenceBits | s.sequence), nil
+}
+
+// elapsed returns the milliseconds since Epoch
+func (s *Snowflake) elapsed() (int64, error) {
+	ms := s.now().Sub(Epoch).Milliseconds()
+	if ms < 0 || ms > maxElapsed {
+		return 0, ErrClockOutOfRange
+	}
+	return ms, nil
+}
+
+// Time extracts the creation time encoded in an ID
+func Time(id int) time.Time {
+	return Epoch.Add(time.Duration(int64(id)>>(nodeBits+sequenceBits)) * time.Millisecond)
+}
+
+// NodeID extracts the node ID encoded in an ID
+func NodeID(id int) int {
+	return int(int64(id) >> sequenceBits & MaxNodeID)
+}
@@ -0,0 +1,130 @@
+package idgen
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewSnowflake_InvalidNodeID(t *testing.T) {
+	for _, nodeID := range []int{-1, MaxNodeID + 1} {
+		if _, err := NewSnowflake(nodeID); !errors.Is(err, ErrInvalidNodeID) {
+			t.Errorf("NewSnowflake(%d) error = %v, want ErrInvalidNodeID", nodeID, err)
+		}
+	}
+}
+
+func TestSnowflake_NextID(t *testing.T) {
+	s, err := NewSnowflake(7)
+	if err != nil {
+		t.Fatalf("NewSnowflake() error = %v", err)
+	}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	id, err := s.NextID()
+	if err != nil {
+		t.Fatalf("NextID() error = %v", err)
+	}
+	if !Time(id).Equal(now) {
+		t.Errorf("Time() = %v, want %v", Time(id), now)
+	}
+	if NodeID(id) != 7 {
+		t.Errorf("NodeID() = %v, want 7", NodeID(id))
+	}
+	if id > 1<<53 {
+		t.Errorf("ID %d exceeds 53 bits", id)
+	}
+
+	// IDs within the same millisecond still increase
+	next, err := s.NextID()
+	if err != nil {
+		t.Fatalf("NextID() error = %v", err)
+	}
+	if next <= id {
+		t.Errorf("NextID() = %d, want greater than %d", next, id)
+	}
+}
+
+func TestSnowflake_SequenceExhaustionWaitsForNextMillisecond(t *testing.T) {
+	s, _ := NewSnowflake(1)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.sleep = func(d time.Duration) { now = now.Add(d) }
+
+	seen := make(map[int]bool)
+	for i := 0; i < (maxSequence+1)*3; i++ {
+		id, err := s.NextID()
+		if err != nil {
+			t.Fatalf("NextID() error = %v", err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate ID %d", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSnowflake_ClockBackwards(t *testing.T) {
+	s, _ := NewSnowflake(1)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.sleep = func(d time.Duration) { now = now.Add(d) }
+
+	first, _ := s.NextID()
+
+	// A small step back is waited out
+	now = now.Add(-5 * time.Millisecond)
+	id, err := s.NextID()
+	if err != nil {
+		t.Fatalf("NextID() after small step back error = %v", err)
+	}
+	if id <= first {
+		t.Errorf("NextID() = %d, want greater than %d", id, first)
+	}
+
+	// A large step back is refused rather than risking duplicates
+	s.sleep = func(time.Duration) {}
+	now = now.Add(-time.Second)
+	if _, err := s.NextID(); !errors.Is(err, ErrClockBackwards) {
+		t.Errorf("NextID() error = %v, want ErrClockBackwards", err)
+	}
+}
+
+func TestSnowflake_ClockOutOfRange(t *testing.T) {
+	s, _ := NewSnowflake(1)
+	s.now = func() time.Time { return Epoch.Add(-time.Hour) }
+
+	if _, err := s.NextID(); !errors.Is(err, ErrClockOutOfRange) {
+		t.Errorf("NextID() error = %v, want ErrClockOutOfRange", err)
+	}
+}
+
+func TestSnowflake_Concurrent(t *testing.T) {
+	s, _ := NewSnowflake(3)
+
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				id, err := s.NextID()
+				if err != nil {
+					t.Errorf("NextID() error = %v", err)
+					return
+				}
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("duplicate ID %d", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,161 @@
+// Package inbound receives webhooks from integrated providers, such as
+// SendGrid bounces, Twilio delivery statuses and Stripe payments: it checks
+// each provider's signature, parses the events and routes them to handlers.
+package inbound
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// MaxSkew is how far a signed timestamp may differ from the server clock
+const MaxSkew = 5 * time.Minute
+
+var (
+	// ErrUnknownProvider is returned for a provider that is not configured
+	ErrUnknownProvider = errors.New("unknown webhook provider")
+	// ErrInvalidSignature is returned when a request's signature is missing
+	// or does not match
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrExpired is returned when a signed timestamp is outside MaxSkew
+	ErrExpired = errors.New("webhook timestamp out of range")
+	// ErrInvalidPayload is returned when a verified request cannot be parsed
+	ErrInvalidPayload = errors.New("invalid webhook payload")
+)
+
+// Request is a received webhook request
+type Request struct {
+	// URL is the full URL the provider posted to, with its query
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// Event is an event parsed from a verified request. ID is unique per
+// provider, so a repeated ID is a retry or a replay.
+type Event struct {
+	Provider string
+	ID       string
+	// Type is the provider's event type, e.g. "bounce", "undelivered" or
+	// "invoice.paid"
+	Type string
+	// Subject is what the event is about, e.g. an email address, a phone
+	// number or a payment object ID
+	Subject string
+	// At is when the provider says the event happened, zero if it does not
+	At   time.Time
+	Data map[string]interface{}
+}
+
+// Provider verifies and parses one provider's webhooks
+type Provider interface {
+	// Name is the provider's name in webhook URLs and configuration
+	Name() string
+	// Verify checks the request's signature, and its timestamp at now when
+	// the provider signs one
+	Verify(req *Request, now time.Time) error
+	// Events parses a verified request
+	Events(req *Request) ([]Event, error)
+}
+
+// NewProvider creates the built-in provider name, "sendgrid", "twilio" or
+// "stripe", with its signing secret: the SendGrid verification key, the
+// Twilio auth token or the Stripe endpoint secret
+func NewProvider(name, secret string) (Provider, error) {
+	switch name {
+	case "sendgrid":
+		return NewSendGrid(secret)
+	case "twilio":
+		return NewTwilio(secret), nil
+	case "stripe":
+		return NewStripe(secret), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+}
+
+// Handler handles an event. An error makes the request fail, so the
+// provider retries it.
+type Handler interface {
+	Handle(event *Event) error
+}
+
+// HandlerFunc adapts a function to the Handler interface
+type HandlerFunc func(event *Event) error
+
+// Handle calls f(event)
+func (f HandlerFunc) Handle(event *Event) error {
+	return f(event)
+}
+
+// AnyType registers a handler for every event type of a provider
+const AnyType = "*"
+
+// Router holds the providers and routes their events to handlers by type
+type Router struct {
+	providers map[string]Provider
+	handlers  map[string][]Handler
+}
+
+// NewRouter creates an empty router
+func NewRouter() *Router {
+	return &Router{
+		providers: make(map[string]Provider),
+		handlers:  make(map[string][]Handler),
+	}
+}
+
+// AddProvider accepts webhooks from provider, replacing one with the same name
+func (r *Router) AddProvider(provider Provider) {
+	r.providers[provider.Name()] = provider
+}
+
+// Provider returns the provider named name. Returns ErrUnknownProvider.
+func (r *Router) Provider(name string) (Provider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+	return provider, nil
+}
+
+// Providers returns the names of the providers, sorted
+func (r *Router) Providers() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Handle routes provider's events of eventType, or of any type with
+// AnyType, to handler. Handlers run in registration order. Register all
+// handlers before the server starts handling requests.
+func (r *Router) Handle(provider, eventType string, handler Handler) {
+	key := provider + "\n" + eventType
+	r.handlers[key] = append(r.handlers[key], handler)
+}
+
+// Dispatch runs the handlers of the event's provider and type and stops at
+// the first error. Events without handlers are accepted.
+func (r *Router) Dispatch(event *Event) error {
+	for _, key := range []string{event.Provider + "\n" + event.Type, event.Provider + "\n" + AnyType} {
+		for _, handler := range r.handlers[key] {
+			if err := handler.Handle(event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkTimestamp fails with ErrExpired unless signedAt is within MaxSkew of now
+func checkTimestamp(signedAt, now time.Time) error {
+	if signedAt.Before(now.Add(-MaxSkew)) || signedAt.After(now.Add(MaxSkew)) {
+		return ErrExpired
+	}
+	return nil
+}
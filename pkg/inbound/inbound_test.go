@@ -0,0 +1,60 @@
+package inbound
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNewProvider(t *testing.T) {
+	for _, name := range []string{"twilio", "stripe"} {
+		provider, err := NewProvider(name, "secret")
+		if err != nil || provider.Name() != name {
+			t.Errorf("NewProvider(%q) = %v, %v", name, provider, err)
+		}
+	}
+	if _, err := NewProvider("sendgrid", "not a key"); err == nil {
+		t.Error("NewProvider(sendgrid) with an invalid key should fail")
+	}
+	if _, err := NewProvider("paypal", "secret"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("NewProvider(paypal) error = %v, want ErrUnknownProvider", err)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	router := NewRouter()
+	router.AddProvider(NewTwilio("token"))
+	router.AddProvider(NewStripe("secret"))
+
+	if names := router.Providers(); !slices.Equal(names, []string{"stripe", "twilio"}) {
+		t.Errorf("Providers() = %v", names)
+	}
+	if _, err := router.Provider("sendgrid"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("Provider(sendgrid) error = %v, want ErrUnknownProvider", err)
+	}
+
+	var ran []string
+	router.Handle("stripe", "charge.refunded", HandlerFunc(func(event *Event) error {
+		ran = append(ran, "refunded "+event.ID)
+		return nil
+	}))
+	router.Handle("stripe", AnyType, HandlerFunc(func(event *Event) error {
+		ran = append(ran, "any "+event.ID)
+		return nil
+	}))
+	router.Handle("twilio", "failed", HandlerFunc(func(*Event) error {
+		return errors.New("store unavailable")
+	}))
+
+	router.Dispatch(&Event{Provider: "stripe", ID: "evt_1", Type: "charge.refunded"})
+	router.Dispatch(&Event{Provider: "stripe", ID: "evt_2", Type: "invoice.paid"})
+	if !slices.Equal(ran, []string{"refunded evt_1", "any evt_1", "any evt_2"}) {
+		t.Errorf("handlers ran %v", ran)
+	}
+	if err := router.Dispatch(&Event{Provider: "twilio", ID: "SM1:failed", Type: "failed"}); err == nil {
+		t.Error("Dispatch() should return the handler's error")
+	}
+	if err := router.Dispatch(&Event{Provider: "twilio", ID: "SM1:sent", Type: "sent"}); err != nil {
+		t.Errorf("Dispatch() without handlers error = %v", err)
+	}
+}
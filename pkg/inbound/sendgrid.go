@@ -0,0 +1,94 @@
+package inbound
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Headers of SendGrid's signed Event Webhook
+const (
+	HeaderSendGridSignature = "X-Twilio-Email-Event-Webhook-Signature"
+	HeaderSendGridTimestamp = "X-Twilio-Email-Event-Webhook-Timestamp"
+)
+
+// SendGrid verifies SendGrid Event Webhook requests, which are signed with
+// ECDSA over the timestamp and the body. Its events have the types SendGrid
+// reports, such as "bounce", "dropped", "spamreport" and "delivered", and
+// the recipient as their subject.
+type SendGrid struct {
+	key *ecdsa.PublicKey
+}
+
+// NewSendGrid creates a SendGrid provider with the base64 verification key
+// shown in SendGrid's Mail Settings
+func NewSendGrid(publicKey string) (*SendGrid, error) {
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SendGrid verification key: %w", err)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SendGrid verification key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid SendGrid verification key: not an ECDSA key")
+	}
+	return &SendGrid{key: key}, nil
+}
+
+// Name returns "sendgrid"
+func (s *SendGrid) Name() string {
+	return "sendgrid"
+}
+
+// Verify checks the ECDSA signature of the timestamp followed by the body
+func (s *SendGrid) Verify(req *Request, now time.Time) error {
+	timestamp := req.Header.Get(HeaderSendGridTimestamp)
+	sig, err := base64.StdEncoding.DecodeString(req.Header.Get(HeaderSendGridSignature))
+	if timestamp == "" || err != nil || len(sig) == 0 {
+		return ErrInvalidSignature
+	}
+
+	digest := sha256.Sum256(append([]byte(timestamp), req.Body...))
+	if !ecdsa.VerifyASN1(s.key, digest[:], sig) {
+		return ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrExpired
+	}
+	return checkTimestamp(time.Unix(seconds, 0), now)
+}
+
+// Events parses the batch of events, identified by their sg_event_id
+func (s *SendGrid) Events(req *Request) ([]Event, error) {
+	var batch []map[string]interface{}
+	if err := json.Unmarshal(req.Body, &batch); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	events := make([]Event, 0, len(batch))
+	for _, data := range batch {
+		id, _ := data["sg_event_id"].(string)
+		eventType, _ := data["event"].(string)
+		if id == "" || eventType == "" {
+			return nil, fmt.Errorf("%w: SendGrid event without sg_event_id or event", ErrInvalidPayload)
+		}
+		event := Event{Provider: s.Name(), ID: id, Type: eventType, Data: data}
+		event.Subject, _ = data["email"].(string)
+		if seconds, ok := data["timestamp"].(float64); ok {
+			event.At = time.Unix(int64(seconds), 0).UTC()
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,71 @@
+package inbound
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// signSendGrid returns a request signed like SendGrid's Event Webhook
+func signSendGrid(t *testing.T, key *ecdsa.PrivateKey, at time.Time, body string) *Request {
+	t.Helper()
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	digest := sha256.Sum256([]byte(timestamp + body))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := http.Header{}
+	header.Set(HeaderSendGridTimestamp, timestamp)
+	header.Set(HeaderSendGridSignature, base64.StdEncoding.EncodeToString(sig))
+	return &Request{URL: "https://api.example.com/webhooks/sendgrid", Header: header, Body: []byte(body)}
+}
+
+func TestSendGrid(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	provider, err := NewSendGrid(base64.StdEncoding.EncodeToString(der))
+	if err != nil {
+		t.Fatalf("NewSendGrid() error = %v", err)
+	}
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	body := `[{"email":"lee@example.com","timestamp":1717243200,"event":"bounce","sg_event_id":"sg-1","reason":"550 mailbox unavailable"},` +
+		`{"email":"kim@example.com","timestamp":1717243201,"event":"delivered","sg_event_id":"sg-2"}]`
+	req := signSendGrid(t, key, now, body)
+	if err := provider.Verify(req, now); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	events, err := provider.Events(req)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("Events() = %v, %v", events, err)
+	}
+	if e := events[0]; e.Provider != "sendgrid" || e.ID != "sg-1" || e.Type != "bounce" || e.Subject != "lee@example.com" ||
+		!e.At.Equal(now) || e.Data["reason"] != "550 mailbox unavailable" {
+		t.Errorf("Events()[0] = %+v", e)
+	}
+
+	tampered := signSendGrid(t, key, now, body)
+	tampered.Body = []byte(`[]`)
+	if err := provider.Verify(tampered, now); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() of a changed body error = %v, want ErrInvalidSignature", err)
+	}
+	if err := provider.Verify(&Request{Header: http.Header{}, Body: []byte(body)}, now); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() unsigned error = %v, want ErrInvalidSignature", err)
+	}
+	if err := provider.Verify(signSendGrid(t, key, now.Add(-10*time.Minute), body), now); !errors.Is(err, ErrExpired) {
+		t.Errorf("Verify() of an old request error = %v, want ErrExpired", err)
+	}
+	if _, err := provider.Events(&Request{Body: []byte(`[{"event":"bounce"}]`)}); !errors.Is(err, ErrInvalidPayload) {
+		t.Errorf("Events() without sg_event_id error = %v, want ErrInvalidPayload", err)
+	}
+}
@@ -0,0 +1,97 @@
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeaderStripeSignature carries the timestamp and signatures of Stripe events
+const HeaderStripeSignature = "Stripe-Signature"
+
+// Stripe verifies Stripe webhook events, which are signed with HMAC-SHA256
+// over the timestamp and the body. Its events have Stripe's types, such as
+// "payment_intent.succeeded" or "charge.refunded", and the ID of the object
+// they are about as their subject.
+type Stripe struct {
+	secret []byte
+}
+
+// NewStripe creates a Stripe provider with the endpoint's signing secret
+func NewStripe(secret string) *Stripe {
+	return &Stripe{secret: []byte(secret)}
+}
+
+// Name returns "stripe"
+func (s *Stripe) Name() string {
+	return "stripe"
+}
+
+// Verify checks that one of the v1 signatures in Stripe-Signature matches
+// the timestamp t, a dot and the body
+func (s *Stripe) Verify(req *Request, now time.Time) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(req.Header.Get(HeaderStripeSignature), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(req.Body)
+	expected := mac.Sum(nil)
+	valid := false
+	for _, sig := range signatures {
+		valid = valid || hmac.Equal(expected, sig)
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrExpired
+	}
+	return checkTimestamp(time.Unix(seconds, 0), now)
+}
+
+// Events parses the event, whose data is the object it is about
+func (s *Stripe) Events(req *Request) ([]Event, error) {
+	var payload struct {
+		ID      string `json:"id"`
+		Type    string `json:"type"`
+		Created int64  `json:"created"`
+		Data    struct {
+			Object map[string]interface{} `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(req.Body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if payload.ID == "" || payload.Type == "" {
+		return nil, fmt.Errorf("%w: Stripe event without id or type", ErrInvalidPayload)
+	}
+
+	event := Event{Provider: s.Name(), ID: payload.ID, Type: payload.Type, Data: payload.Data.Object}
+	event.Subject, _ = payload.Data.Object["id"].(string)
+	if payload.Created > 0 {
+		event.At = time.Unix(payload.Created, 0).UTC()
+	}
+	return []Event{event}, nil
+}
@@ -0,0 +1,58 @@
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// signStripe returns a Stripe-Signature header value for body at at
+func signStripe(secret string, at time.Time, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s", at.Unix(), body)
+	return fmt.Sprintf("t=%d,v1=%s", at.Unix(), hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestStripe(t *testing.T) {
+	provider := NewStripe("whsec_test")
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	body := `{"id":"evt_1","type":"charge.refunded","created":1717243200,"data":{"object":{"id":"ch_1","amount":500}}}`
+
+	tests := []struct {
+		name      string
+		signature string
+		wantErr   error
+	}{
+		{"valid", signStripe("whsec_test", now, body), nil},
+		{"one of several signatures", signStripe("whsec_test", now, body) + ",v1=00ff", nil},
+		{"wrong secret", signStripe("whsec_other", now, body), ErrInvalidSignature},
+		{"missing", "", ErrInvalidSignature},
+		{"too old", signStripe("whsec_test", now.Add(-time.Hour), body), ErrExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(HeaderStripeSignature, tt.signature)
+			err := provider.Verify(&Request{Header: header, Body: []byte(body)}, now)
+			if (tt.wantErr == nil && err != nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	events, err := provider.Events(&Request{Body: []byte(body)})
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Events() = %v, %v", events, err)
+	}
+	if e := events[0]; e.ID != "evt_1" || e.Type != "charge.refunded" || e.Subject != "ch_1" || !e.At.Equal(now) || e.Data["amount"] != float64(500) {
+		t.Errorf("Events()[0] = %+v", e)
+	}
+	if _, err := provider.Events(&Request{Body: []byte(`{"type":"charge.refunded"}`)}); !errors.Is(err, ErrInvalidPayload) {
+		t.Errorf("Events() without an id error = %v, want ErrInvalidPayload", err)
+	}
+}
@@ -0,0 +1,98 @@
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Headers of Twilio's status callbacks
+const (
+	HeaderTwilioSignature   = "X-Twilio-Signature"
+	HeaderTwilioIdempotency = "I-Twilio-Idempotency-Token"
+)
+
+// Twilio verifies Twilio status callbacks, which are signed with HMAC-SHA1
+// over the URL and the form parameters. Its events have the message or call
+// status as their type, such as "delivered", "undelivered" or "failed", and
+// the recipient as their subject. Twilio does not sign a timestamp, so
+// replays are only caught by their event ID.
+type Twilio struct {
+	authToken []byte
+}
+
+// NewTwilio creates a Twilio provider with the account's auth token
+func NewTwilio(authToken string) *Twilio {
+	return &Twilio{authToken: []byte(authToken)}
+}
+
+// Name returns "twilio"
+func (t *Twilio) Name() string {
+	return "twilio"
+}
+
+// Verify checks the signature of the URL followed by every form parameter's
+// name and value, sorted by name
+func (t *Twilio) Verify(req *Request, _ time.Time) error {
+	sig, err := base64.StdEncoding.DecodeString(req.Header.Get(HeaderTwilioSignature))
+	if err != nil || len(sig) == 0 {
+		return ErrInvalidSignature
+	}
+	form, err := url.ParseQuery(string(req.Body))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	var signed strings.Builder
+	signed.WriteString(req.URL)
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, value := range form[name] {
+			signed.WriteString(name)
+			signed.WriteString(value)
+		}
+	}
+
+	mac := hmac.New(sha1.New, t.authToken)
+	mac.Write([]byte(signed.String()))
+	if !hmac.Equal(mac.Sum(nil), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Events parses the callback as one event, identified by Twilio's
+// idempotency token, or by the message or call SID and status without one
+func (t *Twilio) Events(req *Request) ([]Event, error) {
+	form, err := url.ParseQuery(string(req.Body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	sid, status := form.Get("MessageSid"), form.Get("MessageStatus")
+	if sid == "" {
+		sid, status = form.Get("CallSid"), form.Get("CallStatus")
+	}
+	if sid == "" || status == "" {
+		return nil, fmt.Errorf("%w: Twilio callback without a message or call status", ErrInvalidPayload)
+	}
+
+	id := req.Header.Get(HeaderTwilioIdempotency)
+	if id == "" {
+		id = sid + ":" + status
+	}
+	data := make(map[string]interface{}, len(form))
+	for name := range form {
+		data[name] = form.Get(name)
+	}
+	return []Event{{Provider: t.Name(), ID: id, Type: status, Subject: form.Get("To"), Data: data}}, nil
+}
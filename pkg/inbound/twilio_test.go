@@ -0,0 +1,48 @@
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTwilio(t *testing.T) {
+	provider := NewTwilio("auth-token")
+	target := "https://api.example.com/webhooks/twilio?account=main"
+	body := "To=%2B15550100&MessageStatus=undelivered&MessageSid=SM123&ErrorCode=30003"
+
+	// Twilio signs the URL and the parameters sorted by name
+	mac := hmac.New(sha1.New, []byte("auth-token"))
+	mac.Write([]byte(target + "ErrorCode30003MessageSidSM123MessageStatusundeliveredTo+15550100"))
+	header := http.Header{}
+	header.Set(HeaderTwilioSignature, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	req := &Request{URL: target, Header: header, Body: []byte(body)}
+
+	if err := provider.Verify(req, time.Now()); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	events, err := provider.Events(req)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Events() = %v, %v", events, err)
+	}
+	if e := events[0]; e.ID != "SM123:undelivered" || e.Type != "undelivered" || e.Subject != "+15550100" || e.Data["ErrorCode"] != "30003" {
+		t.Errorf("Events()[0] = %+v", e)
+	}
+
+	header.Set(HeaderTwilioIdempotency, "idem-1")
+	if events, _ := provider.Events(req); events[0].ID != "idem-1" {
+		t.Errorf("Events() ID = %q, want the idempotency token", events[0].ID)
+	}
+
+	// The signature covers the URL as well as the body
+	if err := provider.Verify(&Request{URL: "https://api.example.com/webhooks/twilio", Header: header, Body: []byte(body)}, time.Now()); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() at another URL error = %v, want ErrInvalidSignature", err)
+	}
+	if _, err := provider.Events(&Request{Body: []byte("To=%2B15550100")}); !errors.Is(err, ErrInvalidPayload) {
+		t.Errorf("Events() without a status error = %v, want ErrInvalidPayload", err)
+	}
+}
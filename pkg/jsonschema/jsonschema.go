@@ -0,0 +1,346 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Schema is a compiled JSON Schema supporting the subset of keywords used by the API
+type Schema struct {
+	Type                 string             `json:"type"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Format               string             `json:"format"`
+	Enum                 []interface{}      `json:"enum"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+
+	pattern *regexp.Regexp
+}
+
+// FieldError describes a single schema violation
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+
+	// format and args make up Message, so it can be translated
+	format string
+	args   []interface{}
+}
+
+// newFieldError returns a FieldError whose message is format with args
+func newFieldError(field, format string, args ...interface{}) FieldError {
+	return FieldError{Field: field, Message: fmt.Sprintf(format, args...), format: format, args: args}
+}
+
+// Locales validation errors can be written in
+const (
+	LocaleEnglish = "en"
+	LocaleThai    = "th"
+)
+
+// thaiMessages translates the message formats of FieldError
+var thaiMessages = map[string]string{
+	"must be valid JSON":             "ต้องเป็น JSON ที่ถูกต้อง",
+	"must be of type %s":             "ต้องเป็นชนิด %s",
+	"must be one of %v":              "ต้องเป็นค่าใดค่าหนึ่งใน %v",
+	"is required":                    "ต้องระบุ",
+	"is not allowed":                 "ไม่รองรับฟิลด์นี้",
+	"must contain at least %d items": "ต้องมีอย่างน้อย %d รายการ",
+	"must contain at most %d items":  "ต้องมีไม่เกิน %d รายการ",
+	"must be at least %d characters": "ต้องมีอย่างน้อย %d ตัวอักษร",
+	"must be at most %d characters":  "ต้องมีไม่เกิน %d ตัวอักษร",
+	"must match pattern %s":          "ต้องตรงกับรูปแบบ %s",
+	"must be a valid %s":             "ต้องเป็น %s ที่ถูกต้อง",
+	"must be >= %v":                  "ต้องไม่น้อยกว่า %v",
+	"must be <= %v":                  "ต้องไม่เกิน %v",
+}
+
+// ValidationError is returned when a document does not match its schema
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		messages[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// formats checks values of the supported string formats
+var formats = map[string]func(string) bool{
+	"email": emailPattern.MatchString,
+	"date": func(v string) bool {
+		_, err := time.Parse("2006-01-02", v)
+		return err == nil
+	},
+}
+
+// Compile parses and prepares a JSON Schema document
+func Compile(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	if err := schema.compile(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (s *Schema) compile() error {
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	if s.Format != "" && formats[s.Format] == nil {
+		return fmt.Errorf("unsupported format %q", s.Format)
+	}
+	for _, prop := range s.Properties {
+		if err := prop.compile(); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile()
+	}
+	return nil
+}
+
+// Validate checks a decoded JSON document against the schema
+func (s *Schema) Validate(doc interface{}) []FieldError {
+	var errs []FieldError
+	s.validate("", doc, &errs)
+	return errs
+}
+
+func (s *Schema) validate(field string, value interface{}, errs *[]FieldError) {
+	fail := func(format string, args ...interface{}) {
+		name := field
+		if name == "" {
+			name = "(root)"
+		}
+		*errs = append(*errs, newFieldError(name, format, args...))
+	}
+
+	if s.Type != "" && !matchesType(s.Type, value) {
+		fail("must be of type %s", s.Type)
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		fail("must be one of %v", s.Enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, newFieldError(join(field, name), "is required"))
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prop, ok := s.Properties[key]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*errs = append(*errs, newFieldError(join(field, key), "is not allowed"))
+				}
+				continue
+			}
+			prop.validate(join(field, key), v[key], errs)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must contain at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("must contain at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", field, i), item, errs)
+			}
+		}
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match pattern %s", s.Pattern)
+		}
+		if s.Format != "" && !formats[s.Format](v) {
+			fail("must be a valid %s", s.Format)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("must be >= %v", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("must be <= %v", *s.Maximum)
+		}
+	}
+}
+
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// Service holds named schemas and validates request payloads against them
+type Service struct {
+	schemas map[string]*Schema
+	// messages translates error messages; nil keeps them in English
+	messages map[string]string
+}
+
+// NewService creates a new JSON Schema service
+func NewService() *Service {
+	return &Service{
+		schemas: make(map[string]*Schema),
+	}
+}
+
+// SetLocale sets the language of validation errors
+func (s *Service) SetLocale(locale string) error {
+	switch locale {
+	case LocaleEnglish:
+		s.messages = nil
+	case LocaleThai:
+		s.messages = thaiMessages
+	default:
+		return fmt.Errorf("unsupported locale %q, want %s or %s", locale, LocaleEnglish, LocaleThai)
+	}
+	return nil
+}
+
+// Register compiles and stores a schema under the given name
+func (s *Service) Register(name string, data []byte) error {
+	schema, err := Compile(data)
+	if err != nil {
+		return fmt.Errorf("schema %s: %w", name, err)
+	}
+	s.schemas[name] = schema
+	return nil
+}
+
+// RegisterFS registers every *.json file in fsys, named after the file without extension
+func (s *Service) RegisterFS(fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		if err := s.Register(strings.TrimSuffix(path.Base(file), ".json"), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Has reports whether a schema with the given name is registered
+func (s *Service) Has(name string) bool {
+	_, ok := s.schemas[name]
+	return ok
+}
+
+// Validate decodes a JSON payload and validates it against the named schema
+func (s *Service) Validate(name string, body []byte) error {
+	schema, ok := s.schemas[name]
+	if !ok {
+		return fmt.Errorf("schema %s not registered", name)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return &ValidationError{Errors: s.translate([]FieldError{newFieldError("(root)", "must be valid JSON")})}
+	}
+
+	if errs := schema.Validate(doc); len(errs) > 0 {
+		return &ValidationError{Errors: s.translate(errs)}
+	}
+	return nil
+}
+
+// translate rewrites the messages of errs in the service's locale
+func (s *Service) translate(errs []FieldError) []FieldError {
+	if s.messages == nil {
+		return errs
+	}
+	for i, fieldErr := range errs {
+		if format, ok := s.messages[fieldErr.format]; ok {
+			errs[i].Message = fmt.Sprintf(format, fieldErr.args...)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,197 @@
+package jsonschema
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+const userSchema = `{
+	"type": "object",
+	"required": ["email", "profile"],
+	"additionalProperties": false,
+	"properties": {
+		"email": { "type": "string", "format": "email" },
+		"age": { "type": "integer", "minimum": 18, "maximum": 130 },
+		"role": { "type": "string", "enum": ["user", "admin"] },
+		"born": { "type": "string", "format": "date" },
+		"profile": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": { "type": "string", "minLength": 2, "maxLength": 5 },
+				"phone": { "type": "string", "pattern": "^[0-9]+$" }
+			}
+		},
+		"tags": {
+			"type": "array",
+			"maxItems": 2,
+			"items": { "type": "string", "minLength": 1 }
+		}
+	}
+}`
+
+func TestCompile_InvalidSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"malformed json", `{"type":`},
+		{"invalid pattern", `{"type": "string", "pattern": "("}`},
+		{"unsupported format", `{"type": "string", "format": "uuid"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile([]byte(tt.schema)); err == nil {
+				t.Error("Compile() should return error")
+			}
+		})
+	}
+}
+
+func TestService_Validate(t *testing.T) {
+	service := NewService()
+	if err := service.Register("user", []byte(userSchema)); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantFields []string
+	}{
+		{
+			name: "valid document",
+			body: `{"email": "a@b.co", "age": 30, "role": "admin", "born": "1990-02-28", "profile": {"name": "Ann"}, "tags": ["x"]}`,
+		},
+		{
+			name:       "dates must exist in the calendar",
+			body:       `{"email": "a@b.co", "born": "2024-99-99", "profile": {"name": "Ann"}}`,
+			wantFields: []string{"born"},
+		},
+		{
+			name:       "missing required fields",
+			body:       `{}`,
+			wantFields: []string{"email", "profile"},
+		},
+		{
+			name:       "nested errors use dotted paths",
+			body:       `{"email": "a@b.co", "profile": {"name": "A", "phone": "12a"}}`,
+			wantFields: []string{"profile.name", "profile.phone"},
+		},
+		{
+			name:       "wrong types",
+			body:       `{"email": 1, "age": 20.5, "profile": "x"}`,
+			wantFields: []string{"age", "email", "profile"},
+		},
+		{
+			name:       "array items and limits",
+			body:       `{"email": "a@b.co", "profile": {"name": "Ann"}, "tags": ["", "b", "c"]}`,
+			wantFields: []string{"tags", "tags[0]"},
+		},
+		{
+			name:       "enum, range and additional properties",
+			body:       `{"email": "a@b.co", "age": 12, "role": "root", "extra": true, "profile": {"name": "Ann"}}`,
+			wantFields: []string{"age", "extra", "role"},
+		},
+		{
+			name:       "length counted in runes",
+			body:       `{"email": "a@b.co", "profile": {"name": "สมชาย"}}`,
+			wantFields: nil,
+		},
+		{
+			name:       "invalid json",
+			body:       `{"email":`,
+			wantFields: []string{"(root)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.Validate("user", []byte(tt.body))
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() error = %v, want *ValidationError", err)
+			}
+			if len(validationErr.Errors) != len(tt.wantFields) {
+				t.Fatalf("Validate() errors = %v, want fields %v", validationErr.Errors, tt.wantFields)
+			}
+			for i, field := range tt.wantFields {
+				if validationErr.Errors[i].Field != field {
+					t.Errorf("Errors[%d].Field = %v, want %v", i, validationErr.Errors[i].Field, field)
+				}
+			}
+		})
+	}
+}
+
+func TestService_Validate_UnknownSchema(t *testing.T) {
+	service := NewService()
+
+	err := service.Validate("missing", []byte(`{}`))
+	if err == nil {
+		t.Fatal("Validate() should return error for unregistered schema")
+	}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		t.Error("unregistered schema should not be reported as a validation error")
+	}
+}
+
+func TestService_SetLocale(t *testing.T) {
+	service := NewService()
+	if err := service.Register("user", []byte(userSchema)); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := service.SetLocale("fr"); err == nil {
+		t.Error("SetLocale() should reject an unsupported locale")
+	}
+	if err := service.SetLocale(LocaleThai); err != nil {
+		t.Fatalf("SetLocale() error = %v", err)
+	}
+
+	err := service.Validate("user", []byte(`{"email":"a@b.co","profile":{"name":"x"},"tags":["a","b","c"]}`))
+	want := "profile.name: ต้องมีอย่างน้อย 2 ตัวอักษร; tags: ต้องมีไม่เกิน 2 รายการ"
+	if err == nil || err.Error() != want {
+		t.Errorf("Validate() error = %v, want %q", err, want)
+	}
+	if err := service.Validate("user", []byte(`{`)); err == nil || err.Error() != "(root): ต้องเป็น JSON ที่ถูกต้อง" {
+		t.Errorf("Validate() error = %v, want the Thai message", err)
+	}
+
+	if err := service.SetLocale(LocaleEnglish); err != nil {
+		t.Fatalf("SetLocale() error = %v", err)
+	}
+	if err := service.Validate("user", []byte(`{"email":"a@b.co"}`)); err == nil || err.Error() != "profile: is required" {
+		t.Errorf("Validate() error = %v, want the English message", err)
+	}
+}
+
+func TestService_RegisterFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"login.json":  {Data: []byte(`{"type": "object", "required": ["email"]}`)},
+		"readme.txt":  {Data: []byte("ignored")},
+		"signup.json": {Data: []byte(`{"type": "object"}`)},
+	}
+
+	service := NewService()
+	if err := service.RegisterFS(fsys); err != nil {
+		t.Fatalf("RegisterFS() error = %v", err)
+	}
+
+	if !service.Has("login") || !service.Has("signup") {
+		t.Error("RegisterFS() should register every .json file by base name")
+	}
+	if service.Has("readme") {
+		t.Error("RegisterFS() should ignore non-JSON files")
+	}
+}
@@ -0,0 +1,348 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrUnknownAudience is returned for an audience that is not registered
+	ErrUnknownAudience = errors.New("unknown audience")
+	// ErrScopeNotAllowed is returned when a scope is requested that the
+	// audience does not allow
+	ErrScopeNotAllowed = errors.New("scope not allowed for audience")
+)
+
+// DefaultAudienceTTL is how long audience tokens last unless configured
+const DefaultAudienceTTL = 15 * time.Minute
+
+// minRSABits is the smallest RSA key accepted for signing
+const minRSABits = 2048
+
+// Audience is a downstream service that accepts tokens issued for it
+type Audience struct {
+	// Name identifies the audience in token requests and its key set URL
+	Name string
+	// ID is the "aud" claim the service checks, usually its URL
+	ID string
+	// Issuer is the "iss" claim, if set
+	Issuer string
+	// Scopes are the scopes tokens for the audience may carry
+	Scopes []string
+	// TTL is the token lifetime, DefaultAudienceTTL when zero
+	TTL time.Duration
+	// Keys sign the audience's tokens. The first one signs; the others are
+	// only published, so tokens signed before a key rotation still verify.
+	Keys []crypto.Signer
+}
+
+// AudienceClaims are the claims of audience-restricted tokens
+type AudienceClaims struct {
+	Claims
+	// Scope is the space-separated list of granted scopes
+	Scope string `json:"scope,omitempty"`
+}
+
+// JWK is a public key in JSON Web Key form
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// audience is a registered audience with its keys in signing form
+type audience struct {
+	Audience
+	keys []signingKey
+}
+
+// signingKey is an audience key with its algorithm and public JWK
+type signingKey struct {
+	signer crypto.Signer
+	method jwt.SigningMethod
+	jwk    JWK
+}
+
+// RegisterAudience lets the service issue tokens for a downstream service.
+// Register all audiences before the service starts issuing tokens.
+func (s *Service) RegisterAudience(a Audience) error {
+	if a.Name == "" || a.ID == "" {
+		return errors.New("audience needs a name and an ID")
+	}
+	if _, exists := s.audiences[a.Name]; exists {
+		return fmt.Errorf("audience %s is registered twice", a.Name)
+	}
+	if len(a.Keys) == 0 {
+		return fmt.Errorf("audience %s has no keys", a.Name)
+	}
+	for _, scope := range a.Scopes {
+		if scope == "" || strings.ContainsAny(scope, " \t\n") {
+			return fmt.Errorf("audience %s: invalid scope %q", a.Name, scope)
+		}
+	}
+	if a.TTL == 0 {
+		a.TTL = DefaultAudienceTTL
+	}
+
+	registered := &audience{Audience: a}
+	for i, key := range a.Keys {
+		signing, err := newSigningKey(key)
+		if err != nil {
+			return fmt.Errorf("audience %s key %d: %w", a.Name, i+1, err)
+		}
+		registered.keys = append(registered.keys, signing)
+	}
+
+	if s.audiences == nil {
+		s.audiences = make(map[string]*audience)
+	}
+	s.audiences[a.Name] = registered
+	return nil
+}
+
+// GenerateAudienceToken creates a token that only the named audience
+// accepts, carrying the requested scopes, or all of the audience's scopes
+// when none are requested. The token includes the same provider claims as
+// GenerateToken and is signed with the audience's key, so it is never
+// accepted by ValidateToken.
+func (s *Service) GenerateAudienceToken(userID int, email, name string, scopes []string) (string, time.Time, error) {
+	a, ok := s.audiences[name]
+	if !ok {
+		return "", time.Time{}, ErrUnknownAudience
+	}
+	if len(scopes) == 0 {
+		scopes = a.Scopes
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(a.Scopes, scope) {
+			return "", time.Time{}, fmt.Errorf("%w: %s", ErrScopeNotAllowed, scope)
+		}
+	}
+
+	extra, err := s.extraClaims(ClaimsRequest{UserID: userID, Email: email})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expirationTime := now.Add(a.TTL)
+	claims := &AudienceClaims{
+		Claims: Claims{
+			UserID: userID,
+			Email:  email,
+			Extra:  extra,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    a.Issuer,
+				Subject:   strconv.Itoa(userID),
+				Audience:  jwt.ClaimStrings{a.ID},
+				ExpiresAt: jwt.NewNumericDate(expirationTime),
+				IssuedAt:  jwt.NewNumericDate(now),
+			},
+		},
+		Scope: strings.Join(scopes, " "),
+	}
+
+	key := a.keys[0]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.jwk.Kid
+	tokenString, err := token.SignedString(key.signer)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenString, expirationTime, nil
+}
+
+// AudienceScopes returns the scopes the named audience allows
+func (s *Service) AudienceScopes(name string) ([]string, error) {
+	a, ok := s.audiences[name]
+	if !ok {
+		return nil, ErrUnknownAudience
+	}
+	return a.Scopes, nil
+}
+
+// AudienceKeys returns the public keys that verify the named audience's
+// tokens, the signing key first
+func (s *Service) AudienceKeys(name string) ([]JWK, error) {
+	a, ok := s.audiences[name]
+	if !ok {
+		return nil, ErrUnknownAudience
+	}
+	keys := make([]JWK, len(a.keys))
+	for i, key := range a.keys {
+		keys[i] = key.jwk
+	}
+	return keys, nil
+}
+
+// newSigningKey picks the algorithm for key and builds its JWK, identified
+// by its RFC 7638 thumbprint
+func newSigningKey(key crypto.Signer) (signingKey, error) {
+	var (
+		method jwt.SigningMethod
+		jwk    JWK
+		// thumbprint holds the required members in lexicographic order
+		thumbprint any
+	)
+	switch pub := key.Public().(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return signingKey{}, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+		}
+		method = jwt.SigningMethodRS256
+		jwk = JWK{Kty: "RSA", N: b64(pub.N.Bytes()), E: b64(big.NewInt(int64(pub.E)).Bytes())}
+		thumbprint = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return signingKey{}, errors.New("ECDSA keys must use P-256")
+		}
+		method = jwt.SigningMethodES256
+		jwk = JWK{Kty: "EC", Crv: "P-256", X: b64(pub.X.FillBytes(make([]byte, 32))), Y: b64(pub.Y.FillBytes(make([]byte, 32)))}
+		thumbprint = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+		jwk = JWK{Kty: "OKP", Crv: "Ed25519", X: b64(pub)}
+		thumbprint = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	default:
+		return signingKey{}, fmt.Errorf("unsupported key type %T", pub)
+	}
+
+	canonical, err := json.Marshal(thumbprint)
+	if err != nil {
+		return signingKey{}, err
+	}
+	sum := sha256.Sum256(canonical)
+	jwk.Kid = b64(sum[:])
+	jwk.Use = "sig"
+	jwk.Alg = method.Alg()
+	return signingKey{signer: key, method: method, jwk: jwk}, nil
+}
+
+// audiencesFile is the JWT_AUDIENCES file
+type audiencesFile struct {
+	Issuer    string `yaml:"issuer"`
+	Audiences []struct {
+		Name     string        `yaml:"name"`
+		Audience string        `yaml:"audience"`
+		Scopes   []string      `yaml:"scopes"`
+		TTL      time.Duration `yaml:"ttl"`
+		Keys     []string      `yaml:"keys"`
+	} `yaml:"audiences"`
+}
+
+// LoadAudiences reads the audiences in the YAML file at path:
+//
+//	issuer: https://auth.example.com
+//	audiences:
+//	  - name: orders
+//	    audience: https://orders.example.com
+//	    scopes: [orders:read, orders:write]
+//	    ttl: 15m
+//	    keys: [keys/orders.pem, keys/orders-old.pem]
+//
+// Keys are PEM private keys (RSA, P-256 or Ed25519), relative to the file's
+// directory.
+func LoadAudiences(path string) ([]Audience, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file audiencesFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	audiences := make([]Audience, 0, len(file.Audiences))
+	for _, entry := range file.Audiences {
+		a := Audience{Name: entry.Name, ID: entry.Audience, Issuer: file.Issuer, Scopes: entry.Scopes, TTL: entry.TTL}
+		for _, keyPath := range entry.Keys {
+			if !filepath.IsAbs(keyPath) {
+				keyPath = filepath.Join(filepath.Dir(path), keyPath)
+			}
+			key, err := LoadSigningKey(keyPath)
+			if err != nil {
+				return nil, fmt.Errorf("%s: audience %s: %w", path, entry.Name, err)
+			}
+			a.Keys = append(a.Keys, key)
+		}
+		audiences = append(audiences, a)
+	}
+	return audiences, nil
+}
+
+// LoadSigningKey reads a PEM private key in PKCS #8, PKCS #1 (RSA) or SEC 1
+// (EC) form
+func LoadSigningKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported key type %T", path, key)
+	}
+	return signer, nil
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
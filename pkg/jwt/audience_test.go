@@ -0,0 +1,207 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// publicOnly is a signer that only has a public key
+type publicOnly struct{ pub crypto.PublicKey }
+
+func (k publicOnly) Public() crypto.PublicKey { return k.pub }
+func (k publicOnly) Sign(io.Reader, []byte, crypto.SignerOpts) ([]byte, error) {
+	return nil, errors.New("public key only")
+}
+
+func TestNewSigningKey_Thumbprint(t *testing.T) {
+	// The example key of RFC 7638, section 3.1
+	n, _ := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	key, err := newSigningKey(publicOnly{&rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}})
+	if err != nil {
+		t.Fatalf("newSigningKey() error = %v", err)
+	}
+	if key.jwk.Kid != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" || key.jwk.Alg != "RS256" || key.jwk.E != "AQAB" {
+		t.Errorf("jwk = %+v", key.jwk)
+	}
+}
+
+func TestNewSigningKey_Rejected(t *testing.T) {
+	small, _ := rsa.GenerateKey(rand.Reader, 1024)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	for name, key := range map[string]crypto.Signer{"RSA 1024": small, "P-384": p384} {
+		if _, err := newSigningKey(key); err == nil {
+			t.Errorf("newSigningKey(%s) should fail", name)
+		}
+	}
+}
+
+func TestService_GenerateAudienceToken(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	service := NewService("test-secret")
+	service.RegisterClaimsProvider("role", ClaimsProviderFunc(func(ClaimsRequest) (map[string]interface{}, error) {
+		return map[string]interface{}{"role": "user"}, nil
+	}))
+	if err := service.RegisterAudience(Audience{Name: "orders", ID: "https://orders.example.com", Issuer: "https://auth.example.com",
+		Scopes: []string{"orders:read", "orders:write"}, Keys: []crypto.Signer{ecKey, oldKey}}); err != nil {
+		t.Fatalf("RegisterAudience() error = %v", err)
+	}
+	if err := service.RegisterAudience(Audience{Name: "billing", ID: "https://billing.example.com",
+		Scopes: []string{"billing:read"}, TTL: time.Minute, Keys: []crypto.Signer{edKey}}); err != nil {
+		t.Fatalf("RegisterAudience() error = %v", err)
+	}
+
+	tokenString, expiresAt, err := service.GenerateAudienceToken(7, "user@example.com", "orders", []string{"orders:read"})
+	if err != nil {
+		t.Fatalf("GenerateAudienceToken() error = %v", err)
+	}
+	if until := time.Until(expiresAt); until <= 0 || until > DefaultAudienceTTL {
+		t.Errorf("expiresAt in %v, want within %v", until, DefaultAudienceTTL)
+	}
+
+	// Verifies with the published key, for its audience only
+	keys, err := service.AudienceKeys("orders")
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("AudienceKeys() = %+v, %v; want the signing and the old key", keys, err)
+	}
+	claims := &AudienceClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Header["kid"] != keys[0].Kid {
+			return nil, errors.New("unexpected kid")
+		}
+		return &ecKey.PublicKey, nil
+	}, jwt.WithAudience("https://orders.example.com"), jwt.WithIssuer("https://auth.example.com"), jwt.WithValidMethods([]string{"ES256"}))
+	if err != nil || !token.Valid {
+		t.Fatalf("ParseWithClaims() error = %v", err)
+	}
+	if claims.UserID != 7 || claims.Subject != "7" || claims.Scope != "orders:read" || claims.Extra["role"] != "user" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if _, err := jwt.ParseWithClaims(tokenString, &AudienceClaims{}, func(*jwt.Token) (interface{}, error) {
+		return &ecKey.PublicKey, nil
+	}, jwt.WithAudience("https://billing.example.com")); err == nil {
+		t.Error("token should not verify for another audience")
+	}
+
+	// Never accepted as a login token
+	if _, err := service.ValidateToken(tokenString); err == nil {
+		t.Error("ValidateToken() should reject audience tokens")
+	}
+
+	// Omitted scopes grant all of the audience's
+	tokenString, expiresAt, err = service.GenerateAudienceToken(7, "user@example.com", "billing", nil)
+	if err != nil {
+		t.Fatalf("GenerateAudienceToken(billing) error = %v", err)
+	}
+	if time.Until(expiresAt) > time.Minute {
+		t.Errorf("expiresAt = %v, want the audience TTL", expiresAt)
+	}
+	claims = &AudienceClaims{}
+	if _, err := jwt.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+		return edKey.Public(), nil
+	}, jwt.WithValidMethods([]string{"EdDSA"})); err != nil || claims.Scope != "billing:read" {
+		t.Errorf("billing token: scope = %q, error = %v", claims.Scope, err)
+	}
+
+	if _, _, err := service.GenerateAudienceToken(7, "user@example.com", "orders", []string{"billing:read"}); !errors.Is(err, ErrScopeNotAllowed) {
+		t.Errorf("error = %v, want ErrScopeNotAllowed", err)
+	}
+	if _, _, err := service.GenerateAudienceToken(7, "user@example.com", "payroll", nil); !errors.Is(err, ErrUnknownAudience) {
+		t.Errorf("error = %v, want ErrUnknownAudience", err)
+	}
+	if scopes, err := service.AudienceScopes("billing"); err != nil || len(scopes) != 1 {
+		t.Errorf("AudienceScopes() = %v, %v", scopes, err)
+	}
+	if _, err := service.AudienceKeys("payroll"); !errors.Is(err, ErrUnknownAudience) {
+		t.Errorf("AudienceKeys() error = %v, want ErrUnknownAudience", err)
+	}
+}
+
+func TestService_RegisterAudience_Invalid(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	service := NewService("test-secret")
+	if err := service.RegisterAudience(Audience{Name: "orders", ID: "orders", Keys: []crypto.Signer{key}}); err != nil {
+		t.Fatalf("RegisterAudience() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		audience Audience
+	}{
+		{"no name", Audience{ID: "x", Keys: []crypto.Signer{key}}},
+		{"no ID", Audience{Name: "x", Keys: []crypto.Signer{key}}},
+		{"no keys", Audience{Name: "x", ID: "x"}},
+		{"duplicate", Audience{Name: "orders", ID: "x", Keys: []crypto.Signer{key}}},
+		{"scope with space", Audience{Name: "x", ID: "x", Scopes: []string{"read write"}, Keys: []crypto.Signer{key}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.RegisterAudience(tt.audience); err == nil {
+				t.Error("RegisterAudience() should fail")
+			}
+		})
+	}
+}
+
+func TestLoadAudiences(t *testing.T) {
+	dir := t.TempDir()
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	sec1, _ := x509.MarshalECPrivateKey(ecKey)
+	os.Mkdir(filepath.Join(dir, "keys"), 0o700)
+	os.WriteFile(filepath.Join(dir, "keys", "orders.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), 0o600)
+	os.WriteFile(filepath.Join(dir, "old.pem"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}), 0o600)
+
+	path := filepath.Join(dir, "audiences.yaml")
+	os.WriteFile(path, []byte(`issuer: https://auth.example.com
+audiences:
+  - name: orders
+    audience: https://orders.example.com
+    scopes: [orders:read, orders:write]
+    ttl: 5m
+    keys: [keys/orders.pem, `+filepath.Join(dir, "old.pem")+`]
+`), 0o600)
+
+	audiences, err := LoadAudiences(path)
+	if err != nil {
+		t.Fatalf("LoadAudiences() error = %v", err)
+	}
+	if len(audiences) != 1 {
+		t.Fatalf("audiences = %+v", audiences)
+	}
+	a := audiences[0]
+	if a.Name != "orders" || a.ID != "https://orders.example.com" || a.Issuer != "https://auth.example.com" ||
+		len(a.Scopes) != 2 || a.TTL != 5*time.Minute || len(a.Keys) != 2 {
+		t.Errorf("audience = %+v", a)
+	}
+	if !ecKey.Equal(a.Keys[0]) || !ecKey.Equal(a.Keys[1]) {
+		t.Error("keys were not loaded")
+	}
+
+	os.WriteFile(path, []byte("audiences:\n  - name: orders\n    secret: x\n"), 0o600)
+	if _, err := LoadAudiences(path); err == nil {
+		t.Error("LoadAudiences() should reject unknown fields")
+	}
+	os.WriteFile(path, []byte("audiences:\n  - name: orders\n    keys: [missing.pem]\n"), 0o600)
+	if _, err := LoadAudiences(path); err == nil {
+		t.Error("LoadAudiences() should fail for a missing key")
+	}
+}
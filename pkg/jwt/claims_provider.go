@@ -0,0 +1,68 @@
+package jwt
+
+import (
+	"fmt"
+)
+
+// ClaimsRequest describes the token being issued to a claims provider
+type ClaimsRequest struct {
+	UserID int
+	Email  string
+}
+
+// ClaimsProvider adds application-specific claims (tenant, roles, plan, ...)
+// to tokens at issuance. Returned claims are stored under the "ext" claim so
+// they can never override the registered or built-in claims.
+type ClaimsProvider interface {
+	Claims(req ClaimsRequest) (map[string]interface{}, error)
+}
+
+// ClaimsProviderFunc adapts a function to the ClaimsProvider interface
+type ClaimsProviderFunc func(req ClaimsRequest) (map[string]interface{}, error)
+
+// Claims calls f(req)
+func (f ClaimsProviderFunc) Claims(req ClaimsRequest) (map[string]interface{}, error) {
+	return f(req)
+}
+
+// namedProvider is a registered claims provider
+type namedProvider struct {
+	name     string
+	provider ClaimsProvider
+}
+
+// RegisterClaimsProvider adds a provider invoked every time a token is
+// issued. Providers run in registration order. Register all providers
+// before the service starts issuing tokens.
+func (s *Service) RegisterClaimsProvider(name string, provider ClaimsProvider) {
+	s.providers = append(s.providers, namedProvider{name: name, provider: provider})
+}
+
+// extraClaims collects the claims of all registered providers. A provider
+// error fails token issuance, and two providers may not set the same claim.
+func (s *Service) extraClaims(req ClaimsRequest) (map[string]interface{}, error) {
+	if len(s.providers) == 0 {
+		return nil, nil
+	}
+
+	extra := make(map[string]interface{})
+	owners := make(map[string]string)
+	for _, p := range s.providers {
+		claims, err := p.provider.Claims(req)
+		if err != nil {
+			return nil, fmt.Errorf("claims provider %s: %w", p.name, err)
+		}
+		for key, value := range claims {
+			if owner, taken := owners[key]; taken {
+				return nil, fmt.Errorf("claims provider %s: claim %q already set by %s", p.name, key, owner)
+			}
+			owners[key] = p.name
+			extra[key] = value
+		}
+	}
+
+	if len(extra) == 0 {
+		return nil, nil
+	}
+	return extra, nil
+}
@@ -0,0 +1,83 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestService_GenerateToken_ClaimsProviders(t *testing.T) {
+	service := NewService("test-secret")
+
+	var got ClaimsRequest
+	service.RegisterClaimsProvider("tenant", ClaimsProviderFunc(func(req ClaimsRequest) (map[string]interface{}, error) {
+		got = req
+		return map[string]interface{}{"tenant": "acme"}, nil
+	}))
+	service.RegisterClaimsProvider("roles", ClaimsProviderFunc(func(req ClaimsRequest) (map[string]interface{}, error) {
+		return map[string]interface{}{"roles": []string{"admin", "billing"}, "user_id": 0}, nil
+	}))
+
+	token, _, err := service.GenerateToken(7, "claims@example.com")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if got.UserID != 7 || got.Email != "claims@example.com" {
+		t.Errorf("provider received %+v", got)
+	}
+
+	claims, err := service.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims.Extra["tenant"] != "acme" {
+		t.Errorf("Extra[tenant] = %v, want acme", claims.Extra["tenant"])
+	}
+	if roles, ok := claims.Extra["roles"].([]interface{}); !ok || len(roles) != 2 {
+		t.Errorf("Extra[roles] = %v, want two roles", claims.Extra["roles"])
+	}
+
+	// Provider claims live under "ext" and cannot override built-in claims
+	if claims.UserID != 7 {
+		t.Errorf("UserID = %v, want 7", claims.UserID)
+	}
+}
+
+func TestService_GenerateToken_NoProviders(t *testing.T) {
+	service := NewService("test-secret")
+
+	token, _, err := service.GenerateToken(1, "plain@example.com")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	claims, err := service.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims.Extra != nil {
+		t.Errorf("Extra = %v, want nil", claims.Extra)
+	}
+}
+
+func TestService_GenerateToken_ProviderError(t *testing.T) {
+	service := NewService("test-secret")
+	service.RegisterClaimsProvider("plan", ClaimsProviderFunc(func(req ClaimsRequest) (map[string]interface{}, error) {
+		return nil, errors.New("plan lookup failed")
+	}))
+
+	if _, _, err := service.GenerateToken(1, "fail@example.com"); err == nil {
+		t.Error("GenerateToken() should fail when a provider fails")
+	}
+}
+
+func TestService_GenerateToken_ConflictingProviders(t *testing.T) {
+	service := NewService("test-secret")
+	provider := ClaimsProviderFunc(func(req ClaimsRequest) (map[string]interface{}, error) {
+		return map[string]interface{}{"tenant": "acme"}, nil
+	})
+	service.RegisterClaimsProvider("first", provider)
+	service.RegisterClaimsProvider("second", provider)
+
+	if _, _, err := service.GenerateToken(1, "conflict@example.com"); err == nil {
+		t.Error("GenerateToken() should fail when providers set the same claim")
+	}
+}
@@ -10,12 +10,16 @@ import (
 type Claims struct {
 	UserID int    `json:"user_id"`
 	Email  string `json:"email"`
+	// Extra holds the claims added by registered claims providers
+	Extra map[string]interface{} `json:"ext,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // Service provides JWT operations
 type Service struct {
 	secretKey []byte
+	providers []namedProvider
+	audiences map[string]*audience
 }
 
 // NewService creates a new JWT service
@@ -25,13 +29,20 @@ func NewService(secretKey string) *Service {
 	}
 }
 
-// GenerateToken creates a new JWT token for the user
+// GenerateToken creates a new JWT token for the user, including the claims
+// of every registered claims provider
 func (s *Service) GenerateToken(userID int, email string) (string, time.Time, error) {
+	extra, err := s.extraClaims(ClaimsRequest{UserID: userID, Email: email})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
 	expirationTime := time.Now().Add(24 * time.Hour) // Token expires in 24 hours
 
 	claims := &Claims{
 		UserID: userID,
 		Email:  email,
+		Extra:  extra,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -223,3 +223,42 @@ func TestService_GenerateAndValidateRoundtrip(t *testing.T) {
 		})
 	}
 }
+
+// FuzzService_ValidateToken checks that malformed tokens are rejected without
+// panicking and that only tokens signed with the service's key are accepted.
+// Run with go test ./pkg/jwt -fuzz FuzzService_ValidateToken
+func FuzzService_ValidateToken(f *testing.F) {
+	service := NewService("fuzz-secret")
+	valid, _, err := service.GenerateToken(7, "fuzz@example.com")
+	if err != nil {
+		f.Fatal(err)
+	}
+	forged, _, _ := NewService("other-secret").GenerateToken(7, "fuzz@example.com")
+	for _, seed := range []string{
+		valid,
+		forged,
+		valid[:len(valid)-2],
+		"",
+		".",
+		"a.b.c",
+		"eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJ1c2VyX2lkIjo3fQ.",
+		"eyJhbGciOiJSUzI1NiJ9.eyJ1c2VyX2lkIjo3fQ.c2ln",
+		"eyJhbGciOiJIUzI1NiJ9.bnVsbA.",
+	} {
+		f.Add(seed)
+	}
+
+	other := NewService("other-secret")
+	f.Fuzz(func(t *testing.T, token string) {
+		claims, err := service.ValidateToken(token)
+		if err != nil {
+			return
+		}
+		if claims == nil {
+			t.Fatalf("ValidateToken(%q) returned nil claims without an error", token)
+		}
+		if _, err := other.ValidateToken(token); err == nil {
+			t.Fatalf("ValidateToken(%q) accepted by services with different keys", token)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+// Package lanes gives each class of traffic its own concurrency limit, so
+// bulk exports or a sign-up storm fill their own lane and cannot starve
+// requests from signed-in users, and free users cannot starve paying ones.
+package lanes
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrInvalidLane is returned for an unknown class name or a negative limit
+var ErrInvalidLane = errors.New("invalid lane")
+
+// Class is the lane a request is served in. Classes are listed from the
+// most to the least important.
+type Class int
+
+const (
+	// Health is for health checks and load signals
+	Health Class = iota
+	// Paid is for requests with a valid bearer token of a paid plan
+	Paid
+	// Authenticated is for requests with any other valid bearer token
+	Authenticated
+	// Anonymous is for requests without one, e.g. sign-ups and sign-ins
+	Anonymous
+	// Export is for bulk exports, which hold a connection while they stream
+	Export
+)
+
+var classNames = []string{"health", "paid", "authenticated", "anonymous", "export"}
+
+// ParseClass parses "health", "paid", "authenticated", "anonymous" or "export"
+func ParseClass(s string) (Class, error) {
+	for i, name := range classNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return Class(i), nil
+		}
+	}
+	return 0, fmt.Errorf("%w %q: must be one of %s", ErrInvalidLane, s, strings.Join(classNames, ", "))
+}
+
+func (c Class) String() string {
+	if c < 0 || int(c) >= len(classNames) {
+		return fmt.Sprintf("Class(%d)", int(c))
+	}
+	return classNames[c]
+}
+
+// HealthPaths are served in the Health lane. /livez and /readyz are
+// registered before any middleware, so they are never limited.
+var HealthPaths = []string{"/", "/autoscaling"}
+
+// ExportPaths are served in the Export lane
+var ExportPaths = []string{"/admin/users/export", "/admin/events/export"}
+
+// Classify returns the lane of a request for path, given whether it carries
+// a valid bearer token and whether that token's plan is paid. Exports are
+// classified first, so an admin's export does not take a slot from other
+// signed-in users.
+func Classify(path string, authenticated, paid bool) Class {
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		path = "/"
+	}
+	for _, export := range ExportPaths {
+		if path == export {
+			return Export
+		}
+	}
+	for _, health := range HealthPaths {
+		if path == health {
+			return Health
+		}
+	}
+	if authenticated && paid {
+		return Paid
+	}
+	if authenticated {
+		return Authenticated
+	}
+	return Anonymous
+}
+
+// ParseLimits parses limits keyed by class name, e.g. "anonymous" => "50"
+func ParseLimits(pairs map[string]string) (map[Class]int, error) {
+	limits := make(map[Class]int, len(pairs))
+	for name, value := range pairs {
+		class, err := ParseClass(name)
+		if err != nil {
+			return nil, err
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("%w: %s limit %q must be a number of requests", ErrInvalidLane, class, value)
+		}
+		limits[class] = limit
+	}
+	return limits, nil
+}
+
+// lane counts the requests being served in one class
+type lane struct {
+	limit  int64
+	active atomic.Int64
+}
+
+// Lanes admits requests up to each class's limit
+type Lanes struct {
+	lanes []*lane
+}
+
+// New creates lanes with the given limits. Classes without a limit, or with
+// a limit of zero, are not limited.
+func New(limits map[Class]int) *Lanes {
+	l := &Lanes{lanes: make([]*lane, len(classNames))}
+	for i := range l.lanes {
+		l.lanes[i] = &lane{limit: int64(limits[Class(i)])}
+	}
+	return l
+}
+
+// Acquire takes a slot in the class's lane, reporting false when it is full
+func (l *Lanes) Acquire(class Class) (*Slot, bool) {
+	lane := l.lanes[class]
+	if active := lane.active.Add(1); lane.limit > 0 && active > lane.limit {
+		lane.active.Add(-1)
+		return nil, false
+	}
+	return &Slot{lane: lane}, true
+}
+
+// Active returns the number of requests being served in the class's lane
+func (l *Lanes) Active(class Class) int64 {
+	return l.lanes[class].active.Load()
+}
+
+// Slot is a request's place in its lane
+type Slot struct {
+	lane *lane
+	held atomic.Bool
+	once sync.Once
+}
+
+// Hold keeps the slot taken past Done, for a response that is streamed
+// after its handler returns, and returns the function that gives it back.
+// It is safe to call on a nil slot.
+func (s *Slot) Hold() func() {
+	if s == nil {
+		return func() {}
+	}
+	s.held.Store(true)
+	return s.release
+}
+
+// Done gives the slot back unless it is held
+func (s *Slot) Done() {
+	if !s.held.Load() {
+		s.release()
+	}
+}
+
+func (s *Slot) release() {
+	s.once.Do(func() { s.lane.active.Add(-1) })
+}
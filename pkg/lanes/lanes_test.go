@@ -0,0 +1,97 @@
+package lanes
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		path          string
+		authenticated bool
+		paid          bool
+		want          Class
+	}{
+		{"/", false, false, Health},
+		{"/autoscaling", true, true, Health},
+		{"/admin/users/export", true, false, Export},
+		{"/admin/events/export/", true, true, Export},
+		{"/admin/users", true, false, Authenticated},
+		{"/me", true, false, Authenticated},
+		{"/me", true, true, Paid},
+		{"/register", false, false, Anonymous},
+		{"/me", false, true, Anonymous},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.path, tt.authenticated, tt.paid); got != tt.want {
+			t.Errorf("Classify(%s, %v, %v) = %s, want %s", tt.path, tt.authenticated, tt.paid, got, tt.want)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(map[string]string{"Anonymous": "50", "export": " 2"})
+	if err != nil {
+		t.Fatalf("ParseLimits() error = %v", err)
+	}
+	if len(limits) != 2 || limits[Anonymous] != 50 || limits[Export] != 2 {
+		t.Errorf("ParseLimits() = %v, want anonymous=50 and export=2", limits)
+	}
+
+	for _, pairs := range []map[string]string{
+		{"bulk": "2"},
+		{"export": "two"},
+		{"export": "-1"},
+	} {
+		if _, err := ParseLimits(pairs); !errors.Is(err, ErrInvalidLane) {
+			t.Errorf("ParseLimits(%v) error = %v, want ErrInvalidLane", pairs, err)
+		}
+	}
+}
+
+func TestLanes_Acquire(t *testing.T) {
+	lanes := New(map[Class]int{Export: 1})
+
+	first, ok := lanes.Acquire(Export)
+	if !ok {
+		t.Fatal("Acquire() rejected the first export")
+	}
+	if _, ok := lanes.Acquire(Export); ok {
+		t.Fatal("Acquire() admitted a second export over the limit of 1")
+	}
+	if lanes.Active(Export) != 1 {
+		t.Errorf("Active() = %d after a rejection, want 1", lanes.Active(Export))
+	}
+	// A full lane does not affect the others, which are unlimited here
+	for i := 0; i < 10; i++ {
+		if _, ok := lanes.Acquire(Authenticated); !ok {
+			t.Fatal("Acquire() rejected an authenticated request")
+		}
+	}
+
+	first.Done()
+	first.Done()
+	if lanes.Active(Export) != 0 {
+		t.Errorf("Active() = %d after Done() twice, want 0", lanes.Active(Export))
+	}
+}
+
+func TestSlot_Hold(t *testing.T) {
+	lanes := New(map[Class]int{Export: 1})
+	slot, _ := lanes.Acquire(Export)
+
+	// A held slot stays taken after Done until it is released
+	release := slot.Hold()
+	slot.Done()
+	if _, ok := lanes.Acquire(Export); ok {
+		t.Fatal("Acquire() admitted an export while the slot was held")
+	}
+	release()
+	release()
+	if lanes.Active(Export) != 0 {
+		t.Errorf("Active() = %d after release, want 0", lanes.Active(Export))
+	}
+
+	var none *Slot
+	none.Hold()()
+}
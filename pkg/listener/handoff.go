@@ -0,0 +1,116 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Handoff protocol: the child started by Handoff receives the listening
+// socket as fd 3 and the write end of a readiness pipe as fd 4, and is
+// marked by handoffEnv
+const (
+	handoffEnv        = "LISTENER_HANDOFF"
+	handoffListenerFD = 3
+	handoffReadyFD    = 4
+)
+
+// ErrHandoffFailed is returned when the new process exits or does not become
+// ready in time; the caller keeps serving
+var ErrHandoffFailed = errors.New("new process did not become ready")
+
+// inherited is set when Listen took over the socket of a parent process
+var inherited bool
+
+// listenInherited returns the socket passed by the parent process
+func listenInherited() (net.Listener, error) {
+	os.Unsetenv(handoffEnv)
+
+	file := os.NewFile(handoffListenerFD, "handoff-listener")
+	defer file.Close()
+	ln, err := net.FileListener(file)
+	if err != nil {
+		return nil, err
+	}
+	inherited = true
+	return ln, nil
+}
+
+// Handoff starts a new copy of the running binary with the same arguments,
+// sharing ln, and waits up to timeout for it to call Ready. The socket stays
+// open throughout, so connections queue in the kernel and none are refused;
+// on success the caller should stop accepting and drain its connections.
+func Handoff(ln net.Listener, timeout time.Duration) error {
+	filer, ok := ln.(interface{ File() (*os.File, error) })
+	if !ok {
+		return fmt.Errorf("cannot hand off a %T", ln)
+	}
+	// The new process keeps using the socket path after this one closes
+	if unixLn, ok := ln.(*net.UnixListener); ok {
+		unixLn.SetUnlinkOnClose(false)
+	}
+
+	file, err := filer.File()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ready, notify, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer ready.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		notify.Close()
+		return err
+	}
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), handoffEnv+"=1")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{file, notify}
+	err = cmd.Start()
+	notify.Close()
+	if err != nil {
+		return err
+	}
+
+	// Read returns when the child writes or, having exited, closes the pipe
+	done := make(chan error, 1)
+	go func() {
+		_, err := ready.Read(make([]byte, 1))
+		done <- err
+	}()
+
+	select {
+	case err = <-done:
+	case <-time.After(timeout):
+		err = errors.New("timed out")
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("%w: %v", ErrHandoffFailed, err)
+	}
+	return cmd.Process.Release()
+}
+
+// Ready tells the parent that started this process with Handoff that it is
+// serving. It does nothing in a process that was not started by Handoff.
+func Ready() error {
+	if !inherited {
+		return nil
+	}
+	inherited = false
+
+	file := os.NewFile(handoffReadyFD, "handoff-ready")
+	defer file.Close()
+	_, err := file.Write([]byte{1})
+	return err
+}
@@ -0,0 +1,85 @@
+package listener
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestMain runs the test binary as the handoff child when started by Handoff
+func TestMain(m *testing.M) {
+	if os.Getenv(handoffEnv) != "" {
+		os.Exit(runHandoffChild())
+	}
+	os.Exit(m.Run())
+}
+
+// runHandoffChild takes over the parent's socket and answers one connection
+func runHandoffChild() int {
+	ln, err := Listen("ignored:0")
+	if err != nil || os.Getenv("HANDOFF_TEST_FAIL") != "" {
+		return 1
+	}
+	if err := Ready(); err != nil {
+		return 1
+	}
+
+	conn, err := ln.Accept()
+	if err != nil {
+		return 1
+	}
+	conn.Write([]byte("child"))
+	conn.Close()
+	return 0
+}
+
+func TestHandoff(t *testing.T) {
+	ln, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	addr := ln.Addr().String()
+
+	if err := Handoff(ln, 10*time.Second); err != nil {
+		t.Fatalf("Handoff() error = %v", err)
+	}
+	ln.Close()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() after handoff error = %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	got, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(got) != "child" {
+		t.Errorf("response = %q, want the child to serve the socket", got)
+	}
+}
+
+func TestHandoff_ChildFails(t *testing.T) {
+	t.Setenv("HANDOFF_TEST_FAIL", "1")
+
+	ln, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+
+	if err := Handoff(ln, 10*time.Second); !errors.Is(err, ErrHandoffFailed) {
+		t.Errorf("Handoff() error = %v, want ErrHandoffFailed", err)
+	}
+}
+
+func TestReady_NotInherited(t *testing.T) {
+	if err := Ready(); err != nil {
+		t.Errorf("Ready() error = %v, want no-op outside a handoff", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Package listener opens the server's listening socket: TCP, a Unix domain
+// socket or a socket inherited through systemd socket activation.
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Address forms accepted by Listen
+const (
+	UnixPrefix = "unix:"
+	Systemd    = "systemd"
+)
+
+// SocketMode is the permission of created Unix sockets, letting a reverse
+// proxy in the socket's group connect
+const SocketMode os.FileMode = 0o660
+
+// systemdFirstFD is the first file descriptor passed by systemd
+const systemdFirstFD = 3
+
+// ErrNoSystemdSocket is returned when "systemd" is requested but no socket
+// was passed to this process
+var ErrNoSystemdSocket = errors.New("no socket passed by systemd")
+
+// Listen opens the listener described by addr:
+//
+//	":3000", "127.0.0.1:3000"  TCP
+//	"unix:/run/api/api.sock"   Unix domain socket
+//	"systemd"                  first socket from systemd socket activation
+//
+// A process started by Handoff ignores addr and takes over its parent's socket.
+func Listen(addr string) (net.Listener, error) {
+	switch {
+	case os.Getenv(handoffEnv) != "":
+		return listenInherited()
+	case addr == Systemd:
+		return listenSystemd()
+	case strings.HasPrefix(addr, UnixPrefix):
+		return listenUnix(strings.TrimPrefix(addr, UnixPrefix))
+	default:
+		return net.Listen("tcp", addr)
+	}
+}
+
+// listenUnix listens on a Unix socket at path, replacing a stale socket left
+// by a previous run. Other files at path are never removed.
+func listenUnix(path string) (net.Listener, error) {
+	if path == "" {
+		return nil, errors.New("unix socket path is empty")
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, SocketMode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// listenSystemd returns the first socket passed via the LISTEN_PID and
+// LISTEN_FDS protocol. The variables are cleared so child processes do not
+// inherit them.
+func listenSystemd() (net.Listener, error) {
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+	defer os.Unsetenv("LISTEN_FDNAMES")
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, ErrNoSystemdSocket
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, ErrNoSystemdSocket
+	}
+
+	file := os.NewFile(systemdFirstFD, "systemd-socket")
+	defer file.Close()
+	return net.FileListener(file)
+}
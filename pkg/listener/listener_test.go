@@ -0,0 +1,97 @@
+package listener
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestListen_TCP(t *testing.T) {
+	ln, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+
+	if ln.Addr().Network() != "tcp" {
+		t.Errorf("network = %s, want tcp", ln.Addr().Network())
+	}
+}
+
+func TestListen_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	ln, err := Listen(UnixPrefix + path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != SocketMode {
+		t.Errorf("socket mode = %v, want %v", info.Mode().Perm(), SocketMode)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	conn.Close()
+	ln.Close()
+}
+
+func TestListen_UnixReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	// A socket file left behind by a process that did not clean up
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatalf("ListenUnix() error = %v", err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err := Listen(UnixPrefix + path)
+	if err != nil {
+		t.Fatalf("Listen() over a stale socket error = %v", err)
+	}
+	ln.Close()
+}
+
+func TestListen_UnixKeepsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := Listen(UnixPrefix + path); err == nil {
+		t.Error("Listen() should refuse to replace a regular file")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("regular file was removed: %v", err)
+	}
+
+	if _, err := Listen(UnixPrefix); err == nil {
+		t.Error("Listen() should reject an empty socket path")
+	}
+}
+
+func TestListen_SystemdWithoutSocket(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+	t.Setenv("LISTEN_FDS", "")
+	if _, err := Listen(Systemd); !errors.Is(err, ErrNoSystemdSocket) {
+		t.Errorf("Listen() error = %v, want ErrNoSystemdSocket", err)
+	}
+
+	// Sockets meant for another process are ignored
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+	if _, err := Listen(Systemd); !errors.Is(err, ErrNoSystemdSocket) {
+		t.Errorf("Listen() error = %v, want ErrNoSystemdSocket", err)
+	}
+}
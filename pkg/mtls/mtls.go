@@ -0,0 +1,85 @@
+// Package mtls configures mutual TLS and maps client certificate identities
+// (SPIFFE IDs and DNS names) to service accounts.
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+
+	"fiber-hello-world/pkg/tlsserver"
+)
+
+// ServerConfig builds a TLS configuration for the given certificate and key
+// on top of tlsserver.DefaultConfig, with the client CA applied by AddClientCA
+func ServerConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := tlsserver.DefaultConfig()
+	config.Certificates = []tls.Certificate{cert}
+
+	if err := AddClientCA(config, clientCAFile); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// AddClientCA makes config verify client certificates signed by the CA in
+// clientCAFile if presented; clients without one can still connect and use
+// other authentication such as JWT. An empty clientCAFile is a no-op.
+func AddClientCA(config *tls.Config, clientCAFile string) error {
+	if clientCAFile == "" {
+		return nil
+	}
+
+	pem, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return errors.New("client CA file contains no certificates")
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	return nil
+}
+
+// Identities lists the identities a certificate asserts: URI SANs as-is
+// (e.g. "spiffe://cluster.local/ns/billing/sa/worker") and DNS SANs
+// prefixed with "dns:" (e.g. "dns:worker.internal")
+func Identities(cert *x509.Certificate) []string {
+	var identities []string
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	for _, name := range cert.DNSNames {
+		identities = append(identities, "dns:"+name)
+	}
+	return identities
+}
+
+// Mapper maps certificate identities to service accounts
+type Mapper struct {
+	accounts map[string]string
+}
+
+// NewMapper creates a mapper from identity to service account
+func NewMapper(accounts map[string]string) *Mapper {
+	return &Mapper{accounts: accounts}
+}
+
+// Resolve returns the service account of the first mapped identity in the
+// certificate. URI SANs are checked before DNS SANs.
+func (m *Mapper) Resolve(cert *x509.Certificate) (account, identity string, ok bool) {
+	for _, identity := range Identities(cert) {
+		if account, ok := m.accounts[identity]; ok {
+			return account, identity, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,135 @@
+package mtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newCertificate creates a self-signed certificate with the given SANs
+func newCertificate(t *testing.T, uris []string, dnsNames []string) (*x509.Certificate, []byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		DNSNames:              dnsNames,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	for _, raw := range uris {
+		uri, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("url.Parse() error = %v", err)
+		}
+		template.URIs = append(template.URIs, uri)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return cert, certPEM, keyPEM
+}
+
+func TestIdentities(t *testing.T) {
+	cert, _, _ := newCertificate(t, []string{"spiffe://cluster.local/ns/billing/sa/worker"}, []string{"worker.internal"})
+
+	got := Identities(cert)
+	want := []string{"spiffe://cluster.local/ns/billing/sa/worker", "dns:worker.internal"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Identities() = %v, want %v", got, want)
+	}
+}
+
+func TestMapper_Resolve(t *testing.T) {
+	mapper := NewMapper(map[string]string{
+		"spiffe://cluster.local/ns/billing/sa/worker": "billing@service.internal",
+		"dns:reports.internal":                        "reports@service.internal",
+	})
+
+	tests := []struct {
+		name         string
+		uris         []string
+		dnsNames     []string
+		wantAccount  string
+		wantIdentity string
+		wantOK       bool
+	}{
+		{"spiffe", []string{"spiffe://cluster.local/ns/billing/sa/worker"}, nil, "billing@service.internal", "spiffe://cluster.local/ns/billing/sa/worker", true},
+		{"dns", nil, []string{"reports.internal"}, "reports@service.internal", "dns:reports.internal", true},
+		{"uri before dns", []string{"spiffe://cluster.local/ns/billing/sa/worker"}, []string{"reports.internal"}, "billing@service.internal", "spiffe://cluster.local/ns/billing/sa/worker", true},
+		{"unmapped", []string{"spiffe://cluster.local/ns/other/sa/x"}, []string{"other.internal"}, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, _, _ := newCertificate(t, tt.uris, tt.dnsNames)
+			account, identity, ok := mapper.Resolve(cert)
+			if account != tt.wantAccount || identity != tt.wantIdentity || ok != tt.wantOK {
+				t.Errorf("Resolve() = %q, %q, %v; want %q, %q, %v", account, identity, ok, tt.wantAccount, tt.wantIdentity, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestServerConfig(t *testing.T) {
+	dir := t.TempDir()
+	_, certPEM, keyPEM := newCertificate(t, nil, []string{"localhost"})
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, certPEM, 0o600)
+	os.WriteFile(keyFile, keyPEM, 0o600)
+
+	config, err := ServerConfig(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("ServerConfig() error = %v", err)
+	}
+	if config.ClientAuth != tls.NoClientCert {
+		t.Errorf("ClientAuth = %v, want NoClientCert without a client CA", config.ClientAuth)
+	}
+
+	config, err = ServerConfig(certFile, keyFile, certFile)
+	if err != nil {
+		t.Fatalf("ServerConfig() with client CA error = %v", err)
+	}
+	if config.ClientAuth != tls.VerifyClientCertIfGiven || config.ClientCAs == nil {
+		t.Errorf("ClientAuth = %v, want VerifyClientCertIfGiven with a client CA", config.ClientAuth)
+	}
+
+	if _, err := ServerConfig(certFile, keyFile, keyFile); err == nil {
+		t.Error("ServerConfig() should fail when the client CA file has no certificates")
+	}
+	if _, err := ServerConfig(filepath.Join(dir, "missing.pem"), keyFile, ""); err == nil {
+		t.Error("ServerConfig() should fail for a missing certificate")
+	}
+}
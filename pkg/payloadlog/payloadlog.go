@@ -0,0 +1,180 @@
+// Package payloadlog logs the request and response bodies of single routes,
+// switched on at runtime for a limited time while an incident is
+// investigated. Bodies are redacted by a redact.Policy before they are
+// logged.
+package payloadlog
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"fiber-hello-world/pkg/redact"
+)
+
+// ErrInvalidRoute is returned by Enable for a route that cannot be logged
+var ErrInvalidRoute = errors.New("invalid payload logging route")
+
+// MaxDuration bounds how long a route is logged, so a forgotten switch
+// turns itself off
+const MaxDuration = 24 * time.Hour
+
+// MaxBodyBytes bounds each logged body
+const MaxBodyBytes = 4 << 10
+
+// Route is a route whose payloads are logged until Until
+type Route struct {
+	Method string
+	// Path is the route; segments starting with ":" match any segment
+	Path  string
+	Until time.Time
+}
+
+// Name names the route, e.g. "POST /login"
+func (r Route) Name() string {
+	return r.Method + " " + r.Path
+}
+
+// Matches reports whether a request is for the route
+func (r Route) Matches(method, path string) bool {
+	if !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	want := strings.Split(strings.Trim(r.Path, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i, segment := range want {
+		if segment != got[i] && !(strings.HasPrefix(segment, ":") && got[i] != "") {
+			return false
+		}
+	}
+	return true
+}
+
+// Entry is a request and its response as they are logged
+type Entry struct {
+	Method       string
+	Path         string
+	Status       int
+	Duration     time.Duration
+	RequestType  string
+	RequestBody  string
+	ResponseType string
+	ResponseBody string
+}
+
+// Logger holds the routes being logged. It logs nothing until one is
+// enabled.
+type Logger struct {
+	policy *redact.Policy
+	now    func() time.Time
+	printf func(format string, args ...any)
+
+	mu     sync.RWMutex
+	routes []Route
+}
+
+// New creates a logger redacting bodies with policy
+func New(policy *redact.Policy) *Logger {
+	return &Logger{policy: policy, now: time.Now, printf: log.Printf}
+}
+
+// Enable logs a route's payloads for duration, replacing the end of an
+// earlier switch of the same route
+func (l *Logger) Enable(method, path string, duration time.Duration) (Route, error) {
+	route := Route{Method: strings.ToUpper(strings.TrimSpace(method)), Path: strings.TrimSpace(path)}
+	switch {
+	case route.Method == "":
+		return Route{}, fmt.Errorf("%w: method is required", ErrInvalidRoute)
+	case !strings.HasPrefix(route.Path, "/"):
+		return Route{}, fmt.Errorf("%w: path must start with /", ErrInvalidRoute)
+	case duration <= 0 || duration > MaxDuration:
+		return Route{}, fmt.Errorf("%w: duration must be above 0 and at most %s", ErrInvalidRoute, MaxDuration)
+	}
+	route.Until = l.now().Add(duration).UTC()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.routes = append(l.dropLocked(route.Method, route.Path), route)
+	return route, nil
+}
+
+// Disable stops logging a route, reporting whether it was being logged. An
+// empty method and path stop logging every route.
+func (l *Logger) Disable(method, path string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	before := len(l.active())
+	if method == "" && path == "" {
+		l.routes = nil
+	} else {
+		l.routes = l.dropLocked(strings.ToUpper(strings.TrimSpace(method)), strings.TrimSpace(path))
+	}
+	return len(l.active()) < before
+}
+
+// dropLocked returns the routes without the one named; l.mu must be held
+func (l *Logger) dropLocked(method, path string) []Route {
+	kept := make([]Route, 0, len(l.routes))
+	for _, route := range l.routes {
+		if route.Method != method || route.Path != path {
+			kept = append(kept, route)
+		}
+	}
+	return kept
+}
+
+// active returns the routes not expired yet
+func (l *Logger) active() []Route {
+	now := l.now()
+	active := make([]Route, 0, len(l.routes))
+	for _, route := range l.routes {
+		if now.Before(route.Until) {
+			active = append(active, route)
+		}
+	}
+	return active
+}
+
+// Routes returns the routes being logged
+func (l *Logger) Routes() []Route {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.active()
+}
+
+// Match reports whether a request's payloads are logged
+func (l *Logger) Match(method, path string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if len(l.routes) == 0 {
+		return false
+	}
+	now := l.now()
+	for _, route := range l.routes {
+		if now.Before(route.Until) && route.Matches(method, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Log logs an entry with its bodies redacted and cut at MaxBodyBytes
+func (l *Logger) Log(entry Entry) {
+	l.printf("Payload %s %s %d %s request=%s response=%s", entry.Method, entry.Path, entry.Status, entry.Duration.Round(time.Millisecond),
+		l.body(entry.RequestBody, entry.RequestType), l.body(entry.ResponseBody, entry.ResponseType))
+}
+
+// body redacts and cuts a body for logging, quoted so it stays on one line
+func (l *Logger) body(body, contentType string) string {
+	body = l.policy.Body(body, contentType)
+	if len(body) > MaxBodyBytes {
+		body = body[:MaxBodyBytes] + "..."
+	}
+	return fmt.Sprintf("%q", body)
+}
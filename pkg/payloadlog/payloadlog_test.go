@@ -0,0 +1,91 @@
+package payloadlog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-hello-world/pkg/redact"
+)
+
+func TestLogger_Enable(t *testing.T) {
+	logger := New(redact.New(nil))
+	now := time.Date(2025, 6, 3, 8, 0, 0, 0, time.UTC)
+	logger.now = func() time.Time { return now }
+
+	tests := []struct {
+		name         string
+		method, path string
+		duration     time.Duration
+	}{
+		{"no method", "", "/login", time.Hour},
+		{"relative path", "POST", "login", time.Hour},
+		{"no duration", "POST", "/login", 0},
+		{"too long", "POST", "/login", MaxDuration + time.Minute},
+	}
+	for _, tt := range tests {
+		if _, err := logger.Enable(tt.method, tt.path, tt.duration); !errors.Is(err, ErrInvalidRoute) {
+			t.Errorf("Enable() with %s error = %v, want ErrInvalidRoute", tt.name, err)
+		}
+	}
+
+	if logger.Match("POST", "/login") {
+		t.Error("Match() should log nothing before a route is enabled")
+	}
+	route, err := logger.Enable("post", "/users/:id", 30*time.Minute)
+	if err != nil || route.Name() != "POST /users/:id" || !route.Until.Equal(now.Add(30*time.Minute)) {
+		t.Fatalf("Enable() = %+v, %v", route, err)
+	}
+	// Enabling again moves the end rather than adding a route
+	logger.Enable("POST", "/users/:id", time.Hour)
+	logger.Enable("GET", "/me", time.Hour)
+	if routes := logger.Routes(); len(routes) != 2 || !routes[1].Until.Equal(now.Add(time.Hour)) {
+		t.Errorf("Routes() = %+v, want 2 ending in an hour", routes)
+	}
+	if !logger.Match("POST", "/users/42") || logger.Match("POST", "/users") || logger.Match("GET", "/users/42") {
+		t.Error("Match() should only log the enabled routes")
+	}
+
+	if !logger.Disable("GET", "/me") || logger.Disable("GET", "/me") || logger.Match("GET", "/me") {
+		t.Error("Disable() should stop logging the route once")
+	}
+
+	// Routes turn themselves off
+	now = now.Add(time.Hour)
+	if logger.Match("POST", "/users/42") || len(logger.Routes()) != 0 {
+		t.Error("Match() should not log expired routes")
+	}
+
+	logger.Enable("GET", "/me", time.Hour)
+	if !logger.Disable("", "") || len(logger.Routes()) != 0 {
+		t.Error("Disable() without a route should stop logging every route")
+	}
+}
+
+func TestLogger_Log(t *testing.T) {
+	logger := New(redact.New(nil))
+	var logged string
+	logger.printf = func(format string, args ...any) { logged = fmt.Sprintf(format, args...) }
+
+	logger.Log(Entry{
+		Method:       "POST",
+		Path:         "/register",
+		Status:       201,
+		Duration:     12 * time.Millisecond,
+		RequestType:  "application/json",
+		RequestBody:  `{"password":"hunter22","phoneNumber":"0812345678"}`,
+		ResponseType: "application/json",
+		ResponseBody: `{"data":{"id":42}}`,
+	})
+	want := `Payload POST /register 201 12ms request="{\"password\":\"[redacted]\",\"phoneNumber\":\"******5678\"}" response="{\"data\":{\"id\":42}}"`
+	if logged != want {
+		t.Errorf("Log() = %s, want %s", logged, want)
+	}
+
+	logger.Log(Entry{Method: "POST", Path: "/upload", RequestType: "text/plain", RequestBody: strings.Repeat("a", MaxBodyBytes+10)})
+	if !strings.Contains(logged, strings.Repeat("a", MaxBodyBytes)+`..."`) || strings.Contains(logged, strings.Repeat("a", MaxBodyBytes+1)) {
+		t.Error("Log() should cut long bodies")
+	}
+}
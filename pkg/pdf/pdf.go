@@ -0,0 +1,146 @@
+// Package pdf writes plain text as an A4 PDF document in Helvetica, wrapping
+// long lines and adding pages as needed. It covers reports made of text
+// without depending on a PDF library; there are no images or tables.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Page layout, in points
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 50
+	fontSize   = 10
+	leading    = 14
+	titleSize  = 14
+	// lineRunes is where lines are wrapped: Helvetica averages about half
+	// its size per character, so 90 fit the text width with room for wide
+	// letters
+	lineRunes = 90
+	// pageLines is how many lines fit between the margins
+	pageLines = (pageHeight - 2*margin) / leading
+)
+
+// Render returns text as a PDF document titled title and dated created. The
+// title heads the first page. Characters outside Latin-1 are replaced with
+// "?", as the standard fonts cannot show them.
+func Render(title, text string, created time.Time) []byte {
+	// The first line of the first page is taken by the title
+	pages := paginate(append([]string{""}, wrap(text)...))
+
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 5 are fixed; each page adds a page and a content object
+	w.object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	w.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	w.object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	w.object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	w.object(fmt.Sprintf("<< /Title (%s) /Producer (fiber-hello-world) /CreationDate (D:%s) >>",
+		escape(title), created.UTC().Format("20060102150405Z")))
+
+	for i, lines := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n%d TL\n%d %d Td\n", leading, margin, pageHeight-margin-fontSize)
+		if i == 0 {
+			fmt.Fprintf(&content, "/F2 %d Tf\n(%s) Tj\n", titleSize, escape(title))
+			fmt.Fprintf(&content, "T*\n")
+			lines = lines[1:]
+		}
+		fmt.Fprintf(&content, "/F1 %d Tf\n", fontSize)
+		for _, line := range lines {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", escape(line))
+		}
+		content.WriteString("ET")
+
+		w.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 7+2*i))
+		w.object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+	return w.finish()
+}
+
+// writer numbers objects and remembers their offsets for the
+// cross-reference table
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+// object writes the next object with body
+func (w *writer) object(body string) {
+	w.offsets = append(w.offsets, w.buf.Len())
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", len(w.offsets), body)
+}
+
+// finish writes the cross-reference table and trailer
+func (w *writer) finish() []byte {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, xref)
+	return w.buf.Bytes()
+}
+
+// wrap splits text into lines of at most lineRunes, breaking at spaces
+// where possible
+func wrap(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n") {
+		line = strings.TrimRight(line, " \r")
+		for utf8.RuneCountInString(line) > lineRunes {
+			runes := []rune(line)
+			cut := lineRunes
+			for i := lineRunes; i > 0; i-- {
+				if runes[i] == ' ' {
+					cut = i
+					break
+				}
+			}
+			lines = append(lines, strings.TrimRight(string(runes[:cut]), " "))
+			line = strings.TrimLeft(string(runes[cut:]), " ")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// paginate splits lines into pages of at most pageLines
+func paginate(lines []string) [][]string {
+	var pages [][]string
+	for len(lines) > pageLines {
+		pages = append(pages, lines[:pageLines])
+		lines = lines[pageLines:]
+	}
+	return append(pages, lines)
+}
+
+// escape makes s a PDF string literal body in WinAnsiEncoding
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || (r >= 0x7f && r < 0xa0) || r > 0xff:
+			b.WriteByte('?')
+		default:
+			// Latin-1 matches WinAnsiEncoding from 0xa0 on
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
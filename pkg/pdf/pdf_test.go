@@ -0,0 +1,65 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	created := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	doc := Render("Account report (Ann)", "Name: Ann \\ Co\nCity: Zürich, 東京", created)
+
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatalf("Render() is not framed as a PDF:\n%s", doc)
+	}
+	for _, want := range []string{
+		"/Count 1",
+		"(Account report \\(Ann\\)) Tj",
+		"(Name: Ann \\\\ Co) Tj",
+		"(City: Z\xfcrich, ??) Tj",
+		"/CreationDate (D:20261015080000Z)",
+	} {
+		if !bytes.Contains(doc, []byte(want)) {
+			t.Errorf("Render() does not contain %q", want)
+		}
+	}
+
+	// Every cross-reference entry points at its object
+	xref := bytes.LastIndex(doc, []byte("\nxref\n")) + 1
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	if startxref == nil || string(startxref[1]) != strconv.Itoa(xref) {
+		t.Fatalf("startxref = %q, want %d", startxref, xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(doc[xref:], -1)
+	if len(entries) != 7 {
+		t.Fatalf("%d objects, want 7", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(doc[offset:], []byte(want)) {
+			t.Errorf("object %d is not at offset %d", i+1, offset)
+		}
+	}
+}
+
+func TestRender_Pages(t *testing.T) {
+	// A long line wraps at a space and text past a page adds pages
+	long := strings.Repeat("word ", 30)
+	doc := Render("Report", long+"\n"+strings.Repeat("line\n", 2*pageLines), time.Now())
+	if !bytes.Contains(doc, []byte("/Count 3")) {
+		t.Errorf("Render() of %d lines does not have 3 pages", 2*pageLines+2)
+	}
+
+	lines := wrap(long)
+	if len(lines) != 2 || len([]rune(lines[0])) > lineRunes || !strings.HasSuffix(lines[0], "word") {
+		t.Errorf("wrap() = %q", lines)
+	}
+	if lines := wrap(strings.Repeat("x", lineRunes+10)); len(lines) != 2 || len(lines[0]) != lineRunes {
+		t.Errorf("wrap() without spaces = %q", lines)
+	}
+}
@@ -0,0 +1,227 @@
+// Package ratelimit limits how often each client may call a route, in fixed
+// windows, and reports how much of the limit is left so clients can back off
+// before they are refused.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidRule is returned for a rule that cannot be enforced
+var ErrInvalidRule = errors.New("invalid rate limit")
+
+// Rate limit headers: the X-RateLimit-* ones clients commonly read, with a
+// Unix time reset, and the RateLimit-* ones of the IETF draft, with a reset
+// in seconds
+const (
+	HeaderXRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderXRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderXRateLimitReset     = "X-RateLimit-Reset"
+	HeaderRateLimitLimit      = "RateLimit-Limit"
+	HeaderRateLimitRemaining  = "RateLimit-Remaining"
+	HeaderRateLimitReset      = "RateLimit-Reset"
+	HeaderRateLimitPolicy     = "RateLimit-Policy"
+)
+
+// WarnShare is the share of a limit used from which clients are warned
+const WarnShare = 0.8
+
+// Rule allows Limit requests for a route per client in every Window
+type Rule struct {
+	Method string
+	// Path is the route; segments starting with ":" match any segment
+	Path   string
+	Limit  int
+	Window time.Duration
+}
+
+// ParseRule parses a rule from a route such as "POST /login" and a spec
+// such as "10/1m"
+func ParseRule(route, spec string) (Rule, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+	if !ok {
+		return Rule{}, fmt.Errorf("%w: route %q must be a method and a path", ErrInvalidRule, route)
+	}
+	rule := Rule{Method: strings.ToUpper(method), Path: strings.TrimSpace(path)}
+
+	limit, window, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return Rule{}, fmt.Errorf("%w: %s: spec %q must be a limit and a window, e.g. 10/1m", ErrInvalidRule, route, spec)
+	}
+	var err error
+	if rule.Limit, err = strconv.Atoi(strings.TrimSpace(limit)); err != nil {
+		return Rule{}, fmt.Errorf("%w: %s: limit %q is not a number of requests", ErrInvalidRule, route, limit)
+	}
+	if rule.Window, err = time.ParseDuration(strings.TrimSpace(window)); err != nil {
+		return Rule{}, fmt.Errorf("%w: %s: window %q is not a duration", ErrInvalidRule, route, window)
+	}
+	return rule, rule.Validate()
+}
+
+// ParseRules parses rules keyed by route, e.g. "POST /login" => "10/1m",
+// sorted by route so the first match is the same on every start
+func ParseRules(pairs map[string]string) ([]Rule, error) {
+	routes := make([]string, 0, len(pairs))
+	for route := range pairs {
+		routes = append(routes, route)
+	}
+	slices.Sort(routes)
+
+	rules := make([]Rule, 0, len(pairs))
+	for _, route := range routes {
+		rule, err := ParseRule(route, pairs[route])
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Validate checks that the rule names a route and allows some requests
+func (r Rule) Validate() error {
+	switch {
+	case r.Method == "":
+		return fmt.Errorf("%w: method is required", ErrInvalidRule)
+	case !strings.HasPrefix(r.Path, "/"):
+		return fmt.Errorf("%w: %s: path must start with /", ErrInvalidRule, r.Route())
+	case r.Limit <= 0:
+		return fmt.Errorf("%w: %s: limit must be positive", ErrInvalidRule, r.Route())
+	case r.Window < time.Second:
+		return fmt.Errorf("%w: %s: window must be at least 1s", ErrInvalidRule, r.Route())
+	}
+	return nil
+}
+
+// Route names the rule's route, e.g. "POST /login"
+func (r Rule) Route() string {
+	return r.Method + " " + r.Path
+}
+
+// Matches reports whether a request is for the rule's route
+func (r Rule) Matches(method, path string) bool {
+	if !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	want := strings.Split(strings.Trim(r.Path, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i, segment := range want {
+		if segment != got[i] && !(strings.HasPrefix(segment, ":") && got[i] != "") {
+			return false
+		}
+	}
+	return true
+}
+
+// Usage is a client's use of a rule's limit after a request
+type Usage struct {
+	Limit int
+	// Remaining is how many more requests the client may make in the window
+	Remaining int
+	// Reset is when the window ends and the count starts over
+	Reset  time.Time
+	Window time.Duration
+	// Allowed is false when the request is over the limit
+	Allowed bool
+}
+
+// Warn reports whether the client used at least WarnShare of the limit
+func (u Usage) Warn() bool {
+	return float64(u.Limit-u.Remaining) >= WarnShare*float64(u.Limit)
+}
+
+// counter counts a client's requests in one window
+type counter struct {
+	count int
+	reset time.Time
+}
+
+// Limiter counts each client's requests per rule, in this process only
+type Limiter struct {
+	rules []Rule
+	now   func() time.Time
+
+	mu       sync.Mutex
+	counters []map[string]*counter
+	// swept is when counters of past windows were last dropped
+	swept time.Time
+}
+
+// New creates a limiter for rules
+func New(rules []Rule) (*Limiter, error) {
+	counters := make([]map[string]*counter, len(rules))
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		counters[i] = make(map[string]*counter)
+	}
+	return &Limiter{
+		rules:    append([]Rule(nil), rules...),
+		now:      time.Now,
+		counters: counters,
+	}, nil
+}
+
+// Rules returns the limiter's rules
+func (l *Limiter) Rules() []Rule {
+	return l.rules
+}
+
+// Match returns the index of the first rule for a request's route
+func (l *Limiter) Match(method, path string) (int, bool) {
+	for i, rule := range l.rules {
+		if rule.Matches(method, path) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Allow counts a request by client for the rule at index, as returned by
+// Match. Windows start at a client's first request; requests over the limit
+// are not counted.
+func (l *Limiter) Allow(index int, client string) Usage {
+	rule := l.rules[index]
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	c := l.counters[index][client]
+	if c == nil || !now.Before(c.reset) {
+		c = &counter{reset: now.Add(rule.Window)}
+		l.counters[index][client] = c
+	}
+	usage := Usage{Limit: rule.Limit, Reset: c.reset, Window: rule.Window, Allowed: c.count < rule.Limit}
+	if usage.Allowed {
+		c.count++
+	}
+	usage.Remaining = rule.Limit - c.count
+	return usage
+}
+
+// sweep drops the counters of past windows, at most once a minute, so
+// clients that went away do not hold memory
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	l.swept = now
+	for _, clients := range l.counters {
+		for client, c := range clients {
+			if !now.Before(c.reset) {
+				delete(clients, client)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   string
+		spec    string
+		want    Rule
+		wantErr bool
+	}{
+		{"limit and window", "POST /login", "10/1m", Rule{Method: "POST", Path: "/login", Limit: 10, Window: time.Minute}, false},
+		{"spaces", "get /users/:id", " 100 / 1h ", Rule{Method: "GET", Path: "/users/:id", Limit: 100, Window: time.Hour}, false},
+		{"no method", "/login", "10/1m", Rule{}, true},
+		{"relative path", "POST login", "10/1m", Rule{}, true},
+		{"no window", "POST /login", "10", Rule{}, true},
+		{"not a number", "POST /login", "ten/1m", Rule{}, true},
+		{"zero limit", "POST /login", "0/1m", Rule{}, true},
+		{"bad window", "POST /login", "10/minute", Rule{}, true},
+		{"short window", "POST /login", "10/100ms", Rule{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRule(tt.route, tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidRule) {
+					t.Errorf("ParseRule() error = %v, want ErrInvalidRule", err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseRule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(map[string]string{"POST /register": "5/1h", "POST /login": "10/1m"})
+	if err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+	if len(rules) != 2 || rules[0].Route() != "POST /login" || rules[1].Route() != "POST /register" {
+		t.Errorf("ParseRules() = %+v, want the rules sorted by route", rules)
+	}
+	if _, err := ParseRules(map[string]string{"POST /login": "lots"}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("ParseRules() error = %v, want ErrInvalidRule", err)
+	}
+}
+
+func TestRule_Matches(t *testing.T) {
+	rule := Rule{Method: "DELETE", Path: "/me/share-links/:id"}
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{"DELETE", "/me/share-links/12", true},
+		{"delete", "/me/share-links/12/", true},
+		{"GET", "/me/share-links/12", false},
+		{"DELETE", "/me/share-links", false},
+		{"DELETE", "/me/share-links/12/accesses", false},
+	}
+	for _, tt := range tests {
+		if got := rule.Matches(tt.method, tt.path); got != tt.want {
+			t.Errorf("Matches(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter, err := New([]Rule{{Method: "POST", Path: "/login", Limit: 5, Window: time.Minute}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 6, 3, 8, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	index, ok := limiter.Match("POST", "/login")
+	if !ok {
+		t.Fatal("Match() found no rule")
+	}
+	if _, ok := limiter.Match("GET", "/me"); ok {
+		t.Error("Match(GET /me) should find no rule")
+	}
+
+	tests := []struct {
+		remaining int
+		warn      bool
+		allowed   bool
+	}{
+		{4, false, true},
+		{3, false, true},
+		{2, false, true},
+		{1, true, true},
+		{0, true, true},
+		{0, true, false},
+	}
+	for i, tt := range tests {
+		usage := limiter.Allow(index, "203.0.113.7")
+		if usage.Remaining != tt.remaining || usage.Warn() != tt.warn || usage.Allowed != tt.allowed || !usage.Reset.Equal(now.Add(time.Minute)) {
+			t.Errorf("request %d: Allow() = %+v with warn %v, want %d remaining, warn %v, allowed %v", i+1, usage, usage.Warn(), tt.remaining, tt.warn, tt.allowed)
+		}
+	}
+
+	// Other clients have their own count
+	if usage := limiter.Allow(index, "198.51.100.2"); !usage.Allowed || usage.Remaining != 4 {
+		t.Errorf("Allow() for another client = %+v, want 4 remaining", usage)
+	}
+
+	// The count starts over once the window ends
+	now = now.Add(time.Minute)
+	if usage := limiter.Allow(index, "203.0.113.7"); !usage.Allowed || usage.Remaining != 4 || !usage.Reset.Equal(now.Add(time.Minute)) {
+		t.Errorf("Allow() in the next window = %+v, want 4 remaining", usage)
+	}
+	limiter.mu.Lock()
+	clients := len(limiter.counters[index])
+	limiter.mu.Unlock()
+	if clients != 1 {
+		t.Errorf("%d clients counted, want the expired one swept", clients)
+	}
+}
@@ -0,0 +1,275 @@
+// Package recorder keeps recent request/response pairs, with secrets
+// removed, so user-reported bugs can be inspected and replayed.
+package recorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fiber-hello-world/pkg/redact"
+)
+
+// HeaderReplayOf marks a replayed request with the ID of its recording.
+// Replays are not recorded again.
+const HeaderReplayOf = "X-Replay-Of"
+
+// Redacted replaces secret header and field values
+const Redacted = redact.Redacted
+
+// MaxBodyBytes bounds the recorded request and response bodies
+const MaxBodyBytes = 64 << 10
+
+// MaxSize bounds the recordings kept in memory
+const MaxSize = 10000
+
+// ErrInvalidSize is returned for a size outside 1..MaxSize
+var ErrInvalidSize = errors.New("recording size must be between 1 and 10000")
+
+// secretHeaders are recorded as Redacted
+var secretHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"x-api-key":           true,
+	"x-signature":         true,
+}
+
+// skippedHeaders are not recorded, since replays set them anew
+var skippedHeaders = map[string]bool{
+	"content-length":    true,
+	"connection":        true,
+	"transfer-encoding": true,
+	"host":              true,
+}
+
+// Recording is a request and the response it got
+type Recording struct {
+	ID     int64     `json:"id"`
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	// Query is the raw query string, with secret parameters redacted
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body,omitempty"`
+
+	Status          int               `json:"status"`
+	ResponseHeaders map[string]string `json:"responseHeaders"`
+	ResponseBody    string            `json:"responseBody,omitempty"`
+	Duration        time.Duration     `json:"duration"`
+	// Truncated is set when a body was cut at MaxBodyBytes
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Recorder keeps the latest recordings in memory and, when opened with a
+// file, appends them to it as JSON lines
+type Recorder struct {
+	mu         sync.Mutex
+	size       int
+	recordings []Recording
+	nextID     int64
+	file       *os.File
+}
+
+// New creates a recorder keeping the latest size recordings in memory
+func New(size int) (*Recorder, error) {
+	if size < 1 || size > MaxSize {
+		return nil, ErrInvalidSize
+	}
+	return &Recorder{size: size, nextID: 1}, nil
+}
+
+// Open creates a recorder that also appends its recordings to the file at
+// path. The latest recordings already in the file are loaded, so requests
+// recorded before a restart can be replayed against the new code.
+func Open(size int, path string) (*Recorder, error) {
+	r, err := New(size)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording file: %w", err)
+	}
+	if err := r.load(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read recording file %s: %w", path, err)
+	}
+	r.file = file
+	return r, nil
+}
+
+// load reads the recordings in a file written by a recorder
+func (r *Recorder) load(file io.Reader) error {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), 4*MaxBodyBytes+64<<10)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var recording Recording
+		if err := json.Unmarshal(line, &recording); err != nil {
+			return err
+		}
+		r.add(recording)
+		r.nextID = max(r.nextID, recording.ID+1)
+	}
+	return scanner.Err()
+}
+
+// Close closes the recording file, if any
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// Record sanitizes a recording, gives it an ID and keeps it, dropping the
+// oldest one when the recorder is full. It returns the stored recording.
+func (r *Recorder) Record(recording Recording) (Recording, error) {
+	recording = Sanitize(recording)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recording.ID = r.nextID
+	r.nextID++
+	r.add(recording)
+	if r.file != nil {
+		line, err := json.Marshal(recording)
+		if err != nil {
+			return recording, err
+		}
+		if _, err := r.file.Write(append(line, '\n')); err != nil {
+			return recording, fmt.Errorf("failed to write recording: %w", err)
+		}
+	}
+	return recording, nil
+}
+
+// add keeps a recording, dropping the oldest one past the size
+func (r *Recorder) add(recording Recording) {
+	r.recordings = append(r.recordings, recording)
+	if len(r.recordings) > r.size {
+		r.recordings = append(r.recordings[:0], r.recordings[len(r.recordings)-r.size:]...)
+	}
+}
+
+// List returns the kept recordings, newest first
+func (r *Recorder) List() []Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recordings := make([]Recording, len(r.recordings))
+	for i, recording := range r.recordings {
+		recordings[len(r.recordings)-1-i] = recording
+	}
+	return recordings
+}
+
+// Get returns a kept recording
+func (r *Recorder) Get(id int64) (Recording, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, recording := range r.recordings {
+		if recording.ID == id {
+			return recording, true
+		}
+	}
+	return Recording{}, false
+}
+
+// Clear drops the kept recordings. The file, if any, is left alone.
+func (r *Recorder) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recordings = nil
+}
+
+// Sanitize redacts the secrets in a recording: credential headers, secret
+// query parameters and JSON or form fields. Bodies that are not text are
+// replaced by their size, and long ones are cut at MaxBodyBytes.
+func Sanitize(recording Recording) Recording {
+	recording.Headers = sanitizeHeaders(recording.Headers)
+	recording.ResponseHeaders = sanitizeHeaders(recording.ResponseHeaders)
+	if recording.Query != "" {
+		recording.Query = redact.Secrets.Form(recording.Query)
+	}
+
+	var truncated bool
+	recording.Body, truncated = sanitizeBody(recording.Body, header(recording.Headers, "Content-Type"))
+	recording.Truncated = recording.Truncated || truncated
+	recording.ResponseBody, truncated = sanitizeBody(recording.ResponseBody, header(recording.ResponseHeaders, "Content-Type"))
+	recording.Truncated = recording.Truncated || truncated
+	return recording
+}
+
+// header returns a header value by its case-insensitive name
+func header(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+func sanitizeHeaders(headers map[string]string) map[string]string {
+	sanitized := make(map[string]string, len(headers))
+	for name, value := range headers {
+		lower := strings.ToLower(name)
+		switch {
+		case skippedHeaders[lower]:
+		case secretHeaders[lower]:
+			sanitized[name] = Redacted
+		default:
+			sanitized[name] = value
+		}
+	}
+	return sanitized
+}
+
+// sanitizeBody redacts the secret fields of a body, by its content type,
+// and reports whether it was cut. Personal data is kept, so replays send it.
+func sanitizeBody(body, contentType string) (string, bool) {
+	body = redact.Secrets.Body(body, contentType)
+	if len(body) > MaxBodyBytes {
+		return body[:MaxBodyBytes], true
+	}
+	return body, false
+}
+
+// Request rebuilds the recorded request for replaying, marked with
+// HeaderReplayOf. Redacted headers are left out, so the caller sets its own
+// credentials; redacted body fields are sent as recorded.
+func (recording Recording) Request() (*http.Request, error) {
+	target := recording.Path
+	if recording.Query != "" {
+		target += "?" + recording.Query
+	}
+	req, err := http.NewRequest(recording.Method, target, strings.NewReader(recording.Body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid recording: %w", err)
+	}
+	for name, value := range recording.Headers {
+		if value != Redacted {
+			req.Header.Set(name, value)
+		}
+	}
+	req.Header.Set(HeaderReplayOf, strconv.FormatInt(recording.ID, 10))
+	return req, nil
+}
@@ -0,0 +1,123 @@
+package recorder
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	recording := Sanitize(Recording{
+		Method: "POST",
+		Path:   "/login",
+		Query:  "next=%2Fme&access_token=abc",
+		Headers: map[string]string{
+			"Authorization":  "Bearer abc",
+			"Content-Type":   "application/json",
+			"Content-Length": "42",
+			"User-Agent":     "okhttp/4.12.0",
+		},
+		Body:            `{"email":"a@example.com","password":"hunter2","profile":{"apiKey":"k","name":"A"},"items":[{"refreshToken":"r"}]}`,
+		ResponseHeaders: map[string]string{"Content-Type": "image/png", "Set-Cookie": "session=abc"},
+		ResponseBody:    "\x89PNG....",
+	})
+
+	if recording.Headers["Authorization"] != Redacted || recording.Headers["User-Agent"] != "okhttp/4.12.0" {
+		t.Errorf("headers = %v", recording.Headers)
+	}
+	if _, ok := recording.Headers["Content-Length"]; ok {
+		t.Error("Content-Length should not be recorded")
+	}
+	if recording.ResponseHeaders["Set-Cookie"] != Redacted {
+		t.Errorf("response headers = %v", recording.ResponseHeaders)
+	}
+	if strings.Contains(recording.Query, "abc") || !strings.Contains(recording.Query, "next=%2Fme") {
+		t.Errorf("query = %q", recording.Query)
+	}
+	for _, secret := range []string{"hunter2", `"k"`, `"r"`} {
+		if strings.Contains(recording.Body, secret) {
+			t.Errorf("body %s holds %s", recording.Body, secret)
+		}
+	}
+	if !strings.Contains(recording.Body, `"email":"a@example.com"`) || !strings.Contains(recording.Body, `"name":"A"`) {
+		t.Errorf("body = %s, want the other fields kept", recording.Body)
+	}
+	if recording.ResponseBody != "[8 bytes of image/png]" {
+		t.Errorf("response body = %q", recording.ResponseBody)
+	}
+
+	form := Sanitize(Recording{
+		Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		Body:    "username=a&password=hunter2",
+	})
+	if strings.Contains(form.Body, "hunter2") || !strings.Contains(form.Body, "username=a") {
+		t.Errorf("form body = %q", form.Body)
+	}
+
+	long := Sanitize(Recording{Body: strings.Repeat("a", MaxBodyBytes+1)})
+	if len(long.Body) != MaxBodyBytes || !long.Truncated {
+		t.Errorf("long body: %d bytes, truncated %v", len(long.Body), long.Truncated)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	if _, err := New(0); !errors.Is(err, ErrInvalidSize) {
+		t.Errorf("New(0) error = %v, want ErrInvalidSize", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "recordings.jsonl")
+	r, err := Open(2, path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	for _, p := range []string{"/a", "/b", "/c"} {
+		if _, err := r.Record(Recording{Method: "GET", Path: p}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	list := r.List()
+	if len(list) != 2 || list[0].Path != "/c" || list[0].ID != 3 || list[1].Path != "/b" {
+		t.Errorf("List() = %+v", list)
+	}
+	if _, ok := r.Get(1); ok {
+		t.Error("Get() should not find a dropped recording")
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Recordings survive a restart and IDs continue
+	r, err = Open(10, path)
+	if err != nil {
+		t.Fatalf("Open() again error = %v", err)
+	}
+	defer r.Close()
+	if len(r.List()) != 3 {
+		t.Errorf("loaded %d recordings, want 3", len(r.List()))
+	}
+	recording, _ := r.Record(Recording{Method: "GET", Path: "/d"})
+	if recording.ID != 4 {
+		t.Errorf("ID after restart = %d, want 4", recording.ID)
+	}
+}
+
+func TestRecording_Request(t *testing.T) {
+	req, err := Recording{
+		ID:      7,
+		Method:  "PUT",
+		Path:    "/me",
+		Query:   "lang=th",
+		Headers: map[string]string{"Authorization": Redacted, "Content-Type": "application/json"},
+		Body:    `{"fullName":"A"}`,
+	}.Request()
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if req.Method != "PUT" || req.URL.RequestURI() != "/me?lang=th" || req.Header.Get(HeaderReplayOf) != "7" {
+		t.Errorf("request = %s %s %v", req.Method, req.URL, req.Header)
+	}
+	if req.Header.Get("Authorization") != "" || req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v, want the redacted ones left out", req.Header)
+	}
+}
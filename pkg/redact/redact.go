@@ -0,0 +1,171 @@
+// Package redact holds the field-level redaction rules shared by everything
+// that shows request data outside the request: recordings, hook delivery
+// logs and payload logs. Fields are matched by name, so the rules apply to
+// JSON bodies, forms, query strings and hook payloads alike.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Redacted replaces the values of secret fields
+const Redacted = "[redacted]"
+
+// maskMark replaces the hidden characters of masked values
+const maskMark = "*"
+
+// maskKeep is how many trailing characters masked values keep
+const maskKeep = 4
+
+// secretFields are field names, lower-cased, that are always secrets.
+// Names containing one of secretParts are secrets too.
+var secretFields = []string{"apikey", "api_key", "otp", "pin"}
+
+var secretParts = []string{"password", "secret", "token"}
+
+// maskedParts are parts of field names whose values are masked rather than
+// redacted, keeping enough to tell them apart
+var maskedParts = []string{"phone"}
+
+// Policy decides which fields are redacted and which are masked
+type Policy struct {
+	// fields are redacted by exact lower-cased name
+	fields []string
+	// mask enables masking personal data such as phone numbers
+	mask bool
+}
+
+// Secrets redacts secrets only: fields named like passwords, secrets or
+// tokens, and API keys, one-time codes and PINs. It keeps personal data,
+// e.g. for recordings that are replayed.
+var Secrets = &Policy{fields: secretFields}
+
+// New creates a policy for logs: secrets are redacted, phone numbers are
+// masked to their last four digits, and the fields named in extra, such as
+// "birthday", are redacted too
+func New(extra []string) *Policy {
+	fields := slices.Clone(secretFields)
+	for _, field := range extra {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return &Policy{fields: fields, mask: true}
+}
+
+// IsSecret reports whether a field holds a secret, by its name
+func (p *Policy) IsSecret(name string) bool {
+	lower := strings.ToLower(name)
+	if slices.Contains(p.fields, lower) {
+		return true
+	}
+	for _, part := range secretParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMasked reports whether a field is masked, by its name
+func (p *Policy) isMasked(name string) bool {
+	if !p.mask {
+		return false
+	}
+	lower := strings.ToLower(name)
+	for _, part := range maskedParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the value of a field as the policy allows it to be shown
+func (p *Policy) Value(name string, value any) any {
+	switch {
+	case p.IsSecret(name):
+		return Redacted
+	case p.isMasked(name):
+		return Mask(value)
+	}
+	return p.JSON(value)
+}
+
+// Mask hides all but the last four characters of a string value; other
+// values and short strings are redacted
+func Mask(value any) any {
+	s, ok := value.(string)
+	if !ok || len([]rune(s)) <= maskKeep {
+		return Redacted
+	}
+	runes := []rune(s)
+	return strings.Repeat(maskMark, len(runes)-maskKeep) + string(runes[len(runes)-maskKeep:])
+}
+
+// JSON redacts the fields of a decoded JSON value, in place
+func (p *Policy) JSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			v[key] = p.Value(key, field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = p.JSON(item)
+		}
+	}
+	return value
+}
+
+// Form redacts the fields of a URL-encoded form or query string. Forms
+// without such fields are returned as they are.
+func (p *Policy) Form(form string) string {
+	values, err := url.ParseQuery(form)
+	if err != nil {
+		return Redacted
+	}
+	redacted := false
+	for key, fieldValues := range values {
+		for i, value := range fieldValues {
+			if shown := p.Value(key, value); shown != value {
+				fieldValues[i] = shown.(string)
+				redacted = true
+			}
+		}
+	}
+	if !redacted {
+		return form
+	}
+	return values.Encode()
+}
+
+// Body redacts a request or response body by its content type: the fields
+// of JSON bodies and forms are redacted, other text is kept, and binary
+// bodies are replaced by their size
+func (p *Policy) Body(body, contentType string) string {
+	if body == "" {
+		return ""
+	}
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var value any
+		if json.Unmarshal([]byte(body), &value) == nil {
+			if redacted, err := json.Marshal(p.JSON(value)); err == nil {
+				return string(redacted)
+			}
+		}
+		return body
+	case mediaType == "application/x-www-form-urlencoded":
+		return p.Form(body)
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/xml" || mediaType == "":
+		return body
+	}
+	return fmt.Sprintf("[%d bytes of %s]", len(body), mediaType)
+}
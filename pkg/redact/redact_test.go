@@ -0,0 +1,68 @@
+package redact
+
+import "testing"
+
+func TestPolicy_Body(t *testing.T) {
+	logs := New([]string{"Birthday"})
+	tests := []struct {
+		name        string
+		policy      *Policy
+		body        string
+		contentType string
+		want        string
+	}{
+		{"secrets", Secrets, `{"email":"jane@example.com","password":"hunter22","phoneNumber":"0812345678"}`, "application/json",
+			`{"email":"jane@example.com","password":"[redacted]","phoneNumber":"0812345678"}`},
+		{"logs", logs, `{"email":"jane@example.com","password":"hunter22","phoneNumber":"0812345678","birthday":"1990-01-15"}`, "application/json; charset=utf-8",
+			`{"birthday":"[redacted]","email":"jane@example.com","password":"[redacted]","phoneNumber":"******5678"}`},
+		{"nested", logs, `{"data":{"accessToken":"eyJ","users":[{"phone":"123"}]}}`, "application/json",
+			`{"data":{"accessToken":"[redacted]","users":[{"phone":"[redacted]"}]}}`},
+		{"form", logs, "otp=123456&phone=0812345678&step=2", "application/x-www-form-urlencoded",
+			"otp=%5Bredacted%5D&phone=%2A%2A%2A%2A%2A%2A5678&step=2"},
+		{"form without secrets", logs, "step=2&b=1", "application/x-www-form-urlencoded", "step=2&b=1"},
+		{"text", logs, "password=hunter22", "text/plain", "password=hunter22"},
+		{"binary", logs, "\x89PNG", "image/png", "[4 bytes of image/png]"},
+		{"invalid JSON", logs, `{"password":`, "application/json", `{"password":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Body(tt.body, tt.contentType); got != tt.want {
+				t.Errorf("Body() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_IsSecret(t *testing.T) {
+	for _, name := range []string{"password", "newPassword", "client_secret", "resetToken", "API_KEY", "otp", "pin"} {
+		if !Secrets.IsSecret(name) {
+			t.Errorf("IsSecret(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"email", "phoneNumber", "spinner", "birthday"} {
+		if Secrets.IsSecret(name) {
+			t.Errorf("IsSecret(%q) = true, want false", name)
+		}
+	}
+	if !New([]string{" Birthday "}).IsSecret("birthday") {
+		t.Error("New() should redact the extra fields")
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		value any
+		want  any
+	}{
+		{"0812345678", "******5678"},
+		{"+66 81 234 5678", "***********5678"},
+		{"1234", Redacted},
+		{812345678, Redacted},
+		{nil, Redacted},
+	}
+	for _, tt := range tests {
+		if got := Mask(tt.value); got != tt.want {
+			t.Errorf("Mask(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
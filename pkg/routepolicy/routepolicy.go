@@ -0,0 +1,295 @@
+// Package routepolicy declares, for groups of routes, who may call them, how
+// often and from which web origins, in one file loaded at startup, so the
+// rules of a route are read in one place rather than in its wiring.
+package routepolicy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"fiber-hello-world/pkg/ratelimit"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrInvalidPolicy is returned for a group that cannot be enforced
+	ErrInvalidPolicy = errors.New("invalid route policy")
+	// ErrRoleRequired is returned by Group.Authorize when the caller's role
+	// is not one of the group's
+	ErrRoleRequired = errors.New("role required")
+	// ErrScopeRequired is returned by Group.Authorize when the caller lacks
+	// one of the group's scopes
+	ErrScopeRequired = errors.New("scope required")
+)
+
+// AnyOrigin in CORS.Origins allows requests from every origin
+const AnyOrigin = "*"
+
+// CORS lets browser pages on other origins call a group's routes
+type CORS struct {
+	// Origins are the allowed origins, e.g. https://app.example.com, or AnyOrigin
+	Origins []string
+	// Methods and Headers are allowed in preflight requests; no methods
+	// allows GET, HEAD and POST
+	Methods []string
+	Headers []string
+	// ExposeHeaders are the response headers pages may read
+	ExposeHeaders []string
+	// Credentials allows cookies and the Authorization header
+	Credentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// AllowsOrigin reports whether pages on origin may call the routes
+func (c *CORS) AllowsOrigin(origin string) bool {
+	return origin != "" && (slices.Contains(c.Origins, AnyOrigin) || slices.Contains(c.Origins, origin))
+}
+
+// AllowedMethods returns the methods allowed in preflight requests
+func (c *CORS) AllowedMethods() []string {
+	if len(c.Methods) == 0 {
+		return []string{"GET", "HEAD", "POST"}
+	}
+	return c.Methods
+}
+
+// Group is the policy of the routes under a path prefix
+type Group struct {
+	Name string
+	// Prefix is the path of the group's routes, e.g. /admin; it matches
+	// whole segments, so /admin does not match /administrators
+	Prefix string
+	// Roles, when set, restricts the routes to callers with one of them
+	Roles []string
+	// Scopes, when set, restricts the routes to callers with all of them
+	Scopes []string
+	// RateLimit, when set, limits the calls each client makes to all of the
+	// group's routes together
+	RateLimit *ratelimit.Rule
+	// CORS, when set, lets pages on other origins call the routes
+	CORS *CORS
+}
+
+// Authenticated reports whether callers must be authenticated, i.e. the
+// group requires roles or scopes
+func (g *Group) Authenticated() bool {
+	return len(g.Roles) > 0 || len(g.Scopes) > 0
+}
+
+// Authorize checks a caller's role and scopes against the group's
+func (g *Group) Authorize(role string, scopes []string) error {
+	if len(g.Roles) > 0 && !slices.Contains(g.Roles, role) {
+		return fmt.Errorf("%w: %s", ErrRoleRequired, strings.Join(g.Roles, " or "))
+	}
+	for _, scope := range g.Scopes {
+		if !slices.Contains(scopes, scope) {
+			return fmt.Errorf("%w: %s", ErrScopeRequired, scope)
+		}
+	}
+	return nil
+}
+
+// validate checks that the group names its routes and its rules can be applied
+func (g *Group) validate() error {
+	switch {
+	case g.Name == "":
+		return fmt.Errorf("%w: group name is required", ErrInvalidPolicy)
+	case !strings.HasPrefix(g.Prefix, "/"):
+		return fmt.Errorf("%w: %s: prefix must start with /", ErrInvalidPolicy, g.Name)
+	case slices.Contains(g.Roles, ""), slices.Contains(g.Scopes, ""):
+		return fmt.Errorf("%w: %s: roles and scopes must not be empty", ErrInvalidPolicy, g.Name)
+	}
+	if g.RateLimit != nil {
+		if err := g.RateLimit.Validate(); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidPolicy, g.Name, err)
+		}
+	}
+	if g.CORS != nil {
+		switch {
+		case len(g.CORS.Origins) == 0:
+			return fmt.Errorf("%w: %s: cors needs origins", ErrInvalidPolicy, g.Name)
+		case g.CORS.Credentials && slices.Contains(g.CORS.Origins, AnyOrigin):
+			return fmt.Errorf("%w: %s: cors credentials cannot be allowed for any origin", ErrInvalidPolicy, g.Name)
+		case g.CORS.MaxAge < 0:
+			return fmt.Errorf("%w: %s: cors maxAge must not be negative", ErrInvalidPolicy, g.Name)
+		}
+	}
+	return nil
+}
+
+// matches reports whether path is under the group's prefix
+func (g *Group) matches(path string) bool {
+	prefix := strings.TrimSuffix(g.Prefix, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// Policy holds the groups of routes, each request following the group with
+// the longest matching prefix
+type Policy struct {
+	groups  []Group
+	limiter *ratelimit.Limiter
+	// limits maps a group's index to its rule in limiter
+	limits map[int]int
+}
+
+// New creates a policy from groups. Two groups may not share a prefix.
+func New(groups []Group) (*Policy, error) {
+	groups = slices.Clone(groups)
+	for i := range groups {
+		if err := groups[i].validate(); err != nil {
+			return nil, err
+		}
+		for _, other := range groups[:i] {
+			if other.Name == groups[i].Name || strings.TrimSuffix(other.Prefix, "/") == strings.TrimSuffix(groups[i].Prefix, "/") {
+				return nil, fmt.Errorf("%w: groups %s and %s overlap", ErrInvalidPolicy, other.Name, groups[i].Name)
+			}
+		}
+	}
+	// Longest prefix first, so the first match is the most specific group
+	slices.SortStableFunc(groups, func(a, b Group) int {
+		return len(strings.TrimSuffix(b.Prefix, "/")) - len(strings.TrimSuffix(a.Prefix, "/"))
+	})
+
+	policy := &Policy{groups: groups, limits: make(map[int]int)}
+	var rules []ratelimit.Rule
+	for i, group := range groups {
+		if group.RateLimit != nil {
+			policy.limits[i] = len(rules)
+			rules = append(rules, *group.RateLimit)
+		}
+	}
+	limiter, err := ratelimit.New(rules)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPolicy, err)
+	}
+	policy.limiter = limiter
+	return policy, nil
+}
+
+// Groups returns the policy's groups, longest prefix first
+func (p *Policy) Groups() []Group {
+	return p.groups
+}
+
+// Match returns the index of the group of a request's path
+func (p *Policy) Match(path string) (int, bool) {
+	for i := range p.groups {
+		if p.groups[i].matches(path) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Group returns the group at index, as returned by Match
+func (p *Policy) Group(index int) *Group {
+	return &p.groups[index]
+}
+
+// Allow counts a request by client against the rate limit of the group at
+// index. ok is false when the group has no rate limit.
+func (p *Policy) Allow(index int, client string) (usage ratelimit.Usage, ok bool) {
+	rule, ok := p.limits[index]
+	if !ok {
+		return ratelimit.Usage{}, false
+	}
+	return p.limiter.Allow(rule, client), true
+}
+
+// policyFile is the ROUTE_POLICY_FILE
+type policyFile struct {
+	Groups map[string]struct {
+		Prefix    string   `yaml:"prefix"`
+		Roles     []string `yaml:"roles"`
+		Scopes    []string `yaml:"scopes"`
+		RateLimit string   `yaml:"rateLimit"`
+		CORS      *struct {
+			Origins       []string      `yaml:"origins"`
+			Methods       []string      `yaml:"methods"`
+			Headers       []string      `yaml:"headers"`
+			ExposeHeaders []string      `yaml:"exposeHeaders"`
+			Credentials   bool          `yaml:"credentials"`
+			MaxAge        time.Duration `yaml:"maxAge"`
+		} `yaml:"cors"`
+	} `yaml:"groups"`
+}
+
+// Parse reads a policy from YAML, e.g. one embedded in a program:
+//
+//	groups:
+//	  admin:
+//	    prefix: /admin
+//	    roles: [admin]
+//	    rateLimit: 300/1m     # per client, across the group's routes
+//	    cors:
+//	      origins: [https://admin.example.com]
+//	      methods: [GET, POST, PUT, DELETE]
+//	      headers: [Authorization, Content-Type]
+//	      credentials: true
+//	      maxAge: 10m
+//	  reports:
+//	    prefix: /reports
+//	    scopes: [reports:read]
+//	  public:
+//	    prefix: /
+//	    cors:
+//	      origins: ["*"]
+func Parse(data []byte) (*Policy, error) {
+	var file policyFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPolicy, err)
+	}
+
+	groups := make([]Group, 0, len(file.Groups))
+	for name, g := range file.Groups {
+		group := Group{Name: name, Prefix: g.Prefix, Roles: g.Roles, Scopes: g.Scopes}
+		if g.RateLimit != "" {
+			// The rule counts every method of the group's routes
+			rule, err := ratelimit.ParseRule("* "+g.Prefix, g.RateLimit)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrInvalidPolicy, name, err)
+			}
+			group.RateLimit = &rule
+		}
+		if g.CORS != nil {
+			methods := make([]string, len(g.CORS.Methods))
+			for i, method := range g.CORS.Methods {
+				methods[i] = strings.ToUpper(method)
+			}
+			group.CORS = &CORS{
+				Origins:       g.CORS.Origins,
+				Methods:       methods,
+				Headers:       g.CORS.Headers,
+				ExposeHeaders: g.CORS.ExposeHeaders,
+				Credentials:   g.CORS.Credentials,
+				MaxAge:        g.CORS.MaxAge,
+			}
+		}
+		groups = append(groups, group)
+	}
+	// Sort by name so errors and ties are the same on every start
+	slices.SortFunc(groups, func(a, b Group) int { return strings.Compare(a.Name, b.Name) })
+	return New(groups)
+}
+
+// Load reads the policy in the YAML file at path; see Parse
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
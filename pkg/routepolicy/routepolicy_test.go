@@ -0,0 +1,133 @@
+package routepolicy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testPolicy = `
+groups:
+  admin:
+    prefix: /admin
+    roles: [admin, support]
+    rateLimit: 2/1m
+    cors:
+      origins: [https://admin.example.com]
+      methods: [get, post]
+      credentials: true
+      maxAge: 10m
+  reports:
+    prefix: /admin/reports/
+    scopes: [reports:read]
+  public:
+    prefix: /
+    cors:
+      origins: ["*"]
+`
+
+func TestParse(t *testing.T) {
+	policy, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/admin/reports/daily", "reports"},
+		{"/admin/reports", "reports"},
+		{"/admin/users", "admin"},
+		{"/admin", "admin"},
+		{"/administrators", "public"},
+		{"/login", "public"},
+	}
+	for _, tt := range tests {
+		index, ok := policy.Match(tt.path)
+		if !ok || policy.Group(index).Name != tt.want {
+			t.Errorf("Match(%q) = %v, want group %s", tt.path, policy.Group(index).Name, tt.want)
+		}
+	}
+
+	index, _ := policy.Match("/admin/users")
+	admin := policy.Group(index)
+	if cors := admin.CORS; cors == nil || !cors.AllowsOrigin("https://admin.example.com") || cors.AllowsOrigin("https://evil.example.com") ||
+		cors.AllowedMethods()[1] != "POST" || cors.MaxAge != 10*time.Minute {
+		t.Errorf("admin cors = %+v", admin.CORS)
+	}
+	for i := 1; i <= 3; i++ {
+		usage, ok := policy.Allow(index, "user:1")
+		if !ok || usage.Allowed != (i <= 2) {
+			t.Errorf("Allow() request %d = %+v, %v", i, usage, ok)
+		}
+	}
+	if usage, _ := policy.Allow(index, "user:2"); !usage.Allowed {
+		t.Error("clients should be limited separately")
+	}
+	public, _ := policy.Match("/login")
+	if _, ok := policy.Allow(public, "user:1"); ok {
+		t.Error("Allow() on a group without a rate limit should report no limit")
+	}
+}
+
+func TestGroup_Authorize(t *testing.T) {
+	group := Group{Name: "reports", Prefix: "/reports", Roles: []string{"admin"}, Scopes: []string{"reports:read", "reports:export"}}
+	tests := []struct {
+		name   string
+		role   string
+		scopes []string
+		want   error
+	}{
+		{"allowed", "admin", []string{"reports:export", "reports:read", "users:read"}, nil},
+		{"wrong role", "user", []string{"reports:read", "reports:export"}, ErrRoleRequired},
+		{"missing scope", "admin", []string{"reports:read"}, ErrScopeRequired},
+	}
+	for _, tt := range tests {
+		if err := group.Authorize(tt.role, tt.scopes); !errors.Is(err, tt.want) || (err == nil) != (tt.want == nil) {
+			t.Errorf("%s: Authorize() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if (&Group{Prefix: "/"}).Authenticated() || !group.Authenticated() {
+		t.Error("only groups with roles or scopes require authentication")
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+	}{
+		{"unknown field", "groups:\n  admin:\n    prefix: /admin\n    role: admin\n"},
+		{"relative prefix", "groups:\n  admin:\n    prefix: admin\n"},
+		{"same prefix", "groups:\n  a:\n    prefix: /admin\n  b:\n    prefix: /admin/\n"},
+		{"bad rate limit", "groups:\n  admin:\n    prefix: /admin\n    rateLimit: lots\n"},
+		{"cors without origins", "groups:\n  admin:\n    prefix: /admin\n    cors:\n      credentials: false\n"},
+		{"credentials for any origin", "groups:\n  admin:\n    prefix: /admin\n    cors:\n      origins: [\"*\"]\n      credentials: true\n"},
+		{"empty role", "groups:\n  admin:\n    prefix: /admin\n    roles: [\"\"]\n"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse([]byte(tt.policy)); !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("%s: Parse() error = %v, want ErrInvalidPolicy", tt.name, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(policy.Groups()) != 3 || policy.Groups()[0].Name != "reports" {
+		t.Errorf("Groups() = %+v, want reports first", policy.Groups())
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Load() of a missing file should fail")
+	}
+}
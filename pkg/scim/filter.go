@@ -0,0 +1,112 @@
+package scim
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidFilter is returned for filters outside the supported subset
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Comparison is a single "attribute op value" filter expression. Attribute is
+// lowercased because SCIM attribute names are case-insensitive. Value is a
+// string, bool or nil (for null).
+type Comparison struct {
+	Attribute string
+	Operator  string
+	Value     interface{}
+}
+
+// ParseFilter parses the subset of SCIM filters identity providers send when
+// looking users up: "eq" comparisons joined with "and", e.g.
+//
+//	userName eq "jane@example.com" and active eq true
+//
+// An empty filter returns no comparisons.
+func ParseFilter(filter string) ([]Comparison, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var comparisons []Comparison
+	for i := 0; i < len(tokens); {
+		if i > 0 {
+			if !strings.EqualFold(tokens[i].text, "and") || tokens[i].quoted {
+				return nil, fmt.Errorf("%w: only \"and\" may join expressions", ErrInvalidFilter)
+			}
+			i++
+		}
+		if i+3 > len(tokens) {
+			return nil, fmt.Errorf("%w: expected attribute, operator and value", ErrInvalidFilter)
+		}
+
+		attribute, operator, value := tokens[i], tokens[i+1], tokens[i+2]
+		if attribute.quoted || operator.quoted {
+			return nil, fmt.Errorf("%w: unexpected string", ErrInvalidFilter)
+		}
+		if !strings.EqualFold(operator.text, "eq") {
+			return nil, fmt.Errorf("%w: unsupported operator %q", ErrInvalidFilter, operator.text)
+		}
+
+		comparison := Comparison{Attribute: strings.ToLower(attribute.text), Operator: "eq"}
+		switch {
+		case value.quoted:
+			comparison.Value = value.text
+		case strings.EqualFold(value.text, "true"):
+			comparison.Value = true
+		case strings.EqualFold(value.text, "false"):
+			comparison.Value = false
+		case strings.EqualFold(value.text, "null"):
+			comparison.Value = nil
+		default:
+			return nil, fmt.Errorf("%w: unsupported value %q", ErrInvalidFilter, value.text)
+		}
+		comparisons = append(comparisons, comparison)
+		i += 3
+	}
+
+	return comparisons, nil
+}
+
+// token is a filter word or a quoted string with escapes resolved
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits a filter into words and JSON-style quoted strings
+func tokenize(filter string) ([]token, error) {
+	var tokens []token
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case r == ' ' || r == '\t':
+			i++
+		case r == '"':
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilter)
+			}
+			i++
+			tokens = append(tokens, token{text: sb.String(), quoted: true})
+		case r == '(' || r == ')' || r == '[' || r == ']':
+			return nil, fmt.Errorf("%w: grouping is not supported", ErrInvalidFilter)
+		default:
+			start := i
+			for i < len(runes) && runes[i] != ' ' && runes[i] != '\t' && runes[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, token{text: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,60 @@
+package scim
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   []Comparison
+	}{
+		{"empty", "", nil},
+		{"userName", `userName eq "jane@example.com"`, []Comparison{{"username", "eq", "jane@example.com"}}},
+		{"case-insensitive names", `UserName EQ "Jane"`, []Comparison{{"username", "eq", "Jane"}}},
+		{"escaped quote", `displayName eq "Jane \"JJ\" Doe"`, []Comparison{{"displayname", "eq", `Jane "JJ" Doe`}}},
+		{"boolean", `active eq false`, []Comparison{{"active", "eq", false}}},
+		{"and", `userName eq "a@b.co" and active eq true`, []Comparison{
+			{"username", "eq", "a@b.co"},
+			{"active", "eq", true},
+		}},
+		{"dotted attribute", `emails.value eq "a@b.co"`, []Comparison{{"emails.value", "eq", "a@b.co"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	tests := []string{
+		`userName`,
+		`userName eq`,
+		`userName co "jane"`,
+		`userName eq "jane" or active eq true`,
+		`userName eq "jane`,
+		`userName eq jane`,
+		`(userName eq "jane")`,
+		`emails[type eq "work"] eq "a@b.co"`,
+		`"userName" eq "jane"`,
+	}
+
+	for _, filter := range tests {
+		t.Run(filter, func(t *testing.T) {
+			if _, err := ParseFilter(filter); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("ParseFilter(%q) error = %v, want ErrInvalidFilter", filter, err)
+			}
+		})
+	}
+}
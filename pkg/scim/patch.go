@@ -0,0 +1,109 @@
+package scim
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidPatch is returned for malformed PatchOp operations
+var ErrInvalidPatch = errors.New("invalid patch operation")
+
+// Patch operation names
+const (
+	OpAdd     = "add"
+	OpReplace = "replace"
+	OpRemove  = "remove"
+)
+
+// PatchOperation is one entry of a PatchOp request's Operations list
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Change is a normalized patch operation targeting a single attribute.
+// Attribute is a lowercased dotted path with any value filter removed, so
+// `phoneNumbers[type eq "work"].value` becomes "phonenumbers.value".
+type Change struct {
+	Op        string
+	Attribute string
+	Value     interface{}
+}
+
+// Flatten normalizes operations into per-attribute changes. Operations
+// without a path carry an object of attributes (as sent by Okta and Azure AD),
+// which is expanded into one change per leaf attribute.
+func Flatten(operations []PatchOperation) ([]Change, error) {
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("%w: no operations", ErrInvalidPatch)
+	}
+
+	var changes []Change
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		if op != OpAdd && op != OpReplace && op != OpRemove {
+			return nil, fmt.Errorf("%w: unsupported op %q", ErrInvalidPatch, operation.Op)
+		}
+
+		if operation.Path == "" {
+			if op == OpRemove {
+				return nil, fmt.Errorf("%w: remove requires a path", ErrInvalidPatch)
+			}
+			values, ok := operation.Value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%w: value must be an object when path is omitted", ErrInvalidPatch)
+			}
+			changes = appendObject(changes, op, "", values)
+			continue
+		}
+
+		attribute, err := normalizePath(operation.Path)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, Change{Op: op, Attribute: attribute, Value: operation.Value})
+	}
+
+	return changes, nil
+}
+
+// appendObject adds a change for every leaf of a path-less operation value
+func appendObject(changes []Change, op, prefix string, values map[string]interface{}) []Change {
+	for name, value := range values {
+		attribute := strings.ToLower(name)
+		if prefix != "" {
+			attribute = prefix + "." + attribute
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			changes = appendObject(changes, op, attribute, nested)
+			continue
+		}
+		changes = append(changes, Change{Op: op, Attribute: attribute, Value: value})
+	}
+	return changes
+}
+
+// normalizePath lowercases a path and drops value filters in brackets
+func normalizePath(path string) (string, error) {
+	var sb strings.Builder
+	depth := 0
+	for _, r := range path {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+			if depth < 0 {
+				return "", fmt.Errorf("%w: malformed path %q", ErrInvalidPatch, path)
+			}
+		case depth == 0:
+			sb.WriteRune(r)
+		}
+	}
+	if depth != 0 {
+		return "", fmt.Errorf("%w: malformed path %q", ErrInvalidPatch, path)
+	}
+	return strings.ToLower(sb.String()), nil
+}
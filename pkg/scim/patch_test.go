@@ -0,0 +1,80 @@
+package scim
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations []PatchOperation
+		want       []Change
+	}{
+		{
+			name:       "path",
+			operations: []PatchOperation{{Op: "replace", Path: "active", Value: false}},
+			want:       []Change{{OpReplace, "active", false}},
+		},
+		{
+			name:       "op and path are case-insensitive",
+			operations: []PatchOperation{{Op: "Replace", Path: "displayName", Value: "Jane"}},
+			want:       []Change{{OpReplace, "displayname", "Jane"}},
+		},
+		{
+			name:       "value filter dropped",
+			operations: []PatchOperation{{Op: "add", Path: `phoneNumbers[type eq "work"].value`, Value: "0812345678"}},
+			want:       []Change{{OpAdd, "phonenumbers.value", "0812345678"}},
+		},
+		{
+			name: "object without path",
+			operations: []PatchOperation{{Op: "replace", Value: map[string]interface{}{
+				"active": false,
+				"name":   map[string]interface{}{"formatted": "Jane Doe"},
+			}}},
+			want: []Change{{OpReplace, "active", false}, {OpReplace, "name.formatted", "Jane Doe"}},
+		},
+		{
+			name:       "remove",
+			operations: []PatchOperation{{Op: "remove", Path: "phoneNumbers"}},
+			want:       []Change{{OpRemove, "phonenumbers", nil}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Flatten(tt.operations)
+			if err != nil {
+				t.Fatalf("Flatten() error = %v", err)
+			}
+			// Object expansion follows map order
+			sort.Slice(got, func(i, j int) bool { return got[i].Attribute < got[j].Attribute })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Flatten() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlatten_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations []PatchOperation
+	}{
+		{"no operations", nil},
+		{"unknown op", []PatchOperation{{Op: "move", Path: "active"}}},
+		{"remove without path", []PatchOperation{{Op: "remove"}}},
+		{"non-object value without path", []PatchOperation{{Op: "replace", Value: "x"}}},
+		{"unbalanced brackets", []PatchOperation{{Op: "replace", Path: `emails[type eq "work".value`, Value: "x"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Flatten(tt.operations); !errors.Is(err, ErrInvalidPatch) {
+				t.Errorf("Flatten() error = %v, want ErrInvalidPatch", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+// Package scim implements the protocol pieces of SCIM 2.0 (RFC 7643/7644)
+// that the provisioning endpoints need: schema URNs, filter parsing and
+// PatchOp normalization.
+package scim
+
+// Schema and message URNs
+const (
+	SchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// MediaType is the content type of SCIM requests and responses
+const MediaType = "application/scim+json"
+
+// Error types reported in the scimType member of error responses
+const (
+	ErrorInvalidFilter = "invalidFilter"
+	ErrorInvalidPath   = "invalidPath"
+	ErrorInvalidValue  = "invalidValue"
+	ErrorInvalidSyntax = "invalidSyntax"
+	ErrorUniqueness    = "uniqueness"
+)
@@ -0,0 +1,222 @@
+// Package screening rejects user-supplied names that contain profanity,
+// hide control characters, pose as staff or carry contact details.
+package screening
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrRejected is wrapped by every Rejection
+var ErrRejected = errors.New("name rejected")
+
+// Code tells clients why a name was rejected
+type Code string
+
+// Rejection codes
+const (
+	// CodeControlCharacters: the name contains control or bidi override characters
+	CodeControlCharacters Code = "name_control_characters"
+	// CodeContactDetails: the name contains an email address, phone number or URL
+	CodeContactDetails Code = "name_contact_details"
+	// CodeReserved: the name poses as staff, e.g. "Admin" or "5upp0rt"
+	CodeReserved Code = "name_reserved"
+	// CodeProfanity: the name contains a blocked word
+	CodeProfanity Code = "name_profanity"
+)
+
+// Rejection is the error returned for a rejected name
+type Rejection struct {
+	Code   Code
+	Reason string
+}
+
+func (r *Rejection) Error() string { return fmt.Sprintf("%v: %s", ErrRejected, r.Reason) }
+
+// Unwrap makes errors.Is(err, ErrRejected) hold for rejections
+func (r *Rejection) Unwrap() error { return ErrRejected }
+
+// DefaultReserved are the names of staff roles users may not pose as
+var DefaultReserved = []string{
+	"admin", "administrator", "root", "system", "support", "moderator",
+	"staff", "security", "official", "helpdesk",
+}
+
+// DefaultBlocklist is a short list of common English profanity. Deployments
+// should bring a list for their languages.
+var DefaultBlocklist = []string{
+	"fuck", "fucker", "fucking", "shit", "bitch", "cunt", "asshole", "bastard",
+	"dick", "dickhead", "motherfucker", "wanker", "twat", "whore", "slut",
+}
+
+// confusables maps characters that look like Latin letters, including
+// digits and symbols used as letters, to the letter
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'і': 'i', 'ї': 'i', 'ј': 'j', 'к': 'k',
+	'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x',
+	'ѕ': 's', 'ԁ': 'd', 'һ': 'h', 'ԛ': 'q', 'ԝ': 'w',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x',
+	// Digits and symbols
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b',
+	'@': 'a', '$': 's', '!': 'i', '|': 'i',
+	// l and i are told apart by case only, which skeletons drop
+	'l': 'i',
+}
+
+// multiLetter are letter pairs that read as one letter
+var multiLetter = strings.NewReplacer("rn", "m", "vv", "w")
+
+var (
+	emailPattern = regexp.MustCompile(`[^\s@]+@[^\s@]+\.[^\s@]+`)
+	urlPattern   = regexp.MustCompile(`(?i)(://|www\.|\.(com|net|org|io)\b)`)
+)
+
+// maxNameDigits is the most digits a name may have before it is taken for
+// a phone number
+const maxNameDigits = 6
+
+// Options configure a Screener
+type Options struct {
+	// Blocklist replaces DefaultBlocklist when not nil
+	Blocklist []string
+	// Reserved replaces DefaultReserved when not nil
+	Reserved []string
+}
+
+// Screener checks names against its lists. Words are compared by their
+// skeleton: case, accents, invisible characters and lookalike letters are
+// ignored, so "ΑDM1N" matches "admin". Blocked and reserved words match
+// whole words of the name or the whole name with its spaces removed, so
+// "Dickens" and "Rooted" pass.
+type Screener struct {
+	blocked  map[string]bool
+	reserved map[string]bool
+}
+
+// New creates a screener
+func New(opts Options) *Screener {
+	if opts.Blocklist == nil {
+		opts.Blocklist = DefaultBlocklist
+	}
+	if opts.Reserved == nil {
+		opts.Reserved = DefaultReserved
+	}
+	return &Screener{blocked: skeletonSet(opts.Blocklist), reserved: skeletonSet(opts.Reserved)}
+}
+
+// LoadBlocklist reads a blocklist file with one word or phrase per line.
+// Blank lines and lines starting with # are skipped.
+func LoadBlocklist(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blocklist: %w", err)
+	}
+	defer file.Close()
+
+	words := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blocklist: %w", err)
+	}
+	return words, nil
+}
+
+// Screen returns a *Rejection if name may not be used
+func (s *Screener) Screen(name string) error {
+	for _, r := range name {
+		if unicode.IsControl(r) || isBidiControl(r) {
+			return &Rejection{Code: CodeControlCharacters, Reason: "name must not contain control characters"}
+		}
+	}
+
+	digits := 0
+	for _, r := range name {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	if digits > maxNameDigits || emailPattern.MatchString(name) || urlPattern.MatchString(name) {
+		return &Rejection{Code: CodeContactDetails, Reason: "name must not contain contact details"}
+	}
+
+	skeletons := words(name)
+	for _, word := range skeletons {
+		if s.reserved[word] {
+			return &Rejection{Code: CodeReserved, Reason: "name is reserved for staff"}
+		}
+	}
+	for _, word := range skeletons {
+		if s.blocked[word] {
+			return &Rejection{Code: CodeProfanity, Reason: "name contains a blocked word"}
+		}
+	}
+	return nil
+}
+
+// words returns the skeletons of the words in name followed by the
+// skeleton of the whole name without separators
+func words(name string) []string {
+	fields := skeletonFields(name)
+	if len(fields) > 1 {
+		fields = append(fields, strings.Join(fields, ""))
+	}
+	return fields
+}
+
+// skeletonFields splits the skeleton of s into words
+func skeletonFields(s string) []string {
+	return strings.FieldsFunc(skeleton(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// skeleton reduces s to what it looks like: lower case, without accents
+// or invisible characters, with lookalike characters replaced by the
+// letter they pass for
+func skeleton(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(norm.NFKD.String(s)) {
+		if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Cf, r) {
+			continue
+		}
+		if letter, ok := confusables[r]; ok {
+			r = letter
+		}
+		b.WriteRune(r)
+	}
+	return multiLetter.Replace(b.String())
+}
+
+// skeletonSet returns the skeletons of words with their separators removed
+func skeletonSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, word := range list {
+		if fields := skeletonFields(word); len(fields) > 0 {
+			set[strings.Join(fields, "")] = true
+		}
+	}
+	return set
+}
+
+// isBidiControl reports whether r changes the direction of the text
+// around it, which can make a name display differently from how it reads
+func isBidiControl(r rune) bool {
+	return r == '\u061c' || r == '\u200e' || r == '\u200f' ||
+		(r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+}
@@ -0,0 +1,92 @@
+package screening
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScreener_Screen(t *testing.T) {
+	screener := New(Options{})
+	tests := []struct {
+		name string
+		want Code
+	}{
+		{"Somchai Jaidee", ""},
+		{"สมชาย ใจดี", ""},
+		{"José Müller-Lüdenscheidt", ""},
+		{"Charles Dickens", ""},
+		{"Rooted Systems", ""},
+		{"Agent 007", ""},
+		{"Eve\x00", CodeControlCharacters},
+		{"Line\nBreak", CodeControlCharacters},
+		{"Nimda\u202eadmin", CodeControlCharacters},
+		{"Call 0812345678", CodeContactDetails},
+		{"Bob bob@example.com", CodeContactDetails},
+		{"Visit www.example", CodeContactDetails},
+		{"Admin", CodeReserved},
+		{"The Support Team", CodeReserved},
+		{"ΑDM1N", CodeReserved},
+		{"аdmin", CodeReserved},
+		{"ａｄｍｉｎ", CodeReserved},
+		{"ad\u200bmin", CodeReserved},
+		{"A d m i n", CodeReserved},
+		{"adrnin", CodeReserved},
+		{"5upp0rt", CodeReserved},
+		{"Sh!t Happens", CodeProfanity},
+		{"Big Fück", CodeProfanity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := screener.Screen(tt.name)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Screen(%q) = %v, want accepted", tt.name, err)
+				}
+				return
+			}
+			var rejection *Rejection
+			if !errors.As(err, &rejection) || rejection.Code != tt.want || !errors.Is(err, ErrRejected) {
+				t.Errorf("Screen(%q) = %v, want %s", tt.name, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Lists(t *testing.T) {
+	screener := New(Options{Blocklist: []string{"Frak"}, Reserved: []string{"Customer Service"}})
+
+	for name, want := range map[string]Code{
+		"Frak Off":         CodeProfanity,
+		"Shit":             "",
+		"Admin":            "",
+		"Customer Service": CodeReserved,
+	} {
+		var code Code
+		var rejection *Rejection
+		if errors.As(screener.Screen(name), &rejection) {
+			code = rejection.Code
+		}
+		if code != want {
+			t.Errorf("Screen(%q) code = %q, want %q", name, code, want)
+		}
+	}
+}
+
+func TestLoadBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(path, []byte("# English\nfrak\n\n  smeg  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	words, err := LoadBlocklist(path)
+	if err != nil || !reflect.DeepEqual(words, []string{"frak", "smeg"}) {
+		t.Errorf("LoadBlocklist() = %v, %v", words, err)
+	}
+	if _, err := LoadBlocklist(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadBlocklist() should fail for a missing file")
+	}
+}
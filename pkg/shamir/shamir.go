@@ -0,0 +1,126 @@
+// Package shamir splits a secret into shares with Shamir's secret sharing
+// over GF(2^8): any threshold of the shares recovers the secret, and fewer
+// reveal nothing about it.
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// MaxShares is the most shares a secret can be split into
+const MaxShares = 255
+
+// ErrInvalidShares is returned when shares are missing, of different
+// lengths or repeated
+var ErrInvalidShares = errors.New("invalid shares")
+
+// Split splits secret into parts shares, threshold of which recover it. Each
+// share is one byte longer than the secret: the last byte is its x
+// coordinate.
+func Split(secret []byte, parts, threshold int) ([][]byte, error) {
+	switch {
+	case len(secret) == 0:
+		return nil, errors.New("secret must not be empty")
+	case threshold < 2 || threshold > parts:
+		return nil, fmt.Errorf("threshold must be between 2 and the number of parts, got %d of %d", threshold, parts)
+	case parts > MaxShares:
+		return nil, fmt.Errorf("parts must be at most %d, got %d", MaxShares, parts)
+	}
+
+	shares := make([][]byte, parts)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = byte(i + 1)
+	}
+	// One random polynomial of degree threshold-1 per byte, whose constant
+	// term is the byte
+	coefficients := make([]byte, threshold)
+	for b, value := range secret {
+		coefficients[0] = value
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			share[b] = evaluate(coefficients, share[len(secret)])
+		}
+	}
+	return shares, nil
+}
+
+// Combine recovers the secret from shares. Given fewer shares than the
+// threshold it returns a wrong secret rather than an error, so callers
+// check the result, e.g. against a hash.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, fmt.Errorf("%w: at least 2 are needed", ErrInvalidShares)
+	}
+	size := len(shares[0])
+	if size < 2 {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidShares)
+	}
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool, len(shares))
+	for i, share := range shares {
+		if len(share) != size {
+			return nil, fmt.Errorf("%w: lengths differ", ErrInvalidShares)
+		}
+		xs[i] = share[size-1]
+		if xs[i] == 0 || seen[xs[i]] {
+			return nil, fmt.Errorf("%w: repeated or malformed share", ErrInvalidShares)
+		}
+		seen[xs[i]] = true
+	}
+
+	// Lagrange interpolation at x = 0; subtraction is XOR in GF(2^8)
+	secret := make([]byte, size-1)
+	for i := range shares {
+		basis := byte(1)
+		for j := range shares {
+			if i != j {
+				basis = mul(basis, div(xs[j], xs[i]^xs[j]))
+			}
+		}
+		for b := range secret {
+			secret[b] ^= mul(shares[i][b], basis)
+		}
+	}
+	return secret, nil
+}
+
+// evaluate returns the polynomial with coefficients, lowest degree first, at x
+func evaluate(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = mul(y, x) ^ coefficients[i]
+	}
+	return y
+}
+
+// mul multiplies in GF(2^8) with the AES polynomial x^8+x^4+x^3+x+1
+func mul(a, b byte) byte {
+	var product byte
+	for b > 0 {
+		if b&1 == 1 {
+			product ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return product
+}
+
+// div divides a by b, which must not be zero, in GF(2^8)
+func div(a, b byte) byte {
+	// b^254 is the inverse of b, as b^255 = 1
+	inverse := byte(1)
+	for i := 0; i < 254; i++ {
+		inverse = mul(inverse, b)
+	}
+	return mul(a, inverse)
+}
@@ -0,0 +1,73 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(shares) != 5 || len(shares[0]) != len(secret)+1 {
+		t.Fatalf("Split() = %d shares of %d bytes", len(shares), len(shares[0]))
+	}
+
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var picked [][]byte
+		for _, i := range subset {
+			picked = append(picked, shares[i])
+		}
+		got, err := Combine(picked)
+		if err != nil || !bytes.Equal(got, secret) {
+			t.Errorf("Combine(%v) = %q, %v; want the secret", subset, got, err)
+		}
+	}
+
+	// Below the threshold the result is not the secret
+	if got, err := Combine(shares[:2]); err != nil || bytes.Equal(got, secret) {
+		t.Errorf("Combine() of 2 shares = %q, %v; want a wrong secret", got, err)
+	}
+}
+
+func TestSplit_Invalid(t *testing.T) {
+	tests := []struct {
+		name             string
+		secret           []byte
+		parts, threshold int
+	}{
+		{"empty secret", nil, 3, 2},
+		{"threshold of one", []byte("s"), 3, 1},
+		{"threshold above parts", []byte("s"), 3, 4},
+		{"too many parts", []byte("s"), 256, 2},
+	}
+	for _, tt := range tests {
+		if _, err := Split(tt.secret, tt.parts, tt.threshold); err == nil {
+			t.Errorf("Split() with %s succeeded", tt.name)
+		}
+	}
+}
+
+func TestCombine_Invalid(t *testing.T) {
+	shares, err := Split([]byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		shares [][]byte
+	}{
+		{"one share", shares[:1]},
+		{"repeated share", [][]byte{shares[0], shares[0]}},
+		{"different lengths", [][]byte{shares[0], shares[1][1:]}},
+		{"zero x", [][]byte{shares[0], append([]byte("secret"), 0)}},
+	}
+	for _, tt := range tests {
+		if _, err := Combine(tt.shares); !errors.Is(err, ErrInvalidShares) {
+			t.Errorf("Combine() with %s error = %v, want ErrInvalidShares", tt.name, err)
+		}
+	}
+}
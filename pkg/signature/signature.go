@@ -0,0 +1,205 @@
+// Package signature signs and verifies HTTP requests with per-client HMAC
+// keys, so a captured request cannot be altered or replayed even by a party
+// that can read the bearer token (e.g. a compromised TLS-terminating proxy).
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Request headers carrying the signature
+const (
+	HeaderClientID  = "X-Client-ID"
+	HeaderTimestamp = "X-Signature-Timestamp"
+	HeaderNonce     = "X-Signature-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+// DefaultMaxSkew is how far a request timestamp may differ from the server clock
+const DefaultMaxSkew = 5 * time.Minute
+
+// MaxNonces is how many unexpired nonces a verifier without a NonceStore
+// remembers. Once full, signed requests are refused with ErrUnavailable
+// until nonces expire, since a replay could not be ruled out otherwise.
+const MaxNonces = 100_000
+
+var (
+	// ErrMissing is returned when a signature header is absent
+	ErrMissing = errors.New("request signature required")
+	// ErrUnknownClient is returned for a client ID without a key
+	ErrUnknownClient = errors.New("unknown signing client")
+	// ErrExpired is returned when the timestamp is outside the allowed skew
+	ErrExpired = errors.New("request signature timestamp out of range")
+	// ErrInvalid is returned when the signature does not match
+	ErrInvalid = errors.New("invalid request signature")
+	// ErrReplayed is returned when a nonce is reused within the skew window
+	ErrReplayed = errors.New("request signature already used")
+	// ErrUnavailable is returned when the nonce store cannot be reached, so
+	// a replay cannot be ruled out
+	ErrUnavailable = errors.New("request signature check unavailable")
+)
+
+// Request holds the signed parts of an HTTP request
+type Request struct {
+	ClientID      string
+	Timestamp     string // Unix seconds
+	Nonce         string
+	Signature     string // hex-encoded HMAC-SHA256
+	Method        string
+	URI           string // path and query as sent
+	Authorization string // header as sent, empty if absent
+	Body          []byte
+}
+
+// StringToSign builds the canonical string covered by the signature:
+// method, URI, timestamp, nonce and the hex SHA-256 of the Authorization
+// header and of the body, one per line. Covering the header ties the
+// signature to the bearer token, so a request cannot be resent with
+// another one.
+func StringToSign(method, uri, timestamp, nonce, authorization string, body []byte) string {
+	authorizationHash := sha256.Sum256([]byte(authorization))
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		uri,
+		timestamp,
+		nonce,
+		hex.EncodeToString(authorizationHash[:]),
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// Sign returns the hex-encoded signature of a request for the given key
+func Sign(key []byte, method, uri, timestamp, nonce, authorization string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(StringToSign(method, uri, timestamp, nonce, authorization, body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NonceStore remembers nonces shared by several verifiers, e.g. one per
+// server instance, so a request replayed to another instance is rejected
+type NonceStore interface {
+	// Use records nonce for ttl, reporting false if it is already recorded
+	Use(nonce string, ttl time.Duration) (bool, error)
+}
+
+// Verifier checks request signatures against per-client keys and remembers
+// nonces for the skew window to reject replays
+type Verifier struct {
+	keys    map[string][]byte
+	maxSkew time.Duration
+	now     func() time.Time
+	store   NonceStore
+
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	expiries  []usedNonce // in the order nonces expire
+	maxNonces int
+}
+
+// usedNonce is a remembered nonce and when it may be forgotten
+type usedNonce struct {
+	nonce   string
+	expires time.Time
+}
+
+// NewVerifier creates a verifier for the given client ID to key mapping.
+// A non-positive maxSkew uses DefaultMaxSkew.
+func NewVerifier(keys map[string]string, maxSkew time.Duration) *Verifier {
+	if maxSkew <= 0 {
+		maxSkew = DefaultMaxSkew
+	}
+	byteKeys := make(map[string][]byte, len(keys))
+	for clientID, key := range keys {
+		byteKeys[clientID] = []byte(key)
+	}
+	return &Verifier{
+		keys:      byteKeys,
+		maxSkew:   maxSkew,
+		now:       time.Now,
+		nonces:    make(map[string]time.Time),
+		maxNonces: MaxNonces,
+	}
+}
+
+// SetNonceStore remembers nonces in store instead of in memory
+func (v *Verifier) SetNonceStore(store NonceStore) {
+	v.store = store
+}
+
+// Verify checks a request's signature, timestamp and nonce
+func (v *Verifier) Verify(req Request) error {
+	if req.ClientID == "" || req.Timestamp == "" || req.Nonce == "" || req.Signature == "" {
+		return ErrMissing
+	}
+
+	key, ok := v.keys[req.ClientID]
+	if !ok {
+		return ErrUnknownClient
+	}
+
+	seconds, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return ErrExpired
+	}
+	now := v.now()
+	signedAt := time.Unix(seconds, 0)
+	if signedAt.Before(now.Add(-v.maxSkew)) || signedAt.After(now.Add(v.maxSkew)) {
+		return ErrExpired
+	}
+
+	expected := Sign(key, req.Method, req.URI, req.Timestamp, req.Nonce, req.Authorization, req.Body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(req.Signature))) {
+		return ErrInvalid
+	}
+
+	return v.useNonce(req.ClientID+"\n"+req.Nonce, now)
+}
+
+// useNonce records a nonce, failing if it was already seen within the window.
+// Expired nonces are dropped on the way, from the front of the expiry queue,
+// so each request only touches the nonces that expired since the last one;
+// older requests fail the timestamp check.
+func (v *Verifier) useNonce(nonce string, now time.Time) error {
+	// A nonce must outlive any timestamp that could still be accepted
+	ttl := 2 * v.maxSkew
+	if v.store != nil {
+		fresh, err := v.store.Use(nonce, ttl)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnavailable, err)
+		}
+		if !fresh {
+			return ErrReplayed
+		}
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Every nonce lives for ttl, so the queue is in expiry order as long as
+	// the clock does not go back
+	for len(v.expiries) > 0 && now.After(v.expiries[0].expires) {
+		delete(v.nonces, v.expiries[0].nonce)
+		v.expiries = v.expiries[1:]
+	}
+
+	if _, used := v.nonces[nonce]; used {
+		return ErrReplayed
+	}
+	if len(v.nonces) >= v.maxNonces {
+		return fmt.Errorf("%w: %d nonces in use", ErrUnavailable, len(v.nonces))
+	}
+	expires := now.Add(ttl)
+	v.nonces[nonce] = expires
+	v.expiries = append(v.expiries, usedNonce{nonce: nonce, expires: expires})
+	return nil
+}
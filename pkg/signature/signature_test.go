@@ -0,0 +1,173 @@
+package signature
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestVerifier(now time.Time) *Verifier {
+	v := NewVerifier(map[string]string{"mobile": "mobile-secret"}, time.Minute)
+	v.now = func() time.Time { return now }
+	return v
+}
+
+func signedRequest(now time.Time, nonce string) Request {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"role":"admin"}`)
+	return Request{
+		ClientID:      "mobile",
+		Timestamp:     timestamp,
+		Nonce:         nonce,
+		Signature:     Sign([]byte("mobile-secret"), "POST", "/admin/users/bulk/role", timestamp, nonce, "Bearer ops-token", body),
+		Method:        "POST",
+		URI:           "/admin/users/bulk/role",
+		Authorization: "Bearer ops-token",
+		Body:          body,
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		modify func(r *Request)
+		want   error
+	}{
+		{"valid", func(r *Request) {}, nil},
+		{"method is case-insensitive", func(r *Request) { r.Method = "post" }, nil},
+		{"missing signature", func(r *Request) { r.Signature = "" }, ErrMissing},
+		{"missing nonce", func(r *Request) { r.Nonce = "" }, ErrMissing},
+		{"unknown client", func(r *Request) { r.ClientID = "web" }, ErrUnknownClient},
+		{"tampered body", func(r *Request) { r.Body = []byte(`{"role":"user"}`) }, ErrInvalid},
+		{"tampered path", func(r *Request) { r.URI = "/admin/users/bulk/suspend" }, ErrInvalid},
+		{"swapped token", func(r *Request) { r.Authorization = "Bearer stolen-token" }, ErrInvalid},
+		{"removed token", func(r *Request) { r.Authorization = "" }, ErrInvalid},
+		{"malformed timestamp", func(r *Request) { r.Timestamp = "yesterday" }, ErrExpired},
+		{"old timestamp", func(r *Request) {
+			*r = signedRequest(now.Add(-2*time.Minute), "n1")
+		}, ErrExpired},
+		{"future timestamp", func(r *Request) {
+			*r = signedRequest(now.Add(2*time.Minute), "n1")
+		}, ErrExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(now)
+			req := signedRequest(now, "n1")
+			tt.modify(&req)
+			if err := v.Verify(req); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifier_RejectsReplay(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	v := newTestVerifier(now)
+
+	req := signedRequest(now, "once")
+	if err := v.Verify(req); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := v.Verify(req); !errors.Is(err, ErrReplayed) {
+		t.Errorf("replayed Verify() error = %v, want ErrReplayed", err)
+	}
+
+	// A fresh nonce is accepted
+	if err := v.Verify(signedRequest(now, "twice")); err != nil {
+		t.Errorf("Verify() with new nonce error = %v", err)
+	}
+}
+
+func TestVerifier_ForgetsExpiredNonces(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	v := newTestVerifier(now)
+
+	if err := v.Verify(signedRequest(now, "old")); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	v.now = func() time.Time { return now.Add(5 * time.Minute) }
+	if err := v.Verify(signedRequest(now.Add(5*time.Minute), "new")); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if len(v.nonces) != 1 || len(v.expiries) != 1 {
+		t.Errorf("nonces = %d queued %d, want expired nonce dropped", len(v.nonces), len(v.expiries))
+	}
+}
+
+func TestVerifier_LimitsNonces(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	v := newTestVerifier(now)
+	v.maxNonces = 2
+
+	for _, nonce := range []string{"a", "b"} {
+		if err := v.Verify(signedRequest(now, nonce)); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+	}
+	// A full verifier refuses new nonces rather than forgetting used ones
+	if err := v.Verify(signedRequest(now, "c")); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Verify() with a full nonce store error = %v, want ErrUnavailable", err)
+	}
+	if err := v.Verify(signedRequest(now, "a")); !errors.Is(err, ErrReplayed) {
+		t.Errorf("Verify() replayed with a full nonce store error = %v, want ErrReplayed", err)
+	}
+
+	// and accepts them again once the old ones expire
+	later := now.Add(3 * time.Minute)
+	v.now = func() time.Time { return later }
+	if err := v.Verify(signedRequest(later, "c")); err != nil {
+		t.Errorf("Verify() after nonces expired error = %v", err)
+	}
+}
+
+// mapNonces is a NonceStore shared by verifiers standing in for instances
+type mapNonces struct {
+	used map[string]time.Duration
+	err  error
+}
+
+func (m *mapNonces) Use(nonce string, ttl time.Duration) (bool, error) {
+	if m.err != nil {
+		return false, m.err
+	}
+	if _, ok := m.used[nonce]; ok {
+		return false, nil
+	}
+	m.used[nonce] = ttl
+	return true, nil
+}
+
+func TestVerifier_NonceStore(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &mapNonces{used: make(map[string]time.Duration)}
+	first, second := newTestVerifier(now), newTestVerifier(now)
+	first.SetNonceStore(store)
+	second.SetNonceStore(store)
+
+	req := signedRequest(now, "once")
+	if err := first.Verify(req); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	// A request replayed to another instance is rejected too
+	if err := second.Verify(req); !errors.Is(err, ErrReplayed) {
+		t.Errorf("Verify() replayed to another verifier error = %v, want ErrReplayed", err)
+	}
+	if ttl := store.used["mobile\nonce"]; ttl != 2*time.Minute {
+		t.Errorf("nonce kept for %v, want twice the skew", ttl)
+	}
+	if len(first.nonces) != 0 {
+		t.Errorf("nonces in memory = %d, want none with a store", len(first.nonces))
+	}
+
+	store.err = errors.New("connection refused")
+	if err := first.Verify(signedRequest(now, "twice")); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Verify() without the store error = %v, want ErrUnavailable", err)
+	}
+}
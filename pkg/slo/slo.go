@@ -0,0 +1,378 @@
+// Package slo tracks routes against service level objectives: the share of
+// requests that must succeed, optionally within a latency threshold. It
+// reports the error budget left in a rolling window and how fast it is being
+// spent, and can shed load while a budget is exhausted.
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInvalidObjective is returned for an objective that cannot be tracked
+var ErrInvalidObjective = errors.New("invalid objective")
+
+// BurnRateWindows are the lookbacks burn rates are reported over: a fast
+// one that reacts to outages and a slow one that ignores blips. Lookbacks
+// longer than the tracker's window are not reported.
+var BurnRateWindows = []time.Duration{5 * time.Minute, time.Hour}
+
+// MinRequests is the number of requests a route needs in the window before
+// its budget can count as exhausted, so one failure on a quiet route does
+// not shed load
+const MinRequests = 100
+
+// bucketWidth is the resolution of the request counts
+const bucketWidth = time.Minute
+
+// Objective is the share of a route's requests that must be good: answered
+// without a 5xx status and, when Latency is set, within Latency
+type Objective struct {
+	Method string
+	// Path is the route; segments starting with ":" match any segment
+	Path string
+	// Target is the share of good requests, above 0 and below 1
+	Target  float64
+	Latency time.Duration
+}
+
+// ParseObjective parses an objective from a route such as "POST /login" and
+// a spec such as "99.9%" or "99.9% 300ms"
+func ParseObjective(route, spec string) (Objective, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+	if !ok {
+		return Objective{}, fmt.Errorf("%w: route %q must be a method and a path", ErrInvalidObjective, route)
+	}
+	objective := Objective{Method: strings.ToUpper(method), Path: strings.TrimSpace(path)}
+
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Objective{}, fmt.Errorf("%w: %s: spec %q must be a target and an optional latency, e.g. 99.9%% 300ms", ErrInvalidObjective, route, spec)
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "%"), 64)
+	if err != nil {
+		return Objective{}, fmt.Errorf("%w: %s: target %q is not a percentage", ErrInvalidObjective, route, fields[0])
+	}
+	objective.Target = round(percent / 100)
+	if len(fields) == 2 {
+		if objective.Latency, err = time.ParseDuration(fields[1]); err != nil {
+			return Objective{}, fmt.Errorf("%w: %s: latency %q is not a duration", ErrInvalidObjective, route, fields[1])
+		}
+	}
+	return objective, objective.Validate()
+}
+
+// Validate checks that the objective names a route and leaves an error budget
+func (o Objective) Validate() error {
+	switch {
+	case o.Method == "":
+		return fmt.Errorf("%w: method is required", ErrInvalidObjective)
+	case !strings.HasPrefix(o.Path, "/"):
+		return fmt.Errorf("%w: %s: path must start with /", ErrInvalidObjective, o.Route())
+	case o.Target <= 0 || o.Target >= 1:
+		return fmt.Errorf("%w: %s: target must be above 0%% and below 100%%", ErrInvalidObjective, o.Route())
+	case o.Latency < 0:
+		return fmt.Errorf("%w: %s: latency must not be negative", ErrInvalidObjective, o.Route())
+	}
+	return nil
+}
+
+// Route names the objective's route, e.g. "POST /login"
+func (o Objective) Route() string {
+	return o.Method + " " + o.Path
+}
+
+// Matches reports whether a request is for the objective's route
+func (o Objective) Matches(method, path string) bool {
+	if !strings.EqualFold(o.Method, method) {
+		return false
+	}
+	want := strings.Split(strings.Trim(o.Path, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i, segment := range want {
+		if segment != got[i] && !(strings.HasPrefix(segment, ":") && got[i] != "") {
+			return false
+		}
+	}
+	return true
+}
+
+// bucket counts a minute of a route's requests
+type bucket struct {
+	minute int64
+	total  int64
+	bad    int64
+	errors int64
+}
+
+// BurnRate is how fast the error budget is being spent over a lookback: 1
+// spends exactly the budget over the window, 10 spends it in a tenth of it
+type BurnRate struct {
+	Window time.Duration
+	Rate   float64
+}
+
+// ObjectiveReport is an objective's state over the window
+type ObjectiveReport struct {
+	Objective Objective
+	Requests  int64
+	// Errors are requests answered with a 5xx status
+	Errors int64
+	// Slow are requests answered without an error but over the latency
+	Slow int64
+	// Compliance is the share of good requests; 1 without requests
+	Compliance float64
+	// BudgetRemaining is the share of the error budget left; it is
+	// negative once more of the budget was spent than allowed
+	BudgetRemaining float64
+	BurnRates       []BurnRate
+	// Exhausted is set once the budget is spent on at least MinRequests
+	Exhausted bool
+}
+
+// LoadShedding is the state of load shedding
+type LoadShedding struct {
+	// Enabled allows shedding when a budget is exhausted
+	Enabled bool
+	// Active is set while requests are shed
+	Active bool
+	Since  time.Time
+}
+
+// Report is every objective's state with the load shedding state
+type Report struct {
+	Window       time.Duration
+	Objectives   []ObjectiveReport
+	LoadShedding LoadShedding
+}
+
+// Tracker counts the requests of each objective's route in per-minute
+// buckets over a rolling window
+type Tracker struct {
+	objectives []Objective
+	window     time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	buckets [][]bucket
+
+	shedEnabled atomic.Bool
+	shedding    atomic.Bool
+	shedSince   time.Time
+}
+
+// New creates a tracker for objectives over window
+func New(objectives []Objective, window time.Duration) (*Tracker, error) {
+	if window < bucketWidth {
+		return nil, fmt.Errorf("%w: window must be at least %s", ErrInvalidObjective, bucketWidth)
+	}
+	buckets := make([][]bucket, len(objectives))
+	for i, objective := range objectives {
+		if err := objective.Validate(); err != nil {
+			return nil, err
+		}
+		buckets[i] = make([]bucket, window/bucketWidth)
+	}
+	return &Tracker{
+		objectives: append([]Objective(nil), objectives...),
+		window:     window,
+		now:        time.Now,
+		buckets:    buckets,
+	}, nil
+}
+
+// Match returns the index of the first objective for a request's route
+func (t *Tracker) Match(method, path string) (int, bool) {
+	for i, objective := range t.objectives {
+		if objective.Matches(method, path) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Record counts a request for the objective at index, as returned by Match
+func (t *Tracker) Record(index, status int, duration time.Duration) {
+	objective := t.objectives[index]
+	failed := status >= 500
+	slow := !failed && objective.Latency > 0 && duration > objective.Latency
+
+	minute := t.now().Unix() / int64(bucketWidth/time.Second)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := &t.buckets[index][minute%int64(len(t.buckets[index]))]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	b.total++
+	if failed {
+		b.errors++
+	}
+	if failed || slow {
+		b.bad++
+	}
+}
+
+// sum adds up the objective's buckets over the last lookback
+func (t *Tracker) sum(index int, lookback time.Duration) bucket {
+	minute := t.now().Unix() / int64(bucketWidth/time.Second)
+	oldest := minute - int64(lookback/bucketWidth) + 1
+	var total bucket
+	for _, b := range t.buckets[index] {
+		if b.minute >= oldest && b.minute <= minute {
+			total.total += b.total
+			total.bad += b.bad
+			total.errors += b.errors
+		}
+	}
+	return total
+}
+
+// Report computes every objective's state over the window
+func (t *Tracker) Report() *Report {
+	report := &Report{Window: t.window, Objectives: make([]ObjectiveReport, len(t.objectives))}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, objective := range t.objectives {
+		counts := t.sum(i, t.window)
+		budget := 1 - objective.Target
+		objectiveReport := ObjectiveReport{
+			Objective:       objective,
+			Requests:        counts.total,
+			Errors:          counts.errors,
+			Slow:            counts.bad - counts.errors,
+			Compliance:      1,
+			BudgetRemaining: 1,
+			BurnRates:       []BurnRate{},
+		}
+		if counts.total > 0 {
+			badShare := float64(counts.bad) / float64(counts.total)
+			objectiveReport.Compliance = round(1 - badShare)
+			objectiveReport.BudgetRemaining = round(1 - badShare/budget)
+		}
+		objectiveReport.Exhausted = counts.total >= MinRequests && objectiveReport.BudgetRemaining <= 0
+
+		for _, lookback := range BurnRateWindows {
+			if lookback > t.window {
+				continue
+			}
+			rate := BurnRate{Window: lookback}
+			if recent := t.sum(i, lookback); recent.total > 0 {
+				rate.Rate = round(float64(recent.bad) / float64(recent.total) / budget)
+			}
+			objectiveReport.BurnRates = append(objectiveReport.BurnRates, rate)
+		}
+		report.Objectives[i] = objectiveReport
+	}
+
+	report.LoadShedding = LoadShedding{
+		Enabled: t.shedEnabled.Load(),
+		Active:  t.shedding.Load(),
+		Since:   t.shedSince,
+	}
+	return report
+}
+
+// round drops the float error of targets such as 0.999, so a budget spent
+// exactly reads 0 rather than a tiny positive share
+func round(value float64) float64 {
+	return math.Round(value*1e9) / 1e9
+}
+
+// SetLoadShedding allows or stops shedding load while a budget is exhausted
+func (t *Tracker) SetLoadShedding(enabled bool) {
+	t.shedEnabled.Store(enabled)
+	t.Evaluate()
+}
+
+// Shedding reports whether requests are being shed
+func (t *Tracker) Shedding() bool {
+	return t.shedding.Load()
+}
+
+// Evaluate starts shedding load when it is enabled and a route with an
+// exhausted budget is still spending it faster than allowed over the
+// shortest burn rate window, and stops once none is. It returns whether load
+// is being shed.
+func (t *Tracker) Evaluate() bool {
+	exhausted := false
+	if t.shedEnabled.Load() {
+		for _, objective := range t.Report().Objectives {
+			burning := len(objective.BurnRates) == 0 || objective.BurnRates[0].Rate > 1
+			exhausted = exhausted || (objective.Exhausted && burning)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if exhausted != t.shedding.Load() {
+		t.shedding.Store(exhausted)
+		t.shedSince = time.Time{}
+		if exhausted {
+			t.shedSince = t.now().UTC()
+		}
+	}
+	return exhausted
+}
+
+// FormatWindow formats a burn rate window without zero units, e.g. "5m" or "1h"
+func FormatWindow(window time.Duration) string {
+	formatted := window.String()
+	for _, zero := range []string{"0s", "0m"} {
+		if trimmed := strings.TrimSuffix(formatted, zero); trimmed != "" && trimmed != formatted {
+			formatted = trimmed
+		}
+	}
+	return formatted
+}
+
+// WritePrometheus writes the report in the Prometheus text exposition
+// format, for burn rate alerts
+func (r *Report) WritePrometheus(w io.Writer) error {
+	var err error
+	write := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+	write("# HELP api_slo_target Share of requests that must be good.\n# TYPE api_slo_target gauge\n")
+	for _, o := range r.Objectives {
+		write("api_slo_target{route=%q} %g\n", o.Objective.Route(), o.Objective.Target)
+	}
+	write("# HELP api_slo_requests Requests in the SLO window.\n# TYPE api_slo_requests gauge\n")
+	for _, o := range r.Objectives {
+		write("api_slo_requests{route=%q} %d\n", o.Objective.Route(), o.Requests)
+	}
+	write("# HELP api_slo_bad_requests Failed or slow requests in the SLO window.\n# TYPE api_slo_bad_requests gauge\n")
+	for _, o := range r.Objectives {
+		write("api_slo_bad_requests{route=%q} %d\n", o.Objective.Route(), o.Errors+o.Slow)
+	}
+	write("# HELP api_slo_error_budget_remaining Share of the error budget left in the SLO window.\n# TYPE api_slo_error_budget_remaining gauge\n")
+	for _, o := range r.Objectives {
+		write("api_slo_error_budget_remaining{route=%q} %g\n", o.Objective.Route(), o.BudgetRemaining)
+	}
+	write("# HELP api_slo_burn_rate Error budget spent over a lookback relative to the allowed rate.\n# TYPE api_slo_burn_rate gauge\n")
+	for _, o := range r.Objectives {
+		for _, rate := range o.BurnRates {
+			write("api_slo_burn_rate{route=%q,window=%q} %g\n", o.Objective.Route(), FormatWindow(rate.Window), rate.Rate)
+		}
+	}
+	shedding := 0
+	if r.LoadShedding.Active {
+		shedding = 1
+	}
+	write("# HELP api_slo_load_shedding Whether requests are shed while an error budget is exhausted.\n# TYPE api_slo_load_shedding gauge\n")
+	write("api_slo_load_shedding %d\n", shedding)
+	return err
+}
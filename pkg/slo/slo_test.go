@@ -0,0 +1,194 @@
+package slo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseObjective(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   string
+		spec    string
+		want    Objective
+		wantErr bool
+	}{
+		{"target", "POST /login", "99.9%", Objective{Method: "POST", Path: "/login", Target: 0.999}, false},
+		{"target and latency", "get /me", "99.5 300ms", Objective{Method: "GET", Path: "/me", Target: 0.995, Latency: 300 * time.Millisecond}, false},
+		{"no method", "/login", "99%", Objective{}, true},
+		{"relative path", "GET me", "99%", Objective{}, true},
+		{"not a percentage", "GET /me", "most", Objective{}, true},
+		{"no budget", "GET /me", "100%", Objective{}, true},
+		{"bad latency", "GET /me", "99% fast", Objective{}, true},
+		{"too many fields", "GET /me", "99% 1s 2s", Objective{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseObjective(tt.route, tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseObjective() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidObjective) {
+					t.Errorf("ParseObjective() error = %v, want ErrInvalidObjective", err)
+				}
+				return
+			}
+			if got.Method != tt.want.Method || got.Path != tt.want.Path || got.Latency != tt.want.Latency ||
+				got.Target < tt.want.Target-1e-9 || got.Target > tt.want.Target+1e-9 {
+				t.Errorf("ParseObjective() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObjective_Matches(t *testing.T) {
+	objective := Objective{Method: "DELETE", Path: "/me/share-links/:id"}
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{"DELETE", "/me/share-links/12", true},
+		{"delete", "/me/share-links/12/", true},
+		{"GET", "/me/share-links/12", false},
+		{"DELETE", "/me/share-links", false},
+		{"DELETE", "/me/share-links/12/accesses", false},
+	}
+	for _, tt := range tests {
+		if got := objective.Matches(tt.method, tt.path); got != tt.want {
+			t.Errorf("Matches(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+// newTestTracker creates a tracker for POST /login at 99% within 100ms over
+// an hour, on a clock the test advances
+func newTestTracker(t *testing.T) (*Tracker, *time.Time) {
+	t.Helper()
+	tracker, err := New([]Objective{{Method: "POST", Path: "/login", Target: 0.99, Latency: 100 * time.Millisecond}}, time.Hour)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+func TestTracker_Report(t *testing.T) {
+	tracker, now := newTestTracker(t)
+	if _, ok := tracker.Match("GET", "/me"); ok {
+		t.Fatal("Match(GET /me) matched an objective")
+	}
+	index, ok := tracker.Match("POST", "/login")
+	if !ok {
+		t.Fatal("Match(POST /login) matched no objective")
+	}
+
+	report := tracker.Report()
+	if got := report.Objectives[0]; got.Requests != 0 || got.Compliance != 1 || got.BudgetRemaining != 1 || got.Exhausted {
+		t.Errorf("Report() without requests = %+v", got)
+	}
+
+	// An hour ago: 1 error in 100; these leave the 5m burn rate window
+	for i := 0; i < 99; i++ {
+		tracker.Record(index, 200, time.Millisecond)
+	}
+	tracker.Record(index, 503, time.Millisecond)
+	*now = now.Add(30 * time.Minute)
+	// Now: 1 slow request in 100; 4xx responses are good
+	for i := 0; i < 98; i++ {
+		tracker.Record(index, 200, time.Millisecond)
+	}
+	tracker.Record(index, 404, time.Millisecond)
+	tracker.Record(index, 200, time.Second)
+
+	got := tracker.Report().Objectives[0]
+	if got.Requests != 200 || got.Errors != 1 || got.Slow != 1 || got.Compliance != 0.99 {
+		t.Errorf("Report() = %+v, want 200 requests, 1 error and 1 slow", got)
+	}
+	// 2 bad requests are 1% of 200, the whole budget
+	if got.BudgetRemaining > 1e-9 || !got.Exhausted {
+		t.Errorf("Report() budget remaining = %g, exhausted %v; want 0, true", got.BudgetRemaining, got.Exhausted)
+	}
+	if len(got.BurnRates) != 2 || got.BurnRates[0].Window != 5*time.Minute || got.BurnRates[0].Rate < 0.999 || got.BurnRates[0].Rate > 1.001 {
+		t.Errorf("Report() burn rates = %+v, want 1 over 5m", got.BurnRates)
+	}
+
+	// Requests older than the window are forgotten
+	*now = now.Add(time.Hour)
+	if got := tracker.Report().Objectives[0]; got.Requests != 0 {
+		t.Errorf("Report() after the window = %+v, want no requests", got)
+	}
+}
+
+func TestTracker_LoadShedding(t *testing.T) {
+	tracker, now := newTestTracker(t)
+	index, _ := tracker.Match("POST", "/login")
+	for i := 0; i < MinRequests; i++ {
+		tracker.Record(index, 500, time.Millisecond)
+	}
+
+	// Shedding is off until enabled, even with the budget exhausted
+	if tracker.Evaluate() || tracker.Shedding() {
+		t.Fatal("Evaluate() shed load without being enabled")
+	}
+	tracker.SetLoadShedding(true)
+	if !tracker.Shedding() {
+		t.Fatal("Shedding() = false with an exhausted budget")
+	}
+	if state := tracker.Report().LoadShedding; !state.Enabled || !state.Active || !state.Since.Equal(*now) {
+		t.Errorf("Report().LoadShedding = %+v", state)
+	}
+
+	// Once the route stops failing, shedding stops though the budget is spent
+	*now = now.Add(10 * time.Minute)
+	tracker.Record(index, 200, time.Millisecond)
+	if tracker.Evaluate() {
+		t.Error("Evaluate() = true after the burn rate fell")
+	}
+	if !tracker.Report().Objectives[0].Exhausted {
+		t.Error("budget recovered within the window")
+	}
+
+	tracker.SetLoadShedding(false)
+	if state := tracker.Report().LoadShedding; state.Enabled || state.Active || !state.Since.IsZero() {
+		t.Errorf("Report().LoadShedding after disabling = %+v", state)
+	}
+}
+
+func TestReport_WritePrometheus(t *testing.T) {
+	tracker, _ := newTestTracker(t)
+	index, _ := tracker.Match("POST", "/login")
+	tracker.Record(index, 200, time.Millisecond)
+	tracker.Record(index, 500, time.Millisecond)
+
+	var out strings.Builder
+	if err := tracker.Report().WritePrometheus(&out); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	for _, want := range []string{
+		`api_slo_target{route="POST /login"} 0.99` + "\n",
+		`api_slo_requests{route="POST /login"} 2` + "\n",
+		`api_slo_bad_requests{route="POST /login"} 1` + "\n",
+		`api_slo_error_budget_remaining{route="POST /login"} -49` + "\n",
+		`api_slo_burn_rate{route="POST /login",window="5m"} 50` + "\n",
+		`api_slo_burn_rate{route="POST /login",window="1h"} 50` + "\n",
+		"api_slo_load_shedding 0\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WritePrometheus() output is missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := New(nil, time.Second); !errors.Is(err, ErrInvalidObjective) {
+		t.Errorf("New() with a window under a minute error = %v, want ErrInvalidObjective", err)
+	}
+	if _, err := New([]Objective{{Method: "GET", Path: "/", Target: 1}}, time.Hour); !errors.Is(err, ErrInvalidObjective) {
+		t.Errorf("New() with a 100%% target error = %v, want ErrInvalidObjective", err)
+	}
+}
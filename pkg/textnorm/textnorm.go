@@ -0,0 +1,52 @@
+// Package textnorm puts user-supplied text in one canonical form, so strings
+// that look the same compare equal and invisible characters cannot be used
+// to spoof them.
+package textnorm
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Text returns s in NFC without invisible characters and surrounding space.
+// Use it for free text such as names, where compatibility characters like
+// "ﬁ" or "²" are kept as typed.
+func Text(s string) string {
+	return strings.TrimSpace(norm.NFC.String(stripInvisible(s)))
+}
+
+// Identifier returns s in NFKC without invisible characters and
+// surrounding space. Use it for values compared by machines, such as email
+// addresses and phone numbers, where fullwidth "０８１" must equal "081".
+func Identifier(s string) string {
+	return strings.TrimSpace(norm.NFKC.String(stripInvisible(s)))
+}
+
+// IsInvisible reports whether r is a zero-width or bidi control character
+func IsInvisible(r rune) bool {
+	switch {
+	case r == '\u00ad', // soft hyphen
+		r == '\u061c',                  // Arabic letter mark
+		r >= '\u200b' && r <= '\u200f', // zero-width space, joiners and direction marks
+		r >= '\u202a' && r <= '\u202e', // bidi embeddings and overrides
+		r >= '\u2060' && r <= '\u2064', // word joiner and invisible operators
+		r >= '\u2066' && r <= '\u2069', // bidi isolates
+		r == '\ufeff':                  // zero-width no-break space
+		return true
+	}
+	return false
+}
+
+// stripInvisible removes the characters IsInvisible reports
+func stripInvisible(s string) string {
+	if strings.IndexFunc(s, IsInvisible) < 0 {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if IsInvisible(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
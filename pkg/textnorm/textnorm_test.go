@@ -0,0 +1,91 @@
+package textnorm
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"unchanged", "Somchai Jaidee", "Somchai Jaidee"},
+		{"decomposed accents are composed", "Jose\u0301", "Jos\u00e9"},
+		{"zero-width characters are stripped", "Jo\u200bhn\u200d Do\ufeffe", "John Doe"},
+		{"bidi controls are stripped", "\u202eeoD nhoJ\u202c", "eoD nhoJ"},
+		{"surrounding space is trimmed", "  Jane Doe\n", "Jane Doe"},
+		{"compatibility characters are kept", "ﬁona²", "ﬁona²"},
+		{"thai", "สมชาย ใจดี", "สมชาย ใจดี"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Text(tt.input); got != tt.want {
+				t.Errorf("Text(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"user@example.com", "user@example.com"},
+		{"ｕｓｅｒ＠ｅｘａｍｐｌｅ．ｃｏｍ", "user@example.com"},
+		{"０８１２３４５６７８", "0812345678"},
+		{"us\u200ber@example.com ", "user@example.com"},
+	}
+	for _, tt := range tests {
+		if got := Identifier(tt.input); got != tt.want {
+			t.Errorf("Identifier(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// FuzzIdentifier and FuzzText check that normalized values keep no invisible
+// characters or surrounding space and do not change when normalized again.
+// Run with go test ./pkg/textnorm -fuzz FuzzIdentifier (or FuzzText)
+func FuzzIdentifier(f *testing.F) {
+	fuzzNormalizer(f, Identifier)
+}
+
+func FuzzText(f *testing.F) {
+	fuzzNormalizer(f, Text)
+}
+
+func fuzzNormalizer(f *testing.F, normalize func(string) string) {
+	for _, seed := range []string{
+		"user@example.com",
+		"ｕｓｅｒ＠ｅｘａｍｐｌｅ．ｃｏｍ",
+		"０８１２３４５６７８",
+		"us\u200ber@example.com ",
+		"\u202eeoD nhoJ\u202c",
+		"Jose\u0301",
+		"ﬁona²",
+		"\u00a0\u3000x\u00a0",
+		"\xff\xfe",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		got := normalize(s)
+		if strings.IndexFunc(got, IsInvisible) >= 0 {
+			t.Fatalf("normalize(%q) = %q keeps invisible characters", s, got)
+		}
+		if strings.TrimFunc(got, unicode.IsSpace) != got {
+			t.Fatalf("normalize(%q) = %q keeps surrounding space", s, got)
+		}
+		if utf8.ValidString(s) && !utf8.ValidString(got) {
+			t.Fatalf("normalize(%q) = %q is not valid UTF-8", s, got)
+		}
+		if again := normalize(got); again != got {
+			t.Fatalf("normalize(%q) = %q, but normalizing again gives %q", s, got, again)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+// Package timing pads operations to a minimum duration, so how long a
+// response takes does not reveal which path produced it, e.g. whether a login
+// named a registered email or failed before checking a password.
+package timing
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Floor is the minimum duration of an operation. A nil Floor pads nothing.
+type Floor struct {
+	min    time.Duration
+	jitter time.Duration
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+// New creates a floor of min plus a random part of jitter, drawn for each
+// operation so the floor itself cannot be measured and subtracted exactly.
+// It returns nil when min is not positive.
+func New(min, jitter time.Duration) *Floor {
+	if min <= 0 {
+		return nil
+	}
+	return &Floor{min: min, jitter: max(jitter, 0), now: time.Now, sleep: time.Sleep}
+}
+
+// SetClock replaces the clock and the sleep Pad uses, e.g. in tests with a
+// fake clock that sleep advances
+func (f *Floor) SetClock(now func() time.Time, sleep func(time.Duration)) {
+	f.now, f.sleep = now, sleep
+}
+
+// Now returns the time on the floor's clock, when an operation Pad is
+// deferred for starts. A nil Floor returns the zero time without reading
+// the clock.
+func (f *Floor) Now() time.Time {
+	if f == nil {
+		return time.Time{}
+	}
+	return f.now()
+}
+
+// Min returns the shortest duration of a padded operation
+func (f *Floor) Min() time.Duration {
+	if f == nil {
+		return 0
+	}
+	return f.min
+}
+
+// Jitter returns the most a padded operation may take beyond Min, unless
+// the operation itself takes longer
+func (f *Floor) Jitter() time.Duration {
+	if f == nil {
+		return 0
+	}
+	return f.jitter
+}
+
+// target draws the duration of one operation
+func (f *Floor) target() time.Duration {
+	if f.jitter == 0 {
+		return f.min
+	}
+	return f.min + rand.N(f.jitter)
+}
+
+// Pad sleeps until the operation started at start has taken its floor.
+// Operations already past it return at once, so the floor should exceed
+// the slowest path's usual duration. Meant to be deferred:
+//
+//	defer floor.Pad(floor.Now())
+func (f *Floor) Pad(start time.Time) {
+	if f == nil {
+		return
+	}
+	if remaining := f.target() - f.now().Sub(start); remaining > 0 {
+		f.sleep(remaining)
+	}
+}
@@ -0,0 +1,81 @@
+package timing
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when advanced, by work or by sleep
+type fakeClock struct {
+	now   time.Time
+	slept []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.slept = append(c.slept, d)
+	c.now = c.now.Add(d)
+}
+
+func TestFloor_Pad(t *testing.T) {
+	floor := New(100*time.Millisecond, 20*time.Millisecond)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	floor.SetClock(clock.Now, clock.Sleep)
+
+	for range 200 {
+		floor.Pad(floor.Now())
+	}
+	lowest, highest := clock.slept[0], clock.slept[0]
+	for _, d := range clock.slept {
+		lowest, highest = min(lowest, d), max(highest, d)
+	}
+	if lowest < 100*time.Millisecond || highest >= 120*time.Millisecond {
+		t.Errorf("Pad() slept between %v and %v, want 100ms to 120ms", lowest, highest)
+	}
+	if highest-lowest < 5*time.Millisecond {
+		t.Errorf("Pad() slept between %v and %v, want jitter", lowest, highest)
+	}
+
+	// An operation past its floor is not delayed further
+	clock.slept = nil
+	floor.Pad(floor.Now().Add(-time.Second))
+	if len(clock.slept) != 0 {
+		t.Errorf("Pad() after the floor slept %v", clock.slept)
+	}
+}
+
+func TestFloor_EqualizesDurations(t *testing.T) {
+	floor := New(40*time.Millisecond, 0)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	floor.SetClock(clock.Now, clock.Sleep)
+	measure := func(work time.Duration) time.Duration {
+		start := clock.Now()
+		func() {
+			defer floor.Pad(floor.Now())
+			clock.now = clock.now.Add(work)
+		}()
+		return clock.Now().Sub(start)
+	}
+
+	// A fast and a slow path take the same time once padded
+	if fast, slow := measure(0), measure(15*time.Millisecond); fast != 40*time.Millisecond || slow != 40*time.Millisecond {
+		t.Errorf("durations %v and %v, want 40ms", fast, slow)
+	}
+	// and a path past the floor is not padded
+	if slowest := measure(50 * time.Millisecond); slowest != 50*time.Millisecond {
+		t.Errorf("duration past the floor %v, want 50ms", slowest)
+	}
+}
+
+func TestNew_Disabled(t *testing.T) {
+	floor := New(0, time.Second)
+	if floor != nil {
+		t.Fatalf("New(0) = %+v, want nil", floor)
+	}
+	start := time.Now()
+	floor.Pad(floor.Now())
+	if floor.Min() != 0 || floor.Jitter() != 0 || !floor.Now().IsZero() || time.Since(start) > 10*time.Millisecond {
+		t.Error("a nil floor should pad nothing")
+	}
+}
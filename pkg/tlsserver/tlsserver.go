@@ -0,0 +1,65 @@
+// Package tlsserver provides the server's TLS defaults, ACME (Let's Encrypt)
+// certificates and the plain HTTP listener that redirects to HTTPS.
+package tlsserver
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// DefaultConfig returns a TLS configuration limited to TLS 1.2+ with forward
+// secret AEAD cipher suites. TLS 1.3 suites are not configurable and are
+// always secure.
+func DefaultConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+	}
+}
+
+// NewACMEManager creates a manager that obtains and renews certificates for
+// the given domains only, caching them in cacheDir. Using it accepts the
+// CA's terms of service.
+func NewACMEManager(domains []string, email, cacheDir string) *autocert.Manager {
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      autocert.DirCache(cacheDir),
+		Email:      email,
+	}
+}
+
+// ACMEConfig returns DefaultConfig serving certificates from the manager.
+// TLS-ALPN-01 challenges are answered on the TLS listener itself.
+func ACMEConfig(manager *autocert.Manager) *tls.Config {
+	config := DefaultConfig()
+	config.GetCertificate = manager.GetCertificate
+	config.NextProtos = []string{"http/1.1", acme.ALPNProto}
+	return config
+}
+
+// RedirectHandler redirects every request to the same host and URI over
+// HTTPS on httpsPort. The port is left out of the URL when it is 443.
+func RedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,82 @@
+package tlsserver
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/crypto/acme"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	config := DefaultConfig()
+
+	if config.MinVersion != tls.VersionTLS12 {
+		t.Errorf("MinVersion = %x, want TLS 1.2", config.MinVersion)
+	}
+
+	insecure := make(map[uint16]bool)
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecure[suite.ID] = true
+	}
+	for _, id := range config.CipherSuites {
+		if insecure[id] {
+			t.Errorf("CipherSuites contains insecure suite %s", tls.CipherSuiteName(id))
+		}
+	}
+}
+
+func TestACMEConfig(t *testing.T) {
+	manager := NewACMEManager([]string{"api.example.com"}, "ops@example.com", t.TempDir())
+	config := ACMEConfig(manager)
+
+	if config.GetCertificate == nil {
+		t.Error("GetCertificate should be set")
+	}
+	found := false
+	for _, proto := range config.NextProtos {
+		if proto == acme.ALPNProto {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("NextProtos = %v, want %s for TLS-ALPN-01", config.NextProtos, acme.ALPNProto)
+	}
+
+	if err := manager.HostPolicy(context.Background(), "api.example.com"); err != nil {
+		t.Errorf("HostPolicy(api.example.com) error = %v", err)
+	}
+	if err := manager.HostPolicy(context.Background(), "other.example.com"); err == nil {
+		t.Error("HostPolicy should reject domains that are not configured")
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		httpsPort string
+		target    string
+		want      string
+	}{
+		{"default port", "443", "http://api.example.com/me?x=1", "https://api.example.com/me?x=1"},
+		{"strips http port", "443", "http://api.example.com:80/health", "https://api.example.com/health"},
+		{"custom port", "8443", "http://api.example.com:8080/me", "https://api.example.com:8443/me"},
+		{"no port", "", "http://api.example.com/", "https://api.example.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			RedirectHandler(tt.httpsPort).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != http.StatusPermanentRedirect {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusPermanentRedirect)
+			}
+			if got := rec.Header().Get("Location"); got != tt.want {
+				t.Errorf("Location = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// Package tokenverify lets other services verify the audience-restricted
+// tokens this API issues (see POST /tokens) without calling it per request.
+// Keys are fetched from the audience's JWKS URL and cached; a token signed
+// with a key not yet cached triggers a refresh, so key rotations need no
+// restart.
+//
+//	verifier, err := tokenverify.New(tokenverify.Options{
+//		JWKSURL:  "https://auth.example.com/.well-known/audiences/orders/jwks.json",
+//		Audience: "https://orders.example.com",
+//		Issuer:   "https://auth.example.com",
+//	})
+//	claims, err := verifier.Verify(ctx, token)
+//	if err == nil && claims.HasScope("orders:write") { ... }
+package tokenverify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"fiber-hello-world/pkg/jwt"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrInvalidToken is wrapped by every verification failure
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrUnknownKey is returned when the token's key is not in the key set
+	ErrUnknownKey = errors.New("token signed with an unknown key")
+)
+
+// Defaults for Options
+const (
+	DefaultLeeway             = time.Minute
+	DefaultCacheTTL           = 5 * time.Minute
+	DefaultMinRefreshInterval = 30 * time.Second
+)
+
+// maxKeySetBytes bounds the key set response
+const maxKeySetBytes = 1 << 20
+
+// algorithms are the signing methods audience tokens use
+var algorithms = []string{"RS256", "ES256", "EdDSA"}
+
+// Options configure a Verifier
+type Options struct {
+	// JWKSURL is the audience's key set, e.g.
+	// https://auth.example.com/.well-known/audiences/orders/jwks.json
+	JWKSURL string
+	// Audience is the "aud" claim tokens must carry
+	Audience string
+	// Issuer is the "iss" claim tokens must carry, if set
+	Issuer string
+	// Leeway tolerates clock skew when checking exp, nbf and iat
+	// (default DefaultLeeway)
+	Leeway time.Duration
+	// CacheTTL is how long fetched keys are used before the key set is
+	// fetched again (default DefaultCacheTTL)
+	CacheTTL time.Duration
+	// MinRefreshInterval limits refreshes triggered by unknown key IDs
+	// (default DefaultMinRefreshInterval)
+	MinRefreshInterval time.Duration
+	// HTTPClient fetches the key set (default a client with a 10s timeout)
+	HTTPClient *http.Client
+}
+
+// Claims are the claims of a verified token
+type Claims struct {
+	jwt.AudienceClaims
+}
+
+// Scopes returns the granted scopes
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// HasScope reports whether scope was granted
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
+
+// Verifier verifies tokens for one audience. It is safe for concurrent use.
+type Verifier struct {
+	opts Options
+	now  func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]publicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetchErr    error
+}
+
+// publicKey is a key from the key set with its algorithm
+type publicKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// New creates a verifier. Keys are fetched on first use.
+func New(opts Options) (*Verifier, error) {
+	if opts.JWKSURL == "" || opts.Audience == "" {
+		return nil, errors.New("tokenverify: JWKSURL and Audience are required")
+	}
+	if opts.Leeway == 0 {
+		opts.Leeway = DefaultLeeway
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+	if opts.MinRefreshInterval == 0 {
+		opts.MinRefreshInterval = DefaultMinRefreshInterval
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Verifier{opts: opts, now: time.Now}, nil
+}
+
+// Verify checks the token's signature, audience, issuer and lifetime and
+// returns its claims. Errors wrap ErrInvalidToken; a key set that cannot be
+// fetched is reported as is.
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	parserOpts := []jwtlib.ParserOption{
+		jwtlib.WithValidMethods(algorithms),
+		jwtlib.WithAudience(v.opts.Audience),
+		jwtlib.WithLeeway(v.opts.Leeway),
+		jwtlib.WithExpirationRequired(),
+		jwtlib.WithIssuedAt(),
+		jwtlib.WithTimeFunc(v.now),
+	}
+	if v.opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwtlib.WithIssuer(v.opts.Issuer))
+	}
+
+	var keyErr error
+	_, err := jwtlib.ParseWithClaims(tokenString, claims, func(token *jwtlib.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := v.key(ctx, kid)
+		if err != nil {
+			keyErr = err
+			return nil, err
+		}
+		if key.alg != token.Method.Alg() {
+			return nil, fmt.Errorf("key %s is for %s, not %s", kid, key.alg, token.Method.Alg())
+		}
+		return key.key, nil
+	}, parserOpts...)
+	if err != nil {
+		if keyErr != nil && !errors.Is(keyErr, ErrUnknownKey) {
+			return nil, keyErr
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// key returns the key with ID kid, fetching the key set when the cache has
+// expired or kid is unknown, at most every MinRefreshInterval. When a fetch
+// fails, keys already cached keep being used.
+func (v *Verifier) key(ctx context.Context, kid string) (publicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	key, known := v.keys[kid]
+	if known && now.Sub(v.fetchedAt) < v.opts.CacheTTL {
+		return key, nil
+	}
+	if v.attemptedAt.IsZero() || now.Sub(v.attemptedAt) >= v.opts.MinRefreshInterval {
+		v.attemptedAt = now
+		keys, err := v.fetch(ctx)
+		v.fetchErr = err
+		if err == nil {
+			v.keys = keys
+			v.fetchedAt = now
+			key, known = keys[kid]
+		}
+	}
+	switch {
+	case known:
+		return key, nil
+	case v.keys == nil && v.fetchErr != nil:
+		return publicKey{}, v.fetchErr
+	}
+	return publicKey{}, ErrUnknownKey
+}
+
+// fetch downloads and parses the key set
+func (v *Verifier) fetch(ctx context.Context) (map[string]publicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.opts.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("tokenverify: failed to fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tokenverify: failed to fetch key set: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jwt.JWK `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxKeySetBytes)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("tokenverify: invalid key set: %w", err)
+	}
+
+	keys := make(map[string]publicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := parseJWK(jwk)
+		if err != nil {
+			// Skip keys this version cannot use rather than fail on all
+			continue
+		}
+		keys[jwk.Kid] = publicKey{alg: jwk.Alg, key: key}
+	}
+	return keys, nil
+}
+
+// parseJWK returns the public key of a JWK published by this API
+func parseJWK(jwk jwt.JWK) (crypto.PublicKey, error) {
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return nil, fmt.Errorf("key %s is not for signatures", jwk.Kid)
+	}
+	switch {
+	case jwk.Kty == "RSA" && jwk.Alg == "RS256":
+		n, err := decodeInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(jwk.E)
+		if err != nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("key %s: invalid exponent", jwk.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case jwk.Kty == "EC" && jwk.Alg == "ES256" && jwk.Crv == "P-256":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != 32 {
+			return nil, fmt.Errorf("key %s: invalid P-256 key", jwk.Kid)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil || len(y) != 32 {
+			return nil, fmt.Errorf("key %s: invalid P-256 key", jwk.Kid)
+		}
+		// Rejects points that are not on the curve
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, fmt.Errorf("key %s: %w", jwk.Kid, err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case jwk.Kty == "OKP" && jwk.Alg == "EdDSA" && jwk.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("key %s: invalid Ed25519 key", jwk.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("key %s: unsupported %s/%s", jwk.Kid, jwk.Kty, jwk.Alg)
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,218 @@
+package tokenverify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fiber-hello-world/pkg/jwt"
+)
+
+// issuer serves the key set of the "orders" audience like the API does
+type issuer struct {
+	service *jwt.Service
+	fetches atomic.Int32
+	down    atomic.Bool
+	server  *httptest.Server
+}
+
+func newIssuer(t *testing.T, keys ...crypto.Signer) *issuer {
+	t.Helper()
+	iss := &issuer{}
+	iss.rotate(t, keys...)
+	iss.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		iss.fetches.Add(1)
+		if iss.down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		keys, _ := iss.service.AudienceKeys("orders")
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	t.Cleanup(iss.server.Close)
+	return iss
+}
+
+// rotate replaces the audience's keys
+func (iss *issuer) rotate(t *testing.T, keys ...crypto.Signer) {
+	t.Helper()
+	service := jwt.NewService("login-secret")
+	if err := service.RegisterAudience(jwt.Audience{Name: "orders", ID: "https://orders.example.com", Issuer: "https://auth.example.com",
+		Scopes: []string{"orders:read", "orders:write"}, Keys: keys}); err != nil {
+		t.Fatal(err)
+	}
+	iss.service = service
+}
+
+func (iss *issuer) token(t *testing.T, scopes ...string) string {
+	t.Helper()
+	token, _, err := iss.service.GenerateAudienceToken(7, "user@example.com", "orders", scopes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func (iss *issuer) verifier(t *testing.T) *Verifier {
+	t.Helper()
+	verifier, err := New(Options{JWKSURL: iss.server.URL, Audience: "https://orders.example.com", Issuer: "https://auth.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return verifier
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for name, key := range map[string]crypto.Signer{"RS256": rsaKey, "ES256": ecKey, "EdDSA": edKey} {
+		t.Run(name, func(t *testing.T) {
+			iss := newIssuer(t, key)
+			claims, err := iss.verifier(t).Verify(context.Background(), iss.token(t, "orders:read"))
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.UserID != 7 || claims.Subject != "7" || claims.Email != "user@example.com" {
+				t.Errorf("claims = %+v", claims)
+			}
+			if !claims.HasScope("orders:read") || claims.HasScope("orders:write") {
+				t.Errorf("scopes = %v, want orders:read only", claims.Scopes())
+			}
+		})
+	}
+}
+
+func TestVerifier_Rejects(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	iss := newIssuer(t, key)
+	token := iss.token(t)
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"other audience", Options{Audience: "https://billing.example.com"}},
+		{"other issuer", Options{Audience: "https://orders.example.com", Issuer: "https://evil.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.JWKSURL = iss.server.URL
+			verifier, _ := New(tt.opts)
+			if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify() error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+
+	// Login tokens are HMAC-signed and never verify
+	login, _, _ := jwt.NewService("login-secret").GenerateToken(7, "user@example.com")
+	if _, err := iss.verifier(t).Verify(context.Background(), login); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify(login token) error = %v, want ErrInvalidToken", err)
+	}
+
+	// A token signed with a key the audience does not publish
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	forged := newIssuer(t, otherKey).token(t)
+	if _, err := iss.verifier(t).Verify(context.Background(), forged); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Verify(forged) error = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestVerifier_ClockSkew(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	iss := newIssuer(t, key)
+	token := iss.token(t)
+	verifier := iss.verifier(t)
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		valid  bool
+	}{
+		{"expired within leeway", jwt.DefaultAudienceTTL + 30*time.Second, true},
+		{"expired beyond leeway", jwt.DefaultAudienceTTL + 2*time.Minute, false},
+		{"issued slightly in the future", -30 * time.Second, true},
+		{"issued in the future", -2 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier.now = func() time.Time { return time.Now().Add(tt.offset) }
+			// Keep the fetched keys fresh whatever the clock says
+			verifier.fetchedAt = time.Time{}
+			verifier.attemptedAt = time.Time{}
+			if _, err := verifier.Verify(context.Background(), token); (err == nil) != tt.valid {
+				t.Errorf("Verify() error = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestVerifier_KeyCache(t *testing.T) {
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	iss := newIssuer(t, oldKey)
+	verifier := iss.verifier(t)
+	now := time.Now()
+	verifier.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	token := iss.token(t)
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.Verify(ctx, token); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+	}
+	if got := iss.fetches.Load(); got != 1 {
+		t.Errorf("fetches = %d, want 1 while cached", got)
+	}
+
+	// A rotated key is fetched on first sight, once per refresh interval
+	iss.rotate(t, newKey, oldKey)
+	now = now.Add(DefaultMinRefreshInterval)
+	if _, err := verifier.Verify(ctx, iss.token(t)); err != nil {
+		t.Fatalf("Verify(rotated) error = %v", err)
+	}
+	_, unknownKey, _ := ed25519.GenerateKey(rand.Reader)
+	unknown := newIssuer(t, unknownKey).token(t)
+	for i := 0; i < 3; i++ {
+		verifier.Verify(ctx, unknown)
+	}
+	if got := iss.fetches.Load(); got != 2 {
+		t.Errorf("fetches = %d, want 2: unknown keys must not refetch within the interval", got)
+	}
+
+	// Cached keys outlive an unavailable key set
+	iss.down.Store(true)
+	now = now.Add(DefaultCacheTTL)
+	if _, err := verifier.Verify(ctx, token); err != nil {
+		t.Errorf("Verify() with the key set down = %v, want the cached key used", err)
+	}
+	if got := iss.fetches.Load(); got != 3 {
+		t.Errorf("fetches = %d, want a refresh attempt after the cache TTL", got)
+	}
+
+	// Without cached keys the fetch error is reported
+	fresh := iss.verifier(t)
+	if _, err := fresh.Verify(ctx, token); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() without keys = %v, want the fetch error", err)
+	}
+}
+
+func TestNew_RequiresAudience(t *testing.T) {
+	if _, err := New(Options{JWKSURL: "https://auth.example.com/jwks.json"}); err == nil {
+		t.Error("New() should require an audience")
+	}
+}
@@ -1,22 +1,114 @@
 package validator
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 )
 
+// Locales validation errors can be written in
+const (
+	LocaleEnglish = "en"
+	LocaleThai    = "th"
+)
+
 // Service provides validation operations
 type Service struct {
 	validator *validator.Validate
+	locale    string
 }
 
 // NewService creates a new validator service
 func NewService() *Service {
 	return &Service{
 		validator: validator.New(),
+		locale:    LocaleEnglish,
 	}
 }
 
+// SetLocale sets the language of validation errors. English errors are the
+// validator's own; Thai errors give one message per field, naming fields as
+// they appear in the request body.
+func (s *Service) SetLocale(locale string) error {
+	switch locale {
+	case LocaleEnglish:
+	case LocaleThai:
+		s.validator.RegisterTagNameFunc(jsonName)
+	default:
+		return fmt.Errorf("unsupported locale %q, want %s or %s", locale, LocaleEnglish, LocaleThai)
+	}
+	s.locale = locale
+	return nil
+}
+
 // Validate validates a struct based on validation tags
 func (s *Service) Validate(data interface{}) error {
-	return s.validator.Struct(data)
+	err := s.validator.Struct(data)
+	if err == nil || s.locale == LocaleEnglish {
+		return err
+	}
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+	messages := make([]string, len(fieldErrors))
+	for i, fe := range fieldErrors {
+		messages[i] = thaiMessage(fe)
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// jsonName names a struct field by its JSON key, falling back to the Go name
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// thaiMessage describes a failed validation in Thai, in the same
+// "field: message" form as request schema errors. Length rules count
+// characters for strings and items for slices and maps.
+func thaiMessage(fe validator.FieldError) string {
+	param := fe.Param()
+	var unit string
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " ตัวอักษร"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " รายการ"
+	}
+
+	message := "ไม่ถูกต้อง"
+	switch fe.Tag() {
+	case "required":
+		message = "ต้องระบุ"
+	case "email":
+		message = "ต้องเป็นอีเมลที่ถูกต้อง"
+	case "min", "gte":
+		message = "ต้องไม่น้อยกว่า " + param
+		if unit != "" {
+			message = "ต้องมีอย่างน้อย " + param + unit
+		}
+	case "max", "lte":
+		message = "ต้องไม่เกิน " + param
+		if unit != "" {
+			message = "ต้องมีไม่เกิน " + param + unit
+		}
+	case "gt":
+		message = "ต้องมากกว่า " + param
+		if unit != "" {
+			message = "ต้องมีมากกว่า " + param + unit
+		}
+	case "oneof":
+		message = "ต้องเป็นค่าใดค่าหนึ่งต่อไปนี้: " + strings.Join(strings.Fields(param), ", ")
+	}
+	return fe.Field() + ": " + message
 }
@@ -233,3 +233,37 @@ func TestService_Validate_NestedStruct(t *testing.T) {
 		t.Error("Validate() should return error for invalid nested struct")
 	}
 }
+
+type ThaiStruct struct {
+	Email string   `json:"email" validate:"required,email"`
+	Name  string   `json:"fullName" validate:"min=2"`
+	Age   int      `json:"age" validate:"max=150"`
+	Tags  []string `json:"tags" validate:"max=2"`
+	Plan  string   `json:"plan" validate:"oneof=free pro"`
+}
+
+func TestService_SetLocale(t *testing.T) {
+	service := NewService()
+	if err := service.SetLocale("fr"); err == nil {
+		t.Error("SetLocale() should reject an unsupported locale")
+	}
+	if err := service.SetLocale(LocaleThai); err != nil {
+		t.Fatalf("SetLocale() error = %v", err)
+	}
+
+	err := service.Validate(&ThaiStruct{Name: "J", Age: 200, Tags: []string{"a", "b", "c"}, Plan: "gold"})
+	if err == nil {
+		t.Fatal("Validate() should return error for invalid data")
+	}
+	want := "email: ต้องระบุ; fullName: ต้องมีอย่างน้อย 2 ตัวอักษร; age: ต้องไม่เกิน 150; tags: ต้องมีไม่เกิน 2 รายการ; plan: ต้องเป็นค่าใดค่าหนึ่งต่อไปนี้: free, pro"
+	if err.Error() != want {
+		t.Errorf("Validate() error = %q, want %q", err.Error(), want)
+	}
+
+	if err := service.Validate(&ThaiStruct{Email: "invalid", Name: "Somchai", Plan: "free"}); err == nil || err.Error() != "email: ต้องเป็นอีเมลที่ถูกต้อง" {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := service.Validate(nil); err == nil {
+		t.Error("Validate() should return error for nil data")
+	}
+}
@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DefaultInterval is used when a worker is created without a positive interval
+const DefaultInterval = time.Second
+
+// Lease lengths of exclusive workers: a lease lasts leaseIntervals ticks, and
+// at least minLease, so the holder keeps it while its job runs
+const (
+	leaseIntervals = 3
+	minLease       = time.Minute
+)
+
+// Job is a unit of background work run on every tick
+type Job func() error
+
+// Locker grants named leases to one instance at a time
+type Locker interface {
+	// Acquire takes the lease on name for ttl, or extends it when this
+	// instance holds it, reporting whether it does
+	Acquire(name string, ttl time.Duration) (bool, error)
+	// Release gives up the lease on name if this instance holds it
+	Release(name string) error
+}
+
+// Worker runs a job periodically until its context is cancelled
+type Worker struct {
+	name     string
+	interval time.Duration
+	job      Job
+	locker   Locker
+	// leader is whether this instance held the lease on the last tick
+	leader  bool
+	onError func(name string, err error)
+}
+
+// New creates a worker that runs job every interval
+func New(name string, interval time.Duration, job Job) *Worker {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	return &Worker{
+		name:     name,
+		interval: interval,
+		job:      job,
+	}
+}
+
+// Exclusive makes the worker run its job only on the instance holding the
+// lease named after it, so with several replicas the job runs on one of
+// them. The lease is extended on every tick and given up when Run returns;
+// if its holder dies, another instance takes over once it expires. A nil
+// locker leaves the worker running on every instance.
+func (w *Worker) Exclusive(locker Locker) *Worker {
+	w.locker = locker
+	return w
+}
+
+// OnError calls handle with the errors of the job, besides logging them,
+// e.g. to keep a summary of recent errors. Set it before Run.
+func (w *Worker) OnError(handle func(name string, err error)) *Worker {
+	w.onError = handle
+	return w
+}
+
+// Run executes the job once immediately and then on every tick, logging
+// errors, until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if w.lead() {
+			if err := w.job(); err != nil {
+				log.Printf("Worker %s: %v", w.name, err)
+				if w.onError != nil {
+					w.onError(w.name, err)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			w.resign()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead takes or extends the worker's lease, reporting whether this instance
+// should run the job. When the lease cannot be checked the job is skipped,
+// since another instance may be running it.
+func (w *Worker) lead() bool {
+	if w.locker == nil {
+		return true
+	}
+	held, err := w.locker.Acquire(w.name, max(leaseIntervals*w.interval, minLease))
+	if err != nil {
+		log.Printf("Worker %s: taking the lease: %v", w.name, err)
+		held = false
+	}
+	if held != w.leader {
+		if held {
+			log.Printf("Worker %s: running on this instance", w.name)
+		} else {
+			log.Printf("Worker %s: no longer running on this instance", w.name)
+		}
+		w.leader = held
+	}
+	return held
+}
+
+// resign gives up the lease, so another instance takes over without waiting
+// for it to expire
+func (w *Worker) resign() {
+	if w.locker == nil || !w.leader {
+		return
+	}
+	if err := w.locker.Release(w.name); err != nil {
+		log.Printf("Worker %s: releasing the lease: %v", w.name, err)
+	}
+	w.leader = false
+}
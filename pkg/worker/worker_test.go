@@ -0,0 +1,152 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNew_DefaultInterval(t *testing.T) {
+	w := New("test", 0, func() error { return nil })
+
+	if w.interval != DefaultInterval {
+		t.Errorf("interval = %v, want %v", w.interval, DefaultInterval)
+	}
+}
+
+func TestWorker_Run(t *testing.T) {
+	var runs, reported int32
+	w := New("test", time.Millisecond, func() error {
+		atomic.AddInt32(&runs, 1)
+		return errors.New("job errors are logged, not fatal")
+	}).OnError(func(name string, err error) {
+		if name == "test" && err != nil {
+			atomic.AddInt32(&reported, 1)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for atomic.LoadInt32(&runs) < 3 {
+		select {
+		case <-deadline:
+			t.Fatalf("job ran %d times, want at least 3", atomic.LoadInt32(&runs))
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return after the context was cancelled")
+	}
+	if got := atomic.LoadInt32(&reported); got != atomic.LoadInt32(&runs) {
+		t.Errorf("OnError() got %d errors, want %d", got, atomic.LoadInt32(&runs))
+	}
+}
+
+// fakeLocker is a Locker shared by workers standing in for instances, with
+// held naming the instance holding each lease
+type fakeLocker struct {
+	mu   sync.Mutex
+	held map[string]string
+	fail bool
+}
+
+// as returns the locker as seen by the instance owner
+func (l *fakeLocker) as(owner string) Locker {
+	return lockerFunc{
+		acquire: func(name string, ttl time.Duration) (bool, error) {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.fail {
+				return false, errors.New("lock store unavailable")
+			}
+			if holder, ok := l.held[name]; ok && holder != owner {
+				return false, nil
+			}
+			l.held[name] = owner
+			return true, nil
+		},
+		release: func(name string) error {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.held[name] == owner {
+				delete(l.held, name)
+			}
+			return nil
+		},
+	}
+}
+
+type lockerFunc struct {
+	acquire func(name string, ttl time.Duration) (bool, error)
+	release func(name string) error
+}
+
+func (l lockerFunc) Acquire(name string, ttl time.Duration) (bool, error) {
+	return l.acquire(name, ttl)
+}
+func (l lockerFunc) Release(name string) error { return l.release(name) }
+
+func TestWorker_Exclusive(t *testing.T) {
+	locker := &fakeLocker{held: make(map[string]string)}
+	var runsA, runsB int32
+	a := New("digests", time.Millisecond, func() error { atomic.AddInt32(&runsA, 1); return nil }).Exclusive(locker.as("a"))
+	b := New("digests", time.Millisecond, func() error { atomic.AddInt32(&runsB, 1); return nil }).Exclusive(locker.as("b"))
+
+	// a takes the lease first; b never runs while a holds it
+	ctxA, stopA := context.WithCancel(context.Background())
+	doneA := make(chan struct{})
+	go func() {
+		a.Run(ctxA)
+		close(doneA)
+	}()
+	waitFor(t, &runsA, 1)
+	ctxB, stopB := context.WithCancel(context.Background())
+	defer stopB()
+	go b.Run(ctxB)
+	waitFor(t, &runsA, 5)
+	if n := atomic.LoadInt32(&runsB); n != 0 {
+		t.Fatalf("b ran %d times while a held the lease", n)
+	}
+
+	// Once a stops it gives the lease up and b takes over
+	stopA()
+	<-doneA
+	waitFor(t, &runsB, 1)
+
+	// Without a lease check the job is skipped
+	locker.mu.Lock()
+	locker.fail = true
+	locker.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	stopped := atomic.LoadInt32(&runsB)
+	time.Sleep(10 * time.Millisecond)
+	if n := atomic.LoadInt32(&runsB); n != stopped {
+		t.Errorf("b ran %d times while the lease could not be checked", n-stopped)
+	}
+}
+
+// waitFor waits until *runs reaches n
+func waitFor(t *testing.T, runs *int32, n int32) {
+	t.Helper()
+	deadline := time.After(time.Second)
+	for atomic.LoadInt32(runs) < n {
+		select {
+		case <-deadline:
+			t.Fatalf("job ran %d times, want at least %d", atomic.LoadInt32(runs), n)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
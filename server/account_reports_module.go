@@ -0,0 +1,68 @@
+package server
+
+import (
+	"fmt"
+	"os"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// accountReportsModule serves reports of the data kept about users
+type accountReportsModule struct {
+	baseModule
+	accountReportHandler *handler.AccountReportHandler
+}
+
+// AccountReportsModule serves single-use links to HTML or PDF reports of a
+// user's profile, profile changes and sign-ins, for the user at
+// /me/account-report and for compliance requests at
+// /admin/users/:id/account-report. Links last ACCOUNT_REPORT_LINK_TTL and
+// ACCOUNT_REPORT_TEMPLATE optionally replaces the report template.
+func AccountReportsModule(deps *Deps) (Module, error) {
+	if deps.Config.AccountReportLinkTTL <= 0 {
+		return nil, fmt.Errorf("invalid account report configuration: ACCOUNT_REPORT_LINK_TTL must be positive, got %v", deps.Config.AccountReportLinkTTL)
+	}
+	text := usecase.DefaultAccountReportTemplate
+	if deps.Config.AccountReportTemplate != "" {
+		data, err := os.ReadFile(deps.Config.AccountReportTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account report configuration: %w", err)
+		}
+		text = string(data)
+	}
+	template, err := usecase.NewAccountReportTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+
+	loginEventRepo, err := container.Get[repository.LoginEventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	tokenUseCase, err := container.Get[*usecase.TokenUseCase](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	accountReportUseCase := usecase.NewAccountReportUseCase(deps.Users, deps.RevisionRepo, loginEventRepo, tokenUseCase, template, deps.Config.AccountReportLinkTTL)
+	return &accountReportsModule{
+		baseModule:           baseModule{"account-reports"},
+		accountReportHandler: handler.NewAccountReportHandler(accountReportUseCase),
+	}, nil
+}
+
+func (m *accountReportsModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		router.Get("/account-reports/:token", m.accountReportHandler.DownloadReport)
+	})
+	routes.Protected(func(router fiber.Router) {
+		router.Post("/me/account-report", m.accountReportHandler.RequestMyReport)
+	})
+	routes.Admin(func(admin fiber.Router) {
+		admin.Post("/users/:id/account-report", m.accountReportHandler.RequestUserReport)
+	})
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"fmt"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/autoscale"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// adminModule serves the admin API and runs queued admin actions
+type adminModule struct {
+	baseModule
+	deps               *Deps
+	adminActionUseCase *usecase.AdminActionUseCase
+	adminHandler       *handler.AdminHandler
+}
+
+// AdminModule serves the admin API under /admin and runs the worker that
+// executes queued bulk actions
+func AdminModule(deps *Deps) (Module, error) {
+	adminActionRepo, err := container.Get[repository.AdminActionRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	loginEventRepo, err := container.Get[repository.LoginEventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	tokenUseCase, err := container.Get[*usecase.TokenUseCase](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	passwordResetUseCase := usecase.NewPasswordResetUseCase(deps.Users, tokenUseCase, deps.Config.PasswordResetTTL)
+	adminActionUseCase := usecase.NewAdminActionUseCase(adminActionRepo, loginEventRepo, deps.Users, passwordResetUseCase, deps.Config.AdminActionDelay)
+	if err := adminActionUseCase.SetApprovalKinds(deps.Config.AdminApprovalKinds); err != nil {
+		return nil, fmt.Errorf("ADMIN_APPROVAL_KINDS: %w", err)
+	}
+
+	// Due admin actions are the worker's queue, reported to autoscalers
+	signals, err := container.Get[*autoscale.Signals](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	signals.RegisterQueue("admin_actions", adminActionUseCase.Backlog)
+
+	return &adminModule{
+		baseModule:         baseModule{"admin"},
+		deps:               deps,
+		adminActionUseCase: adminActionUseCase,
+		adminHandler:       handler.NewAdminHandler(deps.Funnel, deps.Users, adminActionUseCase, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *adminModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/funnel", m.adminHandler.GetFunnel)
+		admin.Get("/users/:id/history", m.adminHandler.GetUserHistory)
+		admin.Delete("/users/:id", m.adminHandler.DeleteUser)
+		admin.Put("/users/:id/status", m.adminHandler.SetUserStatus)
+		admin.Post("/users/bulk/suspend", m.adminHandler.SuspendUsers)
+		admin.Post("/users/bulk/role", m.adminHandler.SetUsersRole)
+		admin.Post("/users/bulk/incident-reset", m.adminHandler.IncidentReset)
+		admin.Get("/actions/:token", m.adminHandler.GetAction)
+		admin.Post("/actions/:token/undo", m.adminHandler.UndoAction)
+		admin.Post("/actions/:token/approve", m.adminHandler.ApproveAction)
+		admin.Post("/actions/:token/reject", m.adminHandler.RejectAction)
+		admin.Get("/queues", m.adminHandler.ListQueues)
+		admin.Get("/queues/admin_actions/jobs", m.adminHandler.ListQueueJobs)
+		admin.Post("/queues/admin_actions/jobs/:token/retry", m.adminHandler.RetryAction)
+		admin.Post("/queues/admin_actions/jobs/:token/cancel", m.adminHandler.UndoAction)
+		admin.Put("/queues/admin_actions/paused", m.adminHandler.PauseQueue)
+	})
+}
+
+func (m *adminModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("admin-actions", m.deps.Config.WorkerInterval, func() error {
+			_, err := m.adminActionUseCase.ProcessDue(100)
+			return err
+		}).Exclusive(m.deps.Locker),
+	}
+}
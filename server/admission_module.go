@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/pkg/admission"
+	"fiber-hello-world/pkg/autoscale"
+	"fiber-hello-world/pkg/container"
+)
+
+// admissionModule rejects low priority requests under overload
+type admissionModule struct {
+	baseModule
+	controller *admission.Controller
+}
+
+// AdmissionModule rejects requests with 503 and Retry-After while requests
+// in flight, hashing pool saturation or hashing queue wait are over their
+// ADMISSION_* limits, sign-ups first. ADMISSION_PRIORITIES adds to or
+// overrides admission.DefaultRules.
+func AdmissionModule(deps *Deps) (Module, error) {
+	if !deps.Config.AdmissionEnabled() {
+		return nil, nil
+	}
+
+	signals, err := container.Get[*autoscale.Signals](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	// Configured routes come after the defaults, so they win a tie
+	routes := make([]string, 0, len(deps.Config.AdmissionPriorities))
+	for route := range deps.Config.AdmissionPriorities {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	var rules []admission.Rule
+	for _, route := range routes {
+		rule, err := admission.ParseRule(route, deps.Config.AdmissionPriorities[route])
+		if err != nil {
+			return nil, fmt.Errorf("invalid admission configuration: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	limits := admission.Limits{
+		MaxInFlight:   int64(deps.Config.AdmissionInFlight),
+		MaxSaturation: deps.Config.AdmissionSaturation,
+		MaxHashWait:   deps.Config.AdmissionHashWait,
+	}
+	return &admissionModule{
+		baseModule: baseModule{"admission"},
+		controller: admission.New(limits, signals, slices.Concat(admission.DefaultRules, rules)),
+	}, nil
+}
+
+func (m *admissionModule) Routes(routes *Routes) {
+	routes.Use(middleware.AdmissionMiddleware(m.controller))
+}
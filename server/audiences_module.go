@@ -0,0 +1,47 @@
+package server
+
+import (
+	"fmt"
+
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// audiencesModule issues tokens restricted to downstream services
+type audiencesModule struct {
+	baseModule
+	audienceHandler *handler.AudienceHandler
+}
+
+// AudiencesModule serves POST /tokens and the audiences' key sets under
+// /.well-known/audiences when JWT_AUDIENCES is set
+func AudiencesModule(deps *Deps) (Module, error) {
+	if !deps.Config.JWTAudiencesEnabled() {
+		return nil, nil
+	}
+
+	audiences, err := jwt.LoadAudiences(deps.Config.JWTAudiences)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT audiences: %w", err)
+	}
+	for _, audience := range audiences {
+		if err := deps.JWT.RegisterAudience(audience); err != nil {
+			return nil, err
+		}
+	}
+	return &audiencesModule{
+		baseModule:      baseModule{"audiences"},
+		audienceHandler: handler.NewAudienceHandler(deps.Users, deps.JWT, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *audiencesModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		router.Get("/.well-known/audiences/:audience/jwks.json", m.audienceHandler.GetKeys)
+	})
+	routes.Protected(func(router fiber.Router) {
+		router.Post("/tokens", m.audienceHandler.IssueToken)
+	})
+}
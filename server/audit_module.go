@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// auditModule serves the audit trail of admin mutations
+type auditModule struct {
+	baseModule
+	auditHandler *handler.AuditHandler
+}
+
+// AuditModule serves /admin/audit, where admins review what other admins
+// changed, field by field. Entries are recorded whether or not it is served.
+func AuditModule(deps *Deps) (Module, error) {
+	auditRepo, err := container.Get[repository.AuditRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	return &auditModule{
+		baseModule:   baseModule{"audit"},
+		auditHandler: handler.NewAuditHandler(usecase.NewAuditUseCase(auditRepo)),
+	}, nil
+}
+
+func (m *auditModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/audit", m.auditHandler.ListAudit)
+	})
+}
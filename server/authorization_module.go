@@ -0,0 +1,42 @@
+package server
+
+import (
+	"log"
+
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// authorizationModule keeps users' roles in OpenFGA
+type authorizationModule struct {
+	baseModule
+	authorizationHandler *handler.AuthorizationHandler
+}
+
+// AuthorizationModule serves /admin/authorization/sync when OPENFGA_API_URL
+// is set. Other modules protect routes with middleware.PermissionMiddleware
+// and the *usecase.AuthorizationUseCase from the container.
+func AuthorizationModule(deps *Deps) (Module, error) {
+	if !deps.Config.OpenFGAEnabled() {
+		return nil, nil
+	}
+
+	authorizationUseCase, err := container.Get[*usecase.AuthorizationUseCase](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	return &authorizationModule{
+		baseModule:           baseModule{"authorization"},
+		authorizationHandler: handler.NewAuthorizationHandler(authorizationUseCase),
+	}, nil
+}
+
+func (m *authorizationModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Post("/authorization/sync", m.authorizationHandler.SyncRoles)
+	})
+	log.Println("OpenFGA authorization enabled")
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/pkg/autoscale"
+	"fiber-hello-world/pkg/container"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// autoscalingModule serves the load signals autoscalers scale on
+type autoscalingModule struct {
+	baseModule
+	autoscalingHandler *handler.AutoscalingHandler
+}
+
+// AutoscalingModule serves requests in flight, worker queue depths and
+// password hashing pool load at /autoscaling, for the Kubernetes HPA or
+// other custom autoscalers
+func AutoscalingModule(deps *Deps) (Module, error) {
+	signals, err := container.Get[*autoscale.Signals](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	return &autoscalingModule{
+		baseModule:         baseModule{"autoscaling"},
+		autoscalingHandler: handler.NewAutoscalingHandler(signals),
+	}, nil
+}
+
+func (m *autoscalingModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		router.Get("/autoscaling", m.autoscalingHandler.GetSignals)
+	})
+}
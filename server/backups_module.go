@@ -0,0 +1,73 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// scheduleCheckInterval is how often the backup, export and digest workers
+// check whether a run is due, so schedules hold across restarts
+const scheduleCheckInterval = time.Minute
+
+// backupsModule takes scheduled database backups and serves them to admins
+type backupsModule struct {
+	baseModule
+	locker        repository.Locker
+	interval      time.Duration
+	backupUseCase *usecase.BackupUseCase
+	backupHandler *handler.BackupHandler
+}
+
+// BackupsModule backs the database up to BACKUP_DIR every BACKUP_INTERVAL
+// and serves /admin/backups when BACKUP_DIR is set
+func BackupsModule(deps *Deps) (Module, error) {
+	if !deps.Config.BackupsEnabled() {
+		return nil, nil
+	}
+
+	backupStore, err := container.Get[repository.BackupStore](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	backupUseCase := usecase.NewBackupUseCase(backupStore, deps.Config.BackupRetention)
+
+	return &backupsModule{
+		baseModule:    baseModule{"backups"},
+		locker:        deps.Locker,
+		interval:      deps.Config.BackupInterval,
+		backupUseCase: backupUseCase,
+		backupHandler: handler.NewBackupHandler(backupUseCase),
+	}, nil
+}
+
+func (m *backupsModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/backups", m.backupHandler.ListBackups)
+		admin.Post("/backups", m.backupHandler.CreateBackup)
+	})
+}
+
+// Workers takes scheduled backups unless BACKUP_INTERVAL is zero
+func (m *backupsModule) Workers() []*Worker {
+	if m.interval <= 0 {
+		return nil
+	}
+
+	return []*Worker{
+		worker.New("backups", min(m.interval, scheduleCheckInterval), func() error {
+			backup, err := m.backupUseCase.BackupIfDue(m.interval)
+			if backup != nil {
+				log.Printf("Database backed up to %s", backup.Name)
+			}
+			return err
+		}).Exclusive(m.locker),
+	}
+}
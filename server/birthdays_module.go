@@ -0,0 +1,75 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/hooks"
+	"fiber-hello-world/pkg/worker"
+)
+
+// birthdayCheckInterval is how often the birthday worker looks for users
+// whose birthday it is, so greetings go out within that long of
+// BIRTHDAY_TIME in each time zone
+const birthdayCheckInterval = 15 * time.Minute
+
+// birthdaysModule greets users on their birthday
+type birthdaysModule struct {
+	baseModule
+	locker          repository.Locker
+	birthdayUseCase *usecase.BirthdayUseCase
+}
+
+// BirthdaysModule appends a user.birthday event and runs the birthday
+// hooks, e.g. a webhook in HOOK_WEBHOOKS, once a year for each active user
+// when BIRTHDAY_TIME is reached on their birthday in their time zone.
+// Users without one are greeted in BIRTHDAY_TIMEZONE.
+func BirthdaysModule(deps *Deps) (Module, error) {
+	if !deps.Config.BirthdaysEnabled() {
+		return nil, nil
+	}
+
+	at, err := deps.Config.BirthdayTimeOfDay()
+	if err != nil {
+		return nil, fmt.Errorf("invalid birthday configuration: %w", err)
+	}
+	location, err := time.LoadLocation(deps.Config.BirthdayTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid birthday configuration: BIRTHDAY_TIMEZONE: %w", err)
+	}
+	eventRepo, err := container.Get[repository.EventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	hookRegistry, err := container.Get[*hooks.Registry](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	return &birthdaysModule{
+		baseModule:      baseModule{"birthdays"},
+		locker:          deps.Locker,
+		birthdayUseCase: usecase.NewBirthdayUseCase(deps.UserRepo, database.NewSQLiteBirthdayRepository(deps.DB), eventRepo, hookRegistry, location, at),
+	}, nil
+}
+
+func (m *birthdaysModule) Migrations() []Migration {
+	return database.BirthdayMigrations
+}
+
+func (m *birthdaysModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("birthdays", birthdayCheckInterval, func() error {
+			greeted, err := m.birthdayUseCase.Celebrate()
+			if greeted > 0 {
+				log.Printf("Greeted %d users on their birthday", greeted)
+			}
+			return err
+		}).Exclusive(m.locker),
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"fmt"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// breakGlassModule serves key ceremonies and emergency admin access
+type breakGlassModule struct {
+	baseModule
+	deps              *Deps
+	breakGlassUseCase *usecase.BreakGlassUseCase
+	breakGlassHandler *handler.BreakGlassHandler
+}
+
+// BreakGlassModule lets admins seal an emergency credential split among
+// custodians at /admin/break-glass/ceremony, and custodians unseal it at
+// /break-glass/unseal to give one account admin access for BREAK_GLASS_TTL,
+// e.g. when every admin is locked out
+func BreakGlassModule(deps *Deps) (Module, error) {
+	if deps.Config.BreakGlassTTL <= 0 {
+		return nil, fmt.Errorf("invalid break-glass configuration: BREAK_GLASS_TTL must be positive, got %v", deps.Config.BreakGlassTTL)
+	}
+	eventRepo, err := container.Get[repository.EventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	breakGlassUseCase := usecase.NewBreakGlassUseCase(database.NewSQLiteBreakGlassRepository(deps.DB), deps.UserRepo, eventRepo, deps.Config.BreakGlassTTL)
+	return &breakGlassModule{
+		baseModule:        baseModule{"break-glass"},
+		deps:              deps,
+		breakGlassUseCase: breakGlassUseCase,
+		breakGlassHandler: handler.NewBreakGlassHandler(breakGlassUseCase, deps.JWT, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *breakGlassModule) Migrations() []Migration {
+	return database.BreakGlassMigrations
+}
+
+func (m *breakGlassModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		router.Post("/break-glass/unseal", m.breakGlassHandler.Unseal)
+	})
+	routes.GrantAdmin(middleware.BreakGlassGrant(m.breakGlassUseCase))
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/break-glass", m.breakGlassHandler.Status)
+		admin.Post("/break-glass/ceremony", m.breakGlassHandler.Ceremony)
+		admin.Post("/break-glass/sessions/:id/close", m.breakGlassHandler.CloseSession)
+	})
+}
+
+func (m *breakGlassModule) Workers() []*Worker {
+	return []*Worker{
+		// Access ends at expiry either way; this records it in the event log
+		worker.New("break-glass-expiry", m.deps.Config.WorkerInterval, func() error {
+			_, err := m.breakGlassUseCase.CloseExpired()
+			return err
+		}).Exclusive(m.deps.Locker),
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/canary"
+	"fiber-hello-world/pkg/container"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// canariesModule serves a cohort of users alternate implementations of routes
+type canariesModule struct {
+	baseModule
+	canaries      *canaryRoutes
+	canaryHandler *handler.CanaryHandler
+}
+
+// CanariesModule sends the cohort of each route in CANARY_ROUTES, a
+// percentage of users or those with an entitlements flag, to the alternate
+// implementation registered with Routes.Canary or WithCanary, e.g. a
+// rewritten login flow, and compares the variants at /admin/canaries
+func CanariesModule(deps *Deps) (Module, error) {
+	if !deps.Config.CanariesEnabled() {
+		return nil, nil
+	}
+
+	rules, err := canary.ParseRules(deps.Config.CanaryRoutes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid canary configuration: %w", err)
+	}
+	router, err := canary.New(rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid canary configuration: %w", err)
+	}
+
+	// Flag cohorts follow the users' entitlements
+	var entitlementsUseCase *usecase.EntitlementsUseCase
+	for _, rule := range rules {
+		if rule.Flag == "" {
+			continue
+		}
+		if entitlementsUseCase == nil {
+			if entitlementsUseCase, err = container.Get[*usecase.EntitlementsUseCase](deps.Container); err != nil {
+				return nil, fmt.Errorf("invalid canary configuration: %s follows a flag: %w", rule.Route(), err)
+			}
+		}
+		if !slices.Contains(entitlementsUseCase.Features(), rule.Flag) {
+			return nil, fmt.Errorf("invalid canary configuration: %s follows flag %q, which no plan or flag in ENTITLEMENTS_FILE names", rule.Route(), rule.Flag)
+		}
+	}
+
+	return &canariesModule{
+		baseModule: baseModule{"canaries"},
+		canaries: &canaryRoutes{
+			router:       router,
+			jwtService:   deps.JWT,
+			entitlements: entitlementsUseCase,
+			registered:   make(map[string]bool),
+		},
+		canaryHandler: handler.NewCanaryHandler(router),
+	}, nil
+}
+
+func (m *canariesModule) Routes(routes *Routes) {
+	routes.canaries = m.canaries
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/canaries", m.canaryHandler.GetCanaries)
+	})
+	for _, rule := range m.canaries.router.Rules() {
+		log.Printf("Canary of %s enabled for %s", rule.Route(), rule.Cohort())
+	}
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/canary"
+	"fiber-hello-world/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// canaryRoutes serves the routes of the CANARY_ROUTES rules through their
+// stable or their alternate implementation
+type canaryRoutes struct {
+	router       *canary.Router
+	jwtService   *jwt.Service
+	entitlements *usecase.EntitlementsUseCase
+	// alternates are the implementations registered with Routes.Canary and
+	// WithCanary, by route
+	alternates map[string]fiber.Handler
+	registered map[string]bool
+}
+
+// wrap returns router registering its routes through the canaries. prefix
+// is the path of router when it is a group.
+func (r *canaryRoutes) wrap(router fiber.Router, prefix string) fiber.Router {
+	if r == nil {
+		return router
+	}
+	return &canaryRouter{Router: router, prefix: prefix, canaries: r}
+}
+
+// handlers returns the handlers of a route, with the last one, the stable
+// implementation, picking between it and the alternate when a rule names
+// the route. Handlers before it, such as schema validation, serve both.
+func (r *canaryRoutes) handlers(method, path string, handlers []fiber.Handler) []fiber.Handler {
+	rule, ok := r.router.Rule(method, path)
+	if !ok || len(handlers) == 0 {
+		return handlers
+	}
+	alternate, ok := r.alternates[rule.Route()]
+	if !ok {
+		return handlers
+	}
+	r.registered[rule.Route()] = true
+
+	wrapped := append([]fiber.Handler(nil), handlers...)
+	last := len(wrapped) - 1
+	wrapped[last] = middleware.CanaryHandler(r.router, rule, wrapped[last], alternate, r.jwtService, r.entitlements)
+	return wrapped
+}
+
+// check fails for a rule without an alternate implementation or whose route
+// is not registered, so a typo does not go unnoticed
+func (r *canaryRoutes) check() error {
+	if r == nil {
+		return nil
+	}
+	for _, rule := range r.router.Rules() {
+		if _, ok := r.alternates[rule.Route()]; !ok {
+			return fmt.Errorf("canary route %s has no alternate implementation", rule.Route())
+		}
+		if !r.registered[rule.Route()] {
+			return fmt.Errorf("canary route %s is not registered", rule.Route())
+		}
+	}
+	return nil
+}
+
+// canaryRoute names a route the way canary rules do, e.g. "POST /login"
+func canaryRoute(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// canaryRouter registers routes added with Add, Get, Head, Post, Put, Patch
+// and Delete, also in its groups, through the canaries
+type canaryRouter struct {
+	fiber.Router
+	prefix   string
+	canaries *canaryRoutes
+}
+
+func (r *canaryRouter) Add(method, path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Add(method, path, r.canaries.handlers(method, groupPath(r.prefix, path), handlers)...)
+	return r
+}
+
+func (r *canaryRouter) Get(path string, handlers ...fiber.Handler) fiber.Router {
+	// Like Fiber, GET routes also answer HEAD
+	r.Add(fiber.MethodHead, path, handlers...)
+	return r.Add(fiber.MethodGet, path, handlers...)
+}
+
+func (r *canaryRouter) Head(path string, handlers ...fiber.Handler) fiber.Router {
+	return r.Add(fiber.MethodHead, path, handlers...)
+}
+
+func (r *canaryRouter) Post(path string, handlers ...fiber.Handler) fiber.Router {
+	return r.Add(fiber.MethodPost, path, handlers...)
+}
+
+func (r *canaryRouter) Put(path string, handlers ...fiber.Handler) fiber.Router {
+	return r.Add(fiber.MethodPut, path, handlers...)
+}
+
+func (r *canaryRouter) Patch(path string, handlers ...fiber.Handler) fiber.Router {
+	return r.Add(fiber.MethodPatch, path, handlers...)
+}
+
+func (r *canaryRouter) Delete(path string, handlers ...fiber.Handler) fiber.Router {
+	return r.Add(fiber.MethodDelete, path, handlers...)
+}
+
+func (r *canaryRouter) Group(prefix string, handlers ...fiber.Handler) fiber.Router {
+	return &canaryRouter{Router: r.Router.Group(prefix, handlers...), prefix: groupPath(r.prefix, prefix), canaries: r.canaries}
+}
+
+// groupPath joins the path of a group and of a route in it the way Fiber does
+func groupPath(prefix, path string) string {
+	if path == "" {
+		return prefix
+	}
+	if path[0] != '/' {
+		path = "/" + path
+	}
+	return strings.TrimRight(prefix, "/") + path
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"errors"
+	"log"
+
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/pkg/chaos"
+	"fiber-hello-world/pkg/container"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// chaosPath is where admins change the fault injection rules; it is never
+// faulted itself
+const chaosPath = "/admin/chaos"
+
+// chaosModule injects faults into requests for resilience testing
+type chaosModule struct {
+	baseModule
+	injector     *chaos.Injector
+	chaosHandler *handler.ChaosHandler
+}
+
+// ChaosModule lets admins inject latency, errors and dropped connections
+// into requests at runtime through /admin/chaos when CHAOS_ENABLED is set.
+// It refuses to run in production.
+func ChaosModule(deps *Deps) (Module, error) {
+	if !deps.Config.ChaosEnabled {
+		return nil, nil
+	}
+	if deps.Config.Env == "production" {
+		return nil, errors.New("fault injection cannot be enabled in production: unset CHAOS_ENABLED")
+	}
+
+	injector, err := container.Get[*chaos.Injector](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chaosModule{
+		baseModule:   baseModule{"chaos"},
+		injector:     injector,
+		chaosHandler: handler.NewChaosHandler(injector, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *chaosModule) Routes(routes *Routes) {
+	routes.Use(middleware.ChaosMiddleware(m.injector, chaosPath))
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/chaos", m.chaosHandler.GetRules)
+		admin.Put("/chaos", m.chaosHandler.SetRules)
+		admin.Delete("/chaos", m.chaosHandler.ClearRules)
+	})
+	log.Printf("Fault injection enabled, configure it at %s", chaosPath)
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"fmt"
+	"log"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/memory"
+	"fiber-hello-world/internal/infrastructure/redis"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/depcheck"
+	"fiber-hello-world/pkg/diagnostics"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// claimsModule caches the role and status authenticated requests are
+// checked against
+type claimsModule struct {
+	baseModule
+	deps          *Deps
+	claimsUseCase *usecase.ClaimsUseCase
+	claimsHandler *handler.ClaimsHandler
+}
+
+// dependencyClaimsCache is the CLAIMS_CACHE_REDIS_URL server
+const dependencyClaimsCache = "redis:claims-cache"
+
+// ClaimsModule checks every authenticated request against the caller's
+// current role and status, cached in memory and, when
+// CLAIMS_CACHE_REDIS_URL is set, in Redis. A worker drops cached claims
+// after role and status events, and /admin/claims-cache reports hit rates.
+func ClaimsModule(deps *Deps) (Module, error) {
+	if deps.Config.ClaimsCacheTTL <= 0 {
+		return nil, fmt.Errorf("CLAIMS_CACHE_TTL must be positive")
+	}
+	eventRepo, err := container.Get[repository.EventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	userRepo, err := container.Get[repository.UserRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared repository.ClaimsCache
+	var sharedUp func() bool
+	if deps.Config.ClaimsCacheRedisEnabled() {
+		cache, err := redis.NewClaimsCache(deps.Config.ClaimsCacheRedisURL, deps.Config.ClaimsCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("CLAIMS_CACHE_REDIS_URL: %w", err)
+		}
+		dependencies, err := container.Get[*depcheck.Checker](deps.Container)
+		if err != nil {
+			return nil, err
+		}
+		// While Redis is down claims are cached on this node only
+		dependencies.RegisterPinger(dependencyClaimsCache, cache, "shared claims cache")
+		shared, sharedUp = cache, func() bool { return dependencies.Up(dependencyClaimsCache) }
+		log.Println("Claims cached in Redis")
+	}
+
+	local := memory.NewClaimsCache(deps.Config.ClaimsCacheTTL)
+	collector, err := container.Get[*diagnostics.Collector](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	collector.RegisterCache("claims", local.Len)
+
+	claimsUseCase := usecase.NewClaimsUseCase(userRepo, eventRepo, local, shared)
+	claimsUseCase.SetSharedHealth(sharedUp)
+	return &claimsModule{
+		baseModule:    baseModule{"claims"},
+		deps:          deps,
+		claimsUseCase: claimsUseCase,
+		claimsHandler: handler.NewClaimsHandler(claimsUseCase),
+	}, nil
+}
+
+func (m *claimsModule) Routes(routes *Routes) {
+	routes.UseAuthenticated(middleware.ClaimsMiddleware(m.claimsUseCase))
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/claims-cache", m.claimsHandler.GetStats)
+	})
+}
+
+// WarmUp connects to Redis before the first request, when it is used
+func (m *claimsModule) WarmUp() error {
+	return m.claimsUseCase.WarmUp()
+}
+
+func (m *claimsModule) Workers() []*Worker {
+	return []*Worker{worker.New("claims-invalidation", m.deps.Config.WorkerInterval, func() error {
+		_, err := m.claimsUseCase.Sync()
+		return err
+	})}
+}
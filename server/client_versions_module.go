@@ -0,0 +1,86 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/clientversion"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// clientVersionsPath is where admins change the minimum app versions; it
+// is never version-checked itself
+const clientVersionsPath = "/admin/client-versions"
+
+// clientVersionRefreshInterval is how often minimum app versions set by
+// admins on other instances are picked up
+const clientVersionRefreshInterval = time.Minute
+
+// clientVersionsModule turns away outdated apps
+type clientVersionsModule struct {
+	baseModule
+	clientVersionUseCase *usecase.ClientVersionUseCase
+	clientVersionHandler *handler.ClientVersionHandler
+}
+
+// ClientVersionsModule answers 426 Upgrade Required to apps whose
+// X-Client-Version is older than their platform's minimum, from
+// CLIENT_MIN_VERSIONS or set by admins at /admin/client-versions
+func ClientVersionsModule(deps *Deps) (Module, error) {
+	defaults := make(map[string]string, len(deps.Config.ClientMinVersions))
+	for name, version := range deps.Config.ClientMinVersions {
+		platform, err := clientversion.ParsePlatform(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client version configuration: %w", err)
+		}
+		minVersion, err := clientversion.Parse(version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client version configuration: %w", err)
+		}
+		defaults[platform] = minVersion.String()
+	}
+
+	auditRepo, err := container.Get[repository.AuditRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	clientVersionUseCase := usecase.NewClientVersionUseCase(database.NewSQLiteClientVersionRepository(deps.DB), defaults)
+	clientVersionUseCase.SetAuditLog(auditRepo)
+	return &clientVersionsModule{
+		baseModule:           baseModule{"client-versions"},
+		clientVersionUseCase: clientVersionUseCase,
+		clientVersionHandler: handler.NewClientVersionHandler(clientVersionUseCase, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *clientVersionsModule) Migrations() []Migration {
+	return database.ClientVersionMigrations
+}
+
+func (m *clientVersionsModule) Routes(routes *Routes) {
+	routes.Use(middleware.ClientVersionMiddleware(m.clientVersionUseCase, clientVersionsPath))
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/client-versions", m.clientVersionHandler.ListPolicies)
+		admin.Put("/client-versions/:platform", m.clientVersionHandler.SetPolicy)
+		admin.Delete("/client-versions/:platform", m.clientVersionHandler.ResetPolicy)
+	})
+}
+
+// WarmUp loads the minimums set by admins before the first request
+func (m *clientVersionsModule) WarmUp() error {
+	return m.clientVersionUseCase.Refresh()
+}
+
+func (m *clientVersionsModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("client-versions", clientVersionRefreshInterval, m.clientVersionUseCase.Refresh),
+	}
+}
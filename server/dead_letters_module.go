@@ -0,0 +1,81 @@
+package server
+
+import (
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/autoscale"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/hooks"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// deadLettersModule retries failed hook deliveries and serves the dead letters
+type deadLettersModule struct {
+	baseModule
+	deps                *Deps
+	deliveryUseCase     *usecase.HookDeliveryUseCase
+	hookDeliveryHandler *handler.HookDeliveryHandler
+}
+
+// DeadLettersModule retries post-register, password-reset and birthday
+// hooks that fail, such as undelivered emails and webhooks, and keeps the
+// ones that exhaust HOOK_MAX_ATTEMPTS under /admin/dead-letters for
+// replaying. It is disabled with HOOK_MAX_ATTEMPTS=0, leaving failures only
+// logged.
+func DeadLettersModule(deps *Deps) (Module, error) {
+	if !deps.Config.HookRetriesEnabled() {
+		return nil, nil
+	}
+
+	hookRegistry, err := container.Get[*hooks.Registry](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	eventRepo, err := container.Get[repository.EventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	deliveryUseCase := usecase.NewHookDeliveryUseCase(database.NewSQLiteHookDeliveryRepository(deps.DB), eventRepo,
+		hookRegistry, deps.Config.HookMaxAttempts, deps.Config.HookRetryBackoff)
+	hookRegistry.OnFailure(deliveryUseCase.RecordFailure)
+
+	// Deliveries due for a retry are the worker's queue, reported to autoscalers
+	signals, err := container.Get[*autoscale.Signals](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	signals.RegisterQueue("hook_deliveries", deliveryUseCase.Backlog)
+
+	return &deadLettersModule{
+		baseModule:          baseModule{"dead-letters"},
+		deps:                deps,
+		deliveryUseCase:     deliveryUseCase,
+		hookDeliveryHandler: handler.NewHookDeliveryHandler(deliveryUseCase),
+	}, nil
+}
+
+func (m *deadLettersModule) Migrations() []Migration {
+	return database.HookDeliveryMigrations
+}
+
+func (m *deadLettersModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/dead-letters", m.hookDeliveryHandler.ListDeliveries)
+		admin.Post("/dead-letters/replay", m.hookDeliveryHandler.ReplayDeliveries)
+		admin.Get("/dead-letters/:id", m.hookDeliveryHandler.GetDelivery)
+		admin.Post("/dead-letters/:id/replay", m.hookDeliveryHandler.ReplayDelivery)
+	})
+}
+
+func (m *deadLettersModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("hook-deliveries", m.deps.Config.WorkerInterval, func() error {
+			_, err := m.deliveryUseCase.ProcessDue(100)
+			return err
+		}).Exclusive(m.deps.Locker),
+	}
+}
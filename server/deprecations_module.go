@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/deprecation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// deprecationsModule reports the calls to deprecated routes
+type deprecationsModule struct {
+	baseModule
+	deprecationHandler *handler.DeprecationHandler
+}
+
+// DeprecationsModule serves /admin/deprecations, which lists the routes
+// marked with Routes.Deprecate or WithDeprecations and the clients still
+// calling them. Deprecated routes are announced whether or not it is served.
+func DeprecationsModule(deps *Deps) (Module, error) {
+	registry, err := container.Get[*deprecation.Registry](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	return &deprecationsModule{
+		baseModule:         baseModule{"deprecations"},
+		deprecationHandler: handler.NewDeprecationHandler(registry),
+	}, nil
+}
+
+func (m *deprecationsModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/deprecations", m.deprecationHandler.GetDeprecations)
+	})
+}
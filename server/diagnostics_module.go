@@ -0,0 +1,41 @@
+package server
+
+import (
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/diagnostics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// diagnosticsModule serves the state of the instance for triage
+type diagnosticsModule struct {
+	baseModule
+	collector          *diagnostics.Collector
+	diagnosticsHandler *handler.DiagnosticsHandler
+}
+
+// DiagnosticsModule serves goroutines, heap statistics, cache sizes, queue
+// depths, the database pool and a summary of recent errors at
+// /admin/diagnostics. Requests failing with a 5xx status and failed worker
+// runs are the errors summarized.
+func DiagnosticsModule(deps *Deps) (Module, error) {
+	collector, err := container.Get[*diagnostics.Collector](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diagnosticsModule{
+		baseModule:         baseModule{"diagnostics"},
+		collector:          collector,
+		diagnosticsHandler: handler.NewDiagnosticsHandler(collector),
+	}, nil
+}
+
+func (m *diagnosticsModule) Routes(routes *Routes) {
+	routes.Use(middleware.DiagnosticsMiddleware(m.collector))
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/diagnostics", m.diagnosticsHandler.GetDiagnostics)
+	})
+}
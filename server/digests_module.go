@@ -0,0 +1,141 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/depcheck"
+	"fiber-hello-world/pkg/hooks"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// digestsModule emails admins periodic digests
+type digestsModule struct {
+	baseModule
+	locker repository.Locker
+	period entity.DigestPeriod
+	at     time.Duration
+	// mailerUp reports whether the SMTP server passed its last check
+	mailerUp      func() bool
+	digestUseCase *usecase.DigestUseCase
+	digestHandler *handler.DigestHandler
+}
+
+// dependencySMTP is the SMTP_ADDR server
+const dependencySMTP = "smtp"
+
+// DigestsModule emails ADMIN_EMAILS a digest of signups, failed logins,
+// failed hook deliveries and pending deletions on DIGEST_SCHEDULE (daily,
+// or weekly on Mondays) at DIGEST_TIME (UTC) when DIGEST_SCHEDULE is set.
+// It records failed hooks from then on and serves /admin/digest/preview.
+func DigestsModule(deps *Deps) (Module, error) {
+	if !deps.Config.DigestsEnabled() {
+		return nil, nil
+	}
+
+	period := entity.DigestPeriod(deps.Config.DigestSchedule)
+	if !period.IsValid() {
+		return nil, fmt.Errorf("invalid digest configuration: DIGEST_SCHEDULE must be daily or weekly, got %q", deps.Config.DigestSchedule)
+	}
+	at, err := deps.Config.DigestTimeOfDay()
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest configuration: %w", err)
+	}
+	if len(deps.Config.AdminEmails) == 0 {
+		return nil, errors.New("invalid digest configuration: ADMIN_EMAILS is not set")
+	}
+	text := usecase.DefaultDigestTemplate
+	if deps.Config.DigestTemplate != "" {
+		data, err := os.ReadFile(deps.Config.DigestTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid digest configuration: %w", err)
+		}
+		text = string(data)
+	}
+	template, err := usecase.NewDigestTemplate(text, deps.Config.DigestLinkBase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest configuration: %w", err)
+	}
+
+	mailer, err := container.Get[repository.Mailer](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := container.Get[*depcheck.Checker](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	if pinger, ok := mailer.(depcheck.Pinger); ok {
+		dependencies.RegisterPinger(dependencySMTP, pinger, "admin digests")
+	}
+	loginEventRepo, err := container.Get[repository.LoginEventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	adminActionRepo, err := container.Get[repository.AdminActionRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	hookRegistry, err := container.Get[*hooks.Registry](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	// Failed hooks are only logged otherwise; keep them for the digest
+	hookFailureRepo := database.NewSQLiteHookFailureRepository(deps.DB)
+	hookRegistry.OnFailure(func(event *hooks.Event, _ int, hookErr error) {
+		failure := &entity.HookFailure{Point: string(event.Point), UserID: event.UserID, Error: hookErr.Error()}
+		if err := hookFailureRepo.Record(failure); err != nil {
+			log.Printf("Failed to record %s hook failure: %v", event.Point, err)
+		}
+	})
+
+	digestUseCase := usecase.NewDigestUseCase(deps.UserRepo, loginEventRepo, adminActionRepo, hookFailureRepo,
+		database.NewSQLiteDigestRepository(deps.DB), mailer, template, deps.Config.AdminEmails)
+	return &digestsModule{
+		baseModule:    baseModule{"digests"},
+		locker:        deps.Locker,
+		period:        period,
+		at:            at,
+		mailerUp:      func() bool { return dependencies.Up(dependencySMTP) },
+		digestUseCase: digestUseCase,
+		digestHandler: handler.NewDigestHandler(digestUseCase, period),
+	}, nil
+}
+
+func (m *digestsModule) Migrations() []Migration {
+	return database.DigestMigrations
+}
+
+func (m *digestsModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/digest/preview", m.digestHandler.Preview)
+	})
+}
+
+func (m *digestsModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("digests", scheduleCheckInterval, func() error {
+			// A due digest is sent once the SMTP server is back
+			if !m.mailerUp() {
+				return nil
+			}
+			digest, err := m.digestUseCase.SendIfDue(m.period, m.at)
+			if digest != nil {
+				log.Printf("Sent the %s admin digest up to %s", digest.Period, digest.Until.Format("2006-01-02 15:04"))
+			}
+			return err
+		}).Exclusive(m.locker),
+	}
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/swagger"
+
+	_ "fiber-hello-world/docs" // This line is needed for go-swagger to find your docs!
+)
+
+// docsModule serves the Swagger UI
+type docsModule struct {
+	baseModule
+}
+
+// DocsModule serves the Swagger UI at /swagger
+func DocsModule(*Deps) (Module, error) {
+	return docsModule{baseModule{"docs"}}, nil
+}
+
+func (docsModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		router.Get("/swagger/*", swagger.HandlerDefault)
+	})
+}
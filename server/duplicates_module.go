@@ -0,0 +1,68 @@
+package server
+
+import (
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// duplicateScanInterval is how often accounts are compared for duplicates
+const duplicateScanInterval = 24 * time.Hour
+
+// duplicatesModule finds accounts that probably belong to the same person
+type duplicatesModule struct {
+	baseModule
+	locker           repository.Locker
+	duplicateUseCase *usecase.DuplicateUseCase
+	duplicateHandler *handler.DuplicateHandler
+}
+
+// DuplicatesModule compares every account daily for a shared phone number,
+// mailbox or login device, and serves the pairs found at /admin/duplicates
+// for admins to review
+func DuplicatesModule(deps *Deps) (Module, error) {
+	loginEventRepo, err := container.Get[repository.LoginEventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	auditRepo, err := container.Get[repository.AuditRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	duplicateUseCase := usecase.NewDuplicateUseCase(database.NewSQLiteDuplicateRepository(deps.DB), deps.UserRepo, loginEventRepo)
+	duplicateUseCase.SetAuditLog(auditRepo)
+	return &duplicatesModule{
+		baseModule:       baseModule{"duplicates"},
+		locker:           deps.Locker,
+		duplicateUseCase: duplicateUseCase,
+		duplicateHandler: handler.NewDuplicateHandler(duplicateUseCase),
+	}, nil
+}
+
+func (m *duplicatesModule) Migrations() []Migration {
+	return database.DuplicateMigrations
+}
+
+func (m *duplicatesModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/duplicates", m.duplicateHandler.ListDuplicates)
+		admin.Post("/duplicates/scan", m.duplicateHandler.ScanDuplicates)
+		admin.Post("/duplicates/:id/dismiss", m.duplicateHandler.DismissDuplicate)
+	})
+}
+
+func (m *duplicatesModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("duplicates", duplicateScanInterval, func() error {
+			_, err := m.duplicateUseCase.Scan()
+			return err
+		}).Exclusive(m.locker),
+	}
+}
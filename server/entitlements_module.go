@@ -0,0 +1,54 @@
+package server
+
+import (
+	"log"
+	"strings"
+
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// entitlementsModule serves the features users may use
+type entitlementsModule struct {
+	baseModule
+	entitlementsHandler *handler.EntitlementsHandler
+}
+
+// EntitlementsModule serves GET /me/entitlements, the features of the
+// caller's plan, the feature flags and their overrides in
+// ENTITLEMENTS_FILE, and the admin API that overrides them per user
+func EntitlementsModule(deps *Deps) (Module, error) {
+	if !deps.Config.EntitlementsEnabled() {
+		return nil, nil
+	}
+
+	entitlementsUseCase, err := container.Get[*usecase.EntitlementsUseCase](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Entitlements: %s", strings.Join(entitlementsUseCase.Features(), ", "))
+
+	return &entitlementsModule{
+		baseModule:          baseModule{"entitlements"},
+		entitlementsHandler: handler.NewEntitlementsHandler(entitlementsUseCase, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *entitlementsModule) Migrations() []Migration {
+	return database.EntitlementOverrideMigrations
+}
+
+func (m *entitlementsModule) Routes(routes *Routes) {
+	routes.Protected(func(router fiber.Router) {
+		router.Get("/me/entitlements", m.entitlementsHandler.GetMyEntitlements)
+	})
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/users/:id/entitlements", m.entitlementsHandler.GetUserEntitlements)
+		admin.Put("/users/:id/entitlements/:feature", m.entitlementsHandler.SetOverride)
+		admin.Delete("/users/:id/entitlements/:feature", m.entitlementsHandler.DeleteOverride)
+	})
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// eventsModule serves the domain event log
+type eventsModule struct {
+	baseModule
+	eventHandler *handler.EventHandler
+}
+
+// EventsModule serves /admin/events, where admins query and export the
+// domain event log. Events are recorded whether or not it is served.
+func EventsModule(deps *Deps) (Module, error) {
+	eventUseCase, err := container.Get[*usecase.EventUseCase](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	return &eventsModule{
+		baseModule:   baseModule{"events"},
+		eventHandler: handler.NewEventHandler(eventUseCase),
+	}, nil
+}
+
+func (m *eventsModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/events", m.eventHandler.ListEvents)
+		admin.Get("/events/export", m.eventHandler.ExportEvents)
+	})
+}
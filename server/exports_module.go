@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/worker"
+)
+
+// exportsModule writes nightly exports of users and their history
+type exportsModule struct {
+	baseModule
+	locker        repository.Locker
+	at            time.Duration
+	exportUseCase *usecase.ExportUseCase
+}
+
+// ExportsModule exports users and their revision history to EXPORT_STORE
+// once a day at EXPORT_TIME (UTC) when EXPORT_STORE is set
+func ExportsModule(deps *Deps) (Module, error) {
+	if !deps.Config.ExportsEnabled() {
+		return nil, nil
+	}
+
+	at, err := deps.Config.ExportTimeOfDay()
+	if err != nil {
+		return nil, fmt.Errorf("invalid export configuration: %w", err)
+	}
+	snapshots, err := container.Get[repository.SnapshotSource](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	store, err := container.Get[repository.BlobStore](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exportsModule{
+		baseModule:    baseModule{"exports"},
+		locker:        deps.Locker,
+		at:            at,
+		exportUseCase: usecase.NewExportUseCase(snapshots, store, deps.Config.ExportPrefix),
+	}, nil
+}
+
+func (m *exportsModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("exports", scheduleCheckInterval, func() error {
+			manifest, err := m.exportUseCase.ExportIfDue(m.at)
+			if manifest != nil {
+				log.Printf("Exported %d users and %d revisions for %s",
+					manifest.Files[0].Records, manifest.Files[1].Records, manifest.Date)
+			}
+			return err
+		}).Exclusive(m.locker),
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"fmt"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/fraud"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fraudRestrictedRoutes are the sensitive actions refused to users flagged
+// for fraud review until an admin clears them
+var fraudRestrictedRoutes = []string{
+	"PATCH /me",
+	"PUT /me/password",
+	"POST /me/share-links",
+	"POST /auth/qr/approve",
+	"POST /tokens",
+}
+
+// fraudModule scores users for fraud and restricts the flagged ones
+type fraudModule struct {
+	baseModule
+	deps         *Deps
+	fraudUseCase *usecase.FraudUseCase
+	fraudHandler *handler.FraudHandler
+}
+
+// FraudModule scores users who registered or logged in since its last run
+// every WORKER_INTERVAL, flags those reaching FRAUD_FLAG_SCORE, refuses them
+// the routes in fraudRestrictedRoutes, and serves the scores at /admin/fraud
+func FraudModule(deps *Deps) (Module, error) {
+	if deps.Config.FraudFlagScore <= 0 || deps.Config.FraudFlagScore > fraud.MaxScore {
+		return nil, fmt.Errorf("invalid fraud configuration: FRAUD_FLAG_SCORE must be between 1 and %d, got %d", fraud.MaxScore, deps.Config.FraudFlagScore)
+	}
+	if deps.Config.FraudVelocityLimit <= 0 {
+		return nil, fmt.Errorf("invalid fraud configuration: FRAUD_VELOCITY_LIMIT must be positive, got %d", deps.Config.FraudVelocityLimit)
+	}
+	loginEventRepo, err := container.Get[repository.LoginEventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	auditRepo, err := container.Get[repository.AuditRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	scorer := fraud.New(fraud.Options{VelocityLimit: deps.Config.FraudVelocityLimit, DisposableDomains: deps.Config.DisposableDomains})
+	fraudUseCase := usecase.NewFraudUseCase(database.NewSQLiteFraudRepository(deps.DB), deps.UserRepo, loginEventRepo, scorer, deps.Config.FraudFlagScore)
+	fraudUseCase.SetAuditLog(auditRepo)
+	return &fraudModule{
+		baseModule:   baseModule{"fraud"},
+		deps:         deps,
+		fraudUseCase: fraudUseCase,
+		fraudHandler: handler.NewFraudHandler(fraudUseCase),
+	}, nil
+}
+
+func (m *fraudModule) Migrations() []Migration {
+	return database.FraudMigrations
+}
+
+func (m *fraudModule) Routes(routes *Routes) {
+	routes.UseAuthenticated(middleware.FraudMiddleware(m.fraudUseCase, fraudRestrictedRoutes))
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/fraud", m.fraudHandler.ListScores)
+		admin.Get("/fraud/:id", m.fraudHandler.GetScore)
+		admin.Post("/fraud/:id/score", m.fraudHandler.ScoreUser)
+		admin.Post("/fraud/:id/clear", m.fraudHandler.ClearUser)
+	})
+}
+
+func (m *fraudModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("fraud-scores", m.deps.Config.WorkerInterval, func() error {
+			_, err := m.fraudUseCase.ScoreRecent()
+			return err
+		}).Exclusive(m.deps.Locker),
+	}
+}
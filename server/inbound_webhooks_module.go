@@ -0,0 +1,79 @@
+package server
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/inbound"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// inboundEventCleanupInterval is how often expired inbound events are deleted
+const inboundEventCleanupInterval = time.Hour
+
+// inboundWebhooksModule receives webhooks from integrated providers
+type inboundWebhooksModule struct {
+	baseModule
+	locker                repository.Locker
+	inboundUseCase        *usecase.InboundWebhookUseCase
+	inboundWebhookHandler *handler.InboundWebhookHandler
+}
+
+// InboundWebhooksModule serves /webhooks/:provider for the providers in
+// INBOUND_WEBHOOK_SECRETS. Requests are verified with the provider's
+// signature and each event ID is handled once. SendGrid bounces are recorded
+// as user.email_bounced events; other events go to the handlers passed with
+// WithInboundHandler.
+func InboundWebhooksModule(deps *Deps) (Module, error) {
+	if !deps.Config.InboundWebhooksEnabled() {
+		return nil, nil
+	}
+
+	router, err := container.Get[*inbound.Router](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	eventRepo, err := container.Get[repository.EventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	inboundUseCase := usecase.NewInboundWebhookUseCase(database.NewSQLiteInboundEventRepository(deps.DB), router, deps.Users, eventRepo)
+	for _, eventType := range []string{"bounce", "dropped", "spamreport"} {
+		router.Handle("sendgrid", eventType, inbound.HandlerFunc(inboundUseCase.RecordBounce))
+	}
+	log.Printf("Inbound webhooks accepted from %s", strings.Join(router.Providers(), ", "))
+
+	return &inboundWebhooksModule{
+		baseModule:            baseModule{"inbound-webhooks"},
+		locker:                deps.Locker,
+		inboundUseCase:        inboundUseCase,
+		inboundWebhookHandler: handler.NewInboundWebhookHandler(inboundUseCase, deps.Config.InboundWebhookURL),
+	}, nil
+}
+
+func (m *inboundWebhooksModule) Migrations() []Migration {
+	return database.InboundEventMigrations
+}
+
+func (m *inboundWebhooksModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		router.Post("/webhooks/:provider", m.inboundWebhookHandler.Receive)
+	})
+}
+
+func (m *inboundWebhooksModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("inbound-events", inboundEventCleanupInterval, func() error {
+			_, err := m.inboundUseCase.DeleteExpired()
+			return err
+		}).Exclusive(m.locker),
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// kycModule takes users' identity documents and serves them for review
+type kycModule struct {
+	baseModule
+	deps        *Deps
+	hasProvider bool
+	kycUseCase  *usecase.KYCUseCase
+	kycHandler  *handler.KYCHandler
+}
+
+// KYCModule lets users submit identity documents at /me/kyc when KYC_STORE
+// is set, keeping them in that store. Documents are verified by the
+// KYC_PROVIDER_URL service every WORKER_INTERVAL; those it cannot decide, or
+// all of them without a provider, are reviewed by admins at /admin/kyc.
+func KYCModule(deps *Deps) (Module, error) {
+	if !deps.Config.KYCEnabled() {
+		return nil, nil
+	}
+	store, err := newKYCStore(deps.Config)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := container.Get[repository.KYCProvider](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	auditRepo, err := container.Get[repository.AuditRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	kycUseCase := usecase.NewKYCUseCase(database.NewSQLiteKYCRepository(deps.DB), store, provider, deps.Users)
+	kycUseCase.SetAuditLog(auditRepo)
+	return &kycModule{
+		baseModule:  baseModule{"kyc"},
+		deps:        deps,
+		hasProvider: provider != nil,
+		kycUseCase:  kycUseCase,
+		kycHandler:  handler.NewKYCHandler(kycUseCase, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *kycModule) Migrations() []Migration {
+	return database.KYCMigrations
+}
+
+func (m *kycModule) Routes(routes *Routes) {
+	routes.Protected(func(router fiber.Router) {
+		router.Post("/me/kyc", m.kycHandler.Submit)
+		router.Get("/me/kyc", m.kycHandler.GetStatus)
+	})
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/kyc", m.kycHandler.ListSubmissions)
+		admin.Get("/kyc/:id/document", m.kycHandler.GetDocument)
+		admin.Post("/kyc/:id/approve", m.kycHandler.Approve)
+		admin.Post("/kyc/:id/reject", m.kycHandler.Reject)
+	})
+}
+
+func (m *kycModule) Workers() []*Worker {
+	if !m.hasProvider {
+		return nil
+	}
+	return []*Worker{
+		worker.New("kyc-verifications", m.deps.Config.WorkerInterval, func() error {
+			_, err := m.kycUseCase.VerifyPending()
+			return err
+		}).Exclusive(m.deps.Locker),
+	}
+}
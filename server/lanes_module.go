@@ -0,0 +1,39 @@
+package server
+
+import (
+	"fmt"
+
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/lanes"
+)
+
+// lanesModule limits concurrent requests per class of traffic
+type lanesModule struct {
+	baseModule
+	lanes *lanes.Lanes
+	jwt   *jwt.Service
+}
+
+// LanesModule serves health checks, signed-in users, anonymous callers and
+// bulk exports in separate lanes, each limited by LANE_LIMITS, so exports or
+// a sign-up storm cannot take every connection from signed-in users
+func LanesModule(deps *Deps) (Module, error) {
+	if !deps.Config.LanesEnabled() {
+		return nil, nil
+	}
+
+	limits, err := lanes.ParseLimits(deps.Config.LaneLimits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lane configuration: %w", err)
+	}
+	return &lanesModule{
+		baseModule: baseModule{"lanes"},
+		lanes:      lanes.New(limits),
+		jwt:        deps.JWT,
+	}, nil
+}
+
+func (m *lanesModule) Routes(routes *Routes) {
+	routes.Use(middleware.LaneMiddleware(m.lanes, m.jwt))
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"database/sql"
+
+	"fiber-hello-world/config"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/deprecation"
+	"fiber-hello-world/pkg/jsonschema"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/validator"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Migration is a versioned schema change of a module. Versions are numbered
+// per module, starting at 1.
+type Migration = database.Migration
+
+// Worker is a background job run while the server is up
+type Worker = worker.Worker
+
+// Module is a feature that plugs into the server: its routes, the schema
+// changes it needs and its background workers
+type Module interface {
+	// Name identifies the module in DISABLED_MODULES and in module_migrations
+	Name() string
+	// Migrations are applied in order when the server starts
+	Migrations() []Migration
+	// Routes registers the module's routes
+	Routes(routes *Routes)
+	// Workers run until the server is closed
+	Workers() []*Worker
+}
+
+// ModuleFunc builds a module from the shared services. Returning a nil
+// Module skips it, e.g. when its configuration is missing.
+type ModuleFunc func(deps *Deps) (Module, error)
+
+// Deps are the services shared by modules. Container holds every service,
+// so modules can also resolve ones not listed here, e.g.
+// container.Get[repository.AdminActionRepository](deps.Container).
+type Deps struct {
+	Container    *container.Container
+	Config       *config.Config
+	DB           *sql.DB
+	UserRepo     repository.UserRepository
+	RevisionRepo repository.UserRevisionRepository
+	Users        *usecase.UserUseCase
+	Funnel       *usecase.FunnelUseCase
+	JWT          *jwt.Service
+	Validator    *validator.Service
+	Decoder      *decoder.Service
+	Schemas      *jsonschema.Service
+	// Locker grants the leases of exclusive workers; nil unless WORKER_LOCK
+	// is set, when every instance runs them
+	Locker repository.Locker
+	// RequireSignature enforces signed requests on sensitive routes when
+	// SIGNING_KEYS is set; admin routes already have it
+	RequireSignature fiber.Handler
+}
+
+// Routes collects a module's routes. Public routes are registered before the
+// authenticated groups, which match every path, whatever the module order.
+type Routes struct {
+	middleware    []fiber.Handler
+	authenticated []fiber.Handler
+	public        []func(fiber.Router)
+	protected     []func(fiber.Router)
+	admin         []func(fiber.Router)
+	adminGrants   []func(*fiber.Ctx) bool
+	deprecations  []deprecation.Policy
+	alternates    map[string]fiber.Handler
+	// canaries is set by the canaries module when CANARY_ROUTES is set
+	canaries *canaryRoutes
+}
+
+// Use registers middleware run before every route, including other
+// modules' routes
+func (r *Routes) Use(handler fiber.Handler) {
+	r.middleware = append(r.middleware, handler)
+}
+
+// UseAuthenticated registers middleware run after authentication on the
+// protected and admin routes, e.g. to check the caller further
+func (r *Routes) UseAuthenticated(handler fiber.Handler) {
+	r.authenticated = append(r.authenticated, handler)
+}
+
+// Public registers routes that need no authentication
+func (r *Routes) Public(register func(router fiber.Router)) {
+	r.public = append(r.public, register)
+}
+
+// Protected registers routes that require authentication. Handlers read the
+// caller with c.Locals("user").
+func (r *Routes) Protected(register func(router fiber.Router)) {
+	r.protected = append(r.protected, register)
+}
+
+// Admin registers routes under /admin, restricted to users with the admin role
+func (r *Routes) Admin(register func(router fiber.Router)) {
+	r.admin = append(r.admin, register)
+}
+
+// GrantAdmin lets callers without the admin role through to the admin routes
+// when grant returns true, e.g. during a break-glass session
+func (r *Routes) GrantAdmin(grant func(c *fiber.Ctx) bool) {
+	r.adminGrants = append(r.adminGrants, grant)
+}
+
+// Deprecate marks one of the module's routes deprecated. Responses announce
+// it with the Deprecation, Sunset and Link headers and a "warning" field,
+// calls are counted per client at /admin/deprecations, and from the sunset
+// on the route answers 410 Gone. Admin routes are named with their /admin
+// prefix.
+func (r *Routes) Deprecate(policy deprecation.Policy) {
+	r.deprecations = append(r.deprecations, policy)
+}
+
+// Canary registers handler as the alternate implementation of a route,
+// e.g. a rewrite of "POST", "/login", served side by side with the stable
+// one. The route's cohort in CANARY_ROUTES is sent to it, in place of the
+// route's last handler. Admin routes are named with their /admin prefix.
+func (r *Routes) Canary(method, path string, handler fiber.Handler) {
+	if r.alternates == nil {
+		r.alternates = make(map[string]fiber.Handler)
+	}
+	r.alternates[canaryRoute(method, path)] = handler
+}
+
+// DefaultModules are the modules New uses unless WithModules is given
+func DefaultModules() []ModuleFunc {
+	return []ModuleFunc{DocsModule, UsersModule, QRLoginModule, ShareLinksModule, AccountReportsModule, AudiencesModule, ScimModule, AdminModule, DuplicatesModule, FraudModule, KYCModule, BreakGlassModule, EventsModule, AuditModule, ReadModelsModule, SchemaChangesModule, ClaimsModule, ReadCoalescingModule, AuthorizationModule, BackupsModule, ExportsModule, DigestsModule, BirthdaysModule, DeadLettersModule, WebhooksModule, InboundWebhooksModule, PlansModule, EntitlementsModule, PresenceModule, AutoscalingModule, DiagnosticsModule, SLOModule, AdmissionModule, LanesModule, RoutePolicyModule, RateLimitsModule, DeprecationsModule, CanariesModule, PayloadLoggingModule, RecordingsModule, ClientVersionsModule, ChaosModule, PlaygroundModule}
+}
+
+// WithModules serves the given modules instead of DefaultModules. Modules
+// register their routes in the order given.
+func WithModules(modules ...ModuleFunc) Option {
+	return func(o *options) {
+		o.modules = append(o.modules, modules...)
+	}
+}
+
+// The built-in modules keep their tables in the core migrations, which
+// predate modules; new modules bring their own.
+
+// baseModule provides the parts of Module that built-in modules do not use
+type baseModule struct {
+	name string
+}
+
+func (m baseModule) Name() string { return m.name }
+
+func (m baseModule) Migrations() []Migration { return nil }
+
+func (m baseModule) Routes(*Routes) {}
+
+func (m baseModule) Workers() []*Worker { return nil }
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"database/sql"
+
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/infrastructure/memory"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/deprecation"
+	"fiber-hello-world/pkg/hooks"
+	"fiber-hello-world/pkg/inbound"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Types needed to implement a custom user repository outside this module
+type (
+	User           = entity.User
+	UserRepository = repository.UserRepository
+	UserFilter     = repository.UserFilter
+	Page           = repository.Page
+)
+
+// Types needed to implement a custom identity verification provider
+type (
+	KYCProvider   = repository.KYCProvider
+	KYCSubmission = entity.KYCSubmission
+	KYCDecision   = entity.KYCDecision
+)
+
+// ErrEmailTaken must be returned by UserRepository.Create and the update
+// methods when the email belongs to another user
+var ErrEmailTaken = repository.ErrEmailTaken
+
+// NewMemoryUserRepository creates an in-memory user repository, e.g. for tests
+func NewMemoryUserRepository() UserRepository {
+	return memory.NewUserRepository()
+}
+
+// Option customizes a Server created by New
+type Option func(*options)
+
+type options struct {
+	db              *sql.DB
+	overrides       []func(*container.Container)
+	middleware      []fiber.Handler
+	routes          []func(fiber.Router)
+	protectedRoutes []func(fiber.Router)
+	deprecations    []deprecation.Policy
+	canaries        []registeredCanary
+	shadowUsers     UserRepository
+	schemaChanges   []SchemaChange
+	hooks           []registeredHook
+	inboundHandlers []registeredInboundHandler
+	modules         []ModuleFunc
+}
+
+type registeredCanary struct {
+	method  string
+	path    string
+	handler fiber.Handler
+}
+
+type registeredHook struct {
+	point hooks.Point
+	hook  hooks.Hook
+}
+
+type registeredInboundHandler struct {
+	provider  string
+	eventType string
+	handler   inbound.Handler
+}
+
+// WithDatabase uses db instead of opening cfg.DBPath. Migrations are applied
+// to it, and the caller stays responsible for closing it.
+func WithDatabase(db *sql.DB) Option {
+	return func(o *options) {
+		o.db = db
+	}
+}
+
+// WithUserRepository stores users in repo instead of the database. Revision
+// history, funnel and admin actions still use the database.
+func WithUserRepository(repo UserRepository) Option {
+	return Override[UserRepository](repo)
+}
+
+// WithShadowUserRepository mirrors every call to the database's users onto
+// repo, a new backend being migrated to, e.g. Postgres. Results still come
+// from the database; repo's failures and the fields its users differ in are
+// logged. Users must be copied to repo with their IDs first.
+func WithShadowUserRepository(repo UserRepository) Option {
+	return func(o *options) {
+		o.shadowUsers = repo
+	}
+}
+
+// WithKYCProvider verifies identity documents with provider instead of the
+// KYC_PROVIDER_URL service
+func WithKYCProvider(provider KYCProvider) Option {
+	return Override[KYCProvider](provider)
+}
+
+// SchemaChange is an online change to a large table: its rows are
+// backfilled in batches and its reads switched by an admin
+type SchemaChange = database.SchemaChange
+
+// WithSchemaChange backfills change, e.g. of a table added by a module,
+// alongside the core ones in database.SchemaChanges. Its new column or
+// table must already be added and written, and its reads follow the
+// *usecase.SchemaChangeUseCase from the container.
+func WithSchemaChange(change SchemaChange) Option {
+	return func(o *options) {
+		o.schemaChanges = append(o.schemaChanges, change)
+	}
+}
+
+// Override replaces the shared service of type T, such as a repository,
+// use case or *jwt.Service, with value. Services built from T use the
+// replacement, which makes it easy to swap dependencies in tests.
+func Override[T any](value T) Option {
+	return func(o *options) {
+		o.overrides = append(o.overrides, func(c *container.Container) {
+			container.Set(c, value)
+		})
+	}
+}
+
+// WithMiddleware runs handlers on every request, after the client IP is
+// resolved and before any route
+func WithMiddleware(handlers ...fiber.Handler) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, handlers...)
+	}
+}
+
+// WithRoutes registers additional public routes
+func WithRoutes(register func(router fiber.Router)) Option {
+	return func(o *options) {
+		o.routes = append(o.routes, register)
+	}
+}
+
+// WithProtectedRoutes registers additional routes that require
+// authentication. Handlers read the caller with c.Locals("user").
+func WithProtectedRoutes(register func(router fiber.Router)) Option {
+	return func(o *options) {
+		o.protectedRoutes = append(o.protectedRoutes, register)
+	}
+}
+
+// WithDeprecations marks routes deprecated, e.g. ones added with WithRoutes.
+// Modules mark theirs with Routes.Deprecate.
+func WithDeprecations(policies ...deprecation.Policy) Option {
+	return func(o *options) {
+		o.deprecations = append(o.deprecations, policies...)
+	}
+}
+
+// WithCanary registers handler as the alternate implementation of a route,
+// e.g. one added with WithRoutes, for the cohort in CANARY_ROUTES. Modules
+// register theirs with Routes.Canary.
+func WithCanary(method, path string, handler fiber.Handler) Option {
+	return func(o *options) {
+		o.canaries = append(o.canaries, registeredCanary{method: method, path: path, handler: handler})
+	}
+}
+
+// WithHook runs hook at point of the register, login or token issuance
+// flow. Hooks added this way run before webhooks from HOOK_WEBHOOKS.
+func WithHook(point hooks.Point, hook hooks.Hook) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, registeredHook{point: point, hook: hook})
+	}
+}
+
+// WithInboundHandler handles the events of eventType, or of any type with
+// inbound.AnyType, received from provider's webhooks, e.g. "stripe" and
+// "invoice.paid". The provider must be configured in INBOUND_WEBHOOK_SECRETS.
+func WithInboundHandler(provider, eventType string, handler inbound.Handler) Option {
+	return func(o *options) {
+		o.inboundHandlers = append(o.inboundHandlers, registeredInboundHandler{provider: provider, eventType: eventType, handler: handler})
+	}
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/pkg/payloadlog"
+	"fiber-hello-world/pkg/redact"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// payloadLoggingModule logs the bodies of requests to chosen routes
+type payloadLoggingModule struct {
+	baseModule
+	logger            *payloadlog.Logger
+	payloadLogHandler *handler.PayloadLogHandler
+}
+
+// PayloadLoggingModule lets admins log the request and response bodies of
+// chosen routes for a while through /admin/payload-logging, e.g. while
+// investigating an incident. Secrets, phone numbers and the fields in
+// PAYLOAD_LOG_REDACT are redacted.
+func PayloadLoggingModule(deps *Deps) (Module, error) {
+	logger := payloadlog.New(redact.New(deps.Config.PayloadLogRedact))
+	return &payloadLoggingModule{
+		baseModule:        baseModule{"payload-logging"},
+		logger:            logger,
+		payloadLogHandler: handler.NewPayloadLogHandler(logger, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *payloadLoggingModule) Routes(routes *Routes) {
+	routes.Use(middleware.PayloadLogMiddleware(m.logger))
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/payload-logging", m.payloadLogHandler.GetRoutes)
+		admin.Post("/payload-logging", m.payloadLogHandler.EnableRoute)
+		admin.Delete("/payload-logging", m.payloadLogHandler.DisableRoute)
+	})
+}
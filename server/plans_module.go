@@ -0,0 +1,54 @@
+package server
+
+import (
+	"log"
+	"strings"
+
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/inbound"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// plansModule moves users between plans
+type plansModule struct {
+	baseModule
+	planHandler *handler.PlanHandler
+}
+
+// PlansModule serves PUT /admin/users/:id/plan and, when Stripe is in
+// INBOUND_WEBHOOK_SECRETS, applies Stripe subscription events to the plans
+// of the users they name. It is enabled by PLAN_PRICES, which maps Stripe
+// price IDs to plans; without it every user stays on the free plan.
+func PlansModule(deps *Deps) (Module, error) {
+	if !deps.Config.PlansEnabled() {
+		return nil, nil
+	}
+
+	router, err := container.Get[*inbound.Router](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	planUseCase := usecase.NewPlanUseCase(deps.Users, deps.Config.PlanPrices)
+	if _, err := router.Provider("stripe"); err == nil {
+		for _, eventType := range usecase.StripeSubscriptionEvents {
+			router.Handle("stripe", eventType, inbound.HandlerFunc(planUseCase.ApplySubscription))
+		}
+	} else {
+		log.Printf("Stripe webhooks are not configured, so plans only change through the admin API")
+	}
+	log.Printf("Plans: %s", strings.Join(planUseCase.Plans(), ", "))
+
+	return &plansModule{
+		baseModule:  baseModule{"plans"},
+		planHandler: handler.NewPlanHandler(planUseCase, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *plansModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Put("/users/:id/plan", m.planHandler.SetUserPlan)
+	})
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"log"
+
+	"fiber-hello-world/internal/presentation/handler"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// playgroundModule serves the interactive API playground
+type playgroundModule struct {
+	baseModule
+	playgroundHandler *handler.PlaygroundHandler
+}
+
+// PlaygroundModule serves the API playground at /playground in development
+// when PLAYGROUND_ENABLED is set
+func PlaygroundModule(deps *Deps) (Module, error) {
+	if !deps.Config.IsDevelopment() || !deps.Config.PlaygroundEnabled {
+		return nil, nil
+	}
+
+	return &playgroundModule{
+		baseModule:        baseModule{"playground"},
+		playgroundHandler: handler.NewPlaygroundHandler(),
+	}, nil
+}
+
+func (m *playgroundModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		router.Get("/playground", m.playgroundHandler.Page)
+	})
+	log.Println("API playground enabled at /playground")
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// presenceModule tracks when users were last seen and serves their presence
+type presenceModule struct {
+	baseModule
+	interval        time.Duration
+	presenceUseCase *usecase.PresenceUseCase
+	presenceHandler *handler.PresenceHandler
+}
+
+// PresenceModule marks callers as seen on every authenticated request,
+// flushing the times in batches every PRESENCE_FLUSH_INTERVAL, and serves
+// whether users are online, recently active or away within the privacy
+// each user chooses. Enabled with PRESENCE_ENABLED.
+func PresenceModule(deps *Deps) (Module, error) {
+	if !deps.Config.PresenceEnabled {
+		return nil, nil
+	}
+
+	cfg := deps.Config
+	switch {
+	case cfg.PresenceOnlineWindow <= cfg.PresenceFlushInterval:
+		// Otherwise users active on other replicas would not show online
+		return nil, errors.New("invalid presence configuration: PRESENCE_ONLINE_WINDOW must be longer than PRESENCE_FLUSH_INTERVAL")
+	case cfg.PresenceRecentWindow < cfg.PresenceOnlineWindow:
+		return nil, errors.New("invalid presence configuration: PRESENCE_RECENT_WINDOW must not be shorter than PRESENCE_ONLINE_WINDOW")
+	}
+
+	presenceUseCase := usecase.NewPresenceUseCase(database.NewSQLitePresenceRepository(deps.DB), cfg.PresenceOnlineWindow, cfg.PresenceRecentWindow)
+	return &presenceModule{
+		baseModule:      baseModule{"presence"},
+		interval:        cfg.PresenceFlushInterval,
+		presenceUseCase: presenceUseCase,
+		presenceHandler: handler.NewPresenceHandler(presenceUseCase, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *presenceModule) Migrations() []Migration {
+	return database.PresenceMigrations
+}
+
+func (m *presenceModule) Routes(routes *Routes) {
+	routes.UseAuthenticated(middleware.PresenceMiddleware(m.presenceUseCase))
+	routes.Protected(func(router fiber.Router) {
+		router.Get("/presence", m.presenceHandler.GetPresence)
+		router.Get("/me/presence", m.presenceHandler.GetMyPresence)
+		router.Put("/me/presence", m.presenceHandler.SetMyVisibility)
+	})
+}
+
+// Workers flush on every instance, since each buffers its own callers
+func (m *presenceModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("presence", m.interval, func() error {
+			_, err := m.presenceUseCase.Flush()
+			return err
+		}),
+	}
+}
@@ -0,0 +1,620 @@
+package server
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"fiber-hello-world/config"
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/coalescing"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/infrastructure/encryption"
+	"fiber-hello-world/internal/infrastructure/kyc"
+	"fiber-hello-world/internal/infrastructure/mail"
+	"fiber-hello-world/internal/infrastructure/memory"
+	"fiber-hello-world/internal/infrastructure/openfga"
+	"fiber-hello-world/internal/infrastructure/redis"
+	"fiber-hello-world/internal/infrastructure/shadow"
+	"fiber-hello-world/internal/infrastructure/storage"
+	"fiber-hello-world/internal/presentation/schema"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/autoscale"
+	"fiber-hello-world/pkg/chaos"
+	"fiber-hello-world/pkg/clientip"
+	"fiber-hello-world/pkg/coalesce"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/decoder"
+	"fiber-hello-world/pkg/depcheck"
+	"fiber-hello-world/pkg/deprecation"
+	"fiber-hello-world/pkg/diagnostics"
+	"fiber-hello-world/pkg/entitlements"
+	"fiber-hello-world/pkg/hashpool"
+	"fiber-hello-world/pkg/hooks"
+	"fiber-hello-world/pkg/inbound"
+	"fiber-hello-world/pkg/jsonschema"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/recorder"
+	"fiber-hello-world/pkg/routepolicy"
+	"fiber-hello-world/pkg/screening"
+	"fiber-hello-world/pkg/slo"
+	"fiber-hello-world/pkg/timing"
+	"fiber-hello-world/pkg/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// provideServices registers how the shared repositories, use cases and
+// services are built. Nothing is built until requested, so Override can
+// replace any of them first.
+func provideServices(c *container.Container, cfg *config.Config, db *sql.DB, shadowUsers repository.UserRepository, schemaChanges []SchemaChange, registered []registeredHook, inboundHandlers []registeredInboundHandler) {
+	container.Set(c, cfg)
+	container.Set(c, db)
+
+	// Repositories
+	container.Provide(c, func(c *container.Container) (repository.UserRepository, error) {
+		opts, err := userRepositoryOptions(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid multi-region configuration: %w", err)
+		}
+		var users repository.UserRepository = database.NewSQLiteUserRepositoryWithOptions(db, opts)
+		if shadowUsers != nil {
+			// Below encryption, so both backends store the same ciphertext
+			users = shadow.NewUserRepository(users, shadowUsers)
+		}
+		if cfg.FieldEncryptionEnabled() {
+			keys, err := container.Get[repository.KeyStore](c)
+			if err != nil {
+				return nil, err
+			}
+			users = encryption.NewUserRepository(users, keys)
+		}
+		if cfg.OpenFGAEnabled() {
+			store, err := container.Get[repository.RelationshipStore](c)
+			if err != nil {
+				return nil, err
+			}
+			users = openfga.NewUserRepository(users, store)
+		}
+		if cfg.MissingUserCacheEnabled() {
+			missing := memory.NewMissingUserCache(users, cfg.MissingUserCacheTTL, cfg.MissingUserCacheSize)
+			collector, err := container.Get[*diagnostics.Collector](c)
+			if err != nil {
+				return nil, err
+			}
+			collector.RegisterCache("missing_users", missing.Len)
+			users = missing
+		}
+		// Outermost, so a coalesced read is also decrypted once
+		reads, err := container.Get[*coalesce.Group](c)
+		if err != nil {
+			return nil, err
+		}
+		return coalescing.NewUserRepository(users, reads), nil
+	})
+	container.Provide(c, func(*container.Container) (*coalesce.Group, error) {
+		return coalesce.New(), nil
+	})
+	container.Provide(c, func(c *container.Container) (repository.UserRevisionRepository, error) {
+		revisions := database.NewSQLiteUserRevisionRepository(db)
+		if !cfg.FieldEncryptionEnabled() {
+			return revisions, nil
+		}
+		keys, err := container.Get[repository.KeyStore](c)
+		if err != nil {
+			return nil, err
+		}
+		return encryption.NewRevisionRepository(revisions, keys), nil
+	})
+	container.Provide(c, func(*container.Container) (repository.KeyStore, error) {
+		return newKeyStore(cfg)
+	})
+	container.Provide(c, func(*container.Container) (repository.FunnelRepository, error) {
+		return database.NewSQLiteFunnelRepository(db), nil
+	})
+	container.Provide(c, func(*container.Container) (repository.AdminActionRepository, error) {
+		return database.NewSQLiteAdminActionRepository(db), nil
+	})
+	container.Provide(c, func(*container.Container) (repository.OneTimeTokenRepository, error) {
+		return database.NewSQLiteOneTimeTokenRepository(db), nil
+	})
+	container.Provide(c, func(*container.Container) (repository.LoginEventRepository, error) {
+		return database.NewSQLiteLoginEventRepository(db), nil
+	})
+	container.Provide(c, func(*container.Container) (repository.EventRepository, error) {
+		return database.NewSQLiteEventRepository(db), nil
+	})
+	container.Provide(c, func(*container.Container) (repository.AuditRepository, error) {
+		return database.NewSQLiteAuditRepository(db), nil
+	})
+	container.Provide(c, func(*container.Container) (repository.AvatarStorage, error) {
+		return storage.NewLocalAvatarStorage(cfg.UploadDir, "/uploads"), nil
+	})
+	container.Provide(c, func(*container.Container) (repository.BackupStore, error) {
+		return database.NewSQLiteBackupStore(db, cfg.BackupDir), nil
+	})
+	container.Provide(c, func(c *container.Container) (repository.SnapshotSource, error) {
+		// Snapshots hold personal data, so they stay next to the database
+		source := database.NewSQLiteSnapshotSource(db, filepath.Dir(cfg.DBPath))
+		if !cfg.FieldEncryptionEnabled() {
+			return source, nil
+		}
+		keys, err := container.Get[repository.KeyStore](c)
+		if err != nil {
+			return nil, err
+		}
+		return encryption.NewSnapshotSource(source, keys), nil
+	})
+	container.Provide(c, func(*container.Container) (repository.BlobStore, error) {
+		return newExportStore(cfg)
+	})
+	container.Provide(c, func(*container.Container) (repository.KYCProvider, error) {
+		// Without a provider every identity document is reviewed by an admin
+		if cfg.KYCProviderURL == "" {
+			return nil, nil
+		}
+		provider, err := kyc.NewHTTPProvider(kyc.Options{
+			URL:    cfg.KYCProviderURL,
+			Secret: cfg.KYCProviderSecret,
+			Client: &http.Client{Timeout: cfg.KYCProviderTimeout},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid KYC configuration: %w", err)
+		}
+		return provider, nil
+	})
+	container.Provide(c, func(*container.Container) (repository.Mailer, error) {
+		if cfg.SMTPAddr == "" {
+			return nil, fmt.Errorf("invalid mail configuration: SMTP_ADDR is not set")
+		}
+		mailer, err := mail.NewSMTPMailer(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mail configuration: %w", err)
+		}
+		return mailer, nil
+	})
+	container.Provide(c, func(*container.Container) (repository.RelationshipStore, error) {
+		if !cfg.OpenFGAEnabled() {
+			return nil, fmt.Errorf("invalid OpenFGA configuration: OPENFGA_API_URL is not set")
+		}
+		store, err := openfga.NewStore(openfga.Options{
+			APIURL:  cfg.OpenFGAAPIURL,
+			StoreID: cfg.OpenFGAStoreID,
+			ModelID: cfg.OpenFGAModelID,
+			Token:   cfg.OpenFGAToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid OpenFGA configuration: %w", err)
+		}
+		return store, nil
+	})
+
+	// Use cases
+	container.Provide(c, func(c *container.Container) (*usecase.UserUseCase, error) {
+		userRepo, err := container.Get[repository.UserRepository](c)
+		if err != nil {
+			return nil, err
+		}
+		revisionRepo, err := container.Get[repository.UserRevisionRepository](c)
+		if err != nil {
+			return nil, err
+		}
+		hookRegistry, err := container.Get[*hooks.Registry](c)
+		if err != nil {
+			return nil, err
+		}
+		hashPool, err := container.Get[*hashpool.Pool](c)
+		if err != nil {
+			return nil, err
+		}
+		eventRepo, err := container.Get[repository.EventRepository](c)
+		if err != nil {
+			return nil, err
+		}
+		auditRepo, err := container.Get[repository.AuditRepository](c)
+		if err != nil {
+			return nil, err
+		}
+
+		userUseCase := usecase.NewUserUseCase(userRepo, revisionRepo)
+		userUseCase.SetEventLog(eventRepo)
+		userUseCase.SetAuditLog(auditRepo)
+		userUseCase.SetHooks(hookRegistry)
+		userUseCase.SetHashPool(hashPool)
+		userUseCase.SetEnumerationProtection(cfg.EnumerationProtection)
+		userUseCase.SetResponseFloor(timing.New(cfg.AuthMinResponseTime, cfg.AuthResponseJitter))
+		if !entity.ValidCalendar(entity.Calendar(cfg.BirthdayCalendar)) {
+			return nil, fmt.Errorf("invalid birthday calendar %q, want %s or %s", cfg.BirthdayCalendar, entity.CalendarGregorian, entity.CalendarBuddhist)
+		}
+		userUseCase.SetBirthdayCalendar(entity.Calendar(cfg.BirthdayCalendar))
+		if cfg.NameScreening {
+			screener, err := container.Get[*screening.Screener](c)
+			if err != nil {
+				return nil, err
+			}
+			userUseCase.SetNameScreener(screener)
+		}
+		return userUseCase, nil
+	})
+	container.Provide(c, func(c *container.Container) (*usecase.TokenUseCase, error) {
+		tokenRepo, err := container.Get[repository.OneTimeTokenRepository](c)
+		if err != nil {
+			return nil, err
+		}
+		return usecase.NewTokenUseCase(tokenRepo), nil
+	})
+	container.Provide(c, func(c *container.Container) (*usecase.AuthorizationUseCase, error) {
+		store, err := container.Get[repository.RelationshipStore](c)
+		if err != nil {
+			return nil, err
+		}
+		userRepo, err := container.Get[repository.UserRepository](c)
+		if err != nil {
+			return nil, err
+		}
+		return usecase.NewAuthorizationUseCase(store, userRepo), nil
+	})
+	container.Provide(c, func(c *container.Container) (*usecase.EventUseCase, error) {
+		eventRepo, err := container.Get[repository.EventRepository](c)
+		if err != nil {
+			return nil, err
+		}
+		return usecase.NewEventUseCase(eventRepo), nil
+	})
+	container.Provide(c, func(c *container.Container) (*usecase.EntitlementsUseCase, error) {
+		if !cfg.EntitlementsEnabled() {
+			return nil, fmt.Errorf("invalid entitlements configuration: ENTITLEMENTS_FILE is not set")
+		}
+		policy, err := entitlements.Load(cfg.EntitlementsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load entitlements: %w", err)
+		}
+		userUseCase, err := container.Get[*usecase.UserUseCase](c)
+		if err != nil {
+			return nil, err
+		}
+		auditRepo, err := container.Get[repository.AuditRepository](c)
+		if err != nil {
+			return nil, err
+		}
+		entitlementsUseCase := usecase.NewEntitlementsUseCase(policy, database.NewSQLiteEntitlementOverrideRepository(db), userUseCase, cfg.EntitlementsCacheTTL)
+		entitlementsUseCase.SetAuditLog(auditRepo)
+		return entitlementsUseCase, nil
+	})
+	container.Provide(c, func(*container.Container) (*usecase.SchemaChangeUseCase, error) {
+		changes := append(append([]SchemaChange(nil), database.SchemaChanges...), schemaChanges...)
+		names := make([]string, 0, len(changes))
+		for _, change := range changes {
+			if change.Name == "" || change.Table == "" || change.Backfill == "" {
+				return nil, fmt.Errorf("invalid schema change %q: name, table and backfill are required", change.Name)
+			}
+			if slices.Contains(names, change.Name) {
+				return nil, fmt.Errorf("invalid schema change %q: listed twice", change.Name)
+			}
+			names = append(names, change.Name)
+		}
+		return usecase.NewSchemaChangeUseCase(database.NewSQLiteSchemaChangeRepository(db, changes), names, cfg.SchemaBackfillBatch), nil
+	})
+	container.Provide(c, func(c *container.Container) (*usecase.FunnelUseCase, error) {
+		funnelRepo, err := container.Get[repository.FunnelRepository](c)
+		if err != nil {
+			return nil, err
+		}
+		return usecase.NewFunnelUseCase(funnelRepo), nil
+	})
+
+	// Services
+	container.Provide(c, func(*container.Container) (*hooks.Registry, error) {
+		// Lifecycle hooks from options, policy scripts and webhooks
+		return newHookRegistry(cfg, registered)
+	})
+	container.Provide(c, func(*container.Container) (*inbound.Router, error) {
+		// Providers of inbound webhooks and handlers from options
+		return newInboundRouter(cfg, inboundHandlers)
+	})
+	container.Provide(c, func(c *container.Container) (*jwt.Service, error) {
+		userUseCase, err := container.Get[*usecase.UserUseCase](c)
+		if err != nil {
+			return nil, err
+		}
+		hookRegistry, err := container.Get[*hooks.Registry](c)
+		if err != nil {
+			return nil, err
+		}
+
+		jwtService := jwt.NewService(cfg.JWTSecret)
+		jwtService.RegisterClaimsProvider("role", jwt.ClaimsProviderFunc(func(req jwt.ClaimsRequest) (map[string]interface{}, error) {
+			user, err := userUseCase.GetUserByID(req.UserID)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"role": user.Role}, nil
+		}))
+		jwtService.RegisterClaimsProvider("plan", jwt.ClaimsProviderFunc(func(req jwt.ClaimsRequest) (map[string]interface{}, error) {
+			user, err := userUseCase.GetUserByID(req.UserID)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"plan": user.Plan}, nil
+		}))
+		jwtService.RegisterClaimsProvider("hooks", hookRegistry.ClaimsProvider())
+		return jwtService, nil
+	})
+	// The route policy is nil unless ROUTE_POLICY_FILE is set; programs
+	// embedding the server can pass one with Override instead
+	container.Provide(c, func(*container.Container) (*routepolicy.Policy, error) {
+		if !cfg.RoutePolicyEnabled() {
+			return nil, nil
+		}
+		policy, err := routepolicy.Load(cfg.RoutePolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load route policy: %w", err)
+		}
+		return policy, nil
+	})
+	container.Provide(c, func(*container.Container) (*validator.Service, error) {
+		service := validator.NewService()
+		if err := service.SetLocale(cfg.Locale); err != nil {
+			return nil, fmt.Errorf("invalid locale configuration: %w", err)
+		}
+		return service, nil
+	})
+	container.Provide(c, func(*container.Container) (*decoder.Service, error) {
+		return decoder.NewService(cfg.MaxBodyBytes, cfg.MaxJSONDepth), nil
+	})
+	container.Provide(c, func(*container.Container) (*jsonschema.Service, error) {
+		schemaService := jsonschema.NewService()
+		if err := schemaService.RegisterFS(schema.Files); err != nil {
+			return nil, fmt.Errorf("failed to load request schemas: %w", err)
+		}
+		if err := schemaService.SetLocale(cfg.Locale); err != nil {
+			return nil, fmt.Errorf("invalid locale configuration: %w", err)
+		}
+		return schemaService, nil
+	})
+	container.Provide(c, func(*container.Container) (*clientip.Resolver, error) {
+		ipResolver, err := clientip.NewResolver(cfg.TrustedProxies)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy configuration: %w", err)
+		}
+		return ipResolver, nil
+	})
+	container.Provide(c, func(*container.Container) (*hashpool.Pool, error) {
+		return hashpool.New(cfg.HashPoolSize), nil
+	})
+	container.Provide(c, func(c *container.Container) (*autoscale.Signals, error) {
+		hashPool, err := container.Get[*hashpool.Pool](c)
+		if err != nil {
+			return nil, err
+		}
+		return autoscale.New(hashPool), nil
+	})
+	container.Provide(c, func(c *container.Container) (*diagnostics.Collector, error) {
+		signals, err := container.Get[*autoscale.Signals](c)
+		if err != nil {
+			return nil, err
+		}
+		return diagnostics.New(db, signals), nil
+	})
+	container.Provide(c, func(*container.Container) (*screening.Screener, error) {
+		opts := screening.Options{Reserved: cfg.NameReserved}
+		if cfg.NameBlocklist != "" {
+			blocklist, err := screening.LoadBlocklist(cfg.NameBlocklist)
+			if err != nil {
+				return nil, fmt.Errorf("invalid name screening configuration: %w", err)
+			}
+			opts.Blocklist = blocklist
+		}
+		return screening.New(opts), nil
+	})
+	container.Provide(c, func(*container.Container) (*chaos.Injector, error) {
+		return chaos.New(), nil
+	})
+	container.Provide(c, func(*container.Container) (*deprecation.Registry, error) {
+		return deprecation.New(), nil
+	})
+	container.Provide(c, func(*container.Container) (*slo.Tracker, error) {
+		return newSLOTracker(cfg)
+	})
+	container.Provide(c, func(c *container.Container) (repository.Locker, error) {
+		locker, err := newLocker(cfg, db)
+		if err != nil {
+			return nil, err
+		}
+		if redisLocker, ok := locker.(*redis.Locker); ok {
+			dependencies, err := container.Get[*depcheck.Checker](c)
+			if err != nil {
+				return nil, err
+			}
+			// Exclusive workers pause everywhere while no lease can be taken
+			dependencies.RegisterPinger("redis:worker-lock", redisLocker, "exclusive workers (paused)")
+		}
+		return locker, nil
+	})
+	container.Provide(c, func(*container.Container) (*depcheck.Checker, error) {
+		return depcheck.New(cfg.DependencyCheckTimeout), nil
+	})
+	container.Provide(c, func(*container.Container) (*recorder.Recorder, error) {
+		if cfg.RecordingFile != "" {
+			return recorder.Open(cfg.RecordingSize, cfg.RecordingFile)
+		}
+		return recorder.New(cfg.RecordingSize)
+	})
+}
+
+// newSLOTracker builds the tracker of the SLO_OBJECTIVES over SLO_WINDOW.
+// Objectives are ordered by route, so the first match is the same on every
+// node.
+func newSLOTracker(cfg *config.Config) (*slo.Tracker, error) {
+	routes := make([]string, 0, len(cfg.SLOObjectives))
+	for route := range cfg.SLOObjectives {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	objectives := make([]slo.Objective, len(routes))
+	for i, route := range routes {
+		objective, err := slo.ParseObjective(route, cfg.SLOObjectives[route])
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLO configuration: %w", err)
+		}
+		objectives[i] = objective
+	}
+	tracker, err := slo.New(objectives, cfg.SLOWindow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLO configuration: %w", err)
+	}
+	tracker.SetLoadShedding(cfg.SLOLoadShedding)
+	return tracker, nil
+}
+
+// newKeyStore builds the store of per-user encryption keys in FIELD_KEY_DIR.
+// Keys inside BACKUP_DIR would be backed up with the data they protect.
+func newKeyStore(cfg *config.Config) (repository.KeyStore, error) {
+	if cfg.BackupsEnabled() {
+		backupDir, err := filepath.Abs(cfg.BackupDir)
+		if err != nil {
+			return nil, err
+		}
+		keyDir, err := filepath.Abs(cfg.FieldKeyDir)
+		if err != nil {
+			return nil, err
+		}
+		if rel, err := filepath.Rel(backupDir, keyDir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("invalid field encryption configuration: FIELD_KEY_DIR must not be inside BACKUP_DIR")
+		}
+	}
+	return storage.NewFileKeyStore(cfg.FieldKeyDir), nil
+}
+
+// newExportStore builds the object store named by EXPORT_STORE, encrypting
+// objects when EXPORT_ENCRYPTION_KEY is set
+func newExportStore(cfg *config.Config) (repository.BlobStore, error) {
+	store, err := newBlobStore(cfg, "EXPORT", cfg.ExportStore, cfg.ExportDir, cfg.ExportS3Bucket, cfg.ExportEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid export configuration: %w", err)
+	}
+	return store, nil
+}
+
+// newKYCStore builds the object store named by KYC_STORE identity documents
+// are kept in, encrypting them when KYC_ENCRYPTION_KEY is set
+func newKYCStore(cfg *config.Config) (repository.BlobStore, error) {
+	store, err := newBlobStore(cfg, "KYC", cfg.KYCStore, cfg.KYCDir, cfg.KYCS3Bucket, cfg.KYCEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KYC configuration: %w", err)
+	}
+	return store, nil
+}
+
+// newBlobStore builds a "file" store in dir or an "s3" store in bucket,
+// encrypted with the base64 encryptionKey when set. S3 stores share the
+// EXPORT_S3_* endpoint, region, credentials and server-side encryption;
+// prefix names the other settings in errors.
+func newBlobStore(cfg *config.Config, prefix, kind, dir, bucket, encryptionKey string) (repository.BlobStore, error) {
+	var store repository.BlobStore
+	switch kind {
+	case "file":
+		store = storage.NewLocalBlobStore(dir)
+	case "s3":
+		endpoint := cfg.ExportS3Endpoint
+		if endpoint == "" {
+			endpoint = "https://s3." + cfg.ExportS3Region + ".amazonaws.com"
+		}
+		s3Store, err := storage.NewS3BlobStore(storage.S3Options{
+			Endpoint:             endpoint,
+			Region:               cfg.ExportS3Region,
+			Bucket:               bucket,
+			AccessKey:            cfg.ExportS3AccessKey,
+			SecretKey:            cfg.ExportS3SecretKey,
+			ServerSideEncryption: cfg.ExportS3SSE,
+		})
+		if err != nil {
+			return nil, err
+		}
+		store = s3Store
+	default:
+		return nil, fmt.Errorf("%s_STORE must be \"file\" or \"s3\", got %q", prefix, kind)
+	}
+
+	if encryptionKey == "" {
+		return store, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s_ENCRYPTION_KEY must be base64: %w", prefix, err)
+	}
+	return storage.NewEncryptedBlobStore(store, key)
+}
+
+// newLocker builds the WORKER_LOCK store exclusive workers take their
+// leases from, or returns nil when it is not set and every instance runs
+// them
+func newLocker(cfg *config.Config, db *sql.DB) (repository.Locker, error) {
+	if !cfg.WorkerLockEnabled() {
+		return nil, nil
+	}
+	owner, err := lockOwner()
+	if err != nil {
+		return nil, err
+	}
+	switch cfg.WorkerLock {
+	case "database":
+		return database.NewSQLiteLocker(db, owner), nil
+	case "redis":
+		locker, err := redis.NewLocker(cfg.WorkerLockRedisURL, owner)
+		if err != nil {
+			return nil, fmt.Errorf("invalid worker lock configuration: WORKER_LOCK_REDIS_URL: %w", err)
+		}
+		return locker, nil
+	}
+	return nil, fmt.Errorf("invalid worker lock configuration: WORKER_LOCK must be \"database\" or \"redis\", got %q", cfg.WorkerLock)
+}
+
+// lockOwner names this instance in the leases it holds: its host name and a
+// random suffix, so a restarted process does not inherit the old one's leases
+func lockOwner() (string, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "api"
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return host + "-" + hex.EncodeToString(suffix), nil
+}
+
+// newDeps resolves the services shared by modules
+func newDeps(c *container.Container, requireSignature fiber.Handler) (*Deps, error) {
+	var err error
+	deps := &Deps{Container: c, RequireSignature: requireSignature}
+	resolve(c, &deps.Config, &err)
+	resolve(c, &deps.DB, &err)
+	resolve(c, &deps.UserRepo, &err)
+	resolve(c, &deps.RevisionRepo, &err)
+	resolve(c, &deps.Users, &err)
+	resolve(c, &deps.Funnel, &err)
+	resolve(c, &deps.JWT, &err)
+	resolve(c, &deps.Validator, &err)
+	resolve(c, &deps.Decoder, &err)
+	resolve(c, &deps.Schemas, &err)
+	resolve(c, &deps.Locker, &err)
+	return deps, err
+}
+
+// resolve sets *dst from the container unless an earlier lookup failed
+func resolve[T any](c *container.Container, dst *T, err *error) {
+	if *err == nil {
+		*dst, *err = container.Get[T](c)
+	}
+}
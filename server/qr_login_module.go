@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// qrLoginCleanupInterval is how often expired QR logins are deleted
+const qrLoginCleanupInterval = 10 * time.Minute
+
+// qrLoginModule signs desktops in by QR code
+type qrLoginModule struct {
+	baseModule
+	locker         repository.Locker
+	qrLoginUseCase *usecase.QRLoginUseCase
+	qrLoginHandler *handler.QRLoginHandler
+}
+
+// QRLoginModule serves /auth/qr: a desktop shows a QR code that a signed-in
+// device scans and approves, and the desktop polls until it gets a token.
+// Codes are valid for QR_LOGIN_TTL.
+func QRLoginModule(deps *Deps) (Module, error) {
+	if deps.Config.QRLoginTTL <= 0 {
+		return nil, fmt.Errorf("invalid QR login configuration: QR_LOGIN_TTL must be positive, got %v", deps.Config.QRLoginTTL)
+	}
+	loginEventRepo, err := container.Get[repository.LoginEventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	securityUseCase := usecase.NewSecurityUseCase(loginEventRepo, deps.UserRepo)
+
+	qrLoginUseCase := usecase.NewQRLoginUseCase(database.NewSQLiteQRLoginRepository(deps.DB), deps.Users, deps.Config.QRLoginTTL)
+	return &qrLoginModule{
+		baseModule:     baseModule{"qr-login"},
+		locker:         deps.Locker,
+		qrLoginUseCase: qrLoginUseCase,
+		qrLoginHandler: handler.NewQRLoginHandler(qrLoginUseCase, deps.Funnel, securityUseCase, deps.JWT, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *qrLoginModule) Migrations() []Migration {
+	return database.QRLoginMigrations
+}
+
+func (m *qrLoginModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		router.Post("/auth/qr/start", m.qrLoginHandler.Start)
+		router.Post("/auth/qr/status", m.qrLoginHandler.Poll)
+	})
+	routes.Protected(func(router fiber.Router) {
+		router.Post("/auth/qr/scan", m.qrLoginHandler.Scan)
+		router.Post("/auth/qr/approve", m.qrLoginHandler.Approve)
+		router.Post("/auth/qr/deny", m.qrLoginHandler.Deny)
+	})
+}
+
+func (m *qrLoginModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("qr-logins", qrLoginCleanupInterval, func() error {
+			_, err := m.qrLoginUseCase.DeleteExpired()
+			return err
+		}).Exclusive(m.locker),
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/ratelimit"
+)
+
+// rateLimitsModule limits how often each client calls some routes
+type rateLimitsModule struct {
+	baseModule
+	limiter *ratelimit.Limiter
+	jwt     *jwt.Service
+}
+
+// RateLimitsModule limits the calls to the routes in RATE_LIMITS per client,
+// announcing the limit with the X-RateLimit-* and RateLimit-* headers and
+// warning clients that used 80% of it, so they back off before a 429
+func RateLimitsModule(deps *Deps) (Module, error) {
+	if !deps.Config.RateLimitsEnabled() {
+		return nil, nil
+	}
+
+	rules, err := ratelimit.ParseRules(deps.Config.RateLimits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit configuration: %w", err)
+	}
+	limiter, err := ratelimit.New(rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit configuration: %w", err)
+	}
+	return &rateLimitsModule{
+		baseModule: baseModule{"rate-limits"},
+		limiter:    limiter,
+		jwt:        deps.JWT,
+	}, nil
+}
+
+func (m *rateLimitsModule) Routes(routes *Routes) {
+	routes.Use(middleware.RateLimitMiddleware(m.limiter, m.jwt))
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/pkg/coalesce"
+	"fiber-hello-world/pkg/container"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// readCoalescingModule reports on coalesced reads of users
+type readCoalescingModule struct {
+	baseModule
+	coalescingHandler *handler.CoalescingHandler
+}
+
+// ReadCoalescingModule serves /admin/read-coalescing, reporting how many
+// reads of users shared a concurrent identical read. Reads are coalesced
+// whether or not it is served.
+func ReadCoalescingModule(deps *Deps) (Module, error) {
+	reads, err := container.Get[*coalesce.Group](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	return &readCoalescingModule{
+		baseModule:        baseModule{"read-coalescing"},
+		coalescingHandler: handler.NewCoalescingHandler(reads),
+	}, nil
+}
+
+func (m *readCoalescingModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/read-coalescing", m.coalescingHandler.GetStats)
+	})
+}
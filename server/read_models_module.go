@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// readModelsModule keeps the read models behind heavy admin queries
+type readModelsModule struct {
+	baseModule
+	deps             *Deps
+	readModelUseCase *usecase.ReadModelUseCase
+	readModelHandler *handler.ReadModelHandler
+}
+
+// ReadModelsModule projects the domain event log into the user_search and
+// login_stats read models, one worker each, and serves
+// /admin/users/search from them
+func ReadModelsModule(deps *Deps) (Module, error) {
+	eventRepo, err := container.Get[repository.EventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	userRepo, err := container.Get[repository.UserRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	readModelUseCase := usecase.NewReadModelUseCase(database.NewSQLiteReadModelRepository(deps.DB), eventRepo, userRepo)
+	return &readModelsModule{
+		baseModule:       baseModule{"read-models"},
+		deps:             deps,
+		readModelUseCase: readModelUseCase,
+		readModelHandler: handler.NewReadModelHandler(readModelUseCase),
+	}, nil
+}
+
+func (m *readModelsModule) Migrations() []Migration {
+	return database.ReadModelMigrations
+}
+
+func (m *readModelsModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/users/search", m.readModelHandler.SearchUsers)
+		admin.Get("/users/export", m.readModelHandler.ExportUsers)
+		admin.Get("/read-models", m.readModelHandler.ListProjections)
+		admin.Post("/read-models/:name/rebuild", m.readModelHandler.RebuildProjection)
+	})
+}
+
+func (m *readModelsModule) Workers() []*Worker {
+	workers := make([]*Worker, 0, len(entity.Projections))
+	for _, projection := range entity.Projections {
+		workers = append(workers, worker.New("projection-"+projection, m.deps.Config.WorkerInterval, func() error {
+			_, err := m.readModelUseCase.Project(projection)
+			return err
+		}).Exclusive(m.deps.Locker))
+	}
+	return workers
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/recorder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// recordingsPath is where admins inspect and replay recorded requests; it
+// is never recorded itself
+const recordingsPath = "/admin/recordings"
+
+// recordingsModule records requests so bugs can be reproduced
+type recordingsModule struct {
+	baseModule
+	recorder         *recorder.Recorder
+	recordingHandler *handler.RecordingHandler
+}
+
+// RecordingsModule records requests and their responses, with secrets
+// redacted, and lets admins inspect and replay them through
+// /admin/recordings when RECORDING_ENABLED is set. It refuses to run in
+// production.
+func RecordingsModule(deps *Deps) (Module, error) {
+	if !deps.Config.RecordingEnabled {
+		return nil, nil
+	}
+	if deps.Config.Env == "production" {
+		return nil, errors.New("request recording cannot be enabled in production: unset RECORDING_ENABLED")
+	}
+
+	rec, err := container.Get[*recorder.Recorder](deps.Container)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recording configuration: %w", err)
+	}
+
+	return &recordingsModule{
+		baseModule:       baseModule{"recordings"},
+		recorder:         rec,
+		recordingHandler: handler.NewRecordingHandler(rec, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *recordingsModule) Routes(routes *Routes) {
+	routes.Use(middleware.RecordingMiddleware(m.recorder, recordingsPath))
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/recordings", m.recordingHandler.ListRecordings)
+		admin.Delete("/recordings", m.recordingHandler.ClearRecordings)
+		admin.Get("/recordings/:id", m.recordingHandler.GetRecording)
+		admin.Post("/recordings/:id/replay", m.recordingHandler.Replay)
+	})
+	log.Printf("Request recording enabled, replay requests at %s", recordingsPath)
+}
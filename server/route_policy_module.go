@@ -0,0 +1,40 @@
+package server
+
+import (
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/routepolicy"
+)
+
+// routePolicyModule enforces the policy of each group of routes
+type routePolicyModule struct {
+	baseModule
+	policy *routepolicy.Policy
+	jwt    *jwt.Service
+	users  *usecase.UserUseCase
+}
+
+// RoutePolicyModule enforces the CORS rules, rate limits, roles and scopes
+// of the route groups in ROUTE_POLICY_FILE, or of the policy given with
+// Override, in one middleware run before every route
+func RoutePolicyModule(deps *Deps) (Module, error) {
+	policy, err := container.Get[*routepolicy.Policy](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, nil
+	}
+	return &routePolicyModule{
+		baseModule: baseModule{"route-policy"},
+		policy:     policy,
+		jwt:        deps.JWT,
+		users:      deps.Users,
+	}, nil
+}
+
+func (m *routePolicyModule) Routes(routes *Routes) {
+	routes.Use(middleware.RoutePolicyMiddleware(m.policy, m.jwt, m.users))
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"log"
+
+	"fiber-hello-world/config"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/autoscale"
+	"fiber-hello-world/pkg/clientip"
+	"fiber-hello-world/pkg/depcheck"
+	"fiber-hello-world/pkg/deprecation"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/mtls"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// routeDeps are the services the shared middleware is wired to
+type routeDeps struct {
+	ipResolver       *clientip.Resolver
+	signals          *autoscale.Signals
+	deprecations     *deprecation.Registry
+	jwtService       *jwt.Service
+	userUseCase      *usecase.UserUseCase
+	requireSignature fiber.Handler
+	readiness        *readiness
+	dependencies     *depcheck.Checker
+}
+
+// registerRoutes registers the shared middleware and the collected routes
+// on app: public routes first, then the authenticated and admin groups
+func registerRoutes(app *fiber.App, cfg *config.Config, handlers []fiber.Handler, routes *Routes, d routeDeps) {
+	// Probes come first, so no middleware counts, records or fails them
+	registerProbes(app, d.readiness, d.dependencies)
+
+	// Count requests in flight and resolve the real client IP before any
+	// other middleware
+	app.Use(middleware.InFlightMiddleware(d.signals))
+	app.Use(middleware.ClientIPMiddleware(d.ipResolver, cfg.GeoCountryHeader))
+	// Announce deprecated routes and count their calls, also when a later
+	// middleware rejects the request
+	app.Use(middleware.DeprecationMiddleware(d.deprecations))
+	for _, handler := range handlers {
+		app.Use(handler)
+	}
+	for _, handler := range routes.middleware {
+		app.Use(handler)
+	}
+
+	// @Summary Get hello world message
+	// @Description Returns a simple hello world JSON response
+	// @Tags general
+	// @Accept json
+	// @Produce json
+	// @Success 200 {object} map[string]string
+	// @Router / [get]
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"message": "Hello World - Clean Architecture",
+			"version": "2.0",
+		})
+	})
+
+	// Public routes are registered before the JWT-protected group, which
+	// matches every path
+	for _, register := range routes.public {
+		register(routes.canaries.wrap(app, ""))
+	}
+
+	// Protected routes. With mTLS, internal callers may authenticate with a
+	// client certificate instead of a bearer token.
+	authMiddleware := []fiber.Handler{middleware.JWTMiddleware(d.jwtService)}
+	if cfg.MTLSEnabled() {
+		certAuth := middleware.ClientCertMiddleware(mtls.NewMapper(cfg.MTLSIdentities), d.userUseCase)
+		authMiddleware = append([]fiber.Handler{certAuth}, authMiddleware...)
+		log.Println("Client certificate authentication enabled")
+	}
+	authMiddleware = append(authMiddleware, routes.authenticated...)
+	protected := app.Group("/", authMiddleware...)
+	for _, register := range routes.protected {
+		register(routes.canaries.wrap(protected, "/"))
+	}
+
+	// Admin routes
+	if len(routes.admin) > 0 {
+		admin := protected.Group("/admin", middleware.AdminMiddleware(d.userUseCase, routes.adminGrants...), d.requireSignature)
+		for _, register := range routes.admin {
+			register(routes.canaries.wrap(admin, "/admin"))
+		}
+	}
+}
+
+// registerProbes registers the liveness and readiness probes
+func registerProbes(app *fiber.App, readiness *readiness, dependencies *depcheck.Checker) {
+	// @Summary Liveness probe
+	// @Description Returns 200 while the process is serving requests
+	// @Tags general
+	// @Produce json
+	// @Success 200 {object} map[string]string
+	// @Router /livez [get]
+	app.Get("/livez", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok"})
+	})
+
+	// @Summary Readiness probe
+	// @Description Returns 200 once the server has warmed up: database connections opened and primed, optional dependencies checked and module caches loaded. Returns 503 while warming up and once shutdown has started. While an optional dependency such as Redis or SMTP is down the status is "degraded", still with 200, and its entry in dependencies lists the features disabled without it.
+	// @Tags general
+	// @Produce json
+	// @Success 200 {object} dto.ReadinessResponse
+	// @Failure 503 {object} dto.ReadinessResponse
+	// @Router /readyz [get]
+	app.Get("/readyz", func(c *fiber.Ctx) error {
+		resp := dto.ReadinessResponse{Status: "ready", Dependencies: dependencyStatuses(dependencies)}
+		if err := readiness.Ready(); err != nil {
+			resp.Status, resp.Reason = "not ready", err.Error()
+			return c.Status(503).JSON(resp)
+		}
+		if dependencies.Degraded() {
+			resp.Status = "degraded"
+		}
+		return c.JSON(resp)
+	})
+}
+
+// dependencyStatuses lists the optional dependencies for /readyz
+func dependencyStatuses(dependencies *depcheck.Checker) []dto.DependencyStatus {
+	statuses := make([]dto.DependencyStatus, 0, dependencies.Len())
+	for _, status := range dependencies.Statuses() {
+		statuses = append(statuses, dto.DependencyStatus{
+			Name:      status.Name,
+			Up:        status.Up,
+			Features:  status.Features,
+			Error:     status.Error,
+			Since:     status.Since,
+			CheckedAt: status.CheckedAt,
+		})
+	}
+	return statuses
+}
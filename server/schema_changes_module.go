@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// schemaChangesModule backfills the online schema changes of large tables
+type schemaChangesModule struct {
+	baseModule
+	deps                *Deps
+	schemaChangeUseCase *usecase.SchemaChangeUseCase
+	schemaChangeHandler *handler.SchemaChangeHandler
+}
+
+// SchemaChangesModule backfills the changes in database.SchemaChanges and
+// from WithSchemaChange a batch per WORKER_INTERVAL, and lets admins switch
+// their reads at /admin/schema-changes
+func SchemaChangesModule(deps *Deps) (Module, error) {
+	schemaChangeUseCase, err := container.Get[*usecase.SchemaChangeUseCase](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	return &schemaChangesModule{
+		baseModule:          baseModule{"schema-changes"},
+		deps:                deps,
+		schemaChangeUseCase: schemaChangeUseCase,
+		schemaChangeHandler: handler.NewSchemaChangeHandler(schemaChangeUseCase, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *schemaChangesModule) Migrations() []Migration {
+	return database.SchemaChangeMigrations
+}
+
+func (m *schemaChangesModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/schema-changes", m.schemaChangeHandler.ListChanges)
+		admin.Put("/schema-changes/:name/reads", m.schemaChangeHandler.SwitchReads)
+	})
+}
+
+// WarmUp loads which changes read the new schema before the first request
+func (m *schemaChangesModule) WarmUp() error {
+	return m.schemaChangeUseCase.Refresh()
+}
+
+func (m *schemaChangesModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("schema-backfills", m.deps.Config.WorkerInterval, func() error {
+			_, err := m.schemaChangeUseCase.Backfill()
+			return err
+		}).Exclusive(m.deps.Locker),
+		// Every instance picks up the reads switched on another one
+		worker.New("schema-reads", m.deps.Config.WorkerInterval, m.schemaChangeUseCase.Refresh),
+	}
+}
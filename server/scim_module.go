@@ -0,0 +1,43 @@
+package server
+
+import (
+	"log"
+
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// scimModule serves SCIM provisioning for identity providers
+type scimModule struct {
+	baseModule
+	token       string
+	scimHandler *handler.ScimHandler
+}
+
+// ScimModule serves SCIM provisioning at /scim/v2 when SCIM_TOKEN is set
+func ScimModule(deps *Deps) (Module, error) {
+	if !deps.Config.ScimEnabled() {
+		return nil, nil
+	}
+
+	provisioningUseCase := usecase.NewProvisioningUseCase(deps.UserRepo, deps.Users)
+	return &scimModule{
+		baseModule:  baseModule{"scim"},
+		token:       deps.Config.ScimToken,
+		scimHandler: handler.NewScimHandler(provisioningUseCase, deps.Decoder),
+	}, nil
+}
+
+func (m *scimModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		scimRoutes := router.Group("/scim/v2", middleware.ScimMiddleware(m.token))
+		scimRoutes.Get("/Users", m.scimHandler.ListUsers)
+		scimRoutes.Post("/Users", m.scimHandler.CreateUser)
+		scimRoutes.Get("/Users/:id", m.scimHandler.GetUser)
+		scimRoutes.Patch("/Users/:id", m.scimHandler.PatchUser)
+	})
+	log.Println("SCIM provisioning enabled at /scim/v2")
+}
@@ -0,0 +1,469 @@
+// Package server assembles the authentication API so it can run as the
+// api command or be embedded in another Go program.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"fiber-hello-world/config"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/infrastructure/redis"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/pkg/autoscale"
+	"fiber-hello-world/pkg/clientip"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/depcheck"
+	"fiber-hello-world/pkg/deprecation"
+	"fiber-hello-world/pkg/diagnostics"
+	"fiber-hello-world/pkg/encoder"
+	"fiber-hello-world/pkg/hooks"
+	"fiber-hello-world/pkg/idgen"
+	"fiber-hello-world/pkg/inbound"
+	"fiber-hello-world/pkg/listener"
+	"fiber-hello-world/pkg/signature"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrNotListening is returned by Restart before Listen has opened a socket
+var ErrNotListening = errors.New("server is not listening")
+
+// Server is the authentication API with its modules and background workers
+type Server struct {
+	cfg        *config.Config
+	app        *fiber.App
+	encodeJSON func(v interface{}) ([]byte, error)
+	db         *sql.DB
+	ownsDB     bool
+	stopWorker context.CancelFunc
+	workers    sync.WaitGroup
+	readiness  *readiness
+	// dependencies are the optional services features were disabled
+	// without, reported on /readyz
+	dependencies *depcheck.Checker
+
+	mu       sync.Mutex
+	ln       net.Listener
+	drained  chan struct{}
+	drainOne sync.Once
+}
+
+// New builds the server from cfg. It opens the database and starts the
+// background workers; call Close when done.
+func New(cfg *config.Config, opts ...Option) (*Server, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if cfg.WarmUpDBConns < 0 {
+		return nil, fmt.Errorf("WARMUP_DB_CONNS must not be negative, got %d", cfg.WarmUpDBConns)
+	}
+	if cfg.DependencyCheckInterval <= 0 {
+		return nil, fmt.Errorf("DEPENDENCY_CHECK_INTERVAL must be positive, got %v", cfg.DependencyCheckInterval)
+	}
+	encodeJSON, err := encoder.New(cfg.JSONEncoder)
+	if err != nil {
+		return nil, fmt.Errorf("JSON_ENCODER: %w", err)
+	}
+
+	s := &Server{cfg: cfg, encodeJSON: encodeJSON, db: o.db, drained: make(chan struct{}), readiness: newReadiness()}
+	if s.db == nil {
+		db, err := database.OpenDatabase(cfg.DBPath)
+		if err != nil {
+			return nil, err
+		}
+		s.db, s.ownsDB = db, true
+		// Keep the connections opened by the warm-up
+		s.db.SetMaxIdleConns(max(2, cfg.WarmUpDBConns))
+	}
+	if err := database.Migrate(s.db); err != nil {
+		s.Close()
+		return nil, err
+	}
+	log.Println("Database initialized successfully")
+
+	if err := s.build(o); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// build wires repositories, use cases and handlers and registers the routes
+func (s *Server) build(o *options) error {
+	cfg := s.cfg
+
+	// Register the shared services, then the replacements from Override
+	c := container.New()
+	provideServices(c, cfg, s.db, o.shadowUsers, o.schemaChanges, o.hooks, o.inboundHandlers)
+	for _, override := range o.overrides {
+		override(c)
+	}
+
+	dependencies, err := container.Get[*depcheck.Checker](c)
+	if err != nil {
+		return err
+	}
+	s.dependencies = dependencies
+
+	// Sensitive endpoints additionally require a signed request when SIGNING_KEYS is set
+	requireSignature := func(c *fiber.Ctx) error { return c.Next() }
+	if cfg.SignedRequestsEnabled() {
+		verifier := signature.NewVerifier(cfg.SigningKeys, cfg.SignatureMaxSkew)
+		if cfg.SignatureRedisURL != "" {
+			nonces, err := redis.NewNonceStore(cfg.SignatureRedisURL)
+			if err != nil {
+				return fmt.Errorf("SIGNATURE_REDIS_URL: %w", err)
+			}
+			verifier.SetNonceStore(nonces)
+			// Replays are only caught across nodes through Redis, so signed
+			// requests are refused rather than degraded while it is down
+			dependencies.RegisterPinger("redis:signature-nonces", nonces, "signed requests (refused)")
+		}
+		requireSignature = middleware.SignatureMiddleware(verifier)
+		log.Println("Signed requests required for sensitive endpoints")
+	}
+
+	deps, err := newDeps(c, requireSignature)
+	if err != nil {
+		return err
+	}
+	ipResolver, err := container.Get[*clientip.Resolver](c)
+	if err != nil {
+		return err
+	}
+	signals, err := container.Get[*autoscale.Signals](c)
+	if err != nil {
+		return err
+	}
+
+	// Build the feature modules and apply their migrations
+	moduleFuncs := o.modules
+	if len(moduleFuncs) == 0 {
+		moduleFuncs = DefaultModules()
+	}
+	modules, err := loadModules(cfg, moduleFuncs, deps)
+	if err != nil {
+		return err
+	}
+
+	// Start the modules' background workers, e.g. for queued admin actions
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	s.stopWorker = stopWorker
+	// Recheck the optional dependencies, so features disabled while one is
+	// down come back with it
+	workers := []*Worker{worker.New("dependency-checks", cfg.DependencyCheckInterval, func() error {
+		dependencies.CheckAll(workerCtx)
+		return nil
+	})}
+	for _, m := range modules {
+		workers = append(workers, m.Workers()...)
+	}
+	collector, err := container.Get[*diagnostics.Collector](c)
+	if err != nil {
+		return err
+	}
+	for _, w := range workers {
+		// Failed runs are summarized at /admin/diagnostics
+		w.OnError(func(name string, err error) { collector.RecordError("worker "+name, err) })
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			w.Run(workerCtx)
+		}()
+	}
+	// Warm up in the background; /readyz reports ready once it is done
+	go s.warmUp(workerCtx, s.warmUpSteps(modules))
+
+	// Create fiber app
+	// Timeouts bound how long a slow client can hold a connection; fasthttp
+	// serves HTTP/1.1 only, so HTTP/2 needs a proxy in front
+	s.app = fiber.New(fiber.Config{
+		AppName:          "Fiber Authentication API v2.0",
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		IdleTimeout:      cfg.IdleTimeout,
+		ReadBufferSize:   cfg.MaxHeaderBytes,
+		BodyLimit:        cfg.MaxRequestBytes,
+		DisableKeepalive: !cfg.KeepAlive,
+		JSONEncoder:      s.encodeJSON,
+	})
+	s.app.Hooks().OnListen(func(fiber.ListenData) error {
+		// Lets the old process stop after a zero-downtime restart
+		go s.signalHandoffReady(workerCtx)
+		return nil
+	})
+
+	// Routes from modules, followed by those from WithRoutes and WithProtectedRoutes
+	routes := &Routes{}
+	for _, m := range modules {
+		m.Routes(routes)
+	}
+	routes.public = append(routes.public, o.routes...)
+	routes.protected = append(routes.protected, o.protectedRoutes...)
+	routes.deprecations = append(routes.deprecations, o.deprecations...)
+	for _, registered := range o.canaries {
+		routes.Canary(registered.method, registered.path, registered.handler)
+	}
+	if routes.canaries != nil {
+		routes.canaries.alternates = routes.alternates
+	}
+
+	deprecations, err := container.Get[*deprecation.Registry](c)
+	if err != nil {
+		return err
+	}
+	for _, policy := range routes.deprecations {
+		if err := deprecations.Register(policy); err != nil {
+			return err
+		}
+	}
+
+	registerRoutes(s.app, cfg, o.middleware, routes, routeDeps{
+		ipResolver:       ipResolver,
+		signals:          signals,
+		deprecations:     deprecations,
+		jwtService:       deps.JWT,
+		userUseCase:      deps.Users,
+		requireSignature: requireSignature,
+		readiness:        s.readiness,
+		dependencies:     dependencies,
+	})
+	if err := routes.canaries.check(); err != nil {
+		return err
+	}
+	return checkDeprecations(s.app, deprecations)
+}
+
+// checkDeprecations fails when a deprecated route is not registered, so a
+// typo in a policy does not go unnoticed
+func checkDeprecations(app *fiber.App, deprecations *deprecation.Registry) error {
+	for _, policy := range deprecations.Policies() {
+		found := slices.ContainsFunc(app.GetRoutes(true), func(route fiber.Route) bool {
+			return strings.EqualFold(route.Method, policy.Method) && route.Path == policy.Path
+		})
+		if !found {
+			return fmt.Errorf("deprecated route %s is not registered", policy.Route())
+		}
+	}
+	return nil
+}
+
+// App returns the underlying Fiber app, e.g. to mount it in another app or
+// to send test requests with app.Test
+func (s *Server) App() *fiber.App {
+	return s.app
+}
+
+// Listen serves on the configured address (PORT or LISTEN_ADDR, with TLS
+// when configured) and blocks until Shutdown or Restart has drained all
+// connections
+func (s *Server) Listen() error {
+	ln, err := listener.Listen(s.cfg.ListenAddress())
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+
+	serveLn := ln
+	if s.cfg.TLSEnabled() {
+		tlsConfig, acmeManager, err := serverTLSConfig(s.cfg)
+		if err != nil {
+			ln.Close()
+			return fmt.Errorf("failed to load TLS configuration: %w", err)
+		}
+		if s.cfg.HTTPRedirectPort != "" {
+			go serveHTTPRedirect(s.cfg, acmeManager)
+		}
+		serveLn = tls.NewListener(ln, tlsConfig)
+		log.Printf("Server starting with TLS on %s", ln.Addr())
+	} else {
+		log.Printf("Server starting on %s", ln.Addr())
+	}
+
+	if err := s.app.Listener(serveLn); err != nil {
+		return err
+	}
+	// app.Listener returns as soon as shutdown starts; wait for the drain
+	<-s.drained
+	return nil
+}
+
+// Shutdown stops accepting connections and waits until in-flight requests
+// finish or ctx is done. /readyz reports not ready from then on.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.readiness.set(errShuttingDown)
+	defer s.drainOne.Do(func() { close(s.drained) })
+	return s.app.ShutdownWithContext(ctx)
+}
+
+// Restart hands the listening socket to a new copy of the running binary
+// and, once it is serving, shuts this server down. If the new process does
+// not become ready within timeout, this server keeps serving.
+func (s *Server) Restart(timeout time.Duration) error {
+	s.mu.Lock()
+	ln := s.ln
+	s.mu.Unlock()
+	if ln == nil {
+		return ErrNotListening
+	}
+
+	if err := listener.Handoff(ln, timeout); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return s.Shutdown(ctx)
+}
+
+// Close stops the background workers, waiting for running jobs so their
+// leases are released, and closes the database if New opened it
+func (s *Server) Close() error {
+	if s.stopWorker != nil {
+		s.stopWorker()
+	}
+	s.workers.Wait()
+	if s.ownsDB {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// newHookRegistry registers the hooks passed with WithHook, then the policy
+// scripts in HOOK_SCRIPTS, so cheap local rules run before the webhooks in
+// HOOK_WEBHOOKS
+func newHookRegistry(cfg *config.Config, registered []registeredHook) (*hooks.Registry, error) {
+	for _, configured := range []map[string]string{cfg.HookScripts, cfg.HookWebhooks} {
+		for name := range configured {
+			if !slices.Contains(hooks.Points, hooks.Point(name)) {
+				return nil, fmt.Errorf("unknown hook point %q", name)
+			}
+		}
+	}
+
+	registry := hooks.NewRegistry()
+	for _, h := range registered {
+		registry.Register(h.point, h.hook)
+	}
+
+	for _, point := range hooks.Points {
+		path, ok := cfg.HookScripts[string(point)]
+		if !ok {
+			continue
+		}
+		script, err := hooks.LoadScript(path, cfg.HookScriptTimeout)
+		if err != nil {
+			return nil, err
+		}
+		registry.Register(point, script)
+		log.Printf("Policy script %s registered for %s hooks", path, point)
+	}
+
+	for _, point := range hooks.Points {
+		target, ok := cfg.HookWebhooks[string(point)]
+		if !ok {
+			continue
+		}
+		webhook, err := hooks.NewWebhook(target, cfg.HookWebhookSecret, cfg.HookWebhookTimeout)
+		if err != nil {
+			return nil, err
+		}
+		registry.Register(point, webhook)
+		log.Printf("Webhook registered for %s hooks", point)
+	}
+
+	return registry, nil
+}
+
+// newInboundRouter accepts webhooks from the providers in
+// INBOUND_WEBHOOK_SECRETS and routes their events to the handlers passed
+// with WithInboundHandler
+func newInboundRouter(cfg *config.Config, registered []registeredInboundHandler) (*inbound.Router, error) {
+	router := inbound.NewRouter()
+	for name, secret := range cfg.InboundWebhookSecrets {
+		provider, err := inbound.NewProvider(name, secret)
+		if err != nil {
+			return nil, err
+		}
+		router.AddProvider(provider)
+	}
+
+	for _, h := range registered {
+		if _, err := router.Provider(h.provider); err != nil {
+			return nil, fmt.Errorf("inbound handler for %s: %w", h.provider, err)
+		}
+		router.Handle(h.provider, h.eventType, h.handler)
+	}
+	return router, nil
+}
+
+// loadModules builds the modules, skipping those that are not configured or
+// are listed in DISABLED_MODULES, and applies their migrations
+func loadModules(cfg *config.Config, moduleFuncs []ModuleFunc, deps *Deps) ([]Module, error) {
+	var modules []Module
+	var names []string
+	for _, build := range moduleFuncs {
+		m, err := build(deps)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil || slices.Contains(cfg.DisabledModules, m.Name()) {
+			continue
+		}
+		if slices.Contains(names, m.Name()) {
+			return nil, fmt.Errorf("module %q is registered twice", m.Name())
+		}
+
+		if migrations := m.Migrations(); len(migrations) > 0 {
+			if err := database.MigrateModule(deps.DB, m.Name(), migrations); err != nil {
+				return nil, fmt.Errorf("module %s: %w", m.Name(), err)
+			}
+		}
+		modules = append(modules, m)
+		names = append(names, m.Name())
+	}
+
+	log.Printf("Modules enabled: %s", strings.Join(names, ", "))
+	return modules, nil
+}
+
+// userRepositoryOptions builds the user repository's ID generation and
+// conflict handling from configuration
+func userRepositoryOptions(cfg *config.Config) (database.UserRepositoryOptions, error) {
+	var opts database.UserRepositoryOptions
+
+	strategy, err := repository.ParseUpdateStrategy(cfg.UsersUpdateStrategy)
+	if err != nil {
+		return opts, err
+	}
+	opts.UpdateStrategy = strategy
+
+	switch cfg.IDStrategy {
+	case "sequential":
+	case "snowflake":
+		ids, err := idgen.NewSnowflake(cfg.NodeID)
+		if err != nil {
+			return opts, err
+		}
+		opts.IDs = ids
+	default:
+		return opts, fmt.Errorf("unknown ID strategy %q", cfg.IDStrategy)
+	}
+
+	return opts, nil
+}
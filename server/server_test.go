@@ -0,0 +1,3936 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fiber-hello-world/config"
+	"fiber-hello-world/internal/domain/entity"
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/dto"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/autoscale"
+	"fiber-hello-world/pkg/chaos"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/deprecation"
+	"fiber-hello-world/pkg/encoder"
+	"fiber-hello-world/pkg/hooks"
+	"fiber-hello-world/pkg/inbound"
+	"fiber-hello-world/pkg/jwt"
+	"fiber-hello-world/pkg/slo"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newTestConfig(t testing.TB) *config.Config {
+	t.Helper()
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "test.db"))
+	t.Setenv("UPLOAD_DIR", t.TempDir())
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return cfg
+}
+
+// testRequest sends req to app without a timeout, failing the test if it
+// cannot be sent
+func testRequest(t testing.TB, app *fiber.App, req *http.Request) *http.Response {
+	t.Helper()
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", req.Method, req.URL.Path, err)
+	}
+	return resp
+}
+
+// adminToken registers admin@example.com, gives it the admin role and
+// returns a token for it
+func adminToken(t testing.TB, srv *Server) string {
+	t.Helper()
+	body := `{"email":"admin@example.com","password":"password123","fullName":"Admin User","phoneNumber":"0812345678","birthday":"1990-01-15"}`
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := srv.App().Test(req, -1)
+	if err != nil || resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %v, %v", resp, err)
+	}
+	var registered struct {
+		Data dto.UserResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&registered)
+	makeAdmin(t, srv, registered.Data.ID)
+	token, _, err := jwt.NewService(srv.cfg.JWTSecret).GenerateToken(registered.Data.ID, registered.Data.Email)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	return token
+}
+
+// makeAdmin gives the user with id the admin role, as an operator would
+// for the first admin
+func makeAdmin(t testing.TB, srv *Server, id int) {
+	t.Helper()
+	if _, err := srv.db.Exec(`UPDATE users SET role = 'admin' WHERE id = ?`, id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// userToken registers a user who is not an admin and returns their ID and a token
+func userToken(t testing.TB, srv *Server, email, phone string) (int, string) {
+	t.Helper()
+	body := `{"email":"` + email + `","password":"password123","fullName":"Test User","phoneNumber":"` + phone + `","birthday":"1990-01-15"}`
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := srv.App().Test(req, -1)
+	if err != nil || resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %v, %v", resp, err)
+	}
+	var registered struct {
+		Data dto.UserResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&registered)
+	token, _, err := jwt.NewService(srv.cfg.JWTSecret).GenerateToken(registered.Data.ID, registered.Data.Email)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	return registered.Data.ID, token
+}
+
+func TestNew_Defaults(t *testing.T) {
+	srv, err := New(newTestConfig(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	resp, err := srv.App().Test(httptest.NewRequest("GET", "/", nil), -1)
+	if err != nil {
+		t.Fatalf("Test() error = %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Errorf("GET / status = %d, want 200", resp.StatusCode)
+	}
+
+	resp = testRequest(t, srv.App(), httptest.NewRequest("GET", "/me", nil))
+	if resp.StatusCode != 401 {
+		t.Errorf("GET /me status = %d, want 401 without a token", resp.StatusCode)
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.IDStrategy = "random"
+
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should fail for an unknown ID strategy")
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	repo := NewMemoryUserRepository()
+	var middlewareRan bool
+
+	srv, err := New(newTestConfig(t),
+		WithUserRepository(repo),
+		WithMiddleware(func(c *fiber.Ctx) error {
+			middlewareRan = true
+			return c.Next()
+		}),
+		WithRoutes(func(router fiber.Router) {
+			router.Get("/status", func(c *fiber.Ctx) error { return c.SendString("up") })
+		}),
+		WithProtectedRoutes(func(router fiber.Router) {
+			router.Get("/whoami", func(c *fiber.Ctx) error {
+				return c.SendString(c.Locals("user").(*jwt.Claims).Email)
+			})
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	resp, err := srv.App().Test(httptest.NewRequest("GET", "/status", nil), -1)
+	if err != nil {
+		t.Fatalf("GET /status error = %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /status = %d, want 200 without a token", resp.StatusCode)
+	}
+	if !middlewareRan {
+		t.Error("middleware from WithMiddleware did not run")
+	}
+
+	// Registration goes to the custom repository
+	body := `{"email":"embed@example.com","password":"password123","fullName":"Embedded User","phoneNumber":"0812345678","birthday":"1990-01-15"}`
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = srv.App().Test(req, -1)
+	if err != nil {
+		t.Fatalf("POST /register error = %v", err)
+	}
+	if resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %d, want 201", resp.StatusCode)
+	}
+	if _, err := repo.GetByEmail("embed@example.com"); err != nil {
+		t.Errorf("user was not stored in the custom repository: %v", err)
+	}
+
+	resp, err = srv.App().Test(httptest.NewRequest("GET", "/whoami", nil), -1)
+	if err != nil {
+		t.Fatalf("GET /whoami error = %v", err)
+	}
+	if resp.StatusCode != 401 {
+		t.Errorf("GET /whoami status = %d, want 401 without a token", resp.StatusCode)
+	}
+
+	user, _ := repo.GetByEmail("embed@example.com")
+	token, _, err := jwt.NewService(srv.cfg.JWTSecret).GenerateToken(user.ID, user.Email)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	req = httptest.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = srv.App().Test(req, -1)
+	if err != nil {
+		t.Fatalf("GET /whoami error = %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /whoami = %d, want 200", resp.StatusCode)
+	}
+	if got, _ := io.ReadAll(resp.Body); string(got) != "embed@example.com" {
+		t.Errorf("GET /whoami body = %q", got)
+	}
+}
+
+// notesModule is a module with its own table, routes and worker
+type notesModule struct {
+	db        *sql.DB
+	swept     chan struct{}
+	sweepOnce sync.Once
+}
+
+func (m *notesModule) Name() string { return "notes" }
+
+func (m *notesModule) Migrations() []Migration {
+	return []Migration{{Version: 1, Description: "create notes table", Query: `CREATE TABLE notes (body TEXT NOT NULL);`}}
+}
+
+func (m *notesModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		router.Get("/notes/count", func(c *fiber.Ctx) error {
+			var count int
+			if err := m.db.QueryRow(`SELECT COUNT(*) FROM notes`).Scan(&count); err != nil {
+				return err
+			}
+			return c.JSON(fiber.Map{"count": count})
+		})
+	})
+	routes.Protected(func(router fiber.Router) {
+		router.Post("/notes", func(c *fiber.Ctx) error { return c.SendStatus(201) })
+	})
+}
+
+func (m *notesModule) Workers() []*Worker {
+	return []*Worker{worker.New("notes", time.Millisecond, func() error {
+		m.sweepOnce.Do(func() { close(m.swept) })
+		return nil
+	})}
+}
+
+func TestNew_WithModules(t *testing.T) {
+	notes := &notesModule{swept: make(chan struct{})}
+	srv, err := New(newTestConfig(t), WithModules(UsersModule, func(deps *Deps) (Module, error) {
+		notes.db = deps.DB
+		return notes, nil
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	// The notes table was created by the module's migration
+	resp := testRequest(t, srv.App(), httptest.NewRequest("GET", "/notes/count", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /notes/count = %d; want 200 without a token", resp.StatusCode)
+	}
+	resp = testRequest(t, srv.App(), httptest.NewRequest("POST", "/notes", nil))
+	if resp.StatusCode != 401 {
+		t.Errorf("POST /notes status = %d, want 401 without a token", resp.StatusCode)
+	}
+
+	// Modules left out are not served
+	resp = testRequest(t, srv.App(), httptest.NewRequest("GET", "/swagger/index.html", nil))
+	if resp.StatusCode != 401 {
+		t.Errorf("GET /swagger/index.html status = %d, want 401 without the docs module", resp.StatusCode)
+	}
+
+	select {
+	case <-notes.swept:
+	case <-time.After(time.Second):
+		t.Error("module worker did not run")
+	}
+}
+
+func TestNew_DisabledModules(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.DisabledModules = []string{"docs"}
+
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	resp := testRequest(t, srv.App(), httptest.NewRequest("GET", "/swagger/index.html", nil))
+	if resp.StatusCode == 200 {
+		t.Error("GET /swagger/index.html should not be served with the docs module disabled")
+	}
+	resp = testRequest(t, srv.App(), httptest.NewRequest("POST", "/login", strings.NewReader(`{}`)))
+	if resp.StatusCode == 401 || resp.StatusCode == 404 {
+		t.Errorf("POST /login status = %d, want the users module to serve it", resp.StatusCode)
+	}
+}
+
+func TestNew_Backups(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.BackupDir = t.TempDir()
+
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	// With no backups yet, the worker takes one on startup
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		matches, _ := filepath.Glob(filepath.Join(cfg.BackupDir, "backup-*.db"))
+		if len(matches) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("backups = %v, want one backup written on startup", matches)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp := testRequest(t, srv.App(), httptest.NewRequest("POST", "/admin/backups", nil))
+	if resp.StatusCode != 401 {
+		t.Errorf("POST /admin/backups status = %d, want 401 without a token", resp.StatusCode)
+	}
+}
+
+func TestNew_FieldEncryption(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.FieldKeyDir = t.TempDir()
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(`{"email":"crypto@example.com","password":"password123","fullName":"Crypto User","phoneNumber":"0812345678","birthday":"1990-01-15"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := srv.App().Test(req, -1)
+	if err != nil || resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %v, %v", resp, err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `"phoneNumber":"0812345678"`) {
+		t.Errorf("POST /register body = %s, want the phone number in plaintext", body)
+	}
+
+	var phone string
+	if err := srv.db.QueryRow(`SELECT phone_number FROM users WHERE email = ?`, "crypto@example.com").Scan(&phone); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(phone, "enc:") {
+		t.Errorf("stored phone number = %q, want it encrypted", phone)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(cfg.FieldKeyDir, "user-*.key")); len(matches) != 1 {
+		t.Errorf("keys = %v, want one per user", matches)
+	}
+
+	// Keys must not be backed up with the data they protect
+	cfg = newTestConfig(t)
+	cfg.BackupDir = t.TempDir()
+	cfg.FieldKeyDir = filepath.Join(cfg.BackupDir, "keys")
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should reject FIELD_KEY_DIR inside BACKUP_DIR")
+	}
+}
+
+func TestNew_Autoscaling(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.HashPoolSize = 3
+
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	resp, err := srv.App().Test(httptest.NewRequest("GET", "/autoscaling", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	// The request reading the signals is itself in flight
+	for _, want := range []string{`"inFlightRequests":1`, `"admin_actions":0`, `"size":3`} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("GET /autoscaling = %s, want %s", body, want)
+		}
+	}
+
+	resp = testRequest(t, srv.App(), httptest.NewRequest("GET", "/autoscaling?format=prometheus", nil))
+	body, _ = io.ReadAll(resp.Body)
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") || !strings.Contains(string(body), "api_hash_pool_size 3\n") {
+		t.Errorf("GET /autoscaling?format=prometheus = %s %s", resp.Header.Get("Content-Type"), body)
+	}
+}
+
+func TestNew_Chaos(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.ChaosEnabled = true
+
+	injector := chaos.New()
+	if err := injector.SetRules([]chaos.Rule{{Path: "/", Percent: 100, Status: 503}}); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := New(cfg, Override(injector))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	resp := testRequest(t, srv.App(), httptest.NewRequest("GET", "/", nil))
+	if resp.StatusCode != 503 || resp.Header.Get("X-Chaos-Fault") != "error" {
+		t.Errorf("GET / status = %d, want an injected 503", resp.StatusCode)
+	}
+	// The rules can always be changed back
+	resp = testRequest(t, srv.App(), httptest.NewRequest("GET", "/admin/chaos", nil))
+	if resp.StatusCode != 401 {
+		t.Errorf("GET /admin/chaos status = %d, want 401 without a token", resp.StatusCode)
+	}
+
+	cfg.Env = "production"
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should refuse fault injection in production")
+	}
+}
+
+func TestNew_SLO(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.WorkerInterval = 10 * time.Millisecond
+	cfg.SLOObjectives = map[string]string{"GET /me": "99% 1s"}
+	cfg.SLOLoadShedding = true
+	cfg.ChaosEnabled = true
+
+	// Fail GET /me before it authenticates
+	injector := chaos.New()
+	if err := injector.SetRules([]chaos.Rule{{Method: "GET", Path: "/me", Percent: 100, Status: 503}}); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := New(cfg, Override(injector))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+	token := adminToken(t, srv)
+
+	for i := 0; i < slo.MinRequests; i++ {
+		srv.App().Test(httptest.NewRequest("GET", "/me", nil), -1)
+	}
+
+	// The worker starts shedding requests for routes without an objective
+	var resp *http.Response
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		resp = testRequest(t, srv.App(), httptest.NewRequest("GET", "/", nil))
+		if resp.StatusCode != 200 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if resp.StatusCode != 503 || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("GET / while a budget is exhausted = %d, want 503 with Retry-After", resp.StatusCode)
+	}
+
+	// Admin routes are never shed
+	req := httptest.NewRequest("GET", "/admin/slo", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp = testRequest(t, srv.App(), req)
+	var report dto.SLOReportResponse
+	json.NewDecoder(resp.Body).Decode(&report)
+	if resp.StatusCode != 200 || len(report.Objectives) != 1 || !report.LoadShedding.Active {
+		t.Fatalf("GET /admin/slo = %d %+v", resp.StatusCode, report)
+	}
+	if got := report.Objectives[0]; got.Route != "GET /me" || got.Requests != slo.MinRequests || got.Errors != slo.MinRequests || !got.Exhausted {
+		t.Errorf("GET /admin/slo objective = %+v, want %d failed requests and the budget exhausted", got, slo.MinRequests)
+	}
+
+	req = httptest.NewRequest("PUT", "/admin/slo/load-shedding", strings.NewReader(`{"enabled":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if resp = testRequest(t, srv.App(), req); resp.StatusCode != 200 {
+		t.Fatalf("PUT /admin/slo/load-shedding status = %d", resp.StatusCode)
+	}
+	if resp = testRequest(t, srv.App(), httptest.NewRequest("GET", "/", nil)); resp.StatusCode != 200 {
+		t.Errorf("GET / with load shedding disabled = %d, want 200", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest("GET", "/admin/slo?format=prometheus", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp = testRequest(t, srv.App(), req)
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `api_slo_error_budget_remaining{route="GET /me"} -99`+"\n") {
+		t.Errorf("GET /admin/slo?format=prometheus = %s", body)
+	}
+
+	cfg.SLOObjectives = map[string]string{"GET /me": "100%"}
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should refuse an objective without an error budget")
+	}
+}
+
+func TestNew_Admission(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.AdmissionInFlight = 2
+	cfg.AdmissionPriorities = map[string]string{"/autoscaling": "low"}
+
+	signals := autoscale.New(nil)
+	srv, err := New(cfg, Override(signals))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	// Two requests held in flight, plus the one being admitted
+	for i := 0; i < 2; i++ {
+		defer signals.Begin()()
+	}
+	for _, tt := range []struct {
+		method, path string
+		want         int
+	}{
+		{"POST", "/register", 503},
+		{"GET", "/autoscaling", 503},
+		{"GET", "/", 200},
+	} {
+		resp := testRequest(t, srv.App(), httptest.NewRequest(tt.method, tt.path, nil))
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s with 3 in flight = %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want)
+		}
+		if tt.want == 503 && resp.Header.Get("Retry-After") == "" {
+			t.Errorf("%s %s rejected without Retry-After", tt.method, tt.path)
+		}
+	}
+
+	cfg.AdmissionPriorities = map[string]string{"/me": "urgent"}
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should refuse an unknown admission priority")
+	}
+}
+
+func TestNew_Lanes(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.LaneLimits = map[string]string{"anonymous": "1", "export": "1"}
+	entered, unblock := make(chan struct{}), make(chan struct{})
+	srv, err := New(cfg, WithRoutes(func(router fiber.Router) {
+		router.Get("/v1/slow", func(c *fiber.Ctx) error {
+			close(entered)
+			<-unblock
+			return c.SendStatus(fiber.StatusNoContent)
+		})
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+	token := adminToken(t, srv)
+
+	// Both exports complete, so a streamed export gives its slot back
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/admin/users/export", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil || resp.StatusCode != 200 {
+			t.Fatalf("export #%d = %v, %v", i+1, resp, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+	}
+
+	// An anonymous request fills the anonymous lane
+	done := make(chan int)
+	go func() {
+		resp, err := srv.App().Test(httptest.NewRequest("GET", "/v1/slow", nil), -1)
+		if err != nil {
+			done <- 0
+			return
+		}
+		done <- resp.StatusCode
+	}()
+	<-entered
+
+	for _, tt := range []struct {
+		path, token string
+		want        int
+	}{
+		{"/register", "", 503},
+		{"/register", "not-a-token", 503},
+		{"/me", token, 200},
+		{"/", "", 200},
+	} {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		resp := testRequest(t, srv.App(), req)
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET %s with the anonymous lane full = %d, want %d", tt.path, resp.StatusCode, tt.want)
+		}
+		if tt.want == 503 && resp.Header.Get("Retry-After") == "" {
+			t.Errorf("GET %s rejected without Retry-After", tt.path)
+		}
+	}
+
+	close(unblock)
+	if status := <-done; status != fiber.StatusNoContent {
+		t.Fatalf("GET /v1/slow = %d", status)
+	}
+	if resp := testRequest(t, srv.App(), httptest.NewRequest("GET", "/register", nil)); resp.StatusCode == 503 {
+		t.Error("GET /register rejected after the anonymous lane emptied")
+	}
+
+	cfg.LaneLimits = map[string]string{"bulk": "1"}
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should refuse an unknown lane")
+	}
+}
+
+func TestNew_WorkerLock(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.WorkerLock = "database"
+	cfg.WorkerInterval = 10 * time.Millisecond
+
+	// leaseOwner waits until the admin-actions lease is held by an owner
+	// other than not, and returns it
+	leaseOwner := func(srv *Server, not string) string {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			var owner string
+			err := srv.db.QueryRow(`SELECT owner FROM leases WHERE name = 'admin-actions'`).Scan(&owner)
+			if err == nil && owner != not {
+				return owner
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("the admin-actions lease was not taken by another owner than %q", not)
+		return ""
+	}
+
+	first, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	owner := leaseOwner(first, "")
+
+	// A second replica on the same database waits while the first holds it
+	second, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer second.Close()
+	time.Sleep(50 * time.Millisecond)
+	if got := leaseOwner(second, ""); got != owner {
+		t.Fatalf("lease owner = %q while %q held it", got, owner)
+	}
+
+	// Closing the first gives the lease up, and the second takes over
+	first.Close()
+	leaseOwner(second, owner)
+
+	cfg.WorkerLock = "zookeeper"
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should refuse an unknown WORKER_LOCK")
+	}
+}
+
+func TestNew_DeadLetters(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.WorkerInterval = 10 * time.Millisecond
+	cfg.HookMaxAttempts = 2
+	cfg.HookRetryBackoff = 10 * time.Millisecond
+
+	// The post-register webhook is down for its first two calls
+	var calls atomic.Int32
+	srv, err := New(cfg, WithHook(hooks.PostRegister, hooks.HookFunc(func(*hooks.Event) error {
+		if calls.Add(1) <= 2 {
+			return errors.New("webhook unreachable")
+		}
+		return nil
+	})))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+	token := adminToken(t, srv)
+
+	// waitStatus waits until the delivery has status
+	waitStatus := func(status string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			var got string
+			if srv.db.QueryRow(`SELECT status FROM hook_deliveries WHERE id = 1`).Scan(&got) == nil && got == status {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("the delivery did not become %s", status)
+	}
+	get := func(path string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		return resp
+	}
+
+	// Retried once, then dead-lettered with an alert event
+	waitStatus("dead")
+	var dead dto.HookDeliveryListResponse
+	json.NewDecoder(get("/admin/dead-letters").Body).Decode(&dead)
+	if len(dead.Deliveries) != 1 || dead.Deliveries[0].Attempts != 2 || len(dead.Deliveries[0].Errors) != 2 {
+		t.Fatalf("GET /admin/dead-letters = %+v, want one delivery after 2 attempts", dead)
+	}
+	var events dto.EventsResponse
+	json.NewDecoder(get("/admin/events?type=hook.dead_lettered").Body).Decode(&events)
+	if len(events.Events) != 1 {
+		t.Errorf("hook.dead_lettered events = %+v, want one", events.Events)
+	}
+
+	req := httptest.NewRequest("POST", "/admin/dead-letters/1/replay", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("POST /admin/dead-letters/1/replay = %v, %v", resp, err)
+	}
+	waitStatus("delivered")
+
+	cfg = newTestConfig(t)
+	cfg.HookMaxAttempts = 0
+	disabled, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer disabled.Close()
+	req = httptest.NewRequest("GET", "/admin/dead-letters", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken(t, disabled))
+	if resp := testRequest(t, disabled.App(), req); resp.StatusCode != 404 {
+		t.Errorf("GET /admin/dead-letters = %d with HOOK_MAX_ATTEMPTS=0, want 404", resp.StatusCode)
+	}
+}
+
+func TestNew_Webhooks(t *testing.T) {
+	// The receiver is down for the first request
+	var mu sync.Mutex
+	var eventIDs []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		eventIDs = append(eventIDs, r.Header.Get(hooks.HeaderEventID))
+		if len(eventIDs) == 1 {
+			w.WriteHeader(503)
+			return
+		}
+		w.WriteHeader(204)
+	}))
+	defer receiver.Close()
+
+	cfg := newTestConfig(t)
+	cfg.WorkerInterval = 10 * time.Millisecond
+	cfg.HookRetryBackoff = 10 * time.Millisecond
+	cfg.HookWebhooks = map[string]string{"post-register": receiver.URL}
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+	token := adminToken(t, srv)
+
+	// Wait until the retried event has both its attempts
+	var list dto.WebhookDeliveryListResponse
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		req := httptest.NewRequest("GET", "/admin/webhooks/post-register/deliveries", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil || resp.StatusCode != 200 {
+			t.Fatalf("GET /admin/webhooks/post-register/deliveries = %v, %v", resp, err)
+		}
+		list = dto.WebhookDeliveryListResponse{}
+		json.NewDecoder(resp.Body).Decode(&list)
+		if len(list.Deliveries) == 1 && len(list.Deliveries[0].Attempts) == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if len(list.Deliveries) != 1 || list.Deliveries[0].Status != "delivered" || len(list.Deliveries[0].Attempts) != 2 ||
+		list.Deliveries[0].Attempts[0].StatusCode != 503 || list.Deliveries[0].Attempts[1].StatusCode != 204 {
+		t.Fatalf("deliveries = %+v, want a 503 then a 204", list)
+	}
+	mu.Lock()
+	if len(eventIDs) != 2 || eventIDs[0] != list.Deliveries[0].EventID || eventIDs[1] != eventIDs[0] {
+		t.Errorf("%s headers = %v, want %q on both attempts", hooks.HeaderEventID, eventIDs, list.Deliveries[0].EventID)
+	}
+	mu.Unlock()
+
+	req := httptest.NewRequest("GET", "/admin/webhooks/pre-login/deliveries", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if resp := testRequest(t, srv.App(), req); resp.StatusCode != 404 {
+		t.Errorf("GET /admin/webhooks/pre-login/deliveries = %d, want 404 without a webhook", resp.StatusCode)
+	}
+}
+
+func TestNew_InboundWebhooks(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.InboundWebhookSecrets = map[string]string{"stripe": "whsec_test"}
+	var paid []string
+	srv, err := New(cfg, WithInboundHandler("stripe", "invoice.paid", inbound.HandlerFunc(func(event *inbound.Event) error {
+		paid = append(paid, event.Subject)
+		return nil
+	})))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	body := `{"id":"evt_1","type":"invoice.paid","created":1717243200,"data":{"object":{"id":"in_1"}}}`
+	post := func(provider, secret string) *http.Response {
+		t.Helper()
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "." + body))
+		req := httptest.NewRequest("POST", "/webhooks/"+provider, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(inbound.HeaderStripeSignature, "t="+timestamp+",v1="+hex.EncodeToString(mac.Sum(nil)))
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("POST /webhooks/%s error = %v", provider, err)
+		}
+		return resp
+	}
+
+	// A replay of the same event is acknowledged but not handled again
+	for i, want := range []dto.InboundWebhookResponse{{Received: 1}, {Duplicates: 1}} {
+		resp := post("stripe", "whsec_test")
+		var got dto.InboundWebhookResponse
+		json.NewDecoder(resp.Body).Decode(&got)
+		if resp.StatusCode != 200 || got != want {
+			t.Errorf("POST /webhooks/stripe #%d = %d %+v, want 200 %+v", i+1, resp.StatusCode, got, want)
+		}
+	}
+	if len(paid) != 1 || paid[0] != "in_1" {
+		t.Errorf("invoice.paid handled for %v, want in_1 once", paid)
+	}
+
+	if resp := post("stripe", "whsec_other"); resp.StatusCode != 401 {
+		t.Errorf("POST /webhooks/stripe with a wrong signature = %d, want 401", resp.StatusCode)
+	}
+	if resp := post("sendgrid", "whsec_test"); resp.StatusCode != 404 {
+		t.Errorf("POST /webhooks/sendgrid without a key = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestNew_Plans(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.InboundWebhookSecrets = map[string]string{"stripe": "whsec_test"}
+	cfg.PlanPrices = map[string]string{"price_pro": "pro"}
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	token := adminToken(t, srv)
+	send := func(method, path, body string, header map[string]string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		return resp
+	}
+
+	resp := send("POST", "/register", `{"email":"lee@example.com","password":"password123","fullName":"Lee Park","phoneNumber":"0812345678","birthday":"1990-01-15"}`, nil)
+	var registered struct {
+		Data dto.UserResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&registered)
+	if resp.StatusCode != 201 || registered.Data.Plan != "free" {
+		t.Fatalf("POST /register = %d %+v, want 201 on the free plan", resp.StatusCode, registered.Data)
+	}
+	userID := strconv.Itoa(registered.Data.ID)
+
+	body := `{"id":"evt_sub","type":"customer.subscription.created","created":1717243200,"data":{"object":{"id":"sub_1","status":"active",` +
+		`"metadata":{"user_id":"` + userID + `"},"items":{"data":[{"price":{"id":"price_pro"}}]}}}}`
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte(timestamp + "." + body))
+	if resp := send("POST", "/webhooks/stripe", body, map[string]string{
+		inbound.HeaderStripeSignature: "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil)),
+	}); resp.StatusCode != 200 {
+		t.Fatalf("POST /webhooks/stripe = %d, want 200", resp.StatusCode)
+	}
+
+	// Tokens issued after the subscription carry the paid plan
+	resp = send("POST", "/login", `{"email":"lee@example.com","password":"password123"}`, nil)
+	var login dto.LoginResponse
+	json.NewDecoder(resp.Body).Decode(&login)
+	claims, err := jwt.NewService(cfg.JWTSecret).ValidateToken(login.Token)
+	if err != nil {
+		t.Fatalf("POST /login = %d, token error %v", resp.StatusCode, err)
+	}
+	if login.User.Plan != "pro" || claims.Extra["plan"] != "pro" {
+		t.Errorf("login plan = %s, claim %v; want pro", login.User.Plan, claims.Extra["plan"])
+	}
+
+	admin := map[string]string{"Authorization": "Bearer " + token}
+	if resp := send("PUT", "/admin/users/"+userID+"/plan", `{"plan":"gold"}`, admin); resp.StatusCode != 400 {
+		t.Errorf("PUT /admin/users/%s/plan gold = %d, want 400", userID, resp.StatusCode)
+	}
+	resp = send("PUT", "/admin/users/"+userID+"/plan", `{"plan":"free"}`, admin)
+	var changed struct {
+		Data dto.UserResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&changed)
+	if resp.StatusCode != 200 || changed.Data.Plan != "free" {
+		t.Errorf("PUT /admin/users/%s/plan free = %d %+v, want 200 on the free plan", userID, resp.StatusCode, changed.Data)
+	}
+}
+
+func TestNew_Entitlements(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.EntitlementsFile = filepath.Join(t.TempDir(), "entitlements.yaml")
+	if err := os.WriteFile(cfg.EntitlementsFile, []byte("plans:\n  free: [exports]\n  pro: [exports, api_access]\nflags:\n  new_dashboard: on\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	token := adminToken(t, srv)
+	send := func(method, path, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		return resp
+	}
+	entitlements := func(path string) dto.EntitlementsResponse {
+		t.Helper()
+		resp := send("GET", path, "")
+		var response dto.EntitlementsResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		if resp.StatusCode != 200 {
+			t.Fatalf("GET %s = %d, want 200", path, resp.StatusCode)
+		}
+		return response
+	}
+
+	resp := send("GET", "/me/entitlements", "")
+	if got := resp.Header.Get("Cache-Control"); got != "private, max-age=60" {
+		t.Errorf("Cache-Control = %q, want private, max-age=60", got)
+	}
+	if got := entitlements("/me/entitlements"); got.Plan != "free" || strings.Join(got.Features, ",") != "exports,new_dashboard" {
+		t.Errorf("GET /me/entitlements = %+v, want exports and new_dashboard on the free plan", got)
+	}
+
+	if resp := send("PUT", "/admin/users/1/entitlements/teleport", `{"granted":true}`); resp.StatusCode != 400 {
+		t.Errorf("PUT unknown feature = %d, want 400", resp.StatusCode)
+	}
+	if resp := send("PUT", "/admin/users/99/entitlements/api_access", `{"granted":true}`); resp.StatusCode != 404 {
+		t.Errorf("PUT for an unknown user = %d, want 404", resp.StatusCode)
+	}
+	if resp := send("PUT", "/admin/users/1/entitlements/api_access", `{"granted":true}`); resp.StatusCode != 200 {
+		t.Fatalf("PUT /admin/users/1/entitlements/api_access = %d, want 200", resp.StatusCode)
+	}
+	if got := entitlements("/me/entitlements"); got.Sources["api_access"] != "override" {
+		t.Errorf("GET /me/entitlements = %+v, want api_access from the override", got)
+	}
+	if got := entitlements("/admin/users/1/entitlements"); len(got.Overrides) != 1 || !got.Overrides[0].Granted {
+		t.Errorf("GET /admin/users/1/entitlements overrides = %+v, want api_access granted", got.Overrides)
+	}
+
+	if resp := send("DELETE", "/admin/users/1/entitlements/api_access", ""); resp.StatusCode != 200 {
+		t.Errorf("DELETE override = %d, want 200", resp.StatusCode)
+	}
+	if resp := send("DELETE", "/admin/users/1/entitlements/api_access", ""); resp.StatusCode != 404 {
+		t.Errorf("DELETE override again = %d, want 404", resp.StatusCode)
+	}
+
+	cfg = newTestConfig(t)
+	cfg.EntitlementsFile = filepath.Join(t.TempDir(), "missing.yaml")
+	if _, err := New(cfg); err == nil {
+		t.Error("New() with a missing ENTITLEMENTS_FILE should fail")
+	}
+}
+
+func TestNew_Birthdays(t *testing.T) {
+	cfg := newTestConfig(t)
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	// 28 years back, so a February 29 birthday exists that year too
+	birthday := time.Now().UTC().AddDate(-28, 0, 0).Format("2006-01-02")
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(`{"email":"lee@example.com","password":"password123","fullName":"Lee Park","phoneNumber":"0812345678","birthday":"`+birthday+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %v, %v", resp, err)
+	}
+	srv.Close()
+
+	// Past BIRTHDAY_TIME on the user's birthday, the worker greets them on startup
+	cfg.BirthdayTime = "00:00"
+	var mu sync.Mutex
+	var greeted []*hooks.Event
+	srv, err = New(cfg, WithHook(hooks.Birthday, hooks.HookFunc(func(e *hooks.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		greeted = append(greeted, e)
+		return nil
+	})))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		count := len(greeted)
+		mu.Unlock()
+		if count > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the user was not greeted on their birthday")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	event := greeted[0]
+	mu.Unlock()
+	if event.Email != "lee@example.com" || event.Data["age"] != 28 || event.Data["timezone"] != "UTC" {
+		t.Errorf("birthday hook event = %+v, want lee@example.com turning 28 in UTC", event)
+	}
+	var events int
+	if err := srv.db.QueryRow(`SELECT COUNT(*) FROM domain_events WHERE type = ?`, "user.birthday").Scan(&events); err != nil || events != 1 {
+		t.Errorf("user.birthday events = %d, %v; want 1", events, err)
+	}
+
+	cfg.BirthdayTimezone = "Mars/Olympus"
+	if _, err := New(cfg); err == nil || !strings.Contains(err.Error(), "BIRTHDAY_TIMEZONE") {
+		t.Errorf("New() error = %v, want it to name BIRTHDAY_TIMEZONE", err)
+	}
+}
+
+func TestNew_Presence(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.PresenceEnabled = true
+	cfg.PresenceFlushInterval = 10 * time.Millisecond
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	alice := adminToken(t, srv)
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(`{"email":"bob@example.com","password":"password123","fullName":"Bob Lee","phoneNumber":"0812345678","birthday":"1990-01-15"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %v, %v", resp, err)
+	}
+	bob, _, err := jwt.NewService(cfg.JWTSecret).GenerateToken(2, "bob@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(token, method, path, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		return resp
+	}
+	presence := func(token, path string) dto.PresencesResponse {
+		t.Helper()
+		resp := send(token, "GET", path, "")
+		if resp.StatusCode != 200 {
+			t.Fatalf("GET %s = %d, want 200", path, resp.StatusCode)
+		}
+		var response dto.PresencesResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		return response
+	}
+
+	resp := send(alice, "GET", "/me/presence", "")
+	var mine dto.PresenceResponse
+	json.NewDecoder(resp.Body).Decode(&mine)
+	if resp.StatusCode != 200 || mine.Status != "online" || mine.LastSeenAt == nil || mine.Visibility != "everyone" {
+		t.Errorf("GET /me/presence = %d %+v, want online and visible to everyone", resp.StatusCode, mine)
+	}
+	got := presence(bob, "/presence?ids=1,3")
+	if len(got.Users) != 2 || got.Users[0].Status != "online" || got.Users[0].LastSeenAt == nil || got.Users[0].Visibility != "" || got.Users[1].Status != "away" {
+		t.Errorf("GET /presence = %+v, want user 1 online and user 3 away", got.Users)
+	}
+	if resp := send(bob, "GET", "/presence", ""); resp.StatusCode != 400 {
+		t.Errorf("GET /presence without ids = %d, want 400", resp.StatusCode)
+	}
+
+	if resp := send(alice, "PUT", "/me/presence", `{"visibility":"friends"}`); resp.StatusCode != 400 {
+		t.Errorf("PUT /me/presence friends = %d, want 400", resp.StatusCode)
+	}
+	if resp := send(alice, "PUT", "/me/presence", `{"visibility":"nobody"}`); resp.StatusCode != 200 {
+		t.Fatalf("PUT /me/presence = %d, want 200", resp.StatusCode)
+	}
+	if got := presence(bob, "/presence?ids=1"); got.Users[0].Status != "away" || got.Users[0].LastSeenAt != nil {
+		t.Errorf("GET /presence of a hidden user = %+v, want away without a last seen time", got.Users[0])
+	}
+
+	// The flush worker writes the buffered times
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var seen int
+		srv.db.QueryRow(`SELECT COUNT(*) FROM user_presence WHERE last_seen_at IS NOT NULL`).Scan(&seen)
+		if seen == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d users flushed, want 2", seen)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cfg = newTestConfig(t)
+	cfg.PresenceEnabled = true
+	cfg.PresenceOnlineWindow = cfg.PresenceFlushInterval
+	if _, err := New(cfg); err == nil {
+		t.Error("New() with PRESENCE_ONLINE_WINDOW not longer than PRESENCE_FLUSH_INTERVAL should fail")
+	}
+}
+
+func TestNew_RateLimits(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.RateLimits = map[string]string{"POST /login": "5/1m"}
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	login := func() (*http.Response, map[string]interface{}) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"email":"nobody@example.com","password":"password123"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("POST /login error = %v", err)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp, body
+	}
+
+	for i := 1; i <= 5; i++ {
+		resp, body := login()
+		remaining := strconv.Itoa(5 - i)
+		if resp.Header.Get("X-RateLimit-Limit") != "5" || resp.Header.Get("X-RateLimit-Remaining") != remaining ||
+			resp.Header.Get("RateLimit-Remaining") != remaining || resp.Header.Get("RateLimit-Policy") != "5;w=60" {
+			t.Errorf("request %d headers = %v, want a limit of 5 with %s remaining", i, resp.Header, remaining)
+		}
+		if reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); reset <= time.Now().Unix() {
+			t.Errorf("request %d X-RateLimit-Reset = %d, want a Unix time in the future", i, reset)
+		}
+		// Clients are warned once 80% of the limit is used
+		if _, warned := body["warning"]; warned != (i >= 4) {
+			t.Errorf("request %d warning = %v, want one from the 4th request", i, body["warning"])
+		}
+	}
+
+	resp, _ := login()
+	if resp.StatusCode != 429 || resp.Header.Get("Retry-After") == "" || resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("request over the limit = %d with headers %v, want 429 with Retry-After", resp.StatusCode, resp.Header)
+	}
+
+	resp = testRequest(t, srv.App(), httptest.NewRequest("GET", "/", nil))
+	if resp.Header.Get("X-RateLimit-Limit") != "" {
+		t.Error("routes without a rate limit should have no rate limit headers")
+	}
+
+	cfg = newTestConfig(t)
+	cfg.RateLimits = map[string]string{"POST /login": "often"}
+	if _, err := New(cfg); err == nil {
+		t.Error("New() with an invalid RATE_LIMITS should fail")
+	}
+}
+
+func TestNew_ShadowUserRepository(t *testing.T) {
+	shadowUsers := NewMemoryUserRepository()
+	srv, err := New(newTestConfig(t), WithShadowUserRepository(shadowUsers))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	var logs strings.Builder
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	_, token := userToken(t, srv, "shadow@example.com", "0812345678")
+	req := httptest.NewRequest("PATCH", "/me", strings.NewReader(`{"fullName":"Ann Shadow"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("PATCH /me = %v, %v", resp, err)
+	}
+
+	// Writes reach both backends, and reads are compared
+	user, err := shadowUsers.GetByEmail("shadow@example.com")
+	if err != nil || user.FullName != "Ann Shadow" {
+		t.Errorf("shadow user = %+v, %v; want the writes mirrored", user, err)
+	}
+	if strings.Contains(logs.String(), "Shadow users") {
+		t.Errorf("logs = %q, want no differences", logs.String())
+	}
+
+	// The database stays authoritative
+	shadowUsers.Delete(user.ID)
+	req = httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("GET /me = %v, %v; want the user from the database", resp, err)
+	}
+	if !strings.Contains(logs.String(), "failed in the shadow only: user not found") {
+		t.Errorf("logs = %q, want the shadow's failure", logs.String())
+	}
+}
+
+func TestNew_Canaries(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.CanaryRoutes = map[string]string{"POST /v1/login": "100%", "POST /v1/logout": "0%", "GET /v1/items/:id": "flag:new_items"}
+	cfg.EntitlementsFile = filepath.Join(t.TempDir(), "entitlements.yaml")
+	if err := os.WriteFile(cfg.EntitlementsFile, []byte("plans:\n  free: [exports]\nflags:\n  new_items: off\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := New(cfg,
+		WithRoutes(func(router fiber.Router) {
+			router.Post("/v1/login", func(c *fiber.Ctx) error { return c.SendString("stable") })
+			router.Post("/v1/logout", func(c *fiber.Ctx) error { return c.SendString("stable") })
+		}),
+		WithProtectedRoutes(func(router fiber.Router) {
+			router.Get("/v1/items/:id", func(c *fiber.Ctx) error { return c.SendString("stable " + c.Params("id")) })
+		}),
+		WithCanary("POST", "/v1/login", func(c *fiber.Ctx) error { return errors.New("rewrite failed") }),
+		WithCanary("POST", "/v1/logout", func(c *fiber.Ctx) error { return c.SendString("canary") }),
+		WithCanary("GET", "/v1/items/:id", func(c *fiber.Ctx) error { return c.SendString("canary " + c.Params("id")) }),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	token := adminToken(t, srv)
+	send := func(method, path, body string) (*http.Response, string) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		content, _ := io.ReadAll(resp.Body)
+		return resp, string(content)
+	}
+
+	// Everyone is sent to the login rewrite, whose errors are counted
+	if resp, _ := send("POST", "/v1/login", ""); resp.StatusCode != 500 || resp.Header.Get("X-Canary-Variant") != "canary" {
+		t.Errorf("POST /v1/login = %d from %q, want 500 from the canary", resp.StatusCode, resp.Header.Get("X-Canary-Variant"))
+	}
+	if resp, body := send("POST", "/v1/logout", ""); body != "stable" || resp.Header.Get("X-Canary-Variant") != "stable" {
+		t.Errorf("POST /v1/logout = %q, want stable at 0%%", body)
+	}
+
+	// The flag cohort follows the users' entitlements, and alternates read
+	// the route's parameters
+	if _, body := send("GET", "/v1/items/7", ""); body != "stable 7" {
+		t.Errorf("GET /v1/items/7 = %q before the flag, want stable 7", body)
+	}
+	if resp, _ := send("PUT", "/admin/users/1/entitlements/new_items", `{"granted":true}`); resp.StatusCode != 200 {
+		t.Fatalf("granting new_items = %d", resp.StatusCode)
+	}
+	if _, body := send("GET", "/v1/items/7", ""); body != "canary 7" {
+		t.Errorf("GET /v1/items/7 = %q with the flag, want canary 7", body)
+	}
+
+	_, body := send("GET", "/admin/canaries", "")
+	var canaries dto.CanariesResponse
+	if err := json.Unmarshal([]byte(body), &canaries); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]dto.CanaryCountsResponse{
+		"GET /v1/items/:id": {{Requests: 1}, {Requests: 1}},
+		"POST /v1/login":    {{}, {Requests: 1, ServerErrors: 1}},
+		"POST /v1/logout":   {{Requests: 1}, {}},
+	}
+	if len(canaries.Routes) != len(want) {
+		t.Fatalf("canaries = %+v", canaries)
+	}
+	for _, route := range canaries.Routes {
+		if got := [2]dto.CanaryCountsResponse{route.Stable, route.Canary}; got != want[route.Method+" "+route.Path] {
+			t.Errorf("%s %s counts = %+v, want %+v", route.Method, route.Path, got, want[route.Method+" "+route.Path])
+		}
+	}
+
+	// Rules need an alternate, a registered route and a known flag
+	for name, tt := range map[string]struct {
+		routes  map[string]string
+		wantErr string
+	}{
+		"no alternate":  {map[string]string{"POST /v1/login": "10%", "POST /login": "10%"}, "POST /login has no alternate"},
+		"no route":      {map[string]string{"POST /v1/login": "10%", "GET /v1/missing": "10%"}, "GET /v1/missing is not registered"},
+		"unknown flag":  {map[string]string{"POST /v1/login": "flag:teleport"}, `flag "teleport"`},
+		"invalid route": {map[string]string{"/v1/login": "10%"}, "must be a method and a path"},
+	} {
+		cfg := newTestConfig(t)
+		cfg.CanaryRoutes = tt.routes
+		cfg.EntitlementsFile = filepath.Join(t.TempDir(), "entitlements.yaml")
+		if err := os.WriteFile(cfg.EntitlementsFile, []byte("plans:\n  free: [exports]\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		srv, err := New(cfg,
+			WithRoutes(func(router fiber.Router) {
+				router.Post("/v1/login", func(c *fiber.Ctx) error { return nil })
+			}),
+			WithCanary("POST", "/v1/login", func(c *fiber.Ctx) error { return nil }),
+			WithCanary("GET", "/v1/missing", func(c *fiber.Ctx) error { return nil }),
+		)
+		if err == nil {
+			srv.Close()
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("New() with %s error = %v, want %q", name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNew_PayloadLogging(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.PayloadLogRedact = []string{"email"}
+	srv, err := New(cfg, WithRoutes(func(router fiber.Router) {
+		router.Post("/v1/echo", func(c *fiber.Ctx) error {
+			return c.JSON(fiber.Map{"token": "t-123", "phone": "0812345678"})
+		})
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	var logs strings.Builder
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	echo := func() {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/v1/echo", strings.NewReader(`{"email":"a@example.com","password":"hunter2"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 200 {
+			t.Fatalf("POST /v1/echo = %v, %v", resp, err)
+		}
+	}
+	token := adminToken(t, srv)
+	admin := func(method, path, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	echo()
+	if strings.Contains(logs.String(), "Payload POST") {
+		t.Fatalf("logs = %q, want no payloads before logging is enabled", logs.String())
+	}
+
+	if resp := admin("POST", "/admin/payload-logging", `{"method":"POST","path":"/v1/echo","minutes":0}`); resp.StatusCode != 400 {
+		t.Errorf("enable for 0 minutes status = %d, want 400", resp.StatusCode)
+	}
+	if resp := admin("POST", "/admin/payload-logging", `{"method":"POST","path":"/v1/:name","minutes":10}`); resp.StatusCode != 200 {
+		t.Fatalf("enable status = %d, want 200", resp.StatusCode)
+	}
+	var list dto.PayloadLogRoutesResponse
+	json.NewDecoder(admin("GET", "/admin/payload-logging", "").Body).Decode(&list)
+	if len(list.Routes) != 1 || list.Routes[0].Path != "/v1/:name" || time.Until(list.Routes[0].Until) < 9*time.Minute {
+		t.Fatalf("routes = %+v, want /v1/:name for 10 minutes", list)
+	}
+
+	echo()
+	logged := logs.String()
+	if !strings.Contains(logged, "Payload POST /v1/echo 200") || strings.Contains(logged, "hunter2") ||
+		strings.Contains(logged, "a@example.com") || strings.Contains(logged, "t-123") || !strings.Contains(logged, "5678") {
+		t.Errorf("logs = %q, want the payloads with secrets redacted and the phone masked", logged)
+	}
+
+	if resp := admin("DELETE", "/admin/payload-logging?method=GET&path=/v1/echo", ""); resp.StatusCode != 404 {
+		t.Errorf("disable unknown route status = %d, want 404", resp.StatusCode)
+	}
+	if resp := admin("DELETE", "/admin/payload-logging?method=POST&path=/v1/:name", ""); resp.StatusCode != 200 {
+		t.Errorf("disable status = %d, want 200", resp.StatusCode)
+	}
+	logs.Reset()
+	echo()
+	if strings.Contains(logs.String(), "Payload POST") {
+		t.Errorf("logs = %q, want no payloads once logging is disabled", logs.String())
+	}
+}
+
+func TestNew_SchemaChanges(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.WorkerInterval = 10 * time.Millisecond
+	cfg.SchemaBackfillBatch = 2
+
+	// Users from before the expand step, which adds email_lower
+	db, err := database.OpenDatabase(cfg.DBPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	users := database.NewSQLiteUserRepository(db)
+	for _, email := range []string{"One@Example.com", "Two@Example.com", "Three@Example.com"} {
+		if _, err := users.Create(entity.NewUser(email, "hash", "Legacy User", "0812345678", "1990-01-15")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(`ALTER TABLE users ADD COLUMN email_lower TEXT`); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := New(cfg, WithDatabase(db), WithSchemaChange(SchemaChange{
+		Name:     "users_email_lower",
+		Table:    "users",
+		Backfill: `UPDATE users SET email_lower = lower(email) WHERE id > ? AND id <= ?`,
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	token := adminToken(t, srv)
+	send := func(method, path, body string) (*http.Response, string) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		content, _ := io.ReadAll(resp.Body)
+		return resp, string(content)
+	}
+
+	// The worker copies a batch per tick until no rows are left
+	var changes dto.SchemaChangesResponse
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		resp, body := send("GET", "/admin/schema-changes", "")
+		if resp.StatusCode != 200 {
+			t.Fatalf("GET /admin/schema-changes = %d: %s", resp.StatusCode, body)
+		}
+		if err := json.Unmarshal([]byte(body), &changes); err != nil {
+			t.Fatal(err)
+		}
+		if len(changes.Changes) == 1 && changes.Changes[0].Phase == "backfilled" || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(changes.Changes) != 1 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if change := changes.Changes[0]; change.Phase != "backfilled" || change.Total != 3 || change.Backfilled < 3 || change.Progress != 1 || change.BackfilledAt == nil {
+		t.Fatalf("change = %+v, want backfilled", change)
+	}
+	var missing int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM users WHERE id <= 3 AND (email_lower IS NULL OR email_lower != lower(email))`).Scan(&missing); err != nil || missing != 0 {
+		t.Errorf("%d users not backfilled, %v", missing, err)
+	}
+
+	if resp, body := send("PUT", "/admin/schema-changes/users_email_lower/reads", `{"reads":"new"}`); resp.StatusCode != 200 || !strings.Contains(body, `"phase":"reading_new"`) {
+		t.Errorf("switching reads = %d: %s", resp.StatusCode, body)
+	}
+	if resp, _ := send("PUT", "/admin/schema-changes/users_email_lower/reads", `{"reads":"both"}`); resp.StatusCode != 400 {
+		t.Errorf("switching reads to both = %d, want 400", resp.StatusCode)
+	}
+	if resp, _ := send("PUT", "/admin/schema-changes/missing/reads", `{"reads":"new"}`); resp.StatusCode != 404 {
+		t.Errorf("switching reads of an unknown change = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestNew_Duplicates(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	db, err := database.OpenDatabase(cfg.DBPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	srv, err := New(cfg, WithDatabase(db))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	token := adminToken(t, srv)
+	users := database.NewSQLiteUserRepository(db)
+	for _, user := range []*entity.User{
+		entity.NewUser("Jane.Doe@gmail.com", "hash", "Jane Doe", "+66 89 111 2222", "1990-01-15"),
+		entity.NewUser("janedoe+shop@gmail.com", "hash", "Jane D", "089 111 2222", "1990-01-15"),
+		entity.NewUser("someone@example.com", "hash", "Someone Else", "0898887777", "1985-06-01"),
+	} {
+		if _, err := users.Create(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send := func(method, path string) (*http.Response, string) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		content, _ := io.ReadAll(resp.Body)
+		return resp, string(content)
+	}
+
+	if resp, body := send("POST", "/admin/duplicates/scan"); resp.StatusCode != 200 || body != `{"found":1}` {
+		t.Fatalf("POST /admin/duplicates/scan = %d: %s", resp.StatusCode, body)
+	}
+	resp, body := send("GET", "/admin/duplicates")
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /admin/duplicates = %d: %s", resp.StatusCode, body)
+	}
+	var list dto.DuplicateListResponse
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Duplicates) != 1 {
+		t.Fatalf("duplicates = %+v, want one pair", list.Duplicates)
+	}
+	pair := list.Duplicates[0]
+	if len(pair.Users) != 2 || pair.Users[0].Email != "Jane.Doe@gmail.com" || pair.Users[1].Email != "janedoe+shop@gmail.com" {
+		t.Errorf("users = %+v", pair.Users)
+	}
+	if strings.Join(pair.Signals, ",") != "email,phone" || pair.Status != "pending" {
+		t.Errorf("pair = %+v, want pending on email and phone", pair)
+	}
+
+	path := "/admin/duplicates/" + strconv.Itoa(pair.ID) + "/dismiss"
+	if resp, body := send("POST", path); resp.StatusCode != 200 || !strings.Contains(body, `"status":"dismissed"`) {
+		t.Errorf("POST %s = %d: %s", path, resp.StatusCode, body)
+	}
+	// A dismissed pair is not surfaced again by the next scan
+	send("POST", "/admin/duplicates/scan")
+	if _, body := send("GET", "/admin/duplicates"); body != `{"duplicates":[]}` {
+		t.Errorf("pending duplicates after dismissal = %s", body)
+	}
+	if resp, _ := send("POST", "/admin/duplicates/999/dismiss"); resp.StatusCode != 404 {
+		t.Errorf("dismissing an unknown pair = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestNew_Fraud(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.WorkerInterval = 10 * time.Millisecond
+	cfg.FraudFlagScore = 30
+	cfg.DisposableDomains = []string{"burner.example"}
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	adminTok := adminToken(t, srv)
+	userID, userTok := userToken(t, srv, "jane@burner.example", "0898887777")
+
+	send := func(token, method, path, body string) (*http.Response, string) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		content, _ := io.ReadAll(resp.Body)
+		return resp, string(content)
+	}
+
+	// The worker scores new users and flags the disposable address
+	var flagged dto.FraudScoreListResponse
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		resp, body := send(adminTok, "GET", "/admin/fraud", "")
+		if resp.StatusCode != 200 {
+			t.Fatalf("GET /admin/fraud = %d: %s", resp.StatusCode, body)
+		}
+		if err := json.Unmarshal([]byte(body), &flagged); err != nil {
+			t.Fatal(err)
+		}
+		if len(flagged.Scores) > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(flagged.Scores) != 1 {
+		t.Fatalf("flagged = %+v, want the new user", flagged)
+	}
+	if score := flagged.Scores[0]; score.UserID != userID || score.Email != "jane@burner.example" || score.Score != 30 || score.FlaggedAt == nil {
+		t.Errorf("score = %+v", score)
+	}
+
+	// Sensitive actions are refused, others are not
+	if resp, body := send(userTok, "PUT", "/me/password", `{"currentPassword":"password123","newPassword":"password456"}`); resp.StatusCode != 403 || !strings.Contains(body, "under review") {
+		t.Errorf("PUT /me/password while flagged = %d: %s", resp.StatusCode, body)
+	}
+	if resp, _ := send(userTok, "GET", "/me", ""); resp.StatusCode != 200 {
+		t.Errorf("GET /me while flagged = %d, want 200", resp.StatusCode)
+	}
+
+	path := "/admin/fraud/" + strconv.Itoa(userID) + "/clear"
+	if resp, body := send(adminTok, "POST", path, ""); resp.StatusCode != 200 || !strings.Contains(body, `"status":"cleared"`) {
+		t.Fatalf("POST %s = %d: %s", path, resp.StatusCode, body)
+	}
+	if resp, _ := send(adminTok, "POST", path, ""); resp.StatusCode != 409 {
+		t.Errorf("clearing again = %d, want 409", resp.StatusCode)
+	}
+	if resp, body := send(userTok, "PUT", "/me/password", `{"currentPassword":"password123","newPassword":"password456"}`); resp.StatusCode == 403 {
+		t.Errorf("PUT /me/password after clearing = %d: %s", resp.StatusCode, body)
+	}
+	if resp, _ := send(adminTok, "GET", "/admin/fraud/999", ""); resp.StatusCode != 404 {
+		t.Errorf("GET /admin/fraud/999 = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestNew_BreakGlass(t *testing.T) {
+	cfg := newTestConfig(t)
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	adminTok := adminToken(t, srv)
+	_, onCallTok := userToken(t, srv, "oncall@example.com", "0898887777")
+	send := func(token, method, path, body string) (*http.Response, string) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		content, _ := io.ReadAll(resp.Body)
+		return resp, string(content)
+	}
+
+	resp, body := send(adminTok, "POST", "/admin/break-glass/ceremony", `{"shares":3,"threshold":2}`)
+	if resp.StatusCode != 201 {
+		t.Fatalf("POST /admin/break-glass/ceremony = %d: %s", resp.StatusCode, body)
+	}
+	var ceremony dto.BreakGlassCeremonyResponse
+	if err := json.Unmarshal([]byte(body), &ceremony); err != nil || len(ceremony.Shares) != 3 {
+		t.Fatalf("ceremony = %s, %v", body, err)
+	}
+	if resp, _ := send(onCallTok, "GET", "/admin/break-glass", ""); resp.StatusCode != 403 {
+		t.Fatalf("GET /admin/break-glass before breaking the glass = %d, want 403", resp.StatusCode)
+	}
+
+	unseal := func(shares []string, email string) (*http.Response, string) {
+		encoded, _ := json.Marshal(map[string]interface{}{"shares": shares, "email": email, "reason": "all admins locked out"})
+		return send("", "POST", "/break-glass/unseal", string(encoded))
+	}
+	if resp, _ := unseal(ceremony.Shares[:1], "oncall@example.com"); resp.StatusCode != 400 {
+		t.Errorf("unsealing with one share = %d, want 400", resp.StatusCode)
+	}
+	if resp, _ := unseal([]string{ceremony.Shares[0], ceremony.Shares[0]}, "oncall@example.com"); resp.StatusCode != 403 {
+		t.Errorf("unsealing with a repeated share = %d, want 403", resp.StatusCode)
+	}
+	resp, body = unseal([]string{ceremony.Shares[2], ceremony.Shares[0]}, "oncall@example.com")
+	if resp.StatusCode != 200 {
+		t.Fatalf("POST /break-glass/unseal = %d: %s", resp.StatusCode, body)
+	}
+	var unsealed dto.BreakGlassUnsealResponse
+	if err := json.Unmarshal([]byte(body), &unsealed); err != nil || unsealed.Token == "" || !unsealed.Session.Active {
+		t.Fatalf("unsealed = %s, %v", body, err)
+	}
+	// The seal opens once
+	if resp, _ := unseal(ceremony.Shares[:2], "oncall@example.com"); resp.StatusCode != 404 {
+		t.Errorf("unsealing a used seal = %d, want 404", resp.StatusCode)
+	}
+
+	resp, body = send(unsealed.Token, "GET", "/admin/break-glass", "")
+	if resp.StatusCode != 200 || !strings.Contains(body, `"reason":"all admins locked out"`) || strings.Contains(body, `"seal"`) {
+		t.Fatalf("GET /admin/break-glass during the session = %d: %s", resp.StatusCode, body)
+	}
+	if resp, body := send(adminTok, "GET", "/admin/events?type=break_glass.opened", ""); resp.StatusCode != 200 || !strings.Contains(body, `"reason":"all admins locked out"`) {
+		t.Errorf("break_glass.opened events = %d: %s", resp.StatusCode, body)
+	}
+
+	path := "/admin/break-glass/sessions/" + strconv.Itoa(unsealed.Session.ID) + "/close"
+	if resp, body := send(adminTok, "POST", path, ""); resp.StatusCode != 200 || !strings.Contains(body, `"active":false`) {
+		t.Fatalf("POST %s = %d: %s", path, resp.StatusCode, body)
+	}
+	if resp, _ := send(unsealed.Token, "GET", "/admin/break-glass", ""); resp.StatusCode != 403 {
+		t.Errorf("GET /admin/break-glass after closing = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestNew_AdminAccess(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.AdminEmails = []string{"admin@example.com"}
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	get := func(token string) int {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/admin/funnel", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("GET /admin/funnel error = %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	// Registering with an address from ADMIN_EMAILS grants nothing
+	id, token := userToken(t, srv, "admin@example.com", "0812345678")
+	if status := get(token); status != 403 {
+		t.Errorf("GET /admin/funnel as a user = %d, want 403", status)
+	}
+	makeAdmin(t, srv, id)
+	if status := get(token); status != 200 {
+		t.Errorf("GET /admin/funnel as an admin = %d, want 200", status)
+	}
+	// The stored role is read on every request, so a demotion applies at once
+	if _, err := srv.db.Exec(`UPDATE users SET role = 'user' WHERE id = ?`, id); err != nil {
+		t.Fatal(err)
+	}
+	if status := get(token); status != 403 {
+		t.Errorf("GET /admin/funnel after a demotion = %d, want 403", status)
+	}
+}
+
+func TestNew_AdminApprovals(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.AdminApprovalKinds = []string{"delete_users"}
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	adminTok := adminToken(t, srv)
+	secondID, secondTok := userToken(t, srv, "second@example.com", "0898887777")
+	makeAdmin(t, srv, secondID)
+	targetID, _ := userToken(t, srv, "target@example.com", "0897776666")
+	send := func(token, method, path string) (*http.Response, dto.AdminActionResponse) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		var action dto.AdminActionResponse
+		json.NewDecoder(resp.Body).Decode(&action)
+		return resp, action
+	}
+
+	deleteTarget := "/admin/users/" + strconv.Itoa(targetID)
+	resp, action := send(adminTok, "DELETE", deleteTarget)
+	if resp.StatusCode != 202 || action.Status != "awaiting_approval" {
+		t.Fatalf("DELETE %s = %d, %+v; want it awaiting approval", deleteTarget, resp.StatusCode, action)
+	}
+	if resp, _ := send(adminTok, "POST", "/admin/actions/"+action.Token+"/approve"); resp.StatusCode != 403 {
+		t.Errorf("approving one's own action = %d, want 403", resp.StatusCode)
+	}
+	resp, approved := send(secondTok, "POST", "/admin/actions/"+action.Token+"/approve")
+	if resp.StatusCode != 200 || approved.Status != "pending" || approved.ReviewedBy == 0 {
+		t.Errorf("POST approve = %d, %+v; want pending", resp.StatusCode, approved)
+	}
+	if resp, _ := send(secondTok, "POST", "/admin/actions/"+action.Token+"/reject"); resp.StatusCode != 409 {
+		t.Errorf("rejecting an approved action = %d, want 409", resp.StatusCode)
+	}
+
+	_, second := send(adminTok, "DELETE", deleteTarget)
+	if resp, rejected := send(secondTok, "POST", "/admin/actions/"+second.Token+"/reject"); resp.StatusCode != 200 || rejected.Status != "rejected" {
+		t.Errorf("POST reject = %d, %+v; want rejected", resp.StatusCode, rejected)
+	}
+	if resp, _ := send(secondTok, "POST", "/admin/actions/missing/approve"); resp.StatusCode != 404 {
+		t.Errorf("approving an unknown action = %d, want 404", resp.StatusCode)
+	}
+
+	cfg = newTestConfig(t)
+	cfg.AdminApprovalKinds = []string{"erase_everything"}
+	if _, err := New(cfg); err == nil {
+		t.Error("New() with an unknown approval kind should fail")
+	}
+}
+
+func TestNew_Audit(t *testing.T) {
+	cfg := newTestConfig(t)
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	token := adminToken(t, srv)
+	targetID, _ := userToken(t, srv, "target@example.com", "0897776666")
+	admin := func(method, path, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		return resp
+	}
+
+	if resp := admin("PUT", "/admin/users/"+strconv.Itoa(targetID)+"/status", `{"status":"suspended"}`); resp.StatusCode != 200 {
+		t.Fatalf("PUT status = %d, want 200", resp.StatusCode)
+	}
+	if resp := admin("PUT", "/admin/client-versions/android", `{"minVersion":"3.1.0"}`); resp.StatusCode != 200 {
+		t.Fatalf("PUT client version = %d, want 200", resp.StatusCode)
+	}
+
+	resp := admin("GET", "/admin/audit?subjectType=user&subjectId="+strconv.Itoa(targetID), "")
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /admin/audit = %d, want 200", resp.StatusCode)
+	}
+	var audit dto.AuditResponse
+	json.NewDecoder(resp.Body).Decode(&audit)
+	if len(audit.Entries) != 1 {
+		t.Fatalf("user audit entries = %+v, want 1", audit.Entries)
+	}
+	entry := audit.Entries[0]
+	if entry.Action != "user.updated" || entry.ActorID != 1 || len(entry.Changes) != 1 ||
+		entry.Changes[0] != (dto.FieldChangeResponse{Field: "status", Old: "active", New: "suspended"}) {
+		t.Errorf("user audit entry = %+v", entry)
+	}
+
+	resp = admin("GET", "/admin/audit?actor=1", "")
+	json.NewDecoder(resp.Body).Decode(&audit)
+	if len(audit.Entries) != 2 || audit.Entries[0].Action != "client_version.set" || audit.Entries[0].SubjectID != "android" {
+		t.Errorf("admin audit entries = %+v, want the client version first", audit.Entries)
+	}
+	if resp := admin("GET", "/admin/audit?limit=501", ""); resp.StatusCode != 400 {
+		t.Errorf("GET /admin/audit with a limit too high = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestNew_RoutePolicy(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.RoutePolicyFile = filepath.Join(t.TempDir(), "routes.yaml")
+	policy := `
+groups:
+  events:
+    prefix: /admin/events
+    roles: [admin]
+    cors:
+      origins: [https://admin.example.com]
+      methods: [GET]
+      headers: [Authorization]
+      credentials: true
+      maxAge: 10m
+  security:
+    prefix: /me/security
+    scopes: [security:read]
+  me:
+    prefix: /me
+    rateLimit: 2/1m
+`
+	if err := os.WriteFile(cfg.RoutePolicyFile, []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	userID, token := userToken(t, srv, "admin@example.com", "0812345678")
+	send := func(method, path, token string, headers map[string]string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		return resp
+	}
+
+	// CORS preflights are answered before authentication
+	resp := send("OPTIONS", "/admin/events", "", map[string]string{"Origin": "https://admin.example.com", "Access-Control-Request-Method": "GET"})
+	if resp.StatusCode != 204 || resp.Header.Get("Access-Control-Allow-Origin") != "https://admin.example.com" ||
+		resp.Header.Get("Access-Control-Allow-Credentials") != "true" || resp.Header.Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("preflight = %d, %v", resp.StatusCode, resp.Header)
+	}
+	if resp := send("OPTIONS", "/admin/events", "", map[string]string{"Origin": "https://evil.example.com", "Access-Control-Request-Method": "GET"}); resp.StatusCode != 403 {
+		t.Errorf("preflight from another origin = %d, want 403", resp.StatusCode)
+	}
+
+	// Roles are the users' own
+	resp = send("GET", "/admin/events", token, map[string]string{"Origin": "https://admin.example.com"})
+	if resp.StatusCode != 403 || resp.Header.Get("Access-Control-Allow-Origin") != "https://admin.example.com" {
+		t.Errorf("GET /admin/events as a user = %d, want 403 with CORS headers", resp.StatusCode)
+	}
+	makeAdmin(t, srv, userID)
+	if resp := send("GET", "/admin/events", token, nil); resp.StatusCode != 200 {
+		t.Errorf("GET /admin/events as an admin = %d, want 200", resp.StatusCode)
+	}
+	if resp := send("GET", "/admin/events", "", nil); resp.StatusCode != 401 {
+		t.Errorf("GET /admin/events without a token = %d, want 401", resp.StatusCode)
+	}
+
+	// Scopes come from the token's scope claim
+	if resp := send("GET", "/me/security/report", token, nil); resp.StatusCode != 403 {
+		t.Errorf("GET /me/security/report without the scope = %d, want 403", resp.StatusCode)
+	}
+	scoped := jwt.NewService(cfg.JWTSecret)
+	scoped.RegisterClaimsProvider("scope", jwt.ClaimsProviderFunc(func(jwt.ClaimsRequest) (map[string]interface{}, error) {
+		return map[string]interface{}{"scope": "profile security:read"}, nil
+	}))
+	scopedToken, _, err := scoped.GenerateToken(1, "admin@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := send("GET", "/me/security/report", scopedToken, nil); resp.StatusCode != 200 {
+		t.Errorf("GET /me/security/report with the scope = %d, want 200", resp.StatusCode)
+	}
+
+	// The rate limit counts the group's routes together
+	if resp := send("GET", "/me", token, nil); resp.StatusCode != 200 || resp.Header.Get("RateLimit-Limit") != "2" {
+		t.Errorf("GET /me = %d, %v", resp.StatusCode, resp.Header)
+	}
+	send("GET", "/me/share-links", token, nil)
+	if resp := send("GET", "/me", token, nil); resp.StatusCode != 429 {
+		t.Errorf("third call to the /me group = %d, want 429", resp.StatusCode)
+	}
+
+	cfg = newTestConfig(t)
+	cfg.RoutePolicyFile = filepath.Join(t.TempDir(), "missing.yaml")
+	if _, err := New(cfg); err == nil {
+		t.Error("New() with a missing ROUTE_POLICY_FILE should fail")
+	}
+}
+
+func TestNew_ReadCoalescing(t *testing.T) {
+	cfg := newTestConfig(t)
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	token := adminToken(t, srv)
+	get := func(path string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		return resp
+	}
+	for range 3 {
+		if resp := get("/me"); resp.StatusCode != 200 {
+			t.Fatalf("GET /me = %d", resp.StatusCode)
+		}
+	}
+
+	resp := get("/admin/read-coalescing")
+	var stats dto.ReadCoalescingResponse
+	json.NewDecoder(resp.Body).Decode(&stats)
+	if resp.StatusCode != 200 || stats.Calls < 3 || stats.Queries > stats.Calls || stats.Coalesced != stats.Calls-stats.Queries {
+		t.Errorf("GET /admin/read-coalescing = %d, %+v", resp.StatusCode, stats)
+	}
+	resp = get("/admin/read-coalescing?format=prometheus")
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "api_read_coalescing_calls_total ") {
+		t.Errorf("prometheus format = %q", body)
+	}
+	if resp := get("/admin/read-coalescing?format=xml"); resp.StatusCode != 400 {
+		t.Errorf("unknown format = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestNew_MissingUserCache(t *testing.T) {
+	srv, err := New(newTestConfig(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	login := func() int {
+		t.Helper()
+		body := `{"email":"late@example.com","password":"password123"}`
+		req := httptest.NewRequest("POST", "/login", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("POST /login error = %v", err)
+		}
+		return resp.StatusCode
+	}
+	for range 2 {
+		if status := login(); status != 401 {
+			t.Fatalf("login before registering = %d, want 401", status)
+		}
+	}
+	// Registering forgets the cached miss at once
+	userToken(t, srv, "late@example.com", "0812345679")
+	if status := login(); status != 200 {
+		t.Errorf("login after registering = %d, want 200", status)
+	}
+}
+
+func TestNew_Recordings(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.RecordingEnabled = true
+	cfg.RecordingFile = filepath.Join(t.TempDir(), "recordings.jsonl")
+	calls := 0
+	srv, err := New(cfg, WithRoutes(func(router fiber.Router) {
+		router.Post("/v1/echo", func(c *fiber.Ctx) error {
+			calls++
+			if c.Get("Authorization") == "" {
+				return fiber.NewError(fiber.StatusUnauthorized, "missing token")
+			}
+			var body map[string]string
+			if err := json.Unmarshal(c.Body(), &body); err != nil {
+				return err
+			}
+			return c.JSON(fiber.Map{"name": body["name"], "token": "t-123"})
+		})
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	req := httptest.NewRequest("POST", "/v1/echo?lang=th", strings.NewReader(`{"name":"A","password":"hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer user-token")
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("POST /v1/echo = %v, %v", resp, err)
+	}
+
+	token := adminToken(t, srv)
+	admin := func(method, path string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	var list dto.RecordingsResponse
+	json.NewDecoder(admin("GET", "/admin/recordings?path=/v1").Body).Decode(&list)
+	if len(list.Recordings) != 1 || list.Recordings[0].Method != "POST" || list.Recordings[0].Status != 200 {
+		t.Fatalf("recordings = %+v", list)
+	}
+	id := strconv.FormatInt(list.Recordings[0].ID, 10)
+
+	var recording dto.RecordingResponse
+	json.NewDecoder(admin("GET", "/admin/recordings/"+id).Body).Decode(&recording)
+	if recording.Headers["Authorization"] != "[redacted]" || strings.Contains(recording.Body, "hunter2") ||
+		recording.Query != "lang=th" || recording.Response.Body != `{"name":"A","token":"[redacted]"}` {
+		t.Errorf("recording = %+v, want secrets redacted", recording)
+	}
+
+	// The replay runs the route again with the caller's token
+	resp := admin("POST", "/admin/recordings/"+id+"/replay")
+	if resp.StatusCode != 200 {
+		t.Fatalf("replay status = %d", resp.StatusCode)
+	}
+	var replay dto.ReplayResponse
+	json.NewDecoder(resp.Body).Decode(&replay)
+	if calls != 2 || !replay.StatusMatches || !replay.BodyMatches || replay.Replay.Status != 200 {
+		t.Errorf("replay = %+v after %d calls", replay, calls)
+	}
+	// Replays and the admin routes are not recorded, only the echo and the
+	// admin's registration
+	json.NewDecoder(admin("GET", "/admin/recordings").Body).Decode(&list)
+	if len(list.Recordings) != 2 {
+		t.Errorf("recordings after replay = %+v", list)
+	}
+	if resp := admin("GET", "/admin/recordings/999"); resp.StatusCode != 404 {
+		t.Errorf("unknown recording status = %d, want 404", resp.StatusCode)
+	}
+	if data, err := os.ReadFile(cfg.RecordingFile); err != nil || strings.Count(string(data), "\n") != 2 {
+		t.Errorf("recording file = %q, %v", data, err)
+	}
+
+	cfg = newTestConfig(t)
+	cfg.RecordingEnabled = true
+	cfg.Env = "production"
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should refuse request recording in production")
+	}
+}
+
+func TestNew_Events(t *testing.T) {
+	cfg := newTestConfig(t)
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	post := func(path, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	if resp := post("/register", `{"email":"events@example.com","password":"password123","fullName":"Event User","phoneNumber":"0812345678","birthday":"1990-01-15"}`); resp.StatusCode != 201 {
+		t.Fatalf("POST /register status = %d", resp.StatusCode)
+	}
+	if resp := post("/login", `{"email":"events@example.com","password":"password123"}`); resp.StatusCode != 200 {
+		t.Fatalf("POST /login status = %d", resp.StatusCode)
+	}
+
+	makeAdmin(t, srv, 1)
+	token, _, err := jwt.NewService(cfg.JWTSecret).GenerateToken(1, "events@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(path string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	var page dto.EventsResponse
+	json.NewDecoder(get("/admin/events").Body).Decode(&page)
+	if len(page.Events) != 2 || page.Events[0].Type != "user.registered" || page.Events[1].Type != "user.logged_in" ||
+		page.Events[0].Data["email"] != "events@example.com" || page.NextAfter != 0 {
+		t.Errorf("events = %+v", page)
+	}
+	json.NewDecoder(get("/admin/events?type=user.logged_in&limit=1").Body).Decode(&page)
+	if len(page.Events) != 1 || page.Events[0].Data["method"] != "password" || page.NextAfter != page.Events[0].ID {
+		t.Errorf("logins = %+v", page)
+	}
+	for _, path := range []string{"/admin/events?type=user.exploded", "/admin/events?since=yesterday", "/admin/events/export?format=xml"} {
+		if resp := get(path); resp.StatusCode != 400 {
+			t.Errorf("GET %s status = %d, want 400", path, resp.StatusCode)
+		}
+	}
+
+	resp := get("/admin/events/export?format=csv")
+	body, _ := io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if resp.StatusCode != 200 || resp.Header.Get("Content-Type") != "text/csv; charset=utf-8" || len(lines) != 3 ||
+		!strings.HasPrefix(lines[0], "id,type,schemaVersion") || !strings.Contains(lines[1], ",user.registered,1,user,") {
+		t.Errorf("CSV export = %d %q", resp.StatusCode, body)
+	}
+	body, _ = io.ReadAll(get("/admin/events/export?type=user.registered").Body)
+	var event dto.EventResponse
+	if err := json.Unmarshal(body, &event); err != nil || event.Type != "user.registered" || event.SchemaVersion != 1 {
+		t.Errorf("JSON lines export = %q, %v", body, err)
+	}
+}
+
+func TestNew_AccountStatus(t *testing.T) {
+	cfg := newTestConfig(t)
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	send := func(method, path, token, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	token := adminToken(t, srv)
+	if resp := send("POST", "/register", "", `{"email":"status@example.com","password":"password123","fullName":"Status User","phoneNumber":"0812345678","birthday":"1990-01-15"}`); resp.StatusCode != 201 {
+		t.Fatalf("POST /register status = %d", resp.StatusCode)
+	}
+
+	tests := []struct {
+		status string
+		want   int
+	}{
+		{"deactivated", 200},
+		{"suspended", 409},
+		{"archived", 400},
+		{"active", 200},
+	}
+	for _, tt := range tests {
+		if resp := send("PUT", "/admin/users/2/status", token, `{"status":"`+tt.status+`"}`); resp.StatusCode != tt.want {
+			t.Errorf("PUT status %s = %d, want %d", tt.status, resp.StatusCode, tt.want)
+		}
+	}
+	if resp := send("PUT", "/admin/users/99/status", token, `{"status":"active"}`); resp.StatusCode != 404 {
+		t.Errorf("PUT status of an unknown user = %d, want 404", resp.StatusCode)
+	}
+
+	send("PUT", "/admin/users/2/status", token, `{"status":"deactivated"}`)
+	if resp := send("POST", "/login", "", `{"email":"status@example.com","password":"password123"}`); resp.StatusCode != 403 {
+		t.Errorf("POST /login of a deactivated user status = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestNew_ReadModels(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.WorkerInterval = 10 * time.Millisecond
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	for _, body := range []string{
+		`{"email":"ann@example.com","password":"password123","fullName":"Ann Lee","phoneNumber":"0812345678","birthday":"1990-01-15"}`,
+		`{"email":"bob@example.com","password":"password123","fullName":"Bob Stone","phoneNumber":"0812345678","birthday":"1990-01-15"}`,
+	} {
+		req := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 201 {
+			t.Fatalf("POST /register = %v, %v", resp, err)
+		}
+	}
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"email":"ann@example.com","password":"password123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("POST /login = %v, %v", resp, err)
+	}
+
+	makeAdmin(t, srv, 1)
+	token, _, err := jwt.NewService(cfg.JWTSecret).GenerateToken(1, "ann@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(method, path string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// The projection workers catch up with the event log
+	caughtUp := func() bool {
+		var projections dto.ProjectionsResponse
+		json.NewDecoder(send("GET", "/admin/read-models").Body).Decode(&projections)
+		for _, projection := range projections.Projections {
+			if projection.UpdatedAt == nil || projection.Behind != 0 {
+				return false
+			}
+		}
+		return len(projections.Projections) == 2
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !caughtUp() {
+		if time.Now().After(deadline) {
+			t.Fatal("read models did not catch up with the event log")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var result dto.UserSearchResponse
+	json.NewDecoder(send("GET", "/admin/users/search?q=LEE").Body).Decode(&result)
+	if result.Total != 1 || len(result.Users) != 1 || result.Users[0].Email != "ann@example.com" ||
+		result.Users[0].Logins != 1 || result.Users[0].LastLoginAt == nil {
+		t.Errorf("search for lee = %+v", result)
+	}
+	json.NewDecoder(send("GET", "/admin/users/search?status=active&limit=1&offset=1").Body).Decode(&result)
+	if result.Total != 2 || len(result.Users) != 1 || result.Users[0].Email != "bob@example.com" || result.Users[0].Logins != 0 {
+		t.Errorf("second active user = %+v", result)
+	}
+
+	resp := send("GET", "/admin/users/export?status=active")
+	if resp.StatusCode != 200 || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("GET /admin/users/export = %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var exported []string
+	lines := json.NewDecoder(resp.Body)
+	for {
+		var user dto.UserSummaryResponse
+		if err := lines.Decode(&user); err != nil {
+			break
+		}
+		exported = append(exported, user.Email)
+	}
+	if strings.Join(exported, ",") != "ann@example.com,bob@example.com" {
+		t.Errorf("exported users = %v", exported)
+	}
+	if resp := send("GET", "/admin/users/export?createdTo=tomorrow"); resp.StatusCode != 400 {
+		t.Errorf("GET export with a bad createdTo status = %d, want 400", resp.StatusCode)
+	}
+
+	if resp := send("GET", "/admin/users/search?createdFrom=yesterday"); resp.StatusCode != 400 {
+		t.Errorf("GET with a bad createdFrom status = %d, want 400", resp.StatusCode)
+	}
+	if resp := send("POST", "/admin/read-models/login_stats/rebuild"); resp.StatusCode != 202 {
+		t.Errorf("POST rebuild status = %d, want 202", resp.StatusCode)
+	}
+	if resp := send("POST", "/admin/read-models/unknown/rebuild"); resp.StatusCode != 404 {
+		t.Errorf("POST rebuild of an unknown read model status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestNew_Claims(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.WorkerInterval = 10 * time.Millisecond
+	srv, err := New(cfg, WithProtectedRoutes(func(router fiber.Router) {
+		router.Get("/role", func(c *fiber.Ctx) error {
+			role, _ := c.Locals("user").(*jwt.Claims).Extra["role"].(string)
+			return c.SendString(role)
+		})
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	admin := adminToken(t, srv)
+	send := func(method, path, token, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(`{"email":"claims@example.com","password":"password123","fullName":"Claims User","phoneNumber":"0812345678","birthday":"1990-01-15"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %v, %v", resp, err)
+	}
+	token, _, err := jwt.NewService(cfg.JWTSecret).GenerateToken(2, "claims@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The role comes from the user's record, not the token
+	for range 2 {
+		resp := send("GET", "/role", token, "")
+		if body, _ := io.ReadAll(resp.Body); resp.StatusCode != 200 || string(body) != "user" {
+			t.Fatalf("GET /role = %d %q, want the user role", resp.StatusCode, body)
+		}
+	}
+	unknown, _, _ := jwt.NewService(cfg.JWTSecret).GenerateToken(99, "gone@example.com")
+	if resp := send("GET", "/role", unknown, ""); resp.StatusCode != 401 {
+		t.Errorf("GET /role of an unknown user = %d, want 401", resp.StatusCode)
+	}
+
+	// Suspending the user drops their cached claims
+	if resp := send("PUT", "/admin/users/2/status", admin, `{"status":"suspended"}`); resp.StatusCode != 200 {
+		t.Fatalf("PUT status = %d", resp.StatusCode)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for send("GET", "/role", token, "").StatusCode != 403 {
+		if time.Now().After(deadline) {
+			t.Fatal("a suspended user was still let in")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var stats dto.ClaimsCacheResponse
+	json.NewDecoder(send("GET", "/admin/claims-cache", admin, "").Body).Decode(&stats)
+	if stats.Shared || stats.LocalHits == 0 || stats.Misses < 3 || stats.Invalidations != 1 || stats.HitRate <= 0 {
+		t.Errorf("claims cache stats = %+v", stats)
+	}
+}
+
+// warmModule fails its first warm-up, then waits for release
+type warmModule struct {
+	baseModule
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (m *warmModule) WarmUp() error {
+	if m.calls.Add(1) == 1 {
+		return errors.New("cache unavailable")
+	}
+	<-m.release
+	return nil
+}
+
+func TestNew_Readiness(t *testing.T) {
+	cfg := newTestConfig(t)
+	m := &warmModule{baseModule: baseModule{"warm"}, release: make(chan struct{})}
+	srv, err := New(cfg, WithModules(func(*Deps) (Module, error) { return m, nil }))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	probe := func(path string) (int, map[string]string) {
+		t.Helper()
+		resp, err := srv.App().Test(httptest.NewRequest("GET", path, nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body map[string]string
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+	if status, _ := probe("/livez"); status != 200 {
+		t.Errorf("GET /livez status = %d, want 200", status)
+	}
+	if status, body := probe("/readyz"); status != 503 || !strings.HasPrefix(body["reason"], "warming up") {
+		t.Errorf("GET /readyz while warming up = %d %v, want 503", status, body)
+	}
+
+	// The failed warm-up is retried
+	close(m.release)
+	select {
+	case <-srv.Ready():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not become ready")
+	}
+	if status, _ := probe("/readyz"); status != 200 || m.calls.Load() != 2 {
+		t.Errorf("GET /readyz after warm-up = %d after %d warm-ups, want 200 after 2", status, m.calls.Load())
+	}
+	if stats := srv.db.Stats(); stats.Idle < cfg.WarmUpDBConns {
+		t.Errorf("idle database connections = %d, want %d", stats.Idle, cfg.WarmUpDBConns)
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if status, body := probe("/readyz"); status != 503 || body["reason"] != "shutting down" {
+		t.Errorf("GET /readyz after Shutdown = %d %v, want 503", status, body)
+	}
+}
+
+func TestNew_DegradedDependencies(t *testing.T) {
+	// A port nothing listens on, until Redis "comes back" below
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	cfg := newTestConfig(t)
+	cfg.ClaimsCacheRedisURL = "redis://" + addr
+	cfg.DependencyCheckTimeout = 500 * time.Millisecond
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() with Redis down error = %v", err)
+	}
+	defer srv.Close()
+	select {
+	case <-srv.Ready():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not become ready with Redis down")
+	}
+
+	readyz := func() (int, dto.ReadinessResponse) {
+		t.Helper()
+		resp, err := srv.App().Test(httptest.NewRequest("GET", "/readyz", nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body dto.ReadinessResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+	status, body := readyz()
+	if status != 200 || body.Status != "degraded" || len(body.Dependencies) != 1 {
+		t.Fatalf("GET /readyz with Redis down = %d %+v, want 200 degraded", status, body)
+	}
+	if dep := body.Dependencies[0]; dep.Name != "redis:claims-cache" || dep.Up || dep.Error == "" || dep.Features[0] != "shared claims cache" {
+		t.Errorf("dependency = %+v", dep)
+	}
+	// Requests are served from the database meanwhile
+	_, token := userToken(t, srv, "degraded@example.com", "0812345678")
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 200 {
+		t.Errorf("GET /me with Redis down = %v, %v", resp, err)
+	}
+
+	// Redis answers again and the next check enables the cache
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("port %s was taken meanwhile: %v", addr, err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 1024)
+				for {
+					if _, err := conn.Read(buf); err != nil {
+						return
+					}
+					conn.Write([]byte("+PONG\r\n"))
+				}
+			}()
+		}
+	}()
+	srv.dependencies.CheckAll(context.Background())
+	if status, body := readyz(); status != 200 || body.Status != "ready" || !body.Dependencies[0].Up {
+		t.Errorf("GET /readyz after Redis came back = %d %+v, want 200 ready", status, body)
+	}
+}
+
+func TestNew_AccountReports(t *testing.T) {
+	cfg := newTestConfig(t)
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	requestLink := func(path, token string) dto.AccountReportLinkResponse {
+		t.Helper()
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil || resp.StatusCode != 201 {
+			t.Fatalf("POST %s = %v, %v", path, resp, err)
+		}
+		var link dto.AccountReportLinkResponse
+		json.NewDecoder(resp.Body).Decode(&link)
+		return link
+	}
+	download := func(url string) *http.Response {
+		t.Helper()
+		resp, err := srv.App().Test(httptest.NewRequest("GET", url, nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// Users download their own report once
+	userID, token := userToken(t, srv, "report@example.com", "0898765432")
+	link := requestLink("/me/account-report", token)
+	if !strings.Contains(link.URL, "/account-reports/") || link.ExpiresAt.IsZero() {
+		t.Fatalf("link = %+v", link)
+	}
+	resp := download(link.URL + "?format=pdf")
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 || resp.Header.Get("Content-Type") != "application/pdf" || !strings.HasPrefix(string(body), "%PDF-") ||
+		resp.Header.Get("Content-Disposition") != `attachment; filename="account-report-`+strconv.Itoa(userID)+`.pdf"` {
+		t.Fatalf("GET %s = %d %v", link.URL, resp.StatusCode, resp.Header)
+	}
+	if resp := download(link.URL); resp.StatusCode != 410 {
+		t.Errorf("second download = %d, want 410", resp.StatusCode)
+	}
+
+	// Admins get a link to any user's report
+	link = requestLink("/admin/users/"+strconv.Itoa(userID)+"/account-report", adminToken(t, srv))
+	resp = download(link.URL)
+	body, _ = io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") || !strings.Contains(string(body), "report@example.com") {
+		t.Errorf("GET %s = %d %s", link.URL, resp.StatusCode, body)
+	}
+	if resp := download("/account-reports/unknown"); resp.StatusCode != 404 {
+		t.Errorf("unknown link = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestNew_Diagnostics(t *testing.T) {
+	cfg := newTestConfig(t)
+	srv, err := New(cfg, WithRoutes(func(router fiber.Router) {
+		router.Get("/orders/:id", func(c *fiber.Ctx) error {
+			return c.Status(500).JSON(dto.ErrorResponse{Error: "Failed to get order", Message: "order " + c.Params("id") + " is locked"})
+		})
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	for _, id := range []string{"7", "1234"} {
+		if resp, err := srv.App().Test(httptest.NewRequest("GET", "/orders/"+id, nil), -1); err != nil || resp.StatusCode != 500 {
+			t.Fatalf("GET /orders/%s = %v, %v", id, resp, err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/admin/diagnostics", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken(t, srv))
+	resp, err := srv.App().Test(req, -1)
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("GET /admin/diagnostics = %v, %v", resp, err)
+	}
+	var body dto.DiagnosticsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Goroutines == 0 || body.Heap.AllocBytes == 0 || body.DB.Open == 0 {
+		t.Errorf("runtime figures = %d goroutines, heap %+v, db %+v", body.Goroutines, body.Heap, body.DB)
+	}
+	if _, ok := body.Caches["claims"]; !ok {
+		t.Errorf("caches = %v, want the claims cache", body.Caches)
+	}
+	if _, ok := body.Queues["admin_actions"]; !ok {
+		t.Errorf("queues = %v, want admin_actions", body.Queues)
+	}
+	if len(body.Errors) != 1 || body.Errors[0].Source != "GET /orders/:id" || body.Errors[0].Count != 2 ||
+		body.Errors[0].Message != "Failed to get order: order N is locked" {
+		t.Errorf("errors = %+v, want the failed order lookups counted together", body.Errors)
+	}
+
+	// Only admins may read diagnostics
+	_, token := userToken(t, srv, "diagnostics@example.com", "0898765432")
+	req = httptest.NewRequest("GET", "/admin/diagnostics", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 403 {
+		t.Errorf("GET /admin/diagnostics as a user = %v, %v, want 403", resp, err)
+	}
+}
+
+func TestNew_Exports(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.ExportStore = "file"
+	cfg.ExportDir = t.TempDir()
+	cfg.ExportPrefix = "daily/"
+	cfg.ExportTime = "00:00"
+
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	// Past EXPORT_TIME with no export yet today, the worker exports on startup
+	manifest := filepath.Join(cfg.ExportDir, "daily", time.Now().UTC().Format("2006-01-02"), "manifest.json")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(manifest); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s was not written", manifest)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNew_InvalidExportStore(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.ExportStore = "ftp"
+
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should fail for an unknown EXPORT_STORE")
+	}
+}
+
+func TestNew_JSONEncoder(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.JSONEncoder = "sonic"
+	if _, err := New(cfg); !errors.Is(err, encoder.ErrUnknownEncoder) {
+		t.Fatalf("New() error = %v, want ErrUnknownEncoder", err)
+	}
+
+	// The fast encoder must write exactly what encoding/json writes
+	created := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.FixedZone("ICT", 7*3600))
+	user := dto.UserResponse{
+		ID:          42,
+		Email:       "a&b@example.com",
+		FullName:    "Jane <\"JD\"> Doe \u2028 ✓ \xff",
+		PhoneNumber: "0812345678",
+		Birthday:    "1990-01-15",
+		CreatedAt:   created,
+		UpdatedAt:   created.Add(time.Hour).UTC(),
+	}
+	withAvatar := user
+	withAvatar.AvatarURL = "/uploads/avatar.png"
+	values := []interface{}{
+		user,
+		withAvatar,
+		dto.LoginResponse{Message: "Login successful", Token: "a.b.c", User: user, ExpiresAt: created},
+		dto.ErrorResponse{Error: "Unauthorized", Message: "Invalid token\n"},
+		dto.ErrorResponse{Error: "Validation failed", Message: "taken", Code: "name_reserved"},
+		dto.SuccessResponse{Message: "ok"},
+		dto.SuccessResponse{Message: "ok", Data: withAvatar},
+		dto.SuccessResponse{Message: "ok", Data: map[string]int{"count": 1}},
+		dto.SuccessResponse{Message: "ok", Data: []dto.UserResponse{user}},
+		dto.SuccessResponse{Message: "ok", Data: (*dto.UserResponse)(nil)},
+	}
+	for _, v := range values {
+		want, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal(%T) error = %v", v, err)
+		}
+		got, err := encoder.Marshal(v)
+		if err != nil || string(got) != string(want) {
+			t.Errorf("encoder.Marshal(%T) = %s, %v, want %s", v, got, err, want)
+		}
+	}
+
+	invalid := dto.UserResponse{CreatedAt: time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if _, err := encoder.Marshal(dto.SuccessResponse{Data: invalid}); err == nil {
+		t.Error("encoder.Marshal() error = nil for a year past 9999")
+	}
+}
+
+// benchmarkEncoders runs the request built by newReq against a server for
+// each JSON encoder. Compare with
+// go test ./server -run '^$' -bench 'Login|Me' -benchmem
+func benchmarkEncoders(b *testing.B, newReq func(b *testing.B, srv *Server) func() *http.Request) {
+	for _, name := range []string{encoder.Standard, encoder.Fast} {
+		b.Run(name, func(b *testing.B) {
+			cfg := newTestConfig(b)
+			cfg.JSONEncoder = name
+			srv, err := New(cfg)
+			if err != nil {
+				b.Fatalf("New() error = %v", err)
+			}
+			defer srv.Close()
+			req := newReq(b, srv)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				resp, err := srv.App().Test(req(), -1)
+				if err != nil || resp.StatusCode != 200 {
+					b.Fatalf("request = %v, %v", resp, err)
+				}
+				io.Copy(io.Discard, resp.Body)
+			}
+		})
+	}
+}
+
+// BenchmarkLogin is dominated by the bcrypt comparison; the difference
+// between encoders shows in B/op and allocs/op rather than ns/op
+func BenchmarkLogin(b *testing.B) {
+	benchmarkEncoders(b, func(b *testing.B, srv *Server) func() *http.Request {
+		adminToken(b, srv)
+		return func() *http.Request {
+			req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"email":"admin@example.com","password":"password123"}`))
+			req.Header.Set("Content-Type", "application/json")
+			return req
+		}
+	})
+}
+
+func BenchmarkMe(b *testing.B) {
+	benchmarkEncoders(b, func(b *testing.B, srv *Server) func() *http.Request {
+		token := adminToken(b, srv)
+		return func() *http.Request {
+			req := httptest.NewRequest("GET", "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			return req
+		}
+	})
+}
+
+func TestNew_DuplicateModule(t *testing.T) {
+	if _, err := New(newTestConfig(t), WithModules(UsersModule, UsersModule)); err == nil {
+		t.Error("New() should fail when a module is registered twice")
+	}
+}
+
+func TestNew_Override(t *testing.T) {
+	repo := NewMemoryUserRepository()
+	user, err := repo.Create(&User{Email: "override@example.com", FullName: "Override User"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	jwtService := jwt.NewService("override-secret")
+
+	srv, err := New(newTestConfig(t), WithUserRepository(repo), Override(jwtService))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	// Tokens are validated by the replacement service
+	token, _, err := jwtService.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := testRequest(t, srv.App(), req)
+	if resp.StatusCode != 200 {
+		t.Errorf("GET /me = %d; want 200 with a token from the override", resp.StatusCode)
+	}
+}
+
+func TestNew_WithHook(t *testing.T) {
+	srv, err := New(newTestConfig(t), WithHook(hooks.PreRegister, hooks.HookFunc(func(*hooks.Event) error {
+		return hooks.Veto("signups are closed")
+	})))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	body := `{"email":"hooked@example.com","password":"password123","fullName":"Hooked User","phoneNumber":"0812345678","birthday":"1990-01-15"}`
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := testRequest(t, srv.App(), req)
+	if resp.StatusCode != 403 {
+		t.Errorf("POST /register = %d; want 403 when a hook vetoes", resp.StatusCode)
+	}
+}
+
+func TestNew_PasswordReset(t *testing.T) {
+	var token string
+	srv, err := New(newTestConfig(t), WithHook(hooks.PasswordReset, hooks.HookFunc(func(e *hooks.Event) error {
+		token, _ = e.Data["token"].(string)
+		return nil
+	})))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	post := func(path, body string) int {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	post("/register", `{"email":"reset@example.com","password":"password123","fullName":"Reset User","phoneNumber":"0812345678","birthday":"1990-01-15"}`)
+	if status := post("/password/forgot", `{"email":"reset@example.com"}`); status != 202 || token == "" {
+		t.Fatalf("POST /password/forgot = %d, token %q; want 202 and a delivered token", status, token)
+	}
+
+	reset := `{"token":"` + token + `","newPassword":"newpassword"}`
+	if status := post("/password/reset", reset); status != 200 {
+		t.Errorf("POST /password/reset = %d, want 200", status)
+	}
+	if status := post("/password/reset", reset); status != 409 {
+		t.Errorf("replayed POST /password/reset = %d, want 409", status)
+	}
+	if status := post("/login", `{"email":"reset@example.com","password":"newpassword"}`); status != 200 {
+		t.Errorf("POST /login with the new password = %d, want 200", status)
+	}
+}
+
+func TestNew_SecurityReport(t *testing.T) {
+	cfg := newTestConfig(t)
+	// httptest requests come from 0.0.0.0, which stands in for the CDN
+	cfg.TrustedProxies = []string{"0.0.0.0"}
+	cfg.GeoCountryHeader = "CF-IPCountry"
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	do := func(method, path, body string, headers map[string]string) (int, []byte) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, got
+	}
+
+	do("POST", "/register", `{"email":"report@example.com","password":"password123","fullName":"Report User","phoneNumber":"0812345678","birthday":"1990-01-15"}`, nil)
+	do("POST", "/login", `{"email":"report@example.com","password":"wrongpassword"}`, map[string]string{"CF-IPCountry": "ru"})
+	status, body := do("POST", "/login", `{"email":"report@example.com","password":"password123"}`, map[string]string{"CF-IPCountry": "TH", "User-Agent": "test-browser"})
+	var login struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(body, &login); status != 200 || err != nil {
+		t.Fatalf("POST /login = %d %s", status, body)
+	}
+
+	status, body = do("GET", "/me/security/report", "", map[string]string{"Authorization": "Bearer " + login.Token})
+	var report dto.SecurityReportResponse
+	if err := json.Unmarshal(body, &report); status != 200 || err != nil {
+		t.Fatalf("GET /me/security/report = %d %s", status, body)
+	}
+	if report.SuccessfulLogins != 1 || report.FailedAttempts != 1 || len(report.RecentFailures) != 1 || report.RecentFailures[0].Country != "RU" {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.RecentLogins) != 1 || report.RecentLogins[0].Device != "test-browser" || len(report.Countries) != 1 || report.Countries[0] != "TH" {
+		t.Errorf("report logins = %+v, countries %v", report.RecentLogins, report.Countries)
+	}
+}
+
+// documentsModule serves documents their viewers may read, as checked by
+// OpenFGA
+type documentsModule struct {
+	baseModule
+	authz *usecase.AuthorizationUseCase
+}
+
+func (m documentsModule) Routes(routes *Routes) {
+	routes.Protected(func(router fiber.Router) {
+		router.Get("/documents/:id", middleware.PermissionMiddleware(m.authz, "viewer", "document", "id"), func(c *fiber.Ctx) error {
+			return c.SendString("document " + c.Params("id"))
+		})
+	})
+}
+
+func TestNew_OpenFGA(t *testing.T) {
+	// A fake OpenFGA store whose model lets assignees of role:user view document:1
+	var mu sync.Mutex
+	tuples := make(map[string]bool)
+	fga := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		type key struct{ User, Relation, Object string }
+		var req struct {
+			Writes *struct {
+				TupleKeys []key `json:"tuple_keys"`
+			}
+			Deletes *struct {
+				TupleKeys []key `json:"tuple_keys"`
+			}
+			TupleKey key `json:"tuple_key"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch r.URL.Path {
+		case "/stores/store/write":
+			if req.Writes != nil {
+				for _, k := range req.Writes.TupleKeys {
+					tuples[k.User+"|"+k.Relation+"|"+k.Object] = true
+				}
+			}
+			if req.Deletes != nil {
+				for _, k := range req.Deletes.TupleKeys {
+					delete(tuples, k.User+"|"+k.Relation+"|"+k.Object)
+				}
+			}
+			io.WriteString(w, "{}")
+		case "/stores/store/check":
+			allowed := req.TupleKey.Relation == "viewer" && req.TupleKey.Object == "document:1" &&
+				tuples[req.TupleKey.User+"|assignee|role:user"]
+			json.NewEncoder(w).Encode(map[string]bool{"allowed": allowed})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer fga.Close()
+
+	cfg := newTestConfig(t)
+	cfg.OpenFGAAPIURL = fga.URL
+	cfg.OpenFGAStoreID = "store"
+	srv, err := New(cfg, WithModules(UsersModule, AuthorizationModule, func(deps *Deps) (Module, error) {
+		authz, err := container.Get[*usecase.AuthorizationUseCase](deps.Container)
+		return documentsModule{baseModule{"documents"}, authz}, err
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(`{"email":"fga@example.com","password":"password123","fullName":"FGA User","phoneNumber":"0812345678","birthday":"1990-01-15"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %v, %v", resp, err)
+	}
+	mu.Lock()
+	if len(tuples) != 1 {
+		t.Errorf("tuples = %v, want the new user's role", tuples)
+	}
+	mu.Unlock()
+
+	var userID int
+	if err := srv.db.QueryRow(`SELECT id FROM users WHERE email = ?`, "fga@example.com").Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := jwt.NewService(cfg.JWTSecret).GenerateToken(userID, "fga@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]int{"/documents/1": 200, "/documents/2": 403} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if resp := testRequest(t, srv.App(), req); resp.StatusCode != want {
+			t.Errorf("GET %s = %d; want %d", path, resp.StatusCode, want)
+		}
+	}
+
+	resp := testRequest(t, srv.App(), httptest.NewRequest("POST", "/admin/authorization/sync", nil))
+	if resp.StatusCode != 401 {
+		t.Errorf("POST /admin/authorization/sync status = %d, want 401 without a token", resp.StatusCode)
+	}
+}
+
+// recordingMailer records the subjects of the emails it is asked to send
+type recordingMailer struct {
+	mu       sync.Mutex
+	subjects []string
+}
+
+func (m *recordingMailer) Send(to []string, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subjects = append(m.subjects, subject)
+	return nil
+}
+
+func TestNew_Digests(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.DigestSchedule = "daily"
+	cfg.DigestTime = "00:00"
+	cfg.AdminEmails = []string{"admin@example.com"}
+
+	mailer := &recordingMailer{}
+	srv, err := New(cfg, Override[repository.Mailer](mailer), WithHook(hooks.PostRegister, hooks.HookFunc(func(*hooks.Event) error {
+		return errors.New("webhook unreachable")
+	})))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	// Past DIGEST_TIME with no digest sent today, the worker sends one on startup
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mailer.mu.Lock()
+		sent := len(mailer.subjects)
+		mailer.mu.Unlock()
+		if sent > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the daily digest was not sent")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Failed post-register hooks are kept for the next digest
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(`{"email":"digest@example.com","password":"password123","fullName":"Digest User","phoneNumber":"0812345678","birthday":"1990-01-15"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %v, %v", resp, err)
+	}
+	var failure string
+	if err := srv.db.QueryRow(`SELECT error FROM hook_failures WHERE point = ?`, "post-register").Scan(&failure); err != nil || failure != "webhook unreachable" {
+		t.Errorf("hook failure = %q, %v; want it recorded", failure, err)
+	}
+
+	resp := testRequest(t, srv.App(), httptest.NewRequest("GET", "/admin/digest/preview", nil))
+	if resp.StatusCode != 401 {
+		t.Errorf("GET /admin/digest/preview status = %d, want 401 without a token", resp.StatusCode)
+	}
+}
+
+func TestNew_InvalidDigests(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Config)
+	}{
+		{"unknown schedule", func(cfg *config.Config) { cfg.DigestSchedule = "monthly" }},
+		{"no admins", func(cfg *config.Config) { cfg.AdminEmails = nil }},
+		{"no SMTP server", func(cfg *config.Config) { cfg.SMTPAddr = "" }},
+		{"missing template", func(cfg *config.Config) { cfg.DigestTemplate = filepath.Join(t.TempDir(), "digest.tmpl") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.DigestSchedule = "weekly"
+			cfg.AdminEmails = []string{"admin@example.com"}
+			cfg.SMTPAddr = "localhost:25"
+			cfg.SMTPFrom = "api@example.com"
+			tt.modify(cfg)
+
+			if _, err := New(cfg); err == nil {
+				t.Error("New() should fail")
+			}
+		})
+	}
+}
+
+func TestNew_EnumerationProtection(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.EnumerationProtection = true
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	post := func(path, body string) (int, string) {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(got)
+	}
+
+	// A new and an already registered email get the same answer
+	register := `{"email":"taken@example.com","password":"password123","fullName":"Taken User","phoneNumber":"0812345678","birthday":"1990-01-15"}`
+	status, first := post("/register", register)
+	if status != 202 {
+		t.Errorf("POST /register = %d, want 202", status)
+	}
+	if status, second := post("/register", register); status != 202 || second != first {
+		t.Errorf("POST /register for a taken email = %d %s, want %s", status, second, first)
+	}
+
+	_, known := post("/password/forgot", `{"email":"taken@example.com"}`)
+	if status, unknown := post("/password/forgot", `{"email":"nobody@example.com"}`); status != 202 || unknown != known {
+		t.Errorf("POST /password/forgot for an unknown email = %d %s, want %s", status, unknown, known)
+	}
+}
+
+func TestNew_NameScreening(t *testing.T) {
+	blocklist := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(blocklist, []byte("frak\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := newTestConfig(t)
+	cfg.NameScreening = true
+	cfg.NameBlocklist = blocklist
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	for fullName, want := range map[string]string{"Support Desk": "name_reserved", "Frak Face": "name_profanity", "Jane Doe": ""} {
+		body := `{"email":"` + strings.ToLower(strings.Fields(fullName)[0]) + `@example.com","password":"password123","fullName":"` + fullName + `","phoneNumber":"0812345678","birthday":"1990-01-15"}`
+		req := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var errResp dto.ErrorResponse
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if want == "" && resp.StatusCode != 201 {
+			t.Errorf("POST /register for %q = %d, want 201", fullName, resp.StatusCode)
+		} else if want != "" && (resp.StatusCode != 400 || errResp.Code != want) {
+			t.Errorf("POST /register for %q = %d %q, want 400 %s", fullName, resp.StatusCode, errResp.Code, want)
+		}
+	}
+
+	cfg.NameBlocklist = filepath.Join(t.TempDir(), "missing.txt")
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should fail when NAME_BLOCKLIST cannot be read")
+	}
+}
+
+func TestNew_QRLogin(t *testing.T) {
+	cfg := newTestConfig(t)
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	post := func(path, body, token string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if resp := post("/register", `{"email":"phone@example.com","password":"password123","fullName":"Phone User","phoneNumber":"0812345678","birthday":"1990-01-15"}`, ""); resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %d", resp.StatusCode)
+	}
+	var userID int
+	if err := srv.db.QueryRow(`SELECT id FROM users WHERE email = ?`, "phone@example.com").Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+	phone, _, err := jwt.NewService(cfg.JWTSecret).GenerateToken(userID, "phone@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The desktop starts a login and shows the challenge
+	resp := post("/auth/qr/start", "", "")
+	if resp.StatusCode != 201 {
+		t.Fatalf("POST /auth/qr/start = %d, want 201", resp.StatusCode)
+	}
+	var started dto.QRLoginStartResponse
+	json.NewDecoder(resp.Body).Decode(&started)
+	poll := `{"pollToken":"` + started.PollToken + `"}`
+	challenge := `{"challenge":"` + started.Challenge + `"}`
+
+	var status dto.QRLoginPollResponse
+	json.NewDecoder(post("/auth/qr/status", poll, "").Body).Decode(&status)
+	if status.Status != "pending" || status.Token != "" {
+		t.Errorf("status = %+v, want pending", status)
+	}
+
+	// The phone scans and approves it; approving needs a signed-in user
+	if resp := post("/auth/qr/approve", challenge, ""); resp.StatusCode != 401 {
+		t.Errorf("POST /auth/qr/approve without a token = %d, want 401", resp.StatusCode)
+	}
+	if resp := post("/auth/qr/scan", challenge, phone); resp.StatusCode != 200 {
+		t.Errorf("POST /auth/qr/scan = %d, want 200", resp.StatusCode)
+	}
+	if resp := post("/auth/qr/approve", challenge, phone); resp.StatusCode != 200 {
+		t.Fatalf("POST /auth/qr/approve = %d, want 200", resp.StatusCode)
+	}
+	if resp := post("/auth/qr/deny", challenge, phone); resp.StatusCode != 409 {
+		t.Errorf("POST /auth/qr/deny after approval = %d, want 409", resp.StatusCode)
+	}
+
+	// The desktop gets its token once
+	status = dto.QRLoginPollResponse{}
+	json.NewDecoder(post("/auth/qr/status", poll, "").Body).Decode(&status)
+	if status.Status != "completed" || status.Token == "" || status.User == nil || status.User.ID != userID {
+		t.Fatalf("status = %+v, want completed with a token", status)
+	}
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+status.Token)
+	if resp := testRequest(t, srv.App(), req); resp.StatusCode != 200 {
+		t.Errorf("GET /me with the desktop token = %d; want 200", resp.StatusCode)
+	}
+	status = dto.QRLoginPollResponse{}
+	json.NewDecoder(post("/auth/qr/status", poll, "").Body).Decode(&status)
+	if status.Status != "completed" || status.Token != "" {
+		t.Errorf("status again = %+v, want completed without a token", status)
+	}
+
+	if resp := post("/auth/qr/status", `{"pollToken":"unknown"}`, ""); resp.StatusCode != 404 {
+		t.Errorf("POST /auth/qr/status for an unknown token = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestNew_ShareLinks(t *testing.T) {
+	cfg := newTestConfig(t)
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	do := func(method, path, body, token string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if resp := do("POST", "/register", `{"email":"share@example.com","password":"password123","fullName":"Share User","phoneNumber":"0812345678","birthday":"1990-01-15"}`, ""); resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %d", resp.StatusCode)
+	}
+	var userID int
+	if err := srv.db.QueryRow(`SELECT id FROM users WHERE email = ?`, "share@example.com").Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := jwt.NewService(cfg.JWTSecret).GenerateToken(userID, "share@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp := do("POST", "/me/share-links", `{"fields":["fullName"]}`, ""); resp.StatusCode != 401 {
+		t.Errorf("POST /me/share-links without a token = %d, want 401", resp.StatusCode)
+	}
+	if resp := do("POST", "/me/share-links", `{"fields":["password"]}`, token); resp.StatusCode != 400 {
+		t.Errorf("POST /me/share-links sharing the password = %d, want 400", resp.StatusCode)
+	}
+	resp := do("POST", "/me/share-links", `{"fields":["fullName","birthday"],"expiresIn":3600}`, token)
+	if resp.StatusCode != 201 {
+		t.Fatalf("POST /me/share-links = %d, want 201", resp.StatusCode)
+	}
+	var created dto.CreatedShareLinkResponse
+	json.NewDecoder(resp.Body).Decode(&created)
+	if created.Token == "" || !strings.HasSuffix(created.URL, "/share/"+created.Token) || created.Status != "active" || created.MaxViews != 1 {
+		t.Fatalf("created = %+v", created)
+	}
+
+	// Opened once without signing in, with the shared fields only
+	resp = do("GET", "/share/"+created.Token, "", "")
+	if resp.StatusCode != 200 || resp.Header.Get("Cache-Control") != "no-store" {
+		t.Fatalf("GET /share/{token} = %d, Cache-Control %q", resp.StatusCode, resp.Header.Get("Cache-Control"))
+	}
+	var profile map[string]any
+	json.NewDecoder(resp.Body).Decode(&profile)
+	if len(profile) != 2 || profile["fullName"] != "Share User" || profile["birthday"] != "1990-01-15" {
+		t.Errorf("profile = %v, want the full name and birthday only", profile)
+	}
+	if resp := do("GET", "/share/"+created.Token, "", ""); resp.StatusCode != 410 {
+		t.Errorf("GET /share/{token} again = %d, want 410", resp.StatusCode)
+	}
+	if resp := do("GET", "/share/unknown", "", ""); resp.StatusCode != 404 {
+		t.Errorf("GET /share/unknown = %d, want 404", resp.StatusCode)
+	}
+
+	var accesses dto.ShareLinkAccessesResponse
+	json.NewDecoder(do("GET", "/me/share-links/"+strconv.Itoa(created.ID)+"/accesses", "", token).Body).Decode(&accesses)
+	if len(accesses.Accesses) != 2 || accesses.Accesses[0].Granted || !accesses.Accesses[1].Granted {
+		t.Errorf("accesses = %+v, want a denied and a granted access", accesses)
+	}
+
+	if resp := do("DELETE", "/me/share-links/"+strconv.Itoa(created.ID), "", token); resp.StatusCode != 200 {
+		t.Errorf("DELETE /me/share-links/{id} = %d, want 200", resp.StatusCode)
+	}
+	var links dto.ShareLinksResponse
+	json.NewDecoder(do("GET", "/me/share-links", "", token).Body).Decode(&links)
+	if len(links.Links) != 1 || links.Links[0].Status != "revoked" {
+		t.Errorf("links = %+v, want the revoked link", links)
+	}
+}
+
+func TestNew_Deprecations(t *testing.T) {
+	cfg := newTestConfig(t)
+	since := time.Now().Add(-24 * time.Hour)
+	srv, err := New(cfg,
+		WithRoutes(func(router fiber.Router) {
+			router.Get("/v1/greeting", func(c *fiber.Ctx) error {
+				return c.JSON(fiber.Map{"message": "hello"})
+			})
+			router.Get("/v1/farewell", func(c *fiber.Ctx) error {
+				t.Error("a route past its sunset should not run")
+				return nil
+			})
+		}),
+		WithDeprecations(
+			deprecation.Policy{Method: "GET", Path: "/v1/greeting", Since: since, Sunset: time.Now().Add(24 * time.Hour), Successor: "/"},
+			deprecation.Policy{Method: "GET", Path: "/v1/farewell", Since: since, Sunset: time.Now().Add(-time.Hour)},
+		),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	req := httptest.NewRequest("GET", "/v1/greeting", nil)
+	req.Header.Set("User-Agent", "okhttp/4.12.0")
+	resp, err := srv.App().Test(req, -1)
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("GET /v1/greeting = %v, %v", resp, err)
+	}
+	if resp.Header.Get("Deprecation") != "@"+strconv.FormatInt(since.Unix(), 10) || resp.Header.Get("Sunset") == "" ||
+		resp.Header.Get("Link") != `</>; rel="successor-version"` {
+		t.Errorf("headers = %v", resp.Header)
+	}
+	var body map[string]string
+	json.NewDecoder(resp.Body).Decode(&body)
+	if body["message"] != "hello" || !strings.HasPrefix(body["warning"], "GET /v1/greeting is deprecated; use / instead.") {
+		t.Errorf("body = %v, want the message with a warning", body)
+	}
+
+	req = httptest.NewRequest("GET", "/v1/farewell", nil)
+	req.Header.Set("X-Client-ID", "ios-app")
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 410 {
+		t.Errorf("GET /v1/farewell past its sunset = %v, %v; want 410", resp, err)
+	}
+	if resp := testRequest(t, srv.App(), httptest.NewRequest("GET", "/", nil)); resp.Header.Get("Deprecation") != "" {
+		t.Error("routes that are not deprecated should not be announced")
+	}
+
+	token := adminToken(t, srv)
+	req = httptest.NewRequest("GET", "/admin/deprecations", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = srv.App().Test(req, -1)
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("GET /admin/deprecations = %v, %v", resp, err)
+	}
+	var report dto.DeprecationsResponse
+	json.NewDecoder(resp.Body).Decode(&report)
+	if len(report.Routes) != 2 || report.Routes[0].Requests != 1 || report.Routes[0].Clients[0].Client != "okhttp" ||
+		report.Routes[1].Clients[0].Client != "ios-app" {
+		t.Errorf("report = %+v", report)
+	}
+
+	// Policies for routes that do not exist are rejected
+	_, err = New(newTestConfig(t), WithDeprecations(deprecation.Policy{Method: "GET", Path: "/v1/missing", Since: since}))
+	if err == nil || !strings.Contains(err.Error(), "/v1/missing") {
+		t.Errorf("New() with an unknown deprecated route error = %v", err)
+	}
+}
+
+func TestNew_ClientVersions(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.ClientMinVersions = map[string]string{"iOS": "2.3.0"}
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	check := func(header string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Client-Version", header)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	if resp := check("ios/2.3.1"); resp.StatusCode != 200 {
+		t.Errorf("current app status = %d, want 200", resp.StatusCode)
+	}
+	if resp := check("ios two"); resp.StatusCode != 400 {
+		t.Errorf("invalid header status = %d, want 400", resp.StatusCode)
+	}
+	resp := check("ios/2.2.9")
+	if resp.StatusCode != 426 {
+		t.Fatalf("outdated app status = %d, want 426", resp.StatusCode)
+	}
+	var upgrade dto.UpgradeRequiredResponse
+	json.NewDecoder(resp.Body).Decode(&upgrade)
+	if upgrade.Platform != "ios" || upgrade.CurrentVersion != "2.2.9" || upgrade.MinimumVersion != "2.3.0" {
+		t.Errorf("upgrade response = %+v", upgrade)
+	}
+
+	token := adminToken(t, srv)
+	admin := func(method, path, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		// Admins on outdated apps can still change the minimums
+		req.Header.Set("X-Client-Version", "ios/1.0")
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	resp = admin("PUT", "/admin/client-versions/ios", `{"minVersion":"2.4","updateUrl":"https://apps.apple.com/app/id1"}`)
+	if resp.StatusCode != 200 {
+		t.Fatalf("PUT /admin/client-versions/ios status = %d", resp.StatusCode)
+	}
+	resp = check("ios/2.3.1")
+	json.NewDecoder(resp.Body).Decode(&upgrade)
+	if resp.StatusCode != 426 || upgrade.MinimumVersion != "2.4" || upgrade.UpdateURL != "https://apps.apple.com/app/id1" {
+		t.Errorf("app below the new minimum = %d, %+v", resp.StatusCode, upgrade)
+	}
+	if resp := admin("PUT", "/admin/client-versions/ios", `{"minVersion":"latest"}`); resp.StatusCode != 400 {
+		t.Errorf("invalid minimum status = %d, want 400", resp.StatusCode)
+	}
+
+	resp = admin("GET", "/admin/client-versions", "")
+	var policies dto.ClientVersionPoliciesResponse
+	json.NewDecoder(resp.Body).Decode(&policies)
+	if len(policies.Policies) != 1 || policies.Policies[0].Source != "admin" || policies.Policies[0].UpdatedBy != 1 {
+		t.Errorf("policies = %+v", policies)
+	}
+
+	if resp := admin("DELETE", "/admin/client-versions/ios", ""); resp.StatusCode != 200 {
+		t.Errorf("DELETE status = %d, want 200", resp.StatusCode)
+	}
+	if resp := check("ios/2.3.1"); resp.StatusCode != 200 {
+		t.Errorf("status after reset = %d, want the configured minimum to apply", resp.StatusCode)
+	}
+	if resp := admin("DELETE", "/admin/client-versions/ios", ""); resp.StatusCode != 404 {
+		t.Errorf("DELETE without override status = %d, want 404", resp.StatusCode)
+	}
+
+	// Invalid configured minimums are rejected
+	cfg = newTestConfig(t)
+	cfg.ClientMinVersions = map[string]string{"ios": "latest"}
+	if _, err := New(cfg); err == nil || !strings.Contains(err.Error(), "invalid client version configuration") {
+		t.Errorf("New() with an invalid minimum error = %v", err)
+	}
+}
+
+func TestNew_Audiences(t *testing.T) {
+	dir := t.TempDir()
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	if err := os.WriteFile(filepath.Join(dir, "orders.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := newTestConfig(t)
+	cfg.JWTAudiences = filepath.Join(dir, "audiences.yaml")
+	if err := os.WriteFile(cfg.JWTAudiences, []byte("audiences:\n  - name: orders\n    audience: https://orders.example.com\n    scopes: [orders:read, orders:write]\n    keys: [orders.pem]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(`{"email":"aud@example.com","password":"password123","fullName":"Aud User","phoneNumber":"0812345678","birthday":"1990-01-15"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %v, %v", resp, err)
+	}
+	var userID int
+	if err := srv.db.QueryRow(`SELECT id FROM users WHERE email = ?`, "aud@example.com").Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+	login, _, err := jwt.NewService(cfg.JWTSecret).GenerateToken(userID, "aud@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for body, want := range map[string]int{
+		`{"audience":"orders","scopes":["orders:read"]}`: 200,
+		`{"audience":"orders","scopes":["admin"]}`:       400,
+		`{"audience":"payroll"}`:                         400,
+	} {
+		req := httptest.NewRequest("POST", "/tokens", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+login)
+		resp := testRequest(t, srv.App(), req)
+		if resp.StatusCode != want {
+			t.Fatalf("POST /tokens %s = %d; want %d", body, resp.StatusCode, want)
+		}
+		if want != 200 {
+			continue
+		}
+
+		var issued dto.AudienceTokenResponse
+		json.NewDecoder(resp.Body).Decode(&issued)
+		if issued.Scope != "orders:read" {
+			t.Errorf("scope = %q, want orders:read", issued.Scope)
+		}
+		// The audience token does not work against this API
+		req = httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+issued.Token)
+		if resp := testRequest(t, srv.App(), req); resp.StatusCode != 401 {
+			t.Errorf("GET /me with an audience token = %d, want 401", resp.StatusCode)
+		}
+	}
+
+	resp, err := srv.App().Test(httptest.NewRequest("GET", "/.well-known/audiences/orders/jwks.json", nil), -1)
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("GET jwks = %v, %v", resp, err)
+	}
+	var keys dto.JWKSetResponse
+	json.NewDecoder(resp.Body).Decode(&keys)
+	if len(keys.Keys) != 1 || keys.Keys[0].Alg != "EdDSA" || keys.Keys[0].Kid == "" {
+		t.Errorf("keys = %+v", keys)
+	}
+	if resp := testRequest(t, srv.App(), httptest.NewRequest("GET", "/.well-known/audiences/payroll/jwks.json", nil)); resp.StatusCode != 404 {
+		t.Errorf("GET unknown audience jwks = %d, want 404", resp.StatusCode)
+	}
+
+	cfg.JWTAudiences = filepath.Join(dir, "missing.yaml")
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should fail when JWT_AUDIENCES cannot be read")
+	}
+}
+
+func TestNew_HookScripts(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.HookScripts = map[string]string{"pre-register": filepath.Join(t.TempDir(), "signup.rules")}
+	cfg.HookScriptTimeout = time.Second
+	if err := os.WriteFile(cfg.HookScripts["pre-register"], []byte("# Signups are closed\ndomain == \"example.org\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	for email, want := range map[string]int{"jane@example.org": 403, "jane@example.com": 201} {
+		body := `{"email":"` + email + `","password":"password123","fullName":"Jane Doe","phoneNumber":"0812345678","birthday":"1990-01-15"}`
+		req := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := testRequest(t, srv.App(), req)
+		if resp.StatusCode != want {
+			t.Errorf("POST /register for %s = %d; want %d", email, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestNew_UnknownHookPoint(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.HookWebhooks = map[string]string{"pre-logout": "https://hooks.internal"}
+
+	if _, err := New(cfg); err == nil {
+		t.Error("New() should fail for an unknown hook point")
+	}
+}
+
+func TestServer_RestartBeforeListen(t *testing.T) {
+	srv, err := New(newTestConfig(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	if err := srv.Restart(0); err != ErrNotListening {
+		t.Errorf("Restart() error = %v, want ErrNotListening", err)
+	}
+}
+
+func TestNew_ThaiLocale(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Locale = "th"
+	cfg.BirthdayCalendar = "buddhist"
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	// Validation errors are in Thai and name the JSON fields
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(`{"email":"somchai@example.com","password":"123","fullName":"Somchai Jaidee","phoneNumber":"0812345678","birthday":"2533-01-15"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := srv.App().Test(req, -1)
+	if err != nil || resp.StatusCode != 400 {
+		t.Fatalf("POST /register = %v, %v; want 400", resp, err)
+	}
+	var failed dto.ErrorResponse
+	json.NewDecoder(resp.Body).Decode(&failed)
+	if failed.Message != "password: ต้องมีอย่างน้อย 6 ตัวอักษร" {
+		t.Errorf("message = %q, want the Thai message", failed.Message)
+	}
+
+	// Birthdays are entered in the Buddhist era and returned as ISO dates
+	req = httptest.NewRequest("POST", "/register", strings.NewReader(`{"email":"somchai@example.com","password":"password123","fullName":"Somchai Jaidee","phoneNumber":"0812345678","birthday":"2533-01-15"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = srv.App().Test(req, -1)
+	if err != nil || resp.StatusCode != 201 {
+		t.Fatalf("POST /register = %v, %v", resp, err)
+	}
+	var registered struct {
+		Data dto.UserResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&registered)
+	if registered.Data.Birthday != "1990-01-15" {
+		t.Errorf("birthday = %q, want 1990-01-15", registered.Data.Birthday)
+	}
+
+	token, _, err := jwt.NewService(cfg.JWTSecret).GenerateToken(registered.Data.ID, registered.Data.Email)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	for body, want := range map[string]int{
+		`{"nationalId":"1-1017-00230-70-7"}`: 400,
+		`{"nationalId":"1-1017-00230-70-8"}`: 200,
+	} {
+		req = httptest.NewRequest("PATCH", "/me", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if resp := testRequest(t, srv.App(), req); resp.StatusCode != want {
+			t.Errorf("PATCH /me with %s = %d; want %d", body, resp.StatusCode, want)
+		}
+	}
+	req = httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = srv.App().Test(req, -1)
+	if err != nil {
+		t.Fatalf("GET /me error = %v", err)
+	}
+	var me struct {
+		Data dto.UserResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&me)
+	if me.Data.NationalID != "1101700230708" {
+		t.Errorf("nationalId = %q, want 1101700230708", me.Data.NationalID)
+	}
+}
+
+func TestNew_InvalidLocale(t *testing.T) {
+	for _, configure := range []func(*config.Config){
+		func(cfg *config.Config) { cfg.Locale = "fr" },
+		func(cfg *config.Config) { cfg.BirthdayCalendar = "lunar" },
+	} {
+		cfg := newTestConfig(t)
+		configure(cfg)
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New() should fail for locale %q and birthday calendar %q", cfg.Locale, cfg.BirthdayCalendar)
+		}
+	}
+}
+
+// kycProviderFunc verifies identity documents with a function
+type kycProviderFunc func(submission *KYCSubmission, document []byte) (KYCDecision, error)
+
+func (f kycProviderFunc) Verify(submission *KYCSubmission, document []byte) (KYCDecision, error) {
+	return f(submission, document)
+}
+
+func TestNew_KYC(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.WorkerInterval = 10 * time.Millisecond
+	cfg.KYCStore = "file"
+	cfg.KYCDir = t.TempDir()
+	cfg.KYCEncryptionKey = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+	provider := kycProviderFunc(func(submission *KYCSubmission, document []byte) (KYCDecision, error) {
+		if bytes.Contains(document, []byte("blurry")) {
+			return KYCDecision{Status: entity.KYCReview, Reason: "photo unreadable"}, nil
+		}
+		return KYCDecision{Status: entity.KYCVerified, Reference: "chk_1"}, nil
+	})
+	srv, err := New(cfg, WithKYCProvider(provider))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	adminTok := adminToken(t, srv)
+	_, clearTok := userToken(t, srv, "clear@example.com", "0811111111")
+	_, blurryTok := userToken(t, srv, "blurry@example.com", "0822222222")
+
+	send := func(token, method, path, contentType string, body []byte) (*http.Response, string) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		content, _ := io.ReadAll(resp.Body)
+		return resp, string(content)
+	}
+	submit := func(token, documentType string, document []byte) (*http.Response, string) {
+		t.Helper()
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		form.WriteField("documentType", documentType)
+		part, _ := form.CreateFormFile("document", "scan.png")
+		part.Write(document)
+		form.Close()
+		return send(token, "POST", "/me/kyc", form.FormDataContentType(), body.Bytes())
+	}
+	// waitForStatus polls GET /me until the user's kycStatus is want
+	waitForStatus := func(token, want string) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); ; {
+			_, body := send(token, "GET", "/me", "", nil)
+			if strings.Contains(body, `"kycStatus":"`+want+`"`) {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("GET /me = %s, want kycStatus %s", body, want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	png := func(content string) []byte {
+		return append([]byte("\x89PNG\r\n\x1a\n"), content...)
+	}
+
+	if resp, body := submit(clearTok, "library_card", png("clear")); resp.StatusCode != 400 {
+		t.Errorf("POST /me/kyc with an unknown document type = %d: %s", resp.StatusCode, body)
+	}
+	if resp, body := submit(clearTok, "passport", []byte("not an image")); resp.StatusCode != 400 {
+		t.Errorf("POST /me/kyc with a text file = %d: %s", resp.StatusCode, body)
+	}
+
+	// The provider verifies clear documents
+	if resp, body := submit(clearTok, "passport", png("clear")); resp.StatusCode != 201 || !strings.Contains(body, `"status":"pending"`) {
+		t.Fatalf("POST /me/kyc = %d: %s", resp.StatusCode, body)
+	}
+	waitForStatus(clearTok, entity.KYCVerified)
+	if resp, _ := submit(clearTok, "passport", png("clear")); resp.StatusCode != 409 {
+		t.Errorf("POST /me/kyc when verified = %d, want 409", resp.StatusCode)
+	}
+
+	// and passes the others on to admins
+	resp, body := submit(blurryTok, "national_id", png("blurry"))
+	if resp.StatusCode != 201 {
+		t.Fatalf("POST /me/kyc = %d: %s", resp.StatusCode, body)
+	}
+	var submission dto.KYCSubmissionResponse
+	json.Unmarshal([]byte(body), &submission)
+	waitForStatus(blurryTok, entity.KYCReview)
+
+	if resp, body := send(blurryTok, "GET", "/admin/kyc", "", nil); resp.StatusCode != 403 {
+		t.Errorf("GET /admin/kyc as a user = %d: %s", resp.StatusCode, body)
+	}
+	var review dto.KYCSubmissionListResponse
+	resp, body = send(adminTok, "GET", "/admin/kyc", "", nil)
+	if err := json.Unmarshal([]byte(body), &review); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("GET /admin/kyc = %d: %s", resp.StatusCode, body)
+	}
+	if len(review.Submissions) != 1 || review.Submissions[0].Email != "blurry@example.com" || review.Submissions[0].Reason != "photo unreadable" {
+		t.Fatalf("submissions in review = %+v", review.Submissions)
+	}
+
+	// Documents are encrypted at rest and decrypted for review
+	path := "/admin/kyc/" + strconv.Itoa(submission.ID)
+	resp, body = send(adminTok, "GET", path+"/document", "", nil)
+	if resp.StatusCode != 200 || body != string(png("blurry")) || resp.Header.Get("Content-Type") != "image/png" || resp.Header.Get("Cache-Control") != "no-store" {
+		t.Errorf("GET %s/document = %d %q: %q", path, resp.StatusCode, resp.Header.Get("Content-Type"), body)
+	}
+	stored, _ := filepath.Glob(filepath.Join(cfg.KYCDir, "kyc", "*", "*.png"))
+	if len(stored) != 2 {
+		t.Fatalf("stored documents = %v, want 2", stored)
+	}
+	for _, file := range stored {
+		if data, _ := os.ReadFile(file); bytes.Contains(data, []byte("PNG")) {
+			t.Errorf("%s is not encrypted", file)
+		}
+	}
+
+	if resp, body := send(adminTok, "POST", path+"/reject", "application/json", []byte(`{}`)); resp.StatusCode != 400 {
+		t.Errorf("POST %s/reject without a reason = %d: %s", path, resp.StatusCode, body)
+	}
+	if resp, body := send(adminTok, "POST", path+"/reject", "application/json", []byte(`{"reason":"Card is cut off"}`)); resp.StatusCode != 200 || !strings.Contains(body, `"status":"rejected"`) {
+		t.Fatalf("POST %s/reject = %d: %s", path, resp.StatusCode, body)
+	}
+	if resp, _ := send(adminTok, "POST", path+"/approve", "", nil); resp.StatusCode != 409 {
+		t.Errorf("approving a rejected submission = %d, want 409", resp.StatusCode)
+	}
+	if resp, body := send(blurryTok, "GET", "/me/kyc", "", nil); resp.StatusCode != 200 || !strings.Contains(body, `"reason":"Card is cut off"`) {
+		t.Errorf("GET /me/kyc = %d: %s", resp.StatusCode, body)
+	}
+	if resp, _ := send(adminTok, "POST", "/admin/kyc/999/approve", "", nil); resp.StatusCode != 404 {
+		t.Errorf("approving a missing submission = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestNew_KYCDisabled(t *testing.T) {
+	srv, err := New(newTestConfig(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	_, token := userToken(t, srv, "user@example.com", "0812345678")
+	req := httptest.NewRequest("GET", "/me/kyc", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if resp, err := srv.App().Test(req, -1); err != nil || resp.StatusCode != 404 {
+		t.Errorf("GET /me/kyc without KYC_STORE = %v, %v; want 404", resp, err)
+	}
+
+	cfg := newTestConfig(t)
+	cfg.KYCStore = "ftp"
+	if _, err := New(cfg); err == nil || !strings.Contains(err.Error(), "KYC_STORE") {
+		t.Errorf("New() for an unknown KYC_STORE error = %v", err)
+	}
+}
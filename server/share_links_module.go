@@ -0,0 +1,42 @@
+package server
+
+import (
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// shareLinksModule shares profile fields through links
+type shareLinksModule struct {
+	baseModule
+	shareLinkHandler *handler.ShareLinkHandler
+}
+
+// ShareLinksModule serves /me/share-links, where users create, list and
+// revoke links to some of their profile fields, and /share/:token, where
+// anyone with a link opens it without signing in
+func ShareLinksModule(deps *Deps) (Module, error) {
+	shareLinkUseCase := usecase.NewShareLinkUseCase(database.NewSQLiteShareLinkRepository(deps.DB), deps.Users)
+	return &shareLinksModule{
+		baseModule:       baseModule{"share-links"},
+		shareLinkHandler: handler.NewShareLinkHandler(shareLinkUseCase, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *shareLinksModule) Migrations() []Migration {
+	return database.ShareLinkMigrations
+}
+
+func (m *shareLinksModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		router.Get("/share/:token", m.shareLinkHandler.OpenShareLink)
+	})
+	routes.Protected(func(router fiber.Router) {
+		router.Post("/me/share-links", m.shareLinkHandler.CreateShareLink)
+		router.Get("/me/share-links", m.shareLinkHandler.ListShareLinks)
+		router.Delete("/me/share-links/:id", m.shareLinkHandler.RevokeShareLink)
+		router.Get("/me/share-links/:id/accesses", m.shareLinkHandler.ListAccesses)
+	})
+}
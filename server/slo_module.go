@@ -0,0 +1,70 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/slo"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sloModule tracks routes against their service level objectives
+type sloModule struct {
+	baseModule
+	tracker    *slo.Tracker
+	sloHandler *handler.SLOHandler
+	interval   time.Duration
+}
+
+// SLOModule tracks the routes in SLO_OBJECTIVES over SLO_WINDOW, reports
+// their error budgets and burn rates at /admin/slo and, while load shedding
+// is enabled, sheds requests for other routes when a budget is exhausted
+func SLOModule(deps *Deps) (Module, error) {
+	if !deps.Config.SLOEnabled() {
+		return nil, nil
+	}
+
+	tracker, err := container.Get[*slo.Tracker](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sloModule{
+		baseModule: baseModule{"slo"},
+		tracker:    tracker,
+		sloHandler: handler.NewSLOHandler(tracker, deps.Validator, deps.Decoder),
+		interval:   deps.Config.WorkerInterval,
+	}, nil
+}
+
+func (m *sloModule) Routes(routes *Routes) {
+	routes.Use(middleware.SLOMiddleware(m.tracker, "/admin"))
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/slo", m.sloHandler.GetReport)
+		admin.Put("/slo/load-shedding", m.sloHandler.SetLoadShedding)
+	})
+}
+
+func (m *sloModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("slo", m.interval, m.evaluate),
+	}
+}
+
+// evaluate starts or stops load shedding as the error budgets change
+func (m *sloModule) evaluate() error {
+	shedding := m.tracker.Shedding()
+	if m.tracker.Evaluate() != shedding {
+		if shedding {
+			log.Println("Load shedding stopped: no error budget is exhausted and burning")
+		} else {
+			log.Println("Load shedding started: an error budget is exhausted, see /admin/slo")
+		}
+	}
+	return nil
+}
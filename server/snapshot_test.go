@@ -0,0 +1,402 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/dto"
+)
+
+var updateSnapshots = flag.Bool("update", false, "rewrite the golden files in testdata/snapshots")
+
+// snapshotStep is a request whose response is compared with
+// testdata/snapshots/<name>.golden. {var} in the path, body and token is
+// replaced by a value captured from an earlier response.
+type snapshotStep struct {
+	name    string
+	method  string
+	path    string
+	body    string
+	token   string
+	headers map[string]string
+	// capture maps variable names to dotted paths in the response body
+	capture map[string]string
+	// before runs first, e.g. to wait for a background worker
+	before func(t *testing.T)
+}
+
+// volatileKeys hold values that differ between runs; snapshots keep the key
+// but not the value
+var volatileKeys = map[string]bool{
+	"token": true, "url": true, "undoUrl": true, "challenge": true, "pollToken": true,
+	"sha256": true, "size": true, "durationMs": true, "kid": true, "x": true, "Date": true,
+	// claims cache counters depend on how often the read model poll hit it
+	"hitRate": true, "localHits": true, "sharedHits": true, "misses": true, "invalidations": true,
+}
+
+var (
+	jwtPattern  = regexp.MustCompile(`^eyJ[\w-]*\.[\w-]*\.[\w-]*$`)
+	timePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}([T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:\d{2})?)?|\d{8}T\d{6}\.\d{3}Z`)
+	hexPattern  = regexp.MustCompile(`\b[0-9a-f]{32}\b`)
+)
+
+// normalizeSnapshot replaces times, tokens and volatile values so that
+// snapshots only change with the response shape
+func normalizeSnapshot(key string, v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalizeSnapshot(k, item)
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeSnapshot(key, item)
+		}
+		return value
+	case string:
+		switch {
+		case volatileKeys[key] && value != "":
+			return "<" + key + ">"
+		case jwtPattern.MatchString(value):
+			return "<jwt>"
+		}
+		return hexPattern.ReplaceAllString(timePattern.ReplaceAllString(value, "<time>"), "<token>")
+	case float64:
+		if volatileKeys[key] {
+			return "<" + key + ">"
+		}
+	}
+	return v
+}
+
+// lookup follows a dotted path such as data.id through a decoded body
+func lookup(v interface{}, path string) (string, bool) {
+	for _, part := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			v = node[part]
+		case []interface{}:
+			var i int
+			if _, err := fmt.Sscan(part, &i); err != nil || i >= len(node) {
+				return "", false
+			}
+			v = node[i]
+		default:
+			return "", false
+		}
+	}
+	switch value := v.(type) {
+	case string:
+		return value, true
+	case float64:
+		return fmt.Sprint(int64(value)), true
+	}
+	return "", false
+}
+
+// snapshotServer starts a server with every module that serves JSON enabled
+func snapshotServer(t *testing.T) *Server {
+	fga := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/check") {
+			io.WriteString(w, `{"allowed":true}`)
+			return
+		}
+		io.WriteString(w, "{}")
+	}))
+	t.Cleanup(fga.Close)
+
+	dir := t.TempDir()
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	if err := os.WriteFile(filepath.Join(dir, "orders.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestConfig(t)
+	cfg.AdminEmails = []string{"admin@example.com"}
+	cfg.WorkerInterval = 10 * time.Millisecond
+	cfg.ScimToken = "scim-secret"
+	cfg.BackupDir = t.TempDir()
+	cfg.DigestSchedule = "daily"
+	cfg.RecordingEnabled = true
+	cfg.ChaosEnabled = true
+	cfg.SLOObjectives = map[string]string{"POST /login": "99.9%"}
+	cfg.OpenFGAAPIURL, cfg.OpenFGAStoreID = fga.URL, "store"
+	cfg.JWTAudiences = filepath.Join(dir, "audiences.yaml")
+	if err := os.WriteFile(cfg.JWTAudiences, []byte("audiences:\n  - name: orders\n    audience: https://orders.example.com\n    scopes: [orders:read]\n    keys: [orders.pem]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := New(cfg, Override[repository.Mailer](&recordingMailer{}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	select {
+	case <-srv.Ready():
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not warm up")
+	}
+	return srv
+}
+
+// TestSnapshots compares the JSON responses of the endpoints, in success and
+// error cases, with golden files so that renamed fields and changed envelopes
+// show up in review. Run go test ./server -run TestSnapshots -update to
+// accept intended changes.
+func TestSnapshots(t *testing.T) {
+	srv := snapshotServer(t)
+	vars := map[string]string{"adminToken": adminToken(t, srv)}
+
+	// Read models trail the event log; search results depend on them
+	readModelsCaughtUp := func(t *testing.T) {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			req := httptest.NewRequest("GET", "/admin/read-models", nil)
+			req.Header.Set("Authorization", "Bearer "+vars["adminToken"])
+			resp, err := srv.App().Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var projections dto.ProjectionsResponse
+			json.NewDecoder(resp.Body).Decode(&projections)
+			caughtUp := len(projections.Projections) == 2
+			for _, projection := range projections.Projections {
+				caughtUp = caughtUp && projection.Behind == 0 && projection.UpdatedAt != nil
+			}
+			if caughtUp {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("read models did not catch up with the event log")
+	}
+
+	const (
+		register = `{"email":"ann@example.com","password":"password123","fullName":"Ann Lee","phoneNumber":"0812345678","birthday":"1990-01-15"}`
+		user     = "{userToken}"
+		admin    = "{adminToken}"
+	)
+	steps := []snapshotStep{
+		{name: "root", method: "GET", path: "/"},
+		{name: "livez", method: "GET", path: "/livez"},
+		{name: "readyz", method: "GET", path: "/readyz"},
+
+		{name: "register", method: "POST", path: "/register", body: register},
+		{name: "register_duplicate", method: "POST", path: "/register", body: register},
+		{name: "register_invalid", method: "POST", path: "/register", body: `{"email":"not-an-email","password":"x","fullName":"A","phoneNumber":"1","birthday":"1990-01-15"}`},
+		{name: "register_malformed", method: "POST", path: "/register", body: `{"email":`},
+		{name: "register_unsupported_media", method: "POST", path: "/register", body: "email=a", headers: map[string]string{"Content-Type": "text/plain"}},
+		{name: "login", method: "POST", path: "/login", body: `{"email":"ann@example.com","password":"password123"}`, capture: map[string]string{"userToken": "token"}},
+		{name: "login_wrong_password", method: "POST", path: "/login", body: `{"email":"ann@example.com","password":"wrong-password"}`},
+		{name: "login_invalid", method: "POST", path: "/login", body: `{"email":"ann"}`},
+		{name: "password_forgot", method: "POST", path: "/password/forgot", body: `{"email":"ann@example.com"}`},
+		{name: "password_reset_invalid_token", method: "POST", path: "/password/reset", body: `{"token":"nope","newPassword":"password456"}`},
+
+		{name: "me", method: "GET", path: "/me", token: user},
+		{name: "me_unauthorized", method: "GET", path: "/me"},
+		{name: "me_invalid_token", method: "GET", path: "/me", token: "not-a-token"},
+		{name: "me_patch", method: "PATCH", path: "/me", token: user, body: `{"fullName":"Ann Lee-Smith"}`, headers: map[string]string{"Content-Type": "application/merge-patch+json"}},
+		{name: "me_patch_invalid", method: "PATCH", path: "/me", token: user, body: `{"fullName":"A"}`, headers: map[string]string{"Content-Type": "application/merge-patch+json"}},
+		{name: "me_password", method: "PUT", path: "/me/password", token: user, body: `{"currentPassword":"password123","newPassword":"password123"}`},
+		{name: "me_password_wrong_current", method: "PUT", path: "/me/password", token: user, body: `{"currentPassword":"wrong-password","newPassword":"password456"}`},
+		{name: "me_security_report", method: "GET", path: "/me/security/report", token: user},
+
+		{name: "share_link_create", method: "POST", path: "/me/share-links", token: user, body: `{"fields":["fullName"],"maxViews":2}`, capture: map[string]string{"shareToken": "token", "shareID": "id"}},
+		{name: "share_link_create_invalid", method: "POST", path: "/me/share-links", token: user, body: `{"fields":[]}`},
+		{name: "share_links", method: "GET", path: "/me/share-links", token: user},
+		{name: "share_link_open", method: "GET", path: "/share/{shareToken}"},
+		{name: "share_link_open_unknown", method: "GET", path: "/share/unknown"},
+		{name: "share_link_accesses", method: "GET", path: "/me/share-links/{shareID}/accesses", token: user},
+		{name: "share_link_revoke", method: "DELETE", path: "/me/share-links/{shareID}", token: user},
+		{name: "share_link_revoke_unknown", method: "DELETE", path: "/me/share-links/999", token: user},
+
+		{name: "qr_start", method: "POST", path: "/auth/qr/start", capture: map[string]string{"challenge": "challenge", "pollToken": "pollToken"}},
+		{name: "qr_status_pending", method: "POST", path: "/auth/qr/status", body: `{"pollToken":"{pollToken}"}`},
+		{name: "qr_status_unknown", method: "POST", path: "/auth/qr/status", body: `{"pollToken":"unknown"}`},
+		{name: "qr_scan", method: "POST", path: "/auth/qr/scan", token: user, body: `{"challenge":"{challenge}"}`},
+		{name: "qr_approve", method: "POST", path: "/auth/qr/approve", token: user, body: `{"challenge":"{challenge}"}`},
+		{name: "qr_status_approved", method: "POST", path: "/auth/qr/status", body: `{"pollToken":"{pollToken}"}`},
+		{name: "qr_deny_unknown", method: "POST", path: "/auth/qr/deny", token: user, body: `{"challenge":"unknown"}`},
+
+		{name: "tokens", method: "POST", path: "/tokens", token: user, body: `{"audience":"orders","scopes":["orders:read"]}`},
+		{name: "tokens_unknown_audience", method: "POST", path: "/tokens", token: user, body: `{"audience":"payroll"}`},
+		{name: "audience_jwks", method: "GET", path: "/.well-known/audiences/orders/jwks.json"},
+		{name: "audience_jwks_unknown", method: "GET", path: "/.well-known/audiences/payroll/jwks.json"},
+
+		{name: "scim_users", method: "GET", path: "/scim/v2/Users", token: "scim-secret"},
+		{name: "scim_unauthorized", method: "GET", path: "/scim/v2/Users"},
+		{name: "scim_create", method: "POST", path: "/scim/v2/Users", token: "scim-secret", headers: map[string]string{"Content-Type": "application/scim+json"},
+			body:    `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"bob@example.com","name":{"givenName":"Bob","familyName":"Stone"},"phoneNumbers":[{"value":"0812345678","primary":true}]}`,
+			capture: map[string]string{"scimID": "id"}},
+		{name: "scim_get", method: "GET", path: "/scim/v2/Users/{scimID}", token: "scim-secret"},
+		{name: "scim_get_unknown", method: "GET", path: "/scim/v2/Users/999", token: "scim-secret"},
+		{name: "scim_patch", method: "PATCH", path: "/scim/v2/Users/{scimID}", token: "scim-secret", headers: map[string]string{"Content-Type": "application/scim+json"},
+			body: `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","path":"active","value":false}]}`},
+
+		{name: "admin_forbidden", method: "GET", path: "/admin/funnel", token: user},
+		{name: "admin_funnel", method: "GET", path: "/admin/funnel", token: admin},
+		{name: "admin_user_history", method: "GET", path: "/admin/users/2/history", token: admin},
+		{name: "admin_events", method: "GET", path: "/admin/events?type=user.registered", token: admin},
+		{name: "admin_events_invalid", method: "GET", path: "/admin/events?since=yesterday", token: admin},
+		{name: "admin_events_export", method: "GET", path: "/admin/events/export?type=user.registered", token: admin},
+		{name: "admin_users_search", method: "GET", path: "/admin/users/search?q=lee", token: admin, before: readModelsCaughtUp},
+		{name: "admin_users_search_invalid", method: "GET", path: "/admin/users/search?createdFrom=yesterday", token: admin},
+		{name: "admin_users_export", method: "GET", path: "/admin/users/export?q=lee", token: admin},
+		{name: "admin_read_models", method: "GET", path: "/admin/read-models", token: admin},
+		{name: "admin_read_model_rebuild", method: "POST", path: "/admin/read-models/login_stats/rebuild", token: admin},
+		{name: "admin_read_model_rebuild_unknown", method: "POST", path: "/admin/read-models/unknown/rebuild", token: admin},
+		{name: "admin_claims_cache", method: "GET", path: "/admin/claims-cache", token: admin},
+		{name: "admin_authorization_sync", method: "POST", path: "/admin/authorization/sync", token: admin},
+		{name: "admin_user_status", method: "PUT", path: "/admin/users/3/status", token: admin, body: `{"status":"active"}`},
+		{name: "admin_user_status_invalid", method: "PUT", path: "/admin/users/3/status", token: admin, body: `{"status":"gone"}`},
+		{name: "admin_bulk_role", method: "POST", path: "/admin/users/bulk/role", token: admin, body: `{"userIds":[3],"role":"user"}`, capture: map[string]string{"actionToken": "token"}},
+		{name: "admin_action", method: "GET", path: "/admin/actions/{actionToken}", token: admin},
+		{name: "admin_action_undo", method: "POST", path: "/admin/actions/{actionToken}/undo", token: admin},
+		{name: "admin_action_unknown", method: "GET", path: "/admin/actions/unknown", token: admin},
+		{name: "admin_queues", method: "GET", path: "/admin/queues", token: admin},
+		{name: "admin_queues_invalid_window", method: "GET", path: "/admin/queues?window=soon", token: admin},
+		{name: "admin_queue_jobs", method: "GET", path: "/admin/queues/admin_actions/jobs?status=cancelled", token: admin},
+		{name: "admin_queue_jobs_invalid", method: "GET", path: "/admin/queues/admin_actions/jobs?status=done", token: admin},
+		{name: "admin_queue_retry_not_failed", method: "POST", path: "/admin/queues/admin_actions/jobs/{actionToken}/retry", token: admin},
+		{name: "admin_queue_pause", method: "PUT", path: "/admin/queues/admin_actions/paused", token: admin, body: `{"paused":true}`},
+		{name: "admin_queue_pause_invalid", method: "PUT", path: "/admin/queues/admin_actions/paused", token: admin, body: `{}`},
+		{name: "admin_queue_resume", method: "PUT", path: "/admin/queues/admin_actions/paused", token: admin, body: `{"paused":false}`},
+		{name: "admin_bulk_suspend_invalid", method: "POST", path: "/admin/users/bulk/suspend", token: admin, body: `{"userIds":[]}`},
+		{name: "admin_incident_reset_invalid", method: "POST", path: "/admin/users/bulk/incident-reset", token: admin, body: `{"subject":"Reset"}`},
+		{name: "admin_delete_user", method: "DELETE", path: "/admin/users/3", token: admin},
+
+		{name: "admin_backup_create", method: "POST", path: "/admin/backups", token: admin},
+		{name: "admin_backups", method: "GET", path: "/admin/backups", token: admin},
+		{name: "admin_digest_preview", method: "GET", path: "/admin/digest/preview", token: admin},
+		{name: "admin_dead_letters", method: "GET", path: "/admin/dead-letters", token: admin},
+		{name: "admin_dead_letters_invalid", method: "GET", path: "/admin/dead-letters?status=lost", token: admin},
+		{name: "admin_dead_letter_unknown", method: "GET", path: "/admin/dead-letters/999", token: admin},
+		{name: "admin_dead_letters_replay", method: "POST", path: "/admin/dead-letters/replay?point=password-reset", token: admin},
+		{name: "admin_deprecations", method: "GET", path: "/admin/deprecations", token: admin},
+		{name: "autoscaling", method: "GET", path: "/autoscaling"},
+		{name: "admin_slo", method: "GET", path: "/admin/slo", token: admin},
+		{name: "admin_slo_invalid_format", method: "GET", path: "/admin/slo?format=xml", token: admin},
+		{name: "admin_slo_load_shedding", method: "PUT", path: "/admin/slo/load-shedding", token: admin, body: `{"enabled":true}`},
+		{name: "admin_slo_load_shedding_invalid", method: "PUT", path: "/admin/slo/load-shedding", token: admin, body: `{}`},
+
+		{name: "admin_client_version_set", method: "PUT", path: "/admin/client-versions/ios", token: admin, body: `{"minVersion":"2.4.0","updateUrl":"https://apps.example.com/ios"}`},
+		{name: "admin_client_version_set_invalid", method: "PUT", path: "/admin/client-versions/ios", token: admin, body: `{"minVersion":"latest"}`},
+		{name: "admin_client_versions", method: "GET", path: "/admin/client-versions", token: admin},
+		{name: "client_version_too_old", method: "GET", path: "/", headers: map[string]string{"X-Client-Version": "ios/1.0"}},
+		{name: "admin_client_version_reset", method: "DELETE", path: "/admin/client-versions/ios", token: admin},
+
+		{name: "admin_chaos_set", method: "PUT", path: "/admin/chaos", token: admin, body: `{"rules":[{"path":"/nowhere","percent":50,"status":503}]}`},
+		{name: "admin_chaos_set_invalid", method: "PUT", path: "/admin/chaos", token: admin, body: `{"rules":[{"path":"/nowhere","percent":500}]}`},
+		{name: "admin_chaos", method: "GET", path: "/admin/chaos", token: admin},
+		{name: "admin_chaos_clear", method: "DELETE", path: "/admin/chaos", token: admin},
+
+		{name: "admin_recordings", method: "GET", path: "/admin/recordings?path=/me&method=GET", token: admin, capture: map[string]string{"recordingID": "recordings.0.id"}},
+		{name: "admin_recording", method: "GET", path: "/admin/recordings/{recordingID}", token: admin},
+		{name: "admin_recording_unknown", method: "GET", path: "/admin/recordings/999999", token: admin},
+		{name: "admin_recording_replay", method: "POST", path: "/admin/recordings/{recordingID}/replay", token: admin, body: `{}`},
+		{name: "admin_recordings_clear", method: "DELETE", path: "/admin/recordings", token: admin},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if step.before != nil {
+				step.before(t)
+			}
+			expand := func(s string) string {
+				for name, value := range vars {
+					s = strings.ReplaceAll(s, "{"+name+"}", value)
+				}
+				return s
+			}
+
+			var body io.Reader
+			if step.body != "" {
+				body = strings.NewReader(expand(step.body))
+			}
+			req := httptest.NewRequest(step.method, expand(step.path), body)
+			if step.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if step.token != "" {
+				req.Header.Set("Authorization", "Bearer "+expand(step.token))
+			}
+			for name, value := range step.headers {
+				req.Header.Set(name, value)
+			}
+			resp, err := srv.App().Test(req, -1)
+			if err != nil {
+				t.Fatalf("%s %s error = %v", step.method, step.path, err)
+			}
+			raw, _ := io.ReadAll(resp.Body)
+
+			// NDJSON responses are snapshotted as an array of their lines
+			var decoded interface{}
+			if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/x-ndjson") {
+				lines := []interface{}{}
+				scanner := bufio.NewScanner(bytes.NewReader(raw))
+				for scanner.Scan() {
+					var line interface{}
+					if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+						t.Fatalf("NDJSON line %q: %v", scanner.Text(), err)
+					}
+					lines = append(lines, line)
+				}
+				decoded = lines
+			} else if err := json.Unmarshal(raw, &decoded); err != nil {
+				t.Fatalf("%s %s = %d %q, not JSON", step.method, step.path, resp.StatusCode, raw)
+			}
+			for name, path := range step.capture {
+				value, ok := lookup(decoded, path)
+				if !ok {
+					t.Fatalf("%s not found in %s", path, raw)
+				}
+				vars[name] = value
+			}
+
+			var normalized bytes.Buffer
+			encoder := json.NewEncoder(&normalized)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(normalizeSnapshot("", decoded)); err != nil {
+				t.Fatal(err)
+			}
+			got := fmt.Sprintf("%d %s\n%s", resp.StatusCode, resp.Header.Get("Content-Type"), normalized.Bytes())
+			golden := filepath.Join("testdata", "snapshots", step.name+".golden")
+			if *updateSnapshots {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("missing snapshot %s; run go test ./server -run TestSnapshots -update", golden)
+			}
+			if got != string(want) {
+				t.Errorf("%s %s changed shape; if intended, run go test ./server -run TestSnapshots -update\n--- want\n%s--- got\n%s", step.method, step.path, want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"crypto/tls"
+	"log"
+	"net/http"
+
+	"fiber-hello-world/config"
+	"fiber-hello-world/pkg/mtls"
+	"fiber-hello-world/pkg/tlsserver"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// serverTLSConfig builds the TLS configuration from the certificate files or,
+// when ACME domains are configured, from certificates obtained via ACME. The
+// manager is nil when ACME is not used.
+func serverTLSConfig(cfg *config.Config) (*tls.Config, *autocert.Manager, error) {
+	if !cfg.ACMEEnabled() {
+		tlsConfig, err := mtls.ServerConfig(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile)
+		return tlsConfig, nil, err
+	}
+
+	manager := tlsserver.NewACMEManager(cfg.ACMEDomains, cfg.ACMEEmail, cfg.ACMECacheDir)
+	tlsConfig := tlsserver.ACMEConfig(manager)
+	if err := mtls.AddClientCA(tlsConfig, cfg.TLSClientCAFile); err != nil {
+		return nil, nil, err
+	}
+	return tlsConfig, manager, nil
+}
+
+// serveHTTPRedirect listens for plain HTTP and redirects to HTTPS, answering
+// ACME HTTP-01 challenges first when a manager is given
+func serveHTTPRedirect(cfg *config.Config, acmeManager *autocert.Manager) {
+	handler := tlsserver.RedirectHandler(cfg.Port)
+	if acmeManager != nil {
+		handler = acmeManager.HTTPHandler(handler)
+	}
+
+	server := &http.Server{
+		Addr:              ":" + cfg.HTTPRedirectPort,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+	log.Printf("Redirecting HTTP on port %s to HTTPS", cfg.HTTPRedirectPort)
+	if err := server.ListenAndServe(); err != nil {
+		log.Printf("HTTP redirect server stopped: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/presentation/middleware"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// usersModule serves registration, login and the caller's profile
+type usersModule struct {
+	baseModule
+	deps                 *Deps
+	userHandler          *handler.UserHandler
+	passwordResetHandler *handler.PasswordResetHandler
+}
+
+// UsersModule serves registration, login, password resets, the caller's
+// profile and security report, and avatar files
+func UsersModule(deps *Deps) (Module, error) {
+	avatarStorage, err := container.Get[repository.AvatarStorage](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	avatarUseCase := usecase.NewAvatarUseCase(deps.UserRepo, avatarStorage, deps.RevisionRepo)
+
+	loginEventRepo, err := container.Get[repository.LoginEventRepository](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	securityUseCase := usecase.NewSecurityUseCase(loginEventRepo, deps.UserRepo)
+
+	tokenUseCase, err := container.Get[*usecase.TokenUseCase](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	passwordResetUseCase := usecase.NewPasswordResetUseCase(deps.Users, tokenUseCase, deps.Config.PasswordResetTTL)
+
+	return &usersModule{
+		baseModule:           baseModule{"users"},
+		deps:                 deps,
+		userHandler:          handler.NewUserHandler(deps.Users, avatarUseCase, deps.Funnel, securityUseCase, deps.JWT, deps.Validator, deps.Decoder),
+		passwordResetHandler: handler.NewPasswordResetHandler(passwordResetUseCase, deps.Validator, deps.Decoder),
+	}, nil
+}
+
+func (m *usersModule) Routes(routes *Routes) {
+	routes.Public(func(router fiber.Router) {
+		// Uploaded files (avatars)
+		router.Static("/uploads", m.deps.Config.UploadDir)
+
+		router.Post("/register", middleware.SchemaMiddleware(m.deps.Schemas, "register"), m.userHandler.Register)
+		router.Post("/login", middleware.SchemaMiddleware(m.deps.Schemas, "login"), m.userHandler.Login)
+		router.Post("/password/forgot", m.passwordResetHandler.ForgotPassword)
+		router.Post("/password/reset", m.passwordResetHandler.ResetPassword)
+	})
+
+	routes.Protected(func(router fiber.Router) {
+		router.Get("/me", m.userHandler.GetMe)
+		router.Patch("/me", m.userHandler.PatchMe)
+		router.Put("/me/password", m.deps.RequireSignature, m.userHandler.ChangePassword)
+		router.Get("/me/security/report", m.userHandler.GetSecurityReport)
+	})
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/pkg/listener"
+)
+
+// warmUpRetry is how long the warm-up waits before retrying failed steps
+const warmUpRetry = time.Second
+
+// errShuttingDown is reported by /readyz once Shutdown has started
+var errShuttingDown = errors.New("shutting down")
+
+// errWarmingUp is reported by /readyz until the warm-up has finished
+var errWarmingUp = errors.New("warming up")
+
+// Warmer is implemented by modules that prepare for traffic before the
+// server reports ready, e.g. by loading caches. WarmUp is retried until it
+// succeeds.
+type Warmer interface {
+	WarmUp() error
+}
+
+// readiness tracks the warm-up and shutdown for /readyz
+type readiness struct {
+	mu  sync.Mutex
+	err error
+	// warmed is closed once the warm-up has finished
+	warmed chan struct{}
+}
+
+func newReadiness() *readiness {
+	return &readiness{err: errWarmingUp, warmed: make(chan struct{})}
+}
+
+// Ready returns nil when the server is ready for traffic, or why it is not
+func (r *readiness) Ready() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *readiness) set(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != errShuttingDown {
+		r.err = err
+	}
+}
+
+// warmUpStep is one part of the warm-up
+type warmUpStep struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// warmUpSteps primes the database connections and checks the optional
+// dependencies, then warms up the modules that implement Warmer
+func (s *Server) warmUpSteps(modules []Module) []warmUpStep {
+	var steps []warmUpStep
+	if s.cfg.WarmUpDBConns > 0 {
+		steps = append(steps, warmUpStep{"database", func(ctx context.Context) error {
+			return database.WarmUp(ctx, s.db, s.cfg.WarmUpDBConns)
+		}})
+	}
+	// Dependencies that are down disable features rather than hold up
+	// readiness, so the step never fails
+	if s.dependencies.Len() > 0 {
+		steps = append(steps, warmUpStep{"dependencies", func(ctx context.Context) error {
+			s.dependencies.CheckAll(ctx)
+			return nil
+		}})
+	}
+	for _, m := range modules {
+		if warmer, ok := m.(Warmer); ok {
+			steps = append(steps, warmUpStep{m.Name(), func(context.Context) error {
+				return warmer.WarmUp()
+			}})
+		}
+	}
+	return steps
+}
+
+// warmUp runs the steps, retrying those that fail every warmUpRetry, and
+// marks the server ready once all have succeeded. It gives up when ctx is
+// done.
+func (s *Server) warmUp(ctx context.Context, steps []warmUpStep) {
+	started := time.Now()
+	for {
+		var failed []warmUpStep
+		for _, step := range steps {
+			if err := step.run(ctx); err != nil {
+				log.Printf("Warm-up of %s failed: %v", step.name, err)
+				s.readiness.set(fmt.Errorf("%w: %s: %v", errWarmingUp, step.name, err))
+				failed = append(failed, step)
+			}
+		}
+		if len(failed) == 0 {
+			break
+		}
+		steps = failed
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(warmUpRetry):
+		}
+	}
+
+	s.readiness.set(nil)
+	close(s.readiness.warmed)
+	log.Printf("Warm-up finished in %v", time.Since(started).Round(time.Millisecond))
+}
+
+// Ready returns a channel closed once the server has warmed up and reports
+// ready on /readyz
+func (s *Server) Ready() <-chan struct{} {
+	return s.readiness.warmed
+}
+
+// signalHandoffReady lets the process that handed its socket to this one
+// stop, once this one has warmed up, so traffic is not moved to a cold
+// process
+func (s *Server) signalHandoffReady(ctx context.Context) {
+	select {
+	case <-s.readiness.warmed:
+	case <-ctx.Done():
+		return
+	}
+	if err := listener.Ready(); err != nil {
+		log.Printf("Failed to signal readiness to the previous process: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net/url"
+	"slices"
+	"time"
+
+	"fiber-hello-world/internal/domain/repository"
+	"fiber-hello-world/internal/infrastructure/database"
+	"fiber-hello-world/internal/presentation/handler"
+	"fiber-hello-world/internal/usecase"
+	"fiber-hello-world/pkg/container"
+	"fiber-hello-world/pkg/depcheck"
+	"fiber-hello-world/pkg/hooks"
+	"fiber-hello-world/pkg/worker"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// webhookAttemptCleanupInterval is how often expired webhook attempts are deleted
+const webhookAttemptCleanupInterval = time.Hour
+
+// webhooksModule keeps the delivery log of the registered webhooks
+type webhooksModule struct {
+	baseModule
+	locker         repository.Locker
+	webhookUseCase *usecase.WebhookUseCase
+	webhookHandler *handler.WebhookHandler
+}
+
+// WebhooksModule logs every request the webhooks in HOOK_WEBHOOKS, or added
+// with WithHook, send and serves each webhook's deliveries, with response
+// codes and retry timelines, at /admin/webhooks/:id/deliveries. It is
+// disabled when no webhook is registered.
+func WebhooksModule(deps *Deps) (Module, error) {
+	hookRegistry, err := container.Get[*hooks.Registry](deps.Container)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := hookRegistry.Webhooks()
+	if len(webhooks) == 0 {
+		return nil, nil
+	}
+
+	var points []hooks.Point
+	for _, point := range hooks.Points {
+		if len(webhooks[point]) > 0 {
+			points = append(points, point)
+		}
+	}
+	webhookUseCase := usecase.NewWebhookUseCase(database.NewSQLiteWebhookAttemptRepository(deps.DB), points)
+	for _, point := range points {
+		for _, webhook := range webhooks[point] {
+			webhook.OnAttempt(webhookUseCase.RecordAttempt)
+		}
+	}
+	if err := registerWebhookTargets(deps, webhooks, points); err != nil {
+		return nil, err
+	}
+
+	return &webhooksModule{
+		baseModule:     baseModule{"webhooks"},
+		locker:         deps.Locker,
+		webhookUseCase: webhookUseCase,
+		webhookHandler: handler.NewWebhookHandler(webhookUseCase),
+	}, nil
+}
+
+// registerWebhookTargets checks each host webhooks send to. Nothing more is
+// disabled while one is down: hooks that can veto an operation keep failing
+// closed, and failed deliveries of the others are kept for redelivery.
+func registerWebhookTargets(deps *Deps, webhooks map[hooks.Point][]*hooks.Webhook, points []hooks.Point) error {
+	dependencies, err := container.Get[*depcheck.Checker](deps.Container)
+	if err != nil {
+		return err
+	}
+	targets := make(map[string]*hooks.Webhook)
+	features := make(map[string][]string)
+	for _, point := range points {
+		for _, webhook := range webhooks[point] {
+			u, err := url.Parse(webhook.URL())
+			if err != nil {
+				return err
+			}
+			name := "webhook:" + u.Host
+			targets[name] = webhook
+			if feature := string(point) + " webhooks"; !slices.Contains(features[name], feature) {
+				features[name] = append(features[name], feature)
+			}
+		}
+	}
+	for name, webhook := range targets {
+		dependencies.RegisterPinger(name, webhook, features[name]...)
+	}
+	return nil
+}
+
+func (m *webhooksModule) Migrations() []Migration {
+	return database.WebhookAttemptMigrations
+}
+
+func (m *webhooksModule) Routes(routes *Routes) {
+	routes.Admin(func(admin fiber.Router) {
+		admin.Get("/webhooks/:id/deliveries", m.webhookHandler.ListDeliveries)
+	})
+}
+
+func (m *webhooksModule) Workers() []*Worker {
+	return []*Worker{
+		worker.New("webhook-attempts", webhookAttemptCleanupInterval, func() error {
+			_, err := m.webhookUseCase.DeleteExpired()
+			return err
+		}).Exclusive(m.locker),
+	}
+}